@@ -6187,10 +6187,10 @@ def go_deps():
         name = "com_github_nats_io_nats_go",
         build_file_proto_mode = "disable_global",
         importpath = "github.com/nats-io/nats.go",
-        sha256 = "34a735d158d70685faad1fc3153f08da0ddc21c0ae42f6a0cb09430d638364b2",
-        strip_prefix = "github.com/nats-io/nats.go@v1.9.1",
+        sha256 = "018b59c6515dde8ba50ea6af4a6ea5b975ed3461e9b045e035255f4718b602bc",
+        strip_prefix = "github.com/nats-io/nats.go@v1.17.0",
         urls = [
-            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/nats-io/nats.go/com_github_nats_io_nats_go-v1.9.1.zip",
+            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/nats-io/nats.go/com_github_nats_io_nats_go-v1.17.0.zip",
         ],
     )
     go_repository(
@@ -6207,10 +6207,10 @@ def go_deps():
         name = "com_github_nats_io_nkeys",
         build_file_proto_mode = "disable_global",
         importpath = "github.com/nats-io/nkeys",
-        sha256 = "291930a7abcd84edcaffc2cadc75aeb830ebf561144313ead6b8e1fcc03b124f",
-        strip_prefix = "github.com/nats-io/nkeys@v0.1.3",
+        sha256 = "9383fa98356bb67ba1110814918e9997fdbcb83c08ffd6902b5aed7b9d96dfa2",
+        strip_prefix = "github.com/nats-io/nkeys@v0.3.0",
         urls = [
-            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/nats-io/nkeys/com_github_nats_io_nkeys-v0.1.3.zip",
+            "https://storage.googleapis.com/cockroach-godeps/gomod/github.com/nats-io/nkeys/com_github_nats_io_nkeys-v0.3.0.zip",
         ],
     )
     go_repository(
@@ -0,0 +1,463 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/cloud/externalconn"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/ingesting"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// attachBackupOptDatabase is the option of ATTACH BACKUP naming the database
+// of the backup to attach, when the backup contains more than one.
+const attachBackupOptDatabase = "database"
+
+// attachBackupPlanHook implements sql.PlanHookFn. ATTACH BACKUP creates a
+// database whose tables are the tables of a database in a backup. The tables
+// are read-only, and their scans read the files of the backup rather than KV,
+// so that a backup can be queried with plain SQL, including joins, without
+// restoring it. The optimizer constrains the scans of the primary indexes of
+// the tables with the filters of the query, so that only the files
+// overlapping the resulting spans are read.
+//
+// Only the primary indexes of the tables are attached. The backup is detached
+// by dropping the database.
+//
+// The locations of the backup are stored in the descriptors of the tables, so
+// they must be External Connections: the credentials of an External Connection
+// are stored in system.external_connections rather than in the URI, and using
+// it requires the USAGE privilege, which is checked again whenever a table is
+// scanned.
+func attachBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	attachStmt, ok := stmt.(*tree.AttachBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, attachStmt.Subdir, "ATTACH BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	inColFn, err := p.TypeAsStringArray(ctx, tree.Exprs(attachStmt.InCollection), "ATTACH BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	expected := map[string]sql.KVStringOptValidate{
+		backupencryption.BackupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupencryption.BackupOptEncKMS:        sql.KVStringOptRequireValue,
+		backupOptIncStorage:                     sql.KVStringOptRequireValue,
+		attachBackupOptDatabase:                 sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, attachStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, _ chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		// Older nodes don't know about the backup source of the attached tables,
+		// and would treat them as empty, writable tables.
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.AttachBackup) {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"cannot run ATTACH BACKUP before system is fully upgraded to v22.2")
+		}
+
+		hasCreateDB, err := p.HasRoleOption(ctx, roleoption.CREATEDB)
+		if err != nil {
+			return err
+		}
+		if !hasCreateDB {
+			return pgerror.New(pgcode.InsufficientPrivilege, "permission denied to create database")
+		}
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		dest, err := inColFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		if len(dest) < 1 || len(dest[0]) < 1 {
+			return errors.New("invalid base backup specified")
+		}
+		source := descpb.BackupTableSource{
+			CollectionURIs:     dest,
+			IncrementalStorage: opts[backupOptIncStorage],
+			KMSURI:             opts[backupencryption.BackupOptEncKMS],
+		}
+		if err := checkBackupTableSourceURIs(ctx, p, &source); err != nil {
+			return err
+		}
+		// The options are stored in the descriptors of the tables, to resolve
+		// the backup when they are scanned, and a passphrase cannot be stored.
+		if _, ok := opts[backupencryption.BackupOptEncPassphrase]; ok {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"ATTACH BACKUP does not support backups encrypted with a passphrase")
+		}
+
+		var endTime hlc.Timestamp
+		if attachStmt.AsOf.Expr != nil {
+			asOf, err := p.EvalAsOfTimestamp(ctx, attachStmt.AsOf)
+			if err != nil {
+				return err
+			}
+			endTime = asOf.Timestamp
+		}
+		if strings.EqualFold(subdir, backupbase.LatestFileName) {
+			latest, err := backupdest.ReadLatestFile(ctx, dest[0],
+				p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+			if err != nil {
+				return errors.Wrap(err, "read LATEST path")
+			}
+			subdir = latest
+		}
+
+		mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+		defer mem.Close(ctx)
+		chain, err := resolveBackupChain(ctx, p, dest, subdir, opts, endTime, &mem)
+		if err != nil {
+			return err
+		}
+		if endTime.IsEmpty() {
+			endTime = chain.manifests[len(chain.manifests)-1].EndTime
+		}
+		allDescs, _, err := backupinfo.LoadSQLDescsFromBackupsAtTime(chain.manifests, endTime)
+		if err != nil {
+			return err
+		}
+
+		source.Subdir = subdir
+		source.EndTime = endTime
+		return attachBackupDatabase(ctx, p, allDescs, opts[attachBackupOptDatabase],
+			string(attachStmt.Database), source)
+	}
+	return fn, nil, nil, false, nil
+}
+
+// checkBackupTableSourceURIs checks that the locations of the backup of a
+// table attached from a backup are External Connections, and that the user
+// has the privileges to use them.
+func checkBackupTableSourceURIs(
+	ctx context.Context, p sql.PlanHookState, source *descpb.BackupTableSource,
+) error {
+	uris := append([]string(nil), source.CollectionURIs...)
+	if source.IncrementalStorage != "" {
+		uris = append(uris, source.IncrementalStorage)
+	}
+	if source.KMSURI != "" {
+		uris = append(uris, source.KMSURI)
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != externalconn.Scheme {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"ATTACH BACKUP requires the backup and its KMS to be specified with External "+
+					"Connections, since the locations are stored in the descriptors of the tables; "+
+					"got a %s URI", u.Scheme)
+		}
+	}
+	return cloudprivilege.CheckDestinationPrivileges(ctx, p, uris)
+}
+
+// attachBackupDatabase writes the descriptors of a new database with the
+// given name, whose schemas and tables are those of the database with the
+// given name among the descriptors of a backup. If backupDBName is empty, the
+// backup must contain a single database.
+func attachBackupDatabase(
+	ctx context.Context,
+	p sql.PlanHookState,
+	allDescs []catalog.Descriptor,
+	backupDBName, name string,
+	source descpb.BackupTableSource,
+) error {
+	var backupDB catalog.DatabaseDescriptor
+	for _, desc := range allDescs {
+		db, ok := desc.(catalog.DatabaseDescriptor)
+		if !ok || !db.Public() || db.GetID() == keys.SystemDatabaseID ||
+			(backupDBName != "" && db.GetName() != backupDBName) {
+			continue
+		}
+		if backupDB != nil {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"the backup contains more than one database, specify the one to attach with the %s option",
+				attachBackupOptDatabase)
+		}
+		backupDB = db
+	}
+	if backupDB == nil {
+		if backupDBName != "" {
+			return errors.Errorf("database %q not found in the backup", backupDBName)
+		}
+		return errors.New("the backup does not contain a database")
+	}
+
+	descsCol := p.ExtendedEvalContext().Descs
+	dbID, err := descsCol.Direct().LookupDatabaseID(ctx, p.Txn(), name)
+	if err != nil {
+		return err
+	}
+	if dbID != descpb.InvalidID {
+		return sqlerrors.NewDatabaseAlreadyExistsError(name)
+	}
+
+	idGen := p.ExecCfg().DescIDGenerator
+	if dbID, err = idGen.GenerateUniqueDescID(ctx); err != nil {
+		return err
+	}
+	publicSchemaID, err := idGen.GenerateUniqueDescID(ctx)
+	if err != nil {
+		return err
+	}
+	db := dbdesc.NewInitial(dbID, name, p.User(), dbdesc.WithPublicSchemaID(publicSchemaID))
+	schemas := []catalog.SchemaDescriptor{schemadesc.NewBuilder(&descpb.SchemaDescriptor{
+		ParentID:   dbID,
+		Name:       tree.PublicSchema,
+		ID:         publicSchemaID,
+		Privileges: catpb.NewPublicSchemaPrivilegeDescriptor(),
+		Version:    1,
+	}).BuildCreatedMutableSchema()}
+
+	// schemaIDs maps the IDs of the schemas in the backup to the IDs of the
+	// schemas of the attached database.
+	schemaIDs := map[descpb.ID]descpb.ID{
+		keys.PublicSchemaIDForBackup:            publicSchemaID,
+		descpb.InvalidID:                        publicSchemaID,
+		backupDB.GetSchemaID(tree.PublicSchema): publicSchemaID,
+	}
+	for _, desc := range allDescs {
+		sc, ok := desc.(catalog.SchemaDescriptor)
+		if !ok || sc.GetParentID() != backupDB.GetID() || sc.GetName() == tree.PublicSchema || !sc.Public() {
+			continue
+		}
+		id, err := idGen.GenerateUniqueDescID(ctx)
+		if err != nil {
+			return err
+		}
+		scDesc := protoutil.Clone(sc.SchemaDesc()).(*descpb.SchemaDescriptor)
+		scDesc.ID = id
+		scDesc.ParentID = dbID
+		scDesc.Version = 1
+		scDesc.ModificationTime = hlc.Timestamp{}
+		scDesc.Privileges = catpb.NewBasePrivilegeDescriptor(p.User())
+		scDesc.Functions = nil
+		scDesc.DeclarativeSchemaChangerState = nil
+		schemas = append(schemas, schemadesc.NewBuilder(scDesc).BuildCreatedMutableSchema())
+		db.AddSchemaToDatabase(sc.GetName(), descpb.DatabaseDescriptor_SchemaInfo{ID: id})
+		schemaIDs[sc.GetID()] = id
+	}
+
+	var tables []catalog.TableDescriptor
+	for _, desc := range allDescs {
+		table, ok := desc.(catalog.TableDescriptor)
+		if !ok || table.GetParentID() != backupDB.GetID() || !table.Public() ||
+			!table.IsPhysicalTable() || table.IsSequence() || table.IsTemporary() {
+			continue
+		}
+		schemaID, ok := schemaIDs[table.GetParentSchemaID()]
+		if !ok {
+			continue
+		}
+		if reason := unattachableBackupTableReason(table); reason != "" {
+			p.BufferClientNotice(ctx, pgnotice.Newf("table %q is not attached: %s", table.GetName(), reason))
+			continue
+		}
+		id, err := idGen.GenerateUniqueDescID(ctx)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, makeAttachedBackupTable(table, id, dbID, schemaID, p, source))
+	}
+
+	return ingesting.WriteDescriptors(ctx, p.ExecCfg().Codec, p.Txn(), p.User(), descsCol,
+		[]catalog.DatabaseDescriptor{db}, schemas, tables, nil /* types */, nil, /* functions */
+		tree.RequestedDescriptors, nil /* extra */, "" /* inheritParentName */)
+}
+
+// unattachableBackupTableReason returns why a table in a backup cannot be
+// attached, or the empty string if it can.
+func unattachableBackupTableReason(table catalog.TableDescriptor) string {
+	// The types in the backup would need to be attached along with the table.
+	if len(table.TableDesc().DependsOnTypes) > 0 {
+		return "it references user-defined types"
+	}
+	if table.GetExcludeDataFromBackup() {
+		return "its data is excluded from backups"
+	}
+	return ""
+}
+
+// makeAttachedBackupTable returns the descriptor of the table attached from
+// the given table in a backup, with the given IDs. Only the primary index and
+// the constraints which need no other descriptor are kept, and the columns
+// lose their default expressions, since the table is read-only.
+func makeAttachedBackupTable(
+	table catalog.TableDescriptor,
+	id, parentID, parentSchemaID descpb.ID,
+	p sql.PlanHookState,
+	source descpb.BackupTableSource,
+) catalog.TableDescriptor {
+	desc := protoutil.Clone(table.TableDesc()).(*descpb.TableDescriptor)
+	desc.ID = id
+	desc.ParentID = parentID
+	desc.UnexposedParentSchemaID = parentSchemaID
+	desc.Version = 1
+	desc.ModificationTime = hlc.Timestamp{}
+	desc.State = descpb.DescriptorState_PUBLIC
+	desc.OfflineReason = ""
+	desc.Privileges = catpb.NewBasePrivilegeDescriptor(p.User())
+	desc.Indexes = nil
+	desc.Mutations = nil
+	desc.MutationJobs = nil
+	desc.DeclarativeSchemaChangerState = nil
+	desc.OutboundFKs = nil
+	desc.InboundFKs = nil
+	desc.DependedOnBy = nil
+	desc.Triggers = nil
+	desc.RowLevelTTL = nil
+	desc.LocalityConfig = nil
+	for i := range desc.Columns {
+		col := &desc.Columns[i]
+		col.DefaultExpr = nil
+		col.OnUpdateExpr = nil
+		col.UsesSequenceIds = nil
+		col.OwnsSequenceIds = nil
+	}
+	source.TableID = table.GetID()
+	desc.BackupSource = &source
+	return tabledesc.NewBuilder(desc).BuildCreatedMutableTable()
+}
+
+// readAttachedBackupTable implements sql.ReadBackupTableCCL. The backup chain
+// is resolved again for every scan, so that no state outlives the statement.
+func readAttachedBackupTable(
+	ctx context.Context,
+	p sql.PlanHookState,
+	table catalog.TableDescriptor,
+	spans roachpb.Spans,
+	fetchCols []descpb.ColumnID,
+	emit func(context.Context, tree.Datums) error,
+) error {
+	source := table.GetBackupSource()
+	if source == nil {
+		return errors.AssertionFailedf("table %q is not attached from a backup", table.GetName())
+	}
+	// The table may be scanned by a user other than the one who attached it,
+	// and the privileges on the External Connections may have changed since.
+	if err := checkBackupTableSourceURIs(ctx, p, source); err != nil {
+		return err
+	}
+	opts := make(map[string]string)
+	if source.IncrementalStorage != "" {
+		opts[backupOptIncStorage] = source.IncrementalStorage
+	}
+	if source.KMSURI != "" {
+		opts[backupencryption.BackupOptEncKMS] = source.KMSURI
+	}
+
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+	chain, err := resolveBackupChain(ctx, p, source.CollectionURIs, source.Subdir, opts, source.EndTime, &mem)
+	if err != nil {
+		return err
+	}
+	backupTable, err := resolveBackupTableByID(chain.manifests, source.TableID, source.EndTime)
+	if err != nil {
+		return err
+	}
+
+	// The spans are in the keyspace of the attached table, and the files of
+	// the backup in that of the table in the backup.
+	codec := p.ExecCfg().Codec
+	attachedPrefix := codec.TablePrefix(uint32(table.GetID()))
+	backupPrefix := codec.TablePrefix(uint32(backupTable.GetID()))
+	rewrite := func(key roachpb.Key) (roachpb.Key, error) {
+		if !bytes.HasPrefix(key, attachedPrefix) {
+			return nil, errors.AssertionFailedf("key %s is not in table %q", key, table.GetName())
+		}
+		return append(backupPrefix[:len(backupPrefix):len(backupPrefix)], key[len(attachedPrefix):]...), nil
+	}
+	backupSpans := make([]roachpb.Span, len(spans))
+	for i, sp := range spans {
+		if backupSpans[i].Key, err = rewrite(sp.Key); err != nil {
+			return err
+		}
+		if len(sp.EndKey) > 0 {
+			if backupSpans[i].EndKey, err = rewrite(sp.EndKey); err != nil {
+				return err
+			}
+		}
+	}
+	return readBackupTableRows(ctx, p, chain, source.EndTime, source.EndTime, backupTable, table,
+		backupSpans, fetchCols, emit)
+}
+
+// resolveBackupTableByID returns the descriptor of the table with the given
+// ID in the backup as of endTime.
+func resolveBackupTableByID(
+	manifests []backuppb.BackupManifest, id descpb.ID, endTime hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	allDescs, _, err := backupinfo.LoadSQLDescsFromBackupsAtTime(manifests, endTime)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range allDescs {
+		if table, ok := desc.(catalog.TableDescriptor); ok && table.GetID() == id {
+			return table, nil
+		}
+	}
+	return nil, errors.Errorf("table with ID %d not found in the backup", id)
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.attachBackupPlanHook", attachBackupPlanHook)
+	sql.ReadBackupTableCCL = readAttachedBackupTable
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 20
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE SCHEMA data.sc`)
+	sqlDB.Exec(t, `CREATE TABLE data.sc.owners (id INT PRIMARY KEY, account INT, name STRING, INDEX (account))`)
+	sqlDB.Exec(t, `INSERT INTO data.sc.owners SELECT i, i % 5, 'owner' || i::STRING FROM generate_series(1, 10) AS g(i)`)
+
+	// The locations of an attached backup are stored in the descriptors of its
+	// tables, so they must be External Connections rather than raw URIs.
+	sqlDB.Exec(t, `CREATE EXTERNAL CONNECTION attach AS 'nodelocal://0/attach'`)
+	const collection = "external://attach"
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	sqlDB.ExpectErr(t, `ATTACH BACKUP requires the backup and its KMS to be specified with External Connections`,
+		`ATTACH BACKUP FROM LATEST IN 'nodelocal://0/attach' AS attached`)
+	original := sqlDB.QueryStr(t, `SELECT id, balance, payload FROM data.bank ORDER BY id`)
+
+	// Changes made after the backup are not visible in the attached tables.
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 5`)
+	sqlDB.Exec(t, fmt.Sprintf(`ATTACH BACKUP FROM LATEST IN '%s' AS attached`, collection))
+
+	sqlDB.CheckQueryResults(t, `SELECT id, balance, payload FROM attached.bank ORDER BY id`, original)
+	sqlDB.CheckQueryResults(t, `SELECT id FROM attached.bank WHERE id BETWEEN 3 AND 5 ORDER BY id`,
+		[][]string{{"3"}, {"4"}, {"5"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM attached.bank WHERE balance = 0`,
+		[][]string{{fmt.Sprint(numAccounts)}})
+
+	// The tables of the backup are joined by name, and filters on the primary
+	// key of a table constrain the spans read from the backup.
+	sqlDB.CheckQueryResults(t, `
+SELECT b.id, o.name
+  FROM attached.bank AS b JOIN attached.sc.owners AS o ON o.account = b.id
+ WHERE o.id < 4
+ ORDER BY o.id`,
+		[][]string{{"1", "owner1"}, {"2", "owner2"}, {"3", "owner3"}})
+	var explain []string
+	for _, row := range sqlDB.QueryStr(t, `EXPLAIN SELECT * FROM attached.bank WHERE id = 3`) {
+		explain = append(explain, row[0])
+	}
+	plan := strings.Join(explain, "\n")
+	require.Contains(t, plan, "table: bank@bank_pkey")
+	require.Contains(t, plan, "spans: [/3 - /3]")
+
+	// The attached tables are read-only.
+	sqlDB.ExpectErr(t, `cannot mutate table "bank" attached from a backup`,
+		`INSERT INTO attached.bank VALUES (1000, 0, '')`)
+	sqlDB.ExpectErr(t, `cannot mutate table "bank" attached from a backup`,
+		`DELETE FROM attached.bank WHERE id = 1`)
+	sqlDB.ExpectErr(t, `cannot truncate table "bank" attached from a backup`,
+		`TRUNCATE attached.bank`)
+	sqlDB.ExpectErr(t, `schema changes are not supported on tables attached from a backup`,
+		`ALTER TABLE attached.bank ADD COLUMN c INT`)
+	sqlDB.ExpectErr(t, `row-level locking is not supported`,
+		`SELECT * FROM attached.bank WHERE id = 1 FOR UPDATE`)
+
+	sqlDB.ExpectErr(t, `database "attached" already exists`,
+		fmt.Sprintf(`ATTACH BACKUP FROM LATEST IN '%s' AS attached`, collection))
+	sqlDB.ExpectErr(t, `database "other" not found in the backup`,
+		fmt.Sprintf(`ATTACH BACKUP FROM LATEST IN '%s' AS other WITH database = 'other'`, collection))
+
+	// Dropping the database detaches the backup.
+	sqlDB.Exec(t, `DROP DATABASE attached CASCADE`)
+	sqlDB.ExpectErr(t, `relation "attached.bank" does not exist`, `SELECT * FROM attached.bank`)
+}
@@ -625,6 +625,12 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		MaxRetries: 5,
 	}
 
+	// A continuous backup that already completed its full backup only needs to
+	// keep extending its log.
+	if frontier := continuousLogFrontier(b.job); details.Continuous && !frontier.IsEmpty() {
+		return b.runContinuousBackupLog(ctx, p.ExecCfg(), details, backupManifest, defaultStore, frontier)
+	}
+
 	if err := p.ExecCfg().JobRegistry.CheckPausepoint("backup.before.flow"); err != nil {
 		return err
 	}
@@ -696,7 +702,10 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		return err
 	}
 
-	if details.ProtectedTimestampRecord != nil && !b.testingKnobs.ignoreProtectedTimestamps {
+	// A continuous backup keeps its protected timestamp record to protect the
+	// history it is yet to write to its log.
+	if details.ProtectedTimestampRecord != nil && !details.Continuous &&
+		!b.testingKnobs.ignoreProtectedTimestamps {
 		if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 			details := b.job.Details().(jobspb.BackupDetails)
 			return releaseProtectedTimestamp(ctx, txn, p.ExecCfg().ProtectedTimestampProvider,
@@ -739,6 +748,11 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 
 	b.backupStats = res
 
+	if details.Continuous {
+		return b.runContinuousBackupLog(ctx, p.ExecCfg(), details, backupManifest, defaultStore,
+			backupManifest.EndTime)
+	}
+
 	// Collect telemetry.
 	{
 		numClusterNodes, err := clusterNodeCount(p.ExecCfg().Gossip)
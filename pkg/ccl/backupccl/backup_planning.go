@@ -189,6 +189,7 @@ func resolveOptionsForBackupJobDescription(
 	newOpts := tree.BackupOptions{
 		CaptureRevisionHistory: opts.CaptureRevisionHistory,
 		Detached:               opts.Detached,
+		Continuous:             opts.Continuous,
 	}
 
 	if opts.EncryptionPassphrase != nil {
@@ -542,6 +543,13 @@ func backupPlanHook(
 	if backupStmt.Options.Detached == tree.DBoolTrue {
 		detached = true
 	}
+	// A continuous backup never completes on its own, so it always runs
+	// detached.
+	continuous := false
+	if backupStmt.Options.Continuous == tree.DBoolTrue {
+		continuous = true
+		detached = true
+	}
 	revisionHistoryFn := func() (bool, error) { return false, nil } // Defaults to false.
 	if backupStmt.Options.CaptureRevisionHistory != nil {
 		revisionHistoryFn, err = p.TypeAsBool(ctx, backupStmt.Options.CaptureRevisionHistory, "BACKUP")
@@ -665,6 +673,19 @@ func backupPlanHook(
 			}
 		}
 
+		if continuous {
+			if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ContinuousBackups) {
+				return pgerror.New(pgcode.FeatureNotSupported,
+					"cannot run a continuous backup before system is fully upgraded to v22.2")
+			}
+			if err := checkContinuousBackupOptions(backupStmt, encryptionParams, incrementalStorage); err != nil {
+				return err
+			}
+			if err := requireEnterprise(p.ExecCfg(), "continuous"); err != nil {
+				return err
+			}
+		}
+
 		var targetDescs []catalog.Descriptor
 		var completeDBs []descpb.ID
 		var requestedDBs []catalog.DatabaseDescriptor
@@ -704,6 +725,7 @@ func backupPlanHook(
 			AsOfInterval:        asOfInterval,
 			Detached:            detached,
 			ApplicationName:     p.SessionData().ApplicationName,
+			Continuous:          continuous,
 		}
 		if backupStmt.CreatedByInfo != nil && backupStmt.CreatedByInfo.Name == jobs.CreatedByScheduledJobs {
 			initialDetails.ScheduleID = backupStmt.CreatedByInfo.ID
@@ -0,0 +1,292 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupresolver"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// backupTableReaderKVBatchSize is the number of KVs read from the backup which
+// are buffered before they are decoded into rows.
+const backupTableReaderKVBatchSize = 1024
+
+// resolveBackupTable returns the descriptor of the table with the given name
+// in the backup as of endTime.
+func resolveBackupTable(
+	ctx context.Context,
+	p sql.PlanHookState,
+	manifests []backuppb.BackupManifest,
+	name *tree.UnresolvedObjectName,
+	endTime hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	allDescs, _, err := backupinfo.LoadSQLDescsFromBackupsAtTime(manifests, endTime)
+	if err != nil {
+		return nil, err
+	}
+	pattern := name.ToUnresolvedName()
+	matched, err := backupresolver.DescriptorsMatchingTargets(ctx,
+		p.CurrentDatabase(), p.CurrentSearchPath(), allDescs,
+		tree.BackupTargetList{Tables: tree.TableAttrs{TablePatterns: tree.TablePatterns{pattern}}}, endTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the table in the backup")
+	}
+	table, ok := matched.DescsByTablePattern[pattern].(catalog.TableDescriptor)
+	if !ok {
+		return nil, errors.Errorf("table %s not found in the backup", tree.ErrString(name))
+	}
+	if !table.IsPhysicalTable() || table.IsSequence() {
+		return nil, errors.Errorf("%s in the backup is not a table", tree.ErrString(name))
+	}
+	return table, nil
+}
+
+// storedBackupTableColumns returns the public columns of a table in a backup
+// which are stored in its primary index, and can thus be read from the backup.
+func storedBackupTableColumns(table catalog.TableDescriptor, op string) ([]catalog.Column, error) {
+	var cols []catalog.Column
+	for _, col := range table.PublicColumns() {
+		if col.IsVirtual() {
+			continue
+		}
+		// The types in the backup would need to be hydrated using the type
+		// descriptors of the backup.
+		if col.GetType().UserDefined() {
+			return nil, unimplemented.Newf("backup table user-defined types",
+				"%s does not support column %q of user-defined type %s",
+				op, col.GetName(), col.GetType().SQLString())
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// constrainBackupTableSpans returns the spans of the primary index of a table
+// in a backup which contain the rows matching the given filter, and the part
+// of the filter which the spans do not account for. A nil filter matches all
+// the rows. The filter refers to the columns of the table in the backup.
+func constrainBackupTableSpans(
+	ctx context.Context,
+	p sql.PlanHookState,
+	req sql.SpanConstraintRequirement,
+	table catalog.TableDescriptor,
+	filter tree.Expr,
+) ([]roachpb.Span, tree.Expr, error) {
+	if filter == nil {
+		return []roachpb.Span{table.PrimaryIndexSpan(p.ExecCfg().Codec)}, tree.DBoolTrue, nil
+	}
+	tn := tree.MakeUnqualifiedTableName(tree.Name(table.GetName()))
+	return p.ConstrainPrimaryIndexSpanByExpr(ctx, req, &tn, table,
+		&p.ExtendedEvalContext().Context, p.SemaCtx(), filter)
+}
+
+// readBackupTableRows reads the given spans of the primary index of
+// backupTable as of readTime from the files of the backup chain, without
+// ingesting them into KV, and passes the rows to emit in primary key order.
+// Only the files overlapping the spans are read. The keys are rewritten into
+// the keyspace of table, which is backupTable possibly with another ID, and
+// decoded with it. Only the columns in fetchCols are decoded.
+func readBackupTableRows(
+	ctx context.Context,
+	p sql.PlanHookState,
+	chain resolvedBackupChain,
+	endTime, readTime hlc.Timestamp,
+	backupTable, table catalog.TableDescriptor,
+	spans []roachpb.Span,
+	fetchCols []descpb.ColumnID,
+	emit func(context.Context, tree.Datums) error,
+) error {
+	manifests := chain.manifests
+	if err := checkCoverage(ctx, spans, manifests); err != nil {
+		return err
+	}
+	backupLocalityMap, err := makeBackupLocalityMap(chain.localityInfo, p.User())
+	if err != nil {
+		return errors.Wrap(err, "resolving locality locations")
+	}
+	introducedSpanFrontier, err := createIntroducedSpanFrontier(manifests, endTime)
+	if err != nil {
+		return err
+	}
+	entries := makeSimpleImportSpans(spans, manifests, backupLocalityMap,
+		introducedSpanFrontier, nil /* lowWaterMark */, targetRestoreSpanSize.Get(p.ExecCfg().SV()))
+
+	kr, err := makeKeyRewriter(p.ExecCfg().Codec,
+		map[descpb.ID]catalog.TableDescriptor{backupTable.GetID(): table},
+		nil /* tenants */, false /* restoreTenantFromStream */)
+	if err != nil {
+		return err
+	}
+
+	r := &backupTableReader{p: p, emit: emit}
+	var spec descpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, p.ExecCfg().Codec, table, table.GetPrimaryIndex(), fetchCols,
+	); err != nil {
+		return err
+	}
+	if err := r.fetcher.Init(ctx, row.FetcherInitArgs{
+		WillUseCustomKVBatchFetcher: true,
+		Alloc:                       &tree.DatumAlloc{},
+		Spec:                        &spec,
+	}); err != nil {
+		return err
+	}
+	defer r.fetcher.Close(ctx)
+
+	for _, entry := range entries {
+		if err := r.readSpanEntry(ctx, kr, entry, chain.encryption, readTime); err != nil {
+			return err
+		}
+	}
+	return r.decode(ctx)
+}
+
+// backupTableReader decodes the KVs of a table read from a backup into rows.
+type backupTableReader struct {
+	p    sql.PlanHookState
+	emit func(context.Context, tree.Datums) error
+
+	fetcher   row.Fetcher
+	kvFetcher row.SpanKVFetcher
+	kvs       []roachpb.KeyValue
+}
+
+// readSpanEntry reads the files of the entry as of readTime, and buffers the
+// rewritten KVs of the table for decoding.
+func (r *backupTableReader) readSpanEntry(
+	ctx context.Context,
+	kr *KeyRewriter,
+	entry execinfrapb.RestoreSpanEntry,
+	encryption *jobspb.BackupEncryptionOptions,
+	readTime hlc.Timestamp,
+) error {
+	storeFiles := make([]storageccl.StoreFile, 0, len(entry.Files))
+	defer func() {
+		for _, sf := range storeFiles {
+			if err := sf.Store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	for _, file := range entry.Files {
+		dir, err := r.p.ExecCfg().DistSQLSrv.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return err
+		}
+		storeFiles = append(storeFiles, storageccl.StoreFile{Store: dir, FilePath: file.Path})
+	}
+	iterOpts := storage.IterOptions{
+		RangeKeyMaskingBelow: readTime,
+		KeyTypes:             storage.IterKeyTypePointsAndRanges,
+		LowerBound:           keys.LocalMax,
+		UpperBound:           keys.MaxKey,
+	}
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, encryption, iterOpts)
+	if err != nil {
+		return err
+	}
+	readAsOfIter := storage.NewReadAsOfIterator(iter, readTime)
+	defer readAsOfIter.Close()
+
+	startKeyMVCC, endKeyMVCC := storage.MVCCKey{Key: entry.Span.Key},
+		storage.MVCCKey{Key: entry.Span.EndKey}
+	for readAsOfIter.SeekGE(startKeyMVCC); ; readAsOfIter.NextKey() {
+		ok, err := readAsOfIter.Valid()
+		if err != nil {
+			return err
+		}
+		if !ok || !readAsOfIter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		key := readAsOfIter.UnsafeKey()
+		// The decoded datums may alias the KVs, so they are not reused.
+		rewritten, ok, err := kr.RewriteKey(append(roachpb.Key(nil), key.Key...), key.Timestamp.WallTime)
+		if errors.Is(err, ErrImportingKeyError) {
+			// Keys of an in-progress import are not part of the table.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		value := roachpb.Value{RawBytes: append([]byte(nil), readAsOfIter.UnsafeValue()...)}
+		// Rewriting the key means the checksum needs to be updated.
+		value.ClearChecksum()
+		value.InitChecksum(rewritten)
+		if err := r.addKV(ctx, roachpb.KeyValue{Key: rewritten, Value: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addKV buffers the KV for decoding. The buffered KVs are only decoded at row
+// boundaries, since a row may be made of KVs of multiple column families.
+func (r *backupTableReader) addKV(ctx context.Context, kv roachpb.KeyValue) error {
+	if len(r.kvs) >= backupTableReaderKVBatchSize {
+		prev, err := keys.EnsureSafeSplitKey(r.kvs[len(r.kvs)-1].Key)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(kv.Key, prev) {
+			if err := r.decode(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	r.kvs = append(r.kvs, kv)
+	return nil
+}
+
+// decode decodes the buffered KVs into rows and emits them.
+func (r *backupTableReader) decode(ctx context.Context) error {
+	if len(r.kvs) == 0 {
+		return nil
+	}
+	r.kvFetcher.KVs = r.kvs
+	r.kvs = nil
+	if err := r.fetcher.StartScanFrom(ctx, &r.kvFetcher); err != nil {
+		return err
+	}
+	for {
+		datums, err := r.fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return err
+		}
+		if datums == nil {
+			return nil
+		}
+		if err := r.emit(ctx, append(tree.Datums(nil), datums...)); err != nil {
+			return err
+		}
+	}
+}
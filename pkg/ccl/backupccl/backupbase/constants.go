@@ -43,4 +43,14 @@ const (
 	// DefaultIncrementalsSubdir is the default name of the subdirectory to which
 	// incremental backups will be written.
 	DefaultIncrementalsSubdir = "incrementals"
+
+	// ContinuousLogDirectory is the directory, relative to a full backup, to
+	// which a continuous backup writes segments of MVCC history once the full
+	// backup completes.
+	ContinuousLogDirectory = "log"
+
+	// ContinuousLogFrontierName is the name of the file, in the directory of the
+	// log of a continuous backup, recording that the log contains no history
+	// past its last segment up to a later time.
+	ContinuousLogFrontierName = "FRONTIER"
 )
@@ -558,6 +558,13 @@ func ResolveBackupManifests(
 		return nil, nil, nil, 0, err
 	}
 
+	enc := jobspb.BackupEncryptionOptions{
+		Mode: jobspb.EncryptionMode_None,
+	}
+	if encryption != nil {
+		enc = *encryption
+	}
+
 	// If we discovered additional layers, handle them too.
 	if numLayers > 1 {
 		numPartitions := len(fullyResolvedIncrementalsDirectory)
@@ -586,12 +593,6 @@ func ResolveBackupManifests(
 
 		// Load the default backup manifests for each backup layer, this is done
 		// concurrently.
-		enc := jobspb.BackupEncryptionOptions{
-			Mode: jobspb.EncryptionMode_None,
-		}
-		if encryption != nil {
-			enc = *encryption
-		}
 		defaultManifestsForEachLayer, _, memSize, err := backupinfo.FetchPreviousBackups(ctx, mem, user,
 			mkStore, defaultURIs[1:], enc, kmsEnv)
 		if err != nil {
@@ -623,6 +624,44 @@ func ResolveBackupManifests(
 		}
 	}
 
+	// A continuous backup keeps writing the MVCC history of its spans into a log
+	// next to the full backup once it completes. If the requested time is past
+	// the end of the last layer, extend the chain with the log segments that
+	// cover it. Each segment is a revision history layer of its own.
+	if last := mainBackupManifests[len(mainBackupManifests)-1]; last.EndTime.Less(endTime) {
+		segments, err := FindContinuousLogSegments(ctx, baseStores[0], last.EndTime, endTime)
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		if len(segments) > 0 {
+			segmentURIs := make([]string, len(segments))
+			for i := range segments {
+				segmentURIs[i], err = continuousLogSegmentURI(fullyResolvedBaseDirectory[0], segments[i])
+				if err != nil {
+					return nil, nil, nil, 0, err
+				}
+			}
+			segmentManifests, _, memSize, err := backupinfo.FetchPreviousBackups(ctx, mem, user,
+				mkStore, segmentURIs, enc, kmsEnv)
+			if err != nil {
+				return nil, nil, nil, 0, err
+			}
+			ownedMemSize += memSize
+			// The log doesn't write segments that would contain no history, but
+			// records how far past its last segment it is known to be empty.
+			lastSegment := &segmentManifests[len(segmentManifests)-1]
+			if lastSegment.EndTime, err = ReadContinuousLogFrontier(
+				ctx, baseStores[0], lastSegment.EndTime); err != nil {
+				return nil, nil, nil, 0, err
+			}
+			defaultURIs = append(defaultURIs, segmentURIs...)
+			mainBackupManifests = append(mainBackupManifests, segmentManifests...)
+			// Log segments are never partitioned by locality.
+			localityInfo = append(localityInfo,
+				make([]jobspb.RestoreDetails_BackupLocalityInfo, len(segments))...)
+		}
+	}
+
 	totalMemSize := ownedMemSize
 	ownedMemSize = 0
 
@@ -0,0 +1,197 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupdest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/ioctx"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// logSegmentRE matches the manifest of a continuous backup log segment relative
+// to the log directory. Segment directories are named after the start and end
+// time of the history they contain, with each timestamp zero padded so that
+// listing the directory returns segments in time order.
+var logSegmentRE = regexp.MustCompile(
+	`^/?([0-9]{19})([0-9]{10})-([0-9]{19})([0-9]{10})/` + backupbase.BackupManifestName + `$`)
+
+// ContinuousLogSegment is a segment of the log of a continuous backup that
+// contains the MVCC history of the backed up spans in (StartTime, EndTime].
+type ContinuousLogSegment struct {
+	// Dir is the directory of the segment relative to the full backup.
+	Dir       string
+	StartTime hlc.Timestamp
+	EndTime   hlc.Timestamp
+}
+
+// ContinuousLogSegmentDir returns the directory, relative to the full backup
+// it belongs to, of the log segment containing MVCC history in (start, end].
+func ContinuousLogSegmentDir(start, end hlc.Timestamp) string {
+	return fmt.Sprintf("%s/%019d%010d-%019d%010d", backupbase.ContinuousLogDirectory,
+		start.WallTime, start.Logical, end.WallTime, end.Logical)
+}
+
+func parseLogSegmentTimestamp(wall, logical string) (hlc.Timestamp, error) {
+	w, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	l, err := strconv.ParseInt(logical, 10, 32)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return hlc.Timestamp{WallTime: w, Logical: int32(l)}, nil
+}
+
+// ListContinuousLogSegments lists the segments of the log written by a
+// continuous backup into the directory of its full backup, ordered by start
+// time. Segments starting at the same time are ordered by decreasing end time,
+// so a segment which rolled up others comes before them.
+func ListContinuousLogSegments(
+	ctx context.Context, store cloud.ExternalStorage,
+) ([]ContinuousLogSegment, error) {
+	var all []ContinuousLogSegment
+	if err := store.List(ctx, backupbase.ContinuousLogDirectory+"/", listingDelimDataSlash,
+		func(p string) error {
+			m := logSegmentRE.FindStringSubmatch(p)
+			if m == nil {
+				return nil
+			}
+			start, err := parseLogSegmentTimestamp(m[1], m[2])
+			if err != nil {
+				return err
+			}
+			end, err := parseLogSegmentTimestamp(m[3], m[4])
+			if err != nil {
+				return err
+			}
+			all = append(all, ContinuousLogSegment{
+				Dir:       ContinuousLogSegmentDir(start, end),
+				StartTime: start,
+				EndTime:   end,
+			})
+			return nil
+		}); err != nil {
+		return nil, errors.Wrap(err, "reading continuous backup log")
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].StartTime.Equal(all[j].StartTime) {
+			return all[i].StartTime.Less(all[j].StartTime)
+		}
+		return all[j].EndTime.Less(all[i].EndTime)
+	})
+	return all, nil
+}
+
+// FindContinuousLogSegments lists the log written by a continuous backup into
+// the directory of its full backup and returns the segments that extend
+// history from `from` up to `to`, in order. Where segments have been rolled up
+// into a longer one, the longer segment is used. The chain stops at the first
+// gap in the log, so the returned segments may not cover `to` if the log
+// hasn't reached it yet.
+func FindContinuousLogSegments(
+	ctx context.Context, store cloud.ExternalStorage, from, to hlc.Timestamp,
+) ([]ContinuousLogSegment, error) {
+	ctx, sp := tracing.ChildSpan(ctx, "backupdest.FindContinuousLogSegments")
+	defer sp.Finish()
+
+	all, err := ListContinuousLogSegments(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	var segments []ContinuousLogSegment
+	for _, s := range all {
+		if from.Less(s.StartTime) || !from.Less(to) {
+			break
+		}
+		if !s.StartTime.Equal(from) {
+			continue
+		}
+		segments = append(segments, s)
+		from = s.EndTime
+	}
+	return segments, nil
+}
+
+// WriteContinuousLogFrontier records that the log of a continuous backup
+// contains no history in (segmentEnd, frontier], where segmentEnd is the end
+// time of its last segment. The log skips writing segments that would be
+// empty, so this lets the log be restored up to the frontier.
+func WriteContinuousLogFrontier(
+	ctx context.Context, store cloud.ExternalStorage, segmentEnd, frontier hlc.Timestamp,
+) error {
+	content := fmt.Sprintf("%s\n%s\n", segmentEnd.AsOfSystemTime(), frontier.AsOfSystemTime())
+	return cloud.WriteFile(ctx, store,
+		path.Join(backupbase.ContinuousLogDirectory, backupbase.ContinuousLogFrontierName),
+		strings.NewReader(content))
+}
+
+// ReadContinuousLogFrontier returns the time up to which the log of a
+// continuous backup contains no history past the segment ending at
+// segmentEnd. It returns segmentEnd if the log recorded no such time for that
+// segment, e.g. because later segments were written since.
+func ReadContinuousLogFrontier(
+	ctx context.Context, store cloud.ExternalStorage, segmentEnd hlc.Timestamp,
+) (hlc.Timestamp, error) {
+	r, err := store.ReadFile(ctx,
+		path.Join(backupbase.ContinuousLogDirectory, backupbase.ContinuousLogFrontierName))
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return segmentEnd, nil
+		}
+		return hlc.Timestamp{}, err
+	}
+	content, err := ioctx.ReadAll(ctx, r)
+	if closeErr := r.Close(ctx); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrap(err, "reading continuous backup log frontier")
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		return hlc.Timestamp{}, errors.Newf("malformed continuous backup log frontier %q", content)
+	}
+	recordedEnd, err := hlc.ParseHLC(fields[0])
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrap(err, "parsing continuous backup log frontier")
+	}
+	frontier, err := hlc.ParseHLC(fields[1])
+	if err != nil {
+		return hlc.Timestamp{}, errors.Wrap(err, "parsing continuous backup log frontier")
+	}
+	if !recordedEnd.Equal(segmentEnd) || frontier.Less(segmentEnd) {
+		return segmentEnd, nil
+	}
+	return frontier, nil
+}
+
+// continuousLogSegmentURI returns the URI of the log segment given the URI of
+// the full backup it belongs to.
+func continuousLogSegmentURI(baseURI string, segment ContinuousLogSegment) (string, error) {
+	u, err := url.Parse(baseURI)
+	if err != nil {
+		return "", err
+	}
+	u.Path = backuputils.JoinURLPath(u.Path, segment.Dir)
+	return u.String(), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupdest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindContinuousLogSegments(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tc, _, _, cleanupFn := backuputils.BackupDestinationTestSetup(t, backuputils.SingleNode, 1,
+		backuputils.InitManualReplication)
+	defer cleanupFn()
+
+	ctx := context.Background()
+	execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx,
+		fmt.Sprintf("nodelocal://1/%s?AUTH=implicit", t.Name()), username.RootUserName())
+	require.NoError(t, err)
+	defer store.Close()
+
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+	writeSegment := func(start, end hlc.Timestamp) {
+		dir := backupdest.ContinuousLogSegmentDir(start, end)
+		require.NoError(t, cloud.WriteFile(ctx, store, path.Join(dir, backupbase.BackupManifestName),
+			bytes.NewReader(nil)))
+		require.NoError(t, cloud.WriteFile(ctx, store, path.Join(dir, "data", "1.sst"),
+			bytes.NewReader(nil)))
+	}
+
+	// Segments only become visible once their manifest is written, so a segment
+	// containing only data files is ignored.
+	require.NoError(t, cloud.WriteFile(ctx, store,
+		path.Join(backupdest.ContinuousLogSegmentDir(ts(40), ts(50)), "data", "1.sst"),
+		bytes.NewReader(nil)))
+	writeSegment(ts(10), ts(20))
+	writeSegment(ts(20), hlc.Timestamp{WallTime: 30, Logical: 2})
+	writeSegment(hlc.Timestamp{WallTime: 30, Logical: 2}, ts(40))
+	// Segments after a gap in the log, which were rolled up into a single
+	// segment without the originals having been deleted yet.
+	writeSegment(ts(50), ts(60))
+	writeSegment(ts(60), ts(70))
+	writeSegment(ts(50), ts(70))
+
+	for _, td := range []struct {
+		name     string
+		from, to hlc.Timestamp
+		expected []hlc.Timestamp
+	}{
+		{name: "single", from: ts(10), to: ts(15), expected: []hlc.Timestamp{ts(20)}},
+		{name: "segment end", from: ts(10), to: ts(20), expected: []hlc.Timestamp{ts(20)}},
+		{name: "chain", from: ts(10), to: ts(35),
+			expected: []hlc.Timestamp{ts(20), {WallTime: 30, Logical: 2}, ts(40)}},
+		{name: "mid chain", from: ts(20), to: ts(31),
+			expected: []hlc.Timestamp{{WallTime: 30, Logical: 2}, ts(40)}},
+		{name: "stops at gap", from: ts(10), to: ts(55),
+			expected: []hlc.Timestamp{ts(20), {WallTime: 30, Logical: 2}, ts(40)}},
+		{name: "no segment at start", from: ts(15), to: ts(55)},
+		{name: "rolled up", from: ts(50), to: ts(55), expected: []hlc.Timestamp{ts(70)}},
+		{name: "within rolled up", from: ts(60), to: ts(65), expected: []hlc.Timestamp{ts(70)}},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			segments, err := backupdest.FindContinuousLogSegments(ctx, store, td.from, td.to)
+			require.NoError(t, err)
+			var ends []hlc.Timestamp
+			prev := td.from
+			for _, s := range segments {
+				require.Equal(t, prev, s.StartTime)
+				require.Equal(t, backupdest.ContinuousLogSegmentDir(s.StartTime, s.EndTime), s.Dir)
+				ends = append(ends, s.EndTime)
+				prev = s.EndTime
+			}
+			require.Equal(t, td.expected, ends)
+		})
+	}
+}
+
+func TestContinuousLogFrontier(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tc, _, _, cleanupFn := backuputils.BackupDestinationTestSetup(t, backuputils.SingleNode, 1,
+		backuputils.InitManualReplication)
+	defer cleanupFn()
+
+	ctx := context.Background()
+	execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx,
+		fmt.Sprintf("nodelocal://1/%s?AUTH=implicit", t.Name()), username.RootUserName())
+	require.NoError(t, err)
+	defer store.Close()
+
+	ts := func(wall int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wall} }
+
+	// Without a frontier, the log ends with its last segment.
+	frontier, err := backupdest.ReadContinuousLogFrontier(ctx, store, ts(10))
+	require.NoError(t, err)
+	require.Equal(t, ts(10), frontier)
+
+	require.NoError(t, backupdest.WriteContinuousLogFrontier(ctx, store, ts(10),
+		hlc.Timestamp{WallTime: 30, Logical: 1}))
+	frontier, err = backupdest.ReadContinuousLogFrontier(ctx, store, ts(10))
+	require.NoError(t, err)
+	require.Equal(t, hlc.Timestamp{WallTime: 30, Logical: 1}, frontier)
+
+	// The frontier only extends the segment it was recorded for.
+	frontier, err = backupdest.ReadContinuousLogFrontier(ctx, store, ts(20))
+	require.NoError(t, err)
+	require.Equal(t, ts(20), frontier)
+}
@@ -0,0 +1,816 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// continuousBackupFlushInterval is the interval at which a continuous backup
+// writes the MVCC history it received into a new segment of its log. It bounds
+// how far behind the present the latest restorable time of the backup is.
+var continuousBackupFlushInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"bulkio.backup.continuous.flush_interval",
+	"the time between writing segments of the MVCC history log of a continuous backup",
+	30*time.Second,
+	settings.PositiveDuration,
+)
+
+// continuousBackupRollupInterval is the interval at which a continuous backup
+// rolls up the segments it wrote into a single segment, so that the number of
+// layers RESTORE has to chain grows with the rollup interval rather than the
+// flush interval.
+var continuousBackupRollupInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"bulkio.backup.continuous.rollup_interval",
+	"the time covered by the segments of the MVCC history log of a continuous backup that are rolled up into a single segment",
+	time.Hour,
+	settings.PositiveDuration,
+)
+
+// continuousBackupBufferSize limits the memory used to buffer the MVCC history
+// received by a continuous backup until it is written into the log.
+var continuousBackupBufferSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"bulkio.backup.continuous.buffer_size",
+	"the maximum memory used to buffer the MVCC history of a continuous backup before writing it into its log",
+	64<<20,
+)
+
+// checkContinuousBackupOptions checks that a continuous backup was requested
+// with options it supports. The log is written next to the full backup it
+// extends, so the backup must create a new full backup in a collection.
+func checkContinuousBackupOptions(
+	backupStmt *annotatedBackupStatement,
+	encryption jobspb.BackupEncryptionOptions,
+	incrementalStorage []string,
+) error {
+	if !backupStmt.Nested {
+		return errors.New("continuous option is only supported with `BACKUP INTO` syntax")
+	}
+	if backupStmt.AppendToLatest || backupStmt.Subdir != nil || len(backupStmt.IncrementalFrom) > 0 ||
+		len(incrementalStorage) > 0 {
+		return errors.New("continuous option is only supported for full backups into a new subdirectory")
+	}
+	if encryption.Mode != jobspb.EncryptionMode_None {
+		return errors.New("continuous option is not supported for encrypted backups")
+	}
+	return nil
+}
+
+// continuousBackupLog tails the spans of a completed full backup with a
+// rangefeed and periodically writes the MVCC history it receives into
+// segments of a log stored in the directory of the full backup. Each segment
+// is laid out like a revision history incremental backup of the full backup
+// covering the time since the previous segment, so RESTORE ... AS OF SYSTEM
+// TIME can chain them after the full backup to restore any time up to the end
+// of the last segment.
+//
+// Flushes that received no history don't write a segment. Instead the log
+// records in a frontier file how far past its last segment it is known to be
+// empty. The segments written within each rollup interval are merged into a
+// single segment once the interval has passed, so the log stays short enough
+// to be restored.
+//
+// The log only covers the spans of the full backup. Tables created after it
+// was taken are not captured until a new continuous backup is started.
+type continuousBackupLog struct {
+	execCfg *sql.ExecutorConfig
+	job     *jobs.Job
+	details jobspb.BackupDetails
+	store   cloud.ExternalStorage
+
+	// spans are the spans of the full backup, tailed by the rangefeed.
+	spans []roachpb.Span
+	// prev is the manifest of the last layer of the chain, i.e. the full backup
+	// or the last segment written.
+	prev backuppb.BackupManifest
+	// emptyThrough is the time up to which the log is known to contain all
+	// history of the spans. There is no history in (prev.EndTime, emptyThrough].
+	emptyThrough hlc.Timestamp
+	// pending are the segments written since the last rollup, in order.
+	pending []backupdest.ContinuousLogSegment
+
+	// flushC is signaled when the buffer is filling up, to flush it before the
+	// flush interval elapses.
+	flushC chan struct{}
+	// bufferLimit is the size of the buffer of history.
+	bufferLimit int64
+
+	mu struct {
+		syncutil.Mutex
+		// points and rangeKeys buffer the history received by the rangefeed
+		// that is yet to be written to the log.
+		points    []storage.MVCCKeyValue
+		rangeKeys []storage.MVCCRangeKeyValue
+		// acc accounts for the memory used by points and rangeKeys.
+		acc mon.BoundAccount
+		// frontier is the timestamp up to which the rangefeed delivered all
+		// history of the spans.
+		frontier hlc.Timestamp
+		// err is set if history delivered by the rangefeed couldn't be
+		// buffered. The log can't be extended past it, so the job fails.
+		err error
+	}
+}
+
+// runContinuousBackupLog writes the log of a continuous backup, picking up
+// from the given frontier, until the job is paused or canceled.
+func (b *backupResumer) runContinuousBackupLog(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	details jobspb.BackupDetails,
+	fullManifest *backuppb.BackupManifest,
+	store cloud.ExternalStorage,
+	frontier hlc.Timestamp,
+) error {
+	if !kvserver.RangefeedEnabled.Get(&execCfg.Settings.SV) {
+		return errors.New("continuous backup requires the kv.rangefeed.enabled setting")
+	}
+
+	l := &continuousBackupLog{
+		execCfg:     execCfg,
+		job:         b.job,
+		details:     details,
+		store:       store,
+		spans:       fullManifest.Spans,
+		prev:        *fullManifest,
+		flushC:      make(chan struct{}, 1),
+		bufferLimit: continuousBackupBufferSize.Get(&execCfg.Settings.SV),
+	}
+	l.prev.Files = nil
+
+	bufferMon := mon.NewMonitorInheritWithLimit("continuous-backup", l.bufferLimit,
+		execCfg.RootMemoryMonitor)
+	bufferMon.StartNoReserved(ctx, execCfg.RootMemoryMonitor)
+	defer bufferMon.Stop(ctx)
+	l.mu.acc = bufferMon.MakeBoundAccount()
+	defer func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.mu.points, l.mu.rangeKeys = nil, nil
+		l.mu.acc.Close(ctx)
+	}()
+
+	// Segments may have been written after the frontier was last persisted if
+	// the job was interrupted in between, and the frontier may be past the last
+	// segment if the log was empty since. Pick up from the end of the chain of
+	// segments, instead of writing a diverging segment starting at the same
+	// time.
+	segments, err := backupdest.FindContinuousLogSegments(ctx, store, fullManifest.EndTime,
+		hlc.MaxTimestamp)
+	if err != nil {
+		return err
+	}
+	if len(segments) > 0 {
+		last := segments[len(segments)-1]
+		m, _, err := backupinfo.ReadBackupManifest(ctx, nil /* mem */, store,
+			path.Join(last.Dir, backupbase.BackupManifestName), nil /* encryption */, nil /* kmsEnv */)
+		if err != nil {
+			return err
+		}
+		l.prev = m
+		l.prev.Files = nil
+		l.pending = unrolledSegments(segments,
+			continuousBackupRollupInterval.Get(&execCfg.Settings.SV))
+	}
+	frontier.Forward(l.prev.EndTime)
+	l.emptyThrough = frontier
+	if err := l.persistFrontier(ctx, frontier); err != nil {
+		return err
+	}
+	return l.run(ctx, frontier)
+}
+
+// unrolledSegments returns the segments at the end of the chain that are yet
+// to be rolled up, i.e. those after the last segment spanning at least the
+// rollup interval.
+func unrolledSegments(
+	segments []backupdest.ContinuousLogSegment, interval time.Duration,
+) []backupdest.ContinuousLogSegment {
+	i := len(segments)
+	for i > 0 && segments[i-1].EndTime.WallTime-segments[i-1].StartTime.WallTime < interval.Nanoseconds() {
+		i--
+	}
+	return append([]backupdest.ContinuousLogSegment(nil), segments[i:]...)
+}
+
+func (l *continuousBackupLog) run(ctx context.Context, frontier hlc.Timestamp) error {
+	l.mu.frontier = frontier
+	rf, err := l.execCfg.RangeFeedFactory.RangeFeed(ctx,
+		"continuous-backup", l.spans, frontier, l.onValue,
+		rangefeed.WithOnFrontierAdvance(l.onFrontierAdvance),
+		rangefeed.WithOnDeleteRange(l.onDeleteRange),
+		rangefeed.WithOnSSTable(l.onSSTable),
+	)
+	if err != nil {
+		return errors.Wrap(err, "starting rangefeed for continuous backup")
+	}
+	defer rf.Close()
+
+	log.Infof(ctx, "writing continuous backup log of %d spans from %s", len(l.spans), frontier)
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		timer.Reset(continuousBackupFlushInterval.Get(&l.execCfg.Settings.SV))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.Read = true
+		case <-l.flushC:
+		}
+		if err := l.flush(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *continuousBackupLog) onValue(ctx context.Context, value *roachpb.RangeFeedValue) {
+	// Rangefeed values carry no MVCC value header, so their raw bytes are
+	// already a valid encoded MVCC value. Deletions are delivered with empty
+	// values which are written as MVCC point tombstones.
+	kv := storage.MVCCKeyValue{
+		Key:   storage.MVCCKey{Key: value.Key, Timestamp: value.Value.Timestamp},
+		Value: value.Value.RawBytes,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.growBufferLocked(ctx, mvccKeyValueSize(kv)) {
+		l.mu.points = append(l.mu.points, kv)
+	}
+}
+
+func (l *continuousBackupLog) onDeleteRange(
+	ctx context.Context, value *roachpb.RangeFeedDeleteRange,
+) {
+	rkv := storage.MVCCRangeKeyValue{
+		RangeKey: storage.MVCCRangeKey{
+			StartKey:  value.Span.Key,
+			EndKey:    value.Span.EndKey,
+			Timestamp: value.Timestamp,
+		},
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.growBufferLocked(ctx, mvccRangeKeyValueSize(rkv)) {
+		l.mu.rangeKeys = append(l.mu.rangeKeys, rkv)
+	}
+}
+
+func (l *continuousBackupLog) onSSTable(
+	ctx context.Context, sst *roachpb.RangeFeedSSTable, registeredSpan roachpb.Span,
+) {
+	points, rangeKeys, err := readIngestedSST(sst.Data, registeredSpan)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		if l.mu.err == nil {
+			l.mu.err = errors.Wrapf(err, "reading SST ingested into %s", registeredSpan)
+		}
+		return
+	}
+	var size int64
+	for _, kv := range points {
+		size += mvccKeyValueSize(kv)
+	}
+	for _, rkv := range rangeKeys {
+		size += mvccRangeKeyValueSize(rkv)
+	}
+	if l.growBufferLocked(ctx, size) {
+		l.mu.points = append(l.mu.points, points...)
+		l.mu.rangeKeys = append(l.mu.rangeKeys, rangeKeys...)
+	}
+}
+
+// growBufferLocked accounts for history about to be added to the buffer and
+// returns whether it may be. If the buffer is full, the history is dropped and
+// the job fails with a retryable error, since the log can't be extended past
+// it. Flushes are triggered early once the buffer is half full, so this only
+// happens if the rangefeed frontier lags behind while history keeps coming.
+func (l *continuousBackupLog) growBufferLocked(ctx context.Context, size int64) bool {
+	if l.mu.err != nil {
+		return false
+	}
+	if err := l.mu.acc.Grow(ctx, size); err != nil {
+		l.mu.err = jobs.MarkAsRetryJobError(
+			errors.Wrap(err, "buffering history of continuous backup"))
+		return false
+	}
+	if l.mu.acc.Used() > l.bufferLimit/2 {
+		select {
+		case l.flushC <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+func mvccKeyValueSize(kv storage.MVCCKeyValue) int64 {
+	return int64(kv.Key.EncodedSize() + len(kv.Value))
+}
+
+func mvccRangeKeyValueSize(rkv storage.MVCCRangeKeyValue) int64 {
+	return int64(rkv.RangeKey.EncodedSize() + len(rkv.Value))
+}
+
+// readIngestedSST returns the point and range keys of an SST emitted by the
+// rangefeed that fall into the span. The SST is emitted as it was ingested, so
+// it may contain keys outside of the span of the rangefeed.
+func readIngestedSST(
+	data []byte, span roachpb.Span,
+) ([]storage.MVCCKeyValue, []storage.MVCCRangeKeyValue, error) {
+	iter, err := storage.NewMemSSTIterator(data, false, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: span.Key,
+		UpperBound: span.EndKey,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iter.Close()
+
+	var points []storage.MVCCKeyValue
+	var rangeKeys []storage.MVCCRangeKeyValue
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return nil, nil, err
+		} else if !ok {
+			break
+		}
+		hasPoint, hasRange := iter.HasPointAndRange()
+		if hasRange && iter.RangeKeyChanged() {
+			rks := iter.RangeKeys().Clone()
+			rks.Bounds = rks.Bounds.Intersect(span)
+			rangeKeys = append(rangeKeys, rks.AsRangeKeyValues()...)
+		}
+		if hasPoint {
+			points = append(points, storage.MVCCKeyValue{
+				Key:   iter.UnsafeKey().Clone(),
+				Value: append([]byte(nil), iter.UnsafeValue()...),
+			})
+		}
+	}
+	return points, rangeKeys, nil
+}
+
+func (l *continuousBackupLog) onFrontierAdvance(_ context.Context, frontier hlc.Timestamp) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.frontier.Forward(frontier)
+}
+
+// takeResolved removes the history up to the rangefeed frontier from the
+// buffer and returns it along with the frontier and the memory it used, which
+// the caller releases once done with it. History at or below `after` has
+// already been written to the log and is dropped; the rangefeed redelivers it
+// when it restarts.
+func (l *continuousBackupLog) takeResolved(
+	after hlc.Timestamp,
+) (hlc.Timestamp, []storage.MVCCKeyValue, []storage.MVCCRangeKeyValue, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.err != nil {
+		return hlc.Timestamp{}, nil, nil, 0, l.mu.err
+	}
+	frontier := l.mu.frontier
+
+	var taken int64
+	var points, remainingPoints []storage.MVCCKeyValue
+	for _, kv := range l.mu.points {
+		if ts := kv.Key.Timestamp; frontier.Less(ts) {
+			remainingPoints = append(remainingPoints, kv)
+			continue
+		} else if after.Less(ts) {
+			points = append(points, kv)
+		}
+		taken += mvccKeyValueSize(kv)
+	}
+	var rangeKeys, remainingRangeKeys []storage.MVCCRangeKeyValue
+	for _, rkv := range l.mu.rangeKeys {
+		if ts := rkv.RangeKey.Timestamp; frontier.Less(ts) {
+			remainingRangeKeys = append(remainingRangeKeys, rkv)
+			continue
+		} else if after.Less(ts) {
+			rangeKeys = append(rangeKeys, rkv)
+		}
+		taken += mvccRangeKeyValueSize(rkv)
+	}
+	l.mu.points = remainingPoints
+	l.mu.rangeKeys = remainingRangeKeys
+	return frontier, points, rangeKeys, taken, nil
+}
+
+// releaseBuffer releases memory of history taken from the buffer.
+func (l *continuousBackupLog) releaseBuffer(ctx context.Context, size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.acc.Shrink(ctx, size)
+}
+
+// flush writes the history received up to the current rangefeed frontier into
+// a new log segment, and records the new frontier in the job progress. If no
+// history was received since the last segment, no segment is written and only
+// the frontier is recorded.
+func (l *continuousBackupLog) flush(ctx context.Context) error {
+	start := l.prev.EndTime
+	frontier, points, rangeKeys, size, err := l.takeResolved(start)
+	if err != nil {
+		return err
+	}
+	defer l.releaseBuffer(ctx, size)
+	if !l.emptyThrough.Less(frontier) {
+		return nil
+	}
+
+	revs, descs, err := l.segmentDescriptors(ctx, l.emptyThrough, frontier)
+	if err != nil {
+		return err
+	}
+	// The full backup contains no revision history, so the first segment is
+	// always written to let RESTORE pick times past the full backup.
+	if len(points) == 0 && len(rangeKeys) == 0 && len(revs) == 0 && !l.prev.StartTime.IsEmpty() {
+		if err := backupdest.WriteContinuousLogFrontier(ctx, l.store, start, frontier); err != nil {
+			return errors.Wrap(err, "writing continuous backup log frontier")
+		}
+		if err := l.persistFrontier(ctx, frontier); err != nil {
+			return err
+		}
+		l.emptyThrough = frontier
+		return nil
+	}
+
+	m, err := l.writeSegment(ctx, start, frontier, points, rangeKeys, revs, descs)
+	if err != nil {
+		return errors.Wrapf(err, "writing continuous backup log segment ending at %s", frontier)
+	}
+	if err := l.persistFrontier(ctx, frontier); err != nil {
+		return err
+	}
+	m.Files = nil
+	l.prev = m
+	l.emptyThrough = frontier
+	l.pending = append(l.pending, backupdest.ContinuousLogSegment{
+		Dir:       backupdest.ContinuousLogSegmentDir(start, frontier),
+		StartTime: start,
+		EndTime:   frontier,
+	})
+	return l.maybeRollup(ctx)
+}
+
+// writeSegment writes the history in (start, end] into a log segment. The
+// segment manifest is written last so that only complete segments are found
+// by RESTORE.
+func (l *continuousBackupLog) writeSegment(
+	ctx context.Context,
+	start, end hlc.Timestamp,
+	points []storage.MVCCKeyValue,
+	rangeKeys []storage.MVCCRangeKeyValue,
+	revs []backuppb.BackupManifest_DescriptorRevision,
+	descs []descpb.Descriptor,
+) (backuppb.BackupManifest, error) {
+	dir := backupdest.ContinuousLogSegmentDir(start, end)
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Key.Less(points[j].Key)
+	})
+	sort.Slice(rangeKeys, func(i, j int) bool {
+		return rangeKeys[i].RangeKey.Compare(rangeKeys[j].RangeKey) < 0
+	})
+
+	files, counts, err := l.writeSegmentData(ctx, dir, points, rangeKeys)
+	if err != nil {
+		return backuppb.BackupManifest{}, err
+	}
+
+	m := l.prev
+	m.StartTime = start
+	m.EndTime = end
+	m.RevisionStartTime = start
+	m.MVCCFilter = backuppb.MVCCFilter_All
+	m.Descriptors = descs
+	m.DescriptorChanges = revs
+	m.IntroducedSpans = nil
+	m.Files = files
+	m.EntryCounts = counts
+	m.StatisticsFilenames = nil
+	m.PartitionDescriptorFilenames = nil
+	m.ClusterVersion = l.execCfg.Settings.Version.ActiveVersion(ctx).Version
+	if err := backupinfo.WriteBackupManifest(ctx, l.store, path.Join(dir, backupbase.BackupManifestName),
+		nil /* encryption */, nil /* kmsEnv */, &m); err != nil {
+		return backuppb.BackupManifest{}, err
+	}
+	log.VEventf(ctx, 1, "wrote continuous backup log segment %s with %d keys", dir, len(points))
+	return m, nil
+}
+
+// writeSegmentData writes the history into an SST in the segment directory
+// and returns the backup files describing it, one per span of the backup.
+func (l *continuousBackupLog) writeSegmentData(
+	ctx context.Context,
+	dir string,
+	points []storage.MVCCKeyValue,
+	rangeKeys []storage.MVCCRangeKeyValue,
+) ([]backuppb.BackupManifest_File, roachpb.RowCount, error) {
+	var counts roachpb.RowCount
+	if len(points) == 0 && len(rangeKeys) == 0 {
+		return nil, counts, nil
+	}
+
+	name := generateUniqueSSTName(l.execCfg.NodeInfo.NodeID.SQLInstanceID())
+	w, err := l.store.Writer(ctx, path.Join(dir, name))
+	if err != nil {
+		return nil, counts, err
+	}
+	sst := storage.MakeBackupSSTWriter(ctx, l.store.Settings(), w)
+	defer sst.Close()
+
+	var prev storage.MVCCKey
+	for _, kv := range points {
+		// The rangefeed may deliver the same value more than once.
+		if kv.Key.Equal(prev) {
+			continue
+		}
+		prev = kv.Key
+		if err := sst.PutRawMVCC(kv.Key, kv.Value); err != nil {
+			_ = w.Close()
+			return nil, counts, err
+		}
+		counts.DataSize += int64(len(kv.Key.Key) + len(kv.Value))
+	}
+	for _, rkv := range rangeKeys {
+		if err := sst.PutRawMVCCRangeKey(rkv.RangeKey, rkv.Value); err != nil {
+			_ = w.Close()
+			return nil, counts, err
+		}
+	}
+	if err := sst.Finish(); err != nil {
+		_ = w.Close()
+		return nil, counts, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, counts, errors.Wrap(err, "writing SST")
+	}
+
+	files := make([]backuppb.BackupManifest_File, 0, len(l.spans))
+	for _, sp := range l.spans {
+		files = append(files, backuppb.BackupManifest_File{Span: sp, Path: name})
+	}
+	return files, counts, nil
+}
+
+// maybeRollup rolls up the segments written since the last rollup into a
+// single segment once they span the rollup interval. The rolled up segment is
+// written before the segments it replaces are deleted, and RESTORE prefers the
+// longest segment starting at a given time, so the log remains restorable if
+// the job is interrupted in between.
+func (l *continuousBackupLog) maybeRollup(ctx context.Context) error {
+	if len(l.pending) < 2 {
+		return nil
+	}
+	first, last := l.pending[0], l.pending[len(l.pending)-1]
+	interval := continuousBackupRollupInterval.Get(&l.execCfg.Settings.SV)
+	if last.EndTime.WallTime-first.StartTime.WallTime < interval.Nanoseconds() {
+		return nil
+	}
+
+	manifests := make([]backuppb.BackupManifest, len(l.pending))
+	for i, seg := range l.pending {
+		m, _, err := backupinfo.ReadBackupManifest(ctx, nil /* mem */, l.store,
+			path.Join(seg.Dir, backupbase.BackupManifestName), nil /* encryption */, nil /* kmsEnv */)
+		if err != nil {
+			return err
+		}
+		manifests[i] = m
+	}
+
+	dir := backupdest.ContinuousLogSegmentDir(first.StartTime, last.EndTime)
+	files, counts, err := l.rollupSegmentData(ctx, dir, l.pending, manifests)
+	if err != nil {
+		return errors.Wrapf(err, "rolling up continuous backup log segments into %s", dir)
+	}
+	m := manifests[len(manifests)-1]
+	m.StartTime = first.StartTime
+	m.RevisionStartTime = first.StartTime
+	m.DescriptorChanges = nil
+	for i := range manifests {
+		m.DescriptorChanges = append(m.DescriptorChanges, manifests[i].DescriptorChanges...)
+	}
+	m.Files = files
+	m.EntryCounts = counts
+	if err := backupinfo.WriteBackupManifest(ctx, l.store, path.Join(dir, backupbase.BackupManifestName),
+		nil /* encryption */, nil /* kmsEnv */, &m); err != nil {
+		return err
+	}
+
+	for i, seg := range l.pending {
+		if err := l.store.Delete(ctx, path.Join(seg.Dir, backupbase.BackupManifestName)); err != nil {
+			return err
+		}
+		deleted := make(map[string]struct{})
+		for _, f := range manifests[i].Files {
+			if _, ok := deleted[f.Path]; ok {
+				continue
+			}
+			deleted[f.Path] = struct{}{}
+			if err := l.store.Delete(ctx, path.Join(seg.Dir, f.Path)); err != nil {
+				return err
+			}
+		}
+	}
+	log.VEventf(ctx, 1, "rolled up %d continuous backup log segments into %s", len(l.pending), dir)
+	l.pending = nil
+	return nil
+}
+
+// rollupSegmentData merges the history of the segments into an SST in
+// the directory of the rolled up segment and returns the backup files
+// describing it, one per span of the backup. Segments cover disjoint time
+// intervals, so their keys never collide.
+func (l *continuousBackupLog) rollupSegmentData(
+	ctx context.Context,
+	dir string,
+	segments []backupdest.ContinuousLogSegment,
+	manifests []backuppb.BackupManifest,
+) ([]backuppb.BackupManifest_File, roachpb.RowCount, error) {
+	var counts roachpb.RowCount
+	var storeFiles []storageccl.StoreFile
+	for i := range manifests {
+		seen := make(map[string]struct{})
+		for _, f := range manifests[i].Files {
+			if _, ok := seen[f.Path]; ok {
+				continue
+			}
+			seen[f.Path] = struct{}{}
+			storeFiles = append(storeFiles, storageccl.StoreFile{
+				Store:    l.store,
+				FilePath: path.Join(segments[i].Dir, f.Path),
+			})
+		}
+	}
+	if len(storeFiles) == 0 {
+		return nil, counts, nil
+	}
+
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, nil /* encryption */, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: roachpb.KeyMin,
+		UpperBound: roachpb.KeyMax,
+	})
+	if err != nil {
+		return nil, counts, err
+	}
+	defer iter.Close()
+
+	name := generateUniqueSSTName(l.execCfg.NodeInfo.NodeID.SQLInstanceID())
+	w, err := l.store.Writer(ctx, path.Join(dir, name))
+	if err != nil {
+		return nil, counts, err
+	}
+	sst := storage.MakeBackupSSTWriter(ctx, l.store.Settings(), w)
+	defer sst.Close()
+
+	writeErr := func() error {
+		for iter.SeekGE(storage.MVCCKey{Key: roachpb.KeyMin}); ; iter.Next() {
+			if ok, err := iter.Valid(); err != nil {
+				return err
+			} else if !ok {
+				return nil
+			}
+			hasPoint, hasRange := iter.HasPointAndRange()
+			if hasRange && iter.RangeKeyChanged() {
+				rks := iter.RangeKeys()
+				for _, v := range rks.Versions {
+					if err := sst.PutRawMVCCRangeKey(rks.AsRangeKey(v), v.Value); err != nil {
+						return err
+					}
+				}
+			}
+			if hasPoint {
+				key, value := iter.UnsafeKey(), iter.UnsafeValue()
+				if err := sst.PutRawMVCC(key, value); err != nil {
+					return err
+				}
+				counts.DataSize += int64(len(key.Key) + len(value))
+			}
+		}
+	}()
+	if writeErr == nil {
+		writeErr = sst.Finish()
+	}
+	if writeErr != nil {
+		_ = w.Close()
+		return nil, counts, writeErr
+	}
+	if err := w.Close(); err != nil {
+		return nil, counts, errors.Wrap(err, "writing SST")
+	}
+
+	files := make([]backuppb.BackupManifest_File, 0, len(l.spans))
+	for _, sp := range l.spans {
+		files = append(files, backuppb.BackupManifest_File{Span: sp, Path: name})
+	}
+	return files, counts, nil
+}
+
+// segmentDescriptors returns the changes to the backed up descriptors between
+// start and end along with the descriptors of the previous layer updated to
+// their state as of end.
+func (l *continuousBackupLog) segmentDescriptors(
+	ctx context.Context, start, end hlc.Timestamp,
+) ([]backuppb.BackupManifest_DescriptorRevision, []descpb.Descriptor, error) {
+	targetDescs := make([]catalog.Descriptor, 0, len(l.prev.Descriptors))
+	for i := range l.prev.Descriptors {
+		targetDescs = append(targetDescs, backupinfo.NewDescriptorForManifest(&l.prev.Descriptors[i]))
+	}
+	revs, err := getRelevantDescChanges(ctx, l.execCfg, start, end, targetDescs,
+		l.details.ResolvedCompleteDbs, make(map[descpb.ID]descpb.ID),
+		l.prev.DescriptorCoverage == tree.AllDescriptors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	latest := make(map[descpb.ID]*descpb.Descriptor, len(revs))
+	for _, rev := range revs {
+		latest[rev.ID] = rev.Desc
+	}
+	descs := make([]descpb.Descriptor, 0, len(targetDescs))
+	for i, desc := range targetDescs {
+		d, ok := latest[desc.GetID()]
+		if !ok {
+			descs = append(descs, l.prev.Descriptors[i])
+		} else if d != nil {
+			descs = append(descs, *d)
+		}
+	}
+	return revs, descs, nil
+}
+
+// persistFrontier records the frontier of the log in the job progress and
+// moves the protected timestamp of the backup up to it, since history below
+// the frontier is no longer needed to extend the log.
+func (l *continuousBackupLog) persistFrontier(ctx context.Context, frontier hlc.Timestamp) error {
+	return l.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		if err := l.job.Update(ctx, txn, func(
+			txn *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater,
+		) error {
+			if err := md.CheckRunningOrReverting(); err != nil {
+				return err
+			}
+			md.Progress.GetBackup().ContinuousLogFrontier = frontier
+			ju.UpdateProgress(md.Progress)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if l.details.ProtectedTimestampRecord == nil {
+			return nil
+		}
+		return l.execCfg.ProtectedTimestampProvider.UpdateTimestamp(ctx, txn,
+			*l.details.ProtectedTimestampRecord, frontier)
+	})
+}
+
+// continuousLogFrontier returns the frontier of the log of a continuous
+// backup job, or an empty timestamp if the job hasn't started writing the log.
+func continuousLogFrontier(job *jobs.Job) hlc.Timestamp {
+	if p, ok := job.Progress().Details.(*jobspb.Progress_Backup); ok && p.Backup != nil {
+		return p.Backup.ContinuousLogFrontier
+	}
+	return hlc.Timestamp{}
+}
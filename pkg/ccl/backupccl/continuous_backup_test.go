@@ -0,0 +1,283 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinuousBackupPointInTimeRestore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	const numAccounts = 10
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.continuous.flush_interval = '100ms'`)
+
+	const collection = "nodelocal://0/continuous"
+	sqlDB.ExpectErr(t, "continuous option is only supported for full backups into a new subdirectory",
+		`BACKUP DATABASE data INTO LATEST IN $1 WITH continuous`, collection)
+	sqlDB.ExpectErr(t, "continuous option is not supported for encrypted backups",
+		`BACKUP DATABASE data INTO $1 WITH continuous, encryption_passphrase = 'abcdefg'`, collection)
+
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `BACKUP DATABASE data INTO $1 WITH continuous`, collection).Scan(&jobID)
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 100 WHERE id = 1`)
+	var ts1 string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts1)
+
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 2`)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 200 WHERE id = 1`)
+	var ts2 string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts2)
+
+	// The log covers the second timestamp once it has been flushed, at which
+	// point the full backup and the log can be restored to either timestamp.
+	testutils.SucceedsSoon(t, func() error {
+		_, err := sqlDB.DB.ExecContext(ctx, fmt.Sprintf(
+			`RESTORE DATABASE data FROM LATEST IN '%s' AS OF SYSTEM TIME %s WITH new_db_name = 'data2'`,
+			collection, ts2))
+		return err
+	})
+	sqlDB.Exec(t, fmt.Sprintf(
+		`RESTORE DATABASE data FROM LATEST IN '%s' AS OF SYSTEM TIME %s WITH new_db_name = 'data1'`,
+		collection, ts1))
+
+	const checkQuery = `SELECT count(*), sum(balance) FILTER (WHERE id = 1) FROM %s.bank`
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "data1"),
+		[][]string{{fmt.Sprint(numAccounts), "100"}})
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "data2"),
+		[][]string{{fmt.Sprint(numAccounts - 1), "200"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data2.bank WHERE id = 2`, [][]string{{"0"}})
+
+	// Stopping the log leaves the backup restorable.
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+	jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+	sqlDB.Exec(t, fmt.Sprintf(
+		`RESTORE DATABASE data FROM LATEST IN '%s' AS OF SYSTEM TIME %s WITH new_db_name = 'data3'`,
+		collection, ts2))
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "data3"),
+		[][]string{{fmt.Sprint(numAccounts - 1), "200"}})
+}
+
+// TestContinuousBackupLogChain tests restoring from a chain of several log
+// segments, and that a missing or corrupt segment prevents restoring to the
+// times it covers without affecting the times covered before it.
+func TestContinuousBackupLogChain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	const numAccounts = 10
+	tc, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.continuous.flush_interval = '100ms'`)
+
+	const collection = "nodelocal://0/continuous-chain"
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `BACKUP DATABASE data INTO $1 WITH continuous`, collection).Scan(&jobID)
+	var fullSubdir string
+	sqlDB.QueryRow(t, `SELECT path FROM [SHOW BACKUPS IN $1]`, collection).Scan(&fullSubdir)
+
+	restoreQuery := func(ts string, db string) string {
+		return fmt.Sprintf(
+			`RESTORE DATABASE data FROM LATEST IN '%s' AS OF SYSTEM TIME %s WITH new_db_name = '%s'`,
+			collection, ts, db)
+	}
+	const checkQuery = `SELECT count(*), sum(balance) FILTER (WHERE id = 1) FROM %s.bank`
+
+	// Each timestamp is only read once the log covers the previous one, so each
+	// of them falls into a later segment of the log than the previous one.
+	var ts [3]string
+	for i := range ts {
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = $1 WHERE id = 1`, (i+1)*100)
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts[i])
+		db := fmt.Sprintf("data%d", i)
+		testutils.SucceedsSoon(t, func() error {
+			_, err := sqlDB.DB.ExecContext(ctx, restoreQuery(ts[i], db))
+			return err
+		})
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, db),
+			[][]string{{fmt.Sprint(numAccounts), fmt.Sprint((i + 1) * 100)}})
+	}
+
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+	jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+
+	execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx,
+		"nodelocal://1/continuous-chain"+fullSubdir, username.RootUserName())
+	require.NoError(t, err)
+	defer store.Close()
+
+	full, _, err := backupinfo.ReadBackupManifest(ctx, nil /* mem */, store,
+		backupbase.BackupManifestName, nil /* encryption */, nil /* kmsEnv */)
+	require.NoError(t, err)
+	var times [len(ts)]hlc.Timestamp
+	for i := range ts {
+		times[i], err = hlc.ParseHLC(ts[i])
+		require.NoError(t, err)
+	}
+	segments, err := backupdest.FindContinuousLogSegments(ctx, store, full.EndTime, times[2])
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(segments), len(ts))
+
+	// Tamper with the segment covering the second timestamp. The segments
+	// before it still cover the first timestamp.
+	var manifest string
+	for _, s := range segments {
+		if s.StartTime.Less(times[1]) && times[1].LessEq(s.EndTime) {
+			manifest = filepath.Join(rawDir, "continuous-chain", fullSubdir, s.Dir,
+				backupbase.BackupManifestName)
+		}
+	}
+	require.NotEmpty(t, manifest)
+	data := corruptBackupManifest(t, manifest)
+
+	t.Run("corrupt segment", func(t *testing.T) {
+		sqlDB.ExpectErr(t, "checksum mismatch", restoreQuery(ts[1], "corrupt1"))
+		sqlDB.Exec(t, restoreQuery(ts[0], "corrupt0"))
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "corrupt0"),
+			[][]string{{fmt.Sprint(numAccounts), "100"}})
+	})
+
+	t.Run("missing segment", func(t *testing.T) {
+		require.NoError(t, os.Remove(manifest))
+		// The chain stops at the gap left by the segment, so the times after it
+		// can't be restored either.
+		sqlDB.ExpectErr(t, "supplied backups do not cover requested time", restoreQuery(ts[1], "missing1"))
+		sqlDB.ExpectErr(t, "supplied backups do not cover requested time", restoreQuery(ts[2], "missing2"))
+		sqlDB.Exec(t, restoreQuery(ts[0], "missing0"))
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "missing0"),
+			[][]string{{fmt.Sprint(numAccounts), "100"}})
+	})
+
+	// Restoring the segment makes the whole chain restorable again.
+	require.NoError(t, os.WriteFile(manifest, data, 0644 /* perm */))
+	sqlDB.Exec(t, restoreQuery(ts[2], "restored2"))
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "restored2"),
+		[][]string{{fmt.Sprint(numAccounts), "300"}})
+}
+
+// TestContinuousBackupRollupAndEmptySegments tests that the log rolls up its
+// segments once they span the rollup interval, and that it doesn't write
+// segments while the backed up spans see no writes but can still be restored
+// to times in that period.
+func TestContinuousBackupRollupAndEmptySegments(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	const numAccounts = 10
+	tc, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.continuous.flush_interval = '100ms'`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.continuous.rollup_interval = '1s'`)
+
+	const collection = "nodelocal://0/continuous-rollup"
+	var jobID jobspb.JobID
+	sqlDB.QueryRow(t, `BACKUP DATABASE data INTO $1 WITH continuous`, collection).Scan(&jobID)
+	var fullSubdir string
+	sqlDB.QueryRow(t, `SELECT path FROM [SHOW BACKUPS IN $1]`, collection).Scan(&fullSubdir)
+
+	execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx,
+		"nodelocal://1/continuous-rollup"+fullSubdir, username.RootUserName())
+	require.NoError(t, err)
+	defer store.Close()
+	full, _, err := backupinfo.ReadBackupManifest(ctx, nil /* mem */, store,
+		backupbase.BackupManifestName, nil /* encryption */, nil /* kmsEnv */)
+	require.NoError(t, err)
+
+	restoreQuery := func(ts string, db string) string {
+		return fmt.Sprintf(
+			`RESTORE DATABASE data FROM LATEST IN '%s' AS OF SYSTEM TIME %s WITH new_db_name = '%s'`,
+			collection, ts, db)
+	}
+	const checkQuery = `SELECT count(*), sum(balance) FILTER (WHERE id = 1) FROM %s.bank`
+
+	// Keep writing until the segments holding the writes are rolled up.
+	var ts string
+	testutils.SucceedsSoon(t, func() error {
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id = 1`)
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+		segments, err := backupdest.FindContinuousLogSegments(ctx, store, full.EndTime, hlc.MaxTimestamp)
+		if err != nil {
+			return err
+		}
+		if len(segments) == 0 {
+			return errors.New("no log segments yet")
+		}
+		first := segments[0]
+		if first.EndTime.WallTime-first.StartTime.WallTime < time.Second.Nanoseconds() {
+			return errors.Newf("segment %s not rolled up yet", first.Dir)
+		}
+		return nil
+	})
+	var balance int
+	sqlDB.QueryRow(t, `SELECT balance FROM data.bank WHERE id = 1`).Scan(&balance)
+	// Wait for the last write to be flushed before the log goes quiet.
+	testutils.SucceedsSoon(t, func() error {
+		_, err := sqlDB.DB.ExecContext(ctx, restoreQuery(ts, "written"))
+		return err
+	})
+	segments, err := backupdest.ListContinuousLogSegments(ctx, store)
+	require.NoError(t, err)
+	lastEnd := segments[len(segments)-1].EndTime
+
+	// Without writes, the log can be restored to later times without writing
+	// new segments.
+	var quietTS string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&quietTS)
+	testutils.SucceedsSoon(t, func() error {
+		_, err := sqlDB.DB.ExecContext(ctx, restoreQuery(quietTS, "quiet"))
+		return err
+	})
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(checkQuery, "quiet"),
+		[][]string{{fmt.Sprint(numAccounts), fmt.Sprint(balance)}})
+	segments, err = backupdest.ListContinuousLogSegments(ctx, store)
+	require.NoError(t, err)
+	for _, s := range segments {
+		require.True(t, s.EndTime.LessEq(lastEnd), "unexpected segment %s", s.Dir)
+	}
+
+	sqlDB.Exec(t, `CANCEL JOB $1`, jobID)
+	jobutils.WaitForJobToCancel(t, sqlDB, jobID)
+}
@@ -838,6 +838,9 @@ func makeScheduledBackupEval(
 	if err != nil {
 		return nil, err
 	}
+	if schedule.BackupOptions.Continuous == tree.DBoolTrue {
+		return nil, errors.New("continuous option is not supported for scheduled backups")
+	}
 	if schedule.BackupOptions.EncryptionPassphrase != nil {
 		eval.encryptionPassphrase, err =
 			p.TypeAsString(ctx, schedule.BackupOptions.EncryptionPassphrase, scheduleBackupOp)
@@ -77,7 +77,8 @@ var localityCfgs = map[string]roachpb.Locality{
 }
 
 var clusterVersionKeys = map[string]clusterversion.Key{
-	"Start22_2": clusterversion.Start22_2,
+	"Start22_2":    clusterversion.Start22_2,
+	"AttachBackup": clusterversion.AttachBackup,
 }
 
 type sqlDBKey struct {
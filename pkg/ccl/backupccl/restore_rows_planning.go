@@ -0,0 +1,740 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// restoreRowsBatchSize is the number of rows written into the live table by a
+// single batch.
+const restoreRowsBatchSize = 100
+
+var restoreRowsHeader = colinfo.ResultColumns{
+	{Name: "rows", Typ: types.Int},
+}
+
+// restoreRowsPlanHook implements sql.PlanHookFn.
+func restoreRowsPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	restoreStmt, ok := stmt.(*tree.RestoreRows)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureRestoreEnabled,
+		"RESTORE ROWS",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, restoreStmt.Subdir, "RESTORE ROWS")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	fromFn, err := p.TypeAsStringArray(ctx, tree.Exprs(restoreStmt.From), "RESTORE ROWS")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupencryption.BackupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupencryption.BackupOptEncKMS:        sql.KVStringOptRequireValue,
+		backupOptIncStorage:                     sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, restoreStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		from, err := fromFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		restored, err := doRestoreRows(ctx, restoreStmt, p, from, subdir, opts)
+		if err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(restored))}
+		return nil
+	}
+	return fn, restoreRowsHeader, nil, false, nil
+}
+
+// doRestoreRows reads the rows of the table in the backup which has the same
+// name as the target table, and writes those matching the WHERE clause of the
+// statement into the target table in the transaction of the statement. It
+// returns the number of rows which were written.
+func doRestoreRows(
+	ctx context.Context,
+	restoreStmt *tree.RestoreRows,
+	p sql.PlanHookState,
+	from []string,
+	subdir string,
+	opts map[string]string,
+) (int64, error) {
+	if len(from) < 1 || len(from[0]) < 1 {
+		return 0, errors.New("invalid base backup specified")
+	}
+	if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, from); err != nil {
+		return 0, err
+	}
+
+	tn := restoreStmt.Table.ToTableName()
+	_, liveTable, err := resolver.ResolveExistingTableObject(ctx, p, &tn,
+		tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc))
+	if err != nil {
+		return 0, err
+	}
+	for _, priv := range []privilege.Kind{privilege.INSERT, privilege.UPDATE} {
+		if err := p.CheckPrivilege(ctx, liveTable, priv); err != nil {
+			return 0, err
+		}
+	}
+
+	var endTime hlc.Timestamp
+	if restoreStmt.AsOf.Expr != nil {
+		asOf, err := p.EvalAsOfTimestamp(ctx, restoreStmt.AsOf)
+		if err != nil {
+			return 0, err
+		}
+		endTime = asOf.Timestamp
+	}
+
+	if strings.EqualFold(subdir, backupbase.LatestFileName) {
+		latest, err := backupdest.ReadLatestFile(ctx, from[0],
+			p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+		if err != nil {
+			return 0, errors.Wrap(err, "read LATEST path")
+		}
+		subdir = latest
+	}
+
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	chain, err := resolveBackupChain(ctx, p, from, subdir, opts, endTime, &mem)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		mem.Shrink(ctx, chain.memReserved)
+	}()
+	manifests := chain.manifests
+	readTime := endTime
+	if readTime.IsEmpty() {
+		readTime = manifests[len(manifests)-1].EndTime
+	}
+
+	// The table is looked up in the backup using the name it was resolved to.
+	backupTable, err := resolveBackupTable(ctx, p, manifests, tn.ToUnresolvedObjectName(), endTime)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := makeRowsRestorer(ctx, p, backupTable, liveTable)
+	if err != nil {
+		return 0, err
+	}
+	defer r.close(ctx)
+
+	// Only the spans of the primary index which may contain rows matching the
+	// WHERE clause are read from the backup, and the part of the clause which
+	// the spans do not account for is evaluated on the rows which are read.
+	var filter tree.Expr
+	if restoreStmt.Where != nil {
+		filter = restoreStmt.Where.Expr
+	}
+	spans, remaining, err := constrainBackupTableSpans(ctx, p, sql.BestEffortConstrain, backupTable, filter)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.setFilter(ctx, remaining); err != nil {
+		return 0, err
+	}
+
+	// The rows are read from the backup using the key rewriter, which rewrites
+	// the keys of the table in the backup into the keyspace of the live table.
+	if err := readBackupTableRows(
+		ctx, p, chain, endTime, readTime, backupTable, r.backupTable, spans, r.fetchColIDs, r.add,
+	); err != nil {
+		return 0, err
+	}
+	if err := r.flush(ctx); err != nil {
+		return 0, err
+	}
+	return r.restored, nil
+}
+
+// rowsRestorer writes the rows of a table read from a backup into the live
+// table in batches, using the row writers directly. Rows which do not exist in
+// the live table are inserted, and those which do are updated. Like UPSERT,
+// the columns of the live table which are not in the backup get their default
+// value in inserted rows and keep their value in updated rows, and the
+// computed columns are recomputed.
+type rowsRestorer struct {
+	p       sql.PlanHookState
+	evalCtx *eval.Context
+
+	// backupTable is the descriptor of the table in the backup, with its ID
+	// rewritten to the ID of the live table.
+	backupTable catalog.TableDescriptor
+	liveTable   catalog.TableDescriptor
+
+	// fetchCols are the columns of the table in the backup which are read.
+	fetchCols   []catalog.Column
+	fetchColIDs []descpb.ColumnID
+	// filter is the part of the WHERE clause which the spans read from the
+	// backup do not account for, or nil. It is evaluated on the rows read from
+	// the backup.
+	filter      tree.TypedExpr
+	filterIVars schemaexpr.RowIndexedVarContainer
+
+	// cols are the public columns of the live table, in the order of the values
+	// of the rows which are written. The columns which are restored come first,
+	// and restoredOrds are the ordinals of their values in the rows read from
+	// the backup.
+	cols         []catalog.Column
+	restoredOrds []int
+	// updateCols are the columns of cols which are updated in existing rows,
+	// which are all but the primary key columns.
+	updateCols []catalog.Column
+	// ivars binds the columns of the live table to the values of a row, for
+	// the evaluation of the expressions below.
+	ivars             schemaexpr.RowIndexedVarContainer
+	defaultExprs      []tree.TypedExpr
+	computedExprs     []tree.TypedExpr
+	checkExprs        []tree.TypedExpr
+	partialIndexExprs map[descpb.IndexID]tree.TypedExpr
+
+	// fetcher reads the existing rows of the live table which are restored.
+	// Only the columns which are not virtual are fetched, and liveFetchOrds are
+	// their ordinals in cols.
+	fetcher       row.Fetcher
+	liveFetchOrds []int
+	spanBuilder   span.Builder
+	ri            row.Inserter
+	ru            row.Updater
+	alloc         tree.DatumAlloc
+
+	// rows are the buffered values of the restored columns of the rows which
+	// are not written yet.
+	rows     []tree.Datums
+	restored int64
+}
+
+// makeRowsRestorer validates that the rows of the table in the backup can be
+// written into the live table, and prepares the row writers.
+func makeRowsRestorer(
+	ctx context.Context, p sql.PlanHookState, backupTable, liveTable catalog.TableDescriptor,
+) (*rowsRestorer, error) {
+	if err := checkRestoreRowsTarget(liveTable); err != nil {
+		return nil, err
+	}
+	mut := tabledesc.NewBuilder(backupTable.TableDesc()).BuildExistingMutableTable()
+	mut.ID = liveTable.GetID()
+	r := &rowsRestorer{
+		p:           p,
+		evalCtx:     &p.ExtendedEvalContext().Context,
+		backupTable: mut.ImmutableCopy().(catalog.TableDescriptor),
+		liveTable:   liveTable,
+	}
+
+	// All stored columns of the table in the backup are decoded, while only
+	// those which are not computed in the live table are written.
+	backupCols, err := storedBackupTableColumns(r.backupTable, "RESTORE ROWS")
+	if err != nil {
+		return nil, err
+	}
+	var restoredColIDs catalog.TableColSet
+	for _, col := range backupCols {
+		liveCol, err := liveTable.FindColumnWithName(col.ColName())
+		if err != nil || !liveCol.Public() {
+			return nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q of the table in the backup does not exist in %s",
+				col.GetName(), liveTable.GetName())
+		}
+		r.fetchCols = append(r.fetchCols, col)
+		r.fetchColIDs = append(r.fetchColIDs, col.GetID())
+		if !liveCol.IsComputed() {
+			r.cols = append(r.cols, liveCol)
+			r.restoredOrds = append(r.restoredOrds, len(r.fetchCols)-1)
+			restoredColIDs.Add(liveCol.GetID())
+		}
+	}
+	for _, col := range liveTable.PublicColumns() {
+		if !restoredColIDs.Contains(col.GetID()) {
+			r.cols = append(r.cols, col)
+		}
+	}
+	if !sameKeyColumnNames(r.backupTable.GetPrimaryIndex(), liveTable.GetPrimaryIndex()) {
+		return nil, pgerror.Newf(pgcode.InvalidTableDefinition,
+			"primary key of %s does not match the primary key of the table in the backup",
+			liveTable.GetName())
+	}
+	// The existing row which a restored row updates is looked up by primary
+	// key before the columns which are not restored are known.
+	primaryColIDs := liveTable.GetPrimaryIndex().CollectKeyColumnIDs()
+	for _, col := range r.cols {
+		if !primaryColIDs.Contains(col.GetID()) {
+			r.updateCols = append(r.updateCols, col)
+			continue
+		}
+		if !col.IsComputed() {
+			continue
+		}
+		expr, err := parser.ParseExpr(col.GetComputeExpr())
+		if err != nil {
+			return nil, err
+		}
+		refColIDs, err := schemaexpr.ExtractColumnIDs(liveTable, expr)
+		if err != nil {
+			return nil, err
+		}
+		if !refColIDs.SubsetOf(restoredColIDs) {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"primary key column %q of %s is computed from columns which are not in the backup",
+				col.GetName(), liveTable.GetName())
+		}
+	}
+
+	if err := r.initExprs(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.initWriters(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// checkRestoreRowsTarget returns an error if rows cannot be restored into the
+// table, because it has constraints or triggers which are only enforced by the
+// plans of mutation statements, and not by the row writers.
+func checkRestoreRowsTarget(table catalog.TableDescriptor) error {
+	unsupported := func(what string) error {
+		return unimplemented.Newf("restore rows "+what,
+			"RESTORE ROWS does not support tables with %s", what)
+	}
+	if len(table.AllMutations()) > 0 {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"cannot restore rows into %s while a schema change is in progress", table.GetName())
+	}
+	if len(table.TableDesc().OutboundFKs) > 0 || len(table.TableDesc().InboundFKs) > 0 {
+		return unsupported("foreign keys")
+	}
+	if len(table.AllActiveAndInactiveUniqueWithoutIndexConstraints()) > 0 {
+		return unsupported("unique constraints without an index")
+	}
+	for _, idx := range table.ActiveIndexes() {
+		if idx.IsExclusion() {
+			return unsupported("exclusion constraints")
+		}
+	}
+	if len(table.GetTriggers()) > 0 {
+		return unsupported("triggers")
+	}
+	return nil
+}
+
+// initExprs type checks the default and computed column expressions, the
+// CHECK constraints and the partial index predicates of the live table.
+func (r *rowsRestorer) initExprs(ctx context.Context) error {
+	table := r.liveTable
+	tn := tree.NewUnqualifiedTableName(tree.Name(table.GetName()))
+	semaCtx := *r.p.SemaCtx()
+	r.ivars = schemaexpr.RowIndexedVarContainer{
+		Cols:    table.PublicColumns(),
+		Mapping: row.ColIDtoRowIndexFromCols(r.cols),
+	}
+
+	var txCtx transform.ExprTransformContext
+	var err error
+	if r.defaultExprs, err = schemaexpr.MakeDefaultExprs(
+		ctx, r.cols, &txCtx, r.evalCtx, &semaCtx,
+	); err != nil {
+		return err
+	}
+	if r.computedExprs, _, err = schemaexpr.MakeComputedExprs(
+		ctx, r.cols, table.PublicColumns(), table, tn, r.evalCtx, &semaCtx,
+	); err != nil {
+		return err
+	}
+	for _, check := range table.ActiveChecks() {
+		expr, err := parser.ParseExpr(check.Expr)
+		if err != nil {
+			return err
+		}
+		typedExpr, err := schemaexpr.MakeRowExpr(
+			ctx, expr, types.Bool, table.PublicColumns(), table, tn, r.evalCtx, &semaCtx,
+		)
+		if err != nil {
+			return err
+		}
+		r.checkExprs = append(r.checkExprs, typedExpr)
+	}
+	r.partialIndexExprs, _, err = schemaexpr.MakePartialIndexExprs(
+		ctx, table.PartialIndexes(), table.PublicColumns(), table, r.evalCtx, &semaCtx,
+	)
+	return err
+}
+
+// initWriters prepares the row writers, and the fetcher which reads the
+// existing rows of the live table.
+func (r *rowsRestorer) initWriters(ctx context.Context) error {
+	execCfg := r.p.ExecCfg()
+	internal := r.p.SessionData().Internal
+	var err error
+	if r.ri, err = row.MakeInserter(
+		ctx, r.p.Txn(), execCfg.Codec, r.liveTable, r.cols, &r.alloc,
+		&execCfg.Settings.SV, internal, execCfg.GetRowMetrics(internal),
+	); err != nil {
+		return err
+	}
+	if r.ru, err = row.MakeUpdater(
+		ctx, r.p.Txn(), execCfg.Codec, r.liveTable, r.updateCols, r.cols, row.UpdaterDefault,
+		&r.alloc, &execCfg.Settings.SV, internal, execCfg.GetRowMetrics(internal),
+	); err != nil {
+		return err
+	}
+
+	var fetchColIDs []descpb.ColumnID
+	for i, col := range r.cols {
+		if !col.IsVirtual() {
+			fetchColIDs = append(fetchColIDs, col.GetID())
+			r.liveFetchOrds = append(r.liveFetchOrds, i)
+		}
+	}
+	var spec descpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, execCfg.Codec, r.liveTable, r.liveTable.GetPrimaryIndex(), fetchColIDs,
+	); err != nil {
+		return err
+	}
+	if err := r.fetcher.Init(ctx, row.FetcherInitArgs{
+		Txn:   r.p.Txn(),
+		Alloc: &r.alloc,
+		Spec:  &spec,
+		// Restored rows may have the same primary key in the live table, if
+		// their values are cast to the types of its columns.
+		SpansCanOverlap: true,
+	}); err != nil {
+		return err
+	}
+	r.spanBuilder.Init(r.evalCtx, execCfg.Codec, r.liveTable, r.liveTable.GetPrimaryIndex())
+	return nil
+}
+
+// setFilter sets the filter which the rows read from the backup must satisfy
+// to be restored. The filter refers to the columns of the table in the backup.
+func (r *rowsRestorer) setFilter(ctx context.Context, filter tree.Expr) error {
+	if filter == tree.DBoolTrue {
+		return nil
+	}
+	tn := tree.MakeUnqualifiedTableName(tree.Name(r.backupTable.GetName()))
+	semaCtx := *r.p.SemaCtx()
+	typedExpr, err := schemaexpr.MakeRowExpr(
+		ctx, filter, types.Bool, r.fetchCols, r.backupTable, &tn, r.evalCtx, &semaCtx,
+	)
+	if err != nil {
+		return err
+	}
+	r.filter = typedExpr
+	r.filterIVars = schemaexpr.RowIndexedVarContainer{
+		Cols:    r.fetchCols,
+		Mapping: row.ColIDtoRowIndexFromCols(r.fetchCols),
+	}
+	return nil
+}
+
+// sameKeyColumnNames returns whether the key columns of the two indexes have
+// the same names.
+func sameKeyColumnNames(a, b catalog.Index) bool {
+	if a.NumKeyColumns() != b.NumKeyColumns() {
+		return false
+	}
+	for i := 0; i < a.NumKeyColumns(); i++ {
+		if a.GetKeyColumnName(i) != b.GetKeyColumnName(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// add buffers the values of the restored columns of the row read from the
+// backup if it satisfies the filter, and writes the buffered rows once there
+// are enough of them.
+func (r *rowsRestorer) add(ctx context.Context, datums tree.Datums) error {
+	if r.filter != nil {
+		r.filterIVars.CurSourceRow = datums
+		r.evalCtx.PushIVarContainer(&r.filterIVars)
+		res, err := eval.Expr(ctx, r.evalCtx, r.filter)
+		r.evalCtx.PopIVarContainer()
+		if err != nil {
+			return err
+		}
+		if res != tree.DBoolTrue {
+			return nil
+		}
+	}
+	vals := make(tree.Datums, len(r.restoredOrds))
+	for i, ord := range r.restoredOrds {
+		d, err := eval.PerformAssignmentCast(ctx, r.evalCtx, datums[ord], r.cols[i].GetType())
+		if err != nil {
+			return errors.Wrapf(err, "restoring column %q", r.cols[i].GetName())
+		}
+		vals[i] = d
+	}
+	r.rows = append(r.rows, vals)
+	if len(r.rows) >= restoreRowsBatchSize {
+		return r.flush(ctx)
+	}
+	return nil
+}
+
+// flush writes the buffered rows into the live table in a single batch.
+func (r *rowsRestorer) flush(ctx context.Context) error {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	newRows := make([]tree.Datums, len(r.rows))
+	spans := make(roachpb.Spans, len(r.rows))
+	spanIDs := make([]int, len(r.rows))
+	numKeyCols := r.liveTable.GetPrimaryIndex().NumKeyColumns()
+	for i, vals := range r.rows {
+		newRow, err := r.completeRow(ctx, vals)
+		if err != nil {
+			return err
+		}
+		newRows[i] = newRow
+		if spans[i], _, err = r.spanBuilder.SpanFromDatumRow(newRow, numKeyCols, r.ivars.Mapping); err != nil {
+			return err
+		}
+		spanIDs[i] = i
+	}
+	r.rows = r.rows[:0]
+	oldRows, err := r.fetchLiveRows(ctx, spans, spanIDs)
+	if err != nil {
+		return err
+	}
+
+	b := r.p.Txn().NewBatch()
+	for i, newRow := range newRows {
+		oldRow := oldRows[i]
+		if oldRow != nil {
+			for j := len(r.restoredOrds); j < len(r.cols); j++ {
+				if !r.cols[j].IsComputed() {
+					newRow[j] = oldRow[j]
+				}
+			}
+			if newRow, err = r.completeRow(ctx, newRow); err != nil {
+				return err
+			}
+		}
+		if err := r.writeRow(ctx, b, oldRow, newRow); err != nil {
+			return err
+		}
+	}
+	if err := r.p.Txn().Run(ctx, b); err != nil {
+		return row.ConvertBatchError(ctx, r.liveTable, b)
+	}
+	r.restored += int64(len(newRows))
+	return nil
+}
+
+// completeRow fills in the default values of the columns missing from the
+// given values of a row, which are in the order of r.cols, computes the
+// computed columns, and checks the NOT NULL constraints.
+func (r *rowsRestorer) completeRow(ctx context.Context, vals tree.Datums) (tree.Datums, error) {
+	return row.GenerateInsertRow(
+		ctx, r.defaultExprs, r.computedExprs, r.cols, r.cols, r.evalCtx, r.liveTable, vals, &r.ivars,
+	)
+}
+
+// fetchLiveRows returns the existing rows of the live table in the given
+// point spans of its primary index, indexed by span ID, with a nil row for the
+// spans which contain no row.
+func (r *rowsRestorer) fetchLiveRows(
+	ctx context.Context, spans roachpb.Spans, spanIDs []int,
+) ([]tree.Datums, error) {
+	oldRows := make([]tree.Datums, len(spans))
+	if err := r.fetcher.StartScan(
+		ctx, spans, spanIDs, rowinfra.NoBytesLimit, rowinfra.NoRowLimit,
+	); err != nil {
+		return nil, err
+	}
+	for {
+		encRow, spanID, err := r.fetcher.NextRow(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if encRow == nil {
+			return oldRows, nil
+		}
+		oldRow := make(tree.Datums, len(r.cols))
+		for i := range oldRow {
+			oldRow[i] = tree.DNull
+		}
+		for i, encDatum := range encRow {
+			if encDatum.IsUnset() {
+				continue
+			}
+			ord := r.liveFetchOrds[i]
+			if err := encDatum.EnsureDecoded(r.cols[ord].GetType(), &r.alloc); err != nil {
+				return nil, err
+			}
+			oldRow[ord] = encDatum.Datum
+		}
+		// The virtual columns are computed, since they may be indexed.
+		if oldRows[spanID], err = r.completeRow(ctx, oldRow); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// writeRow adds the KV operations which write newRow into the live table to
+// the batch. The row is inserted if oldRow is nil, and updated otherwise.
+func (r *rowsRestorer) writeRow(ctx context.Context, b *kv.Batch, oldRow, newRow tree.Datums) error {
+	if err := r.checkConstraints(ctx, newRow); err != nil {
+		return err
+	}
+	putVals, err := r.evalPartialIndexPredicates(ctx, newRow)
+	if err != nil {
+		return err
+	}
+	var delVals tree.Datums
+	if oldRow != nil {
+		if delVals, err = r.evalPartialIndexPredicates(ctx, oldRow); err != nil {
+			return err
+		}
+	}
+	var pm row.PartialIndexUpdateHelper
+	if err := pm.Init(putVals, delVals, r.liveTable); err != nil {
+		return err
+	}
+
+	traceKV := r.p.ExtendedEvalContext().Tracing.KVTracingEnabled()
+	if oldRow == nil {
+		return r.ri.InsertRow(ctx, b, newRow, pm, false /* overwrite */, traceKV)
+	}
+	updateVals := make(tree.Datums, len(r.updateCols))
+	for i, col := range r.updateCols {
+		updateVals[i] = newRow[r.ivars.Mapping.GetDefault(col.GetID())]
+	}
+	_, err = r.ru.UpdateRow(ctx, b, oldRow, updateVals, pm, traceKV)
+	return err
+}
+
+// checkConstraints returns an error if the row does not satisfy the CHECK
+// constraints of the live table.
+func (r *rowsRestorer) checkConstraints(ctx context.Context, vals tree.Datums) error {
+	if len(r.checkExprs) == 0 {
+		return nil
+	}
+	r.ivars.CurSourceRow = vals
+	r.evalCtx.PushIVarContainer(&r.ivars)
+	defer r.evalCtx.PopIVarContainer()
+	checks := r.liveTable.ActiveChecks()
+	for i, expr := range r.checkExprs {
+		res, err := eval.Expr(ctx, r.evalCtx, expr)
+		if err != nil {
+			return err
+		}
+		if res != tree.DBoolFalse {
+			continue
+		}
+		exprStr, err := schemaexpr.FormatExprForDisplay(
+			ctx, r.liveTable, checks[i].Expr, r.p.SemaCtx(), r.p.SessionData(), tree.FmtParsable,
+		)
+		if err != nil {
+			exprStr = checks[i].Expr
+		}
+		return pgerror.WithConstraintName(pgerror.Newf(
+			pgcode.CheckViolation, "failed to satisfy CHECK constraint (%s)", exprStr,
+		), checks[i].Name)
+	}
+	return nil
+}
+
+// evalPartialIndexPredicates returns whether the row satisfies the predicate
+// of each partial index of the live table.
+func (r *rowsRestorer) evalPartialIndexPredicates(
+	ctx context.Context, vals tree.Datums,
+) (tree.Datums, error) {
+	partialIndexes := r.liveTable.PartialIndexes()
+	if len(partialIndexes) == 0 {
+		return nil, nil
+	}
+	r.ivars.CurSourceRow = vals
+	r.evalCtx.PushIVarContainer(&r.ivars)
+	defer r.evalCtx.PopIVarContainer()
+	res := make(tree.Datums, len(partialIndexes))
+	for i, idx := range partialIndexes {
+		var err error
+		if res[i], err = eval.Expr(ctx, r.evalCtx, r.partialIndexExprs[idx.GetID()]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (r *rowsRestorer) close(ctx context.Context) {
+	r.fetcher.Close(ctx)
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.restoreRowsPlanHook", restoreRowsPlanHook)
+}
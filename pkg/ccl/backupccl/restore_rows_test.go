@@ -0,0 +1,150 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 20
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = "nodelocal://0/rows"
+	const allRows = `SELECT id, balance, payload FROM data.bank ORDER BY id`
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	original := sqlDB.QueryStr(t, allRows)
+
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 5`)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = -1 WHERE id = 10`)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (1000, 1000, 'new')`)
+
+	// Only the rows matching the WHERE clause are restored.
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' WHERE id < 5`, collection),
+		[][]string{{"5"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data.bank`, [][]string{{fmt.Sprint(numAccounts + 1)}})
+	sqlDB.CheckQueryResults(t, `SELECT balance FROM data.bank WHERE id = 10`, [][]string{{"-1"}})
+
+	// Restoring all rows overwrites the updated row, and leaves rows which are
+	// not in the backup in place.
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s'`, collection),
+		[][]string{{fmt.Sprint(numAccounts)}})
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 1000`)
+	sqlDB.CheckQueryResults(t, allRows, original)
+
+	// The secondary indexes of the live table are maintained. The columns which
+	// are not in the backup keep their values in existing rows, and get their
+	// default values in inserted rows. The part of the WHERE clause which does
+	// not constrain the primary key is evaluated on the rows of the backup.
+	sqlDB.Exec(t, `CREATE INDEX balance_idx ON data.bank (balance)`)
+	sqlDB.Exec(t, `ALTER TABLE data.bank ADD COLUMN note STRING DEFAULT 'restored'`)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = -1, note = 'live' WHERE id IN (10, 11)`)
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 12`)
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' WHERE id BETWEEN 10 AND 12 AND id %% 2 = 0`,
+		collection),
+		[][]string{{"2"}})
+	sqlDB.CheckQueryResults(t, `SELECT id, note FROM data.bank@balance_idx WHERE balance = -1`,
+		[][]string{{"11", "live"}})
+	sqlDB.CheckQueryResults(t, `SELECT id, note FROM data.bank WHERE id IN (10, 12) ORDER BY id`,
+		[][]string{{"10", "live"}, {"12", "restored"}})
+
+	// The CHECK constraints of the live table are enforced, while the tables
+	// with constraints which the row writers do not enforce are rejected.
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 12`)
+	sqlDB.Exec(t, `ALTER TABLE data.bank ADD CONSTRAINT not_12 CHECK (id != 12)`)
+	sqlDB.ExpectErr(t, `failed to satisfy CHECK constraint \(id != 12`, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' WHERE id = 12`, collection))
+	sqlDB.Exec(t, `ALTER TABLE data.bank DROP CONSTRAINT not_12`)
+	sqlDB.Exec(t, `CREATE TABLE data.child (id INT PRIMARY KEY REFERENCES data.bank (id))`)
+	sqlDB.ExpectErr(t, "RESTORE ROWS does not support tables with foreign keys", fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' WHERE id = 12`, collection))
+	sqlDB.Exec(t, `DROP TABLE data.child`)
+
+	sqlDB.Exec(t, `CREATE TABLE data.other (id INT PRIMARY KEY)`)
+	sqlDB.ExpectErr(t, "failed to resolve the table in the backup", fmt.Sprintf(
+		`RESTORE ROWS INTO data.other FROM LATEST IN '%s'`, collection))
+
+	sqlDB.Exec(t, `ALTER TABLE data.bank DROP COLUMN payload`)
+	sqlDB.ExpectErr(t, `column "payload" of the table in the backup does not exist in bank`, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s'`, collection))
+}
+
+// TestRestoreRowsIncrementalChain tests restoring rows from the layers of a
+// chain of incremental backups, and that restoring rows fails if a layer of the
+// chain is corrupt or missing.
+func TestRestoreRowsIncrementalChain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 20
+	_, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	chain := makeIncrementalChain(t, sqlDB, rawDir, "rows-chain")
+
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id IN (1, 1000)`)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (2, 0, 'live')`)
+
+	// Restoring from the latest layer applies the changes of every layer.
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' WHERE id IN (1, 2, 1000)`, chain.collection),
+		[][]string{{"2"}})
+	sqlDB.CheckQueryResults(t, `SELECT id, balance FROM data.bank WHERE id IN (1, 2, 1000) ORDER BY id`,
+		[][]string{{"1", "2"}, {"2", "0"}, {"1000", "1000"}})
+
+	// Restoring as of the end of an intermediate layer ignores the later layers.
+	// Rows which were deleted as of that time are not deleted from the live
+	// table.
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id IN (1, 1000)`)
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' AS OF SYSTEM TIME %s WHERE id IN (1, 2, 1000)`,
+		chain.collection, chain.midTime),
+		[][]string{{"1"}})
+	sqlDB.CheckQueryResults(t, `SELECT id, balance FROM data.bank WHERE id IN (1, 2, 1000) ORDER BY id`,
+		[][]string{{"1", "1"}, {"2", "0"}})
+
+	// A restore which fails because of a corrupt or missing layer does not
+	// write any rows, so the live table is left untouched.
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 5 WHERE id = 1`)
+	liveRows := [][]string{{"1", "5"}, {"2", "0"}}
+
+	t.Run("corrupt layer", func(t *testing.T) {
+		manifest := chain.incManifests[1]
+		data := corruptBackupManifest(t, manifest)
+		sqlDB.ExpectErr(t, "checksum mismatch", fmt.Sprintf(
+			`RESTORE ROWS INTO data.bank FROM LATEST IN '%s'`, chain.collection))
+		sqlDB.CheckQueryResults(t,
+			`SELECT id, balance FROM data.bank WHERE id IN (1, 2, 1000) ORDER BY id`, liveRows)
+		require.NoError(t, os.WriteFile(manifest, data, 0644 /* perm */))
+	})
+
+	t.Run("missing layer data", func(t *testing.T) {
+		removeBackupData(t, chain.incManifests[0])
+		sqlDB.ExpectErr(t, "file does not exist", fmt.Sprintf(
+			`RESTORE ROWS INTO data.bank FROM LATEST IN '%s'`, chain.collection))
+		sqlDB.ExpectErr(t, "file does not exist", fmt.Sprintf(
+			`RESTORE ROWS INTO data.bank FROM LATEST IN '%s' AS OF SYSTEM TIME %s`,
+			chain.collection, chain.midTime))
+		sqlDB.CheckQueryResults(t,
+			`SELECT id, balance FROM data.bank WHERE id IN (1, 2, 1000) ORDER BY id`, liveRows)
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// showBackupTablePlanHook implements sql.PlanHookFn. SHOW BACKUP TABLE reads
+// the rows of a table directly from the files of a backup, without ingesting
+// them into KV, so that a backup can be queried without restoring it. Its
+// WHERE clause must be fully translated into spans of the primary index, so
+// that only the files overlapping those spans are read; filters on other
+// columns belong in a query over the statement.
+//
+// ATTACH BACKUP attaches the tables of a backup as a read-only database
+// instead, whose tables can be queried like any other.
+func showBackupTablePlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	showStmt, ok := stmt.(*tree.ShowBackupTable)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, showStmt.Subdir, "SHOW BACKUP TABLE")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	inColFn, err := p.TypeAsStringArray(ctx, tree.Exprs(showStmt.InCollection), "SHOW BACKUP TABLE")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	expected := map[string]sql.KVStringOptValidate{
+		backupencryption.BackupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupencryption.BackupOptEncKMS:        sql.KVStringOptRequireValue,
+		backupOptIncStorage:                     sql.KVStringOptRequireValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, showStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	// The result columns are the columns of the table in the backup, so the
+	// backup is resolved while planning.
+	subdir, err := subdirFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	dest, err := inColFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	if len(dest) < 1 || len(dest[0]) < 1 {
+		return nil, nil, nil, false, errors.New("invalid base backup specified")
+	}
+	if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, dest); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	var endTime hlc.Timestamp
+	if showStmt.AsOf.Expr != nil {
+		asOf, err := p.EvalAsOfTimestamp(ctx, showStmt.AsOf)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+		endTime = asOf.Timestamp
+	}
+
+	if strings.EqualFold(subdir, backupbase.LatestFileName) {
+		latest, err := backupdest.ReadLatestFile(ctx, dest[0],
+			p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+		if err != nil {
+			return nil, nil, nil, false, errors.Wrap(err, "read LATEST path")
+		}
+		subdir = latest
+	}
+
+	// The memory of the manifests is accounted for while planning, and again
+	// while executing, which reuses them rather than resolving them again.
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+	chain, err := resolveBackupChain(ctx, p, dest, subdir, opts, endTime, &mem)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	backupTable, err := resolveBackupTable(ctx, p, chain.manifests, showStmt.Table, endTime)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var filter tree.Expr
+	if showStmt.Where != nil {
+		filter = showStmt.Where.Expr
+	}
+	spans, _, err := constrainBackupTableSpans(ctx, p, sql.MustFullyConstrain, backupTable, filter)
+	if err != nil {
+		return nil, nil, nil, false, pgerror.WithCandidateCode(
+			errors.Wrap(err, "the WHERE clause of SHOW BACKUP TABLE can only constrain the primary key"),
+			pgcode.InvalidParameterValue)
+	}
+	cols, err := storedBackupTableColumns(backupTable, "SHOW BACKUP TABLE")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	header := make(colinfo.ResultColumns, len(cols))
+	fetchCols := make([]descpb.ColumnID, len(cols))
+	for i, col := range cols {
+		header[i] = colinfo.ResultColumn{Name: col.GetName(), Typ: col.GetType(), Hidden: col.IsHidden()}
+		fetchCols[i] = col.GetID()
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+		defer mem.Close(ctx)
+		if err := mem.Grow(ctx, chain.memReserved); err != nil {
+			return err
+		}
+		readTime := endTime
+		if readTime.IsEmpty() {
+			readTime = chain.manifests[len(chain.manifests)-1].EndTime
+		}
+		return readBackupTableRows(ctx, p, chain, endTime, readTime, backupTable, backupTable, spans, fetchCols,
+			func(ctx context.Context, row tree.Datums) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case resultsCh <- row:
+					return nil
+				}
+			})
+	}
+	return fn, header, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.showBackupTablePlanHook", showBackupTablePlanHook)
+}
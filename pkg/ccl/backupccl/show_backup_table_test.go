@@ -0,0 +1,105 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowBackupTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 20
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = "nodelocal://0/show-table"
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	original := sqlDB.QueryStr(t, `SELECT id, balance, payload FROM data.bank ORDER BY id`)
+
+	// Changes made after the full backup are only visible through the
+	// incremental backup.
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 5`)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = -1 WHERE id = 10`)
+	var fullSubdir string
+	sqlDB.QueryRow(t, `SELECT path FROM [SHOW BACKUPS IN $1]`, collection).Scan(&fullSubdir)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	sqlDB.Exec(t, `DROP TABLE data.bank`)
+
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT id, balance, payload FROM [SHOW BACKUP TABLE data.bank FROM '%s' IN '%s'] ORDER BY id`,
+		fullSubdir, collection), original)
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT count(*), min(id) FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s']`, collection),
+		[][]string{{fmt.Sprint(numAccounts - 5), "5"}})
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT balance FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s'] WHERE id = 10`, collection),
+		[][]string{{"-1"}})
+
+	// The WHERE clause of the statement limits the spans which are read, and
+	// can only constrain the primary key.
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT id FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s' WHERE id BETWEEN 3 AND 6]`, collection),
+		[][]string{{"5"}, {"6"}})
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT balance FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s' WHERE id = 10]`, collection),
+		[][]string{{"-1"}})
+	sqlDB.ExpectErr(t, "can only constrain the primary key", fmt.Sprintf(
+		`SHOW BACKUP TABLE data.bank FROM LATEST IN '%s' WHERE balance = 0`, collection))
+
+	sqlDB.ExpectErr(t, "failed to resolve the table in the backup", fmt.Sprintf(
+		`SHOW BACKUP TABLE data.other FROM LATEST IN '%s'`, collection))
+}
+
+// TestShowBackupTableIncrementalChain tests reading a table from the layers of
+// a chain of incremental backups, and that reading it fails if a layer of the
+// chain is corrupt or missing.
+func TestShowBackupTableIncrementalChain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 20
+	_, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	chain := makeIncrementalChain(t, sqlDB, rawDir, "show-table-chain")
+
+	latestQuery := fmt.Sprintf(
+		`SELECT id, balance FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s'] WHERE id IN (1, 2, 1000) ORDER BY id`,
+		chain.collection)
+	asOfQuery := fmt.Sprintf(
+		`SELECT id, balance FROM [SHOW BACKUP TABLE data.bank FROM LATEST IN '%s' AS OF SYSTEM TIME %s] WHERE id IN (1, 2, 1000) ORDER BY id`,
+		chain.collection, chain.midTime)
+
+	// Reading the latest layer applies the changes of every layer, while reading
+	// as of the end of an intermediate layer ignores the later layers.
+	sqlDB.CheckQueryResults(t, latestQuery, [][]string{{"1", "2"}, {"1000", "1000"}})
+	sqlDB.CheckQueryResults(t, asOfQuery, [][]string{{"1", "1"}})
+
+	t.Run("corrupt layer", func(t *testing.T) {
+		manifest := chain.incManifests[1]
+		data := corruptBackupManifest(t, manifest)
+		sqlDB.ExpectErr(t, "checksum mismatch", latestQuery)
+		require.NoError(t, os.WriteFile(manifest, data, 0644 /* perm */))
+		sqlDB.CheckQueryResults(t, latestQuery, [][]string{{"1", "2"}, {"1000", "1000"}})
+	})
+
+	t.Run("missing layer data", func(t *testing.T) {
+		removeBackupData(t, chain.incManifests[0])
+		sqlDB.ExpectErr(t, "file does not exist", latestQuery)
+		sqlDB.ExpectErr(t, "file does not exist", asOfQuery)
+	})
+}
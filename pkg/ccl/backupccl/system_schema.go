@@ -550,6 +550,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 		customRestoreFunc:            roleIDSeqRestoreFunc,
 		restoreInOrder:               roleIDSequenceRestoreOrder,
 	},
+	systemschema.SystemNotificationsTable.GetName(): {
+		shouldIncludeInClusterBackup: optOutOfClusterBackup,
+	},
 }
 
 func rekeySystemTable(
@@ -685,3 +685,80 @@ func requireRecoveryEvent(
 		return nil
 	})
 }
+
+// corruptBackupManifest flips a bit of the backup manifest at the given path on
+// disk and returns its original contents. The checksum stored next to the
+// manifest covers all of it, so reading the corrupt manifest fails with a
+// checksum mismatch.
+func corruptBackupManifest(t *testing.T, manifest string) (original []byte) {
+	original, err := os.ReadFile(manifest)
+	require.NoError(t, err)
+	require.NotEmpty(t, original)
+	corrupt := append([]byte(nil), original...)
+	corrupt[len(corrupt)/2] ^= 1
+	require.NoError(t, os.WriteFile(manifest, corrupt, 0644 /* perm */))
+	return original
+}
+
+// incrementalChain is a chain of backups of the data database made by
+// makeIncrementalChain.
+type incrementalChain struct {
+	// collection is the URI of the collection holding the chain.
+	collection string
+	// midTime is the end time of the first incremental backup, as a decimal
+	// timestamp.
+	midTime string
+	// incManifests are the paths on disk of the manifests of the incremental
+	// backups, in time order.
+	incManifests []string
+}
+
+// makeIncrementalChain backs up the data database set up by
+// backupRestoreTestSetup as a chain of a full backup and two incremental
+// backups, into a collection in the given subdirectory of the external IO
+// directory rawDir. The rows of data.bank change as follows:
+//   - As of midTime, the end of the first incremental backup, the balance of
+//     account 1 is 1 and account 2 is deleted.
+//   - As of the end of the second incremental backup, the balance of account 1
+//     is 2 and account 1000 is inserted with a balance of 1000.
+func makeIncrementalChain(
+	t *testing.T, sqlDB *sqlutils.SQLRunner, rawDir string, subdir string,
+) incrementalChain {
+	collection := "nodelocal://0/" + subdir
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 1 WHERE id = 1`)
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 2`)
+	var midTime string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&midTime)
+	sqlDB.Exec(t, fmt.Sprintf(`BACKUP DATABASE data INTO LATEST IN '%s' AS OF SYSTEM TIME %s`,
+		collection, midTime))
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 2 WHERE id = 1`)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (1000, 1000, 'new')`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+
+	// The incremental backups are stored in time order under the default
+	// incrementals directory of the collection.
+	incManifests, err := filepath.Glob(filepath.Join(rawDir, subdir,
+		backupbase.DefaultIncrementalsSubdir, "*", "*", "*", "*", "*", backupbase.BackupManifestName))
+	require.NoError(t, err)
+	require.Len(t, incManifests, 2)
+
+	return incrementalChain{
+		collection:   collection,
+		midTime:      midTime,
+		incManifests: incManifests,
+	}
+}
+
+// removeBackupData removes the data files of the backup whose manifest is at
+// the given path on disk.
+func removeBackupData(t *testing.T, manifest string) {
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(manifest), "data", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		require.NoError(t, os.Remove(f))
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupinfo"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+// verifyBackupResumer implements the jobs.Resumer interface for VERIFY BACKUP
+// jobs. It reads every file referenced by the manifests of a backup chain on
+// the nodes of the cluster and checks them against the manifests, optionally
+// restoring the backup into a scratch keyspace as well.
+type verifyBackupResumer struct {
+	job *jobs.Job
+
+	progress jobspb.VerifyBackupProgress
+}
+
+var _ jobs.Resumer = &verifyBackupResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *verifyBackupResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+	p := execCtx.(sql.JobExecContext)
+
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	kmsEnv := backupencryption.MakeBackupKMSEnv(p.ExecCfg().Settings, &p.ExecCfg().ExternalIODirConfig,
+		p.ExecCfg().DB, p.User(), p.ExecCfg().InternalExecutor)
+	manifests, memSize, err := backupinfo.LoadBackupManifests(ctx, &mem, details.URIs,
+		p.User(), p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, details.Encryption, &kmsEnv)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		mem.Shrink(ctx, memSize)
+	}()
+
+	backupLocalityMap, err := makeBackupLocalityMap(details.BackupLocalityInfo, p.User())
+	if err != nil {
+		return errors.Wrap(err, "resolving locality locations")
+	}
+	files, layers := makeVerifyBackupFileSpecs(manifests, backupLocalityMap)
+
+	var dryRunSpans []execinfrapb.RestoreSpanEntry
+	if details.DryRunRestore {
+		last := manifests[len(manifests)-1]
+		introducedSpanFrontier, err := createIntroducedSpanFrontier(manifests, details.EndTime)
+		if err != nil {
+			return err
+		}
+		if err := checkCoverage(ctx, last.Spans, manifests); err != nil {
+			return err
+		}
+		dryRunSpans = makeSimpleImportSpans(last.Spans, manifests, backupLocalityMap,
+			introducedSpanFrontier, nil /* lowWaterMark */, targetRestoreSpanSize.Get(p.ExecCfg().SV()))
+	}
+
+	// The verification is restarted from scratch when the job is resumed.
+	r.progress = jobspb.VerifyBackupProgress{}
+	if len(files)+len(dryRunSpans) == 0 {
+		return nil
+	}
+
+	var mu struct {
+		syncutil.Mutex
+		progress jobspb.VerifyBackupProgress
+	}
+	recordCorruptFile := func(layer int, path string, fileErr string) {
+		var uri string
+		if layer < len(details.URIs) {
+			var err error
+			uri, err = cloud.SanitizeExternalStorageURI(details.URIs[layer], nil /* extraParams */)
+			if err != nil {
+				log.Warningf(ctx, "failed to sanitize backup URI: %v", err)
+			}
+		}
+		mu.progress.CorruptFiles = append(mu.progress.CorruptFiles, jobspb.VerifyBackupProgress_CorruptFile{
+			BackupURI: uri,
+			Path:      path,
+			Error:     fileErr,
+		})
+	}
+
+	numChunks := len(files) + len(dryRunSpans)
+	requestFinishedCh := make(chan struct{}, numChunks) // enough buffer to never block
+	progCh := make(chan *execinfrapb.RemoteProducerMetadata_BulkProcessorProgress)
+
+	progressLogger := jobs.NewChunkProgressLogger(r.job, numChunks, 0, /* startFraction */
+		func(progressedCtx context.Context, details jobspb.ProgressDetails) {
+			switch d := details.(type) {
+			case *jobspb.Progress_VerifyBackup:
+				mu.Lock()
+				d.VerifyBackup = protoutil.Clone(&mu.progress).(*jobspb.VerifyBackupProgress)
+				mu.Unlock()
+			default:
+				log.Errorf(progressedCtx, "job payload had unexpected type %T", d)
+			}
+		})
+	jobProgressLoop := func(ctx context.Context) error {
+		return progressLogger.Loop(ctx, requestFinishedCh)
+	}
+
+	jobCheckpointLoop := func(ctx context.Context) error {
+		defer close(requestFinishedCh)
+		// When a processor is done with a file or a span, it will send a progress
+		// update to progCh.
+		for progress := range progCh {
+			var progDetails backuppb.VerifyProgress
+			if err := pbtypes.UnmarshalAny(&progress.ProgressDetails, &progDetails); err != nil {
+				log.Errorf(ctx, "unable to unmarshal verify backup progress details: %+v", err)
+				continue
+			}
+			mu.Lock()
+			if progDetails.DryRun {
+				mu.progress.RowsRestored += progDetails.RowsRestored
+			} else {
+				mu.progress.FilesVerified++
+				mu.progress.BytesVerified += progDetails.Size
+				if progDetails.Error != "" {
+					recordCorruptFile(int(progDetails.Layer), progDetails.Path, progDetails.Error)
+				}
+			}
+			mu.Unlock()
+
+			requestFinishedCh <- struct{}{}
+		}
+		return nil
+	}
+
+	runVerify := func(ctx context.Context) error {
+		return distVerifyBackup(ctx, p, int64(r.job.ID()), files, layers, dryRunSpans,
+			details.EndTime, details.ScratchID, details.Encryption, &kmsEnv, progCh)
+	}
+
+	err = ctxgroup.GoAndWait(ctx, jobProgressLoop, jobCheckpointLoop, runVerify)
+	// Record the final progress, including the corrupt files found so far, even
+	// if the verification did not complete.
+	r.progress = mu.progress
+	if setErr := r.job.SetProgress(ctx, nil /* txn */, r.progress); setErr != nil {
+		log.Warningf(ctx, "failed to record verify backup progress: %v", setErr)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "verifying %d backup files", len(files))
+	}
+	if err := r.clearScratchKeyspace(ctx, p); err != nil {
+		log.Warningf(ctx, "clearing scratch keyspace failed %v", err)
+	}
+
+	if n := len(r.progress.CorruptFiles); n > 0 {
+		paths := make([]string, n)
+		for i, f := range r.progress.CorruptFiles {
+			paths[i] = f.Path
+		}
+		return pgerror.Newf(pgcode.DataCorrupted,
+			"backup contains %d corrupt files: %s", n, strings.Join(paths, ", "))
+	}
+	return nil
+}
+
+// makeVerifyBackupFileSpecs returns a spec for each file referenced by the
+// given backup manifests, grouping all the manifest entries which reference the
+// same file, along with the primary index IDs of the tables of each backup.
+func makeVerifyBackupFileSpecs(
+	manifests []backuppb.BackupManifest, backupLocalityMap map[int]storeByLocalityKV,
+) ([]execinfrapb.VerifyBackupFileSpec, []execinfrapb.VerifyBackupDataSpec_Layer) {
+	var files []execinfrapb.VerifyBackupFileSpec
+	layers := make([]execinfrapb.VerifyBackupDataSpec_Layer, len(manifests))
+	for layer := range manifests {
+		m := &manifests[layer]
+
+		pkIDs := make(map[uint64]bool)
+		for i := range m.Descriptors {
+			if t, _, _, _, _ := descpb.GetDescriptors(&m.Descriptors[i]); t != nil {
+				pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+			}
+		}
+		layers[layer].PKIDs = pkIDs
+
+		fileIdx := make(map[string]int)
+		for _, f := range m.Files {
+			entry := execinfrapb.VerifyBackupFileSpec_Entry{
+				Span:        f.Span,
+				EntryCounts: f.EntryCounts,
+				StartTime:   m.StartTime,
+				EndTime:     m.EndTime,
+			}
+			// The time bounds of a file are only recorded if they differ from
+			// the bounds of the backup.
+			if !f.EndTime.IsEmpty() {
+				entry.StartTime, entry.EndTime = f.StartTime, f.EndTime
+			}
+			if i, ok := fileIdx[f.Path]; ok {
+				files[i].Entries = append(files[i].Entries, entry)
+				continue
+			}
+			fileSpec := execinfrapb.RestoreFileSpec{Path: f.Path, Dir: m.Dir}
+			if dir, ok := backupLocalityMap[layer][f.LocalityKV]; ok {
+				fileSpec.Dir = dir
+			}
+			fileIdx[f.Path] = len(files)
+			files = append(files, execinfrapb.VerifyBackupFileSpec{
+				File:    fileSpec,
+				Layer:   int32(layer),
+				Entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry},
+			})
+		}
+	}
+	return files, layers
+}
+
+// ReportResults implements JobResultsReporter interface.
+func (r *verifyBackupResumer) ReportResults(
+	ctx context.Context, resultsCh chan<- tree.Datums,
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDInt(tree.DInt(r.progress.FilesVerified)),
+		tree.NewDInt(tree.DInt(r.progress.BytesVerified)),
+		tree.NewDInt(tree.DInt(r.progress.RowsRestored)),
+	}:
+		return nil
+	}
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. It clears the scratch
+// keyspace of the dry-run restore, which a processor that was interrupted may
+// not have cleared.
+func (r *verifyBackupResumer) OnFailOrCancel(
+	ctx context.Context, execCtx interface{}, _ error,
+) error {
+	return r.clearScratchKeyspace(ctx, execCtx.(sql.JobExecContext))
+}
+
+// clearScratchKeyspace removes everything under the table prefix of the scratch
+// ID of the job.
+func (r *verifyBackupResumer) clearScratchKeyspace(
+	ctx context.Context, p sql.JobExecContext,
+) error {
+	details := r.job.Details().(jobspb.VerifyBackupDetails)
+	if !details.DryRunRestore {
+		return nil
+	}
+	prefix := p.ExecCfg().Codec.TablePrefix(uint32(details.ScratchID))
+	return clearScratchSpan(ctx, p.ExecCfg().DB, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeVerifyBackup,
+		func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+			return &verifyBackupResumer{
+				job: job,
+			}
+		},
+		jobs.UsesTenantCostControl,
+	)
+}
@@ -0,0 +1,416 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupdest"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backupencryption"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuputils"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/cloudprivilege"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	verifyBackupOptDryRunRestore = "dry_run_restore"
+	verifyBackupOptDetached      = "detached"
+)
+
+var verifyBackupHeader = colinfo.ResultColumns{
+	{Name: "job_id", Typ: types.Int},
+	{Name: "status", Typ: types.String},
+	{Name: "files_verified", Typ: types.Int},
+	{Name: "bytes_verified", Typ: types.Int},
+	{Name: "rows_restored", Typ: types.Int},
+}
+
+// verifyBackupPlanHook implements sql.PlanHookFn.
+func verifyBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	verifyStmt, ok := stmt.(*tree.VerifyBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureRestoreEnabled,
+		"VERIFY BACKUP",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, verifyStmt.Subdir, "VERIFY BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	toFn, err := p.TypeAsStringArray(ctx, tree.Exprs(verifyStmt.To), "VERIFY BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupencryption.BackupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupencryption.BackupOptEncKMS:        sql.KVStringOptRequireValue,
+		backupOptIncStorage:                     sql.KVStringOptRequireValue,
+		verifyBackupOptDryRunRestore:            sql.KVStringOptRequireNoValue,
+		verifyBackupOptDetached:                 sql.KVStringOptRequireNoValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, verifyStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	_, detached := opts[verifyBackupOptDetached]
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnIsSingleStmt || detached) {
+			return errors.Errorf("VERIFY BACKUP cannot be used inside a multi-statement transaction without DETACHED option")
+		}
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		dest, err := toFn()
+		if err != nil {
+			return err
+		}
+		return doVerifyBackupPlan(ctx, verifyStmt, p, dest, subdir, opts, detached, resultsCh)
+	}
+
+	if detached {
+		return fn, jobs.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, verifyBackupHeader, nil, false, nil
+}
+
+func doVerifyBackupPlan(
+	ctx context.Context,
+	verifyStmt *tree.VerifyBackup,
+	p sql.PlanHookState,
+	dest []string,
+	subdir string,
+	opts map[string]string,
+	detached bool,
+	resultsCh chan<- tree.Datums,
+) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.VerifyBackup) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"cannot run VERIFY BACKUP before system is fully upgraded to v22.2")
+	}
+	if len(dest) < 1 || len(dest[0]) < 1 {
+		return errors.New("invalid base backup specified")
+	}
+	if err := cloudprivilege.CheckDestinationPrivileges(ctx, p, dest); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(subdir, backupbase.LatestFileName) {
+		latest, err := backupdest.ReadLatestFile(ctx, dest[0],
+			p.ExecCfg().DistSQLSrv.ExternalStorageFromURI, p.User())
+		if err != nil {
+			return errors.Wrap(err, "read LATEST path")
+		}
+		subdir = latest
+	}
+
+	mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	chain, err := resolveBackupChain(ctx, p, dest, subdir, opts, hlc.Timestamp{}, &mem)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		mem.Shrink(ctx, chain.memReserved)
+	}()
+	manifests := chain.manifests
+
+	description, err := verifyBackupJobDescription(p, verifyStmt, dest, subdir, opts)
+	if err != nil {
+		return err
+	}
+	_, dryRunRestore := opts[verifyBackupOptDryRunRestore]
+	// The dry-run restore writes under the table prefix of a descriptor ID which
+	// is reserved for the job, so that it cannot collide with any other data.
+	var scratchID descpb.ID
+	if dryRunRestore {
+		scratchID, err = p.ExecCfg().DescIDGenerator.GenerateUniqueDescID(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	jr := jobs.Record{
+		Description: description,
+		Username:    p.User(),
+		Details: jobspb.VerifyBackupDetails{
+			URIs:               chain.defaultURIs,
+			BackupLocalityInfo: chain.localityInfo,
+			EndTime:            manifests[len(manifests)-1].EndTime,
+			Encryption:         chain.encryption,
+			DryRunRestore:      dryRunRestore,
+			ScratchID:          scratchID,
+		},
+		Progress: jobspb.VerifyBackupProgress{},
+	}
+
+	if detached {
+		// When running in detached mode, we simply create the job record.
+		// We do not wait for the job to finish.
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+			ctx, jr, jobID, p.Txn()); err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+		return nil
+	}
+
+	// We create the job record in the planner's transaction to ensure that
+	// the job record creation happens transactionally.
+	plannerTxn := p.Txn()
+
+	// Construct the job and commit the transaction. Perform this work in a
+	// closure to ensure that the job is cleaned up if an error occurs.
+	var sj *jobs.StartableJob
+	if err := func() (err error) {
+		defer func() {
+			if err == nil || sj == nil {
+				return
+			}
+			if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+				log.Errorf(ctx, "failed to cleanup job: %v", cleanupErr)
+			}
+		}()
+		jobID := p.ExecCfg().JobRegistry.MakeJobID()
+		if err := p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, &sj, jobID, plannerTxn, jr); err != nil {
+			return err
+		}
+		// We commit the transaction here so that the job can be started. This is
+		// safe because we're in an implicit transaction.
+		return plannerTxn.Commit(ctx)
+	}(); err != nil {
+		return err
+	}
+	if err := sj.Start(ctx); err != nil {
+		return err
+	}
+	if err := sj.AwaitCompletion(ctx); err != nil {
+		return err
+	}
+	return sj.ReportExecutionResults(ctx, resultsCh)
+}
+
+// resolvedBackupChain is a backup along with its incremental backups, as
+// resolved by resolveBackupChain.
+type resolvedBackupChain struct {
+	defaultURIs  []string
+	manifests    []backuppb.BackupManifest
+	localityInfo []jobspb.RestoreDetails_BackupLocalityInfo
+	encryption   *jobspb.BackupEncryptionOptions
+	// memReserved is the memory reserved for the manifests in the bound account
+	// passed to resolveBackupChain.
+	memReserved int64
+}
+
+// resolveBackupChain resolves the manifests of the backup in the given,
+// already resolved, subdirectory of the collection and of its incremental
+// backups up to endTime, decrypting them using the encryption options if any.
+func resolveBackupChain(
+	ctx context.Context,
+	p sql.PlanHookState,
+	dest []string,
+	subdir string,
+	opts map[string]string,
+	endTime hlc.Timestamp,
+	mem *mon.BoundAccount,
+) (resolvedBackupChain, error) {
+	fullyResolvedDest, err := backuputils.AppendPaths(dest, subdir)
+	if err != nil {
+		return resolvedBackupChain{}, err
+	}
+
+	var explicitIncPaths []string
+	if incPath, ok := opts[backupOptIncStorage]; ok {
+		explicitIncPaths = append(explicitIncPaths, incPath)
+	}
+	collection, computedSubdir := backupdest.CollectionAndSubdir(dest[0], subdir)
+	fullyResolvedIncrementalsDirectory, err := backupdest.ResolveIncrementalsBackupLocation(
+		ctx,
+		p.User(),
+		p.ExecCfg(),
+		explicitIncPaths,
+		[]string{collection},
+		computedSubdir,
+	)
+	if err != nil {
+		if errors.Is(err, cloud.ErrListingUnsupported) {
+			log.Warningf(ctx, "storage sink %v does not support listing, only reading the base backup",
+				explicitIncPaths)
+		} else {
+			return resolvedBackupChain{}, err
+		}
+	}
+
+	mkStore := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+	baseStores, cleanupFn, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedDest)
+	if err != nil {
+		return resolvedBackupChain{}, err
+	}
+	defer func() {
+		if err := cleanupFn(); err != nil {
+			log.Warningf(ctx, "failed to close base store: %+v", err)
+		}
+	}()
+	incStores, cleanupFn, err := backupdest.MakeBackupDestinationStores(ctx, p.User(), mkStore,
+		fullyResolvedIncrementalsDirectory)
+	if err != nil {
+		return resolvedBackupChain{}, err
+	}
+	defer func() {
+		if err := cleanupFn(); err != nil {
+			log.Warningf(ctx, "failed to close incremental store: %+v", err)
+		}
+	}()
+
+	ioConf := baseStores[0].ExternalIOConf()
+	kmsEnv := backupencryption.MakeBackupKMSEnv(p.ExecCfg().Settings, &ioConf,
+		p.ExecCfg().DB, p.User(), p.ExecCfg().InternalExecutor)
+
+	var encryption *jobspb.BackupEncryptionOptions
+	if passphrase, ok := opts[backupencryption.BackupOptEncPassphrase]; ok {
+		encOpts, err := backupencryption.ReadEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return resolvedBackupChain{}, err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode: jobspb.EncryptionMode_Passphrase,
+			Key:  storageccl.GenerateKey([]byte(passphrase), encOpts[0].Salt),
+		}
+	} else if kms, ok := opts[backupencryption.BackupOptEncKMS]; ok {
+		encOpts, err := backupencryption.ReadEncryptionOptions(ctx, baseStores[0])
+		if err != nil {
+			return resolvedBackupChain{}, err
+		}
+		var defaultKMSInfo *jobspb.BackupEncryptionOptions_KMSInfo
+		for _, encFile := range encOpts {
+			defaultKMSInfo, err = backupencryption.ValidateKMSURIsAgainstFullBackup(ctx, []string{kms},
+				backupencryption.NewEncryptedDataKeyMapFromProtoMap(encFile.EncryptedDataKeyByKMSMasterKeyID),
+				&kmsEnv)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return resolvedBackupChain{}, err
+		}
+		encryption = &jobspb.BackupEncryptionOptions{
+			Mode:    jobspb.EncryptionMode_KMS,
+			KMSInfo: defaultKMSInfo,
+		}
+	}
+
+	defaultURIs, manifests, localityInfo, memReserved, err := backupdest.ResolveBackupManifests(
+		ctx, mem, baseStores, incStores, mkStore, fullyResolvedDest,
+		fullyResolvedIncrementalsDirectory, endTime, encryption, &kmsEnv, p.User(),
+	)
+	if err != nil {
+		return resolvedBackupChain{}, err
+	}
+	return resolvedBackupChain{
+		defaultURIs:  defaultURIs,
+		manifests:    manifests,
+		localityInfo: localityInfo,
+		encryption:   encryption,
+		memReserved:  memReserved,
+	}, nil
+}
+
+// verifyBackupJobDescription returns the description of a VERIFY BACKUP job,
+// with the URIs sanitized and the encryption passphrase redacted.
+func verifyBackupJobDescription(
+	p sql.PlanHookState,
+	verifyStmt *tree.VerifyBackup,
+	dest []string,
+	subdir string,
+	opts map[string]string,
+) (string, error) {
+	to, err := sanitizeURIList(dest)
+	if err != nil {
+		return "", err
+	}
+	v := &tree.VerifyBackup{
+		Subdir: tree.NewDString(subdir),
+		To:     to,
+	}
+
+	// Options are added in the order in which they were specified.
+	for _, o := range verifyStmt.Options {
+		key := string(o.Key)
+		val, ok := opts[key]
+		if !ok {
+			continue
+		}
+		switch key {
+		case backupencryption.BackupOptEncPassphrase:
+			val = "redacted"
+		case backupencryption.BackupOptEncKMS:
+			if val, err = cloud.RedactKMSURI(val); err != nil {
+				return "", err
+			}
+		case backupOptIncStorage:
+			if val, err = cloud.SanitizeExternalStorageURI(val, nil /* extraParams */); err != nil {
+				return "", err
+			}
+		}
+		var value tree.Expr
+		if val != "" {
+			value = tree.NewDString(val)
+		}
+		v.Options = append(v.Options, tree.KVOption{Key: o.Key, Value: value})
+	}
+
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(v, ann), nil
+}
+
+func init() {
+	sql.AddPlanHook("backupccl.verifyBackupPlanHook", verifyBackupPlanHook)
+}
@@ -0,0 +1,609 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl/backuppb"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
+	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/pebble/vfs"
+	gogotypes "github.com/gogo/protobuf/types"
+)
+
+var verifyBackupOutputTypes = []*types.T{}
+
+const verifyBackupProcessorName = "verifyBackupDataProcessor"
+
+// verifyBackupDataProcessor represents the work each node in a cluster performs
+// during a VERIFY BACKUP. It is assigned a set of backup files, which it reads
+// and checks against the manifest entries which reference them, and optionally
+// a set of restore span entries which it restores into a temporary scratch
+// keyspace. After each file or span, it streams back its progress through the
+// metadata channel provided by DistSQL.
+type verifyBackupDataProcessor struct {
+	execinfra.ProcessorBase
+
+	flowCtx *execinfra.FlowCtx
+	spec    execinfrapb.VerifyBackupDataSpec
+	output  execinfra.RowReceiver
+
+	// cancelAndWaitForWorker cancels the producer goroutine and waits for it to
+	// finish. It can be called multiple times.
+	cancelAndWaitForWorker func()
+	progCh                 chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress
+	verifyErr              error
+
+	// BoundAccount that reserves the memory used by the files being read.
+	memAcc *mon.BoundAccount
+}
+
+var (
+	_ execinfra.Processor = &verifyBackupDataProcessor{}
+	_ execinfra.RowSource = &verifyBackupDataProcessor{}
+)
+
+func newVerifyBackupDataProcessor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.VerifyBackupDataSpec,
+	post *execinfrapb.PostProcessSpec,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	ba := flowCtx.Cfg.BackupMonitor.MakeBoundAccount()
+	vp := &verifyBackupDataProcessor{
+		flowCtx: flowCtx,
+		spec:    spec,
+		output:  output,
+		progCh:  make(chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress),
+		memAcc:  &ba,
+	}
+	if err := vp.Init(ctx, vp, post, verifyBackupOutputTypes, flowCtx, processorID, output, nil, /* memMonitor */
+		execinfra.ProcStateOpts{
+			// This processor doesn't have any inputs to drain.
+			InputsToDrain: nil,
+			TrailingMetaCallback: func() []execinfrapb.ProducerMetadata {
+				vp.close()
+				return nil
+			},
+		}); err != nil {
+		return nil, err
+	}
+	return vp, nil
+}
+
+// Start is part of the RowSource interface.
+func (vp *verifyBackupDataProcessor) Start(ctx context.Context) {
+	ctx = logtags.AddTag(ctx, "job", vp.spec.JobID)
+	ctx = vp.StartInternal(ctx, verifyBackupProcessorName)
+	ctx, cancel := context.WithCancel(ctx)
+
+	vp.cancelAndWaitForWorker = func() {
+		cancel()
+		for range vp.progCh {
+		}
+	}
+	log.Infof(ctx, "starting verification of %d backup files", len(vp.spec.Files))
+	if err := vp.flowCtx.Stopper().RunAsyncTaskEx(ctx, stop.TaskOpts{
+		TaskName: "verifyBackupDataProcessor.runVerifyBackupProcessor",
+		SpanOpt:  stop.ChildSpan,
+	}, func(ctx context.Context) {
+		vp.verifyErr = runVerifyBackupProcessor(ctx, vp.flowCtx, &vp.spec, vp.progCh, vp.memAcc)
+		cancel()
+		close(vp.progCh)
+	}); err != nil {
+		// The closure above hasn't run, so we have to do the cleanup.
+		vp.verifyErr = err
+		cancel()
+		close(vp.progCh)
+	}
+}
+
+// Next is part of the RowSource interface.
+func (vp *verifyBackupDataProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
+	if vp.State != execinfra.StateRunning {
+		return nil, vp.DrainHelper()
+	}
+
+	for prog := range vp.progCh {
+		// Take a copy so that we can send the progress address to the output
+		// processor.
+		p := prog
+		return nil, &execinfrapb.ProducerMetadata{BulkProcessorProgress: &p}
+	}
+
+	if vp.verifyErr != nil {
+		vp.MoveToDraining(vp.verifyErr)
+		return nil, vp.DrainHelper()
+	}
+
+	vp.MoveToDraining(nil /* error */)
+	return nil, vp.DrainHelper()
+}
+
+func (vp *verifyBackupDataProcessor) close() {
+	vp.cancelAndWaitForWorker()
+	if vp.InternalClose() {
+		vp.memAcc.Close(vp.Ctx)
+	}
+}
+
+// ConsumerClosed is part of the RowSource interface. We have to override the
+// implementation provided by ProcessorBase.
+func (vp *verifyBackupDataProcessor) ConsumerClosed() {
+	vp.close()
+}
+
+func runVerifyBackupProcessor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.VerifyBackupDataSpec,
+	progCh chan execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
+	memAcc *mon.BoundAccount,
+) error {
+	sendProgress := func(progDetails backuppb.VerifyProgress) error {
+		details, err := gogotypes.MarshalAny(&progDetails)
+		if err != nil {
+			return err
+		}
+		select {
+		case progCh <- execinfrapb.RemoteProducerMetadata_BulkProcessorProgress{
+			ProgressDetails: *details,
+		}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for i := range spec.Files {
+		f := &spec.Files[i]
+		prog := backuppb.VerifyProgress{Layer: f.Layer, Path: f.File.Path}
+		size, err := verifyBackupFile(ctx, flowCtx, spec, f, memAcc)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !errors.Is(err, errCorruptBackupFile) {
+				return err
+			}
+			log.Warningf(ctx, "backup file %s is corrupt: %v", f.File.Path, err)
+			prog.Error = err.Error()
+		}
+		prog.Size = size
+		if err := sendProgress(prog); err != nil {
+			return err
+		}
+	}
+
+	if len(spec.DryRunSpans) == 0 {
+		return nil
+	}
+	pkIDs := make(map[uint64]bool)
+	for _, l := range spec.Layers {
+		for id := range l.PKIDs {
+			pkIDs[id] = true
+		}
+	}
+	for _, entry := range spec.DryRunSpans {
+		prog := backuppb.VerifyProgress{DryRun: true}
+		for _, file := range entry.Files {
+			prog.DryRunPaths = append(prog.DryRunPaths, file.Path)
+		}
+		rows, err := dryRunRestoreSpanEntry(ctx, flowCtx, spec, entry, pkIDs)
+		if err != nil {
+			return errors.Wrapf(err, "dry-run restore of span %s from files %s",
+				entry.Span, strings.Join(prog.DryRunPaths, ", "))
+		}
+		prog.RowsRestored = rows
+		if err := sendProgress(prog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errCorruptBackupFile marks the errors returned by verifyBackupFile which
+// indicate that the file is corrupt, as opposed to errors which prevented the
+// file from being checked.
+var errCorruptBackupFile = errors.New("corrupt backup file")
+
+// verifyBackupFile reads a backup file from external storage and checks its
+// contents against the manifest entries that reference it. It returns the size
+// of the file.
+func verifyBackupFile(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.VerifyBackupDataSpec,
+	f *execinfrapb.VerifyBackupFileSpec,
+	memAcc *mon.BoundAccount,
+) (int64, error) {
+	dir, err := flowCtx.Cfg.ExternalStorage(ctx, f.File.Dir)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := dir.Close(); err != nil {
+			log.Warningf(ctx, "close export storage failed %v", err)
+		}
+	}()
+
+	sst, err := storageccl.ReadExternalSST(ctx,
+		storageccl.StoreFile{Store: dir, FilePath: f.File.Path}, spec.Encryption)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, err
+		}
+		return 0, errors.Mark(errors.Wrap(err, "reading file"), errCorruptBackupFile)
+	}
+	size := int64(len(sst))
+	if err := memAcc.Grow(ctx, size); err != nil {
+		return size, err
+	}
+	defer memAcc.Shrink(ctx, size)
+
+	var pkIDs map[uint64]bool
+	if int(f.Layer) < len(spec.Layers) {
+		pkIDs = spec.Layers[f.Layer].PKIDs
+	}
+	if err := checkBackupFileContents(sst, f.Entries, pkIDs); err != nil {
+		return size, errors.Mark(err, errCorruptBackupFile)
+	}
+	return size, nil
+}
+
+// checkBackupFileContents checks that the given SST is well-formed and that it
+// agrees with the manifest entries which reference it: the checksums of its
+// blocks and of its values must be valid, its keys must be in order, must fall
+// within the spans and time bounds of the entries, and the row counts of each
+// entry must match the keys the file contains for its span.
+func checkBackupFileContents(
+	sst []byte, entries []execinfrapb.VerifyBackupFileSpec_Entry, pkIDs map[uint64]bool,
+) error {
+	if len(entries) == 0 {
+		return errors.AssertionFailedf("no manifest entries reference the file")
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Span.Key.Compare(entries[j].Span.Key) < 0
+	})
+
+	if err := checkSSTBlocksAndOrdering(sst); err != nil {
+		return err
+	}
+
+	iter, err := storage.NewMemSSTIterator(sst, true /* verify */, storage.IterOptions{
+		KeyTypes:   storage.IterKeyTypePointsAndRanges,
+		LowerBound: keys.LocalMax,
+		UpperBound: keys.MaxKey,
+	})
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	defer iter.Close()
+
+	// inBounds returns whether the timestamp of a key is within the time bounds
+	// of an entry. Keys are exported with timestamps in (StartTime, EndTime].
+	inBounds := func(ts hlc.Timestamp, e *execinfrapb.VerifyBackupFileSpec_Entry) bool {
+		return e.StartTime.Less(ts) && ts.LessEq(e.EndTime)
+	}
+
+	// Each entry is checked against the rows and the distinct keys found in its
+	// span, counted the same way the backup counted them.
+	rows := make([]storage.RowCounter, len(entries))
+	sqlKeys := make([]keyCounter, len(entries))
+	var cur int
+	for iter.SeekGE(storage.MVCCKey{Key: keys.LocalMax}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return errors.Wrap(err, "iterating over file")
+		} else if !ok {
+			break
+		}
+
+		if iter.RangeKeyChanged() {
+			if _, hasRange := iter.HasPointAndRange(); hasRange {
+				rangeKeys := iter.RangeKeys()
+				e := findEntryForSpan(entries, rangeKeys.Bounds)
+				if e == nil {
+					return errors.Newf("range key %s is outside of the spans of the file", rangeKeys.Bounds)
+				}
+				for _, v := range rangeKeys.Versions {
+					if !inBounds(v.Timestamp, e) {
+						return errors.Newf("range key %s has timestamp %s outside of (%s, %s]",
+							rangeKeys.Bounds, v.Timestamp, e.StartTime, e.EndTime)
+					}
+				}
+			}
+		}
+		if hasPoint, _ := iter.HasPointAndRange(); !hasPoint {
+			continue
+		}
+
+		key := iter.UnsafeKey()
+		// Keys are in order, so the entry which covers a key is never before the
+		// one which covered the previous key. An entry's end key may be included
+		// in the file when the export paginated in the middle of the versions of
+		// a key, in which case the next entry starts at that key.
+		for cur < len(entries) {
+			if c := entries[cur].Span.EndKey.Compare(key.Key); c > 0 ||
+				(c == 0 && (cur+1 == len(entries) || entries[cur+1].Span.Key.Compare(key.Key) > 0)) {
+				break
+			}
+			cur++
+		}
+		if cur == len(entries) || key.Key.Compare(entries[cur].Span.Key) < 0 {
+			return errors.Newf("key %s is outside of the spans of the file", key)
+		}
+		if !key.Timestamp.IsEmpty() && !inBounds(key.Timestamp, &entries[cur]) {
+			return errors.Newf("key %s has a timestamp outside of (%s, %s]",
+				key, entries[cur].StartTime, entries[cur].EndTime)
+		}
+		if err := rows[cur].Count(key.Key); err != nil {
+			return errors.Wrapf(err, "decoding %s", key)
+		}
+		if err := sqlKeys[cur].count(key.Key); err != nil {
+			return errors.Wrapf(err, "decoding %s", key)
+		}
+		// Some of the versions of a key at the boundary of two entries may have
+		// been counted by the previous one.
+		if cur > 0 && entries[cur-1].Span.EndKey.Equal(key.Key) {
+			if err := sqlKeys[cur-1].count(key.Key); err != nil {
+				return errors.Wrapf(err, "decoding %s", key)
+			}
+		}
+	}
+
+	// The row counts recorded for an entry can exceed the number of rows in its
+	// span, since an entry can be made of several contiguous export responses
+	// and a row whose keys were split between two of them is counted by each.
+	// Every row counted by a response has at least one key in it though, so the
+	// counts can never exceed the number of distinct keys in the span, nor be
+	// lower than the number of rows.
+	for i := range entries {
+		expected := entries[i].EntryCounts
+		found := countRows(rows[i].BulkOpSummary, pkIDs)
+		if found.Rows > expected.Rows || found.IndexEntries > expected.IndexEntries {
+			return errors.Newf("span %s contains %d rows and %d index entries, but the manifest records %d rows and %d index entries",
+				entries[i].Span, found.Rows, found.IndexEntries, expected.Rows, expected.IndexEntries)
+		}
+		upper := countRows(sqlKeys[i].BulkOpSummary, pkIDs)
+		if expected.Rows > upper.Rows || expected.IndexEntries > upper.IndexEntries {
+			return errors.Newf("span %s contains %d rows and %d index entries in %d and %d keys, but the manifest records %d rows and %d index entries",
+				entries[i].Span, found.Rows, found.IndexEntries, upper.Rows, upper.IndexEntries,
+				expected.Rows, expected.IndexEntries)
+		}
+	}
+	return nil
+}
+
+// keyCounter counts the distinct keys of SQL rows shown to it, ignoring their
+// versions. Its counts are an upper bound of the counts of a storage.RowCounter
+// shown the same keys.
+type keyCounter struct {
+	roachpb.BulkOpSummary
+	prev roachpb.Key
+}
+
+func (k *keyCounter) count(key roachpb.Key) error {
+	// Ignore non-SQL keys, the same way storage.RowCounter does.
+	row, err := keys.EnsureSafeSplitKey(key)
+	if err != nil || len(key) == len(row) {
+		return nil //nolint:returnerrcheck
+	}
+	if key.Equal(k.prev) {
+		return nil
+	}
+	k.prev = append(k.prev[:0], key...)
+
+	rem, _, err := keys.DecodeTenantPrefix(row)
+	if err != nil {
+		return err
+	}
+	_, tableID, indexID, err := keys.DecodeTableIDIndexID(rem)
+	if err != nil {
+		return err
+	}
+	if k.EntryCounts == nil {
+		k.EntryCounts = make(map[uint64]int64)
+	}
+	k.EntryCounts[roachpb.BulkOpSummaryID(uint64(tableID), uint64(indexID))]++
+	return nil
+}
+
+// checkSSTBlocksAndOrdering validates the checksums of all the blocks of the
+// given SST and checks that its point keys are strictly increasing.
+func checkSSTBlocksAndOrdering(sst []byte) error {
+	r, err := sstable.NewReader(vfs.NewMemFile(sst), sstable.ReaderOptions{
+		Comparer: storage.EngineComparer,
+	})
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	defer r.Close()
+
+	if err := r.ValidateBlockChecksums(); err != nil {
+		return errors.Wrap(err, "validating block checksums")
+	}
+
+	iter, err := r.NewIter(nil /* lower */, nil /* upper */)
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	var prev []byte
+	for k, _ := iter.First(); k != nil; k, _ = iter.Next() {
+		if prev != nil && storage.EngineComparer.Compare(prev, k.UserKey) >= 0 {
+			_ = iter.Close()
+			return errors.Newf("keys out of order: %s is not before %s",
+				storage.EngineComparer.FormatKey(prev), storage.EngineComparer.FormatKey(k.UserKey))
+		}
+		prev = append(prev[:0], k.UserKey...)
+	}
+	if err := iter.Error(); err != nil {
+		_ = iter.Close()
+		return errors.Wrap(err, "iterating over file")
+	}
+	return iter.Close()
+}
+
+// findEntryForSpan returns the entry whose span contains the given span, or nil
+// if there is none.
+func findEntryForSpan(
+	entries []execinfrapb.VerifyBackupFileSpec_Entry, sp roachpb.Span,
+) *execinfrapb.VerifyBackupFileSpec_Entry {
+	for i := range entries {
+		if entries[i].Span.Contains(sp) {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// dryRunRestoreSpanEntry restores the files of the given entry as of the
+// restore time into the scratch keyspace of the job, and reads the restored data
+// back to check it. Each key is written under the table prefix of the scratch
+// ID, followed by its original key. It returns the number of rows which were
+// restored.
+func dryRunRestoreSpanEntry(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.VerifyBackupDataSpec,
+	entry execinfrapb.RestoreSpanEntry,
+	pkIDs map[uint64]bool,
+) (int64, error) {
+	db := flowCtx.Cfg.DB
+	scratchPrefix := flowCtx.Codec().TablePrefix(uint32(spec.ScratchID))
+	scratchKey := func(key roachpb.Key) roachpb.Key {
+		return append(append(roachpb.Key(nil), scratchPrefix...), key...)
+	}
+	scratchSpan := roachpb.Span{Key: scratchKey(entry.Span.Key), EndKey: scratchKey(entry.Span.EndKey)}
+	defer func() {
+		if err := clearScratchSpan(ctx, db, scratchSpan); err != nil {
+			log.Warningf(ctx, "clearing scratch keyspace failed %v", err)
+		}
+	}()
+
+	storeFiles := make([]storageccl.StoreFile, 0, len(entry.Files))
+	defer func() {
+		for _, sf := range storeFiles {
+			if err := sf.Store.Close(); err != nil {
+				log.Warningf(ctx, "close export storage failed %v", err)
+			}
+		}
+	}()
+	for _, file := range entry.Files {
+		dir, err := flowCtx.Cfg.ExternalStorage(ctx, file.Dir)
+		if err != nil {
+			return 0, err
+		}
+		storeFiles = append(storeFiles, storageccl.StoreFile{Store: dir, FilePath: file.Path})
+	}
+	iterOpts := storage.IterOptions{
+		RangeKeyMaskingBelow: spec.RestoreTime,
+		KeyTypes:             storage.IterKeyTypePointsAndRanges,
+		LowerBound:           keys.LocalMax,
+		UpperBound:           keys.MaxKey,
+	}
+	iter, err := storageccl.ExternalSSTReader(ctx, storeFiles, spec.Encryption, iterOpts)
+	if err != nil {
+		return 0, err
+	}
+	readAsOfIter := storage.NewReadAsOfIterator(iter, spec.RestoreTime)
+	defer readAsOfIter.Close()
+
+	const bufferSize = 16 << 20
+	adder, err := flowCtx.Cfg.BulkAdder(ctx, db, db.Clock().Now(), kvserverbase.BulkAdderOptions{
+		Name:                  "verify-backup-dry-run",
+		MinBufferSize:         bufferSize,
+		WriteAtBatchTimestamp: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer adder.Close(ctx)
+
+	startKeyMVCC, endKeyMVCC := storage.MVCCKey{Key: entry.Span.Key},
+		storage.MVCCKey{Key: entry.Span.EndKey}
+	for readAsOfIter.SeekGE(startKeyMVCC); ; readAsOfIter.NextKey() {
+		ok, err := readAsOfIter.Valid()
+		if err != nil {
+			return 0, err
+		}
+		if !ok || !readAsOfIter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		key := readAsOfIter.UnsafeKey()
+		value := roachpb.Value{RawBytes: append([]byte(nil), readAsOfIter.UnsafeValue()...)}
+		if err := value.Verify(key.Key); err != nil {
+			return 0, err
+		}
+		k := scratchKey(key.Key)
+		value.ClearChecksum()
+		value.InitChecksum(k)
+		if err := adder.Add(ctx, k, value.RawBytes); err != nil {
+			return 0, err
+		}
+	}
+	if err := adder.Flush(ctx); err != nil {
+		return 0, err
+	}
+
+	var counter storage.RowCounter
+	const pageSize = 10000
+	if err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		counter = storage.RowCounter{}
+		return txn.Iterate(ctx, scratchSpan.Key, scratchSpan.EndKey, pageSize,
+			func(kvs []kv.KeyValue) error {
+				for _, restored := range kvs {
+					if err := restored.Value.Verify(restored.Key); err != nil {
+						return errors.Wrap(err, "reading back restored data")
+					}
+					if err := counter.Count(restored.Key[len(scratchPrefix):]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	}); err != nil {
+		return 0, err
+	}
+	return countRows(counter.BulkOpSummary, pkIDs).Rows, nil
+}
+
+// clearScratchSpan removes the keys written by a dry-run restore in the given
+// span of the scratch keyspace.
+func clearScratchSpan(ctx context.Context, db *kv.DB, sp roachpb.Span) error {
+	b := &kv.Batch{}
+	b.AddRawRequest(&roachpb.ClearRangeRequest{
+		RequestHeader: roachpb.RequestHeader{Key: sp.Key, EndKey: sp.EndKey},
+	})
+	return db.Run(ctx, b)
+}
+
+func init() {
+	rowexec.NewVerifyBackupDataProcessor = newVerifyBackupDataProcessor
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// distVerifyBackup plans a one stage distSQL flow which verifies the given
+// backup files, and optionally dry-run restores the given spans, distributing
+// them in a round-robin fashion across all the nodes of the cluster. It streams
+// back progress updates over the given progCh, which it closes.
+func distVerifyBackup(
+	ctx context.Context,
+	execCtx sql.JobExecContext,
+	jobID int64,
+	files []execinfrapb.VerifyBackupFileSpec,
+	layers []execinfrapb.VerifyBackupDataSpec_Layer,
+	dryRunSpans []execinfrapb.RestoreSpanEntry,
+	restoreTime hlc.Timestamp,
+	scratchID descpb.ID,
+	encryption *jobspb.BackupEncryptionOptions,
+	kmsEnv cloud.KMSEnv,
+	progCh chan *execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
+) error {
+	defer close(progCh)
+	ctx, span := tracing.ChildSpan(ctx, "backupccl.distVerifyBackup")
+	defer span.Finish()
+	var noTxn *kv.Txn
+
+	if encryption != nil && encryption.Mode == jobspb.EncryptionMode_KMS {
+		kms, err := cloud.KMSFromURI(ctx, encryption.KMSInfo.Uri, kmsEnv)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err := kms.Close()
+			if err != nil {
+				log.Infof(ctx, "failed to close KMS: %+v", err)
+			}
+		}()
+
+		encryption.Key, err = kms.Decrypt(ctx, encryption.KMSInfo.EncryptedDataKey)
+		if err != nil {
+			return errors.Wrap(err,
+				"failed to decrypt data key before starting VerifyBackupDataProcessor")
+		}
+	}
+	var fileEncryption *roachpb.FileEncryptionOptions
+	if encryption != nil {
+		fileEncryption = &roachpb.FileEncryptionOptions{Key: encryption.Key}
+	}
+
+	dsp := execCtx.DistSQLPlanner()
+	evalCtx := execCtx.ExtendedEvalContext()
+	planCtx, sqlInstanceIDs, err := dsp.SetupAllNodesPlanning(ctx, evalCtx, execCtx.ExecCfg())
+	if err != nil {
+		return err
+	}
+
+	specs := makeVerifyBackupDataSpecs(sqlInstanceIDs, jobID, files, layers, dryRunSpans,
+		restoreTime, scratchID, fileEncryption)
+	if len(specs) == 0 {
+		return nil
+	}
+
+	// Setup a one-stage plan with one proc per input spec.
+	corePlacement := make([]physicalplan.ProcessorCorePlacement, 0, len(specs))
+	for _, sqlInstanceID := range sqlInstanceIDs {
+		if spec, ok := specs[sqlInstanceID]; ok {
+			corePlacement = append(corePlacement, physicalplan.ProcessorCorePlacement{
+				SQLInstanceID: sqlInstanceID,
+				Core:          execinfrapb.ProcessorCoreUnion{VerifyBackupData: spec},
+			})
+		}
+	}
+
+	p := planCtx.NewPhysicalPlan()
+	// All of the progress information is sent through the metadata stream, so we
+	// have an empty result stream.
+	p.AddNoInputStage(corePlacement, execinfrapb.PostProcessSpec{}, []*types.T{}, execinfrapb.Ordering{})
+	p.PlanToStreamColMap = []int{}
+
+	dsp.FinalizePlan(planCtx, p)
+
+	metaFn := func(_ context.Context, meta *execinfrapb.ProducerMetadata) error {
+		if meta.BulkProcessorProgress != nil {
+			progCh <- meta.BulkProcessorProgress
+		}
+		return nil
+	}
+
+	rowResultWriter := sql.NewRowResultWriter(nil)
+
+	recv := sql.MakeDistSQLReceiver(
+		ctx,
+		sql.NewMetadataCallbackWriter(rowResultWriter, metaFn),
+		tree.Rows,
+		nil,   /* rangeCache */
+		noTxn, /* txn - the flow does not read or write the database */
+		nil,   /* clockUpdater */
+		evalCtx.Tracing,
+		evalCtx.ExecCfg.ContentionRegistry,
+		nil, /* testingPushCallback */
+	)
+	defer recv.Release()
+
+	// Copy the evalCtx, as dsp.Run() might change it.
+	evalCtxCopy := *evalCtx
+	dsp.Run(ctx, planCtx, noTxn, p, recv, &evalCtxCopy, nil /* finishedSetupFn */)
+	return rowResultWriter.Err()
+}
+
+// makeVerifyBackupDataSpecs returns a map from SQL instance ID to the
+// VerifyBackupData spec that should be planned on that node. The files to
+// verify and the spans to dry-run restore are distributed in a round-robin
+// fashion amongst the given nodes.
+func makeVerifyBackupDataSpecs(
+	sqlInstanceIDs []base.SQLInstanceID,
+	jobID int64,
+	files []execinfrapb.VerifyBackupFileSpec,
+	layers []execinfrapb.VerifyBackupDataSpec_Layer,
+	dryRunSpans []execinfrapb.RestoreSpanEntry,
+	restoreTime hlc.Timestamp,
+	scratchID descpb.ID,
+	encryption *roachpb.FileEncryptionOptions,
+) map[base.SQLInstanceID]*execinfrapb.VerifyBackupDataSpec {
+	specsBySQLInstanceID := make(map[base.SQLInstanceID]*execinfrapb.VerifyBackupDataSpec)
+	specFor := func(i int) *execinfrapb.VerifyBackupDataSpec {
+		sqlInstanceID := sqlInstanceIDs[i%len(sqlInstanceIDs)]
+		spec, ok := specsBySQLInstanceID[sqlInstanceID]
+		if !ok {
+			spec = &execinfrapb.VerifyBackupDataSpec{
+				JobID:       jobID,
+				Encryption:  encryption,
+				Layers:      layers,
+				RestoreTime: restoreTime,
+				ScratchID:   scratchID,
+			}
+			specsBySQLInstanceID[sqlInstanceID] = spec
+		}
+		return spec
+	}
+	for i := range files {
+		spec := specFor(i)
+		spec.Files = append(spec.Files, files[i])
+	}
+	for i := range dryRunSpans {
+		spec := specFor(i)
+		spec.DryRunSpans = append(spec.DryRunSpans, dryRunSpans[i])
+	}
+	return specsBySQLInstanceID
+}
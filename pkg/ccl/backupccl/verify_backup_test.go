@@ -0,0 +1,157 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBackupFileContents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const tableID, indexID = 100, 1
+	pkIDs := map[uint64]bool{roachpb.BulkOpSummaryID(tableID, indexID): true}
+	indexPrefix := keys.SystemSQLCodec.IndexPrefix(tableID, indexID)
+	familyKey := func(i int64, family uint32) roachpb.Key {
+		k := encoding.EncodeVarintAscending(append(roachpb.Key(nil), indexPrefix...), i)
+		return keys.MakeFamilyKey(k, family)
+	}
+	rowKey := func(i int64) roachpb.Key {
+		return familyKey(i, 0)
+	}
+
+	// Write an SST with 3 rows at a timestamp of 5. The first row has a second
+	// column family, so that the file contains 4 distinct keys.
+	const numRows, numKeys = 3, 4
+	ts := hlc.Timestamp{WallTime: 5}
+	sstFile := &storage.MemFile{}
+	w := storage.MakeBackupSSTWriter(context.Background(), cluster.MakeTestingClusterSettings(), sstFile)
+	put := func(k roachpb.Key) {
+		key := storage.MVCCKey{Key: k, Timestamp: ts}
+		value := roachpb.MakeValueFromString("value")
+		value.InitChecksum(key.Key)
+		require.NoError(t, w.Put(key, value.RawBytes))
+	}
+	put(familyKey(1, 0))
+	put(familyKey(1, 1))
+	for i := int64(2); i <= numRows; i++ {
+		put(rowKey(i))
+	}
+	require.NoError(t, w.Finish())
+	w.Close()
+	sst := sstFile.Data()
+
+	spanEntry := func(
+		startKey, endKey roachpb.Key, start, end int64, rows int64,
+	) execinfrapb.VerifyBackupFileSpec_Entry {
+		return execinfrapb.VerifyBackupFileSpec_Entry{
+			Span:        roachpb.Span{Key: startKey, EndKey: endKey},
+			EntryCounts: roachpb.RowCount{Rows: rows},
+			StartTime:   hlc.Timestamp{WallTime: start},
+			EndTime:     hlc.Timestamp{WallTime: end},
+		}
+	}
+	entry := func(
+		endKey roachpb.Key, start, end int64, rows int64,
+	) execinfrapb.VerifyBackupFileSpec_Entry {
+		return spanEntry(indexPrefix, endKey, start, end, rows)
+	}
+	corrupted := append([]byte(nil), sst...)
+	corrupted[len(corrupted)/3] ^= 0xff
+
+	for _, tc := range []struct {
+		name    string
+		sst     []byte
+		entries []execinfrapb.VerifyBackupFileSpec_Entry
+		err     string
+	}{
+		{
+			name:    "valid",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 0, 10, numRows)},
+		},
+		{
+			// A row split between two export responses is counted by each of them.
+			name:    "manifest double-counts a split row",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 0, 10, numKeys)},
+		},
+		{
+			name:    "manifest over-counts rows",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 0, 10, numKeys+1)},
+			err:     "contains 3 rows and 0 index entries in 4 and 0 keys, but the manifest records 5 rows",
+		},
+		{
+			name:    "manifest under-counts rows",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 0, 10, numRows-1)},
+			err:     "contains 3 rows and 0 index entries, but the manifest records 2 rows",
+		},
+		{
+			name: "valid per-span counts",
+			sst:  sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{
+				spanEntry(indexPrefix, rowKey(2), 0, 10, 1),
+				spanEntry(rowKey(2), indexPrefix.PrefixEnd(), 0, 10, 2),
+			},
+		},
+		{
+			// The totals match, but the rows are recorded against the wrong span.
+			name: "per-span counts mismatch",
+			sst:  sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{
+				spanEntry(indexPrefix, rowKey(2), 0, 10, 0),
+				spanEntry(rowKey(2), indexPrefix.PrefixEnd(), 0, 10, 3),
+			},
+			err: "contains 1 rows and 0 index entries, but the manifest records 0 rows",
+		},
+		{
+			name:    "key outside of spans",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(rowKey(2), 0, 10, numRows)},
+			err:     "is outside of the spans of the file",
+		},
+		{
+			name:    "key outside of time bounds",
+			sst:     sst,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 5, 10, numRows)},
+			err:     "has a timestamp outside of",
+		},
+		{
+			name:    "corrupted bytes",
+			sst:     corrupted,
+			entries: []execinfrapb.VerifyBackupFileSpec_Entry{entry(indexPrefix.PrefixEnd(), 0, 10, numRows)},
+			err:     ".*",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBackupFileContents(tc.sst, tc.entries, pkIDs)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Regexp(t, tc.err, err.Error())
+			}
+		})
+	}
+}
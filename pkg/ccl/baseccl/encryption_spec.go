@@ -35,6 +35,7 @@ type StoreEncryptionSpec struct {
 	KeyPath        string
 	OldKeyPath     string
 	RotationPeriod time.Duration
+	CipherMode     EncryptionCipherMode
 }
 
 // Convert to a serialized EncryptionOptions protobuf.
@@ -46,6 +47,7 @@ func (es StoreEncryptionSpec) toEncryptionOptions() ([]byte, error) {
 			OldKey:     es.OldKeyPath,
 		},
 		DataKeyRotationPeriod: int64(es.RotationPeriod / time.Second),
+		CipherMode:            es.CipherMode,
 	}
 
 	return protoutil.Marshal(&opts)
@@ -54,8 +56,8 @@ func (es StoreEncryptionSpec) toEncryptionOptions() ([]byte, error) {
 // String returns a fully parsable version of the encryption spec.
 func (es StoreEncryptionSpec) String() string {
 	// All fields are set.
-	return fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s",
-		es.Path, es.KeyPath, es.OldKeyPath, es.RotationPeriod)
+	return fmt.Sprintf("path=%s,key=%s,old-key=%s,rotation-period=%s,cipher-mode=%s",
+		es.Path, es.KeyPath, es.OldKeyPath, es.RotationPeriod, strings.ToLower(es.CipherMode.String()))
 }
 
 // NewStoreEncryptionSpec parses the string passed in and returns a new
@@ -122,6 +124,12 @@ func NewStoreEncryptionSpec(value string) (StoreEncryptionSpec, error) {
 			if err != nil {
 				return StoreEncryptionSpec{}, errors.Wrapf(err, "could not parse rotation-duration value: %s", value)
 			}
+		case "cipher-mode":
+			mode, ok := EncryptionCipherMode_value[strings.ToUpper(value)]
+			if !ok {
+				return StoreEncryptionSpec{}, fmt.Errorf("unknown cipher-mode value: %s (valid values: ctr, gcm)", value)
+			}
+			es.CipherMode = EncryptionCipherMode(mode)
 		default:
 			return StoreEncryptionSpec{}, fmt.Errorf("%s is not a valid enterprise-encryption field", field)
 		}
@@ -467,6 +467,8 @@ func TestAvroSchema(t *testing.T) {
 			`TIMETZ`:            `["null","string"]`,
 			`TIMESTAMP`:         `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
 			`TIMESTAMPTZ`:       `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
+			`TSQUERY`:           `["null","string"]`,
+			`TSVECTOR`:          `["null","string"]`,
 			`UUID`:              `["null","string"]`,
 			`VARBIT`:            `["null",{"type":"array","items":"long"}]`,
 
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedvalidators"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -490,6 +491,13 @@ func createChangefeedJobRecord(
 	if err != nil {
 		return nil, err
 	}
+	if encodingOpts.Format == changefeedbase.OptFormatParquet &&
+		!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ChangefeedParquetFormat) {
+		return nil, errors.Newf(
+			`%s=%s is not supported until upgrade to version %s or higher is finalized`,
+			changefeedbase.OptFormat, changefeedbase.OptFormatParquet,
+			clusterversion.ChangefeedParquetFormat.String())
+	}
 	if _, err := getEncoder(encodingOpts, AllTargets(details)); err != nil {
 		return nil, err
 	}
@@ -501,7 +509,9 @@ func createChangefeedJobRecord(
 	//   DELETEs there is no way to recover which key was deleted. We could make
 	//   the user explicitly pass this option for every cloud storage sink/
 	//   webhook sink and error if they don't, but that seems user-hostile for
-	//   insufficient reason.
+	//   insufficient reason. The kinesis sink only uses the key to derive the
+	//   partition key of its records, so it is in the same situation, and so is
+	//   the nats sink, whose messages have no key.
 	//   This is the same for the topic and webhook sink, which uses
 	//   `topic_in_value` to embed the topic in the value by default, since it
 	//   has no other avenue to express the topic.
@@ -509,7 +519,8 @@ func createChangefeedJobRecord(
 	//   the default error to avoid claiming the user set an option they didn't
 	//   explicitly set. Fortunately we know the only way to cause this is to
 	//   set envelope.
-	if (isCloudStorageSink(parsedSink) || isWebhookSink(parsedSink)) &&
+	if (isCloudStorageSink(parsedSink) || isWebhookSink(parsedSink) || isKinesisSink(parsedSink) ||
+		isNATSSink(parsedSink)) &&
 		encodingOpts.Envelope != changefeedbase.OptEnvelopeBare {
 		if err = opts.ForceKeyInValue(); err != nil {
 			return nil, errors.Errorf(`this sink is incompatible with envelope=%s`, encodingOpts.Envelope)
@@ -800,6 +811,7 @@ func changefeedJobDescription(
 ) (string, error) {
 	cleanedSinkURI, err := cloud.SanitizeExternalStorageURI(sinkURI, []string{
 		changefeedbase.SinkParamSASLPassword,
+		changefeedbase.SinkParamAuthToken,
 		changefeedbase.SinkParamCACert,
 		changefeedbase.SinkParamClientCert,
 	})
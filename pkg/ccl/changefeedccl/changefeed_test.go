@@ -2543,6 +2543,115 @@ func TestChangefeedBareJSON(t *testing.T) {
 	cdcTest(t, testFn, feedTestForceSink("cloudstorage"))
 }
 
+func TestChangefeedParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'initial')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH format=parquet, diff, resolved`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: ->{"__crdb__event_type": "c", "a": 0, "b": "initial"}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a'), (2, 'b')`)
+		assertPayloads(t, foo, []string{
+			`foo: ->{"__crdb__event_type": "c", "a": 1, "b": "a"}`,
+			`foo: ->{"__crdb__event_type": "c", "a": 2, "b": "b"}`,
+		})
+
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (2, 'c'), (3, 'd')`)
+		assertPayloads(t, foo, []string{
+			`foo: ->{"__crdb__event_type": "u", "a": 2, "b": "c"}`,
+			`foo: ->{"__crdb__event_type": "c", "a": 3, "b": "d"}`,
+		})
+
+		// Deleted rows only contain the primary key columns.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: ->{"__crdb__event_type": "d", "a": 1}`,
+		})
+
+		// Resolved timestamps are written in their own files, after the files
+		// holding the rows they cover.
+		var ts string
+		sqlDB.QueryRow(t, `UPDATE foo SET b = 'e' WHERE a = 3 RETURNING cluster_logical_timestamp()`).Scan(&ts)
+		assertPayloads(t, foo, []string{
+			`foo: ->{"__crdb__event_type": "u", "a": 3, "b": "e"}`,
+		})
+		updated := parseTimeToHLC(t, ts)
+		for {
+			if resolved, _ := expectResolvedTimestamp(t, foo); updated.LessEq(resolved) {
+				break
+			}
+		}
+	}
+	cdcTest(t, testFn, feedTestForceSink("cloudstorage"))
+}
+
+func TestChangefeedParquetCompression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, s TestServer, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(s.DB)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'dog'), (1, NULL)`)
+
+		for _, compression := range []string{`gzip`, `zstd`} {
+			t.Run(compression, func(t *testing.T) {
+				foo := feed(t, f, fmt.Sprintf(`CREATE CHANGEFEED FOR foo WITH format=parquet, compression=%s`, compression))
+				defer closeFeed(t, foo)
+				// Without the diff option, updates are reported as inserts.
+				assertPayloads(t, foo, []string{
+					`foo: ->{"__crdb__event_type": "c", "a": 0, "b": "dog"}`,
+					`foo: ->{"__crdb__event_type": "c", "a": 1}`,
+				})
+				sqlDB.Exec(t, `UPDATE foo SET b = 'cat' WHERE a = 0`)
+				assertPayloads(t, foo, []string{
+					`foo: ->{"__crdb__event_type": "c", "a": 0, "b": "cat"}`,
+				})
+				sqlDB.Exec(t, `UPDATE foo SET b = 'dog' WHERE a = 0`)
+			})
+		}
+	}
+	cdcTest(t, testFn, feedTestForceSink("cloudstorage"))
+}
+
+// TestChangefeedParquetMixedVersion verifies that the parquet format is
+// rejected until every node is running a version that can encode it.
+func TestChangefeedParquetMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Settings: cluster.MakeTestingClusterSettingsWithVersions(
+			clusterversion.TestingBinaryVersion,
+			clusterversion.ByKey(clusterversion.ChangefeedParquetFormat-1),
+			false, /* initializeVersion */
+		),
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.ChangefeedParquetFormat - 1),
+				DisableAutomaticVersionUpgrade: make(chan struct{}),
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.ExecMultiple(t, strings.Split(serverSetupStatements, ";")...)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.ExpectErr(t, `format=parquet is not supported until upgrade to version`,
+		`CREATE CHANGEFEED FOR foo INTO 'nodelocal://0/bar' WITH format=parquet`)
+}
+
 func TestChangefeedAvroNotice(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -4218,6 +4327,17 @@ func TestChangefeedErrors(t *testing.T) {
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
 		`experimental-nodelocal://0/bar`,
 	)
+	sqlDB.ExpectErr(
+		t, `topic_in_value is not supported with format=parquet`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet', topic_in_value`,
+		`experimental-nodelocal://0/bar`,
+	)
+
+	// Only the cloudStorageSink supports the parquet format.
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=parquet`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet'`, `kafka://nope`,
+	)
 
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
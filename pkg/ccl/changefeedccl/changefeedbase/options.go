@@ -141,9 +141,10 @@ const (
 	OptEnvelopeWrapped       EnvelopeType = `wrapped`
 	OptEnvelopeBare          EnvelopeType = `bare`
 
-	OptFormatJSON FormatType = `json`
-	OptFormatAvro FormatType = `avro`
-	OptFormatCSV  FormatType = `csv`
+	OptFormatJSON    FormatType = `json`
+	OptFormatAvro    FormatType = `avro`
+	OptFormatCSV     FormatType = `csv`
+	OptFormatParquet FormatType = `parquet`
 
 	OptOnErrorFail  OnErrorType = `fail`
 	OptOnErrorPause OnErrorType = `pause`
@@ -159,11 +160,19 @@ const (
 	// OptKafkaSinkConfig is a JSON configuration for kafka sink (kafkaSinkConfig).
 	OptKafkaSinkConfig   = `kafka_sink_config`
 	OptWebhookSinkConfig = `webhook_sink_config`
+	// OptKinesisSinkConfig is a JSON configuration for kinesis sink
+	// (kinesisSinkConfig).
+	OptKinesisSinkConfig = `kinesis_sink_config`
+	// OptPulsarSinkConfig and OptNATSSinkConfig are JSON configurations for
+	// the pulsar and nats sinks (messageBusSinkConfig).
+	OptPulsarSinkConfig = `pulsar_sink_config`
+	OptNATSSinkConfig   = `nats_sink_config`
 
 	// OptSink allows users to alter the Sink URI of an existing changefeed.
 	// Note that this option is only allowed for alter changefeed statements.
 	OptSink = `sink`
 
+	SinkParamAuthToken              = `auth_token`
 	SinkParamCACert                 = `ca_cert`
 	SinkParamClientCert             = `client_cert`
 	SinkParamClientKey              = `client_key`
@@ -184,7 +193,11 @@ const (
 	SinkSchemeHTTP                  = `http`
 	SinkSchemeHTTPS                 = `https`
 	SinkSchemeKafka                 = `kafka`
+	SinkSchemeKinesis               = `kinesis`
+	SinkSchemeNATS                  = `nats`
 	SinkSchemeNull                  = `null`
+	SinkSchemePulsarHTTP            = `pulsar-http`
+	SinkSchemePulsarHTTPS           = `pulsar-https`
 	SinkSchemeWebhookHTTP           = `webhook-http`
 	SinkSchemeWebhookHTTPS          = `webhook-https`
 	SinkSchemeExternalConnection    = `external`
@@ -289,7 +302,7 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptCursor:                   timestampOption,
 	OptEndTime:                  timestampOption,
 	OptEnvelope:                 enum("row", "key_only", "wrapped", "deprecated_row", "bare"),
-	OptFormat:                   enum("json", "avro", "csv", "experimental_avro"),
+	OptFormat:                   enum("json", "avro", "csv", "parquet", "experimental_avro"),
 	OptFullTableName:            flagOption,
 	OptKeyInValue:               flagOption,
 	OptTopicInValue:             flagOption,
@@ -308,6 +321,9 @@ var ChangefeedOptionExpectValues = map[string]OptionPermittedValues{
 	OptProtectDataFromGCOnPause: flagOption,
 	OptKafkaSinkConfig:          jsonOption,
 	OptWebhookSinkConfig:        jsonOption,
+	OptKinesisSinkConfig:        jsonOption,
+	OptPulsarSinkConfig:         jsonOption,
+	OptNATSSinkConfig:           jsonOption,
 	OptWebhookAuthHeader:        stringOption,
 	OptWebhookClientTimeout:     durationOption,
 	OptOnError:                  enum("pause", "fail"),
@@ -338,6 +354,15 @@ var CloudStorageValidOptions = makeStringSet(OptCompression)
 // WebhookValidOptions is options exclusive to webhook sink
 var WebhookValidOptions = makeStringSet(OptWebhookAuthHeader, OptWebhookClientTimeout, OptWebhookSinkConfig)
 
+// KinesisValidOptions is options exclusive to Kinesis sink
+var KinesisValidOptions = makeStringSet(OptKinesisSinkConfig)
+
+// PulsarValidOptions is options exclusive to Pulsar sink
+var PulsarValidOptions = makeStringSet(OptPulsarSinkConfig)
+
+// NATSValidOptions is options exclusive to NATS sink
+var NATSValidOptions = makeStringSet(OptNATSSinkConfig)
+
 // PubsubValidOptions is options exclusive to pubsub sink
 var PubsubValidOptions = makeStringSet()
 
@@ -710,6 +735,18 @@ func (e EncodingOptions) Validate() error {
 			OptEnvelope, OptEnvelopeRow, OptFormat, OptFormatAvro,
 		)
 	}
+	if e.Format == OptFormatParquet {
+		if e.Envelope != OptEnvelopeWrapped && e.Envelope != OptEnvelopeBare {
+			return errors.Errorf(`%s=%s is not supported with %s=%s`,
+				OptEnvelope, e.Envelope, OptFormat, OptFormatParquet,
+			)
+		}
+		if e.TopicInValue {
+			return errors.Errorf(`%s is not supported with %s=%s`,
+				OptTopicInValue, OptFormat, OptFormatParquet,
+			)
+		}
+	}
 	if e.Envelope != OptEnvelopeWrapped && e.Format != OptFormatJSON {
 		requiresWrap := []struct {
 			k string
@@ -807,6 +844,24 @@ func (s StatementOptions) GetKafkaConfigJSON() SinkSpecificJSONConfig {
 	return s.getJSONValue(OptKafkaSinkConfig)
 }
 
+// GetKinesisConfigJSON returns arbitrary json to be interpreted
+// by the kinesis sink.
+func (s StatementOptions) GetKinesisConfigJSON() SinkSpecificJSONConfig {
+	return s.getJSONValue(OptKinesisSinkConfig)
+}
+
+// GetPulsarConfigJSON returns arbitrary json to be interpreted
+// by the pulsar sink.
+func (s StatementOptions) GetPulsarConfigJSON() SinkSpecificJSONConfig {
+	return s.getJSONValue(OptPulsarSinkConfig)
+}
+
+// GetNATSConfigJSON returns arbitrary json to be interpreted
+// by the nats sink.
+func (s StatementOptions) GetNATSConfigJSON() SinkSpecificJSONConfig {
+	return s.getJSONValue(OptNATSSinkConfig)
+}
+
 // GetResolvedTimestampInterval gets the best-effort interval at which resolved timestamps
 // should be emitted. Nil or 0 means emit as often as possible. False means do not emit at all.
 // Returns an error for negative or invalid duration value.
@@ -51,6 +51,11 @@ func getEncoder(
 		return newConfluentAvroEncoder(opts, targets)
 	case changefeedbase.OptFormatCSV:
 		return newCSVEncoder(opts), nil
+	case changefeedbase.OptFormatParquet:
+		// Rows are encoded by the sink in the parquet format (see
+		// SinkWithEncoder). The encoder is only used for resolved timestamps,
+		// which are written as JSON.
+		return makeJSONEncoder(opts)
 	default:
 		return nil, errors.AssertionFailedf(`unknown format: %s`, opts.Format)
 	}
@@ -60,6 +60,10 @@ type kvEventToRowConsumer struct {
 
 	topicDescriptorCache map[TopicIdentifier]TopicDescriptor
 	topicNamer           *TopicNamer
+
+	// encodingFormat is the format in which rows are encoded. Rows encoded in
+	// the parquet format are encoded by the sink rather than by the encoder.
+	encodingFormat changefeedbase.FormatType
 }
 
 func newEventConsumer(
@@ -158,6 +162,11 @@ func newKVEventToRowConsumer(
 		return nil, err
 	}
 
+	encodingOpts, err := details.Opts.GetEncodingOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	var evaluator *cdceval.Evaluator
 	var safeExpr string
 	if expr.Expr != "" {
@@ -184,6 +193,7 @@ func newKVEventToRowConsumer(
 		topicNamer:           topicNamer,
 		evaluator:            evaluator,
 		safeExpr:             safeExpr,
+		encodingFormat:       encodingOpts.Format,
 	}, nil
 }
 
@@ -287,6 +297,10 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, ev kvevent.Even
 		return nil
 	}
 
+	if c.encodingFormat == changefeedbase.OptFormatParquet {
+		return c.encodeAndEmitForParquet(ctx, ev, updatedRow, prevRow, topic, schemaTimestamp, mvccTimestamp)
+	}
+
 	evCtx := eventContext{
 		updated: schemaTimestamp,
 		mvcc:    mvccTimestamp,
@@ -336,6 +350,40 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, ev kvevent.Even
 	return nil
 }
 
+// encodeAndEmitForParquet hands the row over to the sink, which is responsible
+// for encoding it: rows in the parquet format can't be encoded independently
+// of the file they end up in.
+func (c *kvEventToRowConsumer) encodeAndEmitForParquet(
+	ctx context.Context,
+	ev kvevent.Event,
+	updatedRow cdcevent.Row,
+	prevRow cdcevent.Row,
+	topic TopicDescriptor,
+	updated, mvcc hlc.Timestamp,
+) error {
+	sinkWithEncoder, ok := c.sink.(SinkWithEncoder)
+	if !ok {
+		return errors.AssertionFailedf("expected a sink which encodes rows for %s=%s, found %T",
+			changefeedbase.OptFormat, c.encodingFormat, c.sink)
+	}
+
+	if c.knobs.BeforeEmitRow != nil {
+		if err := c.knobs.BeforeEmitRow(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := sinkWithEncoder.EncodeAndEmitRow(
+		ctx, updatedRow, prevRow, topic, updated, mvcc, ev.DetachAlloc(),
+	); err != nil {
+		return err
+	}
+	if log.V(3) {
+		log.Infof(ctx, `r %s: encoded by the sink`, updatedRow.TableName)
+	}
+	return nil
+}
+
 // Close is a noop for the kvEventToRowConsumer because it
 // has no goroutines in flight.
 func (c *kvEventToRowConsumer) Close() error {
@@ -0,0 +1,201 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/importer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// Names of the columns which are added to the columns of the table in Parquet
+// files to hold the metadata of each event. They play the role of the fields
+// of the wrapped envelope in the other formats.
+const (
+	parquetEventTypeColName     = "__crdb__event_type"
+	parquetUpdatedColName       = "__crdb__updated"
+	parquetMVCCTimestampColName = "__crdb__mvcc_timestamp"
+)
+
+// Values of the event type column. Updates can only be told apart from
+// inserts when the diff option is specified; otherwise, they are reported as
+// inserts.
+const (
+	parquetEventInsert = "c"
+	parquetEventUpdate = "u"
+	parquetEventDelete = "d"
+)
+
+// parquetCompressionCodec returns the Parquet compression codec for the given
+// compression algorithm. Parquet files are compressed page by page by the
+// Parquet writer, rather than as a whole, so that readers can still access
+// their metadata.
+func parquetCompressionCodec(algo compressionAlgo) (parquet.CompressionCodec, error) {
+	switch algo {
+	case "":
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	case sinkCompressionGzip:
+		return parquet.CompressionCodec_GZIP, nil
+	case sinkCompressionZstd:
+		return parquet.CompressionCodec_ZSTD, nil
+	default:
+		return 0, errors.AssertionFailedf("unsupported compression algorithm %q", algo)
+	}
+}
+
+// parquetFileWriter encodes the rows of a changefeed data file in the Parquet
+// format. The cloud storage sink guarantees that all the rows in a file have
+// the same schema, so the schema of the file is derived from the columns of
+// the first row written to it. The columns of the table are mapped to Parquet
+// columns the same way as they are by EXPORT PARQUET.
+type parquetFileWriter struct {
+	writer *goparquet.FileWriter
+	opts   changefeedbase.EncodingOptions
+
+	// columns contains the Parquet columns for the columns of the table, in the
+	// order in which the row iterates over them.
+	columns []importer.ParquetColumn
+	names   []string
+
+	// row is reused across calls to addRow.
+	row map[string]interface{}
+}
+
+// newParquetFileWriter returns a parquetFileWriter writing to w rows with the
+// same columns as the given row. The rows are buffered in row groups of up to
+// maxRowGroupSize bytes before being written out.
+func newParquetFileWriter(
+	row cdcevent.Row,
+	opts changefeedbase.EncodingOptions,
+	codec parquet.CompressionCodec,
+	maxRowGroupSize int64,
+	w io.Writer,
+) (*parquetFileWriter, error) {
+	pw := &parquetFileWriter{
+		opts: opts,
+		row:  make(map[string]interface{}),
+	}
+	if err := row.ForEachColumn().Col(func(col cdcevent.ResultColumn) error {
+		// Deleted rows only contain the values of the primary key columns, so
+		// all the columns need to be nullable.
+		parquetCol, err := importer.NewParquetColumn(col.Typ, col.Name, true /* nullable */)
+		if err != nil {
+			return err
+		}
+		pw.columns = append(pw.columns, parquetCol)
+		pw.names = append(pw.names, col.Name)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	metaCols := []string{parquetEventTypeColName}
+	if opts.UpdatedTimestamps {
+		metaCols = append(metaCols, parquetUpdatedColName)
+	}
+	if opts.MVCCTimestamps {
+		metaCols = append(metaCols, parquetMVCCTimestampColName)
+	}
+	schemaCols := append([]importer.ParquetColumn(nil), pw.columns...)
+	for _, name := range metaCols {
+		for _, colName := range pw.names {
+			if colName == name {
+				return nil, errors.Errorf(
+					"column %q conflicts with the metadata column added by %s=%s",
+					name, changefeedbase.OptFormat, changefeedbase.OptFormatParquet,
+				)
+			}
+		}
+		parquetCol, err := importer.NewParquetColumn(types.String, name, false /* nullable */)
+		if err != nil {
+			return nil, err
+		}
+		schemaCols = append(schemaCols, parquetCol)
+	}
+
+	pw.writer = goparquet.NewFileWriter(w,
+		goparquet.WithCompressionCodec(codec),
+		goparquet.WithSchemaDefinition(importer.NewParquetSchema(schemaCols)),
+		goparquet.WithMaxRowGroupSize(maxRowGroupSize),
+		goparquet.WithCreator("cockroachdb"),
+	)
+	return pw, nil
+}
+
+// addRow encodes a row and adds it to the file. It returns the approximate
+// size of the row before encoding.
+func (w *parquetFileWriter) addRow(
+	updatedRow cdcevent.Row, prevRow cdcevent.Row, updated, mvcc hlc.Timestamp,
+) (int, error) {
+	var size int
+	i := 0
+	if err := updatedRow.ForEachColumn().Datum(func(d tree.Datum, col cdcevent.ResultColumn) error {
+		if i >= len(w.columns) || w.names[i] != col.Name {
+			return errors.AssertionFailedf("unexpected column %q in row %s", col.Name, updatedRow.DebugString())
+		}
+		size += int(d.Size())
+		if d == tree.DNull {
+			w.row[col.Name] = nil
+		} else {
+			v, err := w.columns[i].Encode(d)
+			if err != nil {
+				return err
+			}
+			w.row[col.Name] = v
+		}
+		i++
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if i != len(w.columns) {
+		return 0, errors.AssertionFailedf("expected %d columns, found %d in row %s",
+			len(w.columns), i, updatedRow.DebugString())
+	}
+
+	eventType := parquetEventInsert
+	if updatedRow.IsDeleted() {
+		eventType = parquetEventDelete
+	} else if prevRow.IsInitialized() && !prevRow.IsDeleted() {
+		eventType = parquetEventUpdate
+	}
+	w.row[parquetEventTypeColName] = []byte(eventType)
+	if w.opts.UpdatedTimestamps {
+		w.row[parquetUpdatedColName] = []byte(eval.TimestampToDecimalDatum(updated).Decimal.String())
+	}
+	if w.opts.MVCCTimestamps {
+		w.row[parquetMVCCTimestampColName] = []byte(eval.TimestampToDecimalDatum(mvcc).Decimal.String())
+	}
+
+	if err := w.writer.AddData(w.row); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// size returns the approximate size of the file, including the rows which are
+// still buffered in the current row group.
+func (w *parquetFileWriter) size() int64 {
+	return w.writer.CurrentFileSize() + w.writer.CurrentRowGroupSize()
+}
+
+// close writes out the buffered rows along with the footer of the file. The
+// parquetFileWriter cannot be used afterwards.
+func (w *parquetFileWriter) close() error {
+	return w.writer.Close()
+}
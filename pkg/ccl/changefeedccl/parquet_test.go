@@ -0,0 +1,145 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/importer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetFileWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	colTypes := []*types.T{types.Int, types.String, types.Jsonb}
+	makeRow := func(deleted bool, datums ...tree.Datum) cdcevent.Row {
+		encRow := make(rowenc.EncDatumRow, len(datums))
+		for i, d := range datums {
+			encRow[i] = rowenc.DatumToEncDatum(colTypes[i], d)
+		}
+		return cdcevent.TestingMakeEventRowFromEncDatums(encRow, colTypes, 1 /* numKeyCols */, deleted)
+	}
+	json, err := tree.ParseDJSON(`{"a": 1}`)
+	require.NoError(t, err)
+	inserted := makeRow(false, tree.NewDInt(1), tree.NewDString("one"), json)
+	updated := makeRow(false, tree.NewDInt(1), tree.NewDString("uno"), tree.DNull)
+	deleted := makeRow(true, tree.NewDInt(1), tree.DNull, tree.DNull)
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+
+	for _, tc := range []struct {
+		name  string
+		opts  changefeedbase.EncodingOptions
+		codec parquet.CompressionCodec
+	}{
+		{
+			name: "bare",
+			opts: changefeedbase.EncodingOptions{
+				Format:   changefeedbase.OptFormatParquet,
+				Envelope: changefeedbase.OptEnvelopeBare,
+			},
+			codec: parquet.CompressionCodec_UNCOMPRESSED,
+		},
+		{
+			name: "wrapped with timestamps",
+			opts: changefeedbase.EncodingOptions{
+				Format:            changefeedbase.OptFormatParquet,
+				Envelope:          changefeedbase.OptEnvelopeWrapped,
+				UpdatedTimestamps: true,
+				MVCCTimestamps:    true,
+				Diff:              true,
+			},
+			codec: parquet.CompressionCodec_GZIP,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newParquetFileWriter(inserted, tc.opts, tc.codec, 1<<20, &buf)
+			require.NoError(t, err)
+
+			events := []struct {
+				row, prev cdcevent.Row
+			}{
+				{row: inserted},
+				{row: updated, prev: inserted},
+				{row: deleted, prev: updated},
+			}
+			for i, ev := range events {
+				// The previous row is only available with the diff option.
+				var prev cdcevent.Row
+				if tc.opts.Diff {
+					prev = ev.prev
+				}
+				_, err := w.addRow(ev.row, prev, ts(int64(i+1)), ts(int64(i+10)))
+				require.NoError(t, err)
+			}
+			require.Greater(t, w.size(), int64(0))
+			require.NoError(t, w.close())
+
+			r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			require.Equal(t, int64(len(events)), r.NumRows())
+
+			decode := func(typ *types.T, v interface{}) tree.Datum {
+				if v == nil {
+					return tree.DNull
+				}
+				col, err := importer.NewParquetColumn(typ, "", true /* nullable */)
+				require.NoError(t, err)
+				d, err := col.DecodeFn(v)
+				require.NoError(t, err)
+				return d
+			}
+
+			// Without the diff option, updates can't be told apart from inserts.
+			expectedEventTypes := []string{parquetEventInsert, parquetEventInsert, parquetEventDelete}
+			if tc.opts.Diff {
+				expectedEventTypes[1] = parquetEventUpdate
+			}
+			for i, ev := range events {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+
+				j := 0
+				require.NoError(t, ev.row.ForEachColumn().Datum(func(d tree.Datum, col cdcevent.ResultColumn) error {
+					require.Equal(t, d.String(), decode(col.Typ, row[col.Name]).String(), "column %s", col.Name)
+					j++
+					return nil
+				}))
+				require.Equal(t, len(colTypes), j)
+
+				require.Equal(t, expectedEventTypes[i], string(row[parquetEventTypeColName].([]byte)))
+				_, ok := row[parquetUpdatedColName]
+				require.Equal(t, tc.opts.UpdatedTimestamps, ok)
+				_, ok = row[parquetMVCCTimestampColName]
+				require.Equal(t, tc.opts.MVCCTimestamps, ok)
+				if tc.opts.UpdatedTimestamps {
+					require.Equal(t, eval.TimestampToDecimalDatum(ts(int64(i+1))).Decimal.String(),
+						string(row[parquetUpdatedColName].([]byte)))
+				}
+			}
+			_, err = r.NextRow()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -82,6 +83,26 @@ type ResolvedTimestampSink interface {
 	EmitResolvedTimestamp(ctx context.Context, encoder Encoder, resolved hlc.Timestamp) error
 }
 
+// SinkWithEncoder is an EventSink which encodes the rows it emits itself,
+// rather than relying on an Encoder to encode each row into a message. It is
+// used for formats, such as Parquet, where the encoding of a row depends on
+// the file it ends up in.
+type SinkWithEncoder interface {
+	EventSink
+
+	// EncodeAndEmitRow encodes the given row and enqueues it for asynchronous
+	// delivery on the sink. An error may be returned if a previously enqueued
+	// message has failed.
+	EncodeAndEmitRow(
+		ctx context.Context,
+		updatedRow cdcevent.Row,
+		prevRow cdcevent.Row,
+		topic TopicDescriptor,
+		updated, mvcc hlc.Timestamp,
+		alloc kvevent.Alloc,
+	) error
+}
+
 // SinkWithTopics extends the Sink interface to include a method that returns
 // the topics that a changefeed will emit to.
 type SinkWithTopics interface {
@@ -156,6 +177,11 @@ func getSink(
 		if err != nil {
 			return nil, err
 		}
+		if encodingOpts.Format == changefeedbase.OptFormatParquet &&
+			!isCloudStorageSink(u) && u.Scheme != changefeedbase.SinkSchemeExternalConnection {
+			return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+				changefeedbase.OptFormat, encodingOpts.Format)
+		}
 
 		switch {
 		case u.Scheme == changefeedbase.SinkSchemeNull:
@@ -177,6 +203,22 @@ func getSink(
 				return makeWebhookSink(ctx, sinkURL{URL: u}, encodingOpts, webhookOpts,
 					defaultWorkerCount(), timeutil.DefaultTimeSource{}, metricsBuilder)
 			})
+		case isKinesisSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.KinesisValidOptions, func() (Sink, error) {
+				return makeKinesisSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), encodingOpts,
+					opts.GetKinesisConfigJSON(), serverCfg.Settings, defaultWorkerCount(),
+					timeutil.DefaultTimeSource{}, metricsBuilder)
+			})
+		case isPulsarSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.PulsarValidOptions, func() (Sink, error) {
+				return makePulsarSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), encodingOpts,
+					opts.GetPulsarConfigJSON(), defaultWorkerCount(), timeutil.DefaultTimeSource{}, metricsBuilder)
+			})
+		case isNATSSink(u):
+			return validateOptionsAndMakeSink(changefeedbase.NATSValidOptions, func() (Sink, error) {
+				return makeNATSSink(ctx, sinkURL{URL: u}, AllTargets(feedCfg), encodingOpts,
+					opts.GetNATSConfigJSON(), defaultWorkerCount(), timeutil.DefaultTimeSource{}, metricsBuilder)
+			})
 		case isPubsubSink(u):
 			// TODO: add metrics to pubsubsink
 			return MakePubsubSink(ctx, u, encodingOpts, AllTargets(feedCfg))
@@ -320,6 +362,25 @@ func (s errorWrapperSink) EmitRow(
 	return nil
 }
 
+// EncodeAndEmitRow implements the SinkWithEncoder interface.
+func (s errorWrapperSink) EncodeAndEmitRow(
+	ctx context.Context,
+	updatedRow cdcevent.Row,
+	prevRow cdcevent.Row,
+	topic TopicDescriptor,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	sinkWithEncoder, ok := s.wrapped.(SinkWithEncoder)
+	if !ok {
+		return errors.AssertionFailedf("sink %T does not encode rows", s.wrapped)
+	}
+	if err := sinkWithEncoder.EncodeAndEmitRow(ctx, updatedRow, prevRow, topic, updated, mvcc, alloc); err != nil {
+		return changefeedbase.MarkRetryableError(err)
+	}
+	return nil
+}
+
 // EmitResolvedTimestamp implements Sink interface.
 func (s errorWrapperSink) EmitResolvedTimestamp(
 	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
@@ -547,7 +608,7 @@ type safeSink struct {
 	wrapped     EventSink
 }
 
-var _ EventSink = (*safeSink)(nil)
+var _ SinkWithEncoder = (*safeSink)(nil)
 
 func (s *safeSink) Dial() error {
 	s.Lock()
@@ -573,6 +634,23 @@ func (s *safeSink) EmitRow(
 	return s.wrapped.EmitRow(ctx, topic, key, value, updated, mvcc, alloc)
 }
 
+func (s *safeSink) EncodeAndEmitRow(
+	ctx context.Context,
+	updatedRow cdcevent.Row,
+	prevRow cdcevent.Row,
+	topic TopicDescriptor,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	sinkWithEncoder, ok := s.wrapped.(SinkWithEncoder)
+	if !ok {
+		return errors.AssertionFailedf("sink %T does not encode rows", s.wrapped)
+	}
+	s.Lock()
+	defer s.Unlock()
+	return sinkWithEncoder.EncodeAndEmitRow(ctx, updatedRow, prevRow, topic, updated, mvcc, alloc)
+}
+
 func (s *safeSink) Flush(ctx context.Context) error {
 	if err := s.beforeFlush(ctx); err != nil {
 		return err
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdcevent"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
@@ -36,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/google/btree"
 )
 
@@ -69,6 +71,10 @@ type cloudStorageSinkFile struct {
 	buf         bytes.Buffer
 	alloc       kvevent.Alloc
 	oldestMVCC  hlc.Timestamp
+
+	// parquetWriter encodes the rows of the file when using the parquet format.
+	// It is created along with the first row written to the file.
+	parquetWriter *parquetFileWriter
 }
 
 var _ io.Writer = &cloudStorageSinkFile{}
@@ -175,9 +181,10 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // by a given `<sink_id>` and <session_id> is a unique identifying string for the job
 // session running the `changeAggregator` that owns this sink.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: `ndjson`, which means a text file
+// conforming to the "Newline Delimited JSON" spec, `csv`, or `parquet`, which
+// means a self-contained Parquet file with one column per column of the table
+// plus columns holding the metadata of each event.
 //
 // This naming convention of data files is carefully chosen in order to preserve
 // the external ordering guarantees of CDC. Naming output files in this fashion
@@ -289,6 +296,11 @@ type cloudStorageSink struct {
 
 	compression compressionAlgo
 
+	// encodingOpts and parquetCodec are used to encode rows when using the
+	// parquet format, in which case rows are encoded by the sink.
+	encodingOpts changefeedbase.EncodingOptions
+	parquetCodec parquet.CompressionCodec
+
 	es cloud.ExternalStorage
 
 	// These are fields to track information needed to output files based on the naming
@@ -370,6 +382,7 @@ func makeCloudStorageSink(
 		// TODO(dan,ajwerner): Use the jobs framework's session ID once that's available.
 		jobSessionID:     sessID,
 		topicNamer:       tn,
+		encodingOpts:     encodingOpts,
 		asyncFlushActive: enableAsyncFlush.Get(&settings.SV),
 		// TODO (yevgeniy): Consider adding ctx to Dial method instead.
 		flushCtx: ctx,
@@ -400,6 +413,8 @@ func makeCloudStorageSink(
 		// would require a bit of refactoring.
 		s.ext = `.csv`
 		s.rowDelimiter = []byte{'\n'}
+	case changefeedbase.OptFormatParquet:
+		s.ext = `.parquet`
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptFormat, encodingOpts.Format)
@@ -412,7 +427,9 @@ func makeCloudStorageSink(
 			changefeedbase.OptEnvelope, encodingOpts.Envelope)
 	}
 
-	if encodingOpts.Envelope != changefeedbase.OptEnvelopeBare && !encodingOpts.KeyInValue {
+	// The parquet format always includes the primary key columns.
+	if encodingOpts.Envelope != changefeedbase.OptEnvelopeBare && !encodingOpts.KeyInValue &&
+		encodingOpts.Format != changefeedbase.OptFormatParquet {
 		return nil, errors.Errorf(`this sink requires the WITH %s option`, changefeedbase.OptKeyInValue)
 	}
 
@@ -421,8 +438,15 @@ func makeCloudStorageSink(
 		if err != nil {
 			return nil, err
 		}
-		s.compression = algo
-		s.ext = s.ext + ext
+		if encodingOpts.Format == changefeedbase.OptFormatParquet {
+			// Parquet files are compressed internally by the parquet writer.
+			if s.parquetCodec, err = parquetCompressionCodec(algo); err != nil {
+				return nil, err
+			}
+		} else {
+			s.compression = algo
+			s.ext = s.ext + ext
+		}
 	}
 
 	// We make the external storage with a nil IOAccountingInterceptor since we
@@ -478,6 +502,10 @@ func (s *cloudStorageSink) EmitRow(
 	if s.files == nil {
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
+	if s.encodingOpts.Format == changefeedbase.OptFormatParquet {
+		return errors.AssertionFailedf("rows must be encoded by the sink with %s=%s",
+			changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+	}
 
 	s.metrics.recordMessageSize(int64(len(key) + len(value)))
 	file, err := s.getOrCreateFile(topic, mvcc)
@@ -502,6 +530,58 @@ func (s *cloudStorageSink) EmitRow(
 	return nil
 }
 
+// EncodeAndEmitRow implements the SinkWithEncoder interface.
+//
+// It is used with the parquet format, in which rows are buffered into a
+// parquet file for each topic and schema version. The rows are grouped in row
+// groups no larger than the file_size option, so a file normally contains a
+// single row group.
+func (s *cloudStorageSink) EncodeAndEmitRow(
+	ctx context.Context,
+	updatedRow cdcevent.Row,
+	prevRow cdcevent.Row,
+	topic TopicDescriptor,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	if s.files == nil {
+		return errors.New(`cannot EncodeAndEmitRow on a closed sink`)
+	}
+	if s.encodingOpts.Format != changefeedbase.OptFormatParquet {
+		return errors.AssertionFailedf("unexpected EncodeAndEmitRow with %s=%s",
+			changefeedbase.OptFormat, s.encodingOpts.Format)
+	}
+
+	file, err := s.getOrCreateFile(topic, mvcc)
+	if err != nil {
+		return err
+	}
+	file.alloc.Merge(&alloc)
+
+	if file.parquetWriter == nil {
+		if file.parquetWriter, err = newParquetFileWriter(
+			updatedRow, s.encodingOpts, s.parquetCodec, s.targetMaxFileSize, &file.buf,
+		); err != nil {
+			return err
+		}
+	}
+	size, err := file.parquetWriter.addRow(updatedRow, prevRow, updated, mvcc)
+	if err != nil {
+		return err
+	}
+	s.metrics.recordMessageSize(int64(size))
+	file.rawSize += size
+	file.numMessages++
+
+	if file.parquetWriter.size() > s.targetMaxFileSize {
+		s.metrics.recordSizeBasedFlush()
+		if err := s.flushTopicVersions(ctx, file.topic, file.schemaID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // EmitResolvedTimestamp implements the Sink interface.
 func (s *cloudStorageSink) EmitResolvedTimestamp(
 	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
@@ -671,12 +751,19 @@ func (f *cloudStorageSinkFile) flushToStorage(
 ) error {
 	defer f.alloc.Release(ctx)
 
-	if f.rawSize == 0 {
+	if f.numMessages == 0 {
 		// This method shouldn't be called with an empty file, but be defensive
 		// about not writing empty files anyway.
 		return nil
 	}
 
+	if f.parquetWriter != nil {
+		// Write out the buffered row group and the footer of the file.
+		if err := f.parquetWriter.close(); err != nil {
+			return err
+		}
+	}
+
 	if f.codec != nil {
 		if err := f.codec.Close(); err != nil {
 			return err
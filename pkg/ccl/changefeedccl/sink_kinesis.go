@@ -0,0 +1,332 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/cloud/amazon"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Limits of the Kinesis PutRecords API.
+const (
+	kinesisMaxRecordsPerRequest  = 500
+	kinesisMaxBytesPerRequest    = 5 << 20
+	kinesisMaxBytesPerRecord     = 1 << 20
+	kinesisMaxPartitionKeyLength = 256
+)
+
+// kinesisResolvedPartitionKey is the partition key of the records containing
+// resolved timestamps. It is ignored by Kinesis since these records specify
+// the shard they are written to with an explicit hash key.
+const kinesisResolvedPartitionKey = "resolved"
+
+func isKinesisSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeKinesis
+}
+
+// kinesisAPI is the subset of the Kinesis API used by kinesisClient.
+type kinesisAPI interface {
+	PutRecordsWithContext(
+		ctx aws.Context, input *kinesis.PutRecordsInput, opts ...request.Option,
+	) (*kinesis.PutRecordsOutput, error)
+	ListShardsWithContext(
+		ctx aws.Context, input *kinesis.ListShardsInput, opts ...request.Option,
+	) (*kinesis.ListShardsOutput, error)
+}
+
+var _ kinesisAPI = (*kinesis.Kinesis)(nil)
+
+// kinesisClient is a messageBusClient which writes to Amazon Kinesis data
+// streams. Each topic is written to the stream of the same name.
+//
+// Like the Kafka sink sends all the messages for a row to the same partition,
+// the Kinesis client uses a partition key derived from the primary key of the
+// row, so that all the records for a row are written to the same shard and
+// keep their order. Resolved timestamps are written to every open shard of
+// every stream.
+type kinesisClient struct {
+	settings *cluster.Settings
+	authOpts amazon.AuthOptions
+	endpoint string
+	region   string
+
+	api kinesisAPI
+}
+
+var _ messageBusClient = (*kinesisClient)(nil)
+
+// kinesisDefaultFlush is the default batching of the kinesis sink. Batches are
+// as large as a PutRecords request allows, and are written at least once per
+// second.
+var kinesisDefaultFlush = batchConfig{
+	Messages:  kinesisMaxRecordsPerRequest,
+	Bytes:     kinesisMaxBytesPerRequest,
+	Frequency: jsonDuration(time.Second),
+}
+
+func makeKinesisSink(
+	ctx context.Context,
+	u sinkURL,
+	targets changefeedbase.Targets,
+	encodingOpts changefeedbase.EncodingOptions,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	settings *cluster.Settings,
+	parallelism int,
+	source timeutil.TimeSource,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if u.Host != "" {
+		return nil, errors.Errorf(`kinesis sink URI must not specify a host, use %s or %s to name the streams`,
+			changefeedbase.SinkParamTopicName, changefeedbase.SinkParamTopicPrefix)
+	}
+
+	// Kinesis records have no key, so the key must be in the value for deletes
+	// to be usable.
+	if encodingOpts.Envelope != changefeedbase.OptEnvelopeBare && !encodingOpts.KeyInValue {
+		return nil, errors.Errorf(`this sink requires the WITH %s option`, changefeedbase.OptKeyInValue)
+	}
+
+	// The AWS parameters are the same as those of the other AWS URIs, which
+	// are consumed using cloud.ConsumeURL.
+	consumeURL := cloud.ConsumeURL{URL: u.URL}
+	topicPrefix := consumeURL.ConsumeParam(changefeedbase.SinkParamTopicPrefix)
+	topicName := consumeURL.ConsumeParam(changefeedbase.SinkParamTopicName)
+	// Kinesis stream names have the same restrictions as Kafka topic names,
+	// except that they are shorter.
+	topicNamer, err := MakeTopicNamer(
+		targets,
+		WithPrefix(topicPrefix), WithSingleName(topicName), WithSanitizeFn(SQLNameToKafkaName))
+	if err != nil {
+		return nil, err
+	}
+
+	authOpts := amazon.ConsumeAuthOptions(&consumeURL)
+	endpoint := consumeURL.ConsumeParam(amazon.AWSEndpointParam)
+	region := consumeURL.ConsumeParam(amazon.AWSRegionParam)
+	if unknownParams := consumeURL.RemainingQueryParams(); len(unknownParams) > 0 {
+		return nil, errors.Errorf(
+			`unknown kinesis sink query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+	if region == "" {
+		if endpoint == "" {
+			return nil, errors.Errorf(`kinesis sink requires the %s parameter`, amazon.AWSRegionParam)
+		}
+		region = "default-region"
+	}
+
+	client := &kinesisClient{
+		settings: settings,
+		authOpts: authOpts,
+		endpoint: endpoint,
+		region:   region,
+	}
+	return makeMessageBusSink(ctx, "kinesis", client, topicNamer, encodingOpts, jsonStr,
+		changefeedbase.OptKinesisSinkConfig, kinesisDefaultFlush, parallelism, source, mb)
+}
+
+// dial implements the messageBusClient interface.
+func (c *kinesisClient) dial(ctx context.Context) error {
+	awsConfig := &aws.Config{
+		Region: aws.String(c.region),
+		// Requests are retried by the sink, which also retries the records which
+		// were throttled when a request partially succeeds.
+		MaxRetries: aws.Int(0),
+	}
+	if c.endpoint != "" {
+		awsConfig.Endpoint = aws.String(c.endpoint)
+		client, err := cloud.MakeHTTPClient(c.settings)
+		if err != nil {
+			return err
+		}
+		awsConfig.HTTPClient = client
+	}
+	sess, err := c.authOpts.NewSession(ctx, c.settings, awsConfig, "kinesis")
+	if err != nil {
+		return err
+	}
+	c.api = kinesis.New(sess)
+	return nil
+}
+
+// kinesisPartitionKey returns the partition key of the records for the row
+// with the given encoded primary key. The key is used as is when it is a valid
+// partition key, which makes the records easier to inspect, and is hashed
+// otherwise.
+func kinesisPartitionKey(key []byte) string {
+	if len(key) > 0 && len(key) <= kinesisMaxPartitionKeyLength && utf8.Valid(key) {
+		return string(key)
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// kinesisRecordSize returns the size of a record, which includes its partition
+// key, as counted against the limits of Kinesis.
+func kinesisRecordSize(record *kinesis.PutRecordsRequestEntry) int {
+	return len(record.Data) + len(aws.StringValue(record.PartitionKey))
+}
+
+// publish implements the messageBusClient interface. The records are written
+// with as few PutRecords requests as the limits of Kinesis allow.
+func (c *kinesisClient) publish(
+	ctx context.Context, stream string, msgs []messageBusMessage,
+) ([]error, error) {
+	records := make([]*kinesis.PutRecordsRequestEntry, len(msgs))
+	for i, msg := range msgs {
+		records[i] = &kinesis.PutRecordsRequestEntry{
+			Data:         msg.value,
+			PartitionKey: aws.String(kinesisPartitionKey(msg.key)),
+		}
+		if size := kinesisRecordSize(records[i]); size > kinesisMaxBytesPerRecord {
+			return nil, markMessageBusPermanentError(errors.Errorf(
+				`message of %d bytes exceeds the maximum kinesis record size of %d bytes`,
+				size, kinesisMaxBytesPerRecord))
+		}
+	}
+
+	errs := make([]error, len(records))
+	for start := 0; start < len(records); {
+		end, bytes := start, 0
+		for end < len(records) && end-start < kinesisMaxRecordsPerRequest {
+			size := kinesisRecordSize(records[end])
+			if end > start && bytes+size > kinesisMaxBytesPerRequest {
+				break
+			}
+			bytes += size
+			end++
+		}
+		results, err := c.putRecords(ctx, stream, records[start:end])
+		if err != nil {
+			if start == 0 || errors.Is(err, errMessageBusPermanent) {
+				return nil, err
+			}
+			// The records of the previous requests were written, so only the
+			// remaining records need to be written again.
+			for i := start; i < len(errs); i++ {
+				errs[i] = err
+			}
+			return errs, nil
+		}
+		copy(errs[start:end], results)
+		start = end
+	}
+	return errs, nil
+}
+
+// putRecords writes the records to the stream with one PutRecords request, and
+// returns the error of each record which Kinesis rejected, or nil if it was
+// written. Errors which writing the records again cannot fix are marked as
+// permanent.
+func (c *kinesisClient) putRecords(
+	ctx context.Context, stream string, records []*kinesis.PutRecordsRequestEntry,
+) ([]error, error) {
+	output, err := c.api.PutRecordsWithContext(ctx, &kinesis.PutRecordsInput{
+		StreamName: aws.String(stream),
+		Records:    records,
+	})
+	if err != nil {
+		if !request.IsErrorRetryable(err) && !request.IsErrorThrottle(err) {
+			err = markMessageBusPermanentError(err)
+		}
+		return nil, errors.Wrapf(err, "writing to kinesis stream %s", stream)
+	}
+	if len(output.Records) != len(records) {
+		return nil, errors.Errorf("expected %d results from kinesis, got %d",
+			len(records), len(output.Records))
+	}
+	errs := make([]error, len(records))
+	for i, result := range output.Records {
+		if result.ErrorCode != nil {
+			errs[i] = errors.Newf("%s: %s",
+				aws.StringValue(result.ErrorCode), aws.StringValue(result.ErrorMessage))
+		}
+	}
+	return errs, nil
+}
+
+// publishResolved implements the messageBusClient interface. The resolved
+// timestamp is written to every open shard of the stream, using the lowest
+// hash key of a shard to target it.
+func (c *kinesisClient) publishResolved(ctx context.Context, stream string, payload []byte) error {
+	shards, err := c.listOpenShards(ctx, stream)
+	if err != nil {
+		return err
+	}
+	records := make([]*kinesis.PutRecordsRequestEntry, len(shards))
+	for i, shard := range shards {
+		records[i] = &kinesis.PutRecordsRequestEntry{
+			Data:            payload,
+			PartitionKey:    aws.String(kinesisResolvedPartitionKey),
+			ExplicitHashKey: shard.HashKeyRange.StartingHashKey,
+		}
+	}
+	for len(records) > 0 {
+		n := len(records)
+		if n > kinesisMaxRecordsPerRequest {
+			n = kinesisMaxRecordsPerRequest
+		}
+		errs, err := c.putRecords(ctx, stream, records[:n])
+		if err != nil {
+			return err
+		}
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		records = records[n:]
+	}
+	return nil
+}
+
+// listOpenShards returns the shards of the stream which can be written to.
+func (c *kinesisClient) listOpenShards(
+	ctx context.Context, stream string,
+) ([]*kinesis.Shard, error) {
+	var shards []*kinesis.Shard
+	input := &kinesis.ListShardsInput{StreamName: aws.String(stream)}
+	for {
+		output, err := c.api.ListShardsWithContext(ctx, input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the shards of kinesis stream %s", stream)
+		}
+		for _, shard := range output.Shards {
+			// Closed shards, which were split or merged, have an ending sequence
+			// number.
+			if shard.SequenceNumberRange == nil || shard.SequenceNumberRange.EndingSequenceNumber == nil {
+				shards = append(shards, shard)
+			}
+		}
+		if output.NextToken == nil {
+			return shards, nil
+		}
+		// The stream name must not be set when the token is.
+		input = &kinesis.ListShardsInput{NextToken: output.NextToken}
+	}
+}
+
+// close implements the messageBusClient interface.
+func (c *kinesisClient) close() error {
+	return nil
+}
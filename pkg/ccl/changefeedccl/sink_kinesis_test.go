@@ -0,0 +1,350 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type kinesisStandInRecord struct {
+	Data            []byte
+	PartitionKey    string
+	ExplicitHashKey string
+}
+
+type kinesisStandInShard struct {
+	startingHashKey string
+	closed          bool
+}
+
+// kinesisStandIn is a local HTTP stand-in for the Kinesis API, which serves
+// the PutRecords and ListShards requests of the kinesis sink.
+type kinesisStandIn struct {
+	server *httptest.Server
+	shards []kinesisStandInShard
+
+	mu struct {
+		syncutil.Mutex
+		// records contains the records written to each stream.
+		records map[string][]kinesisStandInRecord
+		// requests is the number of PutRecords requests which were served.
+		requests int
+		// throttledRequests is the number of the next PutRecords requests
+		// which are throttled.
+		throttledRequests int
+		// throttledKeys is the number of times the next records with each
+		// partition key are throttled.
+		throttledKeys map[string]int
+	}
+}
+
+func startKinesisStandIn(shards ...kinesisStandInShard) *kinesisStandIn {
+	s := &kinesisStandIn{shards: shards}
+	s.mu.records = make(map[string][]kinesisStandInRecord)
+	s.mu.throttledKeys = make(map[string]int)
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *kinesisStandIn) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	var res interface{}
+	switch target := r.Header.Get("X-Amz-Target"); target {
+	case "Kinesis_20131202.PutRecords":
+		var req struct {
+			StreamName string
+			Records    []kinesisStandInRecord
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var throttled bool
+		res, throttled = s.putRecords(req.StreamName, req.Records)
+		if throttled {
+			w.WriteHeader(http.StatusBadRequest)
+			res = map[string]string{
+				"__type":  "ProvisionedThroughputExceededException",
+				"message": "Rate exceeded for stream",
+			}
+		}
+	case "Kinesis_20131202.ListShards":
+		var shards []interface{}
+		for i, shard := range s.shards {
+			seqRange := map[string]string{"StartingSequenceNumber": "0"}
+			if shard.closed {
+				seqRange["EndingSequenceNumber"] = "1"
+			}
+			shards = append(shards, map[string]interface{}{
+				"ShardId":             fmt.Sprintf("shardId-%012d", i),
+				"HashKeyRange":        map[string]string{"StartingHashKey": shard.startingHashKey, "EndingHashKey": "0"},
+				"SequenceNumberRange": seqRange,
+			})
+		}
+		res = map[string]interface{}{"Shards": shards}
+	default:
+		http.Error(w, fmt.Sprintf("unexpected target %q", target), http.StatusBadRequest)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		panic(err)
+	}
+}
+
+func (s *kinesisStandIn) putRecords(
+	stream string, records []kinesisStandInRecord,
+) (res map[string]interface{}, throttled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.requests++
+	if s.mu.throttledRequests > 0 {
+		s.mu.throttledRequests--
+		return nil, true
+	}
+	results := make([]map[string]string, len(records))
+	failed := 0
+	for i, record := range records {
+		if s.mu.throttledKeys[record.PartitionKey] > 0 {
+			s.mu.throttledKeys[record.PartitionKey]--
+			results[i] = map[string]string{
+				"ErrorCode":    "ProvisionedThroughputExceededException",
+				"ErrorMessage": "Rate exceeded for shard",
+			}
+			failed++
+			continue
+		}
+		s.mu.records[stream] = append(s.mu.records[stream], record)
+		results[i] = map[string]string{"SequenceNumber": "1", "ShardId": "shardId-000000000000"}
+	}
+	return map[string]interface{}{"FailedRecordCount": failed, "Records": results}, false
+}
+
+func (s *kinesisStandIn) throttle(requests int, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.throttledRequests = requests
+	for _, key := range keys {
+		s.mu.throttledKeys[key]++
+	}
+}
+
+// values returns the partition keys and the data of the records written to
+// the stream.
+func (s *kinesisStandIn) values(stream string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var values []string
+	for _, record := range s.mu.records[stream] {
+		values = append(values, fmt.Sprintf("%s: %s", record.PartitionKey, record.Data))
+	}
+	return values
+}
+
+func (s *kinesisStandIn) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.requests
+}
+
+func (s *kinesisStandIn) sinkURI() string {
+	u, err := url.Parse(s.server.URL)
+	if err != nil {
+		panic(err)
+	}
+	q := make(url.Values)
+	q.Set("AWS_ENDPOINT", u.String())
+	q.Set("AWS_ACCESS_KEY_ID", "id")
+	q.Set("AWS_SECRET_ACCESS_KEY", "secret")
+	return fmt.Sprintf("%s://?%s", changefeedbase.SinkSchemeKinesis, q.Encode())
+}
+
+func makeTestKinesisSink(
+	t *testing.T, uri string, jsonConfig changefeedbase.SinkSpecificJSONConfig, targetNames ...string,
+) (Sink, error) {
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	encodingOpts := changefeedbase.EncodingOptions{
+		Format:     changefeedbase.OptFormatJSON,
+		Envelope:   changefeedbase.OptEnvelopeWrapped,
+		KeyInValue: true,
+	}
+	return makeKinesisSink(context.Background(), sinkURL{URL: u}, makeChangefeedTargets(targetNames...),
+		encodingOpts, jsonConfig, cluster.MakeTestingClusterSettings(), 1, /* parallelism */
+		timeutil.DefaultTimeSource{}, nilMetricsRecorderBuilder)
+}
+
+func TestKinesisSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	standIn := startKinesisStandIn(
+		kinesisStandInShard{startingHashKey: "0"},
+		kinesisStandInShard{startingHashKey: "100", closed: true},
+		kinesisStandInShard{startingHashKey: "200"},
+	)
+	defer standIn.server.Close()
+
+	sink, err := makeTestKinesisSink(t, standIn.sinkURI(),
+		`{"Flush": {"Messages": 2, "Frequency": "1h"}, "Retry": {"Max": 3, "Backoff": "1ms"}}`, "t")
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	var pool testAllocPool
+	emit := func(key, value string) {
+		require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(key), []byte(value), zeroTS, zeroTS, pool.alloc()))
+	}
+	waitForValues := func(n int) []string {
+		testutils.SucceedsSoon(t, func() error {
+			if values := standIn.values("t"); len(values) != n {
+				return errors.Newf("expected %d values, got %v", n, values)
+			}
+			return nil
+		})
+		return standIn.values("t")
+	}
+
+	// Records are written once a batch is full, and when the sink is flushed.
+	emit(`[1]`, `v1`)
+	require.Empty(t, standIn.values("t"))
+	emit(`[2]`, `v1`)
+	require.Equal(t, []string{`[1]: v1`, `[2]: v1`}, waitForValues(2))
+	emit(`[3]`, `v1`)
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[1]: v1`, `[2]: v1`, `[3]: v1`}, standIn.values("t"))
+	require.EqualValues(t, 0, pool.used())
+
+	// Throttled requests are retried.
+	standIn.throttle(2)
+	emit(`[1]`, `v2`)
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, `[1]: v2`, standIn.values("t")[3])
+
+	// When a record is throttled, it is retried along with the records for the
+	// same row which follow it, so that the latest version of the row is
+	// written last.
+	standIn.throttle(0, `[2]`)
+	emit(`[2]`, `v2`)
+	emit(`[2]`, `v3`)
+	require.Equal(t, []string{`[2]: v3`, `[2]: v2`, `[2]: v3`}, waitForValues(7)[4:])
+	require.EqualValues(t, 0, pool.used())
+
+	// Resolved timestamps are written to every open shard.
+	opts := changefeedbase.EncodingOptions{Format: changefeedbase.OptFormatJSON, Envelope: changefeedbase.OptEnvelopeWrapped}
+	enc, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 2}))
+	standIn.mu.Lock()
+	resolved := standIn.mu.records["t"][len(standIn.mu.records["t"])-2:]
+	standIn.mu.Unlock()
+	for i, hashKey := range []string{"0", "200"} {
+		require.Equal(t, hashKey, resolved[i].ExplicitHashKey)
+		require.Equal(t, `{"resolved":"2.0000000000"}`, string(resolved[i].Data))
+	}
+
+	// The error is returned once the retries are exhausted.
+	standIn.throttle(10)
+	emit(`[3]`, `v2`)
+	require.Regexp(t, `ProvisionedThroughputExceededException: Rate exceeded for stream`, sink.Flush(ctx))
+}
+
+func TestKinesisSinkLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	standIn := startKinesisStandIn(kinesisStandInShard{startingHashKey: "0"})
+	defer standIn.server.Close()
+
+	sink, err := makeTestKinesisSink(t, standIn.sinkURI(),
+		`{"Flush": {"Messages": 1200, "Frequency": "1h"}, "Retry": {"Max": 3, "Backoff": "1ms"}}`, "t")
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	var pool testAllocPool
+	emit := func(key, value string) error {
+		return sink.EmitRow(ctx, topic("t"), []byte(key), []byte(value), zeroTS, zeroTS, pool.alloc())
+	}
+
+	// A batch is written with as many requests as the limit on the number of
+	// records of a PutRecords request requires.
+	for i := 0; i < 1200; i++ {
+		require.NoError(t, emit(fmt.Sprintf("[%d]", i), `v1`))
+	}
+	require.NoError(t, sink.Flush(ctx))
+	require.Len(t, standIn.values("t"), 1200)
+	require.Equal(t, 3, standIn.requests())
+	require.EqualValues(t, 0, pool.used())
+
+	// A record which is too large is not written again.
+	require.NoError(t, emit(`[1]`, strings.Repeat("a", kinesisMaxBytesPerRecord)))
+	require.Regexp(t, `exceeds the maximum kinesis record size`, sink.Flush(ctx))
+	require.Equal(t, 3, standIn.requests())
+}
+
+func TestKinesisSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const region = "AWS_REGION=us-east-1"
+	for _, tc := range []struct {
+		uri    string
+		config changefeedbase.SinkSpecificJSONConfig
+		err    string
+	}{
+		{uri: "kinesis://?" + region},
+		{uri: "kinesis://?topic_prefix=p&" + region, config: `{"Flush": {"Messages": 10, "Bytes": 1000}}`},
+		{uri: "kinesis://stream?" + region, err: "must not specify a host"},
+		{uri: "kinesis://", err: "kinesis sink requires the AWS_REGION parameter"},
+		{uri: "kinesis://?foo=bar&" + region, err: "unknown kinesis sink query parameters: foo"},
+		{uri: "kinesis://?" + region, config: `{"Flush": {"Messages": 1000, "Bytes": 6000000}}`},
+		{uri: "kinesis://?" + region, config: `{"Flush": {"Frequency": "0s"}}`, err: "flush frequency is not set"},
+		{uri: "kinesis://?" + region, config: `{"Retry": {"Backoff": "-1s"}}`, err: "all config values must be non-negative"},
+	} {
+		t.Run(fmt.Sprintf("%s %s", tc.uri, tc.config), func(t *testing.T) {
+			_, err := makeTestKinesisSink(t, tc.uri, tc.config, "t")
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestKinesisPartitionKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	require.Equal(t, `[1, "a"]`, kinesisPartitionKey([]byte(`[1, "a"]`)))
+	// Keys which are too long or not valid UTF-8 are hashed.
+	long := kinesisPartitionKey([]byte(strings.Repeat("a", 1000)))
+	require.Len(t, long, 64)
+	require.NotEqual(t, long, kinesisPartitionKey([]byte(strings.Repeat("a", 1001))))
+	require.Len(t, kinesisPartitionKey([]byte{0xff}), 64)
+}
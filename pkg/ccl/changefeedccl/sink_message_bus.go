@@ -0,0 +1,552 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/json"
+	"hash/crc32"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/kvevent"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// messageBusMessage is a message published to a message bus.
+type messageBusMessage struct {
+	// key is the encoded primary key of the row. The messages with the same key
+	// are published in the order they were emitted.
+	key   []byte
+	value []byte
+}
+
+// messageBusClient publishes messages to a particular message bus. It is used
+// by messageBusSink, which implements the batching, ordering and retries of the
+// pulsar, nats and kinesis sinks. Its methods may be called concurrently.
+type messageBusClient interface {
+	// dial connects to the message bus.
+	dial(ctx context.Context) error
+	// publish publishes the messages, in order, to the topic and waits for the
+	// message bus to acknowledge them. It returns the error of each message
+	// which was not acknowledged, or nil if it was, or an error if the messages
+	// could not be published at all. The messages are published again unless
+	// the error is marked with markMessageBusPermanentError.
+	publish(ctx context.Context, topic string, msgs []messageBusMessage) ([]error, error)
+	// publishResolved publishes a message containing a resolved timestamp to
+	// every partition of the topic, and waits for it to be acknowledged.
+	publishResolved(ctx context.Context, topic string, payload []byte) error
+	// close closes the connections to the message bus.
+	close() error
+}
+
+// errMessageBusPermanent marks the errors of a messageBusClient which
+// publishing the messages again cannot fix.
+var errMessageBusPermanent = errors.New("permanent message bus error")
+
+// markMessageBusPermanentError marks an error returned by a messageBusClient
+// so that the messages are not published again.
+func markMessageBusPermanentError(err error) error {
+	return errors.Mark(err, errMessageBusPermanent)
+}
+
+// messageBusSink emits to a message bus using a messageBusClient. The kafka
+// and pubsub sinks do not use it, since their clients batch and order the
+// messages themselves.
+//
+// The messages for a row are all sent to the same worker, which is picked
+// based on the hash of the key of the row. Each worker batches its messages per
+// topic, and publishes a batch once it is large or old enough, or when the sink
+// is flushed. A worker waits for the message bus to acknowledge a batch before
+// it publishes the next one, so the messages for a row are published in the
+// order they were emitted, and it releases the memory of the messages once they
+// are acknowledged.
+type messageBusSink struct {
+	// name is the name of the message bus, used in errors.
+	name        string
+	client      messageBusClient
+	topicNamer  *TopicNamer
+	parallelism int
+	batchCfg    batchConfig
+	retryCfg    retry.Options
+	ts          timeutil.TimeSource
+	metrics     metricsRecorder
+
+	// errChan is written to indicate an error while publishing messages.
+	errChan chan error
+
+	// parallelism workers are created and controlled by the workerGroup,
+	// running with workerCtx. Each worker gets its own events channel.
+	workerCtx   context.Context
+	workerGroup ctxgroup.Group
+	exitWorkers func() // Signaled to shut down all workers.
+	eventsChans []chan messageBusEvent
+}
+
+var _ Sink = (*messageBusSink)(nil)
+
+// messageBusEvent contains either a message to publish or a flush request.
+type messageBusEvent struct {
+	topic    string
+	msg      messageBusMessage
+	alloc    kvevent.Alloc
+	mvcc     hlc.Timestamp
+	emitTime time.Time
+
+	// flushed, if set, requests the worker to publish all of its batches and to
+	// then signal the channel.
+	flushed chan struct{}
+}
+
+// messageBusBatch is a batch of messages published to a topic together.
+type messageBusBatch struct {
+	msgs     []messageBusMessage
+	bytes    int
+	alloc    kvevent.Alloc
+	emitTime time.Time
+	mvcc     hlc.Timestamp
+}
+
+func (b *messageBusBatch) add(ev messageBusEvent) {
+	if len(b.msgs) == 0 {
+		b.emitTime = ev.emitTime
+	}
+	b.msgs = append(b.msgs, ev.msg)
+	b.bytes += len(ev.msg.key) + len(ev.msg.value)
+	b.alloc.Merge(&ev.alloc)
+	if b.mvcc.IsEmpty() || ev.mvcc.Less(b.mvcc) {
+		b.mvcc = ev.mvcc
+	}
+}
+
+func (b *messageBusBatch) reset() {
+	b.msgs = b.msgs[:0]
+	b.bytes = 0
+	b.alloc = kvevent.Alloc{}
+	b.mvcc = hlc.Timestamp{}
+}
+
+// messageBusSinkConfig is the JSON schema of the config of the sinks built on
+// messageBusSink:
+//
+//	{
+//	  "Flush": {
+//	    "Messages":  ...,
+//	    "Bytes":     ...,
+//	    "Frequency": ...,
+//	  },
+//	  "Retry": {
+//	    "Max":     ...,
+//	    "Backoff": ...,
+//	  }
+//	}
+//
+// The default of Flush depends on the message bus. Unless it is set, the
+// messages are published as soon as they are emitted, like for the webhook
+// sink.
+type messageBusSinkConfig struct {
+	Flush batchConfig `json:",omitempty"`
+	Retry retryConfig `json:",omitempty"`
+}
+
+func getMessageBusSinkConfig(
+	jsonStr changefeedbase.SinkSpecificJSONConfig, opt string, defaultFlush batchConfig,
+) (batchCfg batchConfig, retryCfg retry.Options, err error) {
+	retryCfg = defaultRetryConfig()
+
+	var cfg messageBusSinkConfig
+	cfg.Flush = defaultFlush
+	cfg.Retry.Max = jsonMaxRetries(retryCfg.MaxRetries)
+	cfg.Retry.Backoff = jsonDuration(retryCfg.InitialBackoff)
+	if jsonStr != `` {
+		if err = json.Unmarshal([]byte(jsonStr), &cfg); err != nil {
+			return batchCfg, retryCfg, errors.Wrapf(err, "error unmarshalling json")
+		}
+	}
+
+	if cfg.Flush.Messages < 0 || cfg.Flush.Bytes < 0 || cfg.Flush.Frequency < 0 ||
+		cfg.Retry.Max < 0 || cfg.Retry.Backoff < 0 {
+		return batchCfg, retryCfg, errors.Errorf(
+			"invalid option value %s, all config values must be non-negative", opt)
+	}
+	if (cfg.Flush.Messages > 0 || cfg.Flush.Bytes > 0) && cfg.Flush.Frequency == 0 {
+		return batchCfg, retryCfg, errors.Errorf(
+			"invalid option value %s, flush frequency is not set, messages may never be sent", opt)
+	}
+
+	retryCfg.MaxRetries = int(cfg.Retry.Max)
+	retryCfg.InitialBackoff = time.Duration(cfg.Retry.Backoff)
+	return cfg.Flush, retryCfg, nil
+}
+
+func makeMessageBusSink(
+	ctx context.Context,
+	name string,
+	client messageBusClient,
+	topicNamer *TopicNamer,
+	encodingOpts changefeedbase.EncodingOptions,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	opt string,
+	defaultFlush batchConfig,
+	parallelism int,
+	source timeutil.TimeSource,
+	mb metricsRecorderBuilder,
+) (*messageBusSink, error) {
+	switch encodingOpts.Format {
+	case changefeedbase.OptFormatJSON, changefeedbase.OptFormatCSV:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, encodingOpts.Format)
+	}
+
+	batchCfg, retryCfg, err := getMessageBusSinkConfig(jsonStr, opt, defaultFlush)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error processing option %s", opt)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &messageBusSink{
+		name:        name,
+		client:      client,
+		topicNamer:  topicNamer,
+		parallelism: parallelism,
+		batchCfg:    batchCfg,
+		retryCfg:    retryCfg,
+		ts:          source,
+		metrics:     mb(requiresResourceAccounting),
+		workerCtx:   ctx,
+		exitWorkers: cancel,
+	}, nil
+}
+
+// Dial implements the Sink interface.
+func (s *messageBusSink) Dial() error {
+	if err := s.client.dial(s.workerCtx); err != nil {
+		return err
+	}
+	s.setupWorkers()
+	return nil
+}
+
+func (s *messageBusSink) setupWorkers() {
+	s.eventsChans = make([]chan messageBusEvent, s.parallelism)
+	s.workerGroup = ctxgroup.WithContext(s.workerCtx)
+
+	// an error channel with buffer for the first error.
+	s.errChan = make(chan error, 1)
+
+	for i := range s.eventsChans {
+		events := make(chan messageBusEvent)
+		s.eventsChans[i] = events
+		s.workerGroup.GoCtx(func(ctx context.Context) error {
+			s.workerLoop(events)
+			return nil
+		})
+	}
+}
+
+// workerLoop batches the messages sent to the events channel of a worker and
+// publishes the batches.
+func (s *messageBusSink) workerLoop(events chan messageBusEvent) {
+	batches := make(map[string]*messageBusBatch)
+	publishAll := func() error {
+		for topic, b := range batches {
+			if len(b.msgs) == 0 {
+				continue
+			}
+			if err := s.publishBatch(topic, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The timer is started when a message is added to a worker with no pending
+	// messages, so that no message waits for longer than the flush frequency.
+	timer := s.ts.NewTimer()
+	defer timer.Stop()
+	timerStarted := false
+
+	for {
+		select {
+		case <-s.workerCtx.Done():
+			return
+		case ev := <-events:
+			if ev.flushed != nil {
+				if err := publishAll(); err != nil {
+					s.exitWorkersWithError(err)
+					return
+				}
+				ev.flushed <- struct{}{}
+				continue
+			}
+
+			b, ok := batches[ev.topic]
+			if !ok {
+				b = &messageBusBatch{}
+				batches[ev.topic] = b
+			}
+			b.add(ev)
+			if s.shouldPublishBatch(b) {
+				if s.batchCfg.Messages > 0 || s.batchCfg.Bytes > 0 {
+					s.metrics.recordSizeBasedFlush()
+				}
+				if err := s.publishBatch(ev.topic, b); err != nil {
+					s.exitWorkersWithError(err)
+					return
+				}
+			} else if !timerStarted {
+				timer.Reset(time.Duration(s.batchCfg.Frequency))
+				timerStarted = true
+			}
+		case <-timer.Ch():
+			timer.MarkRead()
+			timerStarted = false
+			if err := publishAll(); err != nil {
+				s.exitWorkersWithError(err)
+				return
+			}
+		}
+	}
+}
+
+func (s *messageBusSink) shouldPublishBatch(b *messageBusBatch) bool {
+	switch {
+	// all zero values should publish every time, otherwise messages would wait
+	// for the next flush.
+	case s.batchCfg.Messages == 0 && s.batchCfg.Bytes == 0 && s.batchCfg.Frequency == 0:
+		return true
+	case s.batchCfg.Messages > 0 && len(b.msgs) >= s.batchCfg.Messages:
+		return true
+	case s.batchCfg.Bytes > 0 && b.bytes >= s.batchCfg.Bytes:
+		return true
+	default:
+		return false
+	}
+}
+
+// publishBatch publishes the messages of the batch to the topic, releases them
+// once they are acknowledged and resets the batch.
+func (s *messageBusSink) publishBatch(topic string, b *messageBusBatch) error {
+	if err := s.publishWithRetries(s.workerCtx, topic, b.msgs); err != nil {
+		return err
+	}
+	s.metrics.recordEmittedBatch(b.emitTime, len(b.msgs), b.mvcc, b.bytes, sinkDoesNotCompress)
+	b.alloc.Release(s.workerCtx)
+	b.reset()
+	return nil
+}
+
+// publishWithRetries publishes the messages to the topic. The messages which
+// are not acknowledged are published again with backoff, unless one of them
+// failed with a permanent error.
+func (s *messageBusSink) publishWithRetries(
+	ctx context.Context, topic string, msgs []messageBusMessage,
+) error {
+	pending := msgs
+	var lastErr error
+	for r := retry.StartWithCtx(ctx, s.retryCfg); r.Next(); {
+		if lastErr != nil {
+			s.metrics.recordInternalRetry(int64(len(pending)), false /* reducedBatchSize */)
+		}
+		errs, err := s.client.publish(ctx, topic, pending)
+		if err != nil {
+			if errors.Is(err, errMessageBusPermanent) {
+				return errors.Wrapf(err, "publishing %d messages to %s topic %s", len(pending), s.name, topic)
+			}
+			lastErr = err
+			continue
+		}
+		if pending, lastErr = messageBusMessagesToRetry(pending, errs); len(pending) == 0 {
+			return nil
+		}
+		if errors.Is(lastErr, errMessageBusPermanent) {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Wrapf(lastErr, "publishing %d messages to %s topic %s", len(pending), s.name, topic)
+}
+
+// messageBusMessagesToRetry returns the messages which must be published again
+// after publishing the given messages partially succeeded, as well as the
+// error of the first message which was not acknowledged, or of the first
+// message whose error is permanent if there is one, in which case the caller
+// must not publish the messages again.
+//
+// Along with the messages which were not acknowledged, the messages which
+// follow one of them with the same key are published again even if they were
+// acknowledged. Otherwise, the message which failed would be published after
+// them, and a consumer would see an older version of the row last. Like the
+// other sinks, this may deliver some messages more than once.
+func messageBusMessagesToRetry(
+	msgs []messageBusMessage, errs []error,
+) ([]messageBusMessage, error) {
+	var toRetry []messageBusMessage
+	var firstErr error
+	failedKeys := make(map[string]struct{})
+	for i, err := range errs {
+		key := string(msgs[i].key)
+		if err != nil {
+			if firstErr == nil ||
+				(errors.Is(err, errMessageBusPermanent) && !errors.Is(firstErr, errMessageBusPermanent)) {
+				firstErr = err
+			}
+			failedKeys[key] = struct{}{}
+			toRetry = append(toRetry, msgs[i])
+		} else if _, ok := failedKeys[key]; ok {
+			toRetry = append(toRetry, msgs[i])
+		}
+	}
+	return toRetry, firstErr
+}
+
+// workerIndex assigns rows each to a worker goroutine based on the hash of its
+// primary key, so that the messages for a row are published in order.
+func (s *messageBusSink) workerIndex(key []byte) uint32 {
+	return crc32.ChecksumIEEE(key) % uint32(s.parallelism)
+}
+
+// exitWorkersWithError saves the first error encountered by the workers, and
+// requests all workers to terminate.
+func (s *messageBusSink) exitWorkersWithError(err error) {
+	// errChan has buffer size 1, first error will be saved to the buffer and
+	// subsequent errors will be ignored
+	select {
+	case s.errChan <- err:
+		s.exitWorkers()
+	default:
+	}
+}
+
+// sinkError checks to see if any errors occurred inside workers go routines.
+func (s *messageBusSink) sinkError() error {
+	select {
+	case err := <-s.errChan:
+		return err
+	default:
+		return nil
+	}
+}
+
+// EmitRow implements the Sink interface.
+func (s *messageBusSink) EmitRow(
+	ctx context.Context,
+	topic TopicDescriptor,
+	key, value []byte,
+	updated, mvcc hlc.Timestamp,
+	alloc kvevent.Alloc,
+) error {
+	topicName, err := s.topicNamer.Name(topic)
+	if err != nil {
+		return err
+	}
+	ev := messageBusEvent{
+		topic:    topicName,
+		msg:      messageBusMessage{key: key, value: value},
+		alloc:    alloc,
+		mvcc:     mvcc,
+		emitTime: timeutil.Now(),
+	}
+	select {
+	// check the sink context in case workers have been terminated
+	case <-s.workerCtx.Done():
+		// check again for error in case it triggered since last check
+		// will return more verbose error instead of "context canceled"
+		return errors.CombineErrors(s.workerCtx.Err(), s.sinkError())
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-s.errChan:
+		return err
+	case s.eventsChans[s.workerIndex(key)] <- ev:
+		s.metrics.recordMessageSize(int64(len(key) + len(value)))
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *messageBusSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	defer s.metrics.recordResolvedCallback()()
+
+	if err := s.sinkError(); err != nil {
+		return err
+	}
+	return s.topicNamer.Each(func(topic string) error {
+		payload, err := encoder.EncodeResolvedTimestamp(ctx, topic, resolved)
+		if err != nil {
+			return err
+		}
+		for r := retry.StartWithCtx(ctx, s.retryCfg); r.Next(); {
+			if err = s.client.publishResolved(ctx, topic, payload); err == nil {
+				return nil
+			}
+			if errors.Is(err, errMessageBusPermanent) {
+				break
+			}
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return errors.Wrapf(err, "publishing resolved timestamp to %s topic %s", s.name, topic)
+	})
+}
+
+// Flush implements the Sink interface.
+func (s *messageBusSink) Flush(ctx context.Context) error {
+	defer s.metrics.recordFlushRequestCallback()()
+
+	flushed := make(chan struct{}, len(s.eventsChans))
+	for _, events := range s.eventsChans {
+		select {
+		case <-s.workerCtx.Done():
+			return errors.CombineErrors(s.workerCtx.Err(), s.sinkError())
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-s.errChan:
+			return err
+		case events <- messageBusEvent{flushed: flushed}:
+		}
+	}
+	for range s.eventsChans {
+		select {
+		case <-s.workerCtx.Done():
+			return errors.CombineErrors(s.workerCtx.Err(), s.sinkError())
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-s.errChan:
+			return err
+		case <-flushed:
+		}
+	}
+	return s.sinkError()
+}
+
+// Topics gives the names of all topics that have been initialized
+// and will receive resolved timestamps.
+func (s *messageBusSink) Topics() []string {
+	return s.topicNamer.DisplayNamesSlice()
+}
+
+// Close implements the Sink interface.
+func (s *messageBusSink) Close() error {
+	s.exitWorkers()
+	if s.eventsChans != nil {
+		// ignore errors here since we're closing the sink anyway
+		_ = s.workerGroup.Wait()
+	}
+	return s.client.close()
+}
@@ -0,0 +1,318 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMessageBusClient is a messageBusClient which records the messages it
+// publishes.
+type fakeMessageBusClient struct {
+	mu struct {
+		syncutil.Mutex
+		// published contains the messages published to each topic.
+		published map[string][]string
+		// failedRequests is the number of the next requests which fail.
+		failedRequests int
+		// failedKeys is the number of times the next messages with each key are
+		// not acknowledged.
+		failedKeys map[string]int
+		// permanentErr, if set, is returned by the next request.
+		permanentErr error
+		// rejectedKeys contains the keys of the messages which are never
+		// acknowledged, with a permanent error.
+		rejectedKeys map[string]struct{}
+		// requests is the number of requests made by the sink.
+		requests int
+	}
+}
+
+var _ messageBusClient = (*fakeMessageBusClient)(nil)
+
+func makeFakeMessageBusClient() *fakeMessageBusClient {
+	c := &fakeMessageBusClient{}
+	c.mu.published = make(map[string][]string)
+	c.mu.failedKeys = make(map[string]int)
+	c.mu.rejectedKeys = make(map[string]struct{})
+	return c
+}
+
+func (c *fakeMessageBusClient) dial(ctx context.Context) error {
+	return nil
+}
+
+func (c *fakeMessageBusClient) publish(
+	ctx context.Context, topic string, msgs []messageBusMessage,
+) ([]error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.requests++
+	if err := c.mu.permanentErr; err != nil {
+		c.mu.permanentErr = nil
+		return nil, markMessageBusPermanentError(err)
+	}
+	if c.mu.failedRequests > 0 {
+		c.mu.failedRequests--
+		return nil, errors.New("unavailable")
+	}
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		if _, ok := c.mu.rejectedKeys[string(msg.key)]; ok {
+			errs[i] = markMessageBusPermanentError(errors.New("rejected"))
+			continue
+		}
+		if c.mu.failedKeys[string(msg.key)] > 0 {
+			c.mu.failedKeys[string(msg.key)]--
+			errs[i] = errors.New("not acknowledged")
+			continue
+		}
+		c.mu.published[topic] = append(c.mu.published[topic], fmt.Sprintf("%s: %s", msg.key, msg.value))
+	}
+	return errs, nil
+}
+
+func (c *fakeMessageBusClient) publishResolved(
+	ctx context.Context, topic string, payload []byte,
+) error {
+	errs, err := c.publish(ctx, topic, []messageBusMessage{{value: payload}})
+	if err != nil {
+		return err
+	}
+	return errs[0]
+}
+
+func (c *fakeMessageBusClient) close() error {
+	return nil
+}
+
+func (c *fakeMessageBusClient) fail(requests int, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.failedRequests = requests
+	for _, key := range keys {
+		c.mu.failedKeys[key]++
+	}
+}
+
+func (c *fakeMessageBusClient) failPermanently(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.permanentErr = err
+}
+
+func (c *fakeMessageBusClient) reject(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.mu.rejectedKeys[key] = struct{}{}
+	}
+}
+
+func (c *fakeMessageBusClient) numRequests() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mu.requests
+}
+
+func (c *fakeMessageBusClient) values(topic string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.mu.published[topic]...)
+}
+
+func TestMessageBusSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	client := makeFakeMessageBusClient()
+	topicNamer, err := MakeTopicNamer(makeChangefeedTargets("t"))
+	require.NoError(t, err)
+	mt := timeutil.NewManualTime(timeutil.Now())
+	encodingOpts := changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatJSON,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	}
+	sink, err := makeMessageBusSink(ctx, "fake", client, topicNamer, encodingOpts,
+		`{"Flush": {"Messages": 2, "Frequency": "1h"}, "Retry": {"Max": 3, "Backoff": "1ms"}}`,
+		"fake_sink_config", batchConfig{}, 4 /* parallelism */, mt, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	var pool testAllocPool
+	emit := func(key, value string) {
+		require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(key), []byte(value), zeroTS, zeroTS, pool.alloc()))
+	}
+	waitForValues := func(expected ...string) {
+		testutils.SucceedsSoon(t, func() error {
+			if values := client.values("t"); len(values) != len(expected) {
+				return errors.Newf("expected %d values, got %v", len(expected), values)
+			}
+			return nil
+		})
+		require.Equal(t, expected, client.values("t"))
+	}
+
+	// The messages for a row are published once their batch is full.
+	emit(`[1]`, `v1`)
+	require.Empty(t, client.values("t"))
+	emit(`[1]`, `v2`)
+	waitForValues(`[1]: v1`, `[1]: v2`)
+
+	// They are also published once the flush frequency elapses.
+	emit(`[1]`, `v3`)
+	testutils.SucceedsSoon(t, func() error {
+		for _, timer := range mt.Timers() {
+			if timer == mt.Now().Add(time.Hour) {
+				return nil
+			}
+		}
+		return errors.New("waiting for the worker to start its timer")
+	})
+	mt.Advance(time.Hour)
+	waitForValues(`[1]: v1`, `[1]: v2`, `[1]: v3`)
+	require.EqualValues(t, 0, pool.used())
+
+	// When a message is not acknowledged, it is published again along with the
+	// messages for the same row which follow it, so that the latest version of
+	// the row is published last.
+	client.fail(0, `[2]`)
+	emit(`[2]`, `v1`)
+	emit(`[2]`, `v2`)
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[2]: v2`, `[2]: v1`, `[2]: v2`}, client.values("t")[3:])
+	require.EqualValues(t, 0, pool.used())
+
+	// Resolved timestamps are published to every topic.
+	enc, err := makeJSONEncoder(encodingOpts)
+	require.NoError(t, err)
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 2}))
+	require.Equal(t, `: {"resolved":"2.0000000000"}`, client.values("t")[6])
+
+	// Permanent errors are returned without publishing the messages again.
+	client.failPermanently(errors.New("too large"))
+	emit(`[3]`, `v1`)
+	require.Regexp(t, `publishing 1 messages to fake topic t: too large`, sink.Flush(ctx))
+}
+
+func TestMessageBusSinkRetries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	client := makeFakeMessageBusClient()
+	topicNamer, err := MakeTopicNamer(makeChangefeedTargets("t"))
+	require.NoError(t, err)
+	encodingOpts := changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatJSON,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	}
+	sink, err := makeMessageBusSink(ctx, "fake", client, topicNamer, encodingOpts,
+		`{"Retry": {"Max": 3, "Backoff": "1ms"}}`, "fake_sink_config", batchConfig{},
+		1 /* parallelism */, timeutil.DefaultTimeSource{}, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// Requests which fail are retried.
+	var pool testAllocPool
+	client.fail(2)
+	require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(`[1]`), []byte(`v1`), zeroTS, zeroTS, pool.alloc()))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[1]: v1`}, client.values("t"))
+
+	// The error is returned once the retries are exhausted.
+	client.fail(10)
+	require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(`[2]`), []byte(`v1`), zeroTS, zeroTS, pool.alloc()))
+	require.Regexp(t, `publishing 1 messages to fake topic t: unavailable`, sink.Flush(ctx))
+}
+
+func TestMessageBusSinkPermanentErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	client := makeFakeMessageBusClient()
+	topicNamer, err := MakeTopicNamer(makeChangefeedTargets("t"))
+	require.NoError(t, err)
+	encodingOpts := changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatJSON,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	}
+	sink, err := makeMessageBusSink(ctx, "fake", client, topicNamer, encodingOpts,
+		`{"Flush": {"Messages": 2, "Frequency": "1h"}, "Retry": {"Max": 3, "Backoff": "1ms"}}`, "fake_sink_config", batchConfig{},
+		1 /* parallelism */, timeutil.DefaultTimeSource{}, nilMetricsRecorderBuilder)
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// A resolved timestamp which is rejected is not published again. Resolved
+	// timestamps have no key.
+	client.reject(``)
+	enc, err := makeJSONEncoder(encodingOpts)
+	require.NoError(t, err)
+	require.Regexp(t, `publishing resolved timestamp to fake topic t: rejected`,
+		sink.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 2}))
+	require.Equal(t, 1, client.numRequests())
+
+	// A message which is rejected is not published again, even when another
+	// message of the batch only failed temporarily.
+	client.reject(`[2]`)
+	client.fail(0, `[1]`)
+	var pool testAllocPool
+	require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(`[1]`), []byte(`v1`), zeroTS, zeroTS, pool.alloc()))
+	require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(`[2]`), []byte(`v1`), zeroTS, zeroTS, pool.alloc()))
+	require.Regexp(t, `publishing 2 messages to fake topic t: rejected`, sink.Flush(ctx))
+	require.Equal(t, 2, client.numRequests())
+}
+
+func TestMessageBusSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defaultFlush := batchConfig{Messages: 5, Frequency: jsonDuration(time.Second)}
+	for _, tc := range []struct {
+		config changefeedbase.SinkSpecificJSONConfig
+		flush  batchConfig
+		err    string
+	}{
+		{config: ``, flush: defaultFlush},
+		{config: `{"Flush": {"Bytes": 10}}`, flush: batchConfig{Messages: 5, Bytes: 10, Frequency: defaultFlush.Frequency}},
+		{config: `{"Flush": {"Messages": 10, "Frequency": "1s"}, "Retry": {"Max": "inf"}}`},
+		{config: `{"Flush": {"Bytes": 10, "Frequency": "0s"}}`, err: "flush frequency is not set"},
+		{config: `{"Retry": {"Backoff": "-1s"}}`, err: "all config values must be non-negative"},
+		{config: `{"Flush": {"Messages": "a"}}`, err: "error unmarshalling json"},
+	} {
+		t.Run(string(tc.config), func(t *testing.T) {
+			flush, _, err := getMessageBusSinkConfig(tc.config, "fake_sink_config", defaultFlush)
+			if tc.err == "" {
+				require.NoError(t, err)
+				if tc.flush != (batchConfig{}) {
+					require.Equal(t, tc.flush, flush)
+				}
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	natsDefaultPort = "4222"
+	// natsHandshakeTimeout bounds the time it takes to connect to the server.
+	natsHandshakeTimeout = 10 * time.Second
+	// natsDefaultAckTimeout is the time the client waits for JetStream to
+	// acknowledge a message.
+	natsDefaultAckTimeout = 10 * time.Second
+)
+
+func isNATSSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeNATS
+}
+
+// natsClient is a messageBusClient which publishes to NATS JetStream with the
+// nats.go client. Each topic is a subject, which must be captured by a stream
+// for its messages to be acknowledged.
+//
+// The messages are published asynchronously on a single connection, and the
+// client then waits for JetStream to acknowledge each of them. Since the
+// server processes the messages of a connection in order, the messages
+// published to a subject are persisted in order.
+type natsClient struct {
+	addr       string
+	user       string
+	password   string
+	authToken  string
+	tlsConfig  *tls.Config
+	ackTimeout time.Duration
+
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+var _ messageBusClient = (*natsClient)(nil)
+
+func makeNATSSink(
+	ctx context.Context,
+	u sinkURL,
+	targets changefeedbase.Targets,
+	encodingOpts changefeedbase.EncodingOptions,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	parallelism int,
+	source timeutil.TimeSource,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if u.Host == "" {
+		return nil, errors.Errorf(`nats sink URI must specify the address of a nats server`)
+	}
+
+	// NATS messages have no key, so the key must be in the value for deletes to
+	// be usable.
+	if encodingOpts.Envelope != changefeedbase.OptEnvelopeBare && !encodingOpts.KeyInValue {
+		return nil, errors.Errorf(`this sink requires the WITH %s option`, changefeedbase.OptKeyInValue)
+	}
+
+	topicPrefix := u.consumeParam(changefeedbase.SinkParamTopicPrefix)
+	topicName := u.consumeParam(changefeedbase.SinkParamTopicName)
+	// The characters allowed in Kafka topic names are allowed in NATS subjects,
+	// in which '.' separates the tokens.
+	topicNamer, err := MakeTopicNamer(
+		targets,
+		WithPrefix(topicPrefix), WithSingleName(topicName), WithSanitizeFn(SQLNameToKafkaName))
+	if err != nil {
+		return nil, err
+	}
+
+	client := &natsClient{
+		addr:       u.Host,
+		authToken:  u.consumeParam(changefeedbase.SinkParamAuthToken),
+		ackTimeout: natsDefaultAckTimeout,
+	}
+	if _, port, err := net.SplitHostPort(u.Host); err != nil || port == "" {
+		client.addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	if u.User != nil {
+		client.user = u.User.Username()
+		client.password, _ = u.User.Password()
+	}
+	if client.tlsConfig, err = makeNATSTLSConfig(&u); err != nil {
+		return nil, err
+	}
+	if unknownParams := u.remainingQueryParams(); len(unknownParams) > 0 {
+		return nil, errors.Errorf(
+			`unknown nats sink query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	return makeMessageBusSink(ctx, "nats", client, topicNamer, encodingOpts, jsonStr,
+		changefeedbase.OptNATSSinkConfig, batchConfig{}, parallelism, source, mb)
+}
+
+// makeNATSTLSConfig returns the TLS configuration of the connection to the
+// server, or nil if TLS is not enabled.
+func makeNATSTLSConfig(u *sinkURL) (*tls.Config, error) {
+	dialConfig := struct {
+		tlsEnabled    bool
+		tlsSkipVerify bool
+		caCert        []byte
+		clientCert    []byte
+		clientKey     []byte
+	}{}
+	if _, err := u.consumeBool(changefeedbase.SinkParamTLSEnabled, &dialConfig.tlsEnabled); err != nil {
+		return nil, err
+	}
+	if _, err := u.consumeBool(changefeedbase.SinkParamSkipTLSVerify, &dialConfig.tlsSkipVerify); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamCACert, &dialConfig.caCert); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamClientCert, &dialConfig.clientCert); err != nil {
+		return nil, err
+	}
+	if err := u.decodeBase64(changefeedbase.SinkParamClientKey, &dialConfig.clientKey); err != nil {
+		return nil, err
+	}
+
+	if !dialConfig.tlsEnabled {
+		if dialConfig.caCert != nil {
+			return nil, errors.Errorf(`%s requires %s=true`, changefeedbase.SinkParamCACert, changefeedbase.SinkParamTLSEnabled)
+		}
+		if dialConfig.clientCert != nil {
+			return nil, errors.Errorf(`%s requires %s=true`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamTLSEnabled)
+		}
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: dialConfig.tlsSkipVerify,
+	}
+	if dialConfig.caCert != nil {
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(dialConfig.caCert)
+		cfg.RootCAs = caCertPool
+	}
+	if dialConfig.clientCert != nil && dialConfig.clientKey == nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+	} else if dialConfig.clientKey != nil && dialConfig.clientCert == nil {
+		return nil, errors.Errorf(`%s requires %s to be set`, changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+	if dialConfig.clientCert != nil && dialConfig.clientKey != nil {
+		cert, err := tls.X509KeyPair(dialConfig.clientCert, dialConfig.clientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, `invalid client certificate data provided`)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// dial implements the messageBusClient interface.
+func (c *natsClient) dial(ctx context.Context) error {
+	opts := []nats.Option{
+		nats.Name("cockroachdb-changefeed"),
+		nats.Timeout(natsHandshakeTimeout),
+	}
+	if c.user != "" {
+		opts = append(opts, nats.UserInfo(c.user, c.password))
+	}
+	if c.authToken != "" {
+		opts = append(opts, nats.Token(c.authToken))
+	}
+	if c.tlsConfig != nil {
+		opts = append(opts, nats.Secure(c.tlsConfig))
+	}
+	conn, err := nats.Connect("nats://"+c.addr, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to nats server %s", c.addr)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return errors.Wrapf(err, "connecting to nats server %s", c.addr)
+	}
+	c.conn, c.js = conn, js
+	return nil
+}
+
+// natsPublishError returns the error of a message which was not acknowledged,
+// marked as permanent if publishing the message again cannot succeed.
+func natsPublishError(err error) error {
+	switch {
+	case errors.Is(err, nats.ErrMaxPayload):
+		return markMessageBusPermanentError(errors.Wrap(err,
+			"message exceeds the maximum payload of the nats server"))
+	case errors.Is(err, nats.ErrNoResponders):
+		// Nothing replies to a message published to a subject which no stream
+		// captures.
+		return markMessageBusPermanentError(errors.Wrap(err,
+			"no nats jetstream stream captures the subject"))
+	}
+	var apiErr *nats.APIError
+	if errors.As(err, &apiErr) && apiErr.Code >= 400 && apiErr.Code < 500 {
+		return markMessageBusPermanentError(errors.Wrapf(err, "nats jetstream error %d", apiErr.ErrorCode))
+	}
+	return err
+}
+
+// publish implements the messageBusClient interface.
+func (c *natsClient) publish(
+	ctx context.Context, subject string, msgs []messageBusMessage,
+) ([]error, error) {
+	futures := make([]nats.PubAckFuture, len(msgs))
+	for i, msg := range msgs {
+		var err error
+		if futures[i], err = c.js.PublishAsync(subject, msg.value); err != nil {
+			return nil, natsPublishError(err)
+		}
+	}
+
+	errs := make([]error, len(msgs))
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	timer.Reset(c.ackTimeout)
+	for i, future := range futures {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-future.Ok():
+		case err := <-future.Err():
+			errs[i] = natsPublishError(err)
+		case <-timer.C:
+			timer.Read = true
+			for ; i < len(errs); i++ {
+				errs[i] = errors.Newf("timed out waiting for nats jetstream to acknowledge the message after %s",
+					c.ackTimeout)
+			}
+			return errs, nil
+		}
+	}
+	return errs, nil
+}
+
+// publishResolved implements the messageBusClient interface. Subjects have no
+// partitions, so the message is published once.
+func (c *natsClient) publishResolved(ctx context.Context, subject string, payload []byte) error {
+	errs, err := c.publish(ctx, subject, []messageBusMessage{{value: payload}})
+	if err != nil {
+		return err
+	}
+	return errs[0]
+}
+
+// close implements the messageBusClient interface.
+func (c *natsClient) close() error {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	return nil
+}
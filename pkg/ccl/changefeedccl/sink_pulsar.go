@@ -0,0 +1,253 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// The tenant and namespace of the topics when the sink URI has no path.
+const (
+	pulsarDefaultTenant    = "public"
+	pulsarDefaultNamespace = "default"
+)
+
+func isPulsarSink(u *url.URL) bool {
+	switch u.Scheme {
+	case changefeedbase.SinkSchemePulsarHTTP, changefeedbase.SinkSchemePulsarHTTPS:
+		return true
+	default:
+		return false
+	}
+}
+
+// pulsarClient is a messageBusClient which publishes to the persistent topics
+// of an Apache Pulsar namespace, using the REST producer API of the brokers
+// (available since Pulsar 2.8). The messages are published as strings, and the
+// key of a message is the key of its row, so that Pulsar routes the messages
+// for a row to the same partition of a partitioned topic.
+type pulsarClient struct {
+	client *httputil.Client
+	// baseURL is the URL of the web service of a broker.
+	baseURL   string
+	tenant    string
+	namespace string
+	authToken string
+}
+
+var _ messageBusClient = (*pulsarClient)(nil)
+
+type pulsarProducerMessage struct {
+	Key     string `json:"key,omitempty"`
+	Payload string `json:"payload"`
+}
+
+type pulsarProducerMessages struct {
+	Messages []pulsarProducerMessage `json:"messages"`
+}
+
+// pulsarPublishResponse is the response to a request publishing messages. It
+// contains the result of each message, whose error code is 0 if the message
+// was persisted.
+type pulsarPublishResponse struct {
+	MessagePublishResults []struct {
+		MessageID string `json:"messageId"`
+		ErrorCode int    `json:"errorCode"`
+		Error     string `json:"error"`
+	} `json:"messagePublishResults"`
+}
+
+func makePulsarSink(
+	ctx context.Context,
+	u sinkURL,
+	targets changefeedbase.Targets,
+	encodingOpts changefeedbase.EncodingOptions,
+	jsonStr changefeedbase.SinkSpecificJSONConfig,
+	parallelism int,
+	source timeutil.TimeSource,
+	mb metricsRecorderBuilder,
+) (Sink, error) {
+	if u.Host == "" {
+		return nil, errors.Errorf(`pulsar sink URI must specify the address of the pulsar web service`)
+	}
+	tenant, namespace := pulsarDefaultTenant, pulsarDefaultNamespace
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf(`pulsar sink URI path must be of the form /<tenant>/<namespace>, got %q`, u.Path)
+		}
+		tenant, namespace = parts[0], parts[1]
+	}
+
+	topicPrefix := u.consumeParam(changefeedbase.SinkParamTopicPrefix)
+	topicName := u.consumeParam(changefeedbase.SinkParamTopicName)
+	// Pulsar topic names have the same restrictions as Kafka topic names.
+	topicNamer, err := MakeTopicNamer(
+		targets,
+		WithPrefix(topicPrefix), WithSingleName(topicName), WithSanitizeFn(SQLNameToKafkaName))
+	if err != nil {
+		return nil, err
+	}
+
+	authToken := u.consumeParam(changefeedbase.SinkParamAuthToken)
+	httpClient, err := makeWebhookClient(u, 0 /* timeout */)
+	if err != nil {
+		return nil, err
+	}
+	if unknownParams := u.remainingQueryParams(); len(unknownParams) > 0 {
+		return nil, errors.Errorf(
+			`unknown pulsar sink query parameters: %s`, strings.Join(unknownParams, ", "))
+	}
+
+	scheme := strings.TrimPrefix(u.Scheme, `pulsar-`)
+	client := &pulsarClient{
+		client:    httpClient,
+		baseURL:   (&url.URL{Scheme: scheme, Host: u.Host}).String(),
+		tenant:    tenant,
+		namespace: namespace,
+		authToken: authToken,
+	}
+	return makeMessageBusSink(ctx, "pulsar", client, topicNamer, encodingOpts, jsonStr,
+		changefeedbase.OptPulsarSinkConfig, batchConfig{}, parallelism, source, mb)
+}
+
+// topicPath returns the path of the topic, relative to the path of the API.
+func (c *pulsarClient) topicPath(topic string) string {
+	return fmt.Sprintf("persistent/%s/%s/%s",
+		url.PathEscape(c.tenant), url.PathEscape(c.namespace), url.PathEscape(topic))
+}
+
+// dial implements the messageBusClient interface.
+func (c *pulsarClient) dial(ctx context.Context) error {
+	return nil
+}
+
+// publish implements the messageBusClient interface.
+func (c *pulsarClient) publish(
+	ctx context.Context, topic string, msgs []messageBusMessage,
+) ([]error, error) {
+	req := pulsarProducerMessages{Messages: make([]pulsarProducerMessage, len(msgs))}
+	for i, msg := range msgs {
+		req.Messages[i] = pulsarProducerMessage{Key: string(msg.key), Payload: string(msg.value)}
+	}
+	return c.publishMessages(ctx, "topics/"+c.topicPath(topic), req)
+}
+
+// publishResolved implements the messageBusClient interface.
+func (c *pulsarClient) publishResolved(ctx context.Context, topic string, payload []byte) error {
+	partitions, err := c.partitions(ctx, topic)
+	if err != nil {
+		return err
+	}
+	req := pulsarProducerMessages{Messages: []pulsarProducerMessage{{Payload: string(payload)}}}
+	publish := func(path string) error {
+		errs, err := c.publishMessages(ctx, path, req)
+		if err != nil {
+			return err
+		}
+		return errs[0]
+	}
+	// A topic which is not partitioned has no partitions.
+	if partitions == 0 {
+		return publish("topics/" + c.topicPath(topic))
+	}
+	for i := 0; i < partitions; i++ {
+		if err := publish(fmt.Sprintf("topics/%s/partitions/%d", c.topicPath(topic), i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitions returns the number of partitions of the topic.
+func (c *pulsarClient) partitions(ctx context.Context, topic string) (int, error) {
+	var res struct {
+		Partitions int `json:"partitions"`
+	}
+	path := "admin/v2/" + c.topicPath(topic) + "/partitions"
+	if err := c.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+		return 0, errors.Wrapf(err, "getting the partitions of pulsar topic %s", topic)
+	}
+	return res.Partitions, nil
+}
+
+func (c *pulsarClient) publishMessages(
+	ctx context.Context, path string, req pulsarProducerMessages,
+) ([]error, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var res pulsarPublishResponse
+	if err := c.do(ctx, http.MethodPost, path, body, &res); err != nil {
+		return nil, err
+	}
+	if len(res.MessagePublishResults) != len(req.Messages) {
+		return nil, errors.Errorf("expected %d results from pulsar, got %d",
+			len(req.Messages), len(res.MessagePublishResults))
+	}
+	errs := make([]error, len(req.Messages))
+	for i, result := range res.MessagePublishResults {
+		if result.ErrorCode != 0 {
+			errs[i] = errors.Newf("pulsar error code %d: %s", result.ErrorCode, result.Error)
+		}
+	}
+	return errs, nil
+}
+
+// do sends a request to the web service and decodes its response into res.
+func (c *pulsarClient) do(
+	ctx context.Context, method, path string, body []byte, res interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/"+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", applicationTypeJSON)
+	}
+	if c.authToken != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+c.authToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	resBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read body for HTTP response with status: %d", resp.StatusCode)
+	}
+	if !(resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
+		return errors.Newf("%s: %s", resp.Status, string(resBody))
+	}
+	if err := json.Unmarshal(resBody, res); err != nil {
+		return errors.Wrapf(err, "decoding response %q", resBody)
+	}
+	return nil
+}
+
+// close implements the messageBusClient interface.
+func (c *pulsarClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
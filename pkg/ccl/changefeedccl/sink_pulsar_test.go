@@ -0,0 +1,188 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+// pulsarStandIn is a local HTTP stand-in for the REST API of a Pulsar broker.
+type pulsarStandIn struct {
+	server *httptest.Server
+	// partitions is the number of partitions of every topic.
+	partitions int
+
+	mu struct {
+		syncutil.Mutex
+		// messages contains the messages published to each topic or partition,
+		// by path.
+		messages map[string][]string
+		// failedKeys is the number of times the next messages with each key are
+		// rejected.
+		failedKeys map[string]int
+	}
+}
+
+func startPulsarStandIn(partitions int) *pulsarStandIn {
+	s := &pulsarStandIn{partitions: partitions}
+	s.mu.messages = make(map[string][]string)
+	s.mu.failedKeys = make(map[string]int)
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *pulsarStandIn) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var res interface{}
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/admin/v2/persistent/"):
+		res = map[string]int{"partitions": s.partitions}
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/topics/persistent/"):
+		var req pulsarProducerMessages
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res = s.publish(strings.TrimPrefix(r.URL.Path, "/topics/persistent/"), req.Messages)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		panic(err)
+	}
+}
+
+func (s *pulsarStandIn) publish(path string, msgs []pulsarProducerMessage) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]map[string]interface{}, len(msgs))
+	for i, msg := range msgs {
+		if s.mu.failedKeys[msg.Key] > 0 {
+			s.mu.failedKeys[msg.Key]--
+			results[i] = map[string]interface{}{"errorCode": 1, "error": "timed out"}
+			continue
+		}
+		s.mu.messages[path] = append(s.mu.messages[path], fmt.Sprintf("%s: %s", msg.Key, msg.Payload))
+		results[i] = map[string]interface{}{"errorCode": 0, "messageId": "1:1:-1:0"}
+	}
+	return map[string]interface{}{"messagePublishResults": results}
+}
+
+func (s *pulsarStandIn) fail(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failedKeys[key]++
+}
+
+func (s *pulsarStandIn) messages(path string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.messages[path]
+}
+
+func makeTestPulsarSink(
+	t *testing.T, uri string, jsonConfig changefeedbase.SinkSpecificJSONConfig,
+) (Sink, error) {
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	encodingOpts := changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatJSON,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	}
+	return makePulsarSink(context.Background(), sinkURL{URL: u}, makeChangefeedTargets("t"),
+		encodingOpts, jsonConfig, 2 /* parallelism */, timeutil.DefaultTimeSource{}, nilMetricsRecorderBuilder)
+}
+
+func TestPulsarSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	standIn := startPulsarStandIn(2 /* partitions */)
+	defer standIn.server.Close()
+
+	uri := strings.Replace(standIn.server.URL, "http://", "pulsar-http://", 1) +
+		"/tenant/ns?topic_prefix=cdc-&auth_token=token"
+	sink, err := makeTestPulsarSink(t, uri, `{"Retry": {"Backoff": "1ms"}}`)
+	require.NoError(t, err)
+	require.NoError(t, sink.Dial())
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	var pool testAllocPool
+	emit := func(key, value string) {
+		require.NoError(t, sink.EmitRow(ctx, topic("t"), []byte(key), []byte(value), zeroTS, zeroTS, pool.alloc()))
+	}
+
+	// The messages are published with the key of their row, and the messages
+	// which are rejected are published again.
+	standIn.fail(`[1]`)
+	emit(`[1]`, `v1`)
+	emit(`[1]`, `v2`)
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[1]: v1`, `[1]: v2`}, standIn.messages("tenant/ns/cdc-t"))
+	require.EqualValues(t, 0, pool.used())
+
+	// Resolved timestamps are published to every partition.
+	enc, err := makeJSONEncoder(changefeedbase.EncodingOptions{
+		Format:   changefeedbase.OptFormatJSON,
+		Envelope: changefeedbase.OptEnvelopeWrapped,
+	})
+	require.NoError(t, err)
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 2}))
+	for _, partition := range []string{"0", "1"} {
+		require.Equal(t, []string{`: {"resolved":"2.0000000000"}`},
+			standIn.messages("tenant/ns/cdc-t/partitions/"+partition))
+	}
+}
+
+func TestPulsarSinkConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		uri    string
+		config changefeedbase.SinkSpecificJSONConfig
+		err    string
+	}{
+		{uri: "pulsar-https://broker:8443"},
+		{uri: "pulsar-https://broker:8443/tenant/ns/?topic_name=t&auth_token=a"},
+		{uri: "pulsar-https:///tenant/ns", err: "must specify the address of the pulsar web service"},
+		{uri: "pulsar-https://broker/tenant", err: "path must be of the form /<tenant>/<namespace>"},
+		{uri: "pulsar-https://broker?foo=bar", err: "unknown pulsar sink query parameters: foo"},
+		{uri: "pulsar-https://broker", config: `{"Flush": {"Messages": 10}}`, err: "flush frequency is not set"},
+	} {
+		t.Run(fmt.Sprintf("%s %s", tc.uri, tc.config), func(t *testing.T) {
+			_, err := makeTestPulsarSink(t, tc.uri, tc.config)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Regexp(t, tc.err, err)
+			}
+		})
+	}
+}
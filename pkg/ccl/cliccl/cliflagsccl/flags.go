@@ -34,6 +34,10 @@ Valid fields:
 * key     (required): path to the current key file, or "plain"
 * old-key (required): path to the previous key file, or "plain"
 * rotation-period   : amount of time after which data keys should be rotated
+* cipher-mode       : "ctr" (default) or "gcm". GCM also authenticates the data,
+                      so that tampered files are detected when read. Files
+                      written with retired keys are re-encrypted in the
+                      background.
 
 </PRE>
 example:
@@ -116,7 +116,10 @@ func closedTimestampLikelySufficient(
 ) bool {
 	var offset time.Duration
 	switch ctPolicy {
-	case roachpb.LAG_BY_CLUSTER_SETTING:
+	case roachpb.LAG_BY_CLUSTER_SETTING, roachpb.LAG_BY_READ_REPLICA_TARGET:
+		// NOTE: the lag target of ranges with read replicas is not known here.
+		// It is tighter than the cluster setting, so the cluster setting is a
+		// conservative estimate.
 		offset = getFollowerReadLag(st)
 	case roachpb.LEAD_FOR_GLOBAL_READS:
 		offset = getGlobalReadsLead(clock)
@@ -547,6 +547,13 @@ func TestTenantLogic_default(
 	runLogicTest(t, "default")
 }
 
+func TestTenantLogic_deferrable_constraints(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "deferrable_constraints")
+}
+
 func TestTenantLogic_delete(
 	t *testing.T,
 ) {
@@ -631,6 +638,13 @@ func TestTenantLogic_distsql_tenant(
 	runLogicTest(t, "distsql_tenant")
 }
 
+func TestTenantLogic_domain(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "domain")
+}
+
 func TestTenantLogic_drop_database(
 	t *testing.T,
 ) {
@@ -750,6 +764,13 @@ func TestTenantLogic_exclude_data_from_backup(
 	runLogicTest(t, "exclude_data_from_backup")
 }
 
+func TestTenantLogic_exclusion_constraints(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "exclusion_constraints")
+}
+
 func TestTenantLogic_experimental_distsql_planning(
 	t *testing.T,
 ) {
@@ -897,6 +918,13 @@ func TestTenantLogic_grant_schema(
 	runLogicTest(t, "grant_schema")
 }
 
+func TestTenantLogic_grouping_sets(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "grouping_sets")
+}
+
 func TestTenantLogic_hash_join(
 	t *testing.T,
 ) {
@@ -1079,6 +1107,13 @@ func TestTenantLogic_limit(
 	runLogicTest(t, "limit")
 }
 
+func TestTenantLogic_listen_notify(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "listen_notify")
+}
+
 func TestTenantLogic_lock_timeout(
 	t *testing.T,
 ) {
@@ -1114,6 +1149,13 @@ func TestTenantLogic_materialized_view(
 	runLogicTest(t, "materialized_view")
 }
 
+func TestTenantLogic_merge(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "merge")
+}
+
 func TestTenantLogic_merge_join(
 	t *testing.T,
 ) {
@@ -1345,6 +1387,13 @@ func TestTenantLogic_privileges_table(
 	runLogicTest(t, "privileges_table")
 }
 
+func TestTenantLogic_procedure(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "procedure")
+}
+
 func TestTenantLogic_propagate_input_ordering(
 	t *testing.T,
 ) {
@@ -1352,6 +1401,20 @@ func TestTenantLogic_propagate_input_ordering(
 	runLogicTest(t, "propagate_input_ordering")
 }
 
+func TestTenantLogic_publication(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "publication")
+}
+
+func TestTenantLogic_read_committed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "read_committed")
+}
+
 func TestTenantLogic_reassign_owned_by(
 	t *testing.T,
 ) {
@@ -1744,6 +1807,13 @@ func TestTenantLogic_srfs(
 	runLogicTest(t, "srfs")
 }
 
+func TestTenantLogic_sst_options(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "sst_options")
+}
+
 func TestTenantLogic_statement_source(
 	t *testing.T,
 ) {
@@ -1884,6 +1954,13 @@ func TestTenantLogic_timetz(
 	runLogicTest(t, "timetz")
 }
 
+func TestTenantLogic_triggers(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "triggers")
+}
+
 func TestTenantLogic_trigram_builtins(
 	t *testing.T,
 ) {
@@ -1905,6 +1982,13 @@ func TestTenantLogic_truncate(
 	runLogicTest(t, "truncate")
 }
 
+func TestTenantLogic_tsvector(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "tsvector")
+}
+
 func TestTenantLogic_tuple(
 	t *testing.T,
 ) {
@@ -1961,6 +2045,13 @@ func TestTenantLogic_udf(
 	runLogicTest(t, "udf")
 }
 
+func TestTenantLogic_udf_plpgsql(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "udf_plpgsql")
+}
+
 func TestTenantLogic_union(
 	t *testing.T,
 ) {
@@ -39,6 +39,22 @@ const (
 func (c *FileCipherStreamCreator) CreateNew(
 	ctx context.Context,
 ) (*enginepbccl.EncryptionSettings, FileStream, error) {
+	settings, key, err := c.newSettings(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, err := newFileStream(key, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, stream, nil
+}
+
+// newSettings generates the encryption settings for a new file using the currently active key,
+// which it also returns. The key is nil if the file is not encrypted.
+func (c *FileCipherStreamCreator) newSettings(
+	ctx context.Context,
+) (*enginepbccl.EncryptionSettings, *enginepbccl.SecretKey, error) {
 	key, err := c.keyManager.ActiveKey(ctx)
 	if err != nil {
 		return nil, nil, err
@@ -46,12 +62,15 @@ func (c *FileCipherStreamCreator) CreateNew(
 	settings := &enginepbccl.EncryptionSettings{}
 	if key == nil || key.Info.EncryptionType == enginepbccl.EncryptionType_Plaintext {
 		settings.EncryptionType = enginepbccl.EncryptionType_Plaintext
-		stream := &filePlainStream{}
-		return settings, stream, nil
+		return settings, nil, nil
 	}
 	settings.EncryptionType = key.Info.EncryptionType
 	settings.KeyId = key.Info.KeyId
-	settings.Nonce = make([]byte, ctrNonceSize)
+	nonceSize := ctrNonceSize
+	if isGCMEncryptionType(settings.EncryptionType) {
+		nonceSize = gcmFileNonceSize
+	}
+	settings.Nonce = make([]byte, nonceSize)
 	_, err = rand.Read(settings.Nonce)
 	if err != nil {
 		return nil, nil, err
@@ -62,11 +81,7 @@ func (c *FileCipherStreamCreator) CreateNew(
 	}
 	// Does not matter how we convert 4 random bytes into uint32
 	settings.Counter = binary.LittleEndian.Uint32(counterBytes)
-	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
-	if err != nil {
-		return nil, nil, err
-	}
-	return settings, &fileCipherStream{bcs: ctrCS}, nil
+	return settings, key, nil
 }
 
 // CreateExisting creates a FileStream for an existing file by looking up the key described by
@@ -74,12 +89,35 @@ func (c *FileCipherStreamCreator) CreateNew(
 func (c *FileCipherStreamCreator) CreateExisting(
 	settings *enginepbccl.EncryptionSettings,
 ) (FileStream, error) {
+	key, err := c.existingKey(settings)
+	if err != nil {
+		return nil, err
+	}
+	return newFileStream(key, settings)
+}
+
+// existingKey looks up the key described by the settings of an existing file in the key manager.
+// It returns nil if the file is not encrypted.
+func (c *FileCipherStreamCreator) existingKey(
+	settings *enginepbccl.EncryptionSettings,
+) (*enginepbccl.SecretKey, error) {
 	if settings == nil || settings.EncryptionType == enginepbccl.EncryptionType_Plaintext {
+		return nil, nil
+	}
+	return c.keyManager.GetKey(settings.KeyId)
+}
+
+// newFileStream creates the FileStream for a file encrypted with the given key and settings. The
+// key is nil if the file is not encrypted. Files encrypted with AES-GCM cannot be encrypted in
+// place, so they don't have a FileStream: see gcmFile instead.
+func newFileStream(
+	key *enginepbccl.SecretKey, settings *enginepbccl.EncryptionSettings,
+) (FileStream, error) {
+	if key == nil {
 		return &filePlainStream{}, nil
 	}
-	key, err := c.keyManager.GetKey(settings.KeyId)
-	if err != nil {
-		return nil, err
+	if isGCMEncryptionType(settings.EncryptionType) {
+		return nil, fmt.Errorf("no FileStream for EncryptionType: %s", settings.EncryptionType)
 	}
 	ctrCS, err := newCTRBlockCipherStream(key, settings.Nonce, settings.Counter)
 	if err != nil {
@@ -164,10 +202,12 @@ type cTRBlockCipherStream struct {
 func newCTRBlockCipherStream(
 	key *enginepbccl.SecretKey, nonce []byte, counter uint32,
 ) (*cTRBlockCipherStream, error) {
+	// NB: the key may be used with AES-GCM for new files, while this file was
+	// written with AES-CTR before the cipher mode was changed.
 	switch key.Info.EncryptionType {
-	case enginepbccl.EncryptionType_AES128_CTR:
-	case enginepbccl.EncryptionType_AES192_CTR:
-	case enginepbccl.EncryptionType_AES256_CTR:
+	case enginepbccl.EncryptionType_AES128_CTR, enginepbccl.EncryptionType_AES128_GCM:
+	case enginepbccl.EncryptionType_AES192_CTR, enginepbccl.EncryptionType_AES192_GCM:
+	case enginepbccl.EncryptionType_AES256_CTR, enginepbccl.EncryptionType_AES256_GCM:
 	default:
 		return nil, fmt.Errorf("unknown EncryptionType: %d", key.Info.EncryptionType)
 	}
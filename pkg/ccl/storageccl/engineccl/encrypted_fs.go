@@ -11,6 +11,7 @@ package engineccl
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
@@ -43,7 +44,14 @@ import (
 //   key id and the key.
 // - The store-FS is used only for storing the key file for the generated keys. It is used by
 //   the DataKeyManager. These keys are rotated periodically in a simple manner -- a new
-//   active key is generated for future file writes. Existing files are not affected.
+//   active key is generated for future file writes. Existing files are not affected, but
+//   the sstables using retired keys are rewritten in the background by the reencryptor
+//   (see reencrypt.go).
+//
+// Files are encrypted using AES-CTR, or AES-GCM when the store keys are used in GCM mode.
+// AES-CTR encrypts data in place at arbitrary offsets (see ctr_stream.go), but cannot detect
+// modified files. AES-GCM authenticates the data, so it stores files as a sequence of
+// records instead (see gcm_stream.go).
 //
 // The data-FS and store-FS both use a common implementation. They consume:
 // - the FS they are wrapping: it is always the base-FS in our case, but it does not matter.
@@ -104,21 +112,134 @@ func (f *encryptedFile) ReadAt(p []byte, off int64) (n int, err error) {
 	return n, err
 }
 
+// newEncryptedFile wraps f so that its contents are encrypted and decrypted using the given key
+// and settings. The key is nil if f is not encrypted. created is true if f was just created.
+func newEncryptedFile(
+	f vfs.File, key *enginepbccl.SecretKey, settings *enginepbccl.EncryptionSettings, created bool,
+) (vfs.File, error) {
+	if key != nil && isGCMEncryptionType(settings.EncryptionType) {
+		stream, err := newGCMStream(key, settings.Nonce, settings.Counter)
+		if err != nil {
+			return nil, err
+		}
+		return newGCMFile(f, stream, created), nil
+	}
+	stream, err := newFileStream(key, settings)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedFile{File: f, stream: stream}, nil
+}
+
 // encryptedFS implements vfs.FS.
 type encryptedFS struct {
 	vfs.FS
 	fileRegistry  *storage.PebbleFileRegistry
 	streamCreator *FileCipherStreamCreator
+	// swapMu is held in write mode by the reencryptor while it replaces a file by its
+	// re-encrypted copy, and in read mode by the operations which must not observe the
+	// file and its file registry entry in an inconsistent state.
+	swapMu syncutil.RWMutex
+	// gcmSizes caches the size of the plaintext of files encrypted with AES-GCM,
+	// which Stat could otherwise only compute by reading the header of every
+	// record of the file.
+	gcmSizes gcmSizeCache
+}
+
+// gcmSizeCache maps the names of files encrypted with AES-GCM to the size of
+// their plaintext. An entry is only used while the size of the underlying file
+// is the one it was computed for, so that a file modified without going through
+// the encryptedFS is not reported with a stale size.
+type gcmSizeCache struct {
+	syncutil.Mutex
+	sizes map[string]gcmSizeEntry
+}
+
+type gcmSizeEntry struct {
+	size, fileSize int64
+}
+
+func (c *gcmSizeCache) get(name string, fileSize int64) (int64, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.sizes[name]
+	if !ok || e.fileSize != fileSize {
+		return 0, false
+	}
+	return e.size, true
+}
+
+func (c *gcmSizeCache) set(name string, size, fileSize int64) {
+	c.Lock()
+	defer c.Unlock()
+	if c.sizes == nil {
+		c.sizes = make(map[string]gcmSizeEntry)
+	}
+	c.sizes[name] = gcmSizeEntry{size: size, fileSize: fileSize}
+}
+
+func (c *gcmSizeCache) remove(name string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.sizes, name)
+}
+
+// rename moves the entry of oldname to newname. The entry of newname is
+// removed if oldname has none.
+func (c *gcmSizeCache) rename(oldname, newname string) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.sizes[oldname]
+	delete(c.sizes, oldname)
+	if ok {
+		c.sizes[newname] = e
+	} else {
+		delete(c.sizes, newname)
+	}
+}
+
+// link copies the entry of oldname to newname.
+func (c *gcmSizeCache) link(oldname, newname string) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.sizes[oldname]; ok {
+		c.sizes[newname] = e
+	} else {
+		delete(c.sizes, newname)
+	}
+}
+
+// withFd returns ef, which wraps f, exposing the file descriptor of f. Files
+// encrypted with AES-GCM buffer the data written to them until it fills a
+// record or the file is synced, so their file descriptor is not exposed: Pebble
+// would sync it directly, e.g. in vfs.NewSyncingFile, without writing the
+// buffered data first.
+func (fs *encryptedFS) withFd(name string, f, ef vfs.File) vfs.File {
+	if gf, ok := ef.(*gcmFile); ok {
+		gf.onSize = func(size, fileSize int64) {
+			fs.gcmSizes.set(name, size, fileSize)
+		}
+		return gf
+	}
+	return vfs.WithFd(f, ef)
 }
 
 // Create implements vfs.FS.Create.
 func (fs *encryptedFS) Create(name string) (vfs.File, error) {
+	fs.swapMu.RLock()
+	defer fs.swapMu.RUnlock()
 	f, err := fs.FS.Create(name)
 	if err != nil {
 		return f, err
 	}
+	fs.gcmSizes.remove(name)
 	// NB: f.Close() must be called except in the case of a successful return.
-	settings, stream, err := fs.streamCreator.CreateNew(context.TODO())
+	settings, key, err := fs.streamCreator.newSettings(context.TODO())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	ef, err := newEncryptedFile(f, key, settings, true /* created */)
 	if err != nil {
 		_ = f.Close()
 		return nil, err
@@ -143,52 +264,95 @@ func (fs *encryptedFS) Create(name string) (vfs.File, error) {
 			return nil, err
 		}
 	}
-	ef := &encryptedFile{File: f, stream: stream}
-	return vfs.WithFd(f, ef), nil
+	return fs.withFd(name, f, ef), nil
 }
 
 // Link implements vfs.FS.Link.
 func (fs *encryptedFS) Link(oldname, newname string) error {
+	fs.swapMu.RLock()
+	defer fs.swapMu.RUnlock()
 	if err := fs.FS.Link(oldname, newname); err != nil {
 		return err
 	}
+	fs.gcmSizes.link(oldname, newname)
 	return fs.fileRegistry.MaybeLinkEntry(oldname, newname)
 }
 
 // Open implements vfs.FS.Open.
 func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	fs.swapMu.RLock()
+	defer fs.swapMu.RUnlock()
+	name = fs.maybeResolveInterruptedSwap(name)
 	f, err := fs.FS.Open(name, opts...)
 	if err != nil {
 		return f, err
 	}
-	fileEntry := fs.fileRegistry.GetFileEntry(name)
-	var settings *enginepbccl.EncryptionSettings
-	if fileEntry != nil {
-		if fileEntry.EnvType != fs.streamCreator.envType {
-			f.Close()
-			return nil, fmt.Errorf("filename: %s has env %d not equal to FS env %d",
-				name, fileEntry.EnvType, fs.streamCreator.envType)
-		}
-		settings = &enginepbccl.EncryptionSettings{}
-		if err := protoutil.Unmarshal(fileEntry.EncryptionSettings, settings); err != nil {
-			f.Close()
-			return nil, err
-		}
+	settings, err := fs.fileSettings(name)
+	if err != nil {
+		f.Close()
+		return nil, err
 	}
-	stream, err := fs.streamCreator.CreateExisting(settings)
+	key, err := fs.streamCreator.existingKey(settings)
 	if err != nil {
 		f.Close()
 		return nil, err
 	}
-	ef := &encryptedFile{File: f, stream: stream}
-	return vfs.WithFd(f, ef), nil
+	ef, err := newEncryptedFile(f, key, settings, false /* created */)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return fs.withFd(name, f, ef), nil
+}
+
+// fileSettings returns the encryption settings of the named file, or nil if the file is not
+// encrypted.
+func (fs *encryptedFS) fileSettings(name string) (*enginepbccl.EncryptionSettings, error) {
+	fileEntry := fs.fileRegistry.GetFileEntry(name)
+	if fileEntry == nil {
+		return nil, nil
+	}
+	if fileEntry.EnvType != fs.streamCreator.envType {
+		return nil, fmt.Errorf("filename: %s has env %d not equal to FS env %d",
+			name, fileEntry.EnvType, fs.streamCreator.envType)
+	}
+	settings := &enginepbccl.EncryptionSettings{}
+	if err := protoutil.Unmarshal(fileEntry.EncryptionSettings, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Stat implements vfs.FS.Stat. Like for the files opened by Open, the size of files encrypted
+// with AES-GCM is the size of their plaintext, which is cached once it was computed.
+func (fs *encryptedFS) Stat(name string) (os.FileInfo, error) {
+	settings, err := fs.fileSettings(name)
+	if err != nil || settings == nil || !isGCMEncryptionType(settings.EncryptionType) {
+		return fs.FS.Stat(name)
+	}
+	info, err := fs.FS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if size, ok := fs.gcmSizes.get(name, info.Size()); ok {
+		return gcmFileInfo{FileInfo: info, size: size}, nil
+	}
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
 }
 
 // Remove implements vfs.FS.Remove.
 func (fs *encryptedFS) Remove(name string) error {
+	fs.swapMu.RLock()
+	defer fs.swapMu.RUnlock()
 	if err := fs.FS.Remove(name); err != nil {
 		return err
 	}
+	fs.gcmSizes.remove(name)
 	return fs.fileRegistry.MaybeDeleteEntry(name)
 }
 
@@ -208,6 +372,8 @@ func (fs *encryptedFS) Remove(name string) error {
 // will contain a dangling entry for the old path. The dangling entry
 // will be elided when the file registry is loaded again.
 func (fs *encryptedFS) Rename(oldname, newname string) error {
+	fs.swapMu.RLock()
+	defer fs.swapMu.RUnlock()
 	// First copy the metadata from the old name to the new name. If a
 	// file exists at newname, this copy action will make the file at
 	// newname unlegible, because the encryption-at-rest metadata will
@@ -222,6 +388,7 @@ func (fs *encryptedFS) Rename(oldname, newname string) error {
 	if err := fs.FS.Rename(oldname, newname); err != nil {
 		return err
 	}
+	fs.gcmSizes.rename(oldname, newname)
 	// Remove the old name's metadata.
 	return fs.fileRegistry.MaybeDeleteEntry(oldname)
 }
@@ -244,6 +411,8 @@ func (fs *encryptedFS) ReuseForWrite(oldname, newname string) (vfs.File, error)
 type encryptionStatsHandler struct {
 	storeKM *StoreKeyManager
 	dataKM  *DataKeyManager
+	// reencryptor is nil for read-only stores.
+	reencryptor *reencryptor
 }
 
 func (e *encryptionStatsHandler) GetEncryptionStatus() ([]byte, error) {
@@ -258,6 +427,9 @@ func (e *encryptionStatsHandler) GetEncryptionStatus() ([]byte, error) {
 	if k != nil {
 		s.ActiveDataKey = k.Info
 	}
+	if e.reencryptor != nil {
+		s.Reencryption = e.reencryptor.status()
+	}
 	return protoutil.Marshal(&s)
 }
 
@@ -317,6 +489,7 @@ func newEncryptedEnv(
 		fs:                fs,
 		activeKeyFilename: options.KeyFiles.CurrentKey,
 		oldKeyFilename:    options.KeyFiles.OldKey,
+		authenticated:     options.CipherMode == baseccl.EncryptionCipherMode_GCM,
 	}
 	if err := storeKeyManager.Load(context.TODO()); err != nil {
 		return nil, err
@@ -347,7 +520,11 @@ func newEncryptedEnv(
 		},
 	}
 
+	var r *reencryptor
 	if !readOnly {
+		if err := dataFS.recoverInterruptedSwaps(dbDir); err != nil {
+			return nil, err
+		}
 		key, err := storeKeyManager.ActiveKey(context.TODO())
 		if err != nil {
 			return nil, err
@@ -355,18 +532,36 @@ func newEncryptedEnv(
 		if err := dataKeyManager.SetActiveStoreKeyInfo(context.TODO(), key.Info); err != nil {
 			return nil, err
 		}
+		r = newReencryptor(dataFS, dbDir)
+		r.start()
 	}
 
 	return &storage.EncryptionEnv{
-		Closer: dataKeyManager,
+		Closer: &encryptedEnvCloser{reencryptor: r, dataKM: dataKeyManager},
 		FS:     dataFS,
 		StatsHandler: &encryptionStatsHandler{
-			storeKM: storeKeyManager,
-			dataKM:  dataKeyManager,
+			storeKM:     storeKeyManager,
+			dataKM:      dataKeyManager,
+			reencryptor: r,
 		},
 	}, nil
 }
 
+// encryptedEnvCloser closes an encrypted environment.
+type encryptedEnvCloser struct {
+	// reencryptor is nil for read-only stores.
+	reencryptor *reencryptor
+	dataKM      *DataKeyManager
+}
+
+// Close implements io.Closer.
+func (c *encryptedEnvCloser) Close() error {
+	if c.reencryptor != nil {
+		c.reencryptor.stop()
+	}
+	return c.dataKM.Close()
+}
+
 func canRegistryElide(entry *enginepb.FileEntry) bool {
 	if entry == nil {
 		return true
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/storageutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -221,6 +222,62 @@ func TestEncryptedFSUnencryptedFiles(t *testing.T) {
 	}
 }
 
+// TestEncryptedFSGCMSyncingFile checks that the data written to a file encrypted with AES-GCM
+// is durable once the file is synced through a vfs.NewSyncingFile, which syncs the file
+// descriptor of the files which expose one.
+func TestEncryptedFSGCMSyncingFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Use the disk, whose files have a file descriptor.
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	diskFS := vfs.Default
+	fileRegistry := &storage.PebbleFileRegistry{FS: diskFS, DBDir: dir}
+	require.NoError(t, fileRegistry.Load())
+
+	keyFile := diskFS.PathJoin(dir, "16.key")
+	writeToFile(t, diskFS, keyFile, []byte(keyFile128))
+	keyManager := &StoreKeyManager{
+		fs: diskFS, activeKeyFilename: keyFile, oldKeyFilename: "plain", authenticated: true,
+	}
+	require.NoError(t, keyManager.Load(context.Background()))
+	streamCreator := &FileCipherStreamCreator{keyManager: keyManager, envType: enginepb.EnvType_Store}
+	fs := &encryptedFS{FS: diskFS, fileRegistry: fileRegistry, streamCreator: streamCreator}
+
+	name := diskFS.PathJoin(dir, "000001.log")
+	f, err := fs.Create(name)
+	require.NoError(t, err)
+	_, ok := f.(*gcmFile)
+	require.True(t, ok)
+	f = vfs.NewSyncingFile(f, vfs.SyncingFileOptions{BytesPerSync: 512})
+
+	// Write less than a record, in chunks which make the syncing file sync the
+	// file in the background.
+	var data []byte
+	for i := 0; i < 100; i++ {
+		chunk := []byte(fmt.Sprintf("entry %d\n", i))
+		_, err := f.Write(chunk)
+		require.NoError(t, err)
+		data = append(data, chunk...)
+	}
+	require.NoError(t, f.Sync())
+
+	// The data is in the underlying file before the file is closed.
+	info, err := diskFS.Stat(name)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, info.Size(), int64(len(data)+gcmRecordOverhead))
+	g, err := fs.Open(name)
+	require.NoError(t, err)
+	b, err := io.ReadAll(g)
+	require.NoError(t, err)
+	require.NoError(t, g.Close())
+	require.Equal(t, data, b)
+	info, err = fs.Stat(name)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), info.Size())
+	require.NoError(t, f.Close())
+}
+
 // Minimal test that creates an encrypted Pebble that exercises creation and reading of encrypted
 // files, rereading data after reopening the engine, and stats code.
 func TestPebbleEncryption(t *testing.T) {
@@ -0,0 +1,500 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+// Files encrypted with AES-GCM are stored as a sequence of records, each
+// holding up to gcmRecordSize bytes of the file's contents:
+//
+//   +------------------+---------------------------+----------------+
+//   | length (4 bytes) | ciphertext (length bytes) | tag (16 bytes) |
+//   +------------------+---------------------------+----------------+
+//
+// The nonce of a record is the nonce of the file followed by the counter of
+// the file plus the index of the record, and the length of the record is
+// authenticated along with its ciphertext. A record which was modified, or
+// moved within its file or to another file, thus fails authentication when it
+// is read. Records are never rewritten, so nonces are never reused.
+//
+// A record is written once gcmRecordSize bytes are buffered, as well as when
+// the file is synced or closed, so that synced data is durable like with
+// AES-CTR. Files can thus contain short records which are not at their end,
+// e.g. WALs, which are synced after each batch of writes.
+//
+// A record which is only partially present at the end of a file is ignored,
+// like zeroes following the last record: both are left by a crash while the
+// file was being written, before it was synced. Zeroes followed by anything
+// else than zeroes are reported as corruption. For the same reason, whole
+// records removed from the end of a file are not detected. sstables are
+// protected against this by their footer.
+
+const (
+	// gcmFileNonceSize is the size of the nonce in the EncryptionSettings of a
+	// file. The counter supplies the remaining 4 bytes of the nonce of a record.
+	gcmFileNonceSize = 8
+	gcmNonceSize     = 12
+	gcmTagSize       = 16
+	gcmHeaderSize    = 4
+	// gcmRecordSize is the maximum size of the plaintext of a record.
+	gcmRecordSize = 32 << 10
+	// gcmRecordOverhead is the size of a record in addition to its plaintext.
+	gcmRecordOverhead = gcmHeaderSize + gcmTagSize
+)
+
+// isGCMEncryptionType returns whether files with the given encryption type
+// are encrypted with AES-GCM.
+func isGCMEncryptionType(encType enginepbccl.EncryptionType) bool {
+	switch encType {
+	case enginepbccl.EncryptionType_AES128_GCM,
+		enginepbccl.EncryptionType_AES192_GCM,
+		enginepbccl.EncryptionType_AES256_GCM:
+		return true
+	default:
+		return false
+	}
+}
+
+// gcmEncryptionType returns the AES-GCM encryption type using keys of the same
+// length as the given AES-CTR encryption type.
+func gcmEncryptionType(encType enginepbccl.EncryptionType) enginepbccl.EncryptionType {
+	switch encType {
+	case enginepbccl.EncryptionType_AES128_CTR:
+		return enginepbccl.EncryptionType_AES128_GCM
+	case enginepbccl.EncryptionType_AES192_CTR:
+		return enginepbccl.EncryptionType_AES192_GCM
+	case enginepbccl.EncryptionType_AES256_CTR:
+		return enginepbccl.EncryptionType_AES256_GCM
+	default:
+		return encType
+	}
+}
+
+// gcmStream seals and opens the records of a file encrypted with AES-GCM.
+type gcmStream struct {
+	aead    cipher.AEAD
+	nonce   [gcmFileNonceSize]byte
+	counter uint32
+}
+
+func newGCMStream(key *enginepbccl.SecretKey, nonce []byte, counter uint32) (*gcmStream, error) {
+	if len(nonce) != gcmFileNonceSize {
+		return nil, fmt.Errorf("unexpected nonce size: %d", len(nonce))
+	}
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	stream := &gcmStream{aead: aead, counter: counter}
+	// Copy the nonce since the caller may overwrite it in the future.
+	copy(stream.nonce[:], nonce)
+	return stream, nil
+}
+
+// recordNonce returns the nonce of the record with the given index.
+func (s *gcmStream) recordNonce(index uint32) [gcmNonceSize]byte {
+	var nonce [gcmNonceSize]byte
+	copy(nonce[:], s.nonce[:])
+	binary.BigEndian.PutUint32(nonce[gcmFileNonceSize:], s.counter+index)
+	return nonce
+}
+
+// seal appends the record with the given index and plaintext to dst.
+func (s *gcmStream) seal(dst []byte, index uint32, plaintext []byte) []byte {
+	var header [gcmHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(plaintext)))
+	nonce := s.recordNonce(index)
+	dst = append(dst, header[:]...)
+	return s.aead.Seal(dst, nonce[:], plaintext, header[:])
+}
+
+// open authenticates and decrypts the record with the given index, header and
+// sealed contents (the ciphertext followed by the tag), and appends its
+// plaintext to dst.
+func (s *gcmStream) open(dst []byte, index uint32, header, sealed []byte) ([]byte, error) {
+	nonce := s.recordNonce(index)
+	return s.aead.Open(dst, nonce[:], sealed, header)
+}
+
+// gcmRun is a sequence of consecutive records of a file, all of which but the
+// last one hold gcmRecordSize bytes. A file which was not synced while it was
+// being written, like an sstable, consists of a single run.
+type gcmRun struct {
+	// offset and physOffset are the offsets of the first record of the run in
+	// the plaintext and in the underlying file.
+	offset, physOffset int64
+	// firstRecord is the index of the first record of the run.
+	firstRecord uint32
+	// size is the size of the plaintext of the run.
+	size int64
+}
+
+// gcmRecordLoc is the location of a record of a file.
+type gcmRecordLoc struct {
+	index uint32
+	// offset and physOffset are the offsets of the record in the plaintext and
+	// in the underlying file.
+	offset, physOffset int64
+	size               int
+}
+
+// gcmRecordPool pools the buffers in which records are read.
+var gcmRecordPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, gcmRecordSize+gcmRecordOverhead)
+		return &buf
+	},
+}
+
+// gcmFile implements vfs.File for a file encrypted with AES-GCM. See the top of
+// this file for the format of the underlying file.
+type gcmFile struct {
+	vfs.File
+	stream *gcmStream
+	read   struct {
+		syncutil.Mutex
+		offset int64
+	}
+	mu struct {
+		syncutil.Mutex
+		// loaded is true once runs describe all the records of the underlying
+		// file. The records of opened files are located lazily.
+		loaded bool
+		runs   []gcmRun
+		// numRecords is the number of records in the underlying file, and size and
+		// physSize are the sizes of their plaintext and of the underlying file.
+		numRecords     uint32
+		size, physSize int64
+		// fileSize is the size of the underlying file, which exceeds physSize if
+		// the file ends with zeroes or a partial record.
+		fileSize int64
+		// wBuf holds the data written to the file which was not sealed in a record
+		// yet.
+		wBuf []byte
+		// sealBuf is the buffer in which records are sealed before being written.
+		sealBuf []byte
+	}
+	// onSize, if set, is called with the size of the plaintext of the file and
+	// the size of the underlying file once they are known, and when the file is
+	// synced or closed. It lets the encryptedFS cache the size of the file.
+	onSize func(size, fileSize int64)
+}
+
+// newGCMFile wraps f. created is true if f was just created, and is thus
+// empty.
+func newGCMFile(f vfs.File, stream *gcmStream, created bool) *gcmFile {
+	gf := &gcmFile{File: f, stream: stream}
+	gf.mu.loaded = created
+	return gf
+}
+
+// Write implements io.Writer.
+func (f *gcmFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return 0, err
+	}
+	f.mu.wBuf = append(f.mu.wBuf, p...)
+	sealed := 0
+	for len(f.mu.wBuf)-sealed >= gcmRecordSize {
+		if err := f.writeRecordLocked(f.mu.wBuf[sealed : sealed+gcmRecordSize]); err != nil {
+			return 0, err
+		}
+		sealed += gcmRecordSize
+	}
+	f.mu.wBuf = f.mu.wBuf[:copy(f.mu.wBuf, f.mu.wBuf[sealed:])]
+	return len(p), nil
+}
+
+// writeRecordLocked seals the given plaintext in a record and writes it to the
+// underlying file.
+func (f *gcmFile) writeRecordLocked(plaintext []byte) error {
+	f.mu.sealBuf = f.stream.seal(f.mu.sealBuf[:0], f.mu.numRecords, plaintext)
+	if _, err := f.File.Write(f.mu.sealBuf); err != nil {
+		return err
+	}
+	f.appendRecordLocked(int64(len(plaintext)))
+	f.mu.fileSize += int64(len(f.mu.sealBuf))
+	return nil
+}
+
+// flushLocked writes the buffered data to the underlying file.
+func (f *gcmFile) flushLocked() error {
+	if len(f.mu.wBuf) == 0 {
+		return nil
+	}
+	if err := f.writeRecordLocked(f.mu.wBuf); err != nil {
+		return err
+	}
+	f.mu.wBuf = f.mu.wBuf[:0]
+	return nil
+}
+
+// appendRecordLocked records that a record with a plaintext of the given size
+// follows the records of the file.
+func (f *gcmFile) appendRecordLocked(size int64) {
+	if n := len(f.mu.runs); n > 0 && f.mu.runs[n-1].size%gcmRecordSize == 0 {
+		f.mu.runs[n-1].size += size
+	} else {
+		f.mu.runs = append(f.mu.runs, gcmRun{
+			offset:      f.mu.size,
+			physOffset:  f.mu.physSize,
+			firstRecord: f.mu.numRecords,
+			size:        size,
+		})
+	}
+	f.mu.numRecords++
+	f.mu.size += size
+	f.mu.physSize += size + gcmRecordOverhead
+}
+
+// loadLocked locates the records of the underlying file by reading their
+// headers, if this wasn't done yet.
+func (f *gcmFile) loadLocked() error {
+	if f.mu.loaded {
+		return nil
+	}
+	info, err := f.File.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := info.Size()
+	var header [gcmHeaderSize]byte
+	for f.mu.physSize+gcmHeaderSize <= fileSize {
+		if err := readFullAt(f.File, header[:], f.mu.physSize); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(header[:]))
+		if size > gcmRecordSize {
+			return gcmCorruptionErrorf("invalid encrypted record at offset %d: size %d", f.mu.physSize, size)
+		}
+		if size == 0 {
+			// Zeroes may only follow the last record of the file.
+			if err := checkZeroes(f.File, f.mu.physSize, fileSize); err != nil {
+				return err
+			}
+			break
+		}
+		if f.mu.physSize+size+gcmRecordOverhead > fileSize {
+			// A partial record at the end of the file.
+			break
+		}
+		f.appendRecordLocked(size)
+	}
+	f.mu.fileSize = fileSize
+	f.mu.loaded = true
+	f.reportSizeLocked()
+	return nil
+}
+
+// reportSizeLocked passes the size of the file to f.onSize.
+func (f *gcmFile) reportSizeLocked() {
+	if f.onSize != nil && len(f.mu.wBuf) == 0 {
+		f.onSize(f.mu.size, f.mu.fileSize)
+	}
+}
+
+// checkZeroes returns a corruption error unless the bytes of f between the
+// given offsets are all zeroes.
+func checkZeroes(f io.ReaderAt, off, end int64) error {
+	bufPtr := gcmRecordPool.Get().(*[]byte)
+	defer gcmRecordPool.Put(bufPtr)
+	for off < end {
+		buf := *bufPtr
+		if int64(len(buf)) > end-off {
+			buf = buf[:end-off]
+		}
+		if err := readFullAt(f, buf, off); err != nil {
+			return err
+		}
+		for i, b := range buf {
+			if b != 0 {
+				return gcmCorruptionErrorf("invalid encrypted record at offset %d: "+
+					"found data at offset %d after a zero header", off, off+int64(i))
+			}
+		}
+		off += int64(len(buf))
+	}
+	return nil
+}
+
+// gcmCorruptionErrorf returns an error marked as pebble.ErrCorruption.
+func gcmCorruptionErrorf(format string, args ...interface{}) error {
+	return errors.Mark(errors.Errorf(format, args...), pebble.ErrCorruption)
+}
+
+// recordAtLocked returns the location of the record containing the given
+// offset of the plaintext, which must precede f.mu.size.
+func (f *gcmFile) recordAtLocked(off int64) gcmRecordLoc {
+	runs := f.mu.runs
+	r := runs[sort.Search(len(runs), func(i int) bool { return runs[i].offset > off })-1]
+	n := (off - r.offset) / gcmRecordSize
+	loc := gcmRecordLoc{
+		index:      r.firstRecord + uint32(n),
+		offset:     r.offset + n*gcmRecordSize,
+		physOffset: r.physOffset + n*(gcmRecordSize+gcmRecordOverhead),
+	}
+	loc.size = gcmRecordSize
+	if end := r.offset + r.size; end-loc.offset < gcmRecordSize {
+		loc.size = int(end - loc.offset)
+	}
+	return loc
+}
+
+// readRecord reads, authenticates and decrypts the given record. The plaintext
+// is returned in buf, which must hold gcmRecordSize+gcmRecordOverhead bytes.
+func (f *gcmFile) readRecord(loc gcmRecordLoc, buf []byte) ([]byte, error) {
+	buf = buf[:loc.size+gcmRecordOverhead]
+	if err := readFullAt(f.File, buf, loc.physOffset); err != nil {
+		return nil, err
+	}
+	header, sealed := buf[:gcmHeaderSize], buf[gcmHeaderSize:]
+	if size := int(binary.BigEndian.Uint32(header)); size != loc.size {
+		return nil, errors.Errorf("invalid encrypted record at offset %d: size %d instead of %d",
+			loc.physOffset, size, loc.size)
+	}
+	plaintext, err := f.stream.open(sealed[:0], loc.index, header, sealed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "encrypted record at offset %d failed authentication", loc.physOffset)
+	}
+	return plaintext, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *gcmFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	bufPtr := gcmRecordPool.Get().(*[]byte)
+	defer gcmRecordPool.Put(bufPtr)
+	for n < len(p) {
+		pos := off + int64(n)
+		f.mu.Lock()
+		if err := f.loadLocked(); err != nil {
+			f.mu.Unlock()
+			return n, err
+		}
+		if pos >= f.mu.size {
+			// The data may not be written to the underlying file yet.
+			if i := pos - f.mu.size; i < int64(len(f.mu.wBuf)) {
+				n += copy(p[n:], f.mu.wBuf[i:])
+			}
+			f.mu.Unlock()
+			break
+		}
+		loc := f.recordAtLocked(pos)
+		f.mu.Unlock()
+
+		plaintext, err := f.readRecord(loc, *bufPtr)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], plaintext[pos-loc.offset:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read implements io.Reader.
+func (f *gcmFile) Read(p []byte) (int, error) {
+	f.read.Lock()
+	defer f.read.Unlock()
+	n, err := f.ReadAt(p, f.read.offset)
+	f.read.offset += int64(n)
+	return n, err
+}
+
+// Stat implements vfs.File. The size of the file is the size of its plaintext.
+func (f *gcmFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	return gcmFileInfo{FileInfo: info, size: f.mu.size + int64(len(f.mu.wBuf))}, nil
+}
+
+// Sync implements vfs.File.
+func (f *gcmFile) Sync() error {
+	f.mu.Lock()
+	err := f.flushLocked()
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := f.File.Sync(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.loaded {
+		f.reportSizeLocked()
+	}
+	return nil
+}
+
+// Close implements io.Closer.
+func (f *gcmFile) Close() error {
+	f.mu.Lock()
+	err := f.flushLocked()
+	if err == nil && f.mu.loaded {
+		f.reportSizeLocked()
+	}
+	f.mu.Unlock()
+	return errors.CombineErrors(err, f.File.Close())
+}
+
+// gcmFileInfo is the os.FileInfo of a gcmFile.
+type gcmFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+// Size implements os.FileInfo.
+func (i gcmFileInfo) Size() int64 {
+	return i.size
+}
+
+// readFullAt reads len(buf) bytes from f at the given offset.
+func readFullAt(f io.ReaderAt, buf []byte, off int64) error {
+	n, err := f.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCMFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rng, _ := randutil.NewTestRand()
+
+	key, err := generateKey(enginepbccl.EncryptionType_AES256_GCM)
+	require.NoError(t, err)
+	nonce := make([]byte, gcmFileNonceSize)
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	stream, err := newGCMStream(key, nonce, rng.Uint32())
+	require.NoError(t, err)
+
+	memFS := vfs.NewMem()
+	f, err := memFS.Create("file")
+	require.NoError(t, err)
+	gf := newGCMFile(f, stream, true /* created */)
+
+	// Write data in chunks of random sizes, syncing the file from time to time
+	// so that it contains short records.
+	data := randutil.RandBytes(rng, 5*gcmRecordSize+rng.Intn(gcmRecordSize))
+	for written := 0; written < len(data); {
+		n := 1 + rng.Intn(gcmRecordSize/2)
+		if written+n > len(data) {
+			n = len(data) - written
+		}
+		_, err := gf.Write(data[written : written+n])
+		require.NoError(t, err)
+		written += n
+		if rng.Intn(4) == 0 {
+			require.NoError(t, gf.Sync())
+		}
+	}
+
+	checkContents := func(gf *gcmFile) {
+		info, err := gf.Stat()
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), info.Size())
+		for i := 0; i < 100; i++ {
+			off := rng.Intn(len(data))
+			buf := make([]byte, rng.Intn(3*gcmRecordSize))
+			n, err := gf.ReadAt(buf, int64(off))
+			if off+len(buf) > len(data) {
+				require.Equal(t, io.EOF, err)
+				require.Equal(t, len(data)-off, n)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, len(buf), n)
+			}
+			require.Equal(t, data[off:off+n], buf[:n])
+		}
+		all, err := io.ReadAll(gf)
+		require.NoError(t, err)
+		require.Equal(t, data, all)
+	}
+	// The data which is still buffered can be read too.
+	checkContents(gf)
+	require.NoError(t, gf.Close())
+
+	// The underlying file contains the encrypted data.
+	f, err = memFS.Open("file")
+	require.NoError(t, err)
+	physInfo, err := f.Stat()
+	require.NoError(t, err)
+	require.Greater(t, physInfo.Size(), int64(len(data)))
+	gf = newGCMFile(f, stream, false /* created */)
+	checkContents(gf)
+	require.NoError(t, gf.Close())
+
+	// A partially written record at the end of the file is ignored.
+	f, err = memFS.Create("truncated")
+	require.NoError(t, err)
+	f2, err := memFS.Open("file")
+	require.NoError(t, err)
+	_, err = io.CopyN(f, f2, physInfo.Size()-gcmTagSize)
+	require.NoError(t, err)
+	require.NoError(t, f2.Close())
+	require.NoError(t, f.Close())
+	f, err = memFS.Open("truncated")
+	require.NoError(t, err)
+	gf = newGCMFile(f, stream, false /* created */)
+	info, err := gf.Stat()
+	require.NoError(t, err)
+	require.Less(t, info.Size(), int64(len(data)))
+	require.NoError(t, gf.Close())
+
+	// Zeroes at the end of the file are ignored, but a zero header followed by
+	// anything else than zeroes is reported as corruption.
+	writeModified := func(name string, modify func(contents []byte) []byte) {
+		f, err := memFS.Create(name)
+		require.NoError(t, err)
+		f2, err := memFS.Open("file")
+		require.NoError(t, err)
+		contents, err := io.ReadAll(f2)
+		require.NoError(t, err)
+		require.NoError(t, f2.Close())
+		_, err = f.Write(modify(contents))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	writeModified("zeroes", func(contents []byte) []byte {
+		return append(contents, make([]byte, 3*gcmRecordSize)...)
+	})
+	f, err = memFS.Open("zeroes")
+	require.NoError(t, err)
+	gf = newGCMFile(f, stream, false /* created */)
+	checkContents(gf)
+	require.NoError(t, gf.Close())
+	writeModified("zeroed", func(contents []byte) []byte {
+		// Zero the header of the second record.
+		off := gcmRecordOverhead + binary.BigEndian.Uint32(contents)
+		copy(contents[off:], make([]byte, gcmHeaderSize))
+		return contents
+	})
+	f, err = memFS.Open("zeroed")
+	require.NoError(t, err)
+	gf = newGCMFile(f, stream, false /* created */)
+	_, err = gf.Stat()
+	require.True(t, errors.Is(err, pebble.ErrCorruption), "%v", err)
+	require.NoError(t, gf.Close())
+
+	// Modifying any byte of the file is detected when the data is read.
+	for _, off := range []int64{0, 1, gcmRecordSize + gcmRecordOverhead + 7, physInfo.Size() - 1} {
+		f, err := memFS.Create("tampered")
+		require.NoError(t, err)
+		f2, err := memFS.Open("file")
+		require.NoError(t, err)
+		contents, err := io.ReadAll(f2)
+		require.NoError(t, err)
+		require.NoError(t, f2.Close())
+		contents[off] ^= 1
+		_, err = f.Write(contents)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		f, err = memFS.Open("tampered")
+		require.NoError(t, err)
+		gf = newGCMFile(f, stream, false /* created */)
+		_, err = io.ReadAll(gf)
+		require.Error(t, err)
+		require.NoError(t, gf.Close())
+	}
+
+	// The file can't be read with another nonce.
+	otherStream, err := newGCMStream(key, make([]byte, gcmFileNonceSize), stream.counter)
+	require.NoError(t, err)
+	f, err = memFS.Open("file")
+	require.NoError(t, err)
+	gf = newGCMFile(f, otherStream, false /* created */)
+	_, err = io.ReadAll(gf)
+	require.Regexp(t, "failed authentication", err)
+	require.NoError(t, gf.Close())
+}
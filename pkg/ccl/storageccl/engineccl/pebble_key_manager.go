@@ -77,6 +77,9 @@ type StoreKeyManager struct {
 	fs                vfs.FS
 	activeKeyFilename string
 	oldKeyFilename    string
+	// authenticated is true if the keys are used with AES-GCM instead of
+	// AES-CTR.
+	authenticated bool
 
 	// Implementation. Both are not nil after a successful call to Load().
 	activeKey *enginepbccl.SecretKey
@@ -86,11 +89,11 @@ type StoreKeyManager struct {
 // Load must be called before calling other functions.
 func (m *StoreKeyManager) Load(ctx context.Context) error {
 	var err error
-	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename)
+	m.activeKey, err = loadKeyFromFile(m.fs, m.activeKeyFilename, m.authenticated)
 	if err != nil {
 		return err
 	}
-	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename)
+	m.oldKey, err = loadKeyFromFile(m.fs, m.oldKeyFilename, m.authenticated)
 	if err != nil {
 		return err
 	}
@@ -115,7 +118,11 @@ func (m *StoreKeyManager) GetKey(id string) (*enginepbccl.SecretKey, error) {
 	return nil, fmt.Errorf("store key ID %s was not found", id)
 }
 
-func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error) {
+// loadKeyFromFile loads a store key. The encryption type of the key is
+// determined by its length, and by whether it is used with AES-GCM.
+func loadKeyFromFile(
+	fs vfs.FS, filename string, authenticated bool,
+) (*enginepbccl.SecretKey, error) {
 	now := kmTimeNow().Unix()
 	key := &enginepbccl.SecretKey{}
 	key.Info = &enginepbccl.KeyInfo{}
@@ -148,6 +155,9 @@ func loadKeyFromFile(fs vfs.FS, filename string) (*enginepbccl.SecretKey, error)
 	default:
 		return nil, fmt.Errorf("store key of unsupported length: %d", keyLength)
 	}
+	if authenticated {
+		key.Info.EncryptionType = gcmEncryptionType(key.Info.EncryptionType)
+	}
 	key.Key = b[keyIDLength:]
 	// Hex encoding to make it human readable.
 	key.Info.KeyId = hex.EncodeToString(b[:keyIDLength])
@@ -304,12 +314,15 @@ func (m *DataKeyManager) SetActiveStoreKeyInfo(
 	m.mu.rotationEnabled = true
 	prevActiveStoreKey, found := m.mu.keyRegistry.StoreKeys[m.mu.keyRegistry.ActiveStoreKeyId]
 	if found && prevActiveStoreKey.KeyId == storeKeyInfo.KeyId && m.mu.activeKey != nil {
-		// The active store key has not changed and we already have an active data key,
-		// so no need to do anything.
-		return nil
-	}
-	// For keys other than plaintext, make sure the user is not reusing inactive keys.
-	if storeKeyInfo.EncryptionType != enginepbccl.EncryptionType_Plaintext {
+		if prevActiveStoreKey.EncryptionType == storeKeyInfo.EncryptionType {
+			// The active store key has not changed and we already have an active data key,
+			// so no need to do anything.
+			return nil
+		}
+		// The active store key is now used with a different cipher mode. Rotate the
+		// data key so that new files use that cipher mode too.
+	} else if storeKeyInfo.EncryptionType != enginepbccl.EncryptionType_Plaintext {
+		// For keys other than plaintext, make sure the user is not reusing inactive keys.
 		if _, found := m.mu.keyRegistry.StoreKeys[storeKeyInfo.KeyId]; found {
 			return fmt.Errorf("new active store key ID %s already exists as an inactive key -- this"+
 				"is really dangerous", storeKeyInfo.KeyId)
@@ -376,11 +389,11 @@ func generateAndSetNewDataKey(
 	} else {
 		var keyLength int
 		switch activeStoreKey.EncryptionType {
-		case enginepbccl.EncryptionType_AES128_CTR:
+		case enginepbccl.EncryptionType_AES128_CTR, enginepbccl.EncryptionType_AES128_GCM:
 			keyLength = 16
-		case enginepbccl.EncryptionType_AES192_CTR:
+		case enginepbccl.EncryptionType_AES192_CTR, enginepbccl.EncryptionType_AES192_GCM:
 			keyLength = 24
-		case enginepbccl.EncryptionType_AES256_CTR:
+		case enginepbccl.EncryptionType_AES256_CTR, enginepbccl.EncryptionType_AES256_GCM:
 			keyLength = 32
 		default:
 			return nil, fmt.Errorf("unknown encryption type %d for key ID %s",
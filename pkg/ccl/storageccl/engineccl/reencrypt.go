@@ -0,0 +1,405 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/gogo/protobuf/proto"
+)
+
+// Re-encryption of files using retired data keys.
+//
+// Rotating the store key or the data key only affects the files written
+// afterwards. The reencryptor periodically rewrites the sstables of the
+// data-FS which are encrypted with retired data keys using the active data
+// key, so that the files protected by a retired key eventually disappear, and
+// files written before the cipher mode was changed to AES-GCM end up being
+// authenticated too. Other files, like WALs and MANIFESTs, are rewritten or
+// deleted by Pebble in the normal course of its operation. Files are not
+// re-encrypted while the store key is "plain".
+//
+// A file is re-encrypted by copying it into a temporary file with the
+// reencryptTmpSuffix, and by then swapping the temporary file with the
+// original one. Since a file's contents and its file registry entry can't be
+// changed atomically, the swap proceeds as follows:
+// 1. The temporary file is synced, along with its directory.
+// 2. The entry of the temporary file is copied to the original file.
+// 3. The temporary file is renamed to the original file, and the directory is
+//    synced.
+// 4. The entry of the temporary file is deleted.
+// A crash between steps 2 and 3 leaves an original file whose entry is the
+// entry of the temporary file: the swap is then completed when the store is
+// next opened for writing (see recoverInterruptedSwaps), and read-only stores
+// read the temporary file instead (see maybeResolveInterruptedSwap). Otherwise,
+// the temporary file is removed, if it still exists.
+//
+// Pebble may keep a file open while it is swapped: the open file keeps using
+// the original contents and their settings. Files are only opened, removed or
+// renamed while encryptedFS.swapMu is held in read mode, while steps 2 to 4
+// happen with the mutex held in write mode.
+
+// reencryptTmpSuffix is the suffix of the temporary files into which files are
+// re-encrypted.
+const reencryptTmpSuffix = ".reencrypt"
+
+// Overridden for testing.
+var (
+	// reencryptInterval is the interval between the passes of the reencryptor.
+	reencryptInterval = 10 * time.Minute
+	// reencryptBytesPerSecond limits the rate at which the reencryptor rewrites
+	// files, so that it doesn't compete with foreground traffic.
+	reencryptBytesPerSecond int64 = 32 << 20 // 32 MiB
+)
+
+// reencryptor rewrites the sstables using retired data keys in the background.
+type reencryptor struct {
+	fs    *encryptedFS
+	dbDir string
+	stopC chan struct{}
+	wg    sync.WaitGroup
+	mu    struct {
+		syncutil.Mutex
+		status enginepbccl.ReencryptionStatus
+	}
+}
+
+func newReencryptor(fs *encryptedFS, dbDir string) *reencryptor {
+	return &reencryptor{fs: fs, dbDir: dbDir, stopC: make(chan struct{})}
+}
+
+// start starts the background goroutine of the reencryptor.
+func (r *reencryptor) start() {
+	r.wg.Add(1)
+	go r.run(context.Background())
+}
+
+// stop stops the reencryptor and waits for its goroutine to exit.
+func (r *reencryptor) stop() {
+	close(r.stopC)
+	r.wg.Wait()
+}
+
+// status returns the progress of the reencryptor.
+func (r *reencryptor) status() *enginepbccl.ReencryptionStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.mu.status
+	return &s
+}
+
+func (r *reencryptor) run(ctx context.Context) {
+	defer r.wg.Done()
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		timer.Reset(reencryptInterval)
+		select {
+		case <-r.stopC:
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+		if err := r.pass(ctx); err != nil {
+			log.Warningf(ctx, "failed to re-encrypt files using retired data keys: %v", err)
+		}
+	}
+}
+
+// wait waits for the given duration, and returns false if the reencryptor was
+// stopped in the meantime.
+func (r *reencryptor) wait(d time.Duration) bool {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(d)
+	select {
+	case <-r.stopC:
+		return false
+	case <-timer.C:
+		timer.Read = true
+		return true
+	}
+}
+
+// reencryptCandidate is an sstable using a retired data key.
+type reencryptCandidate struct {
+	path string
+	size int64
+}
+
+// pass re-encrypts the sstables using retired data keys.
+func (r *reencryptor) pass(ctx context.Context) error {
+	candidates, err := r.candidates(ctx)
+	if err != nil {
+		return err
+	}
+	var remainingBytes int64
+	for _, c := range candidates {
+		remainingBytes += c.size
+	}
+	r.mu.Lock()
+	r.mu.status.RemainingFiles = uint64(len(candidates))
+	r.mu.status.RemainingBytes = uint64(remainingBytes)
+	r.mu.Unlock()
+	if len(candidates) > 0 {
+		log.Infof(ctx, "re-encrypting %d sstables (%d bytes) using retired data keys",
+			len(candidates), remainingBytes)
+	}
+
+	for _, c := range candidates {
+		select {
+		case <-r.stopC:
+			return nil
+		default:
+		}
+		reencrypted, err := r.reencryptFile(c.path)
+		if err != nil {
+			return errors.Wrapf(err, "re-encrypting %s", c.path)
+		}
+		r.mu.Lock()
+		r.mu.status.RemainingFiles--
+		r.mu.status.RemainingBytes -= uint64(c.size)
+		if reencrypted {
+			r.mu.status.ReencryptedFiles++
+			r.mu.status.ReencryptedBytes += uint64(c.size)
+		}
+		r.mu.Unlock()
+		if reencrypted && reencryptBytesPerSecond > 0 {
+			if !r.wait(time.Duration(c.size) * time.Second / time.Duration(reencryptBytesPerSecond)) {
+				return nil
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.mu.status.LastPassTime = timeutil.Now().Unix()
+	r.mu.Unlock()
+	return nil
+}
+
+// candidates returns the sstables of the data-FS using retired data keys,
+// sorted by path.
+func (r *reencryptor) candidates(ctx context.Context) ([]reencryptCandidate, error) {
+	key, err := r.fs.streamCreator.keyManager.ActiveKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.Info.EncryptionType == enginepbccl.EncryptionType_Plaintext {
+		return nil, nil
+	}
+	var candidates []reencryptCandidate
+	for name, entry := range r.fs.fileRegistry.List() {
+		// Only the sstables at the root of the store's directory are rewritten.
+		if entry.EnvType != r.fs.streamCreator.envType ||
+			r.fs.PathBase(name) != name || !strings.HasSuffix(name, ".sst") {
+			continue
+		}
+		var settings enginepbccl.EncryptionSettings
+		if err := protoutil.Unmarshal(entry.EncryptionSettings, &settings); err != nil {
+			return nil, err
+		}
+		if settings.KeyId == key.Info.KeyId {
+			continue
+		}
+		path := r.fs.PathJoin(r.dbDir, name)
+		info, err := r.fs.FS.Stat(path)
+		if err != nil {
+			if oserror.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		candidates = append(candidates, reencryptCandidate{path: path, size: info.Size()})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].path < candidates[j].path })
+	return candidates, nil
+}
+
+// reencryptFile rewrites the given file using the active data key. It returns
+// false if the file was removed or replaced concurrently, in which case it
+// doesn't need to be re-encrypted anymore.
+func (r *reencryptor) reencryptFile(path string) (bool, error) {
+	entry := r.fs.fileRegistry.GetFileEntry(path)
+	if entry == nil {
+		return false, nil
+	}
+	tmpPath := path + reencryptTmpSuffix
+	if err := r.copyFile(path, tmpPath); err != nil {
+		if oserror.IsNotExist(err) {
+			return false, r.removeTmp(tmpPath)
+		}
+		return false, errors.CombineErrors(err, r.removeTmp(tmpPath))
+	}
+	swapped, err := r.fs.swap(path, tmpPath, entry)
+	if err != nil || !swapped {
+		return false, errors.CombineErrors(err, r.removeTmp(tmpPath))
+	}
+	return true, nil
+}
+
+// copyFile copies the contents of src to dst, which is created using the
+// active data key, and syncs dst along with its directory.
+func (r *reencryptor) copyFile(src, dst string) error {
+	srcFile, err := r.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := r.fs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return errors.CombineErrors(err, dstFile.Close())
+	}
+	if err := dstFile.Sync(); err != nil {
+		return errors.CombineErrors(err, dstFile.Close())
+	}
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+	return r.fs.syncDir(r.fs.PathDir(dst))
+}
+
+// removeTmp removes the given temporary file, if it exists.
+func (r *reencryptor) removeTmp(tmpPath string) error {
+	if err := r.fs.Remove(tmpPath); err != nil && !oserror.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// swap replaces the file at path, whose file registry entry is expected to be
+// entry, by the file at tmpPath. It returns false if the entry of the file
+// changed, i.e. if the file was removed or replaced since it was copied.
+func (fs *encryptedFS) swap(path, tmpPath string, entry *enginepb.FileEntry) (bool, error) {
+	fs.swapMu.Lock()
+	defer fs.swapMu.Unlock()
+	if cur := fs.fileRegistry.GetFileEntry(path); cur == nil || !proto.Equal(cur, entry) {
+		return false, nil
+	}
+	tmpEntry := fs.fileRegistry.GetFileEntry(tmpPath)
+	if tmpEntry == nil {
+		return false, errors.AssertionFailedf("no file registry entry for %s", tmpPath)
+	}
+	if err := fs.fileRegistry.SetFileEntry(path, tmpEntry); err != nil {
+		return false, err
+	}
+	if err := fs.completeSwapLocked(path, tmpPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// completeSwapLocked renames the file at tmpPath to path, which already uses the
+// file registry entry of the file at tmpPath, and then deletes that entry.
+func (fs *encryptedFS) completeSwapLocked(path, tmpPath string) error {
+	if err := fs.FS.Rename(tmpPath, path); err != nil && !oserror.IsNotExist(err) {
+		return err
+	}
+	fs.gcmSizes.rename(tmpPath, path)
+	// The rename must be durable before the entry of the temporary file, which
+	// identifies the swap as interrupted after a crash, is deleted.
+	if err := fs.syncDir(fs.PathDir(path)); err != nil {
+		return err
+	}
+	return fs.fileRegistry.MaybeDeleteEntry(tmpPath)
+}
+
+// syncDir syncs the given directory.
+func (fs *encryptedFS) syncDir(dir string) error {
+	d, err := fs.FS.OpenDir(dir)
+	if err != nil {
+		return err
+	}
+	return errors.CombineErrors(d.Sync(), d.Close())
+}
+
+// isInterruptedSwap returns whether a crash interrupted the swap of the file at
+// tmpPath with the file at path, after the entry of the file at path was
+// replaced. See the top of this file.
+func (fs *encryptedFS) isInterruptedSwap(path, tmpPath string) bool {
+	tmpEntry := fs.fileRegistry.GetFileEntry(tmpPath)
+	if tmpEntry == nil {
+		return false
+	}
+	entry := fs.fileRegistry.GetFileEntry(path)
+	return entry != nil && proto.Equal(entry, tmpEntry)
+}
+
+// maybeResolveInterruptedSwap returns the name of the file to open in place of
+// the given one: if a crash interrupted the swap of the file with its
+// re-encrypted copy, the copy needs to be read. This can only be the case in
+// read-only stores, since the swap is completed when stores are opened for
+// writing.
+func (fs *encryptedFS) maybeResolveInterruptedSwap(name string) string {
+	tmpName := name + reencryptTmpSuffix
+	if !fs.isInterruptedSwap(name, tmpName) {
+		return name
+	}
+	if _, err := fs.FS.Stat(tmpName); err != nil {
+		// The file was renamed already.
+		return name
+	}
+	return tmpName
+}
+
+// recoverInterruptedSwaps completes the swaps of files with their re-encrypted
+// copies which were interrupted by a crash, and removes the copies of the
+// swaps which were not started. It must be called before the FS is used.
+func (fs *encryptedFS) recoverInterruptedSwaps(dbDir string) error {
+	fs.swapMu.Lock()
+	defer fs.swapMu.Unlock()
+	names, err := fs.FS.List(dbDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, reencryptTmpSuffix) {
+			if err := fs.recoverInterruptedSwapLocked(fs.PathJoin(dbDir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	// Remove the entries of the temporary files which don't exist anymore.
+	for name, entry := range fs.fileRegistry.List() {
+		if entry.EnvType == fs.streamCreator.envType && strings.HasSuffix(name, reencryptTmpSuffix) {
+			if err := fs.recoverInterruptedSwapLocked(fs.PathJoin(dbDir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (fs *encryptedFS) recoverInterruptedSwapLocked(tmpPath string) error {
+	path := strings.TrimSuffix(tmpPath, reencryptTmpSuffix)
+	if fs.isInterruptedSwap(path, tmpPath) {
+		log.Infof(context.TODO(), "completing interrupted re-encryption of %s", path)
+		return fs.completeSwapLocked(path, tmpPath)
+	}
+	if err := fs.FS.Remove(tmpPath); err != nil && !oserror.IsNotExist(err) {
+		return err
+	}
+	fs.gcmSizes.remove(tmpPath)
+	return fs.fileRegistry.MaybeDeleteEntry(tmpPath)
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package engineccl
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/baseccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl/enginepbccl"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors/oserror"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	memFS := vfs.NewMem()
+	require.NoError(t, memFS.MkdirAll("/bar", os.ModePerm))
+	fileRegistry := &storage.PebbleFileRegistry{FS: memFS, DBDir: "/bar"}
+	require.NoError(t, fileRegistry.Load())
+	writeToFile(t, memFS, "16v1.key", []byte("111111111111111111111111111111111234567890123456"))
+	writeToFile(t, memFS, "16v2.key", []byte("111111111111111111111111111111198765432198765432"))
+
+	openEnv := func(
+		keyFile, oldKeyFile string, mode baseccl.EncryptionCipherMode, readOnly bool,
+	) (*storage.EncryptionEnv, *encryptionStatsHandler) {
+		encOptions := baseccl.EncryptionOptions{
+			KeySource: baseccl.EncryptionKeySource_KeyFiles,
+			KeyFiles: &baseccl.EncryptionKeyFiles{
+				CurrentKey: keyFile,
+				OldKey:     oldKeyFile,
+			},
+			DataKeyRotationPeriod: 1000,
+			CipherMode:            mode,
+		}
+		encOptionsBytes, err := protoutil.Marshal(&encOptions)
+		require.NoError(t, err)
+		env, err := newEncryptedEnv(memFS, fileRegistry, "/bar", readOnly, encOptionsBytes)
+		require.NoError(t, err)
+		return env, env.StatsHandler.(*encryptionStatsHandler)
+	}
+	settingsOf := func(name string) enginepbccl.EncryptionSettings {
+		entry := fileRegistry.GetFileEntry(name)
+		require.NotNil(t, entry)
+		var s enginepbccl.EncryptionSettings
+		require.NoError(t, protoutil.Unmarshal(entry.EncryptionSettings, &s))
+		return s
+	}
+	readFile := func(fs vfs.FS, name string) string {
+		f, err := fs.Open(name)
+		require.NoError(t, err)
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		info, err := fs.Stat(name)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(b)), info.Size())
+		return string(b)
+	}
+
+	// Write an sstable and a WAL using AES-CTR.
+	env, _ := openEnv("16v1.key", "plain", baseccl.EncryptionCipherMode_CTR, false /* readOnly */)
+	writeToFile(t, env.FS, "/bar/000001.sst", []byte("sstable"))
+	writeToFile(t, env.FS, "/bar/000002.log", []byte("wal"))
+	require.Equal(t, enginepbccl.EncryptionType_AES128_CTR, settingsOf("/bar/000001.sst").EncryptionType)
+	require.NoError(t, env.Closer.Close())
+
+	// Rotate the store key, switching to AES-GCM. The sstable is re-encrypted
+	// with the new data key, while the WAL is left alone.
+	env, h := openEnv("16v2.key", "16v1.key", baseccl.EncryptionCipherMode_GCM, false /* readOnly */)
+	dataKey, err := h.dataKM.ActiveKey(ctx)
+	require.NoError(t, err)
+	require.Equal(t, enginepbccl.EncryptionType_AES128_GCM, dataKey.Info.EncryptionType)
+	walSettings := settingsOf("/bar/000002.log")
+	require.NoError(t, h.reencryptor.pass(ctx))
+	status := h.reencryptor.status()
+	require.Equal(t, uint64(1), status.ReencryptedFiles)
+	require.Equal(t, uint64(len("sstable")), status.ReencryptedBytes)
+	require.Zero(t, status.RemainingFiles)
+	require.Zero(t, status.RemainingBytes)
+	require.NotZero(t, status.LastPassTime)
+	s := settingsOf("/bar/000001.sst")
+	require.Equal(t, dataKey.Info.KeyId, s.KeyId)
+	require.Equal(t, enginepbccl.EncryptionType_AES128_GCM, s.EncryptionType)
+	require.Equal(t, walSettings, settingsOf("/bar/000002.log"))
+	require.Equal(t, "sstable", readFile(env.FS, "/bar/000001.sst"))
+	require.Equal(t, "wal", readFile(env.FS, "/bar/000002.log"))
+	require.Nil(t, fileRegistry.GetFileEntry("/bar/000001.sst"+reencryptTmpSuffix))
+	_, err = memFS.Stat("/bar/000001.sst" + reencryptTmpSuffix)
+	require.True(t, oserror.IsNotExist(err))
+
+	// Another pass has nothing to do.
+	require.NoError(t, h.reencryptor.pass(ctx))
+	require.Equal(t, uint64(1), h.reencryptor.status().ReencryptedFiles)
+
+	// Simulate a crash in the middle of the swap of an sstable with its
+	// re-encrypted copy, after the entry of the copy was copied to the sstable.
+	writeToFile(t, env.FS, "/bar/000003.sst", []byte("old"))
+	writeToFile(t, env.FS, "/bar/000003.sst"+reencryptTmpSuffix, []byte("new"))
+	require.NoError(t, fileRegistry.SetFileEntry(
+		"/bar/000003.sst", fileRegistry.GetFileEntry("/bar/000003.sst"+reencryptTmpSuffix),
+	))
+	// A temporary file whose swap didn't start is removed on recovery.
+	writeToFile(t, env.FS, "/bar/000001.sst"+reencryptTmpSuffix, []byte("copy"))
+	require.NoError(t, env.Closer.Close())
+
+	// Read-only stores read the re-encrypted copy.
+	env, _ = openEnv("16v2.key", "16v1.key", baseccl.EncryptionCipherMode_GCM, true /* readOnly */)
+	require.Equal(t, "new", readFile(env.FS, "/bar/000003.sst"))
+	require.Equal(t, "sstable", readFile(env.FS, "/bar/000001.sst"))
+	require.NoError(t, env.Closer.Close())
+
+	// The swap is completed when the store is opened for writing.
+	env, _ = openEnv("16v2.key", "16v1.key", baseccl.EncryptionCipherMode_GCM, false /* readOnly */)
+	require.Equal(t, "new", readFile(env.FS, "/bar/000003.sst"))
+	require.Equal(t, "sstable", readFile(env.FS, "/bar/000001.sst"))
+	for _, name := range []string{"/bar/000001.sst", "/bar/000003.sst"} {
+		require.Nil(t, fileRegistry.GetFileEntry(name+reencryptTmpSuffix))
+		_, err = memFS.Stat(name + reencryptTmpSuffix)
+		require.True(t, oserror.IsNotExist(err))
+	}
+	require.NoError(t, env.Closer.Close())
+}
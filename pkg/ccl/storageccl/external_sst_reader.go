@@ -80,24 +80,36 @@ func newMemPebbleSSTReader(
 	inMemorySSTs := make([][]byte, 0, len(storeFiles))
 
 	for _, sf := range storeFiles {
-		f, _, err := getFileWithRetry(ctx, sf.FilePath, sf.Store)
+		content, err := ReadExternalSST(ctx, sf, encryption)
 		if err != nil {
 			return nil, err
 		}
-		content, err := ioctx.ReadAll(ctx, f)
-		f.Close(ctx)
+		inMemorySSTs = append(inMemorySSTs, content)
+	}
+	return storage.NewMultiMemSSTIterator(inMemorySSTs, false, iterOps)
+}
+
+// ReadExternalSST reads the entire contents of an SST from external storage,
+// optionally decrypting it with the supplied parameters.
+func ReadExternalSST(
+	ctx context.Context, sf StoreFile, encryption *roachpb.FileEncryptionOptions,
+) ([]byte, error) {
+	f, _, err := getFileWithRetry(ctx, sf.FilePath, sf.Store)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioctx.ReadAll(ctx, f)
+	f.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if encryption != nil {
+		content, err = DecryptFile(ctx, content, encryption.Key, nil /* mm */)
 		if err != nil {
 			return nil, err
 		}
-		if encryption != nil {
-			content, err = DecryptFile(ctx, content, encryption.Key, nil /* mm */)
-			if err != nil {
-				return nil, err
-			}
-		}
-		inMemorySSTs = append(inMemorySSTs, content)
 	}
-	return storage.NewMultiMemSSTIterator(inMemorySSTs, false, iterOps)
+	return content, nil
 }
 
 // ExternalSSTReader returns a PebbleSSTIterator for the SSTs in external storage,
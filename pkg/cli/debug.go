@@ -1297,6 +1297,7 @@ var debugCmds = []*cobra.Command{
 	debugDecodeProtoCmd,
 	debugGossipValuesCmd,
 	debugTimeSeriesDumpCmd,
+	debugAsimTraceCmd,
 	debugSyncBenchCmd,
 	debugSyncTestCmd,
 	debugEnvCmd,
@@ -1424,6 +1425,8 @@ func init() {
 	f.VarP(&debugRecoverExecuteOpts.Stores, cliflags.RecoverStore.Name, cliflags.RecoverStore.Shorthand, cliflags.RecoverStore.Usage())
 	f.VarP(&debugRecoverExecuteOpts.confirmAction, cliflags.ConfirmActions.Name, cliflags.ConfirmActions.Shorthand,
 		cliflags.ConfirmActions.Usage())
+	f.BoolVar(&debugRecoverExecuteOpts.forcePlan, "force", false,
+		"replace any different plan already staged on the nodes when staging plan through --host")
 
 	f = debugMergeLogsCmd.Flags()
 	f.Var(flagutil.Time(&debugMergeLogsOpts.from), "from",
@@ -1472,6 +1475,12 @@ func init() {
 	f.Var(&debugTimeSeriesDumpOpts.from, "from", "oldest timestamp to include (inclusive)")
 	f.Var(&debugTimeSeriesDumpOpts.to, "to", "newest timestamp to include (inclusive)")
 
+	f = debugAsimTraceCmd.Flags()
+	f.DurationVar(&debugAsimTraceOpts.duration, "duration", debugAsimTraceOpts.duration,
+		"how long to record the load of the cluster for")
+	f.DurationVar(&debugAsimTraceOpts.interval, "interval", debugAsimTraceOpts.interval,
+		"interval between the samples of the trace")
+
 	f = debugSendKVBatchCmd.Flags()
 	f.StringVar(&debugSendKVBatchContext.traceFormat, "trace", debugSendKVBatchContext.traceFormat,
 		"which format to use for the trace output (off, text, jaeger)")
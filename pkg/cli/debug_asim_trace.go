@@ -0,0 +1,223 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugAsimTraceOpts = struct {
+	duration, interval time.Duration
+}{
+	duration: 10 * time.Minute,
+	interval: 10 * time.Second,
+}
+
+var debugAsimTraceCmd = &cobra.Command{
+	Use:   "asim-trace",
+	Short: "record the per-range load of a cluster as an allocator simulator trace",
+	Long: `
+Records the load of the ranges of a cluster over time as a trace, which the
+allocator simulator (asim) can replay against a simulated cluster.
+
+The hot ranges of each store are polled every --interval for --duration, and
+each poll becomes a sample of the trace holding the rates of load which the
+leaseholder of each range reported at that time. Ranges which are not among
+the hottest ranges of their store in a poll have no load in its sample. The
+trace is written to stdout once the recording is over.
+`,
+	Args: cobra.NoArgs,
+	RunE: clierrorplus.MaybeDecorateError(runDebugAsimTrace),
+}
+
+func runDebugAsimTrace(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if debugAsimTraceOpts.interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	if debugAsimTraceOpts.duration < debugAsimTraceOpts.interval {
+		return errors.Newf("--duration (%s) must be at least --interval (%s)",
+			debugAsimTraceOpts.duration, debugAsimTraceOpts.interval)
+	}
+
+	conn, _, finish, err := getClientGRPCConn(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	status := serverpb.NewStatusClient(conn)
+
+	var snapshots []asimTraceSnapshot
+	ticker := time.NewTicker(debugAsimTraceOpts.interval)
+	defer ticker.Stop()
+	end := timeutil.Now().Add(debugAsimTraceOpts.duration)
+	for {
+		at := timeutil.Now()
+		hotRanges, err := status.HotRanges(ctx, &serverpb.HotRangesRequest{})
+		if err != nil {
+			return errors.Wrap(err, "fetching hot ranges")
+		}
+		for nodeID, nodeResp := range hotRanges.HotRangesByNodeID {
+			if nodeResp.ErrorMessage != "" {
+				fmt.Fprintf(stderr, "warning: unable to fetch the hot ranges of n%d: %s\n",
+					nodeID, nodeResp.ErrorMessage)
+			}
+		}
+		snapshots = append(snapshots, asimTraceSnapshot{at: at, hotRanges: hotRanges})
+		fmt.Fprintf(stderr, "recorded sample %d at %s\n", len(snapshots), at.Format(time.RFC3339))
+		if !at.Add(debugAsimTraceOpts.interval).Before(end) {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return workload.WriteTrace(os.Stdout, makeAsimTrace(snapshots))
+}
+
+// asimTraceSnapshot is a report of the hot ranges of a cluster, and the time
+// at which it was requested.
+type asimTraceSnapshot struct {
+	at        time.Time
+	hotRanges *serverpb.HotRangesResponse
+}
+
+// asimTraceRates are the rates of load of a range.
+type asimTraceRates struct {
+	reads, writes, readBytes, writeBytes, cpu float64
+}
+
+// asimTraceRange is a range reported by some snapshot of the hot ranges.
+type asimTraceRange struct {
+	desc roachpb.RangeDescriptor
+	// descTime is the time of the snapshot which desc was taken from.
+	descTime time.Time
+}
+
+// makeAsimTrace builds a trace from snapshots of the hot ranges of a cluster
+// taken over time, in chronological order. Each snapshot becomes a sample of
+// the trace, holding the rates of the ranges it reports. The ranges of the
+// trace are all the ranges reported by some snapshot, in the order of their
+// start keys as of the last snapshot reporting them.
+func makeAsimTrace(snapshots []asimTraceSnapshot) workload.Trace {
+	rangesByID := make(map[roachpb.RangeID]*asimTraceRange)
+	ratesBySnapshot := make([]map[roachpb.RangeID]asimTraceRates, len(snapshots))
+	for i, snapshot := range snapshots {
+		ratesBySnapshot[i] = asimTraceSnapshotRates(snapshot.hotRanges, func(desc roachpb.RangeDescriptor) {
+			if r, ok := rangesByID[desc.RangeID]; !ok || !snapshot.at.Before(r.descTime) {
+				rangesByID[desc.RangeID] = &asimTraceRange{desc: desc, descTime: snapshot.at}
+			}
+		})
+	}
+
+	ranges := make([]*asimTraceRange, 0, len(rangesByID))
+	for _, r := range rangesByID {
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		if c := ranges[i].desc.StartKey.Compare(ranges[j].desc.StartKey); c != 0 {
+			return c < 0
+		}
+		return ranges[i].desc.RangeID < ranges[j].desc.RangeID
+	})
+	var trace workload.Trace
+	for _, r := range ranges {
+		trace.Ranges = append(trace.Ranges, workload.TraceRange{
+			RangeID:  int64(r.desc.RangeID),
+			StartKey: r.desc.StartKey.String(),
+		})
+	}
+
+	for i, snapshot := range snapshots {
+		sample := workload.TraceSample{Offset: snapshot.at.Sub(snapshots[0].at)}
+		// Samples must be in strictly ascending order of their offsets.
+		if n := len(trace.Samples); n > 0 && sample.Offset <= trace.Samples[n-1].Offset {
+			continue
+		}
+		for idx, r := range ranges {
+			if rates, ok := ratesBySnapshot[i][r.desc.RangeID]; ok {
+				sample.Load = appendAsimTraceLoad(sample.Load, idx, rates)
+			}
+		}
+		trace.Samples = append(trace.Samples, sample)
+	}
+	return trace
+}
+
+// asimTraceSnapshotRates returns the rates of the ranges reported by a
+// snapshot of the hot ranges, preferring the report of the leaseholder of a
+// range, which is the replica that serves its load, over those of its
+// followers. The descriptor of each reported range is passed to addRange.
+func asimTraceSnapshotRates(
+	hotRanges *serverpb.HotRangesResponse, addRange func(roachpb.RangeDescriptor),
+) map[roachpb.RangeID]asimTraceRates {
+	rates := make(map[roachpb.RangeID]asimTraceRates)
+	fromLeaseholder := make(map[roachpb.RangeID]bool)
+	nodeIDs := make([]roachpb.NodeID, 0, len(hotRanges.HotRangesByNodeID))
+	for nodeID := range hotRanges.HotRangesByNodeID {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	for _, nodeID := range nodeIDs {
+		for _, store := range hotRanges.HotRangesByNodeID[nodeID].Stores {
+			for _, r := range store.HotRanges {
+				leaseholder := r.LeaseholderNodeID == nodeID
+				if _, ok := rates[r.Desc.RangeID]; ok && (fromLeaseholder[r.Desc.RangeID] || !leaseholder) {
+					continue
+				}
+				addRange(r.Desc)
+				rates[r.Desc.RangeID] = asimTraceRates{
+					reads:      r.ReadsPerSecond,
+					writes:     r.WritesPerSecond,
+					readBytes:  r.ReadBytesPerSecond,
+					writeBytes: r.WriteBytesPerSecond,
+					cpu:        r.CPUTimePerSecond,
+				}
+				fromLeaseholder[r.Desc.RangeID] = leaseholder
+			}
+		}
+	}
+	return rates
+}
+
+func appendAsimTraceLoad(
+	load []workload.TraceRangeLoad, rangeIdx int, rates asimTraceRates,
+) []workload.TraceRangeLoad {
+	if rates.reads == 0 && rates.writes == 0 && rates.readBytes == 0 &&
+		rates.writeBytes == 0 && rates.cpu == 0 {
+		return load
+	}
+	return append(load, workload.TraceRangeLoad{
+		Range:               rangeIdx,
+		ReadsPerSecond:      rates.reads,
+		WritesPerSecond:     rates.writes,
+		ReadBytesPerSecond:  rates.readBytes,
+		WriteBytesPerSecond: rates.writeBytes,
+		CPUNanosPerSecond:   rates.cpu,
+	})
+}
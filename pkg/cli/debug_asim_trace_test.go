@@ -0,0 +1,103 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeAsimTrace(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hotRange := func(
+		rangeID roachpb.RangeID, startKey string, leaseholder roachpb.NodeID, reads, writes, cpu float64,
+	) serverpb.HotRangesResponse_HotRange {
+		return serverpb.HotRangesResponse_HotRange{
+			Desc: roachpb.RangeDescriptor{
+				RangeID:  rangeID,
+				StartKey: roachpb.RKey(startKey),
+			},
+			LeaseholderNodeID: leaseholder,
+			ReadsPerSecond:    reads,
+			WritesPerSecond:   writes,
+			CPUTimePerSecond:  cpu,
+		}
+	}
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := func(
+		offset time.Duration, n1, n2 []serverpb.HotRangesResponse_HotRange,
+	) asimTraceSnapshot {
+		return asimTraceSnapshot{
+			at: start.Add(offset),
+			hotRanges: &serverpb.HotRangesResponse{
+				HotRangesByNodeID: map[roachpb.NodeID]serverpb.HotRangesResponse_NodeResponse{
+					1: {Stores: []*serverpb.HotRangesResponse_StoreResponse{{StoreID: 1, HotRanges: n1}}},
+					2: {Stores: []*serverpb.HotRangesResponse_StoreResponse{{StoreID: 2, HotRanges: n2}}},
+				},
+			},
+		}
+	}
+	type hr = []serverpb.HotRangesResponse_HotRange
+	snapshots := []asimTraceSnapshot{
+		// Range 2 is reported by both nodes, but only n2 holds its lease. The
+		// follower's report is ignored.
+		snapshot(0,
+			hr{hotRange(3, "c", 1, 30, 0, 1e6), hotRange(2, "b", 2, 0, 0, 0)},
+			hr{hotRange(2, "b", 2, 10, 20, 2e6)}),
+		// Range 3 cools down and is no longer reported, while range 4 starts
+		// being reported, after its lease moved to n1.
+		snapshot(10*time.Second,
+			hr{hotRange(4, "a", 1, 5, 5, 3e5)},
+			hr{hotRange(2, "b", 2, 40, 0, 4e6)}),
+		// The lease of range 2 moved to n1, which is now the only node to
+		// report it.
+		snapshot(20*time.Second,
+			hr{hotRange(2, "b", 1, 7, 0, 1e5)},
+			nil),
+	}
+
+	trace := makeAsimTrace(snapshots)
+	require.NoError(t, trace.Validate())
+	require.Equal(t, []workload.TraceRange{
+		{RangeID: 4, StartKey: `"a"`},
+		{RangeID: 2, StartKey: `"b"`},
+		{RangeID: 3, StartKey: `"c"`},
+	}, trace.Ranges)
+	require.Equal(t, []workload.TraceSample{
+		{
+			Offset: 0,
+			Load: []workload.TraceRangeLoad{
+				{Range: 1, ReadsPerSecond: 10, WritesPerSecond: 20, CPUNanosPerSecond: 2e6},
+				{Range: 2, ReadsPerSecond: 30, CPUNanosPerSecond: 1e6},
+			},
+		},
+		{
+			Offset: 10 * time.Second,
+			Load: []workload.TraceRangeLoad{
+				{Range: 0, ReadsPerSecond: 5, WritesPerSecond: 5, CPUNanosPerSecond: 3e5},
+				{Range: 1, ReadsPerSecond: 40, CPUNanosPerSecond: 4e6},
+			},
+		},
+		{
+			Offset: 20 * time.Second,
+			Load: []workload.TraceRangeLoad{
+				{Range: 1, ReadsPerSecond: 7, CPUNanosPerSecond: 1e5},
+			},
+		},
+	}, trace.Samples)
+}
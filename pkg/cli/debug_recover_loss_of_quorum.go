@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -161,6 +163,23 @@ Info:
 [cockroach@node5 ~]$ cockroach debug recover apply-plan --store=/mnt/cockroach-data-1 --store=/mnt/cockroach-data-2 recover-plan.json
 
 Now the cluster could be started again.
+
+Alternatively, if the cluster is still running and its surviving nodes are
+reachable, recovery could be performed through the admin API of any live node
+without access to the stores of every node:
+
+1. Run 'cockroach debug recover make-plan --host <node>' without replica info
+files. Replica info is collected from all reachable nodes of the cluster.
+
+2. Run 'cockroach debug recover apply-plan --host <node>' without --store
+flags. The plan is staged on all nodes of the cluster.
+
+3. Restart the nodes that are listed in the plan. The staged plan is applied
+by nodes on startup.
+
+4. Run 'cockroach debug recover verify --host <node>' to check that nodes have
+applied the plan and that no ranges lack quorum anymore. If some nodes failed
+to restart or apply the plan, the process could be repeated from step 1.
 `,
 	RunE: UsageAndErr,
 }
@@ -169,7 +188,8 @@ func init() {
 	debugRecoverCmd.AddCommand(
 		debugRecoverCollectInfoCmd,
 		debugRecoverPlanCmd,
-		debugRecoverExecuteCmd)
+		debugRecoverExecuteCmd,
+		debugRecoverVerifyCmd)
 }
 
 var debugRecoverCollectInfoCmd = &cobra.Command{
@@ -182,6 +202,9 @@ locations must be provided using --store flags.
 Collected information is written to a destination file if file name is provided,
 or to stdout.
 
+If no store locations are provided, information is collected from all live
+nodes of the cluster using the admin API of the node given by --host.
+
 Multiple store locations could be provided to the command to collect all info from
 node at once. It is also possible to call it per store, in that case all resulting
 files should be fed to plan subcommand.
@@ -197,22 +220,29 @@ var debugRecoverCollectInfoOpts struct {
 }
 
 func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
-	stopper := stop.NewStopper()
-	defer stopper.Stop(cmd.Context())
+	var replicaInfo loqrecoverypb.NodeReplicaInfo
+	var err error
+	if len(debugRecoverCollectInfoOpts.Stores.Specs) == 0 {
+		if replicaInfo, err = collectRemoteReplicaInfo(cmd.Context()); err != nil {
+			return err
+		}
+	} else {
+		stopper := stop.NewStopper()
+		defer stopper.Stop(cmd.Context())
 
-	var stores []storage.Engine
-	for _, storeSpec := range debugRecoverCollectInfoOpts.Stores.Specs {
-		db, err := OpenEngine(storeSpec.Path, stopper, storage.MustExist, storage.ReadOnly)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open store at path %q, ensure that store path is "+
-				"correct and that it is not used by another process", storeSpec.Path)
+		var stores []storage.Engine
+		for _, storeSpec := range debugRecoverCollectInfoOpts.Stores.Specs {
+			db, err := OpenEngine(storeSpec.Path, stopper, storage.MustExist, storage.ReadOnly)
+			if err != nil {
+				return errors.Wrapf(err, "failed to open store at path %q, ensure that store path is "+
+					"correct and that it is not used by another process", storeSpec.Path)
+			}
+			stores = append(stores, db)
 		}
-		stores = append(stores, db)
-	}
 
-	replicaInfo, err := loqrecovery.CollectReplicaInfo(cmd.Context(), stores)
-	if err != nil {
-		return err
+		if replicaInfo, err = loqrecovery.CollectReplicaInfo(cmd.Context(), stores); err != nil {
+			return err
+		}
 	}
 
 	var writer io.Writer = os.Stdout
@@ -241,6 +271,33 @@ func runDebugDeadReplicaCollect(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// collectRemoteReplicaInfo collects replica info from all live nodes of the
+// cluster through the admin API. Info from all nodes is combined into a single
+// collection which is equivalent to the collection of files produced by
+// running collect-info on every node.
+func collectRemoteReplicaInfo(ctx context.Context) (loqrecoverypb.NodeReplicaInfo, error) {
+	c, finish, err := getAdminClient(ctx, serverCfg)
+	if err != nil {
+		return loqrecoverypb.NodeReplicaInfo{}, err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryCollectReplicaInfo(ctx, &serverpb.RecoveryCollectReplicaInfoRequest{})
+	if err != nil {
+		return loqrecoverypb.NodeReplicaInfo{}, errors.Wrap(err,
+			"failed to collect replica info from the cluster")
+	}
+	if len(resp.UnreachableNodeIDs) > 0 {
+		_, _ = fmt.Fprintf(stderr, "Failed to collect replica info from nodes: %s. "+
+			"Stores of those nodes will be considered dead.\n", joinNodeIDs(resp.UnreachableNodeIDs))
+	}
+	var replicaInfo loqrecoverypb.NodeReplicaInfo
+	for _, nodeInfo := range resp.ReplicaInfo {
+		replicaInfo.Replicas = append(replicaInfo.Replicas, nodeInfo.Replicas...)
+	}
+	return replicaInfo, nil
+}
+
 var debugRecoverPlanCmd = &cobra.Command{
 	Use:   "make-plan [replica-files]",
 	Short: "generate a plan to recover ranges that lost quorum",
@@ -254,9 +311,12 @@ Decision is then written into a file or stdout.
 
 This command only creates a plan and doesn't change any data.'
 
+If no replica info files are provided, replica info is collected from all live
+nodes of the cluster using the admin API of the node given by --host.
+
 See debug recover command help for more details on how to use this command.
 `,
-	Args: cobra.MinimumNArgs(1),
+	Args: cobra.ArbitraryArgs,
 	RunE: runDebugPlanReplicaRemoval,
 }
 
@@ -268,9 +328,18 @@ var debugRecoverPlanOpts struct {
 }
 
 func runDebugPlanReplicaRemoval(cmd *cobra.Command, args []string) error {
-	replicas, err := readReplicaInfoData(args)
-	if err != nil {
-		return err
+	var replicas []loqrecoverypb.NodeReplicaInfo
+	if len(args) == 0 {
+		replicaInfo, err := collectRemoteReplicaInfo(cmd.Context())
+		if err != nil {
+			return err
+		}
+		replicas = []loqrecoverypb.NodeReplicaInfo{replicaInfo}
+	} else {
+		var err error
+		if replicas, err = readReplicaInfoData(args); err != nil {
+			return err
+		}
 	}
 
 	var deadStoreIDs []roachpb.StoreID
@@ -389,6 +458,8 @@ Discarded live replicas: %d
 	for node, stores := range report.UpdatedNodes {
 		_, _ = fmt.Fprintf(stderr, "- node n%d, store(s) %s\n", node, joinStoreIDs(stores))
 	}
+	_, _ = fmt.Fprint(stderr, "Alternatively, if the cluster is running, stage the plan on all"+
+		" nodes with `debug recover apply-plan --host` and restart the above nodes.\n")
 
 	return nil
 }
@@ -420,6 +491,10 @@ Apply changes to replicas in the provided stores using a plan.
 This command will read a plan and update replicas that belong to the
 given stores. Stores must be provided using --store flags. 
 
+If no store locations are provided, the plan is staged on all nodes of the
+running cluster using the admin API of the node given by --host. Nodes apply
+staged plan when they are restarted.
+
 See debug recover command help for more details on how to use this command.
 `,
 	Args: cobra.ExactArgs(1),
@@ -429,6 +504,7 @@ See debug recover command help for more details on how to use this command.
 var debugRecoverExecuteOpts struct {
 	Stores        base.StoreSpecList
 	confirmAction confirmActionFlag
+	forcePlan     bool
 }
 
 // runDebugExecuteRecoverPlan is using the following pattern when performing command
@@ -453,6 +529,10 @@ func runDebugExecuteRecoverPlan(cmd *cobra.Command, args []string) error {
 		return errors.Wrapf(err, "failed to unmarshal plan from file %q", planFile)
 	}
 
+	if len(debugRecoverExecuteOpts.Stores.Specs) == 0 {
+		return stageRecoveryPlan(cmd.Context(), nodeUpdates)
+	}
+
 	var localNodeID roachpb.NodeID
 	batches := make(map[roachpb.StoreID]storage.Batch)
 	for _, storeSpec := range debugRecoverExecuteOpts.Stores.Specs {
@@ -536,6 +616,139 @@ func runDebugExecuteRecoverPlan(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// stageRecoveryPlan stages the plan on all nodes of the cluster through the
+// admin API. Plan is applied by nodes on the next restart.
+func stageRecoveryPlan(ctx context.Context, plan loqrecoverypb.ReplicaUpdatePlan) error {
+	if len(plan.Updates) == 0 {
+		_, _ = fmt.Fprintf(stderr, "Plan contains no updates, nothing to do.\n")
+		return nil
+	}
+	nodeSet := make(map[roachpb.NodeID]struct{})
+	var nodeIDs []roachpb.NodeID
+	for _, u := range plan.Updates {
+		if _, ok := nodeSet[u.NodeID()]; !ok {
+			nodeSet[u.NodeID()] = struct{}{}
+			nodeIDs = append(nodeIDs, u.NodeID())
+		}
+		_, _ = fmt.Fprintf(stderr, "Replica %s for range r%d:%s will be updated on node n%d.\n",
+			u.NewReplica, u.RangeID, u.StartKey.AsRKey(), u.NodeID())
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+
+	switch debugRecoverExecuteOpts.confirmAction {
+	case prompt:
+		_, _ = fmt.Fprintf(stderr, "\nProceed with staging plan %s [y/N] ", plan.PlanID)
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return errors.Wrap(err, "failed to read user input")
+		}
+		_, _ = fmt.Fprintf(stderr, "\n")
+		if len(line) < 1 || (line[0] != 'y' && line[0] != 'Y') {
+			_, _ = fmt.Fprint(stderr, "Aborted at user request\n")
+			return nil
+		}
+	case allYes:
+		// All actions enabled by default.
+	default:
+		return errors.New("Aborted by --confirm option")
+	}
+
+	c, finish, err := getAdminClient(ctx, serverCfg)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryStagePlan(ctx, &serverpb.RecoveryStagePlanRequest{
+		Plan:      plan,
+		AllNodes:  true,
+		ForcePlan: debugRecoverExecuteOpts.forcePlan,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to stage loss of quorum recovery plan")
+	}
+	if len(resp.Errors) > 0 {
+		for _, e := range resp.Errors {
+			_, _ = fmt.Fprintf(stderr, "%s\n", e)
+		}
+		return errors.New("failed to stage loss of quorum recovery plan on cluster")
+	}
+	_, _ = fmt.Fprintf(stderr, "Plan %s staged. To complete recovery restart nodes %s.\n"+
+		"Use `debug recover verify` to check recovery progress.\n",
+		plan.PlanID, joinNodeIDs(nodeIDs))
+	return nil
+}
+
+var debugRecoverVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify loss of quorum recovery progress",
+	Long: `
+Verify loss of quorum recovery progress of the running cluster.
+
+This command will report status of recovery plans staged or applied on all
+live nodes of the cluster using the admin API of the node given by --host.
+It also checks if any ranges still lack quorum. Command fails if such ranges
+are found.
+
+See debug recover command help for more details on how to use this command.
+`,
+	Args: cobra.NoArgs,
+	RunE: runDebugVerify,
+}
+
+func runDebugVerify(cmd *cobra.Command, args []string) error {
+	c, finish, err := getAdminClient(cmd.Context(), serverCfg)
+	if err != nil {
+		return err
+	}
+	defer finish()
+
+	resp, err := c.RecoveryVerify(cmd.Context(), &serverpb.RecoveryVerifyRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to verify loss of quorum recovery status")
+	}
+
+	_, _ = fmt.Fprintf(stderr, "Nodes:\n")
+	for _, s := range resp.Statuses {
+		switch {
+		case s.PendingPlanID != nil:
+			_, _ = fmt.Fprintf(stderr, "- node n%d: plan %s is staged, waiting for node restart\n",
+				s.NodeID, *s.PendingPlanID)
+		case s.AppliedPlanID != nil && s.Error != "":
+			_, _ = fmt.Fprintf(stderr, "- node n%d: failed to apply plan %s at %s: %s\n",
+				s.NodeID, *s.AppliedPlanID, s.ApplyTimestamp, s.Error)
+		case s.AppliedPlanID != nil:
+			_, _ = fmt.Fprintf(stderr, "- node n%d: applied plan %s at %s\n",
+				s.NodeID, *s.AppliedPlanID, s.ApplyTimestamp)
+		default:
+			_, _ = fmt.Fprintf(stderr, "- node n%d: no recovery plans\n", s.NodeID)
+		}
+	}
+	for _, e := range resp.Errors {
+		_, _ = fmt.Fprintf(stderr, "Error: %s\n", e)
+	}
+
+	if len(resp.UnavailableRanges) > 0 {
+		_, _ = fmt.Fprintf(stderr, "\nRanges without quorum:\n")
+		for _, desc := range resp.UnavailableRanges {
+			_, _ = fmt.Fprintf(stderr, "- range r%d:%s replicas %s\n",
+				desc.RangeID, desc.StartKey, desc.Replicas())
+		}
+		return errors.Newf("found %d ranges without quorum", len(resp.UnavailableRanges))
+	}
+	_, _ = fmt.Fprintf(stderr, "\nAll ranges have quorum.\n")
+	return nil
+}
+
+func joinNodeIDs(nodeIDs []roachpb.NodeID) string {
+	nodeNames := make([]string, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		nodeNames = append(nodeNames, fmt.Sprintf("n%d", id))
+	}
+	return strings.Join(nodeNames, ", ")
+}
+
 func joinStoreIDs(storeIDs []roachpb.StoreID) string {
 	storeNames := make([]string, 0, len(storeIDs))
 	for _, id := range storeIDs {
@@ -553,4 +766,5 @@ func setDebugRecoverContextDefaults() {
 	debugRecoverPlanOpts.deadStoreIDs = nil
 	debugRecoverExecuteOpts.Stores.Specs = nil
 	debugRecoverExecuteOpts.confirmAction = prompt
+	debugRecoverExecuteOpts.forcePlan = false
 }
@@ -0,0 +1,135 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package amazon
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/errors"
+)
+
+// AuthOptions are the options, passed as query parameters of a URI, which
+// determine how to authenticate to AWS.
+type AuthOptions struct {
+	// Auth is the value of the cloud.AuthParam parameter.
+	Auth      string
+	AccessKey string
+	Secret    string
+	TempToken string
+	// RoleARN is the role to assume, and DelegateRoleARNs is the chain of roles
+	// to assume in order to assume it.
+	RoleARN          string
+	DelegateRoleARNs []string
+}
+
+// ConsumeAuthOptions consumes the authentication parameters from the given URI.
+func ConsumeAuthOptions(uri *cloud.ConsumeURL) AuthOptions {
+	assumeRole, delegateRoles := cloud.ParseRoleString(uri.ConsumeParam(AssumeRoleParam))
+	opts := AuthOptions{
+		Auth:             uri.ConsumeParam(cloud.AuthParam),
+		AccessKey:        uri.ConsumeParam(AWSAccessKeyParam),
+		Secret:           uri.ConsumeParam(AWSSecretParam),
+		TempToken:        uri.ConsumeParam(AWSTempTokenParam),
+		RoleARN:          assumeRole,
+		DelegateRoleARNs: delegateRoles,
+	}
+
+	// AWS secrets often contain + characters, which must be escaped when
+	// included in a query string; otherwise, they represent a space character.
+	// More than a few users have been bitten by this.
+	//
+	// Luckily, AWS secrets are base64-encoded data and thus will never actually
+	// contain spaces. We can convert any space characters we see to +
+	// characters to recover the original secret.
+	opts.Secret = strings.Replace(opts.Secret, " ", "+", -1)
+	return opts
+}
+
+// NewSession creates an AWS session which authenticates as specified by the
+// options, and which uses the given configuration. service names the service
+// the session is used for in error messages.
+func (o AuthOptions) NewSession(
+	ctx context.Context, settings *cluster.Settings, awsConfig *aws.Config, service string,
+) (*session.Session, error) {
+	// "specified": use credentials provided in URI params; error if not present.
+	// "implicit": enable SharedConfig, which loads in credentials from environment.
+	//             Detailed in https://docs.aws.amazon.com/sdk-for-go/api/aws/session/
+	// "": default to `specified`.
+	opts := session.Options{}
+	opts.Config.MergeIn(awsConfig)
+	switch o.Auth {
+	case "", cloud.AuthParamSpecified:
+		if o.AccessKey == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but %s is not set",
+				cloud.AuthParam,
+				cloud.AuthParamSpecified,
+				AWSAccessKeyParam,
+			)
+		}
+		if o.Secret == "" {
+			return nil, errors.Errorf(
+				"%s is set to '%s', but %s is not set",
+				cloud.AuthParam,
+				cloud.AuthParamSpecified,
+				AWSSecretParam,
+			)
+		}
+		opts.Config.Credentials = credentials.NewStaticCredentials(o.AccessKey, o.Secret, o.TempToken)
+	case cloud.AuthParamImplicit:
+		opts.SharedConfigState = session.SharedConfigEnable
+	default:
+		return nil, errors.Errorf("unsupported value %s for %s", o.Auth, cloud.AuthParam)
+	}
+
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "new aws session")
+	}
+
+	if o.RoleARN != "" {
+		if !settings.Version.IsActive(ctx, clusterversion.SupportAssumeRoleAuth) {
+			return nil, errors.Newf(
+				"cannot authenticate to %s via assume role until cluster has fully upgraded to 22.2",
+				service,
+			)
+		}
+
+		// If there are delegate roles in the assume-role chain, we create a session
+		// for each role in order for it to fetch the credentials from the next role
+		// in the chain.
+		for _, role := range o.DelegateRoleARNs {
+			intermediateCreds := stscreds.NewCredentials(sess, role)
+			opts.Config.Credentials = intermediateCreds
+
+			sess, err = session.NewSessionWithOptions(opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "session with intermediate credentials")
+			}
+		}
+
+		creds := stscreds.NewCredentials(sess, o.RoleARN)
+		opts.Config.Credentials = creds
+		sess, err = session.NewSessionWithOptions(opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "session with assume role credentials")
+		}
+	}
+	return sess, nil
+}
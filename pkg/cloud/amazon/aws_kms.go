@@ -16,12 +16,8 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
-	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
@@ -43,27 +39,16 @@ func init() {
 }
 
 type kmsURIParams struct {
-	accessKey        string
-	secret           string
-	tempToken        string
-	endpoint         string
-	region           string
-	auth             string
-	roleARN          string
-	delegateRoleARNs []string
+	AuthOptions
+	endpoint string
+	region   string
 }
 
 func resolveKMSURIParams(kmsURI cloud.ConsumeURL) (kmsURIParams, error) {
-	assumeRole, delegateRoles := cloud.ParseRoleString(kmsURI.ConsumeParam(AssumeRoleParam))
 	params := kmsURIParams{
-		accessKey:        kmsURI.ConsumeParam(AWSAccessKeyParam),
-		secret:           kmsURI.ConsumeParam(AWSSecretParam),
-		tempToken:        kmsURI.ConsumeParam(AWSTempTokenParam),
-		endpoint:         kmsURI.ConsumeParam(AWSEndpointParam),
-		region:           kmsURI.ConsumeParam(KMSRegionParam),
-		auth:             kmsURI.ConsumeParam(cloud.AuthParam),
-		roleARN:          assumeRole,
-		delegateRoleARNs: delegateRoles,
+		AuthOptions: ConsumeAuthOptions(&kmsURI),
+		endpoint:    kmsURI.ConsumeParam(AWSEndpointParam),
+		region:      kmsURI.ConsumeParam(KMSRegionParam),
 	}
 
 	// Validate that all the passed in parameters are supported.
@@ -71,15 +56,6 @@ func resolveKMSURIParams(kmsURI cloud.ConsumeURL) (kmsURIParams, error) {
 		return kmsURIParams{}, errors.Errorf(
 			`unknown KMS query parameters: %s`, strings.Join(unknownParams, ", "))
 	}
-
-	// AWS secrets often contain + characters, which must be escaped when
-	// included in a query string; otherwise, they represent a space character.
-	// More than a few users have been bitten by this.
-	//
-	// Luckily, AWS secrets are base64-encoded data and thus will never actually
-	// contain spaces. We can convert any space characters we see to +
-	// characters to recover the original secret.
-	params.secret = strings.Replace(params.secret, " ", "+", -1)
 	return params, nil
 }
 
@@ -101,10 +77,7 @@ func MakeAWSKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, e
 		return nil, err
 	}
 	region := kmsURIParams.region
-	awsConfig := &aws.Config{
-		Credentials: credentials.NewStaticCredentials(kmsURIParams.accessKey,
-			kmsURIParams.secret, kmsURIParams.tempToken),
-	}
+	awsConfig := &aws.Config{}
 	awsConfig.Logger = newLogAdapter(ctx)
 	if log.V(2) {
 		awsConfig.LogLevel = awsVerboseLogging
@@ -128,69 +101,13 @@ func MakeAWSKMS(ctx context.Context, uri string, env cloud.KMSEnv) (cloud.KMS, e
 		awsConfig.HTTPClient = client
 	}
 
-	// "specified": use credentials provided in URI params; error if not present.
-	// "implicit": enable SharedConfig, which loads in credentials from environment.
-	//             Detailed in https://docs.aws.amazon.com/sdk-for-go/api/aws/session/
-	// "": default to `specified`.
-	opts := session.Options{}
-	switch kmsURIParams.auth {
-	case "", cloud.AuthParamSpecified:
-		if kmsURIParams.accessKey == "" {
-			return nil, errors.Errorf(
-				"%s is set to '%s', but %s is not set",
-				cloud.AuthParam,
-				cloud.AuthParamSpecified,
-				AWSAccessKeyParam,
-			)
-		}
-		if kmsURIParams.secret == "" {
-			return nil, errors.Errorf(
-				"%s is set to '%s', but %s is not set",
-				cloud.AuthParam,
-				cloud.AuthParamSpecified,
-				AWSSecretParam,
-			)
-		}
-		opts.Config.MergeIn(awsConfig)
-	case cloud.AuthParamImplicit:
-		if env.KMSConfig().DisableImplicitCredentials {
-			return nil, errors.New(
-				"implicit credentials disallowed for s3 due to --external-io-implicit-credentials flag")
-		}
-		opts.SharedConfigState = session.SharedConfigEnable
-	default:
-		return nil, errors.Errorf("unsupported value %s for %s", kmsURIParams.auth, cloud.AuthParam)
+	if kmsURIParams.Auth == cloud.AuthParamImplicit && env.KMSConfig().DisableImplicitCredentials {
+		return nil, errors.New(
+			"implicit credentials disallowed for s3 due to --external-io-implicit-credentials flag")
 	}
-
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := kmsURIParams.NewSession(ctx, env.ClusterSettings(), awsConfig, "KMS")
 	if err != nil {
-		return nil, errors.Wrap(err, "new aws session")
-	}
-
-	if kmsURIParams.roleARN != "" {
-		if !env.ClusterSettings().Version.IsActive(ctx, clusterversion.SupportAssumeRoleAuth) {
-			return nil, errors.New("cannot authenticate to KMS via assume role until cluster has fully upgraded to 22.2")
-		}
-
-		// If there are delegate roles in the assume-role chain, we create a session
-		// for each role in order for it to fetch the credentials from the next role
-		// in the chain.
-		for _, role := range kmsURIParams.delegateRoleARNs {
-			intermediateCreds := stscreds.NewCredentials(sess, role)
-			opts.Config.Credentials = intermediateCreds
-
-			sess, err = session.NewSessionWithOptions(opts)
-			if err != nil {
-				return nil, errors.Wrap(err, "session with intermediate credentials")
-			}
-		}
-
-		creds := stscreds.NewCredentials(sess, kmsURIParams.roleARN)
-		opts.Config.Credentials = creds
-		sess, err = session.NewSessionWithOptions(opts)
-		if err != nil {
-			return nil, errors.Wrap(err, "session with assume role credentials")
-		}
+		return nil, err
 	}
 
 	if region == "" {
@@ -57,6 +57,8 @@ const (
 	AWSTempTokenParam = "AWS_SESSION_TOKEN"
 	// AWSEndpointParam is the query parameter for the 'endpoint' in an AWS URI.
 	AWSEndpointParam = "AWS_ENDPOINT"
+	// AWSRegionParam is the query parameter for the 'region' in an AWS URI.
+	AWSRegionParam = "AWS_REGION"
 
 	// AWSServerSideEncryptionMode is the query parameter in an AWS URI, for the
 	// mode to be used for server side encryption. It can either be AES256 or
@@ -72,7 +74,7 @@ const (
 	S3StorageClassParam = "S3_STORAGE_CLASS"
 
 	// S3RegionParam is the query parameter for the 'endpoint' in an S3 URI.
-	S3RegionParam = "AWS_REGION"
+	S3RegionParam = AWSRegionParam
 
 	// KMSRegionParam is the query parameter for the 'region' in every KMS URI.
 	KMSRegionParam = "REGION"
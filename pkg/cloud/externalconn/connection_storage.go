@@ -23,7 +23,8 @@ import (
 	"github.com/cockroachdb/errors"
 )
 
-const scheme = "external"
+// Scheme is the scheme of the URIs that refer to External Connections.
+const Scheme = "external"
 
 func makeExternalConnectionConfig(
 	uri *url.URL, args cloud.ExternalStorageURIContext,
@@ -101,5 +102,5 @@ func makeExternalConnectionStorage(
 
 func init() {
 	cloud.RegisterExternalStorageProvider(cloudpb.ExternalStorageProvider_external, parseExternalConnectionURL,
-		makeExternalConnectionStorage, cloud.RedactedParams(), scheme)
+		makeExternalConnectionStorage, cloud.RedactedParams(), Scheme)
 }
@@ -306,6 +306,60 @@ const (
 	// heuristics to identify invalid table descriptors for userfile-related
 	// descriptors.
 	FixUserfileRelatedDescriptorCorruption
+	// SystemNotificationsTable adds the system.notifications table, which is
+	// used to fan out NOTIFY messages to listening sessions.
+	SystemNotificationsTable
+	// RowLevelTriggers adds support for row-level triggers, which are stored in
+	// table descriptors and invoke user-defined functions.
+	RowLevelTriggers
+	// TSearchTypes adds the TSVECTOR and TSQUERY types, which can be used for
+	// columns and inverted indexes.
+	TSearchTypes
+	// Publications adds support for logical replication publications, which
+	// are stored in database descriptors.
+	Publications
+	// ReadCommittedIsolation allows transactions to run with the READ COMMITTED
+	// isolation level.
+	ReadCommittedIsolation
+	// SplitByLoadCPU is the version from which RangeStats reports the CPU time
+	// spent serving the requests to a range, which allows the merge queue to be
+	// driven by CPU.
+	SplitByLoadCPU
+	// DeferrableConstraints allows foreign key and UNIQUE WITHOUT INDEX constraints
+	// to be declared DEFERRABLE, in which case their checks may be deferred until
+	// the end of the transaction.
+	DeferrableConstraints
+	// PLpgSQLFunctions allows user-defined functions to be written in PL/pgSQL.
+	PLpgSQLFunctions
+	// Procedures adds support for stored procedures, which are invoked with CALL and
+	// may contain COMMIT and ROLLBACK statements.
+	Procedures
+	// Domains adds support for domain types, which are stored in type descriptors
+	// along with their default expression and constraints.
+	Domains
+	// ExclusionConstraints adds support for EXCLUDE constraints, which are backed by
+	// an index and checked by mutations of the table.
+	ExclusionConstraints
+	// ContinuousBackups adds the continuous option of BACKUP, whose jobs keep
+	// writing the MVCC history of the backed up spans into a log once the full
+	// backup completes.
+	ContinuousBackups
+	// ReadReplicas adds the num_read_replicas and read_replica_constraints zone
+	// config fields, which place dedicated non-voting replicas to serve follower
+	// reads.
+	ReadReplicas
+	// VerifyBackup enables VERIFY BACKUP jobs.
+	VerifyBackup
+	// AttachBackup enables ATTACH BACKUP, which writes table descriptors whose
+	// BackupSource is set.
+	AttachBackup
+	// ChangefeedParquetFormat adds the parquet format of changefeeds to cloud
+	// storage sinks.
+	ChangefeedParquetFormat
+	// SSTStorageParams adds the compression and block_size storage parameters
+	// of tables and indexes, which stores apply to the SSTs they write for the
+	// data of a range.
+	SSTStorageParams
 	// *************************************************
 	// Step (1): Add new versions here.
 	// Do not add new versions to a patch release.
@@ -496,6 +550,74 @@ var rawVersionsSingleton = keyedVersions{
 		Key:     FixUserfileRelatedDescriptorCorruption,
 		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 76},
 	},
+	{
+		Key:     SystemNotificationsTable,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 78},
+	},
+	{
+		Key:     RowLevelTriggers,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 80},
+	},
+	{
+		Key:     TSearchTypes,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 82},
+	},
+	{
+		Key:     Publications,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 84},
+	},
+	{
+		Key:     ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 86},
+	},
+	{
+		Key:     SplitByLoadCPU,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 88},
+	},
+	{
+		Key:     DeferrableConstraints,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 90},
+	},
+	{
+		Key:     PLpgSQLFunctions,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 92},
+	},
+	{
+		Key:     Procedures,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 94},
+	},
+	{
+		Key:     Domains,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 96},
+	},
+	{
+		Key:     ExclusionConstraints,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 98},
+	},
+	{
+		Key:     ContinuousBackups,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 100},
+	},
+	{
+		Key:     ReadReplicas,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 102},
+	},
+	{
+		Key:     VerifyBackup,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 104},
+	},
+	{
+		Key:     AttachBackup,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 106},
+	},
+	{
+		Key:     ChangefeedParquetFormat,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 108},
+	},
+	{
+		Key:     SSTStorageParams,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 110},
+	},
 	// *************************************************
 	// Step (2): Add new versions here.
 	// Do not add new versions to a patch release.
@@ -335,6 +335,10 @@ func (z *ZoneConfig) ValidateTandemFields() error {
 		return fmt.Errorf("when voter_constraints are set, num_voters must be set as well")
 	}
 
+	if len(z.ReadReplicaConstraints) > 0 && z.NumReadReplicas == nil {
+		return fmt.Errorf("when read_replica_constraints are set, num_read_replicas must be set as well")
+	}
+
 	if (z.RangeMinBytes != nil || z.RangeMaxBytes != nil) &&
 		(z.RangeMinBytes == nil || z.RangeMaxBytes == nil) {
 		return fmt.Errorf("range_min_bytes and range_max_bytes must be set together")
@@ -390,6 +394,13 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumReadReplicas != nil && *z.NumReadReplicas < 0 {
+		return fmt.Errorf("num_read_replicas cannot be negative")
+	}
+	if z.ReadReplicaLagTarget != nil && *z.ReadReplicaLagTarget <= 0 {
+		return fmt.Errorf("read_replica_lag_target must be positive")
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < base.MinRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, base.MinRangeMaxBytes)
@@ -494,6 +505,40 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	// Read replicas are only constrained by `read_replica_constraints`, which
+	// are validated like the per-replica `constraints` against the number of
+	// read replicas.
+	for _, constraints := range z.ReadReplicaConstraints {
+		for _, constraint := range constraints.Constraints {
+			if constraint.Type == Constraint_DEPRECATED_POSITIVE {
+				return fmt.Errorf("read_replica_constraints must either be required (prefixed with a '+') or " +
+					"prohibited (prefixed with a '-')")
+			}
+		}
+	}
+	if len(z.ReadReplicaConstraints) > 1 ||
+		(len(z.ReadReplicaConstraints) == 1 && z.ReadReplicaConstraints[0].NumReplicas != 0) {
+		var numConstrainedRepls int64
+		for _, constraints := range z.ReadReplicaConstraints {
+			if constraints.NumReplicas <= 0 {
+				return fmt.Errorf("constraints must apply to at least one replica")
+			}
+			numConstrainedRepls += int64(constraints.NumReplicas)
+			for _, constraint := range constraints.Constraints {
+				if constraint.Type != Constraint_REQUIRED && z.NumReadReplicas != nil &&
+					constraints.NumReplicas != *z.NumReadReplicas {
+					return fmt.Errorf(
+						"only required constraints (prefixed with a '+') can be applied to a subset of read replicas")
+				}
+			}
+		}
+		if z.NumReadReplicas != nil && numConstrainedRepls > int64(*z.NumReadReplicas) {
+			return fmt.Errorf("the number of replicas specified in read_replica_constraints (%d) cannot be "+
+				"greater than the number of read replicas configured for the zone (%d)",
+				numConstrainedRepls, *z.NumReadReplicas)
+		}
+	}
+
 	//  Validate that `constraints` aren't incompatible with `voter_constraints`.
 	if err := validateVoterConstraintsCompatibility(z.VoterConstraints, z.Constraints); err != nil {
 		return err
@@ -561,6 +606,20 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.GlobalReads = proto.Bool(*parent.GlobalReads)
 		}
 	}
+	// The read replica constraints are inherited along with the number of read
+	// replicas they apply to.
+	if z.NumReadReplicas == nil {
+		if parent.NumReadReplicas != nil {
+			z.NumReadReplicas = proto.Int32(*parent.NumReadReplicas)
+			z.ReadReplicaConstraints = parent.ReadReplicaConstraints
+		}
+	}
+	if z.ReadReplicaLagTarget == nil {
+		if parent.ReadReplicaLagTarget != nil {
+			lagTarget := *parent.ReadReplicaLagTarget
+			z.ReadReplicaLagTarget = &lagTarget
+		}
+	}
 	if z.RangeMinBytes == nil {
 		if parent.RangeMinBytes != nil {
 			z.RangeMinBytes = proto.Int64(*parent.RangeMinBytes)
@@ -635,6 +694,19 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 		case "lease_preferences":
 			z.LeasePreferences = other.LeasePreferences
 			z.InheritedLeasePreferences = other.InheritedLeasePreferences
+		case "num_read_replicas":
+			z.NumReadReplicas = nil
+			if other.NumReadReplicas != nil {
+				z.NumReadReplicas = proto.Int32(*other.NumReadReplicas)
+			}
+		case "read_replica_constraints":
+			z.ReadReplicaConstraints = other.ReadReplicaConstraints
+		case "read_replica_lag_target":
+			z.ReadReplicaLagTarget = nil
+			if other.ReadReplicaLagTarget != nil {
+				lagTarget := *other.ReadReplicaLagTarget
+				z.ReadReplicaLagTarget = &lagTarget
+			}
 		}
 	}
 }
@@ -793,6 +865,39 @@ func (z *ZoneConfig) DiffWithZone(
 					}
 				}
 			}
+		case "num_read_replicas":
+			if other.NumReadReplicas == nil && z.NumReadReplicas == nil {
+				continue
+			}
+			if z.NumReadReplicas == nil || other.NumReadReplicas == nil ||
+				*z.NumReadReplicas != *other.NumReadReplicas {
+				return false, DiffWithZoneMismatch{
+					Field: "num_read_replicas",
+				}, nil
+			}
+		case "read_replica_constraints":
+			if len(z.ReadReplicaConstraints) != len(other.ReadReplicaConstraints) {
+				return false, DiffWithZoneMismatch{
+					Field: "read_replica_constraints",
+				}, nil
+			}
+			for i, c := range z.ReadReplicaConstraints {
+				if !c.Equal(&other.ReadReplicaConstraints[i]) {
+					return false, DiffWithZoneMismatch{
+						Field: "read_replica_constraints",
+					}, nil
+				}
+			}
+		case "read_replica_lag_target":
+			if other.ReadReplicaLagTarget == nil && z.ReadReplicaLagTarget == nil {
+				continue
+			}
+			if z.ReadReplicaLagTarget == nil || other.ReadReplicaLagTarget == nil ||
+				*z.ReadReplicaLagTarget != *other.ReadReplicaLagTarget {
+				return false, DiffWithZoneMismatch{
+					Field: "read_replica_lag_target",
+				}, nil
+			}
 		default:
 			return false, DiffWithZoneMismatch{}, errors.AssertionFailedf("unknown zone configuration field %q", fieldName)
 		}
@@ -1179,6 +1284,19 @@ func (z *ZoneConfig) toSpanConfig() (roachpb.SpanConfig, error) {
 		}
 	}
 
+	if z.NumReadReplicas != nil {
+		sc.NumReadReplicas = *z.NumReadReplicas
+	}
+	if len(z.ReadReplicaConstraints) != 0 {
+		sc.ReadReplicaConstraints, err = toSpanConfigConstraintsConjunction(z.ReadReplicaConstraints)
+		if err != nil {
+			return roachpb.SpanConfig{}, err
+		}
+	}
+	if z.ReadReplicaLagTarget != nil {
+		sc.ReadReplicaLagTarget = *z.ReadReplicaLagTarget
+	}
+
 	if len(z.LeasePreferences) != 0 {
 		sc.LeasePreferences = make([]roachpb.LeasePreference, len(z.LeasePreferences))
 		for i, leasePreference := range z.LeasePreferences {
@@ -16,6 +16,7 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -237,6 +238,50 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			ZoneConfig{
+				NumReplicas:     proto.Int32(1),
+				RangeMaxBytes:   DefaultZoneConfig().RangeMaxBytes,
+				GC:              &GCPolicy{TTLSeconds: 1},
+				NumReadReplicas: proto.Int32(-1),
+			},
+			"num_read_replicas cannot be negative",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:          proto.Int32(1),
+				RangeMaxBytes:        DefaultZoneConfig().RangeMaxBytes,
+				GC:                   &GCPolicy{TTLSeconds: 1},
+				NumReadReplicas:      proto.Int32(1),
+				ReadReplicaLagTarget: func() *time.Duration { d := time.Duration(0); return &d }(),
+			},
+			"read_replica_lag_target must be positive",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:     proto.Int32(1),
+				RangeMaxBytes:   DefaultZoneConfig().RangeMaxBytes,
+				GC:              &GCPolicy{TTLSeconds: 1},
+				NumReadReplicas: proto.Int32(1),
+				ReadReplicaConstraints: []ConstraintsConjunction{
+					{Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}}, NumReplicas: 2},
+				},
+			},
+			"the number of replicas specified in read_replica_constraints \\(2\\) cannot be greater than the number of read replicas configured for the zone \\(1\\)",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:     proto.Int32(1),
+				RangeMaxBytes:   DefaultZoneConfig().RangeMaxBytes,
+				GC:              &GCPolicy{TTLSeconds: 1},
+				NumReadReplicas: proto.Int32(2),
+				ReadReplicaConstraints: []ConstraintsConjunction{
+					{Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}}, NumReplicas: 1},
+					{Constraints: []Constraint{{Value: "b", Type: Constraint_REQUIRED}}, NumReplicas: 1},
+				},
+			},
+			"",
+		},
 	}
 
 	for i, c := range testCases {
@@ -329,6 +374,18 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 			expected:   "when voter_constraints are set, num_voters must be set as well",
 			shouldFail: true,
 		},
+		{
+			name: "read replica constraints without num_read_replicas",
+			cfg: ZoneConfig{
+				ReadReplicaConstraints: []ConstraintsConjunction{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+					},
+				},
+			},
+			expected:   "when read_replica_constraints are set, num_read_replicas must be set as well",
+			shouldFail: true,
+		},
 		{
 			name: "lease preferences without constraints",
 			cfg: ZoneConfig{
@@ -15,6 +15,7 @@ import (
 	"runtime/debug"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
@@ -209,6 +210,9 @@ type marshalableZoneConfig struct {
 	VoterConstraints             ConstraintsList   `json:"voter_constraints" yaml:"voter_constraints,flow"`
 	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
 	ExperimentalLeasePreferences []LeasePreference `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
+	NumReadReplicas              *int32            `json:"num_read_replicas,omitempty" yaml:"num_read_replicas,omitempty"`
+	ReadReplicaConstraints       *ConstraintsList  `json:"read_replica_constraints,omitempty" yaml:"read_replica_constraints,flow,omitempty"`
+	ReadReplicaLagTarget         *time.Duration    `json:"read_replica_lag_target,omitempty" yaml:"read_replica_lag_target,omitempty"`
 	Subzones                     []Subzone         `json:"subzones" yaml:"-"`
 	SubzoneSpans                 []SubzoneSpan     `json:"subzone_spans" yaml:"-"`
 }
@@ -243,6 +247,16 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	if !c.InheritedLeasePreferences {
 		m.LeasePreferences = c.LeasePreferences
 	}
+	// The read replica fields are omitted unless set, so that zones which
+	// don't use read replicas marshal as they did before they were introduced.
+	if c.NumReadReplicas != nil {
+		m.NumReadReplicas = proto.Int32(*c.NumReadReplicas)
+		m.ReadReplicaConstraints = &ConstraintsList{Constraints: c.ReadReplicaConstraints}
+	}
+	if c.ReadReplicaLagTarget != nil {
+		lagTarget := *c.ReadReplicaLagTarget
+		m.ReadReplicaLagTarget = &lagTarget
+	}
 	// We intentionally do not round-trip ExperimentalLeasePreferences. We never
 	// want to return yaml containing it.
 	m.Subzones = c.Subzones
@@ -293,6 +307,16 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.LeasePreferences != nil || m.ExperimentalLeasePreferences != nil {
 		c.InheritedLeasePreferences = false
 	}
+	if m.NumReadReplicas != nil {
+		c.NumReadReplicas = proto.Int32(*m.NumReadReplicas)
+	}
+	if m.ReadReplicaConstraints != nil {
+		c.ReadReplicaConstraints = m.ReadReplicaConstraints.Constraints
+	}
+	if m.ReadReplicaLagTarget != nil {
+		lagTarget := *m.ReadReplicaLagTarget
+		c.ReadReplicaLagTarget = &lagTarget
+	}
 	c.Subzones = m.Subzones
 	c.SubzoneSpans = m.SubzoneSpans
 	return c
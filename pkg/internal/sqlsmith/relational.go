@@ -1268,8 +1268,9 @@ func (s *Smither) makeOrderBy(refs colRefs) tree.OrderBy {
 	var ob tree.OrderBy
 	for s.coin() {
 		ref := refs[s.rnd.Intn(len(refs))]
-		// We don't support order by jsonb columns.
-		if ref.typ.Family() == types.JsonFamily {
+		// We don't support order by jsonb, tsquery or tsvector columns.
+		switch ref.typ.Family() {
+		case types.JsonFamily, types.TSQueryFamily, types.TSVectorFamily:
 			continue
 		}
 		// PostGIS cannot order box2d types.
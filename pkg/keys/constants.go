@@ -193,6 +193,10 @@ var (
 	// LocalStoreUnsafeReplicaRecoveryKeyMax is the end of keyspace used to store
 	// loss of quorum recovery record entries.
 	LocalStoreUnsafeReplicaRecoveryKeyMax = LocalStoreUnsafeReplicaRecoveryKeyMin.PrefixEnd()
+	// localStoreLossOfQuorumRecoveryStatusSuffix stores the result of the last
+	// loss of quorum recovery plan application attempted on the node. It is
+	// written by the node on startup when a staged plan is found.
+	localStoreLossOfQuorumRecoveryStatusSuffix = []byte("lqrs")
 	// localStoreNodeTombstoneSuffix stores key value pairs that map
 	// nodeIDs to time of removal from cluster.
 	localStoreNodeTombstoneSuffix = []byte("ntmb")
@@ -36,6 +36,7 @@ func TestStoreKeyEncodeDecode(t *testing.T) {
 		{key: StoreClusterVersionKey(), expSuffix: localStoreClusterVersionSuffix, expDetail: nil},
 		{key: StoreLastUpKey(), expSuffix: localStoreLastUpSuffix, expDetail: nil},
 		{key: StoreHLCUpperBoundKey(), expSuffix: localStoreHLCUpperBoundSuffix, expDetail: nil},
+		{key: StoreLossOfQuorumRecoveryStatusKey(), expSuffix: localStoreLossOfQuorumRecoveryStatusSuffix, expDetail: nil},
 	}
 	for _, test := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -811,11 +811,26 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 	errTxnID := pErr.GetTxn().ID
 	newTxn := roachpb.PrepareTransactionForRetry(ctx, pErr, tc.mu.userPriority, tc.clock)
 
+	// Transactions which take a read snapshot per statement don't need to be
+	// restarted if they weren't aborted. Instead, the statement which hit the
+	// error can be retried at a higher read timestamp, once its writes have
+	// been rolled back to a savepoint. The epoch is only bumped if the client
+	// decides to restart the transaction anyway (see ClearTxnRetryableErr).
+	partialRetry := errTxnID == newTxn.ID &&
+		tc.mu.txn.IsoLevel.PerStatementReadSnapshot() && !tc.mu.txn.CommitTimestampFixed
+	if partialRetry {
+		tc.mu.txn.Update(pErr.GetTxn())
+		tc.mu.txn.Refresh(newTxn.WriteTimestamp)
+		tc.mu.txn.UpgradePriority(newTxn.Priority)
+		newTxn = *tc.mu.txn.Clone()
+	}
+
 	// We'll pass a TransactionRetryWithProtoRefreshError up to the next layer.
 	retErr := roachpb.NewTransactionRetryWithProtoRefreshError(
 		pErr.String(),
 		errTxnID, // the id of the transaction that encountered the error
 		newTxn)
+	retErr.PartialRetry = partialRetry
 
 	// Move to a retryable error state, where all Send() calls fail until the
 	// state is cleared.
@@ -836,6 +851,11 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		return retErr
 	}
 
+	if partialRetry {
+		log.VEventf(ctx, 2, "preparing to retry the current statement at %s", newTxn.ReadTimestamp)
+		return retErr
+	}
+
 	// This is where we get a new epoch.
 	tc.mu.txn.Update(&newTxn)
 
@@ -997,6 +1017,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsoLevel(isoLevel enginepb.IsolationLevel) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && isoLevel != tc.mu.txn.IsoLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsoLevel() enginepb.IsolationLevel {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsoLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -1111,6 +1149,10 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		// The transaction can commit above its read timestamp.
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1339,6 +1381,37 @@ func (tc *TxnCoordSender) ManualRefresh(ctx context.Context) error {
 	return pErr.GoError()
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (tc *TxnCoordSender) StepReadTimestamp(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if !tc.mu.txn.IsoLevel.PerStatementReadSnapshot() {
+		return errors.AssertionFailedf(
+			"cannot step the read timestamp of a %s transaction", tc.mu.txn.IsoLevel)
+	}
+	if tc.mu.txnState != txnPending {
+		return errors.AssertionFailedf(
+			"cannot step the read timestamp of a transaction which is not pending: %s", tc.mu.txn)
+	}
+	if tc.mu.txn.CommitTimestampFixed {
+		// The transaction reads at a fixed timestamp, like AS OF SYSTEM TIME
+		// transactions do.
+		return nil
+	}
+
+	// Move the read timestamp, and the provisional commit timestamp with it, to
+	// the present time. The uncertainty interval of the transaction moves with
+	// it, so observed timestamps, which were taken before the new read
+	// timestamp, can no longer be used to limit it.
+	now := tc.clock.Now()
+	tc.mu.txn.Refresh(now)
+	tc.mu.txn.GlobalUncertaintyLimit.Forward(now.Add(tc.clock.MaxOffset().Nanoseconds(), 0))
+	tc.mu.txn.ObservedTimestamps = nil
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked(tc.mu.txn.ReadTimestamp)
+	return nil
+}
+
 // DeferCommitWait is part of the TxnSender interface.
 func (tc *TxnCoordSender) DeferCommitWait(ctx context.Context) func(context.Context) error {
 	tc.mu.Lock()
@@ -1372,11 +1445,43 @@ func (tc *TxnCoordSender) ClearTxnRetryableErr(ctx context.Context) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 	if tc.mu.txnState == txnRetryableError {
+		if tc.mu.storedRetryableErr.PartialRetry {
+			// The error allowed for a partial retry, but the client is restarting
+			// the transaction instead, so we get a new epoch now. The timestamps
+			// of the transaction have already been forwarded for the retry.
+			log.VEventf(ctx, 2, "resetting epoch-based coordinator state on retry")
+			tc.mu.txn.Restart(tc.mu.userPriority, 0 /* upgradePriority */, tc.mu.txn.WriteTimestamp)
+			for _, reqInt := range tc.interceptorStack {
+				reqInt.epochBumpedLocked()
+			}
+		}
 		tc.mu.storedRetryableErr = nil
 		tc.mu.txnState = txnPending
 	}
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (tc *TxnCoordSender) PrepareForPartialRetry(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.txnState != txnRetryableError {
+		return errors.AssertionFailedf(
+			"cannot prepare for partial retry of a transaction without a retryable error: %s", tc.mu.txn)
+	}
+	if !tc.mu.storedRetryableErr.PartialRetry {
+		return errors.AssertionFailedf(
+			"cannot prepare for partial retry after error: %s", tc.mu.storedRetryableErr)
+	}
+	log.VEventf(ctx, 2, "retrying the current statement at %s", tc.mu.txn.ReadTimestamp)
+	// The reads of the statement, which were performed at the previous read
+	// timestamp, are discarded when rolling back to the savepoint taken at the
+	// start of the statement.
+	tc.interceptorAlloc.txnSpanRefresher.refreshedTimestamp.Forward(tc.mu.txn.ReadTimestamp)
+	tc.mu.storedRetryableErr = nil
+	tc.mu.txnState = txnPending
+	return nil
+}
+
 // HasPerformedReads is part of the TxnSender interface.
 func (tc *TxnCoordSender) HasPerformedReads() bool {
 	tc.mu.Lock()
@@ -3000,3 +3000,90 @@ func TestTxnCoordSenderSetFixedTimestamp(t *testing.T) {
 		})
 	}
 }
+
+// TestTxnCoordSenderReadCommittedWriteSkew verifies that READ COMMITTED
+// transactions can commit after their timestamp was pushed above writes to
+// keys they read, while SERIALIZABLE transactions fail to refresh their reads.
+func TestTxnCoordSenderReadCommittedWriteSkew(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	testutils.RunTrueAndFalse(t, "read-committed", func(t *testing.T, readCommitted bool) {
+		s := createTestDB(t)
+		defer s.Stop()
+
+		txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+		if readCommitted {
+			require.NoError(t, txn.SetIsoLevel(enginepb.READ_COMMITTED))
+		}
+		require.NoError(t, txn.Put(ctx, "a", "txn"))
+		_, err := txn.Get(ctx, "b")
+		require.NoError(t, err)
+
+		// Write the key read by the transaction, then push the transaction above
+		// that write with a high-priority read of the key it wrote.
+		require.NoError(t, s.DB.Put(ctx, "b", "other"))
+		require.NoError(t, s.DB.Txn(ctx, func(ctx context.Context, pusher *kv.Txn) error {
+			if err := pusher.SetUserPriority(roachpb.MaxUserPriority); err != nil {
+				return err
+			}
+			_, err := pusher.Get(ctx, "a")
+			return err
+		}))
+
+		err = txn.Commit(ctx)
+		if readCommitted {
+			require.NoError(t, err)
+		} else {
+			require.True(t, errors.HasType(err, (*roachpb.TransactionRetryWithProtoRefreshError)(nil)), "%+v", err)
+		}
+	})
+}
+
+// TestTxnCoordSenderReadCommittedPartialRetry verifies that a write-write
+// conflict of a READ COMMITTED transaction can be retried by rolling back to a
+// savepoint, without restarting the transaction.
+func TestTxnCoordSenderReadCommittedPartialRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+	require.NoError(t, txn.SetIsoLevel(enginepb.READ_COMMITTED))
+	require.NoError(t, txn.Put(ctx, "a", "txn"))
+
+	// The next statement reads a key which is written concurrently before the
+	// statement writes it.
+	require.NoError(t, txn.StepReadTimestamp(ctx))
+	sp, err := txn.CreateSavepoint(ctx)
+	require.NoError(t, err)
+	_, err = txn.Get(ctx, "b")
+	require.NoError(t, err)
+	require.NoError(t, s.DB.Put(ctx, "b", "other"))
+	err = txn.Put(ctx, "b", "txn")
+	var retryErr *roachpb.TransactionRetryWithProtoRefreshError
+	require.True(t, errors.As(err, &retryErr), "%+v", err)
+	require.True(t, retryErr.PartialRetry)
+
+	// Retry the statement with a new read snapshot.
+	require.NoError(t, txn.PrepareForPartialRetry(ctx))
+	require.NoError(t, txn.RollbackToSavepoint(ctx, sp))
+	require.NoError(t, txn.StepReadTimestamp(ctx))
+	res, err := txn.Get(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("other"), res.ValueBytes())
+	require.NoError(t, txn.Put(ctx, "b", "txn"))
+	require.NoError(t, txn.Commit(ctx))
+
+	// The transaction wasn't restarted, so the write of the first statement
+	// was kept.
+	require.Equal(t, enginepb.TxnEpoch(0), txn.Epoch())
+	for _, k := range []string{"a", "b"} {
+		res, err := s.DB.Get(ctx, k)
+		require.NoError(t, err)
+		require.Equal(t, []byte("txn"), res.ValueBytes())
+	}
+}
@@ -433,9 +433,11 @@ func (sr *txnSpanRefresher) maybeRefreshPreemptivelyLocked(
 	// If true, tryRefreshTxnSpans will trivially succeed.
 	refreshFree := ba.CanForwardReadTimestamp
 
-	// If true, this batch is guaranteed to fail without a refresh.
+	// If true, this batch is guaranteed to fail without a refresh. Transactions
+	// which tolerate write skew can commit above their read timestamp.
 	args, hasET := ba.GetArg(roachpb.EndTxn)
-	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit
+	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit &&
+		!ba.Txn.IsoLevel.ToleratesWriteSkew()
 
 	// If neither condition is true, defer the refresh.
 	if !refreshFree && !refreshInevitable && !force {
@@ -703,6 +705,15 @@ func (sr *txnSpanRefresher) epochBumpedLocked() {
 	sr.refreshedTimestamp.Reset()
 }
 
+// readTimestampSteppedLocked is called when the transaction takes a new read
+// snapshot, at the given timestamp. The reads performed before then no longer
+// need to be refreshed if the transaction is pushed.
+func (sr *txnSpanRefresher) readTimestampSteppedLocked(readTimestamp hlc.Timestamp) {
+	sr.refreshFootprint.clear()
+	sr.refreshInvalid = false
+	sr.refreshedTimestamp.Forward(readTimestamp)
+}
+
 // createSavepointLocked is part of the txnInterceptor interface.
 func (sr *txnSpanRefresher) createSavepointLocked(ctx context.Context, s *savepoint) {
 	s.refreshSpans = make([]roachpb.Span, len(sr.refreshFootprint.asSlice()))
@@ -984,6 +984,57 @@ func (a *Allocator) AllocateNonVoter(
 	return a.allocateTarget(ctx, conf, existingVoters, existingNonVoters, replicaStatus, NonVoterTarget)
 }
 
+// analyzedReplicaConstraints holds the analyses of the constraints of a range
+// as per its existing replicas.
+type analyzedReplicaConstraints struct {
+	overall, voter, readReplica constraint.AnalyzedConstraints
+	// readReplicaStores are the stores of the existing non-voters which are the
+	// dedicated read replicas of the range.
+	readReplicaStores map[roachpb.StoreID]struct{}
+	// numOtherNonVoters is the number of existing non-voters which aren't
+	// dedicated read replicas.
+	numOtherNonVoters int
+}
+
+// needsReadReplica returns whether the next non-voter added to the range
+// should be a dedicated read replica. The non-voters making up `num_replicas`
+// are added before the read replicas.
+func (ac analyzedReplicaConstraints) needsReadReplica(conf roachpb.SpanConfig) bool {
+	return ac.numOtherNonVoters >= int(conf.NumReplicas-conf.GetNumVoters()) &&
+		len(ac.readReplicaStores) < int(conf.NumReadReplicas)
+}
+
+// analyzeReplicaConstraints analyzes the `constraints`, `voter_constraints` and
+// `read_replica_constraints` of a range as per its existing replicas. The
+// dedicated read replicas abide only by the `read_replica_constraints`, so they
+// are left out of the analysis of the overall `constraints`.
+func (a *Allocator) analyzeReplicaConstraints(
+	ctx context.Context,
+	conf roachpb.SpanConfig,
+	existingVoters, existingNonVoters []roachpb.ReplicaDescriptor,
+) analyzedReplicaConstraints {
+	readReplicas, otherNonVoters := constraint.ReadReplicas(a.StorePool.GetStoreDescriptor,
+		existingNonVoters, conf.NumReadReplicas, conf.ReadReplicaConstraints)
+	overallReplicas := make([]roachpb.ReplicaDescriptor, 0, len(existingVoters)+len(otherNonVoters))
+	overallReplicas = append(overallReplicas, existingVoters...)
+	overallReplicas = append(overallReplicas, otherNonVoters...)
+
+	ac := analyzedReplicaConstraints{
+		overall: constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
+			overallReplicas, conf.NumReplicas, conf.Constraints),
+		voter: constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
+			existingVoters, conf.GetNumVoters(), conf.VoterConstraints),
+		readReplica: constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
+			readReplicas, conf.NumReadReplicas, conf.ReadReplicaConstraints),
+		readReplicaStores: make(map[roachpb.StoreID]struct{}, len(readReplicas)),
+		numOtherNonVoters: len(otherNonVoters),
+	}
+	for _, repl := range readReplicas {
+		ac.readReplicaStores[repl.StoreID] = struct{}{}
+	}
+	return ac
+}
+
 // AllocateTargetFromList returns a suitable store for a new allocation of a
 // replica of the given type from the set of candidate stores, with the given
 // existing set of voters and non-voters..
@@ -998,20 +1049,21 @@ func (a *Allocator) AllocateTargetFromList(
 	targetType TargetReplicaType,
 ) (roachpb.ReplicationTarget, string) {
 	existingReplicas := append(existingVoters, existingNonVoters...)
-	analyzedOverallConstraints := constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
-		existingReplicas, conf.NumReplicas, conf.Constraints)
-	analyzedVoterConstraints := constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
-		existingVoters, conf.GetNumVoters(), conf.VoterConstraints)
+	analyzed := a.analyzeReplicaConstraints(ctx, conf, existingVoters, existingNonVoters)
 
 	var constraintsChecker constraintsCheckFn
 	switch t := targetType; t {
 	case VoterTarget:
 		constraintsChecker = voterConstraintsCheckerForAllocation(
-			analyzedOverallConstraints,
-			analyzedVoterConstraints,
+			analyzed.overall,
+			analyzed.voter,
 		)
 	case NonVoterTarget:
-		constraintsChecker = nonVoterConstraintsCheckerForAllocation(analyzedOverallConstraints)
+		if analyzed.needsReadReplica(conf) {
+			constraintsChecker = readReplicaConstraintsCheckerForAllocation(analyzed.readReplica)
+		} else {
+			constraintsChecker = nonVoterConstraintsCheckerForAllocation(analyzed.overall)
+		}
 	default:
 		log.KvDistribution.Fatalf(ctx, "unsupported targetReplicaType: %v", t)
 	}
@@ -1138,10 +1190,7 @@ func (a Allocator) RemoveTarget(
 	}
 
 	existingReplicas := append(existingVoters, existingNonVoters...)
-	analyzedOverallConstraints := constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
-		existingReplicas, conf.NumReplicas, conf.Constraints)
-	analyzedVoterConstraints := constraint.AnalyzeConstraints(ctx, a.StorePool.GetStoreDescriptor,
-		existingVoters, conf.GetNumVoters(), conf.VoterConstraints)
+	analyzed := a.analyzeReplicaConstraints(ctx, conf, existingVoters, existingNonVoters)
 
 	var constraintsChecker constraintsCheckFn
 	switch t := targetType; t {
@@ -1150,11 +1199,15 @@ func (a Allocator) RemoveTarget(
 		// apply to all replicas) and `voter_constraints` which apply only to voting
 		// replicas.
 		constraintsChecker = voterConstraintsCheckerForRemoval(
-			analyzedOverallConstraints,
-			analyzedVoterConstraints,
+			analyzed.overall,
+			analyzed.voter,
 		)
 	case NonVoterTarget:
-		constraintsChecker = nonVoterConstraintsCheckerForRemoval(analyzedOverallConstraints)
+		constraintsChecker = nonVoterConstraintsCheckerForRemoval(
+			analyzed.overall,
+			analyzed.readReplica,
+			analyzed.readReplicaStores,
+		)
 	default:
 		log.KvDistribution.Fatalf(ctx, "unsupported targetReplicaType: %v", t)
 	}
@@ -1272,10 +1325,7 @@ func (a Allocator) RebalanceTarget(
 	existingReplicas := append(existingVoters, existingNonVoters...)
 
 	zero := roachpb.ReplicationTarget{}
-	analyzedOverallConstraints := constraint.AnalyzeConstraints(
-		ctx, a.StorePool.GetStoreDescriptor, existingReplicas, conf.NumReplicas, conf.Constraints)
-	analyzedVoterConstraints := constraint.AnalyzeConstraints(
-		ctx, a.StorePool.GetStoreDescriptor, existingVoters, conf.GetNumVoters(), conf.VoterConstraints)
+	analyzed := a.analyzeReplicaConstraints(ctx, conf, existingVoters, existingNonVoters)
 	var removalConstraintsChecker constraintsCheckFn
 	var rebalanceConstraintsChecker rebalanceConstraintsCheckFn
 	var replicaSetToRebalance, replicasWithExcludedStores []roachpb.ReplicaDescriptor
@@ -1284,18 +1334,26 @@ func (a Allocator) RebalanceTarget(
 	switch t := targetType; t {
 	case VoterTarget:
 		removalConstraintsChecker = voterConstraintsCheckerForRemoval(
-			analyzedOverallConstraints,
-			analyzedVoterConstraints,
+			analyzed.overall,
+			analyzed.voter,
 		)
 		rebalanceConstraintsChecker = voterConstraintsCheckerForRebalance(
-			analyzedOverallConstraints,
-			analyzedVoterConstraints,
+			analyzed.overall,
+			analyzed.voter,
 		)
 		replicaSetToRebalance = existingVoters
 		otherReplicaSet = existingNonVoters
 	case NonVoterTarget:
-		removalConstraintsChecker = nonVoterConstraintsCheckerForRemoval(analyzedOverallConstraints)
-		rebalanceConstraintsChecker = nonVoterConstraintsCheckerForRebalance(analyzedOverallConstraints)
+		removalConstraintsChecker = nonVoterConstraintsCheckerForRemoval(
+			analyzed.overall,
+			analyzed.readReplica,
+			analyzed.readReplicaStores,
+		)
+		rebalanceConstraintsChecker = nonVoterConstraintsCheckerForRebalance(
+			analyzed.overall,
+			analyzed.readReplica,
+			analyzed.readReplicaStores,
+		)
 		replicaSetToRebalance = existingNonVoters
 		// When rebalancing non-voting replicas, we don't consider stores that
 		// already have voting replicas as possible candidates. Voting replicas are
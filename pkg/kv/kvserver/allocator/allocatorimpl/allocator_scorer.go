@@ -1718,6 +1718,20 @@ func nonVoterConstraintsCheckerForAllocation(
 	}
 }
 
+// readReplicaConstraintsCheckerForAllocation returns a constraintsCheckFn that
+// determines whether a candidate for a new dedicated read replica is valid
+// and/or necessary as per the `read_replica_constraints` on the range.
+//
+// NB: Read replicas are exempt from the overall `constraints`, which are
+// entirely disregarded here.
+func readReplicaConstraintsCheckerForAllocation(
+	readReplicaConstraints constraint.AnalyzedConstraints,
+) constraintsCheckFn {
+	return func(s roachpb.StoreDescriptor) (valid, necessary bool) {
+		return allocateConstraintsCheck(s, readReplicaConstraints)
+	}
+}
+
 // voterConstraintsCheckerForRemoval returns a constraintsCheckFn that
 // determines whether an existing voting replica is valid and/or necessary with
 // respect to the `constraints` and `voter_constraints` on the range.
@@ -1739,15 +1753,20 @@ func voterConstraintsCheckerForRemoval(
 
 // nonVoterConstraintsCheckerForRemoval returns a constraintsCheckFn that
 // determines whether an existing non-voting replica is valid and/or necessary
-// with respect to the `constraints` on the range.
+// with respect to the `constraints` on the range, or the
+// `read_replica_constraints` if it is a dedicated read replica.
 //
 // NB: Candidates are marked invalid if their removal would result in a
 // violation of `constraints` on the range. They are marked necessary if
 // constraints conformance (for `constraints`) is not possible without them.
 func nonVoterConstraintsCheckerForRemoval(
-	overallConstraints constraint.AnalyzedConstraints,
+	overallConstraints, readReplicaConstraints constraint.AnalyzedConstraints,
+	readReplicaStores map[roachpb.StoreID]struct{},
 ) constraintsCheckFn {
 	return func(s roachpb.StoreDescriptor) (valid, necessary bool) {
+		if _, ok := readReplicaStores[s.StoreID]; ok {
+			return removeConstraintsCheck(s, readReplicaConstraints)
+		}
 		return removeConstraintsCheck(s, overallConstraints)
 	}
 }
@@ -1768,11 +1787,17 @@ func voterConstraintsCheckerForRebalance(
 
 // nonVoterConstraintsCheckerForRebalance returns a rebalanceConstraintsCheckFn
 // that determines whether a given store is a valid and/or necessary rebalance
-// candidate from a given store of an existing non-voting replica.
+// candidate from a given store of an existing non-voting replica. Dedicated
+// read replicas are rebalanced as per the `read_replica_constraints` on the
+// range rather than its `constraints`.
 func nonVoterConstraintsCheckerForRebalance(
-	overallConstraints constraint.AnalyzedConstraints,
+	overallConstraints, readReplicaConstraints constraint.AnalyzedConstraints,
+	readReplicaStores map[roachpb.StoreID]struct{},
 ) rebalanceConstraintsCheckFn {
 	return func(toStore, fromStore roachpb.StoreDescriptor) (valid, necessary bool) {
+		if _, ok := readReplicaStores[fromStore.StoreID]; ok {
+			return rebalanceFromConstraintsCheck(toStore, fromStore, readReplicaConstraints)
+		}
 		return rebalanceFromConstraintsCheck(toStore, fromStore, overallConstraints)
 	}
 }
@@ -3531,6 +3531,68 @@ func TestAllocatorNonVoterAllocationExcludesVoterNodes(t *testing.T) {
 	}
 }
 
+// TestAllocatorReadReplicaAllocation checks that dedicated read replicas are
+// allocated as per the `read_replica_constraints` of a range, and regardless of
+// its overall `constraints`.
+func TestAllocatorReadReplicaAllocation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	constrainTo := func(value string) []roachpb.ConstraintsConjunction {
+		return []roachpb.ConstraintsConjunction{
+			{Constraints: []roachpb.Constraint{{Value: value, Type: roachpb.Constraint_REQUIRED}}},
+		}
+	}
+	testCases := []struct {
+		name       string
+		conf       roachpb.SpanConfig
+		expected   roachpb.StoreID
+		shouldFail bool
+		expError   string
+	}{
+		{
+			name: "read replica exempt from constraints",
+			conf: roachpb.SpanConfig{
+				NumReplicas:            1,
+				Constraints:            constrainTo("b"),
+				NumReadReplicas:        1,
+				ReadReplicaConstraints: constrainTo("a"),
+			},
+			expected: roachpb.StoreID(1),
+		},
+		{
+			name: "read replica constraints unsatisfiable",
+			conf: roachpb.SpanConfig{
+				NumReplicas:            1,
+				NumReadReplicas:        1,
+				ReadReplicaConstraints: constrainTo("b"),
+			},
+			shouldFail: true,
+			expError:   "0 of 2 live stores are able to take a new replica for the range",
+		},
+	}
+
+	for i, test := range testCases {
+		t.Run(fmt.Sprintf("%d:%s", i+1, test.name), func(t *testing.T) {
+			ctx := context.Background()
+			stopper, g, _, a, _ := CreateTestAllocator(ctx, 10, false /* deterministic */)
+			defer stopper.Stop(ctx)
+			sg := gossiputil.NewStoreGossiper(g)
+			sg.GossipStores(multiDCStores, t)
+
+			// The voter is placed on the store in "b".
+			result, _, err := a.AllocateNonVoter(ctx, test.conf, replicas(2), nil /* existingNonVoters */, Dead)
+			if test.shouldFail {
+				require.Error(t, err)
+				require.Regexp(t, test.expError, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, result.StoreID)
+			}
+		})
+	}
+}
+
 func TestAllocateCandidatesNumReplicasConstraints(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
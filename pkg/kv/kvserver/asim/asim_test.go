@@ -12,6 +12,7 @@ package asim_test
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math"
 	"math/rand"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/config"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/state"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/workload"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/stretchr/testify/require"
@@ -45,6 +47,209 @@ func TestRunAllocatorSimulator(t *testing.T) {
 	sim.RunSim(ctx)
 }
 
+// TestRunAllocatorSimulatorTrace replays a trace in which the load moves
+// between ranges, against a cluster whose replicas and leases are initially
+// evenly spread. The load is uniform at first, then concentrates on the ranges
+// whose leases are held by one store, and then moves to the ranges whose
+// leases are held by another store. The store rebalancer is expected to
+// spread the leases of the hot ranges in each phase, and the whole trace is
+// expected to be replayed.
+func TestRunAllocatorSimulatorTrace(t *testing.T) {
+	ctx := context.Background()
+	settings := config.DefaultSimulationSettings()
+	start := state.TestingStartTime()
+	end := start.Add(1000 * time.Second)
+	interval := 10 * time.Second
+	const keysPerRange = 1000
+	const numRanges = 100
+	const coldQPS, hotQPS = 10.0, 250.0
+
+	trace := workload.Trace{}
+	for i := 0; i < numRanges; i++ {
+		trace.Ranges = append(trace.Ranges, workload.TraceRange{RangeID: int64(i + 1)})
+	}
+	var startKeys []state.Key
+	for _, key := range trace.StartKeys(keysPerRange) {
+		startKeys = append(startKeys, state.Key(key))
+	}
+	s := state.LoadConfigWithRanges(state.SingleRegionConfig, startKeys, 3)
+
+	// Find the trace ranges whose leases are initially held by the first two
+	// stores, which are made hot in turn.
+	leaseholder := func(i int) state.StoreID {
+		store, ok := s.LeaseholderStore(s.RangeFor(startKeys[i]).RangeID())
+		require.True(t, ok)
+		return store.StoreID()
+	}
+	hot := make([][]int, 2)
+	for i := 0; i < numRanges; i++ {
+		if storeID := leaseholder(i); storeID <= state.StoreID(len(hot)) {
+			hot[storeID-1] = append(hot[storeID-1], i)
+		}
+	}
+	for _, ranges := range hot {
+		require.Greater(t, len(ranges), 1)
+	}
+
+	makeSample := func(offset time.Duration, hotRanges []int) workload.TraceSample {
+		qps := make([]float64, numRanges)
+		for i := range qps {
+			qps[i] = coldQPS
+		}
+		for _, i := range hotRanges {
+			qps[i] = hotQPS
+		}
+		sample := workload.TraceSample{Offset: offset}
+		for i, q := range qps {
+			sample.Load = append(sample.Load, workload.TraceRangeLoad{
+				Range:               i,
+				ReadsPerSecond:      q * 0.9,
+				WritesPerSecond:     q * 0.1,
+				ReadBytesPerSecond:  q * 0.9 * 256,
+				WriteBytesPerSecond: q * 0.1 * 256,
+			})
+		}
+		return sample
+	}
+	trace.Samples = []workload.TraceSample{
+		makeSample(0, nil),
+		makeSample(300*time.Second, hot[0]),
+		makeSample(650*time.Second, hot[1]),
+	}
+	require.NoError(t, trace.Validate())
+
+	rwg := []workload.Generator{
+		workload.NewTraceGenerator(start, state.TestingWorkloadSeed(), trace, keysPerRange),
+	}
+	m := asim.NewMetricsTracker(os.Stdout)
+	exchange := state.NewFixedDelayExhange(start, settings.StateExchangeInterval, settings.StateExchangeDelay)
+	changer := state.NewReplicaChanger()
+	testPreGossipStores(s, exchange, start)
+
+	sim := asim.NewSimulator(start, end, interval, interval, rwg, s, exchange, changer, settings, m)
+	sim.RunSim(ctx)
+
+	// The reads of the whole trace were served, up to the fractional reads
+	// which are carried over after the last tick.
+	var expectedReads float64
+	for i, sample := range trace.Samples {
+		sampleEnd := end
+		if i+1 < len(trace.Samples) {
+			sampleEnd = start.Add(trace.Samples[i+1].Offset)
+		}
+		elapsed := sampleEnd.Sub(start.Add(sample.Offset)).Seconds()
+		for _, l := range sample.Load {
+			expectedReads += l.ReadsPerSecond * elapsed
+		}
+	}
+	usage := s.ClusterUsageInfo()
+	var reads int64
+	for _, u := range usage.StoreUsage {
+		reads += u.ReadKeys
+	}
+	require.InDelta(t, expectedReads, float64(reads), numRanges)
+
+	// The leases of the ranges which were hot in each phase are no longer all
+	// held by the store which initially held them.
+	require.Greater(t, usage.LeaseTransfers, int64(0))
+	for storeIdx, ranges := range hot {
+		var moved int
+		for _, i := range ranges {
+			if leaseholder(i) != state.StoreID(storeIdx+1) {
+				moved++
+			}
+		}
+		require.Greater(t, moved, 0, "no lease of the hot ranges of s%d was transferred", storeIdx+1)
+	}
+}
+
+var (
+	replayTrace = flag.String("trace", "",
+		"path of a trace recorded with `cockroach debug asim-trace`, which "+
+			"TestReplayAllocatorSimulatorTrace replays instead of testdata/trace.json")
+	replayTraceConfig = flag.String("trace-config", "single_region",
+		"cluster configuration against which the trace is replayed, one of "+
+			"single_region, multi_region or complex")
+)
+
+// TestReplayAllocatorSimulatorTrace replays a trace recorded from a real
+// cluster against a simulated cluster, and prints the metrics of the
+// simulation. The trace and the configuration of the simulated cluster can be
+// chosen with the -trace and -trace-config flags, e.g.
+//
+//	./dev test pkg/kv/kvserver/asim -f TestReplayAllocatorSimulatorTrace \
+//	  --test-args='-trace=/tmp/trace.json -trace-config=complex' -v
+//
+// The simulation runs until the end of the last sample of the trace, which
+// lasts as long as the interval between the last two samples, and the reads of
+// the whole trace are expected to have been served.
+func TestReplayAllocatorSimulatorTrace(t *testing.T) {
+	ctx := context.Background()
+	path := *replayTrace
+	if path == "" {
+		path = testutils.TestDataPath(t, "trace.json")
+	}
+	configs := map[string]state.ClusterInfo{
+		"single_region": state.SingleRegionConfig,
+		"multi_region":  state.MultiRegionConfig,
+		"complex":       state.ComplexConfig,
+	}
+	clusterInfo, ok := configs[*replayTraceConfig]
+	require.True(t, ok, "unknown cluster configuration %q", *replayTraceConfig)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	trace, err := workload.ReadTrace(f)
+	require.NoError(t, err)
+	require.NotEmpty(t, trace.Samples, "the trace has no samples")
+
+	const keysPerRange = 1000
+	var startKeys []state.Key
+	for _, key := range trace.StartKeys(keysPerRange) {
+		startKeys = append(startKeys, state.Key(key))
+	}
+	s := state.LoadConfigWithRanges(clusterInfo, startKeys, 3 /* replicationFactor */)
+
+	settings := config.DefaultSimulationSettings()
+	start := state.TestingStartTime()
+	interval := 10 * time.Second
+	lastSample := trace.Samples[len(trace.Samples)-1].Offset
+	lastSampleDuration := interval
+	if n := len(trace.Samples); n > 1 {
+		lastSampleDuration = lastSample - trace.Samples[n-2].Offset
+	}
+	end := start.Add(lastSample + lastSampleDuration)
+
+	rwg := []workload.Generator{
+		workload.NewTraceGenerator(start, state.TestingWorkloadSeed(), trace, keysPerRange),
+	}
+	m := asim.NewMetricsTracker(os.Stdout)
+	exchange := state.NewFixedDelayExhange(start, settings.StateExchangeInterval, settings.StateExchangeDelay)
+	changer := state.NewReplicaChanger()
+	testPreGossipStores(s, exchange, start)
+
+	sim := asim.NewSimulator(start, end, interval, interval, rwg, s, exchange, changer, settings, m)
+	sim.RunSim(ctx)
+
+	var expectedReads float64
+	for i, sample := range trace.Samples {
+		sampleEnd := end
+		if i+1 < len(trace.Samples) {
+			sampleEnd = start.Add(trace.Samples[i+1].Offset)
+		}
+		elapsed := sampleEnd.Sub(start.Add(sample.Offset)).Seconds()
+		for _, l := range sample.Load {
+			expectedReads += l.ReadsPerSecond * elapsed
+		}
+	}
+	var reads int64
+	for _, u := range s.ClusterUsageInfo().StoreUsage {
+		reads += u.ReadKeys
+	}
+	require.InDelta(t, expectedReads, float64(reads), float64(len(trace.Ranges)))
+}
+
 // testCreateWorkloadGenerator creates a simple uniform workload generator that
 // will generate load events at a rate of 500 per store. The read ratio is
 // fixed to 0.95.
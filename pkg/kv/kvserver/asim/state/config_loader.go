@@ -10,7 +10,12 @@
 
 package state
 
-import "github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/config"
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/asim/config"
+	"github.com/google/btree"
+)
 
 // SingleRegionConfig is a simple cluster config with a single region and 3
 // zones, all have the same number of nodes.
@@ -136,3 +141,57 @@ func LoadConfig(c ClusterInfo) State {
 	}
 	return s
 }
+
+// LoadConfigWithRanges loads a predefined configuration which contains cluster
+// information such as regions, zones, etc. The keyspace is split into ranges
+// at the given start keys, whose replicas and leases are spread evenly over
+// the stores of the cluster.
+func LoadConfigWithRanges(c ClusterInfo, startKeys []Key, replicationFactor int) State {
+	s := newState(config.DefaultSimulationSettings())
+	s.clusterinfo = c
+	for _, r := range c.Regions {
+		for _, z := range r.Zones {
+			for i := 0; i < z.NodeCount; i++ {
+				node := s.AddNode()
+				s.AddStore(node.NodeID())
+			}
+		}
+	}
+	storeIDs := make([]StoreID, 0, len(s.stores))
+	for storeID := range s.stores {
+		storeIDs = append(storeIDs, storeID)
+	}
+	sort.Slice(storeIDs, func(i, j int) bool { return storeIDs[i] < storeIDs[j] })
+	if replicationFactor > len(storeIDs) {
+		replicationFactor = len(storeIDs)
+	}
+
+	for _, key := range startKeys {
+		s.SplitRange(key)
+	}
+
+	// Iterate over the ranges in key order, so that the placement is
+	// deterministic. The replicas are assigned to the stores in turn, and the
+	// lease of each range is given to the store of its replicas which holds
+	// the fewest leases.
+	next := 0
+	leaseCounts := make(map[StoreID]int)
+	s.ranges.rangeTree.Ascend(func(item btree.Item) bool {
+		rng := item.(*rng)
+		var leaseholder StoreID
+		for j := 0; j < replicationFactor; j++ {
+			storeID := storeIDs[next%len(storeIDs)]
+			next++
+			s.addReplica(rng.rangeID, storeID)
+			if j == 0 || leaseCounts[storeID] < leaseCounts[leaseholder] {
+				leaseholder = storeID
+			}
+		}
+		if replicationFactor > 0 {
+			s.setLeaseHolder(rng.rangeID, leaseholder)
+			leaseCounts[leaseholder]++
+		}
+		return true
+	})
+	return s
+}
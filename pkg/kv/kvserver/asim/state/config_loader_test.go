@@ -40,3 +40,43 @@ func TestLoadConfig(t *testing.T) {
 		require.Equal(t, tc.expectedNodeCount, len(state.Nodes()))
 	}
 }
+
+func TestLoadConfigWithRanges(t *testing.T) {
+	const numRanges, replicationFactor = 40, 3
+	startKeys := make([]Key, numRanges)
+	for i := range startKeys {
+		startKeys[i] = Key(i * 10)
+	}
+
+	state := LoadConfigWithRanges(SingleRegionConfig, startKeys, replicationFactor)
+	require.Equal(t, 15, len(state.Stores()))
+	// The first range, which precedes the first start key, is also counted.
+	require.Equal(t, int64(numRanges+1), state.RangeCount())
+
+	replicaCounts := make(map[StoreID]int)
+	leaseCounts := make(map[StoreID]int)
+	for _, rng := range state.Ranges() {
+		require.Len(t, rng.Replicas(), replicationFactor)
+		for storeID, repl := range rng.Replicas() {
+			replicaCounts[storeID]++
+			if repl.HoldsLease() {
+				leaseCounts[storeID]++
+			}
+		}
+	}
+
+	// The replicas and leases are spread evenly over the stores.
+	spread := func(counts map[StoreID]int) int {
+		min, max := counts[1], counts[1]
+		for storeID := range state.Stores() {
+			if c := counts[storeID]; c < min {
+				min = c
+			} else if c > max {
+				max = c
+			}
+		}
+		return max - min
+	}
+	require.LessOrEqual(t, spread(replicaCounts), 1)
+	require.LessOrEqual(t, spread(leaseCounts), 1)
+}
@@ -151,6 +151,7 @@ type StoreUsageInfo struct {
 	WriteBytes int64
 	ReadKeys   int64
 	ReadBytes  int64
+	RequestCPU int64
 }
 
 // ClusterUsageInfo contains the load and state of the cluster. Using this we
@@ -178,8 +179,8 @@ func (u *ClusterUsageInfo) ApplyLoad(r *rng, le workload.LoadEvent) {
 			s = &StoreUsageInfo{}
 			u.StoreUsage[rep.storeID] = s
 		}
-		// Writes are added to all replicas, reads are added to the leaseholder
-		// only.
+		// Writes are added to all replicas, reads and the CPU spent evaluating
+		// requests are added to the leaseholder only.
 		// Note that the accounting here is different from ReplicaLoadCounter above:
 		// here we try to track the actual load on the store, regardless of the
 		// allocator implementation details, and ReplicaLoadCounter tries to follow
@@ -190,6 +191,7 @@ func (u *ClusterUsageInfo) ApplyLoad(r *rng, le workload.LoadEvent) {
 		if rep.holdsLease {
 			s.ReadBytes += le.ReadSize
 			s.ReadKeys += le.Reads
+			s.RequestCPU += le.RequestCPU
 		}
 	}
 }
@@ -0,0 +1,215 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package workload
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Trace is a recording of the per-range load of a real cluster over time,
+// which may be replayed in the simulator by a TraceGenerator. The ranges of
+// the recorded cluster are mapped onto the simulated keyspace in key order,
+// each spanning the same number of keys.
+type Trace struct {
+	// Ranges are the recorded ranges, in key order.
+	Ranges []TraceRange `json:"ranges"`
+	// Samples are the recorded load on the ranges, in ascending order of
+	// their offset. The load of a sample applies from its offset until the
+	// offset of the next sample; the load of the last sample applies
+	// indefinitely.
+	Samples []TraceSample `json:"samples"`
+}
+
+// TraceRange describes a range of the recorded cluster.
+type TraceRange struct {
+	// RangeID is the ID of the range in the recorded cluster.
+	RangeID int64 `json:"range_id"`
+	// StartKey is the pretty printed start key of the range, which is only
+	// recorded for reference.
+	StartKey string `json:"start_key"`
+}
+
+// TraceSample is the load on the recorded ranges at some point in time.
+type TraceSample struct {
+	// Offset is the time of the sample, relative to the start of the trace.
+	Offset time.Duration `json:"offset"`
+	// Load is the load on each range which had any at the time of the sample.
+	Load []TraceRangeLoad `json:"load"`
+}
+
+// TraceRangeLoad is the rate of load on a recorded range.
+type TraceRangeLoad struct {
+	// Range is the index of the range in Trace.Ranges.
+	Range               int     `json:"range"`
+	ReadsPerSecond      float64 `json:"reads_per_second,omitempty"`
+	WritesPerSecond     float64 `json:"writes_per_second,omitempty"`
+	ReadBytesPerSecond  float64 `json:"read_bytes_per_second,omitempty"`
+	WriteBytesPerSecond float64 `json:"write_bytes_per_second,omitempty"`
+	CPUNanosPerSecond   float64 `json:"cpu_nanos_per_second,omitempty"`
+}
+
+// Validate returns an error if the trace is malformed.
+func (t *Trace) Validate() error {
+	for i := range t.Samples {
+		sample := &t.Samples[i]
+		if i > 0 && sample.Offset <= t.Samples[i-1].Offset {
+			return errors.Newf("sample %d at offset %s is not after the previous sample at offset %s",
+				i, sample.Offset, t.Samples[i-1].Offset)
+		}
+		for _, l := range sample.Load {
+			if l.Range < 0 || l.Range >= len(t.Ranges) {
+				return errors.Newf("sample %d references range %d, but the trace has %d ranges",
+					i, l.Range, len(t.Ranges))
+			}
+			if l.ReadsPerSecond < 0 || l.WritesPerSecond < 0 || l.ReadBytesPerSecond < 0 ||
+				l.WriteBytesPerSecond < 0 || l.CPUNanosPerSecond < 0 {
+				return errors.Newf("sample %d has negative load for range %d", i, l.Range)
+			}
+		}
+	}
+	return nil
+}
+
+// StartKeys returns the start key in the simulated keyspace of each recorded
+// range, when each range spans keysPerRange keys.
+func (t *Trace) StartKeys(keysPerRange int64) []int64 {
+	keys := make([]int64, len(t.Ranges))
+	for i := range keys {
+		keys[i] = int64(i) * keysPerRange
+	}
+	return keys
+}
+
+// ReadTrace reads and validates a trace encoded as JSON.
+func ReadTrace(r io.Reader) (Trace, error) {
+	var t Trace
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return Trace{}, errors.Wrap(err, "decoding trace")
+	}
+	if err := t.Validate(); err != nil {
+		return Trace{}, err
+	}
+	return t, nil
+}
+
+// WriteTrace writes the trace encoded as JSON.
+func WriteTrace(w io.Writer, t Trace) error {
+	return json.NewEncoder(w).Encode(t)
+}
+
+// traceRangeLoad is the load on a range which has not yet been generated as
+// load events, as the fractional parts of the rates accumulate between ticks.
+type traceRangeLoad struct {
+	reads, writes, readBytes, writeBytes, cpu float64
+}
+
+// TraceGenerator generates the load recorded in a trace, starting from the
+// first sample of the trace at the start time.
+type TraceGenerator struct {
+	trace        Trace
+	start        time.Time
+	lastRun      time.Time
+	keysPerRange int64
+	rand         *rand.Rand
+	// sampleIdx is the index of the sample whose load applies at lastRun, or
+	// -1 if lastRun is before the first sample.
+	sampleIdx int
+	pending   []traceRangeLoad
+}
+
+// NewTraceGenerator returns a generator that replays the given trace, where
+// each recorded range spans keysPerRange keys of the simulated keyspace. The
+// load of a range is applied to keys of the range chosen at random, so that
+// load based splitting may split the range.
+func NewTraceGenerator(start time.Time, seed int64, trace Trace, keysPerRange int64) Generator {
+	return newTraceGenerator(start, seed, trace, keysPerRange)
+}
+
+func newTraceGenerator(
+	start time.Time, seed int64, trace Trace, keysPerRange int64,
+) *TraceGenerator {
+	return &TraceGenerator{
+		trace:        trace,
+		start:        start,
+		lastRun:      start,
+		keysPerRange: keysPerRange,
+		rand:         rand.New(rand.NewSource(seed)),
+		sampleIdx:    -1,
+		pending:      make([]traceRangeLoad, len(trace.Ranges)),
+	}
+}
+
+// Tick returns the load events up till time tick, from the last time the
+// workload generator was called.
+func (tg *TraceGenerator) Tick(maxTime time.Time) LoadBatch {
+	samples := tg.trace.Samples
+	for tg.lastRun.Before(maxTime) && len(samples) > 0 {
+		// Find the sample whose load applies at the last run.
+		for tg.sampleIdx+1 < len(samples) &&
+			!tg.start.Add(samples[tg.sampleIdx+1].Offset).After(tg.lastRun) {
+			tg.sampleIdx++
+		}
+		// The load of the sample applies until the next sample, if any.
+		end := maxTime
+		if tg.sampleIdx+1 < len(samples) {
+			if next := tg.start.Add(samples[tg.sampleIdx+1].Offset); next.Before(end) {
+				end = next
+			}
+		}
+		if tg.sampleIdx >= 0 {
+			elapsed := end.Sub(tg.lastRun).Seconds()
+			for _, l := range samples[tg.sampleIdx].Load {
+				p := &tg.pending[l.Range]
+				p.reads += l.ReadsPerSecond * elapsed
+				p.writes += l.WritesPerSecond * elapsed
+				p.readBytes += l.ReadBytesPerSecond * elapsed
+				p.writeBytes += l.WriteBytesPerSecond * elapsed
+				p.cpu += l.CPUNanosPerSecond * elapsed
+			}
+		}
+		tg.lastRun = end
+	}
+	if tg.lastRun.Before(maxTime) {
+		tg.lastRun = maxTime
+	}
+
+	// Generate a load event for each range whose accumulated load amounts to
+	// at least one read or write. The remainder is carried over to the next
+	// tick. The ranges are in key order, so the batch is sorted.
+	var ret LoadBatch
+	for i := range tg.pending {
+		p := &tg.pending[i]
+		reads, writes := int64(p.reads), int64(p.writes)
+		if reads == 0 && writes == 0 {
+			continue
+		}
+		event := LoadEvent{
+			Key:        int64(i)*tg.keysPerRange + tg.rand.Int63n(tg.keysPerRange),
+			Reads:      reads,
+			Writes:     writes,
+			ReadSize:   int64(p.readBytes),
+			WriteSize:  int64(p.writeBytes),
+			RequestCPU: int64(p.cpu),
+		}
+		p.reads -= float64(event.Reads)
+		p.writes -= float64(event.Writes)
+		p.readBytes -= float64(event.ReadSize)
+		p.writeBytes -= float64(event.WriteSize)
+		p.cpu -= float64(event.RequestCPU)
+		ret = append(ret, event)
+	}
+	return ret
+}
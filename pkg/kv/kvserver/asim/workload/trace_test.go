@@ -0,0 +1,106 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package workload
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testingTrace() Trace {
+	return Trace{
+		Ranges: []TraceRange{
+			{RangeID: 10, StartKey: "/Table/104/1"},
+			{RangeID: 11, StartKey: "/Table/104/1/100"},
+			{RangeID: 12, StartKey: "/Table/105/1"},
+		},
+		Samples: []TraceSample{
+			{
+				Offset: 0,
+				Load: []TraceRangeLoad{
+					{Range: 0, ReadsPerSecond: 10, WritesPerSecond: 1.5, WriteBytesPerSecond: 150},
+					{Range: 2, WritesPerSecond: 5, CPUNanosPerSecond: 1e6},
+				},
+			},
+			{
+				Offset: 10 * time.Second,
+				Load: []TraceRangeLoad{
+					{Range: 1, ReadsPerSecond: 2, ReadBytesPerSecond: 2000},
+				},
+			},
+		},
+	}
+}
+
+// TestTraceGenerator asserts that the trace generator replays the load of
+// each sample of a trace, until the next sample, against the keys of the
+// ranges which had load.
+func TestTraceGenerator(t *testing.T) {
+	const keysPerRange = 100
+	start := time.Date(2022, 03, 21, 11, 0, 0, 0, time.UTC)
+	tg := newTraceGenerator(start, testingSeed, testingTrace(), keysPerRange)
+
+	type rangeLoad struct {
+		reads, writes, readSize, writeSize, cpu int64
+	}
+	tick := func(at time.Duration) map[int64]rangeLoad {
+		loads := make(map[int64]rangeLoad)
+		batch := tg.Tick(start.Add(at))
+		for i, le := range batch {
+			if i > 0 {
+				require.Less(t, batch[i-1].Key, le.Key)
+			}
+			rangeIdx := le.Key / keysPerRange
+			require.NotContains(t, loads, rangeIdx)
+			loads[rangeIdx] = rangeLoad{le.Reads, le.Writes, le.ReadSize, le.WriteSize, le.RequestCPU}
+		}
+		return loads
+	}
+
+	// The fractional writes on the first range are carried over to the next
+	// tick.
+	require.Equal(t, map[int64]rangeLoad{
+		0: {reads: 50, writes: 7, writeSize: 750},
+		2: {writes: 25, cpu: 5e6},
+	}, tick(5*time.Second))
+	// The first sample applies until the second, at 10s, whose load then
+	// applies until the end of the trace.
+	require.Equal(t, map[int64]rangeLoad{
+		0: {reads: 50, writes: 8, writeSize: 750},
+		1: {reads: 20, readSize: 20000},
+		2: {writes: 25, cpu: 5e6},
+	}, tick(20*time.Second))
+	require.Equal(t, map[int64]rangeLoad{
+		1: {reads: 200, readSize: 200000},
+	}, tick(2*time.Minute))
+}
+
+func TestReadWriteTrace(t *testing.T) {
+	trace := testingTrace()
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrace(&buf, trace))
+	read, err := ReadTrace(&buf)
+	require.NoError(t, err)
+	require.Equal(t, trace, read)
+
+	trace.Samples[1].Offset = 0
+	buf.Reset()
+	require.NoError(t, WriteTrace(&buf, trace))
+	_, err = ReadTrace(&buf)
+	require.Regexp(t, "is not after the previous sample", err)
+
+	_, err = ReadTrace(strings.NewReader(`{"ranges": [], "samples": [{"offset": 0, "load": [{"range": 1}]}]}`))
+	require.Regexp(t, "sample 0 references range 1, but the trace has 0 ranges", err)
+}
@@ -25,6 +25,9 @@ type LoadEvent struct {
 	WriteSize int64
 	Reads     int64
 	ReadSize  int64
+	// RequestCPU is the CPU time, in nanoseconds, spent evaluating the reads
+	// and writes on the leaseholder.
+	RequestCPU int64
 }
 
 // LoadBatch is a sorted list of load events.
@@ -483,8 +483,9 @@ func IsEndTxnTriggeringRetryError(
 		isTxnPushed := txn.WriteTimestamp != readTimestamp
 
 		// Return a transaction retry error if the commit timestamp isn't equal to
-		// the txn timestamp.
-		if isTxnPushed {
+		// the txn timestamp, unless the transaction tolerates write skew, in
+		// which case it is allowed to commit above its read timestamp.
+		if isTxnPushed && !txn.IsoLevel.ToleratesWriteSkew() {
 			retry, reason = true, roachpb.RETRY_SERIALIZABLE
 		}
 	}
@@ -258,6 +258,11 @@ func PushTxn(
 	case txnwait.CanPushWithPriority(args.PusherTxn.Priority, reply.PusheeTxn.Priority):
 		reason = "pusher has priority"
 		pusherWins = true
+	case pushType == roachpb.PUSH_TIMESTAMP && reply.PusheeTxn.IsoLevel.ToleratesWriteSkew():
+		// The pushee can commit above its read timestamp, so pushing its
+		// timestamp does not force it to restart.
+		reason = "pushee tolerates write skew"
+		pusherWins = true
 	case args.Force:
 		reason = "forced push"
 		pusherWins = true
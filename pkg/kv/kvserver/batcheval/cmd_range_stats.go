@@ -52,6 +52,12 @@ func RangeStats(
 		reply.MaxQueriesPerSecond = -1
 	}
 	reply.MaxQueriesPerSecondSet = true
+	if cpu, ok := cArgs.EvalCtx.GetMaxSplitCPU(ctx); ok {
+		reply.MaxCPUPerSecond = cpu
+	} else {
+		// See comment on MaxCPUPerSecond. -1 means !ok.
+		reply.MaxCPUPerSecond = -1
+	}
 	reply.RangeInfo = cArgs.EvalCtx.GetRangeInfo(ctx)
 	return result.Result{}, nil
 }
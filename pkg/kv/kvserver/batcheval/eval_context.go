@@ -89,6 +89,13 @@ type EvalContext interface {
 	// is disabled.
 	GetMaxSplitQPS(context.Context) (float64, bool)
 
+	// GetMaxSplitCPU returns the Replicas maximum CPU time per second, in
+	// nanoseconds, spent evaluating requests over a configured retention period.
+	//
+	// NOTE: This should not be used when the load based splitting cluster setting
+	// is disabled.
+	GetMaxSplitCPU(context.Context) (float64, bool)
+
 	// GetLastSplitQPS returns the Replica's most recent queries/s request rate.
 	//
 	// NOTE: This should not be used when the load based splitting cluster setting
@@ -164,6 +171,7 @@ type MockEvalCtx struct {
 	Clock              *hlc.Clock
 	Stats              enginepb.MVCCStats
 	QPS                float64
+	CPU                float64
 	AbortSpan          *abortspan.AbortSpan
 	GCThreshold        hlc.Timestamp
 	Term, FirstIndex   uint64
@@ -242,6 +250,9 @@ func (m *mockEvalCtxImpl) GetMVCCStats() enginepb.MVCCStats {
 func (m *mockEvalCtxImpl) GetMaxSplitQPS(context.Context) (float64, bool) {
 	return m.QPS, true
 }
+func (m *mockEvalCtxImpl) GetMaxSplitCPU(context.Context) (float64, bool) {
+	return m.CPU, true
+}
 func (m *mockEvalCtxImpl) GetLastSplitQPS(context.Context) float64 {
 	return m.QPS
 }
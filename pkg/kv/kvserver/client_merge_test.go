@@ -50,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/grunning"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -4334,9 +4335,11 @@ func TestMergeQueue(t *testing.T) {
 	t.Run("load-based-merging", func(t *testing.T) {
 		const splitByLoadQPS = 10
 		const mergeByLoadQPS = splitByLoadQPS / 2 // see conservativeLoadBasedSplitThreshold
+		const splitByLoadCPU = 10 * time.Millisecond
+		const mergeByLoadCPU = splitByLoadCPU / 2
 		const splitByLoadMergeDelay = 500 * time.Millisecond
 
-		resetForLoadBasedSubtest := func(t *testing.T) {
+		resetForLoadBasedSubtestWithObjective := func(t *testing.T, objective kvserver.SplitObjective) {
 			reset(t)
 
 			// Enable load-based splitting for these subtests, which also instructs
@@ -4352,7 +4355,9 @@ func TestMergeQueue(t *testing.T) {
 			// meaning that it was a maximum measurement over some extended period of
 			// time.
 			kvserver.SplitByLoadEnabled.Override(ctx, sv, true)
+			kvserver.SplitByLoadObjectiveSetting.Override(ctx, sv, int64(objective))
 			kvserver.SplitByLoadQPSThreshold.Override(ctx, sv, splitByLoadQPS)
+			kvserver.SplitByLoadCPUThreshold.Override(ctx, sv, splitByLoadCPU)
 
 			// Drop the load-based splitting merge delay setting, which also dictates
 			// the duration that a leaseholder must measure QPS before considering its
@@ -4367,6 +4372,9 @@ func TestMergeQueue(t *testing.T) {
 			rhs().LoadBasedSplitter().Reset(tc.Servers[1].Clock().PhysicalTime())
 			manualClock.Increment(splitByLoadMergeDelay.Nanoseconds())
 		}
+		resetForLoadBasedSubtest := func(t *testing.T) {
+			resetForLoadBasedSubtestWithObjective(t, kvserver.SplitQPS)
+		}
 
 		t.Run("unreliable-lhs-qps", func(t *testing.T) {
 			resetForLoadBasedSubtest(t)
@@ -4408,6 +4416,35 @@ func TestMergeQueue(t *testing.T) {
 			clearRange(t, lhsStartKey, rhsEndKey)
 			verifyMergedSoon(t, store, lhsStartKey, rhsStartKey)
 		})
+
+		t.Run("combined-cpu-above-threshold", func(t *testing.T) {
+			if !grunning.Supported() {
+				skip.IgnoreLint(t, "the cpu objective falls back to qps without grunning")
+			}
+			resetForLoadBasedSubtestWithObjective(t, kvserver.SplitCPU)
+
+			moreThanHalfCPU := mergeByLoadCPU/2 + time.Millisecond
+			rhs().LoadBasedSplitter().RecordMax(tc.Servers[0].Clock().PhysicalTime(), float64(moreThanHalfCPU.Nanoseconds()))
+			lhs().LoadBasedSplitter().RecordMax(tc.Servers[1].Clock().PhysicalTime(), float64(moreThanHalfCPU.Nanoseconds()))
+
+			clearRange(t, lhsStartKey, rhsEndKey)
+			verifyUnmergedSoon(t, store, lhsStartKey, rhsStartKey)
+		})
+
+		t.Run("combined-cpu-below-threshold", func(t *testing.T) {
+			if !grunning.Supported() {
+				skip.IgnoreLint(t, "the cpu objective falls back to qps without grunning")
+			}
+			resetForLoadBasedSubtestWithObjective(t, kvserver.SplitCPU)
+
+			manualClock.Increment(splitByLoadMergeDelay.Nanoseconds())
+			lessThanHalfCPU := mergeByLoadCPU/2 - time.Millisecond
+			rhs().LoadBasedSplitter().RecordMax(tc.Servers[0].Clock().PhysicalTime(), float64(lessThanHalfCPU.Nanoseconds()))
+			lhs().LoadBasedSplitter().RecordMax(tc.Servers[1].Clock().PhysicalTime(), float64(lessThanHalfCPU.Nanoseconds()))
+
+			clearRange(t, lhsStartKey, rhsEndKey)
+			verifyMergedSoon(t, store, lhsStartKey, rhsStartKey)
+		})
 	})
 
 	t.Run("sticky-bit", func(t *testing.T) {
@@ -18,7 +18,8 @@ import (
 )
 
 // TargetForPolicy returns the target closed timestamp for a range with the
-// given policy.
+// given policy. lagTargetDuration is how far behind present time the policies
+// which lag present time close timestamps.
 func TargetForPolicy(
 	now hlc.ClockTimestamp,
 	maxClockOffset time.Duration,
@@ -29,7 +30,7 @@ func TargetForPolicy(
 ) hlc.Timestamp {
 	var res hlc.Timestamp
 	switch policy {
-	case roachpb.LAG_BY_CLUSTER_SETTING:
+	case roachpb.LAG_BY_CLUSTER_SETTING, roachpb.LAG_BY_READ_REPLICA_TARGET:
 		// Simple calculation: lag now by desired duration.
 		res = now.ToTimestamp().Add(-lagTargetDuration.Nanoseconds(), 0)
 	case roachpb.LEAD_FOR_GLOBAL_READS:
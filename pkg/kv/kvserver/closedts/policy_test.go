@@ -49,6 +49,11 @@ func TestTargetForPolicy(t *testing.T) {
 			rangePolicy:       roachpb.LAG_BY_CLUSTER_SETTING,
 			expClosedTSTarget: now.Add(-secs(1).Nanoseconds(), 0),
 		},
+		{
+			lagTargetNanos:    millis(500),
+			rangePolicy:       roachpb.LAG_BY_READ_REPLICA_TARGET,
+			expClosedTSTarget: now.Add(-millis(500).Nanoseconds(), 0),
+		},
 		{
 			sideTransportCloseInterval: millis(200),
 			rangePolicy:                roachpb.LEAD_FOR_GLOBAL_READS,
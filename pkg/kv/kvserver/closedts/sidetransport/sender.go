@@ -20,6 +20,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -67,6 +68,14 @@ type Sender struct {
 		// closingFailures buckets the failures to advance the closed timestamps of
 		// ranges for the last publishing cycle.
 		closingFailures [MaxReason]int
+		// numPolicies is the number of policies whose closed timestamps were
+		// published in the last message. The LAG_BY_READ_REPLICA_TARGET policy is
+		// only published once all nodes know about it.
+		numPolicies int
+		// readReplicaLagTarget is the tightest lag target of the ranges with the
+		// LAG_BY_READ_REPLICA_TARGET policy seen in the last publishing cycle, or
+		// zero if there were none.
+		readReplicaLagTarget time.Duration
 	}
 
 	leaseholdersMu struct {
@@ -170,6 +179,11 @@ type BumpSideTransportClosedResult struct {
 	LAI ctpb.LAI
 	// The range's current policy.
 	Policy roachpb.RangeClosedTimestampPolicy
+
+	// ReadReplicaLagTarget is set, regardless of OK, if the range's policy is
+	// LAG_BY_READ_REPLICA_TARGET. It is the lag target of the range's closed
+	// timestamps.
+	ReadReplicaLagTarget time.Duration
 }
 
 // CantCloseReason enumerates the reasons why BunpSideTransportClosed might fail
@@ -306,9 +320,16 @@ func (s *Sender) publish(ctx context.Context) hlc.ClockTimestamp {
 	log.VEventf(ctx, 4, "side-transport generating a new message")
 	s.trackedMu.closingFailures = [MaxReason]int{}
 
+	// Nodes which don't know about the LAG_BY_READ_REPLICA_TARGET policy can't
+	// receive its closed timestamps, and ranges only use it once all nodes do.
+	numPolicies := len(s.trackedMu.lastClosed)
+	if !s.st.Version.IsActive(ctx, clusterversion.ReadReplicas) {
+		numPolicies = int(roachpb.LAG_BY_READ_REPLICA_TARGET)
+	}
+	s.trackedMu.numPolicies = numPolicies
 	msg := &ctpb.Update{
 		NodeID:           s.nodeID,
-		ClosedTimestamps: make([]ctpb.Update_GroupUpdate, len(s.trackedMu.lastClosed)),
+		ClosedTimestamps: make([]ctpb.Update_GroupUpdate, numPolicies),
 	}
 
 	// Determine the message's sequence number.
@@ -326,16 +347,35 @@ func (s *Sender) publish(ctx context.Context) hlc.ClockTimestamp {
 	lagTargetDuration := closedts.TargetDuration.Get(&s.st.SV)
 	leadTargetOverride := closedts.LeadForGlobalReadsOverride.Get(&s.st.SV)
 	sideTransportCloseInterval := closedts.SideTransportCloseInterval.Get(&s.st.SV)
-	for i := range s.trackedMu.lastClosed {
+	// The ranges with the LAG_BY_READ_REPLICA_TARGET policy share a single closed
+	// timestamp, so it lags by the tightest of their lag targets. This closes
+	// timestamps more aggressively than other ranges of the policy ask for,
+	// which only means that writes to them are pushed earlier. A range joining
+	// the policy with a tighter target is taken into account by the next cycle.
+	readReplicaLagTarget := s.trackedMu.readReplicaLagTarget
+	if readReplicaLagTarget == 0 || readReplicaLagTarget > lagTargetDuration {
+		readReplicaLagTarget = lagTargetDuration
+	}
+	s.trackedMu.readReplicaLagTarget = 0
+	for i := 0; i < numPolicies; i++ {
 		pol := roachpb.RangeClosedTimestampPolicy(i)
+		lag := lagTargetDuration
+		if pol == roachpb.LAG_BY_READ_REPLICA_TARGET {
+			lag = readReplicaLagTarget
+		}
 		target := closedts.TargetForPolicy(
 			now,
 			maxClockOffset,
-			lagTargetDuration,
+			lag,
 			leadTargetOverride,
 			sideTransportCloseInterval,
 			pol,
 		)
+		if pol == roachpb.LAG_BY_READ_REPLICA_TARGET {
+			// The lag target changes as ranges join and leave the policy. Don't let
+			// the closed timestamp of the policy regress when it loosens.
+			target.Forward(s.trackedMu.lastClosed[pol])
+		}
 		s.trackedMu.lastClosed[pol] = target
 		msg.ClosedTimestamps[pol] = ctpb.Update_GroupUpdate{
 			Policy:          pol,
@@ -375,6 +415,10 @@ func (s *Sender) publish(ctx context.Context) hlc.ClockTimestamp {
 
 		// Check whether the desired timestamp can be closed on this range.
 		closeRes := lh.BumpSideTransportClosed(ctx, now, s.trackedMu.lastClosed)
+		if lag := closeRes.ReadReplicaLagTarget; lag > 0 &&
+			(s.trackedMu.readReplicaLagTarget == 0 || lag < s.trackedMu.readReplicaLagTarget) {
+			s.trackedMu.readReplicaLagTarget = lag
+		}
 
 		// Ensure that we're communicating with all of the range's followers. Note
 		// that we're including this range's followers before deciding below if the
@@ -474,10 +518,10 @@ func (s *Sender) GetSnapshot() *ctpb.Update {
 		// of incremental messages.
 		SeqNum:           s.trackedMu.lastSeqNum,
 		Snapshot:         true,
-		ClosedTimestamps: make([]ctpb.Update_GroupUpdate, len(s.trackedMu.lastClosed)),
+		ClosedTimestamps: make([]ctpb.Update_GroupUpdate, s.trackedMu.numPolicies),
 		AddedOrUpdated:   make([]ctpb.Update_RangeUpdate, 0, len(s.trackedMu.tracked)),
 	}
-	for pol, ts := range s.trackedMu.lastClosed {
+	for pol, ts := range s.trackedMu.lastClosed[:s.trackedMu.numPolicies] {
 		msg.ClosedTimestamps[pol] = ctpb.Update_GroupUpdate{
 			Policy:          roachpb.RangeClosedTimestampPolicy(pol),
 			ClosedTimestamp: ts,
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -48,6 +49,7 @@ type mockReplica struct {
 	cantBumpReason CantCloseReason
 	lai            ctpb.LAI
 	policy         roachpb.RangeClosedTimestampPolicy
+	lagTarget      time.Duration
 }
 
 var _ Replica = &mockReplica{}
@@ -69,6 +71,8 @@ func (m *mockReplica) BumpSideTransportClosed(
 		Desc:       &m.mu.desc,
 		LAI:        m.lai,
 		Policy:     m.policy,
+
+		ReadReplicaLagTarget: m.lagTarget,
 	}
 }
 
@@ -105,8 +109,13 @@ func (c *mockConn) close()                             { c.closed = true }
 func (c *mockConn) getState() connState                { return connState{} }
 
 func newMockSender(connFactory connFactory) (*Sender, *stop.Stopper) {
+	return newMockSenderWithSettings(connFactory, cluster.MakeTestingClusterSettings())
+}
+
+func newMockSenderWithSettings(
+	connFactory connFactory, st *cluster.Settings,
+) (*Sender, *stop.Stopper) {
 	stopper := stop.NewStopper()
-	st := cluster.MakeTestingClusterSettings()
 	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
 	s := newSenderWithConnFactory(stopper, st, clock, connFactory)
 	s.nodeID = 1 // usually set in (*Sender).Run
@@ -144,6 +153,7 @@ func expGroupUpdates(s *Sender, now hlc.ClockTimestamp) []ctpb.Update_GroupUpdat
 	return []ctpb.Update_GroupUpdate{
 		{Policy: roachpb.LAG_BY_CLUSTER_SETTING, ClosedTimestamp: targetForPolicy(roachpb.LAG_BY_CLUSTER_SETTING)},
 		{Policy: roachpb.LEAD_FOR_GLOBAL_READS, ClosedTimestamp: targetForPolicy(roachpb.LEAD_FOR_GLOBAL_READS)},
+		{Policy: roachpb.LAG_BY_READ_REPLICA_TARGET, ClosedTimestamp: targetForPolicy(roachpb.LAG_BY_READ_REPLICA_TARGET)},
 	}
 }
 
@@ -241,6 +251,76 @@ func TestSenderBasic(t *testing.T) {
 	require.True(t, c3.(*mockConn).closed)
 }
 
+// TestSenderReadReplicaLagTarget checks that the closed timestamps of the
+// LAG_BY_READ_REPLICA_TARGET policy lag by the tightest lag target of the
+// ranges with the policy, and that they are only published once all nodes know
+// about the policy.
+func TestSenderReadReplicaLagTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	connFactory := &mockConnFactory{}
+	s, stopper := newMockSender(connFactory)
+	defer stopper.Stop(ctx)
+	clusterTarget := closedts.TargetDuration.Get(&s.st.SV)
+
+	r1 := newMockReplica(15, 1, 2)
+	r1.policy = roachpb.LAG_BY_READ_REPLICA_TARGET
+	r1.lagTarget = clusterTarget / 3
+	s.RegisterLeaseholder(ctx, r1, 1)
+	r2 := newMockReplica(16, 1, 2)
+	r2.policy = roachpb.LAG_BY_READ_REPLICA_TARGET
+	r2.lagTarget = clusterTarget / 2
+	s.RegisterLeaseholder(ctx, r2, 1)
+
+	// The lag targets of the ranges are only known after the first cycle.
+	now := s.publish(ctx)
+	up, ok := s.buf.GetBySeq(ctx, 1)
+	require.True(t, ok)
+	require.Len(t, up.ClosedTimestamps, int(roachpb.MAX_CLOSED_TIMESTAMP_POLICY))
+	require.Equal(t, now.ToTimestamp().Add(-clusterTarget.Nanoseconds(), 0),
+		up.ClosedTimestamps[roachpb.LAG_BY_READ_REPLICA_TARGET].ClosedTimestamp)
+	require.Equal(t, r1.lagTarget, s.trackedMu.readReplicaLagTarget)
+
+	now = s.publish(ctx)
+	up, ok = s.buf.GetBySeq(ctx, 2)
+	require.True(t, ok)
+	require.Equal(t, now.ToTimestamp().Add(-r1.lagTarget.Nanoseconds(), 0),
+		up.ClosedTimestamps[roachpb.LAG_BY_READ_REPLICA_TARGET].ClosedTimestamp)
+	require.Equal(t, []ctpb.Update_GroupUpdate{
+		up.ClosedTimestamps[roachpb.LAG_BY_CLUSTER_SETTING],
+		up.ClosedTimestamps[roachpb.LEAD_FOR_GLOBAL_READS],
+		up.ClosedTimestamps[roachpb.LAG_BY_READ_REPLICA_TARGET],
+	}, s.GetSnapshot().ClosedTimestamps)
+
+	// When the range with the tightest target leaves the policy, the closed
+	// timestamp of the policy doesn't regress.
+	prev := up.ClosedTimestamps[roachpb.LAG_BY_READ_REPLICA_TARGET].ClosedTimestamp
+	s.UnregisterLeaseholder(ctx, 1, r1.rangeID)
+	s.publish(ctx)
+	now = s.publish(ctx)
+	up, ok = s.buf.GetBySeq(ctx, 4)
+	require.True(t, ok)
+	closed := up.ClosedTimestamps[roachpb.LAG_BY_READ_REPLICA_TARGET].ClosedTimestamp
+	require.True(t, prev.LessEq(closed))
+	require.True(t, closed.LessEq(now.ToTimestamp().Add(-r1.lagTarget.Nanoseconds(), 0)))
+	require.Equal(t, r2.lagTarget, s.trackedMu.readReplicaLagTarget)
+
+	// Nodes which don't know about the policy aren't sent its closed timestamps.
+	st := cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.TestingBinaryVersion,
+		clusterversion.ByKey(clusterversion.ReadReplicas-1),
+		true, /* initializeVersion */
+	)
+	s2, stopper2 := newMockSenderWithSettings(connFactory, st)
+	defer stopper2.Stop(ctx)
+	s2.publish(ctx)
+	up, ok = s2.buf.GetBySeq(ctx, 1)
+	require.True(t, ok)
+	require.Len(t, up.ClosedTimestamps, int(roachpb.LAG_BY_READ_REPLICA_TARGET))
+	require.Len(t, s2.GetSnapshot().ClosedTimestamps, int(roachpb.LAG_BY_READ_REPLICA_TARGET))
+}
+
 func TestSenderConnectionChanges(t *testing.T) {
 	// TODO: Two ranges.
 	// Add follower for range 1: 2, 3.
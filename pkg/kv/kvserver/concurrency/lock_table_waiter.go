@@ -225,7 +225,11 @@ func (w *lockTableWaiterImpl) WaitOn(
 				// If the pushee has the minimum priority or if the pusher has the
 				// maximum priority, push immediately to proceed without queueing.
 				// The push should succeed without entering the txn wait-queue.
-				priorityPush := canPushWithPriority(req, state)
+				// The same is true of readers which conflict with the lock of a
+				// transaction that tolerates write skew, as the reader only needs
+				// to push the timestamp of the lock holder.
+				priorityPush := canPushWithPriority(req, state) ||
+					canPushTimestampOfWriteSkewTolerantTxn(req, state)
 
 				// If the request doesn't want to perform a delayed push for any
 				// reason, continue waiting without a timer.
@@ -1246,6 +1250,19 @@ func canPushWithPriority(req Request, s waitingState) bool {
 	return txnwait.CanPushWithPriority(pusher, pushee)
 }
 
+// canPushTimestampOfWriteSkewTolerantTxn returns whether the request conflicts
+// with the lock of a transaction that tolerates write skew and only needs to
+// push the timestamp of that transaction, in which case the push succeeds
+// regardless of the priorities of the transactions.
+func canPushTimestampOfWriteSkewTolerantTxn(req Request, s waitingState) bool {
+	if s.txn == nil {
+		return false
+	}
+	return req.WaitPolicy == lock.WaitPolicy_Block &&
+		s.guardAccess == spanset.SpanReadOnly &&
+		s.txn.IsoLevel.ToleratesWriteSkew()
+}
+
 func minDuration(a, b time.Duration) time.Duration {
 	if a < b {
 		return a
@@ -95,3 +95,46 @@ func ConjunctionsCheck(store roachpb.StoreDescriptor, constraints []roachpb.Cons
 	}
 	return true
 }
+
+// ReadReplicas partitions the non-voting replicas of a range into its
+// dedicated read replicas and its other non-voters. The read replicas are the
+// first numReadReplicas non-voters which satisfy one of the read replica
+// constraints (any non-voter does, if there are no such constraints).
+func ReadReplicas(
+	getStoreDescFn func(roachpb.StoreID) (roachpb.StoreDescriptor, bool),
+	nonVoters []roachpb.ReplicaDescriptor,
+	numReadReplicas int32,
+	constraints []roachpb.ConstraintsConjunction,
+) (readReplicas, others []roachpb.ReplicaDescriptor) {
+	for _, repl := range nonVoters {
+		if int32(len(readReplicas)) < numReadReplicas && satisfiesAnyConjunction(getStoreDescFn, repl, constraints) {
+			readReplicas = append(readReplicas, repl)
+		} else {
+			others = append(others, repl)
+		}
+	}
+	return readReplicas, others
+}
+
+// satisfiesAnyConjunction returns whether the store of the replica satisfies
+// any of the given conjunctions of constraints. As in AnalyzeConstraints, a
+// store whose descriptor is missing is trusted to be valid.
+func satisfiesAnyConjunction(
+	getStoreDescFn func(roachpb.StoreID) (roachpb.StoreDescriptor, bool),
+	repl roachpb.ReplicaDescriptor,
+	constraints []roachpb.ConstraintsConjunction,
+) bool {
+	if len(constraints) == 0 {
+		return true
+	}
+	store, ok := getStoreDescFn(repl.StoreID)
+	if !ok {
+		return true
+	}
+	for _, subConstraints := range constraints {
+		if ConjunctionsCheck(store, subConstraints.Constraints) {
+			return true
+		}
+	}
+	return false
+}
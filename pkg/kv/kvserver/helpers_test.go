@@ -477,7 +477,7 @@ func (r *Replica) ReadCachedProtectedTS() (readAt, earliestProtectionTimestamp h
 func (r *Replica) ClosedTimestampPolicy() roachpb.RangeClosedTimestampPolicy {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.closedTimestampPolicyRLocked()
+	return r.closedTimestampPolicyRLocked(context.Background())
 }
 
 // TripBreaker synchronously trips the breaker.
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 )
 
@@ -167,7 +168,10 @@ func PlanReplicas(
 	})
 	report.Problems = problems
 	report.UpdatedNodes = updatedLocations.asMapOfSlices()
-	return loqrecoverypb.ReplicaUpdatePlan{Updates: plan}, report, nil
+	return loqrecoverypb.ReplicaUpdatePlan{
+		Updates: plan,
+		PlanID:  uuid.MakeV4(),
+	}, report, nil
 }
 
 // validateReplicaSets evaluates provided set of replicas and an optional
@@ -0,0 +1,96 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"path/filepath"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/errors/oserror"
+)
+
+const (
+	planDirName  = "loss-of-quorum-recovery"
+	planFileName = "staged.plan"
+)
+
+// PlanStore is a place where a loss of quorum recovery plan is staged until
+// the node is restarted and the plan is applied to its stores. Plan is stored
+// in the auxiliary directory of the first store of the node as stores could
+// not be modified while the node is running.
+type PlanStore struct {
+	path string
+	fs   fs.FS
+}
+
+// NewPlanStore creates a plan store in the given directory of provided file
+// system.
+func NewPlanStore(path string, fs fs.FS) PlanStore {
+	return PlanStore{
+		path: filepath.Join(path, planDirName),
+		fs:   fs,
+	}
+}
+
+// SavePlan stages the plan replacing any previously staged plan.
+func (s PlanStore) SavePlan(plan loqrecoverypb.ReplicaUpdatePlan) error {
+	data, err := protoutil.Marshal(&plan)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal loss of quorum recovery plan")
+	}
+	if err := s.fs.MkdirAll(s.path); err != nil {
+		return errors.Wrapf(err, "failed to create loss of quorum recovery plan directory %q", s.path)
+	}
+	// Write the plan into a temp file first and then rename to avoid leaving
+	// a partially written plan behind if the node crashes mid write.
+	tmpFile := s.planFile() + ".tmp"
+	if err := fs.WriteFile(s.fs, tmpFile, data); err != nil {
+		return errors.Wrapf(err, "failed to write loss of quorum recovery plan %q", tmpFile)
+	}
+	if err := s.fs.Rename(tmpFile, s.planFile()); err != nil {
+		return errors.Wrapf(err, "failed to stage loss of quorum recovery plan %q", s.planFile())
+	}
+	return nil
+}
+
+// LoadPlan reads the staged plan if there is one. Returns false if there's no
+// staged plan.
+func (s PlanStore) LoadPlan() (loqrecoverypb.ReplicaUpdatePlan, bool, error) {
+	data, err := fs.ReadFile(s.fs, s.planFile())
+	if err != nil {
+		if oserror.IsNotExist(err) {
+			return loqrecoverypb.ReplicaUpdatePlan{}, false, nil
+		}
+		return loqrecoverypb.ReplicaUpdatePlan{}, false, errors.Wrapf(err,
+			"failed to read loss of quorum recovery plan %q", s.planFile())
+	}
+	var plan loqrecoverypb.ReplicaUpdatePlan
+	if err := protoutil.Unmarshal(data, &plan); err != nil {
+		return loqrecoverypb.ReplicaUpdatePlan{}, false, errors.Wrapf(err,
+			"failed to unmarshal loss of quorum recovery plan %q", s.planFile())
+	}
+	return plan, true, nil
+}
+
+// RemovePlan removes the staged plan if there is one.
+func (s PlanStore) RemovePlan() error {
+	if err := s.fs.Remove(s.planFile()); err != nil && !oserror.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove loss of quorum recovery plan %q", s.planFile())
+	}
+	return nil
+}
+
+func (s PlanStore) planFile() string {
+	return filepath.Join(s.path, planFileName)
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// rangeMetadataScanChunkSize is the number of range descriptors read from meta
+// ranges at once when checking range availability.
+const rangeMetadataScanChunkSize = 100
+
+// StagePlan stages the plan in the plan store if the plan contains updates for
+// replicas of the given node. If the plan has no updates for the node, any
+// previously staged plan is removed instead so that all nodes of the cluster
+// agree on the plan being applied. If a different plan is already staged on
+// the node, it is only replaced if force is set.
+func StagePlan(
+	ctx context.Context,
+	planStore PlanStore,
+	nodeID roachpb.NodeID,
+	plan loqrecoverypb.ReplicaUpdatePlan,
+	force bool,
+) error {
+	staged, exists, err := planStore.LoadPlan()
+	if err != nil {
+		return err
+	}
+	if exists && staged.PlanID != plan.PlanID && !force {
+		return errors.Newf("plan %s is already staged on node n%d", staged.PlanID, nodeID)
+	}
+	if !planHasUpdatesForNode(plan, nodeID) {
+		if exists {
+			log.Infof(ctx, "removing staged loss of quorum recovery plan %s", staged.PlanID)
+		}
+		return planStore.RemovePlan()
+	}
+	log.Infof(ctx, "staging loss of quorum recovery plan %s", plan.PlanID)
+	return planStore.SavePlan(plan)
+}
+
+func planHasUpdatesForNode(plan loqrecoverypb.ReplicaUpdatePlan, nodeID roachpb.NodeID) bool {
+	for _, u := range plan.Updates {
+		if u.NodeID() == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// MaybeApplyPendingRecoveryPlan applies a staged recovery plan to the stores
+// of the node if there is one. It must be called on node startup before stores
+// are started. Result of the application is written to the store local
+// key space of all stores and could be retrieved using GetNodeRecoveryStatus.
+// Staged plan is removed once application is attempted regardless of its
+// outcome so that the node doesn't retry it on every restart. Failure to apply
+// the plan is not returned as error since it shouldn't prevent node from
+// starting, it is only reported in the recovery status.
+func MaybeApplyPendingRecoveryPlan(
+	ctx context.Context, planStore PlanStore, engines []storage.Engine, clock timeutil.TimeSource,
+) error {
+	if len(engines) == 0 {
+		return nil
+	}
+	plan, exists, err := planStore.LoadPlan()
+	if err != nil || !exists {
+		return err
+	}
+
+	log.Infof(ctx, "applying staged loss of quorum recovery plan %s", plan.PlanID)
+	result := loqrecoverypb.PlanApplicationResult{
+		AppliedPlanID:  plan.PlanID,
+		ApplyTimestamp: clock.Now(),
+	}
+	if err := applyStagedPlan(ctx, plan, engines, result.ApplyTimestamp); err != nil {
+		log.Errorf(ctx, "failed to apply loss of quorum recovery plan %s: %v", plan.PlanID, err)
+		result.Error = err.Error()
+	}
+	for _, e := range engines {
+		if err := writeNodeRecoveryResults(ctx, e, result); err != nil {
+			return errors.Wrap(err, "failed to write loss of quorum recovery results")
+		}
+	}
+	return planStore.RemovePlan()
+}
+
+func applyStagedPlan(
+	ctx context.Context,
+	plan loqrecoverypb.ReplicaUpdatePlan,
+	engines []storage.Engine,
+	updateTime time.Time,
+) error {
+	var localNodeID roachpb.NodeID
+	batches := make(map[roachpb.StoreID]storage.Batch)
+	for _, e := range engines {
+		ident, err := kvserver.ReadStoreIdent(ctx, e)
+		if err != nil {
+			if errors.HasType(err, (*kvserver.NotBootstrappedError)(nil)) {
+				// Uninitialized stores can't contain replicas to recover.
+				continue
+			}
+			return err
+		}
+		if localNodeID != ident.NodeID {
+			if localNodeID != roachpb.NodeID(0) {
+				return errors.Errorf("found stores from multiple node IDs n%d, n%d",
+					localNodeID, ident.NodeID)
+			}
+			localNodeID = ident.NodeID
+		}
+		batch := e.NewBatch()
+		defer batch.Close()
+		batches[ident.StoreID] = batch
+	}
+
+	prepReport, err := PrepareUpdateReplicas(
+		ctx, plan, uuid.DefaultGenerator, updateTime, localNodeID, batches)
+	if err != nil {
+		return err
+	}
+	if len(prepReport.MissingStores) > 0 {
+		return errors.Newf("stores %v expected on the node but not found", prepReport.MissingStores)
+	}
+	for _, r := range prepReport.SkippedReplicas {
+		log.Infof(ctx, "replica %s for range r%d is already updated", r.Replica, r.RangeID())
+	}
+	for _, r := range prepReport.UpdatedReplicas {
+		log.Infof(ctx, "replica %s for range r%d:%s will be updated to %s with peer replica(s) removed: %s",
+			r.OldReplica, r.RangeID(), r.StartKey(), r.Replica, r.RemovedReplicas)
+	}
+	applyReport, err := CommitReplicaChanges(batches)
+	if len(applyReport.UpdatedStores) > 0 {
+		log.Infof(ctx, "updated stores %v", applyReport.UpdatedStores)
+	}
+	return err
+}
+
+// GetNodeRecoveryStatus returns loss of quorum recovery status of the node
+// containing the staged plan if any and the result of the last plan
+// application.
+func GetNodeRecoveryStatus(
+	ctx context.Context, nodeID roachpb.NodeID, planStore PlanStore, engines []storage.Engine,
+) (loqrecoverypb.NodeRecoveryStatus, error) {
+	status := loqrecoverypb.NodeRecoveryStatus{NodeID: nodeID}
+	plan, exists, err := planStore.LoadPlan()
+	if err != nil {
+		return loqrecoverypb.NodeRecoveryStatus{}, err
+	}
+	if exists {
+		status.PendingPlanID = &plan.PlanID
+	}
+	for _, e := range engines {
+		result, ok, err := readNodeRecoveryStatusInfo(ctx, e)
+		if err != nil {
+			return loqrecoverypb.NodeRecoveryStatus{}, err
+		}
+		if ok {
+			status.AppliedPlanID = &result.AppliedPlanID
+			status.ApplyTimestamp = &result.ApplyTimestamp
+			status.Error = result.Error
+			break
+		}
+	}
+	return status, nil
+}
+
+func writeNodeRecoveryResults(
+	ctx context.Context, writer storage.ReadWriter, result loqrecoverypb.PlanApplicationResult,
+) error {
+	return storage.MVCCPutProto(ctx, writer, nil, keys.StoreLossOfQuorumRecoveryStatusKey(),
+		hlc.Timestamp{}, hlc.ClockTimestamp{}, nil, &result)
+}
+
+func readNodeRecoveryStatusInfo(
+	ctx context.Context, reader storage.Reader,
+) (loqrecoverypb.PlanApplicationResult, bool, error) {
+	var result loqrecoverypb.PlanApplicationResult
+	ok, err := storage.MVCCGetProto(ctx, reader, keys.StoreLossOfQuorumRecoveryStatusKey(),
+		hlc.Timestamp{}, &result, storage.MVCCGetOptions{})
+	if err != nil {
+		return loqrecoverypb.PlanApplicationResult{}, false, errors.Wrap(err,
+			"failed to read loss of quorum recovery status")
+	}
+	return result, ok, nil
+}
+
+// RangesWithoutQuorum scans meta ranges and returns descriptors of all ranges
+// that can't make progress because majority of their replicas reside on nodes
+// that are not live.
+//
+// Meta ranges are checked before the ranges they address, so that the scan
+// doesn't block on them: the first range, whose descriptor is provided by the
+// caller if known, then the meta2 ranges described in meta1. Each scan of meta
+// ranges is bounded by scanTimeout. If meta ranges can't make progress or
+// can't be read in time, they are returned as unavailable along with an error,
+// and the ranges they address are not checked.
+func RangesWithoutQuorum(
+	ctx context.Context,
+	db *kv.DB,
+	firstRange *roachpb.RangeDescriptor,
+	isLive func(roachpb.NodeID) bool,
+	scanTimeout time.Duration,
+) ([]roachpb.RangeDescriptor, error) {
+	return rangesWithoutQuorum(ctx, firstRange, isLive,
+		func(ctx context.Context, start, end roachpb.Key) ([]roachpb.RangeDescriptor, error) {
+			var descs []roachpb.RangeDescriptor
+			err := contextutil.RunWithTimeout(ctx, "scan meta range", scanTimeout,
+				func(ctx context.Context) error {
+					return db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+						descs = descs[:0]
+						return txn.Iterate(ctx, start, end, rangeMetadataScanChunkSize,
+							func(kvs []kv.KeyValue) error {
+								for _, row := range kvs {
+									var desc roachpb.RangeDescriptor
+									if err := row.ValueProto(&desc); err != nil {
+										return errors.Wrapf(err, "failed to decode range descriptor at %s", row.Key)
+									}
+									descs = append(descs, desc)
+								}
+								return nil
+							})
+					})
+				})
+			return descs, err
+		})
+}
+
+// rangesWithoutQuorum implements RangesWithoutQuorum, reading range
+// descriptors between the given meta keys with scan.
+func rangesWithoutQuorum(
+	ctx context.Context,
+	firstRange *roachpb.RangeDescriptor,
+	isLive func(roachpb.NodeID) bool,
+	scan func(ctx context.Context, start, end roachpb.Key) ([]roachpb.RangeDescriptor, error),
+) ([]roachpb.RangeDescriptor, error) {
+	withoutQuorum := func(descs []roachpb.RangeDescriptor) []roachpb.RangeDescriptor {
+		var ranges []roachpb.RangeDescriptor
+		for _, desc := range descs {
+			if !desc.Replicas().CanMakeProgress(func(rd roachpb.ReplicaDescriptor) bool {
+				return isLive(rd.NodeID)
+			}) {
+				ranges = append(ranges, desc)
+			}
+		}
+		return ranges
+	}
+
+	var metaRanges []roachpb.RangeDescriptor
+	if firstRange != nil {
+		metaRanges = append(metaRanges, *firstRange)
+		if ranges := withoutQuorum(metaRanges); len(ranges) > 0 {
+			return ranges, errors.Newf(
+				"first range r%d lost quorum, other ranges were not checked", firstRange.RangeID)
+		}
+	}
+	meta2Ranges, err := scan(ctx, keys.Meta1Prefix, keys.Meta1KeyMax)
+	if err != nil {
+		return metaRanges, errors.Wrap(err, "failed to read meta2 range descriptors from meta1")
+	}
+	for _, desc := range meta2Ranges {
+		if firstRange == nil || desc.RangeID != firstRange.RangeID {
+			metaRanges = append(metaRanges, desc)
+		}
+	}
+	if ranges := withoutQuorum(metaRanges); len(ranges) > 0 {
+		return ranges, errors.Newf("%d meta ranges lost quorum, other ranges were not checked", len(ranges))
+	}
+
+	descs, err := scan(ctx, keys.Meta2Prefix, keys.MetaMax)
+	if err != nil {
+		return metaRanges, errors.Wrap(err, "failed to read range descriptors from meta ranges")
+	}
+	return withoutQuorum(descs), nil
+}
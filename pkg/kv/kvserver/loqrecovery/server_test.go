@@ -0,0 +1,259 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestPlan(nodeID roachpb.NodeID, storeID roachpb.StoreID) loqrecoverypb.ReplicaUpdatePlan {
+	return loqrecoverypb.ReplicaUpdatePlan{
+		PlanID: uuid.MakeV4(),
+		Updates: []loqrecoverypb.ReplicaUpdate{
+			{
+				RangeID:  7,
+				StartKey: loqrecoverypb.RecoveryKey(roachpb.RKey("a")),
+				NewReplica: roachpb.ReplicaDescriptor{
+					NodeID:    nodeID,
+					StoreID:   storeID,
+					ReplicaID: 12,
+				},
+				NextReplicaID: 13,
+			},
+		},
+	}
+}
+
+func makeTestEngine(t *testing.T, nodeID roachpb.NodeID, storeID roachpb.StoreID) storage.Engine {
+	eng := storage.NewDefaultInMemForTesting()
+	require.NoError(t, storage.MVCCPutProto(context.Background(), eng, nil, keys.StoreIdentKey(),
+		hlc.Timestamp{}, hlc.ClockTimestamp{}, nil, &roachpb.StoreIdent{NodeID: nodeID, StoreID: storeID}))
+	return eng
+}
+
+func TestPlanStore(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+	ps := NewPlanStore(eng.GetAuxiliaryDir(), eng)
+
+	_, exists, err := ps.LoadPlan()
+	require.NoError(t, err)
+	require.False(t, exists, "empty store must not contain a plan")
+
+	plan := makeTestPlan(1, 1)
+	require.NoError(t, ps.SavePlan(plan))
+	loaded, exists, err := ps.LoadPlan()
+	require.NoError(t, err)
+	require.True(t, exists, "saved plan not found")
+	require.Equal(t, plan, loaded)
+
+	require.NoError(t, ps.RemovePlan())
+	_, exists, err = ps.LoadPlan()
+	require.NoError(t, err)
+	require.False(t, exists, "plan was not removed")
+	require.NoError(t, ps.RemovePlan(), "removing missing plan must succeed")
+}
+
+func TestStagePlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	eng := storage.NewDefaultInMemForTesting()
+	defer eng.Close()
+	ps := NewPlanStore(eng.GetAuxiliaryDir(), eng)
+
+	plan := makeTestPlan(1, 1)
+	require.NoError(t, StagePlan(ctx, ps, 1, plan, false))
+	staged, exists, err := ps.LoadPlan()
+	require.NoError(t, err)
+	require.True(t, exists, "plan with updates for the node must be staged")
+	require.Equal(t, plan.PlanID, staged.PlanID)
+
+	// Restaging the same plan is a no-op.
+	require.NoError(t, StagePlan(ctx, ps, 1, plan, false))
+
+	// Different plan can only replace staged one if forced.
+	otherPlan := makeTestPlan(2, 2)
+	require.Error(t, StagePlan(ctx, ps, 1, otherPlan, false))
+	require.NoError(t, StagePlan(ctx, ps, 1, otherPlan, true))
+	_, exists, err = ps.LoadPlan()
+	require.NoError(t, err)
+	require.False(t, exists, "plan without updates for the node must remove staged plan")
+}
+
+func TestApplyPendingRecoveryPlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	clock := timeutil.NewManualTime(timeutil.Unix(0, 123))
+
+	for _, td := range []struct {
+		name      string
+		plan      loqrecoverypb.ReplicaUpdatePlan
+		expectErr string
+	}{
+		{
+			name: "no updates for node",
+			plan: makeTestPlan(2, 2),
+		},
+		{
+			name:      "missing store",
+			plan:      makeTestPlan(1, 5),
+			expectErr: "stores [5] expected on the node but not found",
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			eng := makeTestEngine(t, 1, 1)
+			defer eng.Close()
+			engines := []storage.Engine{eng}
+			ps := NewPlanStore(eng.GetAuxiliaryDir(), eng)
+
+			// Nothing happens if there's no staged plan.
+			require.NoError(t, MaybeApplyPendingRecoveryPlan(ctx, ps, engines, clock))
+			status, err := GetNodeRecoveryStatus(ctx, 1, ps, engines)
+			require.NoError(t, err)
+			require.Equal(t, loqrecoverypb.NodeRecoveryStatus{NodeID: 1}, status)
+
+			require.NoError(t, ps.SavePlan(td.plan))
+			status, err = GetNodeRecoveryStatus(ctx, 1, ps, engines)
+			require.NoError(t, err)
+			require.NotNil(t, status.PendingPlanID)
+			require.Equal(t, td.plan.PlanID, *status.PendingPlanID)
+
+			require.NoError(t, MaybeApplyPendingRecoveryPlan(ctx, ps, engines, clock))
+			status, err = GetNodeRecoveryStatus(ctx, 1, ps, engines)
+			require.NoError(t, err)
+			require.Nil(t, status.PendingPlanID, "plan must be removed after application")
+			require.NotNil(t, status.AppliedPlanID)
+			require.Equal(t, td.plan.PlanID, *status.AppliedPlanID)
+			require.True(t, status.ApplyTimestamp.Equal(clock.Now()),
+				"unexpected application time %s", status.ApplyTimestamp)
+			if td.expectErr == "" {
+				require.Empty(t, status.Error)
+			} else {
+				require.Contains(t, status.Error, td.expectErr)
+			}
+		})
+	}
+}
+
+func TestRangesWithoutQuorum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	makeDesc := func(rangeID roachpb.RangeID, nodeIDs ...roachpb.NodeID) roachpb.RangeDescriptor {
+		desc := roachpb.RangeDescriptor{RangeID: rangeID}
+		for i, nodeID := range nodeIDs {
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				NodeID:    nodeID,
+				StoreID:   roachpb.StoreID(nodeID),
+				ReplicaID: roachpb.ReplicaID(i + 1),
+			})
+		}
+		return desc
+	}
+	// Nodes 1, 2 and 3 are live, nodes 4 and 5 are not.
+	isLive := func(nodeID roachpb.NodeID) bool { return nodeID <= 3 }
+	healthyFirst := makeDesc(1, 1, 2, 4)
+
+	for _, td := range []struct {
+		name       string
+		firstRange *roachpb.RangeDescriptor
+		meta1      []roachpb.RangeDescriptor
+		meta2      []roachpb.RangeDescriptor
+		meta2Err   error
+		// scans is the number of scans of meta ranges expected.
+		scans     int
+		expect    []roachpb.RangeID
+		expectErr string
+	}{
+		{
+			name:       "healthy meta ranges",
+			firstRange: &healthyFirst,
+			meta1:      []roachpb.RangeDescriptor{healthyFirst, makeDesc(2, 1, 2, 3)},
+			meta2:      []roachpb.RangeDescriptor{makeDesc(3, 1, 4, 5), makeDesc(4, 1, 2, 5)},
+			scans:      2,
+			expect:     []roachpb.RangeID{3},
+		},
+		{
+			name:       "first range without quorum",
+			firstRange: func() *roachpb.RangeDescriptor { d := makeDesc(1, 1, 4, 5); return &d }(),
+			scans:      0,
+			expect:     []roachpb.RangeID{1},
+			expectErr:  "first range r1 lost quorum",
+		},
+		{
+			name:       "meta2 range without quorum",
+			firstRange: &healthyFirst,
+			meta1:      []roachpb.RangeDescriptor{healthyFirst, makeDesc(2, 3, 4, 5)},
+			scans:      1,
+			expect:     []roachpb.RangeID{2},
+			expectErr:  "1 meta ranges lost quorum",
+		},
+		{
+			name:       "meta2 scan timeout",
+			firstRange: &healthyFirst,
+			meta1:      []roachpb.RangeDescriptor{healthyFirst, makeDesc(2, 1, 2, 3)},
+			meta2Err:   context.DeadlineExceeded,
+			scans:      2,
+			expect:     []roachpb.RangeID{1, 2},
+			expectErr:  "failed to read range descriptors from meta ranges",
+		},
+		{
+			name:   "unknown first range",
+			meta1:  []roachpb.RangeDescriptor{makeDesc(2, 1, 2, 3)},
+			meta2:  []roachpb.RangeDescriptor{makeDesc(3, 1, 2, 3)},
+			scans:  2,
+			expect: nil,
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			scans := 0
+			ranges, err := rangesWithoutQuorum(ctx, td.firstRange, isLive,
+				func(ctx context.Context, start, end roachpb.Key) ([]roachpb.RangeDescriptor, error) {
+					scans++
+					if start.Equal(keys.Meta1Prefix) {
+						return td.meta1, nil
+					}
+					return td.meta2, td.meta2Err
+				})
+			if td.expectErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, td.expectErr)
+			}
+			var rangeIDs []roachpb.RangeID
+			for _, desc := range ranges {
+				rangeIDs = append(rangeIDs, desc.RangeID)
+			}
+			require.Equal(t, td.expect, rangeIDs)
+			require.Equal(t, td.scans, scans)
+		})
+	}
+}
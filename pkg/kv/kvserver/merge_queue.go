@@ -16,6 +16,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -183,8 +184,8 @@ func (rangeMergePurgatoryError) PurgatoryErrorMarker() {}
 var _ PurgatoryError = rangeMergePurgatoryError{}
 
 func (mq *mergeQueue) requestRangeStats(
-	ctx context.Context, key roachpb.Key,
-) (desc *roachpb.RangeDescriptor, stats enginepb.MVCCStats, qps float64, qpsOK bool, err error) {
+	ctx context.Context, key roachpb.Key, objective SplitObjective,
+) (desc *roachpb.RangeDescriptor, stats enginepb.MVCCStats, load float64, loadOK bool, err error) {
 
 	var ba roachpb.BatchRequest
 	ba.Add(&roachpb.RangeStatsRequest{
@@ -199,14 +200,20 @@ func (mq *mergeQueue) requestRangeStats(
 
 	desc = &res.RangeInfo.Desc
 	stats = res.MVCCStats
-	if res.MaxQueriesPerSecondSet {
-		qps = res.MaxQueriesPerSecond
-		qpsOK = qps >= 0
-	} else {
-		qps = res.DeprecatedLastQueriesPerSecond
-		qpsOK = true
+	switch objective {
+	case SplitQPS:
+		if res.MaxQueriesPerSecondSet {
+			load = res.MaxQueriesPerSecond
+			loadOK = load >= 0
+		} else {
+			load = res.DeprecatedLastQueriesPerSecond
+			loadOK = true
+		}
+	case SplitCPU:
+		load = res.MaxCPUPerSecond
+		loadOK = load >= 0
 	}
-	return desc, stats, qps, qpsOK, nil
+	return desc, stats, load, loadOK, nil
 }
 
 func (mq *mergeQueue) process(
@@ -219,7 +226,15 @@ func (mq *mergeQueue) process(
 
 	lhsDesc := lhsRepl.Desc()
 	lhsStats := lhsRepl.GetMVCCStats()
-	lhsQPS, lhsQPSOK := lhsRepl.GetMaxSplitQPS(ctx)
+	objective := lhsRepl.SplitByLoadObjective()
+	var lhsLoad float64
+	var lhsLoadOK bool
+	switch objective {
+	case SplitQPS:
+		lhsLoad, lhsLoadOK = lhsRepl.GetMaxSplitQPS(ctx)
+	case SplitCPU:
+		lhsLoad, lhsLoadOK = lhsRepl.GetMaxSplitCPU(ctx)
+	}
 	minBytes := lhsRepl.GetMinBytes()
 	if lhsStats.Total() >= minBytes {
 		log.VEventf(ctx, 2, "skipping merge: LHS meets minimum size threshold %d with %d bytes",
@@ -227,7 +242,7 @@ func (mq *mergeQueue) process(
 		return false, nil
 	}
 
-	rhsDesc, rhsStats, rhsQPS, rhsQPSOK, err := mq.requestRangeStats(ctx, lhsDesc.EndKey.AsRawKey())
+	rhsDesc, rhsStats, rhsLoad, rhsLoadOK, err := mq.requestRangeStats(ctx, lhsDesc.EndKey.AsRawKey(), objective)
 	if err != nil {
 		return false, err
 	}
@@ -253,41 +268,48 @@ func (mq *mergeQueue) process(
 	mergedStats := lhsStats
 	mergedStats.Add(rhsStats)
 
-	var mergedQPS float64
+	var mergedLoad float64
 	if lhsRepl.SplitByLoadEnabled() {
 		// When load is a consideration for splits and, by extension, merges, the
 		// mergeQueue is fairly conservative. In an effort to avoid thrashing and to
 		// avoid overreacting to temporary fluctuations in load, the mergeQueue will
 		// only consider a merge when the combined load across the RHS and LHS
-		// ranges is below half the threshold required to split a range due to load.
-		// Furthermore, to ensure that transient drops in load do not trigger range
-		// merges, the mergeQueue will only consider a merge when it deems the
-		// maximum qps measurement from both sides to be sufficiently stable and
-		// reliable, meaning that it was a maximum measurement over some extended
-		// period of time.
-		if !lhsQPSOK {
-			log.VEventf(ctx, 2, "skipping merge: LHS QPS measurement not yet reliable")
+		// ranges is below a fraction (by default half) of the threshold required
+		// to split a range due to load. Furthermore, to ensure that transient
+		// drops in load do not trigger range merges, the mergeQueue will only
+		// consider a merge when it deems the maximum load measurement from both
+		// sides to be sufficiently stable and reliable, meaning that it was a
+		// maximum measurement over some extended period of time.
+		if objective == SplitCPU &&
+			!mq.store.ClusterSettings().Version.IsActive(ctx, clusterversion.SplitByLoadCPU) {
+			// Nodes running an older version do not report the CPU time of the
+			// RHS, which would look like an idle range.
+			log.VEventf(ctx, 2, "skipping merge: CPU measurements not yet reported by all nodes")
+			return false, nil
+		}
+		if !lhsLoadOK {
+			log.VEventf(ctx, 2, "skipping merge: LHS %s measurement not yet reliable", objective)
 			return false, nil
 		}
-		if !rhsQPSOK {
-			log.VEventf(ctx, 2, "skipping merge: RHS QPS measurement not yet reliable")
+		if !rhsLoadOK {
+			log.VEventf(ctx, 2, "skipping merge: RHS %s measurement not yet reliable", objective)
 			return false, nil
 		}
-		mergedQPS = lhsQPS + rhsQPS
+		mergedLoad = lhsLoad + rhsLoad
 	}
 
 	// Check if the merged range would need to be split, if so, skip merge.
 	// Use a lower threshold for load based splitting so we don't find ourselves
 	// in a situation where we keep merging ranges that would be split soon after
 	// by a small increase in load.
-	conservativeLoadBasedSplitThreshold := 0.5 * lhsRepl.SplitByLoadQPSThreshold()
+	conservativeLoadBasedSplitThreshold := lhsRepl.SplitByLoadMergeThreshold()
 	shouldSplit, _ := shouldSplitRange(ctx, mergedDesc, mergedStats,
 		lhsRepl.GetMaxBytes(), lhsRepl.shouldBackpressureWrites(), confReader)
-	if shouldSplit || mergedQPS >= conservativeLoadBasedSplitThreshold {
+	if shouldSplit || mergedLoad >= conservativeLoadBasedSplitThreshold {
 		log.VEventf(ctx, 2,
 			"skipping merge to avoid thrashing: merged range %s may split "+
-				"(estimated size, estimated QPS: %d, %v)",
-			mergedDesc, mergedStats.Total(), mergedQPS)
+				"(estimated size, estimated load: %d, %s)",
+			mergedDesc, mergedStats.Total(), objective.Format(mergedLoad))
 		return false, nil
 	}
 
@@ -383,15 +405,15 @@ func (mq *mergeQueue) process(
 	}
 
 	log.VEventf(ctx, 2, "merging to produce range: %s-%s", mergedDesc.StartKey, mergedDesc.EndKey)
-	reason := fmt.Sprintf("lhs+rhs has (size=%s+%s=%s qps=%.2f+%.2f=%.2fqps) below threshold (size=%s, qps=%.2f)",
+	reason := fmt.Sprintf("lhs+rhs has (size=%s+%s=%s load=%s+%s=%s) below threshold (size=%s, load=%s)",
 		humanizeutil.IBytes(lhsStats.Total()),
 		humanizeutil.IBytes(rhsStats.Total()),
 		humanizeutil.IBytes(mergedStats.Total()),
-		lhsQPS,
-		rhsQPS,
-		mergedQPS,
+		objective.Format(lhsLoad),
+		objective.Format(rhsLoad),
+		objective.Format(mergedLoad),
 		humanizeutil.IBytes(minBytes),
-		conservativeLoadBasedSplitThreshold,
+		objective.Format(conservativeLoadBasedSplitThreshold),
 	)
 	_, pErr := lhsRepl.AdminMerge(ctx, roachpb.AdminMergeRequest{
 		RequestHeader: roachpb.RequestHeader{Key: lhsRepl.Desc().StartKey.AsRawKey()},
@@ -422,8 +444,8 @@ func (mq *mergeQueue) process(
 	// Adjust the splitter to account for the additional load from the RHS. We
 	// could just Reset the splitter, but then we'd need to wait out a full
 	// measurement period (default of 5m) before merging this range again.
-	if mergedQPS != 0 {
-		lhsRepl.loadBasedSplitter.RecordMax(mq.store.Clock().PhysicalTime(), mergedQPS)
+	if mergedLoad != 0 {
+		lhsRepl.loadBasedSplitter.RecordMax(mq.store.Clock().PhysicalTime(), mergedLoad)
 	}
 	return true, nil
 }
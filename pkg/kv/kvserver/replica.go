@@ -19,10 +19,12 @@ import (
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
@@ -841,7 +843,9 @@ func (r *Replica) descRLocked() *roachpb.RangeDescriptor {
 //
 // NOTE: an exported version of this method which does not require the replica
 // lock exists in helpers_test.go. Move here if needed.
-func (r *Replica) closedTimestampPolicyRLocked() roachpb.RangeClosedTimestampPolicy {
+func (r *Replica) closedTimestampPolicyRLocked(
+	ctx context.Context,
+) roachpb.RangeClosedTimestampPolicy {
 	if r.mu.conf.GlobalReads {
 		if !r.mu.state.Desc.ContainsKey(roachpb.RKey(keys.NodeLivenessPrefix)) {
 			return roachpb.LEAD_FOR_GLOBAL_READS
@@ -852,6 +856,11 @@ func (r *Replica) closedTimestampPolicyRLocked() roachpb.RangeClosedTimestampPol
 		// which perform a 1PC transaction with a commit trigger and can not
 		// tolerate being pushed into the future.
 	}
+	if conf := &r.mu.conf; conf.NumReadReplicas > 0 && conf.ReadReplicaLagTarget > 0 &&
+		conf.ReadReplicaLagTarget < closedts.TargetDuration.Get(&r.ClusterSettings().SV) &&
+		r.ClusterSettings().Version.IsActive(ctx, clusterversion.ReadReplicas) {
+		return roachpb.LAG_BY_READ_REPLICA_TARGET
+	}
 	return roachpb.LAG_BY_CLUSTER_SETTING
 }
 
@@ -968,7 +977,7 @@ func (r *Replica) GetRangeInfo(ctx context.Context) roachpb.RangeInfo {
 	defer r.mu.RUnlock()
 	desc := r.descRLocked()
 	l, _ /* nextLease */ := r.getLeaseRLocked()
-	closedts := r.closedTimestampPolicyRLocked()
+	closedts := r.closedTimestampPolicyRLocked(ctx)
 
 	// Sanity check the lease.
 	if !l.Empty() {
@@ -1141,14 +1150,35 @@ func (r *Replica) SetMVCCStatsForTesting(stats *enginepb.MVCCStats) {
 
 // GetMaxSplitQPS returns the Replica's maximum queries/s request rate over a
 // configured measurement period. If the Replica has not been recording QPS for
-// at least an entire measurement period, the method will return false.
+// at least an entire measurement period, or load based splitting is not driven
+// by QPS, the method will return false.
 //
 // NOTE: This should only be used for load based splitting, only
 // works when the load based splitting cluster setting is enabled.
 //
 // Use QueriesPerSecond() for current QPS stats for all other purposes.
 func (r *Replica) GetMaxSplitQPS(ctx context.Context) (float64, bool) {
-	return r.loadBasedSplitter.MaxQPS(ctx, r.Clock().PhysicalTime())
+	if r.SplitByLoadObjective() != SplitQPS {
+		return 0, false
+	}
+	return r.loadBasedSplitter.MaxStat(ctx, r.Clock().PhysicalTime())
+}
+
+// GetMaxSplitCPU returns the Replica's maximum CPU time spent evaluating
+// requests per second, in nanoseconds, over a configured measurement period.
+// If the Replica has not been recording CPU time for at least an entire
+// measurement period, or load based splitting is not driven by CPU, the method
+// will return false.
+//
+// NOTE: This should only be used for load based splitting, only
+// works when the load based splitting cluster setting is enabled.
+//
+// Use CPUTimePerSecond() for current CPU stats for all other purposes.
+func (r *Replica) GetMaxSplitCPU(ctx context.Context) (float64, bool) {
+	if r.SplitByLoadObjective() != SplitCPU {
+		return 0, false
+	}
+	return r.loadBasedSplitter.MaxStat(ctx, r.Clock().PhysicalTime())
 }
 
 // GetLastSplitQPS returns the Replica's most recent queries/s request rate.
@@ -1158,7 +1188,10 @@ func (r *Replica) GetMaxSplitQPS(ctx context.Context) (float64, bool) {
 //
 // Use QueriesPerSecond() for current QPS stats for all other purposes.
 func (r *Replica) GetLastSplitQPS(ctx context.Context) float64 {
-	return r.loadBasedSplitter.LastQPS(ctx, r.Clock().PhysicalTime())
+	if r.SplitByLoadObjective() != SplitQPS {
+		return 0
+	}
+	return r.loadBasedSplitter.LastStat(ctx, r.Clock().PhysicalTime())
 }
 
 // ContainsKey returns whether this range contains the specified key.
@@ -1279,7 +1312,7 @@ func (r *Replica) State(ctx context.Context) kvserverpb.RangeInfo {
 	if r.mu.tenantID != (roachpb.TenantID{}) {
 		ri.TenantID = r.mu.tenantID.ToUint64()
 	}
-	ri.ClosedTimestampPolicy = r.closedTimestampPolicyRLocked()
+	ri.ClosedTimestampPolicy = r.closedTimestampPolicyRLocked(ctx)
 	r.sideTransportClosedTimestamp.mu.Lock()
 	ri.ClosedTimestampSideTransportInfo.ReplicaClosed = r.sideTransportClosedTimestamp.mu.cur.ts
 	ri.ClosedTimestampSideTransportInfo.ReplicaLAI = r.sideTransportClosedTimestamp.mu.cur.lai
@@ -1585,7 +1618,7 @@ func (r *Replica) checkSpanInRangeRLocked(ctx context.Context, rspan roachpb.RSp
 		return nil
 	}
 	return roachpb.NewRangeKeyMismatchErrorWithCTPolicy(
-		ctx, rspan.Key.AsRawKey(), rspan.EndKey.AsRawKey(), desc, r.mu.state.Lease, r.closedTimestampPolicyRLocked())
+		ctx, rspan.Key.AsRawKey(), rspan.EndKey.AsRawKey(), desc, r.mu.state.Lease, r.closedTimestampPolicyRLocked(ctx))
 }
 
 // checkTSAboveGCThresholdRLocked returns an error if a request (identified by
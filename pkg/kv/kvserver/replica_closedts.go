@@ -12,6 +12,7 @@ package kvserver
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
@@ -55,8 +56,11 @@ func (r *Replica) BumpSideTransportClosed(
 	}
 
 	lai := ctpb.LAI(r.mu.state.LeaseAppliedIndex)
-	policy := r.closedTimestampPolicyRLocked()
+	policy := r.closedTimestampPolicyRLocked(ctx)
 	target := targetByPolicy[policy]
+	if policy == roachpb.LAG_BY_READ_REPLICA_TARGET {
+		res.ReadReplicaLagTarget = r.mu.conf.ReadReplicaLagTarget
+	}
 	st := r.leaseStatusForRequestRLocked(ctx, now, hlc.Timestamp{} /* reqTS */)
 	// We need to own the lease but note that stasis (LeaseState_UNUSABLE) doesn't
 	// matter.
@@ -116,17 +120,32 @@ func (r *Replica) BumpSideTransportClosed(
 // closedTimestampTargetRLocked computes the timestamp we'd like to close for
 // this range. Note that we might not be able to ultimately close this timestamp
 // if there are requests in flight.
-func (r *Replica) closedTimestampTargetRLocked() hlc.Timestamp {
+func (r *Replica) closedTimestampTargetRLocked(ctx context.Context) hlc.Timestamp {
 	return closedts.TargetForPolicy(
 		r.Clock().NowAsClockTimestamp(),
 		r.Clock().MaxOffset(),
-		closedts.TargetDuration.Get(&r.ClusterSettings().SV),
+		r.closedTimestampLagTargetRLocked(ctx),
 		closedts.LeadForGlobalReadsOverride.Get(&r.ClusterSettings().SV),
 		closedts.SideTransportCloseInterval.Get(&r.ClusterSettings().SV),
-		r.closedTimestampPolicyRLocked(),
+		r.closedTimestampPolicyRLocked(ctx),
 	)
 }
 
+// closedTimestampLagTargetRLocked returns how far behind present time the range
+// aims to close timestamps when lagging present time. Ranges with dedicated
+// read replicas may ask for a tighter lag than the cluster setting through
+// their `read_replica_lag_target`, which gives them the
+// LAG_BY_READ_REPLICA_TARGET policy.
+//
+// NB: The side transport closes timestamps for all the ranges of the policy at
+// once, lagging by the tightest of their targets. See Sender.publish.
+func (r *Replica) closedTimestampLagTargetRLocked(ctx context.Context) time.Duration {
+	if r.closedTimestampPolicyRLocked(ctx) == roachpb.LAG_BY_READ_REPLICA_TARGET {
+		return r.mu.conf.ReadReplicaLagTarget
+	}
+	return closedts.TargetDuration.Get(&r.ClusterSettings().SV)
+}
+
 // ForwardSideTransportClosedTimestamp forwards the side-transport closed
 // timestamp. It is called by the closed timestamp side-transport receiver.
 func (r *Replica) ForwardSideTransportClosedTimestamp(
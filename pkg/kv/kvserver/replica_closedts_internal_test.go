@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -574,6 +575,79 @@ func TestReplicaClosedTimestamp(t *testing.T) {
 	}
 }
 
+// TestReadReplicaLagTarget checks that ranges with read replicas and a
+// read_replica_lag_target tighter than the cluster setting get the
+// LAG_BY_READ_REPLICA_TARGET closed timestamp policy, lag by that target, and
+// report it to the closed timestamp side transport.
+func TestReadReplicaLagTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	var tc testContext
+	tc.Start(ctx, t, stopper)
+	clusterTarget := closedts.TargetDuration.Get(&tc.store.ClusterSettings().SV)
+
+	for _, test := range []struct {
+		name            string
+		numReadReplicas int32
+		lagTarget       time.Duration
+		expPolicy       roachpb.RangeClosedTimestampPolicy
+		expLag          time.Duration
+	}{
+		{
+			name:      "no read replicas",
+			lagTarget: clusterTarget / 3,
+			expPolicy: roachpb.LAG_BY_CLUSTER_SETTING,
+			expLag:    clusterTarget,
+		},
+		{
+			name:            "no lag target",
+			numReadReplicas: 1,
+			expPolicy:       roachpb.LAG_BY_CLUSTER_SETTING,
+			expLag:          clusterTarget,
+		},
+		{
+			name:            "looser lag target",
+			numReadReplicas: 1,
+			lagTarget:       clusterTarget * 2,
+			expPolicy:       roachpb.LAG_BY_CLUSTER_SETTING,
+			expLag:          clusterTarget,
+		},
+		{
+			name:            "tighter lag target",
+			numReadReplicas: 1,
+			lagTarget:       clusterTarget / 3,
+			expPolicy:       roachpb.LAG_BY_READ_REPLICA_TARGET,
+			expLag:          clusterTarget / 3,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := tc.repl.SpanConfig()
+			conf.NumReadReplicas = test.numReadReplicas
+			conf.ReadReplicaLagTarget = test.lagTarget
+			tc.repl.SetSpanConfig(conf)
+
+			tc.repl.mu.RLock()
+			policy := tc.repl.closedTimestampPolicyRLocked(ctx)
+			lag := tc.repl.closedTimestampLagTargetRLocked(ctx)
+			tc.repl.mu.RUnlock()
+			require.Equal(t, test.expPolicy, policy)
+			require.Equal(t, test.expLag, lag)
+			require.Equal(t, test.expPolicy, tc.repl.GetRangeInfo(ctx).ClosedTimestampPolicy)
+
+			var targets [roachpb.MAX_CLOSED_TIMESTAMP_POLICY]hlc.Timestamp
+			res := tc.repl.BumpSideTransportClosed(ctx, tc.Clock().NowAsClockTimestamp(), targets)
+			if test.expPolicy == roachpb.LAG_BY_READ_REPLICA_TARGET {
+				require.Equal(t, test.lagTarget, res.ReadReplicaLagTarget)
+			} else {
+				require.Zero(t, res.ReadReplicaLagTarget)
+			}
+		})
+	}
+}
+
 // TestQueryResolvedTimestamp verifies that QueryResolvedTimestamp requests
 // behave as expected.
 func TestQueryResolvedTimestamp(t *testing.T) {
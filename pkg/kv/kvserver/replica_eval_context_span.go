@@ -135,6 +135,12 @@ func (rec SpanSetReplicaEvalContext) GetMaxSplitQPS(ctx context.Context) (float6
 	return rec.i.GetMaxSplitQPS(ctx)
 }
 
+// GetMaxSplitCPU returns the Replica's maximum CPU time per second for splitting
+// and merging purposes.
+func (rec SpanSetReplicaEvalContext) GetMaxSplitCPU(ctx context.Context) (float64, bool) {
+	return rec.i.GetMaxSplitCPU(ctx)
+}
+
 // GetLastSplitQPS returns the Replica's most recent queries/s rate for
 // splitting and merging purposes.
 func (rec SpanSetReplicaEvalContext) GetLastSplitQPS(ctx context.Context) float64 {
@@ -95,7 +95,8 @@ func newUnloadedReplica(
 	r.mu.quiescent = true
 	r.mu.conf = store.cfg.DefaultSpanConfig
 	split.Init(&r.loadBasedSplitter, rand.Intn, func() float64 {
-		return float64(SplitByLoadQPSThreshold.Get(&store.cfg.Settings.SV))
+		st := store.cfg.Settings
+		return splitByLoadThreshold(st, splitByLoadObjective(st))
 	}, func() time.Duration {
 		return kvserverbase.SplitByLoadMergeDelay.Get(&store.cfg.Settings.SV)
 	}, store.metrics.LoadSplitterMetrics)
@@ -18,12 +18,13 @@ import (
 // ReplicaLoad tracks a sliding window of throughput on a replica. By default,
 // there are 6, 5 minute sliding windows.
 type ReplicaLoad struct {
-	batchRequests *replicastats.ReplicaStats
-	requests      *replicastats.ReplicaStats
-	writeKeys     *replicastats.ReplicaStats
-	readKeys      *replicastats.ReplicaStats
-	writeBytes    *replicastats.ReplicaStats
-	readBytes     *replicastats.ReplicaStats
+	batchRequests   *replicastats.ReplicaStats
+	requests        *replicastats.ReplicaStats
+	writeKeys       *replicastats.ReplicaStats
+	readKeys        *replicastats.ReplicaStats
+	writeBytes      *replicastats.ReplicaStats
+	readBytes       *replicastats.ReplicaStats
+	requestCPUNanos *replicastats.ReplicaStats
 }
 
 // NewReplicaLoad returns a new ReplicaLoad, which may be used to track the
@@ -34,12 +35,13 @@ func NewReplicaLoad(clock *hlc.Clock, getNodeLocality replicastats.LocalityOracl
 	// request count. Maintaining more than one bucket for client requests
 	// increases the memory footprint O(localities).
 	return &ReplicaLoad{
-		batchRequests: replicastats.NewReplicaStats(clock, getNodeLocality),
-		requests:      replicastats.NewReplicaStats(clock, nil),
-		writeKeys:     replicastats.NewReplicaStats(clock, nil),
-		readKeys:      replicastats.NewReplicaStats(clock, nil),
-		writeBytes:    replicastats.NewReplicaStats(clock, nil),
-		readBytes:     replicastats.NewReplicaStats(clock, nil),
+		batchRequests:   replicastats.NewReplicaStats(clock, getNodeLocality),
+		requests:        replicastats.NewReplicaStats(clock, nil),
+		writeKeys:       replicastats.NewReplicaStats(clock, nil),
+		readKeys:        replicastats.NewReplicaStats(clock, nil),
+		writeBytes:      replicastats.NewReplicaStats(clock, nil),
+		readBytes:       replicastats.NewReplicaStats(clock, nil),
+		requestCPUNanos: replicastats.NewReplicaStats(clock, nil),
 	}
 }
 
@@ -52,6 +54,7 @@ func (rl *ReplicaLoad) split(other *ReplicaLoad) {
 	rl.readKeys.SplitRequestCounts(other.readKeys)
 	rl.writeBytes.SplitRequestCounts(other.writeBytes)
 	rl.readBytes.SplitRequestCounts(other.readBytes)
+	rl.requestCPUNanos.SplitRequestCounts(other.requestCPUNanos)
 }
 
 // merge will combine the tracked load in other, into the calling struct.
@@ -62,6 +65,7 @@ func (rl *ReplicaLoad) merge(other *ReplicaLoad) {
 	rl.readKeys.MergeRequestCounts(other.readKeys)
 	rl.writeBytes.MergeRequestCounts(other.writeBytes)
 	rl.readBytes.MergeRequestCounts(other.readBytes)
+	rl.requestCPUNanos.MergeRequestCounts(other.requestCPUNanos)
 }
 
 // reset will clear all recorded history.
@@ -72,4 +76,5 @@ func (rl *ReplicaLoad) reset() {
 	rl.readKeys.ResetRequestCounts()
 	rl.writeBytes.ResetRequestCounts()
 	rl.readBytes.ResetRequestCounts()
+	rl.requestCPUNanos.ResetRequestCounts()
 }
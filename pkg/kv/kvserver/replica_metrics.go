@@ -331,6 +331,13 @@ func (r *Replica) ReadBytesPerSecond() float64 {
 	return rbps
 }
 
+// CPUTimePerSecond returns the range's average CPU time spent evaluating
+// requests per second, in nanoseconds.
+func (r *Replica) CPUTimePerSecond() float64 {
+	cpus, _ := r.loadStats.requestCPUNanos.AverageRatePerSecond()
+	return cpus
+}
+
 func (r *Replica) needsSplitBySizeRLocked() bool {
 	exceeded, _ := r.exceedsMultipleOfSplitSizeRLocked(1)
 	return exceeded
@@ -141,7 +141,7 @@ type proposer interface {
 	firstIndex() uint64
 	leaseAppliedIndex() uint64
 	enqueueUpdateCheck()
-	closedTimestampTarget() hlc.Timestamp
+	closedTimestampTarget(ctx context.Context) hlc.Timestamp
 
 	// The following require the proposer to hold an exclusive lock.
 	withGroupLocked(func(proposerRaft) error) error
@@ -405,7 +405,7 @@ func (b *propBuf) FlushLockedWithRaftGroup(
 
 	// Compute the closed timestamp target, which will be used to assign a closed
 	// timestamp to all proposals in this batch.
-	closedTSTarget := b.p.closedTimestampTarget()
+	closedTSTarget := b.p.closedTimestampTarget(ctx)
 
 	// Remember the first error that we see when proposing the batch. We don't
 	// immediately return this error because we want to finish clearing out the
@@ -1153,8 +1153,8 @@ func (rp *replicaProposer) enqueueUpdateCheck() {
 	rp.store.enqueueRaftUpdateCheck(rp.RangeID)
 }
 
-func (rp *replicaProposer) closedTimestampTarget() hlc.Timestamp {
-	return (*Replica)(rp).closedTimestampTargetRLocked()
+func (rp *replicaProposer) closedTimestampTarget(ctx context.Context) hlc.Timestamp {
+	return (*Replica)(rp).closedTimestampTargetRLocked(ctx)
 }
 
 func (rp *replicaProposer) withGroupLocked(fn func(raftGroup proposerRaft) error) error {
@@ -146,7 +146,7 @@ func (t *testProposer) enqueueUpdateCheck() {
 	t.enqueued++
 }
 
-func (t *testProposer) closedTimestampTarget() hlc.Timestamp {
+func (t *testProposer) closedTimestampTarget(context.Context) hlc.Timestamp {
 	if t.clock == nil {
 		return hlc.Timestamp{}
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/circuit"
+	"github.com/cockroachdb/cockroach/pkg/util/grunning"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -405,6 +406,19 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 			r.concMgr.FinishReq(g)
 		}
 	}()
+	// The batch is recorded for load-based splitting once it has been executed,
+	// since only then is the CPU time it took known.
+	startCPU := grunning.Time()
+	var recordForSplit bool
+	defer func() {
+		cpu := grunning.Difference(startCPU, grunning.Time())
+		if r.loadStats != nil {
+			r.loadStats.requestCPUNanos.RecordCount(float64(cpu.Nanoseconds()), 0)
+		}
+		if recordForSplit {
+			r.recordBatchForLoadBasedSplitting(ctx, ba, cpu)
+		}
+	}()
 	pp := poison.Policy_Error
 	if r.signallerForBatch(ba).C() == nil {
 		// The request wishes to ignore the circuit breaker, i.e. attempt to propose
@@ -433,7 +447,7 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 
 		// Handle load-based splitting, if necessary.
 		if first {
-			r.recordBatchForLoadBasedSplitting(ctx, ba, latchSpans)
+			recordForSplit = true
 		}
 
 		// Acquire latches to prevent overlapping requests from executing until
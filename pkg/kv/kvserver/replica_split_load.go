@@ -12,11 +12,17 @@ package kvserver
 
 import (
 	"context"
+	"fmt"
+	"time"
 
-	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/grunning"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
 )
 
 // SplitByLoadEnabled wraps "kv.range_split.by_load_enabled".
@@ -35,9 +41,127 @@ var SplitByLoadQPSThreshold = settings.RegisterIntSetting(
 	2500, // 2500 req/s
 ).WithPublic()
 
-// SplitByLoadQPSThreshold returns the QPS request rate for a given replica.
-func (r *Replica) SplitByLoadQPSThreshold() float64 {
-	return float64(SplitByLoadQPSThreshold.Get(&r.store.cfg.Settings.SV))
+// SplitByLoadCPUThreshold wraps "kv.range_split.load_cpu_threshold".
+var SplitByLoadCPUThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"kv.range_split.load_cpu_threshold",
+	"the CPU time per second over which, the range becomes a candidate for load based splitting",
+	250*time.Millisecond,
+	settings.PositiveDuration,
+).WithPublic()
+
+// SplitByLoadObjectiveSetting wraps "kv.range_split.by_load_objective".
+var SplitByLoadObjectiveSetting = settings.RegisterEnumSetting(
+	settings.TenantWritable,
+	"kv.range_split.by_load_objective",
+	"the load which load based splitting and merging is driven by: `qps` uses "+
+		"the requests per second served by a range and `kv.range_split.load_qps_threshold`, "+
+		"`cpu` uses the CPU time per second spent serving the requests to a range and "+
+		"`kv.range_split.load_cpu_threshold`; `cpu` falls back to `qps` on platforms "+
+		"which do not support measuring the CPU time of goroutines",
+	"qps",
+	map[int64]string{
+		int64(SplitQPS): "qps",
+		int64(SplitCPU): "cpu",
+	},
+).WithPublic()
+
+// SplitByLoadMergeThresholdFraction wraps
+// "kv.range_split.by_load_merge_threshold_fraction".
+var SplitByLoadMergeThresholdFraction = settings.RegisterFloatSetting(
+	settings.TenantWritable,
+	"kv.range_split.by_load_merge_threshold_fraction",
+	"the fraction of the load based splitting threshold which the combined load of "+
+		"two ranges must stay below for them to be merged; the gap between the two "+
+		"thresholds prevents ranges from being merged only to be split again",
+	0.5,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("cannot be set to a value outside of (0, 1]: %f", v)
+		}
+		return nil
+	},
+).WithPublic()
+
+// SplitObjective is the load which load based splitting, and by extension
+// merging, is driven by. The load of a replica is measured as the rate per
+// second of the objective's stat.
+type SplitObjective int64
+
+const (
+	// SplitQPS is the number of requests served by the replica.
+	SplitQPS SplitObjective = iota
+	// SplitCPU is the CPU time, in nanoseconds, spent evaluating the requests
+	// served by the replica.
+	SplitCPU
+)
+
+// String returns a human readable name for the split objective.
+func (o SplitObjective) String() string {
+	switch o {
+	case SplitQPS:
+		return "qps"
+	case SplitCPU:
+		return "cpu"
+	default:
+		panic(errors.AssertionFailedf("unknown split objective %d", o))
+	}
+}
+
+// Format returns a human readable string for the rate of the objective's stat.
+func (o SplitObjective) Format(rate float64) string {
+	switch o {
+	case SplitQPS:
+		return fmt.Sprintf("%.2f qps", rate)
+	case SplitCPU:
+		return fmt.Sprintf("%s cpu/s", time.Duration(rate))
+	default:
+		panic(errors.AssertionFailedf("unknown split objective %d", o))
+	}
+}
+
+// splitByLoadObjective returns the load based splitting objective in effect.
+// The CPU time of requests can only be measured on platforms supporting
+// grunning.
+func splitByLoadObjective(st *cluster.Settings) SplitObjective {
+	objective := SplitObjective(SplitByLoadObjectiveSetting.Get(&st.SV))
+	if objective == SplitCPU && !grunning.Supported() {
+		return SplitQPS
+	}
+	return objective
+}
+
+// splitByLoadThreshold returns the rate of the stat of the objective over
+// which a range becomes a candidate for load based splitting.
+func splitByLoadThreshold(st *cluster.Settings, objective SplitObjective) float64 {
+	switch objective {
+	case SplitQPS:
+		return float64(SplitByLoadQPSThreshold.Get(&st.SV))
+	case SplitCPU:
+		return float64(SplitByLoadCPUThreshold.Get(&st.SV).Nanoseconds())
+	default:
+		panic(errors.AssertionFailedf("unknown split objective %d", objective))
+	}
+}
+
+// SplitByLoadObjective returns the load which load based splitting is driven
+// by. Although this is a method of *Replica, the configuration is really
+// global, shared across all stores.
+func (r *Replica) SplitByLoadObjective() SplitObjective {
+	return splitByLoadObjective(r.store.cfg.Settings)
+}
+
+// SplitByLoadThreshold returns the rate of the load based splitting objective's
+// stat over which the replica becomes a candidate for load based splitting.
+func (r *Replica) SplitByLoadThreshold() float64 {
+	return splitByLoadThreshold(r.store.cfg.Settings, r.SplitByLoadObjective())
+}
+
+// SplitByLoadMergeThreshold returns the rate of the load based splitting
+// objective's stat which the combined load of two ranges must stay below for
+// them to be merged.
+func (r *Replica) SplitByLoadMergeThreshold() float64 {
+	return SplitByLoadMergeThresholdFraction.Get(&r.store.cfg.Settings.SV) * r.SplitByLoadThreshold()
 }
 
 // SplitByLoadEnabled returns whether load based splitting is enabled.
@@ -48,16 +172,31 @@ func (r *Replica) SplitByLoadEnabled() bool {
 		!r.store.TestingKnobs().DisableLoadBasedSplitting
 }
 
-// recordBatchForLoadBasedSplitting records the batch's spans to be considered
-// for load based splitting.
+// recordBatchForLoadBasedSplitting records the batch, which took the given CPU
+// time to evaluate, to be considered for load based splitting.
 func (r *Replica) recordBatchForLoadBasedSplitting(
-	ctx context.Context, ba *roachpb.BatchRequest, spans *spanset.SpanSet,
+	ctx context.Context, ba *roachpb.BatchRequest, cpu time.Duration,
 ) {
 	if !r.SplitByLoadEnabled() {
 		return
 	}
-	shouldInitSplit := r.loadBasedSplitter.Record(ctx, timeutil.Now(), len(ba.Requests), func() roachpb.Span {
-		return spans.BoundarySpan(spanset.SpanGlobal)
+	var stat int
+	switch r.SplitByLoadObjective() {
+	case SplitQPS:
+		stat = len(ba.Requests)
+	case SplitCPU:
+		stat = int(cpu.Nanoseconds())
+	}
+	shouldInitSplit := r.loadBasedSplitter.Record(ctx, timeutil.Now(), stat, func() roachpb.Span {
+		// The span is only needed while looking for a split key, so it is
+		// computed lazily. The latch spans of the batch may already have been
+		// released at this point, so the span is derived from its requests.
+		rSpan, err := keys.Range(ba.Requests)
+		if err != nil {
+			log.VEventf(ctx, 2, "unable to determine span of batch for load based splitting: %v", err)
+			return roachpb.Span{}
+		}
+		return rSpan.AsRawSpanWithNoLocals()
 	})
 	if shouldInitSplit {
 		r.store.splitQueue.MaybeAddAsync(ctx, r, r.store.Clock().NowAsClockTimestamp())
@@ -289,7 +289,7 @@ func TestMultiSSTWriterInitSST(t *testing.T) {
 	keySpans := rditer.MakeReplicatedKeySpans(&desc)
 
 	msstw, err := newMultiSSTWriter(
-		ctx, cluster.MakeTestingClusterSettings(), scratch, keySpans, 0,
+		ctx, cluster.MakeTestingClusterSettings(), scratch, keySpans, 0, roachpb.SSTOptions{},
 	)
 	require.NoError(t, err)
 	_, err = msstw.Finish(ctx)
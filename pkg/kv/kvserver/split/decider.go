@@ -25,33 +25,35 @@ import (
 
 const minSplitSuggestionInterval = time.Minute
 const minNoSplitKeyLoggingMetricsInterval = time.Minute
-const minQueriesPerSecondSampleDuration = time.Second
+const minStatSampleDuration = time.Second
 
-// A Decider collects measurements about the activity (measured in qps) on a
-// Replica and, assuming that qps thresholds are exceeded, tries to determine a
+// A Decider collects measurements about the activity on a Replica and, assuming
+// that thresholds are exceeded, tries to determine a
 // split key that would approximately result in halving the load on each of the
 // resultant ranges. Similarly, these measurements are used to determine when a
 // range is serving sufficiently little load, such that it should be allowed to
 // merge with its left or right hand neighbor.
 //
-// Operations should call `Record` with a current timestamp. Operation counts
-// are aggregated over a second and a QPS is computed.
+// The activity is measured in a stat chosen by the caller, such as the number
+// of requests (QPS) or the CPU time spent serving them. Operations should call
+// `Record` with a current timestamp and their contribution to the stat. The
+// contributions are aggregated over a second and a per-second rate is computed.
 //
-// If the QPS is above a threshold, a split finder is instantiated and the spans
+// If the rate is above a threshold, a split finder is instantiated and the spans
 // supplied to Record are sampled for a duration (on the order of ten seconds).
 // Assuming that load consistently remains over threshold, and the workload
 // touches a diverse enough set of keys to benefit from a split, sampling will
 // eventually instruct a caller of Record to carry out a split. When the split
 // is initiated, it can obtain the suggested split point from MaybeSplitKey
-// (which may have disappeared either due to a drop in qps or a change in the
+// (which may have disappeared either due to a drop in load or a change in the
 // workload).
 //
-// These second-long QPS samples are also aggregated together to track the
-// maximum historical QPS over a configurable retention period. This maximum QPS
-// measurement, which is accessible through the MaxQPS method, can be used to
-// prevent load-based splits from being merged away until the resulting ranges
-// have consistently remained below a certain QPS threshold for a sufficiently
-// long period of time.
+// These second-long samples are also aggregated together to track the maximum
+// historical rate over a configurable retention period. This maximum rate,
+// which is accessible through the MaxStat method, can be used to prevent
+// load-based splits from being merged away until the resulting ranges have
+// consistently remained below a certain threshold for a sufficiently long
+// period of time.
 
 // LoadSplitterMetrics consists of metrics for load-based splitter split key.
 type LoadSplitterMetrics struct {
@@ -59,25 +61,25 @@ type LoadSplitterMetrics struct {
 	NoSplitKeyCount *metric.Counter
 }
 
-// Decider tracks the latest QPS and if certain conditions are met, records
+// Decider tracks the latest stat rate and if certain conditions are met, records
 // incoming requests to find potential split keys and checks if sampled
 // candidate split keys satisfy certain requirements.
 type Decider struct {
 	intn                func(n int) int      // supplied to Init
-	qpsThreshold        func() float64       // supplied to Init
-	qpsRetention        func() time.Duration // supplied to Init
+	threshold           func() float64       // supplied to Init
+	retention           func() time.Duration // supplied to Init
 	loadSplitterMetrics *LoadSplitterMetrics // supplied to Init
 
 	mu struct {
 		syncutil.Mutex
 
-		// Fields tracking the current qps sample.
-		lastQPSRollover time.Time // most recent time recorded by requests.
-		lastQPS         float64   // last reqs/s rate as of lastQPSRollover
-		count           int64     // number of requests recorded since last rollover
+		// Fields tracking the current stat sample.
+		lastStatRollover time.Time // most recent time recorded by requests.
+		lastStat         float64   // last stat/s rate as of lastStatRollover
+		count            int64     // stat recorded since last rollover
 
-		// Fields tracking historical qps samples.
-		maxQPS maxQPSTracker
+		// Fields tracking historical stat samples.
+		maxStat maxStatTracker
 
 		// Fields tracking split key suggestions.
 		splitFinder         *Finder   // populated when engaged or decided
@@ -95,18 +97,18 @@ type Decider struct {
 func Init(
 	lbs *Decider,
 	intn func(n int) int,
-	qpsThreshold func() float64,
-	qpsRetention func() time.Duration,
+	threshold func() float64,
+	retention func() time.Duration,
 	loadSplitterMetrics *LoadSplitterMetrics,
 ) {
 	lbs.intn = intn
-	lbs.qpsThreshold = qpsThreshold
-	lbs.qpsRetention = qpsRetention
+	lbs.threshold = threshold
+	lbs.retention = retention
 	lbs.loadSplitterMetrics = loadSplitterMetrics
 }
 
-// Record notifies the Decider that 'n' operations are being carried out which
-// operate on the span returned by the supplied method. The closure will only
+// Record notifies the Decider that operations contributing 'n' to the stat are
+// being carried out which operate on the span returned by the supplied method. The closure will only
 // be called when necessary, that is, when the Decider is considering a split
 // and is sampling key spans to determine a suitable split point.
 //
@@ -125,27 +127,27 @@ func (d *Decider) recordLocked(
 ) bool {
 	d.mu.count += int64(n)
 
-	// First compute requests per second since the last check.
-	if d.mu.lastQPSRollover.IsZero() {
-		d.mu.lastQPSRollover = now
+	// First compute the stat per second since the last check.
+	if d.mu.lastStatRollover.IsZero() {
+		d.mu.lastStatRollover = now
 	}
-	elapsedSinceLastQPS := now.Sub(d.mu.lastQPSRollover)
-	if elapsedSinceLastQPS >= minQueriesPerSecondSampleDuration {
-		// Update the latest QPS and reset the time and request counter.
-		d.mu.lastQPS = (float64(d.mu.count) / float64(elapsedSinceLastQPS)) * 1e9
-		d.mu.lastQPSRollover = now
+	elapsedSinceLastStat := now.Sub(d.mu.lastStatRollover)
+	if elapsedSinceLastStat >= minStatSampleDuration {
+		// Update the latest stat rate and reset the time and stat counter.
+		d.mu.lastStat = (float64(d.mu.count) / float64(elapsedSinceLastStat)) * 1e9
+		d.mu.lastStatRollover = now
 		d.mu.count = 0
 
-		// Record the latest QPS sample in the historical tracker.
-		d.mu.maxQPS.record(now, d.qpsRetention(), d.mu.lastQPS)
+		// Record the latest stat sample in the historical tracker.
+		d.mu.maxStat.record(now, d.retention(), d.mu.lastStat)
 
-		// If the QPS for the range exceeds the threshold, start actively
+		// If the stat rate for the range exceeds the threshold, start actively
 		// tracking potential for splitting this range based on load.
 		// This tracking will begin by initiating a splitFinder so it can
 		// begin to Record requests so it can find a split point. If a
 		// splitFinder already exists, we check if a split point is ready
 		// to be used.
-		if d.mu.lastQPS >= d.qpsThreshold() {
+		if d.mu.lastStat >= d.threshold() {
 			if d.mu.splitFinder == nil {
 				d.mu.splitFinder = NewFinder(now)
 			}
@@ -186,34 +188,34 @@ func (d *Decider) recordLocked(
 	return false
 }
 
-// RecordMax adds a QPS measurement directly into the Decider's historical QPS
-// tracker. The QPS sample is considered to have been captured at the provided
+// RecordMax adds a stat rate measurement directly into the Decider's historical
+// tracker. The sample is considered to have been captured at the provided
 // time.
-func (d *Decider) RecordMax(now time.Time, qps float64) {
+func (d *Decider) RecordMax(now time.Time, stat float64) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.mu.maxQPS.record(now, d.qpsRetention(), qps)
+	d.mu.maxStat.record(now, d.retention(), stat)
 }
 
-// LastQPS returns the most recent QPS measurement.
-func (d *Decider) LastQPS(ctx context.Context, now time.Time) float64 {
+// LastStat returns the most recent stat rate measurement.
+func (d *Decider) LastStat(ctx context.Context, now time.Time) float64 {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.recordLocked(ctx, now, 0, nil) // force QPS computation
-	return d.mu.lastQPS
+	d.recordLocked(ctx, now, 0, nil) // force stat computation
+	return d.mu.lastStat
 }
 
-// MaxQPS returns the maximum QPS measurement recorded over the retention
+// MaxStat returns the maximum stat rate measurement recorded over the retention
 // period. If the Decider has not been recording for a full retention period,
 // the method returns false.
-func (d *Decider) MaxQPS(ctx context.Context, now time.Time) (float64, bool) {
+func (d *Decider) MaxStat(ctx context.Context, now time.Time) (float64, bool) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.recordLocked(ctx, now, 0, nil) // force QPS computation
-	return d.mu.maxQPS.maxQPS(now, d.qpsRetention())
+	d.recordLocked(ctx, now, 0, nil) // force stat computation
+	return d.mu.maxStat.maxStat(now, d.retention())
 }
 
 // MaybeSplitKey returns a key to perform a split at. The return value will be
@@ -270,21 +272,21 @@ func (d *Decider) MaybeSplitKey(ctx context.Context, now time.Time) roachpb.Key
 }
 
 // Reset deactivates any current attempt at determining a split key. The method
-// also discards any historical QPS tracking information.
+// also discards any historical stat tracking information.
 func (d *Decider) Reset(now time.Time) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.mu.lastQPSRollover = time.Time{}
-	d.mu.lastQPS = 0
+	d.mu.lastStatRollover = time.Time{}
+	d.mu.lastStat = 0
 	d.mu.count = 0
-	d.mu.maxQPS.reset(now, d.qpsRetention())
+	d.mu.maxStat.reset(now, d.retention())
 	d.mu.splitFinder = nil
 	d.mu.lastSplitSuggestion = time.Time{}
 	d.mu.lastNoSplitKeyLoggingMetrics = time.Time{}
 }
 
-// maxQPSTracker collects a series of queries-per-second measurement samples and
+// maxStatTracker collects a series of stat-per-second measurement samples and
 // tracks the maximum observed over a period of time.
 //
 // The tracker internally uses a set of time windows in order to age out old
@@ -292,13 +294,13 @@ func (d *Decider) Reset(now time.Time) {
 // a circular buffer of the last N windows of stats. We rotate through the
 // circular buffer every so often as determined by `minRetention`.
 //
-// The tracker can be queried through its `maxQPS` method, which returns the
-// maximum of all queries-per-second samples recorded over the retention period.
+// The tracker can be queried through its `maxStat` method, which returns the
+// maximum of all stat-per-second samples recorded over the retention period.
 // If the tracker has not been recording for a full retention period, then the
 // method returns false.
 //
-// The zero-value of a maxQPSTracker can be used immediately.
-type maxQPSTracker struct {
+// The zero-value of a maxStatTracker can be used immediately.
+type maxStatTracker struct {
 	windows      [6]float64
 	curIdx       int
 	curStart     time.Time
@@ -306,16 +308,16 @@ type maxQPSTracker struct {
 	minRetention time.Duration
 }
 
-// record adds the qps sample to the tracker.
-func (t *maxQPSTracker) record(now time.Time, minRetention time.Duration, qps float64) {
+// record adds the stat sample to the tracker.
+func (t *maxStatTracker) record(now time.Time, minRetention time.Duration, stat float64) {
 	t.maybeReset(now, minRetention)
 	t.maybeRotate(now)
-	t.windows[t.curIdx] = max(t.windows[t.curIdx], qps)
+	t.windows[t.curIdx] = max(t.windows[t.curIdx], stat)
 }
 
-// reset clears the tracker. maxQPS will begin returning false until a full
+// reset clears the tracker. maxStat will begin returning false until a full
 // minRetention period has elapsed.
-func (t *maxQPSTracker) reset(now time.Time, minRetention time.Duration) {
+func (t *maxStatTracker) reset(now time.Time, minRetention time.Duration) {
 	if minRetention <= 0 {
 		panic("minRetention must be positive")
 	}
@@ -326,18 +328,18 @@ func (t *maxQPSTracker) reset(now time.Time, minRetention time.Duration) {
 	t.minRetention = minRetention
 }
 
-func (t *maxQPSTracker) maybeReset(now time.Time, minRetention time.Duration) {
+func (t *maxStatTracker) maybeReset(now time.Time, minRetention time.Duration) {
 	// If the retention period changes, simply reset the entire tracker. Merging
 	// or splitting windows would be a difficult task and could lead to samples
 	// either not being retained for long-enough, or being retained for too long.
-	// Resetting indicates to maxQPS that a new retention period needs to be
+	// Resetting indicates to maxStat that a new retention period needs to be
 	// measured before accurate results can be returned.
 	if minRetention != t.minRetention {
 		t.reset(now, minRetention)
 	}
 }
 
-func (t *maxQPSTracker) maybeRotate(now time.Time) {
+func (t *maxStatTracker) maybeRotate(now time.Time) {
 	sinceLastRotate := now.Sub(t.curStart)
 	windowWidth := t.windowWidth()
 	if sinceLastRotate < windowWidth {
@@ -360,10 +362,10 @@ func (t *maxQPSTracker) maybeRotate(now time.Time) {
 	}
 }
 
-// maxQPS returns the maximum queries-per-second samples recorded over the last
+// maxStat returns the maximum of the stat samples recorded over the last
 // retention period. If the tracker has not been recording for a full retention
 // period, then the method returns false.
-func (t *maxQPSTracker) maxQPS(now time.Time, minRetention time.Duration) (float64, bool) {
+func (t *maxStatTracker) maxStat(now time.Time, minRetention time.Duration) (float64, bool) {
 	t.record(now, minRetention, 0) // expire samples, if necessary
 
 	if now.Sub(t.lastReset) < t.minRetention {
@@ -371,14 +373,14 @@ func (t *maxQPSTracker) maxQPS(now time.Time, minRetention time.Duration) (float
 		return 0, false
 	}
 
-	qps := 0.0
+	stat := 0.0
 	for _, v := range t.windows {
-		qps = max(qps, v)
+		stat = max(stat, v)
 	}
-	return qps, true
+	return stat, true
 }
 
-func (t *maxQPSTracker) windowWidth() time.Duration {
+func (t *maxStatTracker) windowWidth() time.Duration {
 	// NB: -1 because during a rotation, only len(t.windows)-1 windows survive.
 	return t.minRetention / time.Duration(len(t.windows)-1)
 }
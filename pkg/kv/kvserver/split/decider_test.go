@@ -49,42 +49,42 @@ func TestDecider(t *testing.T) {
 		return func() roachpb.Span { return roachpb.Span{Key: roachpb.Key(s)} }
 	}
 
-	assertQPS := func(i int, expQPS float64) {
+	assertStat := func(i int, expStat float64) {
 		t.Helper()
-		qps := d.LastQPS(context.Background(), ms(i))
-		assert.Equal(t, expQPS, qps)
+		stat := d.LastStat(context.Background(), ms(i))
+		assert.Equal(t, expStat, stat)
 	}
 
-	assertMaxQPS := func(i int, expMaxQPS float64, expOK bool) {
+	assertMaxStat := func(i int, expMaxStat float64, expOK bool) {
 		t.Helper()
-		maxQPS, ok := d.MaxQPS(context.Background(), ms(i))
-		assert.Equal(t, expMaxQPS, maxQPS)
+		maxStat, ok := d.MaxStat(context.Background(), ms(i))
+		assert.Equal(t, expMaxStat, maxStat)
 		assert.Equal(t, expOK, ok)
 	}
 
 	assert.Equal(t, false, d.Record(context.Background(), ms(100), 1, nil))
-	assertQPS(100, 0)
-	assertMaxQPS(100, 0, false)
+	assertStat(100, 0)
+	assertMaxStat(100, 0, false)
 
-	assert.Equal(t, ms(100), d.mu.lastQPSRollover)
+	assert.Equal(t, ms(100), d.mu.lastStatRollover)
 	assert.EqualValues(t, 1, d.mu.count)
 
 	assert.Equal(t, false, d.Record(context.Background(), ms(400), 3, nil))
-	assertQPS(100, 0)
-	assertQPS(700, 0)
-	assertMaxQPS(400, 0, false)
+	assertStat(100, 0)
+	assertStat(700, 0)
+	assertMaxStat(400, 0, false)
 
 	assert.Equal(t, false, d.Record(context.Background(), ms(300), 3, nil))
-	assertQPS(100, 0)
-	assertMaxQPS(300, 0, false)
+	assertStat(100, 0)
+	assertMaxStat(300, 0, false)
 
 	assert.Equal(t, false, d.Record(context.Background(), ms(900), 1, nil))
-	assertQPS(0, 0)
-	assertMaxQPS(900, 0, false)
+	assertStat(0, 0)
+	assertMaxStat(900, 0, false)
 
 	assert.Equal(t, false, d.Record(context.Background(), ms(1099), 1, nil))
-	assertQPS(0, 0)
-	assertMaxQPS(1099, 0, false)
+	assertStat(0, 0)
+	assertMaxStat(1099, 0, false)
 
 	// Now 9 operations happened in the interval [100, 1099]. The next higher
 	// timestamp will decide whether to engage the split finder.
@@ -92,9 +92,9 @@ func TestDecider(t *testing.T) {
 	// It won't engage because the duration between the rollovers is 1.1s, and
 	// we had 10 events over that interval.
 	assert.Equal(t, false, d.Record(context.Background(), ms(1200), 1, nil))
-	assertQPS(0, float64(10)/float64(1.1))
-	assert.Equal(t, ms(1200), d.mu.lastQPSRollover)
-	assertMaxQPS(1099, 0, false)
+	assertStat(0, float64(10)/float64(1.1))
+	assert.Equal(t, ms(1200), d.mu.lastStatRollover)
+	assertMaxStat(1099, 0, false)
 
 	var nilFinder *Finder
 
@@ -105,9 +105,9 @@ func TestDecider(t *testing.T) {
 
 	// 2200 is the next rollover point, and 12+1=13 qps should be computed.
 	assert.Equal(t, false, d.Record(context.Background(), ms(2200), 1, op("a")))
-	assert.Equal(t, ms(2200), d.mu.lastQPSRollover)
-	assertQPS(0, float64(13))
-	assertMaxQPS(2200, 13, true)
+	assert.Equal(t, ms(2200), d.mu.lastStatRollover)
+	assertStat(0, float64(13))
+	assertMaxStat(2200, 13, true)
 
 	assert.NotNil(t, d.mu.splitFinder)
 	assert.False(t, d.mu.splitFinder.Ready(ms(10)))
@@ -134,15 +134,15 @@ func TestDecider(t *testing.T) {
 			o = op("a")
 		}
 		assert.False(t, d.Record(context.Background(), ms(tick), 11, o))
-		assert.True(t, d.LastQPS(context.Background(), ms(tick)) > 1.0)
+		assert.True(t, d.LastStat(context.Background(), ms(tick)) > 1.0)
 		// Even though the split key remains.
 		assert.Equal(t, roachpb.Key("z"), d.MaybeSplitKey(context.Background(), ms(tick+999)))
 		tick += 1000
 	}
 	// But after minSplitSuggestionInterval of ticks, we get another one.
 	assert.True(t, d.Record(context.Background(), ms(tick), 11, op("a")))
-	assertQPS(tick, float64(11))
-	assertMaxQPS(tick, 11, true)
+	assertStat(tick, float64(11))
+	assertMaxStat(tick, 11, true)
 
 	// Split key suggestion vanishes once qps drops.
 	tick += 1000
@@ -194,7 +194,7 @@ func TestDecider(t *testing.T) {
 	assert.Nil(t, d.mu.splitFinder)
 }
 
-func TestDecider_MaxQPS(t *testing.T) {
+func TestDecider_MaxStat(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	intn := rand.New(rand.NewSource(11)).Intn
 
@@ -204,14 +204,14 @@ func TestDecider_MaxQPS(t *testing.T) {
 		NoSplitKeyCount: metric.NewCounter(metric.Metadata{}),
 	})
 
-	assertMaxQPS := func(i int, expMaxQPS float64, expOK bool) {
+	assertMaxStat := func(i int, expMaxStat float64, expOK bool) {
 		t.Helper()
-		maxQPS, ok := d.MaxQPS(context.Background(), ms(i))
-		assert.Equal(t, expMaxQPS, maxQPS)
+		maxStat, ok := d.MaxStat(context.Background(), ms(i))
+		assert.Equal(t, expMaxStat, maxStat)
 		assert.Equal(t, expOK, ok)
 	}
 
-	assertMaxQPS(1000, 0, false)
+	assertMaxStat(1000, 0, false)
 
 	// Record a large number of samples.
 	d.Record(context.Background(), ms(1500), 5, nil)
@@ -222,8 +222,8 @@ func TestDecider_MaxQPS(t *testing.T) {
 	d.Record(context.Background(), ms(8000), 5, nil)
 	d.Record(context.Background(), ms(10000), 9, nil)
 
-	assertMaxQPS(10000, 0, false)
-	assertMaxQPS(11000, 17, true)
+	assertMaxStat(10000, 0, false)
+	assertMaxStat(11000, 17, true)
 
 	// Record more samples with a lower QPS.
 	d.Record(context.Background(), ms(12000), 1, nil)
@@ -231,13 +231,13 @@ func TestDecider_MaxQPS(t *testing.T) {
 	d.Record(context.Background(), ms(15000), 2, nil)
 	d.Record(context.Background(), ms(19000), 3, nil)
 
-	assertMaxQPS(20000, 4.5, true)
-	assertMaxQPS(21000, 4, true)
+	assertMaxStat(20000, 4.5, true)
+	assertMaxStat(21000, 4, true)
 
 	// Add in a few QPS reading directly.
 	d.RecordMax(ms(24000), 6)
 
-	assertMaxQPS(25000, 6, true)
+	assertMaxStat(25000, 6, true)
 }
 
 func TestDeciderCallsEnsureSafeSplitKey(t *testing.T) {
@@ -316,18 +316,18 @@ func TestDeciderIgnoresEnsureSafeSplitKeyOnError(t *testing.T) {
 	require.Equal(t, c1().Key, k)
 }
 
-func TestMaxQPSTracker(t *testing.T) {
+func TestMaxStatTracker(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	tick := 100
 	minRetention := time.Second
 
-	var mt maxQPSTracker
+	var mt maxStatTracker
 	mt.reset(ms(tick), minRetention)
 	require.Equal(t, 200*time.Millisecond, mt.windowWidth())
 
-	// Check the maxQPS returns false before any samples are recorded.
-	qps, ok := mt.maxQPS(ms(tick), minRetention)
+	// Check the maxStat returns false before any samples are recorded.
+	qps, ok := mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 0.0, qps)
 	require.Equal(t, false, ok)
 	require.Equal(t, [6]float64{0, 0, 0, 0, 0, 0}, mt.windows)
@@ -340,8 +340,8 @@ func TestMaxQPSTracker(t *testing.T) {
 		mt.record(ms(tick), minRetention, float64(10+i))
 	}
 
-	// maxQPS should still return false, but some windows should have samples.
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	// maxStat should still return false, but some windows should have samples.
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 0.0, qps)
 	require.Equal(t, false, ok)
 	require.Equal(t, [6]float64{12, 16, 20, 24, 0, 0}, mt.windows)
@@ -353,9 +353,9 @@ func TestMaxQPSTracker(t *testing.T) {
 		mt.record(ms(tick), minRetention, float64(24+i))
 	}
 
-	// maxQPS should now return the maximum qps observed during the measurement
+	// maxStat should now return the maximum qps observed during the measurement
 	// period.
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 38.0, qps)
 	require.Equal(t, true, ok)
 	require.Equal(t, [6]float64{35, 38, 20, 24, 27, 31}, mt.windows)
@@ -366,7 +366,7 @@ func TestMaxQPSTracker(t *testing.T) {
 	tick += 500
 	mt.record(ms(tick), minRetention, float64(17))
 
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 38.0, qps)
 	require.Equal(t, true, ok)
 	require.Equal(t, [6]float64{35, 38, 0, 0, 17, 31}, mt.windows)
@@ -375,7 +375,7 @@ func TestMaxQPSTracker(t *testing.T) {
 	// A query far in the future should return 0, because this indicates no
 	// recent activity.
 	tick += 1900
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 0.0, qps)
 	require.Equal(t, true, ok)
 	require.Equal(t, [6]float64{0, 0, 0, 0, 0, 0}, mt.windows)
@@ -388,7 +388,7 @@ func TestMaxQPSTracker(t *testing.T) {
 		mt.record(ms(tick), minRetention, float64(33+i))
 	}
 
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 47.0, qps)
 	require.Equal(t, true, ok)
 	require.Equal(t, [6]float64{35, 39, 43, 47, 0, 0}, mt.windows)
@@ -400,7 +400,7 @@ func TestMaxQPSTracker(t *testing.T) {
 		mt.record(ms(tick), minRetention, float64(13+i))
 	}
 
-	qps, ok = mt.maxQPS(ms(tick), minRetention)
+	qps, ok = mt.maxStat(ms(tick), minRetention)
 	require.Equal(t, 0.0, qps)
 	require.Equal(t, false, ok)
 	require.Equal(t, [6]float64{20, 27, 0, 0, 0, 0}, mt.windows)
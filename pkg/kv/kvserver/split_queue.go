@@ -222,11 +222,11 @@ func (sq *splitQueue) processAttempt(
 	if splitByLoadKey := r.loadBasedSplitter.MaybeSplitKey(ctx, now); splitByLoadKey != nil {
 		batchHandledQPS, _ := r.QueriesPerSecond()
 		raftAppliedQPS := r.WritesPerSecond()
-		splitQPS := r.loadBasedSplitter.LastQPS(ctx, now)
+		splitLoad := r.loadBasedSplitter.LastStat(ctx, now)
 		reason := fmt.Sprintf(
-			"load at key %s (%.2f splitQPS, %.2f batches/sec, %.2f raft mutations/sec)",
+			"load at key %s (%s split load, %.2f batches/sec, %.2f raft mutations/sec)",
 			splitByLoadKey,
-			splitQPS,
+			r.SplitByLoadObjective().Format(splitLoad),
 			batchHandledQPS,
 			raftAppliedQPS,
 		)
@@ -1330,6 +1330,18 @@ func NewStore(
 	queueAdditionOnSystemConfigUpdateBurst.SetOnChange(&cfg.Settings.SV,
 		updateSystemConfigUpdateQueueLimits)
 
+	// The stats recorded by the load based splitters under one objective are
+	// not comparable to those of another, so they are reset when the objective
+	// changes. The callback is registered once per store and only resets the
+	// replicas of this store.
+	SplitByLoadObjectiveSetting.SetOnChange(&cfg.Settings.SV, func(ctx context.Context) {
+		now := s.Clock().PhysicalTime()
+		s.VisitReplicas(func(r *Replica) bool {
+			r.loadBasedSplitter.Reset(now)
+			return true
+		})
+	})
+
 	if s.cfg.Gossip != nil {
 		// Add range scanner and configure with queues.
 		s.scanner = newReplicaScanner(
@@ -3384,6 +3396,7 @@ type HotReplicaInfo struct {
 	WriteKeysPerSecond  float64
 	WriteBytesPerSecond float64
 	ReadBytesPerSecond  float64
+	CPUTimePerSecond    float64
 }
 
 // HottestReplicas returns the hottest replicas on a store, sorted by their
@@ -3402,6 +3415,7 @@ func (s *Store) HottestReplicas() []HotReplicaInfo {
 		hotRepls[i].ReadKeysPerSecond = topQPS[i].Repl().ReadsPerSecond()
 		hotRepls[i].WriteBytesPerSecond = topQPS[i].Repl().WriteBytesPerSecond()
 		hotRepls[i].ReadBytesPerSecond = topQPS[i].Repl().ReadBytesPerSecond()
+		hotRepls[i].CPUTimePerSecond = topQPS[i].Repl().CPUTimePerSecond()
 	}
 	return hotRepls
 }
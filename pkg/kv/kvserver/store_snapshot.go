@@ -164,6 +164,9 @@ type kvBatchSnapshotStrategy struct {
 	// Only used on the receiver side.
 	scratch *SSTSnapshotStorageScratch
 	st      *cluster.Settings
+	// The options of the span config of the range with which the SSTs are
+	// written. Only used on the receiver side.
+	sstOptions roachpb.SSTOptions
 }
 
 // multiSSTWriter is a wrapper around an SSTWriter and SSTSnapshotStorageScratch
@@ -179,6 +182,8 @@ type multiSSTWriter struct {
 	sstChunkSize int64
 	// The total size of SST data. Updated on SST finalization.
 	dataSize int64
+	// The options with which the SSTs are written.
+	sstOptions roachpb.SSTOptions
 }
 
 func newMultiSSTWriter(
@@ -187,12 +192,14 @@ func newMultiSSTWriter(
 	scratch *SSTSnapshotStorageScratch,
 	keySpans []roachpb.Span,
 	sstChunkSize int64,
+	sstOptions roachpb.SSTOptions,
 ) (multiSSTWriter, error) {
 	msstw := multiSSTWriter{
 		st:           st,
 		scratch:      scratch,
 		keySpans:     keySpans,
 		sstChunkSize: sstChunkSize,
+		sstOptions:   sstOptions,
 	}
 	if err := msstw.initSST(ctx); err != nil {
 		return msstw, err
@@ -205,7 +212,7 @@ func (msstw *multiSSTWriter) initSST(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to create new sst file")
 	}
-	newSST := storage.MakeIngestionSSTWriter(ctx, msstw.st, newSSTFile)
+	newSST := storage.MakeIngestionSSTWriterWithOptions(ctx, msstw.st, newSSTFile, msstw.sstOptions)
 	msstw.currSST = newSST
 	if err := msstw.currSST.ClearRawRange(
 		msstw.keySpans[msstw.currSpan].Key, msstw.keySpans[msstw.currSpan].EndKey,
@@ -400,7 +407,9 @@ func (kvSS *kvBatchSnapshotStrategy) Receive(
 	// At the moment we'll write at most five SSTs.
 	// TODO(jeffreyxiao): Re-evaluate as the default range size grows.
 	keyRanges := rditer.MakeReplicatedKeySpans(header.State.Desc)
-	msstw, err := newMultiSSTWriter(ctx, kvSS.st, kvSS.scratch, keyRanges, kvSS.sstChunkSize)
+	msstw, err := newMultiSSTWriter(
+		ctx, kvSS.st, kvSS.scratch, keyRanges, kvSS.sstChunkSize, kvSS.sstOptions,
+	)
 	if err != nil {
 		return noSnap, err
 	}
@@ -935,6 +944,27 @@ func (s *Store) checkSnapshotOverlapLocked(
 	return nil
 }
 
+// snapshotSSTOptions returns the SST options of the span config of the range
+// with the given descriptor, with which the SSTs of a snapshot of the range are
+// written. The storage engine does not let the store choose the options of the
+// SSTs it writes during flushes and compactions per range, so these only apply
+// to the SSTs of snapshots until they are compacted.
+func (s *Store) snapshotSSTOptions(
+	ctx context.Context, desc *roachpb.RangeDescriptor,
+) roachpb.SSTOptions {
+	confReader, err := s.GetConfReader(ctx)
+	if err != nil {
+		log.VEventf(ctx, 2, "unable to retrieve conf reader, using default SST options: %v", err)
+		return roachpb.SSTOptions{}
+	}
+	conf, err := confReader.GetSpanConfigForKey(ctx, desc.StartKey)
+	if err != nil {
+		log.VEventf(ctx, 2, "unable to look up span config, using default SST options: %v", err)
+		return roachpb.SSTOptions{}
+	}
+	return conf.SSTOptions
+}
+
 // receiveSnapshot receives an incoming snapshot via a pre-opened GRPC stream.
 func (s *Store) receiveSnapshot(
 	ctx context.Context, header *kvserverpb.SnapshotRequest_Header, stream incomingSnapshotStream,
@@ -1033,6 +1063,7 @@ func (s *Store) receiveSnapshot(
 			scratch:      s.sstSnapshotStorage.NewScratchSpace(header.State.Desc.RangeID, snapUUID),
 			sstChunkSize: snapshotSSTWriteSyncRate.Get(&s.cfg.Settings.SV),
 			st:           s.ClusterSettings(),
+			sstOptions:   s.snapshotSSTOptions(ctx, header.State.Desc),
 		}
 		defer ss.Close(ctx)
 	default:
@@ -856,6 +856,36 @@ func TestMaybeMarkReplicaInitialized(t *testing.T) {
 	}()
 }
 
+// TestStoreResetsLoadBasedSplittersOnObjectiveChange verifies that changing
+// the load based splitting objective discards the stats recorded by the load
+// based splitters of the store's replicas.
+func TestStoreResetsLoadBasedSplittersOnObjectiveChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	store, manual := createTestStore(ctx, t, testStoreOpts{}, stopper)
+	sv := &store.cfg.Settings.SV
+	// Keep requests from recording into the splitters behind the test's back.
+	SplitByLoadEnabled.Override(ctx, sv, false)
+	SplitByLoadObjectiveSetting.Override(ctx, sv, int64(SplitQPS))
+
+	repl := store.LookupReplica(roachpb.RKeyMin)
+	require.NotNil(t, repl)
+	retention := kvserverbase.SplitByLoadMergeDelay.Get(sv)
+	now := manual.Now()
+	repl.loadBasedSplitter.RecordMax(now, 100)
+	maxStat, ok := repl.loadBasedSplitter.MaxStat(ctx, now.Add(retention))
+	require.True(t, ok)
+	require.Equal(t, 100.0, maxStat)
+
+	SplitByLoadObjectiveSetting.Override(ctx, sv, int64(SplitCPU))
+	maxStat, ok = repl.loadBasedSplitter.MaxStat(ctx, now.Add(retention))
+	require.True(t, ok)
+	require.Zero(t, maxStat)
+}
+
 // TestStoreSend verifies straightforward command execution
 // of both a read-only and a read-write command.
 func TestStoreSend(t *testing.T) {
@@ -67,7 +67,8 @@ func TestingOverrideTxnLivenessThreshold(t time.Duration) func() {
 // ShouldPushImmediately returns whether the PushTxn request should
 // proceed without queueing. This is true for pushes which are neither
 // ABORT nor TIMESTAMP, but also for ABORT and TIMESTAMP pushes where
-// the pushee has min priority or pusher has max priority.
+// the pushee has min priority or pusher has max priority, and for
+// TIMESTAMP pushes where the pushee tolerates write skew.
 func ShouldPushImmediately(req *roachpb.PushTxnRequest) bool {
 	if req.Force {
 		return true
@@ -78,6 +79,9 @@ func ShouldPushImmediately(req *roachpb.PushTxnRequest) bool {
 	if CanPushWithPriority(req.PusherTxn.Priority, req.PusheeTxn.Priority) {
 		return true
 	}
+	if req.PushType == roachpb.PUSH_TIMESTAMP && req.PusheeTxn.IsoLevel.ToleratesWriteSkew() {
+		return true
+	}
 	return false
 }
 
@@ -76,6 +76,17 @@ func (m *MockTransactionalSender) SetUserPriority(pri roachpb.UserPriority) erro
 	return nil
 }
 
+// SetIsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsoLevel(isoLevel enginepb.IsolationLevel) error {
+	m.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) IsoLevel() enginepb.IsolationLevel {
+	return m.txn.IsoLevel
+}
+
 // SetDebugName is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
@@ -209,6 +220,11 @@ func (m *MockTransactionalSender) ManualRefresh(ctx context.Context) error {
 	panic("unimplemented")
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) StepReadTimestamp(ctx context.Context) error {
+	panic("unimplemented")
+}
+
 // DeferCommitWait is part of the TxnSender interface.
 func (m *MockTransactionalSender) DeferCommitWait(ctx context.Context) func(context.Context) error {
 	panic("unimplemented")
@@ -225,6 +241,11 @@ func (m *MockTransactionalSender) GetTxnRetryableErr(
 func (m *MockTransactionalSender) ClearTxnRetryableErr(ctx context.Context) {
 }
 
+// PrepareForPartialRetry is part of the TxnSender interface.
+func (m *MockTransactionalSender) PrepareForPartialRetry(ctx context.Context) error {
+	panic("unimplemented")
+}
+
 // HasPerformedReads is part of TxnSenderFactory.
 func (m *MockTransactionalSender) HasPerformedReads() bool {
 	panic("unimplemented")
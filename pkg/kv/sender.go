@@ -115,6 +115,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsoLevel sets the txn's isolation level. It can only be called
+	// before the txn has performed any operations.
+	SetIsoLevel(enginepb.IsolationLevel) error
+
+	// IsoLevel returns the txn's isolation level.
+	IsoLevel() enginepb.IsolationLevel
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -306,6 +313,13 @@ type TxnSender interface {
 	// before the merge transaction completed.
 	ManualRefresh(ctx context.Context) error
 
+	// StepReadTimestamp gives the transaction a new read snapshot at the
+	// present time. It can only be used by transactions whose isolation level
+	// takes a read snapshot per statement, before each of their statements.
+	// The reads performed before the step no longer need to be refreshed if
+	// the transaction is pushed.
+	StepReadTimestamp(ctx context.Context) error
+
 	// DeferCommitWait defers the transaction's commit-wait operation, passing
 	// responsibility of commit-waiting from the TxnSender to the caller of this
 	// method. The method returns a function which the caller must eventually
@@ -326,6 +340,13 @@ type TxnSender interface {
 	// ClearTxnRetryableErr clears the retryable error, if any.
 	ClearTxnRetryableErr(ctx context.Context)
 
+	// PrepareForPartialRetry clears the retryable error, which must allow for
+	// a partial retry (see TransactionRetryWithProtoRefreshError.PartialRetry),
+	// without restarting the transaction. The caller is expected to roll back
+	// the writes of the statement which hit the error, using a savepoint, and
+	// to retry that statement at the new read timestamp of the transaction.
+	PrepareForPartialRetry(ctx context.Context) error
+
 	// HasPerformedReads returns true if a read has been performed.
 	HasPerformedReads() bool
 
@@ -299,6 +299,26 @@ func (txn *Txn) SetUserPriority(userPriority roachpb.UserPriority) error {
 	return txn.mu.sender.SetUserPriority(userPriority)
 }
 
+// SetIsoLevel sets the transaction's isolation level. Transactions default to
+// SERIALIZABLE. The isolation level must be set before any operations are
+// performed on the transaction.
+func (txn *Txn) SetIsoLevel(isoLevel enginepb.IsolationLevel) error {
+	if txn.typ != RootTxn {
+		return errors.AssertionFailedf("SetIsoLevel() called on leaf txn")
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetIsoLevel(isoLevel)
+}
+
+// IsoLevel returns the transaction's isolation level.
+func (txn *Txn) IsoLevel() enginepb.IsolationLevel {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.IsoLevel()
+}
+
 // TestingSetPriority sets the transaction priority. It is intended for
 // internal (testing) use only.
 func (txn *Txn) TestingSetPriority(priority enginepb.TxnPriority) {
@@ -1106,6 +1126,20 @@ func (txn *Txn) Send(
 	return br, pErr
 }
 
+// PrepareForPartialRetry clears the retryable error of the transaction, which
+// must allow for a partial retry, without restarting the transaction. See
+// TxnSender.PrepareForPartialRetry.
+func (txn *Txn) PrepareForPartialRetry(ctx context.Context) error {
+	if txn.typ != RootTxn {
+		return errors.WithContextTags(errors.AssertionFailedf(
+			"PrepareForPartialRetry() called on leaf txn"), ctx)
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.PrepareForPartialRetry(ctx)
+}
+
 func (txn *Txn) handleRetryableErrLocked(
 	ctx context.Context, retryErr *roachpb.TransactionRetryWithProtoRefreshError,
 ) {
@@ -1523,6 +1557,15 @@ func (txn *Txn) Step(ctx context.Context) error {
 	return txn.mu.sender.Step(ctx)
 }
 
+// StepReadTimestamp gives the transaction a new read snapshot at the present
+// time. It must be called before each statement of transactions whose
+// isolation level takes a read snapshot per statement.
+func (txn *Txn) StepReadTimestamp(ctx context.Context) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.StepReadTimestamp(ctx)
+}
+
 // SetReadSeqNum sets the read sequence number for this transaction.
 func (txn *Txn) SetReadSeqNum(seq enginepb.TxnSeq) error {
 	txn.mu.Lock()
@@ -512,6 +512,7 @@ var nonZeroTxn = Transaction{
 		Priority:          957356782,
 		Sequence:          123,
 		CoordinatorNodeID: 3,
+		IsoLevel:          enginepb.READ_COMMITTED,
 	},
 	Name:                   "name",
 	Status:                 COMMITTED,
@@ -554,6 +555,7 @@ func TestTransactionUpdate(t *testing.T) {
 	txn3.Name = "carl"
 	txn3.Priority = 123
 	txn3.CoordinatorNodeID = 3
+	txn3.IsoLevel = enginepb.READ_COMMITTED
 	txn3.Update(&txn)
 
 	expTxn3 := txn
@@ -569,6 +571,7 @@ func TestTransactionUpdate(t *testing.T) {
 	txn4.Name = "carl"
 	txn4.Priority = 123
 	txn4.CoordinatorNodeID = 3
+	txn4.IsoLevel = enginepb.READ_COMMITTED
 	txn4.Update(&txn)
 
 	expTxn4 := txn
@@ -609,6 +612,7 @@ func TestTransactionUpdate(t *testing.T) {
 	txn5.Name = "carl"
 	txn5.Priority = 123
 	txn5.CoordinatorNodeID = 3
+	txn5.IsoLevel = enginepb.READ_COMMITTED
 	txn5.Update(&txn)
 
 	expTxn5 := txn
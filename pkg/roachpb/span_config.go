@@ -92,6 +92,18 @@ func (s *SpanConfig) ValidateSystemTargetSpanConfig() error {
 	if s.ExcludeDataFromBackup {
 		return errors.AssertionFailedf("ExcludeDataFromBackup set on system span config")
 	}
+	if s.NumReadReplicas != 0 {
+		return errors.AssertionFailedf("NumReadReplicas set on system span config")
+	}
+	if len(s.ReadReplicaConstraints) != 0 {
+		return errors.AssertionFailedf("ReadReplicaConstraints set on system span config")
+	}
+	if s.ReadReplicaLagTarget != 0 {
+		return errors.AssertionFailedf("ReadReplicaLagTarget set on system span config")
+	}
+	if s.SSTOptions != (SSTOptions{}) {
+		return errors.AssertionFailedf("SSTOptions set on system span config")
+	}
 	return nil
 }
 
@@ -108,9 +120,9 @@ func (s *SpanConfig) GetNumVoters() int32 {
 }
 
 // GetNumNonVoters returns the number of non-voting replicas as defined in the
-// span config.
+// span config, including the dedicated read replicas.
 func (s *SpanConfig) GetNumNonVoters() int32 {
-	return s.NumReplicas - s.GetNumVoters()
+	return s.NumReplicas - s.GetNumVoters() + s.NumReadReplicas
 }
 
 func (c Constraint) String() string {
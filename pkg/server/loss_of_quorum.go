@@ -12,14 +12,21 @@ package server
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery/loqrecoverypb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -83,3 +90,268 @@ func publishPendingLossOfQuorumRecoveryEvents(
 		}
 	})
 }
+
+// newLossOfQuorumPlanStore creates a plan store in the auxiliary directory of
+// the first store of the node.
+func newLossOfQuorumPlanStore(engines Engines) loqrecovery.PlanStore {
+	return loqrecovery.NewPlanStore(engines[0].GetAuxiliaryDir(), engines[0])
+}
+
+func maybeApplyStagedLossOfQuorumRecoveryPlan(
+	ctx context.Context, planStore loqrecovery.PlanStore, engines Engines,
+) {
+	if err := loqrecovery.MaybeApplyPendingRecoveryPlan(
+		ctx, planStore, engines, timeutil.DefaultTimeSource{}); err != nil {
+		// We don't want to abort server if we can't apply the plan, operator
+		// would find out about the failure using recovery status.
+		log.Errorf(ctx, "failed to apply staged loss of quorum recovery plan: %v", err)
+	}
+}
+
+// lossOfQuorumRecoveryRPCTimeout bounds the time loss of quorum recovery waits
+// for each node to respond, so that a node which is stuck doesn't block the
+// whole request.
+const lossOfQuorumRecoveryRPCTimeout = time.Minute
+
+// lossOfQuorumRecoveryMetaScanTimeout bounds the time loss of quorum recovery
+// verification waits for each scan of meta ranges, which block if the meta
+// ranges lost quorum.
+const lossOfQuorumRecoveryMetaScanTimeout = 30 * time.Second
+
+// visitRecoveryNodes calls visitor for every node of the cluster that is not
+// decommissioned in order of node IDs. Nodes are contacted sequentially and
+// errors of contacting the node or returned by the visitor are passed to
+// onError. Each call of visitor is bounded by lossOfQuorumRecoveryRPCTimeout.
+// Nodes are taken from the gossiped liveness records rather than from
+// KV as ranges holding node statuses could be unavailable.
+func (s *adminServer) visitRecoveryNodes(
+	ctx context.Context,
+	visitor func(ctx context.Context, nodeID roachpb.NodeID, client serverpb.AdminClient) error,
+	onError func(nodeID roachpb.NodeID, err error),
+) {
+	var nodeIDs []roachpb.NodeID
+	for nodeID, entry := range s.server.nodeLiveness.GetIsLiveMap() {
+		if entry.Membership.Decommissioned() {
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	for _, nodeID := range nodeIDs {
+		var client serverpb.AdminClient
+		err := contextutil.RunWithTimeout(ctx, "dial node", base.NetworkTimeout,
+			func(ctx context.Context) error {
+				var err error
+				client, err = s.dialNode(ctx, nodeID)
+				return err
+			})
+		if err == nil {
+			err = contextutil.RunWithTimeout(ctx, "visit node", lossOfQuorumRecoveryRPCTimeout,
+				func(ctx context.Context) error {
+					return visitor(ctx, nodeID, client)
+				})
+		}
+		if err != nil {
+			onError(nodeID, errors.Wrapf(err, "failed to contact node n%d", nodeID))
+		}
+	}
+}
+
+// RecoveryCollectReplicaInfo implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryCollectReplicaInfo(
+	ctx context.Context, _ *serverpb.RecoveryCollectReplicaInfoRequest,
+) (*serverpb.RecoveryCollectReplicaInfoResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		// NB: not using serverError() here since the priv checker
+		// already returns a proper gRPC error status.
+		return nil, err
+	}
+
+	resp := &serverpb.RecoveryCollectReplicaInfoResponse{}
+	s.visitRecoveryNodes(ctx,
+		func(ctx context.Context, nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			res, err := client.RecoveryCollectLocalReplicaInfo(ctx,
+				&serverpb.RecoveryCollectLocalReplicaInfoRequest{})
+			if err != nil {
+				return err
+			}
+			resp.ReplicaInfo = append(resp.ReplicaInfo, res.ReplicaInfo)
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			log.Warningf(ctx, "failed to collect replica info: %v", err)
+			resp.UnreachableNodeIDs = append(resp.UnreachableNodeIDs, nodeID)
+		})
+	return resp, nil
+}
+
+// RecoveryCollectLocalReplicaInfo implements the serverpb.AdminServer
+// interface.
+func (s *adminServer) RecoveryCollectLocalReplicaInfo(
+	ctx context.Context, _ *serverpb.RecoveryCollectLocalReplicaInfoRequest,
+) (*serverpb.RecoveryCollectLocalReplicaInfoResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	info, err := loqrecovery.CollectReplicaInfo(ctx, s.server.engines)
+	if err != nil {
+		return nil, serverError(ctx, err)
+	}
+	return &serverpb.RecoveryCollectLocalReplicaInfoResponse{ReplicaInfo: info}, nil
+}
+
+// RecoveryStagePlan implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryStagePlan(
+	ctx context.Context, req *serverpb.RecoveryStagePlanRequest,
+) (*serverpb.RecoveryStagePlanResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	if !req.AllNodes {
+		if err := loqrecovery.StagePlan(
+			ctx, s.server.loqPlanStore, s.server.NodeID(), req.Plan, req.ForcePlan); err != nil {
+			return &serverpb.RecoveryStagePlanResponse{
+				Errors: []string{errors.Wrapf(err, "node n%d", s.server.NodeID()).Error()},
+			}, nil
+		}
+		return &serverpb.RecoveryStagePlanResponse{}, nil
+	}
+
+	// Check that all nodes that have updates in the plan are reachable and that
+	// no node has a different plan staged before staging anything. This way we
+	// don't end up with a partially staged plan in the most common failure
+	// cases.
+	resp := &serverpb.RecoveryStagePlanResponse{}
+	reachable := make(map[roachpb.NodeID]struct{})
+	s.visitRecoveryNodes(ctx,
+		func(ctx context.Context, nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			res, err := client.RecoveryNodeStatus(ctx, &serverpb.RecoveryNodeStatusRequest{})
+			if err != nil {
+				return err
+			}
+			reachable[nodeID] = struct{}{}
+			if pending := res.Status.PendingPlanID; pending != nil && *pending != req.Plan.PlanID &&
+				!req.ForcePlan {
+				resp.Errors = append(resp.Errors,
+					errors.Newf("plan %s is already staged on node n%d", *pending, nodeID).Error())
+			}
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			// Unreachable nodes without planned updates are most likely dead and
+			// shouldn't prevent recovery.
+			log.Warningf(ctx, "failed to check recovery status: %v", err)
+		})
+	for _, nodeID := range planNodeIDs(req.Plan) {
+		if _, ok := reachable[nodeID]; !ok {
+			resp.Errors = append(resp.Errors,
+				errors.Newf("plan contains updates for node n%d which is not reachable", nodeID).Error())
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp, nil
+	}
+
+	s.visitRecoveryNodes(ctx,
+		func(ctx context.Context, nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			if _, ok := reachable[nodeID]; !ok {
+				return nil
+			}
+			res, err := client.RecoveryStagePlan(ctx, &serverpb.RecoveryStagePlanRequest{
+				Plan:      req.Plan,
+				ForcePlan: req.ForcePlan,
+			})
+			if err != nil {
+				return err
+			}
+			resp.Errors = append(resp.Errors, res.Errors...)
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			resp.Errors = append(resp.Errors, err.Error())
+		})
+	return resp, nil
+}
+
+// planNodeIDs returns sorted IDs of nodes that have replica updates in the
+// plan.
+func planNodeIDs(plan loqrecoverypb.ReplicaUpdatePlan) []roachpb.NodeID {
+	seen := make(map[roachpb.NodeID]struct{})
+	var nodeIDs []roachpb.NodeID
+	for _, u := range plan.Updates {
+		if _, ok := seen[u.NodeID()]; !ok {
+			seen[u.NodeID()] = struct{}{}
+			nodeIDs = append(nodeIDs, u.NodeID())
+		}
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	return nodeIDs
+}
+
+// RecoveryNodeStatus implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryNodeStatus(
+	ctx context.Context, _ *serverpb.RecoveryNodeStatusRequest,
+) (*serverpb.RecoveryNodeStatusResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	status, err := loqrecovery.GetNodeRecoveryStatus(
+		ctx, s.server.NodeID(), s.server.loqPlanStore, s.server.engines)
+	if err != nil {
+		return nil, serverError(ctx, err)
+	}
+	return &serverpb.RecoveryNodeStatusResponse{Status: status}, nil
+}
+
+// RecoveryVerify implements the serverpb.AdminServer interface.
+func (s *adminServer) RecoveryVerify(
+	ctx context.Context, _ *serverpb.RecoveryVerifyRequest,
+) (*serverpb.RecoveryVerifyResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.RecoveryVerifyResponse{}
+	reachable := make(map[roachpb.NodeID]struct{})
+	s.visitRecoveryNodes(ctx,
+		func(ctx context.Context, nodeID roachpb.NodeID, client serverpb.AdminClient) error {
+			res, err := client.RecoveryNodeStatus(ctx, &serverpb.RecoveryNodeStatusRequest{})
+			if err != nil {
+				return err
+			}
+			reachable[nodeID] = struct{}{}
+			resp.Statuses = append(resp.Statuses, res.Status)
+			return nil
+		},
+		func(nodeID roachpb.NodeID, err error) {
+			resp.Errors = append(resp.Errors, err.Error())
+		})
+
+	// Ranges are considered available if the majority of their replicas
+	// reside on nodes that responded to the status request. The descriptor of
+	// the first range is taken from gossip, since it can't be read from KV if
+	// the range lost quorum.
+	firstRange, err := s.server.gossip.GetFirstRangeDescriptor()
+	if err != nil {
+		log.Warningf(ctx, "failed to get first range descriptor from gossip: %v", err)
+		firstRange = nil
+	}
+	ranges, err := loqrecovery.RangesWithoutQuorum(ctx, s.server.db, firstRange,
+		func(nodeID roachpb.NodeID) bool {
+			_, ok := reachable[nodeID]
+			return ok
+		}, lossOfQuorumRecoveryMetaScanTimeout)
+	if err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+	resp.UnavailableRanges = ranges
+	return resp, nil
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/sidetransport"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptprovider"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptreconcile"
@@ -161,6 +162,10 @@ type Server struct {
 	// layer.
 	kvMemoryMonitor *mon.BytesMonitor
 
+	// loqPlanStore is the location where loss of quorum recovery plans are
+	// staged until they are applied on the next node restart.
+	loqPlanStore loqrecovery.PlanStore
+
 	// The following fields are populated at start time, i.e. in `(*Server).Start`.
 	startTime time.Time
 }
@@ -231,6 +236,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 	stopper.AddCloser(&engines)
 
+	// Loss of quorum recovery plans staged through the admin API are applied
+	// before any store is started as replicas are rewritten directly in the
+	// engines.
+	loqPlanStore := newLossOfQuorumPlanStore(engines)
+	maybeApplyStagedLossOfQuorumRecoveryPlan(ctx, loqPlanStore, engines)
+
 	nodeTombStorage, checkPingFor := getPingCheckDecommissionFn(engines)
 
 	rpcCtxOpts := rpc.ContextOptions{
@@ -924,6 +935,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		externalStorageBuilder: externalStorageBuilder,
 		storeGrantCoords:       gcoords.Stores,
 		kvMemoryMonitor:        kvMemoryMonitor,
+		loqPlanStore:           loqPlanStore,
 	}
 
 	// Begin an async task to periodically purge old sessions in the system.web_sessions table.
@@ -2052,6 +2052,7 @@ func (s *statusServer) rangesHelper(
 				ReadsPerSecond:      rep.ReadsPerSecond(),
 				WriteBytesPerSecond: rep.WriteBytesPerSecond(),
 				ReadBytesPerSecond:  rep.ReadBytesPerSecond(),
+				CPUTimePerSecond:    rep.CPUTimePerSecond(),
 			},
 			Problems: serverpb.RangeProblems{
 				Unavailable:            metrics.Unavailable,
@@ -2483,6 +2484,7 @@ func (s *statusServer) HotRangesV2(
 						RangeID:           r.Desc.RangeID,
 						NodeID:            nodeID,
 						QPS:               r.QueriesPerSecond,
+						CPUTimePerSecond:  r.CPUTimePerSecond,
 						TableName:         tableName,
 						SchemaName:        schemaName,
 						DatabaseName:      dbName,
@@ -2559,6 +2561,7 @@ func (s *statusServer) localHotRanges(ctx context.Context) serverpb.HotRangesRes
 			storeResp.HotRanges[i].ReadsPerSecond = r.ReadKeysPerSecond
 			storeResp.HotRanges[i].WriteBytesPerSecond = r.WriteBytesPerSecond
 			storeResp.HotRanges[i].ReadBytesPerSecond = r.ReadBytesPerSecond
+			storeResp.HotRanges[i].CPUTimePerSecond = r.CPUTimePerSecond
 		}
 		resp.Stores = append(resp.Stores, storeResp)
 		return nil
@@ -15,6 +15,7 @@ package spanconfigsqltranslator
 
 import (
 	"context"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/spanconfig"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -364,6 +366,12 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 	// backups.
 	tableSpanConfig.ExcludeDataFromBackup = table.GetExcludeDataFromBackup()
 
+	// Set the options with which the SSTs holding the table's data are written.
+	// The options of indexes which override them are applied to the spans of
+	// these indexes by makeTableRecords.
+	tableSpanConfig.SSTOptions = makeSSTOptions(roachpb.SSTOptions{}, table.GetSSTOptions())
+	indexSSTOptions := s.getIndexSSTOptions(table)
+
 	records := make([]spanconfig.Record, 0)
 	if table.GetID() == keys.DescriptorTableID {
 		// We have named ranges preceding `system.descriptor`.
@@ -382,17 +390,10 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 			// this way.
 			startKey = s.codec.TenantPrefix()
 		}
-		record, err := spanconfig.MakeRecord(
-			spanconfig.MakeTargetFromSpan(roachpb.Span{
-				Key:    startKey,
-				EndKey: tableEndKey,
-			}), tableSpanConfig)
-		if err != nil {
-			return nil, err
-		}
-		records = append(records, record)
-
-		return records, nil
+		return makeTableRecords(records, roachpb.Span{
+			Key:    startKey,
+			EndKey: tableEndKey,
+		}, tableSpanConfig, indexSSTOptions)
 
 		// TODO(irfansharif): There's an attack vector here that we haven't
 		// addressed satisfactorily. By splitting only on start keys of span
@@ -446,12 +447,11 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 		// If there is a "hole" in the spans covered by the subzones array we fill
 		// it using the parent zone configuration.
 		if !prevEndKey.Equal(span.Key) {
-			record, err := spanconfig.MakeRecord(spanconfig.MakeTargetFromSpan(
-				roachpb.Span{Key: prevEndKey, EndKey: span.Key}), tableSpanConfig)
+			records, err = makeTableRecords(records,
+				roachpb.Span{Key: prevEndKey, EndKey: span.Key}, tableSpanConfig, indexSSTOptions)
 			if err != nil {
 				return nil, err
 			}
-			records = append(records, record)
 		}
 
 		// Add an entry for the subzone.
@@ -460,16 +460,16 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 		// SubzoneSpanConfig.
 		subzoneSpanConfig.GCPolicy.ProtectionPolicies = tableSpanConfig.GCPolicy.ProtectionPolicies[:]
 		subzoneSpanConfig.ExcludeDataFromBackup = tableSpanConfig.ExcludeDataFromBackup
+		subzoneSpanConfig.SSTOptions = tableSpanConfig.SSTOptions
 		if isSystemDesc { // same as above
 			subzoneSpanConfig.RangefeedEnabled = true
 			subzoneSpanConfig.GCPolicy.IgnoreStrictEnforcement = true
 		}
-		record, err := spanconfig.MakeRecord(
-			spanconfig.MakeTargetFromSpan(roachpb.Span{Key: span.Key, EndKey: span.EndKey}), subzoneSpanConfig)
+		records, err = makeTableRecords(records,
+			roachpb.Span{Key: span.Key, EndKey: span.EndKey}, subzoneSpanConfig, indexSSTOptions)
 		if err != nil {
 			return nil, err
 		}
-		records = append(records, record)
 
 		prevEndKey = span.EndKey
 	}
@@ -477,16 +477,103 @@ func (s *SQLTranslator) generateSpanConfigurationsForTable(
 	// If the last subzone span doesn't cover the entire table's keyspace then
 	// we cover the remaining key range with the table's zone configuration.
 	if !prevEndKey.Equal(tableEndKey) {
-		record, err := spanconfig.MakeRecord(
-			spanconfig.MakeTargetFromSpan(roachpb.Span{Key: prevEndKey, EndKey: tableEndKey}), tableSpanConfig)
+		records, err = makeTableRecords(records,
+			roachpb.Span{Key: prevEndKey, EndKey: tableEndKey}, tableSpanConfig, indexSSTOptions)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return records, nil
+}
+
+// indexSSTOptions are the SST options set on an index, along with the span of
+// the index.
+type indexSSTOptions struct {
+	span roachpb.Span
+	opts catpb.SSTOptions
+}
+
+// getIndexSSTOptions returns the SST options of the indexes of the given table
+// which have them set, ordered by the spans of the indexes.
+func (s *SQLTranslator) getIndexSSTOptions(table catalog.TableDescriptor) []indexSSTOptions {
+	var ret []indexSSTOptions
+	for _, idx := range table.AllIndexes() {
+		opts := idx.IndexDesc().SSTOptions
+		if opts == nil {
+			continue
+		}
+		prefix := s.codec.IndexPrefix(uint32(table.GetID()), uint32(idx.GetID()))
+		ret = append(ret, indexSSTOptions{
+			span: roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
+			opts: *opts,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].span.Key.Compare(ret[j].span.Key) < 0
+	})
+	return ret
+}
+
+// makeTableRecords appends the records for the given span of a table's keyspace
+// to records. The span is split at the boundaries of the spans of the given
+// indexes, whose parts use the SST options of the index.
+func makeTableRecords(
+	records []spanconfig.Record,
+	span roachpb.Span,
+	conf roachpb.SpanConfig,
+	indexes []indexSSTOptions,
+) ([]spanconfig.Record, error) {
+	appendRecord := func(span roachpb.Span, conf roachpb.SpanConfig) error {
+		record, err := spanconfig.MakeRecord(spanconfig.MakeTargetFromSpan(span), conf)
+		if err != nil {
+			return err
+		}
 		records = append(records, record)
+		return nil
+	}
+	key := span.Key
+	for _, idx := range indexes {
+		overlap := idx.span.Intersect(roachpb.Span{Key: key, EndKey: span.EndKey})
+		if !overlap.Valid() {
+			continue
+		}
+		if key.Compare(overlap.Key) < 0 {
+			if err := appendRecord(roachpb.Span{Key: key, EndKey: overlap.Key}, conf); err != nil {
+				return nil, err
+			}
+		}
+		indexConf := conf
+		indexConf.SSTOptions = makeSSTOptions(conf.SSTOptions, idx.opts)
+		if err := appendRecord(overlap, indexConf); err != nil {
+			return nil, err
+		}
+		key = overlap.EndKey
+	}
+	if key.Compare(span.EndKey) < 0 {
+		if err := appendRecord(roachpb.Span{Key: key, EndKey: span.EndKey}, conf); err != nil {
+			return nil, err
+		}
 	}
 	return records, nil
 }
 
+// makeSSTOptions returns the given SST options of a span config, overridden by
+// the SST options of a table or an index which are set.
+func makeSSTOptions(sstOpts roachpb.SSTOptions, opts catpb.SSTOptions) roachpb.SSTOptions {
+	switch opts.Compression {
+	case "none":
+		sstOpts.Compression = roachpb.SSTOptions_NONE
+	case "snappy":
+		sstOpts.Compression = roachpb.SSTOptions_SNAPPY
+	case "zstd":
+		sstOpts.Compression = roachpb.SSTOptions_ZSTD
+	}
+	if opts.BlockSize != 0 {
+		sstOpts.BlockSize = opts.BlockSize
+	}
+	return sstOpts
+}
+
 // findDescendantLeafIDs finds all leaf IDs below the given ID in the zone
 // configuration hierarchy. Leaf IDs are either table IDs or named zone IDs
 // (other than RANGE DEFAULT).
@@ -407,6 +407,22 @@ func PrintSpanConfigDiffedAgainstDefaults(conf roachpb.SpanConfig) string {
 	if conf.ExcludeDataFromBackup != defaultConf.ExcludeDataFromBackup {
 		diffs = append(diffs, fmt.Sprintf("exclude_data_from_backup=%v", conf.ExcludeDataFromBackup))
 	}
+	if conf.NumReadReplicas != defaultConf.NumReadReplicas {
+		diffs = append(diffs, fmt.Sprintf("num_read_replicas=%d", conf.NumReadReplicas))
+	}
+	if !reflect.DeepEqual(conf.ReadReplicaConstraints, defaultConf.ReadReplicaConstraints) {
+		diffs = append(diffs, fmt.Sprintf("read_replica_constraints=%v", conf.ReadReplicaConstraints))
+	}
+	if conf.ReadReplicaLagTarget != defaultConf.ReadReplicaLagTarget {
+		diffs = append(diffs, fmt.Sprintf("read_replica_lag_target=%s", conf.ReadReplicaLagTarget))
+	}
+	if conf.SSTOptions.Compression != defaultConf.SSTOptions.Compression {
+		diffs = append(diffs, fmt.Sprintf("sst_compression=%s",
+			strings.ToLower(conf.SSTOptions.Compression.String())))
+	}
+	if conf.SSTOptions.BlockSize != defaultConf.SSTOptions.BlockSize {
+		diffs = append(diffs, fmt.Sprintf("sst_block_size=%d", conf.SSTOptions.BlockSize))
+	}
 
 	return strings.Join(diffs, " ")
 }
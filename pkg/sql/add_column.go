@@ -80,6 +80,19 @@ func (p *planner) addColumnImpl(
 	idx := cdd.PrimaryKeyOrUniqueIndexDescriptor
 	incTelemetryForNewColumn(d, col)
 
+	// Existing rows are backfilled with the default value of the domain if the
+	// column has no default of its own, and a column of a domain that does not
+	// allow NULL values can only be added to a non-empty table with a default.
+	nullable := col.Nullable
+	if domainData := toType.TypeMeta.DomainData; toType.IsDomain() && domainData != nil {
+		if col.DefaultExpr == nil && !col.IsComputed() {
+			col.DefaultExpr = domainData.DefaultExpr
+		}
+		if domainData.NotNull {
+			nullable = false
+		}
+	}
+
 	// Ensure all new indexes are partitioned appropriately.
 	if idx != nil {
 		if n.tableDesc.IsLocalityRegionalByRow() {
@@ -105,7 +118,7 @@ func (p *planner) addColumnImpl(
 
 	// We're checking to see if a user is trying add a non-nullable column without a default to a
 	// non empty table by scanning the primary index span with a limit of 1 to see if any key exists.
-	if !col.Nullable && (col.DefaultExpr == nil && !col.IsComputed()) {
+	if !nullable && (col.DefaultExpr == nil && !col.IsComputed()) {
 		span := n.tableDesc.PrimaryIndexSpan(params.ExecCfg().Codec)
 		kvs, err := params.p.txn.Scan(params.ctx, span.Key, span.EndKey, 1)
 		if err != nil {
@@ -108,6 +108,9 @@ func AlterColumnType(
 	if err != nil {
 		return err
 	}
+	if err := tabledesc.CheckColumnTypeIsSupported(ctx, params.ExecCfg().Settings, typ); err != nil {
+		return err
+	}
 
 	var kind schemachange.ColumnConversionKind
 	if t.Using != nil {
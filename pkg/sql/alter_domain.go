@@ -0,0 +1,298 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type alterDomainNode struct {
+	n        *tree.AlterDomain
+	typeName *tree.TypeName
+	desc     *typedesc.Mutable
+}
+
+// alterDomainNode implements planNode. We set n here to satisfy the linter.
+var _ planNode = &alterDomainNode{n: nil}
+
+// AlterDomain alters the default, nullability or CHECK constraints of a
+// domain.
+// Privileges: ownership of the domain.
+func (p *planner) AlterDomain(ctx context.Context, n *tree.AlterDomain) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"ALTER DOMAIN",
+	); err != nil {
+		return nil, err
+	}
+
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.Domains) {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot run ALTER DOMAIN before system is fully upgraded to v22.2",
+		)
+	}
+
+	prefix, desc, err := p.ResolveMutableTypeDescriptor(ctx, n.Domain, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	typeName := tree.MakeTypeNameWithPrefix(prefix.NamePrefix(), desc.GetName())
+	if desc.Kind != descpb.TypeDescriptor_DOMAIN {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", typeName.String())
+	}
+
+	// The user needs ownership privilege to alter the domain.
+	if err := p.canModifyType(ctx, desc); err != nil {
+		return nil, err
+	}
+
+	return &alterDomainNode{
+		n:        n,
+		typeName: &typeName,
+		desc:     desc,
+	}, nil
+}
+
+func (n *alterDomainNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeAlterCounterWithExtra("domain", n.n.Cmd.TelemetryName()))
+
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterDomainSetDefault:
+		n.desc.DomainDefaultExpr = nil
+		if t.Default != nil {
+			expr, err := params.p.makeDomainDefaultExpr(params.ctx, n.desc.DomainBaseType, t.Default)
+			if err != nil {
+				return err
+			}
+			n.desc.DomainDefaultExpr = expr
+		}
+
+	case *tree.AlterDomainSetNotNull:
+		if t.NotNull {
+			return errors.WithHint(
+				unimplemented.Newf("alter domain set not null",
+					"ALTER DOMAIN SET NOT NULL is not supported"),
+				"Consider adding a CHECK (VALUE IS NOT NULL) constraint instead.",
+			)
+		}
+		n.desc.DomainNotNull = false
+
+	case *tree.AlterDomainAddConstraint:
+		if t.Constraint.Check == nil {
+			return errors.AssertionFailedf("expected a CHECK constraint, found %s",
+				tree.AsString(&t.Constraint))
+		}
+		c, err := params.p.makeDomainCheckConstraint(
+			params.ctx, n.typeName, n.desc.DomainBaseType, n.desc.DomainConstraints, &t.Constraint,
+		)
+		if err != nil {
+			return err
+		}
+		// The existing values of the domain are validated against the new
+		// constraint by the type schema change job, unless NOT VALID was
+		// specified. In both cases, the constraint is enforced on new values
+		// right away.
+		c.Validity = descpb.ConstraintValidity_Validating
+		if t.ValidationBehavior == tree.ValidationSkip {
+			c.Validity = descpb.ConstraintValidity_Unvalidated
+		}
+		n.desc.DomainConstraints = append(n.desc.DomainConstraints, c)
+
+	case *tree.AlterDomainDropConstraint:
+		idx, err := n.findConstraint(t.Constraint, t.IfExists)
+		if err != nil {
+			return err
+		}
+		if idx == -1 {
+			params.p.BufferClientNotice(
+				params.ctx,
+				pgnotice.Newf("constraint %q of domain %q does not exist, skipping",
+					t.Constraint, n.typeName.Object()),
+			)
+			return nil
+		}
+		n.desc.DomainConstraints = append(
+			n.desc.DomainConstraints[:idx], n.desc.DomainConstraints[idx+1:]...,
+		)
+
+	case *tree.AlterDomainValidateConstraint:
+		idx, err := n.findConstraint(t.Constraint, false /* ifExists */)
+		if err != nil {
+			return err
+		}
+		c := &n.desc.DomainConstraints[idx]
+		if c.Validity == descpb.ConstraintValidity_Validated {
+			return nil
+		}
+		// The constraint is already enforced on new values, so only the existing
+		// values need to be validated.
+		if err := params.p.WithInternalExecutor(params.ctx, func(
+			ctx context.Context, txn *kv.Txn, ie sqlutil.InternalExecutor,
+		) error {
+			return validateDomainConstraint(ctx, txn, params.p.Descriptors(), ie, n.desc, c)
+		}); err != nil {
+			return err
+		}
+		c.Validity = descpb.ConstraintValidity_Validated
+
+	default:
+		return errors.AssertionFailedf("unknown alter domain cmd %s", t)
+	}
+
+	if err := params.p.writeTypeSchemaChange(
+		params.ctx, n.desc, tree.AsStringWithFQNames(n.n, params.p.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Write a log event.
+	return params.p.logEvent(params.ctx,
+		n.desc.ID,
+		&eventpb.AlterType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// findConstraint returns the index of the constraint of the domain with the
+// given name. If the constraint does not exist, -1 is returned if ifExists is
+// true, and an error otherwise. An error is also returned if the constraint is
+// still being added.
+func (n *alterDomainNode) findConstraint(name tree.Name, ifExists bool) (int, error) {
+	for i := range n.desc.DomainConstraints {
+		c := &n.desc.DomainConstraints[i]
+		if c.Name != string(name) {
+			continue
+		}
+		if c.Validity == descpb.ConstraintValidity_Validating {
+			return 0, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"constraint %q in the middle of being added, try again later", name)
+		}
+		return i, nil
+	}
+	if ifExists {
+		return -1, nil
+	}
+	return 0, pgerror.Newf(pgcode.UndefinedObject,
+		"constraint %q of domain %q does not exist", name, n.typeName.Object())
+}
+
+func (n *alterDomainNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *alterDomainNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *alterDomainNode) Close(ctx context.Context)           {}
+func (n *alterDomainNode) ReadingOwnWrites()                   {}
+
+// validateDomainConstraint returns an error if any value of the given domain
+// stored in a table, either directly in a column of the domain or as an
+// element of an array of the domain, violates the given CHECK constraint.
+func validateDomainConstraint(
+	ctx context.Context,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	ie sqlutil.InternalExecutor,
+	typeDesc catalog.TypeDescriptor,
+	c *descpb.TypeDescriptor_DomainConstraint,
+) error {
+	checkExpr, err := parser.ParseExpr(c.Expr)
+	if err != nil {
+		return err
+	}
+	base := typeDesc.TypeDesc().DomainBaseType
+	// violation returns a predicate that is true if the given value violates
+	// the constraint. The value is cast to the base type of the domain so that
+	// the constraint expression type checks the same way it does when the
+	// constraint is enforced.
+	violation := func(value *tree.UnresolvedName) (string, error) {
+		expr, err := tree.ReplaceDomainValue(checkExpr, &tree.CastExpr{
+			Expr:       value,
+			Type:       base,
+			SyntaxMode: tree.CastShort,
+		})
+		if err != nil {
+			return "", err
+		}
+		return tree.Serialize(&tree.NotExpr{Expr: &tree.ParenExpr{Expr: expr}}), nil
+	}
+
+	domainOID := catid.TypeIDToOID(typeDesc.GetID())
+	for _, id := range typeDesc.GetReferencingDescriptorIDs() {
+		desc, err := descsCol.GetImmutableDescriptorByID(ctx, txn, id, tree.CommonLookupFlags{
+			AvoidLeased: true,
+			Required:    true,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not validate constraint %q", c.Name)
+		}
+		tableDesc, ok := desc.(catalog.TableDescriptor)
+		if !ok || !tableDesc.IsPhysicalTable() {
+			continue
+		}
+		for _, col := range tableDesc.PublicColumns() {
+			var pred string
+			switch typ := col.GetType(); {
+			case typ.Oid() == domainOID:
+				if pred, err = violation(tree.NewUnresolvedName("t", col.GetName())); err != nil {
+					return err
+				}
+			case typ.Family() == types.ArrayFamily && typ.ArrayContents().Oid() == domainOID:
+				elemPred, err := violation(tree.NewUnresolvedName("u", "v"))
+				if err != nil {
+					return err
+				}
+				colName := col.ColName()
+				pred = fmt.Sprintf(
+					"EXISTS (SELECT 1 FROM unnest(t.%s) AS u(v) WHERE %s)", colName.String(), elemPred,
+				)
+			default:
+				continue
+			}
+			query := fmt.Sprintf("SELECT 1 FROM [%d AS t] WHERE %s LIMIT 1", tableDesc.GetID(), pred)
+			row, err := ie.QueryRowEx(
+				ctx, "validate-domain-constraint", txn,
+				sessiondata.InternalExecutorOverride{User: username.RootUserName()},
+				query,
+			)
+			if err != nil {
+				return errors.Wrapf(err, "could not validate constraint %q", c.Name)
+			}
+			if row != nil {
+				return pgerror.Newf(pgcode.CheckViolation,
+					"column %q of table %q contains values that violate the new constraint",
+					col.GetName(), tableDesc.GetName())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/decodeusername"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type alterPublicationNode struct {
+	n      *tree.AlterPublication
+	dbDesc *dbdesc.Mutable
+	pub    *descpb.PublicationDescriptor
+	// tableIDs are the resolved tables of an ALTER PUBLICATION ... TABLE
+	// command.
+	tableIDs []descpb.ID
+	// newOwner is the resolved owner of an ALTER PUBLICATION ... OWNER TO
+	// command.
+	newOwner username.SQLUsername
+}
+
+// AlterPublication alters a publication of the current database.
+// Privileges: ownership of the publication, and ownership of the tables added
+// to it.
+func (p *planner) AlterPublication(
+	ctx context.Context, n *tree.AlterPublication,
+) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"ALTER PUBLICATION",
+	); err != nil {
+		return nil, err
+	}
+	if err := checkPublicationsEnabled(ctx, p.ExecCfg(), "ALTER PUBLICATION"); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.getMutableDatabaseForPublication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pub := dbDesc.GetMutablePublication(string(n.Name))
+	if pub == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"publication %q does not exist", n.Name)
+	}
+	if err := p.checkPublicationOwnership(ctx, pub); err != nil {
+		return nil, err
+	}
+
+	node := &alterPublicationNode{n: n, dbDesc: dbDesc, pub: pub}
+	switch t := n.Cmd.(type) {
+	case *tree.AlterPublicationTables:
+		if pub.AllTables {
+			return nil, errors.WithDetail(
+				pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"publication %q is defined as FOR ALL TABLES", n.Name),
+				"Tables cannot be added to or dropped from FOR ALL TABLES publications.",
+			)
+		}
+		if t.Action == tree.AlterPublicationDropTables {
+			node.tableIDs, err = p.resolvePublicationTablesToDrop(ctx, pub, t.Tables)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		tables, err := p.resolvePublicationTables(ctx, dbDesc, t.Tables)
+		if err != nil {
+			return nil, err
+		}
+		if t.Action == tree.AlterPublicationAddTables {
+			for _, desc := range tables {
+				if publicationHasTable(pub, desc.GetID()) {
+					return nil, pgerror.Newf(pgcode.DuplicateObject,
+						"relation %q is already member of publication %q",
+						desc.GetName(), pub.Name)
+				}
+			}
+		}
+		node.tableIDs = publicationTableIDs(tables)
+
+	case *tree.AlterPublicationRename:
+		if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+			return nil, err
+		}
+		if t.NewName != n.Name && dbDesc.FindPublicationByName(string(t.NewName)) != nil {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"publication %q already exists", t.NewName)
+		}
+
+	case *tree.AlterPublicationOwner:
+		newOwner, err := decodeusername.FromRoleSpec(
+			p.SessionData(), username.PurposeValidation, t.Owner,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkCanAlterPublicationOwner(ctx, newOwner); err != nil {
+			return nil, err
+		}
+		node.newOwner = newOwner
+	}
+	return node, nil
+}
+
+// resolvePublicationTablesToDrop resolves the tables dropped from a publication
+// by ALTER PUBLICATION ... DROP TABLE, which must be part of it.
+func (p *planner) resolvePublicationTablesToDrop(
+	ctx context.Context, pub *descpb.PublicationDescriptor, names tree.TableNames,
+) ([]descpb.ID, error) {
+	ids := make([]descpb.ID, 0, len(names))
+	for i := range names {
+		tn := &names[i]
+		_, desc, err := resolver.ResolveExistingTableObject(
+			ctx, p, tn, tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !publicationHasTable(pub, desc.GetID()) {
+			return nil, pgerror.Newf(pgcode.UndefinedObject,
+				"relation %q is not part of the publication", desc.GetName())
+		}
+		ids = append(ids, desc.GetID())
+	}
+	return ids, nil
+}
+
+// checkCanAlterPublicationOwner returns an error unless the current user can
+// transfer the ownership of a publication to the given role. The current user
+// already owns the publication.
+func (p *planner) checkCanAlterPublicationOwner(
+	ctx context.Context, newOwner username.SQLUsername,
+) error {
+	roleExists, err := RoleExists(ctx, p.ExecCfg().InternalExecutor, p.Txn(), newOwner)
+	if err != nil {
+		return err
+	}
+	if !roleExists {
+		return pgerror.Newf(pgcode.UndefinedObject, "role/user %q does not exist", newOwner)
+	}
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin || p.User() == newOwner {
+		return nil
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, p.User())
+	if err != nil {
+		return err
+	}
+	if _, ok := memberOf[newOwner]; ok {
+		return nil
+	}
+	return pgerror.Newf(pgcode.InsufficientPrivilege, "must be member of role %q", newOwner)
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because ALTER PUBLICATION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *alterPublicationNode) ReadingOwnWrites() {}
+
+func (n *alterPublicationNode) startExec(params runParams) error {
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterPublicationTables:
+		switch t.Action {
+		case tree.AlterPublicationAddTables:
+			n.pub.TableIDs = append(n.pub.TableIDs, n.tableIDs...)
+		case tree.AlterPublicationSetTables:
+			n.pub.TableIDs = n.tableIDs
+		case tree.AlterPublicationDropTables:
+			remaining := n.pub.TableIDs[:0]
+			for _, id := range n.pub.TableIDs {
+				dropped := false
+				for _, droppedID := range n.tableIDs {
+					dropped = dropped || id == droppedID
+				}
+				if !dropped {
+					remaining = append(remaining, id)
+				}
+			}
+			n.pub.TableIDs = remaining
+		}
+		if err := n.removeDroppedTables(params); err != nil {
+			return err
+		}
+
+	case *tree.AlterPublicationSetParams:
+		if err := storageparam.Set(
+			params.ctx,
+			params.p.SemaCtx(),
+			params.EvalContext(),
+			t.Params,
+			&publicationParamSetter{pub: n.pub},
+		); err != nil {
+			return err
+		}
+
+	case *tree.AlterPublicationRename:
+		n.pub.Name = string(t.NewName)
+
+	case *tree.AlterPublicationOwner:
+		n.pub.OwnerProto = n.newOwner.EncodeProto()
+	}
+
+	if err := params.p.writeNonDropDatabaseChange(
+		params.ctx, n.dbDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		n.dbDesc.GetID(),
+		&eventpb.AlterPublication{
+			PublicationName: string(n.n.Name),
+		})
+}
+
+// removeDroppedTables removes the IDs of the tables which have been dropped
+// since they were added to the publication.
+func (n *alterPublicationNode) removeDroppedTables(params runParams) error {
+	tables, err := getPublicationTables(
+		params.ctx, params.p.Descriptors(), params.p.Txn(), n.dbDesc, n.pub,
+	)
+	if err != nil {
+		return err
+	}
+	n.pub.TableIDs = publicationTableIDs(tables)
+	return nil
+}
+
+func (*alterPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterPublicationNode) Close(context.Context)        {}
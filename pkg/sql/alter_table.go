@@ -230,6 +230,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 					}
 					continue
 				}
+				if err := checkIndexedUniqueIsNotDeferrable(d); err != nil {
+					return err
+				}
 
 				if d.PrimaryKey {
 					// Translate this operation into an ALTER PRIMARY KEY command.
@@ -350,6 +353,35 @@ func (n *alterTableNode) startExec(params runParams) error {
 						return err
 					}
 				}
+			case *tree.ExclusionConstraintTableDef:
+				tableName, err := params.p.getQualifiedTableName(params.ctx, n.tableDesc)
+				if err != nil {
+					return err
+				}
+				idx, err := makeExclusionIndexDescriptor(
+					params.ctx,
+					params.ExecCfg().Settings,
+					n.tableDesc,
+					d,
+					tableName,
+					params.p.SemaCtx(),
+				)
+				if err != nil {
+					return err
+				}
+				idx.CreatedAtNanos = params.EvalContext().GetTxnTimestamp(time.Microsecond).UnixNano()
+				// The existing rows are validated against the constraint once the
+				// index has been backfilled. In the meantime, the constraint is
+				// enforced on writes as soon as the index is write-only.
+				if err := n.tableDesc.AddIndexMutationMaybeWithTempIndex(
+					&idx, descpb.DescriptorMutation_ADD,
+				); err != nil {
+					return err
+				}
+				version := params.ExecCfg().Settings.Version.ActiveVersion(params.ctx)
+				if err := n.tableDesc.AllocateIDs(params.ctx, version); err != nil {
+					return err
+				}
 			case *tree.CheckConstraintTableDef:
 				var err error
 				params.p.runWithOptions(resolveFlags{contextDatabaseID: n.tableDesc.ParentID}, func() {
@@ -571,6 +603,12 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 				ck.Validity = descpb.ConstraintValidity_Validated
 
+			case descpb.ConstraintTypeExclusion:
+				// Exclusion constraints are only unvalidated while their index is
+				// being added, and are validated by the schema change.
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"constraint %q in the middle of being added, try again later", t.Constraint)
+
 			case descpb.ConstraintTypeFK:
 				var foundFk *descpb.ForeignKeyConstraint
 				for i := range n.tableDesc.OutboundFKs {
@@ -809,7 +847,7 @@ func (n *alterTableNode) startExec(params runParams) error {
 			// lead to renames of the underlying index. Ensure that no index with this
 			// new name exists. This is what postgres does.
 			switch details.Kind {
-			case descpb.ConstraintTypeUnique, descpb.ConstraintTypePK:
+			case descpb.ConstraintTypeUnique, descpb.ConstraintTypePK, descpb.ConstraintTypeExclusion:
 				if catalog.FindNonDropIndex(n.tableDesc, func(idx catalog.Index) bool {
 					return idx.GetName() == string(t.NewName)
 				}) != nil {
@@ -1466,6 +1504,24 @@ func validateConstraintNameIsNotUsed(
 		}
 		return false, pgerror.Newf(pgcode.DuplicateObject, "constraint with name %q already exists", name)
 
+	case *tree.ExclusionConstraintTableDef:
+		name = d.Name
+		hasIfNotExists = d.IfNotExists
+		if name == "" {
+			return false, nil
+		}
+		// Exclusion constraints are backed by an index, so their name must not be
+		// used by any index either.
+		if idx, _ := tableDesc.FindIndexWithName(string(name)); idx != nil {
+			if d.IfNotExists {
+				return true, nil
+			}
+			if idx.Dropped() {
+				return false, pgerror.Newf(pgcode.DuplicateObject, "constraint with name %q already exists and is being dropped, try again later", name)
+			}
+			return false, pgerror.Newf(pgcode.DuplicateObject, "constraint with name %q already exists", name)
+		}
+
 	default:
 		return false, errors.AssertionFailedf(
 			"unsupported constraint: %T", cmd.ConstraintDef)
@@ -77,6 +77,15 @@ func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, e
 		}
 	case descpb.TypeDescriptor_ENUM:
 		sqltelemetry.IncrementEnumCounter(sqltelemetry.EnumAlter)
+	case descpb.TypeDescriptor_DOMAIN:
+		switch n.Cmd.(type) {
+		case *tree.AlterTypeAddValue, *tree.AlterTypeRenameValue, *tree.AlterTypeDropValue:
+			return nil, pgerror.Newf(
+				pgcode.WrongObjectType,
+				"%q is not an enum",
+				tree.AsStringWithFQNames(n.Type, &p.semaCtx.Annotations),
+			)
+		}
 	case descpb.TypeDescriptor_TABLE_IMPLICIT_RECORD_TYPE:
 		return nil, pgerror.Newf(
 			pgcode.WrongObjectType,
@@ -334,6 +334,22 @@ func runPlanInsidePlan(
 	params.p.extendedEvalCtx.ExecCfg.DistSQLPlanner.PlanAndRun(
 		ctx, evalCtx, planCtx, params.p.Txn(), plan.main, recv,
 	)
+	if err := resultWriter.Err(); err != nil {
+		return err
+	}
+
+	// The inner plan may contain mutations (e.g. a statement in the body of a
+	// procedure), in which case its cascades and checks must be run too. The
+	// inner plan can never commit the transaction.
+	plannerCopy.autoCommit = false
+	evalCtxFactory := func() *extendedEvalContext {
+		factoryEvalCtx := params.p.ExtendedEvalContextCopy()
+		factoryEvalCtx.Planner = &plannerCopy
+		return factoryEvalCtx
+	}
+	params.p.extendedEvalCtx.ExecCfg.DistSQLPlanner.PlanAndRunCascadesAndChecks(
+		ctx, &plannerCopy, evalCtxFactory, plan, recv,
+	)
 	return resultWriter.Err()
 }
 
@@ -1499,6 +1499,26 @@ func (sc *SchemaChanger) validateIndexes(ctx context.Context) error {
 			)
 		})
 	}
+	// Exclusion constraints are backed by inverted indexes, unless they compare
+	// ranges, in which case they are backed by forward indexes.
+	for _, indexes := range [][]catalog.Index{forwardIndexes, invertedIndexes} {
+		for _, idx := range indexes {
+			if !idx.IsExclusion() {
+				continue
+			}
+			idx := idx
+			grp.GoCtx(func(ctx context.Context) error {
+				return validateExclusionConstraint(
+					ctx,
+					tableDesc,
+					idx,
+					runHistoricalTxn,
+					true, /* withFirstMutationPublic */
+					sessiondata.InternalExecutorOverride{},
+				)
+			})
+		}
+	}
 	if err := grp.Wait(); err != nil {
 		return err
 	}
@@ -1698,6 +1718,72 @@ func countExpectedRowsForInvertedIndex(
 
 }
 
+// validateExclusionConstraint checks that no two rows of the table conflict
+// with each other according to the exclusion constraint backed by the given
+// index.
+func validateExclusionConstraint(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	idx catalog.Index,
+	runHistoricalTxn sqlutil.HistoricalInternalExecTxnRunner,
+	withFirstMutationPublic bool,
+	execOverride sessiondata.InternalExecutorOverride,
+) error {
+	desc := tableDesc
+	start := timeutil.Now()
+	if withFirstMutationPublic {
+		// Make the mutations public in an in-memory copy of the descriptor and
+		// add it to the Collection's synthetic descriptors, so that we can use
+		// SQL below to perform the validation.
+		fakeDesc, err := tableDesc.MakeFirstMutationPublic(catalog.IgnoreConstraints)
+		if err != nil {
+			return err
+		}
+		desc = fakeDesc
+	}
+
+	query, colNames, err := exclusionViolationQuery(desc, idx)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "validating exclusion constraint %q (%q [%v]) with query %q",
+		idx.GetName(), desc.GetName(), colNames, query)
+
+	var values tree.Datums
+	if err := runHistoricalTxn(ctx, func(ctx context.Context, txn *kv.Txn, ie sqlutil.InternalExecutor) error {
+		return ie.WithSyntheticDescriptors([]catalog.Descriptor{desc}, func() error {
+			values, err = ie.QueryRowEx(ctx, "validate-exclusion-constraint", txn, execOverride, query)
+			return err
+		})
+	}); err != nil {
+		return err
+	}
+	log.Infof(ctx, "validated exclusion constraint %s/%s, took %s",
+		desc.GetName(), idx.GetName(), timeutil.Since(start))
+
+	if values.Len() > 0 {
+		valuesStr := make([]string, len(values))
+		for i := range values {
+			valuesStr[i] = values[i].String()
+		}
+		// Note: this error message mirrors the message produced by Postgres
+		// when it fails to add an exclusion constraint due to conflicting keys.
+		return errors.WithDetail(
+			pgerror.WithConstraintName(
+				pgerror.Newf(
+					pgcode.ExclusionViolation, "could not create exclusion constraint %q", idx.GetName(),
+				),
+				idx.GetName(),
+			),
+			fmt.Sprintf(
+				"Key (%s)=(%s) conflicts with another row.",
+				strings.Join(colNames, ","), strings.Join(valuesStr, ","),
+			),
+		)
+	}
+	return nil
+}
+
 // ValidateForwardIndexes checks that the indexes have entries for all the rows.
 //
 // This operates over multiple goroutines concurrently and is thus not
@@ -0,0 +1,154 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/errors"
+)
+
+// ReadBackupTableCCL is the public hook point for the CCL-licensed code which
+// reads the rows of a table attached from a backup. It reads the given spans
+// of the primary index of the table from the files of the backup, decodes the
+// columns in fetchCols, and passes the rows to emit in primary key order.
+var ReadBackupTableCCL = func(
+	ctx context.Context,
+	p PlanHookState,
+	table catalog.TableDescriptor,
+	spans roachpb.Spans,
+	fetchCols []descpb.ColumnID,
+	emit func(context.Context, tree.Datums) error,
+) error {
+	return sqlerrors.NewCCLRequiredError(errors.New(
+		"reading tables attached from a backup requires a CCL binary"))
+}
+
+// backupScanNode scans the primary index of a table attached from a backup
+// with ATTACH BACKUP. The rows are read from the files of the backup through
+// ReadBackupTableCCL rather than from KV, so the node cannot be distributed.
+type backupScanNode struct {
+	desc    catalog.TableDescriptor
+	columns colinfo.ResultColumns
+	// fetchCols are the IDs of the columns of the table, in the order of
+	// columns.
+	fetchCols []descpb.ColumnID
+	spans     roachpb.Spans
+	// hardLimit, if positive, is the maximum number of rows to return.
+	hardLimit   int64
+	reqOrdering ReqOrdering
+
+	next       virtualTableGenerator
+	cleanup    cleanupFunc
+	numRows    int64
+	currentRow tree.Datums
+}
+
+// constructBackupScan constructs a backupScanNode for a scan of a table
+// attached from a backup. The optimizer only plans forward scans of the
+// primary index of such tables, without locking.
+func (ef *execFactory) constructBackupScan(
+	table cat.Table, index cat.Index, params exec.ScanParams, reqOrdering exec.OutputOrdering,
+) (exec.Node, error) {
+	tabDesc := table.(*optTable).desc
+	idx := index.(*optIndex).idx
+	if idx.GetID() != tabDesc.GetPrimaryIndexID() || params.InvertedConstraint != nil {
+		return nil, errors.AssertionFailedf(
+			"table %q attached from a backup can only be scanned through its primary index", tabDesc.GetName())
+	}
+	if params.Reverse {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"reverse scans are not supported on table %q attached from a backup", tabDesc.GetName())
+	}
+	if params.Locking.IsLocking() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"row-level locking is not supported on table %q attached from a backup", tabDesc.GetName())
+	}
+
+	colCfg := makeScanColumnsConfig(table, params.NeededCols)
+	cols, err := initColsForScan(tabDesc, colCfg)
+	if err != nil {
+		return nil, err
+	}
+	fetchCols := make([]descpb.ColumnID, len(cols))
+	for i, col := range cols {
+		if col.IsSystemColumn() {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"system column %q is not supported on table %q attached from a backup",
+				col.GetName(), tabDesc.GetName())
+		}
+		fetchCols[i] = col.GetID()
+	}
+	columns := colinfo.ResultColumnsFromColumns(tabDesc.GetID(), cols)
+	if params.IndexConstraint != nil && params.IndexConstraint.IsContradiction() {
+		return newZeroNode(columns), nil
+	}
+	if err := colCfg.assertValidReqOrdering(reqOrdering); err != nil {
+		return nil, err
+	}
+
+	spans, err := generateScanSpans(ef.planner.EvalContext(), ef.planner.ExecCfg().Codec, tabDesc, idx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &backupScanNode{
+		desc:        tabDesc,
+		columns:     columns,
+		fetchCols:   fetchCols,
+		spans:       spans,
+		hardLimit:   params.HardLimit,
+		reqOrdering: ReqOrdering(reqOrdering),
+	}, nil
+}
+
+func (n *backupScanNode) startExec(params runParams) error {
+	var err error
+	n.next, n.cleanup, err = setupGenerator(params.ctx, func(ctx context.Context, pusher rowPusher) error {
+		return ReadBackupTableCCL(ctx, params.p, n.desc, n.spans, n.fetchCols,
+			func(ctx context.Context, row tree.Datums) error {
+				return pusher.pushRow(row...)
+			})
+	}, params.ExecCfg().Stopper)
+	return err
+}
+
+func (n *backupScanNode) Next(params runParams) (bool, error) {
+	if n.hardLimit > 0 && n.numRows >= n.hardLimit {
+		return false, nil
+	}
+	row, err := n.next()
+	if err != nil || row == nil {
+		return false, err
+	}
+	n.numRows++
+	n.currentRow = row
+	return true, nil
+}
+
+func (n *backupScanNode) Values() tree.Datums {
+	return n.currentRow
+}
+
+func (n *backupScanNode) Close(ctx context.Context) {
+	if n.cleanup != nil {
+		n.cleanup(ctx)
+	}
+}
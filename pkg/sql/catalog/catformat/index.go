@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/errors"
@@ -181,6 +182,66 @@ func indexForDisplay(
 	return f.CloseAndGetString(), nil
 }
 
+// ExclusionConstraintForDisplay formats an index backing an exclusion
+// constraint as the EXCLUDE clause of the constraint. For example:
+//
+//	EXCLUDE USING gist (room WITH =, slots WITH &&) WHERE (NOT cancelled)
+//
+// The non-inverted key columns of the index are compared with the equality
+// operator, and the inverted column is compared with the overlap operator. If
+// the constraint compares ranges, the last two key columns are instead the
+// bounds of the range compared with the overlap operator:
+//
+//	EXCLUDE USING gist (room WITH =, tstzrange(start_at, end_at) WITH &&)
+func ExclusionConstraintForDisplay(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	formatFlags tree.FmtFlags,
+	semaCtx *tree.SemaContext,
+	sessionData *sessiondata.SessionData,
+) (string, error) {
+	desc := index.IndexDesc()
+	f := tree.NewFmtCtx(formatFlags)
+	f.WriteString("EXCLUDE USING gist (")
+	startIdx := desc.ExplicitColumnStartIdx()
+	n := len(desc.KeyColumnNames)
+	eqEnd := n - 1
+	if desc.ExclusionRangeFunc != "" {
+		eqEnd = n - 2
+	}
+	for i := startIdx; i < eqEnd; i++ {
+		f.FormatNameP(&desc.KeyColumnNames[i])
+		f.WriteString(" WITH =, ")
+	}
+	if desc.ExclusionRangeFunc != "" {
+		f.WriteString(desc.ExclusionRangeFunc)
+		f.WriteByte('(')
+		f.FormatNameP(&desc.KeyColumnNames[n-2])
+		f.WriteString(", ")
+		f.FormatNameP(&desc.KeyColumnNames[n-1])
+		f.WriteByte(')')
+	} else {
+		f.FormatNameP(&desc.KeyColumnNames[n-1])
+	}
+	f.WriteString(" WITH &&)")
+
+	if desc.IsPartial() {
+		predFmtFlag := tree.FmtParsable
+		if f.HasFlags(tree.FmtPGCatalog) {
+			predFmtFlag = tree.FmtPGCatalog
+		}
+		pred, err := schemaexpr.FormatExprForDisplay(ctx, table, desc.Predicate, semaCtx, sessionData, predFmtFlag)
+		if err != nil {
+			return "", err
+		}
+		f.WriteString(" WHERE (")
+		f.WriteString(pred)
+		f.WriteByte(')')
+	}
+	return f.CloseAndGetString(), nil
+}
+
 // FormatIndexElements formats the key columns an index. If the column is an
 // inaccessible computed column, the computed column expression is formatted.
 // Otherwise, the column name is formatted. Each column is separated by commas
@@ -314,6 +375,30 @@ func formatStorageConfigs(
 		}
 	}
 
+	if opts := index.SSTOptions; opts != nil {
+		for _, param := range []struct {
+			key   string
+			val   string
+			isSet bool
+		}{
+			{`compression`, lexbase.EscapeSQLString(opts.Compression), opts.Compression != ""},
+			{`block_size`, strconv.FormatInt(opts.BlockSize, 10), opts.BlockSize != 0},
+		} {
+			if !param.isSet {
+				continue
+			}
+			if numCustomSettings > 0 {
+				f.WriteString(", ")
+			} else {
+				f.WriteString(" WITH (")
+			}
+			numCustomSettings++
+			f.WriteString(param.key)
+			f.WriteString("=")
+			f.WriteString(param.val)
+		}
+	}
+
 	if index.IsSharded() {
 		if numCustomSettings > 0 {
 			f.WriteString(", ")
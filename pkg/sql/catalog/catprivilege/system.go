@@ -64,6 +64,7 @@ var (
 		catconstants.SpanCountTableName,
 		catconstants.SystemPrivilegeTableName,
 		catconstants.SystemExternalConnectionsTableName,
+		catconstants.SystemNotificationsTableName,
 	}
 
 	readWriteSystemSequences = []catconstants.SystemTableName{
@@ -228,6 +228,16 @@ func (desc *immutable) GetNonDroppedSchemaName(schemaID descpb.ID) string {
 	return ""
 }
 
+// FindPublicationByName implements the DatabaseDescriptor interface.
+func (desc *immutable) FindPublicationByName(name string) *descpb.PublicationDescriptor {
+	for i := range desc.Publications {
+		if desc.Publications[i].Name == name {
+			return &desc.Publications[i]
+		}
+	}
+	return nil
+}
+
 // ValidateSelf validates that the database descriptor is well formed.
 // Checks include validate the database name, and verifying that there
 // is at least one read and write user.
@@ -254,6 +264,37 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 	if desc.IsMultiRegion() {
 		desc.validateMultiRegion(vea)
 	}
+
+	desc.validatePublications(vea)
+}
+
+func (desc *immutable) validatePublications(vea catalog.ValidationErrorAccumulator) {
+	names := make(map[string]struct{}, len(desc.Publications))
+	ids := make(map[descpb.PublicationID]struct{}, len(desc.Publications))
+	for i := range desc.Publications {
+		pub := &desc.Publications[i]
+		if pub.Name == "" {
+			vea.Report(errors.AssertionFailedf("publication %d has empty name", pub.ID))
+		}
+		if _, ok := names[pub.Name]; ok {
+			vea.Report(errors.AssertionFailedf("duplicate publication name: %q", pub.Name))
+		}
+		names[pub.Name] = struct{}{}
+		if pub.ID == 0 || pub.ID >= desc.NextPublicationID {
+			vea.Report(errors.AssertionFailedf("publication %q has invalid ID %d", pub.Name, pub.ID))
+		}
+		if _, ok := ids[pub.ID]; ok {
+			vea.Report(errors.AssertionFailedf("publication %q has duplicate ID %d", pub.Name, pub.ID))
+		}
+		ids[pub.ID] = struct{}{}
+		if pub.OwnerProto == "" {
+			vea.Report(errors.AssertionFailedf("publication %q has no owner", pub.Name))
+		}
+		if pub.AllTables && len(pub.TableIDs) > 0 {
+			vea.Report(errors.AssertionFailedf(
+				"publication %q includes all tables but also has table IDs", pub.Name))
+		}
+	}
 }
 
 // validateMultiRegion performs checks specific to multi-region DBs.
@@ -499,6 +540,40 @@ func (desc *Mutable) AddSchemaToDatabase(
 	desc.Schemas[schemaName] = schemaInfo
 }
 
+// AddPublication allocates an ID for the given publication and adds it to the
+// database. It returns the allocated ID.
+func (desc *Mutable) AddPublication(pub descpb.PublicationDescriptor) descpb.PublicationID {
+	if desc.NextPublicationID == 0 {
+		desc.NextPublicationID = 1
+	}
+	pub.ID = desc.NextPublicationID
+	desc.NextPublicationID++
+	desc.Publications = append(desc.Publications, pub)
+	return pub.ID
+}
+
+// GetMutablePublication returns the publication with the given name, which can
+// be modified in place, or nil if there is none.
+func (desc *Mutable) GetMutablePublication(name string) *descpb.PublicationDescriptor {
+	for i := range desc.Publications {
+		if desc.Publications[i].Name == name {
+			return &desc.Publications[i]
+		}
+	}
+	return nil
+}
+
+// RemovePublication removes the publication with the given ID from the
+// database, if it exists.
+func (desc *Mutable) RemovePublication(id descpb.PublicationID) {
+	for i := range desc.Publications {
+		if desc.Publications[i].ID == id {
+			desc.Publications = append(desc.Publications[:i], desc.Publications[i+1:]...)
+			return
+		}
+	}
+}
+
 // GetDeclarativeSchemaChangerState is part of the catalog.MutableDescriptor
 // interface.
 func (desc *immutable) GetDeclarativeSchemaChangerState() *scpb.DescriptorState {
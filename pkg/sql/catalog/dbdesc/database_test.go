@@ -112,6 +112,55 @@ func TestValidateDatabaseDesc(t *testing.T) {
 				Privileges:   catpb.NewBaseDatabasePrivilegeDescriptor(username.RootUserName()),
 			},
 		},
+		{
+			`duplicate publication name: "p"`,
+			descpb.DatabaseDescriptor{
+				Name:       "db",
+				ID:         200,
+				Privileges: catpb.NewBaseDatabasePrivilegeDescriptor(username.RootUserName()),
+				Publications: []descpb.PublicationDescriptor{
+					{ID: 1, Name: "p", OwnerProto: username.RootUserName().EncodeProto(), AllTables: true},
+					{ID: 2, Name: "p", OwnerProto: username.RootUserName().EncodeProto(), AllTables: true},
+				},
+				NextPublicationID: 3,
+			},
+		},
+		{
+			`publication "p" has invalid ID 3`,
+			descpb.DatabaseDescriptor{
+				Name:       "db",
+				ID:         200,
+				Privileges: catpb.NewBaseDatabasePrivilegeDescriptor(username.RootUserName()),
+				Publications: []descpb.PublicationDescriptor{
+					{ID: 3, Name: "p", OwnerProto: username.RootUserName().EncodeProto(), AllTables: true},
+				},
+				NextPublicationID: 3,
+			},
+		},
+		{
+			`publication "p" has no owner`,
+			descpb.DatabaseDescriptor{
+				Name:       "db",
+				ID:         200,
+				Privileges: catpb.NewBaseDatabasePrivilegeDescriptor(username.RootUserName()),
+				Publications: []descpb.PublicationDescriptor{
+					{ID: 1, Name: "p", TableIDs: []descpb.ID{201}},
+				},
+				NextPublicationID: 2,
+			},
+		},
+		{
+			`publication "p" includes all tables but also has table IDs`,
+			descpb.DatabaseDescriptor{
+				Name:       "db",
+				ID:         200,
+				Privileges: catpb.NewBaseDatabasePrivilegeDescriptor(username.RootUserName()),
+				Publications: []descpb.PublicationDescriptor{
+					{ID: 1, Name: "p", OwnerProto: username.RootUserName().EncodeProto(), AllTables: true, TableIDs: []descpb.ID{201}},
+				},
+				NextPublicationID: 2,
+			},
+		},
 	}
 	for i, d := range testData {
 		t.Run(d.err, func(t *testing.T) {
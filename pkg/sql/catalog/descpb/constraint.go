@@ -80,6 +80,8 @@ const (
 	ConstraintTypeUnique ConstraintType = "UNIQUE"
 	// ConstraintTypeCheck identifies a CHECK constraint.
 	ConstraintTypeCheck ConstraintType = "CHECK"
+	// ConstraintTypeExclusion identifies an EXCLUDE constraint.
+	ConstraintTypeExclusion ConstraintType = "EXCLUDE"
 )
 
 // ConstraintDetail describes a constraint.
@@ -93,7 +95,8 @@ type ConstraintDetail struct {
 	Details      string
 	Unvalidated  bool
 
-	// Only populated for PK and Unique Constraints with an index.
+	// Only populated for PK, Unique Constraints with an index and Exclusion
+	// Constraints.
 	Index *IndexDescriptor
 
 	// Only populated for Unique Constraints without an index.
@@ -122,6 +125,32 @@ func (c *ConstraintDetail) GetConstraintName() string {
 		return c.FK.Name
 	case ConstraintTypeCheck:
 		return c.CheckConstraint.Name
+	case ConstraintTypeExclusion:
+		return c.Index.Name
 	}
 	return ""
 }
+
+// IsDeferrable returns true if the checks for the constraint may be deferred
+// until the end of the transaction.
+func (c *ConstraintDetail) IsDeferrable() bool {
+	switch {
+	case c.FK != nil:
+		return c.FK.Deferrable
+	case c.UniqueWithoutIndexConstraint != nil:
+		return c.UniqueWithoutIndexConstraint.Deferrable
+	}
+	return false
+}
+
+// IsInitiallyDeferred returns true if the checks for the constraint are
+// deferred until the end of the transaction by default.
+func (c *ConstraintDetail) IsInitiallyDeferred() bool {
+	switch {
+	case c.FK != nil:
+		return c.FK.InitiallyDeferred
+	case c.UniqueWithoutIndexConstraint != nil:
+		return c.UniqueWithoutIndexConstraint.InitiallyDeferred
+	}
+	return false
+}
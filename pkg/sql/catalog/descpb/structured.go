@@ -74,6 +74,12 @@ type IndexID = catid.IndexID
 // ConstraintID is a custom type for TableDescriptor constraint IDs.
 type ConstraintID = catid.ConstraintID
 
+// TriggerID is a custom type for TableDescriptor trigger IDs.
+type TriggerID = catid.TriggerID
+
+// PublicationID is a custom type for DatabaseDescriptor publication IDs.
+type PublicationID = catid.PublicationID
+
 // DescriptorVersion is a custom type for TableDescriptor Versions.
 type DescriptorVersion uint64
 
@@ -257,6 +263,11 @@ func (desc *TableDescriptor) IsAs() bool {
 	return desc.CreateQuery != ""
 }
 
+// IsBackupTable implements the TableDescriptor interface.
+func (desc *TableDescriptor) IsBackupTable() bool {
+	return desc.BackupSource != nil
+}
+
 // IsSequence implements the TableDescriptor interface.
 func (desc *TableDescriptor) IsSequence() bool {
 	return desc.SequenceOpts != nil
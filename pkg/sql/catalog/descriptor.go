@@ -277,6 +277,12 @@ type DatabaseDescriptor interface {
 	// HasPublicSchemaWithDescriptor returns true iff the database has a public
 	// schema which itself has a descriptor.
 	HasPublicSchemaWithDescriptor() bool
+	// GetPublications returns the logical replication publications defined in
+	// this database, if there are any.
+	GetPublications() []descpb.PublicationDescriptor
+	// FindPublicationByName returns the publication with the given name, or
+	// nil if there is none.
+	FindPublicationByName(name string) *descpb.PublicationDescriptor
 }
 
 // TableDescriptor is an interface around the table descriptor types.
@@ -317,6 +323,13 @@ type TableDescriptor interface {
 	// IsAs returns true if the TableDescriptor describes a Table that was created
 	// with a CREATE TABLE AS command.
 	IsAs() bool
+	// IsBackupTable returns true if the TableDescriptor describes a Table that
+	// was attached from a backup with ATTACH BACKUP, whose rows are read from
+	// the files of the backup rather than from the KV layer.
+	IsBackupTable() bool
+	// GetBackupSource returns the backup from which the rows of the table are
+	// read, or nil if the table was not attached from a backup.
+	GetBackupSource() *descpb.BackupTableSource
 
 	// GetSequenceOpts returns the sequence options for this table. Only valid if
 	// IsSequence is true.
@@ -678,6 +691,14 @@ type TableDescriptor interface {
 	// referenced by the returned checks are writable, but not necessarily public.
 	ActiveChecks() []descpb.TableDescriptor_CheckConstraint
 
+	// GetTriggers returns the row-level triggers defined on this table, if
+	// there are any.
+	GetTriggers() []descpb.TriggerDescriptor
+	// FindTriggerWithID returns the trigger with the given ID.
+	FindTriggerWithID(id descpb.TriggerID) (*descpb.TriggerDescriptor, error)
+	// FindTriggerWithName returns the trigger with the given name.
+	FindTriggerWithName(name string) (*descpb.TriggerDescriptor, error)
+
 	// GetLocalityConfig returns the locality config for this table, which
 	// describes the table's multi-region locality policy if one is set (e.g.
 	// GLOBAL or REGIONAL BY ROW).
@@ -701,6 +722,9 @@ type TableDescriptor interface {
 	// GetExcludeDataFromBackup returns true if the table's row data is configured
 	// to be excluded during backup.
 	GetExcludeDataFromBackup() bool
+	// GetSSTOptions returns the options with which the SSTs holding the table's
+	// data are written.
+	GetSSTOptions() catpb.SSTOptions
 	// GetStorageParams returns a list of storage parameters for the table.
 	GetStorageParams(spaceBetweenEqual bool) []string
 	// NoAutoStatsSettingsOverrides is true if no auto stats related settings are
@@ -896,6 +920,9 @@ type FunctionDescriptor interface {
 	// GetLanguage returns the language of this function.
 	GetLanguage() catpb.Function_Language
 
+	// GetIsProcedure returns true if the descriptor describes a procedure.
+	GetIsProcedure() bool
+
 	// ToCreateExpr converts a function descriptor back to a CREATE FUNCTION
 	// statement. This is mainly used for formatting, e.g. SHOW CREATE FUNCTION.
 	ToCreateExpr() (*tree.CreateFunction, error)
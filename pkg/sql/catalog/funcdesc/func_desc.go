@@ -338,6 +338,23 @@ func (desc *immutable) validateInboundTableRef(
 		}
 	}
 
+	for _, trigID := range by.TriggerIDs {
+		trig, err := backRefTbl.FindTriggerWithID(trigID)
+		if err != nil {
+			return errors.AssertionFailedf("depended-on-by relation %q (%d) does not have a trigger with ID %d",
+				backRefTbl.GetName(), by.ID, trigID)
+		}
+		if trig.FuncID != desc.GetID() {
+			return errors.AssertionFailedf("trigger %q of depended-on-by relation %q (%d) does not execute this function",
+				trig.Name, backRefTbl.GetName(), by.ID)
+		}
+	}
+	if len(by.TriggerIDs) > 0 {
+		// Triggers refer to the function directly rather than through the
+		// depends-on references of the relation.
+		return nil
+	}
+
 	for _, id := range backRefTbl.GetDependsOn() {
 		if id == desc.GetID() {
 			return nil
@@ -492,6 +509,50 @@ func (desc *Mutable) SetParentSchemaID(id descpb.ID) {
 	desc.ParentSchemaID = id
 }
 
+// AddTriggerReference adds a back reference from the given trigger of the
+// given table to this function.
+func (desc *Mutable) AddTriggerReference(id descpb.ID, triggerID descpb.TriggerID) {
+	for i := range desc.DependedOnBy {
+		ref := &desc.DependedOnBy[i]
+		if ref.ID == id {
+			for _, existing := range ref.TriggerIDs {
+				if existing == triggerID {
+					return
+				}
+			}
+			ref.TriggerIDs = append(ref.TriggerIDs, triggerID)
+			return
+		}
+	}
+	desc.DependedOnBy = append(desc.DependedOnBy, descpb.FunctionDescriptor_Reference{
+		ID:         id,
+		TriggerIDs: []descpb.TriggerID{triggerID},
+	})
+}
+
+// RemoveTriggerReference removes the back reference from the given trigger of
+// the given table to this function. The reference to the table is removed
+// entirely once it no longer refers to anything.
+func (desc *Mutable) RemoveTriggerReference(id descpb.ID, triggerID descpb.TriggerID) {
+	for i := range desc.DependedOnBy {
+		ref := &desc.DependedOnBy[i]
+		if ref.ID != id {
+			continue
+		}
+		for j := range ref.TriggerIDs {
+			if ref.TriggerIDs[j] == triggerID {
+				ref.TriggerIDs = append(ref.TriggerIDs[:j], ref.TriggerIDs[j+1:]...)
+				break
+			}
+		}
+		if len(ref.TriggerIDs) == 0 && len(ref.ColumnIDs) == 0 &&
+			len(ref.IndexIDs) == 0 && len(ref.ConstraintIDs) == 0 {
+			desc.DependedOnBy = append(desc.DependedOnBy[:i], desc.DependedOnBy[i+1:]...)
+		}
+		return
+	}
+}
+
 // ToFuncObj converts the descriptor to a tree.FuncObj.
 func (desc *immutable) ToFuncObj() tree.FuncObj {
 	ret := tree.FuncObj{
@@ -540,15 +601,24 @@ func (desc *immutable) ContainsUserDefinedTypes() bool {
 
 func (desc *immutable) ToOverload() (ret *tree.Overload, err error) {
 	ret = &tree.Overload{
-		Oid:        catid.FuncIDToOID(desc.ID),
-		ReturnType: tree.FixedReturnType(desc.ReturnType.Type),
-		ReturnSet:  desc.ReturnType.ReturnSet,
-		Body:       desc.FunctionBody,
-		IsUDF:      true,
+		Oid:         catid.FuncIDToOID(desc.ID),
+		ReturnType:  tree.FixedReturnType(desc.ReturnType.Type),
+		ReturnSet:   desc.ReturnType.ReturnSet,
+		Body:        desc.FunctionBody,
+		Language:    desc.getCreateExprLang(),
+		IsUDF:       true,
+		IsProcedure: desc.IsProcedure,
+	}
+	if desc.ReturnType.ReturnSet {
+		ret.Class = tree.GeneratorClass
 	}
 
 	argTypes := make(tree.ArgTypes, 0, len(desc.Args))
 	for _, arg := range desc.Args {
+		// OUT arguments are not part of the signature of the function.
+		if arg.Class == catpb.Function_Arg_OUT {
+			continue
+		}
 		argTypes = append(
 			argTypes,
 			tree.ArgType{Name: arg.Name, Typ: arg.Type},
@@ -605,7 +675,8 @@ func (desc *immutable) calledOnNullInput() (bool, error) {
 // ToCreateExpr implements the FunctionDescriptor interface.
 func (desc *immutable) ToCreateExpr() (ret *tree.CreateFunction, err error) {
 	ret = &tree.CreateFunction{
-		FuncName: tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(desc.Name)),
+		IsProcedure: desc.IsProcedure,
+		FuncName:    tree.MakeFunctionNameFromPrefix(tree.ObjectNamePrefix{}, tree.Name(desc.Name)),
 		ReturnType: tree.FuncReturnType{
 			Type:  desc.ReturnType.Type,
 			IsSet: desc.ReturnType.ReturnSet,
@@ -625,6 +696,11 @@ func (desc *immutable) ToCreateExpr() (ret *tree.CreateFunction, err error) {
 			}
 		}
 	}
+	if desc.IsProcedure {
+		// Procedures only have a body and a language.
+		ret.Options = tree.FunctionOptions{tree.FunctionBodyStr(desc.FunctionBody), desc.getCreateExprLang()}
+		return ret, nil
+	}
 	// We only store 5 function attributes at the moment. We may extend the
 	// pre-allocated capacity in the future.
 	ret.Options = make(tree.FunctionOptions, 0, 5)
@@ -640,6 +716,8 @@ func (desc *immutable) getCreateExprLang() tree.FunctionLanguage {
 	switch desc.Lang {
 	case catpb.Function_SQL:
 		return tree.FunctionLangSQL
+	case catpb.Function_PLPGSQL:
+		return tree.FunctionLangPLpgSQL
 	}
 	return 0
 }
@@ -455,6 +455,32 @@ func TestValidateFuncDesc(t *testing.T) {
 				DependsOnTypes: []descpb.ID{typeWithFuncRefID},
 			},
 		},
+		{
+			"depended-on-by relation \"tbl\" (1006) does not have a trigger with ID 1",
+			descpb.FunctionDescriptor{
+				Name:           "f",
+				ID:             funcDescID,
+				ParentID:       dbID,
+				ParentSchemaID: schemaWithFuncRefID,
+				Privileges:     defaultPrivileges,
+				ReturnType: descpb.FunctionDescriptor_ReturnType{
+					Type: types.Int,
+				},
+				Args: []descpb.FunctionDescriptor_Argument{
+					{
+						Name: "arg1",
+						Type: types.Int,
+					},
+				},
+				LeakProof:  true,
+				Volatility: catpb.Function_IMMUTABLE,
+				DependedOnBy: []descpb.FunctionDescriptor_Reference{
+					{ID: tableWithFuncForwardRefID, TriggerIDs: []descpb.TriggerID{1}},
+				},
+				DependsOn:      []descpb.ID{tableWithFuncBackRefID},
+				DependsOnTypes: []descpb.ID{typeWithFuncRefID},
+			},
+		},
 	}
 
 	for i, test := range testData {
@@ -493,6 +519,7 @@ func TestToOverload(t *testing.T) {
 				},
 				ReturnType: tree.FixedReturnType(types.Int),
 				ReturnSet:  true,
+				Class:      tree.GeneratorClass,
 				Volatility: volatility.Leakproof,
 				Body:       "ANY QUERIES",
 				IsUDF:      true,
@@ -539,6 +566,7 @@ func TestToOverload(t *testing.T) {
 				},
 				ReturnType: tree.FixedReturnType(types.Int),
 				ReturnSet:  true,
+				Class:      tree.GeneratorClass,
 				Volatility: volatility.Stable,
 				Body:       "ANY QUERIES",
 				IsUDF:      true,
@@ -562,6 +590,7 @@ func TestToOverload(t *testing.T) {
 				},
 				ReturnType:        tree.FixedReturnType(types.Int),
 				ReturnSet:         true,
+				Class:             tree.GeneratorClass,
 				Volatility:        volatility.Leakproof,
 				Body:              "ANY QUERIES",
 				IsUDF:             true,
@@ -586,6 +615,7 @@ func TestToOverload(t *testing.T) {
 				},
 				ReturnType: tree.FixedReturnType(types.Int),
 				ReturnSet:  true,
+				Class:      tree.GeneratorClass,
 				Volatility: volatility.Leakproof,
 				Body:       "ANY QUERIES",
 				IsUDF:      true,
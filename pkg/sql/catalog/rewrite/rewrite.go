@@ -27,9 +27,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/screl"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -389,6 +391,47 @@ func rewriteSequencesInFunction(fnBody string, rewrites jobspb.DescRewriteMap) (
 	return fmtCtx.CloseAndGetString(), nil
 }
 
+// rewritePLpgSQLFunctionBody rewrites the sequence and type IDs in the SQL
+// embedded in a PL/pgSQL function body according to rewrites, and replaces
+// its non-empty database qualifiers with overrideDB if it is set.
+func rewritePLpgSQLFunctionBody(
+	fnBody string, rewrites jobspb.DescRewriteMap, overrideDB string,
+) (string, error) {
+	block, err := plpgsqlparser.Parse(fnBody)
+	if err != nil {
+		return "", err
+	}
+	if err := plpgsqltree.SimpleVisit(
+		block, makeSequenceReplaceFunc(rewrites), nil, /* typFn */
+	); err != nil {
+		return "", err
+	}
+
+	opts := []tree.FmtCtxOption{
+		tree.FmtIndexedTypeFormat(func(ctx *tree.FmtCtx, ref *tree.OIDTypeReference) {
+			newRef := ref
+			var id descpb.ID
+			id, err = typedesc.UserDefinedTypeOIDToID(ref.OID)
+			if err != nil {
+				return
+			}
+			if rw, ok := rewrites[id]; ok {
+				newRef = &tree.OIDTypeReference{OID: catid.TypeIDToOID(rw.ID)}
+			}
+			ctx.WriteString(newRef.SQLString())
+		}),
+	}
+	if overrideDB != "" {
+		opts = append(opts, tree.FmtReformatTableNames(makeDBNameReplaceFunc(overrideDB)))
+	}
+	f := tree.NewFmtCtx(tree.FmtSimple, opts...)
+	f.FormatNode(block)
+	if err != nil {
+		return "", err
+	}
+	return f.CloseAndGetString(), nil
+}
+
 // rewriteIDsInTypesT rewrites all ID's in the input types.T using the input
 // ID rewrite mapping.
 func rewriteIDsInTypesT(typ *types.T, descriptorRewrites jobspb.DescRewriteMap) error {
@@ -479,7 +522,7 @@ func TypeDescs(types []*typedesc.Mutable, descriptorRewrites jobspb.DescRewriteM
 			}
 		}
 		switch t := typ.Kind; t {
-		case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
+		case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM, descpb.TypeDescriptor_DOMAIN:
 			if rw, ok := descriptorRewrites[typ.ArrayTypeID]; ok {
 				typ.ArrayTypeID = rw.ID
 			}
@@ -650,6 +693,19 @@ func DatabaseDescs(
 			return err
 		}
 		db.Schemas = newSchemas
+
+		// Rewrite the IDs of the tables included in publications, leaving out
+		// the tables which are not being restored.
+		for i := range db.Publications {
+			pub := &db.Publications[i]
+			tableIDs := pub.TableIDs[:0]
+			for _, id := range pub.TableIDs {
+				if rewrite, ok := descriptorRewrites[id]; ok {
+					tableIDs = append(tableIDs, rewrite.ID)
+				}
+			}
+			pub.TableIDs = tableIDs
+		}
 	}
 	return nil
 }
@@ -673,19 +729,27 @@ func FunctionDescs(
 		fnDesc.ParentID = fnRewrite.ParentID
 
 		// Rewrite function body.
-		fnBody := fnDesc.FunctionBody
-		if overrideDB != "" {
-			dbNameReplaced, err := rewriteFunctionBodyDBNames(fnDesc.FunctionBody, overrideDB)
+		if fnDesc.Lang == catpb.Function_PLPGSQL {
+			fnBody, err := rewritePLpgSQLFunctionBody(fnDesc.FunctionBody, descriptorRewrites, overrideDB)
 			if err != nil {
 				return err
 			}
-			fnBody = dbNameReplaced
-		}
-		fnBody, err := rewriteSequencesInFunction(fnBody, descriptorRewrites)
-		if err != nil {
-			return err
+			fnDesc.FunctionBody = fnBody
+		} else {
+			fnBody := fnDesc.FunctionBody
+			if overrideDB != "" {
+				dbNameReplaced, err := rewriteFunctionBodyDBNames(fnDesc.FunctionBody, overrideDB)
+				if err != nil {
+					return err
+				}
+				fnBody = dbNameReplaced
+			}
+			fnBody, err := rewriteSequencesInFunction(fnBody, descriptorRewrites)
+			if err != nil {
+				return err
+			}
+			fnDesc.FunctionBody = fnBody
 		}
-		fnDesc.FunctionBody = fnBody
 
 		// Rewrite type IDs.
 		for _, arg := range fnDesc.Args {
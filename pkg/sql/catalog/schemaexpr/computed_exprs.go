@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
@@ -55,6 +56,34 @@ func (*RowIndexedVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatt
 	return nil
 }
 
+// MakeRowExpr type checks an expression of the desired type which refers to
+// the given columns of the table by name, such as a CHECK constraint or a
+// filter. The returned expression can be evaluated over rows of those columns
+// with a RowIndexedVarContainer.
+func MakeRowExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	desired *types.T,
+	cols []catalog.Column,
+	tableDesc catalog.TableDescriptor,
+	tn *tree.TableName,
+	evalCtx *eval.Context,
+	semaCtx *tree.SemaContext,
+) (tree.TypedExpr, error) {
+	nr := newNameResolver(evalCtx, tableDesc.GetID(), tn, cols)
+	nr.addIVarContainerToSemaCtx(semaCtx)
+	expr, err := nr.resolveNames(expr)
+	if err != nil {
+		return nil, err
+	}
+	typedExpr, err := tree.TypeCheck(ctx, expr, semaCtx, desired)
+	if err != nil {
+		return nil, err
+	}
+	var txCtx transform.ExprTransformContext
+	return txCtx.NormalizeExpr(ctx, evalCtx, typedExpr)
+}
+
 // CannotWriteToComputedColError constructs a write error for a computed column.
 func CannotWriteToComputedColError(colName string) error {
 	return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
@@ -698,6 +698,23 @@ CREATE TABLE system.external_connections (
 	CONSTRAINT "primary" PRIMARY KEY (connection_name),
 	FAMILY "primary" (connection_name, created, updated, connection_type, connection_details, owner)
 );`
+
+	// SystemNotificationsTableSchema stores the notifications sent with NOTIFY
+	// or pg_notify(). All the notifications sent by a transaction are stored in
+	// a single row, so that they are delivered in the order in which they were
+	// sent. Rows are watched through a rangefeed by every node which has
+	// sessions listening on a channel, and are garbage collected once they are
+	// old enough to have been delivered.
+	SystemNotificationsTableSchema = `
+CREATE TABLE system.notifications (
+	created TIMESTAMPTZ NOT NULL DEFAULT now(),
+	id UUID NOT NULL,
+	sender_pid INT8 NOT NULL,
+	channels STRING[] NOT NULL,
+	payloads STRING[] NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (created, id),
+	FAMILY "primary" (created, id, sender_pid, channels, payloads)
+);`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -2562,6 +2579,37 @@ var (
 			},
 		),
 	)
+
+	SystemNotificationsTable = registerSystemTable(
+		SystemNotificationsTableSchema,
+		systemTable(
+			catconstants.SystemNotificationsTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "created", ID: 1, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+				{Name: "id", ID: 2, Type: types.Uuid},
+				{Name: "sender_pid", ID: 3, Type: types.Int},
+				{Name: "channels", ID: 4, Type: types.StringArray},
+				{Name: "payloads", ID: 5, Type: types.StringArray},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"created", "id", "sender_pid", "channels", "payloads"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:                "primary",
+				ID:                  1,
+				Unique:              true,
+				KeyColumnNames:      []string{"created", "id"},
+				KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC, catpb.IndexColumn_ASC},
+				KeyColumnIDs:        []descpb.ColumnID{1, 2},
+			},
+		),
+	)
 )
 
 type descRefByName struct {
@@ -137,11 +137,13 @@ type Index interface {
 	GetName() string
 	IsPartial() bool
 	IsUnique() bool
+	IsExclusion() bool
 	IsDisabled() bool
 	IsSharded() bool
 	IsNotVisible() bool
 	IsCreatedExplicitly() bool
 	GetPredicate() string
+	GetExclusionRangeFunc() string
 	GetType() descpb.IndexDescriptor_Type
 	GetGeoConfig() geoindex.Config
 	GetVersion() descpb.IndexDescriptorVersion
@@ -92,6 +92,11 @@ func (w index) IsUnique() bool {
 	return w.desc.Unique
 }
 
+// IsExclusion returns true iff the index backs an exclusion constraint.
+func (w index) IsExclusion() bool {
+	return w.desc.Exclusion
+}
+
 // IsDisabled returns true iff the index is disabled.
 func (w index) IsDisabled() bool {
 	return w.desc.Disabled
@@ -120,6 +125,13 @@ func (w index) GetPredicate() string {
 	return w.desc.Predicate
 }
 
+// GetExclusionRangeFunc returns the name of the function which constructs the
+// ranges compared by the exclusion constraint backed by the index, or the
+// empty string if the constraint does not compare ranges.
+func (w index) GetExclusionRangeFunc() string {
+	return w.desc.ExclusionRangeFunc
+}
+
 // GetType returns the type of index, inverted or forward.
 func (w index) GetType() descpb.IndexDescriptor_Type {
 	return w.desc.Type
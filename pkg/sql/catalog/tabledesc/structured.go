@@ -139,7 +139,7 @@ func (desc *wrapper) IndexKeysPerRow(idx catalog.Index) int {
 //	=> t_expr_c_expr1_idx
 func BuildIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) (string, error) {
 	// An index name has a segment for the table name, each key column, and a
-	// final word (either "idx" or "key").
+	// final word (either "idx", "key" or "excl").
 	segments := make([]string, 0, len(idx.KeyColumnNames)+2)
 
 	// Add the table name segment.
@@ -179,6 +179,8 @@ func BuildIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) (string, er
 	// Add the final segment.
 	if idx.Unique {
 		segments = append(segments, "key")
+	} else if idx.Exclusion {
+		segments = append(segments, "excl")
 	} else {
 		segments = append(segments, "idx")
 	}
@@ -685,7 +687,7 @@ func (desc *Mutable) allocateIndexIDs(columnNames map[string]descpb.ColumnID) er
 			}
 			idx.IndexDesc().Name = name
 		}
-		if idx.GetConstraintID() == 0 && idx.IsUnique() {
+		if idx.GetConstraintID() == 0 && (idx.IsUnique() || idx.IsExclusion()) {
 			idx.IndexDesc().ConstraintID = desc.NextConstraintID
 			desc.NextConstraintID++
 		}
@@ -1126,6 +1128,29 @@ func (desc *Mutable) AddColumn(col *descpb.ColumnDescriptor) {
 	desc.Columns = append(desc.Columns, *col)
 }
 
+// AddTrigger allocates an ID for the given trigger and adds it to the table.
+// It returns the allocated ID.
+func (desc *Mutable) AddTrigger(trig descpb.TriggerDescriptor) descpb.TriggerID {
+	if desc.NextTriggerID == 0 {
+		desc.NextTriggerID = 1
+	}
+	trig.ID = desc.NextTriggerID
+	desc.NextTriggerID++
+	desc.Triggers = append(desc.Triggers, trig)
+	return trig.ID
+}
+
+// RemoveTrigger removes the trigger with the given ID from the table, if it
+// exists.
+func (desc *Mutable) RemoveTrigger(id descpb.TriggerID) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].ID == id {
+			desc.Triggers = append(desc.Triggers[:i], desc.Triggers[i+1:]...)
+			return
+		}
+	}
+}
+
 // AddFamily adds a family to the table.
 func (desc *Mutable) AddFamily(fam descpb.ColumnFamilyDescriptor) {
 	desc.Families = append(desc.Families, fam)
@@ -1391,6 +1416,11 @@ func (desc *Mutable) DropConstraint(
 			}
 		}
 
+	case descpb.ConstraintTypeExclusion:
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot drop EXCLUDE constraint %q using ALTER TABLE DROP CONSTRAINT, use DROP INDEX CASCADE instead",
+			tree.ErrNameStringP(&detail.Index.Name))
+
 	case descpb.ConstraintTypeCheck:
 		if detail.CheckConstraint.Validity == descpb.ConstraintValidity_Validating {
 			return unimplemented.NewWithIssueDetailf(42844, "drop-constraint-check-mutation",
@@ -1481,7 +1511,7 @@ func (desc *Mutable) RenameConstraint(
 	renameFK func(*Mutable, *descpb.ForeignKeyConstraint, string) error,
 ) error {
 	switch detail.Kind {
-	case descpb.ConstraintTypePK:
+	case descpb.ConstraintTypePK, descpb.ConstraintTypeExclusion:
 		for _, tableRef := range desc.DependedOnBy {
 			if tableRef.IndexID != detail.Index.ID {
 				continue
@@ -2600,6 +2630,14 @@ func (desc *wrapper) GetExcludeDataFromBackup() bool {
 	return desc.ExcludeDataFromBackup
 }
 
+// GetSSTOptions implements the TableDescriptor interface.
+func (desc *wrapper) GetSSTOptions() catpb.SSTOptions {
+	if desc.SSTOptions == nil {
+		return catpb.SSTOptions{}
+	}
+	return *desc.SSTOptions
+}
+
 // GetStorageParams implements the TableDescriptor interface.
 func (desc *wrapper) GetStorageParams(spaceBetweenEqual bool) []string {
 	var storageParams []string
@@ -2646,6 +2684,12 @@ func (desc *wrapper) GetStorageParams(spaceBetweenEqual bool) []string {
 	if exclude := desc.GetExcludeDataFromBackup(); exclude {
 		appendStorageParam(`exclude_data_from_backup`, `true`)
 	}
+	if compression := desc.GetSSTOptions().Compression; compression != "" {
+		appendStorageParam(`compression`, lexbase.EscapeSQLString(compression))
+	}
+	if blockSize := desc.GetSSTOptions().BlockSize; blockSize != 0 {
+		appendStorageParam(`block_size`, fmt.Sprintf(`%d`, blockSize))
+	}
 	if settings := desc.AutoStatsSettings; settings != nil {
 		if settings.Enabled != nil {
 			value := *settings.Enabled
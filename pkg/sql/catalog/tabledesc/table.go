@@ -16,7 +16,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
@@ -33,6 +35,23 @@ import (
 	"github.com/cockroachdb/redact"
 )
 
+// CheckColumnTypeIsSupported returns an error if the type cannot be used for
+// table columns until the cluster is upgraded to a version which supports it.
+func CheckColumnTypeIsSupported(ctx context.Context, st *cluster.Settings, typ *types.T) error {
+	if typ.Family() == types.ArrayFamily {
+		typ = typ.ArrayContents()
+	}
+	switch typ.Family() {
+	case types.TSQueryFamily, types.TSVectorFamily:
+		if !st.Version.IsActive(ctx, clusterversion.TSearchTypes) {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"version %v must be finalized to use %s columns",
+				clusterversion.ByKey(clusterversion.TSearchTypes), typ.SQLString())
+		}
+	}
+	return nil
+}
+
 // ColumnDefDescs contains the non-error return values for MakeColumnDefDescs.
 type ColumnDefDescs struct {
 	// tree.ColumnTableDef is the column definition from which this struct is
@@ -155,6 +174,11 @@ func MakeColumnDefDescs(
 	if err = colinfo.ValidateColumnDefType(resType); err != nil {
 		return nil, err
 	}
+	if evalCtx != nil && evalCtx.Settings != nil {
+		if err := CheckColumnTypeIsSupported(ctx, evalCtx.Settings, resType); err != nil {
+			return nil, err
+		}
+	}
 	col.Type = resType
 
 	if d.HasDefaultExpr() {
@@ -336,6 +360,27 @@ func (desc *wrapper) FindConstraintWithID(
 	return nil, pgerror.Newf(pgcode.UndefinedObject, "constraint-id \"%d\" does not exist", id)
 }
 
+// FindTriggerWithID implements the TableDescriptor interface.
+func (desc *wrapper) FindTriggerWithID(id descpb.TriggerID) (*descpb.TriggerDescriptor, error) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].ID == id {
+			return &desc.Triggers[i], nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.UndefinedObject, "trigger-id \"%d\" does not exist", id)
+}
+
+// FindTriggerWithName implements the TableDescriptor interface.
+func (desc *wrapper) FindTriggerWithName(name string) (*descpb.TriggerDescriptor, error) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			return &desc.Triggers[i], nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.UndefinedObject,
+		"trigger %q for table %q does not exist", name, desc.GetName())
+}
+
 // GetConstraintInfoWithLookup implements the TableDescriptor interface.
 func (desc *wrapper) GetConstraintInfoWithLookup(
 	tableLookup catalog.TableLookupFn,
@@ -392,6 +437,21 @@ func (desc *wrapper) collectConstraintInfo(
 			detail.Columns = index.KeyColumnNames
 			detail.Index = index
 			info[index.Name] = detail
+		} else if index.Exclusion && !indexI.IsTemporaryIndexForBackfill() {
+			if _, ok := info[index.Name]; ok {
+				return nil, pgerror.Newf(pgcode.DuplicateObject,
+					"duplicate constraint name: %q", index.Name)
+			}
+			detail := descpb.ConstraintDetail{
+				Kind:         descpb.ConstraintTypeExclusion,
+				ConstraintID: index.ConstraintID,
+			}
+			// The exclusion constraint is being validated while its index is
+			// being added.
+			detail.Unvalidated = !indexI.Public()
+			detail.Columns = index.KeyColumnNames
+			detail.Index = index
+			info[index.Name] = detail
 		}
 	}
 
@@ -119,6 +119,10 @@ func (desc *wrapper) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
 	for _, ref := range desc.GetDependedOnBy() {
 		ids.Add(ref.ID)
 	}
+	// Add trigger function dependencies.
+	for i := range desc.Triggers {
+		ids.Add(desc.Triggers[i].FuncID)
+	}
 	// Add sequence dependencies
 	return ids, nil
 }
@@ -196,6 +200,11 @@ func (desc *wrapper) ValidateForwardReferences(
 		}
 	}
 
+	// Check that the functions executed by triggers exist.
+	for i := range desc.Triggers {
+		vea.Report(desc.validateOutboundFuncRef(desc.Triggers[i].FuncID, vdg))
+	}
+
 	// Row-level TTL is not compatible with foreign keys.
 	// This check should be in ValidateSelf but interferes with AllocateIDs.
 	if desc.HasRowLevelTTL() {
@@ -274,6 +283,17 @@ func (desc *wrapper) ValidateBackReferences(
 		}
 	}
 
+	// Check that the functions executed by triggers refer back to them.
+	for i := range desc.Triggers {
+		fn, _ := vdg.GetFunctionDescriptor(desc.Triggers[i].FuncID)
+		if fn == nil {
+			// Don't follow up on backward references for invalid forward
+			// references.
+			continue
+		}
+		vea.Report(desc.validateTriggerFuncRefBackReference(&desc.Triggers[i], fn))
+	}
+
 	// Check relation back-references to relations and functions.
 	for _, by := range desc.DependedOnBy {
 		depDesc, err := vdg.GetDescriptor(by.ID)
@@ -331,6 +351,23 @@ func (desc *wrapper) validateOutboundFuncRefBackReference(ref catalog.FunctionDe
 		ref.GetName(), ref.GetID())
 }
 
+func (desc *wrapper) validateTriggerFuncRefBackReference(
+	trig *descpb.TriggerDescriptor, ref catalog.FunctionDescriptor,
+) error {
+	for _, dep := range ref.GetDependedOnBy() {
+		if dep.ID != desc.GetID() {
+			continue
+		}
+		for _, id := range dep.TriggerIDs {
+			if id == trig.ID {
+				return nil
+			}
+		}
+	}
+	return errors.AssertionFailedf("function %q (%d) executed by trigger %q has no corresponding depended-on-by back reference",
+		ref.GetName(), ref.GetID(), trig.Name)
+}
+
 func (desc *wrapper) validateInboundFunctionRef(
 	by descpb.TableDescriptor_Reference, vdg catalog.ValidationDescGetter,
 ) error {
@@ -615,6 +652,14 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		}
 	}
 
+	// The rows of a table attached from a backup are decoded with the columns
+	// and the primary index which the table had in the backup, so the table
+	// cannot undergo schema changes.
+	if desc.IsBackupTable() && len(desc.Mutations) > 0 {
+		vea.Report(pgerror.New(pgcode.FeatureNotSupported,
+			"schema changes are not supported on tables attached from a backup"))
+	}
+
 	desc.validateAutoStatsSettings(vea)
 
 	if desc.IsSequence() {
@@ -719,6 +764,8 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		desc.validateConstraintIDs(vea)
 	}
 
+	desc.validateTriggers(vea)
+
 	// Ensure that mutations cannot be queued if a primary key change, TTL change
 	// or an alter column type schema change has either been started in
 	// this transaction, or is currently in progress.
@@ -915,6 +962,35 @@ func (desc *wrapper) validateConstraintIDs(vea catalog.ValidationErrorAccumulato
 	}
 }
 
+func (desc *wrapper) validateTriggers(vea catalog.ValidationErrorAccumulator) {
+	if len(desc.Triggers) > 0 && !desc.IsTable() {
+		vea.Report(errors.AssertionFailedf("has triggers despite not being a table"))
+		return
+	}
+	names := make(map[string]struct{}, len(desc.Triggers))
+	ids := make(map[descpb.TriggerID]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		trig := &desc.Triggers[i]
+		if trig.Name == "" {
+			vea.Report(errors.AssertionFailedf("trigger %d has empty name", trig.ID))
+		}
+		if _, ok := names[trig.Name]; ok {
+			vea.Report(errors.AssertionFailedf("duplicate trigger name: %q", trig.Name))
+		}
+		names[trig.Name] = struct{}{}
+		if trig.ID == 0 || trig.ID >= desc.NextTriggerID {
+			vea.Report(errors.AssertionFailedf("trigger %q has invalid ID %d", trig.Name, trig.ID))
+		}
+		if _, ok := ids[trig.ID]; ok {
+			vea.Report(errors.AssertionFailedf("trigger %q has duplicate ID %d", trig.Name, trig.ID))
+		}
+		ids[trig.ID] = struct{}{}
+		if !trig.OnInsert && !trig.OnUpdate && !trig.OnDelete {
+			vea.Report(errors.AssertionFailedf("trigger %q has no events", trig.Name))
+		}
+	}
+}
+
 func (desc *wrapper) validateColumns() error {
 	columnIDs := make(map[descpb.ColumnID]*descpb.ColumnDescriptor, len(desc.Columns))
 	columnNames := make(map[string]descpb.ColumnID, len(desc.Columns))
@@ -1251,6 +1327,26 @@ func (desc *wrapper) validateTableIndexes(
 			return errors.Newf("index %q must contain at least 1 column", idx.GetName())
 		}
 
+		if idx.IsExclusion() {
+			if idx.GetExclusionRangeFunc() == "" {
+				if idx.GetType() != descpb.IndexDescriptor_INVERTED {
+					return errors.Newf("exclusion constraint index %q is not an inverted index", idx.GetName())
+				}
+			} else {
+				if idx.GetType() != descpb.IndexDescriptor_FORWARD {
+					return errors.Newf("range exclusion constraint index %q is not a forward index", idx.GetName())
+				}
+				if idx.NumKeyColumns()-idx.ExplicitColumnStartIdx() < 2 {
+					return errors.Newf("range exclusion constraint index %q must contain the bounds of the range", idx.GetName())
+				}
+			}
+			if idx.IsUnique() || idx.IsSharded() {
+				return errors.Newf("exclusion constraint index %q cannot be unique or sharded", idx.GetName())
+			}
+		} else if idx.GetExclusionRangeFunc() != "" {
+			return errors.Newf("index %q has a range function but does not back an exclusion constraint", idx.GetName())
+		}
+
 		var validateIndexDup catalog.TableColSet
 		for i, colID := range idx.IndexDesc().KeyColumnIDs {
 			inIndexColName := idx.IndexDesc().KeyColumnNames[i]
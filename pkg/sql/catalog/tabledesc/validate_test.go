@@ -133,6 +133,10 @@ var validationMap = []struct {
 			"AutoStatsSettings":             {status: iSolemnlySwearThisFieldIsValidated},
 			"ForecastStats":                 {status: thisFieldReferencesNoObjects},
 			"ImportStartWallTime":           {status: thisFieldReferencesNoObjects},
+			"Triggers":                      {status: iSolemnlySwearThisFieldIsValidated},
+			"NextTriggerID":                 {status: iSolemnlySwearThisFieldIsValidated},
+			"BackupSource":                  {status: thisFieldReferencesNoObjects},
+			"SSTOptions":                    {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -176,6 +180,9 @@ var validationMap = []struct {
 			"UseDeletePreservingEncoding": {status: thisFieldReferencesNoObjects},
 			"ConstraintID":                {status: iSolemnlySwearThisFieldIsValidated},
 			"CreatedAtNanos":              {status: thisFieldReferencesNoObjects},
+			"Exclusion":                   {status: iSolemnlySwearThisFieldIsValidated},
+			"ExclusionRangeFunc":          {status: iSolemnlySwearThisFieldIsValidated},
+			"SSTOptions":                  {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -225,17 +232,21 @@ var validationMap = []struct {
 			"OnUpdate":          {status: thisFieldReferencesNoObjects},
 			"Match":             {status: thisFieldReferencesNoObjects},
 			"ConstraintID":      {status: iSolemnlySwearThisFieldIsValidated},
+			"Deferrable":        {status: thisFieldReferencesNoObjects},
+			"InitiallyDeferred": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
 		obj: descpb.UniqueWithoutIndexConstraint{},
 		fieldMap: map[string]validationStatusInfo{
-			"TableID":      {status: iSolemnlySwearThisFieldIsValidated},
-			"ColumnIDs":    {status: iSolemnlySwearThisFieldIsValidated},
-			"Name":         {status: thisFieldReferencesNoObjects},
-			"Validity":     {status: thisFieldReferencesNoObjects},
-			"Predicate":    {status: iSolemnlySwearThisFieldIsValidated},
-			"ConstraintID": {status: iSolemnlySwearThisFieldIsValidated},
+			"TableID":           {status: iSolemnlySwearThisFieldIsValidated},
+			"ColumnIDs":         {status: iSolemnlySwearThisFieldIsValidated},
+			"Name":              {status: thisFieldReferencesNoObjects},
+			"Validity":          {status: thisFieldReferencesNoObjects},
+			"Predicate":         {status: iSolemnlySwearThisFieldIsValidated},
+			"ConstraintID":      {status: iSolemnlySwearThisFieldIsValidated},
+			"Deferrable":        {status: thisFieldReferencesNoObjects},
+			"InitiallyDeferred": {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -258,6 +269,11 @@ var validationMap = []struct {
 			"OfflineReason":                 {status: thisFieldReferencesNoObjects},
 			"RegionConfig":                  {status: iSolemnlySwearThisFieldIsValidated},
 			"DeclarativeSchemaChangerState": {status: thisFieldReferencesNoObjects},
+			"DomainBaseType":                {status: iSolemnlySwearThisFieldIsValidated},
+			"DomainDefaultExpr": {status: todoIAmKnowinglyAddingTechDebt,
+				reason: "TODO(sql-foundations): validate the references of the domain default expression"},
+			"DomainNotNull":     {status: thisFieldReferencesNoObjects},
+			"DomainConstraints": {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 	{
@@ -274,6 +290,8 @@ var validationMap = []struct {
 			"RegionConfig":                  {status: iSolemnlySwearThisFieldIsValidated},
 			"DefaultPrivileges":             {status: iSolemnlySwearThisFieldIsValidated},
 			"DeclarativeSchemaChangerState": {status: thisFieldReferencesNoObjects},
+			"Publications":                  {status: iSolemnlySwearThisFieldIsValidated},
+			"NextPublicationID":             {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 	{
@@ -324,6 +342,7 @@ var validationMap = []struct {
 			"ModificationTime":              {status: thisFieldReferencesNoObjects},
 			"Version":                       {status: thisFieldReferencesNoObjects},
 			"DeclarativeSchemaChangerState": {status: thisFieldReferencesNoObjects},
+			"IsProcedure":                   {status: thisFieldReferencesNoObjects},
 		},
 	},
 }
@@ -756,6 +775,63 @@ func TestValidateTableDesc(t *testing.T) {
 				NextFamilyID: 1,
 				NextIndexID:  3,
 			}},
+		{`exclusion constraint index "bar" is not an inverted index`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.InterleavedFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []descpb.ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: descpb.IndexDescriptor{
+					ID: 1, Name: "primary", KeyColumnIDs: []descpb.ColumnID{1}, KeyColumnNames: []string{"bar"},
+					KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+					EncodingType:        descpb.PrimaryIndexEncoding,
+					Version:             descpb.LatestIndexDescriptorVersion,
+				},
+				Indexes: []descpb.IndexDescriptor{
+					{ID: 2, Name: "bar", KeyColumnIDs: []descpb.ColumnID{1}, KeyColumnNames: []string{"bar"},
+						KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+						Exclusion:           true,
+					},
+				},
+				NextColumnID: 2,
+				NextFamilyID: 1,
+				NextIndexID:  3,
+			}},
+		{`range exclusion constraint index "bar" must contain the bounds of the range`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.InterleavedFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []descpb.ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: descpb.IndexDescriptor{
+					ID: 1, Name: "primary", KeyColumnIDs: []descpb.ColumnID{1}, KeyColumnNames: []string{"bar"},
+					KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+					EncodingType:        descpb.PrimaryIndexEncoding,
+					Version:             descpb.LatestIndexDescriptorVersion,
+				},
+				Indexes: []descpb.IndexDescriptor{
+					{ID: 2, Name: "bar", KeyColumnIDs: []descpb.ColumnID{1}, KeyColumnNames: []string{"bar"},
+						KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+						Exclusion:           true,
+						ExclusionRangeFunc:  "int8range",
+					},
+				},
+				NextColumnID: 2,
+				NextFamilyID: 1,
+				NextIndexID:  3,
+			}},
 		{`mismatched column IDs (1) and names (0)`,
 			descpb.TableDescriptor{
 				ID:            2,
@@ -2279,6 +2355,103 @@ func TestValidateTableDesc(t *testing.T) {
 					DurationExpr: catpb.Expression("INTERVAL '2 minutes'"),
 				},
 			}},
+		{`duplicate trigger name: "trig"`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.InterleavedFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []descpb.ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: descpb.IndexDescriptor{
+					ID:                  1,
+					Name:                "primary",
+					Unique:              true,
+					KeyColumnIDs:        []descpb.ColumnID{1},
+					KeyColumnNames:      []string{"bar"},
+					KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+					Version:             descpb.PrimaryIndexWithStoredColumnsVersion,
+					EncodingType:        descpb.PrimaryIndexEncoding,
+					ConstraintID:        1,
+				},
+				Triggers: []descpb.TriggerDescriptor{
+					{ID: 1, Name: "trig", OnInsert: true, FuncID: 100},
+					{ID: 2, Name: "trig", OnDelete: true, FuncID: 100},
+				},
+				NextColumnID:     2,
+				NextFamilyID:     1,
+				NextIndexID:      2,
+				NextConstraintID: 2,
+				NextTriggerID:    3,
+			}},
+		{`trigger "trig" has no events`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.InterleavedFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []descpb.ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: descpb.IndexDescriptor{
+					ID:                  1,
+					Name:                "primary",
+					Unique:              true,
+					KeyColumnIDs:        []descpb.ColumnID{1},
+					KeyColumnNames:      []string{"bar"},
+					KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+					Version:             descpb.PrimaryIndexWithStoredColumnsVersion,
+					EncodingType:        descpb.PrimaryIndexEncoding,
+					ConstraintID:        1,
+				},
+				Triggers: []descpb.TriggerDescriptor{
+					{ID: 1, Name: "trig", FuncID: 100},
+				},
+				NextColumnID:     2,
+				NextFamilyID:     1,
+				NextIndexID:      2,
+				NextConstraintID: 2,
+				NextTriggerID:    3,
+			}},
+		{`trigger "trig" has invalid ID 3`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.InterleavedFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []descpb.ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: descpb.IndexDescriptor{
+					ID:                  1,
+					Name:                "primary",
+					Unique:              true,
+					KeyColumnIDs:        []descpb.ColumnID{1},
+					KeyColumnNames:      []string{"bar"},
+					KeyColumnDirections: []catpb.IndexColumn_Direction{catpb.IndexColumn_ASC},
+					Version:             descpb.PrimaryIndexWithStoredColumnsVersion,
+					EncodingType:        descpb.PrimaryIndexEncoding,
+					ConstraintID:        1,
+				},
+				Triggers: []descpb.TriggerDescriptor{
+					{ID: 3, Name: "trig", OnUpdate: true, FuncID: 100},
+				},
+				NextColumnID:     2,
+				NextFamilyID:     1,
+				NextIndexID:      2,
+				NextConstraintID: 2,
+				NextTriggerID:    3,
+			}},
 	}
 	for i, d := range testData {
 		t.Run(d.err, func(t *testing.T) {
@@ -545,6 +545,27 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		if desc.GetArrayTypeID() != descpb.InvalidID {
 			vea.Report(errors.AssertionFailedf("ALIAS type desc has array type ID %d", desc.GetArrayTypeID()))
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if desc.RegionConfig != nil {
+			vea.Report(errors.AssertionFailedf("found region config on %s type desc", desc.Kind.String()))
+		}
+		if desc.DomainBaseType == nil {
+			vea.Report(errors.AssertionFailedf("DOMAIN type desc has nil base type"))
+		} else if desc.DomainBaseType.UserDefined() {
+			vea.Report(errors.AssertionFailedf(
+				"DOMAIN type desc has user-defined base type %d", desc.DomainBaseType.Oid()))
+		}
+		names := make(map[string]struct{}, len(desc.DomainConstraints))
+		for _, c := range desc.DomainConstraints {
+			if c.Name == "" {
+				vea.Report(errors.AssertionFailedf("DOMAIN type desc has unnamed constraint"))
+			}
+			if _, ok := names[c.Name]; ok {
+				vea.Report(errors.AssertionFailedf(
+					"DOMAIN type desc has duplicate constraint name %q", c.Name))
+			}
+			names[c.Name] = struct{}{}
+		}
 	case descpb.TypeDescriptor_TABLE_IMPLICIT_RECORD_TYPE:
 		vea.Report(errors.AssertionFailedf("invalid type descriptor: kind %s should never be serialized or validated", desc.Kind.String()))
 	default:
@@ -666,7 +687,7 @@ func (desc *immutable) ValidateBackReferences(
 
 	// Validate that the backward-referenced types exist.
 	switch desc.GetKind() {
-	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
+	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM, descpb.TypeDescriptor_DOMAIN:
 		// Ensure that the array type exists.
 		// This is considered to be a backward reference, not a forward reference,
 		// as the element type doesn't need the array type to exist, but the
@@ -798,6 +819,14 @@ func (desc *immutable) MakeTypesT(
 			return nil, err
 		}
 		return typ, nil
+	case descpb.TypeDescriptor_DOMAIN:
+		typ := types.MakeDomain(
+			catid.TypeIDToOID(desc.GetID()), catid.TypeIDToOID(desc.ArrayTypeID), desc.DomainBaseType,
+		)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
 	case descpb.TypeDescriptor_ALIAS:
 		// Hydrate the alias and return it.
 		if err := desc.HydrateTypeInfoWithName(ctx, desc.Alias, name, res); err != nil {
@@ -900,6 +929,7 @@ func (desc *immutable) HydrateTypeInfoWithName(
 		return nil
 	}
 	var enumData *types.EnumMetadata
+	var domainData *types.DomainMetadata
 	switch desc.Kind {
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		if typ.Family() != types.EnumFamily {
@@ -910,6 +940,31 @@ func (desc *immutable) HydrateTypeInfoWithName(
 			PhysicalRepresentations: desc.physicalReps,
 			IsMemberReadOnly:        desc.readOnlyMembers,
 		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if !typ.IsDomain() {
+			return errors.New("cannot hydrate a non-domain type with a domain type descriptor")
+		}
+		domainData = &types.DomainMetadata{
+			NotNull:     desc.DomainNotNull,
+			DefaultExpr: desc.DomainDefaultExpr,
+		}
+		// Constraints that are being added are enforced on new values while the
+		// existing values are validated.
+		for i := range desc.DomainConstraints {
+			c := &desc.DomainConstraints[i]
+			if c.Validity == descpb.ConstraintValidity_Dropping {
+				continue
+			}
+			domainData.CheckNames = append(domainData.CheckNames, c.Name)
+			domainData.CheckExprs = append(domainData.CheckExprs, c.Expr)
+		}
+		checks, err := eval.TypeCheckDomainConstraints(
+			ctx, typ.DomainBaseType(), domainData.CheckExprs,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "type checking the constraints of domain %s", name.Object())
+		}
+		domainData.TypedCheckExprs = checks
 	case descpb.TypeDescriptor_ALIAS:
 		if typ.UserDefined() {
 			switch typ.Family() {
@@ -941,8 +996,9 @@ func (desc *immutable) HydrateTypeInfoWithName(
 			Schema:         name.Schema(),
 			Name:           name.Object(),
 		},
-		Version:  uint32(desc.Version),
-		EnumData: enumData,
+		Version:    uint32(desc.Version),
+		EnumData:   enumData,
+		DomainData: domainData,
 	}
 	return nil
 }
@@ -1010,6 +1066,17 @@ func (desc *immutable) IsCompatibleWith(other catalog.TypeDescriptor) error {
 			}
 		}
 		return nil
+	case descpb.TypeDescriptor_DOMAIN:
+		if other.GetKind() != desc.Kind {
+			return errors.Newf("%q of type %q is not compatible with type %q",
+				other.GetName(), other.GetKind(), desc.Kind)
+		}
+		// Values of the domain are encoded as values of its base type, so the
+		// base types must be the same.
+		if !desc.DomainBaseType.Identical(other.TypeDesc().DomainBaseType) {
+			return errors.Newf("%q has a different base type than %q", other.GetName(), desc.Name)
+		}
+		return nil
 	default:
 		return errors.Newf("compatibility comparison unsupported for type kind %s", desc.Kind.String())
 	}
@@ -1027,6 +1094,15 @@ func (desc *immutable) HasPendingSchemaChanges() bool {
 			}
 		}
 		return false
+	case descpb.TypeDescriptor_DOMAIN:
+		// If there are any constraints being added, then a type schema change is
+		// needed to validate them.
+		for i := range desc.DomainConstraints {
+			if desc.DomainConstraints[i].Validity == descpb.ConstraintValidity_Validating {
+				return true
+			}
+		}
+		return false
 	default:
 		return false
 	}
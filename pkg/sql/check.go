@@ -376,6 +376,84 @@ func duplicateRowQuery(
 	), colNames, nil
 }
 
+// exclusionViolationQuery returns a query that finds two distinct rows of the
+// table that conflict with each other according to the exclusion constraint
+// backed by the given index. The query returns the constrained columns of one
+// of the two rows, and the names of these columns.
+func exclusionViolationQuery(
+	srcTbl catalog.TableDescriptor, idx catalog.Index,
+) (sql string, colNames []string, _ error) {
+	keyColIDs := idx.IndexDesc().KeyColumnIDs[idx.ExplicitColumnStartIdx():]
+	colNames, err := srcTbl.NamesForColumnIDs(keyColIDs)
+	if err != nil {
+		return "", nil, err
+	}
+	pkColIDs := srcTbl.GetPrimaryIndex().IndexDesc().KeyColumnIDs
+	pkColNames, err := srcTbl.NamesForColumnIDs(pkColIDs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The rows are projected onto the constrained columns and the primary key
+	// columns, which may be hidden.
+	var srcCols []string
+	seen := make(map[string]struct{}, len(colNames)+len(pkColNames))
+	for _, names := range [][]string{colNames, pkColNames} {
+		for _, n := range names {
+			if _, ok := seen[n]; !ok {
+				seen[n] = struct{}{}
+				srcCols = append(srcCols, tree.NameString(n))
+			}
+		}
+	}
+	src := fmt.Sprintf("SELECT %s FROM [%d AS tbl]", strings.Join(srcCols, ", "), srcTbl.GetID())
+	if idx.IsPartial() {
+		src = fmt.Sprintf("%s WHERE (%s)", src, idx.GetPredicate())
+	}
+
+	// The last constrained column is compared with &&, and the others with =.
+	// If the constraint compares ranges, the last two constrained columns are
+	// the lower and upper bounds of the ranges, which overlap if neither range
+	// is empty and each range starts before the other one ends. A NULL bound
+	// is unbounded, so comparisons with it are not false.
+	numEq := len(colNames) - 1
+	if idx.GetExclusionRangeFunc() != "" {
+		numEq = len(colNames) - 2
+	}
+	outCols := make([]string, len(colNames))
+	on := make([]string, 0, len(colNames)+3)
+	for i, n := range colNames {
+		name := tree.NameString(n)
+		outCols[i] = "a." + name
+		if i < numEq {
+			on = append(on, fmt.Sprintf("a.%[1]s = b.%[1]s", name))
+		} else if idx.GetExclusionRangeFunc() == "" {
+			on = append(on, fmt.Sprintf("a.%[1]s && b.%[1]s", name))
+		}
+	}
+	if idx.GetExclusionRangeFunc() != "" {
+		lower, upper := tree.NameString(colNames[numEq]), tree.NameString(colNames[numEq+1])
+		on = append(on,
+			fmt.Sprintf("(a.%[1]s < a.%[2]s) IS NOT false", lower, upper),
+			fmt.Sprintf("(b.%[1]s < b.%[2]s) IS NOT false", lower, upper),
+			fmt.Sprintf("(a.%[1]s < b.%[2]s) IS NOT false", lower, upper),
+			fmt.Sprintf("(b.%[1]s < a.%[2]s) IS NOT false", lower, upper),
+		)
+	}
+	pkNotEq := make([]string, len(pkColNames))
+	for i, n := range pkColNames {
+		pkNotEq[i] = fmt.Sprintf("a.%[1]s != b.%[1]s", tree.NameString(n))
+	}
+	on = append(on, fmt.Sprintf("(%s)", strings.Join(pkNotEq, " OR ")))
+
+	return fmt.Sprintf(
+		`SELECT %[1]s FROM (%[2]s) AS a JOIN (%[2]s) AS b ON %[3]s LIMIT 1`,
+		strings.Join(outCols, ", "), // 1
+		src,                         // 2
+		strings.Join(on, " AND "),   // 3
+	), colNames, nil
+}
+
 // RevalidateUniqueConstraintsInCurrentDB verifies that all unique constraints
 // defined on tables in the current database are valid. In other words, it
 // verifies that for every table in the database with one or more unique
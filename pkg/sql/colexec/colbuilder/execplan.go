@@ -308,6 +308,7 @@ func canWrap(mode sessiondatapb.VectorizeExecMode, core *execinfrapb.ProcessorCo
 		return errBackupDataWrap
 	case core.SplitAndScatter != nil:
 	case core.RestoreData != nil:
+	case core.VerifyBackupData != nil:
 	case core.Filterer != nil:
 	case core.StreamIngestionData != nil:
 	case core.StreamIngestionFrontier != nil:
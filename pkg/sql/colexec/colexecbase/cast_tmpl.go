@@ -125,6 +125,11 @@ func GetCastOperator(
 	if fromType.Family() == types.UnknownFamily {
 		return &castOpNullAny{castOpBase: base}, nil
 	}
+	if toType.IsDomain() && !fromType.Identical(toType) {
+		// The constraints of domains are only checked by the row-by-row
+		// engine.
+		return nil, errors.Errorf("unhandled cast %s -> %s", fromType.SQLString(), toType.SQLString())
+	}
 	if isIdentityCast(fromType, toType) {
 		// bpchars require special handling.
 		if toType.Oid() == oid.T_bpchar {
@@ -188,6 +193,9 @@ func IsCastSupported(fromType, toType *types.T) bool {
 	if fromType.Family() == types.UnknownFamily {
 		return true
 	}
+	if toType.IsDomain() && !fromType.Identical(toType) {
+		return false
+	}
 	if isIdentityCast(fromType, toType) {
 		return true
 	}
@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxrecommendations"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/listennotify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
@@ -464,6 +465,8 @@ func makeMetrics(internal bool) Metrics {
 			SQLActiveStatements: metric.NewGauge(getMetricMeta(MetaSQLActiveQueries, internal)),
 			SQLContendedTxns:    metric.NewCounter(getMetricMeta(MetaSQLTxnContended, internal)),
 
+			ReadCommittedStmtRetryCount: metric.NewCounter(getMetricMeta(MetaReadCommittedStmtRetry, internal)),
+
 			TxnAbortCount:                     metric.NewCounter(getMetricMeta(MetaTxnAbort, internal)),
 			FailureCount:                      metric.NewCounter(getMetricMeta(MetaFailure, internal)),
 			FullTableOrIndexScanCount:         metric.NewCounter(getMetricMeta(MetaFullTableOrIndexScan, internal)),
@@ -1115,6 +1118,9 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 	}
 
 	ex.resetExtraTxnState(ctx, txnEvent{eventType: txnEvType})
+	if ex.listener != nil {
+		ex.listener.Close()
+	}
 	if ex.hasCreatedTemporarySchema && !ex.server.cfg.TestingKnobs.DisableTempObjectsCleanupOnSessionExit {
 		err := cleanupSessionTempObjects(
 			ctx,
@@ -1234,6 +1240,24 @@ type connExecutor struct {
 	// if traceSessionEventLogEnabled; it is used by ex.sessionEventf()
 	eventLog trace.EventLog
 
+	// procCall tracks the execution of a CALL statement that invokes a procedure
+	// whose body contains COMMIT or ROLLBACK statements. Each segment of the
+	// body between such statements is executed in its own transaction by
+	// executing the CALL statement again, staying in place in the statement
+	// buffer, until the last segment has been executed.
+	procCall struct {
+		// stmt is the CALL statement being executed.
+		stmt *tree.Call
+		// segment is the index of the segment to execute next.
+		segment int
+		// args holds the values of the arguments of the CALL statement, which
+		// are evaluated once, for the first segment.
+		args tree.Datums
+		// resume is set when a segment ended its transaction, in which case the
+		// CALL statement must be executed again for the next segment.
+		resume bool
+	}
+
 	// extraTxnState groups fields scoped to a SQL txn that are not handled by
 	// ex.state, above. The rule of thumb is that, if the state influences state
 	// transitions, it should live in state, otherwise it can live here.
@@ -1276,6 +1300,16 @@ type connExecutor struct {
 		// transaction and it is cleared after the transaction is committed.
 		schemaChangeJobRecords map[descpb.ID]*jobs.Record
 
+		// notifications accumulates the LISTEN, UNLISTEN and NOTIFY statements
+		// executed by the transaction. They take effect once the transaction
+		// commits.
+		notifications txnNotifications
+
+		// deferredConstraints tracks the SET CONSTRAINTS statements executed by
+		// the transaction and the violations of deferred constraints, which are
+		// checked again before the transaction commits.
+		deferredConstraints txnDeferredConstraints
+
 		// firstStmtExecuted indicates that the first statement inside this
 		// transaction has been executed.
 		firstStmtExecuted bool
@@ -1363,12 +1397,15 @@ type connExecutor struct {
 
 		// savepoints maintains the stack of savepoints currently open.
 		savepoints savepointStack
-		// rewindPosSnapshot is a snapshot of the savepoints and sessionData stack
-		// before processing the command at position txnRewindPos. When rewinding,
-		// we're going to restore this snapshot.
+		// rewindPosSnapshot is a snapshot of the savepoints, the sessionData
+		// stack, the queued notifications and the deferred constraints before
+		// processing the command at position txnRewindPos. When rewinding, we're
+		// going to restore this snapshot.
 		rewindPosSnapshot struct {
-			savepoints       savepointStack
-			sessionDataStack *sessiondata.Stack
+			savepoints          savepointStack
+			sessionDataStack    *sessiondata.Stack
+			notifications       notificationsSavepoint
+			deferredConstraints txnDeferredConstraints
 		}
 		// transactionStatementFingerprintIDs tracks all statement IDs that make up the current
 		// transaction. It's length is bound by the TxnStatsNumStmtFingerprintIDsToRecord
@@ -1505,6 +1542,16 @@ type connExecutor struct {
 	// going to find a suitable time to close the connection.
 	draining bool
 
+	// listener tracks the notification channels the session is listening on,
+	// and queues up the notifications received on them. It is created when the
+	// session first starts listening.
+	listener *listennotify.Listener
+
+	// deliverNotificationsPending is set when a DeliverNotifications command has
+	// been pushed on stmtBuf and the notifications haven't been delivered yet.
+	// It is accessed atomically, as it is set by the listener.
+	deliverNotificationsPending int32
+
 	// executorType is set to whether this executor is an ordinary executor which
 	// responds to user queries or an internal one.
 	executorType executorType
@@ -1700,6 +1747,20 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) {
 
 	ex.extraTxnState.createdSequences = make(map[descpb.ID]struct{})
 
+	switch ev.eventType {
+	case txnCommit:
+		ex.applyListenOps(ctx)
+		ex.extraTxnState.notifications.reset()
+		ex.extraTxnState.deferredConstraints.reset()
+	case txnRestart:
+		// The notifications and the deferred constraints are rolled back by the
+		// ROLLBACK TO SAVEPOINT statement which restarted the transaction, or by
+		// the rewind of an automatic retry.
+	default:
+		ex.extraTxnState.notifications.reset()
+		ex.extraTxnState.deferredConstraints.reset()
+	}
+
 	switch ev.eventType {
 	case txnCommit, txnRollback:
 		for name, p := range ex.extraTxnState.prepStmtsNamespaceAtTxnRewindPos.portals {
@@ -1882,6 +1943,15 @@ func (ex *connExecutor) execCmd() error {
 	var ev fsm.Event
 	var payload fsm.EventPayload
 	var res ResultBase
+	// notificationRes is set if res can be used to deliver the notifications
+	// received by the session.
+	var notificationRes NotificationBuffer
+	// procPortal is a copy of the portal executed by the command, taken if the
+	// portal invokes a procedure whose segment ended the transaction. The
+	// portals are closed with the transaction, but this one must be executed
+	// again for the next segment.
+	var procPortal *PreparedPortal
+	var procPortalName string
 	switch tcmd := cmd.(type) {
 	case ExecStmt:
 		ex.phaseTimes.SetSessionPhaseTime(sessionphase.SessionQueryReceived, tcmd.TimeReceived)
@@ -1998,6 +2068,14 @@ func (ex *connExecutor) execCmd() error {
 			// which allows the 1PC txn fast path to be used.
 			canAutoCommit := ex.implicitTxn() && tcmd.FollowedBySync
 			ev, payload, err = ex.execPortal(ctx, portal, portalName, stmtRes, pinfo, canAutoCommit)
+			if err == nil && ex.procCall.resume {
+				if err := portal.accountForCopy(
+					ctx, &ex.extraTxnState.prepStmtsNamespaceMemAcc, portalName,
+				); err != nil {
+					return err
+				}
+				procPortal, procPortalName = &portal, portalName
+			}
 			return err
 		}()
 		// Note: we write to ex.statsCollector.phaseTimes, instead of ex.phaseTimes,
@@ -2050,7 +2128,8 @@ func (ex *connExecutor) execCmd() error {
 			ev, payload = ex.handleAutoCommit(ctx, &tree.CommitTransaction{})
 		}
 		// Note that the Sync result will flush results to the network connection.
-		res = ex.clientComm.CreateSyncResult(pos)
+		syncRes := ex.clientComm.CreateSyncResult(pos)
+		res, notificationRes = syncRes, syncRes
 		if ex.draining {
 			// If we're draining, check whether this is a good time to finish the
 			// connection. If we're not inside a transaction, we stop processing
@@ -2080,6 +2159,16 @@ func (ex *connExecutor) execCmd() error {
 		if err != nil {
 			return err
 		}
+	case StartReplication:
+		ex.phaseTimes.SetSessionPhaseTime(sessionphase.SessionQueryReceived, tcmd.TimeReceived)
+		// Like COPY, START_REPLICATION writes its own messages to the
+		// connection.
+		res = ex.clientComm.CreateCopyInResult(pos)
+		var err error
+		ev, payload, err = ex.execStartReplication(ctx, tcmd)
+		if err != nil {
+			return err
+		}
 	case DrainRequest:
 		// We received a drain request. We terminate immediately if we're not in a
 		// transaction. If we are in a transaction, we'll finish as soon as a Sync
@@ -2093,6 +2182,20 @@ func (ex *connExecutor) execCmd() error {
 	case Flush:
 		// Closing the res will flush the connection's buffer.
 		res = ex.clientComm.CreateFlushResult(pos)
+	case DeliverNotifications:
+		if ex.idleConn() {
+			// Closing the res will deliver the notifications and flush the
+			// connection's buffer.
+			flushRes := ex.clientComm.CreateFlushResult(pos)
+			res, notificationRes = flushRes, flushRes
+		} else {
+			// Like Postgres, we only deliver notifications between transactions.
+			// The notifications will be delivered by the Sync which follows the
+			// end of the current transaction. Note that we don't want to flush
+			// here, as this would prevent the transaction from being
+			// automatically retried.
+			res = ex.clientComm.CreateDrainResult(pos)
+		}
 	default:
 		panic(errors.AssertionFailedf("unsupported command type: %T", cmd))
 	}
@@ -2137,6 +2240,30 @@ func (ex *connExecutor) execCmd() error {
 		advInfo = advanceInfo{code: advanceOne}
 	}
 
+	// A CALL statement that ended the transaction of a segment of a procedure
+	// is executed again for the next segment. If the transaction could not be
+	// ended, the segment is executed again if the transaction is retried.
+	if ex.procCall.resume {
+		ex.procCall.resume = false
+		if advInfo.code == advanceOne {
+			advInfo.code = stayInPlace
+			if procPortal != nil {
+				ex.extraTxnState.prepStmtsNamespace.portals[procPortalName] = *procPortal
+				procPortal = nil
+			}
+		} else {
+			ex.procCall.segment--
+		}
+	}
+	if procPortal != nil {
+		procPortal.close(ctx, &ex.extraTxnState.prepStmtsNamespaceMemAcc, procPortalName)
+	}
+	if advInfo.code != stayInPlace && advInfo.code != rewind {
+		ex.procCall.stmt = nil
+		ex.procCall.segment = 0
+		ex.procCall.args = nil
+	}
+
 	// Decide if we need to close the result or not. We don't need to do it if
 	// we're staying in place or rewinding - the statement will be executed
 	// again.
@@ -2152,6 +2279,9 @@ func (ex *connExecutor) execCmd() error {
 				res.SetError(pe.errorCause())
 			}
 		}
+		if notificationRes != nil && ex.idleConn() {
+			ex.bufferNotifications(notificationRes)
+		}
 		res.Close(ctx, stateToTxnStatusIndicator(ex.machine.CurState()))
 	} else {
 		res.Discard()
@@ -2180,6 +2310,8 @@ func (ex *connExecutor) execCmd() error {
 			return err
 		}
 		ex.extraTxnState.savepoints = ex.extraTxnState.rewindPosSnapshot.savepoints
+		ex.extraTxnState.notifications.rollbackTo(ex.extraTxnState.rewindPosSnapshot.notifications)
+		ex.extraTxnState.deferredConstraints.rollbackTo(ex.extraTxnState.rewindPosSnapshot.deferredConstraints)
 		// Note we use the Replace function instead of reassigning, as there are
 		// copies of the ex.sessionDataStack in the iterators and extendedEvalContext.
 		ex.sessionDataStack.Replace(ex.extraTxnState.rewindPosSnapshot.sessionDataStack)
@@ -2304,10 +2436,14 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case CopyIn:
 				// Can't advance.
+			case StartReplication:
+				// Can't advance.
 			case DrainRequest:
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(errors.AssertionFailedf("unsupported cmd: %T", cmd))
 			}
@@ -2334,6 +2470,8 @@ func (ex *connExecutor) setTxnRewindPos(ctx context.Context, pos CmdPos) error {
 	ex.stmtBuf.Ltrim(ctx, pos)
 	ex.extraTxnState.rewindPosSnapshot.savepoints = ex.extraTxnState.savepoints.clone()
 	ex.extraTxnState.rewindPosSnapshot.sessionDataStack = ex.sessionDataStack.Clone()
+	ex.extraTxnState.rewindPosSnapshot.notifications = ex.extraTxnState.notifications.savepoint()
+	ex.extraTxnState.rewindPosSnapshot.deferredConstraints = ex.extraTxnState.deferredConstraints.savepoint()
 	return ex.commitPrepStmtNamespace(ctx)
 }
 
@@ -2663,9 +2801,12 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		isoLevel := ex.txnIsoLevelToProto(ctx, modes.Isolation)
+		if err := ex.state.setIsoLevel(isoLevel); err != nil {
+			return pgerror.Wrap(err, pgcode.ActiveSQLTransaction,
+				"SET TRANSACTION ISOLATION LEVEL must be called before any query")
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -2788,6 +2929,8 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 		statsProvider:          ex.server.sqlStats,
 		indexUsageStats:        ex.indexUsageStats,
 		statementPreparer:      ex,
+		Notifications:          ex,
+		DeferredConstraints:    ex,
 	}
 	evalCtx.copyFromExecCfg(ex.server.cfg)
 }
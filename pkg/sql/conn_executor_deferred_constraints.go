@@ -0,0 +1,190 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// deferredConstraintsAccessor is the interface through which the planner
+// accesses the deferred constraint checks of the current transaction. It is
+// implemented by connExecutor.
+type deferredConstraintsAccessor interface {
+	// isConstraintDeferred returns true if the checks for the DEFERRABLE
+	// constraint with the given name are currently deferred.
+	isConstraintDeferred(name string, initiallyDeferred bool) bool
+
+	// deferConstraintViolation records a violation of a deferred constraint.
+	deferConstraintViolation(v eval.DeferredConstraintViolation)
+
+	// setConstraints applies a SET CONSTRAINTS statement. It returns the
+	// recorded violations of the constraints which were set to IMMEDIATE; these
+	// violations are no longer recorded and must be checked by the caller.
+	setConstraints(names tree.NameList, deferred bool) []eval.DeferredConstraintViolation
+
+	// deferredConstraintsSavepoint returns a copy of the modes set by SET
+	// CONSTRAINTS and of the recorded violations.
+	deferredConstraintsSavepoint() txnDeferredConstraints
+
+	// rollbackDeferredConstraints restores the state returned by
+	// deferredConstraintsSavepoint.
+	rollbackDeferredConstraints(sp txnDeferredConstraints)
+}
+
+var _ deferredConstraintsAccessor = &connExecutor{}
+
+// constraintsMode is the mode set by SET CONSTRAINTS for a constraint.
+type constraintsMode int8
+
+const (
+	// constraintsModeDefault indicates that SET CONSTRAINTS was not used, so the
+	// constraint is deferred if it was declared INITIALLY DEFERRED.
+	constraintsModeDefault constraintsMode = iota
+	constraintsModeImmediate
+	constraintsModeDeferred
+)
+
+func makeConstraintsMode(deferred bool) constraintsMode {
+	if deferred {
+		return constraintsModeDeferred
+	}
+	return constraintsModeImmediate
+}
+
+// txnDeferredConstraints tracks the SET CONSTRAINTS statements executed in a
+// transaction, and the violations of the DEFERRABLE constraints whose checks
+// were deferred. Like in Postgres, the state only lasts until the end of the
+// transaction.
+//
+// byName and violations are shared with the savepoints of the transaction:
+// they are never modified in place, only replaced or appended to.
+type txnDeferredConstraints struct {
+	// all is the mode set by SET CONSTRAINTS ALL.
+	all constraintsMode
+
+	// byName contains the modes set by SET CONSTRAINTS for individual
+	// constraints since the last SET CONSTRAINTS ALL. They take precedence over
+	// all.
+	byName map[string]constraintsMode
+
+	// violations contains the violations found by the deferred checks. They are
+	// checked again before the transaction commits, since the rows which
+	// violated the constraints may have been fixed by later statements.
+	violations []eval.DeferredConstraintViolation
+}
+
+func (d *txnDeferredConstraints) reset() {
+	*d = txnDeferredConstraints{}
+}
+
+// savepoint returns a copy of the current state, to be restored by rollbackTo
+// when rolling back to a savepoint.
+func (d *txnDeferredConstraints) savepoint() txnDeferredConstraints {
+	return *d
+}
+
+// rollbackTo restores the state recorded by savepoint. The modes set by SET
+// CONSTRAINTS since then are undone, and the violations found since then are
+// forgotten, since the rows which caused them were rolled back.
+func (d *txnDeferredConstraints) rollbackTo(sp txnDeferredConstraints) {
+	*d = sp
+	// Make appending to the violations reallocate them, rather than overwrite
+	// the ones found after the savepoint was created, which other copies of the
+	// state may still refer to.
+	d.violations = d.violations[:len(d.violations):len(d.violations)]
+}
+
+// isConstraintDeferred is part of the deferredConstraintsAccessor interface.
+func (ex *connExecutor) isConstraintDeferred(name string, initiallyDeferred bool) bool {
+	// The deferred checks are run when the session commits its transaction,
+	// which it doesn't do if the transaction was handed to it.
+	if ex.extraTxnState.fromOuterTxn || ex.executorType == executorTypeInternal {
+		return false
+	}
+	d := &ex.extraTxnState.deferredConstraints
+	mode := d.byName[name]
+	if mode == constraintsModeDefault {
+		mode = d.all
+	}
+	if mode == constraintsModeDefault {
+		return initiallyDeferred
+	}
+	return mode == constraintsModeDeferred
+}
+
+// deferConstraintViolation is part of the deferredConstraintsAccessor
+// interface.
+func (ex *connExecutor) deferConstraintViolation(v eval.DeferredConstraintViolation) {
+	d := &ex.extraTxnState.deferredConstraints
+	d.violations = append(d.violations, v)
+}
+
+// setConstraints is part of the deferredConstraintsAccessor interface.
+func (ex *connExecutor) setConstraints(
+	names tree.NameList, deferred bool,
+) (immediate []eval.DeferredConstraintViolation) {
+	d := &ex.extraTxnState.deferredConstraints
+	mode := makeConstraintsMode(deferred)
+	if len(names) == 0 {
+		d.all = mode
+		d.byName = nil
+	} else {
+		byName := make(map[string]constraintsMode, len(d.byName)+len(names))
+		for name, mode := range d.byName {
+			byName[name] = mode
+		}
+		for _, name := range names {
+			byName[string(name)] = mode
+		}
+		d.byName = byName
+	}
+	if deferred {
+		return nil
+	}
+	// Split the recorded violations between those of the constraints which are
+	// now IMMEDIATE and the others.
+	var remaining []eval.DeferredConstraintViolation
+	for _, v := range d.violations {
+		if len(names) == 0 || names.Contains(tree.Name(v.ConstraintName)) {
+			immediate = append(immediate, v)
+		} else {
+			remaining = append(remaining, v)
+		}
+	}
+	d.violations = remaining
+	return immediate
+}
+
+// deferredConstraintsSavepoint is part of the deferredConstraintsAccessor
+// interface.
+func (ex *connExecutor) deferredConstraintsSavepoint() txnDeferredConstraints {
+	return ex.extraTxnState.deferredConstraints.savepoint()
+}
+
+// rollbackDeferredConstraints is part of the deferredConstraintsAccessor
+// interface.
+func (ex *connExecutor) rollbackDeferredConstraints(sp txnDeferredConstraints) {
+	ex.extraTxnState.deferredConstraints.rollbackTo(sp)
+}
+
+// checkDeferredConstraints checks again the violations of the deferred
+// constraints recorded by the current transaction, and returns an error if one
+// of them still exists. It must be called before the transaction commits.
+func (ex *connExecutor) checkDeferredConstraints(ctx context.Context) error {
+	violations := ex.extraTxnState.deferredConstraints.violations
+	if len(violations) == 0 {
+		return nil
+	}
+	return ex.planner.recheckDeferredConstraintViolations(ctx, violations)
+}
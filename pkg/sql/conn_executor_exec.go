@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -713,6 +714,18 @@ func (ex *connExecutor) execStmtInOpenState(
 	p.stmt = stmt
 	p.cancelChecker.Reset(ctx)
 
+	// A CALL statement is executed once for each segment of the body of the
+	// invoked procedure.
+	p.procSegment, p.procArgs, p.procTxnControl = 0, nil, nil
+	if call, ok := ast.(*tree.Call); ok {
+		if ex.procCall.stmt != call {
+			ex.procCall.stmt = call
+			ex.procCall.segment = 0
+			ex.procCall.args = nil
+		}
+		p.procSegment, p.procArgs = ex.procCall.segment, ex.procCall.args
+	}
+
 	p.autoCommit = canAutoCommit && !ex.server.cfg.TestingKnobs.DisableAutoCommitDuringExec
 	p.extendedEvalCtx.TxnIsSingleStmt = canAutoCommit && !ex.extraTxnState.firstStmtExecuted
 	ex.extraTxnState.firstStmtExecuted = true
@@ -728,7 +741,11 @@ func (ex *connExecutor) execStmtInOpenState(
 		stmtCtx = ctx
 	}
 
-	if err := ex.dispatchToExecutionEngine(stmtCtx, p, res); err != nil {
+	dispatch := ex.dispatchToExecutionEngine
+	if ex.usesPerStatementReadSnapshot() {
+		dispatch = ex.dispatchReadCommittedStmtToExecutionEngine
+	}
+	if err := dispatch(stmtCtx, p, res); err != nil {
 		stmtThresholdSpan.Finish()
 		return nil, nil, err
 	}
@@ -756,6 +773,25 @@ func (ex *connExecutor) execStmtInOpenState(
 		return makeErrEvent(err)
 	}
 
+	// The executed segment of a procedure ended with a COMMIT or ROLLBACK
+	// statement, which ends the transaction. The CALL statement is then
+	// executed again for the next segment, in a new transaction.
+	if txnControl := p.procTxnControl; txnControl != nil {
+		if !canAutoCommit {
+			return makeErrEvent(pgerror.New(pgcode.InvalidTransactionTermination,
+				"invalid transaction termination"))
+		}
+		ex.procCall.segment++
+		ex.procCall.args = p.procArgs
+		ex.procCall.resume = true
+		if _, ok := txnControl.(*tree.RollbackTransaction); ok {
+			ev, payload := ex.rollbackSQLTransaction(ctx, txnControl)
+			return ev, payload, nil
+		}
+		ev, payload := ex.handleAutoCommit(ctx, ast)
+		return ev, payload, nil
+	}
+
 	txn := ex.state.mu.txn
 
 	if !os.ImplicitTxn.Get() && txn.IsSerializablePushAndRefreshNotPossible() {
@@ -988,6 +1024,10 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 		ex.state.mu.txn.ConfigureStepping(ctx, prevSteppingMode)
 	}
 
+	if err := ex.checkDeferredConstraints(ctx); err != nil {
+		return err
+	}
+
 	if err := ex.createJobs(ctx); err != nil {
 		return err
 	}
@@ -1016,6 +1056,10 @@ func (ex *connExecutor) commitSQLTransactionInternal(ctx context.Context) error
 		return err
 	}
 
+	if err := ex.sendNotifications(ctx, ex.state.mu.txn); err != nil {
+		return err
+	}
+
 	if err := ex.state.mu.txn.Commit(ctx); err != nil {
 		return err
 	}
@@ -1149,6 +1193,14 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	if stmt.AST.StatementReturnType() == tree.Rows {
 		cols = planner.curPlan.main.planColumns()
 	}
+	// A CALL statement only returns the result of the last segment of a
+	// procedure with OUT or INOUT arguments. The result of the other segments,
+	// and of procedures without such arguments, is void.
+	if _, ok := stmt.AST.(*tree.Call); ok {
+		if len(cols) == 1 && cols[0].Typ.Family() == types.VoidFamily {
+			res = &procCallResult{RestrictedCommandResult: res}
+		}
+	}
 	if err := ex.initStatementResult(ctx, res, stmt.AST, cols); err != nil {
 		res.SetError(err)
 		return nil
@@ -1664,6 +1716,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartExplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
+				ex.txnIsoLevelWithSessionDefault(ctx, s.Modes.Isolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1685,6 +1738,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartImplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+				ex.txnIsoLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1715,6 +1769,7 @@ func (ex *connExecutor) beginImplicitTxn(
 	return eventStartImplicitTxn,
 		makeEventTxnStartPayload(
 			ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+			ex.txnIsoLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 			mode,
 			sqlTs,
 			historicalTs,
@@ -2153,6 +2208,12 @@ func (ex *connExecutor) handleAutoCommit(
 	txn := ex.state.mu.txn
 	if txn.IsCommitted() {
 		log.Event(ctx, "statement execution committed the txn")
+		// The statement cannot commit the txn if it sent notifications, since
+		// they must be written in the txn (see tableWriterBase.finalize).
+		if ex.hasPendingNotifications() {
+			return ex.makeErrEvent(errors.AssertionFailedf(
+				"statement execution committed the txn with pending notifications"), stmt)
+		}
 		return eventTxnFinishCommitted{}, nil
 	}
 
@@ -2424,3 +2485,32 @@ func (ex *connExecutor) execWithProfiling(
 	}
 	return err
 }
+
+// procCallResult is a RestrictedCommandResult for a CALL statement whose result
+// is void, which is the case for procedures without OUT or INOUT arguments and
+// for the segments of a procedure that are not the last one. Like in Postgres,
+// no columns or rows are returned for such a statement. The values of the OUT
+// and INOUT arguments are returned as a row by the last segment, whose result
+// is not wrapped.
+type procCallResult struct {
+	RestrictedCommandResult
+}
+
+// SetColumns is part of the RestrictedCommandResult interface.
+func (r *procCallResult) SetColumns(context.Context, colinfo.ResultColumns) {}
+
+// AddRow is part of the RestrictedCommandResult interface.
+func (r *procCallResult) AddRow(_ context.Context, row tree.Datums) error {
+	if len(row) != 1 || row[0].ResolvedType().Family() != types.VoidFamily {
+		return errors.AssertionFailedf("unexpected result of a void procedure: %s", row)
+	}
+	return nil
+}
+
+// AddBatch is part of the RestrictedCommandResult interface.
+func (r *procCallResult) AddBatch(_ context.Context, batch coldata.Batch) error {
+	if batch.Width() != 1 || batch.ColVec(0).Type().Family() != types.VoidFamily {
+		return errors.AssertionFailedf("unexpected result of a void procedure")
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/listennotify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// notificationsAccessor is the interface through which the planner accesses
+// the LISTEN/NOTIFY state of the session. It is implemented by connExecutor.
+type notificationsAccessor interface {
+	// queueNotification records a notification to be sent once the current
+	// transaction commits.
+	queueNotification(channel, payload string) error
+
+	// queueListen records that the session should start listening on the
+	// given channel once the current transaction commits.
+	queueListen(channel string) error
+
+	// queueUnlisten records that the session should stop listening on the
+	// given channel once the current transaction commits.
+	queueUnlisten(channel string)
+
+	// queueUnlistenAll records that the session should stop listening on all
+	// channels once the current transaction commits.
+	queueUnlistenAll()
+
+	// hasPendingNotifications returns whether the current transaction has
+	// sent notifications.
+	hasPendingNotifications() bool
+
+	// notificationsSavepoint returns the current position in the notifications
+	// and the LISTEN or UNLISTEN statements queued by the transaction.
+	notificationsSavepoint() notificationsSavepoint
+
+	// rollbackNotifications discards the notifications and the LISTEN or
+	// UNLISTEN statements queued since the given position was returned by
+	// notificationsSavepoint.
+	rollbackNotifications(sp notificationsSavepoint)
+
+	// listeningChannels returns the channels the session is listening on.
+	listeningChannels() []string
+}
+
+var _ notificationsAccessor = &connExecutor{}
+
+// txnNotifications accumulates the effects of the LISTEN, UNLISTEN and NOTIFY
+// statements executed in a transaction. Like in Postgres, they only take effect
+// if and when the transaction commits.
+type txnNotifications struct {
+	// pending contains the notifications sent by the transaction, in the order
+	// in which they were sent. Duplicate notifications are folded.
+	pending []pgnotify.Notification
+
+	// listenOps contains the LISTEN and UNLISTEN statements executed in the
+	// transaction, in order.
+	listenOps []listenOp
+}
+
+// listenOp is a LISTEN or UNLISTEN statement to apply once a transaction
+// commits.
+type listenOp struct {
+	// channel is the channel to listen on or to stop listening on. It is empty
+	// for UNLISTEN *.
+	channel string
+	// listen is set for LISTEN, and unset for UNLISTEN.
+	listen bool
+}
+
+func (n *txnNotifications) reset() {
+	n.pending = nil
+	n.listenOps = nil
+}
+
+// notificationsSavepoint records how many notifications and LISTEN or UNLISTEN
+// statements a transaction had queued when a savepoint was created.
+type notificationsSavepoint struct {
+	numPending   int
+	numListenOps int
+}
+
+// savepoint returns the current position in the queued notifications and
+// LISTEN or UNLISTEN statements.
+func (n *txnNotifications) savepoint() notificationsSavepoint {
+	return notificationsSavepoint{
+		numPending:   len(n.pending),
+		numListenOps: len(n.listenOps),
+	}
+}
+
+// rollbackTo discards the notifications and the LISTEN or UNLISTEN statements
+// queued since the given savepoint was created. Like in Postgres, the effects
+// of a rolled back subtransaction are never applied.
+func (n *txnNotifications) rollbackTo(sp notificationsSavepoint) {
+	n.pending = n.pending[:sp.numPending]
+	n.listenOps = n.listenOps[:sp.numListenOps]
+}
+
+// queueNotification is part of the notificationsAccessor interface.
+func (ex *connExecutor) queueNotification(channel, payload string) error {
+	// Notifications are written when the session commits its transaction,
+	// which it doesn't do if the transaction was handed to it.
+	if ex.extraTxnState.fromOuterTxn || ex.server.cfg.NotificationRegistry == nil {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"NOTIFY is not supported in this session")
+	}
+	n := pgnotify.Notification{
+		Channel:   channel,
+		Payload:   payload,
+		SenderPID: ex.queryCancelKey.GetPGBackendPID(),
+	}
+	for _, p := range ex.extraTxnState.notifications.pending {
+		if p == n {
+			return nil
+		}
+	}
+	ex.extraTxnState.notifications.pending = append(ex.extraTxnState.notifications.pending, n)
+	return nil
+}
+
+// queueListen is part of the notificationsAccessor interface.
+func (ex *connExecutor) queueListen(channel string) error {
+	if ex.executorType == executorTypeInternal || ex.server.cfg.NotificationRegistry == nil {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"LISTEN is not supported in this session")
+	}
+	ex.extraTxnState.notifications.listenOps = append(
+		ex.extraTxnState.notifications.listenOps, listenOp{channel: channel, listen: true},
+	)
+	return nil
+}
+
+// queueUnlisten is part of the notificationsAccessor interface.
+func (ex *connExecutor) queueUnlisten(channel string) {
+	ex.extraTxnState.notifications.listenOps = append(
+		ex.extraTxnState.notifications.listenOps, listenOp{channel: channel},
+	)
+}
+
+// queueUnlistenAll is part of the notificationsAccessor interface.
+func (ex *connExecutor) queueUnlistenAll() {
+	ex.queueUnlisten("" /* channel */)
+}
+
+// hasPendingNotifications is part of the notificationsAccessor interface.
+func (ex *connExecutor) hasPendingNotifications() bool {
+	return len(ex.extraTxnState.notifications.pending) > 0
+}
+
+// notificationsSavepoint is part of the notificationsAccessor interface.
+func (ex *connExecutor) notificationsSavepoint() notificationsSavepoint {
+	return ex.extraTxnState.notifications.savepoint()
+}
+
+// rollbackNotifications is part of the notificationsAccessor interface.
+func (ex *connExecutor) rollbackNotifications(sp notificationsSavepoint) {
+	ex.extraTxnState.notifications.rollbackTo(sp)
+}
+
+// listeningChannels is part of the notificationsAccessor interface.
+func (ex *connExecutor) listeningChannels() []string {
+	if ex.listener == nil {
+		return nil
+	}
+	return ex.listener.Channels()
+}
+
+// sendNotifications writes the notifications sent by the current transaction
+// using txn, so that they become visible to the listening sessions when txn
+// commits.
+func (ex *connExecutor) sendNotifications(ctx context.Context, txn *kv.Txn) error {
+	pending := ex.extraTxnState.notifications.pending
+	if len(pending) == 0 {
+		return nil
+	}
+	return ex.server.cfg.NotificationRegistry.Send(
+		ctx, txn, ex.queryCancelKey.GetPGBackendPID(), pending,
+	)
+}
+
+// applyListenOps applies the LISTEN and UNLISTEN statements executed by the
+// transaction which just committed.
+func (ex *connExecutor) applyListenOps(ctx context.Context) {
+	for _, op := range ex.extraTxnState.notifications.listenOps {
+		switch {
+		case op.listen:
+			if ex.listener == nil {
+				connCtx := ex.ctxHolder.connCtx
+				ex.listener = ex.server.cfg.NotificationRegistry.NewListener(func() {
+					ex.onNotification(connCtx)
+				})
+			}
+			// The transaction has already committed, so the best we can do is to
+			// report the failure.
+			if err := ex.listener.Listen(ctx, op.channel); err != nil {
+				log.Warningf(ctx, "failed to listen on channel %q: %v", op.channel, err)
+			}
+		case ex.listener == nil:
+			// The session isn't listening on any channel.
+		case op.channel == "":
+			ex.listener.UnlistenAll()
+		default:
+			ex.listener.Unlisten(op.channel)
+		}
+	}
+}
+
+// onNotification is called by the session's listener when a notification is
+// received. It asks for the notifications to be delivered to the client by
+// pushing a DeliverNotifications command on the statement buffer, unless such
+// a command is already pending.
+//
+// onNotification is called asynchronously: it cannot access the state of the
+// connExecutor.
+func (ex *connExecutor) onNotification(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&ex.deliverNotificationsPending, 0, 1) {
+		return
+	}
+	// The only possible error is that the buffer is closed, in which case the
+	// session is going away anyway.
+	_ = ex.stmtBuf.Push(ctx, DeliverNotifications{})
+}
+
+// bufferNotifications buffers the notifications received by the session on res
+// for them to be delivered to the client. It must only be called when the
+// session is not in a transaction.
+func (ex *connExecutor) bufferNotifications(res NotificationBuffer) {
+	if ex.listener == nil {
+		return
+	}
+	// Reset the flag before draining the queue: a notification queued up after
+	// the queue was drained results in another DeliverNotifications command.
+	atomic.StoreInt32(&ex.deliverNotificationsPending, 0)
+	for _, n := range ex.listener.Drain() {
+		res.BufferNotification(n)
+	}
+}
+
+// maxNotificationChannelLength is the maximum length of the name of a
+// notification channel, matching the maximum length of identifiers in
+// Postgres.
+const maxNotificationChannelLength = 63
+
+// validateNotification checks that a notification can be sent on the given
+// channel with the given payload.
+func validateNotification(channel, payload string) error {
+	if err := validateNotificationChannel(channel); err != nil {
+		return err
+	}
+	if len(payload) >= listennotify.MaxPayloadLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	return nil
+}
+
+// validateNotificationChannel checks that the given channel name is valid.
+func validateNotificationChannel(channel string) error {
+	if channel == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	if len(channel) > maxNotificationChannelLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name too long")
+	}
+	return nil
+}
@@ -101,10 +101,12 @@ func (ex *connExecutor) execSavepointInOpenState(
 	}
 
 	sp := savepoint{
-		name:            s.Name,
-		commitOnRelease: commitOnRelease,
-		kvToken:         token,
-		numDDL:          ex.extraTxnState.numDDL,
+		name:                s.Name,
+		commitOnRelease:     commitOnRelease,
+		kvToken:             token,
+		numDDL:              ex.extraTxnState.numDDL,
+		notifications:       ex.extraTxnState.notifications.savepoint(),
+		deferredConstraints: ex.extraTxnState.deferredConstraints.savepoint(),
 	}
 	savepoints.push(sp)
 	ex.sessionDataStack.PushTopClone()
@@ -203,6 +205,8 @@ func (ex *connExecutor) execRollbackToSavepointInOpenState(
 		ev, payload := ex.makeErrEvent(err, s)
 		return ev, payload
 	}
+	ex.extraTxnState.notifications.rollbackTo(entry.notifications)
+	ex.extraTxnState.deferredConstraints.rollbackTo(entry.deferredConstraints)
 
 	if err := ex.popSavepointsToIdx(s, idx); err != nil {
 		return ex.makeErrEvent(err, s)
@@ -287,6 +291,8 @@ func (ex *connExecutor) execRollbackToSavepointInAbortedState(
 	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, entry.kvToken); err != nil {
 		return ex.makeErrEvent(err, s)
 	}
+	ex.extraTxnState.notifications.rollbackTo(entry.notifications)
+	ex.extraTxnState.deferredConstraints.rollbackTo(entry.deferredConstraints)
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -351,6 +357,16 @@ type savepoint struct {
 	// more DDL statements were executed since the savepoint's creation.
 	// TODO(knz): support partial DDL cancellation in pending txns.
 	numDDL int
+
+	// notifications records the notifications and the LISTEN or UNLISTEN
+	// statements queued by the transaction when the savepoint was created. The
+	// ones queued afterwards are discarded when rolling back to the savepoint.
+	notifications notificationsSavepoint
+
+	// deferredConstraints records the modes set by SET CONSTRAINTS and the
+	// violations of deferred constraints when the savepoint was created. They
+	// are restored when rolling back to the savepoint.
+	deferredConstraints txnDeferredConstraints
 }
 
 type savepointStack []savepoint
@@ -21,6 +21,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -351,6 +353,30 @@ func (c CopyIn) String() string {
 
 var _ Command = CopyIn{}
 
+// StartReplication is the command for streaming logical replication, which is
+// started by the START_REPLICATION replication command.
+type StartReplication struct {
+	Stmt *pgrepl.StartReplication
+	// Conn is the network connection. Streaming takes control of the
+	// connection.
+	Conn pgrepl.Conn
+	// StreamDone is decremented once streaming finishes, signaling that control
+	// of the connection is being handed back to the network routine.
+	StreamDone *sync.WaitGroup
+	// TimeReceived is the time at which the message was received
+	// from the client.
+	TimeReceived time.Time
+}
+
+// command implements the Command interface.
+func (StartReplication) command() string { return "start replication" }
+
+func (c StartReplication) String() string {
+	return fmt.Sprintf("StartReplication: %s", c.Stmt)
+}
+
+var _ Command = StartReplication{}
+
 // DrainRequest represents a notice that the server is draining and command
 // processing should stop soon.
 //
@@ -366,6 +392,22 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications is a command asking for the asynchronous notifications
+// received by the session to be delivered to the client. It is pushed on the
+// buffer by the session's listener when notifications arrive; if the session
+// is in a transaction at that point, the notifications are delivered once the
+// transaction is over instead.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() string { return "deliver notifications" }
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -766,6 +808,18 @@ type RestrictedCommandResult interface {
 	// to this CommandResult, will be flushed immediately to the client.
 	// This is currently used for sinkless changefeeds.
 	DisableBuffering()
+
+	// BufferedResultsLen returns the length of the results which have been
+	// buffered for the client so far. It can be passed to
+	// TruncateBufferedResults.
+	BufferedResultsLen() int
+
+	// TruncateBufferedResults discards the results which were buffered after
+	// the given position, as returned by BufferedResultsLen, along with the
+	// number of rows affected by the statement. It returns false if the results
+	// can't be discarded, because some of them were already sent to the client.
+	// This is used to retry statements of READ COMMITTED transactions.
+	TruncateBufferedResults(idx int) bool
 }
 
 // DescribeResult represents the result of a Describe command (for either
@@ -811,12 +865,22 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+	NotificationBuffer
 }
 
 // FlushResult represents the result of a Flush command. When this result is
 // closed, all previously accumulated results are flushed to the client.
 type FlushResult interface {
 	ResultBase
+	NotificationBuffer
+}
+
+// NotificationBuffer is implemented by the results on which asynchronous
+// notifications can be delivered to the client.
+type NotificationBuffer interface {
+	// BufferNotification appends a notification to the result.
+	// This gets flushed only when the result is closed.
+	BufferNotification(notification pgnotify.Notification)
 }
 
 // DrainResult represents the result of a Drain command. Closing this result
@@ -934,6 +998,11 @@ func (r *streamingCommandResult) BufferNotice(notice pgnotice.Notice) {
 	panic("unimplemented")
 }
 
+// BufferNotification is part of the NotificationBuffer interface.
+func (r *streamingCommandResult) BufferNotification(notification pgnotify.Notification) {
+	panic("unimplemented")
+}
+
 // ResetStmtType is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
@@ -965,6 +1034,18 @@ func (r *streamingCommandResult) DisableBuffering() {
 	panic("cannot disable buffering here")
 }
 
+// BufferedResultsLen is part of the RestrictedCommandResult interface.
+func (r *streamingCommandResult) BufferedResultsLen() int {
+	return 0
+}
+
+// TruncateBufferedResults is part of the RestrictedCommandResult interface.
+func (r *streamingCommandResult) TruncateBufferedResults(int) bool {
+	// The results are not buffered: they are sent to the iterator as soon as
+	// they are added.
+	return false
+}
+
 // SetError is part of the RestrictedCommandResult interface.
 func (r *streamingCommandResult) SetError(err error) {
 	r.err = err
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/constraint"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security/username"
@@ -41,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/spanconfig"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catformat"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
@@ -53,6 +55,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/clusterunique"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -2489,6 +2492,46 @@ func writeCreateTypeDescRow(
 			tree.NewDString(tree.AsString(node)),      // create_statement
 			enumLabelsDatum,
 		)
+	case descpb.TypeDescriptor_DOMAIN:
+		desc := typeDesc.TypeDesc()
+		name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+		if err != nil {
+			return false, err
+		}
+		node := &tree.CreateType{
+			Variety:    tree.Domain,
+			TypeName:   name,
+			DomainType: desc.DomainBaseType,
+		}
+		if desc.DomainDefaultExpr != nil {
+			if node.DomainDefault, err = parser.ParseExpr(*desc.DomainDefaultExpr); err != nil {
+				return false, err
+			}
+		}
+		if desc.DomainNotNull {
+			node.DomainConstraints = append(node.DomainConstraints, tree.DomainConstraint{
+				Nullability: tree.NotNull,
+			})
+		}
+		for _, c := range desc.DomainConstraints {
+			expr, err := parser.ParseExpr(c.Expr)
+			if err != nil {
+				return false, err
+			}
+			node.DomainConstraints = append(node.DomainConstraints, tree.DomainConstraint{
+				Name:  tree.Name(c.Name),
+				Check: expr,
+			})
+		}
+		return true, addRow(
+			tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+			tree.NewDString(db.GetName()),             // database_name
+			tree.NewDString(sc),                       // schema_name
+			tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+			tree.NewDString(typeDesc.GetName()),       // descriptor_name
+			tree.NewDString(tree.AsString(node)),      // create_statement
+			tree.DNull,                                // enum_members
+		)
 	case descpb.TypeDescriptor_MULTIREGION_ENUM:
 		// Multi-region enums are created implicitly, so we don't have create
 		// statements for them.
@@ -2658,13 +2701,21 @@ CREATE TABLE crdb_internal.create_function_statements (
 			}
 			for i := range treeNode.Options {
 				if body, ok := treeNode.Options[i].(tree.FunctionBodyStr); ok {
-					typeReplacedBody, err := formatFunctionQueryTypesForDisplay(ctx, &p.semaCtx, p.SessionData(), string(body))
-					if err != nil {
-						return err
-					}
-					seqReplacedBody, err := formatQuerySequencesForDisplay(ctx, &p.semaCtx, typeReplacedBody, true /* multiStmt */)
-					if err != nil {
-						return err
+					var seqReplacedBody string
+					if fnDesc.GetLanguage() == catpb.Function_PLPGSQL {
+						seqReplacedBody, err = formatPLpgSQLFuncBodyForDisplay(ctx, &p.semaCtx, string(body))
+						if err != nil {
+							return err
+						}
+					} else {
+						typeReplacedBody, err := formatFunctionQueryTypesForDisplay(ctx, &p.semaCtx, p.SessionData(), string(body))
+						if err != nil {
+							return err
+						}
+						seqReplacedBody, err = formatQuerySequencesForDisplay(ctx, &p.semaCtx, typeReplacedBody, true /* multiStmt */)
+						if err != nil {
+							return err
+						}
 					}
 					stmtStrs := strings.Split(seqReplacedBody, "\n")
 					for i := range stmtStrs {
@@ -3343,6 +3394,7 @@ CREATE VIEW crdb_internal.ranges AS SELECT
 	voting_replicas,
 	non_voting_replicas,
 	learner_replicas,
+	read_replicas,
 	split_enforced_until,
 	crdb_internal.lease_holder(start_key) AS lease_holder,
 	(crdb_internal.range_stats(start_key)->>'key_bytes')::INT +
@@ -3367,6 +3419,7 @@ FROM crdb_internal.ranges_no_leases
 		{Name: "voting_replicas", Typ: types.Int2Vector},
 		{Name: "non_voting_replicas", Typ: types.Int2Vector},
 		{Name: "learner_replicas", Typ: types.Int2Vector},
+		{Name: "read_replicas", Typ: types.Int2Vector},
 		{Name: "split_enforced_until", Typ: types.Timestamp},
 		{Name: "lease_holder", Typ: types.Int},
 		{Name: "range_size", Typ: types.Int},
@@ -3470,6 +3523,8 @@ var crdbInternalRangesNoLeasesTable = virtualSchemaTable{
 	// NB 1: The `replicas` column is the union of `voting_replicas` and
 	// `non_voting_replicas` and does not include `learner_replicas`.
 	// NB 2: All the values in the `*replicas` columns correspond to store IDs.
+	// NB 3: The `read_replicas` are the `non_voting_replicas` which are the
+	// dedicated read replicas of the range, as per its span config.
 	schema: `
 CREATE TABLE crdb_internal.ranges_no_leases (
   range_id             INT NOT NULL,
@@ -3487,6 +3542,7 @@ CREATE TABLE crdb_internal.ranges_no_leases (
   voting_replicas      INT[] NOT NULL,
   non_voting_replicas  INT[] NOT NULL,
   learner_replicas     INT[] NOT NULL,
+  read_replicas        INT[] NOT NULL,
   split_enforced_until TIMESTAMP
 )
 `,
@@ -3530,8 +3586,15 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 			return nil, nil, err
 		}
 		nodeIDToLocality := make(map[roachpb.NodeID]roachpb.Locality)
+		nodeIDToDesc := make(map[roachpb.NodeID]roachpb.NodeDescriptor)
 		for _, desc := range descriptors {
 			nodeIDToLocality[desc.NodeID] = desc.Locality
+			nodeIDToDesc[desc.NodeID] = desc
+		}
+
+		spanConfigRecords, err := getSpanConfigRecordsForRanges(ctx, p)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		var desc roachpb.RangeDescriptor
@@ -3584,6 +3647,12 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 					return nil, err
 				}
 			}
+			readReplicasArr := tree.NewDArray(types.Int)
+			for _, replica := range readReplicasOfRange(&desc, spanConfigRecords, nodeIDToDesc) {
+				if err := readReplicasArr.Append(tree.NewDInt(tree.DInt(replica.StoreID))); err != nil {
+					return nil, err
+				}
+			}
 
 			replicaLocalityArr := tree.NewDArray(types.String)
 			for _, replica := range votersAndNonVoters {
@@ -3619,12 +3688,95 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 				votersArr,
 				nonVotersArr,
 				learnersArr,
+				readReplicasArr,
 				splitEnforcedUntil,
 			}, nil
 		}, nil, nil
 	},
 }
 
+// getSpanConfigRecordsForRanges returns the span config records covering the
+// keyspace of the tenant, sorted by their spans, so that the span config of a
+// range can be looked up by readReplicasOfRange.
+func getSpanConfigRecordsForRanges(ctx context.Context, p *planner) ([]spanconfig.Record, error) {
+	accessor := p.ExecCfg().SpanConfigKVAccessor
+	if accessor == nil {
+		return nil, nil
+	}
+	var targets spanconfig.Targets
+	if codec := p.ExecCfg().Codec; codec.ForSystemTenant() {
+		// The span configs of the system span config span itself are reserved for
+		// system span configs, which don't apply to ranges.
+		targets = spanconfig.Targets{
+			spanconfig.MakeTargetFromSpan(roachpb.Span{
+				Key: keys.MinKey, EndKey: keys.SystemSpanConfigSpan.Key,
+			}),
+			spanconfig.MakeTargetFromSpan(roachpb.Span{
+				Key: keys.SystemSpanConfigSpan.EndKey, EndKey: keys.MaxKey,
+			}),
+		}
+	} else {
+		prefix := codec.TenantPrefix()
+		targets = spanconfig.Targets{
+			spanconfig.MakeTargetFromSpan(roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}),
+		}
+	}
+	records, err := accessor.GetSpanConfigRecords(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+	spanRecords := records[:0]
+	for _, record := range records {
+		if record.GetTarget().IsSpanTarget() {
+			spanRecords = append(spanRecords, record)
+		}
+	}
+	sort.Slice(spanRecords, func(i, j int) bool {
+		return spanRecords[i].GetTarget().Less(spanRecords[j].GetTarget())
+	})
+	return spanRecords, nil
+}
+
+// readReplicasOfRange returns the non-voting replicas of the range which are
+// its dedicated read replicas as per the span config applying to it, sorted by
+// store ID. The read replica constraints are matched against the localities of
+// the nodes of the replicas only, since store attributes aren't gossiped to
+// SQL.
+func readReplicasOfRange(
+	desc *roachpb.RangeDescriptor,
+	records []spanconfig.Record,
+	nodeIDToDesc map[roachpb.NodeID]roachpb.NodeDescriptor,
+) []roachpb.ReplicaDescriptor {
+	startKey := desc.StartKey.AsRawKey()
+	idx := sort.Search(len(records), func(i int) bool {
+		return startKey.Compare(records[i].GetTarget().GetSpan().EndKey) < 0
+	})
+	if idx == len(records) || !records[idx].GetTarget().GetSpan().ContainsKey(startKey) {
+		return nil
+	}
+	conf := records[idx].GetConfig()
+	if conf.NumReadReplicas == 0 {
+		return nil
+	}
+	storeIDToNodeID := make(map[roachpb.StoreID]roachpb.NodeID)
+	for _, repl := range desc.Replicas().Descriptors() {
+		storeIDToNodeID[repl.StoreID] = repl.NodeID
+	}
+	getStoreDescFn := func(storeID roachpb.StoreID) (roachpb.StoreDescriptor, bool) {
+		nodeDesc, ok := nodeIDToDesc[storeIDToNodeID[storeID]]
+		if !ok {
+			return roachpb.StoreDescriptor{}, false
+		}
+		return roachpb.StoreDescriptor{StoreID: storeID, Node: nodeDesc}, true
+	}
+	readReplicas, _ := constraint.ReadReplicas(getStoreDescFn,
+		desc.Replicas().NonVoterDescriptors(), conf.NumReadReplicas, conf.ReadReplicaConstraints)
+	sort.Slice(readReplicas, func(i, j int) bool {
+		return readReplicas[i].StoreID < readReplicas[j].StoreID
+	})
+	return readReplicas
+}
+
 // getAllNames returns a map from ID to namespaceKey for every entry in
 // system.namespace.
 func (p *planner) getAllNames(ctx context.Context) (map[descpb.ID]catalog.NameKey, error) {
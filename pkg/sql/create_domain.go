@@ -0,0 +1,260 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catprivilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/seqexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+// createUserDefinedDomain creates a domain type, along with its implicit
+// array type.
+func (p *planner) createUserDefinedDomain(params runParams, n *createTypeNode) error {
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.Domains) {
+		return pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot run CREATE DOMAIN before system is fully upgraded to v22.2",
+		)
+	}
+
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("domain"))
+
+	base, err := tree.ResolveType(params.ctx, n.n.DomainType, p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return err
+	}
+	if err := validateDomainBaseType(base); err != nil {
+		return err
+	}
+
+	schema, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+
+	var defaultExpr *string
+	if n.n.DomainDefault != nil {
+		if defaultExpr, err = p.makeDomainDefaultExpr(params.ctx, base, n.n.DomainDefault); err != nil {
+			return err
+		}
+	}
+
+	var notNull, hasNullability bool
+	var constraints []descpb.TypeDescriptor_DomainConstraint
+	for i := range n.n.DomainConstraints {
+		c := &n.n.DomainConstraints[i]
+		if c.Check == nil {
+			isNotNull := c.Nullability == tree.NotNull
+			if hasNullability && notNull != isNotNull {
+				return pgerror.New(pgcode.Syntax, "conflicting NULL/NOT NULL constraints")
+			}
+			notNull, hasNullability = isNotNull, true
+			continue
+		}
+		dc, err := p.makeDomainCheckConstraint(params.ctx, n.typeName, base, constraints, c)
+		if err != nil {
+			return err
+		}
+		constraints = append(constraints, dc)
+	}
+
+	id, err := params.EvalContext().DescIDGenerator.GenerateUniqueDescID(params.ctx)
+	if err != nil {
+		return err
+	}
+	privs := catprivilege.CreatePrivilegesFromDefaultPrivileges(
+		n.dbDesc.GetDefaultPrivilegeDescriptor(),
+		schema.GetDefaultPrivilegeDescriptor(),
+		n.dbDesc.GetID(),
+		params.SessionData().User(),
+		privilege.Types,
+		n.dbDesc.GetPrivileges(),
+	)
+	typeDesc := typedesc.NewBuilder(&descpb.TypeDescriptor{
+		Name:              n.typeName.Type(),
+		ID:                id,
+		ParentID:          n.dbDesc.GetID(),
+		ParentSchemaID:    schema.GetID(),
+		Kind:              descpb.TypeDescriptor_DOMAIN,
+		Version:           1,
+		Privileges:        privs,
+		DomainBaseType:    base,
+		DomainDefaultExpr: defaultExpr,
+		DomainNotNull:     notNull,
+		DomainConstraints: constraints,
+	}).BuildCreatedMutableType()
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n.typeName, typeDesc, n.dbDesc, schema.GetID())
+	if err != nil {
+		return err
+	}
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		catalogkeys.MakeObjectNameKey(params.ExecCfg().Codec, n.dbDesc.GetID(), schema.GetID(), n.typeName.Type()),
+		id,
+		typeDesc,
+		n.typeName.String(),
+	); err != nil {
+		return err
+	}
+
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// validateDomainBaseType returns an error if a domain cannot be defined over
+// the given type.
+func validateDomainBaseType(base *types.T) error {
+	switch {
+	case base.UserDefined():
+		return unimplemented.Newf("domain over user-defined type",
+			"domains over user-defined types are not supported")
+	case base.Family() == types.ArrayFamily:
+		return unimplemented.Newf("domain over array type",
+			"domains over array types are not supported")
+	case base.Family() == types.TupleFamily || base.Family() == types.AnyFamily ||
+		base.Family() == types.UnknownFamily || base.Family() == types.VoidFamily ||
+		base.Family() == types.TriggerFamily:
+		return pgerror.Newf(pgcode.DatatypeMismatch,
+			"%q is not a valid base type for a domain", base.SQLString())
+	}
+	return colinfo.ValidateColumnDefType(base)
+}
+
+// makeDomainDefaultExpr type checks the default expression of a domain over
+// the given base type and returns its serialized form.
+func (p *planner) makeDomainDefaultExpr(
+	ctx context.Context, base *types.T, expr tree.Expr,
+) (*string, error) {
+	typedExpr, err := schemaexpr.SanitizeVarFreeExpr(
+		ctx, expr, base, "DEFAULT", &p.semaCtx, volatility.Volatile, true, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDomainExprReferences(typedExpr); err != nil {
+		return nil, err
+	}
+	if typedExpr == tree.DNull {
+		return nil, nil
+	}
+	s := tree.Serialize(typedExpr)
+	return &s, nil
+}
+
+// makeDomainCheckConstraint validates a CHECK constraint of a domain over the
+// given base type, and returns the descriptor representation of the
+// constraint. If the constraint is unnamed, it is given a name that does not
+// conflict with the existing constraints.
+func (p *planner) makeDomainCheckConstraint(
+	ctx context.Context,
+	domainName *tree.TypeName,
+	base *types.T,
+	existing []descpb.TypeDescriptor_DomainConstraint,
+	c *tree.DomainConstraint,
+) (descpb.TypeDescriptor_DomainConstraint, error) {
+	inUse := func(name string) bool {
+		for i := range existing {
+			if existing[i].Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	name := string(c.Name)
+	if name == "" {
+		name = domainName.Object() + "_check"
+		for i := 1; inUse(name); i++ {
+			name = fmt.Sprintf("%s_check%d", domainName.Object(), i)
+		}
+	} else if inUse(name) {
+		return descpb.TypeDescriptor_DomainConstraint{}, pgerror.Newf(pgcode.DuplicateObject,
+			"constraint %q for domain %s already exists", name, domainName.Object())
+	}
+
+	// Type check the expression with a NULL value of the base type in place of
+	// VALUE, which ensures that it does not reference anything but VALUE.
+	replaced, err := tree.ReplaceDomainValue(c.Check, tree.NewTypedCastExpr(tree.DNull, base))
+	if err != nil {
+		return descpb.TypeDescriptor_DomainConstraint{}, err
+	}
+	typedExpr, err := schemaexpr.SanitizeVarFreeExpr(
+		ctx, replaced, types.Bool, "CHECK", &p.semaCtx, volatility.Immutable, false, /* allowAssignmentCast */
+	)
+	if err != nil {
+		return descpb.TypeDescriptor_DomainConstraint{}, err
+	}
+	if err := checkDomainExprReferences(typedExpr); err != nil {
+		return descpb.TypeDescriptor_DomainConstraint{}, err
+	}
+	return descpb.TypeDescriptor_DomainConstraint{
+		Name:     name,
+		Expr:     tree.Serialize(c.Check),
+		Validity: descpb.ConstraintValidity_Validated,
+	}, nil
+}
+
+// checkDomainExprReferences returns an error if the default or CHECK
+// expression of a domain references user-defined types, user-defined functions
+// or sequences, which domains do not track dependencies on.
+func checkDomainExprReferences(expr tree.TypedExpr) error {
+	if err := tree.MaybeFailOnUDFUsage(expr); err != nil {
+		return err
+	}
+	seqs, err := seqexpr.GetUsedSequences(expr)
+	if err != nil {
+		return err
+	}
+	if len(seqs) > 0 {
+		return unimplemented.Newf("domain sequence reference",
+			"sequences cannot be referenced by domains")
+	}
+	var usesUDT bool
+	if _, err := tree.SimpleVisit(expr, func(e tree.Expr) (bool, tree.Expr, error) {
+		if te, ok := e.(tree.TypedExpr); ok && te.ResolvedType().UserDefined() {
+			usesUDT = true
+			return false, e, nil
+		}
+		return true, e, nil
+	}); err != nil {
+		return err
+	}
+	if usesUDT {
+		return unimplemented.Newf("domain type reference",
+			"user-defined types cannot be referenced by domains")
+	}
+	return nil
+}
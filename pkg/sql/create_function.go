@@ -26,11 +26,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
 )
 
 type createFunctionNode struct {
@@ -45,6 +48,26 @@ type createFunctionNode struct {
 func (n *createFunctionNode) ReadingOwnWrites() {}
 
 func (n *createFunctionNode) startExec(params runParams) error {
+	if n.cf.IsProcedure && !params.EvalContext().Settings.Version.IsActive(
+		params.ctx,
+		clusterversion.Procedures,
+	) {
+		return pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot run CREATE PROCEDURE before system is fully upgraded to v22.2",
+		)
+	}
+	for _, option := range n.cf.Options {
+		if option == tree.FunctionLangPLpgSQL && !params.EvalContext().Settings.Version.IsActive(
+			params.ctx,
+			clusterversion.PLpgSQLFunctions,
+		) {
+			return pgerror.Newf(
+				pgcode.FeatureNotSupported,
+				"cannot create a PL/pgSQL function before system is fully upgraded to v22.2",
+			)
+		}
+	}
 	if !params.EvalContext().Settings.Version.IsActive(
 		params.ctx,
 		clusterversion.SchemaChangeSupportsCreateFunction,
@@ -59,7 +82,6 @@ func (n *createFunctionNode) startExec(params runParams) error {
 	if n.cf.RoutineBody != nil {
 		return unimplemented.NewWithIssue(85144, "CREATE FUNCTION...sql_body unimplemented")
 	}
-
 	if err := params.p.canCreateOnSchema(
 		params.ctx, n.scDesc.GetID(), n.dbDesc.GetID(), params.p.User(), skipCheckPublicSchema,
 	); err != nil {
@@ -115,11 +137,8 @@ func (*createFunctionNode) Close(ctx context.Context)           {}
 func (n *createFunctionNode) createNewFunction(
 	udfDesc *funcdesc.Mutable, scDesc *schemadesc.Mutable, params runParams,
 ) error {
-	for _, option := range n.cf.Options {
-		err := setFuncOption(params, udfDesc, option)
-		if err != nil {
-			return err
-		}
+	if err := setFuncOptions(params, udfDesc, n.cf.Options); err != nil {
+		return err
 	}
 	if err := funcdesc.CheckLeakProofVolatility(udfDesc); err != nil {
 		return err
@@ -144,9 +163,13 @@ func (n *createFunctionNode) createNewFunction(
 	if err != nil {
 		return err
 	}
-	argTypes := make([]*types.T, len(udfDesc.Args))
-	for i, arg := range udfDesc.Args {
-		argTypes[i] = arg.Type
+	argTypes := make([]*types.T, 0, len(udfDesc.Args))
+	for _, arg := range udfDesc.Args {
+		// OUT arguments are not part of the signature of the function.
+		if arg.Class == catpb.Function_Arg_OUT {
+			continue
+		}
+		argTypes = append(argTypes, arg.Type)
 	}
 	scDesc.AddFunction(
 		udfDesc.GetName(),
@@ -168,6 +191,23 @@ func (n *createFunctionNode) replaceFunction(udfDesc *funcdesc.Mutable, params r
 	// TODO(chengxiong): add validation that the function is not referenced. This
 	// is needed when we start allowing function references from other objects.
 
+	// A function cannot be replaced by a procedure, nor vice versa.
+	if n.cf.IsProcedure != udfDesc.IsProcedure {
+		kind := "function"
+		if udfDesc.IsProcedure {
+			kind = "procedure"
+		}
+		return errors.WithDetailf(
+			pgerror.New(pgcode.WrongObjectType, "cannot change routine kind"),
+			"%q is a %s.", udfDesc.Name, kind,
+		)
+	}
+
+	// The OUT arguments determine the return type, so they cannot change.
+	if len(n.cf.Args) != len(udfDesc.Args) {
+		return pgerror.Newf(pgcode.InvalidFunctionDefinition, "cannot change return type of existing function")
+	}
+
 	// Make sure argument names are not changed.
 	for i := range n.cf.Args {
 		if string(n.cf.Args[i].Name) != udfDesc.Args[i].Name {
@@ -187,11 +227,8 @@ func (n *createFunctionNode) replaceFunction(udfDesc *funcdesc.Mutable, params r
 	}
 
 	resetFuncOption(udfDesc)
-	for _, option := range n.cf.Options {
-		err := setFuncOption(params, udfDesc, option)
-		if err != nil {
-			return err
-		}
+	if err := setFuncOptions(params, udfDesc, n.cf.Options); err != nil {
+		return err
 	}
 
 	if err := funcdesc.CheckLeakProofVolatility(udfDesc); err != nil {
@@ -311,6 +348,7 @@ func (n *createFunctionNode) getMutableFuncDesc(
 		n.cf.ReturnType.IsSet,
 		privileges,
 	)
+	newUdfDesc.IsProcedure = n.cf.IsProcedure
 
 	return &newUdfDesc, true, nil
 }
@@ -406,6 +444,29 @@ func (n *createFunctionNode) addUDFReferences(udfDesc *funcdesc.Mutable, params
 	return nil
 }
 
+// setFuncOptions sets the options of a function being created or replaced.
+// The language is set first because it determines how the body is processed.
+func setFuncOptions(
+	params runParams, udfDesc *funcdesc.Mutable, options tree.FunctionOptions,
+) error {
+	for _, option := range options {
+		if lang, ok := option.(tree.FunctionLanguage); ok {
+			if err := setFuncOption(params, udfDesc, lang); err != nil {
+				return err
+			}
+		}
+	}
+	for _, option := range options {
+		if _, ok := option.(tree.FunctionLanguage); ok {
+			continue
+		}
+		if err := setFuncOption(params, udfDesc, option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func setFuncOption(params runParams, udfDesc *funcdesc.Mutable, option tree.FunctionOption) error {
 	switch t := option.(type) {
 	case tree.FunctionVolatility:
@@ -429,6 +490,14 @@ func setFuncOption(params runParams, udfDesc *funcdesc.Mutable, option tree.Func
 		}
 		udfDesc.SetLang(v)
 	case tree.FunctionBodyStr:
+		if udfDesc.GetLanguage() == catpb.Function_PLPGSQL {
+			serializedFuncBody, err := serializePLpgSQLFuncBody(params.ctx, params.p, string(t))
+			if err != nil {
+				return err
+			}
+			udfDesc.SetFuncBody(serializedFuncBody)
+			break
+		}
 		// Replace any sequence names in the function body with IDs.
 		seqReplacedFuncBody, err := replaceSeqNamesWithIDs(params.ctx, params.p, string(t), true)
 		if err != nil {
@@ -446,6 +515,54 @@ func setFuncOption(params runParams, udfDesc *funcdesc.Mutable, option tree.Func
 	return nil
 }
 
+// serializePLpgSQLFuncBody replaces the sequence names and user-defined types
+// in the SQL embedded in a PL/pgSQL function body with IDs, like the body of a
+// SQL function. Casts and declarations of user-defined types refer to the type
+// by OID, since their expressions may refer to variables of the function.
+func serializePLpgSQLFuncBody(ctx context.Context, p *planner, funcBody string) (string, error) {
+	block, err := plpgsqlparser.Parse(funcBody)
+	if err != nil {
+		return "", err
+	}
+	if err := plpgsqltree.SimpleVisit(
+		block, makeSeqNameReplaceFunc(ctx, p), nil, /* typFn */
+	); err != nil {
+		return "", err
+	}
+
+	serializeType := func(typRef tree.ResolvableTypeReference) (tree.ResolvableTypeReference, error) {
+		typ, err := tree.ResolveType(ctx, typRef, p.semaCtx.TypeResolver)
+		if err != nil {
+			return nil, err
+		}
+		if !typ.UserDefined() {
+			return typRef, nil
+		}
+		return &tree.OIDTypeReference{OID: typ.Oid()}, nil
+	}
+	serializeTypeFunc := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		switch n := expr.(type) {
+		case *tree.CastExpr:
+			newCast := *n
+			if newCast.Type, err = serializeType(n.Type); err != nil {
+				return false, expr, err
+			}
+			return true, &newCast, nil
+		case *tree.AnnotateTypeExpr:
+			newAnnotate := *n
+			if newAnnotate.Type, err = serializeType(n.Type); err != nil {
+				return false, expr, err
+			}
+			return true, &newAnnotate, nil
+		}
+		return true, expr, nil
+	}
+	if err := plpgsqltree.SimpleVisit(block, serializeTypeFunc, serializeType); err != nil {
+		return "", err
+	}
+	return tree.AsStringWithFlags(block, tree.FmtSimple), nil
+}
+
 // resetFuncOption sets all function options to default values.
 func resetFuncOption(udfDesc *funcdesc.Mutable) {
 	udfDesc.SetVolatility(catpb.Function_VOLATILE)
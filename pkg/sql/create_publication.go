@@ -0,0 +1,116 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createPublicationNode struct {
+	n        *tree.CreatePublication
+	dbDesc   *dbdesc.Mutable
+	tableIDs []descpb.ID
+}
+
+// CreatePublication creates a publication in the current database.
+// Privileges: CREATE on database, ownership of the published tables, and
+// admin for FOR ALL TABLES publications.
+func (p *planner) CreatePublication(
+	ctx context.Context, n *tree.CreatePublication,
+) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE PUBLICATION",
+	); err != nil {
+		return nil, err
+	}
+	if err := checkPublicationsEnabled(ctx, p.ExecCfg(), "CREATE PUBLICATION"); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.getMutableDatabaseForPublication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	if dbDesc.FindPublicationByName(string(n.Name)) != nil {
+		return nil, pgerror.Newf(pgcode.DuplicateObject,
+			"publication %q already exists", n.Name)
+	}
+
+	if n.AllTables {
+		if err := p.RequireAdminRole(ctx, "create a publication FOR ALL TABLES"); err != nil {
+			return nil, err
+		}
+	}
+	tables, err := p.resolvePublicationTables(ctx, dbDesc, n.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createPublicationNode{n: n, dbDesc: dbDesc, tableIDs: publicationTableIDs(tables)}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE PUBLICATION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createPublicationNode) ReadingOwnWrites() {}
+
+func (n *createPublicationNode) startExec(params runParams) error {
+	pub := descpb.PublicationDescriptor{
+		Name:            string(n.n.Name),
+		OwnerProto:      params.p.User().EncodeProto(),
+		AllTables:       n.n.AllTables,
+		TableIDs:        n.tableIDs,
+		PublishInsert:   true,
+		PublishUpdate:   true,
+		PublishDelete:   true,
+		PublishTruncate: true,
+	}
+	if err := storageparam.Set(
+		params.ctx,
+		params.p.SemaCtx(),
+		params.EvalContext(),
+		n.n.Params,
+		&publicationParamSetter{pub: &pub},
+	); err != nil {
+		return err
+	}
+
+	n.dbDesc.AddPublication(pub)
+	if err := params.p.writeNonDropDatabaseChange(
+		params.ctx, n.dbDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		n.dbDesc.GetID(),
+		&eventpb.CreatePublication{
+			PublicationName: pub.Name,
+		})
+}
+
+func (*createPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (*createPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createPublicationNode) Close(context.Context)        {}
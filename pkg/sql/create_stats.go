@@ -230,6 +230,12 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 		)
 	}
 
+	if tableDesc.IsBackupTable() {
+		return nil, pgerror.New(
+			pgcode.WrongObjectType, "cannot create statistics on tables attached from a backup",
+		)
+	}
+
 	if tableDesc.GetID() == keys.TableStatisticsTableID {
 		return nil, pgerror.New(
 			pgcode.WrongObjectType, "cannot create statistics on system.table_statistics",
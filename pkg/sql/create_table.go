@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -663,6 +664,7 @@ func addUniqueWithoutIndexColumnTableDef(
 		string(d.Unique.ConstraintName),
 		[]string{string(d.Name)},
 		"", /* predicate */
+		tree.ConstraintNotDeferrable,
 		ts,
 		validationBehavior,
 	); err != nil {
@@ -725,14 +727,55 @@ func addUniqueWithoutIndexTableDef(
 	for i := range colNames {
 		colNames[i] = string(d.Columns[i].Column)
 	}
+	if err := checkDeferrableConstraintIsSupported(ctx, evalCtx.Settings, d.Deferrability); err != nil {
+		return err
+	}
 	if err := ResolveUniqueWithoutIndexConstraint(
-		ctx, desc, string(d.Name), colNames, predicate, ts, validationBehavior,
+		ctx, desc, string(d.Name), colNames, predicate, d.Deferrability, ts, validationBehavior,
 	); err != nil {
 		return err
 	}
 	return nil
 }
 
+// checkIndexedUniqueIsNotDeferrable returns an error if a unique constraint
+// that is enforced by an index is marked DEFERRABLE.
+//
+// A unique index enforces its constraint as the rows are written: the index
+// has a single key for each distinct value of its columns, so two rows with
+// the same value cannot both be written to it, even temporarily. Only UNIQUE
+// WITHOUT INDEX constraints are checked by queries planned by the optimizer,
+// which can run at commit time instead, so only they can be deferred. A
+// non-unique index on the same columns can be added to make their checks
+// efficient.
+func checkIndexedUniqueIsNotDeferrable(d *tree.UniqueConstraintTableDef) error {
+	if !d.Deferrability.IsDeferrable() {
+		return nil
+	}
+	return errors.WithHint(
+		unimplemented.NewWithIssue(31632, "deferrable unique constraints with an index are not supported"),
+		"use UNIQUE WITHOUT INDEX to create a deferrable unique constraint, "+
+			"and a non-unique INDEX on the same columns to check it efficiently",
+	)
+}
+
+// checkDeferrableConstraintIsSupported returns an error if a constraint is
+// marked DEFERRABLE before the cluster is upgraded to a version in which all
+// nodes defer its checks. Nodes running an older version check deferrable
+// constraints immediately, which would make the outcome of a transaction
+// depend on its gateway.
+func checkDeferrableConstraintIsSupported(
+	ctx context.Context, st *cluster.Settings, deferrability tree.ConstraintDeferrability,
+) error {
+	if !deferrability.IsDeferrable() ||
+		st.Version.IsActive(ctx, clusterversion.DeferrableConstraints) {
+		return nil
+	}
+	return pgerror.New(pgcode.FeatureNotSupported,
+		"cannot create a DEFERRABLE constraint before system is fully upgraded to v22.2",
+	)
+}
+
 // ResolveUniqueWithoutIndexConstraint looks up the columns mentioned in a
 // UNIQUE WITHOUT INDEX constraint and adds metadata representing that
 // constraint to the descriptor.
@@ -746,6 +789,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	constraintName string,
 	colNames []string,
 	predicate string,
+	deferrability tree.ConstraintDeferrability,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
 ) error {
@@ -799,12 +843,14 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:         constraintName,
-		TableID:      tbl.ID,
-		ColumnIDs:    columnIDs,
-		Predicate:    predicate,
-		Validity:     validity,
-		ConstraintID: tbl.NextConstraintID,
+		Name:              constraintName,
+		TableID:           tbl.ID,
+		ColumnIDs:         columnIDs,
+		Predicate:         predicate,
+		Validity:          validity,
+		ConstraintID:      tbl.NextConstraintID,
+		Deferrable:        deferrability.IsDeferrable(),
+		InitiallyDeferred: deferrability == tree.ConstraintDeferrableInitiallyDeferred,
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -854,6 +900,10 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *eval.Context,
 ) error {
+	if err := checkDeferrableConstraintIsSupported(ctx, evalCtx.Settings, d.Deferrability); err != nil {
+		return err
+	}
+
 	var originColSet catalog.TableColSet
 	originCols := make([]catalog.Column, len(d.FromCols))
 	for i, fromCol := range d.FromCols {
@@ -1060,6 +1110,8 @@ func ResolveFK(
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
 		ConstraintID:        tbl.NextConstraintID,
+		Deferrable:          d.Deferrability.IsDeferrable(),
+		InitiallyDeferred:   d.Deferrability == tree.ConstraintDeferrableInitiallyDeferred,
 	}
 	tbl.NextConstraintID++
 	if ts == NewTable {
@@ -1878,6 +1930,9 @@ func NewTableDesc(
 				// We will add the unique constraint below.
 				break
 			}
+			if err := checkIndexedUniqueIsNotDeferrable(d); err != nil {
+				return nil, err
+			}
 			// If the index is named, ensure that the name is unique. Unnamed
 			// indexes will be given a unique auto-generated name later on when
 			// AllocateIDs is called.
@@ -1987,6 +2042,19 @@ func NewTableDesc(
 					return nil, err
 				}
 			}
+		case *tree.ExclusionConstraintTableDef:
+			if d.Name != "" {
+				if idx, _ := desc.FindIndexWithName(d.Name.String()); idx != nil {
+					return nil, pgerror.Newf(pgcode.DuplicateRelation, "duplicate index name: %q", d.Name)
+				}
+			}
+			idx, err := makeExclusionIndexDescriptor(ctx, st, &desc, d, &n.Table, semaCtx)
+			if err != nil {
+				return nil, err
+			}
+			if err := desc.AddSecondaryIndex(idx); err != nil {
+				return nil, err
+			}
 		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef:
 			// pass, handled below.
 
@@ -2156,7 +2224,7 @@ func NewTableDesc(
 				}
 			}
 
-		case *tree.IndexTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
+		case *tree.IndexTableDef, *tree.ExclusionConstraintTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
 			// Pass, handled above.
 
 		case *tree.CheckConstraintTableDef:
@@ -0,0 +1,212 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+	funcDesc  *funcdesc.Mutable
+}
+
+// CreateTrigger creates a row-level trigger on a table.
+// Privileges: CREATE on table.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.RowLevelTriggers) {
+		return nil, pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot run CREATE TRIGGER before system is fully upgraded to v22.2",
+		)
+	}
+
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &n.Table, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc.IsTemporary() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot create trigger on temporary table %q", tableDesc.GetName())
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if _, err := tableDesc.FindTriggerWithName(string(n.Name)); err == nil {
+		return nil, pgerror.Newf(pgcode.DuplicateObject,
+			"trigger %q for relation %q already exists", n.Name, tableDesc.GetName())
+	}
+
+	ol, err := p.resolveTriggerFunction(ctx, &n.FuncName)
+	if err != nil {
+		return nil, err
+	}
+	fnID, err := funcdesc.UserDefinedFunctionOIDToID(ol.Oid)
+	if err != nil {
+		return nil, err
+	}
+	funcDesc, err := p.Descriptors().GetMutableFunctionByID(
+		ctx, p.Txn(), fnID, tree.ObjectLookupFlagsWithRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if funcDesc.GetParentID() != tableDesc.GetParentID() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"the trigger function cannot belong to another database")
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc, funcDesc: funcDesc}, nil
+}
+
+// resolveTriggerFunction resolves the function executed by a trigger. As in
+// Postgres, a trigger function takes no arguments and returns trigger; it
+// accesses the rows through the NEW and OLD variables.
+func (p *planner) resolveTriggerFunction(
+	ctx context.Context, fn *tree.FunctionName,
+) (*tree.QualifiedOverload, error) {
+	path := p.CurrentSearchPath()
+	fnDef, err := p.ResolveFunction(ctx, fn.ToUnresolvedObjectName().ToUnresolvedName(), &path)
+	if err != nil {
+		return nil, err
+	}
+	ol, err := fnDef.MatchOverload([]*types.T{}, fn.Schema(), &path)
+	if err != nil {
+		return nil, err
+	}
+	if !ol.IsUDF {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s is not a user-defined function", fnDef.Name)
+	}
+	if ol.ReturnType(nil /* args */).Family() != types.TriggerFamily {
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type trigger", fnDef.Name)
+	}
+	return &ol, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE TRIGGER performs multiple KV operations on descriptors
+// and expects to see its own writes.
+func (n *createTriggerNode) ReadingOwnWrites() {}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	trig := descpb.TriggerDescriptor{
+		Name:   string(n.n.Name),
+		FuncID: n.funcDesc.GetID(),
+	}
+	if n.n.ActionTime == tree.TriggerActionTimeAfter {
+		trig.ActionTime = descpb.TriggerDescriptor_AFTER
+	}
+	for _, ev := range n.n.Events {
+		switch ev {
+		case tree.TriggerEventInsert:
+			trig.OnInsert = true
+		case tree.TriggerEventUpdate:
+			trig.OnUpdate = true
+		case tree.TriggerEventDelete:
+			trig.OnDelete = true
+		}
+	}
+	trigID := n.tableDesc.AddTrigger(trig)
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	n.funcDesc.AddTriggerReference(n.tableDesc.GetID(), trigID)
+	if err := params.p.writeFuncSchemaChange(params.ctx, n.funcDesc); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		n.tableDesc.GetID(),
+		&eventpb.CreateTrigger{
+			TableName:   n.n.Table.FQString(),
+			TriggerName: string(n.n.Name),
+		})
+}
+
+func (*createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTriggerNode) Close(context.Context)        {}
+
+// removeTriggerFuncReference removes the back reference from the function
+// executed by the given trigger.
+func (p *planner) removeTriggerFuncReference(
+	ctx context.Context, tableDesc catalog.TableDescriptor, trig *descpb.TriggerDescriptor,
+) error {
+	fnDesc, err := p.Descriptors().GetMutableFunctionByID(
+		ctx, p.txn, trig.FuncID, tree.ObjectLookupFlagsWithRequired(),
+	)
+	if err != nil {
+		return err
+	}
+	fnDesc.RemoveTriggerReference(tableDesc.GetID(), trig.ID)
+	return p.writeFuncSchemaChange(ctx, fnDesc)
+}
+
+// triggerDependentsError returns an error describing the triggers that
+// prevent the given function from being dropped.
+func (p *planner) triggerDependentsError(
+	ctx context.Context, fnDesc catalog.FunctionDescriptor,
+) error {
+	for _, ref := range fnDesc.GetDependedOnBy() {
+		if len(ref.TriggerIDs) == 0 {
+			continue
+		}
+		tableDesc, err := p.Descriptors().GetImmutableTableByID(
+			ctx, p.txn, ref.ID, tree.ObjectLookupFlagsWithRequired(),
+		)
+		if err != nil {
+			return err
+		}
+		trig, err := tableDesc.FindTriggerWithID(ref.TriggerIDs[0])
+		if err != nil {
+			return err
+		}
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"cannot drop function %q because trigger %q on table %q depends on it",
+				fnDesc.GetName(), trig.Name, tableDesc.GetName()),
+			fmt.Sprintf("drop the trigger first with DROP TRIGGER %s ON %s",
+				tree.NameString(trig.Name), tree.NameString(tableDesc.GetName())),
+		)
+	}
+	return nil
+}
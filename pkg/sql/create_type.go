@@ -107,6 +107,8 @@ func (n *createTypeNode) startExec(params runParams) error {
 	switch n.n.Variety {
 	case tree.Enum:
 		return params.p.createUserDefinedEnum(params, n)
+	case tree.Domain:
+		return params.p.createUserDefinedDomain(params, n)
 	default:
 		return unimplemented.NewWithIssue(25123, "CREATE TYPE")
 	}
@@ -223,6 +225,10 @@ func CreateEnumArrayTypeDesc(
 	switch t := typDesc.Kind; t {
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		elemTyp = types.MakeEnum(catid.TypeIDToOID(typDesc.GetID()), catid.TypeIDToOID(id))
+	case descpb.TypeDescriptor_DOMAIN:
+		elemTyp = types.MakeDomain(
+			catid.TypeIDToOID(typDesc.GetID()), catid.TypeIDToOID(id), typDesc.DomainBaseType,
+		)
 	default:
 		return nil, errors.AssertionFailedf("cannot make array type for kind %s", t.String())
 	}
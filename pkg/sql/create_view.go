@@ -444,14 +444,10 @@ func makeViewTableDesc(
 	return desc, nil
 }
 
-// replaceSeqNamesWithIDs prepares to walk the given viewQuery by defining the
-// function used to replace sequence names with IDs, and parsing the
-// viewQuery into a statement.
-// TODO (Chengxiong): move this to a better place.
-func replaceSeqNamesWithIDs(
-	ctx context.Context, sc resolver.SchemaResolver, queryStr string, multiStmt bool,
-) (string, error) {
-	replaceSeqFunc := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+// makeSeqNameReplaceFunc returns a tree.SimpleVisitFn that replaces the names
+// of the sequences used by an expression with their IDs.
+func makeSeqNameReplaceFunc(ctx context.Context, sc resolver.SchemaResolver) tree.SimpleVisitFn {
+	return func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
 		seqIdentifiers, err := seqexpr.GetUsedSequences(expr)
 		if err != nil {
 			return false, expr, err
@@ -470,6 +466,16 @@ func replaceSeqNamesWithIDs(
 		}
 		return false, newExpr, nil
 	}
+}
+
+// replaceSeqNamesWithIDs prepares to walk the given viewQuery by defining the
+// function used to replace sequence names with IDs, and parsing the
+// viewQuery into a statement.
+// TODO (Chengxiong): move this to a better place.
+func replaceSeqNamesWithIDs(
+	ctx context.Context, sc resolver.SchemaResolver, queryStr string, multiStmt bool,
+) (string, error) {
+	replaceSeqFunc := makeSeqNameReplaceFunc(ctx, sc)
 
 	var stmts tree.Statements
 	if multiStmt {
@@ -506,13 +512,13 @@ func replaceSeqNamesWithIDs(
 	return fmtCtx.String(), nil
 }
 
-// serializeUserDefinedTypes will walk the given view query
-// and serialize any user defined types, so that renaming the type
-// does not corrupt the view.
-func serializeUserDefinedTypes(
-	ctx context.Context, semaCtx *tree.SemaContext, queries string, multiStmt bool,
-) (string, error) {
-	replaceFunc := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+// makeUserDefinedTypeSerializeFunc returns a tree.SimpleVisitFn that
+// serializes the casts and type annotations to user-defined types of an
+// expression, so that they refer to the types by ID.
+func makeUserDefinedTypeSerializeFunc(
+	ctx context.Context, semaCtx *tree.SemaContext,
+) tree.SimpleVisitFn {
+	return func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
 		var innerExpr tree.Expr
 		var typRef tree.ResolvableTypeReference
 		switch n := expr.(type) {
@@ -550,6 +556,15 @@ func serializeUserDefinedTypes(
 		}
 		return false, parsedExpr, nil
 	}
+}
+
+// serializeUserDefinedTypes will walk the given view query
+// and serialize any user defined types, so that renaming the type
+// does not corrupt the view.
+func serializeUserDefinedTypes(
+	ctx context.Context, semaCtx *tree.SemaContext, queries string, multiStmt bool,
+) (string, error) {
+	replaceFunc := makeUserDefinedTypeSerializeFunc(ctx, semaCtx)
 
 	var stmts tree.Statements
 	if multiStmt {
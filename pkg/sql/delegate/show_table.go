@@ -233,6 +233,7 @@ func (d *delegator) delegateShowConstraints(n *tree.ShowConstraints) (tree.State
            WHEN 'u' THEN 'UNIQUE'
            WHEN 'c' THEN 'CHECK'
            WHEN 'f' THEN 'FOREIGN KEY'
+           WHEN 'x' THEN 'EXCLUDE'
            ELSE c.contype::TEXT
         END AS constraint_type,
         c.condef AS details,
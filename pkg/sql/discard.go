@@ -69,6 +69,11 @@ func (n *discardNode) startExec(params runParams) error {
 			return err
 		}
 
+		// UNLISTEN *
+		if notifications := params.extendedEvalCtx.Notifications; notifications != nil {
+			notifications.queueUnlistenAll()
+		}
+
 	case tree.DiscardModeSequences:
 		params.p.sessionDataMutatorIterator.applyOnEachMutator(func(m sessionDataMutator) {
 			m.data.SequenceState = sessiondata.NewSequenceState()
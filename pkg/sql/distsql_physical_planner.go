@@ -455,6 +455,11 @@ func mustWrapValuesNode(planCtx *PlanningCtx, specifiedInQuery bool) bool {
 func checkSupportForPlanNode(node planNode) (distRecommendation, error) {
 	switch n := node.(type) {
 	// Keep these cases alphabetized, please!
+	case *backupScanNode:
+		// The rows of a table attached from a backup are read from the files of
+		// the backup on the gateway.
+		return cannotDistribute, nil
+
 	case *createStatsNode:
 		if n.runAsJob {
 			return cannotDistribute, planNodeNotSupportedErr
@@ -178,6 +178,9 @@ func (e *distSQLSpecExecFactory) ConstructScan(
 			},
 		)
 	}
+	if table.IsBackupTable() {
+		return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: backup scan")
+	}
 
 	// Although we don't yet recommend distributing plans where soft limits
 	// propagate to scan nodes because we don't have infrastructure to only
@@ -982,6 +985,7 @@ func (e *distSQLSpecExecFactory) ConstructUpsert(
 	arbiterIndexes cat.IndexOrdinals,
 	arbiterConstraints cat.UniqueOrdinals,
 	canaryCol exec.NodeColumnOrdinal,
+	deleteCol exec.NodeColumnOrdinal,
 	insertCols exec.TableColumnOrdinalSet,
 	fetchCols exec.TableColumnOrdinalSet,
 	updateCols exec.TableColumnOrdinalSet,
@@ -13,11 +13,14 @@ package sql
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -38,7 +41,7 @@ func (p *planner) DropFunction(
 	if err := checkSchemaChangeEnabled(
 		ctx,
 		p.ExecCfg(),
-		"DROP FUNCTION",
+		n.StatementTag(),
 	); err != nil {
 		return nil, err
 	}
@@ -72,15 +75,34 @@ func (p *planner) DropFunction(
 		if err != nil {
 			return nil, err
 		}
+		// DROP FUNCTION cannot drop a procedure, nor vice versa.
+		if mut.IsProcedure != n.IsProcedure {
+			kind := "function"
+			if n.IsProcedure {
+				kind = "procedure"
+			}
+			argTypes := ol.Types.Types()
+			argTypeNames := make([]string, len(argTypes))
+			for i, typ := range argTypes {
+				argTypeNames[i] = typ.SQLString()
+			}
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "%s(%s) is not a %s",
+				fn.FuncName.Object(), strings.Join(argTypeNames, ", "), kind,
+			)
+		}
 		dropNode.toDrop = append(dropNode.toDrop, mut)
 	}
 
 	if len(dropNode.toDrop) == 0 {
 		return newZeroNode(nil), nil
 	}
-	// TODO(chengxiong): check if there is any backreference which requires
-	// CASCADE drop behavior. This is needed when we start allowing UDF
-	// references from other objects.
+	// Triggers are the only objects which can reference a function. Since
+	// CASCADE is not supported, a function used by a trigger cannot be dropped.
+	for _, fnMutable := range dropNode.toDrop {
+		if err := p.triggerDependentsError(ctx, fnMutable); err != nil {
+			return nil, err
+		}
+	}
 	return dropNode, nil
 }
 
@@ -347,6 +347,13 @@ func (p *planner) dropIndexByName(
 			"use CASCADE if you really want to drop it.",
 		)
 	}
+	if idx.IsExclusion() && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"index %q is in use as exclusion constraint", idx.GetName()),
+			"use CASCADE if you really want to drop it.",
+		)
+	}
 
 	// Check if requires CCL binary for eventual zone config removal.
 	_, zone, _, err := GetZoneConfigInTxn(
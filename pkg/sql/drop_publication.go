@@ -0,0 +1,103 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type dropPublicationNode struct {
+	n      *tree.DropPublication
+	dbDesc *dbdesc.Mutable
+	pubs   []descpb.PublicationDescriptor
+}
+
+// DropPublication drops publications from the current database.
+// Privileges: ownership of the publications.
+func (p *planner) DropPublication(ctx context.Context, n *tree.DropPublication) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP PUBLICATION",
+	); err != nil {
+		return nil, err
+	}
+	if err := checkPublicationsEnabled(ctx, p.ExecCfg(), "DROP PUBLICATION"); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.getMutableDatabaseForPublication(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pubs []descpb.PublicationDescriptor
+	seen := make(map[descpb.PublicationID]struct{}, len(n.Names))
+	for _, name := range n.Names {
+		pub := dbDesc.FindPublicationByName(string(name))
+		if pub == nil {
+			if n.IfExists {
+				continue
+			}
+			return nil, pgerror.Newf(pgcode.UndefinedObject,
+				"publication %q does not exist", name)
+		}
+		if _, ok := seen[pub.ID]; ok {
+			continue
+		}
+		seen[pub.ID] = struct{}{}
+		if err := p.checkPublicationOwnership(ctx, pub); err != nil {
+			return nil, err
+		}
+		pubs = append(pubs, *pub)
+	}
+	if len(pubs) == 0 {
+		return newZeroNode(nil /* columns */), nil
+	}
+	return &dropPublicationNode{n: n, dbDesc: dbDesc, pubs: pubs}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP PUBLICATION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *dropPublicationNode) ReadingOwnWrites() {}
+
+func (n *dropPublicationNode) startExec(params runParams) error {
+	for i := range n.pubs {
+		n.dbDesc.RemovePublication(n.pubs[i].ID)
+	}
+	if err := params.p.writeNonDropDatabaseChange(
+		params.ctx, n.dbDesc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	for i := range n.pubs {
+		if err := params.p.logEvent(params.ctx,
+			n.dbDesc.GetID(),
+			&eventpb.DropPublication{
+				PublicationName: n.pubs[i].Name,
+			}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropPublicationNode) Close(context.Context)        {}
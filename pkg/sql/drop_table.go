@@ -254,6 +254,15 @@ func (p *planner) dropTableImpl(
 	}
 	tableDesc.InboundFKs = nil
 
+	// Remove trigger back references from the functions executed by the
+	// triggers of this table.
+	for i := range tableDesc.Triggers {
+		if err := p.removeTriggerFuncReference(ctx, tableDesc, &tableDesc.Triggers[i]); err != nil {
+			return droppedViews, err
+		}
+	}
+	tableDesc.Triggers = nil
+
 	// Remove sequence dependencies.
 	for _, col := range tableDesc.PublicColumns() {
 		if err := p.removeSequenceDependencies(ctx, tableDesc, col); err != nil {
@@ -66,6 +66,15 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if _, ok := node.toDrop[typeDesc.ID]; ok {
 			continue
 		}
+		if isDomain := typeDesc.Kind == descpb.TypeDescriptor_DOMAIN; isDomain != n.IsDomain {
+			if n.IsDomain {
+				return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", name)
+			}
+			return nil, errors.WithHint(
+				pgerror.Newf(pgcode.WrongObjectType, "%q is a domain", name),
+				"Use DROP DOMAIN to remove a domain.",
+			)
+		}
 		switch typeDesc.Kind {
 		case descpb.TypeDescriptor_ALIAS:
 			// The implicit array types are not directly droppable.
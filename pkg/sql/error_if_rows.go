@@ -18,12 +18,13 @@ import (
 )
 
 // errorIfRowsNode wraps another planNode and returns an error if the wrapped
-// node produces any rows.
+// node produces any rows for which mkErr returns an error.
 type errorIfRowsNode struct {
 	plan planNode
 
-	// mkErr creates the error message, given the values of the first row
-	// produced.
+	// mkErr creates the error message, given the values of a row produced. It
+	// returns nil if the row should not result in an error, in which case the
+	// next row is checked.
 	mkErr exec.MkErrFn
 
 	nexted bool
@@ -39,14 +40,15 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	}
 	n.nexted = true
 
-	ok, err := n.plan.Next(params)
-	if err != nil {
-		return false, err
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return false, err
+		}
+		if err := n.mkErr(n.plan.Values()); err != nil {
+			return false, err
+		}
 	}
-	if ok {
-		return false, n.mkErr(n.plan.Values())
-	}
-	return false, nil
 }
 
 func (n *errorIfRowsNode) Values() tree.Datums {
@@ -0,0 +1,207 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// exclusionRangeBoundFamilies are the type families of the bounds of the
+// ranges constructed by each function which may be used in exclusion
+// constraints.
+var exclusionRangeBoundFamilies = map[string][]types.Family{
+	"int4range": {types.IntFamily},
+	"int8range": {types.IntFamily},
+	"numrange":  {types.DecimalFamily, types.IntFamily},
+	"tsrange":   {types.TimestampFamily},
+	"tstzrange": {types.TimestampTZFamily},
+	"daterange": {types.DateFamily},
+}
+
+// makeExclusionIndexDescriptor creates the descriptor of the index that backs
+// the given exclusion constraint. The columns compared with = are the leading
+// key columns of the index, in the order in which they appear in the
+// constraint. If the element compared with && is an ARRAY or a GEOMETRY
+// column, the index is an inverted index on that column. If it is a range of
+// two columns, such as tstzrange(start_at, end_at), the index is a forward
+// index whose last two key columns are the bounds of the range.
+// Unnamed constraints are given a name when the index IDs are allocated.
+func makeExclusionIndexDescriptor(
+	ctx context.Context,
+	st *cluster.Settings,
+	desc *tabledesc.Mutable,
+	d *tree.ExclusionConstraintTableDef,
+	tn *tree.TableName,
+	semaCtx *tree.SemaContext,
+) (descpb.IndexDescriptor, error) {
+	if !st.Version.IsActive(ctx, clusterversion.ExclusionConstraints) {
+		return descpb.IndexDescriptor{}, pgerror.New(
+			pgcode.FeatureNotSupported,
+			"cannot create an exclusion constraint before system is fully upgraded to v22.2",
+		)
+	}
+	if desc.IsPartitionAllBy() {
+		return descpb.IndexDescriptor{}, pgerror.New(
+			pgcode.FeatureNotSupported,
+			"cannot define an exclusion constraint on a table that is implicitly partitioned with PARTITION ALL BY or LOCALITY REGIONAL BY ROW definition",
+		)
+	}
+
+	var columns tree.IndexElemList
+	var overlapCols tree.IndexElemList
+	var rangeFunc string
+	seen := make(map[tree.Name]struct{}, len(d.Elems)+1)
+	findColumn := func(name tree.Name) (catalog.Column, error) {
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q appears more than once in exclusion constraint", name)
+		}
+		seen[name] = struct{}{}
+		return desc.FindColumnWithName(name)
+	}
+	for _, elem := range d.Elems {
+		if elem.RangeFunc != "" {
+			if elem.Operator.Symbol != treecmp.Overlaps {
+				return descpb.IndexDescriptor{}, unimplemented.NewWithIssuef(46657,
+					"operator %s is not supported on ranges in exclusion constraints", elem.Operator)
+			}
+			if overlapCols != nil {
+				return descpb.IndexDescriptor{}, unimplemented.NewWithIssue(46657,
+					"exclusion constraints with more than one && operator are not supported")
+			}
+			lower, err := findColumn(elem.Column)
+			if err != nil {
+				return descpb.IndexDescriptor{}, err
+			}
+			upper, err := findColumn(elem.RangeUpper)
+			if err != nil {
+				return descpb.IndexDescriptor{}, err
+			}
+			if !isExclusionRangeBoundType(elem.RangeFunc, lower.GetType(), upper.GetType()) {
+				return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.UndefinedFunction,
+					"function %s(%s, %s) does not exist",
+					elem.RangeFunc, lower.GetType().SQLString(), upper.GetType().SQLString())
+			}
+			rangeFunc = elem.RangeFunc
+			overlapCols = tree.IndexElemList{
+				{Column: elem.Column, Direction: tree.Ascending},
+				{Column: elem.RangeUpper, Direction: tree.Ascending},
+			}
+			continue
+		}
+		col, err := findColumn(elem.Column)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		switch elem.Operator.Symbol {
+		case treecmp.EQ:
+			columns = append(columns, tree.IndexElem{Column: elem.Column, Direction: tree.Ascending})
+		case treecmp.Overlaps:
+			if overlapCols != nil {
+				return descpb.IndexDescriptor{}, unimplemented.NewWithIssue(46657,
+					"exclusion constraints with more than one && operator are not supported")
+			}
+			switch col.GetType().Family() {
+			case types.ArrayFamily, types.GeometryFamily:
+			case types.DateFamily, types.TimestampFamily, types.TimestampTZFamily:
+				// These are the bounds of ranges, which are compared with && by
+				// constructing the range from both bounds.
+				return descpb.IndexDescriptor{}, errors.WithHint(
+					pgerror.Newf(pgcode.UndefinedObject,
+						"operator && is not supported in exclusion constraints for column %q of type %s",
+						col.GetName(), col.GetType().SQLString()),
+					"compare the range between two columns instead, "+
+						"for example tstzrange(start_at, end_at) WITH &&",
+				)
+			default:
+				return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.UndefinedObject,
+					"operator && is not supported in exclusion constraints for column %q of type %s",
+					col.GetName(), col.GetType().SQLString())
+			}
+			overlapCols = tree.IndexElemList{{Column: elem.Column, Direction: tree.Ascending}}
+		default:
+			return descpb.IndexDescriptor{}, unimplemented.NewWithIssuef(46657,
+				"operator %s is not supported in exclusion constraints", elem.Operator)
+		}
+	}
+	if overlapCols == nil {
+		return descpb.IndexDescriptor{}, unimplemented.NewWithIssue(46657,
+			"exclusion constraints without a && operator are not supported")
+	}
+	columns = append(columns, overlapCols...)
+
+	if err := validateColumnsAreAccessible(desc, columns); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+	if err := checkIndexColumns(desc, columns, nil /* storing */, rangeFunc == "" /* inverted */); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+
+	idx := descpb.IndexDescriptor{
+		Name:               string(d.Name),
+		Type:               descpb.IndexDescriptor_INVERTED,
+		Version:            descpb.StrictIndexColumnIDGuaranteesVersion,
+		Exclusion:          true,
+		ExclusionRangeFunc: rangeFunc,
+	}
+	if rangeFunc != "" {
+		idx.Type = descpb.IndexDescriptor_FORWARD
+	}
+	if err := idx.FillColumns(columns); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+	if rangeFunc == "" {
+		invCol := overlapCols[0]
+		column, err := desc.FindColumnWithName(invCol.Column)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		if err := populateInvertedIndexDescriptor(ctx, st, column, &idx, invCol); err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+	}
+	if d.Predicate != nil {
+		expr, err := schemaexpr.ValidatePartialIndexPredicate(ctx, desc, d.Predicate, tn, semaCtx)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		idx.Predicate = expr
+	}
+	return idx, nil
+}
+
+// isExclusionRangeBoundType returns true if the given range function constructs
+// a range from a lower and an upper bound of the given types.
+func isExclusionRangeBoundType(rangeFunc string, lower, upper *types.T) bool {
+	if lower.Family() != upper.Family() {
+		return false
+	}
+	for _, f := range exclusionRangeBoundFamilies[rangeFunc] {
+		if lower.Family() == f {
+			return true
+		}
+	}
+	return false
+}
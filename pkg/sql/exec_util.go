@@ -69,6 +69,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
 	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/listennotify"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -844,6 +845,12 @@ var (
 		Measurement: "Contention",
 		Unit:        metric.Unit_COUNT,
 	}
+	MetaReadCommittedStmtRetry = metric.Metadata{
+		Name:        "sql.txn.read_committed.stmt_retry.count",
+		Help:        "Number of statements of READ COMMITTED transactions which were retried after a write-write conflict",
+		Measurement: "SQL Statements",
+		Unit:        metric.Unit_COUNT,
+	}
 	MetaSelectStarted = metric.Metadata{
 		Name:        "sql.select.started.count",
 		Help:        "Number of SQL SELECT statements started",
@@ -1332,6 +1339,10 @@ type ExecutorConfig struct {
 
 	// EventsExporter is the client for the Observability Service.
 	EventsExporter obs.EventsExporter
+
+	// NotificationRegistry delivers the notifications sent with NOTIFY to the
+	// sessions which are listening for them with LISTEN.
+	NotificationRegistry *listennotify.Registry
 }
 
 // UpdateVersionSystemSettingHook provides a callback that allows us
@@ -1805,6 +1816,8 @@ func checkResultType(typ *types.T) error {
 	case types.TimestampTZFamily:
 	case types.IntervalFamily:
 	case types.JsonFamily:
+	case types.TSQueryFamily:
+	case types.TSVectorFamily:
 	case types.UuidFamily:
 	case types.INetFamily:
 	case types.OidFamily:
@@ -2002,6 +2015,10 @@ type SessionArgs struct {
 	// JWTAuthEnabled indicates if the customer is passing a JWT token in the
 	// password field.
 	JWTAuthEnabled bool
+	// Replication is set for logical replication connections, which were
+	// opened with the replication=database startup parameter and accept
+	// replication commands in addition to SQL.
+	Replication bool
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -2961,6 +2978,10 @@ func (m *sessionDataMutator) SetDefaultTransactionPriority(val tree.UserPriority
 	m.data.DefaultTxnPriority = int64(val)
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int64(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
@@ -3123,6 +3144,10 @@ func (m *sessionDataMutator) SetTransactionTimeout(timeout time.Duration) {
 	m.data.TransactionTimeout = timeout
 }
 
+func (m *sessionDataMutator) SetDefaultTextSearchConfig(name string) {
+	m.data.DefaultTextSearchConfig = name
+}
+
 func (m *sessionDataMutator) SetAllowPrepareAsOptPlan(val bool) {
 	m.data.AllowPrepareAsOptPlan = val
 }
@@ -499,6 +499,12 @@ func (c *RestoreDataSpec) summary() (string, []string) {
 	return "RestoreDataSpec", []string{}
 }
 
+// summary implements the diagramCellType interface.
+func (c *VerifyBackupDataSpec) summary() (string, []string) {
+	detail := fmt.Sprintf("%d files", len(c.Files))
+	return "VerifyBackupData", []string{detail}
+}
+
 // summary implements the diagramCellType interface.
 func (c *SplitAndScatterSpec) summary() (string, []string) {
 	detail := fmt.Sprintf("%d chunks", len(c.Chunks))
@@ -45,6 +45,10 @@ type EngineMetrics struct {
 	SQLActiveStatements   *metric.Gauge
 	SQLContendedTxns      *metric.Counter
 
+	// ReadCommittedStmtRetryCount counts the statements of READ COMMITTED
+	// transactions which were retried by the connExecutor.
+	ReadCommittedStmtRetryCount *metric.Counter
+
 	// TxnAbortCount counts transactions that were aborted, either due
 	// to non-retriable errors, or retriable errors when the client-side
 	// retry protocol is not in use.
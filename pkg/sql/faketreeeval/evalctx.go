@@ -407,6 +407,13 @@ func (ep *DummyEvalPlanner) EvalRoutineExpr(
 	return nil, errors.WithStack(errEvalPlanner)
 }
 
+// RoutineExprGenerator is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) RoutineExprGenerator(
+	ctx context.Context, expr *tree.RoutineExpr, input tree.Datums,
+) (eval.ValueGenerator, error) {
+	return nil, errors.WithStack(errEvalPlanner)
+}
+
 // ResolveTypeByOID implements the tree.TypeReferenceResolver interface.
 func (ep *DummyEvalPlanner) ResolveTypeByOID(_ context.Context, _ oid.Oid) (*types.T, error) {
 	return nil, errors.WithStack(errEvalPlanner)
@@ -467,6 +474,31 @@ func (ep *DummyEvalPlanner) GetMultiregionConfig(
 	return nil /* regionConfig */, false
 }
 
+// SendNotification is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) SendNotification(ctx context.Context, channel, payload string) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
+// HasPendingNotifications is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) HasPendingNotifications() bool {
+	return false
+}
+
+// ListeningChannels is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) ListeningChannels() []string {
+	return nil
+}
+
+// IsConstraintCheckDeferred is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) IsConstraintCheckDeferred(name string, initiallyDeferred bool) bool {
+	return false
+}
+
+// DeferConstraintViolation is part of the eval.Planner interface.
+func (ep *DummyEvalPlanner) DeferConstraintViolation(v eval.DeferredConstraintViolation) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
 // DummyPrivilegedAccessor implements the tree.PrivilegedAccessor interface by returning errors.
 type DummyPrivilegedAccessor struct{}
 
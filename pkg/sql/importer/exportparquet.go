@@ -131,7 +131,7 @@ func newParquetExporter(sp execinfrapb.ExportSpec, typs []*types.T) (*parquetExp
 	if err != nil {
 		return nil, err
 	}
-	schema := newParquetSchema(parquetColumns)
+	schema := NewParquetSchema(parquetColumns)
 
 	exporter = &parquetExporter{
 		buf:            buf,
@@ -159,6 +159,15 @@ type ParquetColumn struct {
 	DecodeFn func(interface{}) (tree.Datum, error)
 }
 
+// Encode converts a crdb table column value to the native go type that the
+// parquet vendor can ingest. The datum must not be NULL.
+func (c ParquetColumn) Encode(datum tree.Datum) (interface{}, error) {
+	// If we're encoding a DOidWrapper, then we want to cast the wrapped datum.
+	// Note that we pass in nil as the first argument since we're not interested
+	// in evaluating the evalCtx's placeholders.
+	return c.encodeFn(eval.UnwrapDatum(nil, datum))
+}
+
 // newParquetColumns creates a list of parquet columns, given the input relation's column types.
 func newParquetColumns(typs []*types.T, sp execinfrapb.ExportSpec) ([]ParquetColumn, error) {
 	parquetColumns := make([]ParquetColumn, len(typs))
@@ -417,6 +426,22 @@ func NewParquetColumn(typ *types.T, name string, nullable bool) (ParquetColumn,
 			}
 			return tree.NewDBox2D(b), nil
 		}
+	case types.TSQueryFamily:
+		populateLogicalStringCol(schemaEl)
+		col.encodeFn = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DTSQuery).TSQuery.String()), nil
+		}
+		col.DecodeFn = func(x interface{}) (tree.Datum, error) {
+			return tree.ParseDTSQuery(string(x.([]byte)))
+		}
+	case types.TSVectorFamily:
+		populateLogicalStringCol(schemaEl)
+		col.encodeFn = func(d tree.Datum) (interface{}, error) {
+			return []byte(d.(*tree.DTSVector).TSVector.String()), nil
+		}
+		col.DecodeFn = func(x interface{}) (tree.Datum, error) {
+			return tree.ParseDTSVector(string(x.([]byte)))
+		}
 	case types.GeographyFamily:
 		schemaEl.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
 		col.encodeFn = func(d tree.Datum) (interface{}, error) {
@@ -657,7 +682,7 @@ func NewParquetColumn(typ *types.T, name string, nullable bool) (ParquetColumn,
 	return col, nil
 }
 
-// newParquetSchema creates the schema for the parquet file,
+// NewParquetSchema creates the schema for the parquet file,
 // see example schema:
 //
 //	https://github.com/fraugster/parquet-go/issues/18#issuecomment-946013210
@@ -665,7 +690,7 @@ func NewParquetColumn(typ *types.T, name string, nullable bool) (ParquetColumn,
 // see docs here:
 //
 //	https://pkg.go.dev/github.com/fraugster/parquet-go/parquetschema#SchemaDefinition
-func newParquetSchema(parquetFields []ParquetColumn) *parquetschema.SchemaDefinition {
+func NewParquetSchema(parquetFields []ParquetColumn) *parquetschema.SchemaDefinition {
 	schemaDefinition := new(parquetschema.SchemaDefinition)
 	schemaDefinition.RootColumn = new(parquetschema.ColumnDefinition)
 	schemaDefinition.RootColumn.SchemaElement = parquet.NewSchemaElement()
@@ -776,10 +801,7 @@ func (sp *parquetWriterProcessor) Run(ctx context.Context) {
 							return err
 						}
 
-						// If we're encoding a DOidWrapper, then we want to cast the wrapped datum.
-						// Note that we pass in nil as the first argument since we're not interested
-						// in evaluating the evalCtx's placeholders.
-						edNative, err := exporter.parquetColumns[i].encodeFn(eval.UnwrapDatum(nil, ed.Datum))
+						edNative, err := exporter.parquetColumns[i].Encode(ed.Datum)
 						if err != nil {
 							return err
 						}
@@ -1288,16 +1288,20 @@ https://www.postgresql.org/docs/9.5/infoschema-table-constraints.html`,
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					// Like in Postgres, exclusion constraints are not included.
+					if c.Kind == descpb.ConstraintTypeExclusion {
+						continue
+					}
 					if err := addRow(
-						dbNameStr,                       // constraint_catalog
-						scNameStr,                       // constraint_schema
-						tree.NewDString(conName),        // constraint_name
-						dbNameStr,                       // table_catalog
-						scNameStr,                       // table_schema
-						tbNameStr,                       // table_name
-						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						dbNameStr,                             // constraint_catalog
+						scNameStr,                             // constraint_schema
+						tree.NewDString(conName),              // constraint_name
+						dbNameStr,                             // table_catalog
+						scNameStr,                             // table_schema
+						tbNameStr,                             // table_name
+						tree.NewDString(string(c.Kind)),       // constraint_type
+						yesOrNoDatum(c.IsDeferrable()),        // is_deferrable
+						yesOrNoDatum(c.IsInitiallyDeferred()), // initially_deferred
 					); err != nil {
 						return err
 					}
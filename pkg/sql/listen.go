@@ -0,0 +1,57 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	channel, err := notificationChannel(n.ChannelName)
+	if err != nil {
+		return nil, err
+	}
+	return &listenNode{channel: channel}, nil
+}
+
+type listenNode struct {
+	channel string
+}
+
+func (n *listenNode) startExec(params runParams) error {
+	if params.extendedEvalCtx.Notifications == nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "LISTEN is not supported in this context")
+	}
+	return params.extendedEvalCtx.Notifications.queueListen(n.channel)
+}
+
+func (n *listenNode) Next(_ runParams) (bool, error) { return false, nil }
+func (n *listenNode) Values() tree.Datums            { return nil }
+func (n *listenNode) Close(_ context.Context)        {}
+
+// notificationChannel returns the name of the notification channel referred to
+// by a LISTEN, UNLISTEN or NOTIFY statement.
+func notificationChannel(name *tree.UnresolvedObjectName) (string, error) {
+	if name.NumParts > 1 {
+		return "", pgerror.Newf(pgcode.Syntax, "invalid channel name: %s", name)
+	}
+	channel := name.Object()
+	if err := validateNotificationChannel(channel); err != nil {
+		return "", err
+	}
+	return channel, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package listennotify
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Listener tracks the channels a single session is listening on, and queues
+// up the notifications received on these channels until the session is ready
+// to deliver them to its client.
+type Listener struct {
+	registry *Registry
+	onNotify func()
+
+	// dropWarning rate limits the warnings logged when notifications get
+	// dropped because the queue is full.
+	dropWarning log.EveryN
+
+	mu struct {
+		syncutil.Mutex
+		// channels is the set of channels the session is listening on.
+		channels map[string]struct{}
+		// queue contains the notifications received and not yet delivered.
+		queue []pgnotify.Notification
+		// closed is set once the listener has been closed.
+		closed bool
+	}
+}
+
+// Listen starts listening on the given channel. It is a no-op if the session
+// is already listening on it.
+func (l *Listener) Listen(ctx context.Context, channel string) error {
+	l.mu.Lock()
+	_, ok := l.mu.channels[channel]
+	closed := l.mu.closed
+	l.mu.Unlock()
+	if ok || closed {
+		return nil
+	}
+	if err := l.registry.register(ctx, l, channel); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.channels[channel] = struct{}{}
+	return nil
+}
+
+// Unlisten stops listening on the given channel. It is a no-op if the session
+// is not listening on it.
+func (l *Listener) Unlisten(channel string) {
+	l.mu.Lock()
+	_, ok := l.mu.channels[channel]
+	delete(l.mu.channels, channel)
+	l.mu.Unlock()
+	// NB: The registry's mutex is acquired before the listener's when
+	// dispatching notifications, so the listener's mutex must not be held here.
+	if ok {
+		l.registry.unregister(l, channel)
+	}
+}
+
+// UnlistenAll stops listening on all channels.
+func (l *Listener) UnlistenAll() {
+	l.mu.Lock()
+	channels := l.mu.channels
+	l.mu.channels = make(map[string]struct{})
+	l.mu.Unlock()
+	for channel := range channels {
+		l.registry.unregister(l, channel)
+	}
+}
+
+// Channels returns the channels the session is listening on, in sorted order.
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	channels := make([]string, 0, len(l.mu.channels))
+	for channel := range l.mu.channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Drain returns the queued notifications, in the order in which they were
+// received, and empties the queue.
+func (l *Listener) Drain() []pgnotify.Notification {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queue := l.mu.queue
+	l.mu.queue = nil
+	return queue
+}
+
+// Close stops listening on all channels and discards the queued
+// notifications. The Listener cannot be used anymore afterwards.
+func (l *Listener) Close() {
+	l.UnlistenAll()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.queue = nil
+	l.mu.closed = true
+}
+
+// enqueue queues up a notification received on one of the channels the
+// session is listening on. It returns false if the notification was dropped
+// because too many notifications are already queued up.
+//
+// enqueue is called with the registry's mutex held.
+func (l *Listener) enqueue(ctx context.Context, n pgnotify.Notification) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.mu.channels[n.Channel]; !ok {
+		// The session started listening on the channel, but the registration
+		// has not been recorded on the listener yet, or the session stopped
+		// listening on it in the meantime.
+		return false
+	}
+	if len(l.mu.queue) >= maxQueuedNotifications {
+		if l.dropWarning.ShouldLog() {
+			log.Warningf(ctx, "dropping notification on channel %q: "+
+				"%d notifications are already queued up", n.Channel, len(l.mu.queue))
+		}
+		return false
+	}
+	l.mu.queue = append(l.mu.queue, n)
+	return true
+}
+
+// dropWarningInterval is the minimum interval between two warnings about
+// dropped notifications for a given listener.
+const dropWarningInterval = 10 * time.Second
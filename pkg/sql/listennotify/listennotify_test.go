@@ -0,0 +1,160 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package listennotify_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListenNotify checks that notifications sent on a node are delivered to
+// the sessions listening on the channel on every node, once and only if the
+// sending transaction commits.
+func TestListenNotify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 2, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	connect := func(t *testing.T, serverIdx int) *pgx.Conn {
+		pgURL, cleanup := sqlutils.PGUrl(
+			t, tc.Server(serverIdx).ServingSQLAddr(), t.Name(), url.User(username.RootUser),
+		)
+		t.Cleanup(cleanup)
+		conn, err := pgx.Connect(ctx, pgURL.String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close(ctx) })
+		return conn
+	}
+	waitForNotification := func(t *testing.T, conn *pgx.Conn) *pgconn.Notification {
+		ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		defer cancel()
+		n, err := conn.WaitForNotification(ctx)
+		require.NoError(t, err)
+		return n
+	}
+	// expectNoNotification checks that no notification is delivered to conn
+	// within a short period of time.
+	expectNoNotification := func(t *testing.T, conn *pgx.Conn) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		n, err := conn.WaitForNotification(ctx)
+		require.Error(t, err)
+		require.Nil(t, n)
+	}
+
+	listener := connect(t, 0)
+	remoteListener := connect(t, 1)
+	sender := connect(t, 0)
+	for _, conn := range []*pgx.Conn{listener, remoteListener} {
+		_, err := conn.Exec(ctx, "LISTEN foo")
+		require.NoError(t, err)
+	}
+
+	t.Run("notify", func(t *testing.T) {
+		_, err := sender.Exec(ctx, "NOTIFY foo, 'hello'")
+		require.NoError(t, err)
+		for _, conn := range []*pgx.Conn{listener, remoteListener} {
+			n := waitForNotification(t, conn)
+			require.Equal(t, "foo", n.Channel)
+			require.Equal(t, "hello", n.Payload)
+			require.Equal(t, sender.PgConn().PID(), n.PID)
+		}
+	})
+
+	t.Run("pg_notify", func(t *testing.T) {
+		_, err := sender.Exec(ctx, "SELECT pg_notify('foo', 'world')")
+		require.NoError(t, err)
+		n := waitForNotification(t, listener)
+		require.Equal(t, "world", n.Payload)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		tx, err := sender.Begin(ctx)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "NOTIFY foo, 'rolled back'")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback(ctx))
+		expectNoNotification(t, listener)
+
+		tx, err = sender.Begin(ctx)
+		require.NoError(t, err)
+		// Duplicate notifications sent in a transaction are folded.
+		for i := 0; i < 2; i++ {
+			_, err = tx.Exec(ctx, "NOTIFY foo, 'committed'")
+			require.NoError(t, err)
+		}
+		_, err = tx.Exec(ctx, "NOTIFY foo, 'committed again'")
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+		require.Equal(t, "committed", waitForNotification(t, listener).Payload)
+		require.Equal(t, "committed again", waitForNotification(t, listener).Payload)
+		expectNoNotification(t, listener)
+	})
+
+	t.Run("savepoint", func(t *testing.T) {
+		// The notifications sent after a savepoint are discarded when rolling
+		// back to it.
+		tx, err := sender.Begin(ctx)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "SAVEPOINT s")
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "NOTIFY foo, 'rolled back'")
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "ROLLBACK TO SAVEPOINT s")
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+		expectNoNotification(t, listener)
+	})
+
+	t.Run("implicit mutation", func(t *testing.T) {
+		// The notifications sent by a mutation in an implicit transaction are
+		// written in its transaction, which is then not committed in one phase.
+		_, err := sender.Exec(ctx, "CREATE TABLE t (k INT PRIMARY KEY)")
+		require.NoError(t, err)
+		_, err = sender.Exec(ctx, "INSERT INTO t SELECT 1 FROM pg_notify('foo', 'inserted')")
+		require.NoError(t, err)
+		require.Equal(t, "inserted", waitForNotification(t, listener).Payload)
+
+		_, err = sender.Exec(ctx, "INSERT INTO t SELECT 1 FROM pg_notify('foo', 'duplicate')")
+		require.Error(t, err)
+		expectNoNotification(t, listener)
+	})
+
+	t.Run("unlisten", func(t *testing.T) {
+		_, err := remoteListener.Exec(ctx, "UNLISTEN foo")
+		require.NoError(t, err)
+		_, err = sender.Exec(ctx, "NOTIFY foo")
+		require.NoError(t, err)
+		require.Equal(t, "", waitForNotification(t, listener).Payload)
+		expectNoNotification(t, remoteListener)
+	})
+
+	t.Run("other channel", func(t *testing.T) {
+		_, err := sender.Exec(ctx, "NOTIFY bar")
+		require.NoError(t, err)
+		expectNoNotification(t, listener)
+	})
+}
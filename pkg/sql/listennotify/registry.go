@@ -0,0 +1,366 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package listennotify
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/multitenant"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// retention is the amount of time notifications are kept in
+// system.notifications. Notifications are delivered as soon as the rangefeeds
+// watching the table observe them, so this only needs to be long enough to
+// cover rangefeed restarts.
+var retention = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.notifications.retention",
+	"the amount of time for which notifications sent with NOTIFY are kept "+
+		"before being garbage collected",
+	10*time.Minute,
+	settings.PositiveDuration,
+)
+
+// gcInterval is the interval at which each node with listening sessions
+// deletes expired notifications from system.notifications.
+var gcInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.notifications.gc_interval",
+	"the interval at which expired notifications are garbage collected, set to zero to disable",
+	time.Minute,
+	settings.NonNegativeDuration,
+)
+
+// maxQueuedNotifications is the maximum number of notifications which can be
+// queued up for a single session before new notifications are dropped.
+const maxQueuedNotifications = 10000
+
+// MaxPayloadLength is the maximum length of the payload of a notification,
+// matching the limit imposed by Postgres.
+const MaxPayloadLength = 8000
+
+// Registry keeps track of the sessions on this node that are listening on
+// notification channels, and delivers to them the notifications sent on these
+// channels by any session in the cluster.
+//
+// Notifications are fanned out through system.notifications: NOTIFY inserts a
+// row in the sending transaction, which makes the notification visible to the
+// rangefeed watching the table only if and when that transaction commits. The
+// rangefeed is only started once a session on this node first starts
+// listening.
+type Registry struct {
+	codec            keys.SQLCodec
+	clock            *hlc.Clock
+	f                *rangefeed.Factory
+	stopper          *stop.Stopper
+	st               *cluster.Settings
+	ie               sqlutil.InternalExecutor
+	sysTableResolver catalog.SystemTableIDResolver
+	dec              rowDecoder
+
+	mu struct {
+		syncutil.Mutex
+		// started is set once the rangefeed watching system.notifications has
+		// been started.
+		started bool
+		// listeners maps each channel to the set of listeners on it.
+		listeners map[string]map[*Listener]struct{}
+		// seen contains the keys of the rows of system.notifications which have
+		// already been dispatched, along with their timestamp. Entries are removed
+		// once they are older than the retention period, whether or not the
+		// corresponding rows get garbage collected.
+		seen map[string]hlc.Timestamp
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(
+	codec keys.SQLCodec,
+	clock *hlc.Clock,
+	f *rangefeed.Factory,
+	stopper *stop.Stopper,
+	st *cluster.Settings,
+	ie sqlutil.InternalExecutor,
+	sysTableResolver catalog.SystemTableIDResolver,
+) *Registry {
+	r := &Registry{
+		codec:            codec,
+		clock:            clock,
+		f:                f,
+		stopper:          stopper,
+		st:               st,
+		ie:               ie,
+		sysTableResolver: sysTableResolver,
+		dec:              makeRowDecoder(),
+	}
+	r.mu.listeners = make(map[string]map[*Listener]struct{})
+	r.mu.seen = make(map[string]hlc.Timestamp)
+	return r
+}
+
+// NewListener creates a Listener for a session. onNotify is called, without
+// any lock held, every time a notification is queued on the listener; it must
+// not block.
+func (r *Registry) NewListener(onNotify func()) *Listener {
+	l := &Listener{
+		registry:    r,
+		onNotify:    onNotify,
+		dropWarning: log.Every(dropWarningInterval),
+	}
+	l.mu.channels = make(map[string]struct{})
+	return l
+}
+
+// Send records the given notifications in system.notifications using the
+// provided transaction. They are delivered to the listening sessions once, and
+// only if, txn commits. If txn is nil, the notifications are sent in a
+// transaction of their own.
+func (r *Registry) Send(
+	ctx context.Context, txn *kv.Txn, senderPID uint32, notifications []pgnotify.Notification,
+) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := r.checkVersion(ctx); err != nil {
+		return err
+	}
+	channels := make([]string, len(notifications))
+	payloads := make([]string, len(notifications))
+	for i, n := range notifications {
+		channels[i] = n.Channel
+		payloads[i] = n.Payload
+	}
+	_, err := r.ie.ExecEx(
+		ctx, "send-notifications", txn,
+		sessiondata.NodeUserSessionDataOverride,
+		`INSERT INTO system.notifications (id, sender_pid, channels, payloads)
+VALUES (gen_random_uuid(), $1, $2, $3)`,
+		int64(senderPID), channels, payloads,
+	)
+	return errors.Wrap(err, "sending notifications")
+}
+
+// checkVersion returns an error if the cluster has not been upgraded to a
+// version which has system.notifications.
+func (r *Registry) checkVersion(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.SystemNotificationsTable) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"LISTEN and NOTIFY are not supported until upgrade to version %s is finalized",
+			clusterversion.SystemNotificationsTable.String())
+	}
+	return nil
+}
+
+// register adds l to the listeners on the given channel, starting the
+// rangefeed watching system.notifications if needed.
+func (r *Registry) register(ctx context.Context, l *Listener, channel string) error {
+	if err := r.checkVersion(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.mu.started {
+		if err := r.startLocked(ctx); err != nil {
+			return err
+		}
+		r.mu.started = true
+	}
+	ls, ok := r.mu.listeners[channel]
+	if !ok {
+		ls = make(map[*Listener]struct{})
+		r.mu.listeners[channel] = ls
+	}
+	ls[l] = struct{}{}
+	return nil
+}
+
+// unregister removes l from the listeners on the given channel.
+func (r *Registry) unregister(l *Listener, channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ls := r.mu.listeners[channel]
+	delete(ls, l)
+	if len(ls) == 0 {
+		delete(r.mu.listeners, channel)
+	}
+}
+
+// startLocked starts the rangefeed watching system.notifications, along with
+// the task which garbage collects old notifications.
+func (r *Registry) startLocked(ctx context.Context) error {
+	tableID, err := r.sysTableResolver.LookupSystemTableID(
+		ctx, systemschema.SystemNotificationsTable.GetName(),
+	)
+	if err != nil {
+		return err
+	}
+	tablePrefix := r.codec.TablePrefix(uint32(tableID))
+	tableSpan := roachpb.Span{
+		Key:    tablePrefix,
+		EndKey: tablePrefix.PrefixEnd(),
+	}
+	// The rangefeed and the GC task are tied to the lifetime of the server, not
+	// to the session which happened to start them.
+	bgCtx, _ := r.stopper.WithCancelOnQuiesce(context.Background())
+	bgCtx = multitenant.WithTenantCostControlExemption(bgCtx)
+	// Only notifications committed from now on are of interest; there is no
+	// need for an initial scan.
+	rf, err := r.f.RangeFeed(
+		bgCtx,
+		"notifications",
+		[]roachpb.Span{tableSpan},
+		r.clock.Now(),
+		r.onValue,
+	)
+	if err != nil {
+		return err
+	}
+	r.stopper.AddCloser(rf)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down, in which case there is nothing to garbage collect.
+	_ = r.stopper.RunAsyncTask(bgCtx, "notifications-gc", r.gcLoop)
+	return nil
+}
+
+// onValue is called by the rangefeed for every write to system.notifications.
+func (r *Registry) onValue(ctx context.Context, value *roachpb.RangeFeedValue) {
+	if !value.Value.IsPresent() {
+		// Deletions of expired notifications are of no interest.
+		return
+	}
+	notifications, err := r.dec.decodeRow(roachpb.KeyValue{Key: value.Key, Value: value.Value})
+	if err != nil {
+		log.Warningf(ctx, "failed to decode notifications %v: %v", value.Key, err)
+		return
+	}
+	r.dispatch(ctx, string(value.Key), value.Value.Timestamp, notifications)
+}
+
+// dispatch queues the notifications stored in the row with the given key on
+// the listeners of their channels.
+func (r *Registry) dispatch(
+	ctx context.Context, key string, ts hlc.Timestamp, notifications []pgnotify.Notification,
+) {
+	toWake := make(map[*Listener]struct{})
+	func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// The rangefeed may emit the same row more than once if it gets
+		// restarted. Don't deliver the same notifications twice.
+		if _, ok := r.mu.seen[key]; ok {
+			return
+		}
+		r.mu.seen[key] = ts
+		for _, n := range notifications {
+			for l := range r.mu.listeners[n.Channel] {
+				if l.enqueue(ctx, n) {
+					toWake[l] = struct{}{}
+				}
+			}
+		}
+	}()
+	for l := range toWake {
+		l.onNotify()
+	}
+}
+
+// gcLoop periodically deletes the notifications which are older than the
+// retention period.
+func (r *Registry) gcLoop(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		timer.Reset(r.collectGarbage(ctx))
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectGarbage forgets the dispatched rows which are older than the
+// retention period and, unless garbage collection is disabled, deletes them
+// from system.notifications. It returns the interval after which it should be
+// called again.
+func (r *Registry) collectGarbage(ctx context.Context) time.Duration {
+	// The set of dispatched rows is pruned even if garbage collection is
+	// disabled, so that it doesn't grow without bound.
+	ttl := retention.Get(&r.st.SV)
+	r.forgetSeen(r.clock.Now().Add(-ttl.Nanoseconds(), 0))
+	interval := gcInterval.Get(&r.st.SV)
+	if interval <= 0 {
+		// Check back later in case garbage collection gets re-enabled.
+		return time.Minute
+	}
+	if err := r.deleteExpired(ctx, ttl); err != nil && ctx.Err() == nil {
+		log.Warningf(ctx, "failed to delete expired notifications: %v", err)
+	}
+	return interval
+}
+
+// gcBatchSize is the maximum number of notifications deleted by a single
+// statement.
+const gcBatchSize = 1000
+
+// deleteExpired deletes the notifications which are older than the given
+// retention period, in batches.
+func (r *Registry) deleteExpired(ctx context.Context, ttl time.Duration) error {
+	for {
+		n, err := r.ie.ExecEx(
+			ctx, "delete-expired-notifications", nil, /* txn */
+			sessiondata.NodeUserSessionDataOverride,
+			`DELETE FROM system.notifications WHERE created < now() - $1 LIMIT $2`,
+			ttl, gcBatchSize,
+		)
+		if err != nil {
+			return err
+		}
+		if n < gcBatchSize {
+			return nil
+		}
+	}
+}
+
+// forgetSeen removes from the set of dispatched rows the ones written before
+// the cutoff. A rangefeed restart resumes from the last checkpoint of the
+// rangefeed, which only lags by seconds, so it doesn't emit them again.
+func (r *Registry) forgetSeen(cutoff hlc.Timestamp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, ts := range r.mu.seen {
+		if ts.Less(cutoff) {
+			delete(r.mu.seen, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package listennotify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegistryForgetsSeenRows checks that the rows dispatched by the registry
+// are forgotten once they are older than the retention period, even if
+// garbage collection is disabled.
+func TestRegistryForgetsSeenRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	manual := timeutil.NewManualTime(timeutil.Unix(0, 123))
+	clock := hlc.NewClock(manual, time.Nanosecond /* maxOffset */)
+	st := cluster.MakeTestingClusterSettings()
+	retention.Override(ctx, &st.SV, time.Minute)
+	gcInterval.Override(ctx, &st.SV, 0)
+	r := NewRegistry(
+		keys.SystemSQLCodec, clock, nil /* f */, nil /* stopper */, st, nil /* ie */, nil, /* sysTableResolver */
+	)
+
+	r.dispatch(ctx, "old", clock.Now(), nil /* notifications */)
+	manual.Advance(30 * time.Second)
+	r.dispatch(ctx, "new", clock.Now(), nil /* notifications */)
+	// The notifications are not deleted, since garbage collection is disabled,
+	// so the internal executor isn't used.
+	require.Equal(t, time.Minute, r.collectGarbage(ctx))
+	require.Len(t, r.mu.seen, 2)
+
+	manual.Advance(45 * time.Second)
+	r.collectGarbage(ctx)
+	require.Len(t, r.mu.seen, 1)
+	require.Contains(t, r.mu.seen, "new")
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package listennotify
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/valueside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// Ordinals of the columns of system.notifications.
+const (
+	senderPIDColumnIdx = 2
+	channelsColumnIdx  = 3
+	payloadsColumnIdx  = 4
+)
+
+// rowDecoder decodes rows from the system.notifications table. It is not safe
+// for concurrent use.
+type rowDecoder struct {
+	alloc   tree.DatumAlloc
+	columns []catalog.Column
+	decoder valueside.Decoder
+}
+
+func makeRowDecoder() rowDecoder {
+	columns := systemschema.SystemNotificationsTable.PublicColumns()
+	return rowDecoder{
+		columns: columns,
+		decoder: valueside.MakeDecoder(columns),
+	}
+}
+
+// decodeRow decodes a row of system.notifications into the notifications it
+// contains, in the order in which they were sent. Only the value needs to be
+// decoded: the columns stored in the key are of no interest.
+func (d *rowDecoder) decodeRow(kv roachpb.KeyValue) ([]pgnotify.Notification, error) {
+	bytes, err := kv.Value.GetTuple()
+	if err != nil {
+		return nil, err
+	}
+	datums, err := d.decoder.Decode(&d.alloc, bytes)
+	if err != nil {
+		return nil, err
+	}
+	pid := uint32(tree.MustBeDInt(datums[senderPIDColumnIdx]))
+	channels := tree.MustBeDArray(datums[channelsColumnIdx])
+	payloads := tree.MustBeDArray(datums[payloadsColumnIdx])
+	if channels.Len() != payloads.Len() {
+		return nil, errors.AssertionFailedf(
+			"found %d channels but %d payloads", channels.Len(), payloads.Len(),
+		)
+	}
+	notifications := make([]pgnotify.Notification, channels.Len())
+	for i := range notifications {
+		notifications[i] = pgnotify.Notification{
+			Channel:   string(tree.MustBeDString(channels.Array[i])),
+			Payload:   string(tree.MustBeDString(payloads.Array[i])),
+			SenderPID: pid,
+		}
+	}
+	return notifications, nil
+}
@@ -86,6 +86,20 @@ func TestLogic_create_index(
 	runLogicTest(t, "create_index")
 }
 
+func TestLogic_deferrable_constraints_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "deferrable_constraints_mixed")
+}
+
+func TestLogic_domain_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "domain_mixed")
+}
+
 func TestLogic_drop_database(
 	t *testing.T,
 ) {
@@ -135,6 +149,13 @@ func TestLogic_drop_view(
 	runLogicTest(t, "drop_view")
 }
 
+func TestLogic_exclusion_constraints_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "exclusion_constraints_mixed")
+}
+
 func TestLogic_new_schema_changer_mixed(
 	t *testing.T,
 ) {
@@ -142,9 +163,51 @@ func TestLogic_new_schema_changer_mixed(
 	runLogicTest(t, "new_schema_changer_mixed")
 }
 
+func TestLogic_procedure_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "procedure_mixed")
+}
+
+func TestLogic_publication_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "publication_mixed")
+}
+
+func TestLogic_read_committed_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "read_committed_mixed")
+}
+
+func TestLogic_read_replicas_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "read_replicas_mixed")
+}
+
 func TestLogic_synthetic_privileges_mixed(
 	t *testing.T,
 ) {
 	defer leaktest.AfterTest(t)()
 	runLogicTest(t, "synthetic_privileges_mixed")
 }
+
+func TestLogic_udf_plpgsql_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "udf_plpgsql_mixed")
+}
+
+func TestLogic_tsvector_mixed(
+	t *testing.T,
+) {
+	defer leaktest.AfterTest(t)()
+	runLogicTest(t, "tsvector_mixed")
+}
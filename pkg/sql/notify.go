@@ -0,0 +1,73 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Notify implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	channel, err := notificationChannel(n.ChannelName)
+	if err != nil {
+		return nil, err
+	}
+	return &notifyNode{channel: channel, payload: n.Payload}, nil
+}
+
+type notifyNode struct {
+	channel string
+	payload string
+}
+
+func (n *notifyNode) startExec(params runParams) error {
+	return params.p.SendNotification(params.ctx, n.channel, n.payload)
+}
+
+func (n *notifyNode) Next(_ runParams) (bool, error) { return false, nil }
+func (n *notifyNode) Values() tree.Datums            { return nil }
+func (n *notifyNode) Close(_ context.Context)        {}
+
+// SendNotification is part of the eval.Planner interface.
+func (p *planner) SendNotification(ctx context.Context, channel, payload string) error {
+	if err := validateNotification(channel, payload); err != nil {
+		return err
+	}
+	if p.EvalContext().TxnReadOnly {
+		return pgerror.New(pgcode.ReadOnlySQLTransaction,
+			"cannot execute NOTIFY in a read-only transaction")
+	}
+	if p.extendedEvalCtx.Notifications == nil {
+		return pgerror.New(pgcode.FeatureNotSupported, "NOTIFY is not supported in this context")
+	}
+	return p.extendedEvalCtx.Notifications.queueNotification(channel, payload)
+}
+
+// HasPendingNotifications is part of the eval.Planner interface.
+func (p *planner) HasPendingNotifications() bool {
+	if p.extendedEvalCtx.Notifications == nil {
+		return false
+	}
+	return p.extendedEvalCtx.Notifications.hasPendingNotifications()
+}
+
+// ListeningChannels is part of the eval.Planner interface.
+func (p *planner) ListeningChannels() []string {
+	if p.extendedEvalCtx.Notifications == nil {
+		return nil
+	}
+	return p.extendedEvalCtx.Notifications.listeningChannels()
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/errors"
@@ -104,6 +105,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.AlterDatabaseSetZoneConfigExtension(ctx, n)
 	case *tree.AlterDefaultPrivileges:
 		return p.alterDefaultPrivileges(ctx, n)
+	case *tree.AlterDomain:
+		return p.AlterDomain(ctx, n)
 	case *tree.AlterFunctionOptions:
 		return p.AlterFunctionOptions(ctx, n)
 	case *tree.AlterFunctionRename:
@@ -118,6 +121,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.AlterIndex(ctx, n)
 	case *tree.AlterIndexVisible:
 		return p.AlterIndexVisible(ctx, n)
+	case *tree.AlterPublication:
+		return p.AlterPublication(ctx, n)
 	case *tree.AlterSchema:
 		return p.AlterSchema(ctx, n)
 	case *tree.AlterTable:
@@ -156,6 +161,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.CreateDatabase(ctx, n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreatePublication:
+		return p.CreatePublication(ctx, n)
 	case *tree.CreateSchema:
 		return p.CreateSchema(ctx, n)
 	case *tree.CreateType:
@@ -164,6 +171,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.CreateRole(ctx, n)
 	case *tree.CreateSequence:
 		return p.CreateSequence(ctx, n)
+	case *tree.CreateTrigger:
+		return p.CreateTrigger(ctx, n)
 	case *tree.CreateExtension:
 		return p.CreateExtension(ctx, n)
 	case *tree.CreateExternalConnection:
@@ -184,6 +193,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.DropIndex(ctx, n)
 	case *tree.DropOwnedBy:
 		return p.DropOwnedBy(ctx)
+	case *tree.DropPublication:
+		return p.DropPublication(ctx, n)
 	case *tree.DropRole:
 		return p.DropRole(ctx, n)
 	case *tree.DropSchema:
@@ -192,6 +203,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.DropSequence(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
+	case *tree.DropTrigger:
+		return p.DropTrigger(ctx, n)
 	case *tree.DropType:
 		return p.DropType(ctx, n)
 	case *tree.DropView:
@@ -234,6 +247,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.SetVar(ctx, n)
 	case *tree.SetTransaction:
 		return p.SetTransaction(ctx, n)
+	case *tree.SetConstraints:
+		return p.SetConstraints(ctx, n)
 	case *tree.SetSessionAuthorizationDefault:
 		return p.SetSessionAuthorizationDefault()
 	case *tree.SetSessionCharacteristics:
@@ -262,8 +277,18 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.ShowVar(ctx, &tree.ShowVar{Name: "transaction_status"})
 	case *tree.Truncate:
 		return p.Truncate(ctx, n)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
+	case *tree.Notify:
+		return p.Notify(ctx, n)
 	case *tree.Unlisten:
 		return p.Unlisten(ctx, n)
+	case *pgrepl.IdentifySystem:
+		return p.IdentifySystem(ctx, n)
+	case *pgrepl.CreateReplicationSlot:
+		return p.CreateReplicationSlot(ctx, n)
+	case *pgrepl.DropReplicationSlot:
+		return p.DropReplicationSlot(ctx, n)
 	case tree.CCLOnlyStatement:
 		plan, err := p.maybePlanHook(ctx, stmt)
 		if plan == nil && err == nil {
@@ -292,6 +317,7 @@ func init() {
 		&tree.AlterDatabaseDropSecondaryRegion{},
 		&tree.AlterDatabaseSetZoneConfigExtension{},
 		&tree.AlterDefaultPrivileges{},
+		&tree.AlterDomain{},
 		&tree.AlterFunctionOptions{},
 		&tree.AlterFunctionRename{},
 		&tree.AlterFunctionSetOwner{},
@@ -299,6 +325,7 @@ func init() {
 		&tree.AlterFunctionDepExtension{},
 		&tree.AlterIndex{},
 		&tree.AlterIndexVisible{},
+		&tree.AlterPublication{},
 		&tree.AlterSchema{},
 		&tree.AlterTable{},
 		&tree.AlterTableLocality{},
@@ -320,8 +347,10 @@ func init() {
 		&tree.CreateExtension{},
 		&tree.CreateExternalConnection{},
 		&tree.CreateIndex{},
+		&tree.CreatePublication{},
 		&tree.CreateSchema{},
 		&tree.CreateSequence{},
+		&tree.CreateTrigger{},
 		&tree.CreateType{},
 		&tree.CreateRole{},
 		&tree.Deallocate{},
@@ -332,10 +361,12 @@ func init() {
 		&tree.DropFunction{},
 		&tree.DropIndex{},
 		&tree.DropOwnedBy{},
+		&tree.DropPublication{},
 		&tree.DropRole{},
 		&tree.DropSchema{},
 		&tree.DropSequence{},
 		&tree.DropTable{},
+		&tree.DropTrigger{},
 		&tree.DropType{},
 		&tree.DropView{},
 		&tree.FetchCursor{},
@@ -357,6 +388,7 @@ func init() {
 		&tree.SetZoneConfig{},
 		&tree.SetVar{},
 		&tree.SetTransaction{},
+		&tree.SetConstraints{},
 		&tree.SetSessionAuthorizationDefault{},
 		&tree.SetSessionCharacteristics{},
 		&tree.ShowClusterSetting{},
@@ -371,18 +403,30 @@ func init() {
 		&tree.ShowVar{},
 		&tree.ShowTransactionStatus{},
 		&tree.Truncate{},
+		&tree.Listen{},
+		&tree.Notify{},
 		&tree.Unlisten{},
 
+		// Replication commands, which are only accepted on replication
+		// connections.
+		&pgrepl.IdentifySystem{},
+		&pgrepl.CreateReplicationSlot{},
+		&pgrepl.DropReplicationSlot{},
+
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.AlterBackup{},
 		&tree.AlterBackupSchedule{},
+		&tree.AttachBackup{},
 		&tree.Backup{},
 		&tree.ShowBackup{},
+		&tree.ShowBackupTable{},
 		&tree.Restore{},
 		&tree.CreateChangefeed{},
 		&tree.Import{},
 		&tree.ScheduledBackup{},
 		&tree.StreamIngestion{},
+		&tree.VerifyBackup{},
+		&tree.RestoreRows{},
 	} {
 		typ := optbuilder.OpaqueReadOnly
 		if tree.CanModifySchema(stmt) {
@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/lib/pq/oid"
 )
 
 // Table is an interface to a database table, exposing only the information
@@ -51,6 +52,12 @@ type Table interface {
 	// that they cannot be mutated.
 	IsMaterializedView() bool
 
+	// IsBackupTable returns true if this table was attached from a backup with
+	// ATTACH BACKUP. The rows of such a table are read from the files of the
+	// backup, it can only be scanned through its primary index in the forward
+	// direction, and it cannot be mutated.
+	IsBackupTable() bool
+
 	// ColumnCount returns the number of columns in the table. This includes
 	// public columns, write-only columns, etc.
 	ColumnCount() int
@@ -139,6 +146,23 @@ type Table interface {
 	// i < UniqueCount.
 	Unique(i UniqueOrdinal) UniqueConstraint
 
+	// ExclusionCount returns the number of exclusion constraints defined on this
+	// table.
+	ExclusionCount() int
+
+	// Exclusion returns the ith exclusion constraint defined on this table,
+	// where i < ExclusionCount.
+	Exclusion(i int) ExclusionConstraint
+
+	// TriggerCount returns the number of row-level triggers defined on this
+	// table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger defined on this table, where
+	// i < TriggerCount. Triggers are ordered by name, which is the order in
+	// which they fire.
+	Trigger(i int) Trigger
+
 	// Zone returns a table's zone.
 	Zone() Zone
 
@@ -185,6 +209,40 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger describes a row-level trigger on a table. The trigger invokes a
+// PL/pgSQL function that returns trigger for each row that is inserted,
+// updated, or deleted by a statement. For example:
+//
+//	CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW EXECUTE FUNCTION f()
+type Trigger struct {
+	Name tree.Name
+
+	// Before is true if the function is invoked before each row is modified,
+	// and false if it is invoked after the statement has modified all rows.
+	Before bool
+
+	OnInsert bool
+	OnUpdate bool
+	OnDelete bool
+
+	// FuncOID is the OID of the function invoked by the trigger.
+	FuncOID oid.Oid
+}
+
+// FiresOn returns true if the trigger fires for the given type of row
+// modification.
+func (t *Trigger) FiresOn(event tree.TriggerEventType) bool {
+	switch event {
+	case tree.TriggerEventInsert:
+		return t.OnInsert
+	case tree.TriggerEventUpdate:
+		return t.OnUpdate
+	case tree.TriggerEventDelete:
+		return t.OnDelete
+	}
+	return false
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -297,6 +355,15 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrable is true if the checks for the constraint may be deferred until
+	// the end of the transaction. Since a deferred constraint can be violated
+	// while the transaction is open, the optimizer cannot rely on it to hold.
+	Deferrable() bool
+
+	// InitiallyDeferred is true if the checks for the constraint are deferred
+	// unless SET CONSTRAINTS requests otherwise. It implies Deferrable.
+	InitiallyDeferred() bool
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -344,6 +411,15 @@ type UniqueConstraint interface {
 	// satisfied when building functional dependencies for the table. This enables
 	// additional optimizations, such as omission of uniqueness checks.
 	UniquenessGuaranteedByAnotherIndex() bool
+
+	// Deferrable is true if the checks for the constraint may be deferred until
+	// the end of the transaction. Only constraints without an index can be
+	// deferrable.
+	Deferrable() bool
+
+	// InitiallyDeferred is true if the checks for the constraint are deferred
+	// unless SET CONSTRAINTS requests otherwise. It implies Deferrable.
+	InitiallyDeferred() bool
 }
 
 // UniqueOrdinal identifies a unique constraint (in the context of a Table).
@@ -352,3 +428,44 @@ type UniqueOrdinal = int
 // UniqueOrdinals identifies a list of unique constraints (in the context of
 // a Table).
 type UniqueOrdinals = []UniqueOrdinal
+
+// ExclusionConstraint represents an exclusion constraint on a table. Two rows
+// conflict with each other if their values in all but the last column of the
+// constraint are equal, and their values in the last column overlap (according
+// to the && operator). If the constraint compares ranges, the last two columns
+// are instead the lower and upper bounds of the ranges, and the ranges of the
+// two rows must overlap. An exclusion constraint guarantees that no two rows of
+// the table conflict with each other. Exclusion constraints are always backed
+// by an index.
+type ExclusionConstraint interface {
+	// Name of the exclusion constraint.
+	Name() string
+
+	// TableID returns the stable identifier of the table on which this
+	// exclusion constraint is defined.
+	TableID() StableID
+
+	// ColumnCount returns the number of columns in this constraint.
+	ColumnCount() int
+
+	// ColumnOrdinal returns the table column ordinal of the ith column in this
+	// constraint.
+	ColumnOrdinal(tab Table, i int) int
+
+	// Predicate returns the partial predicate expression and true if the
+	// constraint is a partial exclusion constraint. If it is not, the empty
+	// string and false are returned.
+	Predicate() (string, bool)
+
+	// IsRange is true if the last two columns of the constraint are the lower
+	// and upper bounds of the ranges which are compared with &&. A range
+	// includes its lower bound and excludes its upper bound, and a NULL bound
+	// is unbounded.
+	IsRange() bool
+
+	// Validated is true if the existing data is known to satisfy the
+	// constraint. This is not the case while the constraint is being added to
+	// an existing table, but the constraint still needs to be enforced on new
+	// mutations.
+	Validated() bool
+}
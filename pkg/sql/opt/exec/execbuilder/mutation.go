@@ -15,6 +15,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/mutations"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
@@ -123,6 +125,10 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -159,6 +165,12 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	// We cannot use the fast path if any AFTER triggers need to be invoked
+	// after the insert.
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	md := b.mem.Metadata()
 	tab := md.Table(ins.Table)
 
@@ -171,12 +183,22 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			// Self-referencing FK.
 			return execPlan{}, false, nil
 		}
+		if c.Deferrable {
+			// The fast path cannot defer the check to the end of the
+			// transaction.
+			return execPlan{}, false, nil
+		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
 			return execPlan{}, false, nil
 		}
+		if lookupJoin.Locking.IsLocking() {
+			// The fast path lookups can't lock the referenced rows, which is
+			// required for transactions that tolerate write skew.
+			return execPlan{}, false, nil
+		}
 		// TODO(rytaft): see if we can remove the requirement that LookupExpr is
 		// empty.
 		if len(lookupJoin.On) > 0 || len(lookupJoin.LookupExpr) > 0 ||
@@ -413,7 +435,7 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	// TODO(andyk): Using ensureColumns here can result in an extra Render.
 	// Upgrade execution engine to not require this.
 	cnt := len(ups.InsertCols) + len(ups.FetchCols) + len(ups.UpdateCols) + len(ups.CheckCols) +
-		len(ups.PartialIndexPutCols) + len(ups.PartialIndexDelCols) + 1
+		len(ups.PartialIndexPutCols) + len(ups.PartialIndexDelCols) + 2
 	colList := make(opt.ColList, 0, cnt)
 	colList = appendColsWhenPresent(colList, ups.InsertCols)
 	colList = appendColsWhenPresent(colList, ups.FetchCols)
@@ -421,6 +443,9 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	if ups.CanaryCol != 0 {
 		colList = append(colList, ups.CanaryCol)
 	}
+	if ups.MergeDeleteCol != 0 {
+		colList = append(colList, ups.MergeDeleteCol)
+	}
 	colList = appendColsWhenPresent(colList, ups.CheckCols)
 	colList = appendColsWhenPresent(colList, ups.PartialIndexPutCols)
 	colList = appendColsWhenPresent(colList, ups.PartialIndexDelCols)
@@ -437,6 +462,10 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	if ups.CanaryCol != 0 {
 		canaryCol = input.getNodeColumnOrdinal(ups.CanaryCol)
 	}
+	deleteCol := exec.NodeColumnOrdinal(-1)
+	if ups.MergeDeleteCol != 0 {
+		deleteCol = input.getNodeColumnOrdinal(ups.MergeDeleteCol)
+	}
 	insertColOrds := ordinalSetFromColList(ups.InsertCols)
 	fetchColOrds := ordinalSetFromColList(ups.FetchCols)
 	updateColOrds := ordinalSetFromColList(ups.UpdateCols)
@@ -448,6 +477,7 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 		ups.ArbiterIndexes,
 		ups.ArbiterConstraints,
 		canaryCol,
+		deleteCol,
 		insertColOrds,
 		fetchColOrds,
 		updateColOrds,
@@ -680,7 +710,7 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 }
 
 // buildUniqueChecks builds uniqueness check queries. These check queries are
-// used to enforce UNIQUE WITHOUT INDEX constraints.
+// used to enforce UNIQUE WITHOUT INDEX constraints and exclusion constraints.
 //
 // The checks consist of queries that will only return rows if a constraint is
 // violated. Those queries are each wrapped in an ErrorIfRows operator, which
@@ -700,7 +730,17 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkUniqueCheckErr(md, c, keyVals)
+			if c.Exclusion {
+				return mkExclusionCheckErr(md, c, keyVals)
+			}
+			err := mkUniqueCheckErr(md, c, keyVals)
+			if c.Deferrable {
+				tab := md.Table(c.Table)
+				return b.maybeDeferCheckViolation(
+					tab.ID(), tab.Unique(c.CheckOrdinal), keyVals, err,
+				)
+			}
+			return err
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
@@ -726,7 +766,18 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkFKCheckErr(md, c, keyVals)
+			err := mkFKCheckErr(md, c, keyVals)
+			if c.Deferrable {
+				origin := md.Table(c.OriginTable)
+				var fk cat.ForeignKeyConstraint
+				if c.FKOutbound {
+					fk = origin.OutboundForeignKey(c.FKOrdinal)
+				} else {
+					fk = md.Table(c.ReferencedTable).InboundForeignKey(c.FKOrdinal)
+				}
+				return b.maybeDeferCheckViolation(origin.ID(), fk, keyVals, err)
+			}
+			return err
 		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
@@ -737,6 +788,38 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// deferrableConstraint is the subset of cat.UniqueConstraint and
+// cat.ForeignKeyConstraint needed to defer a constraint check.
+type deferrableConstraint interface {
+	Name() string
+	InitiallyDeferred() bool
+}
+
+// maybeDeferCheckViolation is called when the check for a DEFERRABLE constraint
+// found a violation, described by err. If the constraint is currently deferred,
+// the violation is recorded to be checked again at the end of the transaction
+// and nil is returned. Otherwise, err is returned.
+//
+// Constraints are never deferred in implicit transactions, since the end of
+// the statement is also the end of the transaction.
+func (b *Builder) maybeDeferCheckViolation(
+	tableID cat.StableID, c deferrableConstraint, keyVals tree.Datums, err error,
+) error {
+	if b.evalCtx == nil || b.evalCtx.Planner == nil || b.evalCtx.TxnImplicit {
+		return err
+	}
+	p := b.evalCtx.Planner
+	if !p.IsConstraintCheckDeferred(c.Name(), c.InitiallyDeferred()) {
+		return err
+	}
+	return p.DeferConstraintViolation(eval.DeferredConstraintViolation{
+		TableID:        descpb.ID(tableID),
+		ConstraintName: c.Name(),
+		Key:            keyVals,
+		Err:            err,
+	})
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -779,6 +862,48 @@ func mkUniqueCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.D
 	)
 }
 
+// mkExclusionCheckErr generates a user-friendly error describing an exclusion
+// constraint violation. The keyVals are the values that correspond to the
+// cat.ExclusionConstraint columns.
+func mkExclusionCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums) error {
+	tabMeta := md.TableMeta(c.Table)
+	ec := tabMeta.Table.Exclusion(c.CheckOrdinal)
+	constraintName := ec.Name()
+	var msg, details bytes.Buffer
+
+	// Generate an error of the form:
+	//   ERROR:  conflicting key value violates exclusion constraint "foo"
+	//   DETAIL: Key (k, r)=(1, {1,2}) conflicts with existing key.
+	msg.WriteString("conflicting key value violates exclusion constraint ")
+	lexbase.EncodeEscapedSQLIdent(&msg, constraintName)
+
+	details.WriteString("Key (")
+	for i := 0; i < ec.ColumnCount(); i++ {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		col := tabMeta.Table.Column(ec.ColumnOrdinal(tabMeta.Table, i))
+		details.WriteString(string(col.ColName()))
+	}
+	details.WriteString(")=(")
+	for i, d := range keyVals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(d.String())
+	}
+
+	details.WriteString(") conflicts with existing key.")
+
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(pgcode.ExclusionViolation, "%s", msg.String()),
+			constraintName,
+		),
+		details.String(),
+	)
+}
+
 // mkFKCheckErr generates a user-friendly error describing a foreign key
 // violation. The keyVals are the values that correspond to the
 // cat.ForeignKeyConstraint columns.
@@ -745,5 +745,7 @@ func (b *Builder) buildUDF(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Typ
 		udf.Typ,
 		udf.Volatility,
 		udf.CalledOnNullInput,
+		udf.Program,
+		udf.SetReturning,
 	), nil
 }
@@ -497,6 +497,10 @@ func (u *unknownTable) IsMaterializedView() bool {
 	return false
 }
 
+func (u *unknownTable) IsBackupTable() bool {
+	return false
+}
+
 func (u *unknownTable) ColumnCount() int {
 	return 0
 }
@@ -569,6 +573,22 @@ func (u *unknownTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("not implemented"))
 }
 
+func (u *unknownTable) ExclusionCount() int {
+	return 0
+}
+
+func (u *unknownTable) Exclusion(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
+func (u *unknownTable) TriggerCount() int {
+	return 0
+}
+
+func (u *unknownTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("not implemented"))
+}
+
 func (u *unknownTable) Zone() cat.Zone {
 	return cat.EmptyZone()
 }
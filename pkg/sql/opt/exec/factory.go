@@ -268,7 +268,9 @@ type InsertFastPathFKCheck struct {
 }
 
 // MkErrFn is a function that generates an error which includes values from a
-// relevant row.
+// relevant row. It can return nil if the row does not need to result in an
+// error, for example because the violation of a deferred constraint was
+// recorded to be checked at the end of the transaction.
 type MkErrFn func(tree.Datums) error
 
 // ExplainFactory is an extension of Factory used when constructing a plan that
@@ -116,13 +116,20 @@ func TryFilterInvertedIndex(
 	} else {
 		col := index.InvertedColumn().InvertedSourceColumnOrdinal()
 		typ = factory.Metadata().Table(tabID).Column(col).DatumType()
-		if typ.Family() == types.StringFamily {
+		switch typ.Family() {
+		case types.StringFamily:
 			filterPlanner = &trigramFilterPlanner{
 				tabID:           tabID,
 				index:           index,
 				computedColumns: computedColumns,
 			}
-		} else {
+		case types.TSVectorFamily:
+			filterPlanner = &tsqueryFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		default:
 			filterPlanner = &jsonOrArrayFilterPlanner{
 				tabID:           tabID,
 				index:           index,
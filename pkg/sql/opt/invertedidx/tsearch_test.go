@@ -0,0 +1,124 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestTryFilterTSVector(t *testing.T) {
+	semaCtx := tree.MakeSemaContext()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := eval.NewTestingEvalContext(st)
+
+	tc := testcat.New()
+	if _, err := tc.ExecuteDDL(
+		"CREATE TABLE t (v TSVECTOR, INVERTED INDEX (v))",
+	); err != nil {
+		t.Fatal(err)
+	}
+	var f norm.Factory
+	f.Init(context.Background(), evalCtx, tc)
+	md := f.Metadata()
+	tn := tree.NewUnqualifiedTableName("t")
+	tab := md.AddTable(tc.Table(tn), tn)
+	tsvectorOrd := 1
+
+	// If we can create an inverted filter with the given filter expression and
+	// index, ok=true. If the spans in the resulting inverted index constraint
+	// do not have duplicate primary keys, unique=true. If the spans are tight,
+	// tight=true and remainingFilters="". Otherwise, tight is false and
+	// remainingFilters contains some or all of the original filters.
+	testCases := []struct {
+		filters          string
+		ok               bool
+		tight            bool
+		unique           bool
+		remainingFilters string
+	}{
+		{filters: "v @@ 'a'", ok: true, tight: true, unique: true},
+		{filters: "'a'::TSQUERY @@ v", ok: true, tight: true, unique: true},
+		{filters: "v @@ 'a & b'", ok: true, tight: true, unique: true},
+		{filters: "v @@ 'a | b'", ok: true, tight: true, unique: false},
+		// Prefix searches can match several lexemes of the same tsvector.
+		{filters: "v @@ 'a:*'", ok: true, tight: true, unique: false},
+		// Weights, phrases and negations are checked after the index scan.
+		{filters: "v @@ 'a:A'", ok: true, tight: false, unique: true, remainingFilters: "v @@ 'a:A'"},
+		{filters: "v @@ 'a <-> b'", ok: true, tight: false, unique: true, remainingFilters: "v @@ 'a <-> b'"},
+		{filters: "v @@ 'a & !b'", ok: true, tight: false, unique: true, remainingFilters: "v @@ 'a & !b'"},
+		// Queries which can match tsvectors which contain none of their lexemes
+		// cannot use the index.
+		{filters: "v @@ '!a'", ok: false},
+		{filters: "v @@ 'a | !b'", ok: false},
+
+		// AND and OR of two @@ filters behave as expected.
+		{filters: "v @@ 'a' AND v @@ 'b'", ok: true, tight: true, unique: true},
+		{filters: "v @@ 'a' OR v @@ 'b'", ok: true, tight: true, unique: false},
+		{filters: "v @@ 'a' AND v @@ '!b'", ok: true, tight: false, unique: true, remainingFilters: "v @@ '!b'"},
+		{filters: "v @@ 'a' OR v @@ '!b'", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Logf("test case: %v", tc)
+		filters := testutils.BuildFilters(t, &f, &semaCtx, evalCtx, tc.filters)
+
+		// We're not testing that the correct SpanExpression is returned here;
+		// that is tested elsewhere. This is just testing that we are constraining
+		// the index when we expect to and we have the correct values for tight,
+		// unique, and remainingFilters.
+		spanExpr, _, remainingFilters, _, ok := invertedidx.TryFilterInvertedIndex(
+			context.Background(),
+			evalCtx,
+			&f,
+			filters,
+			nil, /* optionalFilters */
+			tab,
+			md.Table(tab).Index(tsvectorOrd),
+			nil, /* computedColumns */
+		)
+		if tc.ok != ok {
+			t.Fatalf("expected %v, got %v", tc.ok, ok)
+		}
+		if !ok {
+			continue
+		}
+
+		if tc.tight != spanExpr.Tight {
+			t.Fatalf("For (%s), expected tight=%v, but got %v", tc.filters, tc.tight, spanExpr.Tight)
+		}
+		if tc.unique != spanExpr.Unique {
+			t.Fatalf("For (%s), expected unique=%v, but got %v", tc.filters, tc.unique, spanExpr.Unique)
+		}
+
+		if remainingFilters == nil {
+			if tc.remainingFilters != "" {
+				t.Fatalf("For (%s), expected remainingFilters=%s, got <nil>", tc.filters, tc.remainingFilters)
+			}
+			continue
+		}
+		if tc.remainingFilters == "" {
+			t.Fatalf("For (%s), expected remainingFilters=<nil>, got %v", tc.filters, remainingFilters)
+		}
+		expRemainingFilters := testutils.BuildFilters(t, &f, &semaCtx, evalCtx, tc.remainingFilters)
+		if remainingFilters.String() != expRemainingFilters.String() {
+			t.Errorf("For (%s), expected remainingFilters=%v, got %v", tc.filters, expRemainingFilters, remainingFilters)
+		}
+	}
+}
@@ -664,6 +664,9 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			}
 			if t.CanaryCol != 0 {
 				f.formatRelColList(e, tp, "canary column:", opt.ColList{t.CanaryCol})
+				if t.MergeDeleteCol != 0 {
+					f.formatRelColList(e, tp, "merge delete column:", opt.ColList{t.MergeDeleteCol})
+				}
 				f.formatOptionalColList(e, tp, "fetch columns:", t.FetchCols)
 				f.formatMutationCols(e, tp, "insert-mapping:", t.InsertCols, t.Table)
 				f.formatMutationCols(e, tp, "update-mapping:", t.UpdateCols, t.Table)
@@ -1189,7 +1192,15 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *UniqueChecksItem:
 		tab := f.Memo.metadata.TableMeta(t.Table)
-		constraint := tab.Table.Unique(t.CheckOrdinal)
+		var constraint interface {
+			ColumnCount() int
+			ColumnOrdinal(tab cat.Table, i int) int
+		}
+		if t.Exclusion {
+			constraint = tab.Table.Exclusion(t.CheckOrdinal)
+		} else {
+			constraint = tab.Table.Unique(t.CheckOrdinal)
+		}
 		fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
 		for i := 0; i < constraint.ColumnCount(); i++ {
 			if i > 0 {
@@ -1199,6 +1210,12 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 			f.Buffer.WriteString(string(col.ColName()))
 		}
 		f.Buffer.WriteByte(')')
+		if t.Exclusion {
+			f.Buffer.WriteString(" exclusion")
+		}
+		if t.Deferrable {
+			f.Buffer.WriteString(" deferrable")
+		}
 
 	case *FKChecksItem:
 		origin := f.Memo.metadata.TableMeta(t.OriginTable)
@@ -1231,6 +1248,9 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 			f.Buffer.WriteString(string(col.ColName()))
 		}
 		f.Buffer.WriteByte(')')
+		if t.Deferrable {
+			f.Buffer.WriteString(" deferrable")
+		}
 
 	default:
 		private = scalar.Private()
@@ -513,7 +513,7 @@ func checkForeignKeyCase(
 		leftBaseTable := md.Table(leftTableID)
 		for i, cnt := 0, leftBaseTable.OutboundForeignKeyCount(); i < cnt; i++ {
 			fk := leftBaseTable.OutboundForeignKey(i)
-			if !fk.Validated() {
+			if !fk.Validated() || fk.Deferrable() {
 				// The data is not guaranteed to follow the foreign key constraint.
 				continue
 			}
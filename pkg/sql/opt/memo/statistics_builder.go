@@ -2458,13 +2458,11 @@ func (sb *statisticsBuilder) buildProjectSet(
 	// children.
 	var zipRowCount float64
 	for i := range projectSet.Zip {
-		if fn, ok := projectSet.Zip[i].Fn.(*FunctionExpr); ok {
-			if fn.Overload.IsGenerator() {
-				// TODO(rytaft): We may want to estimate the number of rows based on
-				// the type of generator function and its parameters.
-				zipRowCount = unknownGeneratorRowCount
-				break
-			}
+		if isGeneratorZipItem(&projectSet.Zip[i]) {
+			// TODO(rytaft): We may want to estimate the number of rows based on
+			// the type of generator function and its parameters.
+			zipRowCount = unknownGeneratorRowCount
+			break
 		}
 
 		// A scalar function generates one row.
@@ -2478,6 +2476,18 @@ func (sb *statisticsBuilder) buildProjectSet(
 	sb.finalizeFromCardinality(relProps)
 }
 
+// isGeneratorZipItem returns true if the function of the zip item is a
+// set-generating function, either built-in or user-defined.
+func isGeneratorZipItem(item *ZipItem) bool {
+	switch fn := item.Fn.(type) {
+	case *FunctionExpr:
+		return fn.Overload.IsGenerator()
+	case *UDFExpr:
+		return fn.SetReturning
+	}
+	return false
+}
+
 func (sb *statisticsBuilder) colStatProjectSet(
 	colSet opt.ColSet, projectSet *ProjectSetExpr,
 ) *props.ColumnStatistic {
@@ -2515,7 +2525,7 @@ func (sb *statisticsBuilder) colStatProjectSet(
 		for i := range projectSet.Zip {
 			item := &projectSet.Zip[i]
 			if item.Cols.ToSet().Intersects(reqZipCols) {
-				if fn, ok := item.Fn.(*FunctionExpr); ok && fn.Overload.IsGenerator() {
+				if isGeneratorZipItem(item) {
 					// The columns(s) contain a generator function.
 					// TODO(rytaft): We may want to determine which generator function the
 					// requested columns correspond to, and estimate the distinct count and
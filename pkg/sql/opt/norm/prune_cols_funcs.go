@@ -78,6 +78,9 @@ func (c *CustomFuncs) neededMutationCols(
 	if private.CanaryCol != 0 {
 		cols.Add(private.CanaryCol)
 	}
+	if private.MergeDeleteCol != 0 {
+		cols.Add(private.MergeDeleteCol)
+	}
 
 	if private.WithID != 0 {
 		for i := range uniqueChecks {
@@ -223,6 +226,14 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 			}
 		}
 
+		// An Upsert built for a MERGE statement may also delete matched rows, so
+		// it needs the same strict key columns as a Delete.
+		if op == opt.UpsertOp && private.MergeDeleteCol != 0 {
+			for i, n := 0, tabMeta.Table.DeletableIndexCount(); i < n; i++ {
+				cols.UnionWith(tabMeta.IndexKeyColumnsMapInverted(i))
+			}
+		}
+
 	case opt.DeleteOp:
 		// Add in all strict key columns from all indexes, since these are needed
 		// to compose the keys of rows to delete. Include mutation indexes, since
@@ -70,6 +70,19 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// ProcedureSegment is a control knob: it is the index of the segment of
+	// the body of a procedure invoked by a CALL statement that should be built.
+	// The segments of a procedure body are separated by COMMIT and ROLLBACK
+	// statements.
+	ProcedureSegment int
+
+	// ProcedureArgs is a control knob: it holds the values of the arguments of
+	// a CALL statement that invokes a procedure with COMMIT or ROLLBACK
+	// statements. If it is nil when such a statement is built, the arguments
+	// are evaluated and ProcedureArgs is set to their values, which must be
+	// passed back when the following segments of the procedure are built.
+	ProcedureArgs tree.Datums
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
@@ -84,6 +97,11 @@ type Builder struct {
 	// statements.
 	DisableMemoReuse bool
 
+	// ProcedureTxnControl is set to the COMMIT or ROLLBACK statement that ends
+	// the built segment of a procedure invoked by a CALL statement. It is nil if
+	// the built segment is the last one in the body of the procedure.
+	ProcedureTxnControl tree.Statement
+
 	factory *norm.Factory
 	stmt    tree.Statement
 
@@ -124,6 +142,11 @@ type Builder struct {
 	// are disabled and only statements whitelisted are allowed.
 	insideFuncDef bool
 
+	// If set, we are processing a procedure definition; in this case mutation
+	// statements are allowed in addition to the statements allowed in a
+	// function definition.
+	insideProcDef bool
+
 	// If set, we are collecting view dependencies in schemaDeps. This can only
 	// happen inside view/function definitions.
 	//
@@ -281,7 +304,7 @@ func (b *Builder) buildStmt(
 	if b.insideViewDef {
 		// A blocklist of statements that can't be used from inside a view.
 		switch stmt := stmt.(type) {
-		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
+		case *tree.Delete, *tree.Insert, *tree.Update, *tree.Merge, *tree.CreateTable, *tree.CreateView,
 			*tree.Split, *tree.Unsplit, *tree.Relocate, *tree.RelocateRange,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions,
 			*tree.CreateFunction:
@@ -295,6 +318,10 @@ func (b *Builder) buildStmt(
 	if b.insideFuncDef {
 		switch stmt := stmt.(type) {
 		case *tree.Select:
+		case *tree.Insert, *tree.Update, *tree.Delete:
+			if !b.insideProcDef {
+				panic(unimplemented.Newf("user-defined functions", "%s usage inside a function definition", stmt.StatementTag()))
+			}
 		default:
 			panic(unimplemented.Newf("user-defined functions", "%s usage inside a function definition", stmt.StatementTag()))
 		}
@@ -322,6 +349,11 @@ func (b *Builder) buildStmt(
 			return b.buildUpdate(stmt, inScope)
 		})
 
+	case *tree.Merge:
+		return b.processWiths(stmt.With, inScope, func(inScope *scope) *scope {
+			return b.buildMerge(stmt, inScope)
+		})
+
 	case *tree.CreateTable:
 		return b.buildCreateTable(stmt, inScope)
 
@@ -331,6 +363,9 @@ func (b *Builder) buildStmt(
 	case *tree.CreateFunction:
 		return b.buildCreateFunction(stmt, inScope)
 
+	case *tree.Call:
+		return b.buildCall(stmt, inScope)
+
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
 
@@ -0,0 +1,191 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// buildCall builds a CALL statement, which invokes a procedure. The result of
+// the statement is a single row with a column for each OUT argument of the
+// procedure, or a single void column if there are none.
+//
+// The body of a procedure is divided into segments by COMMIT and ROLLBACK
+// statements. Only the segment selected by b.ProcedureSegment is built; the
+// connExecutor is responsible for ending the transaction after each segment
+// and executing the CALL statement again for the next one. When the statement
+// is only prepared, the last segment is built, since its result is the one
+// returned to the client.
+func (b *Builder) buildCall(c *tree.Call, inScope *scope) (outScope *scope) {
+	// The built expression depends on the segment of the procedure, so it
+	// cannot be reused.
+	b.DisableMemoReuse = true
+
+	texpr := inScope.resolveType(c.Proc, types.Any)
+	f, ok := texpr.(*tree.FuncExpr)
+	if !ok {
+		panic(errors.AssertionFailedf("expected a procedure invocation, found %T", texpr))
+	}
+	def, err := f.Func.Resolve(b.ctx, b.semaCtx.SearchPath, b.semaCtx.FunctionResolver)
+	if err != nil {
+		panic(err)
+	}
+	o := f.ResolvedOverload()
+	if !o.IsProcedure {
+		panic(errors.WithHint(
+			pgerror.Newf(pgcode.WrongObjectType, "%s is not a procedure", def.Name),
+			"To call a function, use SELECT.",
+		))
+	}
+
+	// Build the input expressions. The arguments of a procedure whose body is
+	// divided into segments are evaluated only once, when the first segment is
+	// built, so that every segment sees the same values. They are not evaluated
+	// when the statement is only prepared.
+	var input memo.ScalarListExpr
+	if len(f.Exprs) > 0 {
+		input = make(memo.ScalarListExpr, len(f.Exprs))
+		if !b.evalCtx.PrepareOnly && procedureHasTxnControl(o.Body) {
+			if b.ProcedureArgs == nil {
+				b.ProcedureArgs = b.evalProcedureArgs(f.Exprs)
+			}
+			for i, pexpr := range f.Exprs {
+				input[i] = b.factory.ConstructConstVal(
+					b.ProcedureArgs[i], pexpr.(tree.TypedExpr).ResolvedType(),
+				)
+			}
+		} else {
+			for i, pexpr := range f.Exprs {
+				input[i] = b.buildScalar(
+					pexpr.(tree.TypedExpr),
+					inScope,
+					nil, /* outScope */
+					nil, /* outCol */
+					nil, /* colRefs */
+				)
+			}
+		}
+	}
+	routine := b.buildRoutine(def.Name, o, input, f.ResolvedType())
+	typ := routine.DataType()
+
+	// Project the result of the procedure.
+	rowScope := inScope.push()
+	rowScope.expr = b.factory.ConstructNoColsRow()
+	outScope = rowScope.push()
+	col := b.synthesizeColumn(outScope, scopeColName(tree.Name(def.Name)), typ, nil /* expr */, routine)
+	b.constructProjectForScope(rowScope, outScope)
+
+	// The result of a procedure with OUT arguments is a tuple, which is
+	// expanded into a column for each argument.
+	if typ.Family() == types.TupleFamily {
+		tupleScope := outScope
+		outScope = tupleScope.push()
+		for i, label := range typ.TupleLabels() {
+			elem := b.factory.ConstructColumnAccess(
+				b.factory.ConstructVariable(col.id), memo.TupleOrdinal(i),
+			)
+			b.synthesizeColumn(
+				outScope, scopeColName(tree.Name(label)), typ.TupleContents()[i], nil /* expr */, elem,
+			)
+		}
+		b.constructProjectForScope(tupleScope, outScope)
+	}
+	return outScope
+}
+
+// evalProcedureArgs evaluates the arguments of a CALL statement.
+func (b *Builder) evalProcedureArgs(exprs tree.Exprs) tree.Datums {
+	args := make(tree.Datums, len(exprs))
+	for i, expr := range exprs {
+		// Subqueries and user-defined functions are built into the memo, so
+		// they cannot be evaluated here.
+		_, err := tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+			switch t := expr.(type) {
+			case *subquery:
+				return false, expr, unimplemented.New("CALL",
+					"subqueries in the arguments of a procedure with COMMIT or ROLLBACK")
+			case *tree.FuncExpr:
+				if t.ResolvedOverload() != nil && t.ResolvedOverload().IsUDF {
+					return false, expr, unimplemented.New("CALL",
+						"user-defined functions in the arguments of a procedure with COMMIT or ROLLBACK")
+				}
+			}
+			return true, expr, nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		args[i], err = eval.Expr(b.ctx, b.evalCtx, expr.(tree.TypedExpr))
+		if err != nil {
+			panic(err)
+		}
+	}
+	return args
+}
+
+// procedureHasTxnControl returns true if the body of a procedure contains
+// COMMIT or ROLLBACK statements, which divide it into segments.
+func procedureHasTxnControl(body string) bool {
+	stmts, err := parser.Parse(body)
+	if err != nil {
+		panic(err)
+	}
+	for i := range stmts {
+		if isProcedureTxnControl(stmts[i].AST) {
+			return true
+		}
+	}
+	return false
+}
+
+// procedureSegment returns the statements of the given segment of the body of
+// a procedure, along with the COMMIT or ROLLBACK statement that ends the
+// segment. The returned statement is nil if the segment is the last one. A
+// negative segment selects the last segment.
+func procedureSegment(
+	name string, stmts []tree.Statement, segment int,
+) (segmentStmts []tree.Statement, txnControl tree.Statement) {
+	if segment < 0 {
+		for i := len(stmts) - 1; i >= 0; i-- {
+			if isProcedureTxnControl(stmts[i]) {
+				return stmts[i+1:], nil
+			}
+		}
+		return stmts, nil
+	}
+	start := 0
+	for i, stmt := range stmts {
+		if !isProcedureTxnControl(stmt) {
+			continue
+		}
+		if segment == 0 {
+			return stmts[start:i], stmt
+		}
+		segment--
+		start = i + 1
+	}
+	if segment != 0 {
+		// The procedure was replaced with one with fewer segments while it was
+		// being executed.
+		panic(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"procedure %s was modified during its execution", name))
+	}
+	return stmts[start:], nil
+}
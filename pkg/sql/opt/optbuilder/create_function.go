@@ -11,12 +11,15 @@
 package optbuilder
 
 import (
+	"fmt"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -32,10 +35,6 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 			panic(unimplemented.New("CREATE FUNCTION", "cross-db references not supported"))
 		}
 	}
-	if cf.ReturnType.IsSet {
-		panic(unimplemented.NewWithIssue(86391, "user-defined functions with SETOF return types are not supported"))
-	}
-
 	sch, resName := b.resolveSchemaForCreateFunction(&cf.FuncName)
 	schID := b.factory.Metadata().AddSchema(sch)
 	cf.FuncName.ObjectNamePrefix = resName
@@ -68,11 +67,26 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		panic(err)
 	}
 
+	// Procedures only accept a body and a language. They are always volatile
+	// and called on NULL input.
+	if cf.IsProcedure {
+		for _, option := range cf.Options {
+			switch option.(type) {
+			case tree.FunctionBodyStr, tree.FunctionLanguage:
+			default:
+				panic(pgerror.New(pgcode.InvalidFunctionDefinition, "invalid attribute in procedure definition"))
+			}
+		}
+		b.insideProcDef = true
+		defer func() { b.insideProcDef = false }()
+	}
+
 	// Look for function body string from function options.
 	// Note that function body can be an empty string.
 	funcBodyFound := false
 	languageFound := false
 	var funcBodyStr string
+	var language tree.FunctionLanguage
 	for _, option := range cf.Options {
 		switch opt := option.(type) {
 		case tree.FunctionBodyStr:
@@ -80,6 +94,7 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 			funcBodyStr = string(opt)
 		case tree.FunctionLanguage:
 			languageFound = true
+			language = opt
 		}
 	}
 
@@ -89,6 +104,9 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 	if !languageFound {
 		panic(pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified"))
 	}
+	if cf.ReturnType.IsSet && language != tree.FunctionLangPLpgSQL {
+		panic(unimplemented.NewWithIssue(86391, "user-defined functions with SETOF return types are not supported"))
+	}
 
 	// Track the dependencies in the arguments, return type, and statements in
 	// the function body.
@@ -98,7 +116,12 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 	// bodyScope is the base scope for each statement in the body. We add the
 	// named arguments to the scope so that references to them in the body can
 	// be resolved.
+	// OUT arguments are not inputs of the function, so they are not added to
+	// the scope. Instead, they determine the return type of the function.
 	bodyScope := b.allocScope()
+	var outTypes []*types.T
+	var outLabels []string
+	inputOrd := 0
 	for i := range cf.Args {
 		arg := &cf.Args[i]
 		typ, err := tree.ResolveType(b.ctx, arg.Type, b.semaCtx.TypeResolver)
@@ -106,10 +129,22 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 			panic(err)
 		}
 
-		// Add the argument to the base scope of the body.
-		argColName := funcArgColName(arg.Name, i)
-		col := b.synthesizeColumn(bodyScope, argColName, typ, nil /* expr */, nil /* scalar */)
-		col.setArgOrd(i)
+		if arg.Class == tree.FunctionArgOut || arg.Class == tree.FunctionArgInOut {
+			label := string(arg.Name)
+			if label == "" {
+				label = fmt.Sprintf("column%d", len(outTypes)+1)
+			}
+			outTypes = append(outTypes, typ)
+			outLabels = append(outLabels, label)
+		}
+
+		// Add the input argument to the base scope of the body.
+		if arg.Class != tree.FunctionArgOut {
+			argColName := funcArgColName(arg.Name, inputOrd)
+			col := b.synthesizeColumn(bodyScope, argColName, typ, nil /* expr */, nil /* scalar */)
+			col.setArgOrd(inputOrd)
+			inputOrd++
+		}
 
 		// Collect the user defined type dependencies.
 		typeIDs, err := typedesc.GetTypeDescriptorClosure(typ)
@@ -121,6 +156,34 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		}
 	}
 
+	if len(outTypes) > 0 && language == tree.FunctionLangPLpgSQL {
+		panic(unimplemented.New("CREATE FUNCTION", "OUT arguments in PL/pgSQL routines"))
+	}
+
+	// The return type of a procedure and of a function with OUT arguments is
+	// determined by the OUT arguments.
+	if cf.IsProcedure || len(outTypes) > 0 {
+		outType := types.Void
+		if len(outTypes) == 1 && !cf.IsProcedure {
+			outType = outTypes[0]
+		} else if len(outTypes) > 0 {
+			outType = types.MakeLabeledTuple(outTypes, outLabels)
+		}
+		if !cf.IsProcedure && cf.ReturnType.Type != nil {
+			typ, err := tree.ResolveType(b.ctx, cf.ReturnType.Type, b.semaCtx.TypeResolver)
+			if err != nil {
+				panic(err)
+			}
+			if !typ.Equivalent(outType) {
+				panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"function result type must be %s because of OUT parameters", outType.SQLStandardName()))
+			}
+		}
+		cf.ReturnType.Type = outType
+	} else if cf.ReturnType.Type == nil {
+		panic(pgerror.New(pgcode.InvalidFunctionDefinition, "function result type must be specified"))
+	}
+
 	// Collect the user defined type dependency of the return type.
 	funcReturnType, err := tree.ResolveType(b.ctx, cf.ReturnType.Type, b.semaCtx.TypeResolver)
 	if err != nil {
@@ -134,31 +197,81 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		typeDeps.Add(int(typeID))
 	}
 
+	// Trigger functions are only called by triggers, which supply the NEW and
+	// OLD rows and the TG_ variables. As in Postgres, their bodies are only
+	// parsed here; references in the body are resolved when the trigger fires.
+	isTriggerFunc := funcReturnType.Family() == types.TriggerFamily
+	if isTriggerFunc {
+		if language != tree.FunctionLangPLpgSQL {
+			panic(pgerror.New(pgcode.InvalidFunctionDefinition, "SQL functions cannot return type trigger"))
+		}
+		if cf.ReturnType.IsSet {
+			panic(pgerror.New(pgcode.InvalidFunctionDefinition, "trigger functions cannot return a set"))
+		}
+		if len(cf.Args) > 0 {
+			panic(errors.WithHint(
+				pgerror.New(pgcode.InvalidFunctionDefinition, "trigger functions cannot have declared arguments"),
+				"The arguments of the trigger can be accessed through TG_NARGS and TG_ARGV instead.",
+			))
+		}
+	}
+
+	fmtCtx := tree.NewFmtCtx(tree.FmtSimple)
+	if isTriggerFunc {
+		block, err := plpgsqlparser.Parse(funcBodyStr)
+		if err != nil {
+			panic(err)
+		}
+		fmtCtx.FormatNode(block)
+	} else if language == tree.FunctionLangPLpgSQL {
+		// Validate the body by compiling it, which also collects the
+		// dependencies of its statements.
+		block, err := plpgsqlparser.Parse(funcBodyStr)
+		if err != nil {
+			panic(err)
+		}
+		b.buildPLpgSQL(cf.FuncName.Object(), block, bodyScope, funcReturnType, cf.ReturnType.IsSet)
+		deps = append(deps, b.schemaDeps...)
+		typeDeps.UnionWith(b.schemaTypeDeps)
+
+		// Format the body with qualified datasource names.
+		fmtCtx.FormatNode(block)
+	}
+
 	// Parse the function body.
-	stmts, err := parser.Parse(funcBodyStr)
-	if err != nil {
-		panic(err)
+	var stmts parser.Statements
+	if language != tree.FunctionLangPLpgSQL {
+		stmts, err = parser.Parse(funcBodyStr)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	// Validate each statement and collect the dependencies.
-	fmtCtx := tree.NewFmtCtx(tree.FmtSimple)
+	var resultCols []scopeColumn
 	for i, stmt := range stmts {
+		// COMMIT and ROLLBACK statements separate the segments of the body of a
+		// procedure. Each segment is executed in its own transaction.
+		if cf.IsProcedure && isProcedureTxnControl(stmt.AST) {
+			formatFuncBodyStmt(fmtCtx, stmt.AST, i > 0 /* newLine */)
+			resultCols = nil
+			continue
+		}
+
+		// Each statement in the body of a procedure is executed separately, so
+		// mutations of the same table in different statements do not conflict.
+		if cf.IsProcedure {
+			b.areAllTableMutationsSimpleInserts = nil
+		}
 		stmtScope := b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
 
 		// Format the statements with qualified datasource names.
 		formatFuncBodyStmt(fmtCtx, stmt.AST, i > 0 /* newLine */)
 
-		// Validate that the result type of the last statement matches the
-		// return type of the function.
-		if i == len(stmts)-1 {
-			// TODO(mgartner): stmtScope.cols does not describe the result
-			// columns of the statement. We should use physical.Presentation
-			// instead.
-			err := validateReturnType(funcReturnType, stmtScope.cols)
-			if err != nil {
-				panic(err)
-			}
-		}
+		// TODO(mgartner): stmtScope.cols does not describe the result
+		// columns of the statement. We should use physical.Presentation
+		// instead.
+		resultCols = stmtScope.cols
 
 		deps = append(deps, b.schemaDeps...)
 		typeDeps.UnionWith(b.schemaTypeDeps)
@@ -167,6 +280,19 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 		b.schemaTypeDeps = util.FastIntSet{}
 	}
 
+	// Validate that the result type of the last statement matches the return
+	// type of the function. The result of a procedure with a single OUT
+	// argument is the only column of its last statement.
+	if len(stmts) > 0 {
+		expectedType := funcReturnType
+		if cf.IsProcedure && len(outTypes) == 1 {
+			expectedType = outTypes[0]
+		}
+		if err := validateReturnType(expectedType, resultCols); err != nil {
+			panic(err)
+		}
+	}
+
 	// Override the function body so that references are fully qualified.
 	for i, option := range cf.Options {
 		if _, ok := option.(tree.FunctionBodyStr); ok {
@@ -187,6 +313,16 @@ func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (
 	return outScope
 }
 
+// isProcedureTxnControl returns true if the given statement ends the
+// transaction of a segment of the body of a procedure.
+func isProcedureTxnControl(stmt tree.Statement) bool {
+	switch stmt.(type) {
+	case *tree.CommitTransaction, *tree.RollbackTransaction:
+		return true
+	}
+	return false
+}
+
 func formatFuncBodyStmt(fmtCtx *tree.FmtCtx, ast tree.Statement, newLine bool) {
 	if newLine {
 		fmtCtx.WriteString("\n")
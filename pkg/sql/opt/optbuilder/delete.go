@@ -77,8 +77,13 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 // buildDelete constructs a Delete operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildDelete(returning tree.ReturningExprs) {
+	// Invoke any BEFORE DELETE triggers, which may skip the rows to delete.
+	mb.buildBeforeTriggers(tree.TriggerEventDelete)
+
 	mb.buildFKChecksAndCascadesForDelete()
 
+	mb.buildAfterTriggers(tree.TriggerEventDelete)
+
 	// Project partial index DEL boolean columns.
 	mb.projectPartialIndexDelCols()
 
@@ -287,7 +287,8 @@ func (cb *onDeleteFastCascadeBuilder) Build(
 		mb.init(b, "delete", cb.childTable, tree.MakeUnqualifiedTableName(cb.childTable.Name()))
 
 		// Build the input to the delete mutation, which is simply a Scan with a
-		// Select on top.
+		// Select on top. The rows are locked for the same reason as in deletion
+		// checks (see deletionCheckLocking).
 		mb.fetchScope = b.buildScan(
 			b.addTable(cb.childTable, &mb.alias),
 			tableOrdinals(cb.childTable, columnKinds{
@@ -296,7 +297,7 @@ func (cb *onDeleteFastCascadeBuilder) Build(
 				includeInverted:  false,
 			}),
 			nil, /* indexFlags */
-			noRowLocking,
+			b.fkLockingIfWriteSkewTolerated(),
 			b.allocScope(),
 			true, /* disableNotVisibleIndex */
 		)
@@ -508,6 +509,8 @@ func (b *Builder) buildDeleteCascadeMutationInput(
 	bindingProps *props.Relational,
 	oldValues opt.ColList,
 ) (outScope *scope) {
+	// The rows of the child table are locked for the same reason as in
+	// deletion checks (see deletionCheckLocking).
 	outScope = b.buildScan(
 		b.addTable(childTable, childTableAlias),
 		tableOrdinals(childTable, columnKinds{
@@ -516,7 +519,7 @@ func (b *Builder) buildDeleteCascadeMutationInput(
 			includeInverted:  false,
 		}),
 		nil, /* indexFlags */
-		noRowLocking,
+		b.fkLockingIfWriteSkewTolerated(),
 		b.allocScope(),
 		true, /* disableNotVisibleIndex */
 	)
@@ -743,6 +746,8 @@ func (b *Builder) buildUpdateCascadeMutationInput(
 	oldValues opt.ColList,
 	newValues opt.ColList,
 ) (outScope *scope) {
+	// The rows of the child table are locked for the same reason as in
+	// deletion checks (see deletionCheckLocking).
 	outScope = b.buildScan(
 		b.addTable(childTable, childTableAlias),
 		tableOrdinals(childTable, columnKinds{
@@ -751,7 +756,7 @@ func (b *Builder) buildUpdateCascadeMutationInput(
 			includeInverted:  false,
 		}),
 		nil, /* indexFlags */
-		noRowLocking,
+		b.fkLockingIfWriteSkewTolerated(),
 		b.allocScope(),
 		true, /* disableNotVisibleIndex */
 	)
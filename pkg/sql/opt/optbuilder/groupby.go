@@ -100,6 +100,12 @@ type groupby struct {
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
 	buildingGroupingCols bool
+
+	// groupingSets is non-nil if the GROUP BY clause contains ROLLUP, CUBE or
+	// GROUPING SETS which expand to more than one grouping set. In that case,
+	// groupStrs maps to the grouping columns in aggOutScope, which are NULL in
+	// groups that they are not part of. See grouping_sets.go.
+	groupingSets *groupingSets
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -331,7 +337,11 @@ func (b *Builder) buildGroupingColumns(sel *tree.SelectClause, projectionsScope,
 	g := fromScope.groupby
 
 	// The "from" columns are visible to any grouping expressions.
-	b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
+	sets := b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
+	if len(sets) > 1 {
+		b.buildGroupingSetCols(g, sets)
+		return
+	}
 
 	// Copy the grouping columns to the aggOutScope.
 	g.aggOutScope.appendColumns(g.groupingCols())
@@ -429,13 +439,45 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
+	input, ordering := g.aggInScope.expr, g.aggInScope.ordering
+	groupByCols := aggCols
+	var finalProjections memo.ProjectionsExpr
+	if g.groupingSets != nil {
+		// Aggregate the input by all the grouping columns first if possible,
+		// then expand each row into one row per grouping set, and group by the
+		// grouping set ID in addition to the grouping columns.
+		if preAgg, outerCols, final, ok := b.preAggregateGroupingSets(g, aggCols); ok {
+			input, groupByCols, finalProjections = preAgg, outerCols, final
+		}
+		aggs := make([]opt.ScalarExpr, len(groupByCols))
+		for i := range groupByCols {
+			aggs[i] = groupByCols[i].scalar
+		}
+		input = b.constructGroupingSetsInput(g, input, aggs)
+		for i := range groupByCols {
+			groupByCols[i].scalar = aggs[i]
+		}
+		groupingColSet = g.groupingSets.groupingColSet()
+		ordering = nil
+	}
+
 	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr,
+		input,
 		groupingColSet,
-		aggCols,
-		g.aggInScope.ordering,
+		groupByCols,
+		ordering,
 	)
 
+	// Compute the results of the aggregates which were not directly
+	// aggregated from the aggregations of the pre-aggregated grouping sets.
+	if len(finalProjections) > 0 {
+		passthrough := g.aggOutScope.colSet()
+		for i := range finalProjections {
+			passthrough.Remove(finalProjections[i].Col)
+		}
+		g.aggOutScope.expr = b.factory.ConstructProject(g.aggOutScope.expr, finalProjections, passthrough)
+	}
+
 	// Wrap with having filter if it exists.
 	if having != nil {
 		input := g.aggOutScope.expr
@@ -480,7 +522,9 @@ func (b *Builder) buildHaving(having tree.TypedExpr, fromScope *scope) opt.Scala
 
 // buildGroupingList builds a set of memo groups that represent a list of
 // GROUP BY expressions, adding the group-by expressions as columns to
-// aggInScope and populating groupStrs.
+// aggInScope and populating groupStrs. It returns the grouping sets of the
+// GROUP BY clause, each of which is a set of columns in aggInScope. A GROUP BY
+// clause without ROLLUP, CUBE or GROUPING SETS has a single grouping set.
 //
 // groupBy   The given GROUP BY expressions.
 // selects   The select expressions are needed in case one of the GROUP BY
@@ -492,7 +536,7 @@ func (b *Builder) buildHaving(having tree.TypedExpr, fromScope *scope) opt.Scala
 // fromScope The scope for the input to the aggregation (the FROM clause).
 func (b *Builder) buildGroupingList(
 	groupBy tree.GroupBy, selects tree.SelectExprs, projectionsScope *scope, fromScope *scope,
-) {
+) (sets []opt.ColSet) {
 	g := fromScope.groupby
 	g.groupStrs = make(groupByStrSet, len(groupBy))
 	if g.aggInScope.cols == nil {
@@ -508,15 +552,19 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	sets = []opt.ColSet{{}}
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		itemSets := b.buildGroupingSets(e, selects, projectionsScope, fromScope)
+		sets = crossGroupingSets(sets, itemSets)
 	}
 	g.buildingGroupingCols = false
+	return sets
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. It returns the set of aggInScope columns
+// corresponding to the expression.
 //
 // groupBy          The given GROUP BY expression.
 // selects          The select expressions are needed in case the GROUP BY
@@ -536,7 +584,7 @@ func (b *Builder) buildGroupingList(
 //	as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) (cols opt.ColSet) {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -634,7 +682,8 @@ func (b *Builder) buildGrouping(
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+			cols.Add(col.id)
 			continue
 		}
 
@@ -644,7 +693,9 @@ func (b *Builder) buildGrouping(
 		col := aggInScope.addColumn(scopeColName(tree.Name(alias)), e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
 		fromScope.groupby.groupStrs[exprStr] = col
+		cols.Add(col.id)
 	}
+	return cols
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
@@ -914,6 +965,10 @@ func newGroupingError(name tree.Name) error {
 // table. In that case, we can allow col as an "implicit" grouping column, even
 // if it is not specified in the query.
 func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bool {
+	if g.groupingSets != nil {
+		// The PK columns are not part of every grouping set.
+		return false
+	}
 	md := b.factory.Metadata()
 	colMeta := md.ColumnMeta(colID)
 	if colMeta.Table == 0 {
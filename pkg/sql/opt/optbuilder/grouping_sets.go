@@ -0,0 +1,531 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+// This file has builder code specific to GROUP BY clauses with ROLLUP, CUBE
+// and GROUPING SETS, which compute aggregations over several groupings of the
+// same input in a single query.
+//
+// We build such queries with a single GroupBy operator which has a single
+// (expanded) input. Each grouping set is assigned an ID, and the input of the
+// aggregation is built using:
+//
+//  - the usual pre-projection, which renders all the grouping columns and the
+//    arguments to aggregate functions.
+//
+//  - an expansion: a cross join of the pre-projection with a Values expression
+//    that contains one row per grouping set ID. Every input row is thereby
+//    repeated once for each grouping set.
+//
+//  - a projection which replaces each grouping column with NULL in the rows of
+//    the grouping sets that the column is not part of.
+//
+// The GroupBy then groups by the projected grouping columns and the grouping
+// set ID. For example:
+//
+//   SELECT a, b, sum(c) FROM t GROUP BY ROLLUP (a, b)
+//
+//   pre-projection: a, b, c
+//   expansion:      a, b, c, grouping_set_id IN (0, 1, 2)
+//   projection:     CASE WHEN grouping_set_id IN (2) THEN NULL ELSE a END AS a,
+//                   CASE WHEN grouping_set_id IN (1, 2) THEN NULL ELSE b END AS b
+//   aggregation:    group by a, b, grouping_set_id, calculate sum(c)
+//
+// Before the expansion, the pre-projection is aggregated by all the grouping
+// columns when every aggregate can be computed from partial aggregates over
+// finer groups (see preAggregateGroupingSets). The input is then read in a
+// single pass, and the expansion only repeats the groups of the finest
+// grouping rather than every input row. For the example above:
+//
+//   pre-aggregation: group by a, b, calculate sum(c) AS s
+//   expansion:       a, b, s, grouping_set_id IN (0, 1, 2)
+//   aggregation:     group by a, b, grouping_set_id, calculate sum(s)
+//
+// Grouping sets which are empty produce a row even if the input is empty, like
+// a ScalarGroupBy. To support them, the expansion is built as a left join of
+// the grouping set IDs with the pre-projection instead. A NULL-extended row is
+// kept only for the empty grouping sets, and it is filtered out of every
+// aggregate using an AggFilter.
+//
+// Aggregates with an ORDER BY clause are built as window functions over the
+// same expanded input, partitioned by the grouping columns and the grouping
+// set ID (see buildAggregationAsWindow).
+//
+// GROUPING(...) expressions are built as a function of the grouping set ID.
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+// maxCubeElements is the maximum number of elements of a CUBE, which expands
+// to 2^maxCubeElements grouping sets.
+const maxCubeElements = 12
+
+var errGroupingArgs = pgerror.New(pgcode.Grouping,
+	"arguments to GROUPING must be grouping expressions of the associated query level")
+
+// groupingSets stores information about the grouping sets of a GROUP BY
+// clause with ROLLUP, CUBE or GROUPING SETS.
+type groupingSets struct {
+	// sets contains the grouping columns in aggOutScope which are part of each
+	// grouping set. The ID of a grouping set is its index in sets.
+	sets []opt.ColSet
+
+	// inCols contains the grouping columns in aggInScope, and outCols the
+	// corresponding grouping columns in aggOutScope. The latter are NULL in the
+	// groups of grouping sets which they are not part of.
+	inCols  opt.ColList
+	outCols opt.ColList
+
+	// idCol is the column which contains the ID of the grouping set of each
+	// group.
+	idCol opt.ColumnID
+}
+
+// groupingColSet returns the grouping columns of the aggregation.
+func (gs *groupingSets) groupingColSet() opt.ColSet {
+	cols := gs.outCols.ToSet()
+	cols.Add(gs.idCol)
+	return cols
+}
+
+// buildGroupingSets builds the columns of a GROUP BY element and returns the
+// grouping sets that it expands to, as sets of aggInScope columns. A regular
+// expression expands to a single grouping set which contains its columns.
+func (b *Builder) buildGroupingSets(
+	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) []opt.ColSet {
+	aggInScope := fromScope.groupby.aggInScope
+	gs, ok := groupBy.(*tree.GroupingSet)
+	if !ok {
+		return []opt.ColSet{b.buildGrouping(groupBy, selects, projectionsScope, fromScope, aggInScope)}
+	}
+
+	var sets []opt.ColSet
+	switch gs.Type {
+	case tree.RollupGroupingSet:
+		// ROLLUP (a, b) expands to GROUPING SETS ((a, b), (a), ()).
+		sets = make([]opt.ColSet, len(gs.Exprs)+1)
+		for i, e := range gs.Exprs {
+			sets[i+1] = sets[i].Union(b.buildGrouping(e, selects, projectionsScope, fromScope, aggInScope))
+		}
+		for i, j := 0, len(sets)-1; i < j; i, j = i+1, j-1 {
+			sets[i], sets[j] = sets[j], sets[i]
+		}
+
+	case tree.CubeGroupingSet:
+		// CUBE (a, b) expands to GROUPING SETS ((a, b), (a), (b), ()).
+		if len(gs.Exprs) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.TooManyColumns,
+				"CUBE is limited to %d elements", maxCubeElements))
+		}
+		elems := make([]opt.ColSet, len(gs.Exprs))
+		for i, e := range gs.Exprs {
+			elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, aggInScope)
+		}
+		sets = make([]opt.ColSet, 0, 1<<len(elems))
+		for mask := 1<<len(elems) - 1; mask >= 0; mask-- {
+			var set opt.ColSet
+			for i := range elems {
+				if mask&(1<<(len(elems)-1-i)) != 0 {
+					set.UnionWith(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	case tree.ExplicitGroupingSets:
+		for _, e := range gs.Exprs {
+			sets = append(sets, b.buildGroupingSets(e, selects, projectionsScope, fromScope)...)
+			checkGroupingSetCount(len(sets))
+		}
+	}
+	return sets
+}
+
+// crossGroupingSets returns the grouping sets of two consecutive elements of a
+// GROUP BY clause, which is the cross product of their grouping sets.
+func crossGroupingSets(left, right []opt.ColSet) []opt.ColSet {
+	checkGroupingSetCount(len(left) * len(right))
+	res := make([]opt.ColSet, 0, len(left)*len(right))
+	for i := range left {
+		for j := range right {
+			res = append(res, left[i].Union(right[j]))
+		}
+	}
+	return res
+}
+
+func checkGroupingSetCount(n int) {
+	if n > tree.MaxGroupingSets {
+		panic(pgerror.Newf(pgcode.StatementTooComplex,
+			"too many grouping sets present (maximum %d)", tree.MaxGroupingSets))
+	}
+}
+
+// buildGroupingSetCols adds the grouping columns of a GROUP BY clause with
+// multiple grouping sets to aggOutScope. Unlike for a regular GROUP BY, these
+// are new columns rather than pass-through columns from aggInScope, because
+// they are NULL in the groups of grouping sets which they are not part of.
+// The grouping set ID column is added as well, and groupStrs is updated to map
+// to the new columns.
+func (b *Builder) buildGroupingSetCols(g *groupby, sets []opt.ColSet) {
+	gs := &groupingSets{sets: make([]opt.ColSet, len(sets))}
+
+	var outColMap opt.ColMap
+	groupingCols := g.groupingCols()
+	for i := range groupingCols {
+		inCol := &groupingCols[i]
+		if _, ok := outColMap.Get(int(inCol.id)); ok {
+			continue
+		}
+		outCol := b.synthesizeColumn(g.aggOutScope, inCol.name, inCol.typ, inCol.expr, nil /* scalar */)
+		outColMap.Set(int(inCol.id), int(outCol.id))
+		gs.inCols = append(gs.inCols, inCol.id)
+		gs.outCols = append(gs.outCols, outCol.id)
+	}
+	idCol := b.synthesizeColumn(
+		g.aggOutScope, scopeColName("").WithMetadataName("grouping_set_id"), types.Int, nil, nil,
+	)
+	gs.idCol = idCol.id
+
+	for i := range sets {
+		sets[i].ForEach(func(col opt.ColumnID) {
+			outCol, _ := outColMap.Get(int(col))
+			gs.sets[i].Add(opt.ColumnID(outCol))
+		})
+	}
+
+	// References to GROUP BY expressions must now resolve to the new columns.
+	for exprStr, col := range g.groupStrs {
+		outCol, _ := outColMap.Get(int(col.id))
+		g.groupStrs[exprStr] = g.aggOutScope.getColumn(opt.ColumnID(outCol))
+	}
+	g.groupingSets = gs
+}
+
+// preAggregateGroupingSets aggregates the pre-projection in aggInScope by all
+// the grouping columns of a query with grouping sets, so that the aggregates
+// of each grouping set can be computed from the aggregates of these finer
+// groups. It returns false if some aggregate in aggCols cannot be computed
+// this way, like an ordering-sensitive aggregate or a count(DISTINCT ...).
+//
+// Otherwise it returns the pre-aggregation, along with the aggregations to
+// compute over its expansion, and the projections which compute the results
+// of the aggregates in aggCols from the latter when they are not directly
+// aggregated. The aggregates of a count are added up, and its result
+// projected as 0 rather than NULL for an empty grouping set over an empty
+// input. The result of an avg is the sum of its partial sums divided by the
+// sum of its partial counts.
+func (b *Builder) preAggregateGroupingSets(
+	g *groupby, aggCols []scopeColumn,
+) (input memo.RelExpr, outerCols []scopeColumn, final memo.ProjectionsExpr, ok bool) {
+	gs := g.groupingSets
+	f := b.factory
+	md := f.Metadata()
+	if len(gs.inCols) == 0 {
+		return nil, nil, nil, false
+	}
+	for i := range aggCols {
+		if !canPreAggregate(aggCols[i].scalar) {
+			return nil, nil, nil, false
+		}
+	}
+	var hasEmptySet bool
+	for i := range gs.sets {
+		hasEmptySet = hasEmptySet || gs.sets[i].Empty()
+	}
+
+	var innerAggs memo.AggregationsExpr
+	addInner := func(agg opt.ScalarExpr) opt.ColumnID {
+		col := md.AddColumn("", agg.DataType())
+		innerAggs = append(innerAggs, f.ConstructAggregationsItem(agg, col))
+		return col
+	}
+	addOuter := func(agg opt.ScalarExpr) opt.ColumnID {
+		col := md.AddColumn("", agg.DataType())
+		outerCols = append(outerCols, scopeColumn{id: col, scalar: agg})
+		return col
+	}
+	var seen opt.ColSet
+	for i := range aggCols {
+		if seen.Contains(aggCols[i].id) {
+			continue
+		}
+		seen.Add(aggCols[i].id)
+
+		// Peel off the FILTER and DISTINCT of the aggregate, which apply to the
+		// partial aggregates.
+		agg := aggCols[i].scalar
+		var filter opt.ScalarExpr
+		if aggFilter, ok := agg.(*memo.AggFilterExpr); ok {
+			agg, filter = aggFilter.Input, aggFilter.Filter
+		}
+		distinct := false
+		if aggDistinct, ok := agg.(*memo.AggDistinctExpr); ok {
+			agg, distinct = aggDistinct.Input, true
+		}
+		wrap := func(agg opt.ScalarExpr) opt.ScalarExpr {
+			if distinct {
+				agg = f.ConstructAggDistinct(agg)
+			}
+			if filter != nil {
+				agg = f.ConstructAggFilter(agg, filter)
+			}
+			return agg
+		}
+
+		switch agg.Op() {
+		case opt.AvgOp:
+			arg := agg.Child(0).(opt.ScalarExpr)
+			sum := addOuter(f.ConstructSum(f.ConstructVariable(addInner(wrap(f.ConstructSum(arg))))))
+			count := addOuter(f.ConstructSumInt(f.ConstructVariable(addInner(wrap(f.ConstructCount(arg))))))
+			var divisor opt.ScalarExpr = f.ConstructVariable(count)
+			if md.ColumnMeta(sum).Type.Family() == types.FloatFamily {
+				divisor = f.ConstructCast(divisor, types.Float)
+			}
+			final = append(final, f.ConstructProjectionsItem(
+				f.ConstructDiv(f.ConstructVariable(sum), divisor), aggCols[i].id,
+			))
+
+		case opt.CountOp, opt.CountRowsOp:
+			count := f.ConstructSumInt(f.ConstructVariable(addInner(wrap(agg))))
+			if !hasEmptySet {
+				outerCols = append(outerCols, scopeColumn{id: aggCols[i].id, scalar: count})
+				continue
+			}
+			final = append(final, f.ConstructProjectionsItem(
+				f.ConstructCoalesce(memo.ScalarListExpr{
+					f.ConstructVariable(addOuter(count)), f.ConstructConstVal(tree.NewDInt(0), types.Int),
+				}),
+				aggCols[i].id,
+			))
+
+		default:
+			outer := b.constructMergeAggregate(agg.Op(), addInner(wrap(agg)))
+			outerCols = append(outerCols, scopeColumn{id: aggCols[i].id, scalar: outer})
+		}
+	}
+
+	input = f.ConstructGroupBy(g.aggInScope.expr, innerAggs, &memo.GroupingPrivate{
+		GroupingCols: gs.inCols.ToSet(),
+	})
+	return input, outerCols, final, true
+}
+
+// canPreAggregate returns true if the given aggregate, possibly wrapped in
+// AggFilter and AggDistinct operators, can be computed from its partial
+// aggregates over finer groups by preAggregateGroupingSets.
+func canPreAggregate(agg opt.ScalarExpr) bool {
+	if aggFilter, ok := agg.(*memo.AggFilterExpr); ok {
+		agg = aggFilter.Input
+	}
+	if aggDistinct, ok := agg.(*memo.AggDistinctExpr); ok {
+		// Duplicates across the finer groups would be counted again.
+		return opt.AggregateIgnoresDuplicates(aggDistinct.Input.Op()) &&
+			canPreAggregate(aggDistinct.Input)
+	}
+	switch agg.Op() {
+	case opt.AvgOp, opt.CountOp, opt.CountRowsOp, opt.BitAndAggOp, opt.BitOrAggOp,
+		opt.BoolAndOp, opt.BoolOrOp, opt.MaxOp, opt.MinOp, opt.SumOp, opt.SumIntOp, opt.XorAggOp:
+		return true
+	}
+	return false
+}
+
+// constructMergeAggregate constructs the aggregate which combines the partial
+// results in col of an aggregate with the given operator, which has a single
+// argument and is neither an avg nor a count. See opt.AggregatesCanMerge.
+func (b *Builder) constructMergeAggregate(op opt.Operator, col opt.ColumnID) opt.ScalarExpr {
+	v := b.factory.ConstructVariable(col)
+	switch op {
+	case opt.BitAndAggOp:
+		return b.factory.ConstructBitAndAgg(v)
+	case opt.BitOrAggOp:
+		return b.factory.ConstructBitOrAgg(v)
+	case opt.BoolAndOp:
+		return b.factory.ConstructBoolAnd(v)
+	case opt.BoolOrOp:
+		return b.factory.ConstructBoolOr(v)
+	case opt.MaxOp:
+		return b.factory.ConstructMax(v)
+	case opt.MinOp:
+		return b.factory.ConstructMin(v)
+	case opt.SumOp:
+		return b.factory.ConstructSum(v)
+	case opt.SumIntOp:
+		return b.factory.ConstructSumInt(v)
+	case opt.XorAggOp:
+		return b.factory.ConstructXorAgg(v)
+	}
+	panic(errors.AssertionFailedf("unexpected aggregate %s", redact.Safe(op)))
+}
+
+// constructGroupingSetsInput constructs the input of the GroupBy operator of
+// a query with grouping sets from the given input, which is either the
+// pre-projection in aggInScope or its pre-aggregation. See the comment at the
+// top of the file for details. The aggregates in aggs are replaced with
+// AggFilter operators if necessary.
+func (b *Builder) constructGroupingSetsInput(
+	g *groupby, input memo.RelExpr, aggs []opt.ScalarExpr,
+) memo.RelExpr {
+	gs := g.groupingSets
+	f := b.factory
+	md := f.Metadata()
+
+	// Construct the grouping set IDs, and find the IDs of the empty grouping
+	// sets.
+	ids := make(memo.ScalarListExpr, len(gs.sets))
+	var emptySets memo.ScalarListExpr
+	for i := range gs.sets {
+		ids[i] = b.constructGroupingSetID(i)
+		if gs.sets[i].Empty() {
+			emptySets = append(emptySets, ids[i])
+		}
+	}
+	rows := make(memo.ScalarListExpr, len(ids))
+	rowType := types.MakeTuple([]*types.T{types.Int})
+	for i := range ids {
+		rows[i] = f.ConstructTuple(memo.ScalarListExpr{ids[i]}, rowType)
+	}
+	values := f.ConstructValues(rows, &memo.ValuesPrivate{
+		Cols: opt.ColList{gs.idCol},
+		ID:   md.NextUniqueID(),
+	})
+
+	var expanded memo.RelExpr
+	var inputCol opt.ColumnID
+	if len(emptySets) == 0 {
+		expanded = f.ConstructInnerJoin(input, values, memo.TrueFilter, memo.EmptyJoinPrivate)
+	} else {
+		// Add a column which is NULL in the NULL-extended rows of the left join.
+		inputCol = md.AddColumn("grouping_set_input", types.Bool)
+		input = f.ConstructProject(
+			input,
+			memo.ProjectionsExpr{f.ConstructProjectionsItem(memo.TrueSingleton, inputCol)},
+			input.Relational().OutputCols,
+		)
+		expanded = f.ConstructLeftJoin(values, input, memo.TrueFilter, memo.EmptyJoinPrivate)
+		filter := f.ConstructOr(
+			f.ConstructIsNot(f.ConstructVariable(inputCol), memo.NullSingleton),
+			b.constructGroupingSetIDIn(gs.idCol, emptySets),
+		)
+		expanded = f.ConstructSelect(expanded, memo.FiltersExpr{f.ConstructFiltersItem(filter)})
+	}
+
+	// Replace the grouping columns with NULL in the rows of the grouping sets
+	// which they are not part of.
+	projections := make(memo.ProjectionsExpr, 0, len(gs.inCols))
+	for i, inCol := range gs.inCols {
+		outCol := gs.outCols[i]
+		var excluded memo.ScalarListExpr
+		for j := range gs.sets {
+			if !gs.sets[j].Contains(outCol) {
+				excluded = append(excluded, ids[j])
+			}
+		}
+		var scalar opt.ScalarExpr = f.ConstructVariable(inCol)
+		if len(excluded) > 0 {
+			scalar = f.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{f.ConstructWhen(
+					b.constructGroupingSetIDIn(gs.idCol, excluded), f.ConstructNull(md.ColumnMeta(inCol).Type),
+				)},
+				scalar,
+			)
+		}
+		projections = append(projections, f.ConstructProjectionsItem(scalar, outCol))
+	}
+
+	// The aggregates must ignore the NULL-extended rows.
+	if inputCol != 0 {
+		for i := range aggs {
+			agg, filterCol := aggs[i], inputCol
+			if aggFilter, ok := agg.(*memo.AggFilterExpr); ok {
+				filterCol = md.AddColumn("", types.Bool)
+				projections = append(projections, f.ConstructProjectionsItem(
+					f.ConstructAnd(aggFilter.Filter, f.ConstructVariable(inputCol)), filterCol,
+				))
+				agg = aggFilter.Input
+			}
+			aggs[i] = f.ConstructAggFilter(agg, f.ConstructVariable(filterCol))
+		}
+	}
+
+	return f.ConstructProject(expanded, projections, expanded.Relational().OutputCols)
+}
+
+// constructGroupingSetID constructs the ID of the i-th grouping set.
+func (b *Builder) constructGroupingSetID(i int) opt.ScalarExpr {
+	return b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(i)), types.Int)
+}
+
+// constructGroupingSetIDIn constructs an expression which is true if the
+// grouping set ID column is one of the given IDs.
+func (b *Builder) constructGroupingSetIDIn(
+	idCol opt.ColumnID, ids memo.ScalarListExpr,
+) opt.ScalarExpr {
+	typs := make([]*types.T, len(ids))
+	for i := range typs {
+		typs[i] = types.Int
+	}
+	return b.factory.ConstructIn(
+		b.factory.ConstructVariable(idCol),
+		b.factory.ConstructTuple(ids, types.MakeTuple(typs)),
+	)
+}
+
+// buildGroupingOperation builds a GROUPING(...) expression, which returns a
+// bit mask of the arguments which are not part of the grouping set of the
+// current group. The bit of the last argument is the least significant one.
+func (b *Builder) buildGroupingOperation(t *tree.GroupingOperation, inScope *scope) opt.ScalarExpr {
+	g := inScope.groupby
+	if !inScope.inGroupingContext() || inScope.inAgg || g.buildingGroupingCols {
+		panic(errGroupingArgs)
+	}
+	args := make(opt.ColList, len(t.Exprs))
+	for i := range t.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(t.TypedExprAt(i))]
+		if !ok {
+			panic(errGroupingArgs)
+		}
+		args[i] = col.id
+	}
+
+	gs := g.groupingSets
+	if gs == nil {
+		// All grouping columns are part of the only grouping set.
+		return b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+	}
+	whens := make(memo.ScalarListExpr, len(gs.sets))
+	for i := range gs.sets {
+		var mask tree.DInt
+		for _, col := range args {
+			mask <<= 1
+			if !gs.sets[i].Contains(col) {
+				mask |= 1
+			}
+		}
+		whens[i] = b.factory.ConstructWhen(
+			b.constructGroupingSetID(i), b.factory.ConstructConstVal(tree.NewDInt(mask), types.Int),
+		)
+	}
+	return b.factory.ConstructCase(
+		b.factory.ConstructVariable(gs.idCol), whens, b.factory.ConstructNull(types.Int),
+	)
+}
@@ -277,6 +277,11 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	// both cases, include columns undergoing mutations in the write-only state.
 	mb.addSynthesizedColsForInsert()
 
+	// Invoke any BEFORE INSERT triggers, which may modify or skip the rows to
+	// insert. For UPSERT and INSERT..ON CONFLICT, they are invoked for every
+	// proposed row before conflicts are detected.
+	mb.buildBeforeTriggers(tree.TriggerEventInsert)
+
 	// Set insertExpr. This expression is used when building uniqueness checks.
 	// See mutationBuilder.buildCheckInputScan.
 	mb.insertExpr = mb.outScope.expr
@@ -319,6 +324,9 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 			// Add additional columns for computed expressions that may depend on any
 			// updated columns, as well as mutation columns with default values.
 			mb.addSynthesizedColsForUpdate()
+
+			// Invoke any BEFORE UPDATE triggers for the conflicting rows.
+			mb.buildBeforeTriggersForUpsert()
 		}
 
 		// Build the final upsert statement, including any returned expressions.
@@ -336,6 +344,9 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		// Build each of the SET expressions.
 		mb.addUpdateCols(ins.OnConflict.Exprs)
 
+		// Invoke any BEFORE UPDATE triggers for the conflicting rows.
+		mb.buildBeforeTriggersForUpsert()
+
 		// Build the final upsert statement, including any returned expressions.
 		mb.buildUpsert(returning)
 	}
@@ -355,6 +366,8 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 //     values specified for them.
 //  4. Each update value is the same as the corresponding insert value.
 //  5. There are no inbound foreign keys containing non-key columns.
+//  6. There are no INSERT or UPDATE triggers. Triggers need the existing rows
+//     to tell inserted rows from updated ones.
 //
 // TODO(andyk): The fast path is currently only enabled when the UPSERT alias
 // is explicitly selected by the user. It's possible to fast path some queries
@@ -366,6 +379,11 @@ func (mb *mutationBuilder) needExistingRows() bool {
 		return true
 	}
 
+	// #6: Triggers need to know whether each row is inserted or updated.
+	if mb.hasTriggers(tree.TriggerEventInsert) || mb.hasTriggers(tree.TriggerEventUpdate) {
+		return true
+	}
+
 	// If there are any implicit partitioning columns in the primary index,
 	// these columns will need to be fetched.
 	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
@@ -682,8 +700,12 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForInsert()
 
+	mb.buildExclusionChecksForInsert()
+
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerEventInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -699,6 +721,11 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 	// Determine the set of arbiter indexes and constraints to use to check for
 	// conflicts.
 	mb.arbiters = mb.findArbiters(onConflict)
+	if !mb.arbiters.uniqueConstraints.Empty() {
+		// Conflicts with unique constraints without an index are detected by
+		// reading the table, like in uniqueness checks.
+		mb.ensureUniquenessEnforceableByReads()
+	}
 	insertColScope := mb.outScope.replace()
 	insertColScope.appendColumnsFromScope(mb.outScope)
 
@@ -745,6 +772,11 @@ func (mb *mutationBuilder) buildInputForUpsert(
 	// Determine the set of arbiter indexes and constraints to use to check for
 	// conflicts.
 	mb.arbiters = mb.findArbiters(onConflict)
+	if !mb.arbiters.uniqueConstraints.Empty() {
+		// Conflicts with unique constraints without an index are detected by
+		// reading the table, like in uniqueness checks.
+		mb.ensureUniquenessEnforceableByReads()
+	}
 	// TODO(mgartner): Add support for multiple arbiter indexes or constraints,
 	//  similar to buildInputForDoNothing.
 	if mb.arbiters.Len() > 1 {
@@ -905,8 +937,12 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpsert()
 
+	mb.buildExclusionChecksForUpsert()
+
 	mb.buildFKChecksForUpsert()
 
+	mb.buildAfterTriggersForUpsert()
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructUpsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -0,0 +1,474 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// duplicateMergeErrText is error text used when a target row is matched by
+// more than one source row of a MERGE statement.
+const duplicateMergeErrText = "MERGE command cannot affect row a second time"
+
+// buildMerge builds a memo group for an UpsertOp expression that implements a
+// MERGE statement. MERGE shares the Upsert operator with INSERT..ON CONFLICT:
+// each source row is left-joined to the target table using the ON condition,
+// and the fetched primary key column becomes the canary column that selects
+// between inserting a new row and updating the matched one. For example:
+//
+//	CREATE TABLE abc (a INT PRIMARY KEY, b INT, c INT)
+//	MERGE INTO abc USING xyz ON a = x
+//	WHEN MATCHED AND z < 0 THEN DELETE
+//	WHEN MATCHED THEN UPDATE SET b = y
+//	WHEN NOT MATCHED THEN INSERT VALUES (x, y, z)
+//
+// would create an input expression similar to this SQL:
+//
+//	SELECT *, action = 1 AS merge_delete
+//	FROM (
+//	  SELECT
+//	    x, y, z, a, b, c, action,
+//	    CASE action WHEN 3 THEN x ELSE a END AS ins_a,
+//	    CASE action WHEN 3 THEN y ELSE b END AS ins_b,
+//	    CASE action WHEN 3 THEN z ELSE c END AS ins_c,
+//	    CASE action WHEN 2 THEN y ELSE b END AS upd_b
+//	  FROM (
+//	    SELECT *, CASE
+//	      WHEN a IS NOT NULL AND z < 0 THEN 1
+//	      WHEN a IS NOT NULL THEN 2
+//	      WHEN a IS NULL THEN 3
+//	      ELSE 0
+//	    END AS action
+//	    FROM xyz LEFT OUTER JOIN abc ON a = x
+//	  )
+//	  WHERE action != 0
+//	)
+//
+// The action column records the WHEN clause that applies to each row, or 0 if
+// the row is left untouched. The insert and update columns choose their values
+// based on the action, falling back to the existing values for rows that take
+// a different branch. The input is made distinct on the primary key of the
+// target table, and an error is raised if a target row is matched by more than
+// one source row. When the statement has a DELETE clause, an additional
+// boolean column tells the execution engine which matched rows to delete
+// instead of update.
+func (b *Builder) buildMerge(merge *tree.Merge, inScope *scope) (outScope *scope) {
+	// Find which table we're working on, check the permissions. SELECT is
+	// always needed, since existing rows must be read, and the other privileges
+	// depend on the actions of the WHEN clauses.
+	tab, depName, alias, refColumns := b.resolveTableForMutation(merge.Table, privilege.SELECT)
+
+	if refColumns != nil {
+		panic(pgerror.Newf(pgcode.Syntax,
+			"cannot specify a list of column IDs with MERGE"))
+	}
+
+	var hasInsert, hasUpdate, hasDelete bool
+	for _, when := range merge.Whens {
+		switch when.Action {
+		case tree.MergeActionInsert:
+			hasInsert = true
+		case tree.MergeActionUpdate:
+			hasUpdate = true
+		case tree.MergeActionDelete:
+			hasDelete = true
+		}
+	}
+	if hasInsert {
+		b.checkPrivilege(depName, tab, privilege.INSERT)
+	}
+	if hasUpdate {
+		b.checkPrivilege(depName, tab, privilege.UPDATE)
+	}
+	if hasDelete {
+		b.checkPrivilege(depName, tab, privilege.DELETE)
+	}
+
+	// Check if this table has already been mutated in another subquery.
+	b.checkMultipleMutations(tab, false /* simpleInsert */)
+
+	var mb mutationBuilder
+	mb.init(b, "merge", tab, alias)
+
+	// Deleted rows are written by the Upsert operator, which does not plan the
+	// foreign key checks and cascades of a Delete.
+	if hasDelete && tab.InboundForeignKeyCount() > 0 {
+		panic(unimplemented.New("merge delete inbound fk",
+			"MERGE with a DELETE action is not supported on tables referenced by foreign keys"))
+	}
+
+	// Left-join the source rows to the target table, and pick the WHEN clause
+	// that applies to each of them.
+	mb.buildInputForMerge(inScope, merge.Table, merge.Source, merge.On)
+	actionCol := mb.addMergeActionCol(merge.Whens)
+
+	// Build the values inserted by the WHEN NOT MATCHED clauses, as well as any
+	// default and computed columns.
+	mb.addMergeInsertCols(merge.Whens, actionCol)
+	mb.addSynthesizedColsForInsert()
+
+	// Build the values set by the WHEN MATCHED clauses.
+	if hasUpdate {
+		exprs := mb.buildMergeUpdateExprs(merge.Whens, actionCol)
+		mb.addTargetColsForUpdate(exprs)
+		mb.addUpdateCols(exprs)
+	}
+
+	if hasDelete {
+		mb.addMergeDeleteCol(merge.Whens, actionCol)
+	}
+
+	// Invoke any BEFORE triggers for the rows which undergo the kinds of
+	// modification they fire on.
+	mb.buildBeforeTriggersForMerge(hasInsert, hasUpdate, hasDelete)
+
+	// Build the final upsert statement, including any returned expressions.
+	if resultsNeeded(merge.Returning) {
+		mb.buildUpsert(*merge.Returning.(*tree.ReturningExprs))
+	} else {
+		mb.buildUpsert(nil /* returning */)
+	}
+
+	return mb.outScope
+}
+
+// buildInputForMerge constructs the input of a MERGE statement, which is a
+// left outer join between the source data and the target table:
+//
+//	SELECT <cols>
+//	FROM <source> LEFT OUTER JOIN <table> ON <on>
+//
+// All columns from the target table are added to fetchColList, and the first
+// not-null primary key column of the target table becomes the canary column.
+func (mb *mutationBuilder) buildInputForMerge(
+	inScope *scope, texpr tree.TableExpr, source tree.TableExpr, on tree.Expr,
+) {
+	var indexFlags *tree.IndexFlags
+	if target, ok := texpr.(*tree.AliasedTableExpr); ok && target.IndexFlags != nil {
+		indexFlags = target.IndexFlags
+		telemetry.Inc(sqltelemetry.IndexHintUseCounter)
+	}
+
+	// NOTE: Include mutation columns, but be careful to never use them for any
+	//       reason other than as "fetch columns". See buildScan comment.
+	mb.fetchScope = mb.b.buildScan(
+		mb.b.addTable(mb.tab, &mb.alias),
+		tableOrdinals(mb.tab, columnKinds{
+			includeMutations: true,
+			includeSystem:    true,
+			includeInverted:  false,
+		}),
+		indexFlags,
+		noRowLocking,
+		inScope,
+		false, /* disableNotVisibleIndex */
+	)
+
+	// Set list of columns that will be fetched by the input expression.
+	mb.setFetchColIDs(mb.fetchScope.cols)
+
+	sourceScope := mb.b.buildDataSource(source, nil /* indexFlags */, noRowLocking, inScope)
+
+	// Check that the same table name is not used for the source and the target.
+	mb.b.validateJoinTableNames(sourceScope, mb.fetchScope)
+
+	// We create a new scope so that fetchScope is not modified. It will be used
+	// later to build partial index predicate expressions, and we do not want
+	// ambiguities with column names of the source.
+	mb.outScope = mb.fetchScope.replace()
+	mb.outScope.appendColumnsFromScope(sourceScope)
+	mb.outScope.appendColumnsFromScope(mb.fetchScope)
+
+	filter := mb.b.resolveAndBuildScalar(
+		on,
+		types.Bool,
+		exprKindOn,
+		tree.RejectGenerators|tree.RejectWindowApplications,
+		mb.outScope,
+	)
+	mb.outScope.expr = mb.b.factory.ConstructLeftJoin(
+		sourceScope.expr,
+		mb.fetchScope.expr,
+		memo.FiltersExpr{mb.b.factory.ConstructFiltersItem(filter)},
+		memo.EmptyJoinPrivate,
+	)
+
+	// Record a not-null "canary" column. After the left-join, this will be null
+	// if the source row did not match any target row, or not null otherwise.
+	mb.canaryColID = mb.fetchColIDs[findNotNullIndexCol(mb.tab.Index(cat.PrimaryIndex))]
+}
+
+// addMergeActionCol projects an INT column that holds the 1-based index of the
+// WHEN clause that applies to each input row, or 0 if no clause applies or the
+// clause is DO NOTHING. The rows with action 0 are then filtered out, and the
+// remaining rows are made distinct on the primary key of the target table. The
+// action column is returned.
+func (mb *mutationBuilder) addMergeActionCol(whens tree.MergeWhens) *scopeColumn {
+	canaryCol := &mb.fetchScope.cols[findNotNullIndexCol(mb.tab.Index(cat.PrimaryIndex))]
+
+	caseExpr := &tree.CaseExpr{
+		Whens: make([]*tree.When, len(whens)),
+		Else:  tree.NewDInt(0),
+	}
+	for i, when := range whens {
+		op := treecmp.IsNotDistinctFrom
+		if when.Matched {
+			op = treecmp.IsDistinctFrom
+		}
+		var cond tree.Expr = &tree.ComparisonExpr{
+			Operator: treecmp.MakeComparisonOperator(op),
+			Left:     canaryCol,
+			Right:    tree.DNull,
+		}
+		if when.Cond != nil {
+			cond = &tree.AndExpr{Left: cond, Right: when.Cond}
+		}
+		action := tree.NewDInt(tree.DInt(i + 1))
+		if when.Action == tree.MergeActionDoNothing {
+			action = tree.NewDInt(0)
+		}
+		caseExpr.Whens[i] = &tree.When{Cond: cond, Val: action}
+	}
+
+	scalar := mb.b.resolveAndBuildScalar(
+		caseExpr, types.Int, exprKindWhere, tree.RejectSpecial, mb.outScope,
+	)
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	mb.b.synthesizeColumn(
+		projectionsScope, scopeColName("").WithMetadataName("merge_action"), types.Int, nil, scalar,
+	)
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+	actionCol := mb.outScope.cols[len(mb.outScope.cols)-1]
+
+	// Filter out the rows that are left untouched.
+	mb.b.buildWhere(&tree.Where{
+		Type: tree.AstWhere,
+		Expr: &tree.ComparisonExpr{
+			Operator: treecmp.MakeComparisonOperator(treecmp.NE),
+			Left:     &actionCol,
+			Right:    tree.NewDInt(0),
+		},
+	}, mb.outScope)
+
+	// Ensure that each target row is affected at most once. Source rows that
+	// did not match any target row have null fetch columns and are all kept.
+	var pkCols opt.ColSet
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	for i, n := 0, primaryIndex.KeyColumnCount(); i < n; i++ {
+		pkCols.Add(mb.fetchColIDs[primaryIndex.Column(i).Ordinal()])
+	}
+	mb.outScope.ordering = nil
+	mb.outScope = mb.b.buildDistinctOn(
+		pkCols, mb.outScope, true /* nullsAreDistinct */, duplicateMergeErrText,
+	)
+
+	return &actionCol
+}
+
+// addMergeInsertCols projects one insert column for each public, non-computed
+// column of the target table. Each value is chosen among the WHEN NOT MATCHED
+// .. INSERT clauses by the action column:
+//
+//	CASE action WHEN 3 THEN <value> WHEN 5 THEN <default> ... ELSE <fetch> END
+//
+// Rows that are not inserted use the existing values, which keeps the column
+// constraints of the insert columns satisfied for them.
+func (mb *mutationBuilder) addMergeInsertCols(whens tree.MergeWhens, actionCol *scopeColumn) {
+	// INSERT expressions should reject aggregates, generators, etc.
+	scalarProps := &mb.b.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+	mb.b.semaCtx.Properties.Require("MERGE INSERT", tree.RejectSpecial)
+
+	// Collect the value of each target column for each INSERT clause. The
+	// target columns of each clause are validated the same way as those of an
+	// INSERT statement.
+	n := mb.tab.ColumnCount()
+	caseExprs := make([]*tree.CaseExpr, n)
+	for i, when := range whens {
+		if when.Action != tree.MergeActionInsert {
+			continue
+		}
+		if len(when.Columns) != 0 {
+			mb.addTargetNamedColsForInsert(when.Columns)
+			mb.checkNumCols(len(mb.targetColList), len(when.Values))
+		} else if when.Values != nil {
+			mb.addTargetTableColsForInsert(len(when.Values))
+		}
+
+		values := make(map[int]tree.Expr, len(mb.targetColList))
+		for j, colID := range mb.targetColList {
+			ord := mb.tabID.ColumnOrdinal(colID)
+			if _, ok := when.Values[j].(tree.DefaultVal); ok {
+				continue
+			}
+			if col := mb.tab.Column(ord); col.IsGeneratedAlwaysAsIdentity() {
+				panic(sqlerrors.NewGeneratedAlwaysAsIdentityColumnOverrideError(string(col.ColName())))
+			}
+			values[ord] = when.Values[j]
+		}
+		mb.targetColList = make(opt.ColList, 0, n)
+		mb.targetColSet = opt.ColSet{}
+
+		for ord := 0; ord < n; ord++ {
+			col := mb.tab.Column(ord)
+			if col.Kind() != cat.Ordinary || col.IsComputed() {
+				continue
+			}
+			val, ok := values[ord]
+			if !ok {
+				val = mb.parseDefaultExpr(mb.tabID.ColumnID(ord))
+			}
+			if caseExprs[ord] == nil {
+				caseExprs[ord] = &tree.CaseExpr{
+					Expr: actionCol,
+					Else: &mb.fetchScope.cols[ord],
+				}
+			}
+			caseExprs[ord].Whens = append(caseExprs[ord].Whens, &tree.When{
+				Cond: tree.NewDInt(tree.DInt(i + 1)),
+				Val:  val,
+			})
+		}
+	}
+
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	for ord := 0; ord < n; ord++ {
+		col := mb.tab.Column(ord)
+		if col.Kind() != cat.Ordinary || col.IsComputed() {
+			continue
+		}
+		if caseExprs[ord] == nil {
+			// There is no INSERT clause, so the existing value is used.
+			mb.insertColIDs[ord] = mb.fetchColIDs[ord]
+			continue
+		}
+
+		texpr := mb.outScope.resolveType(caseExprs[ord], col.DatumType())
+		colName := scopeColName(col.ColName()).WithMetadataName(string(col.ColName()) + "_ins")
+		scopeCol := projectionsScope.addColumn(colName, texpr)
+		mb.b.buildScalar(texpr, mb.outScope, projectionsScope, scopeCol, nil)
+		mb.insertColIDs[ord] = scopeCol.id
+	}
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// Add assignment casts for insert columns.
+	mb.addAssignmentCasts(mb.insertColIDs)
+}
+
+// buildMergeUpdateExprs combines the SET expressions of the WHEN MATCHED ..
+// UPDATE clauses into a single list of SET expressions, one per updated
+// column. Each value is chosen by the action column:
+//
+//	SET b = CASE action WHEN 2 THEN <value> WHEN 4 THEN <value> ELSE <fetch> END
+//
+// Matched rows that take a different branch keep their existing values.
+func (mb *mutationBuilder) buildMergeUpdateExprs(
+	whens tree.MergeWhens, actionCol *scopeColumn,
+) tree.UpdateExprs {
+	n := mb.tab.ColumnCount()
+	caseExprs := make([]*tree.CaseExpr, n)
+	for i, when := range whens {
+		if when.Action != tree.MergeActionUpdate {
+			continue
+		}
+
+		var values tree.Exprs
+		for _, set := range when.Exprs {
+			mb.addTargetColsByName(set.Names)
+			if !set.Tuple {
+				values = append(values, set.Expr)
+				continue
+			}
+			t, ok := set.Expr.(*tree.Tuple)
+			if !ok {
+				panic(unimplemented.Newf("merge update tuple",
+					"source for a multiple-column UPDATE item in MERGE must be a ROW() expression; not supported: %T", set.Expr))
+			}
+			if len(set.Names) != len(t.Exprs) {
+				panic(pgerror.Newf(pgcode.Syntax,
+					"number of columns (%d) does not match number of values (%d)",
+					len(set.Names), len(t.Exprs)))
+			}
+			values = append(values, t.Exprs...)
+		}
+
+		for j, colID := range mb.targetColList {
+			ord := mb.tabID.ColumnOrdinal(colID)
+			val := values[j]
+			if _, ok := val.(tree.DefaultVal); ok {
+				val = mb.parseDefaultExpr(colID)
+			}
+			if caseExprs[ord] == nil {
+				caseExprs[ord] = &tree.CaseExpr{
+					Expr: actionCol,
+					Else: &mb.fetchScope.cols[ord],
+				}
+			}
+			caseExprs[ord].Whens = append(caseExprs[ord].Whens, &tree.When{
+				Cond: tree.NewDInt(tree.DInt(i + 1)),
+				Val:  val,
+			})
+		}
+		mb.targetColList = make(opt.ColList, 0, n)
+		mb.targetColSet = opt.ColSet{}
+	}
+
+	var exprs tree.UpdateExprs
+	for ord := 0; ord < n; ord++ {
+		if caseExprs[ord] != nil {
+			exprs = append(exprs, &tree.UpdateExpr{
+				Names: tree.NameList{mb.tab.Column(ord).ColName()},
+				Expr:  caseExprs[ord],
+			})
+		}
+	}
+	return exprs
+}
+
+// addMergeDeleteCol projects a boolean column which is true for the matched
+// rows that are deleted by a WHEN MATCHED .. DELETE clause, and records it as
+// the merge delete column of the Upsert operator.
+func (mb *mutationBuilder) addMergeDeleteCol(whens tree.MergeWhens, actionCol *scopeColumn) {
+	caseExpr := &tree.CaseExpr{Expr: actionCol, Else: tree.DBoolFalse}
+	for i, when := range whens {
+		if when.Action == tree.MergeActionDelete {
+			caseExpr.Whens = append(caseExpr.Whens, &tree.When{
+				Cond: tree.NewDInt(tree.DInt(i + 1)),
+				Val:  tree.DBoolTrue,
+			})
+		}
+	}
+
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	texpr := mb.outScope.resolveAndRequireType(caseExpr, types.Bool)
+	scopeCol := projectionsScope.addColumn(scopeColName("").WithMetadataName("merge_delete"), texpr)
+	mb.b.buildScalar(texpr, mb.outScope, projectionsScope, scopeCol, nil)
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+	mb.mergeDeleteColID = scopeCol.id
+}
@@ -138,6 +138,11 @@ type mutationBuilder struct {
 	// an insert; otherwise it's an update.
 	canaryColID opt.ColumnID
 
+	// mergeDeleteColID is the ID of the boolean column that is true for the
+	// matched rows which a MERGE statement deletes rather than updates. It is 0
+	// for all other statements.
+	mergeDeleteColID opt.ColumnID
+
 	// arbiters is the set of indexes and unique constraints that are used to
 	// detect conflicts for UPSERT and INSERT ON CONFLICT statements.
 	arbiters arbiterSet
@@ -169,7 +174,9 @@ type mutationBuilder struct {
 	// once and cached for reuse.
 	parsedUniqueConstraintExprs []tree.Expr
 
-	// uniqueChecks contains unique check queries; see buildUnique* methods.
+	// uniqueChecks contains unique check queries; see buildUnique* methods. It
+	// also contains exclusion constraint check queries; see buildExclusion*
+	// methods.
 	uniqueChecks memo.UniqueChecksExpr
 
 	// fkChecks contains foreign key check queries; see buildFK* methods.
@@ -194,6 +201,9 @@ type mutationBuilder struct {
 	// uniqueCheckHelper is used to prevent allocating the helper separately.
 	uniqueCheckHelper uniqueCheckHelper
 
+	// exclusionCheckHelper is used to prevent allocating the helper separately.
+	exclusionCheckHelper exclusionCheckHelper
+
 	// arbiterPredicateHelper is used to prevent allocating the helper
 	// separately.
 	arbiterPredicateHelper arbiterPredicateHelper
@@ -908,6 +918,7 @@ func (mb *mutationBuilder) makeMutationPrivate(needResults bool) *memo.MutationP
 		FetchCols:           checkEmptyList(mb.fetchColIDs),
 		UpdateCols:          checkEmptyList(mb.updateColIDs),
 		CanaryCol:           mb.canaryColID,
+		MergeDeleteCol:      mb.mergeDeleteColID,
 		ArbiterIndexes:      mb.arbiters.IndexOrdinals(),
 		ArbiterConstraints:  mb.arbiters.UniqueConstraintOrdinals(),
 		CheckCols:           checkEmptyList(mb.checkColIDs),
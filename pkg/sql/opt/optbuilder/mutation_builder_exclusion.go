@@ -0,0 +1,224 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// buildExclusionChecksForInsert builds exclusion constraint check queries for
+// an insert.
+func (mb *mutationBuilder) buildExclusionChecksForInsert() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	h := &mb.exclusionCheckHelper
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		if h.init(mb, i) {
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+	telemetry.Inc(sqltelemetry.ExclusionChecksUseCounter)
+}
+
+// buildExclusionChecksForUpdate builds exclusion constraint check queries for
+// an update.
+func (mb *mutationBuilder) buildExclusionChecksForUpdate() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	h := &mb.exclusionCheckHelper
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		// If this constraint doesn't include the updated columns we don't need to
+		// plan a check.
+		if !mb.exclusionColsUpdated(i) {
+			continue
+		}
+		if h.init(mb, i) {
+			// The insertion check works for updates too since it simply checks that
+			// the newly inserted or updated rows do not conflict with any other
+			// rows.
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+	telemetry.Inc(sqltelemetry.ExclusionChecksUseCounter)
+}
+
+// buildExclusionChecksForUpsert builds exclusion constraint check queries for
+// an upsert.
+func (mb *mutationBuilder) buildExclusionChecksForUpsert() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	h := &mb.exclusionCheckHelper
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		// Exclusion constraints are never arbiters of an INSERT ... ON CONFLICT
+		// statement, so the inserted rows must always be checked.
+		if h.init(mb, i) {
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+	telemetry.Inc(sqltelemetry.ExclusionChecksUseCounter)
+}
+
+// exclusionColsUpdated returns true if any of the columns for an exclusion
+// constraint are being updated (according to updateColIDs). When the exclusion
+// constraint has a partial predicate, it also returns true if the predicate
+// references any of the columns being updated.
+func (mb *mutationBuilder) exclusionColsUpdated(exclusionOrdinal int) bool {
+	return mb.constraintColsUpdated(mb.tab.Exclusion(exclusionOrdinal), func() tree.Expr {
+		return mb.parseExclusionConstraintPredicateExpr(exclusionOrdinal)
+	})
+}
+
+// parseExclusionConstraintPredicateExpr parses the predicate of the given
+// partial exclusion constraint. This function panics if the exclusion
+// constraint at the given ordinal is not partial.
+func (mb *mutationBuilder) parseExclusionConstraintPredicateExpr(exclusionOrdinal int) tree.Expr {
+	predStr, isPartial := mb.tab.Exclusion(exclusionOrdinal).Predicate()
+	if !isPartial {
+		panic(errors.AssertionFailedf(
+			"exclusion constraint at ordinal %d is not a partial exclusion constraint", exclusionOrdinal,
+		))
+	}
+	expr, err := parser.ParseExpr(predStr)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// exclusionCheckHelper is a type associated with a single exclusion constraint
+// and is used to build the "leaves" of an exclusion check expression, namely
+// the WithScan of the mutation input and the Scan of the table.
+type exclusionCheckHelper struct {
+	conflictCheckHelper
+
+	exclusion        cat.ExclusionConstraint
+	exclusionOrdinal int
+}
+
+// init initializes the helper with an exclusion constraint.
+//
+// Returns false if the constraint should be ignored (e.g. because the new
+// values for the constrained columns are known to be always NULL).
+func (h *exclusionCheckHelper) init(mb *mutationBuilder, exclusionOrdinal int) bool {
+	// This initialization pattern ensures that fields are not unwittingly
+	// reused. Field reuse must be explicit.
+	*h = exclusionCheckHelper{
+		exclusion:        mb.tab.Exclusion(exclusionOrdinal),
+		exclusionOrdinal: exclusionOrdinal,
+	}
+
+	// All the columns but the last one are compared with =, and the last one is
+	// compared with &&. If the constraint compares ranges, the last two columns
+	// are the bounds of the ranges, which may be NULL when unbounded.
+	n := h.exclusion.ColumnCount()
+	numEq := n - 1
+	if h.exclusion.IsRange() {
+		numEq = n - 2
+	}
+	columnOrdinals := make([]int, n)
+	var eqOrds, nullConflictOrds util.FastIntSet
+	for i := 0; i < n; i++ {
+		ord := h.exclusion.ColumnOrdinal(mb.tab, i)
+		// The check scans the public columns of the table. If a constrained
+		// column is still being added, the existing rows are validated against
+		// the constraint once the column has been backfilled.
+		if mb.tab.Column(ord).Kind() != cat.Ordinary {
+			return false
+		}
+		columnOrdinals[i] = ord
+		if i < numEq {
+			eqOrds.Add(ord)
+		} else if h.exclusion.IsRange() {
+			nullConflictOrds.Add(ord)
+		}
+	}
+	var pred tree.Expr
+	if _, isPartial := h.exclusion.Predicate(); isPartial {
+		pred = mb.parseExclusionConstraintPredicateExpr(exclusionOrdinal)
+	}
+
+	if !h.conflictCheckHelper.init(mb, columnOrdinals, eqOrds, nullConflictOrds, pred) {
+		return false
+	}
+	h.buildScan()
+	return true
+}
+
+// buildInsertionCheck creates an exclusion check for rows which are added to a
+// table. The input to the insertion check will be produced from the input to
+// the mutation operator.
+func (h *exclusionCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
+	f := h.mb.b.factory
+
+	// The join filters are:
+	//   (new_a = existing_a) AND ... AND (new_z && existing_z)
+	//
+	// If the constraint compares the ranges between the lower bounds l and the
+	// upper bounds u, the last filter is replaced by:
+	//   ((new_l < new_u) IS NOT false) AND ((existing_l < existing_u) IS NOT false) AND
+	//   ((new_l < existing_u) IS NOT false) AND ((existing_l < new_u) IS NOT false)
+	//
+	// The first two filters exclude empty ranges, which do not overlap any
+	// range. A comparison with a NULL bound is NULL, which is not false, since
+	// a NULL bound is unbounded.
+	check, keyCols := h.buildInsertionCheckInput(
+		func(newVals, existingVals []opt.ScalarExpr) []opt.ScalarExpr {
+			n := len(newVals)
+			if !h.exclusion.IsRange() {
+				conditions := make([]opt.ScalarExpr, n)
+				for i := 0; i < n-1; i++ {
+					conditions[i] = f.ConstructEq(newVals[i], existingVals[i])
+				}
+				conditions[n-1] = f.ConstructOverlaps(newVals[n-1], existingVals[n-1])
+				return conditions
+			}
+			conditions := make([]opt.ScalarExpr, n-2, n+2)
+			for i := 0; i < n-2; i++ {
+				conditions[i] = f.ConstructEq(newVals[i], existingVals[i])
+			}
+			lessThan := func(left, right opt.ScalarExpr) opt.ScalarExpr {
+				return f.ConstructIsNot(f.ConstructLt(left, right), memo.FalseSingleton)
+			}
+			newLower, newUpper := newVals[n-2], newVals[n-1]
+			existingLower, existingUpper := existingVals[n-2], existingVals[n-1]
+			return append(conditions,
+				lessThan(newLower, newUpper),
+				lessThan(existingLower, existingUpper),
+				lessThan(newLower, existingUpper),
+				lessThan(existingLower, newUpper),
+			)
+		},
+	)
+
+	return f.ConstructUniqueChecksItem(check, &memo.UniqueChecksItemPrivate{
+		Table:        h.mb.tabID,
+		CheckOrdinal: h.exclusionOrdinal,
+		Exclusion:    true,
+		KeyCols:      keyCols,
+		OpName:       h.mb.opName,
+	})
+}
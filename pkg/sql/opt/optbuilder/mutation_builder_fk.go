@@ -165,7 +165,9 @@ func (mb *mutationBuilder) buildFKChecksAndCascadesForDelete() {
 		}
 
 		withScanScope, _ := mb.buildCheckInputScan(checkInputScanFetchedVals, h.tabOrdinals, true /* isFK */)
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(withScanScope.expr, withScanScope.colList()))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			withScanScope.expr, withScanScope.colList(), h.fk.DeleteReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -345,7 +347,9 @@ func (mb *mutationBuilder) buildFKChecksForUpdate() {
 			},
 		)
 
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(deletedRows, colsForOldRow))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			deletedRows, colsForOldRow, h.fk.UpdateReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -449,7 +453,9 @@ func (mb *mutationBuilder) buildFKChecksForUpsert() {
 				OutCols:   colsForOldRow,
 			},
 		)
-		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(deletedRows, oldRowsScope.colList()))
+		mb.fkChecks = append(mb.fkChecks, h.buildDeletionCheck(
+			deletedRows, oldRowsScope.colList(), h.fk.UpdateReferenceAction(),
+		))
 	}
 	telemetry.Inc(sqltelemetry.ForeignKeyChecksUseCounter)
 }
@@ -611,19 +617,64 @@ func resolveTable(ctx context.Context, catalog cat.Catalog, id cat.StableID) cat
 	return ref.(cat.Table)
 }
 
-// buildOtherTableScan builds a Scan of the "other" table.
-func (h *fkCheckHelper) buildOtherTableScan() (outScope *scope, tabMeta *opt.TableMeta) {
+// buildOtherTableScan builds a Scan of the "other" table, which locks the rows
+// it reads according to the given locking spec.
+func (h *fkCheckHelper) buildOtherTableScan(
+	locking lockingSpec,
+) (outScope *scope, tabMeta *opt.TableMeta) {
 	otherTabMeta := h.mb.b.addTable(h.otherTab, tree.NewUnqualifiedTableName(h.otherTab.Name()))
 	return h.mb.b.buildScan(
 		otherTabMeta,
 		h.otherTabOrdinals,
 		&tree.IndexFlags{IgnoreForeignKeys: true},
-		noRowLocking,
+		locking,
 		h.mb.b.allocScope(),
 		true, /* disableNotVisibleIndex */
 	), otherTabMeta
 }
 
+// insertionCheckLocking returns the locking spec of the scan of the
+// referenced table in an insertion check. SERIALIZABLE transactions don't need
+// to lock the referenced rows: if a concurrent transaction deletes them, the
+// transaction fails to refresh its reads when it commits. Transactions which
+// tolerate write skew, like READ COMMITTED transactions, commit without
+// refreshing, so they lock the referenced rows until they commit instead.
+func (h *fkCheckHelper) insertionCheckLocking() lockingSpec {
+	return h.mb.b.fkLockingIfWriteSkewTolerated()
+}
+
+// deletionCheckLocking returns the locking spec of the scan of the referencing
+// table in a deletion check. SERIALIZABLE transactions don't need to lock the
+// referencing rows: if a concurrent transaction inserts a row which references
+// a deleted row, the transaction fails to refresh its reads when it commits.
+//
+// Transactions which tolerate write skew can't rely on the lock that the
+// insertion check of the concurrent transaction acquires on the referenced
+// row. The deletion may wait on that lock, but it reads the referencing rows
+// at the read snapshot of its statement, which was taken before it waited.
+// Since the lock is unreplicated, the deletion doesn't hit a WriteTooOld error
+// once the insertion commits, and it would miss the new row. Locking reads
+// fail with a WriteTooOld error when they find a row written above their read
+// timestamp, so the referencing rows are locked instead. The statement is then
+// retried with a new read snapshot, which sees the new row.
+func (h *fkCheckHelper) deletionCheckLocking() lockingSpec {
+	return h.mb.b.fkLockingIfWriteSkewTolerated()
+}
+
+// fkLockingIfWriteSkewTolerated returns the locking spec of the scans of FK
+// checks and cascades in transactions which tolerate write skew, or
+// noRowLocking for other transactions. Shared locks are not implemented yet,
+// so the rows are locked exclusively.
+func (b *Builder) fkLockingIfWriteSkewTolerated() lockingSpec {
+	if !b.evalCtx.TxnIsoLevel().ToleratesWriteSkew() {
+		return noRowLocking
+	}
+	return lockingSpec{&tree.LockingItem{
+		Strength:   tree.ForUpdate,
+		WaitPolicy: tree.LockWaitBlock,
+	}}
+}
+
 func (h *fkCheckHelper) allocOrdinals(numCols int) {
 	buf := make([]int, numCols*2)
 	h.tabOrdinals = buf[:numCols]
@@ -710,7 +761,7 @@ func (h *fkCheckHelper) buildInsertionCheck() memo.FKChecksItem {
 	// Build an anti-join, with the origin FK columns on the left and the
 	// referenced columns on the right.
 
-	scanScope, refTabMeta := h.buildOtherTableScan()
+	scanScope, refTabMeta := h.buildOtherTableScan(h.insertionCheckLocking())
 
 	// Build the join filters:
 	//   (origin_a = referenced_a) AND (origin_b = referenced_b) AND ...
@@ -736,19 +787,22 @@ func (h *fkCheckHelper) buildInsertionCheck() memo.FKChecksItem {
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         withScanScope.colList(),
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrable(),
 	})
 }
 
 // buildDeletionCheck creates a FK check for rows which are removed from a
 // table. deletedRows is used as the input to the deletion check, and deleteCols
 // is a list of the columns for the rows being deleted, containing values for
-// the referenced FK columns in the table we are mutating.
+// the referenced FK columns in the table we are mutating. action is the
+// reference action (NO ACTION or RESTRICT) that the check enforces; only NO
+// ACTION checks can be deferred to the end of the transaction.
 func (h *fkCheckHelper) buildDeletionCheck(
-	deletedRows memo.RelExpr, deleteCols opt.ColList,
+	deletedRows memo.RelExpr, deleteCols opt.ColList, action tree.ReferenceAction,
 ) memo.FKChecksItem {
 	// Build a semi join, with the referenced FK columns on the left and the
 	// origin columns on the right.
-	scanScope, origTabMeta := h.buildOtherTableScan()
+	scanScope, origTabMeta := h.buildOtherTableScan(h.deletionCheckLocking())
 
 	// Note that it's impossible to orphan a row whose FK key columns contain a
 	// NULL, since by definition a NULL never refers to an actual row (in
@@ -778,5 +832,6 @@ func (h *fkCheckHelper) buildDeletionCheck(
 		FKOrdinal:       h.fkOrdinal,
 		KeyCols:         deleteCols,
 		OpName:          h.mb.opName,
+		Deferrable:      h.fk.Deferrable() && action == tree.NoAction,
 	})
 }
@@ -0,0 +1,603 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// A row-level trigger invokes a PL/pgSQL function that returns trigger for
+// each row that is modified by a mutation. As in Postgres, the function takes
+// no arguments; the rows and the kind of modification are passed through
+// implicit variables:
+//
+//	NEW              the new row, NULL for deletes
+//	OLD              the old row, NULL for inserts
+//	TG_NAME          the name of the trigger
+//	TG_WHEN          BEFORE or AFTER
+//	TG_LEVEL         ROW
+//	TG_OP            INSERT, UPDATE or DELETE
+//	TG_RELID         the OID of the table
+//	TG_RELNAME       the name of the table (deprecated, same as TG_TABLE_NAME)
+//	TG_TABLE_NAME    the name of the table
+//	TG_TABLE_SCHEMA  the schema of the table
+//	TG_NARGS         the number of trigger arguments, always 0
+//	TG_ARGV          the trigger arguments, always empty
+//
+// NEW and OLD have the implicit record type of the table, which is also the
+// return type of the function. The body of the function is only compiled when
+// a statement fires the trigger, so it is resolved in the context of that
+// statement.
+//
+// -- BEFORE triggers --
+//
+// BEFORE triggers are invoked as part of the mutation input, before the row is
+// modified. The row returned by the function replaces the new row; if the
+// function returns NULL, the row is skipped. Multiple triggers are invoked in
+// order of their names, each receiving the row returned by the previous one.
+// For example, an insert into a table with a BEFORE INSERT trigger looks like:
+//
+//	insert t
+//	 └── project
+//	      ├── columns: a_trigger:9 b_trigger:10 ...
+//	      ├── select
+//	      │    ├── project
+//	      │    │    ├── columns: trig:8
+//	      │    │    ├── values ...
+//	      │    │    └── projections
+//	      │    │         └── udf: f [as=trig:8]
+//	      │    └── filters
+//	      │         └── trig:8 IS DISTINCT FROM NULL
+//	      └── projections
+//	           ├── (trig:8).a [as=a_trigger:9]
+//	           └── (trig:8).b [as=b_trigger:10]
+//
+// Computed columns are recomputed from the values returned by the triggers.
+//
+// -- AFTER triggers --
+//
+// AFTER triggers are invoked after the statement has modified all rows. They
+// reuse the FK cascade mechanism: each trigger is planned as a "cascade" query
+// that invokes the function for each row of the buffered mutation input, and
+// runs after the mutation (and any preceding cascades) completes. The result
+// of the function is ignored.
+//
+// -- UPSERT and MERGE --
+//
+// UPSERT, INSERT..ON CONFLICT DO UPDATE and MERGE only learn whether a row is
+// inserted, updated or deleted once the conflicting rows have been read. As in
+// Postgres, BEFORE INSERT triggers of UPSERT and INSERT..ON CONFLICT fire for
+// every proposed row before the conflicts are detected, so the excluded row
+// reflects their changes. All other triggers of these statements fire after
+// the join with the existing rows, and only for the rows that undergo their
+// kind of modification; the canary column and the MERGE delete column tell
+// them apart. The function of a conditional BEFORE trigger is wrapped in a
+// CASE expression so that it is not invoked for other rows, and an AFTER
+// trigger is planned as one cascade for each kind of modification, which
+// filters the buffered rows.
+//
+// -- Differences from Postgres --
+//
+// Statement-level triggers, INSTEAD OF triggers, WHEN conditions, UPDATE OF
+// column lists, transition tables and trigger arguments are not supported.
+// AFTER triggers of a statement run trigger by trigger rather than row by row.
+
+// hasTriggers returns true if the target table has any triggers which fire on
+// the given type of row modification.
+func (mb *mutationBuilder) hasTriggers(event tree.TriggerEventType) bool {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		if trig := mb.tab.Trigger(i); trig.FiresOn(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildBeforeTriggers wraps the mutation input in expressions which invoke
+// each BEFORE trigger that fires on the given type of row modification. For
+// inserts and updates, the insert or update columns are replaced with the
+// columns of the rows returned by the triggers. See the comment at the top of
+// the file for more details.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEventType) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if !trig.Before || !trig.FiresOn(event) {
+			continue
+		}
+		mb.buildBeforeTrigger(&trig, event, nil /* cond */)
+	}
+}
+
+// buildBeforeTriggersForUpsert invokes the BEFORE UPDATE triggers of an UPSERT
+// or INSERT..ON CONFLICT DO UPDATE statement for the rows which conflict with
+// an existing row. It must be called once the update columns are built.
+func (mb *mutationBuilder) buildBeforeTriggersForUpsert() {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if !trig.Before || !trig.FiresOn(tree.TriggerEventUpdate) {
+			continue
+		}
+		mb.buildBeforeTrigger(&trig, tree.TriggerEventUpdate, mb.triggerEventCond(tree.TriggerEventUpdate))
+	}
+}
+
+// buildBeforeTriggersForMerge invokes the BEFORE triggers of a MERGE
+// statement. Each trigger is only invoked for the rows which undergo the kinds
+// of modification it fires on. hasInsert, hasUpdate and hasDelete are true if
+// the statement has WHEN clauses with the respective actions. It must be
+// called once the insert, update and delete columns are built.
+func (mb *mutationBuilder) buildBeforeTriggersForMerge(hasInsert, hasUpdate, hasDelete bool) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if !trig.Before {
+			continue
+		}
+		if hasInsert && trig.FiresOn(tree.TriggerEventInsert) {
+			mb.buildBeforeTrigger(&trig, tree.TriggerEventInsert, mb.triggerEventCond(tree.TriggerEventInsert))
+		}
+		if hasUpdate && trig.FiresOn(tree.TriggerEventUpdate) {
+			mb.buildBeforeTrigger(&trig, tree.TriggerEventUpdate, mb.triggerEventCond(tree.TriggerEventUpdate))
+		}
+		if hasDelete && trig.FiresOn(tree.TriggerEventDelete) {
+			mb.buildBeforeTrigger(&trig, tree.TriggerEventDelete, mb.triggerEventCond(tree.TriggerEventDelete))
+		}
+	}
+}
+
+// buildBeforeTrigger wraps the mutation input in expressions which invoke the
+// given BEFORE trigger. If cond is not nil, the trigger is only invoked for
+// the rows for which it is true, and other rows are left untouched.
+func (mb *mutationBuilder) buildBeforeTrigger(
+	trig *cat.Trigger, event tree.TriggerEventType, cond opt.ScalarExpr,
+) {
+	f := mb.b.factory
+	recordType := mb.b.resolveTriggerRecordType(mb.tab)
+	ords := visibleColumnOrdinals(mb.tab)
+	newRowCols, oldRowCols := mb.triggerRowCols(event, ords)
+
+	// Project the result of the trigger function.
+	udf := mb.b.buildTriggerFunction(
+		trig, mb.tab, event, recordType,
+		mb.b.buildTriggerRow(newRowCols, recordType),
+		mb.b.buildTriggerRow(oldRowCols, recordType),
+	)
+	if cond != nil {
+		udf = f.ConstructCase(
+			memo.TrueSingleton,
+			memo.ScalarListExpr{f.ConstructWhen(cond, udf)},
+			f.ConstructNull(recordType),
+		)
+	}
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	trigCol := mb.b.synthesizeColumn(
+		projectionsScope, scopeColName("").WithMetadataName(string(trig.Name)), recordType,
+		nil /* expr */, udf,
+	)
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// Skip the rows for which the trigger function returns NULL.
+	filter := f.ConstructIsNot(f.ConstructVariable(trigCol.id), f.ConstructNull(recordType))
+	if cond != nil {
+		filter = f.ConstructOr(f.ConstructNot(cond), filter)
+	}
+	mb.outScope.expr = f.ConstructSelect(
+		mb.outScope.expr,
+		memo.FiltersExpr{f.ConstructFiltersItem(filter)},
+	)
+	if event == tree.TriggerEventDelete {
+		// The row returned by the trigger function is only used to determine
+		// whether the row is deleted.
+		return
+	}
+
+	// Replace the new row with the row returned by the trigger function. The
+	// values of computed columns are ignored; they are recomputed below.
+	colIDs, restrict := mb.insertColIDs, false
+	if event == tree.TriggerEventUpdate {
+		colIDs, restrict = mb.updateColIDs, true
+	}
+	projectionsScope = mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	for i, ord := range ords {
+		tabCol := mb.tab.Column(ord)
+		if tabCol.IsComputed() {
+			colIDs[ord] = 0
+			continue
+		}
+		colName := scopeColName(tabCol.ColName()).WithMetadataName(
+			string(tabCol.ColName()) + "_trigger",
+		)
+		var val opt.ScalarExpr = f.ConstructColumnAccess(
+			f.ConstructVariable(trigCol.id), memo.TupleOrdinal(i),
+		)
+		if cond != nil {
+			// Rows for which the trigger is not invoked keep their values.
+			prevColID := colIDs[ord]
+			if prevColID == 0 {
+				prevColID = mb.fetchColIDs[ord]
+			}
+			val = f.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{f.ConstructWhen(cond, val)},
+				f.ConstructVariable(prevColID),
+			)
+		}
+		newCol := mb.b.synthesizeColumn(projectionsScope, colName, tabCol.DatumType(), nil /* expr */, val)
+		colIDs[ord] = newCol.id
+	}
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// Disambiguate names so that references in the computed expressions refer
+	// to the columns of the returned row. For updates, only the computed columns
+	// which depend on the updated columns are recomputed.
+	mb.disambiguateColumns()
+	mb.addSynthesizedComputedCols(colIDs, restrict)
+	mb.addAssignmentCasts(colIDs)
+}
+
+// triggerEventCond returns a condition which is true for the rows of an
+// UPSERT, INSERT..ON CONFLICT DO UPDATE or MERGE statement which undergo the
+// given kind of modification.
+func (mb *mutationBuilder) triggerEventCond(event tree.TriggerEventType) opt.ScalarExpr {
+	return buildTriggerEventCond(mb.b.factory, event, mb.canaryColID, mb.mergeDeleteColID)
+}
+
+// buildTriggerEventCond builds a condition which is true for the rows which
+// undergo the given kind of modification. canary is the canary column of an
+// UPSERT or MERGE, which is NULL for inserted rows. mergeDelete is the column
+// which is true for the rows deleted by a MERGE, or zero if the statement
+// does not delete rows.
+func buildTriggerEventCond(
+	f *norm.Factory, event tree.TriggerEventType, canary, mergeDelete opt.ColumnID,
+) opt.ScalarExpr {
+	switch event {
+	case tree.TriggerEventInsert:
+		return f.ConstructIs(f.ConstructVariable(canary), memo.NullSingleton)
+	case tree.TriggerEventUpdate:
+		cond := f.ConstructIsNot(f.ConstructVariable(canary), memo.NullSingleton)
+		if mergeDelete != 0 {
+			cond = f.ConstructAnd(cond, f.ConstructNot(f.ConstructVariable(mergeDelete)))
+		}
+		return cond
+	default:
+		return f.ConstructVariable(mergeDelete)
+	}
+}
+
+// buildAfterTriggers adds a cascade for each AFTER trigger that fires on the
+// given type of row modification. For UPSERT and MERGE statements, the
+// cascade only invokes the trigger for the rows which undergo that kind of
+// modification. See the comment at the top of the file for more details.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEventType) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trig := mb.tab.Trigger(i)
+		if trig.Before || !trig.FiresOn(event) {
+			continue
+		}
+		mb.ensureWithID()
+		newCols, oldCols := mb.triggerRowCols(event, visibleColumnOrdinals(mb.tab))
+		builder := newAfterTriggerBuilder(mb.tab, trig, event)
+		if mb.canaryColID != 0 {
+			// The columns which tell the kind of modification of each row are
+			// passed to the cascade after the old row.
+			builder.hasCanary = true
+			oldCols = append(oldCols, mb.canaryColID)
+			if mb.mergeDeleteColID != 0 {
+				builder.hasMergeDelete = true
+				oldCols = append(oldCols, mb.mergeDeleteColID)
+			}
+		}
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:    string(trig.Name),
+			Builder:   builder,
+			WithID:    mb.withID,
+			OldValues: oldCols,
+			NewValues: newCols,
+		})
+	}
+}
+
+// buildAfterTriggersForUpsert adds the cascades of the AFTER triggers of an
+// UPSERT, INSERT..ON CONFLICT DO UPDATE or MERGE statement.
+func (mb *mutationBuilder) buildAfterTriggersForUpsert() {
+	mb.buildAfterTriggers(tree.TriggerEventInsert)
+	mb.buildAfterTriggers(tree.TriggerEventUpdate)
+	if mb.mergeDeleteColID != 0 {
+		mb.buildAfterTriggers(tree.TriggerEventDelete)
+	}
+}
+
+// triggerRowCols returns the columns which hold the values of the new and the
+// old row passed to a trigger function for the given type of row modification.
+// ords are the ordinals of the visible columns of the target table. The new
+// row columns are nil for deletes, and the old row columns are nil for
+// inserts.
+func (mb *mutationBuilder) triggerRowCols(
+	event tree.TriggerEventType, ords []int,
+) (newCols, oldCols opt.ColList) {
+	switch event {
+	case tree.TriggerEventInsert:
+		newCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			newCols[i] = mb.insertColIDs[ord]
+		}
+	case tree.TriggerEventUpdate:
+		newCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			newCols[i] = mb.updateColIDs[ord]
+			if newCols[i] == 0 {
+				newCols[i] = mb.fetchColIDs[ord]
+			}
+		}
+	}
+	if event != tree.TriggerEventInsert {
+		oldCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			oldCols[i] = mb.fetchColIDs[ord]
+		}
+	}
+	return newCols, oldCols
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers.
+//
+// It provides a method to build a query which invokes the trigger function for
+// each modified row, equivalent to a query like:
+//
+//	SELECT f() FROM original_mutation_input
+//
+// For example:
+//
+//	project
+//	 ├── columns: trig:5
+//	 ├── with-scan &1
+//	 │    ├── columns: a:3 b:4
+//	 │    └── mapping:
+//	 │         ├──  column1:1 => a:3
+//	 │         └──  column2:2 => b:4
+//	 └── projections
+//	      └── udf: f [as=trig:5]
+//
+// For UPSERT and MERGE statements, the with-scan is filtered to the rows which
+// undergo the kind of modification the cascade is built for.
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	trigger      cat.Trigger
+	event        tree.TriggerEventType
+
+	// hasCanary is true if the old values passed to the cascade end with the
+	// canary column of an UPSERT or MERGE, followed by the MERGE delete column
+	// if hasMergeDelete is true.
+	hasCanary      bool
+	hasMergeDelete bool
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+func newAfterTriggerBuilder(
+	mutatedTable cat.Table, trigger cat.Trigger, event tree.TriggerEventType,
+) *afterTriggerBuilder {
+	return &afterTriggerBuilder{
+		mutatedTable: mutatedTable,
+		trigger:      trigger,
+		event:        event,
+	}
+}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *eval.Context,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		f := b.factory
+		md := f.Metadata()
+		recordType := b.resolveTriggerRecordType(tb.mutatedTable)
+
+		// Construct a dummy operator as the binding.
+		md.AddWithBinding(binding, f.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		inCols := make(opt.ColList, 0, len(newValues)+len(oldValues))
+		inCols = append(inCols, newValues...)
+		inCols = append(inCols, oldValues...)
+		outCols := make(opt.ColList, len(inCols))
+		for i := range inCols {
+			c := md.ColumnMeta(inCols[i])
+			outCols[i] = md.AddColumn(c.Alias, c.Type)
+		}
+		var input memo.RelExpr = f.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+		newCols, oldCols := outCols[:len(newValues)], outCols[len(newValues):]
+
+		// Only invoke the trigger for the rows which undergo its kind of
+		// modification.
+		if tb.hasCanary {
+			var canary, mergeDelete opt.ColumnID
+			if tb.hasMergeDelete {
+				mergeDelete = oldCols[len(oldCols)-1]
+				oldCols = oldCols[:len(oldCols)-1]
+			}
+			canary = oldCols[len(oldCols)-1]
+			oldCols = oldCols[:len(oldCols)-1]
+			input = f.ConstructSelect(input, memo.FiltersExpr{f.ConstructFiltersItem(
+				buildTriggerEventCond(f, tb.event, canary, mergeDelete),
+			)})
+		}
+
+		udf := b.buildTriggerFunction(
+			&tb.trigger, tb.mutatedTable, tb.event, recordType,
+			b.buildTriggerRow(newCols, recordType),
+			b.buildTriggerRow(oldCols, recordType),
+		)
+		trigCol := md.AddColumn(string(tb.trigger.Name), recordType)
+		return f.ConstructProject(
+			input,
+			memo.ProjectionsExpr{f.ConstructProjectionsItem(udf, trigCol)},
+			opt.ColSet{},
+		)
+	})
+}
+
+// resolveTriggerRecordType returns the implicit record type of the given
+// table, which is the type of the NEW and OLD rows of its triggers and of the
+// rows returned by trigger functions.
+func (b *Builder) resolveTriggerRecordType(tab cat.Table) *types.T {
+	recordType, err := b.catalog.ResolveTypeByOID(b.ctx, catid.TypeIDToOID(catid.DescID(tab.ID())))
+	if err != nil {
+		panic(err)
+	}
+	if n := len(visibleColumnOrdinals(tab)); len(recordType.TupleContents()) != n {
+		panic(errors.AssertionFailedf(
+			"expected record type of table %s to have %d columns, found %d",
+			tab.Name(), n, len(recordType.TupleContents()),
+		))
+	}
+	return recordType
+}
+
+// buildTriggerFunction builds a UDF expression which invokes the function of
+// the given trigger for a row of the given table. newRow and oldRow are the
+// values of the NEW and OLD variables. The implicit variables of the function
+// are its arguments; see the comment at the top of the file.
+func (b *Builder) buildTriggerFunction(
+	trig *cat.Trigger,
+	tab cat.Table,
+	event tree.TriggerEventType,
+	recordType *types.T,
+	newRow, oldRow opt.ScalarExpr,
+) opt.ScalarExpr {
+	f := b.factory
+	name, o, err := b.catalog.ResolveFunctionByOID(b.ctx, trig.FuncOID)
+	if err != nil {
+		panic(err)
+	}
+	if o.Types.Length() != 0 || o.Language != tree.FunctionLangPLpgSQL ||
+		o.ReturnType(nil /* args */).Family() != types.TriggerFamily {
+		panic(errors.AssertionFailedf(
+			"invalid function %s for trigger %s on table %s", name, trig.Name, tab.Name(),
+		))
+	}
+	tn, err := b.catalog.FullyQualifiedName(b.ctx, tab)
+	if err != nil {
+		panic(err)
+	}
+	when := tree.TriggerActionTimeAfter
+	if trig.Before {
+		when = tree.TriggerActionTimeBefore
+	}
+	textVal := func(s string) opt.ScalarExpr {
+		return f.ConstructConstVal(tree.NewDString(s), types.String)
+	}
+	nameVal := func(s string) opt.ScalarExpr {
+		return f.ConstructConstVal(tree.NewDName(s), types.Name)
+	}
+	vars := []struct {
+		name tree.Name
+		typ  *types.T
+		val  opt.ScalarExpr
+	}{
+		{"new", recordType, newRow},
+		{"old", recordType, oldRow},
+		{"tg_name", types.Name, nameVal(string(trig.Name))},
+		{"tg_when", types.String, textVal(when.String())},
+		{"tg_level", types.String, textVal("ROW")},
+		{"tg_op", types.String, textVal(event.String())},
+		{"tg_relid", types.Oid, f.ConstructConstVal(tree.NewDOid(oid.Oid(tab.ID())), types.Oid)},
+		{"tg_relname", types.Name, nameVal(string(tab.Name()))},
+		{"tg_table_name", types.Name, nameVal(string(tab.Name()))},
+		{"tg_table_schema", types.Name, nameVal(string(tn.SchemaName))},
+		{"tg_nargs", types.Int, f.ConstructConstVal(tree.NewDInt(0), types.Int)},
+		{"tg_argv", types.StringArray, f.ConstructConstVal(tree.NewDArray(types.String), types.StringArray)},
+	}
+
+	// The implicit variables are the arguments of the function, so that they
+	// can be referenced by the body like arguments.
+	bodyScope := b.allocScope()
+	input := make(memo.ScalarListExpr, len(vars))
+	for i := range vars {
+		col := b.synthesizeColumn(bodyScope, scopeColName(vars[i].name), vars[i].typ, nil /* expr */, nil /* scalar */)
+		col.setArgOrd(i)
+		input[i] = vars[i].val
+	}
+
+	block, err := plpgsqlparser.Parse(o.Body)
+	if err != nil {
+		panic(err)
+	}
+	body, program, varCols := b.buildPLpgSQL(name, block, bodyScope, recordType, false /* setReturning */)
+	return f.ConstructUDF(
+		input,
+		&memo.UDFPrivate{
+			Name:              name,
+			ArgCols:           varCols,
+			Body:              body,
+			Typ:               recordType,
+			Volatility:        o.Volatility,
+			CalledOnNullInput: true,
+			Program:           program,
+		},
+	)
+}
+
+// buildTriggerRow builds a tuple of the given record type from the given
+// columns, which hold the values of the visible columns of a row. If there are
+// no columns, a NULL of the record type is built instead.
+func (b *Builder) buildTriggerRow(cols opt.ColList, recordType *types.T) opt.ScalarExpr {
+	if len(cols) == 0 {
+		return b.factory.ConstructNull(recordType)
+	}
+	elems := make(memo.ScalarListExpr, len(cols))
+	for i := range cols {
+		elems[i] = b.factory.ConstructVariable(cols[i])
+	}
+	return b.factory.ConstructTuple(elems, recordType)
+}
+
+// visibleColumnOrdinals returns the ordinals of the visible columns of the
+// given table, which are the columns of the implicit record type of the table.
+func visibleColumnOrdinals(tab cat.Table) []int {
+	ords := make([]int, 0, tab.ColumnCount())
+	for i, n := 0, tab.ColumnCount(); i < n; i++ {
+		col := tab.Column(i)
+		if col.Kind() == cat.Ordinary && col.Visibility() == cat.Visible {
+			ords = append(ords, i)
+		}
+	}
+	return ords
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
 
 // UniquenessChecksForGenRandomUUIDClusterMode controls the cluster setting for
@@ -152,29 +153,9 @@ func (mb *mutationBuilder) hasUniqueWithoutIndexConstraints() bool {
 // constraint has a partial predicate, it also returns true if the predicate
 // references any of the columns being updated.
 func (mb *mutationBuilder) uniqueColsUpdated(uniqueOrdinal cat.UniqueOrdinal) bool {
-	uc := mb.tab.Unique(uniqueOrdinal)
-
-	for i, n := 0, uc.ColumnCount(); i < n; i++ {
-		if ord := uc.ColumnOrdinal(mb.tab, i); mb.updateColIDs[ord] != 0 {
-			return true
-		}
-	}
-
-	if _, isPartial := uc.Predicate(); isPartial {
-		pred := mb.parseUniqueConstraintPredicateExpr(uniqueOrdinal)
-		typedPred := mb.fetchScope.resolveAndRequireType(pred, types.Bool)
-
-		var predCols opt.ColSet
-		mb.b.buildScalar(typedPred, mb.fetchScope, nil, nil, &predCols)
-		for colID, ok := predCols.Next(0); ok; colID, ok = predCols.Next(colID + 1) {
-			ord := mb.md.ColumnMeta(colID).Table.ColumnOrdinal(colID)
-			if mb.updateColIDs[ord] != 0 {
-				return true
-			}
-		}
-	}
-
-	return false
+	return mb.constraintColsUpdated(mb.tab.Unique(uniqueOrdinal), func() tree.Expr {
+		return mb.parseUniqueConstraintPredicateExpr(uniqueOrdinal)
+	})
 }
 
 // uniqueConstraintIsArbiter returns true if the given unique constraint is used
@@ -187,24 +168,10 @@ func (mb *mutationBuilder) uniqueConstraintIsArbiter(uniqueOrdinal int) bool {
 // is used to build the "leaves" of a unique check expression, namely the
 // WithScan of the mutation input and the Scan of the table.
 type uniqueCheckHelper struct {
-	mb *mutationBuilder
+	conflictCheckHelper
 
 	unique        cat.UniqueConstraint
 	uniqueOrdinal int
-
-	// uniqueOrdinals are the table ordinals of the unique columns in the table
-	// that is being mutated. They correspond 1-to-1 to the columns in the
-	// UniqueConstraint.
-	uniqueOrdinals util.FastIntSet
-
-	// primaryKeyOrdinals includes the ordinals from any primary key columns
-	// that are not included in uniqueOrdinals.
-	primaryKeyOrdinals util.FastIntSet
-
-	// The scope and column ordinals of the scan that will serve as the right
-	// side of the semi join for the uniqueness checks.
-	scanScope    *scope
-	scanOrdinals []int
 }
 
 // init initializes the helper with a unique constraint.
@@ -215,7 +182,6 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 	// This initialization pattern ensures that fields are not unwittingly
 	// reused. Field reuse must be explicit.
 	*h = uniqueCheckHelper{
-		mb:            mb,
 		unique:        mb.tab.Unique(uniqueOrdinal),
 		uniqueOrdinal: uniqueOrdinal,
 	}
@@ -224,34 +190,26 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 	for i, n := 0, h.unique.ColumnCount(); i < n; i++ {
 		uniqueOrds.Add(h.unique.ColumnOrdinal(mb.tab, i))
 	}
+	var pred tree.Expr
+	if _, isPartial := h.unique.Predicate(); isPartial {
+		pred = mb.parseUniqueConstraintPredicateExpr(uniqueOrdinal)
+	}
 
-	// Find the primary key columns that are not part of the unique constraint.
-	// If there aren't any, we don't need a check.
+	// All the unique columns are compared with =, so no check is needed if the
+	// primary key columns are a subset of the unique columns.
 	// TODO(mgartner): We also don't need a check if there exists a unique index
 	// with columns that are a subset of the unique constraint columns.
 	// Similarly, we don't need a check for a partial unique constraint if there
 	// exists a non-partial unique constraint with columns that are a subset of
 	// the partial unique constraint columns.
-	primaryOrds := getIndexLaxKeyOrdinals(mb.tab.Index(cat.PrimaryIndex))
-	primaryOrds.DifferenceWith(uniqueOrds)
-	if primaryOrds.Empty() {
-		// The primary key columns are a subset of the unique columns; unique check
-		// not needed.
+	if !h.conflictCheckHelper.init(
+		mb, uniqueOrds.Ordered(), uniqueOrds, util.FastIntSet{} /* nullConflictOrds */, pred,
+	) {
 		return false
 	}
 
-	h.uniqueOrdinals = uniqueOrds
-	h.primaryKeyOrdinals = primaryOrds
-
-	for tabOrd, ok := h.uniqueOrdinals.Next(0); ok; tabOrd, ok = h.uniqueOrdinals.Next(tabOrd + 1) {
+	for _, tabOrd := range h.columnOrdinals {
 		colID := mb.mapToReturnColID(tabOrd)
-		// Check if we are setting NULL values for the unique columns, like when
-		// this mutation is the result of a SET NULL cascade action. If at least one
-		// unique column is getting a NULL value, unique check not needed.
-		if memo.OutputColumnIsAlwaysNull(mb.outScope.expr, colID) {
-			return false
-		}
-
 		// If one of the columns is a UUID set to gen_random_uuid() and we don't
 		// require uniqueness checks for gen_random_uuid(), unique check not needed.
 		if mb.md.ColumnMeta(colID).Type.Family() == types.UuidFamily &&
@@ -266,7 +224,7 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 	// Build the scan that will serve as the right side of the semi join in the
 	// uniqueness check. We need to build the scan now so that we can use its
 	// FDs below.
-	h.scanScope, h.scanOrdinals = h.buildTableScan()
+	h.buildScan()
 
 	// Check that the columns in the unique constraint aren't already known to
 	// form a lax key. This can happen if there is a unique index on a superset of
@@ -291,10 +249,9 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 	// presence of the unique index on (region, k) (i.e., the primary index) is
 	// sufficient to guarantee the uniqueness of k.
 	var uniqueCols opt.ColSet
-	h.uniqueOrdinals.ForEach(func(ord int) {
-		colID := h.scanScope.cols[ord].id
-		uniqueCols.Add(colID)
-	})
+	for _, ord := range h.columnOrdinals {
+		uniqueCols.Add(h.scanScope.cols[ord].id)
+	}
 	fds := &h.scanScope.expr.Relational().FuncDeps
 	return !fds.ColsAreLaxKey(uniqueCols)
 }
@@ -305,6 +262,152 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 	f := h.mb.b.factory
 
+	// The join filters are:
+	//   (new_a = existing_a) AND (new_b = existing_b) AND ...
+	check, keyCols := h.buildInsertionCheckInput(
+		func(newVals, existingVals []opt.ScalarExpr) []opt.ScalarExpr {
+			conditions := make([]opt.ScalarExpr, len(newVals))
+			for i := range newVals {
+				conditions[i] = f.ConstructEq(newVals[i], existingVals[i])
+			}
+			return conditions
+		},
+	)
+
+	return f.ConstructUniqueChecksItem(check, &memo.UniqueChecksItemPrivate{
+		Table:        h.mb.tabID,
+		CheckOrdinal: h.uniqueOrdinal,
+		KeyCols:      keyCols,
+		OpName:       h.mb.opName,
+		Deferrable:   h.mb.tab.Unique(h.uniqueOrdinal).Deferrable(),
+	})
+}
+
+// checkedConstraint is the part of the cat.UniqueConstraint and
+// cat.ExclusionConstraint interfaces which is used to build their checks.
+type checkedConstraint interface {
+	ColumnCount() int
+	ColumnOrdinal(tab cat.Table, i int) int
+	Predicate() (string, bool)
+}
+
+// constraintColsUpdated returns true if any of the columns for a unique or
+// exclusion constraint are being updated (according to updateColIDs). When the
+// constraint has a partial predicate, which is returned by parsePred, it also
+// returns true if the predicate references any of the columns being updated.
+func (mb *mutationBuilder) constraintColsUpdated(
+	c checkedConstraint, parsePred func() tree.Expr,
+) bool {
+	for i, n := 0, c.ColumnCount(); i < n; i++ {
+		if ord := c.ColumnOrdinal(mb.tab, i); mb.updateColIDs[ord] != 0 {
+			return true
+		}
+	}
+
+	if _, isPartial := c.Predicate(); isPartial {
+		typedPred := mb.fetchScope.resolveAndRequireType(parsePred(), types.Bool)
+
+		var predCols opt.ColSet
+		mb.b.buildScalar(typedPred, mb.fetchScope, nil, nil, &predCols)
+		for colID, ok := predCols.Next(0); ok; colID, ok = predCols.Next(colID + 1) {
+			ord := mb.md.ColumnMeta(colID).Table.ColumnOrdinal(colID)
+			if mb.updateColIDs[ord] != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// conflictCheckHelper holds the state shared by the helpers which build the
+// checks of UNIQUE WITHOUT INDEX and exclusion constraints. Both constraints
+// are enforced by a self semi-join of the new rows with the existing rows of
+// the table, which finds the new rows that conflict with another row. They
+// only differ in how the constrained columns of two rows are compared.
+type conflictCheckHelper struct {
+	mb *mutationBuilder
+
+	// columnOrdinals are the table ordinals of the constrained columns.
+	columnOrdinals []int
+
+	// pred is the predicate of a partial constraint, or nil.
+	pred tree.Expr
+
+	// primaryKeyOrdinals includes the ordinals from any primary key columns
+	// that are not compared with = by the constraint.
+	primaryKeyOrdinals util.FastIntSet
+
+	// The scope and column ordinals of the scan that will serve as the right
+	// side of the semi join for the checks.
+	scanScope    *scope
+	scanOrdinals []int
+}
+
+// init initializes the helper with the table ordinals of the constrained
+// columns, the subset of them which are compared with =, the subset of them
+// whose NULL values may still conflict with other values, and the predicate of
+// a partial constraint. The scan of the table is built separately by
+// buildScan.
+//
+// Returns false if the constraint should be ignored, because two different
+// rows can never conflict with each other.
+func (h *conflictCheckHelper) init(
+	mb *mutationBuilder,
+	columnOrdinals []int,
+	eqOrds util.FastIntSet,
+	nullConflictOrds util.FastIntSet,
+	pred tree.Expr,
+) bool {
+	*h = conflictCheckHelper{
+		mb:             mb,
+		columnOrdinals: columnOrdinals,
+		pred:           pred,
+	}
+
+	// Find the primary key columns that are not compared with = by the
+	// constraint. If there aren't any, two different rows can never conflict
+	// with each other, so we don't need a check.
+	primaryOrds := getIndexLaxKeyOrdinals(mb.tab.Index(cat.PrimaryIndex))
+	primaryOrds.DifferenceWith(eqOrds)
+	if primaryOrds.Empty() {
+		return false
+	}
+	h.primaryKeyOrdinals = primaryOrds
+
+	// Check if we are setting NULL values for the constrained columns, like
+	// when this mutation is the result of a SET NULL cascade action. NULL
+	// values never conflict with other values, except for the bounds of
+	// ranges, where NULL means unbounded.
+	for _, ord := range columnOrdinals {
+		if nullConflictOrds.Contains(ord) {
+			continue
+		}
+		if memo.OutputColumnIsAlwaysNull(mb.outScope.expr, mb.mapToReturnColID(ord)) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildScan builds the scan of the table that will serve as the right side of
+// the semi join.
+func (h *conflictCheckHelper) buildScan() {
+	h.scanScope, h.scanOrdinals = h.mb.buildCheckTableScan()
+}
+
+// buildInsertionCheckInput builds the input of a check for rows which are
+// added to a table, which will be produced from the input to the mutation
+// operator. The constrained columns of the new and existing rows, in order,
+// are compared by the conditions returned by compare. The input returns the
+// constrained columns of the new rows which conflict with another row, which
+// are also returned as the key columns shown in the error message of a
+// violation.
+func (h *conflictCheckHelper) buildInsertionCheckInput(
+	compare func(newVals, existingVals []opt.ScalarExpr) []opt.ScalarExpr,
+) (memo.RelExpr, opt.ColList) {
+	f := h.mb.b.factory
+
 	// Build a self semi-join, with the new values on the left and the
 	// existing values on the right.
 
@@ -312,42 +415,39 @@ func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 		checkInputScanNewVals, h.scanOrdinals, false, /* isFK */
 	)
 
-	// Build the join filters:
-	//   (new_a = existing_a) AND (new_b = existing_b) AND ...
-	//
-	// Set the capacity to h.uniqueOrdinals.Len()+1 since we'll have an equality
-	// condition for each column in the unique constraint, plus one additional
+	newVals := make([]opt.ScalarExpr, len(h.columnOrdinals))
+	existingVals := make([]opt.ScalarExpr, len(h.columnOrdinals))
+	for i, ord := range h.columnOrdinals {
+		newVals[i] = f.ConstructVariable(withScanScope.cols[ord].id)
+		existingVals[i] = f.ConstructVariable(h.scanScope.cols[ord].id)
+	}
+	conditions := compare(newVals, existingVals)
+
+	// Set the capacity to len(conditions)+1 since we'll have a filter for each
+	// condition comparing the constrained columns, plus one additional
 	// condition to prevent rows from matching themselves (see below). If the
 	// constraint is partial, add 2 to account for filtering both the WithScan
-	// and the Scan by the partial unique constraint predicate.
-	numFilters := h.uniqueOrdinals.Len() + 1
-	_, isPartial := h.unique.Predicate()
-	if isPartial {
+	// and the Scan by the predicate.
+	numFilters := len(conditions) + 1
+	if h.pred != nil {
 		numFilters += 2
 	}
 	semiJoinFilters := make(memo.FiltersExpr, 0, numFilters)
-	for i, ok := h.uniqueOrdinals.Next(0); ok; i, ok = h.uniqueOrdinals.Next(i + 1) {
-		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
-			f.ConstructEq(
-				f.ConstructVariable(withScanScope.cols[i].id),
-				f.ConstructVariable(h.scanScope.cols[i].id),
-			),
-		))
+	for _, cond := range conditions {
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(cond))
 	}
 
-	// If the unique constraint is partial, we need to filter out inserted rows
-	// that don't satisfy the predicate. We also need to make sure that rows do
-	// not match existing rows in the table that do not satisfy the
-	// predicate. So we add the predicate as a filter on both the WithScan
-	// columns and the Scan columns.
-	if isPartial {
-		pred := h.mb.parseUniqueConstraintPredicateExpr(h.uniqueOrdinal)
-
-		typedPred := withScanScope.resolveAndRequireType(pred, types.Bool)
+	// If the constraint is partial, we need to filter out inserted rows that
+	// don't satisfy the predicate. We also need to make sure that rows do not
+	// match existing rows in the table that do not satisfy the predicate. So we
+	// add the predicate as a filter on both the WithScan columns and the Scan
+	// columns.
+	if h.pred != nil {
+		typedPred := withScanScope.resolveAndRequireType(h.pred, types.Bool)
 		withScanPred := h.mb.b.buildScalar(typedPred, withScanScope, nil, nil, nil)
 		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(withScanPred))
 
-		typedPred = h.scanScope.resolveAndRequireType(pred, types.Bool)
+		typedPred = h.scanScope.resolveAndRequireType(h.pred, types.Bool)
 		scanPred := h.mb.b.buildScalar(typedPred, h.scanScope, nil, nil, nil)
 		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(scanPred))
 	}
@@ -373,47 +473,58 @@ func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 	semiJoin := f.ConstructSemiJoin(withScanScope.expr, h.scanScope.expr, semiJoinFilters, memo.EmptyJoinPrivate)
 
 	// Collect the key columns that will be shown in the error message if there
-	// is a duplicate key violation resulting from this uniqueness check.
-	keyCols := make(opt.ColList, 0, h.uniqueOrdinals.Len())
-	for i, ok := h.uniqueOrdinals.Next(0); ok; i, ok = h.uniqueOrdinals.Next(i + 1) {
-		keyCols = append(keyCols, withScanScope.cols[i].id)
+	// is a constraint violation resulting from this check.
+	keyCols := make(opt.ColList, len(h.columnOrdinals))
+	for i, ord := range h.columnOrdinals {
+		keyCols[i] = withScanScope.cols[ord].id
 	}
 
 	// Create a Project that passes-through only the key columns. This allows
 	// normalization rules to prune any unnecessary columns from the expression.
 	// The key columns are always needed in order to display the constraint
 	// violation error.
-	project := f.ConstructProject(semiJoin, nil /* projections */, keyCols.ToSet())
-
-	return f.ConstructUniqueChecksItem(project, &memo.UniqueChecksItemPrivate{
-		Table:        h.mb.tabID,
-		CheckOrdinal: h.uniqueOrdinal,
-		KeyCols:      keyCols,
-		OpName:       h.mb.opName,
-	})
+	return f.ConstructProject(semiJoin, nil /* projections */, keyCols.ToSet()), keyCols
 }
 
-// buildTableScan builds a Scan of the table. The ordinals of the columns
-// scanned are also returned.
-func (h *uniqueCheckHelper) buildTableScan() (outScope *scope, ordinals []int) {
-	tabMeta := h.mb.b.addTable(h.mb.tab, tree.NewUnqualifiedTableName(h.mb.tab.Name()))
+// buildCheckTableScan builds a Scan of the table for a uniqueness or exclusion
+// check. The ordinals of the columns scanned are also returned.
+func (mb *mutationBuilder) buildCheckTableScan() (outScope *scope, ordinals []int) {
+	mb.ensureUniquenessEnforceableByReads()
+	tabMeta := mb.b.addTable(mb.tab, tree.NewUnqualifiedTableName(mb.tab.Name()))
 	ordinals = tableOrdinals(tabMeta.Table, columnKinds{
 		includeMutations: false,
 		includeSystem:    false,
 		includeInverted:  false,
 	})
-	return h.mb.b.buildScan(
+	return mb.b.buildScan(
 		tabMeta,
 		ordinals,
 		// After the update we can't guarantee that the constraints are unique
 		// (which is why we need the uniqueness checks in the first place).
 		&tree.IndexFlags{IgnoreUniqueWithoutIndexKeys: true},
 		noRowLocking,
-		h.mb.b.allocScope(),
+		mb.b.allocScope(),
 		true, /* disableNotVisibleIndex */
 	), ordinals
 }
 
+// ensureUniquenessEnforceableByReads panics with an unimplemented error if the
+// transaction is at an isolation level which tolerates write skew, like READ
+// COMMITTED. Uniqueness constraints which are not enforced by an index, and
+// exclusion constraints, are enforced by reading the table to look for
+// conflicting rows. Nothing prevents a concurrent transaction from inserting a
+// conflicting row which the read did not see; SERIALIZABLE transactions detect
+// this when they refresh their reads, but transactions which tolerate write
+// skew commit without refreshing them. Locking the rows which were read would
+// not help, since the conflicting row doesn't exist yet.
+func (mb *mutationBuilder) ensureUniquenessEnforceableByReads() {
+	if mb.b.evalCtx.TxnIsoLevel().ToleratesWriteSkew() {
+		panic(unimplemented.Newf("read committed uniqueness checks",
+			"%s on table %q requires a uniqueness or exclusion check, which is not yet "+
+				"supported under READ COMMITTED isolation", mb.opName, mb.tab.Name()))
+	}
+}
+
 // columnIsGenRandomUUID returns true if the expression returns the function
 // gen_random_uuid() for the given column.
 func columnIsGenRandomUUID(e memo.RelExpr, col opt.ColumnID) bool {
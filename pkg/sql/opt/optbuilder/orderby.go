@@ -289,4 +289,12 @@ func ensureColumnOrderable(e tree.TypedExpr) {
 		(typ.Family() == types.ArrayFamily && typ.ArrayContents().Family() == types.JsonFamily) {
 		panic(unimplementedWithIssueDetailf(35706, "", "can't order by column type jsonb"))
 	}
+	if typ.Family() == types.ArrayFamily {
+		typ = typ.ArrayContents()
+	}
+	switch typ.Family() {
+	case types.TSQueryFamily, types.TSVectorFamily:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"can't order by column type %s", typ.SQLString()))
+	}
 }
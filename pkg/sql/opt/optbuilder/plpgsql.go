@@ -0,0 +1,893 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// plpgsqlBuilder compiles the body of a PL/pgSQL function into a
+// tree.RoutineProgram. Control flow is lowered into jumps between instructions,
+// and every SQL expression or statement in the body is built into a relational
+// expression that becomes one of the statements of the routine. The variables
+// of the body are represented by columns that the statements refer to as outer
+// columns; they are replaced with the current values of the variables when a
+// statement is planned.
+type plpgsqlBuilder struct {
+	ob *Builder
+
+	// retType is the return type of the function. If setReturning is true, it
+	// is the type of the rows of the returned set.
+	retType      *types.T
+	setReturning bool
+
+	// vars are the variables of the program and cols are the columns that
+	// represent them, in the same order.
+	vars []tree.RoutineVar
+	cols []scopeColumn
+
+	// constant is true for the ordinals of the variables declared CONSTANT.
+	constant []bool
+
+	// levels are the nested blocks that declare the variables that are visible
+	// at the current point of the body, outermost first.
+	levels []plpgsqlLevel
+
+	// frames are the nested blocks and loops that enclose the current point of
+	// the body, outermost first. They are the targets of EXIT and CONTINUE.
+	frames []*plpgsqlFrame
+
+	instrs     []tree.RoutineInstr
+	stmts      memo.RelListExpr
+	numCursors int
+
+	// inHandler is the number of exception handlers enclosing the current
+	// point of the body.
+	inHandler int
+}
+
+// plpgsqlLevel is a set of variables declared together, e.g. by the DECLARE
+// section of a block. The variables can be qualified by label.
+type plpgsqlLevel struct {
+	label tree.Name
+	vars  []int
+}
+
+// plpgsqlFrame is a block or loop that can be left with EXIT, or continued
+// with CONTINUE if it is a loop.
+type plpgsqlFrame struct {
+	label tree.Name
+	loop  bool
+
+	// handlers is true while the body of a block with exception handlers is
+	// being built. The handlers must be uninstalled when leaving the frame.
+	handlers bool
+
+	// cursor is the ordinal of the cursor of a FOR loop over a query, or -1.
+	// The cursor must be closed when leaving the frame.
+	cursor int
+
+	// exits and continues are the indexes of the jumps that leave the frame
+	// and that start the next iteration of the loop, respectively. They are
+	// patched once the targets are known.
+	exits, continues []int
+}
+
+// buildPLpgSQL compiles the body of a PL/pgSQL function into a program and the
+// statements it executes. argScope contains the columns of the arguments of
+// the function, which become the first variables of the program. Arguments can
+// be qualified by the name of the function. The returned columns represent the
+// variables of the program, in order. setReturning is true if the function
+// returns SETOF retType.
+func (b *Builder) buildPLpgSQL(
+	funcName string,
+	block *plpgsqltree.Block,
+	argScope *scope,
+	retType *types.T,
+	setReturning bool,
+) (memo.RelListExpr, *tree.RoutineProgram, opt.ColList) {
+	pb := plpgsqlBuilder{ob: b, retType: retType, setReturning: setReturning}
+	args := plpgsqlLevel{label: tree.Name(funcName)}
+	for i := range argScope.cols {
+		col := argScope.cols[i]
+		args.vars = append(args.vars, len(pb.cols))
+		pb.vars = append(pb.vars, tree.RoutineVar{Name: col.name.MetadataName(), Typ: col.typ})
+		pb.cols = append(pb.cols, col)
+		pb.constant = append(pb.constant, false)
+	}
+	pb.levels = append(pb.levels, args)
+	pb.buildBlock(block)
+
+	cols := make(opt.ColList, len(pb.cols))
+	for i := range pb.cols {
+		cols[i] = pb.cols[i].id
+	}
+	return pb.stmts, &tree.RoutineProgram{
+		Vars:       pb.vars,
+		Instrs:     pb.instrs,
+		NumCursors: pb.numCursors,
+	}, cols
+}
+
+// scope returns a scope in which the variables that are visible at the current
+// point of the body can be referenced. Variables of inner blocks shadow the
+// variables of outer blocks with the same name.
+func (pb *plpgsqlBuilder) scope() *scope {
+	s := pb.ob.allocScope()
+	for _, level := range pb.levels {
+		s = s.push()
+		for _, ord := range level.vars {
+			col := pb.cols[ord]
+			if level.label != "" {
+				col.table = tree.MakeUnqualifiedTableName(level.label)
+			}
+			s.cols = append(s.cols, col)
+		}
+	}
+	return s
+}
+
+// addVar adds a variable with the given name and type to the program and
+// returns its ordinal. The variable is not visible in the body until it is
+// added to a level.
+func (pb *plpgsqlBuilder) addVar(name scopeColumnName, typ *types.T, notNull bool) int {
+	ord := len(pb.cols)
+	id := pb.ob.factory.Metadata().AddColumn(name.MetadataName(), typ)
+	pb.cols = append(pb.cols, scopeColumn{name: name, typ: typ, id: id})
+	pb.vars = append(pb.vars, tree.RoutineVar{Name: name.MetadataName(), Typ: typ, NotNull: notNull})
+	pb.constant = append(pb.constant, false)
+	return ord
+}
+
+// lookupVar returns the ordinal of the visible variable with the given name.
+func (pb *plpgsqlBuilder) lookupVar(name tree.Name) int {
+	for i := len(pb.levels) - 1; i >= 0; i-- {
+		vars := pb.levels[i].vars
+		for j := len(vars) - 1; j >= 0; j-- {
+			if pb.cols[vars[j]].name.MatchesReferenceName(name) {
+				return vars[j]
+			}
+		}
+	}
+	panic(pgerror.Newf(pgcode.Syntax, "\"%s\" is not a known variable", name))
+}
+
+// lookupTarget returns the ordinal of the visible variable with the given name,
+// which is assigned by the body.
+func (pb *plpgsqlBuilder) lookupTarget(name tree.Name) int {
+	ord := pb.lookupVar(name)
+	if pb.constant[ord] {
+		panic(pgerror.Newf(pgcode.Syntax, "variable \"%s\" is declared CONSTANT", name))
+	}
+	return ord
+}
+
+// emit appends the given instruction to the program and returns its index.
+func (pb *plpgsqlBuilder) emit(instr tree.RoutineInstr) int {
+	pb.instrs = append(pb.instrs, instr)
+	return len(pb.instrs) - 1
+}
+
+// emitJump appends a jump with a target that is patched later and returns its
+// index.
+func (pb *plpgsqlBuilder) emitJump(op tree.RoutineOp, stmt int) int {
+	return pb.emit(tree.RoutineInstr{Op: op, Stmt: stmt})
+}
+
+// patch sets the target of the given jumps to the next instruction.
+func (pb *plpgsqlBuilder) patch(jumps ...int) {
+	for _, i := range jumps {
+		pb.instrs[i].Target = len(pb.instrs)
+	}
+}
+
+// buildSQL builds the given SQL statement in the scope of the visible
+// variables and adds it to the statements of the routine. If typs is non-nil,
+// the columns returned by the statement are cast to the given types.
+func (pb *plpgsqlBuilder) buildSQL(stmt tree.Statement, typs []*types.T) int {
+	stmtScope := pb.ob.buildStmt(stmt, typs, pb.scope())
+	return pb.addStmt(stmtScope.expr, stmtScope.makePhysicalProps(), typs)
+}
+
+// buildExpr builds a statement that returns the value of the given expression,
+// cast to the given type.
+func (pb *plpgsqlBuilder) buildExpr(expr tree.Expr, typ *types.T) int {
+	return pb.buildSQL(exprSelect(expr), []*types.T{typ})
+}
+
+// buildCond builds a statement that returns the value of the given boolean
+// expression. context is the kind of statement in which the expression
+// appears, for error messages.
+func (pb *plpgsqlBuilder) buildCond(expr tree.Expr, context string) int {
+	stmtScope := pb.ob.buildStmt(exprSelect(expr), []*types.T{types.Bool}, pb.scope())
+	physProps := stmtScope.makePhysicalProps()
+	typ := pb.ob.factory.Metadata().ColumnMeta(physProps.Presentation[0].ID).Type
+	if typ.Family() != types.BoolFamily && typ.Family() != types.UnknownFamily {
+		panic(pgerror.Newf(pgcode.DatatypeMismatch,
+			"argument of %s must be type bool, not type %s", context, typ))
+	}
+	return pb.addStmt(stmtScope.expr, physProps, []*types.T{types.Bool})
+}
+
+// buildScalar builds a statement that returns the value of the given scalar
+// expression, which can only refer to variables.
+func (pb *plpgsqlBuilder) buildScalar(scalar opt.ScalarExpr, typ *types.T) int {
+	f := pb.ob.factory
+	id := f.Metadata().AddColumn("", typ)
+	input := f.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+		Cols: opt.ColList{},
+		ID:   f.Metadata().NextUniqueID(),
+	})
+	expr := f.ConstructProject(
+		input, memo.ProjectionsExpr{f.ConstructProjectionsItem(scalar, id)}, opt.ColSet{},
+	)
+	return pb.addStmt(expr, &physical.Required{
+		Presentation: physical.Presentation{opt.AliasedColumn{ID: id}},
+	}, nil /* typs */)
+}
+
+// addStmt adds a statement to the routine and returns its index. If typs is
+// non-nil, the columns returned by the statement are cast to the given types.
+func (pb *plpgsqlBuilder) addStmt(
+	expr memo.RelExpr, physProps *physical.Required, typs []*types.T,
+) int {
+	if typs != nil {
+		expr, physProps = pb.castColumns(expr, physProps, typs)
+	}
+	pb.stmts = append(pb.stmts, memo.RelRequiredPropsExpr{
+		RelExpr:   expr,
+		PhysProps: physProps,
+	})
+	return len(pb.stmts) - 1
+}
+
+// castColumns adds assignment casts to the columns returned by the given
+// expression so that their types match typs. The ordering of the expression is
+// preserved.
+func (pb *plpgsqlBuilder) castColumns(
+	expr memo.RelExpr, physProps *physical.Required, typs []*types.T,
+) (memo.RelExpr, *physical.Required) {
+	f := pb.ob.factory
+	md := f.Metadata()
+	cols := physProps.Presentation
+	if len(cols) != len(typs) {
+		panic(pgerror.Newf(pgcode.DatatypeMismatch,
+			"query returned %d columns, but %d values were expected", len(cols), len(typs)))
+	}
+	var projections memo.ProjectionsExpr
+	presentation := make(physical.Presentation, len(cols))
+	for i, col := range cols {
+		presentation[i] = col
+		typ := md.ColumnMeta(col.ID).Type
+		if typ.Identical(typs[i]) {
+			continue
+		}
+		if !cast.ValidCast(typ, typs[i], cast.ContextAssignment) {
+			panic(sqlerrors.NewInvalidAssignmentCastError(typ, typs[i], col.Alias))
+		}
+		id := md.AddColumn(col.Alias, typs[i])
+		projections = append(projections, f.ConstructProjectionsItem(
+			f.ConstructAssignmentCast(f.ConstructVariable(col.ID), typs[i]), id,
+		))
+		presentation[i].ID = id
+	}
+	if len(projections) == 0 {
+		return expr, physProps
+	}
+	newProps := *physProps
+	newProps.Presentation = presentation
+	return f.ConstructProject(expr, projections, expr.Relational().OutputCols), &newProps
+}
+
+// fieldAssignmentExpr returns an expression for the value of the given
+// variable of a record type after the given field is assigned the given value.
+// The other fields keep their current values.
+func (pb *plpgsqlBuilder) fieldAssignmentExpr(
+	varName, field tree.Name, typ *types.T, value tree.Expr,
+) tree.Expr {
+	if typ.Family() != types.TupleFamily {
+		panic(pgerror.Newf(pgcode.Syntax, "\"%s\" is not a record variable", varName))
+	}
+	labels := typ.TupleLabels()
+	fieldIdx := -1
+	for i := range labels {
+		if labels[i] == string(field) {
+			fieldIdx = i
+			break
+		}
+	}
+	if fieldIdx == -1 {
+		panic(pgerror.Newf(pgcode.UndefinedColumn,
+			"record \"%s\" has no field \"%s\"", varName, field))
+	}
+	exprs := make(tree.Exprs, len(labels))
+	for i := range exprs {
+		if i == fieldIdx {
+			exprs[i] = value
+			continue
+		}
+		exprs[i] = &tree.ColumnAccessExpr{
+			Expr:    tree.NewUnresolvedName(string(varName)),
+			ColName: tree.Name(labels[i]),
+		}
+	}
+	return &tree.Tuple{Exprs: exprs, Labels: labels}
+}
+
+// exprSelect returns a SELECT statement that returns the value of the given
+// expression.
+func exprSelect(expr tree.Expr) tree.Statement {
+	return &tree.Select{Select: &tree.SelectClause{Exprs: tree.SelectExprs{{Expr: expr}}}}
+}
+
+func (pb *plpgsqlBuilder) buildStmts(stmts []plpgsqltree.Statement) {
+	for _, stmt := range stmts {
+		pb.buildStatement(stmt)
+	}
+}
+
+func (pb *plpgsqlBuilder) buildStatement(stmt plpgsqltree.Statement) {
+	switch t := stmt.(type) {
+	case *plpgsqltree.Block:
+		pb.buildBlock(t)
+
+	case *plpgsqltree.Assignment:
+		ord := pb.lookupTarget(t.Var)
+		value := t.Value
+		if t.Field != "" {
+			value = pb.fieldAssignmentExpr(t.Var, t.Field, pb.vars[ord].Typ, value)
+		}
+		pb.emit(tree.RoutineInstr{
+			Op:   tree.RoutineAssign,
+			Stmt: pb.buildExpr(value, pb.vars[ord].Typ),
+			Vars: []int{ord},
+		})
+
+	case *plpgsqltree.If:
+		var ends []int
+		next := pb.emitJump(tree.RoutineJumpIfNot, pb.buildCond(t.Condition, "IF"))
+		pb.buildStmts(t.ThenBody)
+		for _, elseIf := range t.ElseIfList {
+			ends = append(ends, pb.emitJump(tree.RoutineJump, -1 /* stmt */))
+			pb.patch(next)
+			next = pb.emitJump(tree.RoutineJumpIfNot, pb.buildCond(elseIf.Condition, "ELSIF"))
+			pb.buildStmts(elseIf.Stmts)
+		}
+		if t.ElseBody != nil {
+			ends = append(ends, pb.emitJump(tree.RoutineJump, -1 /* stmt */))
+			pb.patch(next)
+			pb.buildStmts(t.ElseBody)
+		} else {
+			ends = append(ends, next)
+		}
+		pb.patch(ends...)
+
+	case *plpgsqltree.Loop:
+		pb.pushFrame(t.Label, true /* loop */)
+		top := len(pb.instrs)
+		pb.buildStmts(t.Body)
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineJump, Stmt: -1, Target: top})
+		pb.popFrame(top)
+
+	case *plpgsqltree.While:
+		frame := pb.pushFrame(t.Label, true /* loop */)
+		top := len(pb.instrs)
+		frame.exits = append(frame.exits,
+			pb.emitJump(tree.RoutineJumpIfNot, pb.buildCond(t.Condition, "WHILE")))
+		pb.buildStmts(t.Body)
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineJump, Stmt: -1, Target: top})
+		pb.popFrame(top)
+
+	case *plpgsqltree.ForInt:
+		pb.buildForInt(t)
+
+	case *plpgsqltree.ForQuery:
+		pb.buildForQuery(t)
+
+	case *plpgsqltree.Exit:
+		pb.buildExitOrContinue(t.Label, t.Condition, false /* isContinue */)
+
+	case *plpgsqltree.Continue:
+		pb.buildExitOrContinue(t.Label, t.Condition, true /* isContinue */)
+
+	case *plpgsqltree.Return:
+		pb.buildReturn(t)
+
+	case *plpgsqltree.ReturnNext:
+		pb.buildReturnNext(t)
+
+	case *plpgsqltree.ReturnQuery:
+		pb.buildReturnQuery(t)
+
+	case *plpgsqltree.Raise:
+		pb.buildRaise(t)
+
+	case *plpgsqltree.Perform:
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineExec, Stmt: pb.buildSQL(t.Query, nil /* typs */)})
+
+	case *plpgsqltree.Execute:
+		if len(t.Targets) == 0 {
+			if _, ok := t.SQLStmt.(*tree.Select); ok {
+				panic(errors.WithHint(
+					pgerror.New(pgcode.Syntax, "query has no destination for result data"),
+					"If you want to discard the results of a SELECT, use PERFORM instead.",
+				))
+			}
+			pb.emit(tree.RoutineInstr{Op: tree.RoutineExec, Stmt: pb.buildSQL(t.SQLStmt, nil /* typs */)})
+			break
+		}
+		vars, typs := pb.lookupTargets(t.Targets)
+		pb.emit(tree.RoutineInstr{
+			Op:     tree.RoutineAssign,
+			Stmt:   pb.buildSQL(t.SQLStmt, typs),
+			Vars:   vars,
+			Strict: t.Strict,
+		})
+
+	case *plpgsqltree.Null:
+
+	default:
+		panic(errors.AssertionFailedf("unexpected PL/pgSQL statement %T", stmt))
+	}
+}
+
+// lookupTargets returns the ordinals and types of the variables with the given
+// names, which are assigned by the body.
+func (pb *plpgsqlBuilder) lookupTargets(names tree.NameList) (vars []int, typs []*types.T) {
+	vars = make([]int, len(names))
+	typs = make([]*types.T, len(names))
+	for i, name := range names {
+		vars[i] = pb.lookupTarget(name)
+		typs[i] = pb.vars[vars[i]].Typ
+	}
+	return vars, typs
+}
+
+// buildBlock builds a block. The variables it declares are initialized in
+// order, so the default value of a variable can refer to the variables declared
+// before it. If the block has exception handlers, they are installed while the
+// body of the block executes:
+//
+//	<initialize variables>
+//	BeginHandlers
+//	<body>
+//	EndHandlers
+//	Jump end
+//	handler1: <action>
+//	Jump end
+//	...
+//	end:
+func (pb *plpgsqlBuilder) buildBlock(block *plpgsqltree.Block) {
+	pb.levels = append(pb.levels, plpgsqlLevel{label: block.Label})
+	defer func() { pb.levels = pb.levels[:len(pb.levels)-1] }()
+	level := &pb.levels[len(pb.levels)-1]
+
+	for i := range block.Decls {
+		decl := &block.Decls[i]
+		for _, ord := range level.vars {
+			if pb.cols[ord].name.MatchesReferenceName(decl.Var) {
+				panic(pgerror.Newf(pgcode.DuplicateObject, "duplicate declaration of variable \"%s\"", decl.Var))
+			}
+		}
+		typ, err := tree.ResolveType(pb.ob.ctx, decl.Typ, pb.ob.semaCtx.TypeResolver)
+		if err != nil {
+			panic(err)
+		}
+		if pb.ob.trackSchemaDeps {
+			typeIDs, err := typedesc.GetTypeDescriptorClosure(typ)
+			if err != nil {
+				panic(err)
+			}
+			for typeID := range typeIDs {
+				pb.ob.schemaTypeDeps.Add(int(typeID))
+			}
+		}
+		if decl.NotNull && decl.Default == nil {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"variable \"%s\" must have a default value, since it's declared NOT NULL", decl.Var))
+		}
+		stmt := -1
+		if decl.Default != nil {
+			stmt = pb.buildExpr(decl.Default, typ)
+		}
+		ord := pb.addVar(scopeColName(decl.Var), typ, decl.NotNull)
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineAssign, Stmt: stmt, Vars: []int{ord}})
+		// The variable is only visible after it is initialized, and it can
+		// be initialized even if it is a constant.
+		pb.constant[ord] = decl.Constant
+		level.vars = append(level.vars, ord)
+	}
+
+	frame := pb.pushFrame(block.Label, false /* loop */)
+	if len(block.Exceptions) == 0 {
+		pb.buildStmts(block.Body)
+		pb.popFrame(-1 /* top */)
+		return
+	}
+
+	// The SQLSTATE and message of the handled error are available to the
+	// handlers as variables.
+	sqlState := pb.addVar(scopeColName("sqlstate"), types.String, false /* notNull */)
+	sqlErrM := pb.addVar(scopeColName("sqlerrm"), types.String, false /* notNull */)
+	begin := pb.emit(tree.RoutineInstr{
+		Op:       tree.RoutineBeginHandlers,
+		Stmt:     -1,
+		Vars:     []int{sqlState, sqlErrM},
+		Handlers: make([]tree.RoutineHandler, len(block.Exceptions)),
+	})
+	frame.handlers = true
+	pb.buildStmts(block.Body)
+	frame.handlers = false
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineEndHandlers, Stmt: -1})
+	frame.exits = append(frame.exits, pb.emitJump(tree.RoutineJump, -1 /* stmt */))
+
+	pb.levels = append(pb.levels, plpgsqlLevel{label: block.Label, vars: []int{sqlState, sqlErrM}})
+	pb.inHandler++
+	for i := range block.Exceptions {
+		exception := &block.Exceptions[i]
+		handler := &pb.instrs[begin].Handlers[i]
+		handler.Target = len(pb.instrs)
+		for _, cond := range exception.Conditions {
+			if cond.SQLState != "" {
+				handler.Codes = append(handler.Codes, cond.SQLState)
+				continue
+			}
+			if cond.Name == "others" {
+				handler.Codes = nil
+				break
+			}
+			codes, _ := plpgsqltree.LookupCondition(cond.Name)
+			handler.Codes = append(handler.Codes, codes...)
+		}
+		pb.buildStmts(exception.Action)
+		frame.exits = append(frame.exits, pb.emitJump(tree.RoutineJump, -1 /* stmt */))
+	}
+	pb.inHandler--
+	pb.levels = pb.levels[:len(pb.levels)-1]
+	pb.popFrame(-1 /* top */)
+}
+
+// pushFrame pushes a new block or loop frame with the given label.
+func (pb *plpgsqlBuilder) pushFrame(label tree.Name, loop bool) *plpgsqlFrame {
+	frame := &plpgsqlFrame{label: label, loop: loop, cursor: -1}
+	pb.frames = append(pb.frames, frame)
+	return frame
+}
+
+// popFrame pops the innermost frame. Its exits are patched to jump to the next
+// instruction, and its continues to top.
+func (pb *plpgsqlBuilder) popFrame(top int) {
+	frame := pb.frames[len(pb.frames)-1]
+	pb.frames = pb.frames[:len(pb.frames)-1]
+	pb.patch(frame.exits...)
+	for _, i := range frame.continues {
+		pb.instrs[i].Target = top
+	}
+}
+
+// buildForInt builds a FOR loop over a range of integers. The bounds and the
+// step are evaluated once, before the loop, and a hidden counter drives the
+// loop so that assignments to the loop variable do not affect the iteration:
+//
+//	Assign counter, upper, step
+//	top: JumpIfNot counter <= upper -> end
+//	Assign var = counter
+//	<body>
+//	next: Assign counter = counter + step
+//	Jump top
+//	end:
+func (pb *plpgsqlBuilder) buildForInt(loop *plpgsqltree.ForInt) {
+	f := pb.ob.factory
+	counter := pb.addVar(scopeColName("").WithMetadataName("for_counter"), types.Int, false /* notNull */)
+	upper := pb.addVar(scopeColName("").WithMetadataName("for_upper"), types.Int, false /* notNull */)
+	pb.emit(tree.RoutineInstr{
+		Op: tree.RoutineAssign, Stmt: pb.buildExpr(loop.Lower, types.Int), Vars: []int{counter},
+	})
+	pb.emit(tree.RoutineInstr{
+		Op: tree.RoutineAssign, Stmt: pb.buildExpr(loop.Upper, types.Int), Vars: []int{upper},
+	})
+	step := f.ConstructConstVal(tree.NewDInt(1), types.Int)
+	if loop.Step != nil {
+		stepVar := pb.addVar(scopeColName("").WithMetadataName("for_step"), types.Int, false /* notNull */)
+		pb.emit(tree.RoutineInstr{
+			Op: tree.RoutineAssign, Stmt: pb.buildExpr(loop.Step, types.Int), Vars: []int{stepVar},
+		})
+		step = f.ConstructVariable(pb.cols[stepVar].id)
+		positive := pb.buildScalar(
+			f.ConstructGt(step, f.ConstructConstVal(tree.NewDInt(0), types.Int)), types.Bool,
+		)
+		check := pb.emitJump(tree.RoutineJumpIfNot, positive)
+		skip := pb.emitJump(tree.RoutineJump, -1 /* stmt */)
+		pb.patch(check)
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineRaise, Stmt: -1, Raise: &tree.RoutineMessage{
+			Level:   "EXCEPTION",
+			Message: "BY value of FOR loop must be greater than zero",
+			Code:    pgcode.InvalidParameterValue.String(),
+		}})
+		pb.patch(skip)
+	}
+
+	counterVar := f.ConstructVariable(pb.cols[counter].id)
+	upperVar := f.ConstructVariable(pb.cols[upper].id)
+	cond, next := f.ConstructLe(counterVar, upperVar), f.ConstructPlus(counterVar, step)
+	if loop.Reverse {
+		cond, next = f.ConstructGe(counterVar, upperVar), f.ConstructMinus(counterVar, step)
+	}
+
+	// The loop variable is only visible in the body of the loop.
+	loopVar := pb.addVar(scopeColName(loop.Var), types.Int, false /* notNull */)
+	pb.levels = append(pb.levels, plpgsqlLevel{label: loop.Label, vars: []int{loopVar}})
+	defer func() { pb.levels = pb.levels[:len(pb.levels)-1] }()
+
+	frame := pb.pushFrame(loop.Label, true /* loop */)
+	top := len(pb.instrs)
+	frame.exits = append(frame.exits, pb.emitJump(tree.RoutineJumpIfNot, pb.buildScalar(cond, types.Bool)))
+	pb.emit(tree.RoutineInstr{
+		Op: tree.RoutineAssign, Stmt: pb.buildScalar(counterVar, types.Int), Vars: []int{loopVar},
+	})
+	pb.buildStmts(loop.Body)
+	nextIdx := pb.emit(tree.RoutineInstr{
+		Op: tree.RoutineAssign, Stmt: pb.buildScalar(next, types.Int), Vars: []int{counter},
+	})
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineJump, Stmt: -1, Target: top})
+	pb.popFrame(nextIdx)
+}
+
+// buildForQuery builds a FOR loop over the rows returned by a query. The rows
+// are buffered in a cursor before the first iteration:
+//
+//	OpenCursor c
+//	top: Fetch c -> end
+//	<body>
+//	Jump top
+//	end: CloseCursor c
+func (pb *plpgsqlBuilder) buildForQuery(loop *plpgsqltree.ForQuery) {
+	vars, typs := pb.lookupTargets(loop.Targets)
+	cursor := pb.numCursors
+	pb.numCursors++
+	pb.emit(tree.RoutineInstr{
+		Op:     tree.RoutineOpenCursor,
+		Stmt:   pb.buildSQL(loop.Query, typs),
+		Vars:   vars,
+		Cursor: cursor,
+	})
+	frame := pb.pushFrame(loop.Label, true /* loop */)
+	frame.cursor = cursor
+	top := len(pb.instrs)
+	frame.exits = append(frame.exits, pb.emit(tree.RoutineInstr{
+		Op: tree.RoutineFetch, Stmt: -1, Vars: vars, Cursor: cursor,
+	}))
+	pb.buildStmts(loop.Body)
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineJump, Stmt: -1, Target: top})
+	pb.popFrame(top)
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineCloseCursor, Stmt: -1, Cursor: cursor})
+}
+
+// buildExitOrContinue builds an EXIT or CONTINUE statement. The frames that
+// are left by the jump are unwound: their handlers are uninstalled and their
+// cursors are closed.
+func (pb *plpgsqlBuilder) buildExitOrContinue(label tree.Name, cond tree.Expr, isContinue bool) {
+	stmtName := "EXIT"
+	if isContinue {
+		stmtName = "CONTINUE"
+	}
+	target := -1
+	for i := len(pb.frames) - 1; i >= 0; i-- {
+		frame := pb.frames[i]
+		if label == "" && frame.loop {
+			target = i
+			break
+		}
+		if label != "" && frame.label == label {
+			if isContinue && !frame.loop {
+				panic(pgerror.Newf(pgcode.Syntax,
+					"block label \"%s\" cannot be used in CONTINUE", label))
+			}
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		switch {
+		case label != "":
+			panic(pgerror.Newf(pgcode.Syntax,
+				"there is no label \"%s\" attached to any block or loop enclosing this statement", label))
+		case isContinue:
+			panic(pgerror.New(pgcode.Syntax, "CONTINUE cannot be used outside a loop"))
+		default:
+			panic(pgerror.New(pgcode.Syntax, "EXIT cannot be used outside a loop, unless it has a label"))
+		}
+	}
+
+	skip := -1
+	if cond != nil {
+		skip = pb.emitJump(tree.RoutineJumpIfNot, pb.buildCond(cond, stmtName))
+	}
+	for i := len(pb.frames) - 1; i >= target; i-- {
+		frame := pb.frames[i]
+		if frame.handlers {
+			pb.emit(tree.RoutineInstr{Op: tree.RoutineEndHandlers, Stmt: -1})
+		}
+		if frame.cursor >= 0 && i > target {
+			pb.emit(tree.RoutineInstr{Op: tree.RoutineCloseCursor, Stmt: -1, Cursor: frame.cursor})
+		}
+	}
+	jump := pb.emitJump(tree.RoutineJump, -1 /* stmt */)
+	if frame := pb.frames[target]; isContinue {
+		frame.continues = append(frame.continues, jump)
+	} else {
+		frame.exits = append(frame.exits, jump)
+	}
+	if skip >= 0 {
+		pb.patch(skip)
+	}
+}
+
+// buildReturn builds a RETURN statement.
+func (pb *plpgsqlBuilder) buildReturn(ret *plpgsqltree.Return) {
+	if ret.Expr == nil {
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineReturn, Stmt: -1})
+		return
+	}
+	if pb.setReturning {
+		panic(errors.WithHint(
+			pgerror.New(pgcode.DatatypeMismatch,
+				"RETURN cannot have a parameter in function returning set"),
+			"Use RETURN NEXT or RETURN QUERY.",
+		))
+	}
+	if pb.retType.Family() == types.VoidFamily {
+		panic(pgerror.New(pgcode.DatatypeMismatch,
+			"RETURN cannot have a parameter in function returning void"))
+	}
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineReturn, Stmt: pb.buildExpr(ret.Expr, pb.retType)})
+}
+
+// buildReturnNext builds a RETURN NEXT statement, which adds a single row to
+// the result of a function returning SETOF.
+func (pb *plpgsqlBuilder) buildReturnNext(ret *plpgsqltree.ReturnNext) {
+	if !pb.setReturning {
+		panic(pgerror.New(pgcode.Syntax, "cannot use RETURN NEXT in a non-SETOF function"))
+	}
+	pb.emit(tree.RoutineInstr{
+		Op:   tree.RoutineReturnQuery,
+		Stmt: pb.buildExpr(ret.Expr, pb.retType),
+	})
+}
+
+// buildReturnQuery builds a RETURN QUERY statement, which adds the rows of the
+// query to the result of a function returning SETOF. If the query returns
+// multiple columns, they are combined into a tuple of the return type.
+func (pb *plpgsqlBuilder) buildReturnQuery(ret *plpgsqltree.ReturnQuery) {
+	if !pb.setReturning {
+		panic(pgerror.New(pgcode.Syntax, "cannot use RETURN QUERY in a non-SETOF function"))
+	}
+	f := pb.ob.factory
+	stmtScope := pb.ob.buildStmt(ret.Query, nil /* desiredTypes */, pb.scope())
+	expr, physProps := stmtScope.expr, stmtScope.makePhysicalProps()
+	if cols := physProps.Presentation; len(cols) > 1 && pb.retType.Family() == types.TupleFamily {
+		elems := make(memo.ScalarListExpr, len(cols))
+		for i := range cols {
+			elems[i] = f.ConstructVariable(cols[i].ID)
+		}
+		id := f.Metadata().AddColumn("", pb.retType)
+		expr = f.ConstructProject(
+			expr,
+			memo.ProjectionsExpr{f.ConstructProjectionsItem(f.ConstructTuple(elems, pb.retType), id)},
+			expr.Relational().OutputCols,
+		)
+		newProps := *physProps
+		newProps.Presentation = physical.Presentation{opt.AliasedColumn{ID: id}}
+		physProps = &newProps
+	}
+	if len(physProps.Presentation) != 1 {
+		panic(pgerror.New(pgcode.DatatypeMismatch,
+			"structure of query does not match function result type"))
+	}
+	pb.emit(tree.RoutineInstr{
+		Op:   tree.RoutineReturnQuery,
+		Stmt: pb.addStmt(expr, physProps, []*types.T{pb.retType}),
+	})
+}
+
+// buildRaise builds a RAISE statement. The format parameters and the values of
+// the options are computed by a single statement as strings.
+func (pb *plpgsqlBuilder) buildRaise(raise *plpgsqltree.Raise) {
+	if raise.Level == "" && raise.Message == "" && raise.Condition == (plpgsqltree.Condition{}) &&
+		len(raise.Options) == 0 {
+		if pb.inHandler == 0 {
+			panic(pgerror.New(pgcode.StackedDiagnosticsAccessedWithoutActiveHandler,
+				"RAISE without parameters cannot be used outside an exception handler"))
+		}
+		pb.emit(tree.RoutineInstr{Op: tree.RoutineRaise, Stmt: -1, Raise: &tree.RoutineMessage{}})
+		return
+	}
+
+	msg := &tree.RoutineMessage{
+		Level:     raise.Level,
+		Message:   raise.Message,
+		NumParams: len(raise.Params),
+	}
+	if msg.Level == "" {
+		msg.Level = "EXCEPTION"
+	}
+	numPlaceholders := 0
+	for i := 0; i < len(raise.Message); i++ {
+		if raise.Message[i] == '%' {
+			if i+1 < len(raise.Message) && raise.Message[i+1] == '%' {
+				i++
+				continue
+			}
+			numPlaceholders++
+		}
+	}
+	if numPlaceholders > len(raise.Params) {
+		panic(pgerror.New(pgcode.Syntax, "too few parameters specified for RAISE"))
+	}
+	if numPlaceholders < len(raise.Params) {
+		panic(pgerror.New(pgcode.Syntax, "too many parameters specified for RAISE"))
+	}
+	if cond := raise.Condition; cond.SQLState != "" {
+		msg.Code = cond.SQLState
+		if msg.Message == "" {
+			msg.Message = cond.SQLState
+		}
+	} else if cond.Name != "" {
+		codes, _ := plpgsqltree.LookupCondition(cond.Name)
+		msg.Code = codes[0]
+		if msg.Message == "" {
+			msg.Message = cond.Name
+		}
+	}
+
+	exprs := make(tree.SelectExprs, 0, len(raise.Params)+len(raise.Options))
+	for _, param := range raise.Params {
+		exprs = append(exprs, tree.SelectExpr{Expr: &tree.CastExpr{
+			Expr: param, Type: types.String, SyntaxMode: tree.CastShort,
+		}})
+	}
+	for _, option := range raise.Options {
+		for _, name := range msg.Options {
+			if name == option.Name {
+				panic(pgerror.Newf(pgcode.Syntax, "RAISE option already specified: %s", name))
+			}
+		}
+		if (option.Name == "MESSAGE" && raise.Message != "") ||
+			(option.Name == "ERRCODE" && raise.Condition != (plpgsqltree.Condition{})) {
+			panic(pgerror.Newf(pgcode.Syntax, "RAISE option already specified: %s", option.Name))
+		}
+		msg.Options = append(msg.Options, option.Name)
+		exprs = append(exprs, tree.SelectExpr{Expr: &tree.CastExpr{
+			Expr: option.Value, Type: types.String, SyntaxMode: tree.CastShort,
+		}})
+	}
+
+	stmt := -1
+	if len(exprs) > 0 {
+		typs := make([]*types.T, len(exprs))
+		for i := range typs {
+			typs[i] = types.String
+		}
+		stmt = pb.buildSQL(&tree.Select{Select: &tree.SelectClause{Exprs: exprs}}, typs)
+	}
+	pb.emit(tree.RoutineInstr{Op: tree.RoutineRaise, Stmt: stmt, Raise: msg})
+}
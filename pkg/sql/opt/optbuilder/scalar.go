@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/cast"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
@@ -424,6 +425,9 @@ func (b *Builder) buildScalar(
 	case *sqlFnInfo:
 		out = b.buildSQLFn(t, inScope, outScope, outCol, colRefs)
 
+	case *tree.GroupingOperation:
+		out = b.buildGroupingOperation(t, inScope)
+
 	case *srf:
 		if len(t.cols) == 1 {
 			if inGroupingContext {
@@ -610,6 +614,16 @@ func (b *Builder) buildUDF(
 	colRefs *opt.ColSet,
 ) (out opt.ScalarExpr) {
 	o := f.ResolvedOverload()
+	if o.IsProcedure {
+		panic(errors.WithHint(
+			pgerror.Newf(pgcode.WrongObjectType, "%s is a procedure", def.Name),
+			"To call a procedure, use CALL.",
+		))
+	}
+
+	if f.ResolvedType().Family() == types.TriggerFamily {
+		panic(pgerror.New(pgcode.FeatureNotSupported, "trigger functions can only be called as triggers"))
+	}
 
 	// Build the input expressions.
 	var input memo.ScalarListExpr
@@ -626,6 +640,24 @@ func (b *Builder) buildUDF(
 		}
 	}
 
+	out = b.buildRoutine(def.Name, o, input, f.ResolvedType())
+	if o.Class == tree.GeneratorClass {
+		return b.finishBuildGeneratorFunction(f, o, out, inScope, outScope, outCol)
+	}
+	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+}
+
+// buildRoutine builds a UDF expression that invokes the given user-defined
+// function overload with the given input expressions. typ is the type of the
+// result of the invocation.
+//
+// If the overload is a procedure, only the segment of its body selected by
+// b.ProcedureSegment is built, or the last segment if the statement is only
+// prepared, and b.ProcedureTxnControl is set to the statement that ends the
+// segment. The result of a segment that is not the last one is void.
+func (b *Builder) buildRoutine(
+	name string, o *tree.Overload, input memo.ScalarListExpr, typ *types.T,
+) opt.ScalarExpr {
 	// Create a new scope for building the statements in the function body. We
 	// start with an empty scope because a statement in the function body cannot
 	// refer to anything from the outer expression. If there are function
@@ -652,23 +684,69 @@ func (b *Builder) buildUDF(
 		}
 	}
 
+	// Compile the body of a PL/pgSQL function into a program that drives the
+	// execution of its statements.
+	if o.Language == tree.FunctionLangPLpgSQL {
+		block, err := plpgsqlparser.Parse(o.Body)
+		if err != nil {
+			panic(err)
+		}
+		body, program, varCols := b.buildPLpgSQL(name, block, bodyScope, typ, o.ReturnSet)
+		return b.factory.ConstructUDF(
+			input,
+			&memo.UDFPrivate{
+				Name:              name,
+				ArgCols:           varCols,
+				Body:              body,
+				Typ:               typ,
+				Volatility:        o.Volatility,
+				CalledOnNullInput: o.CalledOnNullInput,
+				Program:           program,
+				SetReturning:      o.ReturnSet,
+			},
+		)
+	}
+
 	// Parse the function body.
-	stmts, err := parser.Parse(o.Body)
+	parsed, err := parser.Parse(o.Body)
 	if err != nil {
 		panic(err)
 	}
+	stmts := make([]tree.Statement, len(parsed))
+	for i := range parsed {
+		stmts[i] = parsed[i].AST
+	}
+	if o.IsProcedure {
+		segment := b.ProcedureSegment
+		if b.evalCtx.PrepareOnly {
+			segment = -1
+		}
+		stmts, b.ProcedureTxnControl = procedureSegment(name, stmts, segment)
+		if b.ProcedureTxnControl != nil {
+			typ = types.Void
+		}
+		// Each statement in the body of a procedure is executed separately, so
+		// mutations of the same table in different statements do not conflict.
+		prevMutations := b.areAllTableMutationsSimpleInserts
+		defer func() { b.areAllTableMutationsSimpleInserts = prevMutations }()
+	}
 
 	// Build an expression for each statement in the function body.
 	rels := make(memo.RelListExpr, len(stmts))
 	for i := range stmts {
-		stmtScope := b.buildStmt(stmts[i].AST, nil /* desiredTypes */, bodyScope)
+		if o.IsProcedure {
+			b.areAllTableMutationsSimpleInserts = nil
+		}
+		stmtScope := b.buildStmt(stmts[i], nil /* desiredTypes */, bodyScope)
 		expr := stmtScope.expr
 		physProps := stmtScope.makePhysicalProps()
 
 		// Add a LIMIT 1 to the last statement. This is valid because any other
 		// rows after the first can simply be ignored. The limit could be
-		// beneficial because it could allow additional optimization.
-		if i == len(stmts)-1 {
+		// beneficial because it could allow additional optimization. The result
+		// of a procedure without OUT arguments is void, so the result of its
+		// last statement is ignored.
+		if i == len(stmts)-1 && !(o.IsProcedure && typ.Family() == types.VoidFamily) {
 			b.buildLimit(&tree.Limit{Count: tree.NewDInt(1)}, b.allocScope(), stmtScope)
 			expr = stmtScope.expr
 			// The limit expression will maintain the desired ordering, if any,
@@ -677,15 +755,23 @@ func (b *Builder) buildUDF(
 			physProps.Ordering = props.OrderingChoice{}
 
 			// If there are multiple output columns, we must combine them into a
-			// tuple - only a single column can be returned from a UDF.
-			if cols := physProps.Presentation; len(cols) > 1 {
+			// tuple - only a single column can be returned from a UDF. The result
+			// of a procedure with OUT arguments is always a tuple, and its
+			// elements are cast to the types of the OUT arguments.
+			if cols := physProps.Presentation; len(cols) > 1 || o.IsProcedure {
 				elems := make(memo.ScalarListExpr, len(cols))
 				for i := range cols {
 					elems[i] = b.factory.ConstructVariable(cols[i].ID)
+					if o.IsProcedure {
+						colType := b.factory.Metadata().ColumnMeta(cols[i].ID).Type
+						if elemType := typ.TupleContents()[i]; !colType.Identical(elemType) {
+							elems[i] = b.factory.ConstructAssignmentCast(elems[i], elemType)
+						}
+					}
 				}
-				tup := b.factory.ConstructTuple(elems, f.ResolvedType())
+				tup := b.factory.ConstructTuple(elems, typ)
 				stmtScope = bodyScope.push()
-				col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, tup)
+				col := b.synthesizeColumn(stmtScope, scopeColName(""), typ, nil /* expr */, tup)
 				expr = b.constructProject(expr, []scopeColumn{*col})
 				physProps = stmtScope.makePhysicalProps()
 			}
@@ -694,17 +780,17 @@ func (b *Builder) buildUDF(
 			// its type matches the function return type.
 			returnCol := physProps.Presentation[0].ID
 			returnColMeta := b.factory.Metadata().ColumnMeta(returnCol)
-			if returnColMeta.Type != f.ResolvedType() {
-				if !cast.ValidCast(returnColMeta.Type, f.ResolvedType(), cast.ContextAssignment) {
+			if returnColMeta.Type != typ {
+				if !cast.ValidCast(returnColMeta.Type, typ, cast.ContextAssignment) {
 					panic(sqlerrors.NewInvalidAssignmentCastError(
-						returnColMeta.Type, f.ResolvedType(), returnColMeta.Alias))
+						returnColMeta.Type, typ, returnColMeta.Alias))
 				}
 				cast := b.factory.ConstructAssignmentCast(
 					b.factory.ConstructVariable(physProps.Presentation[0].ID),
-					f.ResolvedType(),
+					typ,
 				)
 				stmtScope = bodyScope.push()
-				col := b.synthesizeColumn(stmtScope, scopeColName(""), f.ResolvedType(), nil /* expr */, cast)
+				col := b.synthesizeColumn(stmtScope, scopeColName(""), typ, nil /* expr */, cast)
 				expr = b.constructProject(expr, []scopeColumn{*col})
 				physProps = stmtScope.makePhysicalProps()
 			}
@@ -716,18 +802,17 @@ func (b *Builder) buildUDF(
 		}
 	}
 
-	out = b.factory.ConstructUDF(
+	return b.factory.ConstructUDF(
 		input,
 		&memo.UDFPrivate{
-			Name:              def.Name,
+			Name:              name,
 			ArgCols:           argCols,
 			Body:              rels,
-			Typ:               f.ResolvedType(),
+			Typ:               typ,
 			Volatility:        o.Volatility,
 			CalledOnNullInput: o.CalledOnNullInput,
 		},
 	)
-	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
 }
 
 // buildRangeCond builds a RANGE clause as a simpler expression. Examples:
@@ -902,6 +987,8 @@ func (b *Builder) constructComparison(
 			return b.factory.ConstructBBoxIntersects(left, right)
 		}
 		return b.factory.ConstructOverlaps(left, right)
+	case treecmp.TSMatches:
+		return b.factory.ConstructTSMatches(left, right)
 	}
 	panic(errors.AssertionFailedf("unhandled comparison operator: %s", redact.Safe(cmp.Operator)))
 }
@@ -651,6 +651,31 @@ func (s *scope) findFuncArgCol(idx tree.PlaceholderIdx) *scopeColumn {
 	return nil
 }
 
+// resolveArgFieldAccess returns an expression which accesses a field of a
+// function argument of a record type, if the given column item has the form
+// arg.field. In PL/pgSQL, such references take precedence over columns of
+// tables, but here they are only considered if the item does not resolve to a
+// column. If the item does not refer to a record argument, nil is returned.
+func (s *scope) resolveArgFieldAccess(c *tree.ColumnItem) *tree.ColumnAccessExpr {
+	if c.TableName == nil || c.TableName.NumParts != 1 {
+		return nil
+	}
+	name := tree.Name(c.TableName.Parts[0])
+	for ; s != nil; s = s.parent {
+		for i := len(s.cols) - 1; i >= 0; i-- {
+			col := &s.cols[i]
+			if col.argOrd == 0 || !col.name.MatchesReferenceName(name) {
+				continue
+			}
+			if col.typ.Family() != types.TupleFamily {
+				return nil
+			}
+			return &tree.ColumnAccessExpr{Expr: col, ColName: c.ColumnName}
+		}
+	}
+	return nil
+}
+
 // startAggFunc is called when the builder starts building an aggregate
 // function. It is used to disallow nested aggregates and ensure that a
 // grouping error is not called on the aggregate arguments. For example:
@@ -1025,6 +1050,11 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 	case *tree.ColumnItem:
 		colI, resolveErr := colinfo.ResolveColumnItem(s.builder.ctx, s, t)
 		if resolveErr != nil {
+			if access := s.resolveArgFieldAccess(t); access != nil {
+				// The item is a reference to a field of a record argument, such as
+				// NEW.a in the body of a trigger function.
+				return false, access
+			}
 			if sqlerrors.IsUndefinedColumnError(resolveErr) {
 				// Attempt to resolve as columnname.*, which allows items
 				// such as SELECT row_to_json(tbl_name) FROM tbl_name to work.
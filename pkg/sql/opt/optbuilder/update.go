@@ -327,6 +327,10 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 // buildUpdate constructs an Update operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
+	// Invoke any BEFORE UPDATE triggers, which may modify or skip the rows to
+	// update.
+	mb.buildBeforeTriggers(tree.TriggerEventUpdate)
+
 	// Disambiguate names so that references in any expressions, such as a
 	// check constraint, refer to the correct columns.
 	mb.disambiguateColumns()
@@ -344,8 +348,12 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpdate()
 
+	mb.buildExclusionChecksForUpdate()
+
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerEventUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
@@ -595,6 +595,12 @@ func (b *Builder) resolveTableForMutation(
 		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate materialized view %q", tab.Name()))
 	}
 
+	// We can't mutate tables attached from a backup.
+	if tab.IsBackupTable() {
+		panic(pgerror.Newf(pgcode.WrongObjectType,
+			"cannot mutate table %q attached from a backup", tab.Name()))
+	}
+
 	return tab, depName, alias, columns
 }
 
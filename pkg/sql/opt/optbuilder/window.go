@@ -245,6 +245,11 @@ func (b *Builder) buildAggregationAsWindow(
 	groupingColSet opt.ColSet, having opt.ScalarExpr, fromScope *scope,
 ) *scope {
 	g := fromScope.groupby
+	if g.groupingSets != nil {
+		// Partition and group by the grouping set ID in addition to the grouping
+		// columns.
+		groupingColSet = g.groupingSets.groupingColSet()
+	}
 
 	// Create the window frames based on the orderings and groupings specified.
 	argLists := make([][]opt.ScalarExpr, len(g.aggs))
@@ -282,23 +287,31 @@ func (b *Builder) buildAggregationAsWindow(
 	// Initialize the aggregate expression.
 	aggregateExpr := g.aggInScope.expr
 
-	// frames accumulates the set of distinct window frames we're computing over
-	// so that we can group functions over the same partition and ordering.
-	frames := make([]memo.WindowExpr, 0, len(g.aggs))
+	fns := make([]opt.ScalarExpr, len(g.aggs))
 	for i, agg := range g.aggs {
-		fn := b.constructAggregate(agg.def.Name, argLists[i])
+		fns[i] = b.constructAggregate(agg.def.Name, argLists[i])
 		if filterCols[i] != 0 {
-			fn = b.factory.ConstructAggFilter(
-				fn,
+			fns[i] = b.factory.ConstructAggFilter(
+				fns[i],
 				b.factory.ConstructVariable(filterCols[i]),
 			)
 		}
+	}
 
+	if g.groupingSets != nil {
+		// Expand each input row into one row per grouping set.
+		aggregateExpr = b.constructGroupingSetsInput(g, aggregateExpr, fns)
+	}
+
+	// frames accumulates the set of distinct window frames we're computing over
+	// so that we can group functions over the same partition and ordering.
+	frames := make([]memo.WindowExpr, 0, len(g.aggs))
+	for i, agg := range g.aggs {
 		frameIdx := b.findMatchingFrameIndex(&frames, partitions[i], orderings[i])
 
 		frames[frameIdx].Windows = append(frames[frameIdx].Windows,
 			b.factory.ConstructWindowsItem(
-				fn,
+				fns[i],
 				&memo.WindowsItemPrivate{
 					Frame: windowAggregateFrame(),
 					Col:   agg.col.id,
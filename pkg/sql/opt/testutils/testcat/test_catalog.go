@@ -747,6 +747,11 @@ func (tt *Table) IsMaterializedView() bool {
 	return false
 }
 
+// IsBackupTable is part of the cat.Table interface.
+func (tt *Table) IsBackupTable() bool {
+	return false
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (tt *Table) ColumnCount() int {
 	return len(tt.Columns)
@@ -837,6 +842,26 @@ func (tt *Table) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	return &tt.uniqueConstraints[i]
 }
 
+// ExclusionCount is part of the cat.Table interface.
+func (tt *Table) ExclusionCount() int {
+	return 0
+}
+
+// Exclusion is part of the cat.Table interface.
+func (tt *Table) Exclusion(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
+// TriggerCount is part of the cat.Table interface.
+func (tt *Table) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (tt *Table) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
 // Zone is part of the cat.Table interface.
 func (tt *Table) Zone() cat.Zone {
 	zone := zonepb.DefaultZoneConfig()
@@ -1293,6 +1318,9 @@ type ForeignKeyConstraint struct {
 	matchMethod  tree.CompositeKeyMatchMethod
 	deleteAction tree.ReferenceAction
 	updateAction tree.ReferenceAction
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.ForeignKeyConstraint = &ForeignKeyConstraint{}
@@ -1360,6 +1388,16 @@ func (fk *ForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction {
 	return fk.updateAction
 }
 
+// Deferrable is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) Deferrable() bool {
+	return fk.deferrable
+}
+
+// InitiallyDeferred is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) InitiallyDeferred() bool {
+	return fk.initiallyDeferred
+}
+
 // UniqueConstraint implements cat.UniqueConstraint. See that interface
 // for more information on the fields.
 type UniqueConstraint struct {
@@ -1369,6 +1407,9 @@ type UniqueConstraint struct {
 	predicate      string
 	withoutIndex   bool
 	validated      bool
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.UniqueConstraint = &UniqueConstraint{}
@@ -1420,6 +1461,16 @@ func (u *UniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
 	return false
 }
 
+// Deferrable is part of the cat.UniqueConstraint interface.
+func (u *UniqueConstraint) Deferrable() bool {
+	return u.deferrable
+}
+
+// InitiallyDeferred is part of the cat.UniqueConstraint interface.
+func (u *UniqueConstraint) InitiallyDeferred() bool {
+	return u.initiallyDeferred
+}
+
 // Sequence implements the cat.Sequence interface for testing purposes.
 type Sequence struct {
 	SeqID      cat.StableID
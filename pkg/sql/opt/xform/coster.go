@@ -693,6 +693,12 @@ func (c *coster) computeScanCost(scan *memo.ScanExpr, required *physical.Require
 		// practice, this will only happen when this is a primary index scan.
 		return hugeCost
 	}
+	if c.mem.Metadata().Table(scan.Table).IsBackupTable() &&
+		ordering.ScanIsReverse(scan, &required.Ordering) {
+		// Tables attached from a backup are read from the files of the backup,
+		// which can only be read in the forward direction.
+		return hugeCost
+	}
 
 	isUnfiltered := scan.IsUnfiltered(c.mem.Metadata())
 	if scan.Flags.NoFullScan {
@@ -932,6 +938,11 @@ func (c *coster) computeLookupJoinCost(
 	if join.LookupJoinPrivate.Flags.Has(memo.DisallowLookupJoinIntoRight) {
 		return hugeCost
 	}
+	if c.mem.Metadata().Table(join.Table).IsBackupTable() {
+		// Tables attached from a backup are read from the files of the backup,
+		// in which rows cannot be looked up one at a time.
+		return hugeCost
+	}
 	cost := c.computeIndexLookupJoinCost(
 		join,
 		required,
@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/config"
@@ -718,6 +719,8 @@ type optTable struct {
 
 	uniqueConstraints []optUniqueConstraint
 
+	exclusionConstraints []optExclusionConstraint
+
 	outboundFKs []optForeignKeyConstraint
 	inboundFKs  []optForeignKeyConstraint
 
@@ -726,6 +729,10 @@ type optTable struct {
 	// constraints for user defined types.
 	checkConstraints []cat.CheckConstraint
 
+	// triggers is the set of row-level triggers for this table, ordered by
+	// name.
+	triggers []cat.Trigger
+
 	// colMap is a mapping from unique ColumnID to column ordinal within the
 	// table. This is a common lookup that needs to be fast.
 	colMap catalog.TableColMap
@@ -779,6 +786,17 @@ func newOptTable(
 			kind = cat.DeleteOnly
 			visibility = cat.Inaccessible
 		}
+		// Columns of a domain type inherit the NOT NULL constraint and the
+		// default value of the domain.
+		nullable, defaultExpr := col.IsNullable(), col.ColumnDesc().DefaultExpr
+		if typ := col.GetType(); typ.IsDomain() && typ.TypeMeta.DomainData != nil {
+			if typ.TypeMeta.DomainData.NotNull {
+				nullable = false
+			}
+			if defaultExpr == nil {
+				defaultExpr = typ.TypeMeta.DomainData.DefaultExpr
+			}
+		}
 		// Primary key columns that are virtual in the descriptor are considered
 		// "stored" from the perspective of the optimizer because they are
 		// written to the primary index and all secondary indexes.
@@ -789,9 +807,9 @@ func newOptTable(
 				col.ColName(),
 				kind,
 				col.GetType(),
-				col.IsNullable(),
+				nullable,
 				visibility,
-				col.ColumnDesc().DefaultExpr,
+				defaultExpr,
 				col.ColumnDesc().ComputeExpr,
 				col.ColumnDesc().OnUpdateExpr,
 				mapGeneratedAsIdentityType(col.GetGeneratedAsIdentityType()),
@@ -855,12 +873,14 @@ func newOptTable(
 	for i := range ot.desc.GetUniqueWithoutIndexConstraints() {
 		u := &ot.desc.GetUniqueWithoutIndexConstraints()[i]
 		ot.uniqueConstraints = append(ot.uniqueConstraints, optUniqueConstraint{
-			name:         u.Name,
-			table:        ot.ID(),
-			columns:      u.ColumnIDs,
-			predicate:    u.Predicate,
-			withoutIndex: true,
-			validity:     u.Validity,
+			name:              u.Name,
+			table:             ot.ID(),
+			columns:           u.ColumnIDs,
+			predicate:         u.Predicate,
+			withoutIndex:      true,
+			validity:          u.Validity,
+			deferrable:        u.Deferrable,
+			initiallyDeferred: u.InitiallyDeferred,
 		})
 	}
 
@@ -952,6 +972,20 @@ func newOptTable(
 				})
 			}
 		}
+
+		// Add exclusion constraints for the indexes that back them. Indexes that
+		// are being added are included so that the constraint is enforced on
+		// writes while the existing rows are backfilled and validated.
+		if idx.IsExclusion() && !idx.Dropped() && !idx.IsTemporaryIndexForBackfill() {
+			ot.exclusionConstraints = append(ot.exclusionConstraints, optExclusionConstraint{
+				name:      idx.GetName(),
+				table:     ot.ID(),
+				columns:   idx.IndexDesc().KeyColumnIDs[idx.IndexDesc().ExplicitColumnStartIdx():],
+				predicate: idx.GetPredicate(),
+				isRange:   idx.GetExclusionRangeFunc() != "",
+				validated: idx.Public(),
+			})
+		}
 	}
 
 	_ = ot.desc.ForeachOutboundFK(func(fk *descpb.ForeignKeyConstraint) error {
@@ -965,6 +999,8 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 		return nil
 	})
@@ -979,6 +1015,8 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrable:        fk.Deferrable,
+			initiallyDeferred: fk.InitiallyDeferred,
 		})
 		return nil
 	})
@@ -1025,6 +1063,24 @@ func newOptTable(
 	}
 	ot.checkConstraints = append(ot.checkConstraints, synthesizedChecks...)
 
+	// Move all triggers into the opt table, in the order in which they fire.
+	if triggers := desc.GetTriggers(); len(triggers) > 0 {
+		ot.triggers = make([]cat.Trigger, len(triggers))
+		for i := range triggers {
+			ot.triggers[i] = cat.Trigger{
+				Name:     tree.Name(triggers[i].Name),
+				Before:   triggers[i].ActionTime == descpb.TriggerDescriptor_BEFORE,
+				OnInsert: triggers[i].OnInsert,
+				OnUpdate: triggers[i].OnUpdate,
+				OnDelete: triggers[i].OnDelete,
+				FuncOID:  catid.FuncIDToOID(triggers[i].FuncID),
+			}
+		}
+		sort.Slice(ot.triggers, func(i, j int) bool {
+			return ot.triggers[i].Name < ot.triggers[j].Name
+		})
+	}
+
 	// Add stats last, now that other metadata is initialized.
 	if stats != nil {
 		ot.stats = make([]optTableStat, len(stats))
@@ -1146,6 +1202,11 @@ func (ot *optTable) IsMaterializedView() bool {
 	return ot.desc.MaterializedView()
 }
 
+// IsBackupTable implements the cat.Table interface.
+func (ot *optTable) IsBackupTable() bool {
+	return ot.desc.IsBackupTable()
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optTable) ColumnCount() int {
 	return len(ot.columns)
@@ -1250,6 +1311,26 @@ func (ot *optTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	return &ot.uniqueConstraints[i]
 }
 
+// ExclusionCount is part of the cat.Table interface.
+func (ot *optTable) ExclusionCount() int {
+	return len(ot.exclusionConstraints)
+}
+
+// Exclusion is part of the cat.Table interface.
+func (ot *optTable) Exclusion(i int) cat.ExclusionConstraint {
+	return &ot.exclusionConstraints[i]
+}
+
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	return ot.triggers[i]
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optTable) Zone() cat.Zone {
 	return ot.zone
@@ -1817,6 +1898,9 @@ type optUniqueConstraint struct {
 	validity     descpb.ConstraintValidity
 
 	uniquenessGuaranteedByAnotherIndex bool
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.UniqueConstraint = &optUniqueConstraint{}
@@ -1872,6 +1956,74 @@ func (u *optUniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
 	return u.uniquenessGuaranteedByAnotherIndex
 }
 
+// Deferrable is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrable() bool {
+	return u.deferrable
+}
+
+// InitiallyDeferred is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) InitiallyDeferred() bool {
+	return u.initiallyDeferred
+}
+
+// optExclusionConstraint implements cat.ExclusionConstraint and represents an
+// exclusion constraint backed by an index.
+type optExclusionConstraint struct {
+	name string
+
+	table     cat.StableID
+	columns   []descpb.ColumnID
+	predicate string
+	isRange   bool
+
+	validated bool
+}
+
+var _ cat.ExclusionConstraint = &optExclusionConstraint{}
+
+// Name is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Name() string {
+	return e.name
+}
+
+// TableID is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) TableID() cat.StableID {
+	return e.table
+}
+
+// ColumnCount is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnCount() int {
+	return len(e.columns)
+}
+
+// ColumnOrdinal is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnOrdinal(tab cat.Table, i int) int {
+	if tab.ID() != e.table {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ColumnOrdinal (expected %d)",
+			tab.ID(), e.table,
+		))
+	}
+	optTab := convertTableToOptTable(tab)
+	ord, _ := optTab.lookupColumnOrdinal(e.columns[i])
+	return ord
+}
+
+// Predicate is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Predicate() (string, bool) {
+	return e.predicate, e.predicate != ""
+}
+
+// IsRange is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) IsRange() bool {
+	return e.isRange
+}
+
+// Validated is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Validated() bool {
+	return e.validated
+}
+
 // optForeignKeyConstraint implements cat.ForeignKeyConstraint and represents a
 // foreign key relationship. Both the origin and the referenced table store the
 // same optForeignKeyConstraint (as an outbound and inbound reference,
@@ -1889,6 +2041,9 @@ type optForeignKeyConstraint struct {
 	match        descpb.ForeignKeyReference_Match
 	deleteAction catpb.ForeignKeyAction
 	updateAction catpb.ForeignKeyAction
+
+	deferrable        bool
+	initiallyDeferred bool
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1960,6 +2115,16 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrable is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrable() bool {
+	return fk.deferrable
+}
+
+// InitiallyDeferred is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) InitiallyDeferred() bool {
+	return fk.initiallyDeferred
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc catalog.TableDescriptor
@@ -2152,6 +2317,11 @@ func (ot *optVirtualTable) IsMaterializedView() bool {
 	return false
 }
 
+// IsBackupTable implements the cat.Table interface.
+func (ot *optVirtualTable) IsBackupTable() bool {
+	return false
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optVirtualTable) ColumnCount() int {
 	return len(ot.columns)
@@ -2257,6 +2427,26 @@ func (ot *optVirtualTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("no unique constraints"))
 }
 
+// ExclusionCount is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionCount() int {
+	return 0
+}
+
+// Exclusion is part of the cat.Table interface.
+func (ot *optVirtualTable) Exclusion(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optVirtualTable) Zone() cat.Zone {
 	panic(errors.AssertionFailedf("no zone"))
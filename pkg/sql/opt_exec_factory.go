@@ -33,6 +33,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/explain"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -117,6 +119,9 @@ func (ef *execFactory) ConstructScan(
 	if table.IsVirtualTable() {
 		return ef.constructVirtualScan(table, index, params, reqOrdering)
 	}
+	if table.IsBackupTable() {
+		return ef.constructBackupScan(table, index, params, reqOrdering)
+	}
 
 	tabDesc := table.(*optTable).desc
 	idx := index.(*optIndex).idx
@@ -694,6 +699,10 @@ func (ef *execFactory) ConstructLookupJoin(
 	if table.IsVirtualTable() {
 		return ef.constructVirtualTableLookupJoin(joinType, input, table, index, eqCols, lookupCols, onCond)
 	}
+	if table.IsBackupTable() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"lookup joins are not supported into table %q attached from a backup", table.Name())
+	}
 	tabDesc := table.(*optTable).desc
 	idx := index.(*optIndex).idx
 	colCfg := makeScanColumnsConfig(table, lookupCols)
@@ -1569,6 +1578,7 @@ func (ef *execFactory) ConstructUpsert(
 	arbiterIndexes cat.IndexOrdinals,
 	arbiterConstraints cat.UniqueOrdinals,
 	canaryCol exec.NodeColumnOrdinal,
+	deleteCol exec.NodeColumnOrdinal,
 	insertColOrdSet exec.TableColumnOrdinalSet,
 	fetchColOrdSet exec.TableColumnOrdinalSet,
 	updateColOrdSet exec.TableColumnOrdinalSet,
@@ -1628,6 +1638,7 @@ func (ef *execFactory) ConstructUpsert(
 			tw: optTableUpserter{
 				ri:            ri,
 				canaryOrdinal: int(canaryCol),
+				deleteOrdinal: int(deleteCol),
 				fetchCols:     fetchCols,
 				updateCols:    updateCols,
 				ru:            ru,
@@ -1635,6 +1646,19 @@ func (ef *execFactory) ConstructUpsert(
 		},
 	}
 
+	// Create the table deleter for the existing rows that a MERGE statement
+	// deletes rather than updates.
+	if deleteCol != -1 {
+		ups.run.tw.rd = row.MakeDeleter(
+			ef.planner.ExecCfg().Codec,
+			tabDesc,
+			fetchCols,
+			&ef.planner.ExecCfg().Settings.SV,
+			internal,
+			ef.planner.ExecCfg().GetRowMetrics(internal),
+		)
+	}
+
 	// If rows are not needed, no columns are returned.
 	if rowsNeeded {
 		returnCols := makeColList(table, returnColOrdSet)
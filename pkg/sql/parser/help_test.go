@@ -64,6 +64,9 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER TYPE t RENAME ??`, `ALTER TYPE`},
 		{`ALTER TYPE t DROP VALUE ??`, `ALTER TYPE`},
 
+		{`ALTER DOMAIN ??`, `ALTER DOMAIN`},
+		{`ALTER DOMAIN d ADD ??`, `ALTER DOMAIN`},
+
 		{`ALTER INDEX foo@bar RENAME ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar RENAME TO blih ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar SPLIT ??`, `ALTER INDEX`},
@@ -164,6 +167,10 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
 
+		{`CREATE DOMAIN ??`, `CREATE DOMAIN`},
+		{`CREATE DOMAIN d AS ??`, `CREATE DOMAIN`},
+		{`DROP DOMAIN ??`, `DROP DOMAIN`},
+
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA bli ??`, `CREATE SCHEMA`},
@@ -255,6 +262,9 @@ func TestContextualHelp(t *testing.T) {
 		{`INSERT INTO blah VALUES (1) ??`, `VALUES`},
 		{`INSERT INTO blah TABLE foo ??`, `TABLE`},
 
+		{`MERGE INTO ??`, `MERGE`},
+		{`MERGE INTO blah USING foo ON true ??`, `MERGE`},
+
 		{`UPSERT INTO ??`, `UPSERT`},
 		{`UPSERT INTO blah (??`, `<SELECTCLAUSE>`},
 		{`UPSERT INTO blah VALUES (1) RETURNING ??`, `UPSERT`},
@@ -338,6 +348,7 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW SCHEDULES ??`, `SHOW SCHEDULES`},
 
 		{`SHOW BACKUP 'foo' ??`, `SHOW BACKUP`},
+		{`SHOW BACKUP TABLE foo FROM 'bar' ??`, `SHOW BACKUP`},
 
 		{`SHOW CLUSTER SETTING all ??`, `SHOW CLUSTER SETTING`},
 		{`SHOW ALL CLUSTER ??`, `SHOW CLUSTER SETTING`},
@@ -447,6 +458,9 @@ func TestContextualHelp(t *testing.T) {
 		{`SET LOCAL TIME ??`, `SET LOCAL`},
 		{`SET LOCAL TIME ZONE 'UTC' ??`, `SET LOCAL`},
 
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
+		{`SET CONSTRAINTS ALL ??`, `SET CONSTRAINTS`},
+
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
 		{`SET TIME ??`, `SET SESSION`},
@@ -491,6 +505,12 @@ func TestContextualHelp(t *testing.T) {
 
 		{`RESTORE foo FROM 'bar' ??`, `RESTORE`},
 		{`RESTORE DATABASE ??`, `RESTORE`},
+		{`RESTORE ROWS INTO foo FROM 'bar' ??`, `RESTORE`},
+
+		{`VERIFY ??`, `VERIFY BACKUP`},
+		{`ATTACH ??`, `ATTACH BACKUP`},
+		{`ATTACH BACKUP FROM 'foo' IN 'bar' ??`, `ATTACH BACKUP`},
+		{`VERIFY BACKUP FROM 'foo' ??`, `VERIFY BACKUP`},
 
 		{`IMPORT TABLE ??`, `IMPORT`},
 
@@ -503,6 +523,19 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
 		{`ALTER FUNCTION ??`, `ALTER FUNCTION`},
 		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+
+		{`CREATE PROCEDURE ??`, `CREATE PROCEDURE`},
+		{`DROP PROCEDURE ??`, `DROP PROCEDURE`},
+		{`CALL ??`, `CALL`},
+
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`DROP TRIGGER ??`, `DROP TRIGGER`},
+
+		{`CREATE PUBLICATION ??`, `CREATE PUBLICATION`},
+		{`CREATE PUBLICATION p FOR ??`, `CREATE PUBLICATION`},
+		{`ALTER PUBLICATION ??`, `ALTER PUBLICATION`},
+		{`ALTER PUBLICATION p ADD ??`, `ALTER PUBLICATION`},
+		{`DROP PUBLICATION ??`, `DROP PUBLICATION`},
 	}
 
 	// The following checks that the test definition above exercises all
@@ -465,6 +465,9 @@ func arrayOf(
 		if typ.Family() == types.VoidFamily {
 			return nil, pgerror.Newf(pgcode.UndefinedObject, "type void[] does not exist")
 		}
+		if typ.Family() == types.TriggerFamily {
+			return nil, pgerror.Newf(pgcode.UndefinedObject, "type trigger[] does not exist")
+		}
 		if err := types.CheckArrayElementType(typ); err != nil {
 			return nil, err
 		}
@@ -500,6 +500,12 @@ func (u *sqlSymUnion) updateExpr() *tree.UpdateExpr {
 func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
     return u.val.(tree.UpdateExprs)
 }
+func (u *sqlSymUnion) mergeWhen() *tree.MergeWhen {
+    return u.val.(*tree.MergeWhen)
+}
+func (u *sqlSymUnion) mergeWhens() tree.MergeWhens {
+    return u.val.(tree.MergeWhens)
+}
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
@@ -614,6 +620,12 @@ func (u *sqlSymUnion) idxElem() tree.IndexElem {
 func (u *sqlSymUnion) idxElems() tree.IndexElemList {
     return u.val.(tree.IndexElemList)
 }
+func (u *sqlSymUnion) exclusionElem() tree.ExclusionElem {
+    return u.val.(tree.ExclusionElem)
+}
+func (u *sqlSymUnion) exclusionElems() tree.ExclusionElemList {
+    return u.val.(tree.ExclusionElemList)
+}
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
@@ -704,6 +716,9 @@ func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
 func (u *sqlSymUnion) referenceActions() tree.ReferenceActions {
     return u.val.(tree.ReferenceActions)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+    return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) createStatsOptions() *tree.CreateStatsOptions {
     return u.val.(*tree.CreateStatsOptions)
 }
@@ -825,6 +840,24 @@ func (u *sqlSymUnion) functionObj() tree.FuncObj {
 func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
     return u.val.(tree.FuncObjs)
 }
+func (u *sqlSymUnion) createPublication() *tree.CreatePublication {
+    return u.val.(*tree.CreatePublication)
+}
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEventType {
+    return u.val.(tree.TriggerEventType)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+    return u.val.(tree.TriggerEvents)
+}
+func (u *sqlSymUnion) domainConstraint() tree.DomainConstraint {
+    return u.val.(tree.DomainConstraint)
+}
+func (u *sqlSymUnion) domainConstraints() tree.DomainConstraints {
+    return u.val.(tree.DomainConstraints)
+}
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -846,17 +879,17 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 // Ordinary key words in alphabetical order.
 %token <str> ABORT ABSOLUTE ACCESS ACTION ADD ADMIN AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
-%token <str> ASENSITIVE ASYMMETRIC AT ATOMIC ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
+%token <str> ASENSITIVE ASYMMETRIC AT AT_AT ATOMIC ATTACH ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
 
 %token <str> BACKUP BACKUPS BACKWARD BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALL CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE COMPLETIONS CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
-%token <str> CONFLICT CONNECTION CONNECTIONS CONSTRAINT CONSTRAINTS CONTAINS CONTROLCHANGEFEED CONTROLJOB
+%token <str> CONFLICT CONNECTION CONNECTIONS CONSTRAINT CONSTRAINTS CONTAINS CONTINUOUS CONTROLCHANGEFEED CONTROLJOB
 %token <str> CONVERSION CONVERT COPY COST COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
 %token <str> CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
@@ -866,7 +899,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DEPENDS DESC DESTINATION DETACHED
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT EXPERIMENTAL_RELOCATE
@@ -898,16 +931,16 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %token <str> LABEL LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEAKPROOF LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LISTEN LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
+%token <str> MATCH MATCHED MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
 
 %token <str> NAN NAME NAMES NATURAL NEVER NEW_DB_NAME NEW_KMS NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED
 %token <str> NOCONTROLJOB NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING
-%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT NOTHING NOTNULL
+%token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT NOTHING NOTIFY NOTNULL
 %token <str> NOVIEWACTIVITY NOVIEWACTIVITYREDACTED NOVIEWCLUSTERSETTING NOWAIT NULL NULLIF NULLS NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR OLD_KMS ON ONLY OPT OPTION OPTIONS OR
@@ -916,7 +949,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %token <str> PARALLEL PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
-%token <str> PROCEDURAL PUBLIC PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY QUOTE
 
@@ -934,7 +967,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %token <str> SQLLOGIN
 
 %token <str> STABLE START STATE STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING SUPER
-%token <str> SUPPORT SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
+%token <str> SUPPORT SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENT STATEMENTS
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TENANTS TESTING_RELOCATE TEXT THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
@@ -945,7 +978,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNSET UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY_BACKUP_TABLE_DATA VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIEWDEBUG
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VERIFY VERIFY_BACKUP_TABLE_DATA VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIEWDEBUG
 %token <str> VIEWCLUSTERMETADATA VIEWCLUSTERSETTING VIRTUAL VISIBLE VOLATILE VOTERS
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
@@ -1001,9 +1034,11 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> alter_role_stmt
 %type <*tree.SetVar> set_or_reset_clause
 %type <tree.Statement> alter_type_stmt
+%type <tree.Statement> alter_domain_stmt
 %type <tree.Statement> alter_schema_stmt
 %type <tree.Statement> alter_unsupported_stmt
 %type <tree.Statement> alter_func_stmt
+%type <tree.Statement> alter_publication_stmt
 
 // ALTER RANGE
 %type <tree.Statement> alter_zone_range_stmt
@@ -1079,6 +1114,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> backup_stmt
 %type <tree.Statement> begin_stmt
 
+%type <tree.Statement> call_stmt
 %type <tree.Statement> cancel_stmt
 %type <tree.Statement> cancel_jobs_stmt
 %type <tree.Statement> cancel_queries_stmt
@@ -1113,6 +1149,9 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 %type <tree.Statement> create_func_stmt
+%type <tree.Statement> create_proc_stmt
+%type <tree.Statement> create_trigger_stmt
+%type <tree.Statement> create_publication_stmt
 
 %type <tree.Statement> create_stats_stmt
 %type <*tree.CreateStatsOptions> opt_create_stats_options
@@ -1120,6 +1159,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_domain_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
 
@@ -1132,9 +1172,13 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_domain_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 %type <tree.Statement> drop_func_stmt
+%type <tree.Statement> drop_proc_stmt
+%type <tree.Statement> drop_trigger_stmt
+%type <tree.Statement> drop_publication_stmt
 
 %type <tree.Statement> analyze_stmt
 %type <tree.Statement> explain_stmt
@@ -1147,7 +1191,10 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> deallocate_stmt
 %type <tree.Statement> grant_stmt
 %type <tree.Statement> insert_stmt
+%type <tree.Statement> merge_stmt
 %type <tree.Statement> import_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
 %type <tree.Statement> pause_stmt pause_jobs_stmt pause_schedules_stmt pause_all_jobs_stmt
 %type <*tree.Select>   for_schedules_clause
 %type <tree.Statement> reassign_owned_by_stmt
@@ -1157,6 +1204,8 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> resume_stmt resume_jobs_stmt resume_schedules_stmt resume_all_jobs_stmt
 %type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> restore_stmt
+%type <tree.Statement> verify_backup_stmt
+%type <tree.Statement> attach_backup_stmt
 %type <tree.StringOrPlaceholderOptList> string_or_placeholder_opt_list
 %type <[]tree.StringOrPlaceholderOptList> list_of_string_or_placeholder_opt_list
 %type <tree.Statement> revoke_stmt
@@ -1167,6 +1216,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> savepoint_stmt
 
 %type <tree.Statement> preparable_set_stmt nonpreparable_set_stmt
+%type <tree.Statement> set_constraints_stmt
 %type <tree.Statement> set_local_stmt
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt set_or_reset_csetting_stmt
@@ -1238,7 +1288,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.Statement> fetch_cursor_stmt
 %type <tree.Statement> move_cursor_stmt
 %type <tree.CursorStmt> cursor_movement_specifier
-%type <bool> opt_hold opt_binary
+%type <bool> opt_hold opt_binary constraints_mode
 %type <tree.CursorSensitivity> opt_sensitivity
 %type <tree.CursorScrollOption> opt_scroll
 %type <int64> opt_forward_backward forward_backward
@@ -1258,6 +1308,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.NameList> storage_parameter_key_list
 %type <tree.StorageParam> storage_parameter
 %type <[]tree.StorageParam> storage_parameter_list opt_table_with opt_with_storage_parameter_list
+%type <*tree.CreatePublication> opt_publication_for_tables
 
 %type <*tree.Select> select_no_parens
 %type <tree.SelectStatement> select_clause select_with_parens simple_select values_clause table_clause simple_select_clause
@@ -1366,6 +1417,9 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <[]string> session_var_parts
 %type <tree.SelectExprs> target_list
 %type <tree.UpdateExprs> set_clause_list
+%type <*tree.MergeWhen> merge_when
+%type <tree.MergeWhens> merge_when_list
+%type <tree.Expr> opt_merge_when_cond
 %type <*tree.UpdateExpr> set_clause multiple_set_clause
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
@@ -1441,6 +1495,8 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <bool> opt_ordinality opt_compact
 %type <*tree.Order> sortby
 %type <tree.IndexElem> index_elem index_elem_options create_as_param
+%type <tree.ExclusionElemList> exclude_elems
+%type <tree.ExclusionElem> exclude_elem
 %type <tree.TableExpr> table_ref numeric_table_ref func_table
 %type <tree.Exprs> rowsfrom_list
 %type <tree.Expr> rowsfrom_item
@@ -1505,6 +1561,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
 %type <tree.ReferenceActions> reference_actions
+%type <tree.ConstraintDeferrability> opt_deferrable constraint_deferrability
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
 %type <tree.Expr> func_application func_expr_common_subexpr special_function
@@ -1582,6 +1639,14 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %type <tree.FuncObj> function_with_argtypes
 %type <tree.FuncObjs> function_with_argtypes_list
 
+// Trigger relevant components.
+%type <tree.TriggerActionTime> trigger_action_time
+%type <tree.TriggerEventType> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
+%type <tree.Expr> opt_domain_default
+%type <tree.DomainConstraint> domain_constraint domain_constraint_elem domain_check_constraint
+%type <tree.DomainConstraints> opt_domain_constraint_list domain_constraint_list
+
 %type <*tree.LabelSpec> label_spec
 
 
@@ -1597,7 +1662,7 @@ func (u *sqlSymUnion) functionObjs() tree.FuncObjs {
 %nonassoc  '<' '>' '=' LESS_EQUALS GREATER_EQUALS NOT_EQUALS
 %nonassoc  '~' BETWEEN IN LIKE ILIKE SIMILAR NOT_REGMATCH REGIMATCH NOT_REGIMATCH NOT_LA
 %nonassoc  ESCAPE              // ESCAPE must be just above LIKE/ILIKE/SIMILAR
-%nonassoc  CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS
+%nonassoc  CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS AT_AT
 %nonassoc  OVERLAPS
 %left      POSTFIXOP           // dummy for postfix OP rules
 // To support target_elem without AS, we must give IDENT an explicit priority
@@ -1673,6 +1738,7 @@ stmt:
 stmt_without_legacy_transaction:
   preparable_stmt           // help texts in sub-rule
 | analyze_stmt              // EXTEND WITH HELP: ANALYZE
+| call_stmt                 // EXTEND WITH HELP: CALL
 | copy_from_stmt
 | comment_stmt
 | execute_stmt              // EXTEND WITH HELP: EXECUTE
@@ -1693,6 +1759,8 @@ stmt_without_legacy_transaction:
 | fetch_cursor_stmt         // EXTEND WITH HELP: FETCH
 | move_cursor_stmt          // EXTEND WITH HELP: MOVE
 | reindex_stmt
+| listen_stmt
+| notify_stmt
 | unlisten_stmt
 
 // %Help: ALTER
@@ -1715,10 +1783,12 @@ alter_ddl_stmt:
 | alter_partition_stmt          // EXTEND WITH HELP: ALTER PARTITION
 | alter_schema_stmt             // EXTEND WITH HELP: ALTER SCHEMA
 | alter_type_stmt               // EXTEND WITH HELP: ALTER TYPE
+| alter_domain_stmt             // EXTEND WITH HELP: ALTER DOMAIN
 | alter_default_privileges_stmt // EXTEND WITH HELP: ALTER DEFAULT PRIVILEGES
 | alter_changefeed_stmt         // EXTEND WITH HELP: ALTER CHANGEFEED
 | alter_backup_stmt             // EXTEND WITH HELP: ALTER BACKUP
 | alter_func_stmt               // EXTEND WITH HELP: ALTER FUNCTION
+| alter_publication_stmt        // EXTEND WITH HELP: ALTER PUBLICATION
 | alter_backup_schedule  // EXTEND WITH HELP: ALTER BACKUP SCHEDULE
 
 // %Help: ALTER TABLE - change the definition of a table
@@ -1917,6 +1987,59 @@ alter_func_stmt:
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
 
+// %Help: ALTER PUBLICATION - change the definition of a publication
+// %Category: DDL
+// %Text:
+// ALTER PUBLICATION name { ADD | SET | DROP } TABLE table_name [, ...]
+// ALTER PUBLICATION name SET ( publication_parameter [= value] [, ... ] )
+// ALTER PUBLICATION name RENAME TO new_name
+// ALTER PUBLICATION name OWNER TO { new_owner | CURRENT_USER | SESSION_USER }
+// %SeeAlso: CREATE PUBLICATION, DROP PUBLICATION
+alter_publication_stmt:
+  ALTER PUBLICATION name ADD TABLE table_name_list
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationTables{Action: tree.AlterPublicationAddTables, Tables: $6.tableNames()},
+    }
+  }
+| ALTER PUBLICATION name SET TABLE table_name_list
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationTables{Action: tree.AlterPublicationSetTables, Tables: $6.tableNames()},
+    }
+  }
+| ALTER PUBLICATION name DROP TABLE table_name_list
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationTables{Action: tree.AlterPublicationDropTables, Tables: $6.tableNames()},
+    }
+  }
+| ALTER PUBLICATION name SET '(' storage_parameter_list ')'
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationSetParams{Params: $6.storageParams()},
+    }
+  }
+| ALTER PUBLICATION name RENAME TO name
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationRename{NewName: tree.Name($6)},
+    }
+  }
+| ALTER PUBLICATION name OWNER TO role_spec
+  {
+    $$.val = &tree.AlterPublication{
+      Name: tree.Name($3),
+      Cmd: &tree.AlterPublicationOwner{Owner: $6.roleSpec()},
+    }
+  }
+| ALTER PUBLICATION error // SHOW HELP: ALTER PUBLICATION
+
 alter_database_owner:
   ALTER DATABASE database_name OWNER TO role_spec
   {
@@ -2924,6 +3047,101 @@ alter_type_stmt:
   }
 | ALTER TYPE error // SHOW HELP: ALTER TYPE
 
+// %Help: ALTER DOMAIN - change the definition of a domain
+// %Category: DDL
+// %Text: ALTER DOMAIN <type_name> <command>
+//
+// Commands:
+//   ALTER DOMAIN ... { SET DEFAULT <expr> | DROP DEFAULT }
+//   ALTER DOMAIN ... { SET | DROP } NOT NULL
+//   ALTER DOMAIN ... ADD [CONSTRAINT <constraint_name>] CHECK (<expr>) [NOT VALID]
+//   ALTER DOMAIN ... DROP CONSTRAINT [IF EXISTS] <constraint_name> [RESTRICT | CASCADE]
+//   ALTER DOMAIN ... VALIDATE CONSTRAINT <constraint_name>
+//
+// %SeeAlso: CREATE DOMAIN, DROP DOMAIN
+alter_domain_stmt:
+  ALTER DOMAIN type_name SET DEFAULT a_expr
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{Default: $6.expr()},
+    }
+  }
+| ALTER DOMAIN type_name DROP DEFAULT
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetDefault{},
+    }
+  }
+| ALTER DOMAIN type_name SET NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: true},
+    }
+  }
+| ALTER DOMAIN type_name DROP NOT NULL
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainSetNotNull{NotNull: false},
+    }
+  }
+| ALTER DOMAIN type_name ADD CONSTRAINT constraint_name domain_check_constraint opt_validate_behavior
+  {
+    c := $7.domainConstraint()
+    c.Name = tree.Name($6)
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainAddConstraint{
+        Constraint: c,
+        ValidationBehavior: $8.validationBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name ADD domain_check_constraint opt_validate_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainAddConstraint{
+        Constraint: $5.domainConstraint(),
+        ValidationBehavior: $6.validationBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{
+        Constraint: tree.Name($6),
+        DropBehavior: $7.dropBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name DROP CONSTRAINT IF EXISTS constraint_name opt_drop_behavior
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainDropConstraint{
+        IfExists: true,
+        Constraint: tree.Name($8),
+        DropBehavior: $9.dropBehavior(),
+      },
+    }
+  }
+| ALTER DOMAIN type_name VALIDATE CONSTRAINT constraint_name
+  {
+    $$.val = &tree.AlterDomain{
+      Domain: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterDomainValidateConstraint{
+        Constraint: tree.Name($6),
+      },
+    }
+  }
+| ALTER DOMAIN error // SHOW HELP: ALTER DOMAIN
+
 opt_add_val_placement:
   BEFORE SCONST
   {
@@ -3057,6 +3275,7 @@ opt_clear_data:
 //    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : encrypt backups using KMS
 //    detached: execute backup job asynchronously, without waiting for its completion
 //    incremental_location: specify a different path to store the incremental backup
+//    continuous: keep streaming MVCC history into the backup after it completes
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
@@ -3178,6 +3397,10 @@ backup_options:
   {
   $$.val = &tree.BackupOptions{IncrementalStorage: $3.stringOrPlaceholderOptList()}
   }
+| CONTINUOUS
+  {
+    $$.val = &tree.BackupOptions{Continuous: tree.MakeDBool(true)}
+  }
 
 
 // %Help: CREATE SCHEDULE FOR BACKUP - backup data periodically
@@ -3470,6 +3693,11 @@ drop_external_connection_stmt:
 // RESTORE SYSTEM USERS FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
+// or
+// RESTORE ROWS INTO <tablename> FROM <subdir> IN <location...>
+//         [ AS OF SYSTEM TIME <expr> ]
+//         [ WHERE <expr> ]
+//         [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
@@ -3549,6 +3777,17 @@ restore_stmt:
       Options: *($9.restoreOptions()),
     }
   }
+| RESTORE ROWS INTO table_name FROM string_or_placeholder IN string_or_placeholder_opt_list opt_as_of_clause opt_where_clause opt_with_options
+  {
+    $$.val = &tree.RestoreRows{
+      Table: $4.unresolvedObjectName(),
+      Subdir: $6.expr(),
+      From: $8.stringOrPlaceholderOptList(),
+      AsOf: $9.asOfClause(),
+      Where: tree.NewWhere(tree.AstWhere, $10.expr()),
+      Options: $11.kvOptions(),
+    }
+  }
 | RESTORE backup_targets FROM REPLICATION STREAM FROM string_or_placeholder_opt_list opt_as_tenant_clause
   {
    $$.val = &tree.StreamIngestion{
@@ -3559,6 +3798,62 @@ restore_stmt:
   }
 | RESTORE error // SHOW HELP: RESTORE
 
+// %Help: VERIFY BACKUP - check the integrity of a backup
+// %Category: CCL
+// %Text:
+// VERIFY BACKUP FROM <subdir> IN <collection...>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Reads every file of the backup and of its incremental backups, and checks
+// its checksums, key ordering and row counts against the backup manifest.
+//
+// Options:
+//    encryption_passphrase=passphrase: decrypt BACKUP with specified passphrase
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt backups using KMS
+//    incremental_location: location of the incremental backups of the backup
+//    dry_run_restore: also restore the backup into a temporary scratch keyspace
+//    detached: execute the verification job asynchronously, without waiting for its completion
+// %SeeAlso: SHOW BACKUP, RESTORE, WEBDOCS/restore.html
+verify_backup_stmt:
+  VERIFY BACKUP FROM string_or_placeholder IN string_or_placeholder_opt_list opt_with_options
+  {
+    $$.val = &tree.VerifyBackup{
+      Subdir: $4.expr(),
+      To: $6.stringOrPlaceholderOptList(),
+      Options: $7.kvOptions(),
+    }
+  }
+| VERIFY error // SHOW HELP: VERIFY BACKUP
+
+// %Help: ATTACH BACKUP - attach a backup as a read-only database
+// %Category: CCL
+// %Text:
+// ATTACH BACKUP FROM <subdir> IN <collection...> AS <database_name>
+//        [ AS OF SYSTEM TIME <expr> ]
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Creates a read-only database with the tables of a database in the backup.
+// The rows of the tables are read from the files of the backup when they are
+// queried, without restoring them. Drop the database to detach the backup.
+//
+// Options:
+//    database: the database of the backup to attach, if it contains several
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt backups using KMS
+//    incremental_location: location of the incremental backups of the backup
+// %SeeAlso: SHOW BACKUP, RESTORE, DROP DATABASE
+attach_backup_stmt:
+  ATTACH BACKUP FROM string_or_placeholder IN string_or_placeholder_opt_list AS database_name opt_as_of_clause opt_with_options
+  {
+    $$.val = &tree.AttachBackup{
+      Subdir:       $4.expr(),
+      InCollection: $6.stringOrPlaceholderOptList(),
+      Database:     tree.Name($8),
+      AsOf:         $9.asOfClause(),
+      Options:      $10.kvOptions(),
+    }
+  }
+| ATTACH error // SHOW HELP: ATTACH BACKUP
+
 string_or_placeholder_opt_list:
   string_or_placeholder
   {
@@ -3698,11 +3993,7 @@ import_format:
   }
 
 alter_unsupported_stmt:
-  ALTER DOMAIN error
-  {
-    return unimplemented(sqllex, "alter domain")
-  }
-| ALTER AGGREGATE error
+  ALTER AGGREGATE error
   {
     return unimplementedWithIssueDetail(sqllex, 74775, "alter aggregate")
   }
@@ -3961,6 +4252,24 @@ copy_options:
     return unimplementedWithIssueDetail(sqllex, 41608, "encoding")
   }
 
+// %Help: CALL - invoke a procedure
+// %Category: Misc
+// %Text: CALL <name> ( [ <expr> [, ...] ] )
+//
+// A procedure which runs COMMIT or ROLLBACK can only be called outside of an
+// explicit transaction.
+// %SeeAlso: CREATE PROCEDURE
+call_stmt:
+  CALL func_application
+  {
+    p := $2.expr().(*tree.FuncExpr)
+    if p.Type != 0 || p.Filter != nil || p.WindowDef != nil || len(p.OrderBy) > 0 {
+      return setErr(sqllex, errors.New("CALL requires a procedure invocation"))
+    }
+    $$.val = &tree.Call{Proc: p}
+  }
+| CALL error // SHOW HELP: CALL
+
 // %Help: CANCEL
 // %Category: Group
 // %Text: CANCEL JOBS, CANCEL QUERIES, CANCEL SESSIONS
@@ -4199,8 +4508,52 @@ create_func_stmt:
       RoutineBody: $12.routineBody(),
     }
   }
+| CREATE opt_or_replace FUNCTION func_create_name '(' opt_func_arg_with_default_list ')'
+  opt_create_func_opt_list opt_routine_body
+  {
+    // The return type is determined by the OUT arguments.
+    name := $4.unresolvedObjectName().ToFunctionName()
+    $$.val = &tree.CreateFunction{
+      Replace: $2.bool(),
+      FuncName: name,
+      Args: $6.functionArgs(),
+      Options: $8.functionOptions(),
+      RoutineBody: $9.routineBody(),
+    }
+  }
 | CREATE opt_or_replace FUNCTION error // SHOW HELP: CREATE FUNCTION
 
+// %Help: CREATE PROCEDURE - define a new procedure
+// %Category: DDL
+// %Text:
+// CREATE [ OR REPLACE ] PROCEDURE
+//    name ( [ [ argmode ] [ argname ] argtype [, ...] ] )
+//  { LANGUAGE lang_name
+//    | AS 'definition'
+//  } ...
+//
+// The body may run COMMIT and ROLLBACK, which end the transaction of the CALL
+// statement and start a new one. Such procedures cannot be called inside an
+// explicit transaction.
+// %SeeAlso: CALL, DROP PROCEDURE
+create_proc_stmt:
+  CREATE opt_or_replace PROCEDURE func_create_name '(' opt_func_arg_with_default_list ')'
+  opt_create_func_opt_list
+  {
+    name := $4.unresolvedObjectName().ToFunctionName()
+    $$.val = &tree.CreateFunction{
+      IsProcedure: true,
+      Replace: $2.bool(),
+      FuncName: name,
+      Args: $6.functionArgs(),
+      ReturnType: tree.FuncReturnType{
+        Type: types.Void,
+      },
+      Options: $8.functionOptions(),
+    }
+  }
+| CREATE opt_or_replace PROCEDURE error // SHOW HELP: CREATE PROCEDURE
+
 opt_or_replace:
   OR REPLACE { $$.val = true }
 | /* EMPTY */ { $$.val = false }
@@ -4280,9 +4633,9 @@ func_arg:
 
 func_arg_class:
   IN { $$.val = tree.FunctionArgIn }
-| OUT { return unimplemented(sqllex, "create function with 'OUT' argument class") }
-| INOUT { return unimplemented(sqllex, "create function with 'INOUT' argument class") }
-| IN OUT { return unimplemented(sqllex, "create function with 'IN OUT' argument class") }
+| OUT { $$.val = tree.FunctionArgOut }
+| INOUT { $$.val = tree.FunctionArgInOut }
+| IN OUT { $$.val = tree.FunctionArgInOut }
 | VARIADIC { return unimplementedWithIssueDetail(sqllex, 88947, "variadic user-defined functions") }
 
 func_arg_type:
@@ -4433,6 +4786,119 @@ opt_routine_body:
     $$.val = (*tree.RoutineBody)(nil)
   }
 
+// %Help: CREATE TRIGGER - define a new trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER name { BEFORE | AFTER } { INSERT | UPDATE | DELETE } [ OR ... ]
+//    ON table_name FOR EACH ROW EXECUTE { FUNCTION | PROCEDURE } function_name ( )
+//
+// The function must be a PL/pgSQL function without arguments that returns
+// trigger. It accesses the rows through NEW and OLD.
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name trigger_for_each_row EXECUTE function_or_procedure db_object_name '(' ')'
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      ActionTime: $4.triggerActionTime(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      FuncName: $11.unresolvedObjectName().ToFunctionName(),
+    }
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerActionTimeBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerActionTimeAfter
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerEventInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerEventUpdate
+  }
+| UPDATE OF name_list
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "update of column triggers")
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerEventDelete
+  }
+| TRUNCATE
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "truncate triggers")
+  }
+
+trigger_for_each_row:
+  FOR opt_each ROW {}
+| FOR opt_each STATEMENT
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "statement-level triggers")
+  }
+
+opt_each:
+  EACH {}
+| /* EMPTY */ {}
+
+function_or_procedure:
+  FUNCTION {}
+| PROCEDURE {}
+
+// %Help: CREATE PUBLICATION - define a new publication
+// %Category: DDL
+// %Text:
+// CREATE PUBLICATION name
+//    [ FOR ALL TABLES | FOR TABLE table_name [, ...] ]
+//    [ WITH ( publication_parameter [= value] [, ... ] ) ]
+//
+// Parameters:
+//    publish = 'insert, update, delete, truncate'
+// %SeeAlso: ALTER PUBLICATION, DROP PUBLICATION
+create_publication_stmt:
+  CREATE PUBLICATION name opt_publication_for_tables opt_with_storage_parameter_list
+  {
+    n := $4.createPublication()
+    n.Name = tree.Name($3)
+    n.Params = $5.storageParams()
+    $$.val = n
+  }
+| CREATE PUBLICATION error // SHOW HELP: CREATE PUBLICATION
+
+opt_publication_for_tables:
+  FOR ALL TABLES
+  {
+    $$.val = &tree.CreatePublication{AllTables: true}
+  }
+| FOR TABLE table_name_list
+  {
+    $$.val = &tree.CreatePublication{Tables: $3.tableNames()}
+  }
+| /* EMPTY */
+  {
+    $$.val = &tree.CreatePublication{}
+  }
+
 // %Help: DROP FUNCTION - remove a function
 // %Category: DDL
 // %Text:
@@ -4457,6 +4923,80 @@ drop_func_stmt:
   }
 | DROP FUNCTION error // SHOW HELP: DROP FUNCTION
 
+// %Help: DROP PROCEDURE - remove a procedure
+// %Category: DDL
+// %Text:
+// DROP PROCEDURE [ IF EXISTS ] name [ ( [ [ argmode ] [ argname ] argtype [, ...] ] ) ] [, ...]
+//    [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE PROCEDURE
+drop_proc_stmt:
+  DROP PROCEDURE function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      IsProcedure: true,
+      Functions: $3.functionObjs(),
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP PROCEDURE IF EXISTS function_with_argtypes_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      IsProcedure: true,
+      IfExists: true,
+      Functions: $5.functionObjs(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP PROCEDURE error // SHOW HELP: DROP PROCEDURE
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text:
+// DROP TRIGGER [ IF EXISTS ] name ON table_name [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($3),
+      Table: $5.unresolvedObjectName().ToTableName(),
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($5),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      IfExists: true,
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
+// %Help: DROP PUBLICATION - remove a publication
+// %Category: DDL
+// %Text:
+// DROP PUBLICATION [ IF EXISTS ] name [, ...] [ CASCADE | RESTRICT ]
+// %SeeAlso: CREATE PUBLICATION, ALTER PUBLICATION
+drop_publication_stmt:
+  DROP PUBLICATION name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropPublication{
+      Names: $3.nameList(),
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP PUBLICATION IF EXISTS name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropPublication{
+      Names: $5.nameList(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP PUBLICATION error // SHOW HELP: DROP PUBLICATION
+
 function_with_argtypes_list:
   function_with_argtypes
   {
@@ -4583,13 +5123,11 @@ create_unsupported:
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplementedWithIssue(sqllex, 65017) }
-| CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
 | CREATE opt_or_replace RULE error { return unimplemented(sqllex, "create rule") }
 | CREATE SERVER error { return unimplemented(sqllex, "create server") }
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create trigger") }
 
 opt_trusted:
   TRUSTED {}
@@ -4605,19 +5143,16 @@ drop_unsupported:
 | DROP CAST error { return unimplemented(sqllex, "drop cast") }
 | DROP COLLATION error { return unimplemented(sqllex, "drop collation") }
 | DROP CONVERSION error { return unimplemented(sqllex, "drop conversion") }
-| DROP DOMAIN error { return unimplementedWithIssueDetail(sqllex, 27796, "drop") }
 | DROP EXTENSION IF EXISTS name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension if exists") }
 | DROP EXTENSION name error { return unimplementedWithIssueDetail(sqllex, 74777, "drop extension") }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
-| DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
 | DROP RULE error { return unimplemented(sqllex, "drop rule") }
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
@@ -4628,9 +5163,13 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_domain_stmt   // EXTEND WITH HELP: CREATE DOMAIN
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 | create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
+| create_proc_stmt     // EXTEND WITH HELP: CREATE PROCEDURE
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
+| create_publication_stmt // EXTEND WITH HELP: CREATE PUBLICATION
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
@@ -4889,7 +5428,11 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_domain_stmt   // EXTEND WITH HELP: DROP DOMAIN
 | drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
+| drop_proc_stmt     // EXTEND WITH HELP: DROP PROCEDURE
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
+| drop_publication_stmt // EXTEND WITH HELP: DROP PUBLICATION
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -5024,6 +5567,31 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP DOMAIN - remove a domain
+// %Category: DDL
+// %Text: DROP DOMAIN [IF EXISTS] <type_name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE DOMAIN, ALTER DOMAIN
+drop_domain_stmt:
+  DROP DOMAIN type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+      IsDomain: true,
+    }
+  }
+| DROP DOMAIN IF EXISTS type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+      IsDomain: true,
+    }
+  }
+| DROP DOMAIN error // SHOW HELP: DROP DOMAIN
+
 target_types:
   type_name_list
   {
@@ -5205,6 +5773,7 @@ explainable_stmt:
 
 preparable_stmt:
   alter_stmt     // help texts in sub-rule
+| attach_backup_stmt // EXTEND WITH HELP: ATTACH BACKUP
 | backup_stmt    // EXTEND WITH HELP: BACKUP
 | cancel_stmt    // help texts in sub-rule
 | create_stmt    // help texts in sub-rule
@@ -5213,6 +5782,7 @@ preparable_stmt:
 | explain_stmt   // EXTEND WITH HELP: EXPLAIN
 | import_stmt    // EXTEND WITH HELP: IMPORT
 | insert_stmt    // EXTEND WITH HELP: INSERT
+| merge_stmt     // EXTEND WITH HELP: MERGE
 | pause_stmt     // help texts in sub-rule
 | reset_stmt     // help texts in sub-rule
 | restore_stmt   // EXTEND WITH HELP: RESTORE
@@ -5228,6 +5798,7 @@ preparable_stmt:
 | truncate_stmt     // EXTEND WITH HELP: TRUNCATE
 | update_stmt       // EXTEND WITH HELP: UPDATE
 | upsert_stmt       // EXTEND WITH HELP: UPSERT
+| verify_backup_stmt // EXTEND WITH HELP: VERIFY BACKUP
 
 // These are statements that can be used as a data source using the special
 // syntax with brackets. These are a subset of preparable_stmt.
@@ -5235,6 +5806,7 @@ row_source_extension_stmt:
   delete_stmt       // EXTEND WITH HELP: DELETE
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | insert_stmt       // EXTEND WITH HELP: INSERT
+| merge_stmt        // EXTEND WITH HELP: MERGE
 | select_stmt       // help texts in sub-rule
   {
     $$.val = $1.slct()
@@ -5794,7 +6366,7 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 
 // SET SESSION / SET LOCAL / SET CLUSTER SETTING
 preparable_set_stmt:
@@ -6015,6 +6587,35 @@ set_local_stmt:
   }
 | SET LOCAL error  // SHOW HELP: SET LOCAL
 
+// %Help: SET CONSTRAINTS - set when constraints are checked in the transaction
+// %Category: Txn
+// %Text: SET CONSTRAINTS { ALL | <constraintname> [, ...] } { DEFERRED | IMMEDIATE }
+//
+// Only foreign key and UNIQUE WITHOUT INDEX constraints declared DEFERRABLE
+// are affected. Unique constraints which are enforced by an index cannot be
+// declared DEFERRABLE.
+// %SeeAlso: SET TRANSACTION
+set_constraints_stmt:
+  SET CONSTRAINTS ALL constraints_mode
+  {
+    $$.val = &tree.SetConstraints{Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS name_list constraints_mode
+  {
+    $$.val = &tree.SetConstraints{Names: $3.nameList(), Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
+constraints_mode:
+  DEFERRED
+  {
+    $$.val = true
+  }
+| IMMEDIATE
+  {
+    $$.val = false
+  }
+
 // %Help: SET TRANSACTION - configure the transaction settings
 // %Category: Txn
 // %Text:
@@ -6162,11 +6763,11 @@ var_list:
 iso_level:
   READ UNCOMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | READ COMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | SNAPSHOT
   {
@@ -6622,7 +7223,15 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// SHOW BACKUP TABLE <tablename> FROM <subdir> IN <location> [ AS OF SYSTEM TIME <expr> ] [ WHERE <expr> ] [ WITH <option> [= <value>] [, ...] ]
+//
+// SHOW BACKUP TABLE reads the rows of a table directly from the backup files,
+// without restoring them. Its WHERE clause may only constrain the primary key
+// of the table, and limits the spans of the backup which are read. It can be
+// used as a data source, e.g.
+// SELECT * FROM [SHOW BACKUP TABLE ... WHERE id > 10] WHERE ...
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder_opt_list
@@ -6641,6 +7250,17 @@ show_backup_stmt:
 			Options: $8.kvOptions(),
 		}
 	}
+| SHOW BACKUP TABLE table_name FROM string_or_placeholder IN string_or_placeholder_opt_list opt_as_of_clause opt_where_clause opt_with_options
+	{
+		$$.val = &tree.ShowBackupTable{
+			Table:        $4.unresolvedObjectName(),
+			Subdir:       $6.expr(),
+			InCollection: $8.stringOrPlaceholderOptList(),
+			AsOf:         $9.asOfClause(),
+			Where:        tree.NewWhere(tree.AstWhere, $10.expr()),
+			Options:      $11.kvOptions(),
+		}
+	}
 | SHOW BACKUP string_or_placeholder IN string_or_placeholder_opt_list opt_with_options
 	{
 		$$.val = &tree.ShowBackup{
@@ -8115,14 +8735,26 @@ alter_schema_stmt:
 //
 // Table constraints:
 //    PRIMARY KEY ( <colnames...> ) [USING HASH]
-//    FOREIGN KEY ( <colnames...> ) REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
+//    FOREIGN KEY ( <colnames...> ) REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}] [<deferrable>]
 //    UNIQUE ( <colnames...> ) [{STORING | INCLUDE | COVERING} ( <colnames...> )]
+//    UNIQUE WITHOUT INDEX ( <colnames...> ) [<deferrable>]
 //    CHECK ( <expr> )
+//    EXCLUDE USING gist ( <element> WITH <operator> [, ...] ) [WHERE <expr>]
+//
+// Exclusion constraints:
+//    Every element is compared with =, except for one element which is
+//    compared with &&. That element is either an ARRAY or GEOMETRY column, or
+//    a range of two columns such as tstzrange(<colname>, <colname>), whose
+//    lower bound is inclusive and whose upper bound is exclusive.
+//
+// Deferrable constraints:
+//    [NOT] DEFERRABLE [INITIALLY {DEFERRED | IMMEDIATE}]
+//    Only foreign keys and UNIQUE WITHOUT INDEX constraints can be deferred.
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | NOT VISIBLE | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr> | ON UPDATE <expr> | GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY [( <opt_sequence_option_list> )]}
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
-//   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
+//   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}] [<deferrable>]
 //   COLLATE <collationname>
 //   AS ( <expr> ) { STORED | VIRTUAL }
 //
@@ -8602,12 +9234,33 @@ col_qualification_elem:
       WithoutIndex: $2.bool(),
     }
   }
+| UNIQUE opt_without_index constraint_deferrability
+  {
+    if $3.constraintDeferrability().IsDeferrable() {
+      // Unique constraints enforced by an index cannot be deferred, and
+      // deferrable UNIQUE WITHOUT INDEX constraints must be declared as table
+      // constraints.
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable column unique constraint")
+    }
+    $$.val = tree.UniqueConstraint{
+      WithoutIndex: $2.bool(),
+    }
+  }
 | PRIMARY KEY opt_with_storage_parameter_list
   {
     $$.val = tree.PrimaryKeyConstraint{
       StorageParams: $3.storageParams(),
     }
   }
+| PRIMARY KEY opt_with_storage_parameter_list constraint_deferrability
+  {
+    if $4.constraintDeferrability().IsDeferrable() {
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable primary key")
+    }
+    $$.val = tree.PrimaryKeyConstraint{
+      StorageParams: $3.storageParams(),
+    }
+  }
 | PRIMARY KEY USING HASH opt_hash_sharded_bucket_count opt_with_storage_parameter_list
 {
   $$.val = tree.ShardedPrimaryKeyConstraint{
@@ -8628,7 +9281,7 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnOnUpdate{Expr: $3.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
   {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -8636,6 +9289,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrability: $6.constraintDeferrability(),
     }
   }
 | generated_as '(' a_expr ')' STORED
@@ -8761,6 +9415,9 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability().IsDeferrable() {
+      return setErr(sqllex, pgerror.New(pgcode.FeatureNotSupported, "CHECK constraints cannot be marked DEFERRABLE"))
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -8776,6 +9433,7 @@ constraint_elem:
         PartitionByIndex: $7.partitionByIndex(),
         Predicate: $9.expr(),
       },
+      Deferrability: $8.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_with_storage_parameter_list
@@ -8789,6 +9447,20 @@ constraint_elem:
       PrimaryKey: true,
     }
   }
+| PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_with_storage_parameter_list constraint_deferrability
+  {
+    if $8.constraintDeferrability().IsDeferrable() {
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable primary key")
+    }
+    $$.val = &tree.UniqueConstraintTableDef{
+      IndexTableDef: tree.IndexTableDef{
+        Columns: $4.idxElems(),
+        Sharded: $6.shardedIndexDef(),
+        StorageParams: $7.storageParams(),
+      },
+      PrimaryKey: true,
+    }
+  }
 | FOREIGN KEY '(' name_list ')' REFERENCES table_name
     opt_column_list key_match reference_actions opt_deferrable
   {
@@ -8799,11 +9471,57 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrability: $11.constraintDeferrability(),
     }
   }
-| EXCLUDE USING error
+| EXCLUDE USING name '(' exclude_elems ')' opt_where_clause
   {
-    return unimplementedWithIssueDetail(sqllex, 46657, "add constraint exclude using")
+    if $3 != "gist" {
+      return unimplementedWithIssueDetail(sqllex, 46657, "exclude using " + $3)
+    }
+    $$.val = &tree.ExclusionConstraintTableDef{
+      Elems: $5.exclusionElems(),
+      Predicate: $7.expr(),
+    }
+  }
+
+exclude_elems:
+  exclude_elem
+  {
+    $$.val = tree.ExclusionElemList{$1.exclusionElem()}
+  }
+| exclude_elems ',' exclude_elem
+  {
+    $$.val = append($1.exclusionElems(), $3.exclusionElem())
+  }
+
+exclude_elem:
+  column_name WITH all_op
+  {
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      return setErr(sqllex, pgerror.Newf(pgcode.WrongObjectType,
+        "operator %s is not a comparison operator", $3.op()))
+    }
+    $$.val = tree.ExclusionElem{Column: tree.Name($1), Operator: op}
+  }
+| func_expr_windowless WITH all_op
+  {
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      return setErr(sqllex, pgerror.Newf(pgcode.WrongObjectType,
+        "operator %s is not a comparison operator", $3.op()))
+    }
+    elem, ok := tree.NewRangeExclusionElem($1.expr(), op)
+    if !ok {
+      return unimplementedWithIssueDetail(sqllex, 46657, "exclusion constraint on expression")
+    }
+    $$.val = elem
+  }
+| '(' a_expr ')' WITH all_op
+  {
+    /* SKIP DOC */
+    return unimplementedWithIssueDetail(sqllex, 46657, "exclusion constraint on expression")
   }
 
 
@@ -8912,12 +9630,34 @@ create_as_col_qualification_elem:
   }
 
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| constraint_deferrability
+
+constraint_deferrability:
+  DEFERRABLE
+  {
+    $$.val = tree.ConstraintDeferrableInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintDeferrableInitiallyDeferred
+  }
+| DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintDeferrableInitiallyImmediate
+  }
+| INITIALLY DEFERRED
+  {
+    // Like in Postgres, INITIALLY DEFERRED implies DEFERRABLE.
+    $$.val = tree.ConstraintDeferrableInitiallyDeferred
+  }
+| INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
 
 storing:
   COVERING
@@ -9540,8 +10280,6 @@ create_type_stmt:
 | CREATE TYPE type_name '(' error         { return unimplementedWithIssueDetail(sqllex, 27793, "base") }
   // Shell types, gateway to define base types using the previous syntax.
 | CREATE TYPE type_name                   { return unimplementedWithIssueDetail(sqllex, 27793, "shell") }
-  // Domain types.
-| CREATE DOMAIN type_name error           { return unimplementedWithIssueDetail(sqllex, 27796, "create") }
 
 opt_enum_val_list:
   enum_val_list
@@ -9563,6 +10301,87 @@ enum_val_list:
     $$.val = append($1.enumValueList(), tree.EnumValue($3))
   }
 
+// %Help: CREATE DOMAIN - create a domain
+// %Category: DDL
+// %Text:
+// CREATE DOMAIN <type_name> [AS] <type> [DEFAULT <expr>] [<constraint> ...]
+//
+// Constraints:
+//   [CONSTRAINT <constraint_name>] { NOT NULL | NULL | CHECK (<expr>) }
+//
+// Within a CHECK constraint, the value being checked is referred to as VALUE.
+//
+// %SeeAlso: ALTER DOMAIN, DROP DOMAIN
+create_domain_stmt:
+  CREATE DOMAIN type_name opt_as typename opt_domain_default opt_domain_constraint_list
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Domain,
+      DomainType: $5.typeReference(),
+      DomainDefault: $6.expr(),
+      DomainConstraints: $7.domainConstraints(),
+    }
+  }
+| CREATE DOMAIN error // SHOW HELP: CREATE DOMAIN
+
+opt_as:
+  AS {}
+| /* EMPTY */ {}
+
+opt_domain_default:
+  DEFAULT b_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+opt_domain_constraint_list:
+  domain_constraint_list
+| /* EMPTY */
+  {
+    $$.val = tree.DomainConstraints(nil)
+  }
+
+domain_constraint_list:
+  domain_constraint
+  {
+    $$.val = tree.DomainConstraints{$1.domainConstraint()}
+  }
+| domain_constraint_list domain_constraint
+  {
+    $$.val = append($1.domainConstraints(), $2.domainConstraint())
+  }
+
+domain_constraint:
+  CONSTRAINT constraint_name domain_constraint_elem
+  {
+    c := $3.domainConstraint()
+    c.Name = tree.Name($2)
+    $$.val = c
+  }
+| domain_constraint_elem
+
+domain_constraint_elem:
+  NOT NULL
+  {
+    $$.val = tree.DomainConstraint{Nullability: tree.NotNull}
+  }
+| NULL
+  {
+    $$.val = tree.DomainConstraint{Nullability: tree.Null}
+  }
+| domain_check_constraint
+
+domain_check_constraint:
+  CHECK '(' a_expr ')'
+  {
+    $$.val = tree.DomainConstraint{Nullability: tree.SilentNull, Check: $3.expr()}
+  }
+
 // %Help: CREATE INDEX - create a new index
 // %Category: DDL
 // %Text:
@@ -10915,6 +11734,83 @@ update_stmt:
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
+// %Help: MERGE - insert, update or delete rows of a table as per a data source
+// %Category: DML
+// %Text:
+// MERGE INTO <tablename> [[AS] <name>]
+//        USING <source> ON <expr>
+//        WHEN MATCHED [AND <expr>] THEN { UPDATE SET ... | DELETE | DO NOTHING }
+//        WHEN NOT MATCHED [AND <expr>] THEN {
+//          INSERT [( <colnames...> )] { VALUES ( <exprs...> ) | DEFAULT VALUES } |
+//          DO NOTHING
+//        }
+//        [...]
+//        [RETURNING <exprs...>]
+// %SeeAlso: INSERT, UPSERT, UPDATE, DELETE
+merge_stmt:
+  opt_with_clause MERGE INTO table_expr_opt_alias_idx USING table_ref ON a_expr merge_when_list returning_clause
+  {
+    $$.val = &tree.Merge{
+      With: $1.with(),
+      Table: $4.tblExpr(),
+      Source: $6.tblExpr(),
+      On: $8.expr(),
+      Whens: $9.mergeWhens(),
+      Returning: $10.retClause(),
+    }
+  }
+| opt_with_clause MERGE error // SHOW HELP: MERGE
+
+merge_when_list:
+  merge_when
+  {
+    $$.val = tree.MergeWhens{$1.mergeWhen()}
+  }
+| merge_when_list merge_when
+  {
+    $$.val = append($1.mergeWhens(), $2.mergeWhen())
+  }
+
+merge_when:
+  WHEN MATCHED opt_merge_when_cond THEN UPDATE SET set_clause_list
+  {
+    $$.val = &tree.MergeWhen{Matched: true, Cond: $3.expr(), Action: tree.MergeActionUpdate, Exprs: $7.updateExprs()}
+  }
+| WHEN MATCHED opt_merge_when_cond THEN DELETE
+  {
+    $$.val = &tree.MergeWhen{Matched: true, Cond: $3.expr(), Action: tree.MergeActionDelete}
+  }
+| WHEN MATCHED opt_merge_when_cond THEN DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Matched: true, Cond: $3.expr(), Action: tree.MergeActionDoNothing}
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN INSERT VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Cond: $4.expr(), Action: tree.MergeActionInsert, Values: $9.exprs()}
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN INSERT '(' insert_column_list ')' VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Cond: $4.expr(), Action: tree.MergeActionInsert, Columns: $8.nameList(), Values: $12.exprs()}
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN INSERT DEFAULT VALUES
+  {
+    $$.val = &tree.MergeWhen{Cond: $4.expr(), Action: tree.MergeActionInsert}
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Cond: $4.expr(), Action: tree.MergeActionDoNothing}
+  }
+
+opt_merge_when_cond:
+  AND a_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
 opt_from_list:
   FROM from_list {
     $$.val = $2.tblExprs()
@@ -11596,9 +12492,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.RollupGroupingSet, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.CubeGroupingSet, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.ExplicitGroupingSets, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -12167,6 +13072,24 @@ relation_expr_list:
     $$.val = append($1.tableNames(), name)
   }
 
+// LISTEN
+listen_stmt:
+  LISTEN type_name
+  {
+    $$.val = &tree.Listen{ChannelName: $2.unresolvedObjectName()}
+  }
+
+// NOTIFY
+notify_stmt:
+  NOTIFY type_name
+  {
+    $$.val = &tree.Notify{ChannelName: $2.unresolvedObjectName()}
+  }
+| NOTIFY type_name ',' SCONST
+  {
+    $$.val = &tree.Notify{ChannelName: $2.unresolvedObjectName(), Payload: $4}
+  }
+
 // UNLISTEN
 unlisten_stmt:
    UNLISTEN type_name
@@ -13012,6 +13935,10 @@ a_expr:
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.ContainedBy), Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr AT_AT a_expr
+  {
+    $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.TSMatches), Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr '=' a_expr
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.EQ), Left: $1.expr(), Right: $3.expr()}
@@ -13603,7 +14530,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingOperation{Exprs: $3.exprs()}
+  }
 
 func_application:
   func_name '(' ')'
@@ -14247,6 +15177,7 @@ all_op:
 | REGIMATCH { $$.val = treecmp.MakeComparisonOperator(treecmp.RegIMatch) }
 | NOT_REGIMATCH { $$.val = treecmp.MakeComparisonOperator(treecmp.NotRegIMatch) }
 | AND_AND { $$.val = treecmp.MakeComparisonOperator(treecmp.Overlaps) }
+| AT_AT { $$.val = treecmp.MakeComparisonOperator(treecmp.TSMatches) }
 | '~' { $$.val = tree.MakeUnaryOperator(tree.UnaryComplement) }
 | SQRT { $$.val = tree.MakeUnaryOperator(tree.UnarySqrt) }
 | CBRT { $$.val = tree.MakeUnaryOperator(tree.UnaryCbrt) }
@@ -15174,6 +16105,7 @@ unreserved_keyword:
 | ASENSITIVE
 | AT
 | ATOMIC
+| ATTACH
 | ATTRIBUTE
 | AUTOMATIC
 | AVAILABILITY
@@ -15187,6 +16119,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALL
 | CALLED
 | CANCEL
 | CANCELQUERY
@@ -15209,6 +16142,7 @@ unreserved_keyword:
 | CONNECTION
 | CONNECTIONS
 | CONSTRAINTS
+| CONTINUOUS
 | CONTROLCHANGEFEED
 | CONTROLJOB
 | CONVERSION
@@ -15243,6 +16177,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTED
 | ENCRYPTION_PASSPHRASE
@@ -15333,6 +16268,7 @@ unreserved_keyword:
 | LINESTRINGZ
 | LINESTRINGZM
 | LIST
+| LISTEN
 | LOCAL
 | LOCKED
 | LOGIN
@@ -15340,6 +16276,7 @@ unreserved_keyword:
 | LOOKUP
 | LOW
 | MATCH
+| MATCHED
 | MATERIALIZED
 | MAXVALUE
 | MERGE
@@ -15382,6 +16319,7 @@ unreserved_keyword:
 | NOMODIFYCLUSTERSETTING
 | NONVOTERS
 | NOSQLLOGIN
+| NOTIFY
 | NOVIEWACTIVITY
 | NOVIEWACTIVITYREDACTED
 | NOVIEWCLUSTERSETTING
@@ -15426,6 +16364,7 @@ unreserved_keyword:
 | PRIOR
 | PRIORITY
 | PRIVILEGES
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -15511,6 +16450,7 @@ unreserved_keyword:
 | STABLE
 | START
 | STATE
+| STATEMENT
 | STATEMENTS
 | STATISTICS
 | STDIN
@@ -15564,6 +16504,7 @@ unreserved_keyword:
 | VALIDATE
 | VALUE
 | VARYING
+| VERIFY
 | VERIFY_BACKUP_TABLE_DATA
 | VIEW
 | VIEWACTIVITY
@@ -15586,20 +16527,24 @@ unreserved_keyword:
 // Any new keyword should be added to this list.
 bare_label_keywords:
   ATOMIC
+| CALL
 | CALLED
 | COST
 | DEFINER
 | DEPENDS
+| EACH
 | EXTERNAL
 | IMMUTABLE
 | INPUT
 | INVOKER
 | LEAKPROOF
 | PARALLEL
+| PROCEDURE
 | RETURN
 | RETURNS
 | SECURITY
 | STABLE
+| STATEMENT
 | SUPPORT
 | TRANSFORM
 | VOLATILE
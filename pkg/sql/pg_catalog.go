@@ -813,7 +813,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = conTypeTrigger
-	_ = conTypeExclusion
 
 	fkActionNone       = tree.NewDString("a")
 	fkActionRestrict   = tree.NewDString("r")
@@ -998,6 +997,25 @@ func populateTableConstraints(
 			}
 			condef = tree.NewDString(f.CloseAndGetString())
 
+		case descpb.ConstraintTypeExclusion:
+			contype = conTypeExclusion
+			conoid = h.UniqueConstraintOid(db.GetID(), scName, table.GetID(), con.Index.ID)
+			conindid = h.IndexOid(table.GetID(), con.Index.ID)
+			if conkey, err = colIDArrayToDatum(con.Index.KeyColumnIDs); err != nil {
+				return err
+			}
+			idx, err := table.FindIndexWithID(con.Index.ID)
+			if err != nil {
+				return err
+			}
+			def, err := catformat.ExclusionConstraintForDisplay(
+				ctx, table, idx, tree.FmtPGCatalog, p.SemaCtx(), p.SessionData(),
+			)
+			if err != nil {
+				return err
+			}
+			condef = tree.NewDString(def)
+
 		case descpb.ConstraintTypeCheck:
 			conoid = h.CheckConstraintOid(db.GetID(), scName, table.GetID(), con.CheckConstraint)
 			contype = conTypeCheck
@@ -1017,13 +1035,15 @@ func populateTableConstraints(
 			condef = tree.NewDString(fmt.Sprintf("CHECK ((%s))%s", displayExpr, validity))
 		}
 
+		condeferrable := tree.MakeDBool(tree.DBool(con.IsDeferrable()))
+		condeferred := tree.MakeDBool(tree.DBool(con.IsInitiallyDeferred()))
 		if err := addRow(
 			conoid,               // oid
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -2438,17 +2458,35 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 							return err
 						}
 						isStrict := fnDesc.GetNullInputBehavior() != catpb.Function_CALLED_ON_NULL_INPUT
+						// proargtypes only includes the input arguments, while
+						// proallargtypes includes all arguments and is only set if
+						// there are OUT arguments.
 						argTypes := tree.NewDArray(types.Oid)
+						allArgTypes := tree.NewDArray(types.Oid)
 						argModes := tree.NewDArray(types.String)
 						var argNames tree.Datum
 						argNamesArray := tree.NewDArray(types.String)
 						foundAnyArgNames := false
+						foundOutArgs := false
 						for _, arg := range fnDesc.GetArgs() {
-							if err := argTypes.Append(tree.NewDOid(arg.Type.Oid())); err != nil {
+							if arg.Class != catpb.Function_Arg_OUT {
+								if err := argTypes.Append(tree.NewDOid(arg.Type.Oid())); err != nil {
+									return err
+								}
+							}
+							if err := allArgTypes.Append(tree.NewDOid(arg.Type.Oid())); err != nil {
 								return err
 							}
-							// We only support IN arguments at the moment.
-							if err := argModes.Append(tree.NewDString("i")); err != nil {
+							argMode := "i"
+							switch arg.Class {
+							case catpb.Function_Arg_OUT:
+								argMode = "o"
+								foundOutArgs = true
+							case catpb.Function_Arg_IN_OUT:
+								argMode = "b"
+								foundOutArgs = true
+							}
+							if err := argModes.Append(tree.NewDString(argMode)); err != nil {
 								return err
 							}
 							if len(arg.Name) > 0 {
@@ -2462,6 +2500,14 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 						if foundAnyArgNames {
 							argNames = argNamesArray
 						}
+						var allArgTypesDatum tree.Datum = tree.DNull
+						if foundOutArgs {
+							allArgTypesDatum = allArgTypes
+						}
+						kind := "f"
+						if fnDesc.GetIsProcedure() {
+							kind = "p"
+						}
 
 						return addRow(
 							tree.NewDOid(catid.FuncIDToOID(fnDesc.GetID())), // oid
@@ -2482,12 +2528,12 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 							tree.MakeDBool(tree.DBool(isStrict)),                         // proisstrict
 							tree.MakeDBool(tree.DBool(fnDesc.GetReturnType().ReturnSet)), // proretset
 							tree.NewDString(funcVolatility(fnDesc.GetVolatility())),      // provolatile
-							tree.DNull, // proparallel
-							tree.NewDInt(tree.DInt(len(fnDesc.GetArgs()))),  // pronargs
+							tree.DNull,                                      // proparallel
+							tree.NewDInt(tree.DInt(argTypes.Len())),         // pronargs
 							tree.NewDInt(tree.DInt(0)),                      // pronargdefaults
 							tree.NewDOid(fnDesc.GetReturnType().Type.Oid()), // prorettype
 							tree.NewDOidVectorFromDArray(argTypes),          // proargtypes
-							tree.DNull,                                      // proallargtypes
+							allArgTypesDatum,                                // proallargtypes
 							argModes,                                        // proargmodes
 							argNames,                                        // proargnames
 							tree.DNull,                                      // proargdefaults
@@ -2497,8 +2543,8 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 							tree.DNull,                                      // proconfig
 							tree.DNull,                                      // proacl
 							// These columns were automatically created by pg_catalog_test's missing column generator.
-							tree.DNull, // prokind
-							tree.DNull, // prosupport
+							tree.NewDString(kind), // prokind
+							tree.DNull,            // prosupport
 						)
 					})
 				})
@@ -2889,15 +2935,74 @@ https://www.postgresql.org/docs/9.5/catalog-pg-tablespace.html`,
 	},
 }
 
+// Bitmasks for pg_trigger.tgtype. The constants below are the same as the
+// ones in Postgres (see src/include/catalog/pg_trigger.h).
+const (
+	tgTypeRow    = 1 << 0
+	tgTypeBefore = 1 << 1
+	tgTypeInsert = 1 << 2
+	tgTypeDelete = 1 << 3
+	tgTypeUpdate = 1 << 4
+)
+
+// tgEnabledOrigin is the pg_trigger.tgenabled value of enabled triggers.
+var tgEnabledOrigin = tree.NewDString("O")
+
 var pgCatalogTriggerTable = virtualSchemaTable{
-	comment: `triggers (empty - feature does not exist)
+	comment: `triggers
 https://www.postgresql.org/docs/9.5/catalog-pg-trigger.html`,
 	schema: vtable.PGCatalogTrigger,
 	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// Triggers are unsupported.
-		return nil
+		h := makeOidHasher()
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual tables have no triggers */
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				triggers := table.GetTriggers()
+				for i := range triggers {
+					trig := &triggers[i]
+					tgType := tgTypeRow
+					if trig.ActionTime == descpb.TriggerDescriptor_BEFORE {
+						tgType |= tgTypeBefore
+					}
+					if trig.OnInsert {
+						tgType |= tgTypeInsert
+					}
+					if trig.OnDelete {
+						tgType |= tgTypeDelete
+					}
+					if trig.OnUpdate {
+						tgType |= tgTypeUpdate
+					}
+					tgAttr, err := colIDArrayToVector(nil)
+					if err != nil {
+						return err
+					}
+					if err := addRow(
+						h.TriggerOid(table.GetID(), trig.ID),         // oid
+						tableOid(table.GetID()),                      // tgrelid
+						tree.NewDName(trig.Name),                     // tgname
+						tree.NewDOid(catid.FuncIDToOID(trig.FuncID)), // tgfoid
+						tree.NewDInt(tree.DInt(tgType)),              // tgtype
+						tgEnabledOrigin,                              // tgenabled
+						tree.DBoolFalse,                              // tgisinternal
+						oidZero,                                      // tgconstrrelid
+						oidZero,                                      // tgconstrindid
+						oidZero,                                      // tgconstraint
+						tree.DBoolFalse,                              // tgdeferrable
+						tree.DBoolFalse,                              // tginitdeferred
+						zeroVal,                                      // tgnargs
+						tgAttr,                                       // tgattr
+						tree.NewDBytes(""),                           // tgargs
+						tree.DNull,                                   // tgqual
+						tree.DNull,                                   // tgoldtable
+						tree.DNull,                                   // tgnewtable
+						oidZero,                                      // tgparentid
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var (
@@ -2909,7 +3014,6 @@ var (
 	typTypeRange     = tree.NewDString("r")
 
 	// Avoid unused warning for constants.
-	_ = typTypeDomain
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -3031,8 +3135,8 @@ func addPGTypeRow(
 			builtinPrefix = "array_"
 			typElem = tree.NewDOid(typ.ArrayContents().Oid())
 		}
-	case types.VoidFamily:
-		// void does not have an array type.
+	case types.VoidFamily, types.TriggerFamily:
+		// void and trigger do not have array types.
 	default:
 		typArray = tree.NewDOid(types.CalcArrayOid(typ))
 	}
@@ -3043,6 +3147,19 @@ func addPGTypeRow(
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
+	typNotNull := tree.DBoolFalse
+	typBaseType := oidZero
+	typDefault := tree.DNull
+	if typ.IsDomain() {
+		typType = typTypeDomain
+		typBaseType = tree.NewDOid(typ.DomainBaseType().Oid())
+		if data := typ.TypeMeta.DomainData; data != nil {
+			typNotNull = tree.MakeDBool(tree.DBool(data.NotNull))
+			if data.DefaultExpr != nil {
+				typDefault = tree.NewDString(*data.DefaultExpr)
+			}
+		}
+	}
 	typname := typ.PGName()
 	typDelim := tree.NewDString(typ.Delimiter())
 	return addRow(
@@ -3072,13 +3189,13 @@ func addPGTypeRow(
 
 		tree.DNull,      // typalign
 		tree.DNull,      // typstorage
-		tree.DBoolFalse, // typnotnull
-		oidZero,         // typbasetype
+		typNotNull,      // typnotnull
+		typBaseType,     // typbasetype
 		negOneVal,       // typtypmod
 		zeroVal,         // typndims
 		typColl(typ, h), // typcollation
 		tree.DNull,      // typdefaultbin
-		tree.DNull,      // typdefault
+		typDefault,      // typdefault
 		tree.DNull,      // typacl
 	)
 }
@@ -3723,12 +3840,33 @@ var pgCatalogStatUserFunctionsTable = virtualSchemaTable{
 }
 
 var pgCatalogPublicationTable = virtualSchemaTable{
-	comment: "pg_publication was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogPublication,
-	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		return nil
+	comment: `logical replication publications
+https://www.postgresql.org/docs/current/catalog-pg-publication.html`,
+	schema: vtable.PgCatalogPublication,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachDatabaseDesc(ctx, p, dbContext, false, /* requiresPrivileges */
+			func(db catalog.DatabaseDescriptor) error {
+				pubs := db.GetPublications()
+				for i := range pubs {
+					pub := &pubs[i]
+					if err := addRow(
+						h.PublicationOid(db.GetID(), pub.ID),            // oid
+						tree.NewDName(pub.Name),                         // pubname
+						h.UserOid(pub.OwnerProto.Decode()),              // pubowner
+						tree.MakeDBool(tree.DBool(pub.AllTables)),       // puballtables
+						tree.MakeDBool(tree.DBool(pub.PublishInsert)),   // pubinsert
+						tree.MakeDBool(tree.DBool(pub.PublishUpdate)),   // pubupdate
+						tree.MakeDBool(tree.DBool(pub.PublishDelete)),   // pubdelete
+						tree.MakeDBool(tree.DBool(pub.PublishTruncate)), // pubtruncate
+						tree.MakeDBool(false),                           // pubviaroot
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogAmprocTable = virtualSchemaTable{
@@ -3883,12 +4021,29 @@ var pgCatalogStatXactUserTablesTable = virtualSchemaTable{
 }
 
 var pgCatalogPublicationTablesTable = virtualSchemaTable{
-	comment: "pg_publication_tables was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogPublicationTables,
-	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		return nil
+	comment: `tables published by logical replication publications
+https://www.postgresql.org/docs/current/view-pg-publication-tables.html`,
+	schema: vtable.PgCatalogPublicationTables,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual tables are not published */
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				pubs := db.GetPublications()
+				for i := range pubs {
+					pub := &pubs[i]
+					if !publicationIncludesTable(pub, table) {
+						continue
+					}
+					if err := addRow(
+						tree.NewDName(pub.Name),        // pubname
+						tree.NewDName(scName),          // schemaname
+						tree.NewDName(table.GetName()), // tablename
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogStatProgressClusterTable = virtualSchemaTable{
@@ -4162,12 +4317,31 @@ var pgCatalogStatReplicationTable = virtualSchemaTable{
 }
 
 var pgCatalogPublicationRelTable = virtualSchemaTable{
-	comment: "pg_publication_rel was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogPublicationRel,
-	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		return nil
+	comment: `tables explicitly added to logical replication publications
+https://www.postgresql.org/docs/current/catalog-pg-publication-rel.html`,
+	schema: vtable.PgCatalogPublicationRel,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual tables are not published */
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				pubs := db.GetPublications()
+				for i := range pubs {
+					pub := &pubs[i]
+					// FOR ALL TABLES publications have no explicit members.
+					if pub.AllTables || !publicationIncludesTable(pub, table) {
+						continue
+					}
+					if err := addRow(
+						h.PublicationRelOid(db.GetID(), pub.ID, table.GetID()), // oid
+						h.PublicationOid(db.GetID(), pub.ID),                   // prpubid
+						tableOid(table.GetID()),                                // prrelid
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogAvailableExtensionVersionsTable = virtualSchemaTable{
@@ -4238,6 +4412,8 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.StringFamily:      typCategoryString,
 	types.TimestampFamily:   typCategoryDateTime,
 	types.TimestampTZFamily: typCategoryDateTime,
+	types.TSQueryFamily:     typCategoryUserDefined,
+	types.TSVectorFamily:    typCategoryUserDefined,
 	types.ArrayFamily:       typCategoryArray,
 	types.TupleFamily:       typCategoryPseudo,
 	types.OidFamily:         typCategoryNumeric,
@@ -4245,6 +4421,7 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.INetFamily:        typCategoryNetworkAddr,
 	types.UnknownFamily:     typCategoryUnknown,
 	types.VoidFamily:        typCategoryPseudo,
+	types.TriggerFamily:     typCategoryPseudo,
 }
 
 func typCategory(typ *types.T) tree.Datum {
@@ -4463,6 +4640,9 @@ const (
 	rewriteTypeTag
 	dbSchemaRoleTypeTag
 	castTypeTag
+	triggerTypeTag
+	publicationTypeTag
+	publicationRelTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -4631,6 +4811,36 @@ func (h oidHasher) rewriteOid(source descpb.ID, depended descpb.ID) *tree.DOid {
 	return h.getOid()
 }
 
+// TriggerOid creates an OID for the trigger with the given ID on the given
+// table.
+func (h oidHasher) TriggerOid(tableID descpb.ID, triggerID descpb.TriggerID) *tree.DOid {
+	h.writeTypeTag(triggerTypeTag)
+	h.writeTable(tableID)
+	h.writeUInt32(uint32(triggerID))
+	return h.getOid()
+}
+
+// PublicationOid creates an OID for the publication with the given ID in the
+// given database.
+func (h oidHasher) PublicationOid(dbID descpb.ID, pubID descpb.PublicationID) *tree.DOid {
+	h.writeTypeTag(publicationTypeTag)
+	h.writeDB(dbID)
+	h.writeUInt32(uint32(pubID))
+	return h.getOid()
+}
+
+// PublicationRelOid creates an OID for the membership of the given table in
+// the publication with the given ID in the given database.
+func (h oidHasher) PublicationRelOid(
+	dbID descpb.ID, pubID descpb.PublicationID, tableID descpb.ID,
+) *tree.DOid {
+	h.writeTypeTag(publicationRelTypeTag)
+	h.writeDB(dbID)
+	h.writeUInt32(uint32(pubID))
+	h.writeTable(tableID)
+	return h.getOid()
+}
+
 // DBSchemaRoleOid creates an OID based on the combination of a db/schema/role.
 // This is used to generate a unique row identifier for pg_default_acl.
 func (h oidHasher) DBSchemaRoleOid(
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -59,6 +60,7 @@ type commandResult struct {
 	buffer struct {
 		notices            []pgnotice.Notice
 		paramStatusUpdates []paramStatusUpdate
+		notifications      []pgnotify.Notification
 	}
 
 	err error
@@ -144,6 +146,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		}
 	}
 
+	for _, notification := range r.buffer.notifications {
+		if err := r.conn.bufferNotification(notification); err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "unexpected err when sending notification"))
+		}
+	}
+
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
@@ -241,6 +249,31 @@ func (r *commandResult) DisableBuffering() {
 	r.bufferingDisabled = true
 }
 
+// BufferedResultsLen is part of the sql.RestrictedCommandResult interface.
+func (r *commandResult) BufferedResultsLen() int {
+	r.assertNotReleased()
+	return r.conn.writerState.buf.Len()
+}
+
+// TruncateBufferedResults is part of the sql.RestrictedCommandResult interface.
+func (r *commandResult) TruncateBufferedResults(idx int) bool {
+	r.assertNotReleased()
+	fi := &r.conn.writerState.fi
+	if fi.lastFlushed >= r.pos || idx < 0 || idx > fi.buf.Len() {
+		return false
+	}
+	// If the start of the results of this command was registered after idx, it
+	// will be registered again when the results are produced again.
+	if !fi.cmdStarts.empty() {
+		if last := fi.cmdStarts.getLast(); last.pos == r.pos && last.idx > idx {
+			fi.cmdStarts.removeLast()
+		}
+	}
+	fi.buf.Truncate(idx)
+	r.rowsAffected = 0
+	return true
+}
+
 // BufferParamStatusUpdate is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) BufferParamStatusUpdate(param string, val string) {
 	r.buffer.paramStatusUpdates = append(
@@ -254,6 +287,11 @@ func (r *commandResult) BufferNotice(notice pgnotice.Notice) {
 	r.buffer.notices = append(r.buffer.notices, notice)
 }
 
+// BufferNotification is part of the sql.NotificationBuffer interface.
+func (r *commandResult) BufferNotification(notification pgnotify.Notification) {
+	r.buffer.notifications = append(r.buffer.notifications, notification)
+}
+
 // SetColumns is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.assertNotReleased()
@@ -446,6 +484,15 @@ func (r *limitedCommandResult) SupportsAddBatch() bool {
 	return false
 }
 
+// TruncateBufferedResults is part of the sql.RestrictedCommandResult interface.
+func (r *limitedCommandResult) TruncateBufferedResults(idx int) bool {
+	if !r.commandResult.TruncateBufferedResults(idx) {
+		return false
+	}
+	r.seenTuples = 0
+	return true
+}
+
 // moreResultsNeeded is a restricted connection handler that waits for more
 // requests for rows from the active portal, during the "execute portal" flow
 // when a limit has been specified.
@@ -505,6 +552,10 @@ func (r *limitedCommandResult) moreResultsNeeded(ctx context.Context) error {
 			if err := r.conn.Flush(r.pos); err != nil {
 				return err
 			}
+		case sql.DeliverNotifications:
+			// Notifications are only delivered outside of transactions, so they'll
+			// be delivered once the portal's transaction is over.
+			r.conn.stmtBuf.AdvanceOne()
 		default:
 			// If the portal is immediately followed by a COMMIT, we can proceed and
 			// let the portal be destroyed at the end of the transaction.
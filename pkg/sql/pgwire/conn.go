@@ -31,6 +31,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirecancel"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -749,6 +751,14 @@ func (c *conn) bufferNotice(ctx context.Context, noticeErr pgnotice.Notice) erro
 	return writeErrFields(ctx, c.sv, noticeErr, &c.msgBuilder, &c.writerState.buf)
 }
 
+func (c *conn) bufferNotification(notification pgnotify.Notification) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(int32(notification.SenderPID))
+	c.msgBuilder.writeTerminatedString(notification.Channel)
+	c.msgBuilder.writeTerminatedString(notification.Payload)
+	return c.msgBuilder.finishMsg(&c.writerState.buf)
+}
+
 func (c *conn) sendInitialConnData(
 	ctx context.Context, sqlServer *sql.Server, onDefaultIntSizeChange func(newSize int32),
 ) (sql.ConnectionHandler, error) {
@@ -824,6 +834,17 @@ func (c *conn) handleSimpleQuery(
 	}
 
 	startParse := timeutil.Now()
+	if c.sessionArgs.Replication {
+		// Replication connections accept replication commands in addition to
+		// SQL statements.
+		stmt, ok, err := pgrepl.ParseCommand(query)
+		if err != nil {
+			return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
+		}
+		if ok {
+			return c.handleReplicationCommand(ctx, query, stmt, timeReceived, startParse)
+		}
+	}
 	stmts, err := c.parser.ParseWithInt(query, unqualifiedIntSize)
 	if err != nil {
 		log.SqlExec.Errorf(ctx, "failed to parse simple query: %s", query)
@@ -893,6 +914,49 @@ func (c *conn) handleSimpleQuery(
 	return nil
 }
 
+// handleReplicationCommand pushes a replication command for execution.
+//
+// The START_REPLICATION command is special: like COPY, it takes control of the
+// connection, so this network routine blocks until the stream ends.
+//
+// An error is returned iff the statement buffer has been closed. In that case,
+// the connection should be considered toast.
+func (c *conn) handleReplicationCommand(
+	ctx context.Context,
+	query string,
+	stmt tree.Statement,
+	timeReceived time.Time,
+	startParse time.Time,
+) error {
+	endParse := timeutil.Now()
+	if sr, ok := stmt.(*pgrepl.StartReplication); ok {
+		streamDone := sync.WaitGroup{}
+		streamDone.Add(1)
+		if err := c.stmtBuf.Push(
+			ctx,
+			sql.StartReplication{
+				Stmt:         sr,
+				Conn:         c,
+				StreamDone:   &streamDone,
+				TimeReceived: timeReceived,
+			},
+		); err != nil {
+			return err
+		}
+		streamDone.Wait()
+		return nil
+	}
+	return c.stmtBuf.Push(
+		ctx,
+		sql.ExecStmt{
+			Statement:    parser.Statement{AST: stmt, SQL: query},
+			TimeReceived: timeReceived,
+			ParseStart:   startParse,
+			ParseEnd:     endParse,
+			LastInBatch:  true,
+		})
+}
+
 // An error is returned iff the statement buffer has been closed. In that case,
 // the connection should be considered toast.
 func (c *conn) handleParse(
@@ -1316,7 +1380,7 @@ func cookTag(
 		tag = strconv.AppendInt(tag, int64(rowsAffected), 10)
 
 	case tree.Rows:
-		if tagStr != "SHOW" {
+		if tagStr != "SHOW" && tagStr != "CALL" {
 			tag = append(tag, ' ')
 			tag = strconv.AppendUint(tag, uint64(rowsAffected), 10)
 		}
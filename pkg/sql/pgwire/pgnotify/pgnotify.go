@@ -0,0 +1,25 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgnotify
+
+// Notification is an asynchronous notification sent with NOTIFY or
+// pg_notify(). It is delivered to the client of every session listening on
+// its channel as a NotificationResponse message.
+type Notification struct {
+	// Channel is the name of the channel the notification was sent on.
+	Channel string
+	// Payload is the payload of the notification. It is empty if none was
+	// specified.
+	Payload string
+	// SenderPID identifies the session that sent the notification. It matches
+	// the value returned by pg_backend_pid() in that session.
+	SenderPID uint32
+}
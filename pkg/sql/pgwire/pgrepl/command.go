@@ -0,0 +1,544 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// OutputPlugin is the name of the only supported logical decoding output
+// plugin.
+const OutputPlugin = "pgoutput"
+
+// The replication commands below are only accepted on connections opened with
+// the replication=database startup parameter. They are parsed by
+// ParseCommand rather than by the SQL parser, like Postgres does, but they
+// implement tree.Statement so that they can be executed like any other
+// statement.
+
+// IdentifySystem represents an IDENTIFY_SYSTEM replication command.
+type IdentifySystem struct{}
+
+// CreateReplicationSlot represents a CREATE_REPLICATION_SLOT replication
+// command.
+type CreateReplicationSlot struct {
+	Slot      tree.Name
+	Temporary bool
+	Plugin    tree.Name
+	// Snapshot is what to do with the snapshot of the data at the point the
+	// slot was created: SnapshotExport, SnapshotNothing or SnapshotUse.
+	Snapshot string
+}
+
+// The snapshot actions of CREATE_REPLICATION_SLOT.
+const (
+	SnapshotExport  = "export"
+	SnapshotNothing = "nothing"
+	SnapshotUse     = "use"
+)
+
+// DropReplicationSlot represents a DROP_REPLICATION_SLOT replication command.
+type DropReplicationSlot struct {
+	Slot tree.Name
+	Wait bool
+}
+
+// StartReplication represents a START_REPLICATION replication command, which
+// starts streaming the changes of the tables of publications using the
+// pgoutput protocol.
+type StartReplication struct {
+	Slot    tree.Name
+	LSN     LSN
+	Options []Option
+}
+
+// Option is an option of a replication command, e.g. an option passed to the
+// output plugin by START_REPLICATION.
+type Option struct {
+	Name  string
+	Value string
+	// HasValue is false if the option was specified without a value.
+	HasValue bool
+}
+
+var _ tree.Statement = &IdentifySystem{}
+var _ tree.Statement = &CreateReplicationSlot{}
+var _ tree.Statement = &DropReplicationSlot{}
+var _ tree.Statement = &StartReplication{}
+
+// Format implements the tree.NodeFormatter interface.
+func (*IdentifySystem) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("IDENTIFY_SYSTEM")
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (n *CreateReplicationSlot) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("CREATE_REPLICATION_SLOT ")
+	ctx.FormatNode(&n.Slot)
+	if n.Temporary {
+		ctx.WriteString(" TEMPORARY")
+	}
+	ctx.WriteString(" LOGICAL ")
+	ctx.FormatNode(&n.Plugin)
+	if n.Snapshot != "" {
+		ctx.WriteString(" (SNAPSHOT ")
+		lexbase.EncodeSQLString(&ctx.Buffer, n.Snapshot)
+		ctx.WriteString(")")
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (n *DropReplicationSlot) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("DROP_REPLICATION_SLOT ")
+	ctx.FormatNode(&n.Slot)
+	if n.Wait {
+		ctx.WriteString(" WAIT")
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (n *StartReplication) Format(ctx *tree.FmtCtx) {
+	ctx.WriteString("START_REPLICATION SLOT ")
+	ctx.FormatNode(&n.Slot)
+	ctx.WriteString(" LOGICAL ")
+	ctx.WriteString(n.LSN.String())
+	if len(n.Options) > 0 {
+		ctx.WriteString(" (")
+		for i, o := range n.Options {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			lexbase.EncodeRestrictedSQLIdent(&ctx.Buffer, o.Name, lexbase.EncNoFlags)
+			if o.HasValue {
+				ctx.WriteByte(' ')
+				lexbase.EncodeSQLString(&ctx.Buffer, o.Value)
+			}
+		}
+		ctx.WriteString(")")
+	}
+}
+
+func (n *IdentifySystem) String() string        { return tree.AsString(n) }
+func (n *CreateReplicationSlot) String() string { return tree.AsString(n) }
+func (n *DropReplicationSlot) String() string   { return tree.AsString(n) }
+func (n *StartReplication) String() string      { return tree.AsString(n) }
+
+// StatementReturnType implements the tree.Statement interface.
+func (*IdentifySystem) StatementReturnType() tree.StatementReturnType { return tree.Rows }
+
+// StatementType implements the tree.Statement interface.
+func (*IdentifySystem) StatementType() tree.StatementType { return tree.TypeDML }
+
+// StatementTag implements the tree.Statement interface.
+func (*IdentifySystem) StatementTag() string { return "IDENTIFY_SYSTEM" }
+
+// StatementReturnType implements the tree.Statement interface.
+func (*CreateReplicationSlot) StatementReturnType() tree.StatementReturnType { return tree.Rows }
+
+// StatementType implements the tree.Statement interface.
+func (*CreateReplicationSlot) StatementType() tree.StatementType { return tree.TypeDML }
+
+// StatementTag implements the tree.Statement interface.
+func (*CreateReplicationSlot) StatementTag() string { return "CREATE_REPLICATION_SLOT" }
+
+// StatementReturnType implements the tree.Statement interface.
+func (*DropReplicationSlot) StatementReturnType() tree.StatementReturnType { return tree.Ack }
+
+// StatementType implements the tree.Statement interface.
+func (*DropReplicationSlot) StatementType() tree.StatementType { return tree.TypeTCL }
+
+// StatementTag implements the tree.Statement interface.
+func (*DropReplicationSlot) StatementTag() string { return "DROP_REPLICATION_SLOT" }
+
+// StatementReturnType implements the tree.Statement interface.
+//
+// START_REPLICATION takes control of the connection, like COPY does, so it
+// does not return results the usual way.
+func (*StartReplication) StatementReturnType() tree.StatementReturnType { return tree.Unknown }
+
+// StatementType implements the tree.Statement interface.
+func (*StartReplication) StatementType() tree.StatementType { return tree.TypeDML }
+
+// StatementTag implements the tree.Statement interface.
+func (*StartReplication) StatementTag() string { return "START_REPLICATION" }
+
+// ParseCommand parses a query received on a replication connection. ok is
+// false if the query is not a replication command, in which case it should be
+// parsed as SQL.
+func ParseCommand(query string) (_ tree.Statement, ok bool, _ error) {
+	s := scanner{in: query}
+	first := s.peek()
+	if first.typ != tokIdent || first.quoted {
+		return nil, false, nil
+	}
+	var stmt tree.Statement
+	var err error
+	switch first.val {
+	case "identify_system":
+		s.next()
+		stmt = &IdentifySystem{}
+	case "create_replication_slot":
+		s.next()
+		stmt, err = s.parseCreateReplicationSlot()
+	case "drop_replication_slot":
+		s.next()
+		stmt, err = s.parseDropReplicationSlot()
+	case "start_replication":
+		s.next()
+		stmt, err = s.parseStartReplication()
+	case "timeline_history", "base_backup", "read_replication_slot":
+		return nil, true, pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not supported", strings.ToUpper(first.val))
+	default:
+		return nil, false, nil
+	}
+	if err == nil {
+		err = s.parseEnd()
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return stmt, true, nil
+}
+
+// parseCreateReplicationSlot parses the arguments of CREATE_REPLICATION_SLOT:
+//
+//	slot_name [ TEMPORARY ] LOGICAL output_plugin
+//	  [ EXPORT_SNAPSHOT | NOEXPORT_SNAPSHOT | USE_SNAPSHOT
+//	  | ( SNAPSHOT { 'export' | 'nothing' | 'use' } [, ...] ) ]
+func (s *scanner) parseCreateReplicationSlot() (*CreateReplicationSlot, error) {
+	n := &CreateReplicationSlot{Snapshot: SnapshotExport}
+	slot, err := s.expectIdent("slot name")
+	if err != nil {
+		return nil, err
+	}
+	n.Slot = tree.Name(slot)
+	if s.acceptKeyword("temporary") {
+		n.Temporary = true
+	}
+	if s.acceptKeyword("physical") {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"physical replication slots are not supported")
+	}
+	if err := s.expectKeyword("logical"); err != nil {
+		return nil, err
+	}
+	plugin, err := s.expectIdent("output plugin")
+	if err != nil {
+		return nil, err
+	}
+	n.Plugin = tree.Name(plugin)
+	switch {
+	case s.acceptKeyword("export_snapshot"):
+		n.Snapshot = SnapshotExport
+	case s.acceptKeyword("noexport_snapshot"):
+		n.Snapshot = SnapshotNothing
+	case s.acceptKeyword("use_snapshot"):
+		n.Snapshot = SnapshotUse
+	case s.peek().typ == tokLParen:
+		opts, err := s.parseOptions()
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range opts {
+			switch o.Name {
+			case "snapshot":
+				switch o.Value {
+				case SnapshotExport, SnapshotNothing, SnapshotUse:
+					n.Snapshot = o.Value
+				default:
+					return nil, pgerror.Newf(pgcode.Syntax,
+						"unrecognized value for CREATE_REPLICATION_SLOT option \"snapshot\": %q", o.Value)
+				}
+			case "two_phase", "reserve_wal":
+				// Two-phase commits are never streamed and there is no WAL to
+				// reserve.
+			default:
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"unrecognized CREATE_REPLICATION_SLOT option %q", o.Name)
+			}
+		}
+	}
+	return n, nil
+}
+
+// parseDropReplicationSlot parses the arguments of DROP_REPLICATION_SLOT:
+//
+//	slot_name [ WAIT ]
+func (s *scanner) parseDropReplicationSlot() (*DropReplicationSlot, error) {
+	slot, err := s.expectIdent("slot name")
+	if err != nil {
+		return nil, err
+	}
+	return &DropReplicationSlot{Slot: tree.Name(slot), Wait: s.acceptKeyword("wait")}, nil
+}
+
+// parseStartReplication parses the arguments of START_REPLICATION:
+//
+//	SLOT slot_name LOGICAL XXX/XXX [ ( option_name [ 'option_value' ] [, ...] ) ]
+func (s *scanner) parseStartReplication() (*StartReplication, error) {
+	if !s.acceptKeyword("slot") {
+		return nil, pgerror.New(pgcode.FeatureNotSupported, "physical replication is not supported")
+	}
+	slot, err := s.expectIdent("slot name")
+	if err != nil {
+		return nil, err
+	}
+	if s.acceptKeyword("physical") {
+		return nil, pgerror.New(pgcode.FeatureNotSupported, "physical replication is not supported")
+	}
+	if err := s.expectKeyword("logical"); err != nil {
+		return nil, err
+	}
+	t := s.next()
+	if t.typ != tokLSN {
+		return nil, s.syntaxError(t)
+	}
+	lsn, err := ParseLSN(t.val)
+	if err != nil {
+		return nil, err
+	}
+	n := &StartReplication{Slot: tree.Name(slot), LSN: lsn}
+	if s.peek().typ == tokLParen {
+		if n.Options, err = s.parseOptions(); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// parseOptions parses a parenthesized list of options, each of which is made
+// of a name optionally followed by a string value.
+func (s *scanner) parseOptions() ([]Option, error) {
+	s.next()
+	var opts []Option
+	for {
+		name, err := s.expectIdent("option name")
+		if err != nil {
+			return nil, err
+		}
+		o := Option{Name: name}
+		if t := s.peek(); t.typ == tokString || (t.typ == tokIdent && !t.quoted) {
+			// Postgres accepts unquoted values in the new style option syntax,
+			// e.g. (SNAPSHOT nothing).
+			s.next()
+			o.Value, o.HasValue = t.val, true
+		}
+		opts = append(opts, o)
+		switch t := s.next(); t.typ {
+		case tokComma:
+		case tokRParen:
+			return opts, nil
+		default:
+			return nil, s.syntaxError(t)
+		}
+	}
+}
+
+// parseEnd checks that the whole query has been consumed, allowing for a
+// trailing semicolon.
+func (s *scanner) parseEnd() error {
+	if s.peek().typ == tokSemicolon {
+		s.next()
+	}
+	if t := s.next(); t.typ != tokEOF {
+		return s.syntaxError(t)
+	}
+	return nil
+}
+
+func (s *scanner) acceptKeyword(kw string) bool {
+	if t := s.peek(); t.typ == tokIdent && !t.quoted && t.val == kw {
+		s.next()
+		return true
+	}
+	return false
+}
+
+func (s *scanner) expectKeyword(kw string) error {
+	if !s.acceptKeyword(kw) {
+		return s.syntaxError(s.next())
+	}
+	return nil
+}
+
+func (s *scanner) expectIdent(what string) (string, error) {
+	t := s.next()
+	if t.typ != tokIdent {
+		return "", errors.WithHintf(s.syntaxError(t), "expected %s", what)
+	}
+	return t.val, nil
+}
+
+func (s *scanner) syntaxError(t token) error {
+	if t.typ == tokEOF {
+		return pgerror.New(pgcode.Syntax, "syntax error at end of input")
+	}
+	if t.typ == tokError {
+		return pgerror.Newf(pgcode.Syntax, "syntax error: %s", t.val)
+	}
+	return pgerror.Newf(pgcode.Syntax, "syntax error at or near %q", s.in[t.pos:s.pos])
+}
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokError
+	tokIdent
+	tokString
+	tokLSN
+	tokNumber
+	tokLParen
+	tokRParen
+	tokComma
+	tokSemicolon
+)
+
+type token struct {
+	typ tokenType
+	// val is the value of identifiers, strings, LSNs and numbers. Unquoted
+	// identifiers are folded to lowercase.
+	val    string
+	quoted bool
+	pos    int
+}
+
+// scanner tokenizes replication commands, following the rules of the
+// Postgres replication command lexer.
+type scanner struct {
+	in     string
+	pos    int
+	peeked *token
+}
+
+func (s *scanner) peek() token {
+	if s.peeked == nil {
+		t := s.scan()
+		s.peeked = &t
+	}
+	return *s.peeked
+}
+
+func (s *scanner) next() token {
+	t := s.peek()
+	s.peeked = nil
+	return t
+}
+
+func (s *scanner) scan() token {
+	for s.pos < len(s.in) && isSpace(s.in[s.pos]) {
+		s.pos++
+	}
+	start := s.pos
+	if s.pos >= len(s.in) {
+		return token{typ: tokEOF, pos: start}
+	}
+	c := s.in[s.pos]
+	switch {
+	case c == '(':
+		s.pos++
+		return token{typ: tokLParen, pos: start}
+	case c == ')':
+		s.pos++
+		return token{typ: tokRParen, pos: start}
+	case c == ',':
+		s.pos++
+		return token{typ: tokComma, pos: start}
+	case c == ';':
+		s.pos++
+		return token{typ: tokSemicolon, pos: start}
+	case c == '\'' || c == '"':
+		val, ok := s.scanQuoted(c)
+		if !ok {
+			return token{typ: tokError, val: "unterminated quoted string", pos: start}
+		}
+		if c == '"' {
+			return token{typ: tokIdent, val: val, quoted: true, pos: start}
+		}
+		return token{typ: tokString, val: val, pos: start}
+	case isHexDigit(c):
+		for s.pos < len(s.in) && isHexDigit(s.in[s.pos]) {
+			s.pos++
+		}
+		if s.pos < len(s.in) && s.in[s.pos] == '/' {
+			s.pos++
+			for s.pos < len(s.in) && isHexDigit(s.in[s.pos]) {
+				s.pos++
+			}
+			return token{typ: tokLSN, val: s.in[start:s.pos], pos: start}
+		}
+		if isNumber(s.in[start:s.pos]) {
+			return token{typ: tokNumber, val: s.in[start:s.pos], pos: start}
+		}
+		// The hex digits are the start of an identifier.
+		s.pos = start
+	}
+	if isIdentStart(c) {
+		for s.pos < len(s.in) && isIdentChar(s.in[s.pos]) {
+			s.pos++
+		}
+		return token{typ: tokIdent, val: strings.ToLower(s.in[start:s.pos]), pos: start}
+	}
+	s.pos++
+	return token{typ: tokError, val: "unexpected character " + s.in[start:s.pos], pos: start}
+}
+
+// scanQuoted scans a string or identifier delimited by the quote character,
+// in which the quote character is escaped by doubling it.
+func (s *scanner) scanQuoted(quote byte) (string, bool) {
+	var b strings.Builder
+	s.pos++
+	for s.pos < len(s.in) {
+		c := s.in[s.pos]
+		s.pos++
+		if c == quote {
+			if s.pos < len(s.in) && s.in[s.pos] == quote {
+				s.pos++
+			} else {
+				return b.String(), true
+			}
+		}
+		b.WriteByte(c)
+	}
+	return "", false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isNumber(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		in       string
+		expected string
+	}{
+		{`IDENTIFY_SYSTEM`, `IDENTIFY_SYSTEM`},
+		{`  identify_system ; `, `IDENTIFY_SYSTEM`},
+		{
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput`,
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT 'export')`,
+		},
+		{
+			`CREATE_REPLICATION_SLOT "Slot" TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT`,
+			`CREATE_REPLICATION_SLOT "Slot" TEMPORARY LOGICAL pgoutput (SNAPSHOT 'nothing')`,
+		},
+		{
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT 'use', TWO_PHASE)`,
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT 'use')`,
+		},
+		{
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT nothing)`,
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT 'nothing')`,
+		},
+		{`DROP_REPLICATION_SLOT s`, `DROP_REPLICATION_SLOT s`},
+		{`drop_replication_slot s wait;`, `DROP_REPLICATION_SLOT s WAIT`},
+		{
+			`START_REPLICATION SLOT s LOGICAL 0/0`,
+			`START_REPLICATION SLOT s LOGICAL 0/0`,
+		},
+		{
+			`START_REPLICATION SLOT "debezium" LOGICAL 16/b374d848 ("proto_version" '1', publication_names '"Pub", b', binary)`,
+			`START_REPLICATION SLOT debezium LOGICAL 16/B374D848 (proto_version '1', publication_names '"Pub", b', binary)`,
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			stmt, ok, err := ParseCommand(tc.in)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, tree.AsString(stmt))
+		})
+	}
+}
+
+func TestParseCommandNotReplication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, in := range []string{
+		``,
+		`;`,
+		`SELECT 1`,
+		`"identify_system"`,
+		`CREATE PUBLICATION p FOR ALL TABLES`,
+		`'start_replication'`,
+	} {
+		stmt, ok, err := ParseCommand(in)
+		require.NoError(t, err)
+		require.False(t, ok, in)
+		require.Nil(t, stmt)
+	}
+}
+
+func TestParseCommandErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		in   string
+		code pgcode.Code
+		err  string
+	}{
+		{`BASE_BACKUP`, pgcode.FeatureNotSupported, `BASE_BACKUP is not supported`},
+		{`timeline_history 1`, pgcode.FeatureNotSupported, `TIMELINE_HISTORY is not supported`},
+		{`START_REPLICATION 0/0`, pgcode.FeatureNotSupported, `physical replication is not supported`},
+		{`START_REPLICATION SLOT s PHYSICAL 0/0`, pgcode.FeatureNotSupported, `physical replication is not supported`},
+		{`CREATE_REPLICATION_SLOT s PHYSICAL`, pgcode.FeatureNotSupported, `physical replication slots are not supported`},
+		{`IDENTIFY_SYSTEM foo`, pgcode.Syntax, `syntax error at or near "foo"`},
+		{`START_REPLICATION SLOT s LOGICAL`, pgcode.Syntax, `syntax error at end of input`},
+		{`START_REPLICATION SLOT s LOGICAL 1`, pgcode.Syntax, `syntax error at or near "1"`},
+		{`START_REPLICATION SLOT s LOGICAL 100000000/0`, pgcode.Syntax, `invalid LSN "100000000/0"`},
+		{`START_REPLICATION SLOT s LOGICAL 0/0 (a 'b'`, pgcode.Syntax, `syntax error at end of input`},
+		{`START_REPLICATION SLOT s LOGICAL 0/0 (a 'b`, pgcode.Syntax, `syntax error: unterminated quoted string`},
+		{`DROP_REPLICATION_SLOT 's'`, pgcode.Syntax, `syntax error at or near "'s'"`},
+		{`DROP_REPLICATION_SLOT s; x`, pgcode.Syntax, `syntax error at or near "x"`},
+		{
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (SNAPSHOT 'bad')`,
+			pgcode.Syntax,
+			`unrecognized value for CREATE_REPLICATION_SLOT option "snapshot": "bad"`,
+		},
+		{
+			`CREATE_REPLICATION_SLOT s LOGICAL pgoutput (FOO)`,
+			pgcode.Syntax,
+			`unrecognized CREATE_REPLICATION_SLOT option "foo"`,
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			_, ok, err := ParseCommand(tc.in)
+			require.True(t, ok)
+			require.EqualError(t, err, tc.err)
+			require.Equal(t, tc.code, pgerror.GetPGCode(err))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/lib/pq/oid"
+)
+
+// Relation describes a table whose changes are streamed. A Relation message
+// is sent before the first change of every version of a table.
+type Relation struct {
+	ID oid.Oid
+	// Version is the version of the table descriptor the relation was derived
+	// from.
+	Version   uint64
+	Namespace string
+	Name      string
+	Columns   []Column
+}
+
+// Column is a column of a Relation.
+type Column struct {
+	Name string
+	Type *types.T
+	// Key is set for the columns of the primary key.
+	Key bool
+}
+
+// ChangeType is the type of a Change.
+type ChangeType int
+
+const (
+	// Insert is a change which adds a row.
+	Insert ChangeType = iota
+	// Update is a change which modifies an existing row.
+	Update
+	// Delete is a change which removes a row.
+	Delete
+)
+
+// Change is a change to a single row.
+type Change struct {
+	Type     ChangeType
+	Relation *Relation
+	// Row contains the values of all the columns of the relation. For deletes,
+	// it contains the values of the deleted row.
+	Row tree.Datums
+}
+
+// Transaction is a set of changes committed together.
+type Transaction struct {
+	LSN        LSN
+	CommitTime time.Time
+	Changes    []Change
+}
+
+// Event is an event of a Feed. Exactly one of its fields is set.
+type Event struct {
+	// Txn is a transaction to stream.
+	Txn *Transaction
+	// Checkpoint is an LSN up to which all the transactions have been
+	// streamed. It is reported to the client by keepalive messages.
+	Checkpoint LSN
+	// Err is an error which terminates the feed.
+	Err error
+}
+
+// Feed produces the transactions streamed by START_REPLICATION, in LSN order.
+type Feed interface {
+	// Events returns the channel on which the events of the feed are sent.
+	Events() <-chan Event
+	// Close stops the feed.
+	Close()
+}
+
+// Conn is the connection on which the transactions of a Feed are streamed.
+type Conn interface {
+	// StreamReplication streams the transactions of the feed to the client
+	// using the pgoutput protocol, until the client ends the stream. The feed
+	// is closed when it returns.
+	StreamReplication(
+		ctx context.Context,
+		startLSN LSN,
+		feed Feed,
+		conv sessiondatapb.DataConversionConfig,
+		loc *time.Location,
+	) error
+}
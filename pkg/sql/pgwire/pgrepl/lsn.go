@@ -0,0 +1,82 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// LSN is a position in the stream of changes, the equivalent of a Postgres
+// log sequence number.
+//
+// CockroachDB has no write-ahead log shared by all the tables, so the position
+// of a change is derived from its commit timestamp instead: the LSN of a change
+// is the wall time of its MVCC timestamp, in nanoseconds. All the changes
+// committed with the same wall time are streamed as a single transaction, and
+// a client which has processed every transaction up to some LSN can resume
+// streaming from it without missing any change.
+//
+// As a consequence, the transactions which are streamed are not the
+// transactions which were committed: the changes of unrelated transactions
+// which happen to commit with the same wall time, differing only by their
+// logical timestamp or not at all, are merged into a single transaction. The
+// rangefeed the changes are sourced from does not expose the ID of the
+// transaction which wrote them, and splitting the changes by logical
+// timestamp would give several transactions the same LSN, so that a client
+// resuming from one of them would skip the others. A transaction is never
+// split though: all of its changes are committed with the same timestamp.
+type LSN uint64
+
+// InvalidLSN is the zero LSN. Starting replication at it starts streaming the
+// changes committed from now on.
+const InvalidLSN LSN = 0
+
+// LSNFromTimestamp returns the LSN of the changes committed at the given
+// timestamp.
+func LSNFromTimestamp(ts hlc.Timestamp) LSN {
+	return LSN(ts.WallTime)
+}
+
+// String returns the LSN formatted the way Postgres formats them, e.g.
+// 16/B374D848.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// ResumeTimestamp returns the timestamp after which the changes following this
+// LSN were committed, i.e. the largest timestamp with this LSN.
+func (l LSN) ResumeTimestamp() hlc.Timestamp {
+	return hlc.Timestamp{WallTime: int64(l), Logical: math.MaxInt32}
+}
+
+// ParseLSN parses an LSN in the format produced by LSN.String.
+func ParseLSN(s string) (LSN, error) {
+	slash := strings.IndexByte(s, '/')
+	if slash < 0 {
+		return 0, pgerror.Newf(pgcode.Syntax, "invalid LSN %q", s)
+	}
+	hi, err := strconv.ParseUint(s[:slash], 16, 32)
+	if err != nil {
+		return 0, pgerror.Newf(pgcode.Syntax, "invalid LSN %q", s)
+	}
+	lo, err := strconv.ParseUint(s[slash+1:], 16, 32)
+	if err != nil {
+		return 0, pgerror.Newf(pgcode.Syntax, "invalid LSN %q", s)
+	}
+	return LSN(hi<<32 | lo), nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSN(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		lsn LSN
+		str string
+	}{
+		{0, "0/0"},
+		{0x16B374D848, "16/B374D848"},
+		{LSN(1658502462123456789), "17042F33/C1FAB915"},
+	} {
+		require.Equal(t, tc.str, tc.lsn.String())
+		parsed, err := ParseLSN(tc.str)
+		require.NoError(t, err)
+		require.Equal(t, tc.lsn, parsed)
+	}
+
+	// Postgres accepts lowercase hex digits.
+	parsed, err := ParseLSN("16/b374d848")
+	require.NoError(t, err)
+	require.Equal(t, LSN(0x16B374D848), parsed)
+
+	for _, s := range []string{"", "16", "16/", "/B374D848", "G/0", "100000000/0", "0/-1"} {
+		_, err := ParseLSN(s)
+		require.EqualError(t, err, `invalid LSN "`+s+`"`)
+	}
+}
+
+func TestLSNTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := hlc.Timestamp{WallTime: 1658502462123456789, Logical: 3}
+	lsn := LSNFromTimestamp(ts)
+	require.Equal(t, LSN(ts.WallTime), lsn)
+	// All the changes with the LSN are before the resume timestamp, and all the
+	// changes with a later LSN are after it.
+	require.True(t, ts.Less(lsn.ResumeTimestamp()))
+	require.True(t, lsn.ResumeTimestamp().Less(hlc.Timestamp{WallTime: ts.WallTime + 1}))
+	require.Equal(t, lsn, LSNFromTimestamp(lsn.ResumeTimestamp()))
+}
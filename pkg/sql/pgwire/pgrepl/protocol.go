@@ -0,0 +1,220 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+)
+
+// MessageType is the type of a message of the streaming replication protocol.
+// These messages are sent as the payload of CopyData messages.
+type MessageType byte
+
+// https://www.postgresql.org/docs/current/protocol-replication.html
+const (
+	// MsgXLogData carries a message of the pgoutput protocol.
+	MsgXLogData MessageType = 'w'
+	// MsgPrimaryKeepalive is sent by the server to report its position.
+	MsgPrimaryKeepalive MessageType = 'k'
+	// MsgStandbyStatusUpdate is sent by the client to report the position up
+	// to which it has processed the stream.
+	MsgStandbyStatusUpdate MessageType = 'r'
+	// MsgHotStandbyFeedback is only relevant to physical replication; it is
+	// ignored.
+	MsgHotStandbyFeedback MessageType = 'h'
+)
+
+// PgoutputMessageType is the type of a message of the pgoutput logical
+// replication protocol.
+type PgoutputMessageType byte
+
+// https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+const (
+	PgoutputBegin    PgoutputMessageType = 'B'
+	PgoutputCommit   PgoutputMessageType = 'C'
+	PgoutputRelation PgoutputMessageType = 'R'
+	PgoutputInsert   PgoutputMessageType = 'I'
+	PgoutputUpdate   PgoutputMessageType = 'U'
+	PgoutputDelete   PgoutputMessageType = 'D'
+)
+
+// The tags of the tuples of the pgoutput protocol, and of their columns.
+const (
+	TupleNew      = 'N'
+	TupleKey      = 'K'
+	TupleColNull  = 'n'
+	TupleColText  = 't'
+	ReplicaIdentD = 'd'
+	// ColumnFlagKey flags the columns of a Relation message which are part of
+	// the replica identity, i.e. the primary key.
+	ColumnFlagKey = 1
+)
+
+// postgresEpoch is the epoch of the timestamps of the replication protocol.
+var postgresEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ToPostgresTime returns the number of microseconds since the Postgres epoch,
+// which is how the replication protocol represents timestamps.
+func ToPostgresTime(t time.Time) int64 {
+	return t.Sub(postgresEpoch).Microseconds()
+}
+
+// FromPostgresTime is the inverse of ToPostgresTime.
+func FromPostgresTime(micros int64) time.Time {
+	return postgresEpoch.Add(time.Duration(micros) * time.Microsecond)
+}
+
+// StandbyStatusUpdate is the message by which the client reports its progress.
+type StandbyStatusUpdate struct {
+	// WritePosition, FlushPosition and ApplyPosition are the positions up to
+	// which the client has respectively received, durably stored and applied
+	// the changes.
+	WritePosition LSN
+	FlushPosition LSN
+	ApplyPosition LSN
+	ClientTime    time.Time
+	// ReplyRequested is set if the client wants the server to reply with a
+	// keepalive immediately.
+	ReplyRequested bool
+}
+
+// standbyStatusUpdateLen is the length of a StandbyStatusUpdate, excluding
+// its message type.
+const standbyStatusUpdateLen = 8 + 8 + 8 + 8 + 1
+
+// ParseStandbyStatusUpdate parses a StandbyStatusUpdate, excluding its
+// message type.
+func ParseStandbyStatusUpdate(b []byte) (StandbyStatusUpdate, error) {
+	if len(b) != standbyStatusUpdateLen {
+		return StandbyStatusUpdate{}, pgwirebase.NewProtocolViolationErrorf(
+			"invalid standby status update of length %d", len(b))
+	}
+	return StandbyStatusUpdate{
+		WritePosition:  LSN(binary.BigEndian.Uint64(b[0:])),
+		FlushPosition:  LSN(binary.BigEndian.Uint64(b[8:])),
+		ApplyPosition:  LSN(binary.BigEndian.Uint64(b[16:])),
+		ClientTime:     FromPostgresTime(int64(binary.BigEndian.Uint64(b[24:]))),
+		ReplyRequested: b[32] != 0,
+	}, nil
+}
+
+// PgoutputOptions are the options passed to the pgoutput plugin by
+// START_REPLICATION.
+type PgoutputOptions struct {
+	ProtoVersion int
+	// Publications are the names of the publications whose tables' changes
+	// are streamed.
+	Publications []string
+}
+
+// maxProtoVersion is the latest version of the pgoutput protocol that is
+// accepted. Versions after 1 only add messages for in-progress and two-phase
+// transactions, which are never streamed, so they are all handled the same.
+const maxProtoVersion = 4
+
+// ParsePgoutputOptions parses the options passed to the pgoutput plugin.
+func ParsePgoutputOptions(opts []Option) (PgoutputOptions, error) {
+	var res PgoutputOptions
+	seen := make(map[string]bool)
+	for _, o := range opts {
+		if seen[o.Name] {
+			return PgoutputOptions{}, pgerror.Newf(pgcode.Syntax, "conflicting or redundant options")
+		}
+		seen[o.Name] = true
+		switch o.Name {
+		case "proto_version":
+			v, err := strconv.Atoi(o.Value)
+			if err != nil || v < 1 {
+				return PgoutputOptions{}, pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid proto_version %q", o.Value)
+			}
+			if v > maxProtoVersion {
+				return PgoutputOptions{}, pgerror.Newf(pgcode.FeatureNotSupported,
+					"client sent proto_version=%d but server only supports protocol %d or lower",
+					v, maxProtoVersion)
+			}
+			res.ProtoVersion = v
+		case "publication_names":
+			names, err := splitIdentifiers(o.Value)
+			if err != nil {
+				return PgoutputOptions{}, err
+			}
+			res.Publications = names
+		case "binary":
+			if b, err := parseBoolOption(o); err != nil {
+				return PgoutputOptions{}, err
+			} else if b {
+				return PgoutputOptions{}, pgerror.New(pgcode.FeatureNotSupported,
+					"binary transfer of column values is not supported")
+			}
+		case "messages", "streaming", "two_phase", "origin":
+			// Logical decoding messages, in-progress transactions and prepared
+			// transactions are never streamed, and all changes originate
+			// locally, so these options make no difference.
+		default:
+			return PgoutputOptions{}, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unrecognized pgoutput option: %s", o.Name)
+		}
+	}
+	if res.ProtoVersion == 0 {
+		return PgoutputOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+			"proto_version option missing")
+	}
+	if !seen["publication_names"] {
+		return PgoutputOptions{}, pgerror.New(pgcode.InvalidParameterValue,
+			"publication_names parameter missing")
+	}
+	return res, nil
+}
+
+// parseBoolOption parses the value of a boolean option. An option without a
+// value is true.
+func parseBoolOption(o Option) (bool, error) {
+	if !o.HasValue {
+		return true, nil
+	}
+	switch strings.ToLower(o.Value) {
+	case "true", "on", "yes", "1":
+		return true, nil
+	case "false", "off", "no", "0":
+		return false, nil
+	}
+	return false, pgerror.Newf(pgcode.InvalidParameterValue,
+		"%s requires a Boolean value", o.Name)
+}
+
+// splitIdentifiers splits a comma-separated list of identifiers, which may be
+// double-quoted, the way the publication_names option is parsed by Postgres.
+func splitIdentifiers(s string) ([]string, error) {
+	sc := scanner{in: s}
+	var names []string
+	for {
+		t := sc.next()
+		if t.typ != tokIdent {
+			return nil, pgerror.New(pgcode.InvalidName, "invalid publication_names syntax")
+		}
+		names = append(names, t.val)
+		switch sc.next().typ {
+		case tokComma:
+		case tokEOF:
+			return names, nil
+		default:
+			return nil, pgerror.New(pgcode.InvalidName, "invalid publication_names syntax")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	require.Equal(t, int64(0), ToPostgresTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+	ts := time.Date(2022, 7, 22, 15, 4, 5, 123456000, time.UTC)
+	require.Equal(t, int64(711817445123456), ToPostgresTime(ts))
+	require.True(t, ts.Equal(FromPostgresTime(ToPostgresTime(ts))))
+}
+
+func TestParseStandbyStatusUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := time.Date(2022, 7, 22, 15, 4, 5, 123456000, time.UTC)
+	b := make([]byte, standbyStatusUpdateLen)
+	binary.BigEndian.PutUint64(b[0:], 3)
+	binary.BigEndian.PutUint64(b[8:], 2)
+	binary.BigEndian.PutUint64(b[16:], 1)
+	binary.BigEndian.PutUint64(b[24:], uint64(ToPostgresTime(ts)))
+	b[32] = 1
+	u, err := ParseStandbyStatusUpdate(b)
+	require.NoError(t, err)
+	require.Equal(t, LSN(3), u.WritePosition)
+	require.Equal(t, LSN(2), u.FlushPosition)
+	require.Equal(t, LSN(1), u.ApplyPosition)
+	require.True(t, ts.Equal(u.ClientTime))
+	require.True(t, u.ReplyRequested)
+
+	_, err = ParseStandbyStatusUpdate(b[:len(b)-1])
+	require.EqualError(t, err, "invalid standby status update of length 32")
+}
+
+func TestParsePgoutputOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	parse := func(t *testing.T, cmd string) (PgoutputOptions, error) {
+		stmt, ok, err := ParseCommand(cmd)
+		require.True(t, ok)
+		require.NoError(t, err)
+		return ParsePgoutputOptions(stmt.(*StartReplication).Options)
+	}
+	const prefix = `START_REPLICATION SLOT s LOGICAL 0/0 `
+
+	for _, tc := range []struct {
+		opts     string
+		expected PgoutputOptions
+	}{
+		{
+			`(proto_version '1', publication_names 'p')`,
+			PgoutputOptions{ProtoVersion: 1, Publications: []string{"p"}},
+		},
+		{
+			`(proto_version '2', publication_names ' a , "B c",D', binary 'false', messages 'true', streaming 'on')`,
+			PgoutputOptions{ProtoVersion: 2, Publications: []string{"a", "B c", "d"}},
+		},
+	} {
+		t.Run(tc.opts, func(t *testing.T) {
+			opts, err := parse(t, prefix+tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, opts)
+		})
+	}
+
+	for _, tc := range []struct {
+		opts string
+		err  string
+	}{
+		{``, `proto_version option missing`},
+		{`(publication_names 'p')`, `proto_version option missing`},
+		{`(proto_version '1')`, `publication_names parameter missing`},
+		{`(proto_version 'x', publication_names 'p')`, `invalid proto_version "x"`},
+		{`(proto_version '0', publication_names 'p')`, `invalid proto_version "0"`},
+		{
+			`(proto_version '5', publication_names 'p')`,
+			`client sent proto_version=5 but server only supports protocol 4 or lower`,
+		},
+		{`(proto_version '1', proto_version '1')`, `conflicting or redundant options`},
+		{`(proto_version '1', publication_names '')`, `invalid publication_names syntax`},
+		{`(proto_version '1', publication_names 'a,')`, `invalid publication_names syntax`},
+		{`(proto_version '1', publication_names 'a b')`, `invalid publication_names syntax`},
+		{
+			`(proto_version '1', publication_names 'p', binary)`,
+			`binary transfer of column values is not supported`,
+		},
+		{
+			`(proto_version '1', publication_names 'p', binary 'maybe')`,
+			`binary requires a Boolean value`,
+		},
+		{`(proto_version '1', publication_names 'p', foo 'bar')`, `unrecognized pgoutput option: foo`},
+	} {
+		t.Run(tc.opts, func(t *testing.T) {
+			_, err := parse(t, prefix+tc.opts)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -368,6 +368,18 @@ func DecodeDatum(
 				return nil, tree.MakeParseError(string(b), typ, err)
 			}
 			return d, nil
+		case oid.T_tsquery:
+			d, err := tree.ParseDTSQuery(string(b))
+			if err != nil {
+				return nil, tree.MakeParseError(string(b), typ, err)
+			}
+			return d, nil
+		case oid.T_tsvector:
+			d, err := tree.ParseDTSVector(string(b))
+			if err != nil {
+				return nil, tree.MakeParseError(string(b), typ, err)
+			}
+			return d, nil
 		case oidext.T_geography:
 			d, err := tree.ParseDGeography(string(b))
 			if err != nil {
@@ -44,11 +44,15 @@ const (
 	ServerMsgCommandComplete      ServerMessageType = 'C'
 	ServerMsgCloseComplete        ServerMessageType = '3'
 	ServerMsgCopyInResponse       ServerMessageType = 'G'
+	ServerMsgCopyBothResponse     ServerMessageType = 'W'
+	ServerMsgCopyData             ServerMessageType = 'd'
+	ServerMsgCopyDone             ServerMessageType = 'c'
 	ServerMsgDataRow              ServerMessageType = 'D'
 	ServerMsgEmptyQuery           ServerMessageType = 'I'
 	ServerMsgErrorResponse        ServerMessageType = 'E'
 	ServerMsgNoticeResponse       ServerMessageType = 'N'
 	ServerMsgNoData               ServerMessageType = 'n'
+	ServerMsgNotificationResponse ServerMessageType = 'A'
 	ServerMsgParameterDescription ServerMessageType = 't'
 	ServerMsgParameterStatus      ServerMessageType = 'S'
 	ServerMsgParseComplete        ServerMessageType = '1'
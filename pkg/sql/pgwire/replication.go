@@ -0,0 +1,372 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+var replicationKeepaliveInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.replication.keepalive_interval",
+	"the interval at which keepalive messages reporting the current position are "+
+		"sent to logical replication clients",
+	10*time.Second,
+	settings.PositiveDuration,
+)
+
+var replicationSenderTimeout = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.replication.sender_timeout",
+	"the duration after which a logical replication connection is terminated if the "+
+		"client has not sent any message; 0 disables the timeout",
+	time.Minute,
+	settings.NonNegativeDuration,
+)
+
+// replicationClientMsg is a message received from the client while streaming
+// replication.
+type replicationClientMsg struct {
+	// status is set for standby status updates.
+	status *pgrepl.StandbyStatusUpdate
+	// done is set when the client ends the stream.
+	done bool
+	err  error
+}
+
+// StreamReplication is part of the pgrepl.Conn interface.
+//
+// The transactions of the feed are streamed in CopyData messages, following
+// the streaming replication protocol. The client reports its progress in
+// CopyData messages as well, until it ends the stream with a CopyDone message.
+// If the stream fails, the connection is closed after the error is sent, like
+// Postgres does.
+func (c *conn) StreamReplication(
+	ctx context.Context,
+	startLSN pgrepl.LSN,
+	feed pgrepl.Feed,
+	conv sessiondatapb.DataConversionConfig,
+	loc *time.Location,
+) error {
+	defer feed.Close()
+
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyBothResponse)
+	c.msgBuilder.writeByte(byte(pgwirebase.FormatText))
+	c.msgBuilder.putInt16(0)
+	if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	clientMsgs := make(chan replicationClientMsg)
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		c.readReplicationMsgs(ctx, clientMsgs)
+	}()
+	// abort terminates the stream and the connection when an error occurs.
+	abort := func(err error) error {
+		if writeErr := writeErr(ctx, c.sv, err, &c.msgBuilder, c.conn); writeErr != nil {
+			log.VEventf(ctx, 2, "failed to send replication error: %v", writeErr)
+		}
+		cancel()
+		_ = c.conn.Close()
+		<-readerDone
+		return err
+	}
+
+	keepaliveInterval := replicationKeepaliveInterval.Get(c.sv)
+	timeout := replicationSenderTimeout.Get(c.sv)
+	tick := keepaliveInterval
+	if timeout > 0 && timeout/4 < tick {
+		tick = timeout / 4
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var out bytes.Buffer
+	w := &c.msgBuilder
+	// sentLSN is the position up to which all the transactions were sent.
+	sentLSN := startLSN
+	sentRelations := make(map[oid.Oid]uint64)
+	lastReceived := timeutil.Now()
+	lastKeepalive := timeutil.Now()
+	replyRequested := false
+	sendKeepalive := func(requestReply bool) error {
+		out.Reset()
+		if err := writeKeepalive(w, &out, sentLSN, requestReply); err != nil {
+			return err
+		}
+		lastKeepalive = timeutil.Now()
+		_, err := c.conn.Write(out.Bytes())
+		return err
+	}
+
+	for {
+		select {
+		case m := <-clientMsgs:
+			lastReceived = timeutil.Now()
+			replyRequested = false
+			if m.err != nil {
+				return abort(m.err)
+			}
+			if m.done {
+				// The client ended the stream: acknowledge it. The connection
+				// goes back to accepting commands.
+				w.initMsg(pgwirebase.ServerMsgCopyDone)
+				if err := w.finishMsg(c.conn); err != nil {
+					return abort(err)
+				}
+				cancel()
+				<-readerDone
+				return c.SendCommandComplete([]byte("START_REPLICATION"))
+			}
+			if m.status != nil && m.status.ReplyRequested {
+				if err := sendKeepalive(false /* requestReply */); err != nil {
+					return abort(err)
+				}
+			}
+
+		case ev := <-feed.Events():
+			if ev.Err != nil {
+				return abort(ev.Err)
+			}
+			if ev.Txn == nil {
+				if ev.Checkpoint > sentLSN {
+					sentLSN = ev.Checkpoint
+				}
+				continue
+			}
+			out.Reset()
+			if err := writeTransaction(ctx, w, &out, ev.Txn, sentRelations, conv, loc); err != nil {
+				return abort(err)
+			}
+			if _, err := c.conn.Write(out.Bytes()); err != nil {
+				return abort(err)
+			}
+			sentLSN = ev.Txn.LSN
+
+		case <-ticker.C:
+			now := timeutil.Now()
+			if timeout > 0 && now.Sub(lastReceived) > timeout {
+				return abort(pgerror.New(pgcode.AdminShutdown,
+					"terminating replication connection due to timeout"))
+			}
+			// Like Postgres, ask the client for a reply once half of the timeout
+			// has elapsed without hearing from it.
+			requestReply := timeout > 0 && !replyRequested && now.Sub(lastReceived) > timeout/2
+			if requestReply || now.Sub(lastKeepalive) >= keepaliveInterval {
+				if err := sendKeepalive(requestReply); err != nil {
+					return abort(err)
+				}
+				replyRequested = replyRequested || requestReply
+			}
+
+		case <-ctx.Done():
+			return abort(ctx.Err())
+		}
+	}
+}
+
+// readReplicationMsgs reads the messages sent by the client while streaming
+// replication, until the client ends the stream or an error occurs.
+func (c *conn) readReplicationMsgs(ctx context.Context, msgs chan<- replicationClientMsg) {
+	readBuf := pgwirebase.MakeReadBuffer(pgwirebase.ReadBufferOptionWithClusterSettings(c.sv))
+	for {
+		var m replicationClientMsg
+		typ, _, err := readBuf.ReadTypedMsg(c.Rd())
+		switch {
+		case err != nil:
+			m.err = err
+		case typ == pgwirebase.ClientMsgCopyData:
+			if len(readBuf.Msg) == 0 {
+				m.err = pgwirebase.NewProtocolViolationErrorf("empty CopyData message")
+				break
+			}
+			switch pgrepl.MessageType(readBuf.Msg[0]) {
+			case pgrepl.MsgStandbyStatusUpdate:
+				status, err := pgrepl.ParseStandbyStatusUpdate(readBuf.Msg[1:])
+				m.status, m.err = &status, err
+			case pgrepl.MsgHotStandbyFeedback:
+			default:
+				m.err = pgwirebase.NewProtocolViolationErrorf(
+					"unexpected message type %q in CopyData", readBuf.Msg[0])
+			}
+		case typ == pgwirebase.ClientMsgCopyDone:
+			m.done = true
+		case typ == pgwirebase.ClientMsgTerminate:
+			m.err = errors.Wrap(io.EOF, "client terminated the connection")
+		case typ == pgwirebase.ClientMsgFlush || typ == pgwirebase.ClientMsgSync:
+			continue
+		default:
+			m.err = pgwirebase.NewUnrecognizedMsgTypeErr(typ)
+		}
+		select {
+		case msgs <- m:
+		case <-ctx.Done():
+			return
+		}
+		if m.err != nil || m.done {
+			return
+		}
+	}
+}
+
+// writeKeepalive writes a primary keepalive message reporting the position up
+// to which all the changes were sent.
+func writeKeepalive(w *writeBuffer, out io.Writer, lsn pgrepl.LSN, requestReply bool) error {
+	w.initMsg(pgwirebase.ServerMsgCopyData)
+	w.writeByte(byte(pgrepl.MsgPrimaryKeepalive))
+	w.putInt64(int64(lsn))
+	w.putInt64(pgrepl.ToPostgresTime(timeutil.Now()))
+	if requestReply {
+		w.writeByte(1)
+	} else {
+		w.writeByte(0)
+	}
+	return w.finishMsg(out)
+}
+
+// writeTransaction writes the pgoutput messages of a transaction, each in its
+// own XLogData message. A Relation message is written before the first change
+// of every version of a table which hasn't been sent yet.
+func writeTransaction(
+	ctx context.Context,
+	w *writeBuffer,
+	out io.Writer,
+	txn *pgrepl.Transaction,
+	sentRelations map[oid.Oid]uint64,
+	conv sessiondatapb.DataConversionConfig,
+	loc *time.Location,
+) error {
+	commitTime := pgrepl.ToPostgresTime(txn.CommitTime)
+
+	writeXLogDataHeader(w, txn.LSN)
+	w.writeByte(byte(pgrepl.PgoutputBegin))
+	w.putInt64(int64(txn.LSN))
+	w.putInt64(commitTime)
+	// There are no transaction IDs; derive one from the LSN, which identifies
+	// the transaction.
+	w.putInt32(int32(txn.LSN))
+	if err := w.finishMsg(out); err != nil {
+		return err
+	}
+
+	for i := range txn.Changes {
+		ch := &txn.Changes[i]
+		rel := ch.Relation
+		if v, ok := sentRelations[rel.ID]; !ok || v != rel.Version {
+			writeXLogDataHeader(w, txn.LSN)
+			writeRelation(w, rel)
+			if err := w.finishMsg(out); err != nil {
+				return err
+			}
+			sentRelations[rel.ID] = rel.Version
+		}
+		writeXLogDataHeader(w, txn.LSN)
+		writeChange(ctx, w, ch, conv, loc)
+		if err := w.finishMsg(out); err != nil {
+			return err
+		}
+	}
+
+	writeXLogDataHeader(w, txn.LSN)
+	w.writeByte(byte(pgrepl.PgoutputCommit))
+	w.writeByte(0) // flags
+	w.putInt64(int64(txn.LSN))
+	w.putInt64(int64(txn.LSN))
+	w.putInt64(commitTime)
+	return w.finishMsg(out)
+}
+
+// writeXLogDataHeader begins a CopyData message carrying an XLogData message
+// for a change of the transaction at the given LSN.
+func writeXLogDataHeader(w *writeBuffer, lsn pgrepl.LSN) {
+	w.initMsg(pgwirebase.ServerMsgCopyData)
+	w.writeByte(byte(pgrepl.MsgXLogData))
+	w.putInt64(int64(lsn))
+	w.putInt64(int64(lsn))
+	w.putInt64(pgrepl.ToPostgresTime(timeutil.Now()))
+}
+
+// writeRelation writes a pgoutput Relation message.
+func writeRelation(w *writeBuffer, rel *pgrepl.Relation) {
+	w.writeByte(byte(pgrepl.PgoutputRelation))
+	w.putInt32(int32(rel.ID))
+	w.writeTerminatedString(rel.Namespace)
+	w.writeTerminatedString(rel.Name)
+	w.writeByte(pgrepl.ReplicaIdentD)
+	w.putInt16(int16(len(rel.Columns)))
+	for _, col := range rel.Columns {
+		if col.Key {
+			w.writeByte(pgrepl.ColumnFlagKey)
+		} else {
+			w.writeByte(0)
+		}
+		w.writeTerminatedString(col.Name)
+		w.putInt32(int32(col.Type.Oid()))
+		w.putInt32(col.Type.TypeModifier())
+	}
+}
+
+// writeChange writes a pgoutput Insert, Update or Delete message. Updates
+// only carry the new row, and deletes only carry the values of the primary
+// key of the deleted row, as is the case for tables with the default replica
+// identity in Postgres.
+func writeChange(
+	ctx context.Context,
+	w *writeBuffer,
+	ch *pgrepl.Change,
+	conv sessiondatapb.DataConversionConfig,
+	loc *time.Location,
+) {
+	switch ch.Type {
+	case pgrepl.Insert:
+		w.writeByte(byte(pgrepl.PgoutputInsert))
+	case pgrepl.Update:
+		w.writeByte(byte(pgrepl.PgoutputUpdate))
+	case pgrepl.Delete:
+		w.writeByte(byte(pgrepl.PgoutputDelete))
+	}
+	w.putInt32(int32(ch.Relation.ID))
+	keyOnly := ch.Type == pgrepl.Delete
+	if keyOnly {
+		w.writeByte(pgrepl.TupleKey)
+	} else {
+		w.writeByte(pgrepl.TupleNew)
+	}
+	w.putInt16(int16(len(ch.Row)))
+	for i, d := range ch.Row {
+		col := &ch.Relation.Columns[i]
+		if d == tree.DNull || (keyOnly && !col.Key) {
+			w.writeByte(pgrepl.TupleColNull)
+			continue
+		}
+		w.writeByte(pgrepl.TupleColText)
+		w.writeTextDatum(ctx, d, conv, loc, col.Type)
+	}
+}
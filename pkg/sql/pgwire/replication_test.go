@@ -0,0 +1,256 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/lib/pq/oid"
+	"github.com/stretchr/testify/require"
+)
+
+// pgoutputMsg is a decoded XLogData message.
+type pgoutputMsg struct {
+	lsn pgrepl.LSN
+	typ pgrepl.PgoutputMessageType
+	// body is the pgoutput message without its type.
+	body []byte
+}
+
+// decodeXLogData decodes the payload of a CopyData message carrying an
+// XLogData message.
+func decodeXLogData(t *testing.T, data []byte) pgoutputMsg {
+	t.Helper()
+	require.Equal(t, byte(pgrepl.MsgXLogData), data[0])
+	require.Greater(t, len(data), 25)
+	return pgoutputMsg{
+		lsn:  pgrepl.LSN(binary.BigEndian.Uint64(data[1:])),
+		typ:  pgrepl.PgoutputMessageType(data[25]),
+		body: data[26:],
+	}
+}
+
+// decodeCopyDataMsgs decodes the XLogData messages written in CopyData
+// messages to buf.
+func decodeCopyDataMsgs(t *testing.T, buf []byte) []pgoutputMsg {
+	t.Helper()
+	var msgs []pgoutputMsg
+	for len(buf) > 0 {
+		require.Equal(t, byte(pgwirebase.ServerMsgCopyData), buf[0])
+		n := int(binary.BigEndian.Uint32(buf[1:]))
+		msgs = append(msgs, decodeXLogData(t, buf[5:1+n]))
+		buf = buf[1+n:]
+	}
+	return msgs
+}
+
+func TestWriteReplicationTransaction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	rel := &pgrepl.Relation{
+		ID:        104,
+		Version:   1,
+		Namespace: "public",
+		Name:      "kv",
+		Columns: []pgrepl.Column{
+			{Name: "k", Type: types.Int, Key: true},
+			{Name: "v", Type: types.String},
+		},
+	}
+	row := func(k int, v string) tree.Datums {
+		return tree.Datums{tree.NewDInt(tree.DInt(k)), tree.NewDString(v)}
+	}
+	txn := &pgrepl.Transaction{
+		LSN:        pgrepl.LSN(1000),
+		CommitTime: timeutil.Unix(0, 1000),
+		Changes: []pgrepl.Change{
+			{Type: pgrepl.Insert, Relation: rel, Row: row(1, "a")},
+			{Type: pgrepl.Update, Relation: rel, Row: row(1, "b")},
+			{Type: pgrepl.Delete, Relation: rel, Row: row(1, "b")},
+		},
+	}
+
+	w := newWriteBuffer(metric.NewCounter(metric.Metadata{}))
+	sentRelations := make(map[oid.Oid]uint64)
+	write := func(txn *pgrepl.Transaction) []pgoutputMsg {
+		var out bytes.Buffer
+		require.NoError(t, writeTransaction(
+			ctx, w, &out, txn, sentRelations, sessiondatapb.DataConversionConfig{}, time.UTC,
+		))
+		return decodeCopyDataMsgs(t, out.Bytes())
+	}
+	typs := func(msgs []pgoutputMsg) string {
+		var b bytes.Buffer
+		for _, m := range msgs {
+			require.Equal(t, txn.LSN, m.lsn)
+			b.WriteByte(byte(m.typ))
+		}
+		return b.String()
+	}
+
+	msgs := write(txn)
+	// The relation is described before its first change.
+	require.Equal(t, "BRIUDC", typs(msgs))
+	require.Equal(t, uint64(txn.LSN), binary.BigEndian.Uint64(msgs[0].body))
+	require.Equal(t, append([]byte{0, 0, 0, 104}, "public\x00kv\x00"...), msgs[1].body[:14])
+
+	// The insert carries the new row.
+	require.Equal(t,
+		[]byte("\x00\x00\x00\x68N\x00\x02t\x00\x00\x00\x011t\x00\x00\x00\x01a"),
+		msgs[2].body,
+	)
+	// The delete only carries the primary key.
+	require.Equal(t,
+		[]byte("\x00\x00\x00\x68K\x00\x02t\x00\x00\x00\x011n"),
+		msgs[4].body,
+	)
+
+	// The relation is not described again until its version changes.
+	require.Equal(t, "BIUDC", typs(write(txn)))
+	rel.Version++
+	require.Equal(t, "BRIUDC", typs(write(txn)))
+}
+
+func TestReplicationProtocol(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, "SET CLUSTER SETTING kv.rangefeed.enabled = true")
+	r.Exec(t, "SET CLUSTER SETTING sql.replication.keepalive_interval = '10ms'")
+	r.Exec(t, "CREATE TABLE kv (k INT PRIMARY KEY, v STRING)")
+	r.Exec(t, "CREATE TABLE other (k INT PRIMARY KEY)")
+	r.Exec(t, "CREATE PUBLICATION pub FOR TABLE kv WITH (publish = 'insert, delete')")
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(username.RootUser))
+	defer cleanup()
+	pgURL.Path = "defaultdb"
+	q := pgURL.Query()
+	q.Set("replication", "database")
+	pgURL.RawQuery = q.Encode()
+	conn, err := pgconn.Connect(ctx, pgURL.String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close(ctx) }()
+
+	results, err := conn.Exec(ctx, "IDENTIFY_SYSTEM").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results[0].Rows, 1)
+	require.Equal(t, "defaultdb", string(results[0].Rows[0][3]))
+
+	results, err = conn.Exec(ctx, "CREATE_REPLICATION_SLOT slot LOGICAL pgoutput").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results[0].Rows, 1)
+	consistentPoint, err := pgrepl.ParseLSN(string(results[0].Rows[0][1]))
+	require.NoError(t, err)
+
+	// Regular statements are accepted on replication connections.
+	results, err = conn.Exec(ctx, "SELECT 1").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "1", string(results[0].Rows[0][0]))
+
+	// Errors which occur before streaming starts don't close the connection.
+	_, err = conn.Exec(ctx, fmt.Sprintf(
+		"START_REPLICATION SLOT slot LOGICAL %s (proto_version '1', publication_names 'missing')",
+		consistentPoint,
+	)).ReadAll()
+	require.Regexp(t, `publication "missing" does not exist`, err)
+
+	r.Exec(t, "INSERT INTO kv VALUES (1, 'a')")
+	r.Exec(t, "UPDATE kv SET v = 'b' WHERE k = 1")
+	r.Exec(t, "INSERT INTO other VALUES (1)")
+	r.Exec(t, "DELETE FROM kv WHERE k = 1")
+
+	require.NoError(t, conn.SendBytes(ctx, (&pgproto3.Query{String: fmt.Sprintf(
+		"START_REPLICATION SLOT slot LOGICAL %s (proto_version '1', publication_names 'pub')",
+		consistentPoint,
+	)}).Encode(nil)))
+	msg, err := conn.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	require.IsType(t, &pgproto3.CopyBothResponse{}, msg)
+
+	// The update is not published. Keepalives may be interleaved with the
+	// transactions.
+	var typs []byte
+	var lastLSN pgrepl.LSN
+	keepalives := 0
+	for len(typs) < 7 || keepalives == 0 {
+		msg, err := conn.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		data := msg.(*pgproto3.CopyData).Data
+		switch pgrepl.MessageType(data[0]) {
+		case pgrepl.MsgPrimaryKeepalive:
+			keepalives++
+		case pgrepl.MsgXLogData:
+			m := decodeXLogData(t, data)
+			require.LessOrEqual(t, uint64(lastLSN), uint64(m.lsn))
+			lastLSN = m.lsn
+			typs = append(typs, byte(m.typ))
+		default:
+			t.Fatalf("unexpected message type %q", data[0])
+		}
+	}
+	require.Equal(t, "BRICBDC", string(typs))
+
+	// Report the progress of the client, and end the stream.
+	status := make([]byte, 34)
+	status[0] = byte(pgrepl.MsgStandbyStatusUpdate)
+	for i := 0; i < 3; i++ {
+		binary.BigEndian.PutUint64(status[1+8*i:], uint64(lastLSN))
+	}
+	require.NoError(t, conn.SendBytes(ctx, (&pgproto3.CopyData{Data: status}).Encode(nil)))
+	require.NoError(t, conn.SendBytes(ctx, (&pgproto3.CopyDone{}).Encode(nil)))
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		if _, ok := msg.(*pgproto3.CopyDone); ok {
+			break
+		}
+		require.IsType(t, &pgproto3.CopyData{}, msg)
+	}
+	msg, err = conn.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &pgproto3.CommandComplete{CommandTag: []byte("START_REPLICATION")}, msg)
+	msg, err = conn.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	require.IsType(t, &pgproto3.ReadyForQuery{}, msg)
+
+	// The connection can be used again once the stream has ended.
+	results, err = conn.Exec(ctx, "SELECT count(*) FROM kv").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "0", string(results[0].Rows[0][0]))
+}
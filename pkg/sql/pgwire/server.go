@@ -1039,6 +1039,19 @@ func parseClientProvidedSessionParameters(
 			}
 			args.RemoteAddr = &net.TCPAddr{IP: ip, Port: port}
 
+		case "replication":
+			switch strings.ToLower(value) {
+			case "database":
+				args.Replication = true
+			case "true", "on", "yes", "1":
+				return sql.SessionArgs{}, pgerror.New(pgcode.FeatureNotSupported,
+					"physical replication is not supported")
+			case "false", "off", "no", "0":
+			default:
+				return sql.SessionArgs{}, pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid value for parameter \"replication\": %q", value)
+			}
+
 		case "options":
 			opts, err := parseOptions(value)
 			if err != nil {
@@ -239,6 +239,12 @@ func writeTextDatumNotNull(
 	case *tree.DJSON:
 		b.writeLengthPrefixedString(v.JSON.String())
 
+	case *tree.DTSQuery:
+		b.writeLengthPrefixedString(v.TSQuery.String())
+
+	case *tree.DTSVector:
+		b.writeLengthPrefixedString(v.TSVector.String())
+
 	case *tree.DTuple:
 		b.textFormatter.FormatNode(v)
 		b.writeFromFmtCtx(b.textFormatter)
@@ -746,6 +752,10 @@ func writeBinaryDatumNotNull(
 	case *tree.DJSON:
 		writeBinaryJSON(b, v.JSON, t)
 
+	case *tree.DTSQuery, *tree.DTSVector:
+		b.setError(unimplemented.Newf("binenc",
+			"unsupported binary serialization of %s", t.SQLString()))
+
 	case *tree.DOid:
 		b.putInt32(4)
 		b.putInt32(int32(v.Oid))
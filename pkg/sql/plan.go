@@ -145,6 +145,7 @@ type planNodeReadingOwnWrites interface {
 	ReadingOwnWrites()
 }
 
+var _ planNode = &alterDomainNode{}
 var _ planNode = &alterIndexNode{}
 var _ planNode = &alterIndexVisibleNode{}
 var _ planNode = &alterSchemaNode{}
@@ -153,6 +154,7 @@ var _ planNode = &alterTableNode{}
 var _ planNode = &alterTableOwnerNode{}
 var _ planNode = &alterTableSetSchemaNode{}
 var _ planNode = &alterTypeNode{}
+var _ planNode = &backupScanNode{}
 var _ planNode = &bufferNode{}
 var _ planNode = &cancelQueriesNode{}
 var _ planNode = &cancelSessionsNode{}
@@ -234,6 +236,7 @@ var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
 var _ planNodeFastPath = &controlSchedulesNode{}
 
+var _ planNodeReadingOwnWrites = &alterDomainNode{}
 var _ planNodeReadingOwnWrites = &alterIndexNode{}
 var _ planNodeReadingOwnWrites = &alterSchemaNode{}
 var _ planNodeReadingOwnWrites = &alterSequenceNode{}
@@ -46,6 +46,8 @@ func getPlanColumns(plan planNode, mut bool) colinfo.ResultColumns {
 	switch n := plan.(type) {
 
 	// Nodes that define their own schema.
+	case *backupScanNode:
+		return n.columns
 	case *delayedNode:
 		return n.columns
 	case *groupNode:
@@ -560,9 +560,13 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld.ProcedureSegment = p.procSegment
+	bld.ProcedureArgs = p.procArgs
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
+	p.procArgs = bld.ProcedureArgs
+	p.procTxnControl = bld.ProcedureTxnControl
 
 	// For index recommendations, after building we must interrupt the flow to
 	// find potential index candidates in the memo.
@@ -651,7 +655,9 @@ func (opc *optPlanningCtx) runExecBuilder(
 		planTop.instrumentation.outputRows = mem.RootExpr().(memo.RelExpr).Relational().Statistics().RowCount
 	}
 
-	if stmt.ExpectedTypes != nil {
+	// The result of a segment of a procedure that is not the last one is void,
+	// and is not returned to the client.
+	if stmt.ExpectedTypes != nil && opc.p.procTxnControl == nil {
 		cols := result.main.planColumns()
 		if !stmt.ExpectedTypes.TypesEqual(cols) {
 			return pgerror.New(pgcode.FeatureNotSupported, "cached plan must not change result type")
@@ -109,6 +109,14 @@ type extendedEvalContext struct {
 	SchemaChangerState *SchemaChangerState
 
 	statementPreparer statementPreparer
+
+	// Notifications gives access to the LISTEN/NOTIFY state of the session. It
+	// is nil for planners which are not bound to a session.
+	Notifications notificationsAccessor
+
+	// DeferredConstraints gives access to the deferred constraint checks of the
+	// transaction. It is nil for planners which are not bound to a session.
+	DeferredConstraints deferredConstraintsAccessor
 }
 
 // copyFromExecCfg copies relevant fields from an ExecutorConfig.
@@ -211,6 +219,21 @@ type planner struct {
 	// auto-commit. This is dependent on information from the optimizer.
 	autoCommit bool
 
+	// procSegment is the index of the segment of the procedure invoked by a
+	// CALL statement that should be planned. The segments of a procedure are
+	// separated by COMMIT and ROLLBACK statements.
+	procSegment int
+
+	// procArgs holds the values of the arguments of the CALL statement, which
+	// are evaluated by the optimizer when the first segment of a procedure with
+	// COMMIT or ROLLBACK statements is planned.
+	procArgs tree.Datums
+
+	// procTxnControl is set by the optimizer to the COMMIT or ROLLBACK statement
+	// that ends the planned segment of a procedure. It is nil if the statement
+	// is not a CALL statement or if the planned segment is the last one.
+	procTxnControl tree.Statement
+
 	// cancelChecker is used by planNodes to check for cancellation of the associated
 	// query.
 	cancelChecker cancelchecker.CancelChecker
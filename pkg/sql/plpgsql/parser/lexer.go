@@ -0,0 +1,256 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	// tokIdent is an unquoted identifier or keyword. Its str is lower-cased.
+	tokIdent
+	// tokQuotedIdent is a double-quoted identifier. Its str is unquoted.
+	tokQuotedIdent
+	// tokString is a string constant. Its value is obtained by parsing its text
+	// as a SQL expression.
+	tokString
+	tokNumber
+	// tokOp is an operator or punctuation.
+	tokOp
+)
+
+// token is a lexical token of a PL/pgSQL body. The lexer only needs to
+// recognize enough of the SQL lexical structure to find the boundaries of the
+// SQL expressions and statements embedded in the body, which are then parsed by
+// the SQL parser.
+type token struct {
+	kind tokenKind
+	str  string
+	// start and end are the byte offsets of the token in the body.
+	start, end int
+}
+
+// isOpChar returns true for the characters that can form multi-character
+// operators.
+func isOpChar(c byte) bool {
+	return strings.IndexByte("+-*/<>=~!@#%^&|?", c) >= 0
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lex splits the body into tokens. The last token is always a tokEOF.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for {
+		// Skip whitespace and comments.
+		for i < len(src) {
+			c := src[i]
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' {
+				i++
+			} else if strings.HasPrefix(src[i:], "--") {
+				for i < len(src) && src[i] != '\n' {
+					i++
+				}
+			} else if strings.HasPrefix(src[i:], "/*") {
+				depth := 0
+				for i < len(src) {
+					if strings.HasPrefix(src[i:], "/*") {
+						depth++
+						i += 2
+					} else if strings.HasPrefix(src[i:], "*/") {
+						depth--
+						i += 2
+						if depth == 0 {
+							break
+						}
+					} else {
+						i++
+					}
+				}
+				if depth > 0 {
+					return nil, pgerror.New(pgcode.Syntax, "unterminated comment")
+				}
+			} else {
+				break
+			}
+		}
+		if i >= len(src) {
+			toks = append(toks, token{kind: tokEOF, start: i, end: i})
+			return toks, nil
+		}
+
+		start := i
+		c := src[i]
+		switch {
+		case (c == 'e' || c == 'E') && i+1 < len(src) && src[i+1] == '\'':
+			// An escape string, in which a backslash escapes the next character.
+			end, ok := scanString(src, i+1, true /* escapes */)
+			if !ok {
+				return nil, pgerror.New(pgcode.Syntax, "unterminated string")
+			}
+			toks = append(toks, token{kind: tokString, str: src[start:end], start: start, end: end})
+			i = end
+
+		case isIdentStart(c):
+			for i < len(src) && isIdentChar(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokIdent, str: strings.ToLower(src[start:i]), start: start, end: i})
+
+		case c == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, pgerror.New(pgcode.Syntax, "unterminated quoted identifier")
+				}
+				if src[i] == '"' {
+					if i+1 < len(src) && src[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(src[i])
+				i++
+			}
+			toks = append(toks, token{kind: tokQuotedIdent, str: b.String(), start: start, end: i})
+
+		case c == '\'':
+			end, ok := scanString(src, i, false /* escapes */)
+			if !ok {
+				return nil, pgerror.New(pgcode.Syntax, "unterminated string")
+			}
+			toks = append(toks, token{kind: tokString, str: src[start:end], start: start, end: end})
+			i = end
+
+		case c == '$':
+			// Either a dollar-quoted string or a placeholder, e.g. $1.
+			j := i + 1
+			for j < len(src) && isIdentChar(src[j]) && src[j] != '$' {
+				j++
+			}
+			if j < len(src) && src[j] == '$' && (j == i+1 || !isDigit(src[i+1])) {
+				tag := src[i : j+1]
+				end := strings.Index(src[j+1:], tag)
+				if end < 0 {
+					return nil, pgerror.New(pgcode.Syntax, "unterminated dollar-quoted string")
+				}
+				i = j + 1 + end + len(tag)
+				toks = append(toks, token{kind: tokString, str: src[start:i], start: start, end: i})
+			} else {
+				i = j
+				toks = append(toks, token{kind: tokIdent, str: src[start:i], start: start, end: i})
+			}
+
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			// A period followed by another period is the range operator of an
+			// integer FOR loop, e.g. 1..10.
+			if i+1 < len(src) && src[i] == '.' && src[i+1] != '.' {
+				i++
+				for i < len(src) && isDigit(src[i]) {
+					i++
+				}
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && isDigit(src[j]) {
+					i = j
+					for i < len(src) && isDigit(src[i]) {
+						i++
+					}
+				}
+			}
+			toks = append(toks, token{kind: tokNumber, str: src[start:i], start: start, end: i})
+
+		case c == ':' || c == '.':
+			i++
+			if i < len(src) && ((c == ':' && (src[i] == '=' || src[i] == ':')) || (c == '.' && src[i] == '.')) {
+				i++
+			}
+			toks = append(toks, token{kind: tokOp, str: src[start:i], start: start, end: i})
+
+		case isOpChar(c):
+			for i < len(src) && isOpChar(src[i]) {
+				if i > start && (strings.HasPrefix(src[i:], "--") || strings.HasPrefix(src[i:], "/*")) {
+					break
+				}
+				i++
+			}
+			toks = append(toks, token{kind: tokOp, str: src[start:i], start: start, end: i})
+
+		default:
+			i++
+			toks = append(toks, token{kind: tokOp, str: src[start:i], start: start, end: i})
+		}
+	}
+}
+
+// scanString scans the single-quoted string starting at src[start] and returns
+// the offset following it. Consecutive quoted strings separated by whitespace
+// that includes a newline are a single string, as in SQL.
+func scanString(src string, start int, escapes bool) (end int, ok bool) {
+	i := start + 1
+	for {
+		if i >= len(src) {
+			return 0, false
+		}
+		switch src[i] {
+		case '\\':
+			if escapes {
+				i++
+			}
+		case '\'':
+			if i+1 < len(src) && src[i+1] == '\'' {
+				i++
+				break
+			}
+			// Look for a continuation of the string on a later line.
+			j := i + 1
+			newline := false
+			for j < len(src) && strings.IndexByte(" \t\n\r\f", src[j]) >= 0 {
+				newline = newline || src[j] == '\n'
+				j++
+			}
+			if newline && j < len(src) && src[j] == '\'' {
+				i = j
+				break
+			}
+			return i + 1, true
+		}
+		i++
+	}
+}
@@ -0,0 +1,798 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package parser parses the bodies of PL/pgSQL functions.
+//
+// The parser is a hand-written recursive descent parser for the PL/pgSQL
+// statements. It finds the boundaries of the SQL expressions, queries and type
+// names embedded in the statements and hands their text to the SQL parser, in
+// the same way as the PL/pgSQL parser of Postgres.
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	sqlparser "github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// Parse parses the body of a PL/pgSQL function.
+func Parse(body string) (block *plpgsqltree.Block, err error) {
+	toks, err := lex(body)
+	if err != nil {
+		return nil, err
+	}
+	p := plpgsqlParser{src: body, toks: toks}
+	defer func() {
+		if r := recover(); r != nil {
+			if pe, ok := r.(parseError); ok {
+				block, err = nil, pe.error
+				return
+			}
+			panic(r)
+		}
+	}()
+	return p.parseBody(), nil
+}
+
+// parseError is used to propagate errors through the recursive descent. It is
+// recovered by Parse.
+type parseError struct {
+	error
+}
+
+// plpgsqlParser holds the state of the parser.
+type plpgsqlParser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+// raiseLevels are the severities that RAISE accepts.
+var raiseLevels = map[string]struct{}{
+	"debug": {}, "log": {}, "info": {}, "notice": {}, "warning": {}, "exception": {},
+}
+
+// raiseOptions are the options that RAISE accepts with USING.
+var raiseOptions = map[string]struct{}{
+	"message": {}, "detail": {}, "hint": {}, "errcode": {},
+}
+
+func (p *plpgsqlParser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *plpgsqlParser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+offset]
+}
+
+func (p *plpgsqlParser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword returns true if tok is the given unquoted keyword.
+func isKeyword(tok token, kw string) bool {
+	return tok.kind == tokIdent && tok.str == kw
+}
+
+// isOp returns true if tok is the given operator or punctuation.
+func isOp(tok token, op string) bool {
+	return tok.kind == tokOp && tok.str == op
+}
+
+// accept consumes the next token if it is the given keyword.
+func (p *plpgsqlParser) accept(kw string) bool {
+	if isKeyword(p.peek(), kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the next token, which must be the given keyword.
+func (p *plpgsqlParser) expect(kw string) {
+	if !p.accept(kw) {
+		p.errorf("expected %s", strings.ToUpper(kw))
+	}
+}
+
+// expectOp consumes the next token, which must be the given operator.
+func (p *plpgsqlParser) expectOp(op string) {
+	if !isOp(p.peek(), op) {
+		p.errorf("expected %q", op)
+	}
+	p.pos++
+}
+
+// errorf reports a syntax error at the next token.
+func (p *plpgsqlParser) errorf(format string, args ...interface{}) {
+	tok := p.peek()
+	near := "EOF"
+	if tok.kind != tokEOF {
+		near = p.src[tok.start:tok.end]
+	}
+	err := errors.Wrapf(errors.Newf(format, args...), "at or near %q: syntax error", near)
+	p.fail(pgerror.WithCandidateCode(p.withSource(err, tok.start), pgcode.Syntax))
+}
+
+// withSource annotates err with the line of the body at the given offset.
+func (p *plpgsqlParser) withSource(err error, offset int) error {
+	lineStart := strings.LastIndexByte(p.src[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(p.src[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(p.src)
+	} else {
+		lineEnd += offset
+	}
+	return errors.WithDetailf(err, "source SQL:\n%s\n%s^",
+		p.src[lineStart:lineEnd], strings.Repeat(" ", offset-lineStart))
+}
+
+func (p *plpgsqlParser) fail(err error) {
+	panic(parseError{err})
+}
+
+// parseBody parses a function body: a block optionally followed by a
+// semicolon.
+func (p *plpgsqlParser) parseBody() *plpgsqltree.Block {
+	label := p.optLabel()
+	if !isKeyword(p.peek(), "declare") && !isKeyword(p.peek(), "begin") {
+		p.errorf("expected DECLARE or BEGIN")
+	}
+	block := p.parseBlock(label)
+	if isOp(p.peek(), ";") {
+		p.next()
+	}
+	if p.peek().kind != tokEOF {
+		p.errorf("unexpected input after END")
+	}
+	return block
+}
+
+// optLabel parses an optional <<label>>.
+func (p *plpgsqlParser) optLabel() tree.Name {
+	if !isOp(p.peek(), "<<") {
+		return ""
+	}
+	p.next()
+	label := p.ident()
+	p.expectOp(">>")
+	return label
+}
+
+// ident parses an identifier.
+func (p *plpgsqlParser) ident() tree.Name {
+	tok := p.peek()
+	if tok.kind != tokIdent && tok.kind != tokQuotedIdent {
+		p.errorf("expected identifier")
+	}
+	p.next()
+	return tree.Name(tok.str)
+}
+
+// optEndLabel parses the optional label following the END of a block or loop.
+// If present, it must match the label of the block or loop.
+func (p *plpgsqlParser) optEndLabel(label tree.Name) {
+	tok := p.peek()
+	if tok.kind != tokIdent && tok.kind != tokQuotedIdent {
+		return
+	}
+	if label == "" {
+		p.errorf("end label %q specified for unlabeled block", tok.str)
+	}
+	if tree.Name(tok.str) != label {
+		p.errorf("end label %q differs from block's label %q", tok.str, string(label))
+	}
+	p.next()
+}
+
+// parseBlock parses:
+//
+//	[ DECLARE declarations ]
+//	BEGIN
+//	  statements
+//	[ EXCEPTION
+//	  WHEN condition [ OR condition ... ] THEN
+//	    handler_statements
+//	  ... ]
+//	END [ label ]
+func (p *plpgsqlParser) parseBlock(label tree.Name) *plpgsqltree.Block {
+	block := &plpgsqltree.Block{Label: label}
+	if p.accept("declare") {
+		for !isKeyword(p.peek(), "begin") {
+			// A block may have several DECLARE sections.
+			if p.accept("declare") {
+				continue
+			}
+			block.Decls = append(block.Decls, p.parseDeclaration())
+		}
+	}
+	p.expect("begin")
+	block.Body = p.parseStmts("exception", "end")
+	if p.accept("exception") {
+		for isKeyword(p.peek(), "when") {
+			p.next()
+			var exc plpgsqltree.Exception
+			for {
+				exc.Conditions = append(exc.Conditions, p.parseCondition())
+				if !p.accept("or") {
+					break
+				}
+			}
+			p.expect("then")
+			exc.Action = p.parseStmts("when", "end")
+			block.Exceptions = append(block.Exceptions, exc)
+		}
+		if len(block.Exceptions) == 0 {
+			p.errorf("expected WHEN")
+		}
+	}
+	p.expect("end")
+	p.optEndLabel(label)
+	return block
+}
+
+// parseDeclaration parses:
+//
+//	name [ CONSTANT ] type [ NOT NULL ] [ { DEFAULT | := | = } expression ];
+func (p *plpgsqlParser) parseDeclaration() plpgsqltree.Declaration {
+	var decl plpgsqltree.Declaration
+	decl.Var = p.ident()
+	if p.accept("constant") {
+		decl.Constant = true
+	}
+	switch {
+	case isKeyword(p.peek(), "alias"):
+		p.fail(unimplemented.New("plpgsql alias", "ALIAS declarations are not yet supported"))
+	case isKeyword(p.peek(), "cursor") || isKeyword(p.peek(), "refcursor"):
+		p.fail(unimplemented.New("plpgsql cursor", "cursor declarations are not yet supported"))
+	}
+	typStart := p.peek()
+	typText := p.rawUntil(func(tok token) bool {
+		return isOp(tok, ";") || isOp(tok, ":=") || isOp(tok, "=") || isKeyword(tok, "default") ||
+			isKeyword(tok, "not") || isKeyword(tok, "collate") || isKeyword(tok, "begin")
+	}, "data type")
+	typ, err := sqlparser.GetTypeFromValidSQLSyntax(typText)
+	if err != nil {
+		p.fail(p.withSource(err, typStart.start))
+	}
+	decl.Typ = typ
+	if isKeyword(p.peek(), "collate") {
+		p.fail(unimplemented.New("plpgsql collate", "COLLATE in declarations is not yet supported"))
+	}
+	if p.accept("not") {
+		p.expect("null")
+		decl.NotNull = true
+	}
+	if isOp(p.peek(), ":=") || isOp(p.peek(), "=") || isKeyword(p.peek(), "default") {
+		p.next()
+		decl.Default = p.parseExprUntilSemicolon()
+	}
+	p.expectOp(";")
+	return decl
+}
+
+// parseCondition parses an exception condition: a condition name, OTHERS, or
+// SQLSTATE 'xxxxx'.
+func (p *plpgsqlParser) parseCondition() plpgsqltree.Condition {
+	if p.accept("sqlstate") {
+		code := p.parseString()
+		if len(code) != 5 {
+			p.errorf("invalid SQLSTATE code %q", code)
+		}
+		return plpgsqltree.Condition{SQLState: code}
+	}
+	tok := p.peek()
+	if tok.kind != tokIdent {
+		p.errorf("expected exception condition")
+	}
+	if tok.str != "others" {
+		if _, ok := plpgsqltree.LookupCondition(tok.str); !ok {
+			p.fail(p.withSource(pgerror.Newf(pgcode.UndefinedObject,
+				"unrecognized exception condition %q", tok.str), tok.start))
+		}
+	}
+	p.next()
+	return plpgsqltree.Condition{Name: tok.str}
+}
+
+// parseStmts parses statements, each terminated by a semicolon, until one of
+// the given keywords is found.
+func (p *plpgsqlParser) parseStmts(terminators ...string) []plpgsqltree.Statement {
+	var stmts []plpgsqltree.Statement
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			p.errorf("expected %s", strings.ToUpper(terminators[len(terminators)-1]))
+		}
+		if tok.kind == tokIdent {
+			for _, t := range terminators {
+				if tok.str == t {
+					return stmts
+				}
+			}
+		}
+		stmts = append(stmts, p.parseStmt())
+		p.expectOp(";")
+	}
+}
+
+// parseStmt parses a single statement, without the terminating semicolon.
+func (p *plpgsqlParser) parseStmt() plpgsqltree.Statement {
+	label := p.optLabel()
+	tok := p.peek()
+	if label != "" {
+		switch {
+		case isKeyword(tok, "declare"), isKeyword(tok, "begin"), isKeyword(tok, "loop"),
+			isKeyword(tok, "while"), isKeyword(tok, "for"):
+		default:
+			p.errorf("a label must precede a block or a loop")
+		}
+	}
+	if tok.kind == tokIdent {
+		switch tok.str {
+		case "declare", "begin":
+			return p.parseBlock(label)
+		case "if":
+			return p.parseIf()
+		case "loop":
+			p.next()
+			body := p.parseLoopBody(label)
+			return &plpgsqltree.Loop{Label: label, Body: body}
+		case "while":
+			p.next()
+			cond := p.parseExprUntil("loop")
+			p.next()
+			body := p.parseLoopBody(label)
+			return &plpgsqltree.While{Label: label, Condition: cond, Body: body}
+		case "for":
+			return p.parseFor(label)
+		case "exit", "continue":
+			return p.parseExitOrContinue()
+		case "return":
+			return p.parseReturn()
+		case "raise":
+			return p.parseRaise()
+		case "perform":
+			p.next()
+			start := p.peek()
+			text := p.rawUntil(func(tok token) bool { return isOp(tok, ";") }, "expression")
+			return &plpgsqltree.Perform{Query: p.parseSQL("SELECT "+text, start.start)}
+		case "null":
+			if isOp(p.peekAt(1), ";") {
+				p.next()
+				return &plpgsqltree.Null{}
+			}
+		case "case":
+			p.fail(unimplemented.New("plpgsql case", "CASE statements are not yet supported"))
+		case "get":
+			p.fail(unimplemented.New("plpgsql get diagnostics", "GET DIAGNOSTICS is not yet supported"))
+		case "execute":
+			p.fail(unimplemented.New("plpgsql execute", "dynamic SQL with EXECUTE is not yet supported"))
+		case "open", "fetch", "move", "close":
+			p.fail(unimplemented.New("plpgsql cursor",
+				fmt.Sprintf("%s is not yet supported", strings.ToUpper(tok.str))))
+		}
+	}
+	if (tok.kind == tokIdent || tok.kind == tokQuotedIdent) &&
+		(isOp(p.peekAt(1), ":=") || isOp(p.peekAt(1), "=")) {
+		p.next()
+		p.next()
+		return &plpgsqltree.Assignment{Var: tree.Name(tok.str), Value: p.parseExprUntilSemicolon()}
+	}
+	if (tok.kind == tokIdent || tok.kind == tokQuotedIdent) && isOp(p.peekAt(1), ".") &&
+		(p.peekAt(2).kind == tokIdent || p.peekAt(2).kind == tokQuotedIdent) &&
+		(isOp(p.peekAt(3), ":=") || isOp(p.peekAt(3), "=")) {
+		field := p.peekAt(2)
+		p.next()
+		p.next()
+		p.next()
+		p.next()
+		return &plpgsqltree.Assignment{
+			Var: tree.Name(tok.str), Field: tree.Name(field.str), Value: p.parseExprUntilSemicolon(),
+		}
+	}
+	return p.parseExecute()
+}
+
+// parseIf parses:
+//
+//	IF condition THEN statements
+//	[ { ELSIF | ELSEIF } condition THEN statements ... ]
+//	[ ELSE statements ]
+//	END IF
+func (p *plpgsqlParser) parseIf() plpgsqltree.Statement {
+	p.expect("if")
+	stmt := &plpgsqltree.If{}
+	stmt.Condition = p.parseExprUntil("then")
+	p.next()
+	stmt.ThenBody = p.parseStmts("elsif", "elseif", "else", "end")
+	for p.accept("elsif") || p.accept("elseif") {
+		var elseIf plpgsqltree.ElseIf
+		elseIf.Condition = p.parseExprUntil("then")
+		p.next()
+		elseIf.Stmts = p.parseStmts("elsif", "elseif", "else", "end")
+		stmt.ElseIfList = append(stmt.ElseIfList, elseIf)
+	}
+	if p.accept("else") {
+		stmt.ElseBody = p.parseStmts("end")
+	}
+	p.expect("end")
+	p.expect("if")
+	return stmt
+}
+
+// parseLoopBody parses the statements of a loop following LOOP, up to and
+// including END LOOP [ label ].
+func (p *plpgsqlParser) parseLoopBody(label tree.Name) []plpgsqltree.Statement {
+	body := p.parseStmts("end")
+	p.expect("end")
+	p.expect("loop")
+	p.optEndLabel(label)
+	return body
+}
+
+// parseFor parses:
+//
+//	FOR name IN [ REVERSE ] expression .. expression [ BY expression ] LOOP
+//	  statements
+//	END LOOP [ label ]
+//
+// or:
+//
+//	FOR target [, target ...] IN query LOOP
+//	  statements
+//	END LOOP [ label ]
+func (p *plpgsqlParser) parseFor(label tree.Name) plpgsqltree.Statement {
+	p.expect("for")
+	var targets tree.NameList
+	for {
+		targets = append(targets, p.ident())
+		if !isOp(p.peek(), ",") {
+			break
+		}
+		p.next()
+	}
+	p.expect("in")
+
+	// The loop iterates over an integer range if there is a .. operator before
+	// LOOP.
+	isRange := false
+	p.lookahead(func(tok token, depth int) bool {
+		if depth == 0 && isOp(tok, "..") {
+			isRange = true
+		}
+		return depth == 0 && (isKeyword(tok, "loop") || isOp(tok, ";"))
+	})
+	if !isRange {
+		if isKeyword(p.peek(), "execute") {
+			p.fail(unimplemented.New("plpgsql execute", "dynamic SQL with EXECUTE is not yet supported"))
+		}
+		start := p.peek()
+		text := p.rawUntil(func(tok token) bool { return isKeyword(tok, "loop") }, "query")
+		p.expect("loop")
+		query := p.parseSQL(text, start.start)
+		body := p.parseLoopBody(label)
+		return &plpgsqltree.ForQuery{Label: label, Targets: targets, Query: query, Body: body}
+	}
+
+	if len(targets) != 1 {
+		p.errorf("integer FOR loop must have only one target variable")
+	}
+	stmt := &plpgsqltree.ForInt{Label: label, Var: targets[0]}
+	// REVERSE is only a keyword if it is not the start of an expression, like
+	// reverse(x).
+	if isKeyword(p.peek(), "reverse") && !isOp(p.peekAt(1), "(") {
+		p.next()
+		stmt.Reverse = true
+	}
+	stmt.Lower = p.parseExpr(func(tok token) bool { return isOp(tok, "..") })
+	p.next()
+	stmt.Upper = p.parseExprUntil("by", "loop")
+	if p.accept("by") {
+		stmt.Step = p.parseExprUntil("loop")
+	}
+	p.expect("loop")
+	stmt.Body = p.parseLoopBody(label)
+	return stmt
+}
+
+// parseExitOrContinue parses:
+//
+//	{ EXIT | CONTINUE } [ label ] [ WHEN condition ]
+func (p *plpgsqlParser) parseExitOrContinue() plpgsqltree.Statement {
+	isExit := p.next().str == "exit"
+	var label tree.Name
+	if tok := p.peek(); (tok.kind == tokIdent && tok.str != "when") || tok.kind == tokQuotedIdent {
+		label = p.ident()
+	}
+	var cond tree.Expr
+	if p.accept("when") {
+		cond = p.parseExprUntilSemicolon()
+	}
+	if isExit {
+		return &plpgsqltree.Exit{Label: label, Condition: cond}
+	}
+	return &plpgsqltree.Continue{Label: label, Condition: cond}
+}
+
+// parseReturn parses:
+//
+//	RETURN [ expression ]
+//
+// or:
+//
+//	RETURN NEXT expression
+//
+// or:
+//
+//	RETURN QUERY query
+func (p *plpgsqlParser) parseReturn() plpgsqltree.Statement {
+	p.expect("return")
+	switch {
+	case isKeyword(p.peek(), "query"):
+		p.next()
+		if isKeyword(p.peek(), "execute") {
+			p.fail(unimplemented.New("plpgsql execute", "dynamic SQL with EXECUTE is not yet supported"))
+		}
+		start := p.peek()
+		text := p.rawUntil(func(tok token) bool { return isOp(tok, ";") }, "query")
+		return &plpgsqltree.ReturnQuery{Query: p.parseSQL(text, start.start)}
+	case isKeyword(p.peek(), "next"):
+		p.next()
+		return &plpgsqltree.ReturnNext{Expr: p.parseExprUntilSemicolon()}
+	case isOp(p.peek(), ";"):
+		return &plpgsqltree.Return{}
+	}
+	return &plpgsqltree.Return{Expr: p.parseExprUntilSemicolon()}
+}
+
+// parseRaise parses:
+//
+//	RAISE [ level ] 'format' [, expression [, ...]] [ USING option = expression [, ... ] ]
+//	RAISE [ level ] condition_name [ USING option = expression [, ... ] ]
+//	RAISE [ level ] SQLSTATE 'sqlstate' [ USING option = expression [, ... ] ]
+//	RAISE [ level ] USING option = expression [, ... ]
+//	RAISE
+func (p *plpgsqlParser) parseRaise() plpgsqltree.Statement {
+	p.expect("raise")
+	stmt := &plpgsqltree.Raise{}
+	if tok := p.peek(); tok.kind == tokIdent {
+		if _, ok := raiseLevels[tok.str]; ok {
+			stmt.Level = strings.ToUpper(tok.str)
+			p.next()
+		}
+	}
+	switch tok := p.peek(); {
+	case tok.kind == tokString:
+		stmt.Message = p.parseString()
+		for isOp(p.peek(), ",") {
+			p.next()
+			stmt.Params = append(stmt.Params, p.parseExpr(func(tok token) bool {
+				return isOp(tok, ",") || isOp(tok, ";") || isKeyword(tok, "using")
+			}))
+		}
+	case isKeyword(tok, "sqlstate"):
+		stmt.Condition = p.parseCondition()
+	case tok.kind == tokIdent && tok.str != "using":
+		if tok.str == "others" {
+			p.errorf("OTHERS cannot be raised")
+		}
+		stmt.Condition = p.parseCondition()
+	}
+	if p.accept("using") {
+		for {
+			tok := p.peek()
+			if _, ok := raiseOptions[tok.str]; tok.kind != tokIdent || !ok {
+				p.errorf("unrecognized RAISE statement option")
+			}
+			p.next()
+			if !isOp(p.peek(), "=") && !isOp(p.peek(), ":=") {
+				p.errorf("expected \"=\"")
+			}
+			p.next()
+			stmt.Options = append(stmt.Options, plpgsqltree.RaiseOption{
+				Name: strings.ToUpper(tok.str),
+				Value: p.parseExpr(func(tok token) bool {
+					return isOp(tok, ",") || isOp(tok, ";")
+				}),
+			})
+			if !isOp(p.peek(), ",") {
+				break
+			}
+			p.next()
+		}
+	}
+	return stmt
+}
+
+// parseExecute parses a SQL statement with an optional INTO clause:
+//
+//	sql_statement [ INTO [ STRICT ] target [, target ...] ]
+//
+// The INTO clause may appear anywhere in the statement.
+func (p *plpgsqlParser) parseExecute() plpgsqltree.Statement {
+	stmt := &plpgsqltree.Execute{}
+	start := p.peek()
+	if start.kind == tokOp {
+		p.errorf("expected statement")
+	}
+	// INTO directly following INSERT, UPSERT or MERGE is part of the statement.
+	skipInto := isKeyword(start, "insert") || isKeyword(start, "upsert") || isKeyword(start, "merge")
+	startPos := p.pos
+	intoStart, intoEnd := -1, -1
+	depth := 0
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			p.errorf("unexpected end of function definition")
+		}
+		if depth == 0 && isOp(tok, ";") {
+			break
+		}
+		if depth == 0 && isKeyword(tok, "into") && !(skipInto && p.pos == startPos+1) {
+			if intoStart >= 0 {
+				p.errorf("INTO specified more than once")
+			}
+			intoStart = tok.start
+			p.next()
+			stmt.Strict = p.accept("strict")
+			for {
+				stmt.Targets = append(stmt.Targets, p.ident())
+				if !isOp(p.peek(), ",") {
+					break
+				}
+				p.next()
+			}
+			intoEnd = p.peek().start
+			continue
+		}
+		depth = nest(tok, depth)
+		p.next()
+	}
+	end := p.peek().start
+	text := p.src[start.start:end]
+	if intoStart >= 0 {
+		text = p.src[start.start:intoStart] + " " + p.src[intoEnd:end]
+	}
+	stmt.SQLStmt = p.parseSQL(strings.TrimSpace(text), start.start)
+	return stmt
+}
+
+// nest returns the nesting depth of parentheses, brackets and CASE expressions
+// following tok, given the depth preceding it.
+func nest(tok token, depth int) int {
+	switch {
+	case isOp(tok, "("), isOp(tok, "["), isKeyword(tok, "case"):
+		return depth + 1
+	case isOp(tok, ")"), isOp(tok, "]"), isKeyword(tok, "end"):
+		if depth > 0 {
+			return depth - 1
+		}
+	}
+	return depth
+}
+
+// scan calls fn with each token starting with the next one, along with the
+// nesting depth of parentheses, brackets and CASE expressions, until fn returns
+// true or the end of the body is reached. The token for which fn returns true
+// is not consumed.
+func (p *plpgsqlParser) scan(fn func(tok token, depth int) bool) {
+	depth := 0
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF || fn(tok, depth) {
+			return
+		}
+		depth = nest(tok, depth)
+		p.next()
+	}
+}
+
+// lookahead is like scan, but it does not consume any tokens.
+func (p *plpgsqlParser) lookahead(fn func(tok token, depth int) bool) {
+	pos := p.pos
+	p.scan(fn)
+	p.pos = pos
+}
+
+// rawUntil returns the text of the tokens preceding the first token at depth
+// zero for which stop returns true, and consumes those tokens. what describes
+// the expected text in the error reported if there are no such tokens.
+func (p *plpgsqlParser) rawUntil(stop func(tok token) bool, what string) string {
+	start := p.peek()
+	var last token
+	p.scan(func(tok token, depth int) bool {
+		if depth == 0 && stop(tok) {
+			return true
+		}
+		last = tok
+		return false
+	})
+	if p.peek().kind == tokEOF {
+		p.errorf("unexpected end of function definition")
+	}
+	if p.peek().start == start.start {
+		p.errorf("missing %s", what)
+	}
+	return p.src[start.start:last.end]
+}
+
+// parseExpr parses the SQL expression preceding the first token at depth zero
+// for which stop returns true.
+func (p *plpgsqlParser) parseExpr(stop func(tok token) bool) tree.Expr {
+	start := p.peek()
+	text := p.rawUntil(stop, "expression")
+	expr, err := sqlparser.ParseExpr(text)
+	if err != nil {
+		p.fail(p.withSource(err, start.start))
+	}
+	return expr
+}
+
+// parseExprUntil parses the SQL expression preceding any of the given
+// keywords.
+func (p *plpgsqlParser) parseExprUntil(keywords ...string) tree.Expr {
+	return p.parseExpr(func(tok token) bool {
+		for _, kw := range keywords {
+			if isKeyword(tok, kw) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseExprUntilSemicolon parses the SQL expression preceding the next
+// semicolon.
+func (p *plpgsqlParser) parseExprUntilSemicolon() tree.Expr {
+	return p.parseExpr(func(tok token) bool { return isOp(tok, ";") })
+}
+
+// parseSQL parses a single SQL statement. offset is the position of the
+// statement in the body, used for error reporting.
+func (p *plpgsqlParser) parseSQL(sql string, offset int) tree.Statement {
+	stmt, err := sqlparser.ParseOne(sql)
+	if err != nil {
+		p.fail(p.withSource(err, offset))
+	}
+	return stmt.AST
+}
+
+// parseString parses a string constant.
+func (p *plpgsqlParser) parseString() string {
+	tok := p.peek()
+	if tok.kind != tokString {
+		p.errorf("expected string constant")
+	}
+	expr, err := sqlparser.ParseExpr(tok.str)
+	if err != nil {
+		p.fail(p.withSource(err, tok.start))
+	}
+	s, ok := expr.(*tree.StrVal)
+	if !ok {
+		p.errorf("expected string constant")
+	}
+	p.next()
+	return s.RawString()
+}
@@ -0,0 +1,271 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security/username"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/paramparse"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/storageparam"
+	"github.com/cockroachdb/errors"
+)
+
+// checkPublicationsEnabled returns an error if publications cannot be used
+// yet because the cluster is not fully upgraded.
+func checkPublicationsEnabled(ctx context.Context, execCfg *ExecutorConfig, op string) error {
+	if !execCfg.Settings.Version.IsActive(ctx, clusterversion.Publications) {
+		return pgerror.Newf(
+			pgcode.FeatureNotSupported,
+			"cannot run %s before system is fully upgraded to v22.2", op,
+		)
+	}
+	return nil
+}
+
+// getMutableDatabaseForPublication returns the current database, in which
+// publications are created and looked up.
+func (p *planner) getMutableDatabaseForPublication(ctx context.Context) (*dbdesc.Mutable, error) {
+	dbName := p.CurrentDatabase()
+	if dbName == "" {
+		return nil, pgerror.New(pgcode.UndefinedDatabase,
+			"cannot use publications without a current database")
+	}
+	return p.Descriptors().GetMutableDatabaseByName(
+		ctx, p.txn, dbName, tree.DatabaseLookupFlags{Required: true},
+	)
+}
+
+// checkPublicationOwnership returns an error unless the current user owns the
+// given publication, or is an admin.
+func (p *planner) checkPublicationOwnership(
+	ctx context.Context, pub *descpb.PublicationDescriptor,
+) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		return nil
+	}
+	owner := pub.OwnerProto.Decode()
+	isOwner, err := p.checkRolePredicate(ctx, p.User(), func(role username.SQLUsername) (bool, error) {
+		return role == owner, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of publication %s", tree.Name(pub.Name))
+	}
+	return nil
+}
+
+// resolvePublicationTables resolves the tables named in a CREATE or ALTER
+// PUBLICATION statement, which must belong to the given database and be owned
+// by the current user. The result has no duplicates.
+func (p *planner) resolvePublicationTables(
+	ctx context.Context, db catalog.DatabaseDescriptor, names tree.TableNames,
+) ([]catalog.TableDescriptor, error) {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]catalog.TableDescriptor, 0, len(names))
+	seen := make(map[descpb.ID]struct{}, len(names))
+	for i := range names {
+		tn := &names[i]
+		_, desc, err := resolver.ResolveExistingTableObject(
+			ctx, p, tn, tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPublicationTable(db, desc); err != nil {
+			return nil, err
+		}
+		if !hasAdmin {
+			hasOwnership, err := p.HasOwnership(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			if !hasOwnership {
+				return nil, pgerror.Newf(pgcode.InsufficientPrivilege,
+					"must be owner of table %s", tree.Name(desc.GetName()))
+			}
+		}
+		if _, ok := seen[desc.GetID()]; ok {
+			continue
+		}
+		seen[desc.GetID()] = struct{}{}
+		tables = append(tables, desc)
+	}
+	return tables, nil
+}
+
+// publicationTableIDs returns the IDs of the given tables.
+func publicationTableIDs(tables []catalog.TableDescriptor) []descpb.ID {
+	ids := make([]descpb.ID, len(tables))
+	for i, desc := range tables {
+		ids[i] = desc.GetID()
+	}
+	return ids
+}
+
+// checkPublicationTable returns an error if the given table cannot be added to
+// a publication of the given database.
+func checkPublicationTable(db catalog.DatabaseDescriptor, desc catalog.TableDescriptor) error {
+	if desc.IsVirtualTable() {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"cannot add virtual table %q to publication", desc.GetName())
+	}
+	if desc.IsTemporary() {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"cannot add temporary table %q to publication", desc.GetName())
+	}
+	if desc.GetParentID() != db.GetID() {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot add table %q from another database to publication", desc.GetName())
+	}
+	if len(desc.GetFamilies()) > 1 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot add table %q with multiple column families to publication", desc.GetName())
+	}
+	return nil
+}
+
+// getPublicationTables returns the tables of the given publication, in the
+// order of their IDs. The tables which have been dropped since they were added
+// to the publication are skipped.
+func getPublicationTables(
+	ctx context.Context,
+	col *descs.Collection,
+	txn *kv.Txn,
+	db catalog.DatabaseDescriptor,
+	pub *descpb.PublicationDescriptor,
+) ([]catalog.TableDescriptor, error) {
+	all, err := col.GetAllTableDescriptorsInDatabase(ctx, txn, db)
+	if err != nil {
+		return nil, err
+	}
+	var ret []catalog.TableDescriptor
+	for _, desc := range all {
+		if publicationIncludesTable(pub, desc) {
+			ret = append(ret, desc)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].GetID() < ret[j].GetID() })
+	return ret, nil
+}
+
+// publicationIncludesTable returns whether the changes of the given table are
+// published by the publication.
+func publicationIncludesTable(
+	pub *descpb.PublicationDescriptor, desc catalog.TableDescriptor,
+) bool {
+	if desc.Dropped() || desc.Offline() || !desc.IsTable() ||
+		desc.IsVirtualTable() || desc.IsTemporary() {
+		return false
+	}
+	return pub.AllTables || publicationHasTable(pub, desc.GetID())
+}
+
+// publicationHasTable returns whether the table with the given ID was
+// explicitly added to the publication.
+func publicationHasTable(pub *descpb.PublicationDescriptor, id descpb.ID) bool {
+	for _, tableID := range pub.TableIDs {
+		if tableID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// publicationParamSetter sets the parameters of a publication specified by the
+// WITH clause of CREATE PUBLICATION and by ALTER PUBLICATION ... SET.
+type publicationParamSetter struct {
+	pub *descpb.PublicationDescriptor
+}
+
+var _ storageparam.Setter = (*publicationParamSetter)(nil)
+
+// Set implements the storageparam.Setter interface.
+func (s *publicationParamSetter) Set(
+	_ context.Context, _ *tree.SemaContext, _ *eval.Context, key string, datum tree.Datum,
+) error {
+	switch key {
+	case "publish":
+		str, ok := tree.AsDString(datum)
+		if !ok {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"parameter %q requires a string value", key)
+		}
+		s.pub.PublishInsert = false
+		s.pub.PublishUpdate = false
+		s.pub.PublishDelete = false
+		s.pub.PublishTruncate = false
+		if strings.TrimSpace(string(str)) == "" {
+			return nil
+		}
+		for _, op := range strings.Split(string(str), ",") {
+			switch strings.ToLower(strings.TrimSpace(op)) {
+			case "insert":
+				s.pub.PublishInsert = true
+			case "update":
+				s.pub.PublishUpdate = true
+			case "delete":
+				s.pub.PublishDelete = true
+			case "truncate":
+				s.pub.PublishTruncate = true
+			default:
+				return pgerror.Newf(pgcode.InvalidParameterValue,
+					"unrecognized %q value: %q", key, strings.TrimSpace(op))
+			}
+		}
+		return nil
+	case "publish_via_partition_root":
+		b, err := paramparse.GetSingleBool(key, datum)
+		if err != nil {
+			return err
+		}
+		if *b {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"parameter %q is not supported", key)
+		}
+		return nil
+	}
+	return pgerror.Newf(pgcode.InvalidParameterValue,
+		"unrecognized publication parameter: %q", key)
+}
+
+// Reset implements the storageparam.Setter interface.
+func (s *publicationParamSetter) Reset(_ context.Context, _ *eval.Context, key string) error {
+	return errors.AssertionFailedf("publication parameter %q cannot be reset", key)
+}
+
+// RunPostChecks implements the storageparam.Setter interface.
+func (s *publicationParamSetter) RunPostChecks() error {
+	return nil
+}
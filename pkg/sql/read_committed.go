@@ -0,0 +1,160 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// readCommittedIsolationEnabled controls whether transactions can use the READ
+// COMMITTED isolation level. When disabled, READ COMMITTED transactions are
+// upgraded to SERIALIZABLE. It is disabled by default: applications which ask
+// for READ COMMITTED have always been given SERIALIZABLE, and must not start
+// tolerating write skew just because the cluster was upgraded.
+var readCommittedIsolationEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.txn.read_committed_isolation.enabled",
+	"set to true to allow transactions to use the READ COMMITTED isolation level; "+
+		"otherwise, they are upgraded to SERIALIZABLE",
+	false,
+)
+
+// readCommittedMaxStmtRetries is the number of times a statement of a READ
+// COMMITTED transaction is retried after a write-write conflict, before the
+// conflict is handled like in SERIALIZABLE transactions.
+var readCommittedMaxStmtRetries = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.txn.read_committed_isolation.max_statement_retries",
+	"the maximum number of times a statement of a READ COMMITTED transaction is retried "+
+		"after a write-write conflict, before the transaction is restarted",
+	10,
+	settings.NonNegativeInt,
+)
+
+// txnIsoLevelToProto returns the isolation level with which a transaction
+// requesting the given isolation level is run. READ COMMITTED transactions are
+// run as SERIALIZABLE transactions if READ COMMITTED is disabled or not
+// supported by all the nodes of the cluster yet.
+func (ex *connExecutor) txnIsoLevelToProto(
+	ctx context.Context, level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	switch level {
+	case tree.ReadCommittedIsolation:
+		st := ex.server.cfg.Settings
+		if readCommittedIsolationEnabled.Get(&st.SV) &&
+			st.Version.IsActive(ctx, clusterversion.ReadCommittedIsolation) {
+			return enginepb.READ_COMMITTED
+		}
+		return enginepb.SERIALIZABLE
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		return enginepb.SERIALIZABLE
+	default:
+		log.Fatalf(ctx, "unknown isolation level: %s", level)
+		return enginepb.SERIALIZABLE
+	}
+}
+
+func (ex *connExecutor) txnIsoLevelWithSessionDefault(
+	ctx context.Context, level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData().DefaultTxnIsolationLevel)
+	}
+	return ex.txnIsoLevelToProto(ctx, level)
+}
+
+// usesPerStatementReadSnapshot returns whether the statements of the current
+// transaction each take a fresh read snapshot. This is the case for READ
+// COMMITTED transactions, until they perform schema changes: the descriptors
+// they wrote must then be read at a stable timestamp.
+func (ex *connExecutor) usesPerStatementReadSnapshot() bool {
+	return ex.state.isoLevel.PerStatementReadSnapshot() && ex.extraTxnState.numDDL == 0
+}
+
+// dispatchReadCommittedStmtToExecutionEngine is like dispatchToExecutionEngine,
+// but for statements of transactions which take a fresh read snapshot for each
+// statement.
+//
+// If the statement hits a write-write conflict, its writes are rolled back to
+// a savepoint taken when the statement started, and the statement is retried
+// with a more recent read snapshot. The transaction doesn't need to be
+// restarted, so the conflict isn't surfaced to the client. The statement is
+// not retried if results were already flushed to the client, if it performed
+// a schema change, or if it was retried too many times already: the retry
+// error is then handled like in SERIALIZABLE transactions.
+func (ex *connExecutor) dispatchReadCommittedStmtToExecutionEngine(
+	ctx context.Context, p *planner, res RestrictedCommandResult,
+) error {
+	txn := ex.state.mu.txn
+	maxRetries := readCommittedMaxStmtRetries.Get(&ex.server.cfg.Settings.SV)
+	for attempt := int64(0); ; attempt++ {
+		if attempt > 0 {
+			// Create a new sequencing point, so that the retried statement reads
+			// the writes of the previous statements, but not the ones which were
+			// rolled back.
+			if err := txn.Step(ctx); err != nil {
+				res.SetError(err)
+				return nil
+			}
+		}
+		if err := txn.StepReadTimestamp(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		savepoint, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			res.SetError(err)
+			return nil
+		}
+		bufferPos := res.BufferedResultsLen()
+		numDDL := ex.extraTxnState.numDDL
+		notifications := ex.extraTxnState.notifications.savepoint()
+		deferredConstraints := ex.extraTxnState.deferredConstraints.savepoint()
+
+		if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+			return err
+		}
+
+		var retryErr *roachpb.TransactionRetryWithProtoRefreshError
+		if !errors.As(res.Err(), &retryErr) || !retryErr.PartialRetry || retryErr.TxnID != txn.ID() {
+			return nil
+		}
+		if attempt >= maxRetries || ex.extraTxnState.numDDL != numDDL {
+			return nil
+		}
+		if !res.TruncateBufferedResults(bufferPos) {
+			// Some of the results of the statement were already sent to the
+			// client.
+			return nil
+		}
+		log.VEventf(ctx, 2, "retrying statement after write-write conflict: %v", retryErr)
+		res.SetError(nil)
+		if err := txn.PrepareForPartialRetry(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		if err := txn.RollbackToSavepoint(ctx, savepoint); err != nil {
+			res.SetError(err)
+			return nil
+		}
+		ex.extraTxnState.notifications.rollbackTo(notifications)
+		ex.extraTxnState.deferredConstraints.rollbackTo(deferredConstraints)
+		ex.metrics.EngineMetrics.ReadCommittedStmtRetryCount.Inc(1)
+	}
+}
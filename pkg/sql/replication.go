@@ -0,0 +1,264 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// execStartReplication streams the changes of the tables of the publications
+// passed to START_REPLICATION, until the client ends the stream.
+//
+// Errors which occur before streaming starts are reported to the client like
+// the errors of any other statement. Once streaming has started, the
+// connection is terminated if an error occurs, so the error is returned.
+func (ex *connExecutor) execStartReplication(
+	ctx context.Context, cmd StartReplication,
+) (_ fsm.Event, retPayload fsm.EventPayload, retErr error) {
+	ex.incrementStartedStmtCounter(cmd.Stmt)
+	defer func() {
+		if retErr == nil && !payloadHasError(retPayload) {
+			ex.incrementExecutedStmtCounter(cmd.Stmt)
+		}
+		if retErr != nil {
+			log.SqlExec.Errorf(ctx, "error executing %s: %+v", cmd, retErr)
+		}
+	}()
+
+	// When we're done, unblock the network connection.
+	defer cmd.StreamDone.Done()
+
+	errPayload := func(err error) (fsm.Event, fsm.EventPayload, error) {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload, nil
+	}
+	if _, isNoTxn := ex.machine.CurState().(stateNoTxn); !isNoTxn {
+		return errPayload(pgerror.New(pgcode.ActiveSQLTransaction,
+			"START_REPLICATION cannot be executed inside a transaction"))
+	}
+	if err := checkPublicationsEnabled(ctx, ex.server.cfg, "START_REPLICATION"); err != nil {
+		return errPayload(err)
+	}
+	opts, err := pgrepl.ParsePgoutputOptions(cmd.Stmt.Options)
+	if err != nil {
+		return errPayload(err)
+	}
+
+	var tables map[descpb.ID]publishedChanges
+	if err := DescsTxn(ctx, ex.server.cfg, func(
+		ctx context.Context, txn *kv.Txn, col *descs.Collection,
+	) (err error) {
+		p, cleanup := newInternalPlanner(
+			"start-replication",
+			txn,
+			ex.sessionData().User(),
+			&MemoryMetrics{},
+			ex.server.cfg,
+			ex.sessionData().SessionData,
+			WithDescCollection(col),
+		)
+		defer cleanup()
+		tables, err = p.resolveReplicatedTables(ctx, opts.Publications)
+		return err
+	}); err != nil {
+		return errPayload(err)
+	}
+
+	// Streaming starts after the given LSN, or now if there is none.
+	startTS := cmd.Stmt.LSN.ResumeTimestamp()
+	if cmd.Stmt.LSN == pgrepl.InvalidLSN {
+		startTS = ex.server.cfg.Clock.Now()
+	}
+	feed, err := startReplicationFeed(ctx, ex.server.cfg, tables, startTS)
+	if err != nil {
+		return errPayload(err)
+	}
+	sd := ex.sessionData()
+	if err := cmd.Conn.StreamReplication(
+		ctx, cmd.Stmt.LSN, feed, sd.DataConversionConfig, sd.GetLocation(),
+	); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
+
+// resolveReplicatedTables resolves the tables of the given publications of the
+// current database, along with the kinds of changes published for each of
+// them. Like for changefeeds, the current user needs the CHANGEFEED privilege
+// on the tables, or the SELECT privilege if they have the CONTROLCHANGEFEED
+// role option.
+func (p *planner) resolveReplicatedTables(
+	ctx context.Context, publications []string,
+) (map[descpb.ID]publishedChanges, error) {
+	db, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.txn, p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true},
+	)
+	if err != nil {
+		return nil, err
+	}
+	hasControlChangefeed, err := p.HasRoleOption(ctx, roleoption.CONTROLCHANGEFEED)
+	if err != nil {
+		return nil, err
+	}
+	requiredPrivilege := privilege.CHANGEFEED
+	if hasControlChangefeed {
+		requiredPrivilege = privilege.SELECT
+	}
+
+	tables := make(map[descpb.ID]publishedChanges)
+	for _, name := range publications {
+		pub := db.FindPublicationByName(name)
+		if pub == nil {
+			return nil, pgerror.Newf(pgcode.UndefinedObject,
+				"publication %q does not exist", name)
+		}
+		pubTables, err := getPublicationTables(ctx, p.Descriptors(), p.txn, db, pub)
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range pubTables {
+			if err := p.CheckPrivilege(ctx, desc, requiredPrivilege); err != nil {
+				return nil, errors.WithHint(err,
+					"Users with CONTROLCHANGEFEED need SELECT, other users need CHANGEFEED.")
+			}
+			if len(desc.GetFamilies()) > 1 {
+				return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot stream the changes of table %q with multiple column families",
+					desc.GetName())
+			}
+			published := tables[desc.GetID()]
+			published.insert = published.insert || pub.PublishInsert
+			published.update = published.update || pub.PublishUpdate
+			published.delete = published.delete || pub.PublishDelete
+			tables[desc.GetID()] = published
+		}
+	}
+	return tables, nil
+}
+
+var identifySystemColumns = colinfo.ResultColumns{
+	{Name: "systemid", Typ: types.String},
+	{Name: "timeline", Typ: types.Int4},
+	{Name: "xlogpos", Typ: types.String},
+	{Name: "dbname", Typ: types.String},
+}
+
+// IdentifySystem implements the IDENTIFY_SYSTEM replication command, which
+// returns the identifier of the cluster and the current position.
+// Privileges: None.
+func (p *planner) IdentifySystem(ctx context.Context, n *pgrepl.IdentifySystem) (planNode, error) {
+	return &delayedNode{
+		name:    n.String(),
+		columns: identifySystemColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			clusterID := p.ExecCfg().NodeInfo.LogicalClusterID().ToUint128()
+			lsn := pgrepl.LSNFromTimestamp(p.ExecCfg().Clock.Now())
+			dbName := tree.DNull
+			if db := p.CurrentDatabase(); db != "" {
+				dbName = tree.NewDString(db)
+			}
+			row := tree.Datums{
+				tree.NewDString(strconv.FormatUint(clusterID.Lo, 10)),
+				tree.NewDInt(1),
+				tree.NewDString(lsn.String()),
+				dbName,
+			}
+			return newReplicationCommandValuesNode(ctx, p, identifySystemColumns, row)
+		},
+	}, nil
+}
+
+var createReplicationSlotColumns = colinfo.ResultColumns{
+	{Name: "slot_name", Typ: types.String},
+	{Name: "consistent_point", Typ: types.String},
+	{Name: "snapshot_name", Typ: types.String},
+	{Name: "output_plugin", Typ: types.String},
+}
+
+// CreateReplicationSlot implements the CREATE_REPLICATION_SLOT replication
+// command. Replication slots are not persisted: the position from which to
+// resume streaming is tracked by the client. The command returns the current
+// position, from which streaming can start, and in place of the name of a
+// snapshot, the timestamp of the data at that position, which can be read
+// using AS OF SYSTEM TIME.
+// Privileges: None.
+func (p *planner) CreateReplicationSlot(
+	ctx context.Context, n *pgrepl.CreateReplicationSlot,
+) (planNode, error) {
+	if n.Plugin != pgrepl.OutputPlugin {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"output plugin %q is not supported", n.Plugin)
+	}
+	if n.Snapshot == pgrepl.SnapshotUse {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"snapshot action %q is not supported", n.Snapshot)
+	}
+	return &delayedNode{
+		name:    n.String(),
+		columns: createReplicationSlotColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			// Changes may still be committed at the current wall time, so the
+			// consistent point precedes it. All the changes committed up to the
+			// consistent point are visible at its resume timestamp.
+			lsn := pgrepl.LSNFromTimestamp(p.ExecCfg().Clock.Now()) - 1
+			snapshot := tree.DNull
+			if n.Snapshot != pgrepl.SnapshotNothing {
+				snapshot = tree.NewDString(lsn.ResumeTimestamp().AsOfSystemTime())
+			}
+			row := tree.Datums{
+				tree.NewDString(string(n.Slot)),
+				tree.NewDString(lsn.String()),
+				snapshot,
+				tree.NewDString(pgrepl.OutputPlugin),
+			}
+			return newReplicationCommandValuesNode(ctx, p, createReplicationSlotColumns, row)
+		},
+	}, nil
+}
+
+// DropReplicationSlot implements the DROP_REPLICATION_SLOT replication
+// command. Since replication slots are not persisted, there is nothing to do.
+// Privileges: None.
+func (p *planner) DropReplicationSlot(
+	ctx context.Context, n *pgrepl.DropReplicationSlot,
+) (planNode, error) {
+	return newZeroNode(nil /* columns */), nil
+}
+
+// newReplicationCommandValuesNode returns a valuesNode producing the single
+// row returned by a replication command.
+func newReplicationCommandValuesNode(
+	ctx context.Context, p *planner, columns colinfo.ResultColumns, row tree.Datums,
+) (planNode, error) {
+	v := p.newContainerValuesNode(columns, 1 /* capacity */)
+	if _, err := v.rows.AddRow(ctx, row); err != nil {
+		v.Close(ctx)
+		return nil, err
+	}
+	return v, nil
+}
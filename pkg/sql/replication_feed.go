@@ -0,0 +1,608 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"container/heap"
+	"context"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// publishedChanges are the kinds of changes of a table which are streamed,
+// i.e. the union of the kinds published by the publications which include it.
+type publishedChanges struct {
+	insert, update, delete bool
+}
+
+// replicationBufferSize limits the memory used by a replication stream to
+// buffer the changes which it can't send yet, either because the rangefeed's
+// frontier hasn't moved past them or because the client hasn't consumed the
+// transactions before them.
+var replicationBufferSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"sql.replication.buffer_size",
+	"the maximum memory used by a logical replication stream to buffer the changes it has not sent yet",
+	64<<20,
+)
+
+// replicationFeed is the pgrepl.Feed which streams the changes committed to
+// the tables of publications. It is sourced from a rangefeed over the spans
+// of the tables.
+//
+// The changes are buffered until the rangefeed's frontier has moved past their
+// timestamp, and are then streamed in timestamp order as transactions grouping
+// the changes with the same LSN, i.e. the same wall time (see pgrepl.LSN).
+// Only the changes to the primary index of the tables are of interest.
+//
+// The callbacks of the rangefeed never block on the client: the transactions
+// ready to be streamed are queued, and sent by a separate goroutine. Both the
+// pending changes and the queued transactions are accounted for by a memory
+// monitor, and the feed fails if the client falls too far behind.
+type replicationFeed struct {
+	execCfg *ExecutorConfig
+	tables  map[descpb.ID]publishedChanges
+	events  chan pgrepl.Event
+	rf      *rangefeed.RangeFeed
+
+	// bufferMon limits the memory accounted for by mu.acc.
+	bufferMon *mon.BytesMonitor
+	// readyC is signaled when events are added to mu.ready.
+	readyC chan struct{}
+	// stopSender stops the goroutine sending the ready events, and senderDone
+	// is closed once it has.
+	stopSender func()
+	senderDone chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		// ready contains the events which can be sent, in order.
+		ready []readyEvent
+		// acc accounts for the memory of the pending changes, which is moved to
+		// the ready events, and released once they are sent.
+		acc mon.BoundAccount
+	}
+
+	// The fields below are only accessed by the callbacks of the rangefeed,
+	// which are invoked serially.
+
+	// decoders caches the decoders of the versions of the tables.
+	decoders map[replicatedTableVersion]*replicatedTableDecoder
+	// pending contains the changes which can't be streamed until the frontier
+	// moves past their timestamp, ordered by timestamp. seen contains their
+	// keys and timestamps, since the rangefeed may emit the same value more than
+	// once when it restarts.
+	pending pendingChanges
+	seen    map[pendingChangeKey]struct{}
+	// checkpoint is the last LSN up to which all the transactions were sent.
+	checkpoint pgrepl.LSN
+	// failed is set once an error was queued; the feed is useless afterwards.
+	failed bool
+}
+
+var _ pgrepl.Feed = (*replicationFeed)(nil)
+
+type readyEvent struct {
+	ev pgrepl.Event
+	// size is the memory accounted for the event.
+	size int64
+}
+
+type pendingChange struct {
+	key    pendingChangeKey
+	change pgrepl.Change
+	// size is the memory accounted for the change.
+	size int64
+}
+
+// pendingChanges is a min-heap of changes ordered by timestamp. Changes with
+// the same timestamp are ordered by key, so that a transaction is streamed in
+// the same order every time.
+type pendingChanges []pendingChange
+
+var _ heap.Interface = (*pendingChanges)(nil)
+
+func (h pendingChanges) Len() int { return len(h) }
+
+func (h pendingChanges) Less(i, j int) bool {
+	if c := h[i].key.ts.Compare(h[j].key.ts); c != 0 {
+		return c < 0
+	}
+	return h[i].key.key < h[j].key.key
+}
+
+func (h pendingChanges) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pendingChanges) Push(x interface{}) { *h = append(*h, x.(pendingChange)) }
+
+func (h *pendingChanges) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = pendingChange{}
+	*h = old[:n-1]
+	return x
+}
+
+type pendingChangeKey struct {
+	key string
+	ts  hlc.Timestamp
+}
+
+type replicatedTableVersion struct {
+	id      descpb.ID
+	version descpb.DescriptorVersion
+}
+
+// replicatedTableDecoder decodes the rows of a version of a table.
+type replicatedTableDecoder struct {
+	desc      catalog.TableDescriptor
+	relation  pgrepl.Relation
+	fetcher   row.Fetcher
+	kvFetcher row.SpanKVFetcher
+	alloc     tree.DatumAlloc
+}
+
+// replicationFeedBufferSize is the number of events of a replicationFeed which
+// can be buffered in its channel before its sender blocks.
+const replicationFeedBufferSize = 16
+
+// startReplicationFeed starts a feed of the changes committed after the
+// given timestamp to the given tables.
+func startReplicationFeed(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	tables map[descpb.ID]publishedChanges,
+	startTS hlc.Timestamp,
+) (*replicationFeed, error) {
+	f := &replicationFeed{
+		execCfg:    execCfg,
+		tables:     tables,
+		events:     make(chan pgrepl.Event, replicationFeedBufferSize),
+		readyC:     make(chan struct{}, 1),
+		senderDone: make(chan struct{}),
+		decoders:   make(map[replicatedTableVersion]*replicatedTableDecoder),
+		seen:       make(map[pendingChangeKey]struct{}),
+		checkpoint: pgrepl.LSNFromTimestamp(startTS),
+	}
+	if len(tables) == 0 {
+		// There is nothing to watch; the feed never produces any event.
+		close(f.senderDone)
+		f.stopSender = func() {}
+		return f, nil
+	}
+
+	f.bufferMon = mon.NewMonitorInheritWithLimit("logical-replication",
+		replicationBufferSize.Get(&execCfg.Settings.SV), execCfg.RootMemoryMonitor)
+	f.bufferMon.StartNoReserved(ctx, execCfg.RootMemoryMonitor)
+	f.mu.acc = f.bufferMon.MakeBoundAccount()
+	senderCtx, cancel := context.WithCancel(ctx)
+	f.stopSender = cancel
+	if err := execCfg.DistSQLSrv.Stopper.RunAsyncTask(senderCtx, "logical-replication-sender",
+		f.sendLoop); err != nil {
+		cancel()
+		close(f.senderDone)
+		f.releaseBuffer(ctx)
+		return nil, err
+	}
+	spans := make([]roachpb.Span, 0, len(tables))
+	for id := range tables {
+		spans = append(spans, execCfg.Codec.TableSpan(uint32(id)))
+	}
+	rf, err := execCfg.RangeFeedFactory.RangeFeed(
+		ctx,
+		"logical-replication",
+		spans,
+		startTS,
+		f.onValue,
+		rangefeed.WithDiff(true),
+		rangefeed.WithOnFrontierAdvance(f.onFrontierAdvance),
+		rangefeed.WithOnSSTable(f.onSSTable),
+		rangefeed.WithOnDeleteRange(f.onDeleteRange),
+		rangefeed.WithOnInternalError(func(ctx context.Context, err error) {
+			f.fail(ctx, err)
+		}),
+	)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.rf = rf
+	return f, nil
+}
+
+// Events is part of the pgrepl.Feed interface.
+func (f *replicationFeed) Events() <-chan pgrepl.Event {
+	return f.events
+}
+
+// Close is part of the pgrepl.Feed interface.
+func (f *replicationFeed) Close() {
+	if f.rf != nil {
+		f.rf.Close()
+	}
+	f.stopSender()
+	<-f.senderDone
+	if f.bufferMon != nil {
+		f.releaseBuffer(context.Background())
+	}
+}
+
+func (f *replicationFeed) releaseBuffer(ctx context.Context) {
+	f.mu.Lock()
+	f.mu.ready = nil
+	f.mu.acc.Close(ctx)
+	f.mu.Unlock()
+	f.bufferMon.Stop(ctx)
+}
+
+// sendLoop sends the ready events on the channel of the feed until it is
+// stopped.
+func (f *replicationFeed) sendLoop(ctx context.Context) {
+	defer close(f.senderDone)
+	for {
+		f.mu.Lock()
+		ready := f.mu.ready
+		f.mu.ready = nil
+		f.mu.Unlock()
+		for _, r := range ready {
+			select {
+			case f.events <- r.ev:
+			case <-ctx.Done():
+				return
+			}
+			f.mu.Lock()
+			f.mu.acc.Shrink(ctx, r.size)
+			f.mu.Unlock()
+		}
+		select {
+		case <-f.readyC:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// enqueue queues an event to be sent by the sender, along with the memory
+// already accounted for it.
+func (f *replicationFeed) enqueue(ev pgrepl.Event, size int64) {
+	f.mu.Lock()
+	f.mu.ready = append(f.mu.ready, readyEvent{ev: ev, size: size})
+	f.mu.Unlock()
+	select {
+	case f.readyC <- struct{}{}:
+	default:
+	}
+}
+
+// fail terminates the feed with the given error once the events queued before
+// it are sent.
+func (f *replicationFeed) fail(ctx context.Context, err error) {
+	if f.failed {
+		return
+	}
+	f.failed = true
+	f.enqueue(pgrepl.Event{Err: err}, 0 /* size */)
+}
+
+// onValue is called by the rangefeed for every write to the tables.
+func (f *replicationFeed) onValue(ctx context.Context, value *roachpb.RangeFeedValue) {
+	if f.failed {
+		return
+	}
+	ts := value.Value.Timestamp
+	if pgrepl.LSNFromTimestamp(ts) <= f.checkpoint {
+		// This value was already streamed before the rangefeed restarted.
+		return
+	}
+	seenKey := pendingChangeKey{key: string(value.Key), ts: ts}
+	if _, ok := f.seen[seenKey]; ok {
+		return
+	}
+	ch, ok, err := f.decodeChange(ctx, value)
+	if err != nil {
+		f.fail(ctx, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	f.addPending(ctx, seenKey, ch)
+}
+
+// addPending buffers a change until the frontier moves past it. The feed fails
+// if the buffer is full.
+func (f *replicationFeed) addPending(ctx context.Context, key pendingChangeKey, ch pgrepl.Change) {
+	size := pendingChangeSize(key, ch)
+	f.mu.Lock()
+	err := f.mu.acc.Grow(ctx, size)
+	f.mu.Unlock()
+	if err != nil {
+		f.fail(ctx, errors.Wrap(err,
+			"buffering changes of the replication stream; the client is not keeping up"))
+		return
+	}
+	f.seen[key] = struct{}{}
+	heap.Push(&f.pending, pendingChange{key: key, change: ch, size: size})
+}
+
+// pendingChangeSize estimates the memory used by a pending change, including
+// its entry in the seen set.
+func pendingChangeSize(key pendingChangeKey, ch pgrepl.Change) int64 {
+	size := 2*int64(len(key.key)) + int64(unsafe.Sizeof(pendingChange{}))
+	for _, d := range ch.Row {
+		size += int64(d.Size())
+	}
+	return size
+}
+
+// decodeChange decodes the change made by a write to a table. It returns false
+// if the change is not streamed.
+func (f *replicationFeed) decodeChange(
+	ctx context.Context, value *roachpb.RangeFeedValue,
+) (_ pgrepl.Change, ok bool, _ error) {
+	ts := value.Value.Timestamp
+	d, isPrimary, err := f.decoderForKey(ctx, value.Key, ts)
+	if err != nil || !isPrimary {
+		return pgrepl.Change{}, false, err
+	}
+	published := f.tables[d.desc.GetID()]
+	ch := pgrepl.Change{Relation: &d.relation}
+	keyValue := roachpb.KeyValue{Key: value.Key, Value: value.Value}
+	switch {
+	case !value.Value.IsPresent():
+		if !value.PrevValue.IsPresent() {
+			// The row didn't exist.
+			return pgrepl.Change{}, false, nil
+		}
+		ch.Type = pgrepl.Delete
+		keyValue.Value = value.PrevValue
+		ok = published.delete
+	case value.PrevValue.IsPresent():
+		ch.Type = pgrepl.Update
+		ok = published.update
+	default:
+		ch.Type = pgrepl.Insert
+		ok = published.insert
+	}
+	if !ok {
+		return pgrepl.Change{}, false, nil
+	}
+	ch.Row, err = d.decodeRow(ctx, keyValue)
+	if err != nil {
+		return pgrepl.Change{}, false, errors.Wrapf(err,
+			"decoding row of table %q", d.desc.GetName())
+	}
+	return ch, true, nil
+}
+
+// decoderForKey returns the decoder of the version of the table which the
+// given key belongs to at the given timestamp, and whether the key belongs to
+// the primary index of the table.
+func (f *replicationFeed) decoderForKey(
+	ctx context.Context, key roachpb.Key, ts hlc.Timestamp,
+) (_ *replicatedTableDecoder, isPrimary bool, _ error) {
+	remaining, err := f.execCfg.Codec.StripTenantPrefix(key)
+	if err != nil {
+		return nil, false, err
+	}
+	_, tableID, indexID, err := rowenc.DecodePartialTableIDIndexID(remaining)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, ok := f.tables[tableID]; !ok {
+		return nil, false, nil
+	}
+	lease, err := f.execCfg.LeaseManager.Acquire(ctx, ts, tableID)
+	if err != nil {
+		return nil, false, err
+	}
+	desc := lease.Underlying()
+	lease.Release(ctx)
+	if indexID != desc.(catalog.TableDescriptor).GetPrimaryIndexID() {
+		return nil, false, nil
+	}
+	v := replicatedTableVersion{id: tableID, version: desc.GetVersion()}
+	if d, ok := f.decoders[v]; ok {
+		return d, true, nil
+	}
+	d, err := f.makeDecoder(ctx, tableID, ts)
+	if err != nil {
+		return nil, false, err
+	}
+	f.decoders[v] = d
+	return d, true, nil
+}
+
+// makeDecoder makes the decoder of the version of the table at the given
+// timestamp. The descriptor is read in a transaction rather than leased so
+// that the user-defined types it references are hydrated.
+func (f *replicationFeed) makeDecoder(
+	ctx context.Context, tableID descpb.ID, ts hlc.Timestamp,
+) (*replicatedTableDecoder, error) {
+	d := &replicatedTableDecoder{}
+	var schemaName string
+	if err := DescsTxn(ctx, f.execCfg, func(
+		ctx context.Context, txn *kv.Txn, col *descs.Collection,
+	) (err error) {
+		if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+			return err
+		}
+		d.desc, err = col.GetImmutableTableByID(ctx, txn, tableID, tree.ObjectLookupFlagsWithRequired())
+		if err != nil {
+			return err
+		}
+		sc, err := col.GetImmutableSchemaByID(
+			ctx, txn, d.desc.GetParentSchemaID(), tree.SchemaLookupFlags{Required: true},
+		)
+		if err != nil {
+			return err
+		}
+		schemaName = sc.GetName()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(d.desc.GetFamilies()) > 1 {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot stream the changes of table %q with multiple column families",
+			d.desc.GetName())
+	}
+
+	d.relation = pgrepl.Relation{
+		ID:        oid.Oid(d.desc.GetID()),
+		Version:   uint64(d.desc.GetVersion()),
+		Namespace: schemaName,
+		Name:      d.desc.GetName(),
+	}
+	primaryIndex := d.desc.GetPrimaryIndex()
+	var colIDs []descpb.ColumnID
+	for _, col := range d.desc.PublicColumns() {
+		if col.IsVirtual() {
+			continue
+		}
+		colIDs = append(colIDs, col.GetID())
+		d.relation.Columns = append(d.relation.Columns, pgrepl.Column{
+			Name: col.GetName(),
+			Type: col.GetType(),
+			Key:  primaryIndex.CollectKeyColumnIDs().Contains(col.GetID()),
+		})
+	}
+
+	var spec descpb.IndexFetchSpec
+	if err := rowenc.InitIndexFetchSpec(
+		&spec, f.execCfg.Codec, d.desc, primaryIndex, colIDs,
+	); err != nil {
+		return nil, err
+	}
+	if err := d.fetcher.Init(
+		ctx,
+		row.FetcherInitArgs{
+			WillUseCustomKVBatchFetcher: true,
+			Alloc:                       &d.alloc,
+			Spec:                        &spec,
+		},
+	); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// decodeRow decodes the row stored in the given key-value pair.
+func (d *replicatedTableDecoder) decodeRow(
+	ctx context.Context, keyValue roachpb.KeyValue,
+) (tree.Datums, error) {
+	d.kvFetcher.KVs = append(d.kvFetcher.KVs[:0], keyValue)
+	if err := d.fetcher.StartScanFrom(ctx, &d.kvFetcher); err != nil {
+		return nil, err
+	}
+	datums, err := d.fetcher.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if datums == nil {
+		return nil, errors.AssertionFailedf("unexpected empty row")
+	}
+	// The fetcher reuses its row.
+	return append(tree.Datums(nil), datums...), nil
+}
+
+// onFrontierAdvance queues the transactions whose changes were all received.
+func (f *replicationFeed) onFrontierAdvance(ctx context.Context, frontier hlc.Timestamp) {
+	if f.failed {
+		return
+	}
+	// Changes may still be received at the wall time of the frontier.
+	checkpoint := pgrepl.LSNFromTimestamp(frontier) - 1
+	if checkpoint <= f.checkpoint {
+		return
+	}
+	for len(f.pending) > 0 && pgrepl.LSNFromTimestamp(f.pending[0].key.ts) <= checkpoint {
+		ts := f.pending[0].key.ts
+		txn := &pgrepl.Transaction{
+			LSN:        pgrepl.LSNFromTimestamp(ts),
+			CommitTime: ts.GoTime(),
+		}
+		var size int64
+		for len(f.pending) > 0 && pgrepl.LSNFromTimestamp(f.pending[0].key.ts) == txn.LSN {
+			p := heap.Pop(&f.pending).(pendingChange)
+			txn.Changes = append(txn.Changes, p.change)
+			size += p.size
+			delete(f.seen, p.key)
+		}
+		f.enqueue(pgrepl.Event{Txn: txn}, size)
+	}
+	f.checkpoint = checkpoint
+	f.enqueue(pgrepl.Event{Checkpoint: checkpoint}, 0 /* size */)
+}
+
+// onSSTable is called by the rangefeed when an SST is ingested into the
+// tables. The rows it contains can't be streamed, so it fails the feed unless
+// the SST only contains entries of other indexes than the primary index, e.g.
+// the entries of a new index being backfilled.
+func (f *replicationFeed) onSSTable(
+	ctx context.Context, sst *roachpb.RangeFeedSSTable, _ roachpb.Span,
+) {
+	f.checkBulkWrite(ctx, sst.Span, sst.WriteTS, "ingested by a bulk operation")
+}
+
+// onDeleteRange is called by the rangefeed when a span of the tables is
+// deleted using an MVCC range tombstone. Like onSSTable, it fails the feed
+// unless the primary index of a table is unaffected.
+func (f *replicationFeed) onDeleteRange(
+	ctx context.Context, value *roachpb.RangeFeedDeleteRange,
+) {
+	f.checkBulkWrite(ctx, value.Span, value.Timestamp, "deleted by a bulk operation")
+}
+
+func (f *replicationFeed) checkBulkWrite(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, what string,
+) {
+	if f.failed {
+		return
+	}
+	for id := range f.tables {
+		lease, err := f.execCfg.LeaseManager.Acquire(ctx, ts, id)
+		if err != nil {
+			if catalog.HasInactiveDescriptorError(err) {
+				// The table was dropped.
+				continue
+			}
+			f.fail(ctx, err)
+			return
+		}
+		desc := lease.Underlying().(catalog.TableDescriptor)
+		lease.Release(ctx)
+		if desc.PrimaryIndexSpan(f.execCfg.Codec).Overlaps(span) {
+			f.fail(ctx, pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot stream the changes of table %q %s", desc.GetName(), what))
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicationFeedBuffer checks that the changes buffered by a
+// replicationFeed are queued as transactions in LSN order once the frontier
+// moves past them, and that the feed fails once its buffer is full.
+func TestReplicationFeedBuffer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	makeFeed := func(limit int64) *replicationFeed {
+		f := &replicationFeed{
+			readyC: make(chan struct{}, 1),
+			seen:   make(map[pendingChangeKey]struct{}),
+		}
+		f.bufferMon = mon.NewMonitorWithLimit("test", mon.MemoryResource, limit,
+			nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, st)
+		f.bufferMon.Start(ctx, nil /* pool */, mon.NewStandaloneBudget(math.MaxInt64))
+		f.mu.acc = f.bufferMon.MakeBoundAccount()
+		return f
+	}
+	change := func(i int) pgrepl.Change {
+		return pgrepl.Change{Type: pgrepl.Insert, Row: tree.Datums{tree.NewDInt(tree.DInt(i))}}
+	}
+	add := func(f *replicationFeed, key string, wall int64, logical int32, i int) {
+		ts := hlc.Timestamp{WallTime: wall, Logical: logical}
+		f.addPending(ctx, pendingChangeKey{key: key, ts: ts}, change(i))
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		f := makeFeed(math.MaxInt64)
+		defer f.releaseBuffer(ctx)
+
+		add(f, "c", 30, 0, 5)
+		add(f, "b", 10, 1, 2)
+		add(f, "a", 20, 0, 3)
+		add(f, "a", 10, 0, 1)
+		add(f, "d", 20, 0, 4)
+
+		// Nothing is queued until the frontier moves past the wall time of the
+		// changes.
+		f.onFrontierAdvance(ctx, hlc.Timestamp{WallTime: 10})
+		require.Len(t, f.mu.ready, 1)
+		require.Equal(t, pgrepl.LSN(9), f.mu.ready[0].ev.Checkpoint)
+
+		f.onFrontierAdvance(ctx, hlc.Timestamp{WallTime: 21})
+		var txns []*pgrepl.Transaction
+		for _, r := range f.mu.ready {
+			if r.ev.Txn != nil {
+				txns = append(txns, r.ev.Txn)
+			}
+		}
+		require.Len(t, txns, 2)
+		require.Equal(t, pgrepl.LSN(10), txns[0].LSN)
+		require.Equal(t, []pgrepl.Change{change(1), change(2)}, txns[0].Changes)
+		require.Equal(t, pgrepl.LSN(20), txns[1].LSN)
+		require.Equal(t, []pgrepl.Change{change(3), change(4)}, txns[1].Changes)
+		require.Equal(t, pgrepl.LSN(20), f.mu.ready[len(f.mu.ready)-1].ev.Checkpoint)
+
+		// The remaining change is still pending.
+		require.Len(t, f.pending, 1)
+		require.Len(t, f.seen, 1)
+	})
+
+	t.Run("full", func(t *testing.T) {
+		f := makeFeed(pendingChangeSize(pendingChangeKey{key: "a"}, change(1)))
+		defer f.releaseBuffer(ctx)
+
+		add(f, "a", 10, 0, 1)
+		require.False(t, f.failed)
+		add(f, "b", 10, 0, 2)
+		require.True(t, f.failed)
+		require.Len(t, f.pending, 1)
+		require.Len(t, f.mu.ready, 1)
+		require.Regexp(t, "the client is not keeping up", f.mu.ready[0].ev.Err)
+	})
+}
@@ -15,16 +15,20 @@ import (
 	"strconv"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 )
 
 // EvalRoutineExpr returns the result of evaluating the routine. It calls the
 // routine's PlanFn to generate a plan for each statement in the routine, then
 // runs the plans. The resulting value of the last statement in the routine is
-// returned.
+// returned, unless the routine has a program that determines the statements to
+// run and the result.
 func (p *planner) EvalRoutineExpr(
 	ctx context.Context, expr *tree.RoutineExpr, input tree.Datums,
 ) (result tree.Datum, err error) {
@@ -38,75 +42,60 @@ func (p *planner) EvalRoutineExpr(
 		}
 	}
 
-	retTypes := []*types.T{expr.ResolvedType()}
-
-	// The result of the routine is the result of the last statement. The result
-	// of any preceding statements is ignored. We set up a rowResultWriter that
-	// can store the results of the final statement here.
-	var rch rowContainerHelper
-	rch.Init(ctx, retTypes, p.ExtendedEvalContext(), "routine" /* opName */)
-	defer rch.Close(ctx)
-	rrw := NewRowResultWriter(&rch)
-
 	// Configure stepping for volatile routines so that mutations made by the
 	// invoking statement are visible to the routine.
-	txn := p.Txn()
 	if expr.Volatility == volatility.Volatile {
-		prevSteppingMode := txn.ConfigureStepping(ctx, kv.SteppingEnabled)
-		prevSeqNum := txn.GetLeafTxnInputState(ctx).ReadSeqNum
+		restore := p.enableRoutineStepping(ctx)
 		defer func() {
 			// If the routine errored, the transaction should be aborted, so
 			// there is no need to reconfigure stepping or revert to the
 			// original sequence number.
 			if err == nil {
-				_ = p.Txn().ConfigureStepping(ctx, prevSteppingMode)
-				err = txn.SetReadSeqNum(prevSeqNum)
+				err = restore()
 			}
 		}()
 	}
 
-	// Execute each statement in the routine sequentially.
 	ef := newExecFactory(ctx, p)
-	for i := 0; i < expr.NumStmts; i++ {
-		if err := func() error {
-			opName := "udf-stmt-" + expr.Name + "-" + strconv.Itoa(i)
-			ctx, sp := tracing.ChildSpan(ctx, opName)
-			defer sp.Finish()
-
-			// Generate a plan for executing the ith statement.
-			plan, err := expr.PlanFn(ctx, ef, i, input)
-			if err != nil {
-				return err
-			}
 
-			// If this is the last statement, use the rowResultWriter created above.
-			// Otherwise, use a rowResultWriter that drops all rows added to it.
-			var w rowResultWriter
-			if i == expr.NumStmts-1 {
-				w = rrw
-			} else {
-				w = &droppingResultWriter{}
-			}
+	// The statements of a routine written in a procedural language are
+	// executed as directed by its program.
+	if expr.Program != nil {
+		return p.evalRoutineProgram(ctx, expr, ef, input, nil /* result */)
+	}
 
-			// Place a sequence point before each statement in the routine for
-			// volatile functions.
-			if expr.Volatility == volatility.Volatile {
-				if err := txn.Step(ctx); err != nil {
-					return err
-				}
-			}
+	retTypes := []*types.T{expr.ResolvedType()}
 
-			// Run the plan.
-			err = runPlanInsidePlan(ctx, p.RunParams(ctx), plan.(*planComponents), w)
-			if err != nil {
-				return err
-			}
+	// The result of the routine is the result of the last statement. The result
+	// of any preceding statements is ignored. We set up a rowResultWriter that
+	// can store the results of the final statement here.
+	var rch rowContainerHelper
+	rch.Init(ctx, retTypes, p.ExtendedEvalContext(), "routine" /* opName */)
+	defer rch.Close(ctx)
+	rrw := NewRowResultWriter(&rch)
+
+	// The result of a routine that returns void, such as a procedure without
+	// OUT arguments, is not the result of its last statement, which may not
+	// return any columns at all.
+	isVoid := expr.ResolvedType().Family() == types.VoidFamily
 
-			return nil
-		}(); err != nil {
+	// Execute each statement in the routine sequentially.
+	for i := 0; i < expr.NumStmts; i++ {
+		// If this is the last statement, use the rowResultWriter created above.
+		// Otherwise, use a rowResultWriter that drops all rows added to it.
+		var w rowResultWriter
+		if i == expr.NumStmts-1 && !isVoid {
+			w = rrw
+		} else {
+			w = &droppingResultWriter{}
+		}
+		if err := p.runRoutineStmt(ctx, expr, ef, i, input, w); err != nil {
 			return nil, err
 		}
 	}
+	if isVoid {
+		return tree.DVoidDatum, nil
+	}
 
 	// Fetch the first row from the row container and return the first
 	// datum.
@@ -123,6 +112,150 @@ func (p *planner) EvalRoutineExpr(
 	return res[0], nil
 }
 
+// enableRoutineStepping enables stepping of the transaction so that the
+// statements of a volatile routine see the mutations made by the invoking
+// statement and by each other. The returned function restores the previous
+// stepping mode and read sequence number.
+func (p *planner) enableRoutineStepping(ctx context.Context) (restore func() error) {
+	txn := p.Txn()
+	prevSteppingMode := txn.ConfigureStepping(ctx, kv.SteppingEnabled)
+	prevSeqNum := txn.GetLeafTxnInputState(ctx).ReadSeqNum
+	return func() error {
+		_ = txn.ConfigureStepping(ctx, prevSteppingMode)
+		return txn.SetReadSeqNum(prevSeqNum)
+	}
+}
+
+// RoutineExprGenerator returns a generator of the rows of a set-returning
+// routine. The routine is run when the generator is started, and its rows are
+// buffered until they are consumed.
+func (p *planner) RoutineExprGenerator(
+	ctx context.Context, expr *tree.RoutineExpr, input tree.Datums,
+) (eval.ValueGenerator, error) {
+	if expr.Program == nil {
+		return nil, errors.AssertionFailedf(
+			"set-returning routine %s must have a program", expr.Name,
+		)
+	}
+	return &routineGenerator{p: p, expr: expr, input: input}, nil
+}
+
+// routineGenerator is a ValueGenerator that produces the rows of a
+// set-returning routine.
+type routineGenerator struct {
+	p     *planner
+	expr  *tree.RoutineExpr
+	input tree.Datums
+
+	rch     rowContainerHelper
+	rchInit bool
+	iter    *rowContainerIterator
+	row     tree.Datums
+}
+
+var _ eval.ValueGenerator = &routineGenerator{}
+
+// ResolvedType is part of the eval.ValueGenerator interface.
+func (g *routineGenerator) ResolvedType() *types.T {
+	return types.MakeTuple([]*types.T{g.expr.ResolvedType()})
+}
+
+// Start is part of the eval.ValueGenerator interface.
+func (g *routineGenerator) Start(ctx context.Context, _ *kv.Txn) (err error) {
+	// Release the rows of a previous run if the generator is restarted.
+	g.Close(ctx)
+	typs := []*types.T{g.expr.ResolvedType()}
+	g.rch.Init(ctx, typs, g.p.ExtendedEvalContext(), "routine-generator" /* opName */)
+	g.rchInit = true
+
+	// If the routine should not be called on null input, then it returns no
+	// rows if any of the datums in the input are NULL.
+	if !g.expr.CalledOnNullInput {
+		for i := range g.input {
+			if g.input[i] == tree.DNull {
+				g.iter = newRowContainerIterator(ctx, g.rch, typs)
+				return nil
+			}
+		}
+	}
+
+	if g.expr.Volatility == volatility.Volatile {
+		restore := g.p.enableRoutineStepping(ctx)
+		defer func() {
+			if err == nil {
+				err = restore()
+			}
+		}()
+	}
+	ef := newExecFactory(ctx, g.p)
+	if _, err := g.p.evalRoutineProgram(
+		ctx, g.expr, ef, g.input, NewRowResultWriter(&g.rch),
+	); err != nil {
+		return err
+	}
+	g.iter = newRowContainerIterator(ctx, g.rch, typs)
+	return nil
+}
+
+// Next is part of the eval.ValueGenerator interface.
+func (g *routineGenerator) Next(context.Context) (bool, error) {
+	row, err := g.iter.Next()
+	if err != nil || row == nil {
+		return false, err
+	}
+	g.row = row
+	return true, nil
+}
+
+// Values is part of the eval.ValueGenerator interface.
+func (g *routineGenerator) Values() (tree.Datums, error) {
+	return g.row, nil
+}
+
+// Close is part of the eval.ValueGenerator interface.
+func (g *routineGenerator) Close(ctx context.Context) {
+	if g.iter != nil {
+		g.iter.Close()
+		g.iter = nil
+	}
+	if g.rchInit {
+		g.rch.Close(ctx)
+		g.rchInit = false
+	}
+}
+
+// runRoutineStmt plans the ith statement of the routine with the given input
+// and runs it, adding the rows it returns to w.
+func (p *planner) runRoutineStmt(
+	ctx context.Context,
+	expr *tree.RoutineExpr,
+	ef exec.Factory,
+	i int,
+	input tree.Datums,
+	w rowResultWriter,
+) error {
+	opName := "udf-stmt-" + expr.Name + "-" + strconv.Itoa(i)
+	ctx, sp := tracing.ChildSpan(ctx, opName)
+	defer sp.Finish()
+
+	// Generate a plan for executing the ith statement.
+	plan, err := expr.PlanFn(ctx, ef, i, input)
+	if err != nil {
+		return err
+	}
+
+	// Place a sequence point before each statement in the routine for
+	// volatile functions.
+	if expr.Volatility == volatility.Volatile {
+		if err := p.Txn().Step(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Run the plan.
+	return runPlanInsidePlan(ctx, p.RunParams(ctx), plan.(*planComponents), w)
+}
+
 // droppingResultWriter drops all rows that are added to it. It only tracks
 // errors with the SetError and Err functions.
 type droppingResultWriter struct {
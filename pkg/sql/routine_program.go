@@ -0,0 +1,529 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// routineProgramRun is the state of an execution of the program of a routine.
+type routineProgramRun struct {
+	p    *planner
+	expr *tree.RoutineExpr
+	prog *tree.RoutineProgram
+	ef   exec.Factory
+
+	// vars are the current values of the variables of the program. They are
+	// the input of every statement.
+	vars tree.Datums
+
+	// cursors buffer the rows of the statements that are iterated over.
+	cursors []routineCursor
+
+	// numOpened is the number of times a cursor was opened.
+	numOpened int
+
+	// result receives the rows added to the result of a set-returning routine
+	// by RoutineReturnQuery instructions.
+	result rowResultWriter
+
+	// handlers is the stack of installed error handlers, innermost last.
+	handlers []routineHandlerFrame
+
+	// handling is the error that is being handled, which is raised again by a
+	// RAISE instruction without a level.
+	handling error
+}
+
+// routineCursor is a cursor of a routine program.
+type routineCursor struct {
+	rows rowContainerHelper
+	iter *rowContainerIterator
+	// openOrd is the value of numOpened when the cursor was last opened.
+	openOrd int
+}
+
+// routineHandlerFrame is a set of error handlers installed by a
+// RoutineBeginHandlers instruction.
+//
+// If an error is raised, the changes made since the handlers were installed
+// are undone: the KV writes are rolled back to savepoint, the state of the SQL
+// transaction is restored from txnState, and the cursors opened since then
+// (numOpened) are closed.
+type routineHandlerFrame struct {
+	instr     *tree.RoutineInstr
+	savepoint kv.SavepointToken
+	txnState  sqlTxnSavepoint
+	numOpened int
+}
+
+// sqlTxnSavepoint records the state of the SQL transaction which is not stored
+// in KV, and which is rolled back along with a KV savepoint.
+type sqlTxnSavepoint struct {
+	notifications       notificationsSavepoint
+	deferredConstraints txnDeferredConstraints
+}
+
+// createSQLTxnSavepoint records the state of the SQL transaction, to be
+// restored by rollbackToSQLTxnSavepoint.
+func (p *planner) createSQLTxnSavepoint() sqlTxnSavepoint {
+	var sp sqlTxnSavepoint
+	if p.extendedEvalCtx.Notifications != nil {
+		sp.notifications = p.extendedEvalCtx.Notifications.notificationsSavepoint()
+	}
+	if p.extendedEvalCtx.DeferredConstraints != nil {
+		sp.deferredConstraints = p.extendedEvalCtx.DeferredConstraints.deferredConstraintsSavepoint()
+	}
+	return sp
+}
+
+// rollbackToSQLTxnSavepoint restores the state of the SQL transaction recorded
+// by createSQLTxnSavepoint: the notifications queued since then are discarded,
+// and the state of the deferred constraints is restored.
+func (p *planner) rollbackToSQLTxnSavepoint(sp sqlTxnSavepoint) {
+	if p.extendedEvalCtx.Notifications != nil {
+		p.extendedEvalCtx.Notifications.rollbackNotifications(sp.notifications)
+	}
+	if p.extendedEvalCtx.DeferredConstraints != nil {
+		p.extendedEvalCtx.DeferredConstraints.rollbackDeferredConstraints(sp.deferredConstraints)
+	}
+}
+
+// evalRoutineProgram evaluates a routine by interpreting its program. If the
+// routine returns a set, its rows are added to result and the returned datum
+// is nil.
+func (p *planner) evalRoutineProgram(
+	ctx context.Context,
+	expr *tree.RoutineExpr,
+	ef exec.Factory,
+	input tree.Datums,
+	result rowResultWriter,
+) (tree.Datum, error) {
+	r := routineProgramRun{
+		p:       p,
+		expr:    expr,
+		prog:    expr.Program,
+		ef:      ef,
+		vars:    make(tree.Datums, len(expr.Program.Vars)),
+		cursors: make([]routineCursor, expr.Program.NumCursors),
+		result:  result,
+	}
+	copy(r.vars, input)
+	for i := len(input); i < len(r.vars); i++ {
+		r.vars[i] = tree.DNull
+	}
+	defer r.close(ctx)
+	return r.run(ctx)
+}
+
+// run interprets the program and returns the result of the routine.
+func (r *routineProgramRun) run(ctx context.Context) (tree.Datum, error) {
+	pc := 0
+	for pc < len(r.prog.Instrs) {
+		next, done, res, err := r.step(ctx, pc)
+		if err != nil {
+			if next, err = r.handleError(ctx, err); err != nil {
+				return nil, err
+			}
+		} else if done {
+			if err := r.releaseHandlers(ctx); err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+		pc = next
+	}
+	if err := r.releaseHandlers(ctx); err != nil {
+		return nil, err
+	}
+	// A set-returning routine may end without RETURN.
+	if r.expr.Generator {
+		return nil, nil
+	}
+	if r.expr.ResolvedType().Family() == types.VoidFamily {
+		return tree.DVoidDatum, nil
+	}
+	return nil, pgerror.New(pgcode.RoutineExceptionFunctionExecutedNoReturnStatement,
+		"control reached end of function without RETURN")
+}
+
+// step executes the instruction at pc. It returns the index of the next
+// instruction to execute, or done=true and the result of the routine if the
+// instruction ends the execution.
+func (r *routineProgramRun) step(
+	ctx context.Context, pc int,
+) (next int, done bool, res tree.Datum, _ error) {
+	instr := &r.prog.Instrs[pc]
+	next = pc + 1
+	switch instr.Op {
+	case tree.RoutineAssign:
+		if instr.Stmt < 0 {
+			return next, false, nil, r.assign(instr.Vars, nil /* row */)
+		}
+		var w firstRowWriter
+		if err := r.runStmt(ctx, instr.Stmt, &w); err != nil {
+			return 0, false, nil, err
+		}
+		if instr.Strict {
+			if w.numRows == 0 {
+				return 0, false, nil, pgerror.New(pgcode.NoDataFound, "query returned no rows")
+			}
+			if w.numRows > 1 {
+				return 0, false, nil, pgerror.New(pgcode.TooManyRows, "query returned more than one row")
+			}
+		}
+		return next, false, nil, r.assign(instr.Vars, w.row)
+
+	case tree.RoutineExec:
+		return next, false, nil, r.runStmt(ctx, instr.Stmt, &droppingResultWriter{})
+
+	case tree.RoutineJump:
+		return instr.Target, false, nil, nil
+
+	case tree.RoutineJumpIfNot:
+		var w firstRowWriter
+		if err := r.runStmt(ctx, instr.Stmt, &w); err != nil {
+			return 0, false, nil, err
+		}
+		if w.numRows == 0 || w.row[0] != tree.DBoolTrue {
+			return instr.Target, false, nil, nil
+		}
+		return next, false, nil, nil
+
+	case tree.RoutineOpenCursor:
+		c := &r.cursors[instr.Cursor]
+		c.close(ctx)
+		typs := make([]*types.T, len(instr.Vars))
+		for i, ord := range instr.Vars {
+			typs[i] = r.prog.Vars[ord].Typ
+		}
+		c.rows.Init(ctx, typs, r.p.ExtendedEvalContext(), "routine-cursor" /* opName */)
+		c.openOrd = r.numOpened
+		r.numOpened++
+		if err := r.runStmt(ctx, instr.Stmt, NewRowResultWriter(&c.rows)); err != nil {
+			return 0, false, nil, err
+		}
+		c.iter = newRowContainerIterator(ctx, c.rows, typs)
+		return next, false, nil, nil
+
+	case tree.RoutineFetch:
+		row, err := r.cursors[instr.Cursor].iter.Next()
+		if err != nil {
+			return 0, false, nil, err
+		}
+		if row == nil {
+			return instr.Target, false, nil, nil
+		}
+		return next, false, nil, r.assign(instr.Vars, row)
+
+	case tree.RoutineCloseCursor:
+		r.cursors[instr.Cursor].close(ctx)
+		return next, false, nil, nil
+
+	case tree.RoutineReturn:
+		if instr.Stmt < 0 {
+			if r.expr.Generator {
+				return 0, true, nil, nil
+			}
+			if r.expr.ResolvedType().Family() == types.VoidFamily {
+				return 0, true, tree.DVoidDatum, nil
+			}
+			return 0, true, tree.DNull, nil
+		}
+		var w firstRowWriter
+		if err := r.runStmt(ctx, instr.Stmt, &w); err != nil {
+			return 0, false, nil, err
+		}
+		if w.numRows == 0 {
+			return 0, true, tree.DNull, nil
+		}
+		return 0, true, w.row[0], nil
+
+	case tree.RoutineReturnQuery:
+		if r.result == nil {
+			return 0, false, nil, errors.AssertionFailedf(
+				"RETURN QUERY in routine %s that does not return a set", r.expr.Name,
+			)
+		}
+		return next, false, nil, r.runStmt(ctx, instr.Stmt, r.result)
+
+	case tree.RoutineRaise:
+		return next, false, nil, r.raise(ctx, instr)
+
+	case tree.RoutineBeginHandlers:
+		savepoint, err := r.p.Txn().CreateSavepoint(ctx)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		r.handlers = append(r.handlers, routineHandlerFrame{
+			instr:     instr,
+			savepoint: savepoint,
+			txnState:  r.p.createSQLTxnSavepoint(),
+			numOpened: r.numOpened,
+		})
+		return next, false, nil, nil
+
+	case tree.RoutineEndHandlers:
+		frame := r.handlers[len(r.handlers)-1]
+		r.handlers = r.handlers[:len(r.handlers)-1]
+		return next, false, nil, r.p.Txn().ReleaseSavepoint(ctx, frame.savepoint)
+
+	default:
+		return 0, false, nil, errors.AssertionFailedf("unexpected routine instruction %d", instr.Op)
+	}
+}
+
+// runStmt runs the given statement of the routine with the current values of
+// the variables as input.
+func (r *routineProgramRun) runStmt(ctx context.Context, stmt int, w rowResultWriter) error {
+	return r.p.runRoutineStmt(ctx, r.expr, r.ef, stmt, r.vars, w)
+}
+
+// assign assigns the values of row to the given variables. If row is nil, the
+// variables are set to NULL.
+func (r *routineProgramRun) assign(vars []int, row tree.Datums) error {
+	for i, ord := range vars {
+		val := tree.Datum(tree.DNull)
+		if row != nil {
+			val = row[i]
+		}
+		if val == tree.DNull && r.prog.Vars[ord].NotNull {
+			return pgerror.Newf(pgcode.NullValueNotAllowed,
+				"null value cannot be assigned to variable \"%s\" declared NOT NULL",
+				r.prog.Vars[ord].Name)
+		}
+		r.vars[ord] = val
+	}
+	return nil
+}
+
+// handleError handles an error raised while executing an instruction. The
+// installed handlers are uninstalled, innermost first, and the changes made
+// since they were installed are rolled back, until a handler that matches the
+// error is found. It returns the index of the first instruction of the
+// handler, or the error if no handler matches.
+func (r *routineProgramRun) handleError(ctx context.Context, err error) (next int, _ error) {
+	if errIsRetriable(err) {
+		return 0, err
+	}
+	code := pgerror.GetPGCode(err).String()
+	for len(r.handlers) > 0 {
+		frame := r.handlers[len(r.handlers)-1]
+		r.handlers = r.handlers[:len(r.handlers)-1]
+		if rollbackErr := r.p.Txn().RollbackToSavepoint(ctx, frame.savepoint); rollbackErr != nil {
+			return 0, errors.CombineErrors(err, rollbackErr)
+		}
+		r.p.rollbackToSQLTxnSavepoint(frame.txnState)
+		for i := range r.cursors {
+			if c := &r.cursors[i]; c.openOrd >= frame.numOpened {
+				c.close(ctx)
+			}
+		}
+		for _, handler := range frame.instr.Handlers {
+			if !routineHandlerMatches(handler, code) {
+				continue
+			}
+			flat := pgerror.Flatten(err)
+			r.vars[frame.instr.Vars[0]] = tree.NewDString(code)
+			r.vars[frame.instr.Vars[1]] = tree.NewDString(flat.Message)
+			r.handling = err
+			return handler.Target, nil
+		}
+	}
+	return 0, err
+}
+
+// routineHandlerMatches returns true if the handler matches errors with the
+// given SQLSTATE.
+func routineHandlerMatches(handler tree.RoutineHandler, code string) bool {
+	if len(handler.Codes) == 0 {
+		return code != pgcode.QueryCanceled.String()
+	}
+	for _, c := range handler.Codes {
+		if c == code || (strings.HasSuffix(c, "000") && c[:2] == code[:2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseHandlers uninstalls all handlers, keeping the changes made since they
+// were installed.
+func (r *routineProgramRun) releaseHandlers(ctx context.Context) error {
+	for len(r.handlers) > 0 {
+		frame := r.handlers[len(r.handlers)-1]
+		r.handlers = r.handlers[:len(r.handlers)-1]
+		if err := r.p.Txn().ReleaseSavepoint(ctx, frame.savepoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// raise reports the message of a RoutineRaise instruction, or returns the
+// error it raises.
+func (r *routineProgramRun) raise(ctx context.Context, instr *tree.RoutineInstr) error {
+	raise := instr.Raise
+	if raise.Level == "" {
+		if r.handling == nil {
+			return pgerror.New(pgcode.StackedDiagnosticsAccessedWithoutActiveHandler,
+				"RAISE without parameters cannot be used outside an exception handler")
+		}
+		return r.handling
+	}
+	var row tree.Datums
+	if instr.Stmt >= 0 {
+		var w firstRowWriter
+		if err := r.runStmt(ctx, instr.Stmt, &w); err != nil {
+			return err
+		}
+		row = w.row
+	}
+
+	msg := formatRaiseMessage(raise.Message, row[:raise.NumParams])
+	code := raise.Code
+	var detail, hint string
+	for i, option := range raise.Options {
+		val := row[raise.NumParams+i]
+		if val == tree.DNull {
+			return pgerror.New(pgcode.NullValueNotAllowed, "RAISE statement option cannot be null")
+		}
+		s := string(tree.MustBeDString(val))
+		switch option {
+		case "MESSAGE":
+			msg = s
+		case "DETAIL":
+			detail = s
+		case "HINT":
+			hint = s
+		case "ERRCODE":
+			if len(s) == 5 && strings.Trim(strings.ToUpper(s), "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+				code = strings.ToUpper(s)
+			} else if codes, ok := plpgsqltree.LookupCondition(s); ok {
+				code = codes[0]
+			} else {
+				return pgerror.Newf(pgcode.UndefinedObject, "unrecognized exception condition \"%s\"", s)
+			}
+			if msg == "" {
+				msg = s
+			}
+		}
+	}
+
+	if raise.Level != "EXCEPTION" {
+		notice := pgnotice.NewWithSeverityf(raise.Level, "%s", msg)
+		if detail != "" {
+			notice = pgnotice.Notice(errors.WithDetail(notice, detail))
+		}
+		if hint != "" {
+			notice = pgnotice.Notice(errors.WithHint(notice, hint))
+		}
+		r.p.BufferClientNotice(ctx, notice)
+		return nil
+	}
+	if code == "" {
+		code = pgcode.RaiseException.String()
+	}
+	if msg == "" {
+		msg = code
+	}
+	err := pgerror.Newf(pgcode.MakeCode(code), "%s", msg)
+	if detail != "" {
+		err = errors.WithDetail(err, detail)
+	}
+	if hint != "" {
+		err = errors.WithHint(err, hint)
+	}
+	return err
+}
+
+// formatRaiseMessage replaces each % in the format string of a RAISE statement
+// with the next parameter, and each %% with %.
+func formatRaiseMessage(format string, params tree.Datums) string {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] == '%' {
+			if i+1 < len(format) && format[i+1] == '%' {
+				sb.WriteByte('%')
+				i++
+				continue
+			}
+			if len(params) > 0 {
+				if params[0] == tree.DNull {
+					sb.WriteString("<NULL>")
+				} else {
+					sb.WriteString(string(tree.MustBeDString(params[0])))
+				}
+				params = params[1:]
+				continue
+			}
+		}
+		sb.WriteByte(format[i])
+	}
+	return sb.String()
+}
+
+// close releases the resources of the run.
+func (r *routineProgramRun) close(ctx context.Context) {
+	for i := range r.cursors {
+		r.cursors[i].close(ctx)
+	}
+}
+
+// close releases the rows buffered by the cursor, if it is open.
+func (c *routineCursor) close(ctx context.Context) {
+	if c.iter != nil {
+		c.iter.Close()
+		c.iter = nil
+	}
+	c.rows.Close(ctx)
+}
+
+// firstRowWriter keeps a copy of the first row added to it and counts the
+// rows.
+type firstRowWriter struct {
+	row     tree.Datums
+	numRows int
+	err     error
+}
+
+// AddRow is part of the rowResultWriter interface.
+func (w *firstRowWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	if w.numRows == 0 {
+		w.row = append(tree.Datums(nil), row...)
+	}
+	w.numRows++
+	return nil
+}
+
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (w *firstRowWriter) IncrementRowsAffected(ctx context.Context, n int) {}
+
+// SetError is part of the rowResultWriter interface.
+func (w *firstRowWriter) SetError(err error) {
+	w.err = err
+}
+
+// Err is part of the rowResultWriter interface.
+func (w *firstRowWriter) Err() error {
+	return w.err
+}
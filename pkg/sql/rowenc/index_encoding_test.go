@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1096,3 +1097,90 @@ func TestEncodeTrigramInvertedIndexSpansError(t *testing.T) {
 		require.Error(t, err)
 	}
 }
+
+func TestEncodeTSVectorInvertedIndexSpans(t *testing.T) {
+	testCases := []struct {
+		// The value that's being indexed in the tsvector index.
+		indexedValue string
+		// The tsquery that's being turned into spans to search with.
+		query string
+		// Whether we expect that the spans should contain all of the keys produced
+		// by indexing the indexedValue.
+		containsKeys bool
+		// Whether we expect that the indexed value should match the query.
+		expected bool
+	}{
+		{`a b c`, `a`, true, true},
+		{`a b c`, `d`, false, false},
+		{`a b c`, `a & b`, true, true},
+		{`a b c`, `a & d`, false, false},
+		{`a b c`, `a | d`, true, true},
+		{`a b c`, `d | e`, false, false},
+		{`abc def`, `ab:*`, true, true},
+		{`abc def`, `ac:*`, false, false},
+		{`a:1A b:2`, `a:A`, true, true},
+		{`a:1A b:2`, `b:A`, true, false},
+		{`a:1 b:2`, `a <-> b`, true, true},
+		{`a:1 b:2`, `b <-> a`, true, false},
+		{`a b`, `a & !b`, true, false},
+		{`a c`, `a & !b`, true, true},
+	}
+
+	runTest := func(vector tsearch.TSVector, query tsearch.TSQuery, expectContainsKeys, expected bool) {
+		t.Logf("test case: %s %s %t %t", vector, query, expectContainsKeys, expected)
+		keys, err := EncodeInvertedIndexTableKeys(
+			tree.NewDTSVector(vector), nil, descpb.LatestIndexDescriptorVersion,
+		)
+		require.NoError(t, err)
+
+		invertedExpr, err := query.GetInvertedExpr()
+		require.NoError(t, err)
+
+		spanExpr, ok := invertedExpr.(*inverted.SpanExpression)
+		if !ok {
+			t.Fatalf("invertedExpr %v is not a SpanExpression", invertedExpr)
+		}
+
+		// Check if the indexed value is included by the spans.
+		containsKeys, err := spanExpr.ContainsKeys(keys)
+		require.NoError(t, err)
+		require.Equal(t, expectContainsKeys, containsKeys, "%s, %s: expected containsKeys", vector, query)
+
+		actual := tsearch.EvalTSQuery(query, vector)
+		require.Equal(t, expected, actual, "%s, %s: expected evaluation result to match", vector, query)
+		if spanExpr.Tight {
+			require.Equal(t, containsKeys, actual, "%s, %s: tight spans must match the evaluation", vector, query)
+		}
+	}
+
+	// Run pre-defined test cases from above.
+	for _, c := range testCases {
+		vector, err := tsearch.ParseTSVector(c.indexedValue)
+		require.NoError(t, err)
+		query, err := tsearch.ParseTSQuery(c.query)
+		require.NoError(t, err)
+		runTest(vector, query, c.containsKeys, c.expected)
+	}
+
+	// Run some random test cases. The spans must contain the keys of every
+	// tsvector which matches the query.
+	rng, _ := randutil.NewTestRand()
+	for i := 0; i < 100; i++ {
+		vector := tsearch.RandomTSVector(rng)
+		query := tsearch.RandomTSQuery(rng)
+		invertedExpr, err := query.GetInvertedExpr()
+		if err != nil {
+			// The query cannot be evaluated using the index.
+			continue
+		}
+		keys, err := EncodeInvertedIndexTableKeys(
+			tree.NewDTSVector(vector), nil, descpb.LatestIndexDescriptorVersion,
+		)
+		require.NoError(t, err)
+		containsKeys, err := invertedExpr.(*inverted.SpanExpression).ContainsKeys(keys)
+		require.NoError(t, err)
+		if tsearch.EvalTSQuery(query, vector) {
+			require.True(t, containsKeys, "%s, %s: expected containsKeys", vector, query)
+		}
+	}
+}
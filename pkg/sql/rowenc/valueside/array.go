@@ -16,6 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
@@ -235,6 +236,10 @@ func DatumTypeToArrayElementEncodingType(t *types.T) (encoding.Type, error) {
 		return encoding.JSON, nil
 	case types.TupleFamily:
 		return encoding.Tuple, nil
+	case types.TSQueryFamily:
+		return encoding.TSQuery, nil
+	case types.TSVectorFamily:
+		return encoding.TSVector, nil
 	default:
 		return 0, errors.AssertionFailedf(
 			"no known encoding type for %s", redact.Safe(t.Family().Name()),
@@ -313,6 +318,10 @@ func encodeArrayElement(b []byte, d tree.Datum) ([]byte, error) {
 			return nil, err
 		}
 		return encoding.EncodeUntaggedBytesValue(b, encoded), nil
+	case *tree.DTSQuery:
+		return encoding.EncodeUntaggedBytesValue(b, tsearch.EncodeTSQuery(nil, t.TSQuery)), nil
+	case *tree.DTSVector:
+		return encoding.EncodeUntaggedBytesValue(b, tsearch.EncodeTSVector(nil, t.TSVector)), nil
 	case *tree.DTuple:
 		return encodeUntaggedTuple(t, b, encoding.NoColumnID, nil)
 	default:
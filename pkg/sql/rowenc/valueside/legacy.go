@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
@@ -141,6 +142,16 @@ func MarshalLegacy(colType *types.T, val tree.Datum) (roachpb.Value, error) {
 			r.SetBytes(data)
 			return r, nil
 		}
+	case types.TSQueryFamily:
+		if v, ok := val.(*tree.DTSQuery); ok {
+			r.SetBytes(tsearch.EncodeTSQuery(nil, v.TSQuery))
+			return r, nil
+		}
+	case types.TSVectorFamily:
+		if v, ok := val.(*tree.DTSVector); ok {
+			r.SetBytes(tsearch.EncodeTSVector(nil, v.TSVector))
+			return r, nil
+		}
 	case types.ArrayFamily:
 		if v, ok := val.(*tree.DArray); ok {
 			if err := checkElementType(v.ParamTyp, colType.ArrayContents()); err != nil {
@@ -347,6 +358,26 @@ func UnmarshalLegacy(a *tree.DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return tree.NewDJSON(jsonDatum), nil
+	case types.TSQueryFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		q, err := tsearch.DecodeTSQuery(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDTSQuery(q), nil
+	case types.TSVectorFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		vec, err := tsearch.DecodeTSVector(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDTSVector(vec), nil
 	case types.EnumFamily:
 		v, err := value.GetBytes()
 		if err != nil {
@@ -269,6 +269,15 @@ func NewProcessor(
 		}
 		return NewRestoreDataProcessor(ctx, flowCtx, processorID, *core.RestoreData, post, inputs[0], outputs[0])
 	}
+	if core.VerifyBackupData != nil {
+		if err := checkNumInOut(inputs, outputs, 0, 1); err != nil {
+			return nil, err
+		}
+		if NewVerifyBackupDataProcessor == nil {
+			return nil, errors.New("VerifyBackupData processor unimplemented")
+		}
+		return NewVerifyBackupDataProcessor(ctx, flowCtx, processorID, *core.VerifyBackupData, post, outputs[0])
+	}
 	if core.StreamIngestionData != nil {
 		if err := checkNumInOut(inputs, outputs, 0, 1); err != nil {
 			return nil, err
@@ -385,6 +394,9 @@ var NewSplitAndScatterProcessor func(context.Context, *execinfra.FlowCtx, int32,
 // NewRestoreDataProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
 var NewRestoreDataProcessor func(context.Context, *execinfra.FlowCtx, int32, execinfrapb.RestoreDataSpec, *execinfrapb.PostProcessSpec, execinfra.RowSource, execinfra.RowReceiver) (execinfra.Processor, error)
 
+// NewVerifyBackupDataProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
+var NewVerifyBackupDataProcessor func(context.Context, *execinfra.FlowCtx, int32, execinfrapb.VerifyBackupDataSpec, *execinfrapb.PostProcessSpec, execinfra.RowReceiver) (execinfra.Processor, error)
+
 // NewStreamIngestionDataProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
 var NewStreamIngestionDataProcessor func(context.Context, *execinfra.FlowCtx, int32, execinfrapb.StreamIngestionDataSpec, *execinfrapb.PostProcessSpec, execinfra.RowReceiver) (execinfra.Processor, error)
 
@@ -43,6 +43,12 @@ type projectSetProcessor struct {
 	// The size of the slice is the same as `exprHelpers` though.
 	funcs []*tree.FuncExpr
 
+	// routines contains a valid pointer to a set-returning RoutineExpr for
+	// every entry in `exprHelpers` that is an invocation of a user-defined
+	// function returning SETOF. The size of the slice is the same as
+	// `exprHelpers`.
+	routines []*tree.RoutineExpr
+
 	// mustBeStreaming indicates whether at least one function in funcs is of
 	// "streaming" nature.
 	mustBeStreaming bool
@@ -55,7 +61,7 @@ type projectSetProcessor struct {
 	rowBuffer rowenc.EncDatumRow
 
 	// gens contains the current "active" ValueGenerators for each entry
-	// in `funcs`. They are initialized anew for every new row in the source.
+	// in `funcs` and `routines`. They are initialized anew for every new row in the source.
 	gens []eval.ValueGenerator
 
 	// done indicates for each `Expr` whether the values produced by
@@ -87,6 +93,7 @@ func newProjectSetProcessor(
 		spec:        spec,
 		exprHelpers: make([]*execinfrapb.ExprHelper, len(spec.Exprs)),
 		funcs:       make([]*tree.FuncExpr, len(spec.Exprs)),
+		routines:    make([]*tree.RoutineExpr, len(spec.Exprs)),
 		rowBuffer:   make(rowenc.EncDatumRow, len(outputTypes)),
 		gens:        make([]eval.ValueGenerator, len(spec.Exprs)),
 		done:        make([]bool, len(spec.Exprs)),
@@ -123,6 +130,9 @@ func newProjectSetProcessor(
 			// Expr is a set-generating function.
 			ps.funcs[i] = tFunc
 			ps.mustBeStreaming = ps.mustBeStreaming || tFunc.IsVectorizeStreaming()
+		} else if tRoutine, ok := helper.Expr.(*tree.RoutineExpr); ok && tRoutine.Generator {
+			// Expr is a set-returning user-defined function.
+			ps.routines[i] = tRoutine
 		}
 		ps.exprHelpers[i] = &helper
 	}
@@ -155,7 +165,7 @@ func (ps *projectSetProcessor) nextInputRow() (
 
 	// Initialize a round of SRF generators or scalar values.
 	for i := range ps.exprHelpers {
-		if fn := ps.funcs[i]; fn != nil {
+		if fn, routine := ps.funcs[i], ps.routines[i]; fn != nil || routine != nil {
 			// A set-generating function. Prepare its ValueGenerator.
 
 			// First, make sure to close its ValueGenerator from the previous
@@ -169,7 +179,13 @@ func (ps *projectSetProcessor) nextInputRow() (
 			ps.exprHelpers[i].Row = row
 
 			ps.EvalCtx.IVarContainer = ps.exprHelpers[i]
-			gen, err := eval.GetGenerator(ps.Ctx, ps.EvalCtx, fn)
+			var gen eval.ValueGenerator
+			var err error
+			if fn != nil {
+				gen, err = eval.GetGenerator(ps.Ctx, ps.EvalCtx, fn)
+			} else {
+				gen, err = eval.GetRoutineGenerator(ps.Ctx, ps.EvalCtx, routine)
+			}
 			if err != nil {
 				return nil, nil, err
 			}
@@ -657,6 +657,8 @@ func (b *builderState) ResolveEnumType(
 	case descpb.TypeDescriptor_ENUM:
 		b.ensureDescriptor(typ.GetID())
 		b.mustOwn(typ.GetID())
+	case descpb.TypeDescriptor_DOMAIN:
+		panic(scerrors.NotImplementedErrorf(nil /* n */, "domain type %q", typ.GetName()))
 	case descpb.TypeDescriptor_TABLE_IMPLICIT_RECORD_TYPE:
 		// Implicit record types are not directly modifiable.
 		panic(pgerror.Newf(pgcode.DependentObjectsStillExist,
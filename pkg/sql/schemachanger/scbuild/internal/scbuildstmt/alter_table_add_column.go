@@ -139,6 +139,9 @@ func alterTableAddColumn(
 	}
 
 	spec.colType.TypeT = b.ResolveTypeRef(d.Type)
+	if spec.colType.TypeT.Type.IsDomain() {
+		panic(scerrors.NotImplementedErrorf(d, "column of a domain type"))
+	}
 	if spec.colType.TypeT.Type.UserDefined() {
 		typeID, err := typedesc.UserDefinedTypeOIDToID(spec.colType.TypeT.Type.Oid())
 		if err != nil {
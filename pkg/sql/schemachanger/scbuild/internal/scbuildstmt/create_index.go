@@ -44,6 +44,12 @@ func CreateIndex(b BuildCtx, n *tree.CreateIndex) {
 	if _, _, tbl := scpb.FindTable(relationElements); tbl != nil {
 		fallBackIfZoneConfigExists(b, n, tbl.TableID)
 	}
+	// The index elements don't carry the SST options of an index.
+	for _, param := range n.StorageParams {
+		if key := string(param.Key); key == "compression" || key == "block_size" {
+			panic(scerrors.NotImplementedErrorf(n, "storage parameter %q", key))
+		}
+	}
 	index := scpb.Index{
 		IsUnique:       n.Unique,
 		IsInverted:     n.Inverted,
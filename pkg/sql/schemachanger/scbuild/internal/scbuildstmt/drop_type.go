@@ -27,6 +27,9 @@ func DropType(b BuildCtx, n *tree.DropType) {
 	if n.DropBehavior == tree.DropCascade {
 		panic(scerrors.NotImplementedErrorf(n, "DROP TYPE CASCADE is not yet supported"))
 	}
+	if n.IsDomain {
+		panic(scerrors.NotImplementedErrorf(n, "DROP DOMAIN is not yet supported"))
+	}
 	var toCheckBackrefs []catid.DescID
 	arrayTypesToAlsoCheck := make(map[catid.DescID]catid.DescID)
 	for _, name := range n.Names {
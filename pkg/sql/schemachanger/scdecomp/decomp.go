@@ -177,6 +177,19 @@ func (w *walkCtx) walkType(typ catalog.TypeDescriptor) {
 			TypeID: typ.GetID(),
 			TypeT:  *typeT,
 		})
+	case descpb.TypeDescriptor_DOMAIN:
+		// Domains are not supported by the declarative schema changer beyond
+		// being dropped along with their parent schema or database, for which
+		// they can be represented like an alias of their base type.
+		typeT, err := newTypeT(typ.TypeDesc().DomainBaseType)
+		if err != nil {
+			panic(errors.NewAssertionErrorWithWrappedErrf(err, "domain type %q (%d)",
+				typ.GetName(), typ.GetID()))
+		}
+		w.ev(descriptorStatus(typ), &scpb.AliasType{
+			TypeID: typ.GetID(),
+			TypeT:  *typeT,
+		})
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		w.ev(descriptorStatus(typ), &scpb.EnumType{
 			TypeID:        typ.GetID(),
@@ -203,6 +216,11 @@ func (w *walkCtx) walkType(typ catalog.TypeDescriptor) {
 }
 
 func (w *walkCtx) walkRelation(tbl catalog.TableDescriptor) {
+	// Fall back to legacy schema changer if the table has any triggers, since
+	// their function back-references are not tracked by any element.
+	if len(tbl.GetTriggers()) > 0 {
+		panic(scerrors.NotImplementedErrorf(nil, "triggers not supported in declarative schema changer"))
+	}
 	switch {
 	case tbl.IsSequence():
 		w.ev(descriptorStatus(tbl), &scpb.Sequence{
@@ -465,6 +483,11 @@ func (w *walkCtx) walkColumn(tbl catalog.TableDescriptor, col catalog.Column) {
 }
 
 func (w *walkCtx) walkIndex(tbl catalog.TableDescriptor, idx catalog.Index) {
+	// Fall back to legacy schema changer if the index backs an exclusion
+	// constraint, since no element records this.
+	if idx.IsExclusion() {
+		panic(scerrors.NotImplementedErrorf(nil, "exclusion constraints not supported in declarative schema changer"))
+	}
 	onErrPanic := func(err error) {
 		if err == nil {
 			return
@@ -88,6 +88,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_agg(arg1: timetz) -> timetz[]`:                                                                 18,
 	`array_agg(arg1: jsonb) -> jsonb[]`:                                                                   19,
 	`array_agg(arg1: varbit) -> varbit[]`:                                                                 20,
+	`array_agg(arg1: tsquery) -> tsquery[]`:                                                               2038,
+	`array_agg(arg1: tsvector) -> tsvector[]`:                                                             2039,
 	`array_agg(arg1: anyenum) -> anyenum[]`:                                                               21,
 	`array_agg(arg1: tuple) -> tuple[]`:                                                                   22,
 	`array_append(array: bool[], elem: bool) -> bool[]`:                                                   1082,
@@ -110,6 +112,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_append(array: timetz[], elem: timetz) -> timetz[]`:                                             1099,
 	`array_append(array: jsonb[], elem: jsonb) -> jsonb[]`:                                                1100,
 	`array_append(array: varbit[], elem: varbit) -> varbit[]`:                                             1101,
+	`array_append(array: tsquery[], elem: tsquery) -> tsquery[]`:                                          2040,
+	`array_append(array: tsvector[], elem: tsvector) -> tsvector[]`:                                       2041,
 	`array_append(array: anyenum[], elem: anyenum) -> anyenum[]`:                                          1102,
 	`array_append(array: tuple[], elem: tuple) -> tuple[]`:                                                1103,
 	`array_cat(left: bool[], right: bool[]) -> bool[]`:                                                    1126,
@@ -132,6 +136,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_cat(left: timetz[], right: timetz[]) -> timetz[]`:                                              1143,
 	`array_cat(left: jsonb[], right: jsonb[]) -> jsonb[]`:                                                 1144,
 	`array_cat(left: varbit[], right: varbit[]) -> varbit[]`:                                              1145,
+	`array_cat(left: tsquery[], right: tsquery[]) -> tsquery[]`:                                           2042,
+	`array_cat(left: tsvector[], right: tsvector[]) -> tsvector[]`:                                        2043,
 	`array_cat(left: anyenum[], right: anyenum[]) -> anyenum[]`:                                           1146,
 	`array_cat(left: tuple[], right: tuple[]) -> tuple[]`:                                                 1147,
 	`array_in(input: anyelement) -> anyelement[]`:                                                         2013,
@@ -158,6 +164,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_position(array: timetz[], elem: timetz) -> int`:                                                1209,
 	`array_position(array: jsonb[], elem: jsonb) -> int`:                                                  1210,
 	`array_position(array: varbit[], elem: varbit) -> int`:                                                1211,
+	`array_position(array: tsquery[], elem: tsquery) -> int`:                                              2044,
+	`array_position(array: tsvector[], elem: tsvector) -> int`:                                            2045,
 	`array_position(array: anyenum[], elem: anyenum) -> int`:                                              1212,
 	`array_position(array: tuple[], elem: tuple) -> int`:                                                  1213,
 	`array_positions(array: bool[], elem: bool) -> int[]`:                                                 1214,
@@ -180,6 +188,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_positions(array: timetz[], elem: timetz) -> int[]`:                                             1231,
 	`array_positions(array: jsonb[], elem: jsonb) -> int[]`:                                               1232,
 	`array_positions(array: varbit[], elem: varbit) -> int[]`:                                             1233,
+	`array_positions(array: tsquery[], elem: tsquery) -> int[]`:                                           2046,
+	`array_positions(array: tsvector[], elem: tsvector) -> int[]`:                                         2047,
 	`array_positions(array: anyenum[], elem: anyenum) -> int[]`:                                           1234,
 	`array_positions(array: tuple[], elem: tuple) -> int[]`:                                               1235,
 	`array_prepend(elem: bool, array: bool[]) -> bool[]`:                                                  1104,
@@ -202,6 +212,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_prepend(elem: timetz, array: timetz[]) -> timetz[]`:                                            1121,
 	`array_prepend(elem: jsonb, array: jsonb[]) -> jsonb[]`:                                               1122,
 	`array_prepend(elem: varbit, array: varbit[]) -> varbit[]`:                                            1123,
+	`array_prepend(elem: tsquery, array: tsquery[]) -> tsquery[]`:                                         2048,
+	`array_prepend(elem: tsvector, array: tsvector[]) -> tsvector[]`:                                      2049,
 	`array_prepend(elem: anyenum, array: anyenum[]) -> anyenum[]`:                                         1124,
 	`array_prepend(elem: tuple, array: tuple[]) -> tuple[]`:                                               1125,
 	`array_recv(input: anyelement) -> anyelement[]`:                                                       2011,
@@ -225,6 +237,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_remove(array: timetz[], elem: timetz) -> anyelement`:                                           1165,
 	`array_remove(array: jsonb[], elem: jsonb) -> anyelement`:                                             1166,
 	`array_remove(array: varbit[], elem: varbit) -> anyelement`:                                           1167,
+	`array_remove(array: tsquery[], elem: tsquery) -> anyelement`:                                         2050,
+	`array_remove(array: tsvector[], elem: tsvector) -> anyelement`:                                       2051,
 	`array_remove(array: anyenum[], elem: anyenum) -> anyelement`:                                         1168,
 	`array_remove(array: tuple[], elem: tuple) -> anyelement`:                                             1169,
 	`array_replace(array: bool[], toreplace: bool, replacewith: bool) -> anyelement`:                      1170,
@@ -247,6 +261,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`array_replace(array: timetz[], toreplace: timetz, replacewith: timetz) -> anyelement`:                1187,
 	`array_replace(array: jsonb[], toreplace: jsonb, replacewith: jsonb) -> anyelement`:                   1188,
 	`array_replace(array: varbit[], toreplace: varbit, replacewith: varbit) -> anyelement`:                1189,
+	`array_replace(array: tsquery[], toreplace: tsquery, replacewith: tsquery) -> anyelement`:             2052,
+	`array_replace(array: tsvector[], toreplace: tsvector, replacewith: tsvector) -> anyelement`:          2053,
 	`array_replace(array: anyenum[], toreplace: anyenum, replacewith: anyenum) -> anyelement`:             1190,
 	`array_replace(array: tuple[], toreplace: tuple, replacewith: tuple) -> anyelement`:                   1191,
 	`array_send(anyelement[]: anyelement[]) -> bytes`:                                                     2010,
@@ -440,6 +456,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`crdb_internal.num_inverted_index_entries(val: jsonb, version: int) -> int`:                                                         1336,
 	`crdb_internal.num_inverted_index_entries(val: string, version: int) -> int`:                                                        1337,
 	`crdb_internal.num_inverted_index_entries(val: anyelement[], version: int) -> int`:                                                  1338,
+	`crdb_internal.num_inverted_index_entries(val: tsvector) -> int`:                                                                    2080,
+	`crdb_internal.num_inverted_index_entries(val: tsvector, version: int) -> int`:                                                      2081,
 	`crdb_internal.payloads_for_span(span_id: int) -> tuple{string AS payload_type, jsonb AS payload_jsonb}`:                            349,
 	`crdb_internal.payloads_for_trace(trace_id: int) -> tuple{int AS span_id, string AS payload_type, jsonb AS payload_jsonb}`:          350,
 	`crdb_internal.pb_to_json(pbname: string, data: bytes) -> jsonb`:                                                                    1270,
@@ -615,6 +633,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`first_value(val: timetz) -> timetz`:                                                                1698,
 	`first_value(val: jsonb) -> jsonb`:                                                                  1699,
 	`first_value(val: varbit) -> varbit`:                                                                1700,
+	`first_value(val: tsquery) -> tsquery`:                                                              2054,
+	`first_value(val: tsvector) -> tsvector`:                                                            2055,
 	`float4in(input: anyelement) -> float4`:                                                             1913,
 	`float4out(float4: float4) -> bytes`:                                                                1912,
 	`float4recv(input: anyelement) -> float4`:                                                           1911,
@@ -868,6 +888,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lag(val: timetz) -> timetz`:                                                                        1578,
 	`lag(val: jsonb) -> jsonb`:                                                                          1579,
 	`lag(val: varbit) -> varbit`:                                                                        1580,
+	`lag(val: tsquery) -> tsquery`:                                                                      2056,
+	`lag(val: tsvector) -> tsvector`:                                                                    2057,
 	`lag(val: bool, n: int) -> bool`:                                                                    1581,
 	`lag(val: box2d, n: int) -> box2d`:                                                                  1582,
 	`lag(val: int, n: int) -> int`:                                                                      1583,
@@ -888,6 +910,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lag(val: timetz, n: int) -> timetz`:                                                                1598,
 	`lag(val: jsonb, n: int) -> jsonb`:                                                                  1599,
 	`lag(val: varbit, n: int) -> varbit`:                                                                1600,
+	`lag(val: tsquery, n: int) -> tsquery`:                                                              2058,
+	`lag(val: tsvector, n: int) -> tsvector`:                                                            2059,
 	`lag(val: bool, n: int, default: bool) -> bool`:                                                     1601,
 	`lag(val: box2d, n: int, default: box2d) -> box2d`:                                                  1602,
 	`lag(val: int, n: int, default: int) -> int`:                                                        1603,
@@ -908,6 +932,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lag(val: timetz, n: int, default: timetz) -> timetz`:                                               1618,
 	`lag(val: jsonb, n: int, default: jsonb) -> jsonb`:                                                  1619,
 	`lag(val: varbit, n: int, default: varbit) -> varbit`:                                               1620,
+	`lag(val: tsquery, n: int, default: tsquery) -> tsquery`:                                            2060,
+	`lag(val: tsvector, n: int, default: tsvector) -> tsvector`:                                         2061,
 	`last_value(val: bool) -> bool`:                                                                     1701,
 	`last_value(val: box2d) -> box2d`:                                                                   1702,
 	`last_value(val: int) -> int`:                                                                       1703,
@@ -928,6 +954,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`last_value(val: timetz) -> timetz`:                                                                 1718,
 	`last_value(val: jsonb) -> jsonb`:                                                                   1719,
 	`last_value(val: varbit) -> varbit`:                                                                 1720,
+	`last_value(val: tsquery) -> tsquery`:                                                               2062,
+	`last_value(val: tsvector) -> tsvector`:                                                             2063,
 	`lastval() -> int`:                                                                                  974,
 	`lead(val: bool) -> bool`:                                                                           1621,
 	`lead(val: box2d) -> box2d`:                                                                         1622,
@@ -949,6 +977,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lead(val: timetz) -> timetz`:                                                                       1638,
 	`lead(val: jsonb) -> jsonb`:                                                                         1639,
 	`lead(val: varbit) -> varbit`:                                                                       1640,
+	`lead(val: tsquery) -> tsquery`:                                                                     2064,
+	`lead(val: tsvector) -> tsvector`:                                                                   2065,
 	`lead(val: bool, n: int) -> bool`:                                                                   1641,
 	`lead(val: box2d, n: int) -> box2d`:                                                                 1642,
 	`lead(val: int, n: int) -> int`:                                                                     1643,
@@ -969,6 +999,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lead(val: timetz, n: int) -> timetz`:                                                               1658,
 	`lead(val: jsonb, n: int) -> jsonb`:                                                                 1659,
 	`lead(val: varbit, n: int) -> varbit`:                                                               1660,
+	`lead(val: tsquery, n: int) -> tsquery`:                                                             2066,
+	`lead(val: tsvector, n: int) -> tsvector`:                                                           2067,
 	`lead(val: bool, n: int, default: bool) -> bool`:                                                    1661,
 	`lead(val: box2d, n: int, default: box2d) -> box2d`:                                                 1662,
 	`lead(val: int, n: int, default: int) -> int`:                                                       1663,
@@ -989,6 +1021,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`lead(val: timetz, n: int, default: timetz) -> timetz`:                                              1678,
 	`lead(val: jsonb, n: int, default: jsonb) -> jsonb`:                                                 1679,
 	`lead(val: varbit, n: int, default: varbit) -> varbit`:                                              1680,
+	`lead(val: tsquery, n: int, default: tsquery) -> tsquery`:                                           2068,
+	`lead(val: tsvector, n: int, default: tsvector) -> tsvector`:                                        2069,
 	`least(anyelement...) -> anyelement`:                                                                982,
 	`left(input: bytes, return_set: int) -> bytes`:                                                      963,
 	`left(input: string, return_set: int) -> string`:                                                    964,
@@ -1042,6 +1076,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`max(arg1: timetz) -> anyelement`:                                                                   187,
 	`max(arg1: jsonb) -> anyelement`:                                                                    188,
 	`max(arg1: varbit) -> anyelement`:                                                                   189,
+	`max(arg1: tsquery) -> anyelement`:                                                                  2070,
+	`max(arg1: tsvector) -> anyelement`:                                                                 2071,
 	`md5(string...) -> string`:                                                                          896,
 	`md5(bytes...) -> string`:                                                                           897,
 	`min(arg1: collatedstring{*}) -> anyelement`:                                                        190,
@@ -1066,6 +1102,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`min(arg1: timetz) -> anyelement`:                                                                   209,
 	`min(arg1: jsonb) -> anyelement`:                                                                    210,
 	`min(arg1: varbit) -> anyelement`:                                                                   211,
+	`min(arg1: tsquery) -> anyelement`:                                                                  2072,
+	`min(arg1: tsvector) -> anyelement`:                                                                 2073,
 	`mod(x: float, y: float) -> float`:                                                                  782,
 	`mod(x: decimal, y: decimal) -> decimal`:                                                            783,
 	`mod(x: int, y: int) -> int`:                                                                        784,
@@ -1102,6 +1140,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`nth_value(val: timetz, n: int) -> timetz`:                                                          1738,
 	`nth_value(val: jsonb, n: int) -> jsonb`:                                                            1739,
 	`nth_value(val: varbit, n: int) -> varbit`:                                                          1740,
+	`nth_value(val: tsquery, n: int) -> tsquery`:                                                        2074,
+	`nth_value(val: tsvector, n: int) -> tsvector`:                                                      2075,
 	`ntile(n: int) -> int`:                                                                              1560,
 	`num_nonnulls(anyelement...) -> int`:                                                                1359,
 	`num_nulls(anyelement...) -> int`:                                                                   1358,
@@ -1176,6 +1216,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`percentile_disc_impl(arg1: float, arg2: timetz) -> timetz`:                                         280,
 	`percentile_disc_impl(arg1: float, arg2: jsonb) -> jsonb`:                                           281,
 	`percentile_disc_impl(arg1: float, arg2: varbit) -> varbit`:                                         282,
+	`percentile_disc_impl(arg1: float, arg2: tsquery) -> tsquery`:                                       2076,
+	`percentile_disc_impl(arg1: float, arg2: tsvector) -> tsvector`:                                     2077,
 	`percentile_disc_impl(arg1: float[], arg2: bool) -> bool[]`:                                         283,
 	`percentile_disc_impl(arg1: float[], arg2: box2d) -> box2d[]`:                                       284,
 	`percentile_disc_impl(arg1: float[], arg2: int) -> int[]`:                                           285,
@@ -1196,6 +1238,8 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`percentile_disc_impl(arg1: float[], arg2: timetz) -> timetz[]`:                                     300,
 	`percentile_disc_impl(arg1: float[], arg2: jsonb) -> jsonb[]`:                                       301,
 	`percentile_disc_impl(arg1: float[], arg2: varbit) -> varbit[]`:                                     302,
+	`percentile_disc_impl(arg1: float[], arg2: tsquery) -> tsquery[]`:                                   2078,
+	`percentile_disc_impl(arg1: float[], arg2: tsvector) -> tsvector[]`:                                 2079,
 	`pg_advisory_unlock(int: int) -> bool`:                                                              1428,
 	`pg_backend_pid() -> int`:                                                                           1400,
 	`pg_client_encoding() -> string`:                                                                    1429,
@@ -1228,7 +1272,9 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`pg_is_in_recovery() -> bool`:                                                                       1436,
 	`pg_is_other_temp_schema(oid: oid) -> bool`:                                                         1416,
 	`pg_is_xlog_replay_paused() -> bool`:                                                                1437,
+	`pg_listening_channels() -> string`:                                                                 2036,
 	`pg_my_temp_schema() -> oid`:                                                                        1415,
+	`pg_notify(channel: string, payload: string) -> void`:                                               2037,
 	`pg_options_to_table(options: string[]) -> tuple{string AS option_name, string AS option_value}`:    323,
 	`pg_relation_is_updatable(reloid: oid, include_triggers: bool) -> int4`:                             1433,
 	`pg_sequence_parameters(sequence_oid: oid) -> string`:                                               1420,
@@ -1237,7 +1283,11 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`pg_try_advisory_lock(int: int) -> bool`:                                                            1427,
 	`pg_type_is_visible(oid: oid) -> bool`:                                                              1432,
 	`pg_typeof(val: anyelement) -> string`:                                                              1417,
+	`phraseto_tsquery(text: string) -> tsquery`:                                                         2098,
+	`phraseto_tsquery(config: string, text: string) -> tsquery`:                                         2099,
 	`pi() -> float`:                                                                                     785,
+	`plainto_tsquery(text: string) -> tsquery`:                                                          2100,
+	`plainto_tsquery(config: string, text: string) -> tsquery`:                                          2101,
 	`postgis_addbbox(geometry: geometry) -> geometry`:                                                   357,
 	`postgis_dropbbox(geometry: geometry) -> geometry`:                                                  358,
 	`postgis_extensions_upgrade() -> string`:                                                            361,
@@ -1907,6 +1957,22 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`timetz_out(timetz: timetz) -> bytes`:                                                                                                                                           1908,
 	`timetz_recv(input: anyelement) -> timetz`:                                                                                                                                      1907,
 	`timetz_send(timetz: timetz) -> bytes`:                                                                                                                                          1906,
+	`trigger_in(input: anyelement) -> trigger`:                                                                                                                                      2102,
+	`trigger_out(trigger: trigger) -> bytes`:                                                                                                                                        2103,
+	`trigger_recv(input: anyelement) -> trigger`:                                                                                                                                    2104,
+	`trigger_send(trigger: trigger) -> bytes`:                                                                                                                                       2105,
+	`ts_rank(vector: tsvector, query: tsquery) -> float4`:                                                                                                                           2090,
+	`ts_rank(vector: tsvector, query: tsquery, normalization: int) -> float4`:                                                                                                       2091,
+	`ts_rank(weights: float[], vector: tsvector, query: tsquery) -> float4`:                                                                                                         2092,
+	`ts_rank(weights: float[], vector: tsvector, query: tsquery, normalization: int) -> float4`:                                                                                     2093,
+	`tsqueryin(input: anyelement) -> tsquery`:                                                                                                                                       2082,
+	`tsqueryout(tsquery: tsquery) -> bytes`:                                                                                                                                         2083,
+	`tsqueryrecv(input: anyelement) -> tsquery`:                                                                                                                                     2084,
+	`tsquerysend(tsquery: tsquery) -> bytes`:                                                                                                                                        2085,
+	`tsvectorin(input: anyelement) -> tsvector`:                                                                                                                                     2086,
+	`tsvectorout(tsvector: tsvector) -> bytes`:                                                                                                                                      2087,
+	`tsvectorrecv(input: anyelement) -> tsvector`:                                                                                                                                   2088,
+	`tsvectorsend(tsvector: tsvector) -> bytes`:                                                                                                                                     2089,
 	`timezone(timezone: string, timestamptz_string: string) -> timestamp`:                                                                                                           1059,
 	`timezone(timezone: string, timestamp: timestamp) -> timestamptz`:                                                                                                               1060,
 	`timezone(timezone: string, timestamptz: timestamptz) -> timestamp`:                                                                                                             1061,
@@ -1932,6 +1998,10 @@ var builtinOidsBySignature = map[string]oid.Oid{
 	`to_regrole(text: string) -> regtype`:                                                                                                                                           2031,
 	`to_regtype(text: string) -> regtype`:                                                                                                                                           2033,
 	`to_timestamp(timestamp: float) -> timestamptz`:                                                                                                                                 997,
+	`to_tsquery(query: string) -> tsquery`:                                                                                                                                          2094,
+	`to_tsquery(config: string, query: string) -> tsquery`:                                                                                                                          2095,
+	`to_tsvector(document: string) -> tsvector`:                                                                                                                                     2096,
+	`to_tsvector(config: string, document: string) -> tsvector`:                                                                                                                     2097,
 	`to_uuid(val: string) -> bytes`:                                                                                                                                                 868,
 	`transaction_timestamp() -> timestamptz`:                                                                                                                                        1014,
 	`transaction_timestamp() -> timestamp`:                                                                                                                                          1015,
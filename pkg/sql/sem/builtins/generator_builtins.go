@@ -234,6 +234,22 @@ var generators = map[string]builtinDefinition{
 			volatility.Immutable,
 		),
 	),
+	"pg_listening_channels": makeBuiltin(
+		tree.FunctionProperties{
+			Class:            tree.GeneratorClass,
+			Category:         builtinconstants.CategoryGenerator,
+			DistsqlBlocklist: true,
+		},
+		// See https://www.postgresql.org/docs/current/functions-info.html.
+		makeGeneratorOverload(
+			tree.ArgTypes{},
+			types.String,
+			makeListeningChannelsGenerator,
+			"Returns the set of names of the asynchronous notification channels "+
+				"that the current session is listening on.",
+			volatility.Stable,
+		),
+	),
 	`pg_options_to_table`: makeBuiltin(
 		genProps(),
 		makeGeneratorOverload(
@@ -1064,6 +1080,18 @@ func (s *arrayValueGenerator) Values() (tree.Datums, error) {
 	return tree.Datums{s.array.Array[s.nextIndex]}, nil
 }
 
+func makeListeningChannelsGenerator(
+	_ context.Context, evalCtx *eval.Context, _ tree.Datums,
+) (eval.ValueGenerator, error) {
+	arr := tree.NewDArray(types.String)
+	for _, channel := range evalCtx.Planner.ListeningChannels() {
+		if err := arr.Append(tree.NewDString(channel)); err != nil {
+			return nil, err
+		}
+	}
+	return &arrayValueGenerator{array: arr}, nil
+}
+
 func makeExpandArrayGenerator(
 	_ context.Context, _ *eval.Context, args tree.Datums,
 ) (eval.ValueGenerator, error) {
@@ -84,6 +84,7 @@ var typeBuiltinsHaveUnderscore = map[oid.Oid]struct{}{
 	types.Timestamp.Oid():   {},
 	types.TimestampTZ.Oid(): {},
 	types.AnyTuple.Oid():    {},
+	types.Trigger.Oid():     {},
 }
 
 // PGIOBuiltinPrefix returns the string prefix to a type's IO functions. This
@@ -558,6 +559,36 @@ var pgBuiltins = map[string]builtinDefinition{
 		},
 	),
 
+	// See https://www.postgresql.org/docs/current/functions-info.html.
+	"pg_notify": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         builtinconstants.CategorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"channel", types.String},
+				{"payload", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Void),
+			Fn: func(ctx context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				var channel, payload string
+				if args[0] != tree.DNull {
+					channel = string(tree.MustBeDString(args[0]))
+				}
+				if args[1] != tree.DNull {
+					payload = string(tree.MustBeDString(args[1]))
+				}
+				return tree.DVoidDatum, evalCtx.Planner.SendNotification(ctx, channel, payload)
+			},
+			Info: "Sends a notification event with the given payload to all the sessions " +
+				"listening on the given channel, once the current transaction commits. This " +
+				"is equivalent to the NOTIFY statement.",
+			Volatility:        volatility.Volatile,
+			CalledOnNullInput: true,
+		},
+	),
+
 	// See https://www.postgresql.org/docs/9.3/static/catalog-pg-database.html.
 	"pg_encoding_to_char": makeBuiltin(defProps(),
 		tree.Overload{
@@ -0,0 +1,230 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package builtins
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins/builtinconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/volatility"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
+)
+
+func init() {
+	for k, v := range tsearchBuiltins {
+		v.props.Category = builtinconstants.CategoryFullTextSearch
+		registerBuiltin(k, v)
+	}
+}
+
+var tsearchBuiltins = map[string]builtinDefinition{
+	"to_tsvector": makeTSearchConfigBuiltin(
+		"document", types.TSVector,
+		func(c *tsearch.Config, s string) (tree.Datum, error) {
+			v, err := tsearch.ToTSVector(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSVector(v), nil
+		},
+		"Converts the document to a tsvector, normalizing its words into lexemes",
+	),
+	"to_tsquery": makeTSearchConfigBuiltin(
+		"query", types.TSQuery,
+		func(c *tsearch.Config, s string) (tree.Datum, error) {
+			q, err := tsearch.ToTSQuery(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+		"Converts the query, which must be in the tsquery format, to a tsquery, "+
+			"normalizing its words into lexemes",
+	),
+	"plainto_tsquery": makeTSearchConfigBuiltin(
+		"text", types.TSQuery,
+		func(c *tsearch.Config, s string) (tree.Datum, error) {
+			q, err := tsearch.PlainToTSQuery(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+		"Converts the text to a tsquery which matches the documents containing "+
+			"all of its words, normalizing them into lexemes",
+	),
+	"phraseto_tsquery": makeTSearchConfigBuiltin(
+		"text", types.TSQuery,
+		func(c *tsearch.Config, s string) (tree.Datum, error) {
+			q, err := tsearch.PhraseToTSQuery(c, s)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+		"Converts the text to a tsquery which matches the documents containing "+
+			"its words in the same order, normalizing them into lexemes",
+	),
+	"ts_rank": makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryFullTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsRank(tsearch.DefaultRankWeights, args[0], args[1], 0 /* normalization */)
+			},
+			Info:       tsRankInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"vector", types.TSVector},
+				{"query", types.TSQuery},
+				{"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				return tsRank(tsearch.DefaultRankWeights, args[0], args[1], int(tree.MustBeDInt(args[2])))
+			},
+			Info:       tsRankInfo + " " + tsRankNormalizationInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.FloatArray},
+				{"vector", types.TSVector},
+				{"query", types.TSQuery},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				weights, err := makeTSRankWeights(tree.MustBeDArray(args[0]))
+				if err != nil {
+					return nil, err
+				}
+				return tsRank(weights, args[1], args[2], 0 /* normalization */)
+			},
+			Info:       tsRankInfo + " " + tsRankWeightsInfo,
+			Volatility: volatility.Immutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"weights", types.FloatArray},
+				{"vector", types.TSVector},
+				{"query", types.TSQuery},
+				{"normalization", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.Float4),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				weights, err := makeTSRankWeights(tree.MustBeDArray(args[0]))
+				if err != nil {
+					return nil, err
+				}
+				return tsRank(weights, args[1], args[2], int(tree.MustBeDInt(args[3])))
+			},
+			Info:       tsRankInfo + " " + tsRankWeightsInfo + " " + tsRankNormalizationInfo,
+			Volatility: volatility.Immutable,
+		},
+	),
+}
+
+const (
+	tsRankInfo = "Ranks the document for the query based on the frequency of the " +
+		"lexemes of the query in the document."
+	tsRankWeightsInfo = "The weights array gives the weights of the positions " +
+		"labeled D, C, B and A, in that order."
+	tsRankNormalizationInfo = "The normalization is a bit mask which selects " +
+		"how the rank is normalized by the length of the document: 1 divides it " +
+		"by 1 + the logarithm of the length, 2 by the length, 8 by the number of " +
+		"unique lexemes, 16 by 1 + the logarithm of the number of unique lexemes, " +
+		"and 32 by itself + 1."
+)
+
+// makeTSearchConfigBuiltin returns the definition of a full text search
+// function which converts a string to a tsvector or tsquery, using either the
+// given text search configuration or the default_text_search_config session
+// setting.
+func makeTSearchConfigBuiltin(
+	argName string,
+	retType *types.T,
+	fn func(c *tsearch.Config, s string) (tree.Datum, error),
+	info string,
+) builtinDefinition {
+	return makeBuiltin(
+		tree.FunctionProperties{Category: builtinconstants.CategoryFullTextSearch},
+		tree.Overload{
+			Types:      tree.ArgTypes{{argName, types.String}},
+			ReturnType: tree.FixedReturnType(retType),
+			Fn: func(_ context.Context, evalCtx *eval.Context, args tree.Datums) (tree.Datum, error) {
+				c, err := tsearch.GetConfig(defaultTextSearchConfig(evalCtx))
+				if err != nil {
+					return nil, err
+				}
+				return fn(c, string(tree.MustBeDString(args[0])))
+			},
+			Info: info + " using the text search configuration of the " +
+				"default_text_search_config session setting.",
+			// The result depends on the default_text_search_config session setting.
+			Volatility: volatility.Stable,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {argName, types.String}},
+			ReturnType: tree.FixedReturnType(retType),
+			Fn: func(_ context.Context, _ *eval.Context, args tree.Datums) (tree.Datum, error) {
+				c, err := tsearch.GetConfig(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return fn(c, string(tree.MustBeDString(args[1])))
+			},
+			Info:       info + " using the given text search configuration.",
+			Volatility: volatility.Immutable,
+		},
+	)
+}
+
+// defaultTextSearchConfig returns the name of the text search configuration
+// of the default_text_search_config session setting.
+func defaultTextSearchConfig(evalCtx *eval.Context) string {
+	if name := evalCtx.SessionData().DefaultTextSearchConfig; name != "" {
+		return name
+	}
+	return tsearch.DefaultConfig
+}
+
+// makeTSRankWeights returns the weights for ts_rank from the given array of
+// weights.
+func makeTSRankWeights(arr *tree.DArray) ([4]float32, error) {
+	if arr.HasNulls {
+		return tsearch.DefaultRankWeights, pgerror.New(pgcode.NullValueNotAllowed,
+			"array of weight must not contain nulls")
+	}
+	weights := make([]float64, len(arr.Array))
+	for i, d := range arr.Array {
+		weights[i] = float64(tree.MustBeDFloat(d))
+	}
+	return tsearch.MakeRankWeights(weights)
+}
+
+// tsRank returns the ts_rank of the tsvector for the tsquery.
+func tsRank(weights [4]float32, vector, query tree.Datum, normalization int) (tree.Datum, error) {
+	rank, err := tsearch.Rank(
+		weights, tree.MustBeDTSVector(vector).TSVector, tree.MustBeDTSQuery(query).TSQuery, normalization,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDFloat(tree.DFloat(rank)), nil
+}
@@ -192,6 +192,16 @@ func ValidCast(src, tgt *types.T, ctx Context) bool {
 // LookupCast returns a cast that describes the cast from src to tgt if it
 // exists. If it does not exist, ok=false is returned.
 func LookupCast(src, tgt *types.T) (Cast, bool) {
+	// Domains can be cast to and from whatever their base types can be cast
+	// to and from. The constraints of a target domain are checked when the cast
+	// is evaluated.
+	if src.IsDomain() {
+		return LookupCast(src.DomainBaseType(), tgt)
+	}
+	if tgt.IsDomain() {
+		return LookupCast(src, tgt.DomainBaseType())
+	}
+
 	srcFamily := src.Family()
 	tgtFamily := tgt.Family()
 
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/trigram"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 )
 
@@ -1511,3 +1512,19 @@ func (e *evaluator) EvalSimilarToOp(
 	key := similarToKey{s: string(tree.MustBeDString(right)), escape: '\\'}
 	return matchRegexpWithKey(e.ctx(), left, key)
 }
+
+func (e *evaluator) EvalTSMatchesQueryVectorOp(
+	ctx context.Context, _ *tree.TSMatchesQueryVectorOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	q := tree.MustBeDTSQuery(left)
+	v := tree.MustBeDTSVector(right)
+	return tree.MakeDBool(tree.DBool(tsearch.EvalTSQuery(q.TSQuery, v.TSVector))), nil
+}
+
+func (e *evaluator) EvalTSMatchesVectorQueryOp(
+	ctx context.Context, _ *tree.TSMatchesVectorQueryOp, left, right tree.Datum,
+) (tree.Datum, error) {
+	v := tree.MustBeDTSVector(left)
+	q := tree.MustBeDTSQuery(right)
+	return tree.MakeDBool(tree.DBool(tsearch.EvalTSQuery(q.TSQuery, v.TSVector))), nil
+}
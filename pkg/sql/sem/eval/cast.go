@@ -58,10 +58,21 @@ func ReType(expr tree.TypedExpr, wantedType *types.T) (_ tree.TypedExpr, ok bool
 
 // PerformCast performs a cast from the provided Datum to the specified
 // types.T. The original datum is returned if its type is identical
-// to the specified type.
+// to the specified type. Casts to a domain are performed as casts to its base
+// type, followed by a check of the constraints of the domain.
 func PerformCast(
 	ctx context.Context, evalCtx *Context, d tree.Datum, t *types.T,
 ) (tree.Datum, error) {
+	if t.IsDomain() {
+		ret, err := PerformCast(ctx, evalCtx, d, t.DomainBaseType())
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckDomainConstraints(ctx, evalCtx, ret, t); err != nil {
+			return nil, err
+		}
+		return ret, nil
+	}
 	ret, err := performCastWithoutPrecisionTruncation(ctx, evalCtx, d, t, true /* truncateWidth */)
 	if err != nil {
 		return nil, err
@@ -86,6 +97,16 @@ func PerformAssignmentCast(
 			"invalid assignment cast: %s -> %s", d.ResolvedType(), t,
 		)
 	}
+	if t.IsDomain() {
+		ret, err := PerformAssignmentCast(ctx, evalCtx, d, t.DomainBaseType())
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckDomainConstraints(ctx, evalCtx, ret, t); err != nil {
+			return nil, err
+		}
+		return ret, nil
+	}
 	d, err := performCastWithoutPrecisionTruncation(ctx, evalCtx, d, t, false /* truncateWidth */)
 	if err != nil {
 		return nil, err
@@ -467,6 +488,10 @@ func performCastWithoutPrecisionTruncation(
 			s = t.String()
 		case *tree.DJSON:
 			s = t.JSON.String()
+		case *tree.DTSQuery:
+			s = t.TSQuery.String()
+		case *tree.DTSVector:
+			s = t.TSVector.String()
 		case *tree.DEnum:
 			s = t.LogicalRep
 		case *tree.DVoid:
@@ -835,6 +860,24 @@ func performCastWithoutPrecisionTruncation(
 			}
 			return tree.ParseDJSON(string(j))
 		}
+	case types.TSQueryFamily:
+		switch v := d.(type) {
+		case *tree.DString:
+			return tree.ParseDTSQuery(string(*v))
+		case *tree.DCollatedString:
+			return tree.ParseDTSQuery(v.Contents)
+		case *tree.DTSQuery:
+			return v, nil
+		}
+	case types.TSVectorFamily:
+		switch v := d.(type) {
+		case *tree.DString:
+			return tree.ParseDTSVector(string(*v))
+		case *tree.DCollatedString:
+			return tree.ParseDTSVector(v.Contents)
+		case *tree.DTSVector:
+			return v, nil
+		}
 	case types.ArrayFamily:
 		switch v := d.(type) {
 		case *tree.DString:
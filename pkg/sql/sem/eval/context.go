@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -626,6 +627,15 @@ func (ec *Context) BoundedStaleness() bool {
 		ec.AsOfSystemTime.BoundedStaleness
 }
 
+// TxnIsoLevel returns the isolation level of the transaction in which the
+// statement is executing, or SERIALIZABLE if there is no transaction.
+func (ec *Context) TxnIsoLevel() enginepb.IsolationLevel {
+	if ec.Txn == nil {
+		return enginepb.SERIALIZABLE
+	}
+	return ec.Txn.IsoLevel()
+}
+
 // ensureExpectedType will return an error if a datum does not match the
 // provided type. If the expected type is Any or if the datum is a Null
 // type, then no error will be returned.
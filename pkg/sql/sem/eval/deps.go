@@ -220,6 +220,12 @@ type Planner interface {
 		ctx context.Context, expr *tree.RoutineExpr, input tree.Datums,
 	) (tree.Datum, error)
 
+	// RoutineExprGenerator returns a ValueGenerator that produces the rows of a
+	// set-returning routine invoked with the given input datums.
+	RoutineExprGenerator(
+		ctx context.Context, expr *tree.RoutineExpr, input tree.Datums,
+	) (ValueGenerator, error)
+
 	// UnsafeUpsertDescriptor is used to repair descriptors in dire
 	// circumstances. See the comment on the planner implementation.
 	UnsafeUpsertDescriptor(
@@ -363,6 +369,46 @@ type Planner interface {
 	// second return value is false if the database doesn't exist or is not
 	// multiregion.
 	GetMultiregionConfig(ctx context.Context, databaseID descpb.ID) (interface{}, bool)
+
+	// SendNotification queues up a notification to be sent on the given channel
+	// once the current transaction commits, as done by NOTIFY.
+	SendNotification(ctx context.Context, channel, payload string) error
+
+	// HasPendingNotifications returns whether the current transaction has sent
+	// notifications, which must be written before it commits.
+	HasPendingNotifications() bool
+
+	// ListeningChannels returns the notification channels the session is
+	// listening on.
+	ListeningChannels() []string
+
+	// IsConstraintCheckDeferred returns true if the checks for the DEFERRABLE
+	// constraint with the given name are currently deferred until the end of
+	// the transaction, as set by SET CONSTRAINTS. initiallyDeferred is used if
+	// SET CONSTRAINTS was not used for the constraint.
+	IsConstraintCheckDeferred(name string, initiallyDeferred bool) bool
+
+	// DeferConstraintViolation records a violation of a deferred constraint.
+	// The violation is checked again when the transaction commits, or when the
+	// constraint is set to IMMEDIATE.
+	DeferConstraintViolation(v DeferredConstraintViolation) error
+}
+
+// DeferredConstraintViolation describes a row which violated a DEFERRABLE
+// foreign key or unique constraint while the constraint was deferred.
+type DeferredConstraintViolation struct {
+	// TableID is the table on which the constraint is defined. For foreign
+	// keys, this is the origin (referencing) table.
+	TableID descpb.ID
+	// ConstraintName is the name of the constraint.
+	ConstraintName string
+	// Key contains the values of the constraint's columns for the violating
+	// row. For foreign keys, these are the values of the origin columns (which
+	// are the same as the values of the referenced columns).
+	Key tree.Datums
+	// Err is the error to return if the violation still exists when the
+	// constraint is checked again.
+	Err error
 }
 
 // InternalRows is an iterator interface that's exposed by the internal
@@ -0,0 +1,128 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package eval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// TypeCheckDomainConstraints parses and type checks the given CHECK
+// constraint expressions of a domain with the given base type, for use by
+// CheckDomainConstraints. References to VALUE are replaced with the ordinal
+// reference @1, which is bound to the value being checked when the constraints
+// are evaluated. It is called once when a domain type is hydrated, so that the
+// constraints need not be parsed and type checked for every value.
+func TypeCheckDomainConstraints(
+	ctx context.Context, baseType *types.T, exprs []string,
+) ([]tree.TypedExpr, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = &domainValue{typ: baseType}
+	value := tree.NewTypedOrdinalReference(0, baseType)
+	typedExprs := make([]tree.TypedExpr, len(exprs))
+	for i, exprStr := range exprs {
+		expr, err := parser.ParseExpr(exprStr)
+		if err != nil {
+			return nil, err
+		}
+		expr, err = tree.ReplaceDomainValue(expr, value)
+		if err != nil {
+			return nil, err
+		}
+		typedExprs[i], err = tree.TypeCheck(ctx, expr, &semaCtx, types.Bool)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return typedExprs, nil
+}
+
+// CheckDomainConstraints returns an error if the given datum, which must
+// already have been cast to the base type of the domain t, does not satisfy
+// the NOT NULL and CHECK constraints of t. It is a no-op if t is not a domain.
+//
+// Like CHECK constraints of tables, the CHECK constraints of a domain are
+// satisfied if they evaluate to NULL.
+func CheckDomainConstraints(
+	ctx context.Context, evalCtx *Context, d tree.Datum, t *types.T,
+) error {
+	if !t.IsDomain() {
+		return nil
+	}
+	data := t.TypeMeta.DomainData
+	if data == nil {
+		return errors.AssertionFailedf("domain %s is not hydrated", t.SQLString())
+	}
+	if d == tree.DNull && data.NotNull {
+		return pgerror.Newf(pgcode.NotNullViolation,
+			"domain %s does not allow null values", t.Name())
+	}
+	if len(data.CheckExprs) == 0 {
+		return nil
+	}
+	checks, ok := data.TypedCheckExprs.([]tree.TypedExpr)
+	if !ok || len(checks) != len(data.CheckExprs) {
+		return errors.AssertionFailedf(
+			"the constraints of domain %s are not type checked", t.SQLString())
+	}
+	evalCtx.PushIVarContainer(&domainValue{typ: t.DomainBaseType(), d: d})
+	defer evalCtx.PopIVarContainer()
+	for i, check := range checks {
+		res, err := Expr(ctx, evalCtx, check)
+		if err != nil {
+			return err
+		}
+		if res == tree.DBoolFalse {
+			return pgerror.Newf(pgcode.CheckViolation,
+				"value for domain %s violates check constraint %q", t.Name(), data.CheckNames[i])
+		}
+	}
+	return nil
+}
+
+// domainValue is the IndexedVarContainer which binds the value being checked
+// to the CHECK constraints of a domain.
+type domainValue struct {
+	typ *types.T
+	d   tree.Datum
+}
+
+var _ IndexedVarContainer = &domainValue{}
+
+// IndexedVarEval is part of the IndexedVarContainer interface.
+func (v *domainValue) IndexedVarEval(
+	_ context.Context, idx int, _ tree.ExprEvaluator,
+) (tree.Datum, error) {
+	if idx != 0 || v.d == nil {
+		return nil, errors.AssertionFailedf("invalid reference @%d to the domain value", idx+1)
+	}
+	return v.d, nil
+}
+
+// IndexedVarResolvedType is part of the tree.IndexedVarContainer interface.
+func (v *domainValue) IndexedVarResolvedType(int) *types.T {
+	return v.typ
+}
+
+// IndexedVarNodeFormatter is part of the tree.IndexedVarContainer interface.
+func (v *domainValue) IndexedVarNodeFormatter(int) tree.NodeFormatter {
+	n := tree.Name("value")
+	return &n
+}
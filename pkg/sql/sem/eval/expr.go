@@ -182,8 +182,12 @@ func (e *evaluator) EvalCastExpr(ctx context.Context, expr *tree.CastExpr) (tree
 		return nil, err
 	}
 
-	// NULL cast to anything is NULL.
+	// NULL cast to anything is NULL, unless it is cast to a domain that does
+	// not allow it.
 	if d == tree.DNull {
+		if err := CheckDomainConstraints(ctx, e.ctx(), d, expr.ResolvedType()); err != nil {
+			return nil, err
+		}
 		return d, nil
 	}
 	d = UnwrapDatum(e.ctx(), d)
@@ -369,6 +373,12 @@ func (e *evaluator) EvalDefaultVal(ctx context.Context, expr *tree.DefaultVal) (
 	return nil, errors.AssertionFailedf("unhandled type %T", expr)
 }
 
+func (e *evaluator) EvalGroupingOperation(
+	ctx context.Context, expr *tree.GroupingOperation,
+) (tree.Datum, error) {
+	return nil, errors.AssertionFailedf("unhandled type %T", expr)
+}
+
 func (e *evaluator) EvalIsNotNullExpr(
 	ctx context.Context, expr *tree.IsNotNullExpr,
 ) (tree.Datum, error) {
@@ -618,8 +628,22 @@ func (e *evaluator) EvalSubquery(ctx context.Context, subquery *tree.Subquery) (
 func (e *evaluator) EvalRoutineExpr(
 	ctx context.Context, routine *tree.RoutineExpr,
 ) (tree.Datum, error) {
-	var err error
-	var input tree.Datums
+	if routine.Generator {
+		return nil, errors.AssertionFailedf(
+			"set-returning routine %s must be evaluated as a generator", routine.Name,
+		)
+	}
+	input, err := e.evalRoutineInput(ctx, routine)
+	if err != nil {
+		return nil, err
+	}
+	return e.Planner.EvalRoutineExpr(ctx, routine, input)
+}
+
+// evalRoutineInput evaluates the input expressions of the routine.
+func (e *evaluator) evalRoutineInput(
+	ctx context.Context, routine *tree.RoutineExpr,
+) (input tree.Datums, err error) {
 	if len(routine.Input) > 0 {
 		// Evaluate each input expression.
 		// TODO(mgartner): Use a scratch tree.Datums to avoid allocation on
@@ -632,7 +656,7 @@ func (e *evaluator) EvalRoutineExpr(
 			}
 		}
 	}
-	return e.Planner.EvalRoutineExpr(ctx, routine, input)
+	return input, nil
 }
 
 func (e *evaluator) EvalTuple(ctx context.Context, t *tree.Tuple) (tree.Datum, error) {
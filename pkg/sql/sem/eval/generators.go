@@ -40,6 +40,24 @@ func GetGenerator(
 	return ol.Generator.(GeneratorOverload)(ctx, evalCtx, args)
 }
 
+// GetRoutineGenerator is used to construct a ValueGenerator from a
+// set-returning RoutineExpr.
+func GetRoutineGenerator(
+	ctx context.Context, evalCtx *Context, expr *tree.RoutineExpr,
+) (ValueGenerator, error) {
+	if !expr.Generator {
+		return nil, errors.AssertionFailedf(
+			"cannot call GetRoutineGenerator() on routine that does not return a set: %s",
+			tree.ErrString(expr),
+		)
+	}
+	input, err := (*evaluator)(evalCtx).evalRoutineInput(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+	return evalCtx.Planner.RoutineExprGenerator(ctx, expr, input)
+}
+
 // Table generators, also called "set-generating functions", are
 // special functions that return an entire table.
 //
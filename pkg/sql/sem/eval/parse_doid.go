@@ -149,29 +149,13 @@ func ParseDOid(ctx context.Context, evalCtx *Context, s string, t *types.T) (*tr
 		// Trim type modifiers, e.g. `numeric(10,3)` becomes `numeric`.
 		s = pgSignatureRegexp.ReplaceAllString(s, "$1")
 
-		dOid, errSafeToIgnore, missingTypeErr := evalCtx.Planner.ResolveOIDFromString(
+		dOid, _ /* errSafeToIgnore */, missingTypeErr := evalCtx.Planner.ResolveOIDFromString(
 			ctx, t, tree.NewDString(tree.Name(s).Normalize()),
 		)
-		if missingTypeErr == nil {
-			return dOid, nil
-		} else if !errSafeToIgnore {
+		if missingTypeErr != nil {
 			return nil, missingTypeErr
 		}
-		// Fall back to some special cases that we support for compatibility
-		// only. Client use syntax like 'sometype'::regtype to produce the oid
-		// for a type that they want to search a catalog table for. Since we
-		// don't support that type, we return an artificial OID that will never
-		// match anything.
-		switch s {
-		// We don't support triggers, but some tools search for them
-		// specifically.
-		case "trigger":
-		default:
-			return nil, missingTypeErr
-		}
-		// Types we don't support get OID 0, so they won't match anything
-		// in catalogs.
-		return tree.NewDOidWithTypeAndName(0, t, s), nil
+		return dOid, nil
 
 	case oid.T_regclass:
 		tn, err := castStringToRegClassTableName(s)
@@ -0,0 +1,543 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package plpgsqltree contains the abstract syntax tree of PL/pgSQL function
+// bodies. SQL expressions and statements embedded in a PL/pgSQL body are
+// represented with the nodes of the tree package.
+package plpgsqltree
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Statement is a PL/pgSQL statement.
+type Statement interface {
+	tree.NodeFormatter
+	format(f *formatter)
+}
+
+var _ Statement = &Block{}
+var _ Statement = &Assignment{}
+var _ Statement = &If{}
+var _ Statement = &Loop{}
+var _ Statement = &While{}
+var _ Statement = &ForInt{}
+var _ Statement = &ForQuery{}
+var _ Statement = &Exit{}
+var _ Statement = &Continue{}
+var _ Statement = &Return{}
+var _ Statement = &ReturnNext{}
+var _ Statement = &ReturnQuery{}
+var _ Statement = &Raise{}
+var _ Statement = &Perform{}
+var _ Statement = &Execute{}
+var _ Statement = &Null{}
+
+// Block is a block of statements, with optional declarations of variables
+// and handlers for the errors raised by its statements. The body of a PL/pgSQL
+// function is a block.
+type Block struct {
+	Label      tree.Name
+	Decls      []Declaration
+	Body       []Statement
+	Exceptions []Exception
+}
+
+// Declaration declares a variable of a block.
+type Declaration struct {
+	Var      tree.Name
+	Constant bool
+	Typ      tree.ResolvableTypeReference
+	NotNull  bool
+	// Default is the initial value of the variable. It is nil if the variable
+	// is initially NULL.
+	Default tree.Expr
+}
+
+// Exception is a handler for the errors raised by the statements of a block
+// that match any of its conditions.
+type Exception struct {
+	Conditions []Condition
+	Action     []Statement
+}
+
+// Condition identifies errors by a condition name, e.g. "division_by_zero",
+// or by SQLSTATE. The condition named "others" matches all errors.
+type Condition struct {
+	// Name is the name of the condition. It is empty if SQLState is set.
+	Name string
+	// SQLState is the SQLSTATE of the matched errors.
+	SQLState string
+}
+
+// Assignment assigns the value of an expression to a variable, or to a field
+// of a variable of a record type if Field is set.
+type Assignment struct {
+	Var   tree.Name
+	Field tree.Name
+	Value tree.Expr
+}
+
+// If executes the statements of the first branch whose condition is true, or
+// the ELSE statements if there is no such branch.
+type If struct {
+	Condition  tree.Expr
+	ThenBody   []Statement
+	ElseIfList []ElseIf
+	ElseBody   []Statement
+}
+
+// ElseIf is an ELSIF branch of an If statement.
+type ElseIf struct {
+	Condition tree.Expr
+	Stmts     []Statement
+}
+
+// Loop executes its statements until it is exited with EXIT or RETURN.
+type Loop struct {
+	Label tree.Name
+	Body  []Statement
+}
+
+// While executes its statements for as long as its condition is true.
+type While struct {
+	Label     tree.Name
+	Condition tree.Expr
+	Body      []Statement
+}
+
+// ForInt executes its statements for each integer in a range. The loop
+// variable is implicitly declared as an INT8.
+type ForInt struct {
+	Label   tree.Name
+	Var     tree.Name
+	Reverse bool
+	Lower   tree.Expr
+	Upper   tree.Expr
+	// Step is the increment of the loop variable. It is nil if the increment
+	// is 1.
+	Step tree.Expr
+	Body []Statement
+}
+
+// ForQuery executes its statements for each row returned by a query. The
+// columns of the row are assigned to the target variables.
+type ForQuery struct {
+	Label   tree.Name
+	Targets tree.NameList
+	Query   tree.Statement
+	Body    []Statement
+}
+
+// Exit exits the innermost loop, or the loop or block with the given label,
+// if its condition is true or omitted.
+type Exit struct {
+	Label     tree.Name
+	Condition tree.Expr
+}
+
+// Continue starts the next iteration of the innermost loop, or the loop with
+// the given label, if its condition is true or omitted.
+type Continue struct {
+	Label     tree.Name
+	Condition tree.Expr
+}
+
+// Return returns from the function. Expr is nil if the function returns no
+// value, or the rows added by RETURN NEXT and RETURN QUERY.
+type Return struct {
+	Expr tree.Expr
+}
+
+// ReturnNext adds a row with the value of Expr to the result of the function.
+type ReturnNext struct {
+	Expr tree.Expr
+}
+
+// ReturnQuery adds the rows returned by a query to the result of the function.
+type ReturnQuery struct {
+	Query tree.Statement
+}
+
+// Raise reports a message or raises an error.
+type Raise struct {
+	// Level is the severity of the message: DEBUG, LOG, INFO, NOTICE, WARNING or
+	// EXCEPTION. It is empty if it was omitted.
+	Level string
+	// Message is the format string of the message. Each % is replaced by the
+	// next parameter.
+	Message string
+	Params  tree.Exprs
+	// Condition identifies the raised error instead of a message. It is empty if
+	// it was omitted.
+	Condition Condition
+	Options   []RaiseOption
+}
+
+// RaiseOption is an option given to RAISE with USING, e.g. HINT = 'try again'.
+type RaiseOption struct {
+	Name  string
+	Value tree.Expr
+}
+
+// Perform executes a query and discards its results.
+type Perform struct {
+	Query tree.Statement
+}
+
+// Execute executes a SQL statement. If Targets is non-empty, the columns of the
+// first row returned by the statement are assigned to them.
+type Execute struct {
+	SQLStmt tree.Statement
+	Strict  bool
+	Targets tree.NameList
+}
+
+// Null does nothing.
+type Null struct{}
+
+// formatter formats PL/pgSQL statements, indenting nested statements.
+type formatter struct {
+	ctx    *tree.FmtCtx
+	indent int
+}
+
+func formatStatement(ctx *tree.FmtCtx, s Statement) {
+	f := formatter{ctx: ctx}
+	s.format(&f)
+}
+
+func (f *formatter) newline() {
+	f.ctx.WriteByte('\n')
+	for i := 0; i < f.indent; i++ {
+		f.ctx.WriteString("  ")
+	}
+}
+
+// stmts formats a list of statements, each on its own line and indented one
+// level deeper than the enclosing statement.
+func (f *formatter) stmts(stmts []Statement) {
+	f.indent++
+	for _, s := range stmts {
+		f.newline()
+		s.format(f)
+		f.ctx.WriteByte(';')
+	}
+	f.indent--
+}
+
+func (f *formatter) label(label tree.Name) {
+	if label != "" {
+		f.ctx.WriteString("<<")
+		f.ctx.FormatNode(&label)
+		f.ctx.WriteString(">>")
+		f.newline()
+	}
+}
+
+func (f *formatter) endLabel(label tree.Name) {
+	if label != "" {
+		f.ctx.WriteByte(' ')
+		f.ctx.FormatNode(&label)
+	}
+}
+
+func (f *formatter) loopBody(body []Statement, label tree.Name) {
+	f.ctx.WriteString(" LOOP")
+	f.stmts(body)
+	f.newline()
+	f.ctx.WriteString("END LOOP")
+	f.endLabel(label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Block) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Block) format(f *formatter) {
+	f.label(s.Label)
+	if len(s.Decls) > 0 {
+		f.ctx.WriteString("DECLARE")
+		f.indent++
+		for i := range s.Decls {
+			f.newline()
+			f.ctx.FormatNode(&s.Decls[i])
+			f.ctx.WriteByte(';')
+		}
+		f.indent--
+		f.newline()
+	}
+	f.ctx.WriteString("BEGIN")
+	f.stmts(s.Body)
+	if len(s.Exceptions) > 0 {
+		f.newline()
+		f.ctx.WriteString("EXCEPTION")
+		f.indent++
+		for i := range s.Exceptions {
+			f.newline()
+			s.Exceptions[i].format(f)
+		}
+		f.indent--
+	}
+	f.newline()
+	f.ctx.WriteString("END")
+	f.endLabel(s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (d *Declaration) Format(ctx *tree.FmtCtx) {
+	ctx.FormatNode(&d.Var)
+	if d.Constant {
+		ctx.WriteString(" CONSTANT")
+	}
+	ctx.WriteByte(' ')
+	ctx.FormatTypeReference(d.Typ)
+	if d.NotNull {
+		ctx.WriteString(" NOT NULL")
+	}
+	if d.Default != nil {
+		ctx.WriteString(" := ")
+		ctx.FormatNode(d.Default)
+	}
+}
+
+func (e *Exception) format(f *formatter) {
+	f.ctx.WriteString("WHEN ")
+	for i := range e.Conditions {
+		if i > 0 {
+			f.ctx.WriteString(" OR ")
+		}
+		f.ctx.FormatNode(&e.Conditions[i])
+	}
+	f.ctx.WriteString(" THEN")
+	f.stmts(e.Action)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (c *Condition) Format(ctx *tree.FmtCtx) {
+	if c.SQLState != "" {
+		ctx.WriteString("SQLSTATE ")
+		lexbase.EncodeSQLString(&ctx.Buffer, c.SQLState)
+		return
+	}
+	ctx.WriteString(c.Name)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Assignment) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Assignment) format(f *formatter) {
+	f.ctx.FormatNode(&s.Var)
+	if s.Field != "" {
+		f.ctx.WriteByte('.')
+		f.ctx.FormatNode(&s.Field)
+	}
+	f.ctx.WriteString(" := ")
+	f.ctx.FormatNode(s.Value)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *If) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *If) format(f *formatter) {
+	f.ctx.WriteString("IF ")
+	f.ctx.FormatNode(s.Condition)
+	f.ctx.WriteString(" THEN")
+	f.stmts(s.ThenBody)
+	for i := range s.ElseIfList {
+		f.newline()
+		f.ctx.WriteString("ELSIF ")
+		f.ctx.FormatNode(s.ElseIfList[i].Condition)
+		f.ctx.WriteString(" THEN")
+		f.stmts(s.ElseIfList[i].Stmts)
+	}
+	if len(s.ElseBody) > 0 {
+		f.newline()
+		f.ctx.WriteString("ELSE")
+		f.stmts(s.ElseBody)
+	}
+	f.newline()
+	f.ctx.WriteString("END IF")
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Loop) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Loop) format(f *formatter) {
+	f.label(s.Label)
+	f.ctx.WriteString("LOOP")
+	f.stmts(s.Body)
+	f.newline()
+	f.ctx.WriteString("END LOOP")
+	f.endLabel(s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *While) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *While) format(f *formatter) {
+	f.label(s.Label)
+	f.ctx.WriteString("WHILE ")
+	f.ctx.FormatNode(s.Condition)
+	f.loopBody(s.Body, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ForInt) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *ForInt) format(f *formatter) {
+	f.label(s.Label)
+	f.ctx.WriteString("FOR ")
+	f.ctx.FormatNode(&s.Var)
+	f.ctx.WriteString(" IN ")
+	if s.Reverse {
+		f.ctx.WriteString("REVERSE ")
+	}
+	f.ctx.FormatNode(s.Lower)
+	f.ctx.WriteString("..")
+	f.ctx.FormatNode(s.Upper)
+	if s.Step != nil {
+		f.ctx.WriteString(" BY ")
+		f.ctx.FormatNode(s.Step)
+	}
+	f.loopBody(s.Body, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ForQuery) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *ForQuery) format(f *formatter) {
+	f.label(s.Label)
+	f.ctx.WriteString("FOR ")
+	f.ctx.FormatNode(&s.Targets)
+	f.ctx.WriteString(" IN ")
+	f.ctx.FormatNode(s.Query)
+	f.loopBody(s.Body, s.Label)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Exit) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Exit) format(f *formatter) {
+	f.ctx.WriteString("EXIT")
+	f.endLabel(s.Label)
+	if s.Condition != nil {
+		f.ctx.WriteString(" WHEN ")
+		f.ctx.FormatNode(s.Condition)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Continue) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Continue) format(f *formatter) {
+	f.ctx.WriteString("CONTINUE")
+	f.endLabel(s.Label)
+	if s.Condition != nil {
+		f.ctx.WriteString(" WHEN ")
+		f.ctx.FormatNode(s.Condition)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Return) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Return) format(f *formatter) {
+	f.ctx.WriteString("RETURN")
+	if s.Expr != nil {
+		f.ctx.WriteByte(' ')
+		f.ctx.FormatNode(s.Expr)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ReturnNext) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *ReturnNext) format(f *formatter) {
+	f.ctx.WriteString("RETURN NEXT ")
+	f.ctx.FormatNode(s.Expr)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *ReturnQuery) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *ReturnQuery) format(f *formatter) {
+	f.ctx.WriteString("RETURN QUERY ")
+	f.ctx.FormatNode(s.Query)
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Raise) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Raise) format(f *formatter) {
+	f.ctx.WriteString("RAISE")
+	if s.Level != "" {
+		f.ctx.WriteByte(' ')
+		f.ctx.WriteString(s.Level)
+	}
+	if s.Condition != (Condition{}) {
+		f.ctx.WriteByte(' ')
+		f.ctx.FormatNode(&s.Condition)
+	} else if s.Message != "" || len(s.Params) > 0 {
+		f.ctx.WriteByte(' ')
+		lexbase.EncodeSQLString(&f.ctx.Buffer, s.Message)
+		for _, p := range s.Params {
+			f.ctx.WriteString(", ")
+			f.ctx.FormatNode(p)
+		}
+	}
+	for i, o := range s.Options {
+		if i == 0 {
+			f.ctx.WriteString(" USING ")
+		} else {
+			f.ctx.WriteString(", ")
+		}
+		f.ctx.WriteString(o.Name)
+		f.ctx.WriteString(" = ")
+		f.ctx.FormatNode(o.Value)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Perform) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Perform) format(f *formatter) {
+	// The query is parsed from the text following PERFORM prefixed with
+	// SELECT, so the prefix is replaced with PERFORM.
+	start := f.ctx.Len()
+	f.ctx.FormatNode(s.Query)
+	query := f.ctx.String()[start:]
+	f.ctx.Truncate(start)
+	f.ctx.WriteString("PERFORM ")
+	f.ctx.WriteString(strings.TrimPrefix(query, "SELECT "))
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Execute) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Execute) format(f *formatter) {
+	f.ctx.FormatNode(s.SQLStmt)
+	if len(s.Targets) > 0 {
+		f.ctx.WriteString(" INTO ")
+		if s.Strict {
+			f.ctx.WriteString("STRICT ")
+		}
+		f.ctx.FormatNode(&s.Targets)
+	}
+}
+
+// Format implements the tree.NodeFormatter interface.
+func (s *Null) Format(ctx *tree.FmtCtx) { formatStatement(ctx, s) }
+
+func (s *Null) format(f *formatter) {
+	f.ctx.WriteString("NULL")
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package plpgsqltree
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// TypeVisitFn returns the replacement of the type of a declared variable.
+type TypeVisitFn func(typ tree.ResolvableTypeReference) (tree.ResolvableTypeReference, error)
+
+// SimpleVisit rewrites the SQL embedded in the statement and its nested
+// statements in place. Each SQL expression is replaced by the result of
+// tree.SimpleVisit with preFn, and each SQL statement by the result of
+// tree.SimpleStmtVisit with preFn. If typFn is not nil, the type of each
+// declared variable is replaced by its result.
+func SimpleVisit(s Statement, preFn tree.SimpleVisitFn, typFn TypeVisitFn) error {
+	v := simpleVisitor{preFn: preFn, typFn: typFn}
+	return v.stmt(s)
+}
+
+type simpleVisitor struct {
+	preFn tree.SimpleVisitFn
+	typFn TypeVisitFn
+}
+
+func (v *simpleVisitor) expr(expr *tree.Expr) error {
+	if *expr == nil {
+		return nil
+	}
+	newExpr, err := tree.SimpleVisit(*expr, v.preFn)
+	if err != nil {
+		return err
+	}
+	*expr = newExpr
+	return nil
+}
+
+func (v *simpleVisitor) sqlStmt(stmt *tree.Statement) error {
+	newStmt, err := tree.SimpleStmtVisit(*stmt, v.preFn)
+	if err != nil {
+		return err
+	}
+	*stmt = newStmt
+	return nil
+}
+
+func (v *simpleVisitor) stmts(stmts []Statement) error {
+	for _, s := range stmts {
+		if err := v.stmt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *simpleVisitor) stmt(s Statement) error {
+	switch t := s.(type) {
+	case *Block:
+		for i := range t.Decls {
+			d := &t.Decls[i]
+			if v.typFn != nil {
+				typ, err := v.typFn(d.Typ)
+				if err != nil {
+					return err
+				}
+				d.Typ = typ
+			}
+			if err := v.expr(&d.Default); err != nil {
+				return err
+			}
+		}
+		if err := v.stmts(t.Body); err != nil {
+			return err
+		}
+		for i := range t.Exceptions {
+			if err := v.stmts(t.Exceptions[i].Action); err != nil {
+				return err
+			}
+		}
+	case *Assignment:
+		return v.expr(&t.Value)
+	case *If:
+		if err := v.expr(&t.Condition); err != nil {
+			return err
+		}
+		if err := v.stmts(t.ThenBody); err != nil {
+			return err
+		}
+		for i := range t.ElseIfList {
+			if err := v.expr(&t.ElseIfList[i].Condition); err != nil {
+				return err
+			}
+			if err := v.stmts(t.ElseIfList[i].Stmts); err != nil {
+				return err
+			}
+		}
+		return v.stmts(t.ElseBody)
+	case *Loop:
+		return v.stmts(t.Body)
+	case *While:
+		if err := v.expr(&t.Condition); err != nil {
+			return err
+		}
+		return v.stmts(t.Body)
+	case *ForInt:
+		for _, e := range []*tree.Expr{&t.Lower, &t.Upper, &t.Step} {
+			if err := v.expr(e); err != nil {
+				return err
+			}
+		}
+		return v.stmts(t.Body)
+	case *ForQuery:
+		if err := v.sqlStmt(&t.Query); err != nil {
+			return err
+		}
+		return v.stmts(t.Body)
+	case *Exit:
+		return v.expr(&t.Condition)
+	case *Continue:
+		return v.expr(&t.Condition)
+	case *Return:
+		return v.expr(&t.Expr)
+	case *ReturnNext:
+		return v.expr(&t.Expr)
+	case *ReturnQuery:
+		return v.sqlStmt(&t.Query)
+	case *Raise:
+		for i := range t.Params {
+			if err := v.expr(&t.Params[i]); err != nil {
+				return err
+			}
+		}
+		for i := range t.Options {
+			if err := v.expr(&t.Options[i].Value); err != nil {
+				return err
+			}
+		}
+	case *Perform:
+		return v.sqlStmt(&t.Query)
+	case *Execute:
+		return v.sqlStmt(&t.SQLStmt)
+	case *Null:
+	default:
+		return errors.AssertionFailedf("unexpected PL/pgSQL statement %T", s)
+	}
+	return nil
+}
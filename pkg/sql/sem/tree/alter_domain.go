@@ -0,0 +1,151 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// AlterDomain represents an ALTER DOMAIN statement.
+type AlterDomain struct {
+	Domain *UnresolvedObjectName
+	Cmd    AlterDomainCmd
+}
+
+var _ Statement = &AlterDomain{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomain) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER DOMAIN ")
+	ctx.FormatNode(node.Domain)
+	ctx.FormatNode(node.Cmd)
+}
+
+// AlterDomainCmd represents a domain modification operation.
+type AlterDomainCmd interface {
+	NodeFormatter
+	alterDomainCmd()
+	// TelemetryName returns the counter name to use for telemetry purposes.
+	TelemetryName() string
+}
+
+func (*AlterDomainSetDefault) alterDomainCmd()         {}
+func (*AlterDomainSetNotNull) alterDomainCmd()         {}
+func (*AlterDomainAddConstraint) alterDomainCmd()      {}
+func (*AlterDomainDropConstraint) alterDomainCmd()     {}
+func (*AlterDomainValidateConstraint) alterDomainCmd() {}
+
+var _ AlterDomainCmd = &AlterDomainSetDefault{}
+var _ AlterDomainCmd = &AlterDomainSetNotNull{}
+var _ AlterDomainCmd = &AlterDomainAddConstraint{}
+var _ AlterDomainCmd = &AlterDomainDropConstraint{}
+var _ AlterDomainCmd = &AlterDomainValidateConstraint{}
+
+// AlterDomainSetDefault represents an ALTER DOMAIN SET DEFAULT or DROP DEFAULT
+// command.
+type AlterDomainSetDefault struct {
+	// Default is nil for DROP DEFAULT.
+	Default Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainSetDefault) Format(ctx *FmtCtx) {
+	if node.Default == nil {
+		ctx.WriteString(" DROP DEFAULT")
+	} else {
+		ctx.WriteString(" SET DEFAULT ")
+		ctx.FormatNode(node.Default)
+	}
+}
+
+// TelemetryName implements the AlterDomainCmd interface.
+func (node *AlterDomainSetDefault) TelemetryName() string {
+	return "set_default"
+}
+
+// AlterDomainSetNotNull represents an ALTER DOMAIN SET NOT NULL or DROP NOT
+// NULL command.
+type AlterDomainSetNotNull struct {
+	NotNull bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainSetNotNull) Format(ctx *FmtCtx) {
+	if node.NotNull {
+		ctx.WriteString(" SET NOT NULL")
+	} else {
+		ctx.WriteString(" DROP NOT NULL")
+	}
+}
+
+// TelemetryName implements the AlterDomainCmd interface.
+func (node *AlterDomainSetNotNull) TelemetryName() string {
+	return "set_not_null"
+}
+
+// AlterDomainAddConstraint represents an ALTER DOMAIN ADD CONSTRAINT command.
+type AlterDomainAddConstraint struct {
+	Constraint         DomainConstraint
+	ValidationBehavior ValidationBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainAddConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ADD ")
+	ctx.FormatNode(&node.Constraint)
+	if node.ValidationBehavior == ValidationSkip {
+		ctx.WriteString(" NOT VALID")
+	}
+}
+
+// TelemetryName implements the AlterDomainCmd interface.
+func (node *AlterDomainAddConstraint) TelemetryName() string {
+	return "add_constraint"
+}
+
+// AlterDomainDropConstraint represents an ALTER DOMAIN DROP CONSTRAINT
+// command.
+type AlterDomainDropConstraint struct {
+	IfExists     bool
+	Constraint   Name
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainDropConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" DROP CONSTRAINT ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Constraint)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
+
+// TelemetryName implements the AlterDomainCmd interface.
+func (node *AlterDomainDropConstraint) TelemetryName() string {
+	return "drop_constraint"
+}
+
+// AlterDomainValidateConstraint represents an ALTER DOMAIN VALIDATE
+// CONSTRAINT command.
+type AlterDomainValidateConstraint struct {
+	Constraint Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDomainValidateConstraint) Format(ctx *FmtCtx) {
+	ctx.WriteString(" VALIDATE CONSTRAINT ")
+	ctx.FormatNode(&node.Constraint)
+}
+
+// TelemetryName implements the AlterDomainCmd interface.
+func (node *AlterDomainValidateConstraint) TelemetryName() string {
+	return "validate_constraint"
+}
@@ -0,0 +1,43 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// AttachBackup represents an ATTACH BACKUP statement.
+type AttachBackup struct {
+	// Subdir is the subdirectory of the backup to attach within the collection.
+	Subdir Expr
+	// InCollection contains the locations of the backup collection.
+	InCollection StringOrPlaceholderOptList
+	// Database is the name of the read-only database created for the backup.
+	Database Name
+	AsOf     AsOfClause
+	Options  KVOptions
+}
+
+var _ Statement = &AttachBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *AttachBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("ATTACH BACKUP FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.InCollection)
+	ctx.WriteString(" AS ")
+	ctx.FormatNode(&node.Database)
+	if node.AsOf.Expr != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(&node.AsOf)
+	}
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
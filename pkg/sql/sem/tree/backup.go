@@ -45,6 +45,7 @@ type BackupOptions struct {
 	Detached               *DBool
 	EncryptionKMSURI       StringOrPlaceholderOptList
 	IncrementalStorage     StringOrPlaceholderOptList
+	Continuous             *DBool
 }
 
 var _ NodeFormatter = &BackupOptions{}
@@ -195,6 +196,44 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	}
 }
 
+// RestoreRows represents a RESTORE ROWS statement, which upserts rows of a
+// table read from a backup into the live table.
+type RestoreRows struct {
+	// Table is the live table the rows are restored into.
+	Table *UnresolvedObjectName
+	// Subdir is the subdirectory of the backup within the collection.
+	Subdir Expr
+	// From contains the locations of the backup collection.
+	From    StringOrPlaceholderOptList
+	AsOf    AsOfClause
+	Where   *Where
+	Options KVOptions
+}
+
+var _ Statement = &RestoreRows{}
+
+// Format implements the NodeFormatter interface.
+func (node *RestoreRows) Format(ctx *FmtCtx) {
+	ctx.WriteString("RESTORE ROWS INTO ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.From)
+	if node.AsOf.Expr != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(&node.AsOf)
+	}
+	if node.Where != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(node.Where)
+	}
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
@@ -278,6 +317,11 @@ func (o *BackupOptions) Format(ctx *FmtCtx) {
 		ctx.WriteString("incremental_location = ")
 		ctx.FormatNode(&o.IncrementalStorage)
 	}
+
+	if o.Continuous == DBoolTrue {
+		maybeAddSep()
+		ctx.WriteString("continuous")
+	}
 }
 
 // CombineWith merges other backup options into this backup options struct.
@@ -317,6 +361,14 @@ func (o *BackupOptions) CombineWith(other *BackupOptions) error {
 		return errors.New("incremental_location option specified multiple times")
 	}
 
+	if o.Continuous != nil {
+		if other.Continuous != nil {
+			return errors.New("continuous option specified multiple times")
+		}
+	} else {
+		o.Continuous = other.Continuous
+	}
+
 	return nil
 }
 
@@ -326,7 +378,8 @@ func (o BackupOptions) IsDefault() bool {
 	return o.CaptureRevisionHistory == options.CaptureRevisionHistory &&
 		o.Detached == options.Detached && cmp.Equal(o.EncryptionKMSURI, options.EncryptionKMSURI) &&
 		o.EncryptionPassphrase == options.EncryptionPassphrase &&
-		cmp.Equal(o.IncrementalStorage, options.IncrementalStorage)
+		cmp.Equal(o.IncrementalStorage, options.IncrementalStorage) &&
+		o.Continuous == options.Continuous
 }
 
 // Format implements the NodeFormatter interface.
@@ -537,6 +537,8 @@ var (
 		types.INet,
 		types.Jsonb,
 		types.VarBit,
+		types.TSQuery,
+		types.TSVector,
 		types.AnyEnum,
 		types.AnyEnumArray,
 		types.INetArray,
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/pretty"
 	"github.com/cockroachdb/errors"
@@ -341,7 +342,68 @@ func (l *EnumValueList) Format(ctx *FmtCtx) {
 	}
 }
 
-// CreateType represents a CREATE TYPE statement.
+// DomainConstraint represents a NOT NULL, NULL or CHECK constraint of a
+// domain.
+type DomainConstraint struct {
+	// Name is the optional name of the constraint.
+	Name Name
+	// Nullability is NotNull or Null for NOT NULL and NULL constraints, and
+	// SilentNull for CHECK constraints.
+	Nullability Nullability
+	// Check is the expression of a CHECK constraint. Within the expression, the
+	// value of the domain is referred to as VALUE.
+	Check Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DomainConstraint) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	switch {
+	case node.Check != nil:
+		ctx.WriteString("CHECK (")
+		ctx.FormatNode(node.Check)
+		ctx.WriteByte(')')
+	case node.Nullability == NotNull:
+		ctx.WriteString("NOT NULL")
+	default:
+		ctx.WriteString("NULL")
+	}
+}
+
+// DomainConstraints represents a list of domain constraints.
+type DomainConstraints []DomainConstraint
+
+// Format implements the NodeFormatter interface.
+func (node *DomainConstraints) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// domainValueName is the name by which the value of a domain is referred to
+// in the CHECK constraints of the domain.
+const domainValueName = "value"
+
+// ReplaceDomainValue returns a copy of the given CHECK constraint expression
+// of a domain in which all references to VALUE are replaced with the given
+// expression.
+func ReplaceDomainValue(expr Expr, value Expr) (Expr, error) {
+	return SimpleVisit(expr, func(e Expr) (recurse bool, newExpr Expr, err error) {
+		if n, ok := e.(*UnresolvedName); ok && n.NumParts == 1 && n.Parts[0] == domainValueName {
+			return false, value, nil
+		}
+		return true, e, nil
+	})
+}
+
+// CreateType represents a CREATE TYPE or a CREATE DOMAIN statement.
 type CreateType struct {
 	TypeName *UnresolvedObjectName
 	Variety  CreateTypeVariety
@@ -349,12 +411,32 @@ type CreateType struct {
 	EnumLabels EnumValueList
 	// IfNotExists is true if IF NOT EXISTS was requested.
 	IfNotExists bool
+	// DomainType, DomainDefault and DomainConstraints are set when this
+	// represents a CREATE DOMAIN statement.
+	DomainType        ResolvableTypeReference
+	DomainDefault     Expr
+	DomainConstraints DomainConstraints
 }
 
 var _ Statement = &CreateType{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateType) Format(ctx *FmtCtx) {
+	if node.Variety == Domain {
+		ctx.WriteString("CREATE DOMAIN ")
+		ctx.FormatNode(node.TypeName)
+		ctx.WriteString(" AS ")
+		ctx.FormatTypeReference(node.DomainType)
+		if node.DomainDefault != nil {
+			ctx.WriteString(" DEFAULT ")
+			ctx.FormatNode(node.DomainDefault)
+		}
+		if len(node.DomainConstraints) > 0 {
+			ctx.WriteByte(' ')
+			ctx.FormatNode(&node.DomainConstraints)
+		}
+		return
+	}
 	ctx.WriteString("CREATE TYPE ")
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
@@ -387,6 +469,7 @@ func (*IndexTableDef) tableDef()                {}
 func (*FamilyTableDef) tableDef()               {}
 func (*ForeignKeyConstraintTableDef) tableDef() {}
 func (*CheckConstraintTableDef) tableDef()      {}
+func (*ExclusionConstraintTableDef) tableDef()  {}
 func (*LikeTableDef) tableDef()                 {}
 
 // TableDefs represents a list of table definitions.
@@ -476,6 +559,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrability  ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -659,6 +743,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrability = t.Deferrability
 		case *ColumnComputedDef:
 			if d.GeneratedIdentity.IsGeneratedAsIdentity {
 				return nil, pgerror.Newf(pgcode.Syntax,
@@ -829,6 +914,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(&node.References.Deferrability)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -958,10 +1044,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table         TableName
+	Col           Name // empty-string means use PK
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -1048,14 +1135,16 @@ type ConstraintTableDef interface {
 func (*UniqueConstraintTableDef) constraintTableDef()     {}
 func (*ForeignKeyConstraintTableDef) constraintTableDef() {}
 func (*CheckConstraintTableDef) constraintTableDef()      {}
+func (*ExclusionConstraintTableDef) constraintTableDef()  {}
 
 // UniqueConstraintTableDef represents a unique constraint within a CREATE
 // TABLE statement.
 type UniqueConstraintTableDef struct {
 	IndexTableDef
-	PrimaryKey   bool
-	WithoutIndex bool
-	IfNotExists  bool
+	PrimaryKey    bool
+	WithoutIndex  bool
+	Deferrability ConstraintDeferrability
+	IfNotExists   bool
 }
 
 // SetName implements the TableDef interface.
@@ -1100,6 +1189,7 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionByIndex != nil {
 		ctx.FormatNode(node.PartitionByIndex)
 	}
+	ctx.FormatNode(&node.Deferrability)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -1176,15 +1266,59 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability specifies whether the checks for a foreign key or
+// unique constraint can be deferred until the end of the transaction. See
+// https://www.postgresql.org/docs/current/sql-set-constraints.html.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	// ConstraintNotDeferrable indicates that the constraint is checked at the
+	// end of each statement.
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	// ConstraintDeferrableInitiallyImmediate indicates that the constraint is
+	// checked at the end of each statement, unless the transaction defers it
+	// with SET CONSTRAINTS.
+	ConstraintDeferrableInitiallyImmediate
+	// ConstraintDeferrableInitiallyDeferred indicates that the constraint is
+	// checked at the end of the transaction, unless the transaction makes it
+	// immediate with SET CONSTRAINTS.
+	ConstraintDeferrableInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	ConstraintNotDeferrable:                "",
+	ConstraintDeferrableInitiallyImmediate: "DEFERRABLE",
+	ConstraintDeferrableInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (d ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[d]
+}
+
+// IsDeferrable returns true if checking the constraint can be deferred.
+func (d ConstraintDeferrability) IsDeferrable() bool {
+	return d != ConstraintNotDeferrable
+}
+
+// Format implements the NodeFormatter interface.
+func (d *ConstraintDeferrability) Format(ctx *FmtCtx) {
+	if d.IsDeferrable() {
+		ctx.WriteByte(' ')
+		ctx.WriteString(d.String())
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name        Name
-	Table       TableName
-	FromCols    NameList
-	ToCols      NameList
-	Actions     ReferenceActions
-	Match       CompositeKeyMatchMethod
-	IfNotExists bool
+	Name          Name
+	Table         TableName
+	FromCols      NameList
+	ToCols        NameList
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
+	IfNotExists   bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -1215,6 +1349,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(&node.Deferrability)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1261,6 +1396,136 @@ func (node *CheckConstraintTableDef) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// ExclusionConstraintTableDef represents an exclusion constraint within a
+// CREATE TABLE statement. For example:
+//
+//	EXCLUDE USING gist (room WITH =, slots WITH &&) WHERE (NOT cancelled)
+//
+// No two rows satisfying the predicate may have values for which each of the
+// element operators returns true.
+type ExclusionConstraintTableDef struct {
+	Name        Name
+	Elems       ExclusionElemList
+	Predicate   Expr
+	IfNotExists bool
+}
+
+// SetName implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetName(name Name) {
+	node.Name = name
+}
+
+// SetIfNotExists implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetIfNotExists() {
+	node.IfNotExists = true
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintTableDef) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		if node.IfNotExists {
+			ctx.WriteString("IF NOT EXISTS ")
+		}
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("EXCLUDE USING gist (")
+	ctx.FormatNode(&node.Elems)
+	ctx.WriteByte(')')
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
+}
+
+// ExclusionElem is a column of an exclusion constraint, along with the
+// operator used to compare the values of the column.
+//
+// If RangeFunc is set, the element is instead the range constructed by the
+// function with that name from the lower bound Column and the upper bound
+// RangeUpper, such as tstzrange(start_at, end_at).
+type ExclusionElem struct {
+	Column     Name
+	RangeFunc  string
+	RangeUpper Name
+	Operator   treecmp.ComparisonOperator
+}
+
+// rangeConstructors are the functions which construct a range from its lower
+// and upper bounds, which may be used in exclusion constraints.
+var rangeConstructors = map[string]struct{}{
+	"int4range": {},
+	"int8range": {},
+	"numrange":  {},
+	"tsrange":   {},
+	"tstzrange": {},
+	"daterange": {},
+}
+
+// NewRangeExclusionElem returns the exclusion constraint element which compares
+// the range constructed by the given function expression with the given
+// operator. The second return value is false if the expression does not
+// construct a range from two columns, as in tstzrange(start_at, end_at).
+func NewRangeExclusionElem(expr Expr, op treecmp.ComparisonOperator) (ExclusionElem, bool) {
+	fn, ok := expr.(*FuncExpr)
+	if !ok || fn.Type != 0 || fn.Filter != nil || fn.WindowDef != nil ||
+		len(fn.OrderBy) > 0 || len(fn.Exprs) != 2 {
+		return ExclusionElem{}, false
+	}
+	name, ok := fn.Func.FunctionReference.(*UnresolvedName)
+	if !ok || name.NumParts != 1 {
+		return ExclusionElem{}, false
+	}
+	rangeFunc := strings.ToLower(name.Parts[0])
+	if _, ok := rangeConstructors[rangeFunc]; !ok {
+		return ExclusionElem{}, false
+	}
+	var bounds [2]Name
+	for i, e := range fn.Exprs {
+		col, ok := e.(*UnresolvedName)
+		if !ok || col.Star || col.NumParts != 1 {
+			return ExclusionElem{}, false
+		}
+		bounds[i] = Name(col.Parts[0])
+	}
+	return ExclusionElem{
+		Column:     bounds[0],
+		RangeFunc:  rangeFunc,
+		RangeUpper: bounds[1],
+		Operator:   op,
+	}, true
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionElem) Format(ctx *FmtCtx) {
+	if node.RangeFunc != "" {
+		ctx.WriteString(node.RangeFunc)
+		ctx.WriteByte('(')
+		ctx.FormatNode(&node.Column)
+		ctx.WriteString(", ")
+		ctx.FormatNode(&node.RangeUpper)
+		ctx.WriteByte(')')
+	} else {
+		ctx.FormatNode(&node.Column)
+	}
+	ctx.WriteString(" WITH ")
+	ctx.WriteString(node.Operator.String())
+}
+
+// ExclusionElemList is a list of ExclusionElem.
+type ExclusionElemList []ExclusionElem
+
+// Format implements the NodeFormatter interface.
+func (l *ExclusionElemList) Format(ctx *FmtCtx) {
+	for i := range *l {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*l)[i])
+	}
+}
+
 // FamilyTableDef represents a family definition within a CREATE TABLE
 // statement.
 type FamilyTableDef struct {
@@ -1622,12 +1887,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:         *col.References.Table,
+					FromCols:      NameList{col.Name},
+					ToCols:        targetCol,
+					Name:          col.References.ConstraintName,
+					Actions:       col.References.Actions,
+					Match:         col.References.Match,
+					Deferrability: col.References.Deferrability,
 				})
 				col.References.Table = nil
 			}
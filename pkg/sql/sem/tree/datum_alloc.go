@@ -45,6 +45,8 @@ type DatumAlloc struct {
 	duuidAlloc        []DUuid
 	dipnetAlloc       []DIPAddr
 	djsonAlloc        []DJSON
+	dtsqueryAlloc     []DTSQuery
+	dtsvectorAlloc    []DTSVector
 	dtupleAlloc       []DTuple
 	doidAlloc         []DOid
 	dvoidAlloc        []DVoid
@@ -441,6 +443,36 @@ func (a *DatumAlloc) NewDJSON(v DJSON) *DJSON {
 	return r
 }
 
+// NewDTSQuery allocates a DTSQuery.
+func (a *DatumAlloc) NewDTSQuery(v DTSQuery) *DTSQuery {
+	if a.AllocSize == 0 {
+		a.AllocSize = defaultDatumAllocSize
+	}
+	buf := &a.dtsqueryAlloc
+	if len(*buf) == 0 {
+		*buf = make([]DTSQuery, a.AllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
+// NewDTSVector allocates a DTSVector.
+func (a *DatumAlloc) NewDTSVector(v DTSVector) *DTSVector {
+	if a.AllocSize == 0 {
+		a.AllocSize = defaultDatumAllocSize
+	}
+	buf := &a.dtsvectorAlloc
+	if len(*buf) == 0 {
+		*buf = make([]DTSVector, a.AllocSize)
+	}
+	r := &(*buf)[0]
+	*r = v
+	*buf = (*buf)[1:]
+	return r
+}
+
 // NewDTuple allocates a DTuple.
 func (a *DatumAlloc) NewDTuple(v DTuple) *DTuple {
 	if a.AllocSize == 0 {
@@ -1442,6 +1442,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeEqFn(types.TimeTZ, types.TimeTZ, volatility.Leakproof),
 		makeEqFn(types.Timestamp, types.Timestamp, volatility.Leakproof),
 		makeEqFn(types.TimestampTZ, types.TimestampTZ, volatility.Leakproof),
+		makeEqFn(types.TSQuery, types.TSQuery, volatility.Immutable),
+		makeEqFn(types.TSVector, types.TSVector, volatility.Immutable),
 		makeEqFn(types.Uuid, types.Uuid, volatility.Leakproof),
 		makeEqFn(types.VarBit, types.VarBit, volatility.Leakproof),
 
@@ -1498,6 +1500,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeLtFn(types.TimeTZ, types.TimeTZ, volatility.Leakproof),
 		makeLtFn(types.Timestamp, types.Timestamp, volatility.Leakproof),
 		makeLtFn(types.TimestampTZ, types.TimestampTZ, volatility.Leakproof),
+		makeLtFn(types.TSQuery, types.TSQuery, volatility.Immutable),
+		makeLtFn(types.TSVector, types.TSVector, volatility.Immutable),
 		makeLtFn(types.Uuid, types.Uuid, volatility.Leakproof),
 		makeLtFn(types.VarBit, types.VarBit, volatility.Leakproof),
 
@@ -1554,6 +1558,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeLeFn(types.TimeTZ, types.TimeTZ, volatility.Leakproof),
 		makeLeFn(types.Timestamp, types.Timestamp, volatility.Leakproof),
 		makeLeFn(types.TimestampTZ, types.TimestampTZ, volatility.Leakproof),
+		makeLeFn(types.TSQuery, types.TSQuery, volatility.Immutable),
+		makeLeFn(types.TSVector, types.TSVector, volatility.Immutable),
 		makeLeFn(types.Uuid, types.Uuid, volatility.Leakproof),
 		makeLeFn(types.VarBit, types.VarBit, volatility.Leakproof),
 
@@ -1631,6 +1637,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeIsFn(types.TimeTZ, types.TimeTZ, volatility.Leakproof),
 		makeIsFn(types.Timestamp, types.Timestamp, volatility.Leakproof),
 		makeIsFn(types.TimestampTZ, types.TimestampTZ, volatility.Leakproof),
+		makeIsFn(types.TSQuery, types.TSQuery, volatility.Immutable),
+		makeIsFn(types.TSVector, types.TSVector, volatility.Immutable),
 		makeIsFn(types.Uuid, types.Uuid, volatility.Leakproof),
 		makeIsFn(types.VarBit, types.VarBit, volatility.Leakproof),
 
@@ -1686,6 +1694,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeEvalTupleIn(types.TimeTZ, volatility.Leakproof),
 		makeEvalTupleIn(types.Timestamp, volatility.Leakproof),
 		makeEvalTupleIn(types.TimestampTZ, volatility.Leakproof),
+		makeEvalTupleIn(types.TSQuery, volatility.Leakproof),
+		makeEvalTupleIn(types.TSVector, volatility.Leakproof),
 		makeEvalTupleIn(types.Uuid, volatility.Leakproof),
 		makeEvalTupleIn(types.VarBit, volatility.Leakproof),
 	},
@@ -1819,6 +1829,21 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 			},
 		)...,
 	),
+
+	treecmp.TSMatches: {
+		&CmpOp{
+			LeftType:   types.TSVector,
+			RightType:  types.TSQuery,
+			EvalOp:     &TSMatchesVectorQueryOp{},
+			Volatility: volatility.Immutable,
+		},
+		&CmpOp{
+			LeftType:   types.TSQuery,
+			RightType:  types.TSVector,
+			EvalOp:     &TSMatchesQueryVectorOp{},
+			Volatility: volatility.Immutable,
+		},
+	},
 })
 
 func makeBox2DComparisonOperators(op func(lhs, rhs *geo.CartesianBoundingBox) bool) cmpOpOverload {
@@ -37,6 +37,7 @@ type ExprEvaluator interface {
 	EvalComparisonExpr(context.Context, *ComparisonExpr) (Datum, error)
 	EvalDefaultVal(context.Context, *DefaultVal) (Datum, error)
 	EvalFuncExpr(context.Context, *FuncExpr) (Datum, error)
+	EvalGroupingOperation(context.Context, *GroupingOperation) (Datum, error)
 	EvalIfErrExpr(context.Context, *IfErrExpr) (Datum, error)
 	EvalIfExpr(context.Context, *IfExpr) (Datum, error)
 	EvalIndexedVar(context.Context, *IndexedVar) (Datum, error)
@@ -221,6 +222,16 @@ func (node *DString) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return node, nil
 }
 
+// Eval is part of the TypedExpr interface.
+func (node *DTSQuery) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return node, nil
+}
+
+// Eval is part of the TypedExpr interface.
+func (node *DTSVector) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return node, nil
+}
+
 // Eval is part of the TypedExpr interface.
 func (node *DTime) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return node, nil
@@ -266,6 +277,11 @@ func (node *FuncExpr) Eval(ctx context.Context, v ExprEvaluator) (Datum, error)
 	return v.EvalFuncExpr(ctx, node)
 }
 
+// Eval is part of the TypedExpr interface.
+func (node *GroupingOperation) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
+	return v.EvalGroupingOperation(ctx, node)
+}
+
 // Eval is part of the TypedExpr interface.
 func (node *IfErrExpr) Eval(ctx context.Context, v ExprEvaluator) (Datum, error) {
 	return v.EvalIfErrExpr(ctx, node)
@@ -686,6 +686,34 @@ type CoalesceExpr struct {
 	typeAnnotation
 }
 
+// GroupingOperation represents a GROUPING(...) expression. Its result is a
+// bit mask in which the bit for each argument, starting from the least
+// significant bit for the last argument, is set if the argument is not
+// included in the grouping set of the current row.
+type GroupingOperation struct {
+	Exprs Exprs
+
+	typeAnnotation
+}
+
+// MaxGroupingOperationArgs is the maximum number of arguments to a
+// GroupingOperation.
+const MaxGroupingOperationArgs = 31
+
+// TypedExprAt returns the expression at the specified index as a TypedExpr.
+func (node *GroupingOperation) TypedExprAt(idx int) TypedExpr {
+	return node.Exprs[idx].(TypedExpr)
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingOperation) Format(ctx *FmtCtx) {
+	ctx.WriteString("GROUPING(")
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
+func (node *GroupingOperation) String() string { return AsString(node) }
+
 // NewTypedCoalesceExpr returns a CoalesceExpr that is well-typed.
 func NewTypedCoalesceExpr(typedExprs TypedExprs, typ *types.T) *CoalesceExpr {
 	c := &CoalesceExpr{
@@ -1710,6 +1738,8 @@ func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
 func (node *DTimestampTZ) String() string     { return AsString(node) }
+func (node *DTSQuery) String() string         { return AsString(node) }
+func (node *DTSVector) String() string        { return AsString(node) }
 func (node *DTuple) String() string           { return AsString(node) }
 func (node *DArray) String() string           { return AsString(node) }
 func (node *DOid) String() string             { return AsString(node) }
@@ -0,0 +1,113 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// Merge represents a MERGE statement.
+type Merge struct {
+	With      *With
+	Table     TableExpr
+	Source    TableExpr
+	On        Expr
+	Whens     MergeWhens
+	Returning ReturningClause
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Merge) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.With)
+	ctx.WriteString("MERGE INTO ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" USING ")
+	ctx.FormatNode(node.Source)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(node.On)
+	for _, when := range node.Whens {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(when)
+	}
+	if HasReturningClause(node.Returning) {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Returning)
+	}
+}
+
+// MergeActionType is the type of the action of a WHEN clause of a MERGE
+// statement.
+type MergeActionType int
+
+const (
+	// MergeActionDoNothing leaves the target table untouched.
+	MergeActionDoNothing MergeActionType = iota
+	// MergeActionUpdate updates the matched row of the target table.
+	MergeActionUpdate
+	// MergeActionDelete deletes the matched row of the target table.
+	MergeActionDelete
+	// MergeActionInsert inserts a new row into the target table.
+	MergeActionInsert
+)
+
+// MergeWhens represents the list of WHEN clauses of a MERGE statement.
+type MergeWhens []*MergeWhen
+
+// MergeWhen represents a WHEN clause of a MERGE statement.
+type MergeWhen struct {
+	// Matched is true for WHEN MATCHED clauses, which apply to the source rows
+	// that match a row of the target table, and false for WHEN NOT MATCHED
+	// clauses, which apply to the other source rows.
+	Matched bool
+	// Cond is the optional condition which the source row must also satisfy
+	// for the clause to apply.
+	Cond   Expr
+	Action MergeActionType
+	// Exprs are the SET expressions of a MergeActionUpdate.
+	Exprs UpdateExprs
+	// Columns and Values are the target columns and the values to insert of a
+	// MergeActionInsert. Values is nil for INSERT DEFAULT VALUES.
+	Columns NameList
+	Values  Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *MergeWhen) Format(ctx *FmtCtx) {
+	if node.Matched {
+		ctx.WriteString("WHEN MATCHED")
+	} else {
+		ctx.WriteString("WHEN NOT MATCHED")
+	}
+	if node.Cond != nil {
+		ctx.WriteString(" AND ")
+		ctx.FormatNode(node.Cond)
+	}
+	ctx.WriteString(" THEN ")
+	switch node.Action {
+	case MergeActionDoNothing:
+		ctx.WriteString("DO NOTHING")
+	case MergeActionUpdate:
+		ctx.WriteString("UPDATE SET ")
+		ctx.FormatNode(&node.Exprs)
+	case MergeActionDelete:
+		ctx.WriteString("DELETE")
+	case MergeActionInsert:
+		ctx.WriteString("INSERT")
+		if len(node.Columns) > 0 {
+			ctx.WriteString(" (")
+			ctx.FormatNode(&node.Columns)
+			ctx.WriteByte(')')
+		}
+		if node.Values == nil {
+			ctx.WriteString(" DEFAULT VALUES")
+		} else {
+			ctx.WriteString(" VALUES (")
+			ctx.FormatNode(&node.Values)
+			ctx.WriteByte(')')
+		}
+	}
+}
@@ -186,6 +186,11 @@ type Overload struct {
 	UDFContainsOnlySignature bool
 	// Body is the SQL string body of a user-defined function.
 	Body string
+	// Language is the language of the body of a user-defined function.
+	Language FunctionLanguage
+	// IsProcedure is set to true when this is the overload of a user-defined
+	// procedure, which can only be invoked with a CALL statement.
+	IsProcedure bool
 	// ReturnSet is set to true when a user-defined function is defined to return
 	// a set of values.
 	ReturnSet bool
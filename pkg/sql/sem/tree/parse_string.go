@@ -93,6 +93,10 @@ func ParseAndRequireString(
 		d, err = MakeDEnumFromLogicalRepresentation(t, s)
 	case types.TupleFamily:
 		d, dependsOnContext, err = ParseDTupleFromString(ctx, s, t)
+	case types.TSQueryFamily:
+		d, err = ParseDTSQuery(s)
+	case types.TSVectorFamily:
+		d, err = ParseDTSVector(s)
 	case types.VoidFamily:
 		d = DVoidDatum
 	default:
@@ -0,0 +1,162 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// CreatePublication represents a CREATE PUBLICATION statement.
+type CreatePublication struct {
+	Name Name
+	// AllTables is set for FOR ALL TABLES publications, in which case Tables
+	// is empty.
+	AllTables bool
+	Tables    TableNames
+	Params    StorageParams
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreatePublication) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE PUBLICATION ")
+	ctx.FormatNode(&node.Name)
+	if node.AllTables {
+		ctx.WriteString(" FOR ALL TABLES")
+	} else if len(node.Tables) > 0 {
+		ctx.WriteString(" FOR TABLE ")
+		ctx.FormatNode(&node.Tables)
+	}
+	if len(node.Params) > 0 {
+		ctx.WriteString(" WITH (")
+		ctx.FormatNode(&node.Params)
+		ctx.WriteString(")")
+	}
+}
+
+// AlterPublication represents an ALTER PUBLICATION statement.
+type AlterPublication struct {
+	Name Name
+	Cmd  AlterPublicationCmd
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterPublication) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER PUBLICATION ")
+	ctx.FormatNode(&node.Name)
+	ctx.FormatNode(node.Cmd)
+}
+
+// AlterPublicationCmd represents a publication modification operation.
+type AlterPublicationCmd interface {
+	NodeFormatter
+	alterPublicationCmd()
+}
+
+func (*AlterPublicationTables) alterPublicationCmd()    {}
+func (*AlterPublicationSetParams) alterPublicationCmd() {}
+func (*AlterPublicationRename) alterPublicationCmd()    {}
+func (*AlterPublicationOwner) alterPublicationCmd()     {}
+
+var _ AlterPublicationCmd = &AlterPublicationTables{}
+var _ AlterPublicationCmd = &AlterPublicationSetParams{}
+var _ AlterPublicationCmd = &AlterPublicationRename{}
+var _ AlterPublicationCmd = &AlterPublicationOwner{}
+
+// AlterPublicationTablesAction is the way in which an ALTER PUBLICATION
+// statement modifies the tables of a publication.
+type AlterPublicationTablesAction int
+
+const (
+	// AlterPublicationAddTables adds tables to the publication.
+	AlterPublicationAddTables AlterPublicationTablesAction = iota
+	// AlterPublicationSetTables replaces the tables of the publication.
+	AlterPublicationSetTables
+	// AlterPublicationDropTables removes tables from the publication.
+	AlterPublicationDropTables
+)
+
+// String implements the fmt.Stringer interface.
+func (a AlterPublicationTablesAction) String() string {
+	switch a {
+	case AlterPublicationAddTables:
+		return "ADD"
+	case AlterPublicationSetTables:
+		return "SET"
+	case AlterPublicationDropTables:
+		return "DROP"
+	}
+	return "UNKNOWN"
+}
+
+// AlterPublicationTables represents an ALTER PUBLICATION { ADD | SET | DROP }
+// TABLE command.
+type AlterPublicationTables struct {
+	Action AlterPublicationTablesAction
+	Tables TableNames
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterPublicationTables) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ")
+	ctx.WriteString(node.Action.String())
+	ctx.WriteString(" TABLE ")
+	ctx.FormatNode(&node.Tables)
+}
+
+// AlterPublicationSetParams represents an ALTER PUBLICATION SET command.
+type AlterPublicationSetParams struct {
+	Params StorageParams
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterPublicationSetParams) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET (")
+	ctx.FormatNode(&node.Params)
+	ctx.WriteString(")")
+}
+
+// AlterPublicationRename represents an ALTER PUBLICATION RENAME command.
+type AlterPublicationRename struct {
+	NewName Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterPublicationRename) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RENAME TO ")
+	ctx.FormatNode(&node.NewName)
+}
+
+// AlterPublicationOwner represents an ALTER PUBLICATION OWNER TO command.
+type AlterPublicationOwner struct {
+	Owner RoleSpec
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterPublicationOwner) Format(ctx *FmtCtx) {
+	ctx.WriteString(" OWNER TO ")
+	ctx.FormatNode(&node.Owner)
+}
+
+// DropPublication represents a DROP PUBLICATION statement.
+type DropPublication struct {
+	Names        NameList
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropPublication) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP PUBLICATION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Names)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteString(" ")
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
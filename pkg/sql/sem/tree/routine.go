@@ -67,6 +67,18 @@ type RoutineExpr struct {
 	// its inputs are NULL. If false, the function will not be evaluated in the
 	// presence of null inputs, and will instead evaluate directly to NULL.
 	CalledOnNullInput bool
+
+	// Program, if non-nil, drives the execution of the statements in the
+	// routine. It is set for routines written in a procedural language, like
+	// PL/pgSQL. If it is nil, the statements are executed in order and the
+	// result of the last statement is the result of the routine.
+	Program *RoutineProgram
+
+	// Generator is true if the routine returns a set of rows rather than a
+	// single value. It is then evaluated as a ValueGenerator by the ProjectSet
+	// that invokes it, and the rows are added to its result by the
+	// RoutineReturnQuery instructions of its program.
+	Generator bool
 }
 
 // NewTypedRoutineExpr returns a new RoutineExpr that is well-typed.
@@ -78,6 +90,8 @@ func NewTypedRoutineExpr(
 	typ *types.T,
 	v volatility.V,
 	calledOnNullInput bool,
+	program *RoutineProgram,
+	generator bool,
 ) *RoutineExpr {
 	return &RoutineExpr{
 		Input:             input,
@@ -86,6 +100,8 @@ func NewTypedRoutineExpr(
 		Typ:               typ,
 		Volatility:        v,
 		CalledOnNullInput: calledOnNullInput,
+		Program:           program,
+		Generator:         generator,
 		Name:              name,
 	}
 }
@@ -119,3 +135,165 @@ func (node *RoutineExpr) Walk(v Visitor) Expr {
 	// Cannot walk into a routine, so this is a no-op.
 	return node
 }
+
+// RoutineProgram is the lowered form of the body of a routine written in a
+// procedural language. It is a sequence of instructions that are interpreted
+// in order, starting with the first, until a RoutineReturn instruction is
+// reached or execution runs past the last instruction. Instructions refer to
+// the statements of the routine by index. Each statement is planned with the
+// current values of the program's variables as input, so a statement may refer
+// to any variable.
+type RoutineProgram struct {
+	// Vars describes the variables of the program. The first variables are the
+	// arguments of the routine, in order. All other variables are NULL when
+	// execution starts.
+	Vars []RoutineVar
+
+	// Instrs are the instructions of the program.
+	Instrs []RoutineInstr
+
+	// NumCursors is the number of cursors used by the program to iterate over
+	// the results of a statement.
+	NumCursors int
+}
+
+// RoutineVar is a variable of a RoutineProgram.
+type RoutineVar struct {
+	// Name is the name of the variable, used in error messages.
+	Name string
+
+	// Typ is the type of the variable. Statements that assign to the variable
+	// return values of this type.
+	Typ *types.T
+
+	// NotNull is true if it is an error to assign NULL to the variable.
+	NotNull bool
+}
+
+// RoutineOp is the operation performed by a RoutineInstr.
+type RoutineOp uint8
+
+const (
+	_ RoutineOp = iota
+
+	// RoutineAssign assigns the columns of the first row returned by Stmt to
+	// Vars. If Stmt is -1, or it returns no rows and Strict is false, Vars are
+	// set to NULL.
+	RoutineAssign
+
+	// RoutineExec executes Stmt and discards its results.
+	RoutineExec
+
+	// RoutineJump continues execution at Target.
+	RoutineJump
+
+	// RoutineJumpIfNot continues execution at Target if the single value
+	// returned by Stmt is not true.
+	RoutineJumpIfNot
+
+	// RoutineOpenCursor executes Stmt and buffers the returned rows in Cursor.
+	// The columns of the rows have the types of Vars.
+	RoutineOpenCursor
+
+	// RoutineFetch assigns the next row buffered in Cursor to Vars. If all rows
+	// have been fetched, execution continues at Target instead.
+	RoutineFetch
+
+	// RoutineCloseCursor releases the rows buffered in Cursor.
+	RoutineCloseCursor
+
+	// RoutineReturn ends execution of the program. If Stmt is -1, the routine
+	// returns no value, or the rows added by RoutineReturnQuery instructions if
+	// it is a generator. Otherwise its result is the single value returned by
+	// Stmt.
+	RoutineReturn
+
+	// RoutineReturnQuery adds the rows returned by Stmt to the result of a
+	// generator routine. Execution continues with the next instruction.
+	RoutineReturnQuery
+
+	// RoutineRaise reports a message or raises an error, as described by
+	// Raise.
+	RoutineRaise
+
+	// RoutineBeginHandlers installs Handlers until the matching
+	// RoutineEndHandlers instruction is executed. If an error occurs while they
+	// are installed, the changes made since this instruction are rolled back,
+	// the handlers are uninstalled, and execution continues at the Target of
+	// the first handler that matches the error. The SQLSTATE and the message of
+	// the error are assigned to the two Vars.
+	RoutineBeginHandlers
+
+	// RoutineEndHandlers uninstalls the handlers installed by the innermost
+	// RoutineBeginHandlers instruction.
+	RoutineEndHandlers
+)
+
+// RoutineInstr is an instruction of a RoutineProgram. The meaning of each
+// field depends on Op.
+type RoutineInstr struct {
+	Op RoutineOp
+
+	// Stmt is the index of the statement executed by the instruction, or -1 if
+	// it executes no statement.
+	Stmt int
+
+	// Vars are the ordinals of the variables assigned by the instruction.
+	Vars []int
+
+	// Target is the index of the instruction at which execution continues
+	// when the instruction jumps.
+	Target int
+
+	// Cursor is the ordinal of the cursor used by the instruction.
+	Cursor int
+
+	// Strict is true if the statement of a RoutineAssign instruction must
+	// return exactly one row.
+	Strict bool
+
+	// Raise describes the message or error of a RoutineRaise instruction.
+	Raise *RoutineMessage
+
+	// Handlers are the error handlers installed by a RoutineBeginHandlers
+	// instruction.
+	Handlers []RoutineHandler
+}
+
+// RoutineMessage describes the message or error reported by a RoutineRaise
+// instruction. The statement of the instruction returns a single row with the
+// text of the format parameters followed by the values of the options.
+type RoutineMessage struct {
+	// Level is the severity of the message: DEBUG, LOG, INFO, NOTICE, WARNING or
+	// EXCEPTION. Only EXCEPTION raises an error. If Level is empty, the error
+	// that is currently being handled is raised again.
+	Level string
+
+	// Message is the format string of the message. Each % in the string is
+	// replaced by the next format parameter, and %% is replaced by %.
+	Message string
+
+	// Code is the SQLSTATE of the raised error.
+	Code string
+
+	// NumParams is the number of format parameters.
+	NumParams int
+
+	// Options are the names of the USING options that follow the format
+	// parameters: MESSAGE, DETAIL, HINT or ERRCODE.
+	Options []string
+}
+
+// RoutineHandler is an error handler installed by a RoutineBeginHandlers
+// instruction.
+type RoutineHandler struct {
+	// Codes are the SQLSTATEs of the errors handled by the handler. A code of
+	// an error class, ending in "000", matches all errors in that class. If
+	// Codes is empty, the handler matches any error except a query
+	// cancellation. Errors that require the transaction to be retried are never
+	// handled.
+	Codes []string
+
+	// Target is the index of the first instruction of the handler.
+	Target int
+}
@@ -20,12 +20,14 @@
 package tree
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/catid"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treewindow"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/redact"
 )
@@ -730,6 +732,73 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType is the type of a GroupingSet.
+type GroupingSetType int
+
+const (
+	// RollupGroupingSet is a ROLLUP (...) grouping set. It expands to every
+	// prefix of its expressions, including the empty one.
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet is a CUBE (...) grouping set. It expands to every subset
+	// of its expressions.
+	CubeGroupingSet
+	// ExplicitGroupingSets is a GROUPING SETS (...) grouping set. It expands
+	// to the concatenation of the grouping sets of its elements.
+	ExplicitGroupingSets
+)
+
+// MaxGroupingSets is the maximum number of grouping sets that a GROUP BY
+// clause may expand to.
+const MaxGroupingSets = 4096
+
+// GroupingSet represents a ROLLUP, CUBE or GROUPING SETS element of a GROUP
+// BY clause. The elements of ROLLUP and CUBE are expressions, where a Tuple
+// denotes a list of expressions which are added to or removed from a
+// grouping set together. The elements of GROUPING SETS are expressions,
+// Tuples (where an empty Tuple denotes the empty grouping set), or nested
+// GroupingSets.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+var _ Expr = &GroupingSet{}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	switch node.Type {
+	case RollupGroupingSet:
+		ctx.WriteString("ROLLUP (")
+	case CubeGroupingSet:
+		ctx.WriteString("CUBE (")
+	case ExplicitGroupingSets:
+		ctx.WriteString("GROUPING SETS (")
+	}
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
+func (node *GroupingSet) String() string { return AsString(node) }
+
+// Walk implements the Expr interface.
+func (node *GroupingSet) Walk(v Visitor) Expr {
+	exprs, changed := walkExprSlice(v, node.Exprs)
+	if changed {
+		nodeCopy := *node
+		nodeCopy.Exprs = exprs
+		return &nodeCopy
+	}
+	return node
+}
+
+// TypeCheck implements the Expr interface. Grouping sets are only valid as
+// elements of a GROUP BY clause, which expands them before type checking.
+func (node *GroupingSet) TypeCheck(
+	_ context.Context, _ *SemaContext, _ *types.T,
+) (TypedExpr, error) {
+	return nil, pgerror.New(pgcode.Syntax, "grouping sets are only allowed in GROUP BY")
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -135,6 +135,40 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 	}
 }
 
+// ShowBackupTable represents a SHOW BACKUP TABLE statement, which reads the
+// rows of a table directly from a backup. Where, if set, constrains the primary
+// key of the rows which are read.
+type ShowBackupTable struct {
+	Table        *UnresolvedObjectName
+	Subdir       Expr
+	InCollection StringOrPlaceholderOptList
+	AsOf         AsOfClause
+	Where        *Where
+	Options      KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowBackupTable) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW BACKUP TABLE ")
+	ctx.FormatNode(node.Table)
+	ctx.WriteString(" FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.InCollection)
+	if node.AsOf.Expr != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(&node.AsOf)
+	}
+	if node.Where != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(node.Where)
+	}
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // ShowColumns represents a SHOW COLUMNS statement.
 type ShowColumns struct {
 	Table       *UnresolvedObjectName
@@ -172,8 +172,10 @@ type CCLOnlyStatement interface {
 
 var _ CCLOnlyStatement = &AlterBackup{}
 var _ CCLOnlyStatement = &AlterBackupSchedule{}
+var _ CCLOnlyStatement = &AttachBackup{}
 var _ CCLOnlyStatement = &Backup{}
 var _ CCLOnlyStatement = &ShowBackup{}
+var _ CCLOnlyStatement = &ShowBackupTable{}
 var _ CCLOnlyStatement = &Restore{}
 var _ CCLOnlyStatement = &CreateChangefeed{}
 var _ CCLOnlyStatement = &AlterChangefeed{}
@@ -181,6 +183,8 @@ var _ CCLOnlyStatement = &Import{}
 var _ CCLOnlyStatement = &Export{}
 var _ CCLOnlyStatement = &ScheduledBackup{}
 var _ CCLOnlyStatement = &StreamIngestion{}
+var _ CCLOnlyStatement = &VerifyBackup{}
+var _ CCLOnlyStatement = &RestoreRows{}
 
 // StatementReturnType implements the Statement interface.
 func (*AlterChangefeed) StatementReturnType() StatementReturnType { return Rows }
@@ -439,6 +443,17 @@ func (*AlterTenantSetClusterSetting) StatementType() StatementType { return Type
 // StatementTag returns a short string identifying the type of statement.
 func (*AlterTenantSetClusterSetting) StatementTag() string { return "ALTER TENANT SET CLUSTER SETTING" }
 
+// StatementReturnType implements the Statement interface.
+func (*AlterDomain) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterDomain) StatementType() StatementType { return TypeDDL }
+
+// StatementTag implements the Statement interface.
+func (*AlterDomain) StatementTag() string { return "ALTER DOMAIN" }
+
+func (*AlterDomain) hiddenFromShowQueries() {}
+
 // StatementReturnType implements the Statement interface.
 func (*AlterType) StatementReturnType() StatementReturnType { return DDL }
 
@@ -490,6 +505,17 @@ func (*Analyze) StatementType() StatementType { return TypeDDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*Analyze) StatementTag() string { return "ANALYZE" }
 
+// StatementReturnType implements the Statement interface.
+func (*AttachBackup) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AttachBackup) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AttachBackup) StatementTag() string { return "ATTACH BACKUP" }
+
+func (*AttachBackup) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*Backup) StatementReturnType() StatementReturnType { return Rows }
 
@@ -582,6 +608,15 @@ func (n *ControlJobsOfType) StatementTag() string {
 	return fmt.Sprintf("%s ALL %s JOBS", JobCommandToStatement[n.Command], strings.ToUpper(n.Type))
 }
 
+// StatementReturnType implements the Statement interface.
+func (*Call) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*Call) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Call) StatementTag() string { return "CALL" }
+
 // StatementReturnType implements the Statement interface.
 func (*CancelQueries) StatementReturnType() StatementReturnType { return RowsAffected }
 
@@ -789,7 +824,12 @@ func (*CreateType) StatementReturnType() StatementReturnType { return DDL }
 func (*CreateType) StatementType() StatementType { return TypeDDL }
 
 // StatementTag implements the Statement interface.
-func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+func (n *CreateType) StatementTag() string {
+	if n.Variety == Domain {
+		return "CREATE DOMAIN"
+	}
+	return "CREATE TYPE"
+}
 
 func (*CreateType) modifiesSchema() bool { return true }
 
@@ -942,7 +982,12 @@ func (*DropType) StatementReturnType() StatementReturnType { return DDL }
 func (*DropType) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropType) StatementTag() string { return "DROP TYPE" }
+func (n *DropType) StatementTag() string {
+	if n.IsDomain {
+		return "DROP DOMAIN"
+	}
+	return "DROP TYPE"
+}
 
 // StatementReturnType implements the Statement interface.
 func (*DropSchema) StatementReturnType() StatementReturnType { return DDL }
@@ -1050,12 +1095,39 @@ func (*Import) cclOnlyStatement() {}
 // StatementReturnType implements the Statement interface.
 func (*LiteralValuesClause) StatementReturnType() StatementReturnType { return Rows }
 
+// StatementReturnType implements the Statement interface.
+func (n *Merge) StatementReturnType() StatementReturnType { return n.Returning.statementReturnType() }
+
+// StatementType implements the Statement interface.
+func (*Merge) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Merge) StatementTag() string { return "MERGE" }
+
 // StatementType implements the Statement interface.
 func (*LiteralValuesClause) StatementType() StatementType { return TypeDML }
 
 // StatementTag returns a short string identifying the type of statement.
 func (*LiteralValuesClause) StatementTag() string { return "VALUES" }
 
+// StatementReturnType implements the Statement interface.
+func (*Listen) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Listen) StatementType() StatementType { return TypeTCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Listen) StatementTag() string { return "LISTEN" }
+
+// StatementReturnType implements the Statement interface.
+func (*Notify) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*Notify) StatementType() StatementType { return TypeTCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Notify) StatementTag() string { return "NOTIFY" }
+
 // StatementReturnType implements the Statement interface.
 func (*ParenSelect) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1201,6 +1273,19 @@ func (*Restore) cclOnlyStatement() {}
 
 func (*Restore) hiddenFromShowQueries() {}
 
+// StatementReturnType implements the Statement interface.
+func (*RestoreRows) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*RestoreRows) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RestoreRows) StatementTag() string { return "RESTORE ROWS" }
+
+func (*RestoreRows) cclOnlyStatement() {}
+
+func (*RestoreRows) hiddenFromShowQueries() {}
+
 // StatementReturnType implements the Statement interface.
 func (*Revoke) StatementReturnType() StatementReturnType { return DDL }
 
@@ -1305,6 +1390,15 @@ func (*SetClusterSetting) StatementType() StatementType { return TypeDCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
 
+// StatementReturnType implements the Statement interface.
+func (*SetConstraints) StatementReturnType() StatementReturnType { return Ack }
+
+// StatementType implements the Statement interface.
+func (*SetConstraints) StatementType() StatementType { return TypeDCL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetConstraints) StatementTag() string { return "SET CONSTRAINTS" }
+
 // StatementReturnType implements the Statement interface.
 func (*SetTransaction) StatementReturnType() StatementReturnType { return Ack }
 
@@ -1463,6 +1557,17 @@ func (*ShowBackup) StatementTag() string { return "SHOW BACKUP" }
 
 func (*ShowBackup) cclOnlyStatement() {}
 
+// StatementReturnType implements the Statement interface.
+func (*ShowBackupTable) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*ShowBackupTable) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowBackupTable) StatementTag() string { return "SHOW BACKUP TABLE" }
+
+func (*ShowBackupTable) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*ShowDatabases) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1899,6 +2004,17 @@ func (*Unlisten) StatementType() StatementType { return TypeTCL }
 // StatementTag returns a short string identifying the type of statement.
 func (*Unlisten) StatementTag() string { return "UNLISTEN" }
 
+// StatementReturnType implements the Statement interface.
+func (*VerifyBackup) StatementReturnType() StatementReturnType { return Rows }
+
+// StatementType implements the Statement interface.
+func (*VerifyBackup) StatementType() StatementType { return TypeDML }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*VerifyBackup) StatementTag() string { return "VERIFY BACKUP" }
+
+func (*VerifyBackup) cclOnlyStatement() {}
+
 // StatementReturnType implements the Statement interface.
 func (*ValuesClause) StatementReturnType() StatementReturnType { return Rows }
 
@@ -1915,7 +2031,21 @@ func (*CreateFunction) StatementReturnType() StatementReturnType { return DDL }
 func (*CreateFunction) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*CreateFunction) StatementTag() string { return "CREATE FUNCTION" }
+func (n *CreateFunction) StatementTag() string {
+	if n.IsProcedure {
+		return "CREATE PROCEDURE"
+	}
+	return "CREATE FUNCTION"
+}
+
+// StatementReturnType implements the Statement interface.
+func (*CreateTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreateTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateTrigger) StatementTag() string { return "CREATE TRIGGER" }
 
 // StatementReturnType implements the Statement interface.
 func (*RoutineReturn) StatementReturnType() StatementReturnType { return Rows }
@@ -1933,7 +2063,48 @@ func (*DropFunction) StatementReturnType() StatementReturnType { return DDL }
 func (*DropFunction) StatementType() StatementType { return TypeDDL }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropFunction) StatementTag() string { return "DROP FUNCTION" }
+func (n *DropFunction) StatementTag() string {
+	if n.IsProcedure {
+		return "DROP PROCEDURE"
+	}
+	return "DROP FUNCTION"
+}
+
+// StatementReturnType implements the Statement interface.
+func (*DropTrigger) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropTrigger) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropTrigger) StatementTag() string { return "DROP TRIGGER" }
+
+// StatementReturnType implements the Statement interface.
+func (*CreatePublication) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*CreatePublication) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreatePublication) StatementTag() string { return "CREATE PUBLICATION" }
+
+// StatementReturnType implements the Statement interface.
+func (*AlterPublication) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*AlterPublication) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterPublication) StatementTag() string { return "ALTER PUBLICATION" }
+
+// StatementReturnType implements the Statement interface.
+func (*DropPublication) StatementReturnType() StatementReturnType { return DDL }
+
+// StatementType implements the Statement interface.
+func (*DropPublication) StatementType() StatementType { return TypeDDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropPublication) StatementTag() string { return "DROP PUBLICATION" }
 
 // StatementReturnType implements the Statement interface.
 func (*AlterFunctionOptions) StatementReturnType() StatementReturnType { return DDL }
@@ -2000,6 +2171,7 @@ func (n *AlterDatabaseSecondaryRegion) String() string        { return AsString(
 func (n *AlterDatabaseDropSecondaryRegion) String() string    { return AsString(n) }
 func (n *AlterDatabaseSetZoneConfigExtension) String() string { return AsString(n) }
 func (n *AlterDefaultPrivileges) String() string              { return AsString(n) }
+func (n *AlterDomain) String() string                         { return AsString(n) }
 func (n *AlterFunctionOptions) String() string                { return AsString(n) }
 func (n *AlterFunctionRename) String() string                 { return AsString(n) }
 func (n *AlterFunctionSetSchema) String() string              { return AsString(n) }
@@ -2023,16 +2195,19 @@ func (n *AlterTableOwner) String() string                     { return AsString(
 func (n *AlterTableSetSchema) String() string                 { return AsString(n) }
 func (n *AlterTenantSetClusterSetting) String() string        { return AsString(n) }
 func (n *AlterType) String() string                           { return AsString(n) }
+func (n *AlterPublication) String() string                    { return AsString(n) }
 func (n *AlterRole) String() string                           { return AsString(n) }
 func (n *AlterRoleSet) String() string                        { return AsString(n) }
 func (n *AlterSequence) String() string                       { return AsString(n) }
 func (n *Analyze) String() string                             { return AsString(n) }
+func (n *AttachBackup) String() string                        { return AsString(n) }
 func (n *Backup) String() string                              { return AsString(n) }
 func (n *BeginTransaction) String() string                    { return AsString(n) }
 func (n *ControlJobs) String() string                         { return AsString(n) }
 func (n *ControlSchedules) String() string                    { return AsString(n) }
 func (n *ControlJobsForSchedules) String() string             { return AsString(n) }
 func (n *ControlJobsOfType) String() string                   { return AsString(n) }
+func (n *Call) String() string                                { return AsString(n) }
 func (n *CancelQueries) String() string                       { return AsString(n) }
 func (n *CancelSessions) String() string                      { return AsString(n) }
 func (n *CannedOptPlan) String() string                       { return AsString(n) }
@@ -2050,8 +2225,10 @@ func (n *CreateDatabase) String() string                      { return AsString(
 func (n *CreateExtension) String() string                     { return AsString(n) }
 func (n *CreateFunction) String() string                      { return AsString(n) }
 func (n *CreateIndex) String() string                         { return AsString(n) }
+func (n *CreatePublication) String() string                   { return AsString(n) }
 func (n *CreateRole) String() string                          { return AsString(n) }
 func (n *CreateTable) String() string                         { return AsString(n) }
+func (n *CreateTrigger) String() string                       { return AsString(n) }
 func (n *CreateSchema) String() string                        { return AsString(n) }
 func (n *CreateSequence) String() string                      { return AsString(n) }
 func (n *CreateStats) String() string                         { return AsString(n) }
@@ -2063,9 +2240,11 @@ func (n *DropDatabase) String() string                        { return AsString(
 func (n *DropFunction) String() string                        { return AsString(n) }
 func (n *DropIndex) String() string                           { return AsString(n) }
 func (n *DropOwnedBy) String() string                         { return AsString(n) }
+func (n *DropPublication) String() string                     { return AsString(n) }
 func (n *DropSchema) String() string                          { return AsString(n) }
 func (n *DropSequence) String() string                        { return AsString(n) }
 func (n *DropTable) String() string                           { return AsString(n) }
+func (n *DropTrigger) String() string                         { return AsString(n) }
 func (n *DropType) String() string                            { return AsString(n) }
 func (n *DropView) String() string                            { return AsString(n) }
 func (n *DropRole) String() string                            { return AsString(n) }
@@ -2082,6 +2261,7 @@ func (n *MoveCursor) String() string                          { return AsString(
 func (n *Insert) String() string                              { return AsString(n) }
 func (n *Import) String() string                              { return AsString(n) }
 func (n *LiteralValuesClause) String() string                 { return AsString(n) }
+func (n *Merge) String() string                               { return AsString(n) }
 func (n *ParenSelect) String() string                         { return AsString(n) }
 func (n *Prepare) String() string                             { return AsString(n) }
 func (n *ReassignOwnedBy) String() string                     { return AsString(n) }
@@ -2095,6 +2275,7 @@ func (n *ReparentDatabase) String() string                    { return AsString(
 func (n *RenameIndex) String() string                         { return AsString(n) }
 func (n *RenameTable) String() string                         { return AsString(n) }
 func (n *Restore) String() string                             { return AsString(n) }
+func (n *RestoreRows) String() string                         { return AsString(n) }
 func (n *RoutineReturn) String() string                       { return AsString(n) }
 func (n *Revoke) String() string                              { return AsString(n) }
 func (n *RevokeRole) String() string                          { return AsString(n) }
@@ -2107,6 +2288,7 @@ func (n *Scrub) String() string                               { return AsString(
 func (n *Select) String() string                              { return AsString(n) }
 func (n *SelectClause) String() string                        { return AsString(n) }
 func (n *SetClusterSetting) String() string                   { return AsString(n) }
+func (n *SetConstraints) String() string                      { return AsString(n) }
 func (n *SetZoneConfig) String() string                       { return AsString(n) }
 func (n *SetSessionAuthorizationDefault) String() string      { return AsString(n) }
 func (n *SetSessionCharacteristics) String() string           { return AsString(n) }
@@ -2114,6 +2296,7 @@ func (n *SetTransaction) String() string                      { return AsString(
 func (n *SetTracing) String() string                          { return AsString(n) }
 func (n *SetVar) String() string                              { return AsString(n) }
 func (n *ShowBackup) String() string                          { return AsString(n) }
+func (n *ShowBackupTable) String() string                     { return AsString(n) }
 func (n *ShowClusterSetting) String() string                  { return AsString(n) }
 func (n *ShowClusterSettingList) String() string              { return AsString(n) }
 func (n *ShowTenantClusterSetting) String() string            { return AsString(n) }
@@ -2171,3 +2354,4 @@ func (n *Truncate) String() string                            { return AsString(
 func (n *UnionClause) String() string                         { return AsString(n) }
 func (n *Update) String() string                              { return AsString(n) }
 func (n *ValuesClause) String() string                        { return AsString(n) }
+func (n *VerifyBackup) String() string                        { return AsString(n) }
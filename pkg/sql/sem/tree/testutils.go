@@ -88,6 +88,12 @@ func SampleDatum(t *types.T) Datum {
 		return NewDGeography(geo.MustParseGeographyFromEWKB([]byte("\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0\x3f\x00\x00\x00\x00\x00\x00\xf0\x3f")))
 	case types.GeometryFamily:
 		return NewDGeometry(geo.MustParseGeometryFromEWKB([]byte("\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xf0\x3f\x00\x00\x00\x00\x00\x00\xf0\x3f")))
+	case types.TSQueryFamily:
+		q, _ := ParseDTSQuery("a & b:*")
+		return q
+	case types.TSVectorFamily:
+		v, _ := ParseDTSVector("a:1 b:2A")
+		return v
 	default:
 		panic(errors.AssertionFailedf("SampleDatum not implemented for %s", t))
 	}
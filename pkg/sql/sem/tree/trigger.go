@@ -0,0 +1,121 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// TriggerActionTime represents the time at which a trigger fires relative to
+// the modification of a row.
+type TriggerActionTime int
+
+const (
+	// TriggerActionTimeBefore indicates that the trigger fires before the row
+	// is modified.
+	TriggerActionTimeBefore TriggerActionTime = iota
+	// TriggerActionTimeAfter indicates that the trigger fires after the row is
+	// modified.
+	TriggerActionTimeAfter
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerActionTime) String() string {
+	switch t {
+	case TriggerActionTimeBefore:
+		return "BEFORE"
+	case TriggerActionTimeAfter:
+		return "AFTER"
+	}
+	return "UNKNOWN"
+}
+
+// TriggerEventType represents a type of row modification that fires a
+// trigger.
+type TriggerEventType int
+
+const (
+	// TriggerEventInsert fires the trigger for inserted rows.
+	TriggerEventInsert TriggerEventType = iota
+	// TriggerEventUpdate fires the trigger for updated rows.
+	TriggerEventUpdate
+	// TriggerEventDelete fires the trigger for deleted rows.
+	TriggerEventDelete
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerEventType) String() string {
+	switch t {
+	case TriggerEventInsert:
+		return "INSERT"
+	case TriggerEventUpdate:
+		return "UPDATE"
+	case TriggerEventDelete:
+		return "DELETE"
+	}
+	return "UNKNOWN"
+}
+
+// TriggerEvents is a list of events which fire a trigger.
+type TriggerEvents []TriggerEventType
+
+// Format implements the NodeFormatter interface.
+func (node TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range node {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+type CreateTrigger struct {
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     TriggerEvents
+	Table      TableName
+	FuncName   FunctionName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ")
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteString(" ")
+	ctx.FormatNode(node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	ctx.WriteString(" FOR EACH ROW EXECUTE FUNCTION ")
+	ctx.FormatNode(&node.FuncName)
+	ctx.WriteString("()")
+}
+
+// DropTrigger represents a DROP TRIGGER statement.
+type DropTrigger struct {
+	Name         Name
+	Table        TableName
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteString(" ")
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
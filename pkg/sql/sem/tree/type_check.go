@@ -840,6 +840,29 @@ func (expr *CoalesceExpr) TypeCheck(
 	return expr, nil
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingOperation) TypeCheck(
+	ctx context.Context, semaCtx *SemaContext, desired *types.T,
+) (TypedExpr, error) {
+	if semaCtx != nil && semaCtx.Properties.required.rejectFlags&RejectAggregates != 0 {
+		return nil, pgerror.Newf(pgcode.Grouping,
+			"grouping operations are not allowed in %s", semaCtx.Properties.required.context)
+	}
+	if len(expr.Exprs) > MaxGroupingOperationArgs {
+		return nil, pgerror.Newf(pgcode.TooManyArguments,
+			"GROUPING must have fewer than %d arguments", MaxGroupingOperationArgs+1)
+	}
+	for i, subExpr := range expr.Exprs {
+		typedSubExpr, err := subExpr.TypeCheck(ctx, semaCtx, types.Any)
+		if err != nil {
+			return nil, err
+		}
+		expr.Exprs[i] = typedSubExpr
+	}
+	expr.typ = types.Int
+	return expr, nil
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *ComparisonExpr) TypeCheck(
 	ctx context.Context, semaCtx *SemaContext, desired *types.T,
@@ -1849,6 +1872,18 @@ func (d *DJSON) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedE
 	return d, nil
 }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSQuery) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DTSVector) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
+	return d, nil
+}
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DTuple) TypeCheck(_ context.Context, _ *SemaContext, _ *types.T) (TypedExpr, error) {
@@ -80,7 +80,7 @@ func (f *FunctionName) FQString() string {
 
 func (f *FunctionName) objectName() {}
 
-// CreateFunction represents a CREATE FUNCTION statement.
+// CreateFunction represents a CREATE FUNCTION or CREATE PROCEDURE statement.
 type CreateFunction struct {
 	IsProcedure bool
 	Replace     bool
@@ -97,17 +97,25 @@ func (node *CreateFunction) Format(ctx *FmtCtx) {
 	if node.Replace {
 		ctx.WriteString("OR REPLACE ")
 	}
-	ctx.WriteString("FUNCTION ")
+	if node.IsProcedure {
+		ctx.WriteString("PROCEDURE ")
+	} else {
+		ctx.WriteString("FUNCTION ")
+	}
 	ctx.FormatNode(&node.FuncName)
 	ctx.WriteString("(")
 	ctx.FormatNode(node.Args)
 	ctx.WriteString(")\n\t")
-	ctx.WriteString("RETURNS ")
-	if node.ReturnType.IsSet {
-		ctx.WriteString("SETOF ")
+	// Procedures do not have a return type, and the return type of a function
+	// with OUT arguments can be omitted.
+	if !node.IsProcedure && node.ReturnType.Type != nil {
+		ctx.WriteString("RETURNS ")
+		if node.ReturnType.IsSet {
+			ctx.WriteString("SETOF ")
+		}
+		ctx.WriteString(node.ReturnType.Type.SQLString())
+		ctx.WriteString("\n\t")
 	}
-	ctx.WriteString(node.ReturnType.Type.SQLString())
-	ctx.WriteString("\n\t")
 	var funcBody FunctionBodyStr
 	for _, option := range node.Options {
 		switch t := option.(type) {
@@ -241,6 +249,8 @@ const (
 	_ FunctionLanguage = iota
 	// FunctionLangSQL represent SQL language.
 	FunctionLangSQL
+	// FunctionLangPLpgSQL represents the PL/pgSQL procedural language.
+	FunctionLangPLpgSQL
 )
 
 // Format implements the NodeFormatter interface.
@@ -249,6 +259,8 @@ func (node FunctionLanguage) Format(ctx *FmtCtx) {
 	switch node {
 	case FunctionLangSQL:
 		ctx.WriteString("SQL")
+	case FunctionLangPLpgSQL:
+		ctx.WriteString("plpgsql")
 	default:
 		panic(pgerror.New(pgcode.InvalidParameterValue, "Unknown function option"))
 	}
@@ -260,6 +272,8 @@ func AsFunctionLanguage(lang string) (FunctionLanguage, error) {
 	switch strings.ToLower(lang) {
 	case "sql":
 		return FunctionLangSQL, nil
+	case "plpgsql":
+		return FunctionLangPLpgSQL, nil
 	}
 	return 0, errors.Newf("language %q does not exist", lang)
 }
@@ -342,8 +356,9 @@ type FuncReturnType struct {
 	IsSet bool
 }
 
-// DropFunction represents a DROP FUNCTION statement.
+// DropFunction represents a DROP FUNCTION or DROP PROCEDURE statement.
 type DropFunction struct {
+	IsProcedure  bool
 	IfExists     bool
 	Functions    FuncObjs
 	DropBehavior DropBehavior
@@ -351,7 +366,11 @@ type DropFunction struct {
 
 // Format implements the NodeFormatter interface.
 func (node *DropFunction) Format(ctx *FmtCtx) {
-	ctx.WriteString("DROP FUNCTION ")
+	if node.IsProcedure {
+		ctx.WriteString("DROP PROCEDURE ")
+	} else {
+		ctx.WriteString("DROP FUNCTION ")
+	}
 	if node.IfExists {
 		ctx.WriteString("IF EXISTS ")
 	}
@@ -395,18 +414,21 @@ func (node FuncObj) Format(ctx *FmtCtx) {
 func (node FuncObj) InputArgTypes(
 	ctx context.Context, res TypeReferenceResolver,
 ) ([]*types.T, error) {
-	// TODO(chengxiong): handle INOUT, OUT and VARIADIC argument classes when we
-	// support them. This is because only IN and INOUT arg types need to be
-	// considered to match a overload.
+	// TODO(chengxiong): handle VARIADIC argument classes when we support them.
+	// OUT arguments are not part of the signature, so only IN and INOUT arg
+	// types need to be considered to match a overload.
 	var argTypes []*types.T
 	if node.Args != nil {
-		argTypes = make([]*types.T, len(node.Args))
-		for i, arg := range node.Args {
+		argTypes = make([]*types.T, 0, len(node.Args))
+		for _, arg := range node.Args {
+			if arg.Class == FunctionArgOut {
+				continue
+			}
 			typ, err := ResolveType(ctx, arg.Type, res)
 			if err != nil {
 				return nil, err
 			}
-			argTypes[i] = typ
+			argTypes = append(argTypes, typ)
 		}
 	}
 	return argTypes, nil
@@ -488,6 +510,19 @@ func (node *AlterFunctionDepExtension) Format(ctx *FmtCtx) {
 	ctx.WriteString(string(node.Extension))
 }
 
+// Call represents a CALL statement, which invokes a procedure.
+type Call struct {
+	Proc *FuncExpr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Call) Format(ctx *FmtCtx) {
+	ctx.WriteString("CALL ")
+	// The procedure invocation is not an expression, so it is never wrapped in
+	// parentheses.
+	node.Proc.Format(ctx)
+}
+
 // UDFDisallowanceVisitor is used to determine if a type checked expression
 // contains any UDF function sub-expression. It's needed only temporarily to
 // disallow any usage of UDF from relation objects.
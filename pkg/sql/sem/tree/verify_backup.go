@@ -0,0 +1,34 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// VerifyBackup represents a VERIFY BACKUP statement.
+type VerifyBackup struct {
+	// Subdir is the subdirectory of the backup to verify within the collection.
+	Subdir Expr
+	// To contains the locations of the backup collection.
+	To      StringOrPlaceholderOptList
+	Options KVOptions
+}
+
+var _ Statement = &VerifyBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *VerifyBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("VERIFY BACKUP FROM ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(&node.To)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
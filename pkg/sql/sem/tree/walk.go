@@ -185,6 +185,25 @@ func (expr *CoalesceExpr) Walk(v Visitor) Expr {
 	return ret
 }
 
+// copyNode makes a copy of this Expr without recursing in any child Exprs.
+func (expr *GroupingOperation) copyNode() *GroupingOperation {
+	exprCopy := *expr
+	return &exprCopy
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingOperation) Walk(v Visitor) Expr {
+	ret := expr
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		if ret == expr {
+			ret = expr.copyNode()
+		}
+		ret.Exprs = exprs
+	}
+	return ret
+}
+
 // Walk implements the Expr interface.
 func (expr *ComparisonExpr) Walk(v Visitor) Expr {
 	left, changedL := WalkExpr(v, expr.Left)
@@ -785,6 +804,12 @@ func (expr *DCollatedString) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DTimestamp) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DTSQuery) Walk(_ Visitor) Expr { return expr }
+
+// Walk implements the Expr interface.
+func (expr *DTSVector) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DTimestampTZ) Walk(_ Visitor) Expr { return expr }
 
@@ -1093,6 +1118,47 @@ func (stmt *Insert) walkStmt(v Visitor) Statement {
 	return ret
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Merge) copyNode() *Merge {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *Merge) walkStmt(v Visitor) Statement {
+	ret := stmt
+	on, changed := WalkExpr(v, stmt.On)
+	if changed {
+		ret = stmt.copyNode()
+		ret.On = on
+	}
+	returning, changed := walkReturningClause(v, stmt.Returning)
+	if changed {
+		if ret == stmt {
+			ret = stmt.copyNode()
+		}
+		ret.Returning = returning
+	}
+	return ret
+}
+
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Call) copyNode() *Call {
+	stmtCopy := *stmt
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *Call) walkStmt(v Visitor) Statement {
+	ret := stmt
+	proc, changed := WalkExpr(v, stmt.Proc)
+	if changed {
+		ret = stmt.copyNode()
+		ret.Proc = proc.(*FuncExpr)
+	}
+	return ret
+}
+
 // copyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *CreateTable) copyNode() *CreateTable {
 	stmtCopy := *stmt
@@ -1660,6 +1726,7 @@ func (stmt *BeginTransaction) walkStmt(v Visitor) Statement {
 var _ walkableStmt = &AlterTenantSetClusterSetting{}
 var _ walkableStmt = &CreateTable{}
 var _ walkableStmt = &Backup{}
+var _ walkableStmt = &Call{}
 var _ walkableStmt = &Delete{}
 var _ walkableStmt = &Explain{}
 var _ walkableStmt = &Insert{}
@@ -0,0 +1,243 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/eval"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+)
+
+// SetConstraints implements the SET CONSTRAINTS statement.
+// See https://www.postgresql.org/docs/current/sql-set-constraints.html for
+// details.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	if p.extendedEvalCtx.DeferredConstraints == nil {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"SET CONSTRAINTS is not supported in this context")
+	}
+	return &setConstraintsNode{n: n}, nil
+}
+
+type setConstraintsNode struct {
+	n *tree.SetConstraints
+}
+
+func (n *setConstraintsNode) startExec(params runParams) error {
+	p := params.p
+	// Like Postgres, SET CONSTRAINTS has no effect outside of a transaction
+	// block.
+	if p.extendedEvalCtx.TxnImplicit {
+		p.BufferClientNotice(
+			params.ctx,
+			pgnotice.NewWithSeverityf(
+				"WARNING",
+				"SET CONSTRAINTS can only be used in transaction blocks",
+			),
+		)
+		return nil
+	}
+	// The constraints which become IMMEDIATE are checked right away.
+	immediate := p.extendedEvalCtx.DeferredConstraints.setConstraints(n.n.Names, n.n.Deferred)
+	return p.recheckDeferredConstraintViolations(params.ctx, immediate)
+}
+
+func (n *setConstraintsNode) Next(_ runParams) (bool, error) { return false, nil }
+func (n *setConstraintsNode) Values() tree.Datums            { return nil }
+func (n *setConstraintsNode) Close(_ context.Context)        {}
+
+// IsConstraintCheckDeferred is part of the eval.Planner interface.
+func (p *planner) IsConstraintCheckDeferred(name string, initiallyDeferred bool) bool {
+	if p.extendedEvalCtx.DeferredConstraints == nil {
+		return false
+	}
+	return p.extendedEvalCtx.DeferredConstraints.isConstraintDeferred(name, initiallyDeferred)
+}
+
+// DeferConstraintViolation is part of the eval.Planner interface.
+func (p *planner) DeferConstraintViolation(v eval.DeferredConstraintViolation) error {
+	if p.extendedEvalCtx.DeferredConstraints == nil {
+		return errors.AssertionFailedf("cannot defer the checks for constraint %q", v.ConstraintName)
+	}
+	p.extendedEvalCtx.DeferredConstraints.deferConstraintViolation(v)
+	return nil
+}
+
+// recheckDeferredConstraintViolations checks whether the given violations of
+// deferred constraints still exist, and returns the error of the first one
+// which does.
+func (p *planner) recheckDeferredConstraintViolations(
+	ctx context.Context, violations []eval.DeferredConstraintViolation,
+) error {
+	for i := range violations {
+		if err := p.recheckDeferredConstraintViolation(ctx, &violations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *planner) recheckDeferredConstraintViolation(
+	ctx context.Context, v *eval.DeferredConstraintViolation,
+) error {
+	tbl, err := p.Descriptors().GetImmutableTableByID(
+		ctx, p.Txn(), v.TableID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{
+				Required:       true,
+				IncludeDropped: true,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if tbl.Dropped() {
+		// The rows which violated the constraint are gone.
+		return nil
+	}
+
+	var query string
+	// minViolatingCount is the value that the first column of the row returned
+	// by the query must reach for the violation to still exist.
+	var minViolatingCount int64
+	for _, fk := range tbl.AllActiveAndInactiveForeignKeys() {
+		if fk.Name == v.ConstraintName {
+			if query, err = deferredForeignKeyViolationQuery(ctx, p, tbl, fk, v.Key); err != nil {
+				return err
+			}
+			minViolatingCount = 1
+			break
+		}
+	}
+	for _, uc := range tbl.AllActiveAndInactiveUniqueWithoutIndexConstraints() {
+		if query == "" && uc.Name == v.ConstraintName {
+			if query, err = deferredUniqueViolationQuery(tbl, uc); err != nil {
+				return err
+			}
+			minViolatingCount = 2
+			break
+		}
+	}
+	if query == "" {
+		// The constraint was dropped.
+		return nil
+	}
+
+	// NULL values are not passed as arguments; see
+	// deferredForeignKeyViolationQuery.
+	args := make([]interface{}, 0, len(v.Key))
+	for _, d := range v.Key {
+		if d != tree.DNull {
+			args = append(args, d)
+		}
+	}
+	row, err := p.QueryRowEx(
+		ctx, "check deferred constraint", sessiondata.NodeUserSessionDataOverride, query, args...,
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil && int64(tree.MustBeDInt(row[0])) >= minViolatingCount {
+		return v.Err
+	}
+	return nil
+}
+
+// deferredForeignKeyViolationQuery returns a query which returns a row if the
+// origin table of the given foreign key still contains a row with the given
+// key, and if that row still has no match in the referenced table. The query
+// takes the non-NULL values of key as arguments.
+func deferredForeignKeyViolationQuery(
+	ctx context.Context,
+	p *planner,
+	srcTbl catalog.TableDescriptor,
+	fk *descpb.ForeignKeyConstraint,
+	key tree.Datums,
+) (string, error) {
+	targetTbl, err := p.Descriptors().GetImmutableTableByID(
+		ctx, p.Txn(), fk.ReferencedTableID, tree.ObjectLookupFlagsWithRequired(),
+	)
+	if err != nil {
+		return "", err
+	}
+	srcCols, err := srcTbl.NamesForColumnIDs(fk.OriginColumnIDs)
+	if err != nil {
+		return "", err
+	}
+	targetCols, err := targetTbl.NamesForColumnIDs(fk.ReferencedColumnIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(key) != len(srcCols) {
+		return "", errors.AssertionFailedf(
+			"expected %d values for foreign key %q, got %d", len(srcCols), fk.Name, len(key),
+		)
+	}
+	srcWhere := make([]string, len(srcCols))
+	on := make([]string, len(srcCols))
+	placeholder := 1
+	for i := range srcCols {
+		srcCol := fmt.Sprintf("s.%s", tree.NameString(srcCols[i]))
+		// A NULL value can only be part of a violation for MATCH FULL foreign
+		// keys, in which case it never has a match in the referenced table.
+		if key[i] == tree.DNull {
+			srcWhere[i] = fmt.Sprintf("%s IS NULL", srcCol)
+		} else {
+			srcWhere[i] = fmt.Sprintf("%s = $%d", srcCol, placeholder)
+			placeholder++
+		}
+		on[i] = fmt.Sprintf("%s = t.%s", srcCol, tree.NameString(targetCols[i]))
+	}
+	return fmt.Sprintf(
+		`SELECT 1 FROM [%[1]d AS src] AS s
+		  WHERE %[2]s
+		    AND NOT EXISTS (SELECT 1 FROM [%[3]d AS target] AS t WHERE %[4]s)
+		  LIMIT 1`,
+		srcTbl.GetID(),                  // 1
+		strings.Join(srcWhere, " AND "), // 2
+		targetTbl.GetID(),               // 3
+		strings.Join(on, " AND "),       // 4
+	), nil
+}
+
+// deferredUniqueViolationQuery returns a query which counts the rows of the
+// table which have the given values for the columns of the unique constraint.
+// The query takes these values as arguments.
+func deferredUniqueViolationQuery(
+	tbl catalog.TableDescriptor, uc *descpb.UniqueWithoutIndexConstraint,
+) (string, error) {
+	colNames, err := tbl.NamesForColumnIDs(uc.ColumnIDs)
+	if err != nil {
+		return "", err
+	}
+	where := make([]string, 0, len(colNames)+1)
+	for i := range colNames {
+		where = append(where, fmt.Sprintf("%s = $%d", tree.NameString(colNames[i]), i+1))
+	}
+	if uc.IsPartial() {
+		where = append(where, fmt.Sprintf("(%s)", uc.Predicate))
+	}
+	return fmt.Sprintf(
+		`SELECT count(*) FROM [%d AS tbl] WHERE %s`,
+		tbl.GetID(),
+		strings.Join(where, " AND "),
+	), nil
+}
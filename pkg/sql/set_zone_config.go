@@ -15,8 +15,10 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -138,6 +140,47 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 			c.InheritedLeasePreferences = false
 		},
 	},
+	"num_read_replicas": {
+		requiredType: types.Int,
+		setter:       func(c *zonepb.ZoneConfig, d tree.Datum) { c.NumReadReplicas = proto.Int32(int32(tree.MustBeDInt(d))) },
+		checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, _ tree.Datum) error {
+			return checkReadReplicasAllowed(ctx, execCfg)
+		},
+	},
+	"read_replica_constraints": {
+		requiredType: types.String,
+		setter: func(c *zonepb.ZoneConfig, d tree.Datum) {
+			readReplicaConstraintsList := zonepb.ConstraintsList{
+				Constraints: c.ReadReplicaConstraints,
+			}
+			loadYAML(&readReplicaConstraintsList, string(tree.MustBeDString(d)))
+			c.ReadReplicaConstraints = readReplicaConstraintsList.Constraints
+		},
+		checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, _ tree.Datum) error {
+			return checkReadReplicasAllowed(ctx, execCfg)
+		},
+	},
+	"read_replica_lag_target": {
+		requiredType: types.Interval,
+		setter: func(c *zonepb.ZoneConfig, d tree.Datum) {
+			lagTarget := time.Duration(tree.MustBeDInterval(d).Duration.Nanos())
+			c.ReadReplicaLagTarget = &lagTarget
+		},
+		checkAllowed: func(ctx context.Context, execCfg *ExecutorConfig, _ tree.Datum) error {
+			return checkReadReplicasAllowed(ctx, execCfg)
+		},
+	},
+}
+
+// checkReadReplicasAllowed returns an error if dedicated read replicas can't
+// be configured yet. Nodes which don't know about them would count read
+// replicas against the num_replicas of the zone and remove them.
+func checkReadReplicasAllowed(ctx context.Context, execCfg *ExecutorConfig) error {
+	if !execCfg.Settings.Version.IsActive(ctx, clusterversion.ReadReplicas) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"cannot configure read replicas before system is fully upgraded to v22.2")
+	}
+	return nil
 }
 
 // zoneOptionKeys contains the keys from suportedZoneConfigOptions in
@@ -695,6 +738,12 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 			if err := yaml.UnmarshalStrict([]byte(yamlConfig), &newZone); err != nil {
 				return pgerror.Wrap(err, pgcode.CheckViolation, "could not parse zone config")
 			}
+			if newZone.NumReadReplicas != nil || len(newZone.ReadReplicaConstraints) > 0 ||
+				newZone.ReadReplicaLagTarget != nil {
+				if err := checkReadReplicasAllowed(params.ctx, params.ExecCfg()); err != nil {
+					return err
+				}
+			}
 
 			// Load settings from YAML into the partial zone as well.
 			if err := yaml.UnmarshalStrict([]byte(yamlConfig), &finalZone); err != nil {
@@ -883,7 +932,10 @@ func validateNoRepeatKeysInZone(zone *zonepb.ZoneConfig) error {
 	if err := validateNoRepeatKeysInConjunction(zone.Constraints); err != nil {
 		return err
 	}
-	return validateNoRepeatKeysInConjunction(zone.VoterConstraints)
+	if err := validateNoRepeatKeysInConjunction(zone.VoterConstraints); err != nil {
+		return err
+	}
+	return validateNoRepeatKeysInConjunction(zone.ReadReplicaConstraints)
 }
 
 func validateNoRepeatKeysInConjunction(conjunctions []zonepb.ConstraintsConjunction) error {
@@ -947,6 +999,11 @@ func accumulateUniqueConstraints(zone *zonepb.ZoneConfig) []zonepb.Constraint {
 			addToValidate(constraint)
 		}
 	}
+	for _, constraints := range zone.ReadReplicaConstraints {
+		for _, constraint := range constraints.Constraints {
+			addToValidate(constraint)
+		}
+	}
 	for _, leasePreferences := range zone.LeasePreferences {
 		for _, constraint := range leasePreferences.Constraints {
 			addToValidate(constraint)
@@ -967,7 +1024,8 @@ func validateZoneAttrsAndLocalities(
 	ctx context.Context, execCfg *ExecutorConfig, zone *zonepb.ZoneConfig,
 ) error {
 	// Avoid RPCs to the Node/Region server if we don't have anything to validate.
-	if len(zone.Constraints) == 0 && len(zone.VoterConstraints) == 0 && len(zone.LeasePreferences) == 0 {
+	if len(zone.Constraints) == 0 && len(zone.VoterConstraints) == 0 &&
+		len(zone.ReadReplicaConstraints) == 0 && len(zone.LeasePreferences) == 0 {
 		return nil
 	}
 	if execCfg.Codec.ForSystemTenant() {
@@ -142,6 +142,26 @@ func ShowCreateTable(
 	for _, idx := range desc.PublicNonPrimaryIndexes() {
 		// Showing the primary index is handled above.
 
+		// Indexes backing exclusion constraints are shown as constraints.
+		if idx.IsExclusion() {
+			f.WriteString(",\n\tCONSTRAINT ")
+			f.FormatNameP(&idx.IndexDesc().Name)
+			f.WriteByte(' ')
+			exclStr, err := catformat.ExclusionConstraintForDisplay(
+				ctx,
+				desc,
+				idx,
+				tree.FmtSimple,
+				p.RunParams(ctx).p.SemaCtx(),
+				p.RunParams(ctx).p.SessionData(),
+			)
+			if err != nil {
+				return "", err
+			}
+			f.WriteString(exclStr)
+			continue
+		}
+
 		// Build the PARTITION BY clause.
 		var partitionBuf bytes.Buffer
 		if err := ShowCreatePartitioning(
@@ -196,6 +216,10 @@ func ShowCreateTable(
 		}
 	}
 
+	if err := showTriggers(ctx, p, tn, desc, &f.Buffer); err != nil {
+		return "", err
+	}
+
 	return f.CloseAndGetString(), nil
 }
 
@@ -24,7 +24,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	plpgsqlparser "github.com/cockroachdb/cockroach/pkg/sql/plpgsql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/plpgsqltree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -177,19 +179,25 @@ func formatViewQueryForDisplay(
 	return sequenceReplacedViewQuery
 }
 
-// formatQuerySequencesForDisplay walks the view query and
-// looks for sequence IDs in the statement. If it finds any,
-// it will replace the IDs with the descriptor's fully qualified name.
-func formatQuerySequencesForDisplay(
-	ctx context.Context, semaCtx *tree.SemaContext, queries string, multiStmt bool,
-) (string, error) {
-	replaceFunc := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+// makeSeqIDDisplayFunc returns a tree.SimpleVisitFn that replaces the IDs of
+// the sequences used by an expression with their fully qualified names.
+func makeSeqIDDisplayFunc(ctx context.Context, semaCtx *tree.SemaContext) tree.SimpleVisitFn {
+	return func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
 		newExpr, err = schemaexpr.ReplaceIDsWithFQNames(ctx, expr, semaCtx)
 		if err != nil {
 			return false, expr, err
 		}
 		return false, newExpr, nil
 	}
+}
+
+// formatQuerySequencesForDisplay walks the view query and
+// looks for sequence IDs in the statement. If it finds any,
+// it will replace the IDs with the descriptor's fully qualified name.
+func formatQuerySequencesForDisplay(
+	ctx context.Context, semaCtx *tree.SemaContext, queries string, multiStmt bool,
+) (string, error) {
+	replaceFunc := makeSeqIDDisplayFunc(ctx, semaCtx)
 
 	var stmts tree.Statements
 	if multiStmt {
@@ -281,20 +289,13 @@ func formatViewQueryTypesForDisplay(
 	return newStmt.String(), nil
 }
 
-// formatFunctionQueryTypesForDisplay is similar to
-// formatViewQueryTypesForDisplay but can only be used for function.
-// nil is used as the table descriptor for schemaexpr.FormatExprForDisplay call.
-// This is fine assuming that UDFs cannot be created with expression casting a
-// column/var to an enum in function body. This is super rare case for now, and
-// it's tracked with issue #87475. We should also unify this function with
-// formatViewQueryTypesForDisplay.
-func formatFunctionQueryTypesForDisplay(
-	ctx context.Context,
-	semaCtx *tree.SemaContext,
-	sessionData *sessiondata.SessionData,
-	queries string,
-) (string, error) {
-	replaceFunc := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+// makeFunctionTypeDisplayFunc returns a tree.SimpleVisitFn that deserializes
+// the user-defined types of the casts and type annotations of an expression in
+// the body of a function to display their names.
+func makeFunctionTypeDisplayFunc(
+	ctx context.Context, semaCtx *tree.SemaContext, sessionData *sessiondata.SessionData,
+) tree.SimpleVisitFn {
+	return func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
 		// We need to resolve the type to check if it's user-defined. If not,
 		// no other work is needed.
 		var typRef tree.ResolvableTypeReference
@@ -326,6 +327,22 @@ func formatFunctionQueryTypesForDisplay(
 		}
 		return false, newExpr, nil
 	}
+}
+
+// formatFunctionQueryTypesForDisplay is similar to
+// formatViewQueryTypesForDisplay but can only be used for function.
+// nil is used as the table descriptor for schemaexpr.FormatExprForDisplay call.
+// This is fine assuming that UDFs cannot be created with expression casting a
+// column/var to an enum in function body. This is super rare case for now, and
+// it's tracked with issue #87475. We should also unify this function with
+// formatViewQueryTypesForDisplay.
+func formatFunctionQueryTypesForDisplay(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	sessionData *sessiondata.SessionData,
+	queries string,
+) (string, error) {
+	replaceFunc := makeFunctionTypeDisplayFunc(ctx, semaCtx, sessionData)
 
 	var stmts tree.Statements
 	parsedStmts, err := parser.Parse(queries)
@@ -352,6 +369,98 @@ func formatFunctionQueryTypesForDisplay(
 	return fmtCtx.CloseAndGetString(), nil
 }
 
+// formatPLpgSQLFuncBodyForDisplay replaces the sequence IDs and user-defined
+// type OIDs in the body of a PL/pgSQL function with their fully qualified
+// names. It is the inverse of serializePLpgSQLFuncBody.
+func formatPLpgSQLFuncBodyForDisplay(
+	ctx context.Context, semaCtx *tree.SemaContext, funcBody string,
+) (string, error) {
+	block, err := plpgsqlparser.Parse(funcBody)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse function body")
+	}
+	if err := plpgsqltree.SimpleVisit(
+		block, makeSeqIDDisplayFunc(ctx, semaCtx), nil, /* typFn */
+	); err != nil {
+		return "", err
+	}
+	var typErr error
+	fmtCtx := tree.NewFmtCtx(
+		tree.FmtSimple,
+		tree.FmtIndexedTypeFormat(func(fmtCtx *tree.FmtCtx, ref *tree.OIDTypeReference) {
+			typ, err := tree.ResolveType(ctx, ref, semaCtx.TypeResolver)
+			if err != nil {
+				typErr = err
+				fmtCtx.WriteString(ref.SQLString())
+				return
+			}
+			fmtCtx.WriteString(typ.SQLString())
+		}),
+	)
+	fmtCtx.FormatNode(block)
+	formatted := fmtCtx.CloseAndGetString()
+	if typErr != nil {
+		return "", typErr
+	}
+	return formatted, nil
+}
+
+// showTriggers prints out the CREATE TRIGGER statements sufficient to recreate
+// a table's triggers.
+func showTriggers(
+	ctx context.Context,
+	p PlanHookState,
+	tn *tree.TableName,
+	table catalog.TableDescriptor,
+	buf *bytes.Buffer,
+) error {
+	triggers := table.GetTriggers()
+	if len(triggers) == 0 {
+		return nil
+	}
+	planner := p.RunParams(ctx).p
+	f := tree.NewFmtCtx(tree.FmtSimple)
+	for i := range triggers {
+		trig := &triggers[i]
+		fnDesc, err := planner.Descriptors().GetImmutableFunctionByID(
+			ctx, planner.Txn(), trig.FuncID, tree.ObjectLookupFlagsWithRequired(),
+		)
+		if err != nil {
+			return err
+		}
+		scDesc, err := planner.Descriptors().GetImmutableSchemaByID(
+			ctx, planner.Txn(), fnDesc.GetParentSchemaID(), tree.SchemaLookupFlags{Required: true},
+		)
+		if err != nil {
+			return err
+		}
+		n := tree.CreateTrigger{
+			Name:  tree.Name(trig.Name),
+			Table: *tn,
+			FuncName: tree.MakeFunctionNameFromPrefix(
+				tree.ObjectNamePrefix{SchemaName: tree.Name(scDesc.GetName()), ExplicitSchema: true},
+				tree.Name(fnDesc.GetName()),
+			),
+		}
+		if trig.ActionTime == descpb.TriggerDescriptor_AFTER {
+			n.ActionTime = tree.TriggerActionTimeAfter
+		}
+		if trig.OnInsert {
+			n.Events = append(n.Events, tree.TriggerEventInsert)
+		}
+		if trig.OnUpdate {
+			n.Events = append(n.Events, tree.TriggerEventUpdate)
+		}
+		if trig.OnDelete {
+			n.Events = append(n.Events, tree.TriggerEventDelete)
+		}
+		f.WriteString(";\n")
+		f.FormatNode(&n)
+	}
+	buf.WriteString(f.CloseAndGetString())
+	return nil
+}
+
 // showComments prints out the COMMENT statements sufficient to populate a
 // table's comments, including its index and column comments.
 func showComments(
@@ -485,12 +594,25 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	writeConstraintDeferrability(buf, fk.Deferrable, fk.InitiallyDeferred)
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
 	return nil
 }
 
+// writeConstraintDeferrability writes the DEFERRABLE clause of a constraint.
+// Nothing is written for constraints that are not deferrable.
+func writeConstraintDeferrability(buf *bytes.Buffer, deferrable, initiallyDeferred bool) {
+	if !deferrable {
+		return
+	}
+	buf.WriteString(" DEFERRABLE")
+	if initiallyDeferred {
+		buf.WriteString(" INITIALLY DEFERRED")
+	}
+}
+
 // ShowCreateSequence returns a valid SQL representation of the
 // CREATE SEQUENCE statement used to create the given sequence.
 func ShowCreateSequence(
@@ -731,6 +853,7 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		writeConstraintDeferrability(&f.Buffer, c.Deferrable, c.InitiallyDeferred)
 		if c.IsPartial() {
 			f.WriteString(" WHERE ")
 			pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.Predicate, semaCtx, sessionData, tree.FmtParsable)
@@ -194,6 +194,13 @@ func zoneConfigToSQL(zs *tree.ZoneSpecifier, zone *zonepb.ZoneConfig) (string, e
 		return "", err
 	}
 	prefs = strings.TrimSpace(prefs)
+	readReplicaConstraints, err := yamlMarshalFlow(zonepb.ConstraintsList{
+		Constraints: zone.ReadReplicaConstraints,
+	})
+	if err != nil {
+		return "", err
+	}
+	readReplicaConstraints = strings.TrimSpace(readReplicaConstraints)
 
 	useComma := false
 	maybeWriteComma := func(f *tree.FmtCtx) {
@@ -243,6 +250,17 @@ func zoneConfigToSQL(zs *tree.ZoneSpecifier, zone *zonepb.ZoneConfig) (string, e
 		maybeWriteComma(f)
 		f.Printf("\tlease_preferences = %s", lexbase.EscapeSQLString(prefs))
 	}
+	if zone.NumReadReplicas != nil {
+		maybeWriteComma(f)
+		f.Printf("\tnum_read_replicas = %d", *zone.NumReadReplicas)
+		maybeWriteComma(f)
+		f.Printf("\tread_replica_constraints = %s", lexbase.EscapeSQLString(readReplicaConstraints))
+	}
+	if zone.ReadReplicaLagTarget != nil {
+		maybeWriteComma(f)
+		f.Printf("\tread_replica_lag_target = %s",
+			lexbase.EscapeSQLString(zone.ReadReplicaLagTarget.String()))
+	}
 	return f.String(), nil
 }
 
@@ -289,6 +289,10 @@ func tableTypeCanHaveStats(table catalog.TableDescriptor) bool {
 		// Don't try to get statistics for views.
 		return false
 	}
+	if table.IsBackupTable() {
+		// The rows of tables attached from a backup are not stored in KV.
+		return false
+	}
 	return true
 }
 
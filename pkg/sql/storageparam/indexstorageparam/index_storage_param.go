@@ -16,6 +16,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/paramparse"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -107,6 +108,13 @@ func (po *Setter) applyGeometryIndexSetting(
 	return nil
 }
 
+func (po *Setter) getOrCreateSSTOptions() *catpb.SSTOptions {
+	if po.IndexDesc.SSTOptions == nil {
+		po.IndexDesc.SSTOptions = &catpb.SSTOptions{}
+	}
+	return po.IndexDesc.SSTOptions
+}
+
 // Set implements the Setter interface.
 func (po *Setter) Set(
 	ctx context.Context,
@@ -126,6 +134,20 @@ func (po *Setter) Set(
 		return po.applyS2ConfigSetting(ctx, evalCtx, key, expr, 1, 32)
 	case `geometry_min_x`, `geometry_max_x`, `geometry_min_y`, `geometry_max_y`:
 		return po.applyGeometryIndexSetting(ctx, evalCtx, key, expr)
+	case `compression`:
+		compression, err := storageparam.GetSSTCompression(ctx, evalCtx, key, expr)
+		if err != nil {
+			return err
+		}
+		po.getOrCreateSSTOptions().Compression = compression
+		return nil
+	case `block_size`:
+		blockSize, err := storageparam.GetSSTBlockSize(ctx, evalCtx, key, expr)
+		if err != nil {
+			return err
+		}
+		po.getOrCreateSSTOptions().BlockSize = blockSize
+		return nil
 	// `bucket_count` is handled in schema changer when creating hash sharded
 	// indexes.
 	case `bucket_count`:
@@ -14,7 +14,9 @@ package storageparam
 
 import (
 	"context"
+	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/paramparse"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -112,3 +114,64 @@ func SetFillFactor(ctx context.Context, evalCtx *eval.Context, key string, datum
 	}
 	return nil
 }
+
+// sstCompressions are the values of the compression storage param.
+var sstCompressions = []string{"none", "snappy", "zstd"}
+
+// Bounds of the block_size storage param.
+const (
+	minSSTBlockSize = 1 << 10
+	maxSSTBlockSize = 16 << 20
+)
+
+// checkSSTOptionsSupported returns an error if the cluster does not support
+// the compression and block_size storage params yet.
+func checkSSTOptionsSupported(ctx context.Context, evalCtx *eval.Context, key string) error {
+	if evalCtx != nil && !evalCtx.Settings.Version.IsActive(ctx, clusterversion.SSTStorageParams) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"storage parameter %q is not supported until upgrade to version %s is finalized",
+			key, clusterversion.ByKey(clusterversion.SSTStorageParams))
+	}
+	return nil
+}
+
+// GetSSTCompression validates the compression storage param and returns its
+// value.
+func GetSSTCompression(
+	ctx context.Context, evalCtx *eval.Context, key string, datum tree.Datum,
+) (string, error) {
+	if err := checkSSTOptionsSupported(ctx, evalCtx, key); err != nil {
+		return "", err
+	}
+	val, err := paramparse.DatumAsString(ctx, evalCtx, key, datum)
+	if err != nil {
+		return "", err
+	}
+	val = strings.ToLower(val)
+	for _, compression := range sstCompressions {
+		if val == compression {
+			return val, nil
+		}
+	}
+	return "", pgerror.Newf(pgcode.InvalidParameterValue,
+		"%q must be one of %s", key, strings.Join(sstCompressions, ", "))
+}
+
+// GetSSTBlockSize validates the block_size storage param and returns its
+// value.
+func GetSSTBlockSize(
+	ctx context.Context, evalCtx *eval.Context, key string, datum tree.Datum,
+) (int64, error) {
+	if err := checkSSTOptionsSupported(ctx, evalCtx, key); err != nil {
+		return 0, err
+	}
+	val, err := paramparse.DatumAsInt(ctx, evalCtx, key, datum)
+	if err != nil {
+		return 0, err
+	}
+	if val < minSSTBlockSize || val > maxSSTBlockSize {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%q must be between %d and %d", key, minSSTBlockSize, maxSSTBlockSize)
+	}
+	return val, nil
+}
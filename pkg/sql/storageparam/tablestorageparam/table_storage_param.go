@@ -123,6 +123,21 @@ func (po *Setter) getOrCreateRowLevelTTL() *catpb.RowLevelTTL {
 	return rowLevelTTL
 }
 
+func (po *Setter) getOrCreateSSTOptions() *catpb.SSTOptions {
+	if po.TableDesc.SSTOptions == nil {
+		po.TableDesc.SSTOptions = &catpb.SSTOptions{}
+	}
+	return po.TableDesc.SSTOptions
+}
+
+// maybeClearSSTOptions clears the SST options of the table once none of them
+// is set anymore.
+func (po *Setter) maybeClearSSTOptions() {
+	if opts := po.TableDesc.SSTOptions; opts != nil && *opts == (catpb.SSTOptions{}) {
+		po.TableDesc.SSTOptions = nil
+	}
+}
+
 type tableParam struct {
 	onSet   func(ctx context.Context, po *Setter, semaCtx *tree.SemaContext, evalCtx *eval.Context, key string, datum tree.Datum) error
 	onReset func(ctx context.Context, po *Setter, evalCtx *eval.Context, key string) error
@@ -462,6 +477,42 @@ var tableParams = map[string]tableParam{
 			return nil
 		},
 	},
+	`compression`: {
+		onSet: func(ctx context.Context, po *Setter, semaCtx *tree.SemaContext,
+			evalCtx *eval.Context, key string, datum tree.Datum) error {
+			compression, err := storageparam.GetSSTCompression(ctx, evalCtx, key, datum)
+			if err != nil {
+				return err
+			}
+			po.getOrCreateSSTOptions().Compression = compression
+			return nil
+		},
+		onReset: func(_ context.Context, po *Setter, evalCtx *eval.Context, key string) error {
+			if opts := po.TableDesc.SSTOptions; opts != nil {
+				opts.Compression = ""
+				po.maybeClearSSTOptions()
+			}
+			return nil
+		},
+	},
+	`block_size`: {
+		onSet: func(ctx context.Context, po *Setter, semaCtx *tree.SemaContext,
+			evalCtx *eval.Context, key string, datum tree.Datum) error {
+			blockSize, err := storageparam.GetSSTBlockSize(ctx, evalCtx, key, datum)
+			if err != nil {
+				return err
+			}
+			po.getOrCreateSSTOptions().BlockSize = blockSize
+			return nil
+		},
+		onReset: func(_ context.Context, po *Setter, evalCtx *eval.Context, key string) error {
+			if opts := po.TableDesc.SSTOptions; opts != nil {
+				opts.BlockSize = 0
+				po.maybeClearSSTOptions()
+			}
+			return nil
+		},
+	},
 	catpb.AutoStatsEnabledTableSettingName: {
 		onSet:   autoStatsEnabledSettingFunc,
 		onReset: autoStatsTableSettingResetFunc,
@@ -146,6 +146,10 @@ type tableWriterBase struct {
 	forceProductionBatchSizes bool
 	// sv settings values for cluster settings
 	sv *settings.Values
+	// hasPendingNotifications, if set, returns whether the transaction has sent
+	// notifications. They are written when the transaction commits, so they
+	// prevent the auto commit.
+	hasPendingNotifications func() bool
 }
 
 var maxBatchBytes = settings.RegisterByteSizeSetting(
@@ -175,6 +179,9 @@ func (tb *tableWriterBase) init(
 	}
 	tb.maxBatchByteSize = mutations.MaxBatchByteSize(batchMaxBytes, tb.forceProductionBatchSizes)
 	tb.sv = settings
+	if evalCtx != nil && evalCtx.Planner != nil {
+		tb.hasPendingNotifications = evalCtx.Planner.HasPendingNotifications
+	}
 	tb.initNewBatch()
 	return nil
 }
@@ -220,7 +227,11 @@ func (tb *tableWriterBase) finalize(ctx context.Context) (err error) {
 		// Also, we don't want to try to commit here if the deadline is expired.
 		// If we bubble back up to SQL then maybe we can get a fresh deadline
 		// before committing.
-		!tb.txn.DeadlineLikelySufficient(tb.sv) {
+		!tb.txn.DeadlineLikelySufficient(tb.sv) &&
+		// Notifications sent by the statement, e.g. with pg_notify() or from a
+		// trigger, are written by the connExecutor in the same transaction
+		// before it commits.
+		(tb.hasPendingNotifications == nil || !tb.hasPendingNotifications()) {
 		log.Event(ctx, "autocommit enabled")
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
@@ -26,6 +26,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -73,6 +75,10 @@ func (t *truncateNode) startExec(params runParams) error {
 		if err := p.CheckPrivilege(ctx, tableDesc, privilege.DROP); err != nil {
 			return err
 		}
+		if tableDesc.IsBackupTable() {
+			return pgerror.Newf(pgcode.WrongObjectType,
+				"cannot truncate table %q attached from a backup", tableDesc.GetName())
+		}
 
 		toTruncate[tableDesc.ID] = tn.FQString()
 		toTraverse = append(toTraverse, *tableDesc)
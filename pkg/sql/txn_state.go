@@ -96,6 +96,10 @@ type txnState struct {
 	// The transaction's priority.
 	priority roachpb.UserPriority
 
+	// The transaction's isolation level. It is only set for transactions which
+	// were created by resetForNewSQLTxn, not for transactions passed in.
+	isoLevel enginepb.IsolationLevel
+
 	// The transaction's read only state.
 	readOnly bool
 
@@ -161,6 +165,10 @@ const (
 //
 //	not nil.
 //
+// isoLevel: The transaction's isolation level. Pass enginepb.SERIALIZABLE if
+//
+//	the txn arg is not nil.
+//
 // readOnly: The read-only character of the new txn.
 // txn: If not nil, this txn will be used instead of creating a new txn. If so,
 //
@@ -177,6 +185,7 @@ func (ts *txnState) resetForNewSQLTxn(
 	sqlTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
 	priority roachpb.UserPriority,
+	isoLevel enginepb.IsolationLevel,
 	readOnly tree.ReadWriteMode,
 	txn *kv.Txn,
 	tranCtx transitionCtx,
@@ -185,6 +194,7 @@ func (ts *txnState) resetForNewSQLTxn(
 	// Reset state vars to defaults.
 	ts.sqlTimestamp = sqlTimestamp
 	ts.isHistorical = false
+	ts.isoLevel = enginepb.SERIALIZABLE
 	ts.lastEpoch = 0
 
 	// Create a context for this transaction. It will include a root span that
@@ -224,10 +234,16 @@ func (ts *txnState) resetForNewSQLTxn(
 			if err := ts.setPriorityLocked(priority); err != nil {
 				panic(err)
 			}
+			if err := ts.setIsoLevelLocked(isoLevel); err != nil {
+				panic(err)
+			}
 		} else {
 			if priority != roachpb.UnspecifiedUserPriority {
 				panic(errors.AssertionFailedf("unexpected priority when using an existing txn: %s", priority))
 			}
+			if isoLevel != enginepb.SERIALIZABLE {
+				panic(errors.AssertionFailedf("unexpected isolation level when using an existing txn: %s", isoLevel))
+			}
 			ts.mu.txn = txn
 		}
 
@@ -336,6 +352,20 @@ func (ts *txnState) setPriorityLocked(userPriority roachpb.UserPriority) error {
 	return nil
 }
 
+func (ts *txnState) setIsoLevel(isoLevel enginepb.IsolationLevel) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.setIsoLevelLocked(isoLevel)
+}
+
+func (ts *txnState) setIsoLevelLocked(isoLevel enginepb.IsolationLevel) error {
+	if err := ts.mu.txn.SetIsoLevel(isoLevel); err != nil {
+		return err
+	}
+	ts.isoLevel = isoLevel
+	return nil
+}
+
 func (ts *txnState) setReadOnlyMode(mode tree.ReadWriteMode) error {
 	switch mode {
 	case tree.UnspecifiedReadWriteMode:
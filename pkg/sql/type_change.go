@@ -106,6 +106,9 @@ func (p *planner) writeTypeSchemaChange(
 	// Check if there is a cached specification for this type, otherwise create one.
 	record, recordExists := p.extendedEvalCtx.SchemaChangeJobRecords[typeDesc.ID]
 	transitioningMembers, beingDropped := findTransitioningMembers(typeDesc)
+	// A job that validates domain constraints must be cancelable so that it
+	// fails, and rolls back the constraints, when the validation fails.
+	cancelable := beingDropped || domainHasValidatingConstraints(typeDesc)
 	if recordExists {
 		// Update it.
 		newDetails := jobspb.TypeSchemaChangeDetails{
@@ -123,8 +126,8 @@ func (p *planner) writeTypeSchemaChange(
 					return nonCancelable
 				}
 				// Type change jobs are non-cancelable unless an enum member is being
-				// dropped.
-				return !beingDropped
+				// dropped or a domain constraint is being validated.
+				return !cancelable
 			})
 		log.Infof(ctx, "job %d: updated with type change for type %d", record.JobID, typeDesc.ID)
 	} else {
@@ -140,8 +143,9 @@ func (p *planner) writeTypeSchemaChange(
 			},
 			Progress: jobspb.TypeSchemaChangeProgress{},
 			// Type change jobs in general are not cancelable, unless they include
-			// a transition that drops an enum member.
-			NonCancelable: !beingDropped,
+			// a transition that drops an enum member or validate a domain
+			// constraint.
+			NonCancelable: !cancelable,
 		}
 		p.extendedEvalCtx.SchemaChangeJobRecords[typeDesc.ID] = &newRecord
 		log.Infof(ctx, "queued new type change job %d for type %d", newRecord.JobID, typeDesc.ID)
@@ -484,6 +488,18 @@ func (t *typeSchemaChanger) exec(ctx context.Context) error {
 		}
 	}
 
+	if typeDesc.GetKind() == descpb.TypeDescriptor_DOMAIN &&
+		domainHasValidatingConstraints(typeDesc) {
+		if err := t.validateDomainConstraints(ctx); err != nil {
+			return err
+		}
+
+		// Make sure all of the type descriptor leases are updated.
+		if err := refreshTypeDescriptorLeases(ctx, leaseMgr, typeDesc); err != nil {
+			return err
+		}
+	}
+
 	// If the type is being dropped, remove the descriptor here.
 	if typeDesc.Dropped() {
 		if err := t.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
@@ -592,6 +608,115 @@ func (t *typeSchemaChanger) cleanupEnumValues(ctx context.Context) error {
 	return DescsTxn(ctx, t.execCfg, cleanup)
 }
 
+// domainHasValidatingConstraints returns whether the given type is a domain
+// with CHECK constraints that are being added.
+func domainHasValidatingConstraints(typeDesc catalog.TypeDescriptor) bool {
+	if typeDesc.GetKind() != descpb.TypeDescriptor_DOMAIN {
+		return false
+	}
+	for _, c := range typeDesc.TypeDesc().DomainConstraints {
+		if c.Validity == descpb.ConstraintValidity_Validating {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDomainConstraints validates the existing values of a domain against
+// the CHECK constraints that are being added to it, and marks the constraints
+// as validated. The values are validated in a separate txn from the one that
+// mutates the descriptor, as this validation can take arbitrarily long.
+func (t *typeSchemaChanger) validateDomainConstraints(ctx context.Context) error {
+	var validated []string
+	validate := func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		validated = validated[:0]
+		typeDesc, err := descsCol.GetImmutableTypeByID(ctx, txn, t.typeID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{
+				AvoidLeased: true,
+				Required:    true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		for i := range typeDesc.TypeDesc().DomainConstraints {
+			c := &typeDesc.TypeDesc().DomainConstraints[i]
+			if c.Validity != descpb.ConstraintValidity_Validating {
+				continue
+			}
+			if err := validateDomainConstraint(
+				ctx, txn, descsCol, t.execCfg.InternalExecutor, typeDesc, c,
+			); err != nil {
+				return err
+			}
+			validated = append(validated, c.Name)
+		}
+		return nil
+	}
+	if err := DescsTxn(ctx, t.execCfg, validate); err != nil {
+		return err
+	}
+
+	run := func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		typeDesc, err := descsCol.GetMutableTypeVersionByID(ctx, txn, t.typeID)
+		if err != nil {
+			return err
+		}
+		for i := range typeDesc.DomainConstraints {
+			c := &typeDesc.DomainConstraints[i]
+			for _, name := range validated {
+				if c.Name == name && c.Validity == descpb.ConstraintValidity_Validating {
+					c.Validity = descpb.ConstraintValidity_Validated
+				}
+			}
+		}
+		b := txn.NewBatch()
+		if err := descsCol.WriteDescToBatch(
+			ctx, true /* kvTrace */, typeDesc, b,
+		); err != nil {
+			return err
+		}
+		// The version of the array type needs to get bumped as well so that
+		// changes to the element type are picked up.
+		arrayTypeDesc, err := descsCol.GetMutableTypeVersionByID(ctx, txn, typeDesc.ArrayTypeID)
+		if err != nil {
+			return err
+		}
+		if err := descsCol.WriteDescToBatch(
+			ctx, true /* kvTrace */, arrayTypeDesc, b,
+		); err != nil {
+			return err
+		}
+		return txn.Run(ctx, b)
+	}
+	return DescsTxn(ctx, t.execCfg, run)
+}
+
+// cleanupDomainConstraints removes the CHECK constraints that were being added
+// to a domain if their validation fails.
+func (t *typeSchemaChanger) cleanupDomainConstraints(ctx context.Context) error {
+	cleanup := func(ctx context.Context, txn *kv.Txn, descsCol *descs.Collection) error {
+		typeDesc, err := descsCol.GetMutableTypeVersionByID(ctx, txn, t.typeID)
+		if err != nil {
+			return err
+		}
+		// No cleanup required.
+		if !domainHasValidatingConstraints(typeDesc) {
+			return nil
+		}
+		idx := 0
+		for _, c := range typeDesc.DomainConstraints {
+			if c.Validity != descpb.ConstraintValidity_Validating {
+				typeDesc.DomainConstraints[idx] = c
+				idx++
+			}
+		}
+		typeDesc.DomainConstraints = typeDesc.DomainConstraints[:idx]
+		return descsCol.WriteDesc(ctx, true /* kvTrace */, typeDesc, txn)
+	}
+	return DescsTxn(ctx, t.execCfg, cleanup)
+}
+
 // convertToSQLStringRepresentation takes an array of bytes (the physical
 // representation of an enum) and converts it into a string that can be used
 // in a SQL predicate.
@@ -1303,6 +1428,10 @@ func (t *typeChangeResumer) OnFailOrCancel(
 			return err
 		}
 
+		if err := tc.cleanupDomainConstraints(ctx); err != nil {
+			return err
+		}
+
 		if fn := tc.execCfg.TypeSchemaChangerTestingKnobs.RunAfterOnFailOrCancel; fn != nil {
 			return fn()
 		}
@@ -95,6 +95,9 @@ var OidToType = map[oid.Oid]*T{
 	oid.T_timetz:       TimeTZ,
 	oid.T_timestamp:    Timestamp,
 	oid.T_timestamptz:  TimestampTZ,
+	oid.T_trigger:      Trigger,
+	oid.T_tsquery:      TSQuery,
+	oid.T_tsvector:     TSVector,
 	oid.T_unknown:      Unknown,
 	oid.T_uuid:         Uuid,
 	oid.T_varbit:       VarBit,
@@ -140,6 +143,8 @@ var oidToArrayOid = map[oid.Oid]oid.Oid{
 	oid.T_timetz:       oid.T__timetz,
 	oid.T_timestamp:    oid.T__timestamp,
 	oid.T_timestamptz:  oid.T__timestamptz,
+	oid.T_tsquery:      oid.T__tsquery,
+	oid.T_tsvector:     oid.T__tsvector,
 	oid.T_uuid:         oid.T__uuid,
 	oid.T_varbit:       oid.T__varbit,
 	oid.T_varchar:      oid.T__varchar,
@@ -174,6 +179,8 @@ var familyToOid = map[Family]oid.Oid{
 	JsonFamily:           oid.T_jsonb,
 	TupleFamily:          oid.T_record,
 	BitFamily:            oid.T_bit,
+	TSQueryFamily:        oid.T_tsquery,
+	TSVectorFamily:       oid.T_tsvector,
 	AnyFamily:            oid.T_anyelement,
 
 	GeometryFamily:  oidext.T_geometry,
@@ -194,6 +201,10 @@ func init() {
 // CalcArrayOid returns the OID of the array type having elements of the given
 // type.
 func CalcArrayOid(elemTyp *T) oid.Oid {
+	if elemTyp.IsDomain() {
+		// Domains have their own array types, like enums.
+		return elemTyp.UserDefinedArrayOID()
+	}
 	o := elemTyp.Oid()
 	switch elemTyp.Family() {
 	case ArrayFamily:
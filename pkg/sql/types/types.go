@@ -197,6 +197,27 @@ type UserDefinedTypeMetadata struct {
 
 	// enumData is non-nil iff the metadata is for an ENUM type.
 	EnumData *EnumMetadata
+
+	// DomainData is non-nil iff the metadata is for a DOMAIN type.
+	DomainData *DomainMetadata
+}
+
+// DomainMetadata is metadata about a DOMAIN needed for evaluation.
+type DomainMetadata struct {
+	// NotNull is true if the domain does not allow NULL values.
+	NotNull bool
+	// DefaultExpr is the serialized default expression of the domain, or nil
+	// if it has none.
+	DefaultExpr *string
+	// CheckNames and CheckExprs are the names and the serialized expressions
+	// of the CHECK constraints of the domain that new values must satisfy.
+	// Within an expression, the value being checked is referred to as VALUE.
+	CheckNames []string
+	CheckExprs []string
+	// TypedCheckExprs is the []tree.TypedExpr of the CHECK constraints in
+	// CheckExprs, parsed and type checked once when the type is hydrated. It
+	// is opaque here because this package cannot depend on tree.
+	TypedCheckExprs interface{}
 }
 
 // EnumMetadata is metadata about an ENUM needed for evaluation.
@@ -469,6 +490,24 @@ var (
 		},
 	}
 
+	// TSQuery is the type of a query for full text search.
+	TSQuery = &T{
+		InternalType: InternalType{
+			Family: TSQueryFamily,
+			Oid:    oid.T_tsquery,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSVector is the type of a document processed for full text search.
+	TSVector = &T{
+		InternalType: InternalType{
+			Family: TSVectorFamily,
+			Oid:    oid.T_tsvector,
+			Locale: &emptyLocale,
+		},
+	}
+
 	// Void is the type representing void.
 	Void = &T{
 		InternalType: InternalType{
@@ -478,6 +517,17 @@ var (
 		},
 	}
 
+	// Trigger is the pseudo-type returned by trigger functions. Values of this
+	// type never exist; a trigger function instead returns a row of the table
+	// it fires on.
+	Trigger = &T{
+		InternalType: InternalType{
+			Family: TriggerFamily,
+			Oid:    oid.T_trigger,
+			Locale: &emptyLocale,
+		},
+	}
+
 	// EncodedKey is a special type used internally for passing encoded key data.
 	// It behaves similarly to Bytes in most circumstances, except
 	// encoding/decoding. It is currently used to pass around inverted index keys,
@@ -517,6 +567,8 @@ var (
 		TimeTZ,
 		Jsonb,
 		VarBit,
+		TSQuery,
+		TSVector,
 	}
 
 	// Any is a special type used only during static analysis as a wildcard type
@@ -1111,6 +1163,20 @@ func MakeEnum(typeOID, arrayTypeOID oid.Oid) *T {
 	}}
 }
 
+// MakeDomain constructs a new instance of a domain over the given base type
+// with the given stable type ID. A domain has the same family and physical
+// representation as its base type. Note that it does not hydrate cached fields
+// on the type.
+func MakeDomain(typeOID, arrayTypeOID oid.Oid, base *T) *T {
+	internal := base.InternalType
+	internal.Oid = typeOID
+	internal.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID:  arrayTypeOID,
+		DomainBaseOID: base.Oid(),
+	}
+	return &T{InternalType: internal}
+}
+
 // MakeArray constructs a new instance of an ArrayFamily type with the given
 // element type (which may itself be an ArrayFamily type).
 func MakeArray(typ *T) *T {
@@ -1284,6 +1350,10 @@ func (t *T) TypeModifier() int32 {
 // precision. If the given type already has no type modifiers, it is returned
 // unchanged and the function does not allocate a new type.
 func (t *T) WithoutTypeModifiers() *T {
+	if t.IsDomain() {
+		// The type modifiers of a domain are part of its definition.
+		return t
+	}
 	switch t.Family() {
 	case ArrayFamily:
 		// Remove type modifiers of the array content type.
@@ -1389,6 +1459,23 @@ func (t *T) UserDefined() bool {
 	return IsOIDUserDefinedType(t.Oid())
 }
 
+// IsDomain returns whether or not t is a domain type.
+func (t *T) IsDomain() bool {
+	return t.InternalType.UDTMetadata != nil && t.InternalType.UDTMetadata.DomainBaseOID != 0
+}
+
+// DomainBaseType returns the base type of a domain type. The base type has
+// the same type modifiers as the domain. It panics if t is not a domain.
+func (t *T) DomainBaseType() *T {
+	if !t.IsDomain() {
+		panic(errors.AssertionFailedf("type %s is not a domain", t.SQLString()))
+	}
+	base := &T{InternalType: t.InternalType}
+	base.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+	base.InternalType.UDTMetadata = nil
+	return base
+}
+
 // IsOIDUserDefinedType returns whether or not o corresponds to a user
 // defined type.
 func IsOIDUserDefinedType(o oid.Oid) bool {
@@ -1419,6 +1506,9 @@ var familyNames = map[Family]string{
 	TimestampFamily:      "timestamp",
 	TimestampTZFamily:    "timestamptz",
 	TimeTZFamily:         "timetz",
+	TSQueryFamily:        "tsquery",
+	TSVectorFamily:       "tsvector",
+	TriggerFamily:        "trigger",
 	TupleFamily:          "tuple",
 	UnknownFamily:        "unknown",
 	UuidFamily:           "uuid",
@@ -1445,6 +1535,12 @@ func (f Family) Name() string {
 //
 // TODO(andyk): Should these be changed to be the same as SQLStandardName?
 func (t *T) Name() string {
+	if t.IsDomain() {
+		if t.TypeMeta.Name == nil {
+			return t.DomainBaseType().Name()
+		}
+		return t.TypeMeta.Name.Basename()
+	}
 	switch fam := t.Family(); fam {
 	case AnyFamily:
 		return "anyelement"
@@ -1582,6 +1678,12 @@ func (t *T) TelemetryName() string {
 // This function is full of special cases. See backend/utils/adt/format_type.c
 // in Postgres.
 func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
+	if t.IsDomain() {
+		if t.TypeMeta.Name == nil {
+			return t.DomainBaseType().SQLStandardNameWithTypmod(haveTypmod, typmod)
+		}
+		return t.TypeMeta.Name.Basename()
+	}
 	var buf strings.Builder
 	switch t.Family() {
 	case AnyFamily:
@@ -1736,6 +1838,10 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 			return "timestamp with time zone"
 		}
 		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
+	case TSQueryFamily:
+		return "tsquery"
+	case TSVectorFamily:
+		return "tsvector"
 	case TupleFamily:
 		if t.UserDefined() {
 			// If we have a user-defined tuple type, use its user-defined name.
@@ -1748,6 +1854,8 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		return "uuid"
 	case VoidFamily:
 		return "void"
+	case TriggerFamily:
+		return "trigger"
 	case EnumFamily:
 		return t.TypeMeta.Name.Basename()
 	default:
@@ -1776,6 +1884,13 @@ func (t *T) InformationSchemaName() string {
 // reproduce the type via parsing the string as a type. It is used in error
 // messages and also to produce the output of SHOW CREATE.
 func (t *T) SQLString() string {
+	if t.IsDomain() {
+		// See the comment on user-defined types in the EnumFamily case below.
+		if t.TypeMeta.Name == nil {
+			return fmt.Sprintf("@%d", t.Oid())
+		}
+		return t.TypeMeta.Name.FQName()
+	}
 	switch t.Family() {
 	case BitFamily:
 		o := t.Oid()
@@ -2089,6 +2204,9 @@ func (t *InternalType) Identical(other *InternalType) bool {
 		if t.UDTMetadata.ArrayTypeOID != other.UDTMetadata.ArrayTypeOID {
 			return false
 		}
+		if t.UDTMetadata.DomainBaseOID != other.UDTMetadata.DomainBaseOID {
+			return false
+		}
 	} else if t.UDTMetadata != nil {
 		return false
 	} else if other.UDTMetadata != nil {
@@ -2121,6 +2239,15 @@ func (t *T) Unmarshal(data []byte) error {
 // setting required values. This is necessary to preserve backwards-
 // compatibility with older formats (e.g. restoring database from old backup).
 func (t *T) upgradeType() error {
+	if t.IsDomain() {
+		// A domain is marshaled like its base type, except for its OID, which
+		// must not be remapped.
+		domainOID := t.InternalType.Oid
+		t.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+		err := t.upgradeType()
+		t.InternalType.Oid = domainOID
+		return err
+	}
 	switch t.Family() {
 	case IntFamily:
 		// Check VisibleType field that was populated in previous versions.
@@ -2358,6 +2485,14 @@ func (t *T) MarshalTo(data []byte) (int, error) {
 // CRDB. This is necessary to preserve backwards-compatibility in mixed-version
 // scenarios, such as during upgrade.
 func (t *T) downgradeType() error {
+	if t.IsDomain() {
+		// A domain is marshaled like its base type, except for its OID.
+		domainOID := t.InternalType.Oid
+		t.InternalType.Oid = t.InternalType.UDTMetadata.DomainBaseOID
+		err := t.downgradeType()
+		t.InternalType.Oid = domainOID
+		return err
+	}
 	// Set Family and VisibleType for 19.1 backwards-compatibility.
 	switch t.Family() {
 	case BitFamily:
@@ -2814,8 +2949,6 @@ var postgresPredefinedTypeIssues = map[string]int{
 	"money":         41578,
 	"path":          21286,
 	"pg_lsn":        -1,
-	"tsquery":       7821,
-	"tsvector":      7821,
 	"txid_snapshot": -1,
 	"xml":           43355,
 }
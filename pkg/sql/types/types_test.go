@@ -1086,3 +1086,20 @@ func TestEnumWithoutTypeMetaNameDoesNotPanicInSQLString(t *testing.T) {
 	arrayType := MakeArray(typ)
 	require.Equal(t, "@100100[]", arrayType.SQLString())
 }
+
+func TestDomainMarshalRoundTrip(t *testing.T) {
+	for _, base := range []*T{VarChar, MakeChar(3), Int2, Float4, VarBit, Name} {
+		t.Run(base.SQLString(), func(t *testing.T) {
+			typ := MakeDomain(100100, 100101, base)
+			require.True(t, typ.IsDomain())
+			require.Equal(t, "@100100", typ.SQLString())
+			require.True(t, typ.DomainBaseType().Identical(base))
+
+			data, err := protoutil.Marshal(typ)
+			require.NoError(t, err)
+			var res T
+			require.NoError(t, protoutil.Unmarshal(data, &res))
+			require.True(t, res.Identical(typ), "expected %s, found %s", typ.DebugString(), res.DebugString())
+		})
+	}
+}
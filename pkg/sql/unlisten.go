@@ -13,17 +13,43 @@ package sql
 import (
 	"context"
 
-	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 )
 
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
 func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
-	p.BufferClientNotice(ctx,
-		pgerror.WithSeverity(
-			unimplemented.NewWithIssuef(41522, "CRDB does not support LISTEN, making UNLISTEN a no-op"),
-			"NOTICE",
-		),
-	)
-	return newZeroNode(nil /* columns */), nil
+	if n.Star {
+		return &unlistenNode{all: true}, nil
+	}
+	channel, err := notificationChannel(n.ChannelName)
+	if err != nil {
+		return nil, err
+	}
+	return &unlistenNode{channel: channel}, nil
 }
+
+type unlistenNode struct {
+	channel string
+	// all is set for UNLISTEN *.
+	all bool
+}
+
+func (n *unlistenNode) startExec(params runParams) error {
+	notifications := params.extendedEvalCtx.Notifications
+	if notifications == nil {
+		// A session which isn't bound to a client connection can't be listening
+		// on any channel.
+		return nil
+	}
+	if n.all {
+		notifications.queueUnlistenAll()
+	} else {
+		notifications.queueUnlisten(n.channel)
+	}
+	return nil
+}
+
+func (n *unlistenNode) Next(_ runParams) (bool, error) { return false, nil }
+func (n *unlistenNode) Values() tree.Datums            { return nil }
+func (n *unlistenNode) Close(_ context.Context)        {}
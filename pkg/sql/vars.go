@@ -44,12 +44,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 )
 
@@ -400,16 +403,26 @@ var varGen = map[string]sessionVar{
 	`default_transaction_isolation`: {
 		Set: func(_ context.Context, m sessionDataMutator, s string) error {
 			switch strings.ToUpper(s) {
-			case `READ UNCOMMITTED`, `READ COMMITTED`, `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`, `DEFAULT`:
-				// Do nothing. All transactions execute with serializable isolation.
+			case `READ UNCOMMITTED`, `READ COMMITTED`:
+				// READ UNCOMMITTED is executed as READ COMMITTED, like in Postgres.
+				m.SetDefaultTransactionIsolationLevel(tree.ReadCommittedIsolation)
+			case `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`:
+				// SNAPSHOT and REPEATABLE READ are executed as SERIALIZABLE.
+				m.SetDefaultTransactionIsolationLevel(tree.SerializableIsolation)
+			case `DEFAULT`:
+				m.SetDefaultTransactionIsolationLevel(tree.UnspecifiedIsolation)
 			default:
-				return newVarValueError(`default_transaction_isolation`, s, "serializable")
+				return newVarValueError(`default_transaction_isolation`, s, "serializable", "read committed")
 			}
 
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
-			return "serializable", nil
+			level := tree.IsolationLevel(evalCtx.SessionData().DefaultTxnIsolationLevel)
+			if level == tree.UnspecifiedIsolation {
+				level = tree.SerializableIsolation
+			}
+			return strings.ToLower(level.String()), nil
 		},
 		GlobalDefault: func(sv *settings.Values) string { return "default" },
 	},
@@ -1025,6 +1038,24 @@ var varGen = map[string]sessionVar{
 	// See https://www.postgresql.org/docs/12/runtime-config-client.html.
 	`default_table_access_method`: makeCompatStringVar(`default_table_access_method`, `heap`),
 
+	// See https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TEXT-SEARCH-CONFIG
+	`default_text_search_config`: {
+		Set: func(_ context.Context, m sessionDataMutator, s string) error {
+			c, err := tsearch.GetConfig(s)
+			if err != nil {
+				return wrapSetVarError(err, `default_text_search_config`, s)
+			}
+			m.SetDefaultTextSearchConfig(c.Name())
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
+			return evalCtx.SessionData().DefaultTextSearchConfig, nil
+		},
+		GlobalDefault: func(sv *settings.Values) string {
+			return tsearch.DefaultConfig
+		},
+	},
+
 	// See https://www.postgresql.org/docs/13/runtime-config-compatible.html
 	// CockroachDB only supports safe_encoding for now. If `client_encoding` is updated to
 	// allow encodings other than UTF8, then the default value of `backslash_quote` should
@@ -1401,15 +1432,19 @@ var varGen = map[string]sessionVar{
 	// This is not directly documented in PG's docs but does indeed behave this way.
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
-		Get: func(evalCtx *extendedEvalContext, _ *kv.Txn) (string, error) {
-			return "serializable", nil
+		Get: func(evalCtx *extendedEvalContext, txn *kv.Txn) (string, error) {
+			if txn.IsoLevel() == enginepb.READ_COMMITTED {
+				return strings.ToLower(tree.ReadCommittedIsolation.String()), nil
+			}
+			return strings.ToLower(tree.SerializableIsolation.String()), nil
 		},
-		RuntimeSet: func(_ context.Context, evalCtx *extendedEvalContext, local bool, s string) error {
-			_, ok := tree.IsolationLevelMap[s]
+		RuntimeSet: func(ctx context.Context, evalCtx *extendedEvalContext, local bool, s string) error {
+			level, ok := tree.IsolationLevelMap[strings.ToLower(s)]
 			if !ok {
-				return newVarValueError(`transaction_isolation`, s, "serializable")
+				return newVarValueError(`transaction_isolation`, s, "serializable", "read committed")
 			}
-			return nil
+			modes := tree.TransactionModes{Isolation: level}
+			return evalCtx.TxnModesSetter.setTransactionModes(ctx, modes, hlc.Timestamp{})
 		},
 		GlobalDefault: func(_ *settings.Values) string { return "serializable" },
 	},
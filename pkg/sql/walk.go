@@ -120,6 +120,7 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	switch n := plan.(type) {
 	case *valuesNode:
 	case *scanNode:
+	case *backupScanNode:
 
 	case *filterNode:
 		n.source.plan = v.visit(n.source.plan)
@@ -347,6 +348,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterDatabaseDropSecondaryRegion{}):        "alter database secondary region",
 	reflect.TypeOf(&alterDatabaseSetZoneConfigExtensionNode{}): "alter database configure zone extension",
 	reflect.TypeOf(&alterDefaultPrivilegesNode{}):              "alter default privileges",
+	reflect.TypeOf(&alterDomainNode{}):                         "alter domain",
 	reflect.TypeOf(&alterFunctionOptionsNode{}):                "alter function",
 	reflect.TypeOf(&alterFunctionRenameNode{}):                 "alter function rename",
 	reflect.TypeOf(&alterFunctionSetOwnerNode{}):               "alter function owner",
@@ -354,6 +356,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterFunctionDepExtensionNode{}):           "alter function depends on extension",
 	reflect.TypeOf(&alterIndexNode{}):                          "alter index",
 	reflect.TypeOf(&alterIndexVisibleNode{}):                   "alter index visibility",
+	reflect.TypeOf(&alterPublicationNode{}):                    "alter publication",
 	reflect.TypeOf(&alterSequenceNode{}):                       "alter sequence",
 	reflect.TypeOf(&alterSchemaNode{}):                         "alter schema",
 	reflect.TypeOf(&alterTableNode{}):                          "alter table",
@@ -365,6 +368,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterRoleNode{}):                           "alter role",
 	reflect.TypeOf(&alterRoleSetNode{}):                        "alter role set var",
 	reflect.TypeOf(&applyJoinNode{}):                           "apply join",
+	reflect.TypeOf(&backupScanNode{}):                          "backup scan",
 	reflect.TypeOf(&bufferNode{}):                              "buffer",
 	reflect.TypeOf(&cancelQueriesNode{}):                       "cancel queries",
 	reflect.TypeOf(&cancelSessionsNode{}):                      "cancel sessions",
@@ -383,10 +387,12 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&createExternalConectionNode{}):             "create external connection",
 	reflect.TypeOf(&createFunctionNode{}):                      "create function",
 	reflect.TypeOf(&createIndexNode{}):                         "create index",
+	reflect.TypeOf(&createPublicationNode{}):                   "create publication",
 	reflect.TypeOf(&createSequenceNode{}):                      "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):                        "create schema",
 	reflect.TypeOf(&createStatsNode{}):                         "create statistics",
 	reflect.TypeOf(&createTableNode{}):                         "create table",
+	reflect.TypeOf(&createTriggerNode{}):                       "create trigger",
 	reflect.TypeOf(&createTypeNode{}):                          "create type",
 	reflect.TypeOf(&CreateRoleNode{}):                          "create user/role",
 	reflect.TypeOf(&createViewNode{}):                          "create view",
@@ -399,9 +405,11 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&dropExternalConnectionNode{}):              "drop external connection",
 	reflect.TypeOf(&dropFunctionNode{}):                        "drop function",
 	reflect.TypeOf(&dropIndexNode{}):                           "drop index",
+	reflect.TypeOf(&dropPublicationNode{}):                     "drop publication",
 	reflect.TypeOf(&dropSequenceNode{}):                        "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):                          "drop schema",
 	reflect.TypeOf(&dropTableNode{}):                           "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):                         "drop trigger",
 	reflect.TypeOf(&dropTypeNode{}):                            "drop type",
 	reflect.TypeOf(&DropRoleNode{}):                            "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):                            "drop view",
@@ -422,8 +430,10 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&invertedJoinNode{}):                        "inverted join",
 	reflect.TypeOf(&joinNode{}):                                "join",
 	reflect.TypeOf(&limitNode{}):                               "limit",
+	reflect.TypeOf(&listenNode{}):                              "listen",
 	reflect.TypeOf(&lookupJoinNode{}):                          "lookup join",
 	reflect.TypeOf(&max1RowNode{}):                             "max1row",
+	reflect.TypeOf(&notifyNode{}):                              "notify",
 	reflect.TypeOf(&ordinalityNode{}):                          "ordinality",
 	reflect.TypeOf(&projectSetNode{}):                          "project set",
 	reflect.TypeOf(&reassignOwnedByNode{}):                     "reassign owned by",
@@ -450,6 +460,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&sequenceSelectNode{}):                      "sequence select",
 	reflect.TypeOf(&serializeNode{}):                           "run",
 	reflect.TypeOf(&setClusterSettingNode{}):                   "set cluster setting",
+	reflect.TypeOf(&setConstraintsNode{}):                      "set constraints",
 	reflect.TypeOf(&setSessionAuthorizationDefaultNode{}):      "set session authorization",
 	reflect.TypeOf(&setVarNode{}):                              "set",
 	reflect.TypeOf(&setZoneConfigNode{}):                       "configure zone",
@@ -466,6 +477,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&truncateNode{}):                            "truncate",
 	reflect.TypeOf(&unaryNode{}):                               "emptyrow",
 	reflect.TypeOf(&unionNode{}):                               "union",
+	reflect.TypeOf(&unlistenNode{}):                            "unlisten",
 	reflect.TypeOf(&updateNode{}):                              "update",
 	reflect.TypeOf(&upsertNode{}):                              "upsert",
 	reflect.TypeOf(&valuesNode{}):                              "values",
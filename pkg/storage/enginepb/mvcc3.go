@@ -60,3 +60,20 @@ func (h MVCCValueHeader) IsEmpty() bool {
 	// https://github.com/cockroachdb/cockroach/issues/88818
 	return h.LocalTimestamp.IsEmpty()
 }
+
+// SafeValue implements the redact.SafeValue interface.
+func (IsolationLevel) SafeValue() {}
+
+// ToleratesWriteSkew returns whether transactions at the isolation level may
+// commit at a timestamp above their read timestamp without refreshing their
+// reads. Such transactions can be pushed by conflicting readers without
+// waiting for them to complete.
+func (l IsolationLevel) ToleratesWriteSkew() bool {
+	return l == READ_COMMITTED
+}
+
+// PerStatementReadSnapshot returns whether transactions at the isolation level
+// take a new read snapshot for each of their statements.
+func (l IsolationLevel) PerStatementReadSnapshot() bool {
+	return l == READ_COMMITTED
+}
@@ -1061,12 +1061,14 @@ func (p *Pebble) Close() {
 	}
 
 	handleErr(p.db.Close())
-	if p.fileRegistry != nil {
-		handleErr(p.fileRegistry.Close())
-	}
+	// NB: the encryption-at-rest environment may update the file registry until
+	// it is closed.
 	if p.encryption != nil {
 		handleErr(p.encryption.Closer.Close())
 	}
+	if p.fileRegistry != nil {
+		handleErr(p.fileRegistry.Close())
+	}
 	if p.closer != nil {
 		handleErr(p.closer.Close())
 	}
@@ -264,6 +264,12 @@ func (r *PebbleFileRegistry) GetFileEntry(filename string) *enginepb.FileEntry {
 	return r.mu.entries[filename]
 }
 
+// List returns a copy of the entries of the registry, keyed by the names of
+// their files relative to the registry's directory.
+func (r *PebbleFileRegistry) List() map[string]*enginepb.FileEntry {
+	return r.getRegistryCopy().Files
+}
+
 // SetFileEntry sets filename => entry in the registry map and persists the registry.
 // It should not be called for entries corresponding to unencrypted files since the
 // absence of a file in the file registry implies that it is unencrypted.
@@ -104,8 +104,28 @@ func MakeBackupSSTWriter(ctx context.Context, cs *cluster.Settings, f io.Writer)
 // format set to RocksDBv2.
 func MakeIngestionSSTWriter(
 	ctx context.Context, cs *cluster.Settings, f writeCloseSyncer,
+) SSTWriter {
+	return MakeIngestionSSTWriterWithOptions(ctx, cs, f, roachpb.SSTOptions{})
+}
+
+// MakeIngestionSSTWriterWithOptions is like MakeIngestionSSTWriter, but writes
+// the data blocks with the compression and block size of the given SST options
+// of a span config, where these are set.
+func MakeIngestionSSTWriterWithOptions(
+	ctx context.Context, cs *cluster.Settings, f writeCloseSyncer, sstOpts roachpb.SSTOptions,
 ) SSTWriter {
 	opts := MakeIngestionWriterOptions(ctx, cs)
+	switch sstOpts.Compression {
+	case roachpb.SSTOptions_NONE:
+		opts.Compression = sstable.NoCompression
+	case roachpb.SSTOptions_SNAPPY:
+		opts.Compression = sstable.SnappyCompression
+	case roachpb.SSTOptions_ZSTD:
+		opts.Compression = sstable.ZstdCompression
+	}
+	if sstOpts.BlockSize != 0 {
+		opts.BlockSize = int(sstOpts.BlockSize)
+	}
 	return SSTWriter{
 		fw:                sstable.NewWriter(f, opts),
 		f:                 f,
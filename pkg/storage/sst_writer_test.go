@@ -12,7 +12,9 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -189,6 +191,44 @@ func TestSSTWriterRangeKeys(t *testing.T) {
 	}, scanIter(t, iter))
 }
 
+func TestMakeIngestionSSTWriterWithOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	writeSST := func(opts roachpb.SSTOptions) *sstable.Reader {
+		sstFile := &MemFile{}
+		sst := MakeIngestionSSTWriterWithOptions(ctx, st, sstFile, opts)
+		defer sst.Close()
+		for i := 0; i < 100; i++ {
+			key := pointKey(fmt.Sprintf("%03d", i), 1)
+			require.NoError(t, sst.Put(key, stringValueRaw(strings.Repeat("a", 100))))
+		}
+		require.NoError(t, sst.Finish())
+		r, err := sstable.NewMemReader(sstFile.Data(), sstable.ReaderOptions{
+			Comparer: EngineComparer,
+		})
+		require.NoError(t, err)
+		return r
+	}
+
+	defaults := writeSST(roachpb.SSTOptions{})
+	defer defaults.Close()
+	zstd := writeSST(roachpb.SSTOptions{Compression: roachpb.SSTOptions_ZSTD})
+	defer zstd.Close()
+	require.Equal(t, sstable.ZstdCompression.String(), zstd.Properties.CompressionName)
+	require.Equal(t, defaults.Properties.NumDataBlocks, zstd.Properties.NumDataBlocks)
+
+	uncompressed := writeSST(roachpb.SSTOptions{
+		Compression: roachpb.SSTOptions_NONE,
+		BlockSize:   1 << 10,
+	})
+	defer uncompressed.Close()
+	require.Equal(t, sstable.NoCompression.String(), uncompressed.Properties.CompressionName)
+	require.Greater(t, uncompressed.Properties.NumDataBlocks, defaults.Properties.NumDataBlocks)
+}
+
 func BenchmarkWriteSSTable(b *testing.B) {
 	b.StopTimer()
 	// Writing the SST 10 times keeps size needed for ~10s benchtime under 1gb.
@@ -2676,6 +2676,14 @@ var charts = []sectionDescription{
 				},
 				AxisLabel: "Transactions",
 			},
+			{
+				Title: "READ COMMITTED Statement Retries",
+				Metrics: []string{
+					"sql.txn.read_committed.stmt_retry.count",
+					"sql.txn.read_committed.stmt_retry.count.internal",
+				},
+				AxisLabel: "SQL Statements",
+			},
 		},
 	},
 	{
@@ -3291,6 +3299,7 @@ var charts = []sectionDescription{
 					"jobs.auto_span_config_reconciliation.currently_running",
 					"jobs.auto_sql_stats_compaction.currently_running",
 					"jobs.stream_replication.currently_running",
+					"jobs.verify_backup.currently_running",
 				},
 			},
 			{
@@ -3311,6 +3320,7 @@ var charts = []sectionDescription{
 					"jobs.stream_ingestion.currently_idle",
 					"jobs.stream_replication.currently_idle",
 					"jobs.typedesc_schema_change.currently_idle",
+					"jobs.verify_backup.currently_idle",
 				},
 			},
 			{
@@ -3385,6 +3395,18 @@ var charts = []sectionDescription{
 				},
 				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
 			},
+			{
+				Title: "Verify Backup",
+				Metrics: []string{
+					"jobs.verify_backup.fail_or_cancel_completed",
+					"jobs.verify_backup.fail_or_cancel_failed",
+					"jobs.verify_backup.fail_or_cancel_retry_error",
+					"jobs.verify_backup.resume_completed",
+					"jobs.verify_backup.resume_failed",
+					"jobs.verify_backup.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
 			{
 				Title: "Schema Change",
 				Metrics: []string{
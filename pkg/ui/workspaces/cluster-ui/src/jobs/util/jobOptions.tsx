@@ -130,6 +130,7 @@ export const typeOptions = [
   { value: JobType.UNSPECIFIED.toString(), name: "All" },
   { value: JobType.BACKUP.toString(), name: "Backups" },
   { value: JobType.RESTORE.toString(), name: "Restores" },
+  { value: JobType.VERIFY_BACKUP.toString(), name: "Backup Verifications" },
   { value: JobType.IMPORT.toString(), name: "Imports" },
   { value: JobType.SCHEMA_CHANGE.toString(), name: "Schema Changes" },
   { value: JobType.CHANGEFEED.toString(), name: "Changefeed" },
@@ -135,6 +135,38 @@ export default class EncryptionStatus {
     ];
   }
 
+  renderReencryptionStatus(
+    status: protosccl.cockroach.ccl.storageccl.engineccl.enginepbccl.IReencryptionStatus,
+  ) {
+    // The status is missing for read-only stores.
+    if (_.isNil(status)) {
+      return null;
+    }
+    const lastPassTime = FixLong(status.last_pass_time);
+    const lastPass = lastPassTime.eq(0)
+      ? "Never"
+      : moment.unix(lastPassTime.toNumber()).utc().format(dateFormat);
+    const reencrypted =
+      FixLong(status.reencrypted_files) +
+      " (" +
+      Bytes(FixLong(status.reencrypted_bytes).toNumber()) +
+      ")";
+    const remaining =
+      FixLong(status.remaining_files) +
+      " (" +
+      Bytes(FixLong(status.remaining_bytes).toNumber()) +
+      ")";
+
+    return [
+      this.renderHeaderRow(
+        "Re-encryption: files rewritten using the active data key",
+      ),
+      this.renderSimpleRow("Re-encrypted files", reencrypted),
+      this.renderSimpleRow("Remaining files", remaining),
+      this.renderSimpleRow("Last completed pass", lastPass),
+    ];
+  }
+
   getEncryptionRows() {
     const { store } = this.props;
     const rawStatus = store.encryption_status;
@@ -163,6 +195,7 @@ export default class EncryptionStatus {
       this.renderStoreKey(decodedStatus.active_store_key),
       this.renderDataKey(decodedStatus.active_data_key),
       this.renderFileStats(store),
+      this.renderReencryptionStatus(decodedStatus.reencryption),
     ];
   }
 }
@@ -164,6 +164,12 @@ var upgrades = []upgrade.Upgrade{
 		NoPrecondition,
 		fixInvalidObjectsThatLookLikeBadUserfileConstraint,
 	),
+	upgrade.NewTenantUpgrade(
+		"add the system.notifications table",
+		toCV(clusterversion.SystemNotificationsTable),
+		NoPrecondition,
+		systemNotificationsTableMigration,
+	),
 }
 
 func init() {
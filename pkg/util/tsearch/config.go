@@ -0,0 +1,206 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// DefaultConfig is the text search configuration used when none is specified.
+const DefaultConfig = "english"
+
+// Config is a text search configuration, which specifies how the words of a
+// document or of a query are normalized into lexemes.
+type Config struct {
+	name string
+	// stem returns the lexeme of a word which contains only letters.
+	stem func(string) string
+	// stopWords are the words which are too common to be useful in searches,
+	// and so are ignored.
+	stopWords map[string]struct{}
+}
+
+var configs = map[string]*Config{
+	"simple": {
+		name: "simple",
+		stem: func(s string) string { return s },
+	},
+	"english": {
+		name:      "english",
+		stem:      stemEnglish,
+		stopWords: englishStopWords,
+	},
+}
+
+// GetConfig returns the text search configuration with the given name, which
+// may be qualified with the pg_catalog schema.
+func GetConfig(name string) (*Config, error) {
+	if c, ok := configs[strings.TrimPrefix(name, "pg_catalog.")]; ok {
+		return c, nil
+	}
+	return nil, pgerror.Newf(pgcode.UndefinedObject,
+		"text search configuration %q does not exist", name)
+}
+
+// Name returns the name of the configuration.
+func (c *Config) Name() string {
+	return c.name
+}
+
+// word is a word of a document.
+type word struct {
+	text string
+	// position is the position of the word in the document, starting at 1.
+	position int
+}
+
+// words splits the text into words, which are the runs of letters and digits
+// of the text.
+func words(text string) []word {
+	var res []word
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			res = append(res, word{text: text[start:i], position: len(res) + 1})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		res = append(res, word{text: text[start:], position: len(res) + 1})
+	}
+	return res
+}
+
+// lexeme returns the lexeme of the word, or false if it is a stop word. The
+// words which contain digits are only lowercased.
+func (c *Config) lexeme(w string) (string, bool) {
+	w = strings.ToLower(w)
+	if _, ok := c.stopWords[w]; ok {
+		return "", false
+	}
+	if strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+		return w, true
+	}
+	return c.stem(w), true
+}
+
+// ToTSVector returns the tsvector of the document, which contains the lexemes
+// of its words along with their positions. The stop words are ignored, but
+// still count towards the positions of the other words. This is the
+// to_tsvector function.
+func ToTSVector(c *Config, document string) (TSVector, error) {
+	var terms []tsTerm
+	for _, w := range words(document) {
+		lexeme, ok := c.lexeme(w.text)
+		if !ok {
+			continue
+		}
+		if err := checkLexemeLen(lexeme); err != nil {
+			return nil, err
+		}
+		position := w.position
+		if position > maxPosition {
+			position = maxPosition
+		}
+		terms = append(terms, tsTerm{
+			lexeme:    lexeme,
+			positions: []tsPosition{{position: uint16(position)}},
+		})
+	}
+	return normalizeTSVector(terms), nil
+}
+
+// ToTSQuery returns the tsquery of a query in the input representation of a
+// tsquery, whose operands are normalized into lexemes. An operand which
+// consists of several words matches the phrase made of those words. The stop
+// words are removed from the query. This is the to_tsquery function.
+func ToTSQuery(c *Config, input string) (TSQuery, error) {
+	return parseTSQuery(input, func(o tsQueryOperand) (*tsNode, bool, error) {
+		return c.phrase(o.text, o.weightMask, o.prefix)
+	})
+}
+
+// PlainToTSQuery returns the tsquery which matches the documents which contain
+// all the lexemes of the words of the text, which is not parsed for operators.
+// This is the plainto_tsquery function.
+func PlainToTSQuery(c *Config, text string) (TSQuery, error) {
+	var root *tsNode
+	for _, w := range words(text) {
+		lexeme, ok := c.lexeme(w.text)
+		if !ok {
+			continue
+		}
+		if err := checkLexemeLen(lexeme); err != nil {
+			return TSQuery{}, err
+		}
+		node := &tsNode{lexeme: lexeme}
+		if root == nil {
+			root = node
+		} else {
+			root = &tsNode{op: and, left: root, right: node}
+		}
+	}
+	return TSQuery{root: root}, nil
+}
+
+// PhraseToTSQuery returns the tsquery which matches the documents which
+// contain the phrase made of the lexemes of the words of the text, which is
+// not parsed for operators. This is the phraseto_tsquery function.
+func PhraseToTSQuery(c *Config, text string) (TSQuery, error) {
+	root, removed, err := c.phrase(text, 0 /* weightMask */, false /* prefix */)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	if removed {
+		root = cleanupTSQuery(root)
+	}
+	return TSQuery{root: root}, nil
+}
+
+// phrase returns the node which matches the words of the text at consecutive
+// positions, with the given weights and prefix flag. The stop words are nil
+// operands of the node, which must be cleaned up if removed is set.
+func (c *Config) phrase(
+	text string, weightMask uint8, prefix bool,
+) (_ *tsNode, removed bool, _ error) {
+	var root *tsNode
+	ws := words(text)
+	if len(ws) == 0 {
+		return nil, true, nil
+	}
+	for i, w := range ws {
+		var node *tsNode
+		if lexeme, ok := c.lexeme(w.text); ok {
+			if err := checkLexemeLen(lexeme); err != nil {
+				return nil, false, err
+			}
+			node = &tsNode{lexeme: lexeme, weightMask: weightMask, prefix: prefix}
+		} else {
+			removed = true
+		}
+		if i == 0 {
+			root = node
+		} else {
+			root = &tsNode{op: followedBy, distance: 1, left: root, right: node}
+		}
+	}
+	return root, removed, nil
+}
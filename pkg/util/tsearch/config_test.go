@@ -0,0 +1,130 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStemEnglish(t *testing.T) {
+	for word, want := range map[string]string{
+		// Words from the vocabulary of the Snowball project.
+		"consign":       "consign",
+		"consigned":     "consign",
+		"consignment":   "consign",
+		"consistency":   "consist",
+		"consistently":  "consist",
+		"consolation":   "consol",
+		"consolatory":   "consolatori",
+		"consolidating": "consolid",
+		"consolingly":   "consol",
+		"conspicuous":   "conspicu",
+		"conspiracy":    "conspiraci",
+		"conspirators":  "conspir",
+		"constable":     "constabl",
+		"constancy":     "constanc",
+		"knackeries":    "knackeri",
+		"knavish":       "knavish",
+		"kneaded":       "knead",
+		"knees":         "knee",
+		"knightly":      "knight",
+		"knitting":      "knit",
+		"knives":        "knive",
+		"knocker":       "knocker",
+		// Other words.
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "tie",
+		"cats":           "cat",
+		"gas":            "gas",
+		"hoped":          "hope",
+		"hopping":        "hop",
+		"agreed":         "agre",
+		"happily":        "happili",
+		"relational":     "relat",
+		"generalization": "general",
+		"generously":     "generous",
+		"foxes":          "fox",
+		"jumped":         "jump",
+		"lazy":           "lazi",
+		"cry":            "cri",
+		"say":            "say",
+		"by":             "by",
+		"skies":          "sky",
+		"news":           "news",
+		"succeeding":     "succeed",
+		"supernovae":     "supernova",
+	} {
+		assert.Equal(t, want, stemEnglish(word), word)
+	}
+}
+
+func TestToTSVector(t *testing.T) {
+	for _, tc := range []struct {
+		config   string
+		document string
+		want     string
+	}{
+		{"english", "The quick brown fox jumped over the lazy dog",
+			"'brown':3 'dog':9 'fox':4 'jump':5 'lazi':8 'quick':2"},
+		{"simple", "The quick brown fox jumped over the lazy dog",
+			"'brown':3 'dog':9 'fox':4 'jumped':5 'lazy':8 'over':6 'quick':2 'the':1,7"},
+		{"english", "a fat  cat sat on a mat - it ate a fat rats",
+			"'ate':9 'cat':3 'fat':2,11 'mat':7 'rat':12 'sat':4"},
+		{"english", "Sequences 123 and R2D2", "'123':2 'r2d2':4 'sequenc':1"},
+		{"pg_catalog.english", "", ""},
+	} {
+		t.Run(tc.document, func(t *testing.T) {
+			c, err := GetConfig(tc.config)
+			require.NoError(t, err)
+			v, err := ToTSVector(c, tc.document)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, v.String())
+		})
+	}
+	_, err := GetConfig("french")
+	assert.Regexp(t, "text search configuration \"french\" does not exist", err)
+}
+
+func TestToTSQuery(t *testing.T) {
+	c, err := GetConfig("english")
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		fn    func(*Config, string) (TSQuery, error)
+		input string
+		want  string
+	}{
+		{ToTSQuery, "The & Fat & Rats", "'fat' & 'rat'"},
+		{ToTSQuery, "Fat | Rats:AB", "'fat' | 'rat':AB"},
+		{ToTSQuery, "supern:*A & star:A*B", "'supern':*A & 'star':*AB"},
+		{ToTSQuery, "'supernovae stars' & !crab", "'supernova' <-> 'star' & !'crab'"},
+		{ToTSQuery, "fat <-> the <-> rat", "'fat' <2> 'rat'"},
+		{ToTSQuery, "fat <-> 'the rats'", "'fat' <2> 'rat'"},
+		{ToTSQuery, "the <-> fat", "'fat'"},
+		{ToTSQuery, "!the & fat", "'fat'"},
+		{ToTSQuery, "(the | a) & fat", "'fat'"},
+		{ToTSQuery, "the", ""},
+		{PlainToTSQuery, "The Fat & Rats:C", "'fat' & 'rat' & 'c'"},
+		{PlainToTSQuery, "the", ""},
+		{PhraseToTSQuery, "The Fat Rats", "'fat' <-> 'rat'"},
+		{PhraseToTSQuery, "The Cat and Rats", "'cat' <2> 'rat'"},
+		{PhraseToTSQuery, "the cats ate the rats", "'cat' <-> 'ate' <2> 'rat'"},
+		{PhraseToTSQuery, "", ""},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := tc.fn(c, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, q.String())
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/cockroachdb/errors"
+)
+
+// The binary encodings of tsvector and tsquery are the ones of the binary
+// format of PostgreSQL, which are also used to store them.
+
+// The types of the items of the binary encoding of a tsquery.
+const (
+	tsQueryItemOperand  = 1
+	tsQueryItemOperator = 2
+)
+
+// The operators of the binary encoding of a tsquery.
+const (
+	tsQueryOperatorNot        = 1
+	tsQueryOperatorAnd        = 2
+	tsQueryOperatorOr         = 3
+	tsQueryOperatorFollowedBy = 4
+)
+
+// encode returns the encoding of the position, which holds the weight in its
+// two upper bits.
+func (p tsPosition) encode() uint16 {
+	return uint16(p.weight)<<14 | p.position
+}
+
+func decodePosition(enc uint16) tsPosition {
+	return tsPosition{position: enc & (1<<14 - 1), weight: weight(enc >> 14)}
+}
+
+// EncodeTSVector appends the binary encoding of the tsvector to appendTo. It
+// is the number of lexemes, followed by each lexeme as a NUL-terminated string
+// followed by the number of its positions and by the positions.
+func EncodeTSVector(appendTo []byte, v TSVector) []byte {
+	appendTo = appendUint32(appendTo, uint32(len(v)))
+	for i := range v {
+		appendTo = append(appendTo, v[i].lexeme...)
+		appendTo = append(appendTo, 0)
+		appendTo = appendUint16(appendTo, uint16(len(v[i].positions)))
+		for _, pos := range v[i].positions {
+			appendTo = appendUint16(appendTo, pos.encode())
+		}
+	}
+	return appendTo
+}
+
+// DecodeTSVector decodes a tsvector from its binary encoding.
+func DecodeTSVector(b []byte) (TSVector, error) {
+	d := decoder{b: b}
+	n, err := d.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > len(b) {
+		return nil, errors.Errorf("invalid number of lexemes in tsvector: %d", n)
+	}
+	terms := make([]tsTerm, n)
+	for i := range terms {
+		if terms[i].lexeme, err = d.readString(); err != nil {
+			return nil, err
+		}
+		var numPositions, enc uint16
+		if numPositions, err = d.readUint16(); err != nil {
+			return nil, err
+		}
+		if numPositions > 0 {
+			terms[i].positions = make([]tsPosition, numPositions)
+		}
+		for j := range terms[i].positions {
+			if enc, err = d.readUint16(); err != nil {
+				return nil, err
+			}
+			terms[i].positions[j] = decodePosition(enc)
+		}
+	}
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return normalizeTSVector(terms), nil
+}
+
+// EncodeTSQuery appends the binary encoding of the tsquery to appendTo. It is
+// the number of nodes of the tree, followed by the nodes in pre-order, with the
+// right operand of each operator before its left operand. An operand is its
+// weights, its prefix flag and its lexeme as a NUL-terminated string. An
+// operator is followed by its distance if it is a followedBy operator.
+func EncodeTSQuery(appendTo []byte, q TSQuery) []byte {
+	n := 0
+	q.root.walk(func(*tsNode) { n++ })
+	appendTo = appendUint32(appendTo, uint32(n))
+	return q.root.encode(appendTo)
+}
+
+func (n *tsNode) encode(appendTo []byte) []byte {
+	if n == nil {
+		return appendTo
+	}
+	switch n.op {
+	case operand:
+		prefix := byte(0)
+		if n.prefix {
+			prefix = 1
+		}
+		appendTo = append(appendTo, tsQueryItemOperand, n.weightMask, prefix)
+		appendTo = append(appendTo, n.lexeme...)
+		return append(appendTo, 0)
+	case not:
+		appendTo = append(appendTo, tsQueryItemOperator, tsQueryOperatorNot)
+	case and:
+		appendTo = append(appendTo, tsQueryItemOperator, tsQueryOperatorAnd)
+	case or:
+		appendTo = append(appendTo, tsQueryItemOperator, tsQueryOperatorOr)
+	case followedBy:
+		appendTo = append(appendTo, tsQueryItemOperator, tsQueryOperatorFollowedBy)
+		appendTo = appendUint16(appendTo, n.distance)
+	}
+	appendTo = n.right.encode(appendTo)
+	return n.left.encode(appendTo)
+}
+
+// DecodeTSQuery decodes a tsquery from its binary encoding.
+func DecodeTSQuery(b []byte) (TSQuery, error) {
+	d := decoder{b: b}
+	n, err := d.readUint32()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	if int(n) > len(b) {
+		return TSQuery{}, errors.Errorf("invalid number of items in tsquery: %d", n)
+	}
+	var root *tsNode
+	if n > 0 {
+		if root, err = d.readNode(); err != nil {
+			return TSQuery{}, err
+		}
+	}
+	if err := d.finish(); err != nil {
+		return TSQuery{}, err
+	}
+	return TSQuery{root: root}, nil
+}
+
+// decoder decodes the binary encodings of tsvectors and tsqueries.
+type decoder struct {
+	b []byte
+}
+
+var errTruncated = errors.New("truncated binary encoding of text search value")
+
+func (d *decoder) finish() error {
+	if len(d.b) > 0 {
+		return errors.Errorf("%d trailing bytes in binary encoding of text search value", len(d.b))
+	}
+	return nil
+}
+
+func (d *decoder) readByte() (byte, error) {
+	if len(d.b) < 1 {
+		return 0, errTruncated
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c, nil
+}
+
+func (d *decoder) readUint16() (uint16, error) {
+	if len(d.b) < 2 {
+		return 0, errTruncated
+	}
+	v := binary.BigEndian.Uint16(d.b)
+	d.b = d.b[2:]
+	return v, nil
+}
+
+func (d *decoder) readUint32() (uint32, error) {
+	if len(d.b) < 4 {
+		return 0, errTruncated
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v, nil
+}
+
+// readString decodes a NUL-terminated string.
+func (d *decoder) readString() (string, error) {
+	i := bytes.IndexByte(d.b, 0)
+	if i < 0 {
+		return "", errTruncated
+	}
+	s := string(d.b[:i])
+	d.b = d.b[i+1:]
+	return s, nil
+}
+
+// readNode decodes a node of a tsquery along with its operands.
+func (d *decoder) readNode() (*tsNode, error) {
+	itemType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch itemType {
+	case tsQueryItemOperand:
+		n := &tsNode{op: operand}
+		if n.weightMask, err = d.readByte(); err != nil {
+			return nil, err
+		}
+		var prefix byte
+		if prefix, err = d.readByte(); err != nil {
+			return nil, err
+		}
+		n.prefix = prefix != 0
+		if n.lexeme, err = d.readString(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case tsQueryItemOperator:
+		var operator byte
+		if operator, err = d.readByte(); err != nil {
+			return nil, err
+		}
+		n := &tsNode{}
+		switch operator {
+		case tsQueryOperatorNot:
+			n.op = not
+		case tsQueryOperatorAnd:
+			n.op = and
+		case tsQueryOperatorOr:
+			n.op = or
+		case tsQueryOperatorFollowedBy:
+			n.op = followedBy
+			if n.distance, err = d.readUint16(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.Errorf("unknown tsquery operator: %d", operator)
+		}
+		if n.right, err = d.readNode(); err != nil {
+			return nil, err
+		}
+		if n.op != not {
+			if n.left, err = d.readNode(); err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	}
+	return nil, errors.Errorf("unknown tsquery item type: %d", itemType)
+}
+
+func appendUint16(appendTo []byte, v uint16) []byte {
+	return append(appendTo, byte(v>>8), byte(v))
+}
+
+func appendUint32(appendTo []byte, v uint32) []byte {
+	return append(appendTo, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
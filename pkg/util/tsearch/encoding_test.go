@@ -0,0 +1,64 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTSVector(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"a",
+		"a:1A fat:2B,4C cat:5D 'joe''s':16383",
+	} {
+		v, err := ParseTSVector(input)
+		require.NoError(t, err)
+		enc := EncodeTSVector(nil, v)
+		decoded, err := DecodeTSVector(enc)
+		require.NoError(t, err)
+		assert.Equal(t, v.String(), decoded.String())
+		_, err = DecodeTSVector(enc[:len(enc)-1])
+		assert.Error(t, err)
+	}
+	v, err := ParseTSVector("a:1A b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 2, 'a', 0, 0, 1, 0xc0, 1, 'b', 0, 0, 0}, EncodeTSVector(nil, v))
+}
+
+func TestEncodeTSQuery(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"a",
+		"fat:AB & (rat:* | !cat) <3> 'joe''s'",
+		"!(a <-> b) | c <0> d",
+	} {
+		q, err := ParseTSQuery(input)
+		require.NoError(t, err)
+		enc := EncodeTSQuery(nil, q)
+		decoded, err := DecodeTSQuery(enc)
+		require.NoError(t, err)
+		assert.Equal(t, q.String(), decoded.String())
+		_, err = DecodeTSQuery(enc[:len(enc)-1])
+		assert.Error(t, err)
+	}
+	q, err := ParseTSQuery("a:*B & !b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0, 0, 0, 4,
+		2, 2,
+		2, 1,
+		1, 0, 0, 'b', 0,
+		1, 4, 1, 'a', 0,
+	}, EncodeTSQuery(nil, q))
+}
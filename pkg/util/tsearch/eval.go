@@ -0,0 +1,332 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "sort"
+
+// matchResult is the result of matching a tsquery node against a tsvector.
+// A node may match maybe if its result depends on the positions of lexemes
+// which have no positions in the tsvector.
+type matchResult uint8
+
+const (
+	matchNo matchResult = iota
+	matchMaybe
+	matchYes
+)
+
+// EvalTSQuery returns whether the tsvector matches the tsquery, which is the
+// result of the tsvector @@ tsquery operator.
+func EvalTSQuery(q TSQuery, v TSVector) bool {
+	if q.root == nil {
+		return false
+	}
+	return v.match(q.root) != matchNo
+}
+
+// match returns whether the tsvector matches the node. The followedBy
+// operators are evaluated using the positions of their operands.
+func (v TSVector) match(n *tsNode) matchResult {
+	switch n.op {
+	case operand:
+		if v.matchOperand(n, nil /* positions */) {
+			return matchYes
+		}
+		return matchNo
+	case not:
+		switch v.match(n.right) {
+		case matchYes:
+			return matchNo
+		case matchNo:
+			return matchYes
+		}
+		return matchMaybe
+	case and:
+		l := v.match(n.left)
+		if l == matchNo {
+			return matchNo
+		}
+		r := v.match(n.right)
+		if r == matchNo {
+			return matchNo
+		}
+		if l == matchYes && r == matchYes {
+			return matchYes
+		}
+		return matchMaybe
+	case or:
+		l := v.match(n.left)
+		if l == matchYes {
+			return matchYes
+		}
+		r := v.match(n.right)
+		if r == matchYes {
+			return matchYes
+		}
+		if l == matchNo && r == matchNo {
+			return matchNo
+		}
+		return matchMaybe
+	case followedBy:
+		var data phraseData
+		// A phrase can only match a tsvector with positions.
+		if v.matchPhrase(n, &data) == matchYes {
+			return matchYes
+		}
+		return matchNo
+	}
+	return matchNo
+}
+
+// matchOperand returns whether the operand is found in the tsvector. If
+// positions is not nil, the positions at which the operand is found are
+// appended to it, and the operand doesn't match the lexemes which have no
+// positions.
+func (v TSVector) matchOperand(n *tsNode, positions *[]uint16) bool {
+	start, end := v.operandTerms(n)
+	found := false
+	for i := start; i < end; i++ {
+		t := &v[i]
+		if len(t.positions) == 0 {
+			// Historically, lexemes without positions match the operands with
+			// weights, so we preserve this behavior.
+			found = found || positions == nil
+			continue
+		}
+		for _, pos := range t.positions {
+			if n.weightMask != 0 && n.weightMask&(1<<pos.weight) == 0 {
+				continue
+			}
+			found = true
+			if positions == nil {
+				break
+			}
+			*positions = append(*positions, pos.position)
+		}
+	}
+	return found
+}
+
+// operandTerms returns the range of the terms of the tsvector which the
+// operand matches.
+func (v TSVector) operandTerms(n *tsNode) (start, end int) {
+	if n.prefix {
+		return v.findPrefix(n.lexeme)
+	}
+	i, ok := v.find(n.lexeme)
+	if !ok {
+		return i, i
+	}
+	return i, i + 1
+}
+
+// hasPositionlessTerm returns whether any term which the operand matches has
+// no positions.
+func (v TSVector) hasPositionlessTerm(n *tsNode) bool {
+	start, end := v.operandTerms(n)
+	for i := start; i < end; i++ {
+		if len(v[i].positions) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// phraseData is the set of positions at which a node matches within a
+// phrase. A position is the end of a match, and the width is the distance
+// between the start and the end of a match. If negate is set, the node
+// matches at every position except the ones in the set.
+type phraseData struct {
+	positions []uint16
+	negate    bool
+	width     int
+}
+
+// matchPhrase computes the positions at which the node matches, which are
+// stored in data.
+func (v TSVector) matchPhrase(n *tsNode, data *phraseData) matchResult {
+	switch n.op {
+	case operand:
+		if v.matchOperand(n, &data.positions) {
+			if len(data.positions) > 1 {
+				data.positions = sortUniquePositions(data.positions)
+			}
+			return matchYes
+		}
+		if v.hasPositionlessTerm(n) {
+			return matchMaybe
+		}
+		return matchNo
+	case not:
+		// The not operator doesn't change the width of its operand.
+		switch v.matchPhrase(n.right, data) {
+		case matchNo:
+			// Match nowhere becomes match everywhere.
+			data.negate = true
+			return matchYes
+		case matchYes:
+			if len(data.positions) > 0 {
+				data.negate = !data.negate
+				return matchYes
+			}
+			// Match everywhere becomes match nowhere.
+			data.negate = false
+			return matchNo
+		}
+		return matchMaybe
+	case and, followedBy:
+		var l, r phraseData
+		lMatch := v.matchPhrase(n.left, &l)
+		if lMatch == matchNo {
+			return matchNo
+		}
+		rMatch := v.matchPhrase(n.right, &r)
+		if rMatch == matchNo {
+			return matchNo
+		}
+		if lMatch == matchMaybe || rMatch == matchMaybe {
+			return matchMaybe
+		}
+		var lOffset, rOffset int
+		if n.op == followedBy {
+			// The left operand must end distance positions before the start of
+			// the right one.
+			lOffset = int(n.distance) + r.width
+			data.width = int(n.distance) + l.width + r.width
+		} else {
+			// Both operands must end at the same position, once aligned to the
+			// widest one.
+			data.width = l.width
+			if r.width > data.width {
+				data.width = r.width
+			}
+			lOffset, rOffset = data.width-l.width, data.width-r.width
+		}
+		switch {
+		case l.negate && r.negate:
+			data.negate = true
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, true, true, true)
+		case l.negate:
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, false, false, true)
+		case r.negate:
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, true, false, false)
+		default:
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, false, true, false)
+		}
+	case or:
+		var l, r phraseData
+		lMatch := v.matchPhrase(n.left, &l)
+		rMatch := v.matchPhrase(n.right, &r)
+		if lMatch == matchNo && rMatch == matchNo {
+			return matchNo
+		}
+		if lMatch == matchMaybe || rMatch == matchMaybe {
+			return matchMaybe
+		}
+		if rMatch == matchNo {
+			*data = l
+			return matchYes
+		}
+		if lMatch == matchNo {
+			*data = r
+			return matchYes
+		}
+		data.width = l.width
+		if r.width > data.width {
+			data.width = r.width
+		}
+		lOffset, rOffset := data.width-l.width, data.width-r.width
+		switch {
+		case l.negate && r.negate:
+			data.negate = true
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, false, true, false)
+		case l.negate:
+			data.negate = true
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, true, false, false)
+		case r.negate:
+			data.negate = true
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, false, false, true)
+		default:
+			data.positions = combinePositions(l.positions, r.positions, lOffset, rOffset, true, true, true)
+		}
+	}
+	if len(data.positions) > 0 || data.negate {
+		return matchYes
+	}
+	return matchNo
+}
+
+// combinePositions merges two sorted sets of positions, once offset. The
+// positions which are only in the left set are kept if leftOnly is set, the
+// ones in both sets are kept if both is set, and the ones only in the right
+// set are kept if rightOnly is set.
+func combinePositions(
+	left, right []uint16, lOffset, rOffset int, leftOnly, both, rightOnly bool,
+) []uint16 {
+	var res []uint16
+	emit := func(pos int) {
+		if pos < 0 || pos > maxPosition {
+			return
+		}
+		res = append(res, uint16(pos))
+	}
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		if i >= len(left) {
+			if !rightOnly {
+				break
+			}
+			emit(int(right[j]) + rOffset)
+			j++
+			continue
+		}
+		if j >= len(right) {
+			if !leftOnly {
+				break
+			}
+			emit(int(left[i]) + lOffset)
+			i++
+			continue
+		}
+		lPos, rPos := int(left[i])+lOffset, int(right[j])+rOffset
+		switch {
+		case lPos < rPos:
+			if leftOnly {
+				emit(lPos)
+			}
+			i++
+		case lPos == rPos:
+			if both {
+				emit(rPos)
+			}
+			i++
+			j++
+		default:
+			if rightOnly {
+				emit(rPos)
+			}
+			j++
+		}
+	}
+	return res
+}
+
+// sortUniquePositions sorts the positions and removes the duplicates.
+func sortUniquePositions(positions []uint16) []uint16 {
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	res := positions[:1]
+	for _, pos := range positions[1:] {
+		if pos != res[len(res)-1] {
+			res = append(res, pos)
+		}
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/keysbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// EncodeInvertedIndexKeys returns the inverted index keys of the tsvector,
+// which are the lexemes of the tsvector prefixed by inKey.
+func EncodeInvertedIndexKeys(inKey []byte, v TSVector) [][]byte {
+	outKeys := make([][]byte, len(v))
+	for i := range v {
+		// Make sure to copy inKey into a new byte slice to avoid aliasing.
+		outKey := make([]byte, len(inKey), len(inKey)+len(v[i].lexeme)+2)
+		copy(outKey, inKey)
+		outKeys[i] = encoding.EncodeStringAscending(outKey, v[i].lexeme)
+	}
+	return outKeys
+}
+
+// GetInvertedExpr returns the inverted expression which must be evaluated on
+// an inverted index of tsvectors to find the tsvectors which may match the
+// tsquery. It returns an error if the tsquery cannot be evaluated using the
+// index, which is the case of the tsqueries that can match tsvectors which
+// contain none of their lexemes, such as !a.
+func (q TSQuery) GetInvertedExpr() (inverted.Expression, error) {
+	if q.root == nil {
+		return nil, errors.New("empty tsquery cannot be evaluated using an inverted index")
+	}
+	expr := q.root.invertedExpr()
+	if _, ok := expr.(inverted.NonInvertedColExpression); ok {
+		return nil, errors.New("tsquery cannot be evaluated using an inverted index")
+	}
+	return expr, nil
+}
+
+// invertedExpr returns the inverted expression of the node.
+func (n *tsNode) invertedExpr() inverted.Expression {
+	switch n.op {
+	case operand:
+		key := encoding.EncodeStringAscending(nil /* b */, n.lexeme)
+		// The lexemes of a tsvector are unique, so an operand can only match a
+		// tsvector once, unless it matches a prefix. A tsvector which contains
+		// the lexeme of the operand matches it unless the operand has weights.
+		tight := n.weightMask == 0
+		if n.prefix {
+			// Remove the terminator of the encoded lexeme so that the span
+			// contains all the lexemes with the prefix.
+			start := key[:len(key)-2]
+			return inverted.ExprForSpan(inverted.Span{
+				Start: inverted.EncVal(start),
+				End:   inverted.EncVal(keysbase.PrefixEnd(start)),
+			}, tight)
+		}
+		spanExpr := inverted.ExprForSpan(inverted.MakeSingleValSpan(key), tight)
+		spanExpr.Unique = true
+		return spanExpr
+	case not:
+		// A negation can match tsvectors which contain none of the lexemes of
+		// the query.
+		return inverted.NonInvertedColExpression{}
+	case and:
+		return inverted.And(n.left.invertedExpr(), n.right.invertedExpr())
+	case or:
+		return inverted.Or(n.left.invertedExpr(), n.right.invertedExpr())
+	case followedBy:
+		// The lexemes of a phrase must be in the tsvector, but the positions of
+		// the lexemes must be checked as well.
+		expr := inverted.And(n.left.invertedExpr(), n.right.invertedExpr())
+		expr.SetNotTight()
+		return expr
+	}
+	return inverted.NonInvertedColExpression{}
+}
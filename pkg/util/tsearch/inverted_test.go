@@ -0,0 +1,71 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeInvertedIndexKeys(t *testing.T) {
+	v, err := ParseTSVector("b:2 a:1 c")
+	require.NoError(t, err)
+	inKey := []byte("prefix")
+	keys := EncodeInvertedIndexKeys(inKey[:len(inKey):len(inKey)], v)
+	require.Len(t, keys, 3)
+	for i, lexeme := range []string{"a", "b", "c"} {
+		assert.Equal(t, encoding.EncodeStringAscending([]byte("prefix"), lexeme), keys[i])
+	}
+}
+
+func TestGetInvertedExpr(t *testing.T) {
+	testCases := []struct {
+		query      string
+		invertible bool
+		tight      bool
+		unique     bool
+	}{
+		{query: "a", invertible: true, tight: true, unique: true},
+		{query: "a:*", invertible: true, tight: true},
+		{query: "a:A", invertible: true, unique: true},
+		{query: "a & b", invertible: true, tight: true, unique: true},
+		{query: "a | b", invertible: true, tight: true},
+		{query: "a <-> b", invertible: true, unique: true},
+		{query: "a & !b", invertible: true, unique: true},
+		{query: "!a <-> b", invertible: true, unique: true},
+		{query: "!a"},
+		{query: "a | !b"},
+		{query: "!a & !b"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			expr, err := q.GetInvertedExpr()
+			if !tc.invertible {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.tight, expr.IsTight())
+			spanExpr, ok := expr.(*inverted.SpanExpression)
+			require.True(t, ok)
+			assert.Equal(t, tc.unique, spanExpr.Unique)
+		})
+	}
+
+	_, err := TSQuery{}.GetInvertedExpr()
+	require.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "math/rand"
+
+// randomLexeme returns a short random lexeme made of lowercase letters.
+func randomLexeme(rng *rand.Rand) string {
+	b := make([]byte, 1+rng.Intn(5))
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(26))
+	}
+	return string(b)
+}
+
+// RandomTSVector returns a random tsvector for testing.
+func RandomTSVector(rng *rand.Rand) TSVector {
+	terms := make([]tsTerm, rng.Intn(10))
+	for i := range terms {
+		terms[i].lexeme = randomLexeme(rng)
+		for j, n := 0, rng.Intn(4); j < n; j++ {
+			terms[i].positions = append(terms[i].positions, tsPosition{
+				position: uint16(1 + rng.Intn(maxPosition)),
+				weight:   weight(rng.Intn(int(weightA) + 1)),
+			})
+		}
+	}
+	return normalizeTSVector(terms)
+}
+
+// RandomTSQuery returns a random non-empty tsquery for testing.
+func RandomTSQuery(rng *rand.Rand) TSQuery {
+	return TSQuery{root: randomTSNode(rng, 3 /* depth */)}
+}
+
+// randomTSNode returns a random tsquery node whose tree is at most depth
+// levels deep.
+func randomTSNode(rng *rand.Rand, depth int) *tsNode {
+	if depth == 0 || rng.Intn(3) == 0 {
+		n := &tsNode{op: operand, lexeme: randomLexeme(rng)}
+		if rng.Intn(4) == 0 {
+			n.weightMask = uint8(1 + rng.Intn(15))
+		}
+		n.prefix = rng.Intn(4) == 0
+		return n
+	}
+	switch op := tsOperator(1 + rng.Intn(int(followedBy))); op {
+	case not:
+		return &tsNode{op: not, right: randomTSNode(rng, depth-1)}
+	case followedBy:
+		return &tsNode{
+			op:       followedBy,
+			distance: uint16(rng.Intn(4)),
+			left:     randomTSNode(rng, depth-1),
+			right:    randomTSNode(rng, depth-1),
+		}
+	default:
+		return &tsNode{op: op, left: randomTSNode(rng, depth-1), right: randomTSNode(rng, depth-1)}
+	}
+}
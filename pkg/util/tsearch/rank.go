@@ -0,0 +1,231 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// DefaultRankWeights are the weights of the positions with the weights D, C,
+// B and A used by Rank when no weights are specified.
+var DefaultRankWeights = [4]float32{0.1, 0.2, 0.4, 1.0}
+
+// The flags of the normalization of ranks, which can be combined.
+const (
+	// rankNormLogLength divides the rank by 1 + the logarithm of the length of
+	// the document.
+	rankNormLogLength = 0x01
+	// rankNormLength divides the rank by the length of the document.
+	rankNormLength = 0x02
+	// rankNormExtDist divides the rank by the mean harmonic distance between
+	// extents, which is only used by ts_rank_cd.
+	rankNormExtDist = 0x04
+	// rankNormUniq divides the rank by the number of unique lexemes of the
+	// document.
+	rankNormUniq = 0x08
+	// rankNormLogUniq divides the rank by 1 + the logarithm of the number of
+	// unique lexemes of the document.
+	rankNormLogUniq = 0x10
+	// rankNormRDivRPlus1 divides the rank by itself + 1.
+	rankNormRDivRPlus1 = 0x20
+)
+
+// MakeRankWeights returns the weights of the positions with the weights D, C,
+// B and A for Rank, from the given array of weights. A negative weight is
+// replaced by its default weight.
+func MakeRankWeights(weights []float64) ([4]float32, error) {
+	res := DefaultRankWeights
+	if len(weights) < len(res) {
+		return res, pgerror.New(pgcode.ArraySubscript, "array of weight is too short")
+	}
+	for i := range res {
+		if weights[i] > 1.0 {
+			return res, pgerror.New(pgcode.InvalidParameterValue, "weight out of range")
+		}
+		if weights[i] >= 0 {
+			res[i] = float32(weights[i])
+		}
+	}
+	return res, nil
+}
+
+// Rank returns the rank of the tsvector for the tsquery, which measures how
+// relevant the document is to the query based on how often, and how close to
+// each other, the lexemes of the query are found in the document. The
+// normalization is a bit mask of the ways to normalize the rank by the length
+// of the document. This is the ts_rank function.
+func Rank(weights [4]float32, v TSVector, q TSQuery, normalization int) (float32, error) {
+	if normalization < 0 {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"normalization flags must be non-negative, got %d", normalization)
+	}
+	if len(v) == 0 || q.root == nil {
+		return 0, nil
+	}
+	operands := uniqueOperands(q)
+	var rank float32
+	if (q.root.op == and || q.root.op == followedBy) && len(operands) >= 2 {
+		rank = rankAnd(weights, v, operands)
+	} else {
+		rank = rankOr(weights, v, operands)
+	}
+	if rank < 0 {
+		rank = 1e-20
+	}
+
+	if normalization&rankNormLogLength != 0 {
+		rank /= float32(math.Log(float64(v.length()+1)) / math.Log(2.0))
+	}
+	if normalization&rankNormLength != 0 {
+		if length := v.length(); length > 0 {
+			rank /= float32(length)
+		}
+	}
+	// rankNormExtDist is not applicable to ts_rank.
+	if normalization&rankNormUniq != 0 {
+		rank /= float32(len(v))
+	}
+	if normalization&rankNormLogUniq != 0 {
+		rank /= float32(math.Log(float64(len(v)+1)) / math.Log(2.0))
+	}
+	if normalization&rankNormRDivRPlus1 != 0 {
+		rank /= rank + 1
+	}
+	return rank, nil
+}
+
+// length returns the number of positions of the tsvector, counting the
+// lexemes without positions once.
+func (v TSVector) length() int {
+	length := 0
+	for i := range v {
+		if n := len(v[i].positions); n > 0 {
+			length += n
+		} else {
+			length++
+		}
+	}
+	return length
+}
+
+// uniqueOperands returns the operands of the tsquery with unique lexemes,
+// sorted by lexeme.
+func uniqueOperands(q TSQuery) []*tsNode {
+	var operands []*tsNode
+	q.root.walk(func(n *tsNode) {
+		if n.op == operand {
+			operands = append(operands, n)
+		}
+	})
+	sort.SliceStable(operands, func(i, j int) bool {
+		return operands[i].lexeme < operands[j].lexeme
+	})
+	res := operands[:0]
+	for _, o := range operands {
+		if len(res) == 0 || res[len(res)-1].lexeme != o.lexeme {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// positionsOrDefault returns the positions of the term, or the default
+// position if it has none.
+func (t *tsTerm) positionsOrDefault(defaultPosition tsPosition) []tsPosition {
+	if len(t.positions) == 0 {
+		return []tsPosition{defaultPosition}
+	}
+	return t.positions
+}
+
+// rankOr ranks each operand of the query by the number and the weights of
+// its positions in the document, and returns the mean of the ranks.
+func rankOr(weights [4]float32, v TSVector, operands []*tsNode) float32 {
+	var rank float32
+	for _, o := range operands {
+		start, end := v.operandTerms(o)
+		for i := start; i < end; i++ {
+			var sum, maxWeight float32 = 0, -1
+			maxIdx := 0
+			for j, pos := range v[i].positionsOrDefault(tsPosition{}) {
+				w := weights[pos.weight]
+				sum += w / float32((j+1)*(j+1))
+				if w > maxWeight {
+					maxWeight, maxIdx = w, j
+				}
+			}
+			// The limit of sum(1/i^2) for i in [1, inf) is pi^2/6.
+			rank += (maxWeight + sum - maxWeight/float32((maxIdx+1)*(maxIdx+1))) / 1.64493406685
+		}
+	}
+	if len(operands) > 0 {
+		rank /= float32(len(operands))
+	}
+	return rank
+}
+
+// rankAnd ranks the document by the distances between the positions of each
+// pair of operands of the query. It returns a negative rank if no pairs of
+// operands are found.
+func rankAnd(weights [4]float32, v TSVector, operands []*tsNode) float32 {
+	var rank float32 = -1
+	// The lexemes without positions are considered to be at the largest
+	// position.
+	defaultPosition := tsPosition{position: maxPosition}
+	positions := make([][]tsPosition, len(operands))
+	noPositions := make([]bool, len(operands))
+	for i, o := range operands {
+		start, end := v.operandTerms(o)
+		for t := start; t < end; t++ {
+			positions[i] = v[t].positionsOrDefault(defaultPosition)
+			noPositions[i] = len(v[t].positions) == 0
+			for k := 0; k < i; k++ {
+				if positions[k] == nil {
+					continue
+				}
+				for _, p := range positions[i] {
+					for _, q := range positions[k] {
+						dist := int(p.position) - int(q.position)
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							if !noPositions[i] && !noPositions[k] {
+								continue
+							}
+							dist = maxPosition + 1
+						}
+						w := float32(math.Sqrt(float64(weights[p.weight] * weights[q.weight] * wordDistance(dist))))
+						if rank < 0 {
+							rank = w
+						} else {
+							rank = 1 - (1-rank)*(1-w)
+						}
+					}
+				}
+			}
+		}
+	}
+	return rank
+}
+
+// wordDistance returns the factor of the rank of two lexemes at the given
+// distance from each other.
+func wordDistance(dist int) float32 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return float32(1.0 / (1.005 + 0.05*math.Exp(float64(dist)/1.5-2)))
+}
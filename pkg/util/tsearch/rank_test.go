@@ -0,0 +1,68 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRank(t *testing.T) {
+	for _, tc := range []struct {
+		vector        string
+		query         string
+		weights       []float64
+		normalization int
+		want          float32
+	}{
+		{vector: "brown:3 dog:9 fox:4 jump:5 lazi:8 quick:2", query: "fox", want: 0.06079271},
+		{vector: "brown:3 dog:9 fox:4 jump:5 lazi:8 quick:2", query: "fox | cat", want: 0.030396355},
+		{vector: "brown:3 dog:9 fox:4 jump:5 lazi:8 quick:2", query: "cat", want: 0},
+		{vector: "brown:3 dog:9 fox:4 jump:5 lazi:8 quick:2", query: "fox:A", want: 0.06079271},
+		{vector: "a:1 b:2", query: "a & b", want: 0.09910322},
+		{vector: "a:1 b:2", query: "a <-> b", want: 0.09910322},
+		{vector: "a:1 b:2", query: "a & c", want: 1e-20},
+		{vector: "a:1A b:2", query: "a", want: 0.6079271},
+		{vector: "a:1A b:2", query: "a", weights: []float64{0.1, 0.2, 0.4, 0.5}, want: 0.30396354},
+		{vector: "a:1A b:2", query: "a", weights: []float64{0.1, 0.2, 0.4, -1}, want: 0.6079271},
+		{vector: "a:1A b:2", query: "a", normalization: 1, want: 0.3835593},
+		{vector: "a:1A b:2", query: "a", normalization: 2, want: 0.30396354},
+		{vector: "a:1A b:2", query: "a", normalization: 8, want: 0.30396354},
+		{vector: "a:1A b:2", query: "a", normalization: 16, want: 0.3835593},
+		{vector: "a:1A b:2", query: "a", normalization: 32, want: 0.37808126},
+		{vector: "a:1A b:2", query: "a", normalization: 2 | 32, want: 0.23310739},
+		{vector: "a b", query: "a", want: 0.06079271},
+		{vector: "", query: "a", want: 0},
+	} {
+		t.Run(tc.vector+" "+tc.query, func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			weights := DefaultRankWeights
+			if tc.weights != nil {
+				weights, err = MakeRankWeights(tc.weights)
+				require.NoError(t, err)
+			}
+			rank, err := Rank(weights, v, q, tc.normalization)
+			require.NoError(t, err)
+			assert.InDelta(t, tc.want, rank, 1e-6)
+		})
+	}
+}
+
+func TestMakeRankWeights(t *testing.T) {
+	_, err := MakeRankWeights([]float64{0.1, 0.2, 0.3})
+	assert.Regexp(t, "array of weight is too short", err)
+	_, err = MakeRankWeights([]float64{0.1, 0.2, 0.3, 1.1})
+	assert.Regexp(t, "weight out of range", err)
+}
@@ -0,0 +1,366 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "strings"
+
+// englishStemExceptions are the words whose stems are special cases of the
+// English stemmer.
+var englishStemExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	// The invariant forms.
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishStep1aInvariants are the words which are not stemmed past step 1a.
+var englishStep1aInvariants = map[string]struct{}{
+	"inning": {}, "outing": {}, "canning": {}, "herring": {}, "earring": {},
+	"proceed": {}, "exceed": {}, "succeed": {},
+}
+
+// stemEnglish returns the stem of a lowercase English word, using the Porter2
+// stemming algorithm of the Snowball project, which is also the one used by
+// the english text search configuration of PostgreSQL.
+func stemEnglish(word string) string {
+	if stem, ok := englishStemExceptions[word]; ok {
+		return stem
+	}
+	if len(word) < 3 {
+		return word
+	}
+	s := englishStemmer{b: []byte(word)}
+	s.prelude()
+	s.markRegions()
+	s.step1a()
+	if _, ok := englishStep1aInvariants[string(s.b)]; !ok {
+		s.step1b()
+		s.step1c()
+		s.step2()
+		s.step3()
+		s.step4()
+		s.step5()
+	}
+	return strings.Replace(string(s.b), "Y", "y", -1)
+}
+
+// englishStemmer holds the state of the stemming of a word. The y which are
+// consonants are marked by replacing them with Y.
+type englishStemmer struct {
+	b []byte
+	// r1 and r2 are the offsets of the R1 and R2 regions of the word, which
+	// are used to decide which suffixes are removed.
+	r1, r2 int
+}
+
+func isEnglishVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// prelude removes an initial apostrophe and marks the y which are consonants.
+func (s *englishStemmer) prelude() {
+	if s.b[0] == '\'' {
+		s.b = s.b[1:]
+	}
+	for i := range s.b {
+		if s.b[i] == 'y' && (i == 0 || isEnglishVowel(s.b[i-1])) {
+			s.b[i] = 'Y'
+		}
+	}
+}
+
+// markRegions computes R1, which is the region after the first non-vowel
+// following a vowel, and R2, which is the region after the first non-vowel
+// following a vowel in R1.
+func (s *englishStemmer) markRegions() {
+	s.r1 = -1
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(s.b), prefix) {
+			s.r1 = len(prefix)
+			break
+		}
+	}
+	if s.r1 < 0 {
+		s.r1 = s.regionAfter(0)
+	}
+	s.r2 = s.regionAfter(s.r1)
+}
+
+// regionAfter returns the offset after the first non-vowel following a vowel
+// at or after start, or the length of the word if there is none.
+func (s *englishStemmer) regionAfter(start int) int {
+	for i := start + 1; i < len(s.b); i++ {
+		if !isEnglishVowel(s.b[i]) && isEnglishVowel(s.b[i-1]) {
+			return i + 1
+		}
+	}
+	return len(s.b)
+}
+
+func (s *englishStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// longestSuffix returns the longest of the suffixes which the word ends with,
+// or "" if none.
+func (s *englishStemmer) longestSuffix(suffixes ...string) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if len(suffix) > len(longest) && s.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+// replaceSuffix replaces the suffix of the word of the given length.
+func (s *englishStemmer) replaceSuffix(length int, replacement string) {
+	s.b = append(s.b[:len(s.b)-length], replacement...)
+}
+
+// inR1 and inR2 return whether the suffix of the given length is in R1 or R2.
+func (s *englishStemmer) inR1(length int) bool { return len(s.b)-length >= s.r1 }
+func (s *englishStemmer) inR2(length int) bool { return len(s.b)-length >= s.r2 }
+
+// containsVowel returns whether the word contains a vowel before the offset.
+func (s *englishStemmer) containsVowel(end int) bool {
+	for i := 0; i < end; i++ {
+		if isEnglishVowel(s.b[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsWithShortSyllable returns whether the word up to the offset ends with a
+// short syllable, which is either a non-vowel other than w, x or Y preceded by
+// a vowel preceded by a non-vowel, or a non-vowel preceded by a vowel at the
+// start of the word.
+func (s *englishStemmer) endsWithShortSyllable(end int) bool {
+	if end >= 3 {
+		c := s.b[end-1]
+		if !isEnglishVowel(c) && c != 'w' && c != 'x' && c != 'Y' &&
+			isEnglishVowel(s.b[end-2]) && !isEnglishVowel(s.b[end-3]) {
+			return true
+		}
+	}
+	return end == 2 && isEnglishVowel(s.b[0]) && !isEnglishVowel(s.b[1])
+}
+
+// isShort returns whether the word is short, which is when R1 is empty and it
+// ends with a short syllable.
+func (s *englishStemmer) isShort() bool {
+	return s.r1 == len(s.b) && s.endsWithShortSyllable(len(s.b))
+}
+
+func (s *englishStemmer) step1a() {
+	if suffix := s.longestSuffix("'", "'s", "'s'"); suffix != "" {
+		s.replaceSuffix(len(suffix), "")
+	}
+	switch suffix := s.longestSuffix("sses", "ied", "ies", "s", "us", "ss"); suffix {
+	case "sses":
+		s.replaceSuffix(len(suffix), "ss")
+	case "ied", "ies":
+		if len(s.b) > len(suffix)+1 {
+			s.replaceSuffix(len(suffix), "i")
+		} else {
+			s.replaceSuffix(len(suffix), "ie")
+		}
+	case "s":
+		// The s is deleted if the preceding part of the word contains a vowel
+		// which is not immediately before it.
+		if s.containsVowel(len(s.b) - 2) {
+			s.replaceSuffix(len(suffix), "")
+		}
+	}
+}
+
+func (s *englishStemmer) step1b() {
+	switch suffix := s.longestSuffix("eed", "eedly", "ed", "edly", "ing", "ingly"); suffix {
+	case "eed", "eedly":
+		if s.inR1(len(suffix)) {
+			s.replaceSuffix(len(suffix), "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		if !s.containsVowel(len(s.b) - len(suffix)) {
+			return
+		}
+		s.replaceSuffix(len(suffix), "")
+		switch {
+		case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+			s.replaceSuffix(0, "e")
+		case s.endsWithDouble():
+			s.replaceSuffix(1, "")
+		case s.isShort():
+			s.replaceSuffix(0, "e")
+		}
+	}
+}
+
+// endsWithDouble returns whether the word ends with one of the doubles bb, dd,
+// ff, gg, mm, nn, pp, rr or tt.
+func (s *englishStemmer) endsWithDouble() bool {
+	n := len(s.b)
+	if n < 2 || s.b[n-1] != s.b[n-2] {
+		return false
+	}
+	switch s.b[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+// step1c replaces a final y by i if it is preceded by a non-vowel which is not
+// the first letter of the word.
+func (s *englishStemmer) step1c() {
+	n := len(s.b)
+	if n > 2 && (s.b[n-1] == 'y' || s.b[n-1] == 'Y') && !isEnglishVowel(s.b[n-2]) {
+		s.b[n-1] = 'i'
+	}
+}
+
+var englishStep2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var englishStep3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var englishStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement",
+	"ment", "ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+// longestSuffixOf returns the longest of the suffixes in the map which the
+// word ends with, or "" if none.
+func (s *englishStemmer) longestSuffixOf(suffixes map[string]string) string {
+	longest := ""
+	for suffix := range suffixes {
+		if len(suffix) > len(longest) && s.hasSuffix(suffix) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+func (s *englishStemmer) step2() {
+	suffix := s.longestSuffixOf(englishStep2Suffixes)
+	if suffix == "" || !s.inR1(len(suffix)) {
+		return
+	}
+	switch suffix {
+	case "ogi":
+		// ogi is only replaced if it is preceded by l.
+		if n := len(s.b) - len(suffix); n == 0 || s.b[n-1] != 'l' {
+			return
+		}
+	case "li":
+		// li is only deleted if it is preceded by a valid li-ending.
+		n := len(s.b) - len(suffix)
+		if n == 0 || !strings.ContainsRune("cdeghkmnrt", rune(s.b[n-1])) {
+			return
+		}
+	}
+	s.replaceSuffix(len(suffix), englishStep2Suffixes[suffix])
+}
+
+func (s *englishStemmer) step3() {
+	suffix := s.longestSuffixOf(englishStep3Suffixes)
+	if suffix == "" || !s.inR1(len(suffix)) {
+		return
+	}
+	if suffix == "ative" && !s.inR2(len(suffix)) {
+		return
+	}
+	s.replaceSuffix(len(suffix), englishStep3Suffixes[suffix])
+}
+
+func (s *englishStemmer) step4() {
+	suffix := s.longestSuffix(englishStep4Suffixes...)
+	if suffix == "" || !s.inR2(len(suffix)) {
+		return
+	}
+	if suffix == "ion" {
+		// ion is only deleted if it is preceded by s or t.
+		if n := len(s.b) - len(suffix); n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't') {
+			return
+		}
+	}
+	s.replaceSuffix(len(suffix), "")
+}
+
+// step5 deletes a final e in R2, or in R1 if not preceded by a short syllable,
+// and a final l in R2 preceded by l.
+func (s *englishStemmer) step5() {
+	n := len(s.b)
+	switch s.b[n-1] {
+	case 'e':
+		if s.inR2(1) || (s.inR1(1) && !s.endsWithShortSyllable(n-1)) {
+			s.replaceSuffix(1, "")
+		}
+	case 'l':
+		if s.inR2(1) && n > 1 && s.b[n-2] == 'l' {
+			s.replaceSuffix(1, "")
+		}
+	}
+}
@@ -0,0 +1,497 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// tsOperator is the operator of a tsquery node.
+type tsOperator uint8
+
+const (
+	// operand is the "operator" of the leaves of a tsquery, which are lexemes.
+	operand tsOperator = iota
+	// not matches the documents which don't match its operand.
+	not
+	// and matches the documents which match both of its operands.
+	and
+	// or matches the documents which match either of its operands.
+	or
+	// followedBy matches the documents in which its right operand is found at
+	// a given distance after its left operand.
+	followedBy
+)
+
+// priority returns the binding priority of the operator, which determines
+// where parentheses are needed when formatting a tsquery.
+func (o tsOperator) priority() int {
+	switch o {
+	case not:
+		return 4
+	case followedBy:
+		return 3
+	case and:
+		return 2
+	case or:
+		return 1
+	}
+	return 5
+}
+
+// tsNode is a node of the tree of a tsquery.
+type tsNode struct {
+	op tsOperator
+
+	// lexeme is the lexeme of an operand.
+	lexeme string
+	// weightMask is the set of weights of the positions which an operand
+	// matches, with the bit 1<<w set for each matching weight w. If it is
+	// zero, all the positions match.
+	weightMask uint8
+	// prefix is set if the operand matches all the lexemes which start with
+	// lexeme.
+	prefix bool
+
+	// distance is the distance between the operands of a followedBy operator.
+	distance uint16
+
+	// left is the left operand of a binary operator. It is nil for the other
+	// nodes.
+	left *tsNode
+	// right is the right operand of a binary operator, or the operand of a not
+	// operator.
+	right *tsNode
+}
+
+// TSQuery is a query for full text search. It is a tree of lexemes combined
+// with boolean and phrase operators.
+type TSQuery struct {
+	root *tsNode
+}
+
+// ParseTSQuery parses the input representation of a tsquery, such as:
+//
+//	'fat' & ( 'rat' | !'cat' ) & 'sup':*AB <-> 'nova'
+//
+// The operators are, from the loosest to the tightest binding: | (or), &
+// (and), <-> and <N> (followed by, at a distance of 1 or N), and ! (not). The
+// lexemes are not normalized.
+func ParseTSQuery(input string) (TSQuery, error) {
+	return parseTSQuery(input, func(o tsQueryOperand) (*tsNode, bool, error) {
+		return &tsNode{lexeme: o.text, weightMask: o.weightMask, prefix: o.prefix}, false, nil
+	})
+}
+
+// tsQueryOperand is an operand of the input representation of a tsquery.
+type tsQueryOperand struct {
+	text       string
+	weightMask uint8
+	prefix     bool
+}
+
+// makeOperandFn returns the node of an operand of a tsquery which is being
+// parsed. The nil nodes within the returned node, or a nil node, stand for
+// operands which must be removed from the query, such as stop words, in which
+// case removed is set.
+type makeOperandFn func(tsQueryOperand) (_ *tsNode, removed bool, _ error)
+
+// parseTSQuery parses the input representation of a tsquery, using makeOperand
+// to process its operands. If any operand is removed, the query is cleaned up
+// so that it doesn't reference it.
+func parseTSQuery(input string, makeOperand makeOperandFn) (TSQuery, error) {
+	p := tsQueryParser{input: input, makeOperand: makeOperand}
+	p.skipSpace()
+	if p.done() {
+		return TSQuery{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return TSQuery{}, p.syntaxError()
+	}
+	if p.removed {
+		root = cleanupTSQuery(root)
+	}
+	return TSQuery{root: root}, nil
+}
+
+type tsQueryParser struct {
+	input       string
+	pos         int
+	makeOperand makeOperandFn
+	// removed is set if an operand was removed from the query.
+	removed bool
+}
+
+func (p *tsQueryParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tsQueryParser) skipSpace() {
+	for !p.done() && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *tsQueryParser) syntaxError() error {
+	return pgerror.Newf(pgcode.Syntax, "syntax error in tsquery: %q", p.input)
+}
+
+// consume skips the whitespace, and then the token if it is next.
+func (p *tsQueryParser) consume(token byte) bool {
+	p.skipSpace()
+	if !p.done() && p.input[p.pos] == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tsQueryParser) parseOr() (*tsNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume('|') {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &tsNode{op: or, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *tsQueryParser) parseAnd() (*tsNode, error) {
+	left, err := p.parseFollowedBy()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume('&') {
+		right, err := p.parseFollowedBy()
+		if err != nil {
+			return nil, err
+		}
+		left = &tsNode{op: and, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *tsQueryParser) parseFollowedBy() (*tsNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		distance, ok, err := p.followedByOperator()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &tsNode{op: followedBy, distance: distance, left: left, right: right}
+	}
+}
+
+// followedByOperator parses a <-> or <N> operator if it is next, and returns
+// its distance.
+func (p *tsQueryParser) followedByOperator() (distance uint16, ok bool, _ error) {
+	if !p.consume('<') {
+		return 0, false, nil
+	}
+	if strings.HasPrefix(p.input[p.pos:], "->") {
+		p.pos += 2
+		return 1, true, nil
+	}
+	start := p.pos
+	for !p.done() && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos || p.done() || p.input[p.pos] != '>' {
+		return 0, false, p.syntaxError()
+	}
+	n, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil || n > maxPhraseDistance {
+		return 0, false, pgerror.Newf(pgcode.InvalidParameterValue,
+			"distance in phrase operator must be an integer value between zero and %d inclusive",
+			maxPhraseDistance)
+	}
+	p.pos++
+	return uint16(n), true, nil
+}
+
+func (p *tsQueryParser) parseNot() (*tsNode, error) {
+	if p.consume('!') {
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &tsNode{op: not, right: child}, nil
+	}
+	if p.consume('(') {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, p.syntaxError()
+		}
+		return node, nil
+	}
+	return p.parseOperand()
+}
+
+// parseOperand parses a quoted or unquoted lexeme, which may be followed by a
+// colon and then by a * prefix flag and by weights.
+func (p *tsQueryParser) parseOperand() (*tsNode, error) {
+	p.skipSpace()
+	if p.done() {
+		return nil, p.syntaxError()
+	}
+	var b strings.Builder
+	quoted := p.input[p.pos] == '\''
+	if quoted {
+		p.pos++
+	}
+	for {
+		if p.done() {
+			if quoted {
+				return nil, p.syntaxError()
+			}
+			break
+		}
+		c := p.input[p.pos]
+		if c == '\\' {
+			if p.pos+1 >= len(p.input) {
+				return nil, p.syntaxError()
+			}
+			b.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if quoted && c == '\'' {
+			p.pos++
+			if p.done() || p.input[p.pos] != '\'' {
+				break
+			}
+			b.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		if !quoted && (isSpace(c) || isTSQueryOperatorChar(c) || c == ':') {
+			break
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	o := tsQueryOperand{text: b.String()}
+	if o.text == "" {
+		return nil, p.syntaxError()
+	}
+	if err := checkLexemeLen(o.text); err != nil {
+		return nil, err
+	}
+	if !p.done() && p.input[p.pos] == ':' {
+		p.pos++
+		for ; !p.done(); p.pos++ {
+			if c := p.input[p.pos]; c == '*' {
+				o.prefix = true
+			} else if w, ok := parseWeight(c); ok {
+				o.weightMask |= 1 << w
+			} else {
+				break
+			}
+		}
+	}
+	node, removed, err := p.makeOperand(o)
+	if err != nil {
+		return nil, err
+	}
+	p.removed = p.removed || removed
+	return node, nil
+}
+
+func isTSQueryOperatorChar(c byte) bool {
+	switch c {
+	case '!', '&', '|', '(', ')', '<':
+		return true
+	}
+	return false
+}
+
+// cleanupTSQuery removes the nil operands from the tree, along with the
+// operators which are left with no operands. The distances of the followedBy
+// operators are increased by the widths of the removed operands, so that e.g.
+// the query for the phrase "cat ate the rat" is 'cat' <-> 'ate' <2> 'rat'.
+func cleanupTSQuery(node *tsNode) *tsNode {
+	node, _, _ = cleanupTSNode(node)
+	return node
+}
+
+// cleanupTSNode returns the cleaned up node, along with the distances which
+// must be added to the left and to the right of it by the enclosing followedBy
+// operators to account for the removed operands.
+func cleanupTSNode(node *tsNode) (_ *tsNode, leftAdd, rightAdd int) {
+	if node == nil || node.op == operand {
+		return node, 0, 0
+	}
+	if node.op == not {
+		var right *tsNode
+		right, leftAdd, rightAdd = cleanupTSNode(node.right)
+		if right == nil {
+			return nil, leftAdd, rightAdd
+		}
+		return &tsNode{op: not, right: right}, leftAdd, rightAdd
+	}
+
+	left, leftLeftAdd, leftRightAdd := cleanupTSNode(node.left)
+	right, rightLeftAdd, rightRightAdd := cleanupTSNode(node.right)
+	isPhrase := node.op == followedBy
+	distance := int(node.distance)
+	switch {
+	case left == nil && right == nil:
+		// The distance of a removed phrase is propagated to both sides, and the
+		// enclosing operator counts it once.
+		if isPhrase {
+			add := leftLeftAdd + distance + rightLeftAdd
+			return nil, add, add
+		}
+		add := leftLeftAdd
+		if rightLeftAdd > add {
+			add = rightLeftAdd
+		}
+		return nil, add, add
+	case left == nil:
+		if isPhrase {
+			return right, leftLeftAdd + distance + rightLeftAdd, rightRightAdd
+		}
+		return right, rightLeftAdd, rightRightAdd
+	case right == nil:
+		if isPhrase {
+			return left, leftLeftAdd, leftRightAdd + distance + rightRightAdd
+		}
+		return left, leftLeftAdd, leftRightAdd
+	case isPhrase:
+		distance += leftRightAdd + rightLeftAdd
+		if distance > maxPhraseDistance {
+			distance = maxPhraseDistance
+		}
+		res := &tsNode{op: followedBy, distance: uint16(distance), left: left, right: right}
+		return res, leftLeftAdd, rightRightAdd
+	default:
+		return &tsNode{op: node.op, left: left, right: right}, 0, 0
+	}
+}
+
+// String returns the output representation of the tsquery.
+func (q TSQuery) String() string {
+	var b strings.Builder
+	if q.root != nil {
+		q.root.format(&b, 0 /* parentPriority */, false /* rightOfPhrase */)
+	}
+	return b.String()
+}
+
+// format writes the node to b. Parentheses are added around the node if it
+// binds looser than its parent, or if it is a phrase which is the right
+// operand of a phrase.
+func (n *tsNode) format(b *strings.Builder, parentPriority int, rightOfPhrase bool) {
+	if n.op == operand {
+		writeLexeme(b, n.lexeme)
+		if n.prefix || n.weightMask != 0 {
+			b.WriteByte(':')
+			if n.prefix {
+				b.WriteByte('*')
+			}
+			for w := weightA; ; w-- {
+				if n.weightMask&(1<<w) != 0 {
+					b.WriteByte(weightLetters[w])
+				}
+				if w == weightD {
+					break
+				}
+			}
+		}
+		return
+	}
+	priority := n.op.priority()
+	parens := priority < parentPriority || (rightOfPhrase && n.op == followedBy)
+	if parens {
+		b.WriteString("( ")
+	}
+	if n.op == not {
+		b.WriteByte('!')
+		n.right.format(b, priority, false /* rightOfPhrase */)
+	} else {
+		n.left.format(b, priority, false /* rightOfPhrase */)
+		switch n.op {
+		case and:
+			b.WriteString(" & ")
+		case or:
+			b.WriteString(" | ")
+		case followedBy:
+			if n.distance == 1 {
+				b.WriteString(" <-> ")
+			} else {
+				b.WriteString(" <")
+				b.WriteString(strconv.Itoa(int(n.distance)))
+				b.WriteString("> ")
+			}
+		}
+		n.right.format(b, priority, n.op == followedBy)
+	}
+	if parens {
+		b.WriteString(" )")
+	}
+}
+
+// IsEmpty returns whether the tsquery has no lexemes.
+func (q TSQuery) IsEmpty() bool {
+	return q.root == nil
+}
+
+// Compare returns -1, 0 or 1 depending on whether q sorts before, the same as,
+// or after other.
+func (q TSQuery) Compare(other TSQuery) int {
+	return strings.Compare(q.String(), other.String())
+}
+
+// Size returns the approximate size of the tsquery in bytes.
+func (q TSQuery) Size() uintptr {
+	var size uintptr
+	q.root.walk(func(n *tsNode) {
+		size += 48 + uintptr(len(n.lexeme))
+	})
+	return size
+}
+
+// walk calls fn on each node of the tree rooted at n, in pre-order.
+func (n *tsNode) walk(fn func(*tsNode)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	n.left.walk(fn)
+	n.right.walk(fn)
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSQuery(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{input: "", want: ""},
+		{input: "fat & rat", want: "'fat' & 'rat'"},
+		{input: "fat & (rat | cat)", want: "'fat' & ( 'rat' | 'cat' )"},
+		{input: "fat & rat & ! cat", want: "'fat' & 'rat' & !'cat'"},
+		{input: "fat:ab & cat", want: "'fat':AB & 'cat'"},
+		{input: "super:*", want: "'super':*"},
+		{input: "'super':*ba", want: "'super':*AB"},
+		{input: "'joe''s' | 'a b'", want: "'joe''s' | 'a b'"},
+		{input: "a & b | c", want: "'a' & 'b' | 'c'"},
+		{input: "a | b & c", want: "'a' | 'b' & 'c'"},
+		{input: "(a | b) & c", want: "( 'a' | 'b' ) & 'c'"},
+		{input: "!(a & b)", want: "!( 'a' & 'b' )"},
+		{input: "!!a", want: "!!'a'"},
+		{input: "a<->b", want: "'a' <-> 'b'"},
+		{input: "a <2> b", want: "'a' <2> 'b'"},
+		{input: "a <0> b", want: "'a' <0> 'b'"},
+		{input: "(a <-> b) <-> c", want: "'a' <-> 'b' <-> 'c'"},
+		{input: "a <-> (b <-> c)", want: "'a' <-> ( 'b' <-> 'c' )"},
+		{input: "a <-> b & c", want: "'a' <-> 'b' & 'c'"},
+		{input: "a <-> (b & c)", want: "'a' <-> ( 'b' & 'c' )"},
+		{input: "!a <-> b", want: "!'a' <-> 'b'"},
+		{input: "!(a <-> b)", want: "!( 'a' <-> 'b' )"},
+		{input: "a &", err: "syntax error in tsquery"},
+		{input: "a b", err: "syntax error in tsquery"},
+		{input: "(a", err: "syntax error in tsquery"},
+		{input: "a)", err: "syntax error in tsquery"},
+		{input: "a <x> b", err: "syntax error in tsquery"},
+		{input: "a <16385> b", err: "distance in phrase operator must be an integer value between zero and 16384 inclusive"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.input)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Regexp(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, q.String())
+			// The output representation parses back to the same tsquery.
+			roundTripped, err := ParseTSQuery(q.String())
+			require.NoError(t, err)
+			assert.Equal(t, q.String(), roundTripped.String())
+		})
+	}
+}
+
+func TestEvalTSQuery(t *testing.T) {
+	for _, tc := range []struct {
+		vector string
+		query  string
+		want   bool
+	}{
+		{"a fat cat sat on a mat and ate a fat rat", "cat & rat", true},
+		{"a fat cat sat on a mat and ate a fat rat", "fat & cow", false},
+		{"a fat cat sat on a mat and ate a fat rat", "cow | rat", true},
+		{"a fat cat sat on a mat and ate a fat rat", "!cow", true},
+		{"a fat cat sat on a mat and ate a fat rat", "!cat", false},
+		{"a fat cat", "", false},
+		{"", "!cat", true},
+		{"postgraduate", "postgr:*", true},
+		{"postgraduate", "postgres:*", false},
+		// Weights.
+		{"a:1A b:2", "a:A", true},
+		{"a:1A b:2", "b:A", false},
+		{"a:1A b:2", "b:AD", true},
+		{"a b", "a:A", true},
+		// Phrases.
+		{"fatal:1 error:2", "fatal <-> error", true},
+		{"error:1 is:2 not:3 fatal:4", "fatal <-> error", false},
+		{"a:1 b:3", "a <-> b", false},
+		{"a:1 b:3", "a <2> b", true},
+		{"a:1 b:1", "a <0> b", true},
+		{"a:1 b:2 c:3", "a <-> b <-> c", true},
+		{"a:1 b:2 c:3", "a <2> c", true},
+		{"a:1 b:2 c:3", "(a <-> b) <-> c", true},
+		{"a:1 b:2 c:3", "a <-> (b <-> c)", true},
+		{"a:1 b:2 c:3", "a <-> (b & c)", false},
+		{"a:1 b:2 c:2", "a <-> (b & c)", true},
+		{"a:1 b:2 c:4", "a <-> (b | c)", true},
+		{"a:1 b:3 c:4", "a <-> (b | c)", false},
+		{"a:1 b:2", "!a <-> b", false},
+		{"a:1 c:2 b:3", "!a <-> b", true},
+		{"a:1 b:2", "a <-> !b", false},
+		{"a:1 c:2", "a <-> !b", true},
+		{"a:1 b:2", "!(a <-> b)", false},
+		{"a:1 b:3", "!(a <-> b)", true},
+		{"sup:1 supreme:2 nova:3", "sup:* <-> nova", true},
+		// Phrases don't match lexemes without positions.
+		{"a b", "a <-> b", false},
+		{"a b", "a & b", true},
+		{"a:1 b", "a <-> b", false},
+	} {
+		t.Run(tc.vector+" @@ "+tc.query, func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, EvalTSQuery(q, v))
+		})
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package tsearch implements the tsvector and tsquery types used for full text
+// search, along with the operations over them: matching a tsquery against a
+// tsvector, ranking, and the normalization of documents and queries into
+// lexemes. The behavior follows that of PostgreSQL.
+package tsearch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+const (
+	// maxLexemeLen is the maximum length of a lexeme, in bytes.
+	maxLexemeLen = 2047
+	// maxPosition is the largest position of a lexeme in a document. Larger
+	// positions are clamped to it.
+	maxPosition = 16383
+	// maxPhraseDistance is the largest distance of a phrase operator.
+	maxPhraseDistance = 16384
+	// maxNumPositions is the maximum number of positions kept per lexeme.
+	// Further positions are dropped.
+	maxNumPositions = 256
+)
+
+// weight is the weight of a lexeme position, which is used to mark positions
+// in different parts of a document, such as a title and a body. The weights
+// are ordered from the least to the most important.
+type weight uint8
+
+const (
+	weightD weight = iota
+	weightC
+	weightB
+	weightA
+)
+
+// weightLetters are the letters of the weights, indexed by weight.
+const weightLetters = "DCBA"
+
+// parseWeight returns the weight named by the letter c, which is
+// case-insensitive.
+func parseWeight(c byte) (weight, bool) {
+	switch c {
+	case 'a', 'A':
+		return weightA, true
+	case 'b', 'B':
+		return weightB, true
+	case 'c', 'C':
+		return weightC, true
+	case 'd', 'D':
+		return weightD, true
+	}
+	return 0, false
+}
+
+// tsPosition is a position of a lexeme in a document, along with its weight.
+type tsPosition struct {
+	position uint16
+	weight   weight
+}
+
+// tsTerm is a lexeme of a tsvector along with its positions, which are sorted
+// and unique. A term may have no positions, e.g. if it was stripped.
+type tsTerm struct {
+	lexeme    string
+	positions []tsPosition
+}
+
+// TSVector is a sorted list of unique lexemes along with their positions. It
+// is the processed form of a document which is optimized for full text search.
+type TSVector []tsTerm
+
+// ParseTSVector parses the input representation of a tsvector, which is a
+// whitespace separated list of lexemes, each followed by an optional list of
+// positions, such as:
+//
+//	'fat':2 'cat':3A,5 rat
+//
+// Lexemes which contain whitespace or quotes must be quoted, and the quotes
+// within a quoted lexeme are doubled. Backslashes escape the next character.
+func ParseTSVector(input string) (TSVector, error) {
+	p := tsVectorParser{input: input}
+	var terms []tsTerm
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		lexeme, err := p.lexeme()
+		if err != nil {
+			return nil, err
+		}
+		term := tsTerm{lexeme: lexeme}
+		if !p.done() && p.input[p.pos] == ':' {
+			if term.positions, err = p.positions(); err != nil {
+				return nil, err
+			}
+		}
+		if !p.done() && !isSpace(p.input[p.pos]) {
+			return nil, p.syntaxError()
+		}
+		terms = append(terms, term)
+	}
+	return normalizeTSVector(terms), nil
+}
+
+type tsVectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *tsVectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tsVectorParser) skipSpace() {
+	for !p.done() && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *tsVectorParser) syntaxError() error {
+	return pgerror.Newf(pgcode.Syntax, "syntax error in tsvector: %q", p.input)
+}
+
+// lexeme parses a lexeme, which is either quoted or terminated by whitespace
+// or by the colon which starts its positions.
+func (p *tsVectorParser) lexeme() (string, error) {
+	var b strings.Builder
+	quoted := p.input[p.pos] == '\''
+	if quoted {
+		p.pos++
+	}
+	for {
+		if p.done() {
+			if quoted {
+				return "", p.syntaxError()
+			}
+			break
+		}
+		c := p.input[p.pos]
+		if c == '\\' {
+			if p.pos+1 >= len(p.input) {
+				return "", p.syntaxError()
+			}
+			b.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if quoted && c == '\'' {
+			p.pos++
+			if p.done() || p.input[p.pos] != '\'' {
+				break
+			}
+			b.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		if !quoted && (isSpace(c) || (c == ':' && p.pos+1 < len(p.input) && isDigit(p.input[p.pos+1]))) {
+			break
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	lexeme := b.String()
+	if lexeme == "" {
+		return "", p.syntaxError()
+	}
+	if err := checkLexemeLen(lexeme); err != nil {
+		return "", err
+	}
+	return lexeme, nil
+}
+
+// positions parses the comma separated list of positions of a lexeme, which
+// starts with a colon. Each position may be followed by a weight.
+func (p *tsVectorParser) positions() ([]tsPosition, error) {
+	var positions []tsPosition
+	p.pos++
+	for {
+		start := p.pos
+		for !p.done() && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+		if start == p.pos {
+			return nil, p.syntaxError()
+		}
+		n, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil || n > maxPosition {
+			n = maxPosition
+		}
+		if n == 0 {
+			return nil, pgerror.Newf(pgcode.Syntax, "wrong position info in tsvector: %q", p.input)
+		}
+		pos := tsPosition{position: uint16(n)}
+		if !p.done() {
+			if w, ok := parseWeight(p.input[p.pos]); ok {
+				pos.weight = w
+				p.pos++
+			}
+		}
+		positions = append(positions, pos)
+		if p.done() || p.input[p.pos] != ',' {
+			return positions, nil
+		}
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func checkLexemeLen(lexeme string) error {
+	if len(lexeme) > maxLexemeLen {
+		return pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"word is too long (%d bytes, max %d bytes)", len(lexeme), maxLexemeLen)
+	}
+	return nil
+}
+
+// normalizeTSVector sorts the terms by lexeme and merges the terms which have
+// the same lexeme.
+func normalizeTSVector(terms []tsTerm) TSVector {
+	if len(terms) == 0 {
+		return TSVector{}
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].lexeme < terms[j].lexeme
+	})
+	res := terms[:1]
+	for _, t := range terms[1:] {
+		last := &res[len(res)-1]
+		if t.lexeme == last.lexeme {
+			last.positions = append(last.positions, t.positions...)
+			continue
+		}
+		res = append(res, t)
+	}
+	for i := range res {
+		res[i].positions = normalizePositions(res[i].positions)
+	}
+	return res
+}
+
+// normalizePositions sorts the positions and removes the duplicate positions,
+// keeping the largest weight of each position.
+func normalizePositions(positions []tsPosition) []tsPosition {
+	if len(positions) == 0 {
+		return nil
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].position < positions[j].position
+	})
+	res := positions[:1]
+	for _, pos := range positions[1:] {
+		last := &res[len(res)-1]
+		if pos.position == last.position {
+			if pos.weight > last.weight {
+				last.weight = pos.weight
+			}
+			continue
+		}
+		res = append(res, pos)
+	}
+	if len(res) > maxNumPositions {
+		res = res[:maxNumPositions]
+	}
+	return res
+}
+
+// String returns the output representation of the tsvector.
+func (v TSVector) String() string {
+	var b strings.Builder
+	for i, t := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		writeLexeme(&b, t.lexeme)
+		for j, pos := range t.positions {
+			if j == 0 {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(pos.position)))
+			if pos.weight != weightD {
+				b.WriteByte(weightLetters[pos.weight])
+			}
+		}
+	}
+	return b.String()
+}
+
+// writeLexeme writes the quoted lexeme to b, escaping its quotes and
+// backslashes.
+func writeLexeme(b *strings.Builder, lexeme string) {
+	b.WriteByte('\'')
+	for i := 0; i < len(lexeme); i++ {
+		if c := lexeme[i]; c == '\'' || c == '\\' {
+			b.WriteByte(c)
+		}
+		b.WriteByte(lexeme[i])
+	}
+	b.WriteByte('\'')
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, the same as,
+// or after other. Vectors with fewer lexemes sort first, and vectors with the
+// same number of lexemes are compared lexeme by lexeme.
+func (v TSVector) Compare(other TSVector) int {
+	if len(v) != len(other) {
+		return compareInts(len(v), len(other))
+	}
+	for i := range v {
+		a, b := &v[i], &other[i]
+		if c := strings.Compare(a.lexeme, b.lexeme); c != 0 {
+			return c
+		}
+		if len(a.positions) != len(b.positions) {
+			return compareInts(len(a.positions), len(b.positions))
+		}
+		for j := range a.positions {
+			if a.positions[j] != b.positions[j] {
+				return compareInts(int(a.positions[j].encode()), int(b.positions[j].encode()))
+			}
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Lexemes returns the lexemes of the tsvector, in sorted order.
+func (v TSVector) Lexemes() []string {
+	lexemes := make([]string, len(v))
+	for i := range v {
+		lexemes[i] = v[i].lexeme
+	}
+	return lexemes
+}
+
+// Size returns the approximate size of the tsvector in bytes.
+func (v TSVector) Size() uintptr {
+	size := uintptr(len(v)) * 40
+	for i := range v {
+		size += uintptr(len(v[i].lexeme)) + uintptr(len(v[i].positions))*4
+	}
+	return size
+}
+
+// find returns the index of the term with the given lexeme, and whether it
+// was found.
+func (v TSVector) find(lexeme string) (int, bool) {
+	i := sort.Search(len(v), func(i int) bool {
+		return v[i].lexeme >= lexeme
+	})
+	return i, i < len(v) && v[i].lexeme == lexeme
+}
+
+// findPrefix returns the range of the terms whose lexemes start with prefix.
+func (v TSVector) findPrefix(prefix string) (start, end int) {
+	start, _ = v.find(prefix)
+	end = start
+	for end < len(v) && strings.HasPrefix(v[end].lexeme, prefix) {
+		end++
+	}
+	return start, end
+}
+
+// isWordRune returns whether r is part of a word of a document.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
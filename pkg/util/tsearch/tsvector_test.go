@@ -0,0 +1,75 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSVector(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{input: "", want: ""},
+		{input: "  ", want: ""},
+		{input: "a fat cat sat on a mat and ate a fat rat",
+			want: "'a' 'and' 'ate' 'cat' 'fat' 'mat' 'on' 'rat' 'sat'"},
+		{input: "the lexeme '    ' contains spaces",
+			want: "'    ' 'contains' 'lexeme' 'spaces' 'the'"},
+		{input: "the lexeme 'Joe''s' contains a quote",
+			want: "'Joe''s' 'a' 'contains' 'lexeme' 'quote' 'the'"},
+		{input: `a\ b c\\d`, want: `'a b' 'c\\d'`},
+		{input: "a:1 fat:2 cat:3 sat:4 on:5 a:6 mat:7 and:8 ate:9 a:10 fat:11 rat:12",
+			want: "'a':1,6,10 'and':8 'ate':9 'cat':3 'fat':2,11 'mat':7 'on':5 'rat':12 'sat':4"},
+		{input: "a:1A fat:2B,4C cat:5D", want: "'a':1A 'cat':5 'fat':2B,4C"},
+		{input: "a:3,1,3b a:2", want: "'a':1,2,3B"},
+		{input: "a:1 a", want: "'a':1"},
+		{input: "'a':99999", want: "'a':16383"},
+		{input: "a:b", want: "'a:b'"},
+		{input: "a:0", err: "wrong position info in tsvector"},
+		{input: "a:1x", err: "syntax error in tsvector"},
+		{input: "'a", err: "syntax error in tsvector"},
+		{input: "''", err: "syntax error in tsvector"},
+		{input: "a:", want: "'a:'"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := ParseTSVector(tc.input)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Regexp(t, tc.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, v.String())
+			// The output representation parses back to the same tsvector.
+			roundTripped, err := ParseTSVector(v.String())
+			require.NoError(t, err)
+			assert.Equal(t, 0, v.Compare(roundTripped))
+		})
+	}
+}
+
+func TestTSVectorCompare(t *testing.T) {
+	ordered := []string{"", "a", "a:1", "a:2", "a:1A", "a:1,2", "b", "a b"}
+	for i := range ordered {
+		for j := range ordered {
+			l, err := ParseTSVector(ordered[i])
+			require.NoError(t, err)
+			r, err := ParseTSVector(ordered[j])
+			require.NoError(t, err)
+			assert.Equal(t, compareInts(i, j), l.Compare(r), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+}
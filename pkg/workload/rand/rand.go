@@ -450,6 +450,10 @@ func DatumToGoSQL(d tree.Datum) (interface{}, error) {
 		return d.IPAddr.String(), nil
 	case *tree.DJSON:
 		return d.JSON.String(), nil
+	case *tree.DTSQuery:
+		return d.TSQuery.String(), nil
+	case *tree.DTSVector:
+		return d.TSVector.String(), nil
 	case *tree.DTimeTZ:
 		return d.TimeTZ.String(), nil
 	case *tree.DBox2D: